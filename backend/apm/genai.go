@@ -0,0 +1,174 @@
+package apm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const genAIInstrumentation = "github.com/chaitin/panda-wiki/apm/genai"
+
+// GenAIModel model described by the gen_ai semantic conventions
+type GenAIModel struct {
+	// gen_ai.system, e.g. openai or deepseek
+	System string
+	Model  string
+	// base url of the api, reported as server.address and server.port
+	BaseURL string
+	// request parameters set when the model was created, options of a request take precedence
+	Temperature *float32
+}
+
+// chatModel chat model recording a span with gen_ai attributes for each request
+type chatModel struct {
+	model.BaseChatModel
+	info GenAIModel
+}
+
+// TraceChatModel wrap the chat model to record a client span for each request, with the model, token usage and
+// finish reason as gen_ai attributes so dashboards for llm apps work with the traces. spans are dropped if apm is disabled
+func TraceChatModel(m model.BaseChatModel, info GenAIModel) model.BaseChatModel {
+	return &chatModel{BaseChatModel: m, info: info}
+}
+
+func (m *chatModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	ctx, span := m.start(ctx, opts)
+	defer span.End()
+	msg, err := m.BaseChatModel.Generate(ctx, input, opts...)
+	if err != nil {
+		endWithError(span, err)
+		return nil, err
+	}
+	span.SetAttributes(responseAttributes(msg, nil)...)
+	return msg, nil
+}
+
+func (m *chatModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	ctx, span := m.start(ctx, opts)
+	sr, err := m.BaseChatModel.Stream(ctx, input, opts...)
+	if err != nil {
+		endWithError(span, err)
+		span.End()
+		return nil, err
+	}
+	// chunks are passed on as they come, the span ends with the stream
+	out, sw := schema.Pipe[*schema.Message](1)
+	go func() {
+		defer span.End()
+		defer sr.Close()
+		defer sw.Close()
+		var finishReasons []string
+		for {
+			msg, err := sr.Recv()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				endWithError(span, err)
+				sw.Send(nil, err)
+				return
+			}
+			if msg.ResponseMeta != nil && msg.ResponseMeta.FinishReason != "" {
+				finishReasons = append(finishReasons, msg.ResponseMeta.FinishReason)
+			}
+			span.SetAttributes(responseAttributes(msg, finishReasons)...)
+			if closed := sw.Send(msg, nil); closed {
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+func (m *chatModel) start(ctx context.Context, opts []model.Option) (context.Context, trace.Span) {
+	options := model.GetCommonOptions(&model.Options{Temperature: m.info.Temperature, Model: &m.info.Model}, opts...)
+	attrs := []attribute.KeyValue{
+		semconv.GenAIOperationNameChat,
+		semconv.GenAISystemKey.String(m.info.System),
+		semconv.GenAIRequestModel(*options.Model),
+	}
+	if options.Temperature != nil {
+		attrs = append(attrs, semconv.GenAIRequestTemperature(float64(*options.Temperature)))
+	}
+	if options.TopP != nil {
+		attrs = append(attrs, semconv.GenAIRequestTopP(float64(*options.TopP)))
+	}
+	if options.MaxTokens != nil {
+		attrs = append(attrs, semconv.GenAIRequestMaxTokens(*options.MaxTokens))
+	}
+	if len(options.Stop) > 0 {
+		attrs = append(attrs, semconv.GenAIRequestStopSequences(options.Stop...))
+	}
+	attrs = append(attrs, serverAttributes(m.info.BaseURL)...)
+	return otel.Tracer(genAIInstrumentation).Start(ctx, "chat "+*options.Model,
+		trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+// responseAttributes usage and finish reasons of a response or a chunk of a stream
+func responseAttributes(msg *schema.Message, finishReasons []string) []attribute.KeyValue {
+	if msg == nil || msg.ResponseMeta == nil {
+		return nil
+	}
+	var attrs []attribute.KeyValue
+	if finishReasons == nil && msg.ResponseMeta.FinishReason != "" {
+		finishReasons = []string{msg.ResponseMeta.FinishReason}
+	}
+	if len(finishReasons) > 0 {
+		attrs = append(attrs, semconv.GenAIResponseFinishReasons(finishReasons...))
+	}
+	if usage := msg.ResponseMeta.Usage; usage != nil {
+		attrs = append(attrs,
+			semconv.GenAIUsageInputTokens(usage.PromptTokens),
+			semconv.GenAIUsageOutputTokens(usage.CompletionTokens),
+		)
+	}
+	return attrs
+}
+
+func serverAttributes(baseURL string) []attribute.KeyValue {
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Host == "" {
+		return nil
+	}
+	attrs := []attribute.KeyValue{semconv.ServerAddress(u.Hostname())}
+	port := u.Port()
+	if port == "" {
+		switch u.Scheme {
+		case "https":
+			port = "443"
+		case "http":
+			port = "80"
+		}
+	}
+	if p, err := strconv.Atoi(port); err == nil {
+		attrs = append(attrs, semconv.ServerPort(p))
+	}
+	return attrs
+}
+
+func endWithError(span trace.Span, err error) {
+	errorType := "_OTHER"
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		errorType = "timeout"
+	case errors.Is(err, context.Canceled):
+		errorType = "canceled"
+	case errors.As(err, &netErr):
+		errorType = "network"
+	}
+	span.SetAttributes(semconv.ErrorTypeKey.String(errorType))
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
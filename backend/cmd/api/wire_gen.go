@@ -21,6 +21,7 @@ import (
 	"github.com/chaitin/panda-wiki/server/http"
 	"github.com/chaitin/panda-wiki/store/cache"
 	"github.com/chaitin/panda-wiki/store/ipdb"
+	"github.com/chaitin/panda-wiki/store/mail"
 	"github.com/chaitin/panda-wiki/store/pg"
 	"github.com/chaitin/panda-wiki/store/rag"
 	"github.com/chaitin/panda-wiki/store/s3"
@@ -54,54 +55,98 @@ func createApp() (*App, error) {
 		return nil, err
 	}
 	ragRepository := mq2.NewRAGRepository(mqProducer)
+	nodeReviewRepository := pg2.NewNodeReviewRepository(db)
 	cacheCache, err := cache.NewCache(configConfig)
 	if err != nil {
 		return nil, err
 	}
 	kbRepo := cache2.NewKBRepo(cacheCache)
-	knowledgeBaseUsecase, err := usecase.NewKnowledgeBaseUsecase(knowledgeBaseRepository, nodeRepository, ragRepository, ragService, kbRepo, logger, configConfig)
+	nodeAttachmentRepository := pg2.NewNodeAttachmentRepository(db)
+	minioClient, err := s3.NewMinioClient(configConfig)
 	if err != nil {
 		return nil, err
 	}
-	shareAuthMiddleware := middleware.NewShareAuthMiddleware(logger, knowledgeBaseUsecase)
-	baseHandler := handler.NewBaseHandler(echo, logger, configConfig, shareAuthMiddleware)
-	userRepository := pg2.NewUserRepository(db, logger)
-	userUsecase, err := usecase.NewUserUsecase(userRepository, logger, configConfig)
+	objectStorage, err := s3.NewAttachmentStorage(minioClient)
 	if err != nil {
 		return nil, err
 	}
-	userAccessRepository := pg2.NewUserAccessRepository(db, logger)
-	authMiddleware, err := middleware.NewAuthMiddleware(configConfig, logger, userAccessRepository)
+	nodeAttachmentUsecase := usecase.NewNodeAttachmentUsecase(nodeAttachmentRepository, nodeRepository, objectStorage, configConfig, logger)
+	appRepository := pg2.NewAppRepository(db, logger)
+	botProfileUsecase := usecase.NewBotProfileUsecase(appRepository, knowledgeBaseRepository, minioClient, logger)
+	modelRepository := pg2.NewModelRepository(db, logger)
+	knowledgeBaseUsecase, err := usecase.NewKnowledgeBaseUsecase(knowledgeBaseRepository, nodeRepository, ragRepository, nodeReviewRepository, modelRepository, ragService, kbRepo, nodeAttachmentUsecase, botProfileUsecase, logger, configConfig)
 	if err != nil {
 		return nil, err
 	}
-	userHandler := v1.NewUserHandler(echo, baseHandler, logger, userUsecase, authMiddleware, configConfig)
+	readerRepository := pg2.NewReaderRepository(db)
+	rateLimitRepo := cache2.NewRateLimitCache(cacheCache, logger)
+	botDetector := usecase.NewBotDetector(rateLimitRepo, logger)
+	userRepository := pg2.NewUserRepository(db, logger)
+	kbMemberRepository := pg2.NewKBMemberRepository(db)
+	ldapUsecase := usecase.NewLDAPUsecase(configConfig, userRepository, kbMemberRepository, readerRepository, logger)
+	readerUsecase := usecase.NewReaderUsecase(readerRepository, botDetector, ldapUsecase, configConfig, logger)
+	shareAuthMiddleware := middleware.NewShareAuthMiddleware(logger, knowledgeBaseUsecase, readerUsecase)
+	baseHandler := handler.NewBaseHandler(echo, logger, configConfig, shareAuthMiddleware)
+	kbMemberUsecase := usecase.NewKBMemberUsecase(kbMemberRepository, userRepository, logger)
+	twoFactorRepository := pg2.NewTwoFactorRepository(db)
+	twoFactorChallengeRepo := cache2.NewTwoFactorChallengeCache(cacheCache, logger)
+	twoFactorUsecase := usecase.NewTwoFactorUsecase(configConfig, twoFactorRepository, twoFactorChallengeRepo, userRepository, kbMemberRepository, logger)
+	sessionRepository := pg2.NewSessionRepository(db, logger)
+	sessionRepo := cache2.NewSessionCache(cacheCache, logger)
+	sessionUsecase := usecase.NewSessionUsecase(sessionRepository, sessionRepo, kbMemberUsecase, logger)
+	userAccessRepository := pg2.NewUserAccessRepository(db, logger)
 	conversationRepository := pg2.NewConversationRepository(db)
-	modelRepository := pg2.NewModelRepository(db, logger)
-	llmUsecase := usecase.NewLLMUsecase(configConfig, ragService, conversationRepository, knowledgeBaseRepository, nodeRepository, modelRepository, logger)
-	knowledgeBaseHandler := v1.NewKnowledgeBaseHandler(baseHandler, echo, knowledgeBaseUsecase, llmUsecase, authMiddleware, logger)
-	minioClient, err := s3.NewMinioClient(configConfig)
+	retrievalRepo := cache2.NewRetrievalCache(cacheCache, logger)
+	glossaryRepository := pg2.NewGlossaryRepository(db)
+	llmUsecase := usecase.NewLLMUsecase(configConfig, ragService, conversationRepository, knowledgeBaseRepository, nodeRepository, modelRepository, retrievalRepo, glossaryRepository, logger)
+	nodeLinkRepository := pg2.NewNodeLinkRepository(db)
+	answerCacheRepository := pg2.NewAnswerCacheRepository(db)
+	nodeUsecase := usecase.NewNodeUsecase(nodeRepository, ragRepository, knowledgeBaseRepository, llmUsecase, logger, minioClient, modelRepository, nodeAttachmentUsecase, nodeLinkRepository, answerCacheRepository)
+	apiTokenRepository := pg2.NewAPITokenRepository(db)
+	sandboxUsecase := usecase.NewSandboxUsecase(knowledgeBaseRepository, nodeRepository, ragService, knowledgeBaseUsecase, nodeUsecase, llmUsecase, modelRepository, logger)
+	apiTokenUsecase := usecase.NewAPITokenUsecase(apiTokenRepository, userRepository, knowledgeBaseRepository, sandboxUsecase, logger)
+	userUsecase, err := usecase.NewUserUsecase(userRepository, kbMemberUsecase, ldapUsecase, twoFactorUsecase, sessionUsecase, apiTokenUsecase, logger, configConfig)
 	if err != nil {
 		return nil, err
 	}
-	nodeUsecase := usecase.NewNodeUsecase(nodeRepository, ragRepository, knowledgeBaseRepository, llmUsecase, logger, minioClient, modelRepository)
-	nodeHandler := v1.NewNodeHandler(baseHandler, echo, nodeUsecase, authMiddleware, logger)
-	appRepository := pg2.NewAppRepository(db, logger)
+	authMiddleware, err := middleware.NewAuthMiddleware(configConfig, logger, userAccessRepository, kbMemberUsecase, apiTokenUsecase, sessionUsecase)
+	if err != nil {
+		return nil, err
+	}
+	userHandler := v1.NewUserHandler(echo, baseHandler, logger, userUsecase, authMiddleware, configConfig)
+	knowledgeBaseHandler := v1.NewKnowledgeBaseHandler(baseHandler, echo, knowledgeBaseUsecase, llmUsecase, kbMemberUsecase, authMiddleware, logger)
+	nodeHandler := v1.NewNodeHandler(baseHandler, echo, nodeUsecase, knowledgeBaseUsecase, authMiddleware, logger)
+	statRepository := pg2.NewStatRepository(db)
 	geoRepo := cache2.NewGeoCache(cacheCache, logger)
+	statEventRepository := mq2.NewStatEventRepository(mqProducer, configConfig)
+	webhookRepository := mq2.NewWebhookRepository(mqProducer)
 	ipdbIPDB, err := ipdb.NewIPDB(configConfig, logger)
 	if err != nil {
 		return nil, err
 	}
 	ipAddressRepo := ipdb2.NewIPAddressRepo(ipdbIPDB, logger)
-	conversationUsecase := usecase.NewConversationUsecase(conversationRepository, nodeRepository, geoRepo, logger, ipAddressRepo)
+	conversationUsecase := usecase.NewConversationUsecase(conversationRepository, nodeRepository, statRepository, geoRepo, statEventRepository, webhookRepository, knowledgeBaseUsecase, logger, ipAddressRepo)
 	modelUsecase := usecase.NewModelUsecase(modelRepository, nodeRepository, ragRepository, ragService, logger, configConfig, knowledgeBaseRepository)
-	chatUsecase := usecase.NewChatUsecase(llmUsecase, conversationUsecase, modelUsecase, appRepository, logger)
-	appUsecase := usecase.NewAppUsecase(appRepository, nodeUsecase, logger, configConfig, chatUsecase)
+	anomalyRepository := pg2.NewAnomalyRepository(db)
+	anomalyRepo := cache2.NewAnomalyCache(cacheCache, logger)
+	anomalyUsecase := usecase.NewAnomalyUsecase(anomalyRepository, anomalyRepo, webhookRepository, ipAddressRepo, logger)
+	nodeACLRepository := pg2.NewNodeACLRepository(db)
+	nodeACLUsecase := usecase.NewNodeACLUsecase(nodeACLRepository, knowledgeBaseRepository, logger)
+	promptTemplateRepository := pg2.NewPromptTemplateRepository(db)
+	answerCacheUsecase := usecase.NewAnswerCacheUsecase(answerCacheRepository, modelRepository, llmUsecase, logger)
+	guardrailRepository := pg2.NewGuardrailRepository(db)
+	guardrailUsecase := usecase.NewGuardrailUsecase(guardrailRepository, logger)
+	chatUsecase := usecase.NewChatUsecase(llmUsecase, conversationUsecase, modelUsecase, appRepository, statRepository, knowledgeBaseRepository, botDetector, anomalyUsecase, ipAddressRepo, nodeACLUsecase, promptTemplateRepository, answerCacheUsecase, guardrailUsecase, logger)
+	appUsecase := usecase.NewAppUsecase(appRepository, nodeUsecase, logger, configConfig, chatUsecase, nodeACLUsecase)
 	appHandler := v1.NewAppHandler(echo, baseHandler, logger, authMiddleware, appUsecase, modelUsecase, conversationUsecase, configConfig)
 	fileUsecase := usecase.NewFileUsecase(logger, minioClient, configConfig)
 	fileHandler := v1.NewFileHandler(echo, baseHandler, logger, authMiddleware, minioClient, configConfig, fileUsecase)
-	modelHandler := v1.NewModelHandler(echo, baseHandler, logger, authMiddleware, modelUsecase, llmUsecase)
-	conversationHandler := v1.NewConversationHandler(echo, baseHandler, logger, authMiddleware, conversationUsecase)
+	modelCompareUsecase := usecase.NewModelCompareUsecase(llmUsecase, modelRepository, logger)
+	modelHandler := v1.NewModelHandler(echo, baseHandler, logger, authMiddleware, modelUsecase, llmUsecase, modelCompareUsecase)
+	mailer := mail.NewMailer(configConfig)
+	transcriptEmailUsecase := usecase.NewTranscriptEmailUsecase(conversationRepository, knowledgeBaseRepository, rateLimitRepo, mailer, logger)
+	conversationImportUsecase := usecase.NewConversationImportUsecase(conversationRepository, configConfig, logger)
+	conversationHandler := v1.NewConversationHandler(echo, baseHandler, logger, authMiddleware, conversationUsecase, transcriptEmailUsecase, conversationImportUsecase)
 	crawlerUsecase, err := usecase.NewCrawlerUsecase(logger)
 	if err != nil {
 		return nil, err
@@ -113,33 +158,180 @@ func createApp() (*App, error) {
 	crawlerHandler := v1.NewCrawlerHandler(echo, baseHandler, authMiddleware, logger, configConfig, crawlerUsecase, notionUseCase, epubUsecase, wikiJSUsecase, feishuUseCase)
 	creationUsecase := usecase.NewCreationUsecase(logger, llmUsecase, modelUsecase)
 	creationHandler := v1.NewCreationHandler(echo, baseHandler, logger, creationUsecase)
-	statRepository := pg2.NewStatRepository(db)
-	statUseCase := usecase.NewStatUseCase(statRepository, nodeRepository, conversationRepository, appRepository, ipAddressRepo, geoRepo, logger)
-	statHandler := v1.NewStatHandler(baseHandler, echo, statUseCase, logger)
+	visitorRepo := cache2.NewVisitorCache(cacheCache, logger)
+	statUseCase := usecase.NewStatUseCase(statRepository, nodeRepository, conversationRepository, appRepository, ipAddressRepo, geoRepo, visitorRepo, statEventRepository, knowledgeBaseUsecase, botDetector, logger)
+	questionClusterUsecase := usecase.NewQuestionClusterUsecase(statRepository, modelRepository, llmUsecase, logger)
+	statHandler := v1.NewStatHandler(baseHandler, echo, statUseCase, questionClusterUsecase, authMiddleware, logger)
+	onboardingUsecase := usecase.NewOnboardingUsecase(knowledgeBaseUsecase, nodeUsecase, appUsecase, appRepository, knowledgeBaseRepository, nodeRepository, modelRepository, logger)
+	onboardingHandler := v1.NewOnboardingHandler(baseHandler, echo, onboardingUsecase, authMiddleware, logger)
+	gapReportUsecase := usecase.NewGapReportUsecase(knowledgeBaseRepository, appRepository, conversationRepository, nodeRepository, logger)
+	gapReportHandler := v1.NewGapReportHandler(baseHandler, echo, gapReportUsecase, authMiddleware, logger)
+	cronRepository := pg2.NewCronRepository(db)
+	cronUsecase := usecase.NewCronUsecase(cronRepository, configConfig, logger)
+	cronHandler := v1.NewCronHandler(baseHandler, echo, cronUsecase, authMiddleware, logger)
+	nodeReplaceRepository := pg2.NewNodeReplaceRepository(db)
+	mqNodeReplaceRepository := mq2.NewNodeReplaceRepository(mqProducer)
+	nodeReplaceUsecase := usecase.NewNodeReplaceUsecase(nodeReplaceRepository, mqNodeReplaceRepository, logger)
+	nodeReplaceHandler := v1.NewNodeReplaceHandler(baseHandler, echo, nodeReplaceUsecase, authMiddleware, logger)
+	settingRepository := pg2.NewSettingRepository(db)
+	maintenanceUsecase := usecase.NewMaintenanceUsecase(settingRepository, knowledgeBaseUsecase, logger)
+	readOnlyMiddleware := middleware.NewReadOnlyMiddleware(logger, maintenanceUsecase, kbMemberUsecase)
+	maintenanceHandler := v1.NewMaintenanceHandler(baseHandler, echo, maintenanceUsecase, authMiddleware, readOnlyMiddleware, logger)
+	nodeReviewUsecase := usecase.NewNodeReviewUsecase(nodeReviewRepository, nodeRepository, knowledgeBaseUsecase, logger)
+	nodeReviewHandler := v1.NewNodeReviewHandler(baseHandler, echo, nodeReviewUsecase, authMiddleware, logger)
+	pgWebhookRepository := pg2.NewWebhookRepository(db)
+	webhookUsecase := usecase.NewWebhookUsecase(pgWebhookRepository, logger)
+	webhookHandler := v1.NewWebhookHandler(baseHandler, echo, webhookUsecase, authMiddleware, logger)
+	digestUsecase := usecase.NewDigestUsecase(knowledgeBaseRepository, conversationRepository, nodeUsecase, logger)
+	digestHandler := v1.NewDigestHandler(baseHandler, echo, digestUsecase, authMiddleware, logger)
+	nodeTemplateRepository := pg2.NewNodeTemplateRepository(db)
+	nodeTemplateUsecase := usecase.NewNodeTemplateUsecase(nodeTemplateRepository, nodeUsecase, logger)
+	nodeTemplateHandler := v1.NewNodeTemplateHandler(baseHandler, echo, nodeTemplateUsecase, authMiddleware, logger)
+	nodeAttachmentHandler := v1.NewNodeAttachmentHandler(baseHandler, echo, nodeAttachmentUsecase, authMiddleware, logger)
+	externalLinkRepository := pg2.NewExternalLinkRepository(db)
+	externalLinkUsecase := usecase.NewExternalLinkUsecase(externalLinkRepository, knowledgeBaseRepository, logger)
+	externalLinkHandler := v1.NewExternalLinkHandler(baseHandler, echo, externalLinkUsecase, authMiddleware, logger)
+	nodeCommentRepository := pg2.NewNodeCommentRepository(db)
+	nodeCommentUsecase := usecase.NewNodeCommentUsecase(nodeCommentRepository, nodeRepository, knowledgeBaseRepository, userRepository, botDetector, logger)
+	nodeCommentHandler := v1.NewNodeCommentHandler(baseHandler, echo, nodeCommentUsecase, authMiddleware, logger)
+	warmupUsecase := usecase.NewWarmupUsecase(configConfig, knowledgeBaseUsecase, llmUsecase, modelRepository, conversationRepository, ragService, logger)
+	healthHandler := v1.NewHealthHandler(baseHandler, echo, warmupUsecase, logger)
+	indexIntegrityUsecase := usecase.NewIndexIntegrityUsecase(knowledgeBaseRepository, nodeRepository, ragRepository, ragService, configConfig, logger)
+	indexIntegrityHandler := v1.NewIndexIntegrityHandler(baseHandler, echo, indexIntegrityUsecase, authMiddleware, logger)
+	nodeExportRepository := pg2.NewNodeExportRepository(db)
+	mqNodeExportRepository := mq2.NewNodeExportRepository(mqProducer)
+	nodeExportUsecase := usecase.NewNodeExportUsecase(nodeExportRepository, nodeRepository, nodeAttachmentRepository, mqNodeExportRepository, objectStorage, logger)
+	nodeExportHandler := v1.NewNodeExportHandler(baseHandler, echo, nodeExportUsecase, authMiddleware, logger)
+	botProfileHandler := v1.NewBotProfileHandler(baseHandler, echo, botProfileUsecase, authMiddleware, logger)
+	nodeImportUsecase := usecase.NewNodeImportUsecase(nodeUsecase, knowledgeBaseUsecase, nodeAttachmentUsecase, modelRepository, minioClient, configConfig, logger)
+	nodeImportHandler := v1.NewNodeImportHandler(baseHandler, echo, nodeImportUsecase, authMiddleware, logger)
+	importSourceRepository := pg2.NewImportSourceRepository(db)
+	importSourceUsecase := usecase.NewImportSourceUsecase(importSourceRepository, nodeUsecase, knowledgeBaseUsecase, nodeAttachmentUsecase, minioClient, configConfig, logger)
+	importSourceHandler := v1.NewImportSourceHandler(baseHandler, echo, importSourceUsecase, authMiddleware, logger)
+	anomalyHandler := v1.NewAnomalyHandler(baseHandler, echo, anomalyUsecase, authMiddleware, logger)
+	nearDuplicateRepository := pg2.NewNearDuplicateRepository(db)
+	nearDuplicateUsecase := usecase.NewNearDuplicateUsecase(nearDuplicateRepository, knowledgeBaseRepository, logger)
+	nearDuplicateHandler := v1.NewNearDuplicateHandler(baseHandler, echo, nearDuplicateUsecase, authMiddleware, logger)
+	retentionRepository := pg2.NewRetentionRepository(db)
+	retentionUsecase := usecase.NewRetentionUsecase(settingRepository, retentionRepository, logger)
+	retentionHandler := v1.NewRetentionHandler(baseHandler, echo, retentionUsecase, authMiddleware, logger)
+	nodeOwnerRepository := pg2.NewNodeOwnerRepository(db)
+	nodeOwnerUsecase := usecase.NewNodeOwnerUsecase(nodeOwnerRepository, knowledgeBaseRepository, userRepository, logger)
+	nodeOwnerHandler := v1.NewNodeOwnerHandler(baseHandler, echo, nodeOwnerUsecase, authMiddleware, logger)
+	nodeACLHandler := v1.NewNodeACLHandler(baseHandler, echo, nodeACLUsecase, authMiddleware, logger)
+	readerHandler := v1.NewReaderHandler(baseHandler, echo, readerUsecase, authMiddleware, logger)
+	dataExportRepository := pg2.NewDataExportRepository(db)
+	mqDataExportRepository := mq2.NewDataExportRepository(mqProducer)
+	dataExportUsecase := usecase.NewDataExportUsecase(dataExportRepository, mqDataExportRepository, objectStorage, logger)
+	dataExportHandler := v1.NewDataExportHandler(baseHandler, echo, dataExportUsecase, authMiddleware, logger)
+	glossaryUsecase := usecase.NewGlossaryUsecase(glossaryRepository, logger)
+	glossaryHandler := v1.NewGlossaryHandler(baseHandler, echo, glossaryUsecase, authMiddleware, logger)
+	telemetryRepository := pg2.NewTelemetryRepository(db)
+	telemetryUsecase := usecase.NewTelemetryUsecase(settingRepository, telemetryRepository, configConfig, logger)
+	telemetryMiddleware := middleware.NewTelemetryMiddleware(logger, telemetryUsecase)
+	telemetryHandler := v1.NewTelemetryHandler(baseHandler, echo, telemetryUsecase, authMiddleware, telemetryMiddleware, logger)
+	kbMemberHandler := v1.NewKBMemberHandler(baseHandler, echo, kbMemberUsecase, authMiddleware, logger)
+	conversationRescoreRepository := pg2.NewConversationRescoreRepository(db)
+	mqConversationRescoreRepository := mq2.NewConversationRescoreRepository(mqProducer)
+	conversationRescoreUsecase := usecase.NewConversationRescoreUsecase(conversationRescoreRepository, mqConversationRescoreRepository, knowledgeBaseRepository, llmUsecase, logger)
+	conversationRescoreHandler := v1.NewConversationRescoreHandler(baseHandler, echo, conversationRescoreUsecase, authMiddleware, logger)
+	oidcStateRepo := cache2.NewOIDCStateCache(cacheCache, logger)
+	oidcUsecase := usecase.NewOIDCUsecase(configConfig, userRepository, kbMemberRepository, oidcStateRepo, userUsecase, logger)
+	oidcHandler := v1.NewOIDCHandler(echo, baseHandler, logger, oidcUsecase)
+	twoFactorHandler := v1.NewTwoFactorHandler(echo, baseHandler, logger, twoFactorUsecase, userUsecase, authMiddleware)
+	apiTokenHandler := v1.NewAPITokenHandler(baseHandler, echo, apiTokenUsecase, authMiddleware, logger)
+	apiTokenMiddleware := middleware.NewAPITokenMiddleware(logger, apiTokenUsecase)
+	sandboxHandler := v1.NewSandboxHandler(baseHandler, echo, sandboxUsecase, apiTokenMiddleware, logger)
+	auditLogRepository := pg2.NewAuditLogRepository(db)
+	auditUsecase := usecase.NewAuditUsecase(auditLogRepository, userRepository, settingRepository, logger)
+	auditMiddleware := middleware.NewAuditMiddleware(logger, authMiddleware, auditUsecase)
+	auditHandler := v1.NewAuditHandler(baseHandler, echo, auditUsecase, authMiddleware, auditMiddleware, logger)
+	sessionHandler := v1.NewSessionHandler(baseHandler, echo, sessionUsecase, authMiddleware, logger)
+	ipRuleUsecase := usecase.NewIPRuleUsecase(settingRepository, configConfig, logger)
+	ipRuleMiddleware := middleware.NewIPRuleMiddleware(logger, ipRuleUsecase)
+	ipRuleHandler := v1.NewIPRuleHandler(baseHandler, echo, ipRuleUsecase, authMiddleware, ipRuleMiddleware, logger)
+	promptTemplateUsecase := usecase.NewPromptTemplateUsecase(promptTemplateRepository, appRepository, knowledgeBaseRepository, llmUsecase, modelUsecase, logger)
+	promptTemplateHandler := v1.NewPromptTemplateHandler(baseHandler, echo, promptTemplateUsecase, authMiddleware, logger)
+	messageDebugUsecase := usecase.NewMessageDebugUsecase(conversationRepository, knowledgeBaseRepository, appRepository, llmUsecase, chatUsecase, logger)
+	messageDebugHandler := v1.NewMessageDebugHandler(baseHandler, echo, messageDebugUsecase, authMiddleware, logger)
+	evalRepository := pg2.NewEvalRepository(db)
+	mqEvalRepository := mq2.NewEvalRepository(mqProducer)
+	evalUsecase := usecase.NewEvalUsecase(evalRepository, mqEvalRepository, knowledgeBaseRepository, appRepository, promptTemplateRepository, modelRepository, llmUsecase, logger)
+	evalHandler := v1.NewEvalHandler(baseHandler, echo, evalUsecase, authMiddleware, logger)
+	guardrailHandler := v1.NewGuardrailHandler(baseHandler, echo, guardrailUsecase, authMiddleware, logger)
 	apiHandlers := &v1.APIHandlers{
-		UserHandler:          userHandler,
-		KnowledgeBaseHandler: knowledgeBaseHandler,
-		NodeHandler:          nodeHandler,
-		AppHandler:           appHandler,
-		FileHandler:          fileHandler,
-		ModelHandler:         modelHandler,
-		ConversationHandler:  conversationHandler,
-		CrawlerHandler:       crawlerHandler,
-		CreationHandler:      creationHandler,
-		StatHandler:          statHandler,
-	}
-	shareNodeHandler := share.NewShareNodeHandler(baseHandler, echo, nodeUsecase, logger)
+		UserHandler:                userHandler,
+		KnowledgeBaseHandler:       knowledgeBaseHandler,
+		NodeHandler:                nodeHandler,
+		AppHandler:                 appHandler,
+		FileHandler:                fileHandler,
+		ModelHandler:               modelHandler,
+		ConversationHandler:        conversationHandler,
+		CrawlerHandler:             crawlerHandler,
+		CreationHandler:            creationHandler,
+		StatHandler:                statHandler,
+		OnboardingHandler:          onboardingHandler,
+		GapReportHandler:           gapReportHandler,
+		CronHandler:                cronHandler,
+		NodeReplaceHandler:         nodeReplaceHandler,
+		MaintenanceHandler:         maintenanceHandler,
+		NodeReviewHandler:          nodeReviewHandler,
+		WebhookHandler:             webhookHandler,
+		DigestHandler:              digestHandler,
+		NodeTemplateHandler:        nodeTemplateHandler,
+		NodeAttachmentHandler:      nodeAttachmentHandler,
+		ExternalLinkHandler:        externalLinkHandler,
+		NodeCommentHandler:         nodeCommentHandler,
+		HealthHandler:              healthHandler,
+		IndexIntegrityHandler:      indexIntegrityHandler,
+		NodeExportHandler:          nodeExportHandler,
+		BotProfileHandler:          botProfileHandler,
+		NodeImportHandler:          nodeImportHandler,
+		ImportSourceHandler:        importSourceHandler,
+		AnomalyHandler:             anomalyHandler,
+		NearDuplicateHandler:       nearDuplicateHandler,
+		RetentionHandler:           retentionHandler,
+		NodeOwnerHandler:           nodeOwnerHandler,
+		NodeACLHandler:             nodeACLHandler,
+		ReaderHandler:              readerHandler,
+		DataExportHandler:          dataExportHandler,
+		GlossaryHandler:            glossaryHandler,
+		TelemetryHandler:           telemetryHandler,
+		KBMemberHandler:            kbMemberHandler,
+		ConversationRescoreHandler: conversationRescoreHandler,
+		OIDCHandler:                oidcHandler,
+		TwoFactorHandler:           twoFactorHandler,
+		APITokenHandler:            apiTokenHandler,
+		SandboxHandler:             sandboxHandler,
+		AuditHandler:               auditHandler,
+		SessionHandler:             sessionHandler,
+		IPRuleHandler:              ipRuleHandler,
+		PromptTemplateHandler:      promptTemplateHandler,
+		MessageDebugHandler:        messageDebugHandler,
+		EvalHandler:                evalHandler,
+		GuardrailHandler:           guardrailHandler,
+	}
+	shareNodeHandler := share.NewShareNodeHandler(baseHandler, echo, nodeUsecase, nodeAttachmentUsecase, glossaryUsecase, nodeACLUsecase, logger)
 	shareAppHandler := share.NewShareAppHandler(echo, baseHandler, logger, appUsecase)
-	shareChatHandler := share.NewShareChatHandler(echo, baseHandler, logger, appUsecase, chatUsecase, conversationUsecase, modelUsecase)
-	sitemapUsecase := usecase.NewSitemapUsecase(nodeRepository, knowledgeBaseRepository, logger)
+	shareChatHandler := share.NewShareChatHandler(echo, baseHandler, logger, appUsecase, chatUsecase, conversationUsecase, modelUsecase, transcriptEmailUsecase)
+	sitemapUsecase := usecase.NewSitemapUsecase(nodeRepository, knowledgeBaseRepository, nodeACLUsecase, logger)
 	shareSitemapHandler := share.NewShareSitemapHandler(echo, baseHandler, sitemapUsecase, appUsecase, logger)
 	shareStatHandler := share.NewShareStatHandler(baseHandler, echo, statUseCase)
+	searchUsecase := usecase.NewSearchUsecase(nodeRepository, knowledgeBaseRepository, appRepository, statRepository, nodeACLUsecase, logger)
+	shareSearchHandler := share.NewShareSearchHandler(echo, baseHandler, searchUsecase, logger)
+	shareCommentHandler := share.NewShareCommentHandler(echo, baseHandler, nodeCommentUsecase, nodeACLUsecase, logger)
+	shareImportSourceHandler := share.NewShareImportSourceHandler(echo, baseHandler, logger, importSourceUsecase)
+	shareReaderHandler := share.NewShareReaderHandler(echo, baseHandler, logger, readerUsecase)
 	shareHandler := &share.ShareHandler{
-		ShareNodeHandler:    shareNodeHandler,
-		ShareAppHandler:     shareAppHandler,
-		ShareChatHandler:    shareChatHandler,
-		ShareSitemapHandler: shareSitemapHandler,
-		ShareStatHandler:    shareStatHandler,
+		ShareNodeHandler:         shareNodeHandler,
+		ShareAppHandler:          shareAppHandler,
+		ShareChatHandler:         shareChatHandler,
+		ShareSitemapHandler:      shareSitemapHandler,
+		ShareStatHandler:         shareStatHandler,
+		ShareSearchHandler:       shareSearchHandler,
+		ShareCommentHandler:      shareCommentHandler,
+		ShareImportSourceHandler: shareImportSourceHandler,
+		ShareReaderHandler:       shareReaderHandler,
 	}
 	app := &App{
 		HTTPServer:    httpServer,
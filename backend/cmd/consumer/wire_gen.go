@@ -11,9 +11,14 @@ import (
 	mq2 "github.com/chaitin/panda-wiki/handler/mq"
 	"github.com/chaitin/panda-wiki/log"
 	"github.com/chaitin/panda-wiki/mq"
+	cache2 "github.com/chaitin/panda-wiki/repo/cache"
+	mq3 "github.com/chaitin/panda-wiki/repo/mq"
 	pg2 "github.com/chaitin/panda-wiki/repo/pg"
+	"github.com/chaitin/panda-wiki/store/cache"
 	"github.com/chaitin/panda-wiki/store/pg"
 	"github.com/chaitin/panda-wiki/store/rag"
+	"github.com/chaitin/panda-wiki/store/s3"
+	"github.com/chaitin/panda-wiki/store/statsink"
 	"github.com/chaitin/panda-wiki/usecase"
 )
 
@@ -41,22 +46,157 @@ func createApp() (*App, error) {
 	knowledgeBaseRepository := pg2.NewKnowledgeBaseRepository(db, configConfig, logger, ragService)
 	conversationRepository := pg2.NewConversationRepository(db)
 	modelRepository := pg2.NewModelRepository(db, logger)
-	llmUsecase := usecase.NewLLMUsecase(configConfig, ragService, conversationRepository, knowledgeBaseRepository, nodeRepository, modelRepository, logger)
-	ragmqHandler, err := mq2.NewRAGMQHandler(mqConsumer, logger, ragService, nodeRepository, knowledgeBaseRepository, llmUsecase, modelRepository)
+	cacheCache, err := cache.NewCache(configConfig)
+	if err != nil {
+		return nil, err
+	}
+	retrievalRepo := cache2.NewRetrievalCache(cacheCache, logger)
+	glossaryRepository := pg2.NewGlossaryRepository(db)
+	llmUsecase := usecase.NewLLMUsecase(configConfig, ragService, conversationRepository, knowledgeBaseRepository, nodeRepository, modelRepository, retrievalRepo, glossaryRepository, logger)
+	answerCacheRepository := pg2.NewAnswerCacheRepository(db)
+	mqProducer, err := mq.NewMQProducer(configConfig, logger)
+	if err != nil {
+		return nil, err
+	}
+	ragRepository := mq3.NewRAGRepository(mqProducer)
+	kbRepo := cache2.NewKBRepo(cacheCache)
+	ragmqHandler, err := mq2.NewRAGMQHandler(mqConsumer, logger, ragService, nodeRepository, knowledgeBaseRepository, llmUsecase, modelRepository, answerCacheRepository, ragRepository, kbRepo)
 	if err != nil {
 		return nil, err
 	}
 	statRepository := pg2.NewStatRepository(db)
-	statCronHandler := mq2.NewStatCronHandler(logger, statRepository)
+	cronRepository := pg2.NewCronRepository(db)
+	cronUsecase := usecase.NewCronUsecase(cronRepository, configConfig, logger)
+	settingRepository := pg2.NewSettingRepository(db)
+	retentionRepository := pg2.NewRetentionRepository(db)
+	retentionUsecase := usecase.NewRetentionUsecase(settingRepository, retentionRepository, logger)
+	retentionCronHandler := mq2.NewRetentionCronHandler(logger, retentionUsecase, cronUsecase)
+	questionClusterUsecase := usecase.NewQuestionClusterUsecase(statRepository, modelRepository, llmUsecase, logger)
+	questionClusterCronHandler := mq2.NewQuestionClusterCronHandler(logger, questionClusterUsecase, cronUsecase)
+	appRepository := pg2.NewAppRepository(db, logger)
+	gapReportUsecase := usecase.NewGapReportUsecase(knowledgeBaseRepository, appRepository, conversationRepository, nodeRepository, logger)
+	gapReportCronHandler := mq2.NewGapReportCronHandler(logger, gapReportUsecase, cronUsecase)
+	sink, err := statsink.NewSink(configConfig)
+	if err != nil {
+		return nil, err
+	}
+	statSinkMQHandler, err := mq2.NewStatSinkMQHandler(mqConsumer, configConfig, sink, logger)
+	if err != nil {
+		return nil, err
+	}
+	nodeReplaceRepository := pg2.NewNodeReplaceRepository(db)
+	mqNodeReplaceRepository := mq3.NewNodeReplaceRepository(mqProducer)
+	nodeReplaceUsecase := usecase.NewNodeReplaceUsecase(nodeReplaceRepository, mqNodeReplaceRepository, logger)
+	nodeReplaceMQHandler, err := mq2.NewNodeReplaceMQHandler(mqConsumer, logger, nodeReplaceUsecase)
+	if err != nil {
+		return nil, err
+	}
+	webhookRepository := pg2.NewWebhookRepository(db)
+	webhookUsecase := usecase.NewWebhookUsecase(webhookRepository, logger)
+	webhookMQHandler, err := mq2.NewWebhookMQHandler(mqConsumer, logger, webhookUsecase)
+	if err != nil {
+		return nil, err
+	}
+	externalLinkRepository := pg2.NewExternalLinkRepository(db)
+	externalLinkUsecase := usecase.NewExternalLinkUsecase(externalLinkRepository, knowledgeBaseRepository, logger)
+	externalLinkCronHandler := mq2.NewExternalLinkCronHandler(logger, externalLinkUsecase, cronUsecase)
+	indexIntegrityUsecase := usecase.NewIndexIntegrityUsecase(knowledgeBaseRepository, nodeRepository, ragRepository, ragService, configConfig, logger)
+	indexIntegrityCronHandler := mq2.NewIndexIntegrityCronHandler(logger, indexIntegrityUsecase, cronUsecase)
+	nodeExportRepository := pg2.NewNodeExportRepository(db)
+	nodeAttachmentRepository := pg2.NewNodeAttachmentRepository(db)
+	mqNodeExportRepository := mq3.NewNodeExportRepository(mqProducer)
+	minioClient, err := s3.NewMinioClient(configConfig)
+	if err != nil {
+		return nil, err
+	}
+	objectStorage, err := s3.NewAttachmentStorage(minioClient)
+	if err != nil {
+		return nil, err
+	}
+	nodeExportUsecase := usecase.NewNodeExportUsecase(nodeExportRepository, nodeRepository, nodeAttachmentRepository, mqNodeExportRepository, objectStorage, logger)
+	nodeExportMQHandler, err := mq2.NewNodeExportMQHandler(mqConsumer, logger, nodeExportUsecase)
+	if err != nil {
+		return nil, err
+	}
+	importSourceRepository := pg2.NewImportSourceRepository(db)
+	nodeAttachmentUsecase := usecase.NewNodeAttachmentUsecase(nodeAttachmentRepository, nodeRepository, objectStorage, configConfig, logger)
+	nodeLinkRepository := pg2.NewNodeLinkRepository(db)
+	nodeUsecase := usecase.NewNodeUsecase(nodeRepository, ragRepository, knowledgeBaseRepository, llmUsecase, logger, minioClient, modelRepository, nodeAttachmentUsecase, nodeLinkRepository, answerCacheRepository)
+	digestUsecase := usecase.NewDigestUsecase(knowledgeBaseRepository, conversationRepository, nodeUsecase, logger)
+	digestCronHandler := mq2.NewDigestCronHandler(logger, digestUsecase, cronUsecase)
+	nodeReviewRepository := pg2.NewNodeReviewRepository(db)
+	botProfileUsecase := usecase.NewBotProfileUsecase(appRepository, knowledgeBaseRepository, minioClient, logger)
+	knowledgeBaseUsecase, err := usecase.NewKnowledgeBaseUsecase(knowledgeBaseRepository, nodeRepository, ragRepository, nodeReviewRepository, modelRepository, ragService, kbRepo, nodeAttachmentUsecase, botProfileUsecase, logger, configConfig)
+	if err != nil {
+		return nil, err
+	}
+	importSourceUsecase := usecase.NewImportSourceUsecase(importSourceRepository, nodeUsecase, knowledgeBaseUsecase, nodeAttachmentUsecase, minioClient, configConfig, logger)
+	importSourceCronHandler := mq2.NewImportSourceCronHandler(logger, importSourceUsecase, cronUsecase)
+	nearDuplicateRepository := pg2.NewNearDuplicateRepository(db)
+	nearDuplicateUsecase := usecase.NewNearDuplicateUsecase(nearDuplicateRepository, knowledgeBaseRepository, logger)
+	nearDuplicateCronHandler := mq2.NewNearDuplicateCronHandler(logger, nearDuplicateUsecase, cronUsecase)
+	nodeOwnerRepository := pg2.NewNodeOwnerRepository(db)
+	userRepository := pg2.NewUserRepository(db, logger)
+	nodeOwnerUsecase := usecase.NewNodeOwnerUsecase(nodeOwnerRepository, knowledgeBaseRepository, userRepository, logger)
+	reviewReminderCronHandler := mq2.NewReviewReminderCronHandler(logger, nodeOwnerUsecase, cronUsecase)
+	dataExportRepository := pg2.NewDataExportRepository(db)
+	mqDataExportRepository := mq3.NewDataExportRepository(mqProducer)
+	dataExportUsecase := usecase.NewDataExportUsecase(dataExportRepository, mqDataExportRepository, objectStorage, logger)
+	dataExportMQHandler, err := mq2.NewDataExportMQHandler(mqConsumer, logger, dataExportUsecase)
+	if err != nil {
+		return nil, err
+	}
+	telemetryRepository := pg2.NewTelemetryRepository(db)
+	telemetryUsecase := usecase.NewTelemetryUsecase(settingRepository, telemetryRepository, configConfig, logger)
+	telemetryCronHandler := mq2.NewTelemetryCronHandler(logger, telemetryUsecase, cronUsecase)
+	conversationRescoreRepository := pg2.NewConversationRescoreRepository(db)
+	mqConversationRescoreRepository := mq3.NewConversationRescoreRepository(mqProducer)
+	conversationRescoreUsecase := usecase.NewConversationRescoreUsecase(conversationRescoreRepository, mqConversationRescoreRepository, knowledgeBaseRepository, llmUsecase, logger)
+	conversationRescoreMQHandler, err := mq2.NewConversationRescoreMQHandler(mqConsumer, logger, conversationRescoreUsecase)
+	if err != nil {
+		return nil, err
+	}
+	evalRepository := pg2.NewEvalRepository(db)
+	mqEvalRepository := mq3.NewEvalRepository(mqProducer)
+	promptTemplateRepository := pg2.NewPromptTemplateRepository(db)
+	evalUsecase := usecase.NewEvalUsecase(evalRepository, mqEvalRepository, knowledgeBaseRepository, appRepository, promptTemplateRepository, modelRepository, llmUsecase, logger)
+	evalMQHandler, err := mq2.NewEvalMQHandler(mqConsumer, logger, evalUsecase)
+	if err != nil {
+		return nil, err
+	}
+	sandboxUsecase := usecase.NewSandboxUsecase(knowledgeBaseRepository, nodeRepository, ragService, knowledgeBaseUsecase, nodeUsecase, llmUsecase, modelRepository, logger)
+	sandboxCronHandler := mq2.NewSandboxCronHandler(logger, sandboxUsecase, cronUsecase)
+	kbMemberRepository := pg2.NewKBMemberRepository(db)
+	readerRepository := pg2.NewReaderRepository(db)
+	ldapUsecase := usecase.NewLDAPUsecase(configConfig, userRepository, kbMemberRepository, readerRepository, logger)
+	ldapSyncCronHandler := mq2.NewLDAPSyncCronHandler(logger, ldapUsecase, cronUsecase)
 	mqHandlers := &mq2.MQHandlers{
-		RAGMQHandler:    ragmqHandler,
-		StatCronHandler: statCronHandler,
+		RAGMQHandler:                 ragmqHandler,
+		RetentionCronHandler:         retentionCronHandler,
+		QuestionClusterCronHandler:   questionClusterCronHandler,
+		GapReportCronHandler:         gapReportCronHandler,
+		StatSinkMQHandler:            statSinkMQHandler,
+		NodeReplaceMQHandler:         nodeReplaceMQHandler,
+		WebhookMQHandler:             webhookMQHandler,
+		DigestCronHandler:            digestCronHandler,
+		ExternalLinkCronHandler:      externalLinkCronHandler,
+		IndexIntegrityCronHandler:    indexIntegrityCronHandler,
+		NodeExportMQHandler:          nodeExportMQHandler,
+		ImportSourceCronHandler:      importSourceCronHandler,
+		NearDuplicateCronHandler:     nearDuplicateCronHandler,
+		ReviewReminderCronHandler:    reviewReminderCronHandler,
+		DataExportMQHandler:          dataExportMQHandler,
+		TelemetryCronHandler:         telemetryCronHandler,
+		ConversationRescoreMQHandler: conversationRescoreMQHandler,
+		EvalMQHandler:                evalMQHandler,
+		SandboxCronHandler:           sandboxCronHandler,
+		LDAPSyncCronHandler:          ldapSyncCronHandler,
 	}
 	app := &App{
-		MQConsumer:      mqConsumer,
-		Config:          configConfig,
-		MQHandlers:      mqHandlers,
-		StatCronHandler: statCronHandler,
+		MQConsumer:           mqConsumer,
+		Config:               configConfig,
+		MQHandlers:           mqHandlers,
+		RetentionCronHandler: retentionCronHandler,
 	}
 	return app, nil
 }
@@ -64,8 +204,8 @@ func createApp() (*App, error) {
 // wire.go:
 
 type App struct {
-	MQConsumer      mq.MQConsumer
-	Config          *config.Config
-	MQHandlers      *mq2.MQHandlers
-	StatCronHandler *mq2.StatCronHandler
+	MQConsumer           mq.MQConsumer
+	Config               *config.Config
+	MQHandlers           *mq2.MQHandlers
+	RetentionCronHandler *mq2.RetentionCronHandler
 }
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func main() {
+	app, err := createApp()
+	if err != nil {
+		panic(err)
+	}
+	port := app.Config.Embedding.Port
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		panic(err)
+	}
+	// finish batches in flight before exiting
+	go func() {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+		<-sig
+		app.GRPCServer.Server.GracefulStop()
+	}()
+	app.Logger.Info(fmt.Sprintf("Starting embedder on port %d", port))
+	if err := app.GRPCServer.Server.Serve(lis); err != nil {
+		panic(err)
+	}
+	app.GRPCServer.Batcher.Close()
+}
@@ -0,0 +1,30 @@
+//go:build wireinject
+// +build wireinject
+
+package main
+
+import (
+	"github.com/google/wire"
+
+	"github.com/chaitin/panda-wiki/config"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/server/grpc"
+)
+
+func createApp() (*App, error) {
+	wire.Build(
+		wire.Struct(new(App), "*"),
+		wire.NewSet(
+			config.ProviderSet,
+			log.ProviderSet,
+			grpc.ProviderSet,
+		),
+	)
+	return &App{}, nil
+}
+
+type App struct {
+	GRPCServer *grpc.GRPCServer
+	Config     *config.Config
+	Logger     *log.Logger
+}
@@ -47,18 +47,31 @@ func createApp() (*App, error) {
 	knowledgeBaseRepository := pg2.NewKnowledgeBaseRepository(db, configConfig, logger, ragService)
 	conversationRepository := pg2.NewConversationRepository(db)
 	modelRepository := pg2.NewModelRepository(db, logger)
-	llmUsecase := usecase.NewLLMUsecase(configConfig, ragService, conversationRepository, knowledgeBaseRepository, nodeRepository, modelRepository, logger)
+	cacheCache, err := cache.NewCache(configConfig)
+	if err != nil {
+		return nil, err
+	}
+	retrievalRepo := cache2.NewRetrievalCache(cacheCache, logger)
+	glossaryRepository := pg2.NewGlossaryRepository(db)
+	llmUsecase := usecase.NewLLMUsecase(configConfig, ragService, conversationRepository, knowledgeBaseRepository, nodeRepository, modelRepository, retrievalRepo, glossaryRepository, logger)
 	minioClient, err := s3.NewMinioClient(configConfig)
 	if err != nil {
 		return nil, err
 	}
-	nodeUsecase := usecase.NewNodeUsecase(nodeRepository, ragRepository, knowledgeBaseRepository, llmUsecase, logger, minioClient, modelRepository)
-	cacheCache, err := cache.NewCache(configConfig)
+	nodeAttachmentRepository := pg2.NewNodeAttachmentRepository(db)
+	objectStorage, err := s3.NewAttachmentStorage(minioClient)
 	if err != nil {
 		return nil, err
 	}
+	nodeAttachmentUsecase := usecase.NewNodeAttachmentUsecase(nodeAttachmentRepository, nodeRepository, objectStorage, configConfig, logger)
+	nodeLinkRepository := pg2.NewNodeLinkRepository(db)
+	answerCacheRepository := pg2.NewAnswerCacheRepository(db)
+	nodeUsecase := usecase.NewNodeUsecase(nodeRepository, ragRepository, knowledgeBaseRepository, llmUsecase, logger, minioClient, modelRepository, nodeAttachmentUsecase, nodeLinkRepository, answerCacheRepository)
+	nodeReviewRepository := pg2.NewNodeReviewRepository(db)
 	kbRepo := cache2.NewKBRepo(cacheCache)
-	knowledgeBaseUsecase, err := usecase.NewKnowledgeBaseUsecase(knowledgeBaseRepository, nodeRepository, ragRepository, ragService, kbRepo, logger, configConfig)
+	appRepository := pg2.NewAppRepository(db, logger)
+	botProfileUsecase := usecase.NewBotProfileUsecase(appRepository, knowledgeBaseRepository, minioClient, logger)
+	knowledgeBaseUsecase, err := usecase.NewKnowledgeBaseUsecase(knowledgeBaseRepository, nodeRepository, ragRepository, nodeReviewRepository, modelRepository, ragService, kbRepo, nodeAttachmentUsecase, botProfileUsecase, logger, configConfig)
 	if err != nil {
 		return nil, err
 	}
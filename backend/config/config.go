@@ -3,22 +3,29 @@ package config
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	Log           LogConfig   `mapstructure:"log"`
-	HTTP          HTTPConfig  `mapstructure:"http"`
-	AdminPassword string      `mapstructure:"admin_password"`
-	PG            PGConfig    `mapstructure:"pg"`
-	MQ            MQConfig    `mapstructure:"mq"`
-	RAG           RAGConfig   `mapstructure:"rag"`
-	Redis         RedisConfig `mapstructure:"redis"`
-	Auth          AuthConfig  `mapstructure:"auth"`
-	S3            S3Config    `mapstructure:"s3"`
-	CaddyAPI      string      `mapstructure:"caddy_api"`
-	SubnetPrefix  string      `mapstructure:"subnet_prefix"`
+	Log           LogConfig       `mapstructure:"log"`
+	HTTP          HTTPConfig      `mapstructure:"http"`
+	AdminPassword string          `mapstructure:"admin_password"`
+	PG            PGConfig        `mapstructure:"pg"`
+	MQ            MQConfig        `mapstructure:"mq"`
+	RAG           RAGConfig       `mapstructure:"rag"`
+	Embedding     EmbedderConfig  `mapstructure:"embedding"`
+	Redis         RedisConfig     `mapstructure:"redis"`
+	Auth          AuthConfig      `mapstructure:"auth"`
+	S3            S3Config        `mapstructure:"s3"`
+	SMTP          SMTPConfig      `mapstructure:"smtp"`
+	StatSink      StatSinkConfig  `mapstructure:"stat_sink"`
+	Cron          CronConfig      `mapstructure:"cron"`
+	Parser        ParserConfig    `mapstructure:"parser"`
+	Telemetry     TelemetryConfig `mapstructure:"telemetry"`
+	CaddyAPI      string          `mapstructure:"caddy_api"`
+	SubnetPrefix  string          `mapstructure:"subnet_prefix"`
 }
 
 type LogConfig struct {
@@ -27,6 +34,11 @@ type LogConfig struct {
 
 type HTTPConfig struct {
 	Port int `mapstructure:"port"`
+	// ignore the ip rules of the settings, to recover after rules locked every admin out
+	IgnoreIPRules bool `mapstructure:"ignore_ip_rules"`
+	// cidrs or ips of the reverse proxies whose X-Forwarded-For is trusted, the subnet of the bundled caddy by default.
+	// the ip of the connection is the client ip if empty
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
 }
 
 type PGConfig struct {
@@ -47,6 +59,10 @@ type NATSConfig struct {
 type RAGConfig struct {
 	Provider string      `mapstructure:"provider"`
 	CTRAG    CTRAGConfig `mapstructure:"ct_rag"`
+	// embedding dimension of the vector index, the embedding model must match it
+	Dimension int `mapstructure:"dimension"`
+	// indexes of the most active kbs are preloaded on startup
+	WarmupKBCount int `mapstructure:"warmup_kb_count"`
 }
 
 type CTRAGConfig struct {
@@ -54,20 +70,115 @@ type CTRAGConfig struct {
 	APIKey  string `mapstructure:"api_key"`
 }
 
+// EmbedderConfig embedding worker service, the api and consumer embed in process if addr is empty
+type EmbedderConfig struct {
+	// grpc address of the embedder, e.g. panda-wiki-embedder:9000
+	Addr string `mapstructure:"addr"`
+	// grpc port the embedder listens on
+	Port int `mapstructure:"port"`
+	// texts merged into one batch from concurrent requests of the same model
+	BatchSize int `mapstructure:"batch_size"`
+	// max wait of a request for others to join its batch, in milliseconds
+	BatchWait int `mapstructure:"batch_wait"`
+	// batches embedded at the same time
+	Workers int `mapstructure:"workers"`
+	// requests waiting for a batch, callers block when it is full
+	QueueSize int `mapstructure:"queue_size"`
+}
+
 type RedisConfig struct {
 	Addr     string `mapstructure:"addr"`
 	Password string `mapstructure:"password"`
 }
 
 type AuthConfig struct {
-	Type string    `mapstructure:"type"`
-	JWT  JWTConfig `mapstructure:"jwt"`
+	Type string     `mapstructure:"type"`
+	JWT  JWTConfig  `mapstructure:"jwt"`
+	OIDC OIDCConfig `mapstructure:"oidc"`
+	LDAP LDAPConfig `mapstructure:"ldap"`
+	// two-factor authentication of password logins, sso users are verified by their provider
+	TwoFactor TwoFactorConfig `mapstructure:"two_factor"`
 }
 
 type JWTConfig struct {
 	Secret string `mapstructure:"secret"`
 }
 
+// OIDCConfig single sign-on of the admin console with an OpenID Connect provider, disabled if issuer is empty.
+// roles of sso users follow their groups at every login
+type OIDCConfig struct {
+	// e.g. https://keycloak.example.com/realms/corp, https://login.microsoftonline.com/{tenant}/v2.0
+	Issuer       string `mapstructure:"issuer"`
+	ClientID     string `mapstructure:"client_id"`
+	ClientSecret string `mapstructure:"client_secret"`
+	// callback registered for the client, e.g. https://wiki.example.com/api/v1/user/oidc/callback
+	RedirectURL string   `mapstructure:"redirect_url"`
+	Scopes      []string `mapstructure:"scopes"`
+	// name shown on the login button
+	DisplayName string `mapstructure:"display_name"`
+	// claim listing the groups of the user, read from userinfo if missing in the id token
+	GroupsClaim string `mapstructure:"groups_claim"`
+	// claim used as the account of new users, email if missing
+	AccountClaim string `mapstructure:"account_claim"`
+	// users in any of the groups are admins
+	AdminGroups []string `mapstructure:"admin_groups"`
+	// roles of members on kbs by group, users in no admin group and with no role are rejected
+	KBRoles []GroupKBRoleConfig `mapstructure:"kb_roles"`
+	// only the built-in admin account may log in with password, kept for recovery
+	DisablePasswordLogin bool `mapstructure:"disable_password_login"`
+}
+
+// LDAPConfig password login of admins and readers against an LDAP directory like OpenLDAP or Active Directory,
+// disabled if url is empty. roles and reader groups follow the groups of the directory at login and the hourly sync
+type LDAPConfig struct {
+	// ldap://ldap.example.com:389 or ldaps://dc.example.com:636
+	URL string `mapstructure:"url"`
+	// upgrade ldap:// connections with StartTLS before binding, on by default.
+	// turn off only for directories reached over a trusted network, passwords are sent in cleartext then
+	StartTLS bool `mapstructure:"start_tls"`
+	// skip verifying the certificate of ldaps or StartTLS, for self-signed directories
+	InsecureSkipVerify bool `mapstructure:"insecure_skip_verify"`
+	// service account searching users and groups, e.g. cn=wiki,ou=services,dc=example,dc=com
+	BindDN       string `mapstructure:"bind_dn"`
+	BindPassword string `mapstructure:"bind_password"`
+	// users are searched under base dn
+	BaseDN string `mapstructure:"base_dn"`
+	// filter of the user logging in, {account} is replaced with the escaped account, e.g.
+	// (&(objectClass=user)(sAMAccountName={account})) for active directory
+	UserFilter string `mapstructure:"user_filter"`
+	// attribute used as the account of new users
+	AccountAttr string `mapstructure:"account_attr"`
+	EmailAttr   string `mapstructure:"email_attr"`
+	NameAttr    string `mapstructure:"name_attr"`
+	// attribute of the user listing dns of its groups
+	GroupAttr string `mapstructure:"group_attr"`
+	// groups are also searched under group base dn with the filter if set, {dn} is replaced with the escaped dn of the user,
+	// e.g. (&(objectClass=groupOfNames)(member={dn})) for directories without memberOf
+	GroupBaseDN string `mapstructure:"group_base_dn"`
+	GroupFilter string `mapstructure:"group_filter"`
+	// groups are matched by their full dn, case insensitive, e.g. cn=wiki-admins,ou=groups,dc=example,dc=com
+	AdminGroups []string            `mapstructure:"admin_groups"`
+	KBRoles     []GroupKBRoleConfig `mapstructure:"kb_roles"`
+	// readers of the public sites may log in with their directory account, their groups are those of the directory
+	ReaderLogin bool `mapstructure:"reader_login"`
+}
+
+// TwoFactorConfig totp two-factor authentication of admin accounts
+type TwoFactorConfig struct {
+	// optional, owners or all. admins and owners of a kb must enroll at their next login under owners,
+	// every user under all, any user may still enroll under optional
+	Policy string `mapstructure:"policy"`
+	// issuer shown by authenticator apps
+	Issuer string `mapstructure:"issuer"`
+}
+
+// GroupKBRoleConfig role on kb of members of a group of the identity provider
+type GroupKBRoleConfig struct {
+	Group string `mapstructure:"group"`
+	KBID  string `mapstructure:"kb_id"`
+	Role  string `mapstructure:"role"` // owner, editor, analyst, support_agent
+}
+
 type S3Config struct {
 	Endpoint    string `mapstructure:"endpoint"`
 	AccessKey   string `mapstructure:"access_key"`
@@ -75,6 +186,69 @@ type S3Config struct {
 	MaxFileSize int64  `mapstructure:"max_file_size"`
 }
 
+type SMTPConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	From     string `mapstructure:"from"`
+}
+
+// StatSinkConfig ship stat events to external analytics, disabled if type is empty
+type StatSinkConfig struct {
+	Type          string               `mapstructure:"type"` // http, clickhouse, bigquery
+	BatchSize     int                  `mapstructure:"batch_size"`
+	FlushInterval int                  `mapstructure:"flush_interval"` // seconds
+	HTTP          HTTPSinkConfig       `mapstructure:"http"`
+	ClickHouse    ClickHouseSinkConfig `mapstructure:"clickhouse"`
+	BigQuery      BigQuerySinkConfig   `mapstructure:"bigquery"`
+}
+
+type HTTPSinkConfig struct {
+	URL     string            `mapstructure:"url"`
+	Headers map[string]string `mapstructure:"headers"`
+}
+
+type ClickHouseSinkConfig struct {
+	URL      string `mapstructure:"url"` // http interface, e.g. http://clickhouse:8123
+	Database string `mapstructure:"database"`
+	Table    string `mapstructure:"table"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+}
+
+type BigQuerySinkConfig struct {
+	ProjectID       string `mapstructure:"project_id"`
+	Dataset         string `mapstructure:"dataset"`
+	Table           string `mapstructure:"table"`
+	CredentialsFile string `mapstructure:"credentials_file"` // service account key file
+}
+
+// CronConfig alert when a cron job fails repeatedly, disabled if webhook url is empty
+type CronConfig struct {
+	AlertAfterFailures int               `mapstructure:"alert_after_failures"`
+	AlertWebhook       CronWebhookConfig `mapstructure:"alert_webhook"`
+	// re-index missing nodes and delete orphan documents found by the index integrity check
+	RepairIndex bool `mapstructure:"repair_index"`
+}
+
+type CronWebhookConfig struct {
+	Type   string `mapstructure:"type"` // dingtalk, feishu
+	URL    string `mapstructure:"url"`
+	Secret string `mapstructure:"secret"`
+}
+
+// ParserConfig layout aware parsing service converting uploaded pdf, docx and pptx files to markdown
+type ParserConfig struct {
+	URL string `mapstructure:"url"`
+}
+
+// TelemetryConfig upstream collector of anonymous telemetry reports, reporting is unavailable if url is empty.
+// reports are only sent after the operator enables reporting in the console
+type TelemetryConfig struct {
+	ReportURL string `mapstructure:"report_url"`
+}
+
 func NewConfig() (*Config, error) {
 	// set default config
 	SUBNET_PREFIX := os.Getenv("SUBNET_PREFIX")
@@ -87,7 +261,8 @@ func NewConfig() (*Config, error) {
 		},
 		AdminPassword: "",
 		HTTP: HTTPConfig{
-			Port: 8000,
+			Port:           8000,
+			TrustedProxies: []string{SUBNET_PREFIX + ".0/24"},
 		},
 		PG: PGConfig{
 			DSN: "host=panda-wiki-postgres user=panda-wiki password=panda-wiki-secret dbname=panda-wiki port=5432 sslmode=disable TimeZone=Asia/Shanghai",
@@ -106,6 +281,15 @@ func NewConfig() (*Config, error) {
 				BaseURL: fmt.Sprintf("http://%s.18:8080/api/v1", SUBNET_PREFIX),
 				APIKey:  "sk-1234567890",
 			},
+			Dimension:     1536,
+			WarmupKBCount: 5,
+		},
+		Embedding: EmbedderConfig{
+			Port:      9000,
+			BatchSize: 64,
+			BatchWait: 20,
+			Workers:   1,
+			QueueSize: 1024,
 		},
 		Redis: RedisConfig{
 			Addr:     "panda-wiki-redis:6379",
@@ -114,6 +298,24 @@ func NewConfig() (*Config, error) {
 		Auth: AuthConfig{
 			Type: "jwt",
 			JWT:  JWTConfig{Secret: ""},
+			OIDC: OIDCConfig{
+				Scopes:       []string{"openid", "profile", "email"},
+				DisplayName:  "SSO",
+				GroupsClaim:  "groups",
+				AccountClaim: "preferred_username",
+			},
+			LDAP: LDAPConfig{
+				StartTLS:    true,
+				UserFilter:  "(&(objectClass=person)(uid={account}))",
+				AccountAttr: "uid",
+				EmailAttr:   "mail",
+				NameAttr:    "cn",
+				GroupAttr:   "memberOf",
+			},
+			TwoFactor: TwoFactorConfig{
+				Policy: "owners",
+				Issuer: "PandaWiki",
+			},
 		},
 		S3: S3Config{
 			Endpoint:    "panda-wiki-minio:9000",
@@ -121,6 +323,24 @@ func NewConfig() (*Config, error) {
 			SecretKey:   "",
 			MaxFileSize: 100 * 1024 * 1024, // 100MB
 		},
+		SMTP: SMTPConfig{
+			Port: 465,
+		},
+		StatSink: StatSinkConfig{
+			BatchSize:     500,
+			FlushInterval: 10,
+			ClickHouse: ClickHouseSinkConfig{
+				Database: "default",
+				Table:    "panda_wiki_stat_events",
+			},
+		},
+		Cron: CronConfig{
+			AlertAfterFailures: 3,
+			RepairIndex:        true,
+		},
+		Parser: ParserConfig{
+			URL: "http://panda-wiki-crawler:8080/api/v1/parse",
+		},
 		CaddyAPI:     "/app/run/caddy-admin.sock",
 		SubnetPrefix: "169.254.15",
 	}
@@ -163,15 +383,33 @@ func overrideWithEnv(c *Config) {
 	if env := os.Getenv("JWT_SECRET"); env != "" {
 		c.Auth.JWT.Secret = env
 	}
+	if env := os.Getenv("OIDC_CLIENT_SECRET"); env != "" {
+		c.Auth.OIDC.ClientSecret = env
+	}
+	if env := os.Getenv("LDAP_BIND_PASSWORD"); env != "" {
+		c.Auth.LDAP.BindPassword = env
+	}
 	if env := os.Getenv("S3_SECRET_KEY"); env != "" {
 		c.S3.SecretKey = env
 	}
+	if env := os.Getenv("SMTP_PASSWORD"); env != "" {
+		c.SMTP.Password = env
+	}
+	if env := os.Getenv("STAT_SINK_CLICKHOUSE_PASSWORD"); env != "" {
+		c.StatSink.ClickHouse.Password = env
+	}
 	if env := os.Getenv("ADMIN_PASSWORD"); env != "" {
 		c.AdminPassword = env
 	}
 	if env := os.Getenv("SUBNET_PREFIX"); env != "" {
 		c.SubnetPrefix = env
 	}
+	if env := os.Getenv("IGNORE_IP_RULES"); env == "true" {
+		c.HTTP.IgnoreIPRules = true
+	}
+	if env, ok := os.LookupEnv("TRUSTED_PROXIES"); ok {
+		c.HTTP.TrustedProxies = strings.FieldsFunc(env, func(r rune) bool { return r == ',' || r == ' ' })
+	}
 }
 
 func (*Config) GetString(key string) string {
@@ -15,70 +15,9 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/v1/app": {
-            "put": {
-                "description": "Update app",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "app"
-                ],
-                "summary": "Update app",
-                "parameters": [
-                    {
-                        "description": "app",
-                        "name": "app",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/domain.UpdateAppReq"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/domain.Response"
-                        }
-                    }
-                }
-            },
+        "/api/v1/anomaly/block": {
             "delete": {
-                "description": "Delete app",
-                "consumes": [
-                    "application/json"
-                ],
-                "tags": [
-                    "app"
-                ],
-                "summary": "Delete app",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "app id",
-                        "name": "id",
-                        "in": "query",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/domain.Response"
-                        }
-                    }
-                }
-            }
-        },
-        "/api/v1/app/detail": {
-            "get": {
-                "description": "Get app detail",
+                "description": "lift the block of an ip and reset its counters",
                 "consumes": [
                     "application/json"
                 ],
@@ -86,21 +25,19 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "app"
+                    "anomaly"
                 ],
-                "summary": "Get app detail",
+                "summary": "UnblockIP",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "kb id",
                         "name": "kb_id",
                         "in": "query",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "app type",
-                        "name": "type",
+                        "name": "remote_ip",
                         "in": "query",
                         "required": true
                     }
@@ -109,27 +46,15 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/domain.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/domain.AppDetailResp"
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/domain.Response"
                         }
                     }
                 }
             }
         },
-        "/api/v1/conversation": {
+        "/api/v1/anomaly/list": {
             "get": {
-                "description": "get conversation list",
+                "description": "abnormal chat traffic detected in kb, newest first",
                 "consumes": [
                     "application/json"
                 ],
@@ -137,15 +62,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "conversation"
+                    "anomaly"
                 ],
-                "summary": "get conversation list",
+                "summary": "GetAnomalyList",
                 "parameters": [
-                    {
-                        "type": "string",
-                        "name": "app_id",
-                        "in": "query"
-                    },
                     {
                         "type": "string",
                         "name": "kb_id",
@@ -172,8 +92,18 @@ const docTemplate = `{
                         "in": "query"
                     },
                     {
+                        "enum": [
+                            "question_storm",
+                            "long_input",
+                            "repeated_prompt"
+                        ],
                         "type": "string",
-                        "name": "subject",
+                        "x-enum-varnames": [
+                            "AnomalyTypeQuestionStorm",
+                            "AnomalyTypeLongInput",
+                            "AnomalyTypeRepeatedPrompt"
+                        ],
+                        "name": "type",
                         "in": "query"
                     }
                 ],
@@ -189,7 +119,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/handler_v1.ConversationListItems"
+                                            "$ref": "#/definitions/handler_v1.AnomalyListItems"
                                         }
                                     }
                                 }
@@ -199,9 +129,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/conversation/detail": {
+        "/api/v1/anomaly/report": {
             "get": {
-                "description": "get conversation detail",
+                "description": "anomalies of the last 7 days by type and ip, and the ips still blocked",
                 "consumes": [
                     "application/json"
                 ],
@@ -209,21 +139,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "conversation"
+                    "anomaly"
                 ],
-                "summary": "get conversation detail",
+                "summary": "GetAnomalyReport",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "user id",
-                        "name": "X-SafePoint-User-ID",
-                        "in": "header",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "conversation id",
-                        "name": "id",
+                        "description": "kb id",
+                        "name": "kb_id",
                         "in": "query",
                         "required": true
                     }
@@ -240,7 +163,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/domain.ConversationDetailResp"
+                                            "$ref": "#/definitions/domain.AnomalyReport"
                                         }
                                     }
                                 }
@@ -250,33 +173,28 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/crawler/epub/convert": {
+        "/api/v1/api_token": {
             "post": {
-                "description": "QpubConvert",
+                "description": "create personal or service api token limited to its scopes, the token is only returned in this response",
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "crawler"
+                    "api_token"
                 ],
-                "summary": "QpubConvert",
+                "summary": "CreateAPIToken",
                 "parameters": [
                     {
-                        "type": "file",
-                        "description": "file",
-                        "name": "file",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "kb_id",
-                        "name": "kb_id",
-                        "in": "formData",
-                        "required": true
+                        "description": "create api token request",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.CreateAPITokenReq"
+                        }
                     }
                 ],
                 "responses": {
@@ -291,7 +209,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/domain.EpubResp"
+                                            "$ref": "#/definitions/domain.CreateAPITokenResp"
                                         }
                                     }
                                 }
@@ -299,11 +217,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/crawler/feishu/get_doc": {
-            "post": {
-                "description": "Get Docx in Feishu Spaces",
+            },
+            "delete": {
+                "description": "revoke api token and delete its sandbox kbs",
                 "consumes": [
                     "application/json"
                 ],
@@ -311,20 +227,41 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "crawler"
+                    "api_token"
                 ],
-                "summary": "FeishuGetDocx",
+                "summary": "DeleteAPIToken",
                 "parameters": [
                     {
-                        "description": "Get Docx",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
+                        "type": "string",
+                        "description": "api token id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.GetDocxReq"
+                            "$ref": "#/definitions/domain.Response"
                         }
                     }
+                }
+            }
+        },
+        "/api/v1/api_token/list": {
+            "get": {
+                "description": "every api token for admins, members only get their own",
+                "consumes": [
+                    "application/json"
                 ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "api_token"
+                ],
+                "summary": "GetAPITokenList",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -339,7 +276,7 @@ const docTemplate = `{
                                         "data": {
                                             "type": "array",
                                             "items": {
-                                                "$ref": "#/definitions/domain.GetDocxResp"
+                                                "$ref": "#/definitions/domain.APIToken"
                                             }
                                         }
                                     }
@@ -350,9 +287,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/crawler/feishu/list_doc": {
+        "/api/v1/api_token/rotate": {
             "post": {
-                "description": "List Docx in Feishu Spaces",
+                "description": "replace the token keeping its scopes, the previous token stops working at once and the new one is only returned in this response",
                 "consumes": [
                     "application/json"
                 ],
@@ -360,17 +297,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "crawler"
+                    "api_token"
                 ],
-                "summary": "FeishuListDoc",
+                "summary": "RotateAPIToken",
                 "parameters": [
                     {
-                        "description": "Search Docx",
+                        "description": "rotate api token request",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.SearchDocxReq"
+                            "$ref": "#/definitions/domain.RotateAPITokenReq"
                         }
                     }
                 ],
@@ -386,10 +323,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/domain.SearchDocxResp"
-                                            }
+                                            "$ref": "#/definitions/domain.CreateAPITokenResp"
                                         }
                                     }
                                 }
@@ -399,9 +333,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/crawler/feishu/list_spaces": {
-            "post": {
-                "description": "List All Feishu Spaces",
+        "/api/v1/app": {
+            "put": {
+                "description": "Update app",
                 "consumes": [
                     "application/json"
                 ],
@@ -409,17 +343,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "crawler"
+                    "app"
                 ],
-                "summary": "FeishuListSpaces",
+                "summary": "Update app",
                 "parameters": [
                     {
-                        "description": "List Spaces",
-                        "name": "body",
+                        "description": "app",
+                        "name": "app",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.GetSpaceListReq"
+                            "$ref": "#/definitions/domain.UpdateAppReq"
                         }
                     }
                 ],
@@ -427,30 +361,42 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/domain.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/domain.GetSpaceListResp"
-                                            }
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete app",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "app"
+                ],
+                "summary": "Delete app",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "app id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
                         }
                     }
                 }
             }
         },
-        "/api/v1/crawler/feishu/search_wiki": {
+        "/api/v1/app/bot_profile/sync": {
             "post": {
-                "description": "Search Wiki in Feishu Spaces",
+                "description": "push bot name, avatar and description from kb branding settings to connected bot channels, channels not allowing bots to change their profile are reported unsupported",
                 "consumes": [
                     "application/json"
                 ],
@@ -458,17 +404,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "crawler"
+                    "app"
                 ],
-                "summary": "FeishuSearchWiki",
+                "summary": "SyncBotProfiles",
                 "parameters": [
                     {
-                        "description": "Search Wiki",
+                        "description": "sync bot profiles",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.SearchWikiReq"
+                            "$ref": "#/definitions/domain.SyncBotProfilesReq"
                         }
                     }
                 ],
@@ -484,10 +430,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/domain.SearchWikiResp"
-                                            }
+                                            "$ref": "#/definitions/domain.SyncBotProfilesResp"
                                         }
                                     }
                                 }
@@ -497,9 +440,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/crawler/notion/get_doc": {
-            "post": {
-                "description": "GetDocs",
+        "/api/v1/app/detail": {
+            "get": {
+                "description": "Get app detail",
                 "consumes": [
                     "application/json"
                 ],
@@ -507,18 +450,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "crawler"
+                    "app"
                 ],
-                "summary": "GetDocs",
+                "summary": "Get app detail",
                 "parameters": [
                     {
-                        "description": "Get Docs",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/domain.GetDocsReq"
-                        }
+                        "type": "string",
+                        "description": "kb id",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "app type",
+                        "name": "type",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -533,10 +481,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/domain.Page"
-                                            }
+                                            "$ref": "#/definitions/domain.AppDetailResp"
                                         }
                                     }
                                 }
@@ -546,9 +491,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/crawler/notion/get_list": {
-            "post": {
-                "description": "NotionGetList",
+        "/api/v1/app/prompt": {
+            "get": {
+                "description": "latest prompt template of the app, the built-in template and the variables available in templates",
                 "consumes": [
                     "application/json"
                 ],
@@ -556,18 +501,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "crawler"
+                    "app"
                 ],
-                "summary": "NotionGetList",
+                "summary": "GetAppPrompt",
                 "parameters": [
                     {
-                        "description": "Notion Get List",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/domain.NotnionGetListReq"
-                        }
+                        "type": "string",
+                        "description": "app id",
+                        "name": "app_id",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -582,10 +525,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/domain.PageInfo"
-                                            }
+                                            "$ref": "#/definitions/domain.GetAppPromptResp"
                                         }
                                     }
                                 }
@@ -593,11 +533,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/crawler/parse_rss": {
+            },
             "post": {
-                "description": "Parse RSS",
+                "description": "save the prompt template as a new version of the app, used by its next chats. empty content resets the app to the built-in prompt",
                 "consumes": [
                     "application/json"
                 ],
@@ -605,17 +543,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "crawler"
+                    "app"
                 ],
-                "summary": "Parse RSS",
+                "summary": "SaveAppPrompt",
                 "parameters": [
                     {
-                        "description": "Parse URL",
+                        "description": "prompt template",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.ParseURLReq"
+                            "$ref": "#/definitions/domain.SaveAppPromptReq"
                         }
                     }
                 ],
@@ -631,7 +569,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/domain.ParseURLResp"
+                                            "$ref": "#/definitions/domain.AppPromptTemplate"
                                         }
                                     }
                                 }
@@ -641,9 +579,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/crawler/parse_sitemap": {
+        "/api/v1/app/prompt/preview": {
             "post": {
-                "description": "Parse Sitemap",
+                "description": "render the prompt template for the app without saving it, and answer the question with it by the chat model of the kb if given",
                 "consumes": [
                     "application/json"
                 ],
@@ -651,17 +589,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "crawler"
+                    "app"
                 ],
-                "summary": "Parse Sitemap",
+                "summary": "PreviewAppPrompt",
                 "parameters": [
                     {
-                        "description": "Parse URL",
+                        "description": "prompt template",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.ParseURLReq"
+                            "$ref": "#/definitions/domain.PreviewAppPromptReq"
                         }
                     }
                 ],
@@ -677,7 +615,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/domain.ParseURLResp"
+                                            "$ref": "#/definitions/domain.PreviewAppPromptResp"
                                         }
                                     }
                                 }
@@ -687,9 +625,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/crawler/scrape": {
+        "/api/v1/app/prompt/restore": {
             "post": {
-                "description": "Scrape",
+                "description": "save the content of an earlier version as a new version of the app",
                 "consumes": [
                     "application/json"
                 ],
@@ -697,17 +635,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "crawler"
+                    "app"
                 ],
-                "summary": "Scrape",
+                "summary": "RestoreAppPrompt",
                 "parameters": [
                     {
-                        "description": "Scrape",
+                        "description": "version",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.ScrapeReq"
+                            "$ref": "#/definitions/domain.RestoreAppPromptReq"
                         }
                     }
                 ],
@@ -723,7 +661,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/domain.ScrapeResp"
+                                            "$ref": "#/definitions/domain.AppPromptTemplate"
                                         }
                                     }
                                 }
@@ -733,32 +671,25 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/crawler/wikijs/analysis_export_file": {
-            "post": {
-                "description": "AnalysisExportFile",
+        "/api/v1/app/prompt/versions": {
+            "get": {
+                "description": "saved prompt template versions of the app, latest first",
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "crawler"
+                    "app"
                 ],
-                "summary": "AnalysisExportFile",
+                "summary": "GetAppPromptVersions",
                 "parameters": [
-                    {
-                        "type": "file",
-                        "description": "file",
-                        "name": "file",
-                        "in": "formData",
-                        "required": true
-                    },
                     {
                         "type": "string",
-                        "description": "kb_id",
-                        "name": "kb_id",
-                        "in": "formData",
+                        "description": "app id",
+                        "name": "app_id",
+                        "in": "query",
                         "required": true
                     }
                 ],
@@ -776,7 +707,7 @@ const docTemplate = `{
                                         "data": {
                                             "type": "array",
                                             "items": {
-                                                "$ref": "#/definitions/domain.WikiJSResp"
+                                                "$ref": "#/definitions/domain.AppPromptTemplate"
                                             }
                                         }
                                     }
@@ -787,9 +718,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/creation/text": {
-            "post": {
-                "description": "Text creation",
+        "/api/v1/audit/detail": {
+            "get": {
+                "description": "entry with the resource before and after the write and its hash",
                 "consumes": [
                     "application/json"
                 ],
@@ -797,68 +728,43 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "creation"
-                ],
-                "summary": "Text creation",
-                "parameters": [
-                    {
-                        "description": "text creation request",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/domain.TextReq"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "success",
-                        "schema": {
-                            "type": "string"
-                        }
-                    }
-                }
-            }
-        },
-        "/api/v1/file/upload": {
-            "post": {
-                "description": "Upload File",
-                "consumes": [
-                    "multipart/form-data"
-                ],
-                "tags": [
-                    "file"
+                    "audit"
                 ],
-                "summary": "Upload File",
+                "summary": "GetAuditLog",
                 "parameters": [
                     {
-                        "type": "file",
-                        "description": "File",
-                        "name": "file",
-                        "in": "formData",
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "id",
+                        "in": "query",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Knowledge Base ID",
-                        "name": "kb_id",
-                        "in": "formData"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.ObjectUploadResp"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.AuditLog"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
             }
         },
-        "/api/v1/knowledge_base": {
-            "post": {
-                "description": "CreateKnowledgeBase",
+        "/api/v1/audit/list": {
+            "get": {
+                "description": "writes to the admin apis, latest first, filtered by actor, resource, kb and time range",
                 "consumes": [
                     "application/json"
                 ],
@@ -866,33 +772,84 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "knowledge_base"
+                    "audit"
                 ],
-                "summary": "CreateKnowledgeBase",
+                "summary": "GetAuditLogList",
                 "parameters": [
                     {
-                        "description": "CreateKnowledgeBase Request",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/domain.CreateKnowledgeBaseReq"
-                        }
+                        "type": "string",
+                        "name": "actor_id",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "unix timestamp, no limit if not set",
+                        "name": "end_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "kb_id",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "per_page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "name": "resource_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "resource_type",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "unix timestamp, no limit if not set",
+                        "name": "start_time",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler_v1.AuditLogListItems"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
             }
         },
-        "/api/v1/knowledge_base/detail": {
+        "/api/v1/audit/verify": {
             "get": {
-                "description": "GetKnowledgeBaseDetail",
+                "description": "recompute the hash chain of the audit log from the anchor left by retention, the first edited entry or the entry after a deleted one is returned",
                 "consumes": [
                     "application/json"
                 ],
@@ -900,18 +857,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "knowledge_base"
-                ],
-                "summary": "GetKnowledgeBaseDetail",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Knowledge Base ID",
-                        "name": "id",
-                        "in": "query",
-                        "required": true
-                    }
+                    "audit"
                 ],
+                "summary": "VerifyAuditLogs",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -924,7 +872,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/domain.KnowledgeBaseDetail"
+                                            "$ref": "#/definitions/domain.AuditLogVerifyResp"
                                         }
                                     }
                                 }
@@ -932,9 +880,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "UpdateKnowledgeBase",
+            }
+        },
+        "/api/v1/conversation": {
+            "get": {
+                "description": "get conversation list",
                 "consumes": [
                     "application/json"
                 ],
@@ -942,31 +892,77 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "knowledge_base"
+                    "conversation"
                 ],
-                "summary": "UpdateKnowledgeBase",
+                "summary": "get conversation list",
                 "parameters": [
                     {
-                        "description": "UpdateKnowledgeBase Request",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/domain.UpdateKnowledgeBaseReq"
-                        }
+                        "type": "string",
+                        "name": "app_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "name": "historical",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "per_page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "name": "remote_ip",
+                        "in": "query"
+                    },
+                    {
+                        "description": "matches the title or the first question",
+                        "type": "string",
+                        "name": "subject",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler_v1.ConversationListItems"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "DeleteKnowledgeBase",
+            }
+        },
+        "/api/v1/conversation/detail": {
+            "get": {
+                "description": "get conversation detail, supports If-None-Match and returns only the since message, messages after it and messages still streaming",
                 "consumes": [
                     "application/json"
                 ],
@@ -974,41 +970,37 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "knowledge_base"
+                    "conversation"
                 ],
-                "summary": "DeleteKnowledgeBase",
+                "summary": "get conversation detail",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Knowledge Base ID",
+                        "description": "user id",
+                        "name": "X-SafePoint-User-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "etag of last response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "conversation id",
                         "name": "id",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "id of the last message already fetched, messages of the same id replace fetched ones",
+                        "name": "since",
+                        "in": "query"
                     }
                 ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/domain.Response"
-                        }
-                    }
-                }
-            }
-        },
-        "/api/v1/knowledge_base/list": {
-            "get": {
-                "description": "GetKnowledgeBaseList",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "knowledge_base"
-                ],
-                "summary": "GetKnowledgeBaseList",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -1021,56 +1013,80 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/domain.KnowledgeBaseListItem"
-                                            }
+                                            "$ref": "#/definitions/domain.ConversationDetailResp"
                                         }
                                     }
                                 }
                             ]
                         }
+                    },
+                    "304": {
+                        "description": "conversation not changed"
                     }
                 }
             }
         },
-        "/api/v1/knowledge_base/release": {
+        "/api/v1/conversation/import": {
             "post": {
-                "description": "CreateKBRelease",
+                "description": "import support transcripts of a legacy helpdesk as historical conversations, csv has one message per row with conversation_id, role, content and created_at columns, zendesk is a json export of tickets with comments",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "knowledge_base"
+                    "conversation"
                 ],
-                "summary": "CreateKBRelease",
+                "summary": "import helpdesk transcripts",
                 "parameters": [
                     {
-                        "description": "CreateKBRelease Request",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/domain.CreateKBReleaseReq"
-                        }
+                        "type": "file",
+                        "description": "transcript export",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "kb id",
+                        "name": "kb_id",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "csv or zendesk",
+                        "name": "format",
+                        "in": "formData",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.ImportTranscriptsResp"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
             }
         },
-        "/api/v1/knowledge_base/release/list": {
+        "/api/v1/conversation/message/debug": {
             "get": {
-                "description": "GetKBReleaseList",
+                "description": "replay retrieval and prompt of an answer with the current documents and settings: the query, candidates of each retrieval stage with scores, the final prompt and model parameters",
                 "consumes": [
                     "application/json"
                 ],
@@ -1078,16 +1094,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "knowledge_base"
+                    "conversation"
                 ],
-                "summary": "GetKBReleaseList",
+                "summary": "DebugMessage",
                 "parameters": [
                     {
+                        "description": "kb id",
                         "type": "string",
-                        "description": "Knowledge Base ID",
                         "name": "kb_id",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "description": "answer message id",
+                        "type": "string",
+                        "name": "message_id",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -1102,7 +1125,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/domain.GetKBReleaseListResp"
+                                            "$ref": "#/definitions/domain.MessageDebugResp"
                                         }
                                     }
                                 }
@@ -1112,40 +1135,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/model": {
-            "put": {
-                "description": "update model",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "model"
-                ],
-                "parameters": [
-                    {
-                        "description": "update model request",
-                        "name": "model",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/domain.UpdateModelReq"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/domain.Response"
-                        }
-                    }
-                }
-            },
+        "/api/v1/conversation/rescore": {
             "post": {
-                "description": "create model",
+                "description": "create async job retrieving documents of recent questions again with candidate answer settings and evaluating the confidence gate offline, to estimate the change of low confidence rate and satisfaction before the settings are saved",
                 "consumes": [
                     "application/json"
                 ],
@@ -1153,17 +1145,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "model"
+                    "conversation"
                 ],
-                "summary": "create model",
+                "summary": "CreateConversationRescoreJob",
                 "parameters": [
                     {
-                        "description": "create model request",
-                        "name": "model",
+                        "description": "conversation rescore request",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.CreateModelReq"
+                            "$ref": "#/definitions/domain.ConversationRescoreReq"
                         }
                     }
                 ],
@@ -1171,15 +1163,27 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.ConversationRescoreJob"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
             }
         },
-        "/api/v1/model/check": {
-            "post": {
-                "description": "check model",
+        "/api/v1/conversation/rescore/job": {
+            "get": {
+                "description": "status of conversation rescore job, with the scores before and after the candidate settings when succeeded",
                 "consumes": [
                     "application/json"
                 ],
@@ -1187,18 +1191,21 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "model"
+                    "conversation"
                 ],
-                "summary": "check model",
+                "summary": "GetConversationRescoreJob",
                 "parameters": [
                     {
-                        "description": "check model request",
-                        "name": "model",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/domain.CheckModelReq"
-                        }
+                        "type": "string",
+                        "name": "job_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -1213,7 +1220,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/domain.CheckModelResp"
+                                            "$ref": "#/definitions/domain.ConversationRescoreJob"
                                         }
                                     }
                                 }
@@ -1223,9 +1230,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/model/detail": {
+        "/api/v1/conversation/rescore/jobs": {
             "get": {
-                "description": "get model detail",
+                "description": "GetConversationRescoreJobList",
                 "consumes": [
                     "application/json"
                 ],
@@ -1233,14 +1240,27 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "model"
+                    "conversation"
                 ],
-                "summary": "get model detail",
+                "summary": "GetConversationRescoreJobList",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "model id",
-                        "name": "id",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "per_page",
                         "in": "query",
                         "required": true
                     }
@@ -1257,7 +1277,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/domain.ModelDetailResp"
+                                            "$ref": "#/definitions/handler_v1.ConversationRescoreJobListItems"
                                         }
                                     }
                                 }
@@ -1267,9 +1287,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/model/list": {
+        "/api/v1/conversation/source_attribution": {
             "get": {
-                "description": "get model list",
+                "description": "documents most cited by answers of the recent days, 30 by default, with likes and dislikes of those answers",
                 "consumes": [
                     "application/json"
                 ],
@@ -1277,9 +1297,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "model"
+                    "conversation"
+                ],
+                "summary": "get source attribution report",
+                "parameters": [
+                    {
+                        "maximum": 365,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "days",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    }
                 ],
-                "summary": "get model list",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -1292,7 +1327,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/domain.ModelListItem"
+                                            "$ref": "#/definitions/domain.SourceAttributionReport"
                                         }
                                     }
                                 }
@@ -1302,67 +1337,107 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/model/provider/supported": {
+        "/api/v1/conversation/stream": {
             "get": {
-                "description": "get provider supported model list",
+                "description": "stream all conversations matching the filter as newline delimited json, oldest first, without pagination.\na stream failed after it started ends with a line of {\"error\": \"...\"}",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/x-ndjson"
                 ],
                 "tags": [
-                    "model"
+                    "conversation"
                 ],
-                "summary": "get provider supported model list",
+                "summary": "stream conversations",
                 "parameters": [
                     {
                         "type": "string",
-                        "name": "api_header",
+                        "name": "app_id",
                         "in": "query"
                     },
                     {
-                        "type": "string",
-                        "name": "api_key",
+                        "minimum": 0,
+                        "type": "integer",
+                        "name": "end_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "name": "historical",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "name": "base_url",
+                        "name": "kb_id",
                         "in": "query",
                         "required": true
                     },
                     {
-                        "enum": [
-                            "SiliconFlow",
-                            "OpenAI",
-                            "Ollama",
-                            "DeepSeek",
-                            "Moonshot",
-                            "AzureOpenAI",
-                            "BaiZhiCloud",
-                            "Hunyuan",
-                            "BaiLian",
-                            "Volcengine"
-                        ],
                         "type": "string",
-                        "name": "provider",
+                        "name": "remote_ip",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "unix timestamps bounding created_at, unbounded if 0",
+                        "name": "start_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "include messages and references of each conversation",
+                        "name": "with_messages",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ConversationStreamItem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/conversation/transcript_emails": {
+            "get": {
+                "description": "get log of conversation transcripts sent to end users by email",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "conversation"
+                ],
+                "summary": "get transcript email list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "conversation_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "kb_id",
                         "in": "query",
                         "required": true
                     },
                     {
-                        "enum": [
-                            "chat",
-                            "embedding",
-                            "rerank"
-                        ],
-                        "type": "string",
-                        "x-enum-varnames": [
-                            "ModelTypeChat",
-                            "ModelTypeEmbedding",
-                            "ModelTypeRerank"
-                        ],
-                        "name": "type",
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "per_page",
                         "in": "query",
                         "required": true
                     }
@@ -1379,7 +1454,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/domain.GetProviderModelListResp"
+                                            "$ref": "#/definitions/handler_v1.TranscriptEmailListItems"
                                         }
                                     }
                                 }
@@ -1389,28 +1464,33 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/node": {
+        "/api/v1/crawler/epub/convert": {
             "post": {
-                "description": "Create Node",
+                "description": "QpubConvert",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "node"
+                    "crawler"
                 ],
-                "summary": "Create Node",
+                "summary": "QpubConvert",
                 "parameters": [
                     {
-                        "description": "Node",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/domain.CreateNodeReq"
-                        }
+                        "type": "file",
+                        "description": "file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "kb_id",
+                        "name": "kb_id",
+                        "in": "formData",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -1425,10 +1505,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "object",
-                                            "additionalProperties": {
-                                                "type": "string"
-                                            }
+                                            "$ref": "#/definitions/domain.EpubResp"
                                         }
                                     }
                                 }
@@ -1438,9 +1515,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/node/action": {
+        "/api/v1/crawler/feishu/get_doc": {
             "post": {
-                "description": "Node Action",
+                "description": "Get Docx in Feishu Spaces",
                 "consumes": [
                     "application/json"
                 ],
@@ -1448,17 +1525,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "node"
+                    "crawler"
                 ],
-                "summary": "Node Action",
+                "summary": "FeishuGetDocx",
                 "parameters": [
                     {
-                        "description": "Action",
-                        "name": "action",
+                        "description": "Get Docx",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.NodeActionReq"
+                            "$ref": "#/definitions/domain.GetDocxReq"
                         }
                     }
                 ],
@@ -1474,9 +1551,9 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "object",
-                                            "additionalProperties": {
-                                                "type": "string"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.GetDocxResp"
                                             }
                                         }
                                     }
@@ -1487,9 +1564,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/node/detail": {
-            "get": {
-                "description": "Get Node Detail",
+        "/api/v1/crawler/feishu/list_doc": {
+            "post": {
+                "description": "List Docx in Feishu Spaces",
                 "consumes": [
                     "application/json"
                 ],
@@ -1497,16 +1574,18 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "node"
+                    "crawler"
                 ],
-                "summary": "Get Node Detail",
+                "summary": "FeishuListDoc",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "ID",
-                        "name": "id",
-                        "in": "query",
-                        "required": true
+                        "description": "Search Docx",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.SearchDocxReq"
+                        }
                     }
                 ],
                 "responses": {
@@ -1521,7 +1600,10 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/domain.NodeDetailResp"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.SearchDocxResp"
+                                            }
                                         }
                                     }
                                 }
@@ -1529,9 +1611,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Update Node Detail",
+            }
+        },
+        "/api/v1/crawler/feishu/list_spaces": {
+            "post": {
+                "description": "List All Feishu Spaces",
                 "consumes": [
                     "application/json"
                 ],
@@ -1539,55 +1623,19 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "node"
+                    "crawler"
                 ],
-                "summary": "Update Node Detail",
+                "summary": "FeishuListSpaces",
                 "parameters": [
                     {
-                        "description": "Node",
+                        "description": "List Spaces",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.UpdateNodeReq"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/domain.Response"
+                            "$ref": "#/definitions/domain.GetSpaceListReq"
                         }
                     }
-                }
-            }
-        },
-        "/api/v1/node/list": {
-            "get": {
-                "description": "Get Node List",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "node"
-                ],
-                "summary": "Get Node List",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "name": "kb_id",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "name": "search",
-                        "in": "query"
-                    }
                 ],
                 "responses": {
                     "200": {
@@ -1603,7 +1651,7 @@ const docTemplate = `{
                                         "data": {
                                             "type": "array",
                                             "items": {
-                                                "$ref": "#/definitions/domain.NodeListItemResp"
+                                                "$ref": "#/definitions/domain.GetSpaceListResp"
                                             }
                                         }
                                     }
@@ -1614,9 +1662,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/node/move": {
+        "/api/v1/crawler/feishu/search_wiki": {
             "post": {
-                "description": "Move Node",
+                "description": "Search Wiki in Feishu Spaces",
                 "consumes": [
                     "application/json"
                 ],
@@ -1624,17 +1672,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "node"
+                    "crawler"
                 ],
-                "summary": "Move Node",
+                "summary": "FeishuSearchWiki",
                 "parameters": [
                     {
-                        "description": "Move Node",
+                        "description": "Search Wiki",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.MoveNodeReq"
+                            "$ref": "#/definitions/domain.SearchWikiReq"
                         }
                     }
                 ],
@@ -1642,15 +1690,30 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.SearchWikiResp"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
             }
         },
-        "/api/v1/node/recommend_nodes": {
-            "get": {
-                "description": "Recommend Nodes",
+        "/api/v1/crawler/notion/get_doc": {
+            "post": {
+                "description": "GetDocs",
                 "consumes": [
                     "application/json"
                 ],
@@ -1658,25 +1721,18 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "node"
+                    "crawler"
                 ],
-                "summary": "Recommend Nodes",
+                "summary": "GetDocs",
                 "parameters": [
                     {
-                        "type": "string",
-                        "name": "kb_id",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "array",
-                        "items": {
-                            "type": "string"
-                        },
-                        "collectionFormat": "csv",
-                        "name": "node_ids",
-                        "in": "query",
-                        "required": true
+                        "description": "Get Docs",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.GetDocsReq"
+                        }
                     }
                 ],
                 "responses": {
@@ -1693,7 +1749,7 @@ const docTemplate = `{
                                         "data": {
                                             "type": "array",
                                             "items": {
-                                                "$ref": "#/definitions/domain.RecommendNodeListResp"
+                                                "$ref": "#/definitions/domain.Page"
                                             }
                                         }
                                     }
@@ -1704,9 +1760,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/node/summary": {
+        "/api/v1/crawler/notion/get_list": {
             "post": {
-                "description": "Summary Node",
+                "description": "NotionGetList",
                 "consumes": [
                     "application/json"
                 ],
@@ -1714,17 +1770,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "node"
+                    "crawler"
                 ],
-                "summary": "Summary Node",
+                "summary": "NotionGetList",
                 "parameters": [
                     {
-                        "description": "Summary Node",
+                        "description": "Notion Get List",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.NodeSummaryReq"
+                            "$ref": "#/definitions/domain.NotnionGetListReq"
                         }
                     }
                 ],
@@ -1732,15 +1788,30 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.PageInfo"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
             }
         },
-        "/api/v1/stat/browsers": {
-            "get": {
-                "description": "GetBrowsers",
+        "/api/v1/crawler/parse_rss": {
+            "post": {
+                "description": "Parse RSS",
                 "consumes": [
                     "application/json"
                 ],
@@ -1748,31 +1819,45 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "stat"
+                    "crawler"
                 ],
-                "summary": "GetBrowsers",
+                "summary": "Parse RSS",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "kb_id",
-                        "name": "kb_id",
-                        "in": "query",
-                        "required": true
+                        "description": "Parse URL",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.ParseURLReq"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.ParseURLResp"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
             }
         },
-        "/api/v1/stat/conversation_distribution": {
-            "get": {
-                "description": "GetConversationDistribution",
+        "/api/v1/crawler/parse_sitemap": {
+            "post": {
+                "description": "Parse Sitemap",
                 "consumes": [
                     "application/json"
                 ],
@@ -1780,31 +1865,45 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "stat"
+                    "crawler"
                 ],
-                "summary": "GetConversationDistribution",
+                "summary": "Parse Sitemap",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "kb_id",
-                        "name": "kb_id",
-                        "in": "query",
-                        "required": true
+                        "description": "Parse URL",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.ParseURLReq"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.ParseURLResp"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
             }
         },
-        "/api/v1/stat/count": {
-            "get": {
-                "description": "GetCount",
+        "/api/v1/crawler/scrape": {
+            "post": {
+                "description": "Scrape",
                 "consumes": [
                     "application/json"
                 ],
@@ -1812,47 +1911,68 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "stat"
+                    "crawler"
                 ],
-                "summary": "GetCount",
+                "summary": "Scrape",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "kb_id",
-                        "name": "kb_id",
-                        "in": "query",
-                        "required": true
+                        "description": "Scrape",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.ScrapeReq"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.ScrapeResp"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
             }
         },
-        "/api/v1/stat/geo_count": {
-            "get": {
-                "description": "GetGeoCount",
+        "/api/v1/crawler/wikijs/analysis_export_file": {
+            "post": {
+                "description": "AnalysisExportFile",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "stat"
+                    "crawler"
                 ],
-                "summary": "GetGeoCount",
+                "summary": "AnalysisExportFile",
                 "parameters": [
+                    {
+                        "type": "file",
+                        "description": "file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
                     {
                         "type": "string",
                         "description": "kb_id",
                         "name": "kb_id",
-                        "in": "query",
+                        "in": "formData",
                         "required": true
                     }
                 ],
@@ -1860,15 +1980,30 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.WikiJSResp"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
             }
         },
-        "/api/v1/stat/hot_pages": {
-            "get": {
-                "description": "GetHotPages",
+        "/api/v1/creation/text": {
+            "post": {
+                "description": "Text creation",
                 "consumes": [
                     "application/json"
                 ],
@@ -1876,31 +2011,71 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "stat"
+                    "creation"
                 ],
-                "summary": "GetHotPages",
+                "summary": "Text creation",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "kb_id",
-                        "name": "kb_id",
-                        "in": "query",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
+                        "description": "text creation request",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.TextReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "success",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/cron/jobs": {
+            "get": {
+                "description": "last run and consecutive failures of each cron job",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cron"
+                ],
+                "summary": "GetCronJobStatus",
+                "responses": {
+                    "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.CronJobStatus"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
             }
         },
-        "/api/v1/stat/instant_count": {
+        "/api/v1/cron/runs": {
             "get": {
-                "description": "GetInstantCount",
+                "description": "run history of cron jobs, newest first",
                 "consumes": [
                     "application/json"
                 ],
@@ -1908,31 +2083,68 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "stat"
+                    "cron"
                 ],
-                "summary": "GetInstantCount",
+                "summary": "GetCronRunList",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "kb_id",
-                        "name": "kb_id",
+                        "name": "job",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "per_page",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "enum": [
+                            "success",
+                            "failed"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "CronRunStatusSuccess",
+                            "CronRunStatusFailed"
+                        ],
+                        "name": "status",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler_v1.CronRunListItems"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
             }
         },
-        "/api/v1/stat/instant_pages": {
-            "get": {
-                "description": "GetInstantPages",
+        "/api/v1/data/export": {
+            "post": {
+                "description": "create async job exporting everything stored for the kb, or the records referencing an end user, as json lines files of a zip archive for compliance requests",
                 "consumes": [
                     "application/json"
                 ],
@@ -1940,31 +2152,45 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "stat"
+                    "data_export"
                 ],
-                "summary": "GetInstantPages",
+                "summary": "CreateDataExportJob",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "kb_id",
-                        "name": "kb_id",
-                        "in": "query",
-                        "required": true
+                        "description": "data export request",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.DataExportReq"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.DataExportJob"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
             }
         },
-        "/api/v1/stat/referer_hosts": {
+        "/api/v1/data/export/job": {
             "get": {
-                "description": "GetRefererHosts",
+                "description": "status of data export job, with download url of the archive when succeeded",
                 "consumes": [
                     "application/json"
                 ],
@@ -1972,13 +2198,18 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "stat"
+                    "data_export"
                 ],
-                "summary": "GetRefererHosts",
+                "summary": "GetDataExportJob",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "kb_id",
+                        "name": "job_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
                         "name": "kb_id",
                         "in": "query",
                         "required": true
@@ -1988,35 +2219,84 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.DataExportJob"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
             }
         },
-        "/api/v1/user": {
+        "/api/v1/data/export/jobs": {
             "get": {
-                "description": "GetUser",
+                "description": "GetDataExportJobList",
                 "consumes": [
                     "application/json"
                 ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "user"
+                    "data_export"
+                ],
+                "summary": "GetDataExportJobList",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "per_page",
+                        "in": "query",
+                        "required": true
+                    }
                 ],
-                "summary": "GetUser",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.UserInfoResp"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler_v1.DataExportJobListItems"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
             }
         },
-        "/api/v1/user/create": {
-            "post": {
-                "description": "CreateUser",
+        "/api/v1/digest": {
+            "get": {
+                "description": "volume, escalations, new questions and example transcripts of conversations of a day",
                 "consumes": [
                     "application/json"
                 ],
@@ -2024,33 +2304,48 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "digest"
                 ],
-                "summary": "CreateUser",
+                "summary": "GetDailyDigest",
                 "parameters": [
                     {
-                        "description": "CreateUser Request",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/domain.CreateUserReq"
-                        }
+                        "type": "string",
+                        "description": "YYYY-MM-DD, yesterday if empty",
+                        "name": "date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.DailyDigestResp"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
             }
         },
-        "/api/v1/user/delete": {
-            "delete": {
-                "description": "DeleteUser",
+        "/api/v1/digest/send": {
+            "post": {
+                "description": "save digest as node and push it to group webhook, as configured in kb digest settings",
                 "consumes": [
                     "application/json"
                 ],
@@ -2058,17 +2353,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "digest"
                 ],
-                "summary": "DeleteUser",
+                "summary": "SendDailyDigest",
                 "parameters": [
                     {
-                        "description": "DeleteUser Request",
+                        "description": "digest request",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.DeleteUserReq"
+                            "$ref": "#/definitions/domain.DailyDigestReq"
                         }
                     }
                 ],
@@ -2082,9 +2377,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/user/list": {
-            "get": {
-                "description": "ListUsers",
+        "/api/v1/eval/question": {
+            "post": {
+                "description": "add questions with expected answers to eval set, a set has at most 200 questions",
                 "consumes": [
                     "application/json"
                 ],
@@ -2092,9 +2387,20 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "eval"
+                ],
+                "summary": "CreateEvalQuestions",
+                "parameters": [
+                    {
+                        "description": "eval questions",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.CreateEvalQuestionsReq"
+                        }
+                    }
                 ],
-                "summary": "ListUsers",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -2109,7 +2415,7 @@ const docTemplate = `{
                                         "data": {
                                             "type": "array",
                                             "items": {
-                                                "$ref": "#/definitions/domain.UserListItemResp"
+                                                "$ref": "#/definitions/domain.EvalQuestion"
                                             }
                                         }
                                     }
@@ -2118,11 +2424,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/user/login": {
-            "post": {
-                "description": "Login",
+            },
+            "put": {
+                "description": "update question and expected answer, results of past runs keep the copy they were judged with",
                 "consumes": [
                     "application/json"
                 ],
@@ -2130,17 +2434,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "eval"
                 ],
-                "summary": "Login",
+                "summary": "UpdateEvalQuestion",
                 "parameters": [
                     {
-                        "description": "Login Request",
+                        "description": "eval question",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.LoginReq"
+                            "$ref": "#/definitions/domain.UpdateEvalQuestionReq"
                         }
                     }
                 ],
@@ -2148,33 +2452,31 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.LoginResp"
+                            "$ref": "#/definitions/domain.Response"
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/user/reset_password": {
-            "put": {
-                "description": "ResetPassword",
-                "consumes": [
+            },
+            "delete": {
+                "description": "delete question of eval set",
+                "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "eval"
                 ],
-                "summary": "ResetPassword",
+                "summary": "DeleteEvalQuestion",
                 "parameters": [
                     {
-                        "description": "ResetPassword Request",
+                        "description": "eval question",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.ResetPasswordReq"
+                            "$ref": "#/definitions/domain.DeleteEvalQuestionReq"
                         }
                     }
                 ],
@@ -2188,9 +2490,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/share/v1/app/web/info": {
+        "/api/v1/eval/question/list": {
             "get": {
-                "description": "GetAppInfo",
+                "description": "questions of eval set in the order they were added",
                 "consumes": [
                     "application/json"
                 ],
@@ -2198,15 +2500,20 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "share_app"
+                    "eval"
                 ],
-                "summary": "GetAppInfo",
+                "summary": "GetEvalQuestionList",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "kb id",
-                        "name": "X-KB-ID",
-                        "in": "header",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "name": "set_id",
+                        "in": "query",
                         "required": true
                     }
                 ],
@@ -2214,15 +2521,30 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.EvalQuestion"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
             }
         },
-        "/share/v1/chat/message": {
+        "/api/v1/eval/run": {
             "post": {
-                "description": "ChatMessage",
+                "description": "create async run answering every question of the set with the current documents, chat model of the kb and prompt of the app, and scoring faithfulness and relevance of the answers by the model as judge",
                 "consumes": [
                     "application/json"
                 ],
@@ -2230,40 +2552,92 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "share_chat"
+                    "eval"
                 ],
-                "summary": "ChatMessage",
+                "summary": "CreateEvalRun",
+                "parameters": [
+                    {
+                        "description": "eval run request",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.CreateEvalRunReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.EvalRun"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "get": {
+                "description": "status of eval run with the model and prompt version it used, and its summary when succeeded",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "eval"
+                ],
+                "summary": "GetEvalRun",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "app type",
-                        "name": "app_type",
+                        "name": "kb_id",
                         "in": "query",
                         "required": true
                     },
                     {
-                        "description": "request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/domain.ChatRequest"
-                        }
+                        "type": "string",
+                        "name": "run_id",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.EvalRun"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
             }
         },
-        "/share/v1/node/detail": {
+        "/api/v1/eval/run/list": {
             "get": {
-                "description": "GetNodeDetail",
+                "description": "eval runs of the kb or of one set, newest first",
                 "consumes": [
                     "application/json"
                 ],
@@ -2271,38 +2645,62 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "share_node"
+                    "eval"
                 ],
-                "summary": "GetNodeDetail",
+                "summary": "GetEvalRunList",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "kb id",
-                        "name": "X-KB-ID",
-                        "in": "header",
+                        "name": "kb_id",
+                        "in": "query",
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "node id",
-                        "name": "id",
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "per_page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "runs of all sets if empty",
+                        "type": "string",
+                        "name": "set_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler_v1.EvalRunListItems"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
             }
         },
-        "/share/v1/node/list": {
+        "/api/v1/eval/run/results": {
             "get": {
-                "description": "GetNodeList",
+                "description": "answers and scores of eval run, failed and lowest scored first",
                 "consumes": [
                     "application/json"
                 ],
@@ -2310,15 +2708,40 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "share_node"
+                    "eval"
                 ],
-                "summary": "GetNodeList",
+                "summary": "GetEvalResultList",
                 "parameters": [
+                    {
+                        "description": "only questions which failed or did not pass",
+                        "type": "boolean",
+                        "name": "failed_only",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
-                        "description": "kb id",
-                        "name": "X-KB-ID",
-                        "in": "header",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "per_page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "name": "run_id",
+                        "in": "query",
                         "required": true
                     }
                 ],
@@ -2326,15 +2749,27 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler_v1.EvalResultListItems"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
             }
         },
-        "/share/v1/stat/page": {
+        "/api/v1/eval/set": {
             "post": {
-                "description": "RecordPage",
+                "description": "create eval set of golden questions",
                 "consumes": [
                     "application/json"
                 ],
@@ -2342,17 +2777,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "share_stat"
+                    "eval"
                 ],
-                "summary": "RecordPage",
+                "summary": "CreateEvalSet",
                 "parameters": [
                     {
-                        "description": "request",
-                        "name": "request",
+                        "description": "eval set",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.StatPageReq"
+                            "$ref": "#/definitions/domain.CreateEvalSetReq"
                         }
                     }
                 ],
@@ -2360,481 +2795,16528 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.EvalSet"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
-        "domain.AccessSettings": {
-            "type": "object",
-            "properties": {
-                "base_url": {
-                    "type": "string"
-                },
-                "hosts": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
+            },
+            "put": {
+                "description": "update name and description of eval set",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "eval"
+                ],
+                "summary": "UpdateEvalSet",
+                "parameters": [
+                    {
+                        "description": "eval set",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.UpdateEvalSetReq"
+                        }
                     }
-                },
-                "ports": {
-                    "type": "array",
-                    "items": {
-                        "type": "integer"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
                     }
-                },
-                "private_key": {
-                    "type": "string"
-                },
+                }
+            },
+            "delete": {
+                "description": "delete eval set with its questions, runs and results",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "eval"
+                ],
+                "summary": "DeleteEvalSet",
+                "parameters": [
+                    {
+                        "description": "eval set",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.DeleteEvalSetReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/eval/set/list": {
+            "get": {
+                "description": "eval sets of the kb with their question count and the summary of their latest run",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "eval"
+                ],
+                "summary": "GetEvalSetList",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.EvalSetListItem"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/eval/trend": {
+            "get": {
+                "description": "scores of the recent succeeded runs of eval set oldest first, with the model and prompt version of each run to compare changes",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "eval"
+                ],
+                "summary": "GetEvalTrend",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "name": "set_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.EvalTrendResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/file/upload": {
+            "post": {
+                "description": "Upload File",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "tags": [
+                    "file"
+                ],
+                "summary": "Upload File",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "File",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Knowledge Base ID",
+                        "name": "kb_id",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ObjectUploadResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/gap_report": {
+            "get": {
+                "description": "unanswered questions of last week, stale documents and unresolved tickets imported from a helpdesk",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "gap_report"
+                ],
+                "summary": "GetGapReport",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "kb_id",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.GapReport"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/gap_report/send": {
+            "post": {
+                "description": "SendGapReport",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "gap_report"
+                ],
+                "summary": "SendGapReport",
+                "parameters": [
+                    {
+                        "description": "body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.SendGapReportReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/glossary": {
+            "put": {
+                "description": "update glossary term",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "glossary"
+                ],
+                "summary": "UpdateGlossaryTerm",
+                "parameters": [
+                    {
+                        "description": "term",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.UpdateGlossaryTermReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "create glossary term, its first mention in published documents is linked and its definition is given to the model when mentioned",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "glossary"
+                ],
+                "summary": "CreateGlossaryTerm",
+                "parameters": [
+                    {
+                        "description": "term",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.CreateGlossaryTermReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object",
+                                            "additionalProperties": {
+                                                "type": "string"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "delete glossary term",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "glossary"
+                ],
+                "summary": "DeleteGlossaryTerm",
+                "parameters": [
+                    {
+                        "description": "term",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.DeleteGlossaryTermReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/glossary/list": {
+            "get": {
+                "description": "glossary terms of kb",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "glossary"
+                ],
+                "summary": "GetGlossaryTermList",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "kb id",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.GlossaryTerm"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/guardrail/events": {
+            "get": {
+                "description": "questions and answers matched by the guardrail checks of the apps of kb, newest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "guardrail"
+                ],
+                "summary": "GetGuardrailEventList",
+                "parameters": [
+                    {
+                        "enum": [
+                            "block",
+                            "warn",
+                            "log"
+                        ],
+                        "x-enum-varnames": [
+                            "GuardrailActionBlock",
+                            "GuardrailActionWarn",
+                            "GuardrailActionLog"
+                        ],
+                        "type": "string",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "app_id",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "profanity",
+                            "injection",
+                            "off_topic"
+                        ],
+                        "x-enum-varnames": [
+                            "GuardrailCheckProfanity",
+                            "GuardrailCheckInjection",
+                            "GuardrailCheckOffTopic"
+                        ],
+                        "type": "string",
+                        "name": "check",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "per_page",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler_v1.GuardrailEventListItems"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/health/live": {
+            "get": {
+                "description": "process is up and serving http",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Live",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/health/ready": {
+            "get": {
+                "description": "ready after warm-up preloaded hot kb indexes and verified embedding dimension, 503 with the error otherwise",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Ready",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.WarmupStatus"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.WarmupStatus"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/import_source": {
+            "put": {
+                "description": "update name, target folder, sync interval or connection of import source, an empty token or app secret keeps the saved one",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "import_source"
+                ],
+                "summary": "UpdateImportSource",
+                "parameters": [
+                    {
+                        "description": "import source",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.UpdateImportSourceReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "add a confluence cloud or server space, notion pages and databases shared with an integration, a feishu wiki space or drive folder readable by a custom app, a yuque repo readable by a token, markdown files of a github or gitlab repo, an openapi 3 spec imported as a document for each tag and operation, pages of a website listed by its sitemap or found by following links within a depth and include and exclude rules, or entries of an rss, atom or json feed, credentials are checked by reading them. sources with a sync interval are synced automatically, git sources with a webhook secret also on push events. pages removed from a source are marked stale, their nodes are kept, entries dropped from feeds are not",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "import_source"
+                ],
+                "summary": "CreateImportSource",
+                "parameters": [
+                    {
+                        "description": "import source",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.CreateImportSourceReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.ImportSource"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "delete import source, imported documents are kept",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "import_source"
+                ],
+                "summary": "DeleteImportSource",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/import_source/list": {
+            "get": {
+                "description": "import sources of kb with status of their last sync, without credentials",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "import_source"
+                ],
+                "summary": "GetImportSourceList",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.ImportSource"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/import_source/preview": {
+            "post": {
+                "description": "pages a sync of the saved source, or of new settings, would create, update or leave unchanged and the nodes it would remove, nothing is written",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "import_source"
+                ],
+                "summary": "PreviewImportSource",
+                "parameters": [
+                    {
+                        "description": "saved source id, or type and settings of a new source",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.PreviewImportSourceReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.ImportSourcePreview"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/import_source/stale_pages": {
+            "get": {
+                "description": "imported pages no longer found in the source by the last sync, their nodes are kept until deleted in the kb",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "import_source"
+                ],
+                "summary": "GetImportSourceStalePages",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.ImportSourceStalePage"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/import_source/sync": {
+            "post": {
+                "description": "import new pages and pages changed since the last sync with their attachments in background, synced documents are published",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "import_source"
+                ],
+                "summary": "SyncImportSource",
+                "parameters": [
+                    {
+                        "description": "import source",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.ImportSourceReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.ImportSource"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/ip_rules": {
+            "get": {
+                "description": "cidr allow and deny rules of the admin apis and of the public site apis, every ip is allowed if not set",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ip_rule"
+                ],
+                "summary": "GetIPRules",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.IPRules"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "replace the ip rules, denied ips are rejected first and only allowed ips pass if allow is not empty. rejected if the admin rules would block the ip of the request",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ip_rule"
+                ],
+                "summary": "UpdateIPRules",
+                "parameters": [
+                    {
+                        "description": "ip rules",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.IPRules"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/knowledge_base": {
+            "post": {
+                "description": "CreateKnowledgeBase",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "knowledge_base"
+                ],
+                "summary": "CreateKnowledgeBase",
+                "parameters": [
+                    {
+                        "description": "CreateKnowledgeBase Request",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.CreateKnowledgeBaseReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/knowledge_base/compliance_profiles": {
+            "get": {
+                "description": "built-in content policy profiles selectable per deployment region",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "knowledge_base"
+                ],
+                "summary": "GetComplianceProfiles",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.ComplianceProfile"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/knowledge_base/detail": {
+            "get": {
+                "description": "GetKnowledgeBaseDetail",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "knowledge_base"
+                ],
+                "summary": "GetKnowledgeBaseDetail",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Knowledge Base ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.KnowledgeBaseDetail"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "UpdateKnowledgeBase",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "knowledge_base"
+                ],
+                "summary": "UpdateKnowledgeBase",
+                "parameters": [
+                    {
+                        "description": "UpdateKnowledgeBase Request",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.UpdateKnowledgeBaseReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "DeleteKnowledgeBase",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "knowledge_base"
+                ],
+                "summary": "DeleteKnowledgeBase",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Knowledge Base ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/knowledge_base/index/integrity": {
+            "get": {
+                "description": "compare published nodes with vector store documents of kb and report drift",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "knowledge_base"
+                ],
+                "summary": "CheckIndexIntegrity",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.IndexIntegrityReport"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/knowledge_base/index/repair": {
+            "post": {
+                "description": "re-index nodes missing in vector store and delete orphan documents of kb",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "knowledge_base"
+                ],
+                "summary": "RepairIndex",
+                "parameters": [
+                    {
+                        "description": "index integrity request",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.IndexIntegrityReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.IndexIntegrityReport"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/knowledge_base/list": {
+            "get": {
+                "description": "knowledge bases of the user, all for admins and those with a role for members",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "knowledge_base"
+                ],
+                "summary": "GetKnowledgeBaseList",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.KnowledgeBaseListItem"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/knowledge_base/member": {
+            "put": {
+                "description": "add the user to kb with the role, or change its role. owners manage settings and members, editors edit documents, analysts view conversations and reports, support agents view conversations",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "knowledge_base"
+                ],
+                "summary": "SetKBMember",
+                "parameters": [
+                    {
+                        "description": "set kb member request",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.SetKBMemberReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "remove the role of the user on kb, members without a role can't access the kb",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "knowledge_base"
+                ],
+                "summary": "DeleteKBMember",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "name": "user_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/knowledge_base/member/list": {
+            "get": {
+                "description": "member users of kb with their roles, admins may access every kb without a role",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "knowledge_base"
+                ],
+                "summary": "GetKBMemberList",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.KBMemberListItem"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/knowledge_base/release": {
+            "post": {
+                "description": "CreateKBRelease",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "knowledge_base"
+                ],
+                "summary": "CreateKBRelease",
+                "parameters": [
+                    {
+                        "description": "CreateKBRelease Request",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.CreateKBReleaseReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/knowledge_base/release/list": {
+            "get": {
+                "description": "GetKBReleaseList",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "knowledge_base"
+                ],
+                "summary": "GetKBReleaseList",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Knowledge Base ID",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.GetKBReleaseListResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/maintenance": {
+            "get": {
+                "description": "global read-only mode, and read-only mode of the kb if kb_id is given",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "maintenance"
+                ],
+                "summary": "GetMaintenance",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "kb id",
+                        "name": "kb_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.MaintenanceResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "switch read-only mode globally, or of a kb if kb_id is given",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "maintenance"
+                ],
+                "summary": "UpdateMaintenance",
+                "parameters": [
+                    {
+                        "description": "maintenance settings",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.UpdateMaintenanceReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/model": {
+            "put": {
+                "description": "update model",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "model"
+                ],
+                "parameters": [
+                    {
+                        "description": "update model request",
+                        "name": "model",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.UpdateModelReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "create model",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "model"
+                ],
+                "summary": "create model",
+                "parameters": [
+                    {
+                        "description": "create model request",
+                        "name": "model",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.CreateModelReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/model/budget": {
+            "get": {
+                "description": "get monthly budget settings of model",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "model"
+                ],
+                "summary": "get model budget",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "model id",
+                        "name": "model_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.ModelBudget"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "update monthly budget settings of model",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "model"
+                ],
+                "summary": "update model budget",
+                "parameters": [
+                    {
+                        "description": "model budget",
+                        "name": "model",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.ModelBudget"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/model/check": {
+            "post": {
+                "description": "check model",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "model"
+                ],
+                "summary": "check model",
+                "parameters": [
+                    {
+                        "description": "check model request",
+                        "name": "model",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.CheckModelReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.CheckModelResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/model/check/kb": {
+            "get": {
+                "description": "check connectivity of the chat, embedding and rerank models used by kb, the default models if kb selects none",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "model"
+                ],
+                "summary": "check kb models",
+                "parameters": [
+                    {
+                        "description": "kb id",
+                        "type": "string",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.CheckKBModelsResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/model/check/saved": {
+            "get": {
+                "description": "check connectivity of a configured model",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "model"
+                ],
+                "summary": "check saved model",
+                "parameters": [
+                    {
+                        "description": "model id",
+                        "type": "string",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.CheckModelResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/model/compare": {
+            "post": {
+                "description": "answer one question with 2 or 3 chat models at the same time over the same retrieved documents, with latency, usage and cost of each answer",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "model"
+                ],
+                "summary": "compare models",
+                "parameters": [
+                    {
+                        "description": "compare models request",
+                        "name": "req",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.CompareModelsReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.CompareModelsResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/model/detail": {
+            "get": {
+                "description": "get model detail",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "model"
+                ],
+                "summary": "get model detail",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "model id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.ModelDetailResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/model/list": {
+            "get": {
+                "description": "get model list",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "model"
+                ],
+                "summary": "get model list",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.ModelListItem"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/model/provider/supported": {
+            "get": {
+                "description": "get provider supported model list",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "model"
+                ],
+                "summary": "get provider supported model list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "api_header",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "api_key",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "base_url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "SiliconFlow",
+                            "OpenAI",
+                            "Ollama",
+                            "DeepSeek",
+                            "Moonshot",
+                            "AzureOpenAI",
+                            "BaiZhiCloud",
+                            "Hunyuan",
+                            "BaiLian",
+                            "Volcengine",
+                            "vLLM",
+                            "Other"
+                        ],
+                        "type": "string",
+                        "name": "provider",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "chat",
+                            "embedding",
+                            "rerank",
+                            "asr"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "ModelTypeChat",
+                            "ModelTypeEmbedding",
+                            "ModelTypeRerank",
+                            "ModelTypeASR"
+                        ],
+                        "name": "type",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.GetProviderModelListResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/model/spend": {
+            "get": {
+                "description": "get token usage, spend and budget of chat models in month",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "model"
+                ],
+                "summary": "get model spend",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "month, e.g. 2025-06, default current month",
+                        "name": "month",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.ModelSpendResp"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node": {
+            "post": {
+                "description": "Create Node",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node"
+                ],
+                "summary": "Create Node",
+                "parameters": [
+                    {
+                        "description": "Node",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.CreateNodeReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object",
+                                            "additionalProperties": {
+                                                "type": "string"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/acl": {
+            "put": {
+                "description": "restrict the nodes on the public site and in chat retrieval to logged in viewers or viewer groups, public access removes the restriction. subtree also restricts all descendants",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node"
+                ],
+                "summary": "SetNodeACL",
+                "parameters": [
+                    {
+                        "description": "set acl request",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.SetNodeACLReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/acl/list": {
+            "get": {
+                "description": "restricted nodes of kb with their acls, nodes not listed are public",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node"
+                ],
+                "summary": "GetNodeACLList",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.NodeACLListItem"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/action": {
+            "post": {
+                "description": "Node Action",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node"
+                ],
+                "summary": "Node Action",
+                "parameters": [
+                    {
+                        "description": "Action",
+                        "name": "action",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.NodeActionReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object",
+                                            "additionalProperties": {
+                                                "type": "string"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/attachment": {
+            "delete": {
+                "description": "delete node attachment and its stored object",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node_attachment"
+                ],
+                "summary": "DeleteNodeAttachment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/attachment/list": {
+            "get": {
+                "description": "attachments of node with signed download urls",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node_attachment"
+                ],
+                "summary": "GetNodeAttachmentList",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "name": "node_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.NodeAttachmentListItem"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/attachment/upload": {
+            "post": {
+                "description": "upload file attached to node, stored in private bucket",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node_attachment"
+                ],
+                "summary": "UploadNodeAttachment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "kb id",
+                        "name": "kb_id",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "node id",
+                        "name": "node_id",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.NodeAttachmentListItem"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/backlinks": {
+            "get": {
+                "description": "nodes linking to the node",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node"
+                ],
+                "summary": "Get Node Backlinks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "kb id",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "node id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.NodeBacklinkResp"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/broken_links": {
+            "get": {
+                "description": "internal links of kb pointing to deleted nodes",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node"
+                ],
+                "summary": "Get Broken Node Links",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "kb id",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.BrokenNodeLinkResp"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/comment": {
+            "delete": {
+                "description": "delete comment and its replies",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node_comment"
+                ],
+                "summary": "DeleteNodeComment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/comment/list": {
+            "get": {
+                "description": "comments of kb for moderation, filter status=pending for the moderation queue",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node_comment"
+                ],
+                "summary": "GetNodeCommentList",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "name": "node_id",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "per_page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "approved",
+                            "rejected",
+                            "spam"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "NodeCommentStatusPending",
+                            "NodeCommentStatusApproved",
+                            "NodeCommentStatusRejected",
+                            "NodeCommentStatusSpam"
+                        ],
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler_v1.NodeCommentListItems"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/comment/moderate": {
+            "post": {
+                "description": "approve, reject or mark comments as spam, only approved comments are shown on the site",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node_comment"
+                ],
+                "summary": "ModerateNodeComments",
+                "parameters": [
+                    {
+                        "description": "moderate request",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.ModerateNodeCommentsReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/comment/reply": {
+            "post": {
+                "description": "reply to an approved comment, shown as staff reply on the site",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node_comment"
+                ],
+                "summary": "ReplyNodeComment",
+                "parameters": [
+                    {
+                        "description": "reply request",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.ReplyNodeCommentReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object",
+                                            "additionalProperties": {
+                                                "type": "string"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/defaults": {
+            "get": {
+                "description": "defaults of folder and effective defaults inherited by new child nodes",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node"
+                ],
+                "summary": "Get Node Defaults",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "kb id",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "folder id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.NodeDefaultsResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "set folder defaults inherited by new child nodes",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node"
+                ],
+                "summary": "Update Node Defaults",
+                "parameters": [
+                    {
+                        "description": "defaults",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.UpdateNodeDefaultsReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/detail": {
+            "get": {
+                "description": "Get Node Detail",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node"
+                ],
+                "summary": "Get Node Detail",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.NodeDetailResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update Node Detail",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node"
+                ],
+                "summary": "Update Node Detail",
+                "parameters": [
+                    {
+                        "description": "Node",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.UpdateNodeReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/discard_draft": {
+            "post": {
+                "description": "drop unpublished changes and restore the latest published content",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node"
+                ],
+                "summary": "Discard Node Draft",
+                "parameters": [
+                    {
+                        "description": "node",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.DiscardNodeDraftReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/duplicates/check": {
+            "post": {
+                "description": "find existing documents of any kb identical to documents about to be imported, create with link_to_node_id to link instead of duplicating",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node"
+                ],
+                "summary": "Check Node Duplicates",
+                "parameters": [
+                    {
+                        "description": "contents to import",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.NodeDuplicateCheckReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.NodeDuplicateCheckResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/export": {
+            "post": {
+                "description": "create async job exporting all documents as markdown files with front-matter and their attachments to a zip archive",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node"
+                ],
+                "summary": "CreateExportJob",
+                "parameters": [
+                    {
+                        "description": "export kb",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.NodeExportReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.NodeExportJob"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/export/job": {
+            "get": {
+                "description": "status of export job, with download url of the archive when succeeded",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node"
+                ],
+                "summary": "GetExportJob",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "job_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.NodeExportJob"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/export/jobs": {
+            "get": {
+                "description": "GetExportJobList",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node"
+                ],
+                "summary": "GetExportJobList",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "per_page",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler_v1.NodeExportJobListItems"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/external_link/broken": {
+            "get": {
+                "description": "outbound links of nodes found broken by the daily link check, with last checked time",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node"
+                ],
+                "summary": "GetBrokenExternalLinks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "kb id",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.BrokenExternalLinkResp"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/import/documents": {
+            "post": {
+                "description": "convert pdf, docx and pptx files to documents by the layout aware parsing service, keeping headings and tables, the original file is added as an attachment of its document. files failing to parse are skipped, imported documents are published for indexing",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node"
+                ],
+                "summary": "ImportDocuments",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "pdf, docx or pptx files, the field may be repeated",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "kb id",
+                        "name": "kb_id",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "folder to import into",
+                        "name": "parent_id",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.ImportDocumentsResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/import/markdown": {
+            "post": {
+                "description": "import zip of markdown files with optional front-matter, directories become folders, documents at existing paths are skipped unless overwrite, imported documents are published for indexing",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node"
+                ],
+                "summary": "ImportMarkdownArchive",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "zip archive",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "kb id",
+                        "name": "kb_id",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "folder to import into",
+                        "name": "parent_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "overwrite documents at existing paths",
+                        "name": "overwrite",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.ImportMarkdownArchiveResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/import/media": {
+            "post": {
+                "description": "transcribe an uploaded or downloaded audio or video file by the asr model and create a transcript document, paragraphs are led by their time offsets linked to the moment in the media so answers can cite them. the transcript is published for indexing",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node"
+                ],
+                "summary": "ImportMedia",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "audio or video file, required if url is empty",
+                        "name": "file",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "url of the audio or video to download",
+                        "name": "url",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "kb id",
+                        "name": "kb_id",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "folder to import into",
+                        "name": "parent_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "name of the transcript",
+                        "name": "name",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.ImportMediaResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/list": {
+            "get": {
+                "description": "Get Node List",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node"
+                ],
+                "summary": "Get Node List",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "name": "search",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.NodeListItemResp"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/mark_reviewed": {
+            "post": {
+                "description": "record the content of the nodes is still accurate, they leave the stale report until the given days pass again",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node"
+                ],
+                "summary": "Mark Nodes Reviewed",
+                "parameters": [
+                    {
+                        "description": "mark reviewed request",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.MarkNodesReviewedReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/move": {
+            "post": {
+                "description": "Move Node",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node"
+                ],
+                "summary": "Move Node",
+                "parameters": [
+                    {
+                        "description": "Move Node",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.MoveNodeReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/near_duplicates": {
+            "get": {
+                "description": "pairs of documents with similar content found by the daily detection job, most similar first, to be merged or deleted",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node"
+                ],
+                "summary": "GetNearDuplicates",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "name": "include_dismissed",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.NearDuplicateResp"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/near_duplicates/detect": {
+            "post": {
+                "description": "compare the documents of kb now instead of waiting for the daily job, returns the pairs found",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node"
+                ],
+                "summary": "DetectNearDuplicates",
+                "parameters": [
+                    {
+                        "description": "near-duplicate detect request",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.NearDuplicateDetectReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.NearDuplicateResp"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/near_duplicates/dismiss": {
+            "post": {
+                "description": "keep a pair found not redundant out of the report, it stays dismissed while it is found again",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node"
+                ],
+                "summary": "DismissNearDuplicate",
+                "parameters": [
+                    {
+                        "description": "near-duplicate dismiss request",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.NearDuplicateDismissReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/owner": {
+            "put": {
+                "description": "assign the owner and review interval to the nodes, the owner is reminded when they are due for review. empty owner_id removes the owner",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node"
+                ],
+                "summary": "SetNodeOwner",
+                "parameters": [
+                    {
+                        "description": "set owner request",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.SetNodeOwnerReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/owner/overdue": {
+            "get": {
+                "description": "owned documents neither updated nor reviewed within their review interval, by owner, most overdue first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node"
+                ],
+                "summary": "GetOverdueNodes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "all owners if empty",
+                        "type": "string",
+                        "name": "owner_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.OwnerOverdueNodes"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/publish": {
+            "post": {
+                "description": "publish drafts of nodes and create a release",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node"
+                ],
+                "summary": "Publish Nodes",
+                "parameters": [
+                    {
+                        "description": "nodes",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.PublishNodeReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object",
+                                            "additionalProperties": {
+                                                "type": "string"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/published": {
+            "get": {
+                "description": "content of node served by the public site and rag index",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node"
+                ],
+                "summary": "Get Published Node",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "node id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.PublishedNodeResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/recommend_nodes": {
+            "get": {
+                "description": "Recommend Nodes",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node"
+                ],
+                "summary": "Recommend Nodes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "name": "node_ids",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.RecommendNodeListResp"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/replace": {
+            "post": {
+                "description": "create async find and replace job, versions of changed nodes are saved for rollback",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node"
+                ],
+                "summary": "CreateReplaceJob",
+                "parameters": [
+                    {
+                        "description": "find and replace",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.NodeReplaceReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.NodeReplaceJob"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/replace/job": {
+            "get": {
+                "description": "GetReplaceJob",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node"
+                ],
+                "summary": "GetReplaceJob",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "job_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.NodeReplaceJob"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/replace/jobs": {
+            "get": {
+                "description": "GetReplaceJobList",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node"
+                ],
+                "summary": "GetReplaceJobList",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "per_page",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler_v1.NodeReplaceJobListItems"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/replace/preview": {
+            "post": {
+                "description": "nodes and samples affected by find and replace, nothing is changed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node"
+                ],
+                "summary": "PreviewReplace",
+                "parameters": [
+                    {
+                        "description": "find and replace",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.NodeReplaceReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.NodeReplacePreviewResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/replace/rollback": {
+            "post": {
+                "description": "restore content of nodes changed by the job, nodes edited after the job are skipped",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node"
+                ],
+                "summary": "RollbackReplaceJob",
+                "parameters": [
+                    {
+                        "description": "replace job",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.NodeReplaceJobReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.NodeReplaceRollbackResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/review/approve": {
+            "post": {
+                "description": "approve review, the reviewed version of node is published and re-indexed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node_review"
+                ],
+                "summary": "ApproveNodeReview",
+                "parameters": [
+                    {
+                        "description": "approve request",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.ApproveNodeReviewReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/review/detail": {
+            "get": {
+                "description": "review detail",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node_review"
+                ],
+                "summary": "GetNodeReview",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "kb id",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "review id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.NodeReview"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/review/list": {
+            "get": {
+                "description": "reviews of kb, newest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node_review"
+                ],
+                "summary": "GetNodeReviewList",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "name": "node_id",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "per_page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "approved",
+                            "rejected",
+                            "cancelled"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "NodeReviewStatusPending",
+                            "NodeReviewStatusApproved",
+                            "NodeReviewStatusRejected",
+                            "NodeReviewStatusCancelled"
+                        ],
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler_v1.NodeReviewListItems"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/review/reject": {
+            "post": {
+                "description": "reject review, comment is required",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node_review"
+                ],
+                "summary": "RejectNodeReview",
+                "parameters": [
+                    {
+                        "description": "reject request",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.RejectNodeReviewReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/review/submit": {
+            "post": {
+                "description": "submit current version of node for review, earlier pending reviews of the node are cancelled",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node_review"
+                ],
+                "summary": "SubmitNodeReview",
+                "parameters": [
+                    {
+                        "description": "submit request",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.SubmitNodeReviewReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.NodeReview"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/rewrite_import_links": {
+            "post": {
+                "description": "point links between imported pages at their new nodes, called after all pages of an import are created",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node"
+                ],
+                "summary": "Rewrite Import Links",
+                "parameters": [
+                    {
+                        "description": "source url to node map",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.RewriteImportLinksReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.RewriteImportLinksResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/stale": {
+            "get": {
+                "description": "documents of kb neither updated nor reviewed in the given days, ordered by age or by recent views to find docs read a lot but old",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node"
+                ],
+                "summary": "Get Stale Nodes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 3650,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "default: NodeStaleDefaultDays",
+                        "name": "days",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "age",
+                            "views"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "NodeStaleOrderAge",
+                            "NodeStaleOrderViews"
+                        ],
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "per_page",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler_v1.NodeStaleListItems"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/summary": {
+            "post": {
+                "description": "Summary Node",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node"
+                ],
+                "summary": "Summary Node",
+                "parameters": [
+                    {
+                        "description": "Summary Node",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.NodeSummaryReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/template": {
+            "put": {
+                "description": "update node template, built-in templates are read-only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node_template"
+                ],
+                "summary": "UpdateNodeTemplate",
+                "parameters": [
+                    {
+                        "description": "template",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.UpdateNodeTemplateReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "create node template, {{name}} placeholders in names and content are filled on use",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node_template"
+                ],
+                "summary": "CreateNodeTemplate",
+                "parameters": [
+                    {
+                        "description": "template",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.CreateNodeTemplateReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object",
+                                            "additionalProperties": {
+                                                "type": "string"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "delete node template, built-in templates are read-only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node_template"
+                ],
+                "summary": "DeleteNodeTemplate",
+                "parameters": [
+                    {
+                        "description": "template",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.DeleteNodeTemplateReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/template/create_node": {
+            "post": {
+                "description": "copy structure and placeholder content of template into a new node",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node_template"
+                ],
+                "summary": "CreateNodeFromTemplate",
+                "parameters": [
+                    {
+                        "description": "create request",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.CreateNodeFromTemplateReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object",
+                                            "additionalProperties": {
+                                                "type": "string"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/template/detail": {
+            "get": {
+                "description": "node template detail",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node_template"
+                ],
+                "summary": "GetNodeTemplate",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "kb id",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "template id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.NodeTemplate"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/node/template/list": {
+            "get": {
+                "description": "built-in templates followed by templates of kb",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node_template"
+                ],
+                "summary": "GetNodeTemplateList",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "kb id",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.NodeTemplateListItem"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/onboarding/checklist": {
+            "get": {
+                "description": "GetChecklist",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "onboarding"
+                ],
+                "summary": "GetChecklist",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "kb_id",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.OnboardingChecklistResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/onboarding/starter_kb": {
+            "post": {
+                "description": "CreateStarterKB",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "onboarding"
+                ],
+                "summary": "CreateStarterKB",
+                "parameters": [
+                    {
+                        "description": "CreateStarterKB Request",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.CreateStarterKBReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.CreateStarterKBResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/reader": {
+            "post": {
+                "description": "create a reader account of kb, readers log in to the public site with email and password",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reader"
+                ],
+                "summary": "CreateReader",
+                "parameters": [
+                    {
+                        "description": "create reader request",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.CreateReaderReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.CreateReaderResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "update name, groups, status and password of a reader, changes apply to tokens already issued",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reader"
+                ],
+                "summary": "UpdateReader",
+                "parameters": [
+                    {
+                        "description": "update reader request",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.UpdateReaderReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "delete reader account, its tokens are rejected afterwards",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reader"
+                ],
+                "summary": "DeleteReader",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/reader/list": {
+            "get": {
+                "description": "reader accounts of kb, newest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reader"
+                ],
+                "summary": "GetReaderList",
+                "parameters": [
+                    {
+                        "description": "readers in the group only if set",
+                        "type": "string",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "per_page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "part of email or name",
+                        "type": "string",
+                        "name": "search",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler_v1.ReaderListItems"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/retention": {
+            "get": {
+                "description": "days conversations, messages, stat events, job runs, audit logs, guardrail events, anomalies and revoked sessions are kept, 0 keeps them forever",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "retention"
+                ],
+                "summary": "GetRetention",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.RetentionSettings"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "data older than its retention is removed by the hourly retention job",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "retention"
+                ],
+                "summary": "UpdateRetention",
+                "parameters": [
+                    {
+                        "description": "retention settings",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.RetentionSettings"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/sandbox/eval": {
+            "post": {
+                "description": "answer the questions from the sandbox kb as chat would with its chat model and the built-in prompt, check the retrieved documents against the expected documents and confidence and score the answers with the model as judge, passed tells the pipeline whether to fail",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sandbox"
+                ],
+                "summary": "EvalSandbox",
+                "parameters": [
+                    {
+                        "description": "sandbox eval request",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.SandboxEvalReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.SandboxEvalResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/sandbox/kb": {
+            "post": {
+                "description": "create temporary kb of the api token, torn down when it expires if not deleted",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sandbox"
+                ],
+                "summary": "CreateSandbox",
+                "parameters": [
+                    {
+                        "description": "create sandbox request",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.CreateSandboxReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.SandboxKB"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "DeleteSandbox",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sandbox"
+                ],
+                "summary": "DeleteSandbox",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "sandbox kb id",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/sandbox/kb/list": {
+            "get": {
+                "description": "GetSandboxList",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sandbox"
+                ],
+                "summary": "GetSandboxList",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.SandboxKB"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/sandbox/nodes": {
+            "post": {
+                "description": "create documents in the sandbox kb and publish them, poll the status until they are indexed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sandbox"
+                ],
+                "summary": "PushSandboxNodes",
+                "parameters": [
+                    {
+                        "description": "push sandbox nodes request",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.PushSandboxNodesReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.PushSandboxNodesResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/sandbox/status": {
+            "get": {
+                "description": "how many published documents of the sandbox kb are indexed, ready when all are",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sandbox"
+                ],
+                "summary": "GetSandboxStatus",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "sandbox kb id",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.SandboxStatusResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/stat/answer_confidence": {
+            "get": {
+                "description": "daily count of answers and low confidence answers replied with reference links only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stat"
+                ],
+                "summary": "GetAnswerConfidenceStat",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "unix timestamp, default: now",
+                        "name": "end_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "unix timestamp, default: 7d ago",
+                        "name": "start_time",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.AnswerConfidenceStatResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/stat/browsers": {
+            "get": {
+                "description": "GetBrowsers",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stat"
+                ],
+                "summary": "GetBrowsers",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "kb_id",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/stat/conversation_distribution": {
+            "get": {
+                "description": "GetConversationDistribution",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stat"
+                ],
+                "summary": "GetConversationDistribution",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "kb_id",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/stat/count": {
+            "get": {
+                "description": "GetCount",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stat"
+                ],
+                "summary": "GetCount",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "kb_id",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/stat/funnel": {
+            "get": {
+                "description": "sessions and conversion rates of each funnel step",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stat"
+                ],
+                "summary": "GetFunnelStat",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "unix timestamp, default: now",
+                        "name": "end_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "unix timestamp, default: 7d ago",
+                        "name": "start_time",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.FunnelStepStat"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/stat/geo": {
+            "get": {
+                "description": "visitor locations in time range, grouped as country \u003e province \u003e city name/value tree for map charts",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stat"
+                ],
+                "summary": "GetGeoStat",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "optional, all apps if empty",
+                        "name": "app_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "unix timestamp, default: now",
+                        "name": "end_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "unix timestamp, default: 24h ago",
+                        "name": "start_time",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.GeoStatResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/stat/geo_count": {
+            "get": {
+                "description": "GetGeoCount",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stat"
+                ],
+                "summary": "GetGeoCount",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "kb_id",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/stat/hot_pages": {
+            "get": {
+                "description": "GetHotPages",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stat"
+                ],
+                "summary": "GetHotPages",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "kb_id",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/stat/instant_count": {
+            "get": {
+                "description": "GetInstantCount",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stat"
+                ],
+                "summary": "GetInstantCount",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "kb_id",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/stat/instant_pages": {
+            "get": {
+                "description": "GetInstantPages",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stat"
+                ],
+                "summary": "GetInstantPages",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "kb_id",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/stat/nodes": {
+            "get": {
+                "description": "views, unique visitors, average dwell time and rag citations of nodes",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stat"
+                ],
+                "summary": "GetNodeStats",
+                "parameters": [
+                    {
+                        "maximum": 90,
+                        "type": "integer",
+                        "description": "default: 7",
+                        "name": "days",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "optional, all nodes if empty",
+                        "name": "node_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.NodeStatResp"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/stat/platforms": {
+            "get": {
+                "description": "device, browser and os breakdown of conversations, widget conversations of last 7 days by default",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stat"
+                ],
+                "summary": "GetPlatformStat",
+                "parameters": [
+                    {
+                        "enum": [
+                            1,
+                            2,
+                            3,
+                            4,
+                            5,
+                            6,
+                            7
+                        ],
+                        "type": "integer",
+                        "x-enum-varnames": [
+                            "AppTypeWeb",
+                            "AppTypeWidget",
+                            "AppTypeDingTalkBot",
+                            "AppTypeFeishuBot",
+                            "AppTypeWechatBot",
+                            "AppTypeWechatServiceBot",
+                            "AppTypeDisCordBot"
+                        ],
+                        "description": "conversations of the app type, widget by default",
+                        "name": "app_type",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 90,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "last days, 7 by default",
+                        "name": "days",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.PlatformStatResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/stat/question_clusters": {
+            "get": {
+                "description": "get top question clusters of kb in time range",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stat"
+                ],
+                "summary": "GetQuestionClusters",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "unix timestamp, default: now",
+                        "name": "end_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "default: 10, max: 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "unix timestamp, default: 24h ago",
+                        "name": "start_time",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.QuestionClusterResp"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/stat/realtime": {
+            "get": {
+                "description": "push active conversations, requests per minute and geo breakdown every 5 seconds",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "stat"
+                ],
+                "summary": "GetRealtimeStat",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "kb_id",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.StatRealtimeResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/stat/referer_hosts": {
+            "get": {
+                "description": "GetRefererHosts",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stat"
+                ],
+                "summary": "GetRefererHosts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "kb_id",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/stat/traffic_sources": {
+            "get": {
+                "description": "get page visits or conversations of last 24 hours grouped by referer host or utm parameter",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stat"
+                ],
+                "summary": "GetTrafficSources",
+                "parameters": [
+                    {
+                        "enum": [
+                            "referer_host",
+                            "utm_source",
+                            "utm_medium",
+                            "utm_campaign",
+                            "utm_term",
+                            "utm_content"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "TrafficSourceDimensionRefererHost",
+                            "TrafficSourceDimensionUTMSource",
+                            "TrafficSourceDimensionUTMMedium",
+                            "TrafficSourceDimensionUTMCampaign",
+                            "TrafficSourceDimensionUTMTerm",
+                            "TrafficSourceDimensionUTMContent"
+                        ],
+                        "name": "dimension",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "page",
+                            "conversation"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "TrafficSourceTypePage",
+                            "TrafficSourceTypeConversation"
+                        ],
+                        "name": "type",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.TrafficSourceCount"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/telemetry": {
+            "get": {
+                "description": "whether anonymous usage is aggregated locally and reported upstream, both are off until enabled",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "telemetry"
+                ],
+                "summary": "GetTelemetrySettings",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.TelemetrySettingsResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "reporting upstream requires telemetry enabled, usage aggregated so far is removed when telemetry is disabled",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "telemetry"
+                ],
+                "summary": "UpdateTelemetrySettings",
+                "parameters": [
+                    {
+                        "description": "telemetry settings",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.UpdateTelemetrySettingsReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/telemetry/report": {
+            "get": {
+                "description": "anonymous feature usage, error rates and size of the deployment over the last 7 days, exactly what is sent upstream when reporting is enabled",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "telemetry"
+                ],
+                "summary": "GetTelemetryReport",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.TelemetryReport"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user": {
+            "get": {
+                "description": "GetUser",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "GetUser",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.UserInfoResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/2fa": {
+            "get": {
+                "description": "two-factor authentication of the current user and whether the policy requires it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "GetTwoFactorStatus",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.TwoFactorStatusResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "turn off two-factor authentication of the current user, rejected if the policy requires it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "DisableTwoFactor",
+                "parameters": [
+                    {
+                        "description": "code of the authenticator app or a backup code",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.TwoFactorCodeReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/2fa/backup_codes": {
+            "post": {
+                "description": "replace the backup codes of the current user, unused ones stop working",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "RegenerateBackupCodes",
+                "parameters": [
+                    {
+                        "description": "code of the authenticator app or a backup code",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.TwoFactorCodeReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.TwoFactorConfirmResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/2fa/confirm": {
+            "post": {
+                "description": "enable the enrollment of the current user with the first code, return the backup codes",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "ConfirmTwoFactor",
+                "parameters": [
+                    {
+                        "description": "code of the authenticator app",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.TwoFactorCodeReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.TwoFactorConfirmResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/2fa/enroll": {
+            "post": {
+                "description": "new secret of the authenticator app of the current user, enabled by confirming its first code",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "EnrollTwoFactor",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.TwoFactorEnrollResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/2fa/login": {
+            "post": {
+                "description": "console token after the password, with a code of the authenticator app or a backup code",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "TwoFactorLogin",
+                "parameters": [
+                    {
+                        "description": "two-factor login request",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.TwoFactorLoginReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.LoginResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/2fa/login/confirm": {
+            "post": {
+                "description": "enable the enrollment started at login with the first code, return the backup codes and the console token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "TwoFactorLoginConfirm",
+                "parameters": [
+                    {
+                        "description": "two-factor login request",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.TwoFactorLoginReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.TwoFactorConfirmResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/2fa/login/enroll": {
+            "post": {
+                "description": "secret of the authenticator app of a user who must enroll before logging in",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "TwoFactorLoginEnroll",
+                "parameters": [
+                    {
+                        "description": "two-factor challenge",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.TwoFactorChallengeReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.TwoFactorEnrollResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/2fa/reset": {
+            "delete": {
+                "description": "remove two-factor authentication of a user who lost its authenticator app and backup codes, only for admins",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "ResetTwoFactor",
+                "parameters": [
+                    {
+                        "description": "reset two-factor request",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.ResetTwoFactorReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/create": {
+            "post": {
+                "description": "CreateUser",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "CreateUser",
+                "parameters": [
+                    {
+                        "description": "CreateUser Request",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.CreateUserReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/delete": {
+            "delete": {
+                "description": "DeleteUser",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "DeleteUser",
+                "parameters": [
+                    {
+                        "description": "DeleteUser Request",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.DeleteUserReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/list": {
+            "get": {
+                "description": "ListUsers",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "ListUsers",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.UserListItemResp"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/login": {
+            "post": {
+                "description": "token of the console, or a challenge token and the two-factor step left to log in",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Login",
+                "parameters": [
+                    {
+                        "description": "Login Request",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.LoginReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.LoginResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/oidc/callback": {
+            "get": {
+                "description": "finish the login with the code of the oidc provider, redirect to the login page of the console with the token, the two-factor challenge or the error in the fragment",
+                "tags": [
+                    "user"
+                ],
+                "summary": "OIDCCallback",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "code",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "error",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "error_description",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "state",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "302": {
+                        "description": "Found"
+                    }
+                }
+            }
+        },
+        "/api/v1/user/oidc/login": {
+            "get": {
+                "description": "redirect to the login page of the oidc provider, which redirects back to the callback",
+                "tags": [
+                    "user"
+                ],
+                "summary": "OIDCLogin",
+                "parameters": [
+                    {
+                        "description": "path of the console opened after login, / if not set",
+                        "type": "string",
+                        "name": "redirect",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "302": {
+                        "description": "Found"
+                    }
+                }
+            }
+        },
+        "/api/v1/user/oidc/status": {
+            "get": {
+                "description": "whether the login page offers single sign-on and password login",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "GetOIDCStatus",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.OIDCStatusResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/reset_password": {
+            "put": {
+                "description": "ResetPassword",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "ResetPassword",
+                "parameters": [
+                    {
+                        "description": "ResetPassword Request",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.ResetPasswordReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/role": {
+            "put": {
+                "description": "change the global role of another user, admins may access every kb and members only the kbs they are added to",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "UpdateUserRole",
+                "parameters": [
+                    {
+                        "description": "UpdateUserRole Request",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.UpdateUserRoleReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/session": {
+            "delete": {
+                "description": "log out the session at once, members may only revoke their own sessions",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "RevokeSession",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/session/list": {
+            "get": {
+                "description": "active console sessions with their device and ip, admins get those of every user, members only get their own",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "GetSessionList",
+                "parameters": [
+                    {
+                        "description": "sessions of the user, every user for admins if empty, members only get their own",
+                        "type": "string",
+                        "name": "user_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.SessionListItem"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/session/revoke_all": {
+            "post": {
+                "description": "log out every session of the user at once, like after its credentials leaked, members may only revoke their own sessions",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "RevokeUserSessions",
+                "parameters": [
+                    {
+                        "description": "revoke user sessions request",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.RevokeUserSessionsReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/webhook": {
+            "put": {
+                "description": "update webhook",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "UpdateWebhook",
+                "parameters": [
+                    {
+                        "description": "webhook",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.UpdateWebhookReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "create webhook, payload is the raw event unless a template or jsonpath mapping is given",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "CreateWebhook",
+                "parameters": [
+                    {
+                        "description": "webhook",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.CreateWebhookReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object",
+                                            "additionalProperties": {
+                                                "type": "string"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "delete webhook",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "DeleteWebhook",
+                "parameters": [
+                    {
+                        "description": "webhook",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.DeleteWebhookReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/webhook/list": {
+            "get": {
+                "description": "webhooks of kb",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "GetWebhookList",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "kb id",
+                        "name": "kb_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.Webhook"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/webhook/preview": {
+            "post": {
+                "description": "render payload of template or jsonpath mapping with a sample event",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "PreviewWebhook",
+                "parameters": [
+                    {
+                        "description": "payload settings",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.PreviewWebhookReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.PreviewWebhookResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/share/v1/app/web/info": {
+            "get": {
+                "description": "GetAppInfo",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "share_app"
+                ],
+                "summary": "GetAppInfo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "kb id",
+                        "name": "X-KB-ID",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/share/v1/chat/feedback": {
+            "post": {
+                "description": "like or dislike an answer of the conversation, the latest answer if message_id is empty, rating again replaces the previous one",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "share_chat"
+                ],
+                "summary": "SubmitFeedback",
+                "parameters": [
+                    {
+                        "description": "request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.MessageFeedbackReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/share/v1/chat/message": {
+            "post": {
+                "description": "ChatMessage",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "share_chat"
+                ],
+                "summary": "ChatMessage",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "app type",
+                        "name": "app_type",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.ChatRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/share/v1/chat/prefetch": {
+            "post": {
+                "description": "called by the widget while the user is typing, debounced, to retrieve documents of the draft question in advance. a message sent with the same question, ignoring case, spaces and trailing punctuation, reuses them within 2 minutes",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "share_chat"
+                ],
+                "summary": "PrefetchRetrieval",
+                "parameters": [
+                    {
+                        "description": "request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.PrefetchRetrievalReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.PrefetchRetrievalResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/share/v1/chat/transcript_email": {
+            "post": {
+                "description": "send a copy of the conversation with reference links to the email of end user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "share_chat"
+                ],
+                "summary": "SendTranscriptEmail",
+                "parameters": [
+                    {
+                        "description": "request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.SendTranscriptEmailReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/share/v1/comment": {
+            "post": {
+                "description": "comment on published node, pending or spam comments are shown after approved",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "share_comment"
+                ],
+                "summary": "CreateNodeComment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "kb id",
+                        "name": "X-KB-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.CreateNodeCommentReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.CreateNodeCommentResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/share/v1/comment/list": {
+            "get": {
+                "description": "approved comment threads of published node",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "share_comment"
+                ],
+                "summary": "GetNodeComments",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "kb id",
+                        "name": "X-KB-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "node id",
+                        "name": "node_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.ShareNodeComment"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/share/v1/import_source/git/webhook": {
+            "post": {
+                "description": "webhook of a git import source, push events of its branch start a sync. github webhooks are signed with the webhook secret, gitlab webhooks send it as secret token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "share_import_source"
+                ],
+                "summary": "GitWebhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "kb id",
+                        "name": "X-KB-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "import source id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.GitWebhookResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/share/v1/node/attachment/list": {
+            "get": {
+                "description": "attachments of published node with signed download urls",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "share_node"
+                ],
+                "summary": "GetNodeAttachmentList",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "kb id",
+                        "name": "X-KB-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "node id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.NodeAttachmentListItem"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/share/v1/node/detail": {
+            "get": {
+                "description": "GetNodeDetail",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "share_node"
+                ],
+                "summary": "GetNodeDetail",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "kb id",
+                        "name": "X-KB-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "node id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/share/v1/node/list": {
+            "get": {
+                "description": "GetNodeList",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "share_node"
+                ],
+                "summary": "GetNodeList",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "kb id",
+                        "name": "X-KB-ID",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/share/v1/reader/info": {
+            "get": {
+                "description": "viewer identified by the X-Viewer-Token header, a reader account or a user of the sso portal. anonymous viewers have no user id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "share_reader"
+                ],
+                "summary": "GetViewerInfo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "kb id",
+                        "name": "X-KB-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "viewer token",
+                        "name": "X-Viewer-Token",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.NodeViewer"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/share/v1/reader/login": {
+            "post": {
+                "description": "log in a reader account, the token is sent as X-Viewer-Token header to the share apis",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "share_reader"
+                ],
+                "summary": "Login",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "kb id",
+                        "name": "X-KB-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.ReaderLoginReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.ReaderLoginResp"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/share/v1/search": {
+            "get": {
+                "description": "keyword search of published documents, name matches first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "share_search"
+                ],
+                "summary": "SearchNodes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "kb id",
+                        "name": "X-KB-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "maximum": 50,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.NodeSearchResult"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/share/v1/search/complete": {
+            "get": {
+                "description": "completions of a partial query from document titles and popular questions for the search box, tolerating typos",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "share_search"
+                ],
+                "summary": "GetSearchCompletions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "kb id",
+                        "name": "X-KB-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "maximum": 20,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.SearchCompletion"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/share/v1/search/fulltext": {
+            "get": {
+                "description": "full-text search of published documents ranked by relevance, with highlighted name and content snippets",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "share_search"
+                ],
+                "summary": "FullTextSearchNodes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "kb id",
+                        "name": "X-KB-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "per_page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/domain.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler_share.FullTextSearchResults"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/share/v1/search/suggest": {
+            "get": {
+                "description": "search suggestions of browsers in opensearch suggestions format",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "share_search"
+                ],
+                "summary": "GetSearchSuggestions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "kb id",
+                        "name": "X-KB-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "search terms",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {}
+                        }
+                    }
+                }
+            }
+        },
+        "/share/v1/stat/dwell": {
+            "post": {
+                "description": "RecordDwell",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "share_stat"
+                ],
+                "summary": "RecordDwell",
+                "parameters": [
+                    {
+                        "description": "request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.StatNodeDwellReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/share/v1/stat/funnel": {
+            "post": {
+                "description": "RecordFunnelEvent",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "share_stat"
+                ],
+                "summary": "RecordFunnelEvent",
+                "parameters": [
+                    {
+                        "description": "request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.StatFunnelEventReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/share/v1/stat/page": {
+            "post": {
+                "description": "RecordPage",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "share_stat"
+                ],
+                "summary": "RecordPage",
+                "parameters": [
+                    {
+                        "description": "request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.StatPageReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Response"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "domain.APIToken": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "never expires if nil",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kb_id": {
+                    "description": "the only kb a service token may access, any kb if empty",
+                    "type": "string"
+                },
+                "kind": {
+                    "$ref": "#/definitions/domain.APITokenKind"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "max_sandboxes": {
+                    "description": "sandbox kbs the token may keep at the same time",
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "description": "first characters of the token to recognize it",
+                    "type": "string"
+                },
+                "rotated_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.APITokenScope"
+                    }
+                }
+            }
+        },
+        "domain.APITokenKind": {
+            "type": "string",
+            "enum": [
+                "personal",
+                "service"
+            ],
+            "x-enum-comments": {
+                "APITokenKindPersonal": "acts as the user who created it with its current roles, limited to the scopes of the token",
+                "APITokenKindService": "created by admins for integrations, acts as an admin limited to the scopes and kb of the token"
+            },
+            "x-enum-varnames": [
+                "APITokenKindPersonal",
+                "APITokenKindService"
+            ]
+        },
+        "domain.APITokenScope": {
+            "type": "string",
+            "enum": [
+                "sandbox",
+                "nodes:read",
+                "nodes:write",
+                "conversations:read",
+                "stats:read"
+            ],
+            "x-enum-comments": {
+                "APITokenScopeConversationsRead": "read conversations and their messages",
+                "APITokenScopeNodesRead": "read nodes of kbs",
+                "APITokenScopeNodesWrite": "create, update, move and delete nodes and publish releases, also allows reading nodes",
+                "APITokenScopeSandbox": "create sandbox kbs, push documents, evaluate questions and tear them down, production kbs are never visible",
+                "APITokenScopeStatsRead": "read statistics, gap reports and digests"
+            },
+            "x-enum-varnames": [
+                "APITokenScopeSandbox",
+                "APITokenScopeNodesRead",
+                "APITokenScopeNodesWrite",
+                "APITokenScopeConversationsRead",
+                "APITokenScopeStatsRead"
+            ]
+        },
+        "domain.AccessSettings": {
+            "type": "object",
+            "properties": {
+                "base_url": {
+                    "type": "string"
+                },
+                "hosts": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "ports": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "private_key": {
+                    "type": "string"
+                },
+                "public_key": {
+                    "type": "string"
+                },
+                "reader_access": {
+                    "description": "restrict the public site to reader accounts or sso users",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ReaderAccess"
+                        }
+                    ]
+                },
+                "simple_auth": {
+                    "$ref": "#/definitions/domain.SimpleAuth"
+                },
+                "ssl_ports": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "viewer_auth": {
+                    "description": "identify viewers of the public site for node acls",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ViewerAuth"
+                        }
+                    ]
+                }
+            }
+        },
+        "domain.AnomalyIPCount": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "ip_address": {
+                    "$ref": "#/definitions/domain.IPAddress"
+                },
+                "remote_ip": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.AnomalyListItem": {
+            "type": "object",
+            "properties": {
+                "app_id": {
+                    "type": "string"
+                },
+                "blocked": {
+                    "description": "block of the ip is still in effect",
+                    "type": "boolean"
+                },
+                "blocked_until": {
+                    "type": "string"
+                },
+                "count": {
+                    "description": "hits counted in the window when detected",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "detail": {
+                    "description": "sample of the offending question",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "ip_address": {
+                    "$ref": "#/definitions/domain.IPAddress"
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "remote_ip": {
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/domain.AnomalyType"
+                }
+            }
+        },
+        "domain.AnomalyReport": {
+            "type": "object",
+            "properties": {
+                "blocked": {
+                    "description": "ips whose block is still in effect",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ConversationAnomaly"
+                    }
+                },
+                "days": {
+                    "type": "integer"
+                },
+                "top_ips": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.AnomalyIPCount"
+                    }
+                },
+                "types": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.AnomalyTypeCount"
+                    }
+                }
+            }
+        },
+        "domain.AnomalySettings": {
+            "type": "object",
+            "properties": {
+                "block_minutes": {
+                    "type": "integer"
+                },
+                "disabled": {
+                    "type": "boolean"
+                },
+                "max_input_length": {
+                    "description": "max runes of a question",
+                    "type": "integer"
+                },
+                "questions_per_hour": {
+                    "type": "integer"
+                },
+                "repeat_limit": {
+                    "description": "identical questions of an ip in 10 minutes",
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.AnomalyType": {
+            "type": "string",
+            "enum": [
+                "question_storm",
+                "long_input",
+                "repeated_prompt"
+            ],
+            "x-enum-varnames": [
+                "AnomalyTypeQuestionStorm",
+                "AnomalyTypeLongInput",
+                "AnomalyTypeRepeatedPrompt"
+            ]
+        },
+        "domain.AnomalyTypeCount": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "type": {
+                    "$ref": "#/definitions/domain.AnomalyType"
+                }
+            }
+        },
+        "domain.AnswerCacheSettings": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "min_similarity": {
+                    "description": "min cosine similarity of question embeddings, 0.95 when not set",
+                    "type": "number",
+                    "maximum": 1,
+                    "minimum": 0
+                },
+                "ttl_hours": {
+                    "description": "hours answers are served from the cache, 24 when not set",
+                    "type": "integer",
+                    "maximum": 720,
+                    "minimum": 0
+                }
+            }
+        },
+        "domain.AnswerConfidenceCount": {
+            "type": "object",
+            "properties": {
+                "answered": {
+                    "type": "integer"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "low_confidence": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.AnswerConfidenceStatResp": {
+            "type": "object",
+            "properties": {
+                "answered": {
+                    "type": "integer"
+                },
+                "daily": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.AnswerConfidenceCount"
+                    }
+                },
+                "low_confidence": {
+                    "type": "integer"
+                },
+                "low_rate": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.AnswerFormat": {
+            "type": "string",
+            "enum": [
+                "markdown",
+                "plain"
+            ],
+            "x-enum-varnames": [
+                "AnswerFormatMarkdown",
+                "AnswerFormatPlain"
+            ]
+        },
+        "domain.AnswerPipelineSettings": {
+            "type": "object",
+            "properties": {
+                "format": {
+                    "description": "target format of format, default markdown",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.AnswerFormat"
+                        }
+                    ]
+                },
+                "max_length": {
+                    "description": "max runes of length_trim",
+                    "type": "integer"
+                },
+                "steps": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.AnswerStepType"
+                    }
+                }
+            }
+        },
+        "domain.AnswerProvenance": {
+            "type": "object",
+            "properties": {
+                "chunk_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "generated_at": {
+                    "type": "string"
+                },
+                "kb_id": {
+                    "description": "latest release of kb when the answer was generated, empty if kb was never released",
+                    "type": "string"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "node_ids": {
+                    "description": "retrieved documents and chunks in the prompt, by rank",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "provider": {
+                    "description": "chat model of the answer, empty on low confidence replies which are not generated",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ModelProvider"
+                        }
+                    ]
+                },
+                "release_id": {
+                    "type": "string"
+                },
+                "release_tag": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.AnswerSettings": {
+            "type": "object",
+            "properties": {
+                "cache": {
+                    "$ref": "#/definitions/domain.AnswerCacheSettings"
+                },
+                "confidence_gate": {
+                    "$ref": "#/definitions/domain.ConfidenceGateSettings"
+                },
+                "retrieval": {
+                    "$ref": "#/definitions/domain.RetrievalSettings"
+                }
+            }
+        },
+        "domain.AnswerStepType": {
+            "type": "string",
+            "enum": [
+                "citation_validation",
+                "pii_scrub",
+                "format",
+                "length_trim",
+                "disclaimer"
+            ],
+            "x-enum-varnames": [
+                "AnswerStepCitationValidation",
+                "AnswerStepPIIScrub",
+                "AnswerStepFormat",
+                "AnswerStepLengthTrim",
+                "AnswerStepDisclaimer"
+            ]
+        },
+        "domain.AppDetailResp": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "recommend_nodes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.RecommendNodeListResp"
+                    }
+                },
+                "settings": {
+                    "$ref": "#/definitions/domain.AppSettingsResp"
+                },
+                "type": {
+                    "$ref": "#/definitions/domain.AppType"
+                }
+            }
+        },
+        "domain.AppPromptTemplate": {
+            "type": "object",
+            "properties": {
+                "app_id": {
+                    "type": "string"
+                },
+                "content": {
+                    "description": "empty resets the app to the built-in prompt",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "note": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "from 1 per app",
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.AppSettings": {
+            "type": "object",
+            "properties": {
+                "answer_pipeline": {
+                    "description": "post-processing steps of answers",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.AnswerPipelineSettings"
+                        }
+                    ]
+                },
+                "auto_sitemap": {
+                    "type": "boolean"
+                },
+                "body_code": {
+                    "type": "string"
+                },
+                "btns": {
+                    "type": "array",
+                    "items": {}
+                },
+                "catalog_settings": {
+                    "description": "catalog settings",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.CatalogSettings"
+                        }
+                    ]
+                },
+                "chit_chat": {
+                    "description": "canned replies of greetings and small talk",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ChitChatSettings"
+                        }
+                    ]
+                },
+                "citation": {
+                    "description": "presentation of answer sources",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.CitationSettings"
+                        }
+                    ]
+                },
+                "continuation": {
+                    "description": "continuation of answers cut off by the max tokens of the model",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ContinuationSettings"
+                        }
+                    ]
+                },
+                "conversation_title": {
+                    "description": "titles of new conversations",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ConversationTitleSettings"
+                        }
+                    ]
+                },
+                "desc": {
+                    "description": "seo",
+                    "type": "string"
+                },
+                "dingtalk_bot_client_id": {
+                    "description": "DingTalkBot",
+                    "type": "string"
+                },
+                "dingtalk_bot_client_secret": {
+                    "type": "string"
+                },
+                "dingtalk_bot_template_id": {
+                    "type": "string"
+                },
+                "discord_bot_token": {
+                    "description": "DisCordBot",
+                    "type": "string"
+                },
+                "feishu_bot_app_id": {
+                    "description": "FeishuBot",
+                    "type": "string"
+                },
+                "feishu_bot_app_secret": {
+                    "type": "string"
+                },
+                "follow_up": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.FollowUpSettings"
+                        }
+                    ],
+                    "description": "follow-up questions suggested after answers"
+                },
+                "footer_settings": {
+                    "description": "footer settings",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.FooterSettings"
+                        }
+                    ]
+                },
+                "gap_report": {
+                    "description": "weekly gap report pushed to DingTalk/Feishu group",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.GapReportSettings"
+                        }
+                    ]
+                },
+                "guardrails": {
+                    "$ref": "#/definitions/domain.GuardrailSettings"
+                },
+                "head_code": {
+                    "description": "inject code",
+                    "type": "string"
+                },
+                "icon": {
+                    "type": "string"
+                },
+                "keyword": {
+                    "type": "string"
+                },
+                "provenance": {
+                    "description": "provenance metadata of answers",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ProvenanceSettings"
+                        }
+                    ]
+                },
+                "recommend_node_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "recommend_questions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "search_placeholder": {
+                    "type": "string"
+                },
+                "theme_and_style": {
+                    "$ref": "#/definitions/domain.ThemeAndStyle"
+                },
+                "theme_mode": {
+                    "description": "theme",
+                    "type": "string"
+                },
+                "title": {
+                    "description": "nav",
+                    "type": "string"
+                },
+                "wechat_app_agent_id": {
+                    "type": "string"
+                },
+                "wechat_app_corpid": {
+                    "type": "string"
+                },
+                "wechat_app_encodingaeskey": {
+                    "type": "string"
+                },
+                "wechat_app_secret": {
+                    "type": "string"
+                },
+                "wechat_app_token": {
+                    "description": "WechatAppBot",
+                    "type": "string"
+                },
+                "wechat_service_corpid": {
+                    "type": "string"
+                },
+                "wechat_service_encodingaeskey": {
+                    "type": "string"
+                },
+                "wechat_service_secret": {
+                    "type": "string"
+                },
+                "wechat_service_token": {
+                    "description": "WechatServiceBot",
+                    "type": "string"
+                },
+                "welcome_str": {
+                    "description": "welcome",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.AppSettingsResp": {
+            "type": "object",
+            "properties": {
+                "answer_pipeline": {
+                    "description": "post-processing steps of answers",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.AnswerPipelineSettings"
+                        }
+                    ]
+                },
+                "auto_sitemap": {
+                    "type": "boolean"
+                },
+                "body_code": {
+                    "type": "string"
+                },
+                "btns": {
+                    "type": "array",
+                    "items": {}
+                },
+                "catalog_settings": {
+                    "description": "catalog settings",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.CatalogSettings"
+                        }
+                    ]
+                },
+                "chit_chat": {
+                    "description": "canned replies of greetings and small talk",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ChitChatSettings"
+                        }
+                    ]
+                },
+                "citation": {
+                    "description": "presentation of answer sources",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.CitationSettings"
+                        }
+                    ]
+                },
+                "continuation": {
+                    "description": "continuation of answers cut off by the max tokens of the model",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ContinuationSettings"
+                        }
+                    ]
+                },
+                "conversation_title": {
+                    "description": "titles of new conversations",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ConversationTitleSettings"
+                        }
+                    ]
+                },
+                "desc": {
+                    "description": "seo",
+                    "type": "string"
+                },
+                "dingtalk_bot_client_id": {
+                    "description": "DingTalkBot",
+                    "type": "string"
+                },
+                "dingtalk_bot_client_secret": {
+                    "type": "string"
+                },
+                "dingtalk_bot_template_id": {
+                    "type": "string"
+                },
+                "discord_bot_token": {
+                    "description": "DisCordBot",
+                    "type": "string"
+                },
+                "feishu_bot_app_id": {
+                    "description": "FeishuBot",
+                    "type": "string"
+                },
+                "feishu_bot_app_secret": {
+                    "type": "string"
+                },
+                "follow_up": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.FollowUpSettings"
+                        }
+                    ],
+                    "description": "follow-up questions suggested after answers"
+                },
+                "footer_settings": {
+                    "description": "footer settings",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.FooterSettings"
+                        }
+                    ]
+                },
+                "gap_report": {
+                    "description": "weekly gap report pushed to DingTalk/Feishu group",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.GapReportSettings"
+                        }
+                    ]
+                },
+                "guardrails": {
+                    "$ref": "#/definitions/domain.GuardrailSettings"
+                },
+                "head_code": {
+                    "description": "inject code",
+                    "type": "string"
+                },
+                "icon": {
+                    "type": "string"
+                },
+                "keyword": {
+                    "type": "string"
+                },
+                "provenance": {
+                    "description": "provenance metadata of answers",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ProvenanceSettings"
+                        }
+                    ]
+                },
+                "recommend_node_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "recommend_questions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "search_placeholder": {
+                    "type": "string"
+                },
+                "theme_and_style": {
+                    "$ref": "#/definitions/domain.ThemeAndStyle"
+                },
+                "theme_mode": {
+                    "description": "theme",
+                    "type": "string"
+                },
+                "title": {
+                    "description": "nav",
+                    "type": "string"
+                },
+                "wechat_app_agent_id": {
+                    "type": "string"
+                },
+                "wechat_app_corpid": {
+                    "type": "string"
+                },
+                "wechat_app_encodingaeskey": {
+                    "type": "string"
+                },
+                "wechat_app_secret": {
+                    "type": "string"
+                },
+                "wechat_app_token": {
+                    "description": "WechatAppBot",
+                    "type": "string"
+                },
+                "wechat_service_corpid": {
+                    "type": "string"
+                },
+                "wechat_service_encodingaeskey": {
+                    "type": "string"
+                },
+                "wechat_service_secret": {
+                    "type": "string"
+                },
+                "wechat_service_token": {
+                    "description": "WechatServiceBot",
+                    "type": "string"
+                },
+                "welcome_str": {
+                    "description": "welcome",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.AppType": {
+            "type": "integer",
+            "enum": [
+                1,
+                2,
+                3,
+                4,
+                5,
+                6,
+                7
+            ],
+            "x-enum-varnames": [
+                "AppTypeWeb",
+                "AppTypeWidget",
+                "AppTypeDingTalkBot",
+                "AppTypeFeishuBot",
+                "AppTypeWechatBot",
+                "AppTypeWechatServiceBot",
+                "AppTypeDisCordBot"
+            ]
+        },
+        "domain.ApproveNodeReviewReq": {
+            "type": "object",
+            "required": [
+                "id",
+                "kb_id"
+            ],
+            "properties": {
+                "comment": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kb_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.AuditLog": {
+            "type": "object",
+            "properties": {
+                "actor_account": {
+                    "description": "account of the actor when the action was taken",
+                    "type": "string"
+                },
+                "actor_id": {
+                    "type": "string"
+                },
+                "after": {
+                    "description": "row after the action, the request body if the resource has no snapshot",
+                    "type": "object"
+                },
+                "api_token_id": {
+                    "description": "api token the action was taken with, empty for console logins",
+                    "type": "string"
+                },
+                "before": {
+                    "description": "row before the action, empty if the resource has no snapshot or did not exist",
+                    "type": "object"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "hash": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "prev_hash": {
+                    "type": "string"
+                },
+                "remote_ip": {
+                    "type": "string"
+                },
+                "resource_id": {
+                    "type": "string"
+                },
+                "resource_type": {
+                    "type": "string"
+                },
+                "route": {
+                    "description": "matched route of the api, like /api/v1/node/detail",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.AuditLogListItem": {
+            "type": "object",
+            "properties": {
+                "actor_account": {
+                    "type": "string"
+                },
+                "actor_id": {
+                    "type": "string"
+                },
+                "api_token_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "remote_ip": {
+                    "type": "string"
+                },
+                "resource_id": {
+                    "type": "string"
+                },
+                "resource_type": {
+                    "type": "string"
+                },
+                "route": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.AuditLogVerifyResp": {
+            "type": "object",
+            "properties": {
+                "anchor_id": {
+                    "description": "last entry removed by retention, the chain is verified from its hash. 0 if none was removed",
+                    "type": "integer"
+                },
+                "broken_id": {
+                    "description": "first entry which was edited or follows a deleted entry, 0 if valid",
+                    "type": "integer"
+                },
+                "checked": {
+                    "description": "entries checked, up to the first broken one",
+                    "type": "integer"
+                },
+                "valid": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "domain.BannedPhrase": {
+            "type": "object",
+            "properties": {
+                "phrase": {
+                    "type": "string"
+                },
+                "replacement": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.BotProfile": {
+            "type": "object",
+            "properties": {
+                "avatar": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.BotProfileSyncResult": {
+            "type": "object",
+            "properties": {
+                "app_id": {
+                    "type": "string"
+                },
+                "app_name": {
+                    "type": "string"
+                },
+                "app_type": {
+                    "$ref": "#/definitions/domain.AppType"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "fields": {
+                    "description": "fields pushed to the channel",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.BotProfileSyncStatus"
+                }
+            }
+        },
+        "domain.BotProfileSyncStatus": {
+            "type": "string",
+            "enum": [
+                "synced",
+                "unsupported",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "BotProfileSyncStatusSynced",
+                "BotProfileSyncStatusUnsupported",
+                "BotProfileSyncStatusFailed"
+            ]
+        },
+        "domain.BrandGroup": {
+            "type": "object",
+            "properties": {
+                "links": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Link"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.BrandingSettings": {
+            "type": "object",
+            "properties": {
+                "auto_sync": {
+                    "description": "push profile to channels when kb name or branding changes",
+                    "type": "boolean"
+                },
+                "avatar": {
+                    "description": "image url, /static-file path or data uri",
+                    "type": "string"
+                },
+                "bot_name": {
+                    "description": "kb name if empty",
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "emoji": {
+                    "description": "prefix of the bot name, e.g. 📘",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.BrokenExternalLinkResp": {
+            "type": "object",
+            "properties": {
+                "checked_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "node_id": {
+                    "type": "string"
+                },
+                "node_name": {
+                    "type": "string"
+                },
+                "status_code": {
+                    "type": "integer"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.BrokenNodeLinkResp": {
+            "type": "object",
+            "properties": {
+                "source_id": {
+                    "type": "string"
+                },
+                "source_name": {
+                    "type": "string"
+                },
+                "target_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.BrowserCount": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.BudgetExceededAction": {
+            "type": "string",
+            "enum": [
+                "disable",
+                "fallback"
+            ],
+            "x-enum-varnames": [
+                "BudgetExceededActionDisable",
+                "BudgetExceededActionFallback"
+            ]
+        },
+        "domain.CatalogSettings": {
+            "type": "object",
+            "properties": {
+                "catalog_folder": {
+                    "description": "1: 展开, 2: 折叠, default: 1",
+                    "type": "integer"
+                },
+                "catalog_visible": {
+                    "description": "1: 显示, 2: 隐藏, default: 1",
+                    "type": "integer"
+                },
+                "catalog_width": {
+                    "description": "200 - 300, default: 260",
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.ChatRequest": {
+            "type": "object",
+            "required": [
+                "app_type",
+                "message"
+            ],
+            "properties": {
+                "app_type": {
+                    "enum": [
+                        1,
+                        2,
+                        3
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.AppType"
+                        }
+                    ]
+                },
+                "conversation_id": {
+                    "type": "string"
+                },
+                "locale": {
+                    "description": "language of the user for the locale variable of prompt templates, the Accept-Language header if not set",
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "nonce": {
+                    "type": "string"
+                },
+                "referer": {
+                    "description": "document.referrer and location.href of the widget host page, for traffic attribution",
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.CheckKBModelsResp": {
+            "type": "object",
+            "properties": {
+                "chat": {
+                    "$ref": "#/definitions/domain.KBModelCheck"
+                },
+                "embedding": {
+                    "$ref": "#/definitions/domain.KBModelCheck"
+                },
+                "rerank": {
+                    "$ref": "#/definitions/domain.KBModelCheck"
+                }
+            }
+        },
+        "domain.CheckModelReq": {
+            "type": "object",
+            "required": [
+                "base_url",
+                "model",
+                "provider",
+                "type"
+            ],
+            "properties": {
+                "api_header": {
+                    "type": "string"
+                },
+                "api_key": {
+                    "type": "string"
+                },
+                "api_version": {
+                    "description": "for azure openai",
+                    "type": "string"
+                },
+                "base_url": {
+                    "type": "string"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "priority": {
+                    "description": "failover pool of chat models, 0 keeps the model out of the pool",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "provider": {
+                    "enum": [
+                        "OpenAI",
+                        "Ollama",
+                        "DeepSeek",
+                        "SiliconFlow",
+                        "Moonshot",
+                        "Other",
+                        "AzureOpenAI",
+                        "BaiZhiCloud",
+                        "Hunyuan",
+                        "BaiLian",
+                        "Volcengine",
+                        "vLLM"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ModelProvider"
+                        }
+                    ]
+                },
+                "type": {
+                    "enum": [
+                        "chat",
+                        "embedding",
+                        "rerank"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ModelType"
+                        }
+                    ]
+                },
+                "weight": {
+                    "description": "share of requests among pooled models of the same priority, 1 if 0",
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "domain.CheckModelResp": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ChitChatSettings": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "replies": {
+                    "description": "replies by kind of small talk, the default reply of the kind if not set",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "domain.CitationSettings": {
+            "type": "object",
+            "properties": {
+                "style": {
+                    "enum": [
+                        "inline",
+                        "footnote",
+                        "cards",
+                        "none"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.CitationStyle"
+                        }
+                    ]
+                }
+            }
+        },
+        "domain.CitationStyle": {
+            "type": "string",
+            "enum": [
+                "inline",
+                "footnote",
+                "cards",
+                "none"
+            ],
+            "x-enum-varnames": [
+                "CitationStyleInline",
+                "CitationStyleFootnote",
+                "CitationStyleCards",
+                "CitationStyleNone"
+            ]
+        },
+        "domain.ClientPlatform": {
+            "type": "object",
+            "properties": {
+                "browser": {
+                    "type": "string"
+                },
+                "device": {
+                    "$ref": "#/definitions/domain.DeviceType"
+                },
+                "os": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.CommentSettings": {
+            "type": "object",
+            "properties": {
+                "blocked_words": {
+                    "description": "comments containing any of the words are spam",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "require_moderation": {
+                    "description": "comments are pending until approved in the moderation queue",
+                    "type": "boolean"
+                }
+            }
+        },
+        "domain.CompareModel": {
+            "type": "object",
+            "properties": {
+                "api_header": {
+                    "type": "string"
+                },
+                "api_key": {
+                    "type": "string"
+                },
+                "api_version": {
+                    "type": "string"
+                },
+                "base_url": {
+                    "type": "string"
+                },
+                "completion_price": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "model": {
+                    "type": "string"
+                },
+                "model_id": {
+                    "type": "string"
+                },
+                "prompt_price": {
+                    "description": "price per 1M tokens, prices of the budget of configured models if 0",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "provider": {
+                    "$ref": "#/definitions/domain.ModelProvider"
+                }
+            }
+        },
+        "domain.CompareModelResult": {
+            "type": "object",
+            "properties": {
+                "answer": {
+                    "type": "string"
+                },
+                "completion_tokens": {
+                    "type": "integer"
+                },
+                "cost": {
+                    "type": "number"
+                },
+                "error": {
+                    "description": "answer until the model failed",
+                    "type": "string"
+                },
+                "first_token_ms": {
+                    "description": "time to the first token and to the whole answer",
+                    "type": "integer"
+                },
+                "latency_ms": {
+                    "type": "integer"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "model_id": {
+                    "type": "string"
+                },
+                "prompt_tokens": {
+                    "type": "integer"
+                },
+                "provider": {
+                    "$ref": "#/definitions/domain.ModelProvider"
+                },
+                "total_tokens": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.CompareModelsReq": {
+            "type": "object",
+            "required": [
+                "kb_id",
+                "models",
+                "question"
+            ],
+            "properties": {
+                "citation_style": {
+                    "description": "citation style of the prompt, inline by default",
+                    "enum": [
+                        "inline",
+                        "footnote",
+                        "cards",
+                        "none"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.CitationStyle"
+                        }
+                    ]
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "models": {
+                    "type": "array",
+                    "maxItems": 3,
+                    "minItems": 2,
+                    "items": {
+                        "$ref": "#/definitions/domain.CompareModel"
+                    }
+                },
+                "question": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.CompareModelsResp": {
+            "type": "object",
+            "properties": {
+                "documents": {
+                    "description": "documents retrieved once and given to all models",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.NodeCotentChunkSSE"
+                    }
+                },
+                "question": {
+                    "type": "string"
+                },
+                "results": {
+                    "description": "results in the order of the requested models",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.CompareModelResult"
+                    }
+                }
+            }
+        },
+        "domain.ComplianceDisclaimer": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string"
+                },
+                "keywords": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ComplianceProfile": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "region": {
+                    "$ref": "#/definitions/domain.ComplianceRegion"
+                },
+                "settings": {
+                    "$ref": "#/definitions/domain.ComplianceSettings"
+                }
+            }
+        },
+        "domain.ComplianceRegion": {
+            "type": "string",
+            "enum": [
+                "",
+                "cn",
+                "eu",
+                "us"
+            ],
+            "x-enum-varnames": [
+                "ComplianceRegionNone",
+                "ComplianceRegionCN",
+                "ComplianceRegionEU",
+                "ComplianceRegionUS"
+            ]
+        },
+        "domain.ComplianceSettings": {
+            "type": "object",
+            "properties": {
+                "banned_phrases": {
+                    "description": "phrases the model must never output, like competitor or deprecated product names",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.BannedPhrase"
+                    }
+                },
+                "blocked_reply": {
+                    "type": "string"
+                },
+                "disabled_topics": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ComplianceTopic"
+                    }
+                },
+                "disclaimers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ComplianceDisclaimer"
+                    }
+                },
+                "region": {
+                    "description": "built-in profile of the deployment region, merged with the custom rules below",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ComplianceRegion"
+                        }
+                    ]
+                },
+                "stop_sequences": {
+                    "description": "generation stops at any of them, passed to the model",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "domain.ComplianceTopic": {
+            "type": "object",
+            "properties": {
+                "keywords": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ConfidenceGateSettings": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "min_groundedness": {
+                    "description": "min share of question terms found in retrieved documents, 0-1",
+                    "type": "number",
+                    "maximum": 1,
+                    "minimum": 0
+                },
+                "min_retrieval_score": {
+                    "description": "min similarity of the best retrieved chunk, 0-1",
+                    "type": "number",
+                    "maximum": 1,
+                    "minimum": 0
+                }
+            }
+        },
+        "domain.ConfluenceSettings": {
+            "type": "object",
+            "required": [
+                "base_url",
+                "space_key"
+            ],
+            "properties": {
+                "base_url": {
+                    "description": "site url, e.g. https://example.atlassian.net/wiki",
+                    "type": "string"
+                },
+                "space_key": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "username": {
+                    "description": "account email of cloud api tokens, empty for personal access tokens of server",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ContinuationSettings": {
+            "type": "object",
+            "properties": {
+                "max_rounds": {
+                    "description": "continuation requests after a truncated answer, 0 disables continuation. 2 if not set",
+                    "type": "integer",
+                    "maximum": 5,
+                    "minimum": 0
+                }
+            }
+        },
+        "domain.ConversationAnomaly": {
+            "type": "object",
+            "properties": {
+                "app_id": {
+                    "type": "string"
+                },
+                "blocked_until": {
+                    "type": "string"
+                },
+                "count": {
+                    "description": "hits counted in the window when detected",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "detail": {
+                    "description": "sample of the offending question",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "remote_ip": {
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/domain.AnomalyType"
+                }
+            }
+        },
+        "domain.ConversationDetailResp": {
+            "type": "object",
+            "properties": {
+                "app_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "historical": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "ip_address": {
+                    "$ref": "#/definitions/domain.IPAddress"
+                },
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ConversationMessage"
+                    }
+                },
+                "references": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ConversationReference"
+                    }
+                },
+                "remote_ip": {
+                    "type": "string"
+                },
+                "subject": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ConversationInfo": {
+            "type": "object",
+            "properties": {
+                "historical": {
+                    "description": "helpdesk ticket of historical conversations",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.HistoricalInfo"
+                        }
+                    ]
+                },
+                "platform": {
+                    "description": "device, browser and os of web and widget visitors",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ClientPlatform"
+                        }
+                    ]
+                },
+                "source": {
+                    "$ref": "#/definitions/domain.TrafficSource"
+                },
+                "user_info": {
+                    "$ref": "#/definitions/domain.UserInfo"
+                }
+            }
+        },
+        "domain.ConversationListItem": {
+            "type": "object",
+            "properties": {
+                "app_name": {
+                    "type": "string"
+                },
+                "app_type": {
+                    "$ref": "#/definitions/domain.AppType"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "historical": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "ip_address": {
+                    "$ref": "#/definitions/domain.IPAddress"
+                },
+                "remote_ip": {
+                    "type": "string"
+                },
+                "subject": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ConversationMessage": {
+            "type": "object",
+            "properties": {
+                "app_id": {
+                    "type": "string"
+                },
+                "completion_tokens": {
+                    "type": "integer"
+                },
+                "confidence": {
+                    "description": "retrieval confidence of assistant answer, low confidence answers are replied with reference links only",
+                    "type": "number"
+                },
+                "content": {
+                    "type": "string"
+                },
+                "conversation_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "failovers": {
+                    "description": "endpoints skipped after they timed out, were rate limited or failed before answering",
+                    "type": "integer"
+                },
+                "feedback_comment": {
+                    "type": "string"
+                },
+                "feedback_type": {
+                    "description": "rating of the answer by the end user",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.MessageFeedbackType"
+                        }
+                    ]
+                },
+                "follow_ups": {
+                    "description": "follow-up questions suggested after the answer, nil if the app does not suggest them",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.FollowUpQuestion"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "low_confidence": {
+                    "type": "boolean"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "model_id": {
+                    "type": "string"
+                },
+                "prompt_template_version": {
+                    "description": "version of the prompt template of the app the answer was generated with, 0 for the built-in prompt",
+                    "type": "integer"
+                },
+                "prompt_tokens": {
+                    "type": "integer"
+                },
+                "provenance": {
+                    "description": "where the answer came from, recorded if provenance is enabled for the app",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.AnswerProvenance"
+                        }
+                    ]
+                },
+                "provider": {
+                    "description": "model, of the endpoint which served the answer",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ModelProvider"
+                        }
+                    ]
+                },
+                "remote_ip": {
+                    "description": "stats",
+                    "type": "string"
+                },
+                "rerank_scores": {
+                    "description": "scores of the documents of the answer before and after the rerank stage, nil if the kb does not rerank",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.RerankScore"
+                    }
+                },
+                "retrieval_query": {
+                    "description": "query the documents of the answer were retrieved with, empty if the question was not rewritten",
+                    "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/schema.RoleType"
+                },
+                "route": {
+                    "description": "how the question was answered, empty on questions and answers saved before routing",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.QuestionRoute"
+                        }
+                    ]
+                },
+                "status": {
+                    "description": "streaming answers are checkpointed, partial answers stay streaming if the server stops",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.MessageStatus"
+                        }
+                    ]
+                },
+                "total_tokens": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ConversationReference": {
+            "type": "object",
+            "properties": {
+                "app_id": {
+                    "type": "string"
+                },
+                "conversation_id": {
+                    "type": "string"
+                },
+                "message_id": {
+                    "description": "answer listing the reference, empty for references saved before answers were tracked",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "node_id": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ConversationRescoreJob": {
+            "type": "object",
+            "properties": {
+                "answer_settings": {
+                    "description": "candidate settings of the job, nil for the settings of kb when it runs",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.AnswerSettings"
+                        }
+                    ]
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "description": "admin who started the job",
+                    "type": "string"
+                },
+                "days": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "result": {
+                    "$ref": "#/definitions/domain.ConversationRescoreResult"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.NodeExportJobStatus"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ConversationRescoreReq": {
+            "type": "object",
+            "required": [
+                "kb_id"
+            ],
+            "properties": {
+                "answer_settings": {
+                    "description": "retrieval and confidence gate settings to evaluate, the current settings of kb if not set",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.AnswerSettings"
+                        }
+                    ]
+                },
+                "days": {
+                    "description": "answers of the recent days, 7 if not set",
+                    "type": "integer",
+                    "maximum": 90,
+                    "minimum": 1
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "limit": {
+                    "description": "most recent answers re-scored, 200 if not set",
+                    "type": "integer",
+                    "maximum": 500,
+                    "minimum": 1
+                }
+            }
+        },
+        "domain.ConversationRescoreResult": {
+            "type": "object",
+            "properties": {
+                "after": {
+                    "$ref": "#/definitions/domain.ConversationRescoreScores"
+                },
+                "answer_count": {
+                    "type": "integer"
+                },
+                "before": {
+                    "$ref": "#/definitions/domain.ConversationRescoreScores"
+                },
+                "dislike_count": {
+                    "type": "integer"
+                },
+                "disliked_gated": {
+                    "type": "integer"
+                },
+                "disliked_retrieval_changed": {
+                    "type": "integer"
+                },
+                "failed_count": {
+                    "description": "answers skipped because retrieval failed",
+                    "type": "integer"
+                },
+                "like_count": {
+                    "type": "integer"
+                },
+                "liked_gated": {
+                    "description": "rated answers which would be gated, likes lost and dislikes avoided",
+                    "type": "integer"
+                },
+                "newly_answered": {
+                    "description": "low confidence answers which would be answered, and answers which would be replied with reference links only",
+                    "type": "integer"
+                },
+                "newly_gated": {
+                    "type": "integer"
+                },
+                "retrieval_changed": {
+                    "description": "answers whose best document is not one they referenced, and how many of them were disliked",
+                    "type": "integer"
+                },
+                "samples": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ConversationRescoreSample"
+                    }
+                }
+            }
+        },
+        "domain.ConversationRescoreSample": {
+            "type": "object",
+            "properties": {
+                "conversation_id": {
+                    "type": "string"
+                },
+                "feedback_type": {
+                    "$ref": "#/definitions/domain.MessageFeedbackType"
+                },
+                "low_confidence": {
+                    "type": "boolean"
+                },
+                "message_id": {
+                    "type": "string"
+                },
+                "new_groundedness": {
+                    "type": "number"
+                },
+                "new_low_confidence": {
+                    "description": "low confidence under the settings of the job",
+                    "type": "boolean"
+                },
+                "new_node_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "new_retrieval_score": {
+                    "type": "number"
+                },
+                "question": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ConversationRescoreScores": {
+            "type": "object",
+            "properties": {
+                "avg_groundedness": {
+                    "description": "share of question terms found in retrieved documents, only known for re-scored answers",
+                    "type": "number"
+                },
+                "avg_retrieval_score": {
+                    "type": "number"
+                },
+                "low_confidence_count": {
+                    "type": "integer"
+                },
+                "low_confidence_rate": {
+                    "type": "number"
+                },
+                "satisfaction_rate": {
+                    "description": "likes of rated answers, after the settings rated answers which would be gated are left out",
+                    "type": "number"
+                }
+            }
+        },
+        "domain.ConversationStreamItem": {
+            "type": "object",
+            "properties": {
+                "app_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "historical": {
+                    "description": "imported from a legacy helpdesk, not a chat with an app",
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "info": {
+                    "$ref": "#/definitions/domain.ConversationInfo"
+                },
+                "is_bot": {
+                    "type": "boolean"
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ConversationMessage"
+                    }
+                },
+                "nonce": {
+                    "type": "string"
+                },
+                "references": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ConversationReference"
+                    }
+                },
+                "remote_ip": {
+                    "type": "string"
+                },
+                "subject": {
+                    "description": "subject for conversation, now is first question",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ConversationTitleMode": {
+            "type": "string",
+            "enum": [
+                "heuristic",
+                "llm"
+            ],
+            "x-enum-comments": {
+                "ConversationTitleModeHeuristic": "first sentence of the first question, cleaned up and truncated",
+                "ConversationTitleModeLLM": "summarized by the chat model after the first answer, the heuristic title is kept until then or if it fails"
+            },
+            "x-enum-varnames": [
+                "ConversationTitleModeHeuristic",
+                "ConversationTitleModeLLM"
+            ]
+        },
+        "domain.ConversationTitleSettings": {
+            "type": "object",
+            "properties": {
+                "mode": {
+                    "description": "heuristic if not set",
+                    "enum": [
+                        "heuristic",
+                        "llm"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ConversationTitleMode"
+                        }
+                    ]
+                }
+            }
+        },
+        "domain.ConversationTranscriptEmail": {
+            "type": "object",
+            "properties": {
+                "conversation_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "remote_ip": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.TranscriptEmailStatus"
+                }
+            }
+        },
+        "domain.CreateAPITokenReq": {
+            "type": "object",
+            "required": [
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "expires_in_days": {
+                    "description": "days until the token expires, never if not set",
+                    "type": "integer",
+                    "maximum": 365,
+                    "minimum": 1
+                },
+                "kb_id": {
+                    "description": "limit a service token to the kb",
+                    "type": "string"
+                },
+                "kind": {
+                    "description": "personal if not set",
+                    "enum": [
+                        "personal",
+                        "service"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.APITokenKind"
+                        }
+                    ]
+                },
+                "max_sandboxes": {
+                    "description": "5 if not set",
+                    "type": "integer",
+                    "maximum": 50,
+                    "minimum": 1
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "scopes": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/domain.APITokenScope"
+                    }
+                }
+            }
+        },
+        "domain.CreateAPITokenResp": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "never expires if nil",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kb_id": {
+                    "description": "the only kb a service token may access, any kb if empty",
+                    "type": "string"
+                },
+                "kind": {
+                    "$ref": "#/definitions/domain.APITokenKind"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "max_sandboxes": {
+                    "description": "sandbox kbs the token may keep at the same time",
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "description": "first characters of the token to recognize it",
+                    "type": "string"
+                },
+                "rotated_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.APITokenScope"
+                    }
+                },
+                "token": {
+                    "description": "the token, shown only once",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.CreateEvalQuestionsReq": {
+            "type": "object",
+            "required": [
+                "kb_id",
+                "questions",
+                "set_id"
+            ],
+            "properties": {
+                "kb_id": {
+                    "type": "string"
+                },
+                "questions": {
+                    "type": "array",
+                    "maxItems": 200,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/domain.EvalQuestionInput"
+                    }
+                },
+                "set_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.CreateEvalRunReq": {
+            "type": "object",
+            "required": [
+                "kb_id",
+                "set_id"
+            ],
+            "properties": {
+                "app_id": {
+                    "description": "app whose prompt template and citation style are evaluated, the built-in prompt if empty",
+                    "type": "string"
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "note": {
+                    "type": "string",
+                    "maxLength": 200
+                },
+                "set_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.CreateEvalSetReq": {
+            "type": "object",
+            "required": [
+                "kb_id",
+                "name"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                }
+            }
+        },
+        "domain.CreateGlossaryTermReq": {
+            "type": "object",
+            "required": [
+                "definition",
+                "kb_id",
+                "term"
+            ],
+            "properties": {
+                "aliases": {
+                    "type": "array",
+                    "maxItems": 20,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "definition": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "node_id": {
+                    "type": "string"
+                },
+                "term": {
+                    "type": "string",
+                    "maxLength": 100
+                }
+            }
+        },
+        "domain.CreateImportSourceReq": {
+            "type": "object",
+            "required": [
+                "kb_id",
+                "type"
+            ],
+            "properties": {
+                "confluence": {
+                    "$ref": "#/definitions/domain.ConfluenceSettings"
+                },
+                "feed": {
+                    "$ref": "#/definitions/domain.FeedSettings"
+                },
+                "feishu": {
+                    "$ref": "#/definitions/domain.FeishuSettings"
+                },
+                "git": {
+                    "$ref": "#/definitions/domain.GitSettings"
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "name": {
+                    "description": "name of the space or the first page if empty",
+                    "type": "string"
+                },
+                "notion": {
+                    "$ref": "#/definitions/domain.NotionSettings"
+                },
+                "openapi": {
+                    "$ref": "#/definitions/domain.OpenAPISettings"
+                },
+                "parent_id": {
+                    "type": "string"
+                },
+                "sync_interval": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "type": {
+                    "enum": [
+                        "confluence",
+                        "notion",
+                        "feishu",
+                        "yuque",
+                        "git",
+                        "openapi",
+                        "website",
+                        "feed"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ImportSourceType"
+                        }
+                    ]
+                },
+                "website": {
+                    "$ref": "#/definitions/domain.WebsiteSettings"
+                },
+                "yuque": {
+                    "$ref": "#/definitions/domain.YuqueSettings"
+                }
+            }
+        },
+        "domain.CreateKBReleaseReq": {
+            "type": "object",
+            "required": [
+                "kb_id",
+                "message",
+                "tag"
+            ],
+            "properties": {
+                "kb_id": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "node_ids": {
+                    "description": "create release after these nodes published",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "tag": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.CreateKnowledgeBaseReq": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "hosts": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "ports": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "private_key": {
+                    "type": "string"
+                },
                 "public_key": {
                     "type": "string"
                 },
-                "simple_auth": {
-                    "$ref": "#/definitions/domain.SimpleAuth"
-                },
-                "ssl_ports": {
-                    "type": "array",
-                    "items": {
-                        "type": "integer"
-                    }
+                "ssl_ports": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "domain.CreateModelReq": {
+            "type": "object",
+            "required": [
+                "base_url",
+                "model",
+                "provider",
+                "type"
+            ],
+            "properties": {
+                "api_header": {
+                    "type": "string"
+                },
+                "api_key": {
+                    "type": "string"
+                },
+                "api_version": {
+                    "description": "for azure openai",
+                    "type": "string"
+                },
+                "base_url": {
+                    "type": "string"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "priority": {
+                    "description": "failover pool of chat models, 0 keeps the model out of the pool",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "provider": {
+                    "enum": [
+                        "OpenAI",
+                        "Ollama",
+                        "DeepSeek",
+                        "SiliconFlow",
+                        "Moonshot",
+                        "Other",
+                        "AzureOpenAI",
+                        "BaiZhiCloud",
+                        "Hunyuan",
+                        "BaiLian",
+                        "Volcengine",
+                        "vLLM"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ModelProvider"
+                        }
+                    ]
+                },
+                "type": {
+                    "enum": [
+                        "chat",
+                        "embedding",
+                        "rerank"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ModelType"
+                        }
+                    ]
+                },
+                "weight": {
+                    "description": "share of requests among pooled models of the same priority, 1 if 0",
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "domain.CreateNodeCommentReq": {
+            "type": "object",
+            "required": [
+                "content",
+                "node_id"
+            ],
+            "properties": {
+                "content": {
+                    "type": "string",
+                    "maxLength": 2000
+                },
+                "nick_name": {
+                    "type": "string",
+                    "maxLength": 50
+                },
+                "node_id": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.CreateNodeCommentResp": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.NodeCommentStatus"
+                }
+            }
+        },
+        "domain.CreateNodeFromTemplateReq": {
+            "type": "object",
+            "required": [
+                "kb_id",
+                "template_id"
+            ],
+            "properties": {
+                "kb_id": {
+                    "type": "string"
+                },
+                "name": {
+                    "description": "name of the new node, the template name with placeholders filled if empty",
+                    "type": "string"
+                },
+                "parent_id": {
+                    "type": "string"
+                },
+                "template_id": {
+                    "type": "string"
+                },
+                "variables": {
+                    "description": "values of placeholders, {{title}} and {{date}} are filled by default",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "domain.CreateNodeReq": {
+            "type": "object",
+            "required": [
+                "kb_id",
+                "name",
+                "type"
+            ],
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "emoji": {
+                    "type": "string"
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "link_to_node_id": {
+                    "description": "link to this existing identical node instead of duplicating its content",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "type": "string"
+                },
+                "seo": {
+                    "$ref": "#/definitions/domain.NodeSEO"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "type": {
+                    "enum": [
+                        1,
+                        2
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.NodeType"
+                        }
+                    ]
+                },
+                "visibility": {
+                    "$ref": "#/definitions/domain.NodeVisibility"
+                }
+            }
+        },
+        "domain.CreateNodeTemplateReq": {
+            "type": "object",
+            "required": [
+                "kb_id",
+                "name",
+                "type"
+            ],
+            "properties": {
+                "category": {
+                    "type": "string"
+                },
+                "children": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.NodeTemplateItem"
+                    }
+                },
+                "content": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "emoji": {
+                    "type": "string"
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "type": {
+                    "enum": [
+                        1,
+                        2
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.NodeType"
+                        }
+                    ]
+                }
+            }
+        },
+        "domain.CreateReaderReq": {
+            "type": "object",
+            "required": [
+                "email",
+                "kb_id",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "groups": {
+                    "type": "array",
+                    "maxItems": 50,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "password": {
+                    "type": "string",
+                    "minLength": 8
+                }
+            }
+        },
+        "domain.CreateReaderResp": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.CreateSandboxReq": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "answer_settings": {
+                    "description": "retrieval and confidence gate settings of the sandbox, defaults if not set",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.AnswerSettings"
+                        }
+                    ]
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "ttl_minutes": {
+                    "description": "minutes until the sandbox is torn down if the pipeline does not, 120 if not set",
+                    "type": "integer",
+                    "maximum": 1440,
+                    "minimum": 5
+                }
+            }
+        },
+        "domain.CreateStarterKBReq": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "hosts": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "ports": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "ssl_ports": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "domain.CreateStarterKBResp": {
+            "type": "object",
+            "properties": {
+                "app_id": {
+                    "type": "string"
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "node_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "domain.CreateUserReq": {
+            "type": "object",
+            "required": [
+                "account",
+                "password"
+            ],
+            "properties": {
+                "account": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string",
+                    "minLength": 8
+                },
+                "role": {
+                    "description": "admin if empty, members only access the kbs they are added to",
+                    "enum": [
+                        "admin",
+                        "member"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.UserRole"
+                        }
+                    ]
+                }
+            }
+        },
+        "domain.CreateWebhookReq": {
+            "type": "object",
+            "required": [
+                "events",
+                "kb_id",
+                "name",
+                "url"
+            ],
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "events": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "format": {
+                    "enum": [
+                        "template",
+                        "jsonpath"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.WebhookFormat"
+                        }
+                    ]
+                },
+                "headers": {
+                    "$ref": "#/definitions/domain.StringMap"
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "mapping": {
+                    "$ref": "#/definitions/domain.StringMap"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "template": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.CronJobStatus": {
+            "type": "object",
+            "properties": {
+                "consecutive_failures": {
+                    "description": "failed runs since the last successful run",
+                    "type": "integer"
+                },
+                "job": {
+                    "type": "string"
+                },
+                "last_run": {
+                    "description": "latest run of the job",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.CronRun"
+                        }
+                    ]
+                }
+            }
+        },
+        "domain.CronRun": {
+            "type": "object",
+            "properties": {
+                "duration_ms": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "job": {
+                    "type": "string"
+                },
+                "result": {
+                    "description": "job specific result shown in the job dashboard",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.CronRunResult"
+                        }
+                    ]
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.CronRunStatus"
+                }
+            }
+        },
+        "domain.CronRunResult": {
+            "type": "object",
+            "additionalProperties": {}
+        },
+        "domain.CronRunStatus": {
+            "type": "string",
+            "enum": [
+                "success",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "CronRunStatusSuccess",
+                "CronRunStatusFailed"
+            ]
+        },
+        "domain.DailyDigest": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string"
+                },
+                "escalations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.DigestEscalation"
+                    }
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "kb_name": {
+                    "type": "string"
+                },
+                "new_questions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.DigestQuestion"
+                    }
+                },
+                "prev_volume": {
+                    "description": "volume of the day before, for comparison",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.DigestVolume"
+                        }
+                    ]
+                },
+                "transcripts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.DigestTranscript"
+                    }
+                },
+                "volume": {
+                    "$ref": "#/definitions/domain.DigestVolume"
+                }
+            }
+        },
+        "domain.DailyDigestReq": {
+            "type": "object",
+            "required": [
+                "kb_id"
+            ],
+            "properties": {
+                "date": {
+                    "description": "YYYY-MM-DD, yesterday if empty",
+                    "type": "string"
+                },
+                "kb_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.DailyDigestResp": {
+            "type": "object",
+            "properties": {
+                "digest": {
+                    "$ref": "#/definitions/domain.DailyDigest"
+                },
+                "markdown": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.DataExportJob": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "description": "admin who requested the export",
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "record_count": {
+                    "type": "integer"
+                },
+                "remote_ip": {
+                    "type": "string"
+                },
+                "scope": {
+                    "$ref": "#/definitions/domain.DataExportScope"
+                },
+                "size": {
+                    "description": "archive size in bytes",
+                    "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.NodeExportJobStatus"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "description": "signed download url of the archive, set when succeeded",
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.DataExportReq": {
+            "type": "object",
+            "required": [
+                "kb_id",
+                "scope"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "remote_ip": {
+                    "type": "string"
+                },
+                "scope": {
+                    "enum": [
+                        "kb",
+                        "end_user"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.DataExportScope"
+                        }
+                    ]
+                },
+                "user_id": {
+                    "description": "identifiers of the end user, records matching any of them are exported. at least one is required for end_user scope",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.DataExportScope": {
+            "type": "string",
+            "enum": [
+                "kb",
+                "end_user"
+            ],
+            "x-enum-comments": {
+                "DataExportScopeEndUser": "records of the kb referencing an end user, for right of access requests",
+                "DataExportScopeKB": "everything stored for the kb"
+            },
+            "x-enum-varnames": [
+                "DataExportScopeKB",
+                "DataExportScopeEndUser"
+            ]
+        },
+        "domain.DeleteEvalQuestionReq": {
+            "type": "object",
+            "required": [
+                "id",
+                "kb_id"
+            ],
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "kb_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.DeleteEvalSetReq": {
+            "type": "object",
+            "required": [
+                "id",
+                "kb_id"
+            ],
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "kb_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.DeleteGlossaryTermReq": {
+            "type": "object",
+            "required": [
+                "id",
+                "kb_id"
+            ],
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "kb_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.DeleteNodeTemplateReq": {
+            "type": "object",
+            "required": [
+                "id",
+                "kb_id"
+            ],
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "kb_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.DeleteUserReq": {
+            "type": "object",
+            "required": [
+                "user_id"
+            ],
+            "properties": {
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.DeleteWebhookReq": {
+            "type": "object",
+            "required": [
+                "id",
+                "kb_id"
+            ],
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "kb_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.DeviceType": {
+            "type": "string",
+            "enum": [
+                "mobile",
+                "tablet",
+                "desktop",
+                "unknown"
+            ],
+            "x-enum-varnames": [
+                "DeviceTypeMobile",
+                "DeviceTypeTablet",
+                "DeviceTypeDesktop",
+                "DeviceTypeUnknown"
+            ]
+        },
+        "domain.DigestEscalation": {
+            "type": "object",
+            "properties": {
+                "conversation_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "low_confidence_count": {
+                    "type": "integer"
+                },
+                "no_answer_count": {
+                    "type": "integer"
+                },
+                "subject": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.DigestMessage": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/schema.RoleType"
+                }
+            }
+        },
+        "domain.DigestQuestion": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "question": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.DigestSettings": {
+            "type": "object",
+            "properties": {
+                "console_url": {
+                    "description": "admin console base url for deep links",
+                    "type": "string"
+                },
+                "create_node": {
+                    "description": "save digest as a private markdown node",
+                    "type": "boolean"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "parent_id": {
+                    "type": "string"
+                },
+                "webhook": {
+                    "description": "push digest to dingtalk/feishu group",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.NotifyWebhook"
+                        }
+                    ]
+                }
+            }
+        },
+        "domain.DigestTranscript": {
+            "type": "object",
+            "properties": {
+                "conversation_id": {
+                    "type": "string"
+                },
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.DigestMessage"
+                    }
+                },
+                "subject": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.DigestVolume": {
+            "type": "object",
+            "properties": {
+                "conversation_count": {
+                    "type": "integer"
+                },
+                "message_count": {
+                    "type": "integer"
+                },
+                "visitor_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.DiscardNodeDraftReq": {
+            "type": "object",
+            "required": [
+                "id",
+                "kb_id"
+            ],
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "kb_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.EpubResp": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ErrorCode": {
+            "type": "string",
+            "enum": [
+                "INVALID_REQUEST",
+                "UNAUTHORIZED",
+                "FORBIDDEN",
+                "NOT_FOUND",
+                "KB_NOT_FOUND",
+                "NODE_NOT_FOUND",
+                "APP_NOT_FOUND",
+                "CONFLICT",
+                "RATE_LIMITED",
+                "QUOTA_EXCEEDED",
+                "BLOCKED",
+                "READ_ONLY",
+                "MODEL_NOT_CONFIGURED",
+                "MODEL_TIMEOUT",
+                "MODEL_FAILED",
+                "TIMEOUT",
+                "INTERNAL_ERROR"
+            ],
+            "x-enum-varnames": [
+                "ErrCodeInvalidRequest",
+                "ErrCodeUnauthorized",
+                "ErrCodeForbidden",
+                "ErrCodeNotFound",
+                "ErrCodeKBNotFound",
+                "ErrCodeNodeNotFound",
+                "ErrCodeAppNotFound",
+                "ErrCodeConflict",
+                "ErrCodeRateLimited",
+                "ErrCodeQuotaExceeded",
+                "ErrCodeBlocked",
+                "ErrCodeReadOnly",
+                "ErrCodeModelNotConfigured",
+                "ErrCodeModelTimeout",
+                "ErrCodeModelFailed",
+                "ErrCodeTimeout",
+                "ErrCodeInternal"
+            ]
+        },
+        "domain.EvalQuestion": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expected_answer": {
+                    "description": "reference answer the judge compares answers with",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "question": {
+                    "description": "question as a user would ask it",
+                    "type": "string"
+                },
+                "set_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.EvalQuestionInput": {
+            "type": "object",
+            "required": [
+                "expected_answer",
+                "question"
+            ],
+            "properties": {
+                "expected_answer": {
+                    "type": "string",
+                    "maxLength": 10000
+                },
+                "question": {
+                    "type": "string",
+                    "maxLength": 2000
+                }
+            }
+        },
+        "domain.EvalResult": {
+            "type": "object",
+            "properties": {
+                "answer": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "description": "answer or judgement failed, the question has no scores",
+                    "type": "string"
+                },
+                "expected_answer": {
+                    "type": "string"
+                },
+                "faithfulness": {
+                    "type": "number"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "latency_ms": {
+                    "type": "integer"
+                },
+                "node_ids": {
+                    "description": "documents given to the model",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "passed": {
+                    "type": "boolean"
+                },
+                "question": {
+                    "type": "string"
+                },
+                "question_id": {
+                    "type": "string"
+                },
+                "reason": {
+                    "description": "reason of the judge for the scores",
+                    "type": "string"
+                },
+                "relevance": {
+                    "type": "number"
+                },
+                "run_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.EvalRun": {
+            "type": "object",
+            "properties": {
+                "app_id": {
+                    "description": "app whose prompt template and citation style are used, the built-in prompt if empty",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "judge_model": {
+                    "description": "model judging the answers, the same chat model",
+                    "type": "string"
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "model_id": {
+                    "type": "string"
+                },
+                "note": {
+                    "description": "what changed since the previous run, e.g. new model or prompt",
+                    "type": "string"
+                },
+                "prompt_template_version": {
+                    "type": "integer"
+                },
+                "provider": {
+                    "description": "model and prompt the questions were answered with, filled when the run starts",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ModelProvider"
+                        }
+                    ]
+                },
+                "set_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.NodeExportJobStatus"
+                },
+                "summary": {
+                    "$ref": "#/definitions/domain.EvalRunSummary"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.EvalRunSummary": {
+            "type": "object",
+            "properties": {
+                "avg_faithfulness": {
+                    "description": "0-1, answer supported by the retrieved documents",
+                    "type": "number"
+                },
+                "avg_latency_ms": {
+                    "type": "integer"
+                },
+                "avg_relevance": {
+                    "description": "0-1, answer addresses the question and agrees with the expected answer",
+                    "type": "number"
+                },
+                "failed_count": {
+                    "description": "questions not answered or judged because the model failed",
+                    "type": "integer"
+                },
+                "pass_count": {
+                    "type": "integer"
+                },
+                "pass_rate": {
+                    "type": "number"
+                },
+                "question_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.EvalSet": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.EvalSetListItem": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "last_run": {
+                    "description": "summary of the latest succeeded run, nil if the set never ran",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.EvalRun"
+                        }
+                    ]
+                },
+                "name": {
+                    "type": "string"
+                },
+                "question_count": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.EvalTrendResp": {
+            "type": "object",
+            "properties": {
+                "runs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.EvalRun"
+                    }
+                },
+                "set_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.FallbackModel": {
+            "type": "object",
+            "properties": {
+                "api_header": {
+                    "type": "string"
+                },
+                "api_key": {
+                    "type": "string"
+                },
+                "api_version": {
+                    "type": "string"
+                },
+                "base_url": {
+                    "type": "string"
+                },
+                "completion_price": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "model": {
+                    "type": "string"
+                },
+                "prompt_price": {
+                    "description": "price per 1M tokens, spend of the fallback model is recorded apart from the budget model",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "provider": {
+                    "$ref": "#/definitions/domain.ModelProvider"
+                }
+            }
+        },
+        "domain.FeedSettings": {
+            "type": "object",
+            "required": [
+                "url"
+            ],
+            "properties": {
+                "fetch_page": {
+                    "description": "fetch the page of entries whose feed content is only a summary",
+                    "type": "boolean"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.FeishuSettings": {
+            "type": "object",
+            "required": [
+                "app_id"
+            ],
+            "properties": {
+                "app_id": {
+                    "type": "string"
+                },
+                "app_secret": {
+                    "type": "string"
+                },
+                "folder_token": {
+                    "type": "string"
+                },
+                "space_id": {
+                    "description": "the app must be a member of the wiki space, or be granted the folder",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.FollowUpQuestion": {
+            "type": "object",
+            "properties": {
+                "node_id": {
+                    "type": "string"
+                },
+                "node_name": {
+                    "type": "string"
+                },
+                "question": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.FollowUpSettings": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "description": "3 if not set",
+                    "type": "integer",
+                    "maximum": 3,
+                    "minimum": 1
+                },
+                "enabled": {
+                    "description": "generate follow-up questions by the chat model and send them as a suggestions event before done",
+                    "type": "boolean"
+                }
+            }
+        },
+        "domain.FooterSettings": {
+            "type": "object",
+            "properties": {
+                "brand_desc": {
+                    "type": "string"
+                },
+                "brand_groups": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.BrandGroup"
+                    }
+                },
+                "brand_logo": {
+                    "type": "string"
+                },
+                "brand_name": {
+                    "type": "string"
+                },
+                "corp_name": {
+                    "type": "string"
+                },
+                "footer_style": {
+                    "type": "string"
+                },
+                "icp": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.FullTextSearchResult": {
+            "type": "object",
+            "properties": {
+                "emoji": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "name_highlight": {
+                    "type": "string"
+                },
+                "rank": {
+                    "type": "number"
+                },
+                "snippets": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.FunnelStep": {
+            "type": "string",
+            "enum": [
+                "visit",
+                "search",
+                "chat",
+                "resolution"
+            ],
+            "x-enum-varnames": [
+                "FunnelStepVisit",
+                "FunnelStepSearch",
+                "FunnelStepChat",
+                "FunnelStepResolution"
+            ]
+        },
+        "domain.FunnelStepStat": {
+            "type": "object",
+            "properties": {
+                "conversion_rate": {
+                    "description": "converted / converted of previous step",
+                    "type": "number"
+                },
+                "converted": {
+                    "description": "sessions reached this step and all previous steps",
+                    "type": "integer"
+                },
+                "overall_rate": {
+                    "description": "converted / sessions of the first step",
+                    "type": "number"
+                },
+                "sessions": {
+                    "description": "sessions reached this step",
+                    "type": "integer"
+                },
+                "step": {
+                    "$ref": "#/definitions/domain.FunnelStep"
+                }
+            }
+        },
+        "domain.GapReport": {
+            "type": "object",
+            "properties": {
+                "end_time": {
+                    "type": "string"
+                },
+                "historical_questions": {
+                    "description": "unresolved tickets imported from a legacy helpdesk, whatever their time",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.UnansweredQuestion"
+                    }
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "kb_name": {
+                    "type": "string"
+                },
+                "stale_nodes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.StaleNode"
+                    }
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "unanswered_questions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.UnansweredQuestion"
+                    }
+                }
+            }
+        },
+        "domain.GapReportSettings": {
+            "type": "object",
+            "properties": {
+                "console_url": {
+                    "description": "admin console base url for deep links",
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "secret": {
+                    "description": "sign secret of the group bot, optional",
+                    "type": "string"
+                },
+                "webhook_url": {
+                    "description": "custom group bot webhook",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.GeoRegion": {
+            "type": "object",
+            "properties": {
+                "countries": {
+                    "description": "countries resolved by ipdb, e.g. 中国",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prompt_hint": {
+                    "description": "extra answer instruction of the region, e.g. quote prices in CNY",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.GeoSettings": {
+            "type": "object",
+            "properties": {
+                "default_region": {
+                    "description": "region of visitors whose country matches no region, e.g. bots without ip",
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "regions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.GeoRegion"
+                    }
+                }
+            }
+        },
+        "domain.GeoStatNode": {
+            "type": "object",
+            "properties": {
+                "children": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.GeoStatNode"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.GeoStatResp": {
+            "type": "object",
+            "properties": {
+                "countries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.GeoStatNode"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.GetAppPromptResp": {
+            "type": "object",
+            "properties": {
+                "current": {
+                    "description": "latest version of the app, nil if it never saved a template",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.AppPromptTemplate"
+                        }
+                    ]
+                },
+                "default": {
+                    "description": "built-in template of apps without a template",
+                    "type": "string"
+                },
+                "variables": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.PromptVariable"
+                    }
+                }
+            }
+        },
+        "domain.GetDocsReq": {
+            "type": "object",
+            "required": [
+                "kb_id"
+            ],
+            "properties": {
+                "integration": {
+                    "type": "string"
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "pages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.PageInfo"
+                    }
+                }
+            }
+        },
+        "domain.GetDocxReq": {
+            "type": "object",
+            "required": [
+                "kb_id"
+            ],
+            "properties": {
+                "app_id": {
+                    "type": "string"
+                },
+                "app_secret": {
+                    "type": "string"
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "urls": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "user_access_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.GetDocxResp": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.GetKBReleaseListResp": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.KBReleaseListItemResp"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.GetProviderModelListResp": {
+            "type": "object",
+            "properties": {
+                "models": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ProviderModelListItem"
+                    }
+                }
+            }
+        },
+        "domain.GetSpaceListReq": {
+            "type": "object",
+            "properties": {
+                "app_id": {
+                    "type": "string"
+                },
+                "app_secret": {
+                    "type": "string"
+                },
+                "user_access_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.GetSpaceListResp": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "space_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.GitSettings": {
+            "type": "object",
+            "required": [
+                "url"
+            ],
+            "properties": {
+                "branch": {
+                    "description": "default branch if empty",
+                    "type": "string"
+                },
+                "path": {
+                    "description": "directory of the docs in the repo, the whole repo if empty",
+                    "type": "string"
+                },
+                "token": {
+                    "description": "personal or project access token of private repos",
+                    "type": "string"
+                },
+                "url": {
+                    "description": "https url of the repo, e.g. https://github.com/group/docs.git",
+                    "type": "string"
+                },
+                "webhook_secret": {
+                    "description": "secret of github webhooks or token of gitlab webhooks, push events sync the source",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.GitWebhookResp": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string"
+                },
+                "synced": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "domain.GlossaryTerm": {
+            "type": "object",
+            "properties": {
+                "aliases": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "definition": {
+                    "description": "definition shown on hover of linked terms and given to the model",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "node_id": {
+                    "description": "document explaining the term, linked terms point to it if set",
+                    "type": "string"
+                },
+                "term": {
+                    "description": "standard term, matched case sensitively like its aliases",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.GuardrailAction": {
+            "type": "string",
+            "enum": [
+                "block",
+                "warn",
+                "log"
+            ],
+            "x-enum-comments": {
+                "GuardrailActionBlock": "replace the answer by the blocked reply",
+                "GuardrailActionLog": "answer and only record the event",
+                "GuardrailActionWarn": "answer and send a guardrail event so the client can show a notice"
+            },
+            "x-enum-varnames": [
+                "GuardrailActionBlock",
+                "GuardrailActionWarn",
+                "GuardrailActionLog"
+            ]
+        },
+        "domain.GuardrailCheckType": {
+            "type": "string",
+            "enum": [
+                "profanity",
+                "injection",
+                "off_topic"
+            ],
+            "x-enum-comments": {
+                "GuardrailCheckInjection": "questions trying to override the instructions of the model",
+                "GuardrailCheckOffTopic": "questions not similar to any document of the kb",
+                "GuardrailCheckProfanity": "profanity and blocklist words in the question or the answer"
+            },
+            "x-enum-varnames": [
+                "GuardrailCheckProfanity",
+                "GuardrailCheckInjection",
+                "GuardrailCheckOffTopic"
+            ]
+        },
+        "domain.GuardrailEvent": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "$ref": "#/definitions/domain.GuardrailAction"
+                },
+                "app_id": {
+                    "type": "string"
+                },
+                "check": {
+                    "$ref": "#/definitions/domain.GuardrailCheckType"
+                },
+                "conversation_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "detail": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "question": {
+                    "description": "question of the event, cut to GuardrailDetailRunes",
+                    "type": "string"
+                },
+                "remote_ip": {
+                    "type": "string"
+                },
+                "stage": {
+                    "$ref": "#/definitions/domain.GuardrailStage"
+                }
+            }
+        },
+        "domain.GuardrailSettings": {
+            "type": "object",
+            "properties": {
+                "blocked_reply": {
+                    "type": "string"
+                },
+                "blocklist": {
+                    "description": "words matched by the profanity check besides the builtin list, case insensitive",
+                    "type": "array",
+                    "maxItems": 500,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "injection": {
+                    "description": "prompt injection heuristics, on questions",
+                    "enum": [
+                        "block",
+                        "warn",
+                        "log"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.GuardrailAction"
+                        }
+                    ]
+                },
+                "min_topic_score": {
+                    "description": "min vector similarity of the best retrieved chunk, 0.3 if not set",
+                    "type": "number",
+                    "maximum": 1,
+                    "minimum": 0
+                },
+                "off_topic": {
+                    "description": "similarity of the question to the documents of the kb, on questions after retrieval",
+                    "enum": [
+                        "block",
+                        "warn",
+                        "log"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.GuardrailAction"
+                        }
+                    ]
+                },
+                "profanity": {
+                    "description": "builtin profanity list and the blocklist, on questions and answers",
+                    "enum": [
+                        "block",
+                        "warn",
+                        "log"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.GuardrailAction"
+                        }
+                    ]
+                }
+            }
+        },
+        "domain.GuardrailStage": {
+            "type": "string",
+            "enum": [
+                "question",
+                "answer"
+            ],
+            "x-enum-comments": {
+                "GuardrailStageAnswer": "on the answer before it is sent, answers are buffered if the answer check blocks",
+                "GuardrailStageQuestion": "on the question before retrieval, or after retrieval for the off-topic check, before the model is called"
+            },
+            "x-enum-varnames": [
+                "GuardrailStageQuestion",
+                "GuardrailStageAnswer"
+            ]
+        },
+        "domain.HistoricalInfo": {
+            "type": "object",
+            "properties": {
+                "external_id": {
+                    "description": "ticket id, re-importing it is skipped",
+                    "type": "string"
+                },
+                "resolved": {
+                    "description": "unresolved tickets are reported as unanswered questions by gap analysis",
+                    "type": "boolean"
+                },
+                "source": {
+                    "$ref": "#/definitions/domain.HistoricalSource"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.HistoricalSource": {
+            "type": "string",
+            "enum": [
+                "csv",
+                "zendesk"
+            ],
+            "x-enum-varnames": [
+                "HistoricalSourceCSV",
+                "HistoricalSourceZendesk"
+            ]
+        },
+        "domain.IPAddress": {
+            "type": "object",
+            "properties": {
+                "city": {
+                    "type": "string"
+                },
+                "country": {
+                    "type": "string"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "province": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.IPRuleSet": {
+            "type": "object",
+            "properties": {
+                "allow": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "deny": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "domain.IPRules": {
+            "type": "object",
+            "properties": {
+                "admin": {
+                    "$ref": "#/definitions/domain.IPRuleSet"
+                },
+                "public": {
+                    "$ref": "#/definitions/domain.IPRuleSet"
+                }
+            }
+        },
+        "domain.ImportDocumentsResp": {
+            "type": "object",
+            "properties": {
+                "failed_files": {
+                    "description": "files the parsing service failed on, not imported",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "node_ids": {
+                    "description": "a document for each parsed file, named by its title or file name",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "release_id": {
+                    "description": "new documents are published and queued for indexing, unless the kb requires review",
+                    "type": "string"
+                },
+                "warnings": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "domain.ImportLink": {
+            "type": "object",
+            "required": [
+                "node_id",
+                "url"
+            ],
+            "properties": {
+                "node_id": {
+                    "type": "string"
+                },
+                "url": {
+                    "description": "source url of the imported page, or page id for notion",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ImportMarkdownArchiveResp": {
+            "type": "object",
+            "properties": {
+                "attachment_count": {
+                    "type": "integer"
+                },
+                "folder_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "image_count": {
+                    "type": "integer"
+                },
+                "node_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "release_id": {
+                    "description": "new and updated documents are published and queued for indexing, unless the kb requires review",
+                    "type": "string"
+                },
+                "skipped_paths": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "updated_node_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "warnings": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "domain.ImportMediaResp": {
+            "type": "object",
+            "properties": {
+                "duration": {
+                    "description": "length of the media in seconds",
+                    "type": "number"
+                },
+                "language": {
+                    "type": "string"
+                },
+                "node_id": {
+                    "type": "string"
+                },
+                "release_id": {
+                    "description": "the transcript is published and queued for indexing, unless the kb requires review",
+                    "type": "string"
+                },
+                "segment_count": {
+                    "type": "integer"
+                },
+                "warnings": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "domain.ImportPreviewAction": {
+            "type": "string",
+            "enum": [
+                "create",
+                "update",
+                "unchanged"
+            ],
+            "x-enum-varnames": [
+                "ImportPreviewActionCreate",
+                "ImportPreviewActionUpdate",
+                "ImportPreviewActionUnchanged"
+            ]
+        },
+        "domain.ImportSource": {
+            "type": "object",
+            "properties": {
+                "changed_count": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "last_synced_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "page_count": {
+                    "description": "pages of the source and pages created or updated by the last sync",
+                    "type": "integer"
+                },
+                "parent_id": {
+                    "description": "folder the pages are imported into, kb root if empty",
+                    "type": "string"
+                },
+                "settings": {
+                    "$ref": "#/definitions/domain.ImportSourceSettings"
+                },
+                "stale_count": {
+                    "description": "imported pages no longer in the source, their nodes are kept",
+                    "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.ImportSourceStatus"
+                },
+                "sync_interval": {
+                    "description": "hours between automatic syncs, only synced manually if 0",
+                    "type": "integer"
+                },
+                "type": {
+                    "$ref": "#/definitions/domain.ImportSourceType"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ImportSourcePreview": {
+            "type": "object",
+            "properties": {
+                "create_count": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "pages": {
+                    "description": "parents before their children",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ImportSourcePreviewPage"
+                    }
+                },
+                "removed_count": {
+                    "description": "imported pages no longer in the source, marked stale by the sync and their nodes are kept",
+                    "type": "integer"
+                },
+                "update_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.ImportSourcePreviewPage": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "$ref": "#/definitions/domain.ImportPreviewAction"
+                },
+                "folder": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ImportSourceReq": {
+            "type": "object",
+            "required": [
+                "id",
+                "kb_id"
+            ],
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "kb_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ImportSourceSettings": {
+            "type": "object",
+            "properties": {
+                "confluence": {
+                    "$ref": "#/definitions/domain.ConfluenceSettings"
+                },
+                "feed": {
+                    "$ref": "#/definitions/domain.FeedSettings"
+                },
+                "feishu": {
+                    "$ref": "#/definitions/domain.FeishuSettings"
+                },
+                "git": {
+                    "$ref": "#/definitions/domain.GitSettings"
+                },
+                "notion": {
+                    "$ref": "#/definitions/domain.NotionSettings"
+                },
+                "openapi": {
+                    "$ref": "#/definitions/domain.OpenAPISettings"
+                },
+                "website": {
+                    "$ref": "#/definitions/domain.WebsiteSettings"
+                },
+                "yuque": {
+                    "$ref": "#/definitions/domain.YuqueSettings"
+                }
+            }
+        },
+        "domain.ImportSourceStalePage": {
+            "type": "object",
+            "properties": {
+                "external_id": {
+                    "description": "id of the page in the source, the url of website pages",
+                    "type": "string"
+                },
+                "node_id": {
+                    "type": "string"
+                },
+                "node_name": {
+                    "type": "string"
+                },
+                "stale_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ImportSourceStatus": {
+            "type": "string",
+            "enum": [
+                "idle",
+                "syncing",
+                "succeeded",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "ImportSourceStatusIdle",
+                "ImportSourceStatusSyncing",
+                "ImportSourceStatusSucceeded",
+                "ImportSourceStatusFailed"
+            ]
+        },
+        "domain.ImportSourceType": {
+            "type": "string",
+            "enum": [
+                "confluence",
+                "notion",
+                "feishu",
+                "yuque",
+                "git",
+                "openapi",
+                "website",
+                "feed"
+            ],
+            "x-enum-varnames": [
+                "ImportSourceTypeConfluence",
+                "ImportSourceTypeNotion",
+                "ImportSourceTypeFeishu",
+                "ImportSourceTypeYuque",
+                "ImportSourceTypeGit",
+                "ImportSourceTypeOpenAPI",
+                "ImportSourceTypeWebsite",
+                "ImportSourceTypeFeed"
+            ]
+        },
+        "domain.ImportTranscriptsResp": {
+            "type": "object",
+            "properties": {
+                "conversation_count": {
+                    "type": "integer"
+                },
+                "message_count": {
+                    "type": "integer"
+                },
+                "skipped_count": {
+                    "description": "already imported",
+                    "type": "integer"
+                },
+                "warnings": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "domain.IndexIntegrityReport": {
+            "type": "object",
+            "properties": {
+                "checked_at": {
+                    "type": "string"
+                },
+                "chunk_count": {
+                    "type": "integer"
+                },
+                "deleted_doc_count": {
+                    "type": "integer"
+                },
+                "doc_count": {
+                    "description": "documents and chunks in the vector store",
+                    "type": "integer"
+                },
+                "empty_count": {
+                    "type": "integer"
+                },
+                "empty_node_ids": {
+                    "description": "nodes whose document failed parsing or has no chunks",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "missing_count": {
+                    "type": "integer"
+                },
+                "missing_node_ids": {
+                    "description": "nodes without document in the vector store",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "node_count": {
+                    "description": "published nodes which should be indexed",
+                    "type": "integer"
+                },
+                "orphan_count": {
+                    "type": "integer"
+                },
+                "orphan_doc_ids": {
+                    "description": "documents in the vector store not referenced by any node",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "reindexed_count": {
+                    "description": "nodes queued for re-indexing and orphan documents deleted by repair",
+                    "type": "integer"
+                },
+                "repaired": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "domain.IndexIntegrityReq": {
+            "type": "object",
+            "required": [
+                "kb_id"
+            ],
+            "properties": {
+                "kb_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.KBMemberListItem": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/domain.KBRole"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "user_role": {
+                    "$ref": "#/definitions/domain.UserRole"
+                }
+            }
+        },
+        "domain.KBModelCheck": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "model_id": {
+                    "type": "string"
+                },
+                "provider": {
+                    "$ref": "#/definitions/domain.ModelProvider"
+                }
+            }
+        },
+        "domain.KBModelSettings": {
+            "type": "object",
+            "properties": {
+                "chat_model_id": {
+                    "type": "string"
+                },
+                "embedding_model_id": {
+                    "description": "documents are embedded again after the embedding model is changed",
+                    "type": "string"
+                },
+                "rerank_model_id": {
+                    "type": "string"
+                },
+                "rewrite_model_id": {
+                    "description": "chat model rewriting follow-up questions into standalone queries, the chat model of the kb if empty",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.KBPermission": {
+            "type": "string",
+            "enum": [
+                "view",
+                "edit_nodes",
+                "export_nodes",
+                "view_conversations",
+                "view_analytics",
+                "manage_settings",
+                "export_data"
+            ],
+            "x-enum-comments": {
+                "KBPermissionView": "read documents, releases and the kb, every role",
+                "KBPermissionEditNodes": "create, edit, publish and import documents",
+                "KBPermissionExportNodes": "export documents",
+                "KBPermissionViewConversations": "read conversations and feedback of readers",
+                "KBPermissionViewAnalytics": "reports and anomalies of questions and traffic",
+                "KBPermissionManageSettings": "kb, app, access and integration settings, and the members of the kb",
+                "KBPermissionExportData": "export all data of the kb, readers and conversations included"
+            },
+            "x-enum-varnames": [
+                "KBPermissionView",
+                "KBPermissionEditNodes",
+                "KBPermissionExportNodes",
+                "KBPermissionViewConversations",
+                "KBPermissionViewAnalytics",
+                "KBPermissionManageSettings",
+                "KBPermissionExportData"
+            ]
+        },
+        "domain.KBReleaseListItemResp": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "tag": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.KBRole": {
+            "type": "string",
+            "enum": [
+                "owner",
+                "editor",
+                "analyst",
+                "support_agent"
+            ],
+            "x-enum-varnames": [
+                "KBRoleOwner",
+                "KBRoleEditor",
+                "KBRoleAnalyst",
+                "KBRoleSupportAgent"
+            ]
+        },
+        "domain.KeywordQuery": {
+            "type": "object",
+            "properties": {
+                "identifiers": {
+                    "description": "tokens with digits or inner punctuation, e.g. error codes, SKUs and versions",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "words": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "domain.KnowledgeBaseDetail": {
+            "type": "object",
+            "properties": {
+                "access_settings": {
+                    "$ref": "#/definitions/domain.AccessSettings"
+                },
+                "anomaly_settings": {
+                    "$ref": "#/definitions/domain.AnomalySettings"
+                },
+                "answer_settings": {
+                    "$ref": "#/definitions/domain.AnswerSettings"
+                },
+                "branding_settings": {
+                    "$ref": "#/definitions/domain.BrandingSettings"
+                },
+                "comment_settings": {
+                    "$ref": "#/definitions/domain.CommentSettings"
+                },
+                "compliance_settings": {
+                    "$ref": "#/definitions/domain.ComplianceSettings"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "dataset_id": {
+                    "type": "string"
+                },
+                "digest_settings": {
+                    "$ref": "#/definitions/domain.DigestSettings"
+                },
+                "geo_settings": {
+                    "$ref": "#/definitions/domain.GeoSettings"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "maintenance_settings": {
+                    "$ref": "#/definitions/domain.MaintenanceSettings"
+                },
+                "model_settings": {
+                    "$ref": "#/definitions/domain.KBModelSettings"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "review_reminder_settings": {
+                    "$ref": "#/definitions/domain.ReviewReminderSettings"
+                },
+                "review_settings": {
+                    "$ref": "#/definitions/domain.ReviewSettings"
+                },
+                "stat_settings": {
+                    "$ref": "#/definitions/domain.StatSettings"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.KnowledgeBaseListItem": {
+            "type": "object",
+            "properties": {
+                "access_settings": {
+                    "$ref": "#/definitions/domain.AccessSettings"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "dataset_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Link": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.LoginReq": {
+            "type": "object",
+            "required": [
+                "account",
+                "password"
+            ],
+            "properties": {
+                "account": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.LoginResp": {
+            "type": "object",
+            "properties": {
+                "challenge_token": {
+                    "type": "string"
+                },
+                "token": {
+                    "description": "empty if a second factor is needed",
+                    "type": "string"
+                },
+                "two_factor": {
+                    "description": "step left to log in, with the challenge token",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.TwoFactorStep"
+                        }
+                    ]
+                }
+            }
+        },
+        "domain.MaintenanceResp": {
+            "type": "object",
+            "properties": {
+                "global": {
+                    "$ref": "#/definitions/domain.MaintenanceSettings"
+                },
+                "kb": {
+                    "$ref": "#/definitions/domain.MaintenanceSettings"
+                }
+            }
+        },
+        "domain.MaintenanceSettings": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "read_only": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "domain.MarkNodesReviewedReq": {
+            "type": "object",
+            "required": [
+                "kb_id",
+                "node_ids"
+            ],
+            "properties": {
+                "kb_id": {
+                    "type": "string"
+                },
+                "node_ids": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "domain.MessageDebugModel": {
+            "type": "object",
+            "properties": {
+                "citation_style": {
+                    "$ref": "#/definitions/domain.CitationStyle"
+                },
+                "failovers": {
+                    "type": "integer"
+                },
+                "history_token_budget": {
+                    "type": "integer"
+                },
+                "max_continuation_rounds": {
+                    "description": "continuation requests allowed after a truncated answer",
+                    "type": "integer"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "model_id": {
+                    "type": "string"
+                },
+                "prompt_template_version": {
+                    "description": "version of the prompt template of the app, 0 for the built-in prompt",
+                    "type": "integer"
+                },
+                "provider": {
+                    "$ref": "#/definitions/domain.ModelProvider"
+                },
+                "stop_sequences": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "temperature": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.MessageDebugResp": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "description": "answer as saved",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ConversationMessage"
+                        }
+                    ]
+                },
+                "model": {
+                    "$ref": "#/definitions/domain.MessageDebugModel"
+                },
+                "notes": {
+                    "description": "differences of the replay from the chat",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "prompt": {
+                    "description": "prompt of the replay, history trimmed to the token budget as in chats",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.PromptMessage"
+                    }
+                },
+                "question": {
+                    "type": "string"
+                },
+                "retrieval": {
+                    "description": "retrieval replayed now, compare with the rerank scores and provenance saved on the answer",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.RetrievalTrace"
+                        }
+                    ]
+                }
+            }
+        },
+        "domain.MessageFeedbackReq": {
+            "type": "object",
+            "required": [
+                "conversation_id",
+                "nonce",
+                "type"
+            ],
+            "properties": {
+                "comment": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "conversation_id": {
+                    "type": "string"
+                },
+                "message_id": {
+                    "description": "latest answer of the conversation if empty",
+                    "type": "string"
+                },
+                "nonce": {
+                    "type": "string"
+                },
+                "type": {
+                    "enum": [
+                        "like",
+                        "dislike"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.MessageFeedbackType"
+                        }
+                    ]
+                }
+            }
+        },
+        "domain.MessageFeedbackType": {
+            "type": "string",
+            "enum": [
+                "like",
+                "dislike"
+            ],
+            "x-enum-varnames": [
+                "MessageFeedbackLike",
+                "MessageFeedbackDislike"
+            ]
+        },
+        "domain.MessageFrom": {
+            "type": "integer",
+            "enum": [
+                0,
+                1
+            ],
+            "x-enum-varnames": [
+                "MessageFromGroup",
+                "MessageFromPrivate"
+            ]
+        },
+        "domain.MessageStatus": {
+            "type": "string",
+            "enum": [
+                "streaming",
+                "completed",
+                "failed",
+                "interrupted"
+            ],
+            "x-enum-varnames": [
+                "MessageStatusStreaming",
+                "MessageStatusCompleted",
+                "MessageStatusFailed",
+                "MessageStatusInterrupted"
+            ]
+        },
+        "domain.ModelBudget": {
+            "type": "object",
+            "required": [
+                "exceeded_action",
+                "model_id"
+            ],
+            "properties": {
+                "completion_price": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "exceeded_action": {
+                    "enum": [
+                        "disable",
+                        "fallback"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.BudgetExceededAction"
+                        }
+                    ]
+                },
+                "exceeded_message": {
+                    "type": "string"
+                },
+                "fallback_model": {
+                    "description": "cheaper model used when exceeded action is fallback",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.FallbackModel"
+                        }
+                    ]
+                },
+                "model_id": {
+                    "type": "string"
+                },
+                "monthly_budget": {
+                    "description": "spend limit per month, 0 means unlimited",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "monthly_token_limit": {
+                    "description": "token limit per month, 0 means unlimited",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "notify_thresholds": {
+                    "description": "usage percentages to notify, e.g. [50, 80, 100]",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "notify_webhook": {
+                    "$ref": "#/definitions/domain.NotifyWebhook"
+                },
+                "prompt_price": {
+                    "description": "price per 1M tokens",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ModelDetailResp": {
+            "type": "object",
+            "properties": {
+                "api_header": {
+                    "type": "string"
+                },
+                "api_key": {
+                    "type": "string"
+                },
+                "api_version": {
+                    "description": "for azure openai",
+                    "type": "string"
+                },
+                "base_url": {
+                    "type": "string"
+                },
+                "completion_tokens": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "priority": {
+                    "type": "integer"
+                },
+                "prompt_tokens": {
+                    "type": "integer"
+                },
+                "provider": {
+                    "$ref": "#/definitions/domain.ModelProvider"
+                },
+                "total_tokens": {
+                    "type": "integer"
+                },
+                "type": {
+                    "$ref": "#/definitions/domain.ModelType"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "weight": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.ModelListItem": {
+            "type": "object",
+            "properties": {
+                "api_header": {
+                    "type": "string"
+                },
+                "api_key": {
+                    "type": "string"
+                },
+                "api_version": {
+                    "description": "for azure openai",
+                    "type": "string"
+                },
+                "base_url": {
+                    "type": "string"
+                },
+                "completion_tokens": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "priority": {
+                    "type": "integer"
+                },
+                "prompt_tokens": {
+                    "type": "integer"
+                },
+                "provider": {
+                    "$ref": "#/definitions/domain.ModelProvider"
+                },
+                "total_tokens": {
+                    "type": "integer"
+                },
+                "type": {
+                    "$ref": "#/definitions/domain.ModelType"
+                },
+                "weight": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.ModelProvider": {
+            "type": "string",
+            "enum": [
+                "OpenAI",
+                "Ollama",
+                "DeepSeek",
+                "Moonshot",
+                "SiliconFlow",
+                "AzureOpenAI",
+                "BaiZhiCloud",
+                "Hunyuan",
+                "BaiLian",
+                "Volcengine",
+                "vLLM",
+                "Other"
+            ],
+            "x-enum-comments": {
+                "ModelProviderBrandBaiLian": "qwen models of dashscope, by its openai compatible mode",
+                "ModelProviderBrandVLLM": "self-hosted vllm server"
+            },
+            "x-enum-varnames": [
+                "ModelProviderBrandOpenAI",
+                "ModelProviderBrandOllama",
+                "ModelProviderBrandDeepSeek",
+                "ModelProviderBrandMoonshot",
+                "ModelProviderBrandSiliconFlow",
+                "ModelProviderBrandAzureOpenAI",
+                "ModelProviderBrandBaiZhiCloud",
+                "ModelProviderBrandHunyuan",
+                "ModelProviderBrandBaiLian",
+                "ModelProviderBrandVolcengine",
+                "ModelProviderBrandVLLM",
+                "ModelProviderBrandOther"
+            ]
+        },
+        "domain.ModelSpendResp": {
+            "type": "object",
+            "properties": {
+                "completion_tokens": {
+                    "type": "integer"
+                },
+                "cost": {
+                    "type": "number"
+                },
+                "exceeded": {
+                    "type": "boolean"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "model_id": {
+                    "type": "string"
+                },
+                "month": {
+                    "type": "string"
+                },
+                "monthly_budget": {
+                    "type": "number"
+                },
+                "monthly_token_limit": {
+                    "type": "integer"
+                },
+                "prompt_tokens": {
+                    "type": "integer"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "total_tokens": {
+                    "type": "integer"
+                },
+                "usage_percent": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.ModelType": {
+            "type": "string",
+            "enum": [
+                "chat",
+                "embedding",
+                "rerank",
+                "asr"
+            ],
+            "x-enum-comments": {
+                "ModelTypeASR": "speech recognition model transcribing imported audio and video"
+            },
+            "x-enum-varnames": [
+                "ModelTypeChat",
+                "ModelTypeEmbedding",
+                "ModelTypeRerank",
+                "ModelTypeASR"
+            ]
+        },
+        "domain.ModerateNodeCommentsReq": {
+            "type": "object",
+            "required": [
+                "ids",
+                "kb_id",
+                "status"
+            ],
+            "properties": {
+                "ids": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "enum": [
+                        "approved",
+                        "rejected",
+                        "spam"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.NodeCommentStatus"
+                        }
+                    ]
+                }
+            }
+        },
+        "domain.MoveNodeReq": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "next_id": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "type": "string"
+                },
+                "prev_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.NearDuplicateDetectReq": {
+            "type": "object",
+            "required": [
+                "kb_id"
+            ],
+            "properties": {
+                "kb_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.NearDuplicateDismissReq": {
+            "type": "object",
+            "required": [
+                "kb_id",
+                "node_id",
+                "other_node_id"
+            ],
+            "properties": {
+                "kb_id": {
+                    "type": "string"
+                },
+                "node_id": {
+                    "type": "string"
+                },
+                "other_node_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.NearDuplicateResp": {
+            "type": "object",
+            "properties": {
+                "detected_at": {
+                    "type": "string"
+                },
+                "dismissed": {
+                    "type": "boolean"
+                },
+                "node_id": {
+                    "type": "string"
+                },
+                "node_name": {
+                    "type": "string"
+                },
+                "other_node_id": {
+                    "type": "string"
+                },
+                "other_node_name": {
+                    "type": "string"
+                },
+                "similarity": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.NodeACLListItem": {
+            "type": "object",
+            "properties": {
+                "access": {
+                    "$ref": "#/definitions/domain.NodeAccess"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "groups": {
+                    "description": "groups allowed to view the node, for groups access",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "node_id": {
+                    "type": "string"
+                },
+                "node_name": {
+                    "type": "string"
+                },
+                "node_type": {
+                    "$ref": "#/definitions/domain.NodeType"
+                },
+                "subtree": {
+                    "description": "the rule also covers all descendants of the node, which must pass it besides their own rules",
+                    "type": "boolean"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.NodeAccess": {
+            "type": "string",
+            "enum": [
+                "public",
+                "logged_in",
+                "groups"
+            ],
+            "x-enum-varnames": [
+                "NodeAccessPublic",
+                "NodeAccessLoggedIn",
+                "NodeAccessGroups"
+            ]
+        },
+        "domain.NodeActionReq": {
+            "type": "object",
+            "required": [
+                "action",
+                "ids",
+                "kb_id"
+            ],
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "enum": [
+                        "delete",
+                        "private",
+                        "public"
+                    ]
+                },
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "kb_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.NodeAttachmentListItem": {
+            "type": "object",
+            "properties": {
+                "content_type": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "node_id": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "url": {
+                    "description": "signed download url",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.NodeBacklinkResp": {
+            "type": "object",
+            "properties": {
+                "emoji": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.NodeStatus"
+                },
+                "type": {
+                    "$ref": "#/definitions/domain.NodeType"
+                },
+                "visibility": {
+                    "$ref": "#/definitions/domain.NodeVisibility"
+                }
+            }
+        },
+        "domain.NodeCommentListItem": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "moderated_at": {
+                    "type": "string"
+                },
+                "moderated_by": {
+                    "type": "string"
+                },
+                "nick_name": {
+                    "type": "string"
+                },
+                "node_id": {
+                    "type": "string"
+                },
+                "node_name": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "description": "replied comment, root_id is the first comment of the thread",
+                    "type": "string"
+                },
+                "remote_ip": {
+                    "type": "string"
+                },
+                "root_id": {
+                    "type": "string"
+                },
+                "spam_reason": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.NodeCommentStatus"
+                },
+                "user_id": {
+                    "description": "set for replies of admins",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.NodeCommentStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "approved",
+                "rejected",
+                "spam"
+            ],
+            "x-enum-varnames": [
+                "NodeCommentStatusPending",
+                "NodeCommentStatusApproved",
+                "NodeCommentStatusRejected",
+                "NodeCommentStatusSpam"
+            ]
+        },
+        "domain.NodeCotentChunkSSE": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "node_id": {
+                    "type": "string"
+                },
+                "summary": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.NodeDefaults": {
+            "type": "object",
+            "properties": {
+                "seo": {
+                    "$ref": "#/definitions/domain.NodeSEO"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "template": {
+                    "description": "initial content of new documents",
+                    "type": "string"
+                },
+                "visibility": {
+                    "enum": [
+                        1,
+                        2
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.NodeVisibility"
+                        }
+                    ]
+                }
+            }
+        },
+        "domain.NodeDefaultsResp": {
+            "type": "object",
+            "properties": {
+                "defaults": {
+                    "description": "defaults set on the folder",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.NodeDefaults"
+                        }
+                    ]
+                },
+                "effective": {
+                    "description": "defaults inherited from upper folders merged with the folder defaults",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.NodeDefaults"
+                        }
+                    ]
+                }
+            }
+        },
+        "domain.NodeDetailResp": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "defaults": {
+                    "$ref": "#/definitions/domain.NodeDefaults"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "inherited_fields": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "meta": {
+                    "$ref": "#/definitions/domain.NodeMeta"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "type": "string"
+                },
+                "reviewed_at": {
+                    "type": "string"
+                },
+                "reviewed_by": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.NodeStatus"
+                },
+                "type": {
+                    "$ref": "#/definitions/domain.NodeType"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "visibility": {
+                    "$ref": "#/definitions/domain.NodeVisibility"
+                }
+            }
+        },
+        "domain.NodeDuplicate": {
+            "type": "object",
+            "properties": {
+                "kb_id": {
+                    "type": "string"
+                },
+                "kb_name": {
+                    "type": "string"
+                },
+                "node_id": {
+                    "type": "string"
+                },
+                "node_name": {
+                    "type": "string"
+                },
+                "same_kb": {
+                    "type": "boolean"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.NodeDuplicateCheckItem": {
+            "type": "object",
+            "required": [
+                "key"
+            ],
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "key": {
+                    "description": "key of the item in the import, e.g. source url, returned with its duplicates",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.NodeDuplicateCheckReq": {
+            "type": "object",
+            "required": [
+                "items",
+                "kb_id"
+            ],
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "maxItems": 500,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/domain.NodeDuplicateCheckItem"
+                    }
+                },
+                "kb_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.NodeDuplicateCheckResp": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "description": "only items with duplicates are returned",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.NodeDuplicateCheckResult"
+                    }
+                }
+            }
+        },
+        "domain.NodeDuplicateCheckResult": {
+            "type": "object",
+            "properties": {
+                "duplicates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.NodeDuplicate"
+                    }
+                },
+                "key": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.NodeExportJob": {
+            "type": "object",
+            "properties": {
+                "attachment_count": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "node_count": {
+                    "type": "integer"
+                },
+                "size": {
+                    "description": "archive size in bytes",
+                    "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.NodeExportJobStatus"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "description": "signed download url of the archive, set when succeeded",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.NodeExportJobStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "running",
+                "succeeded",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "NodeExportJobStatusPending",
+                "NodeExportJobStatusRunning",
+                "NodeExportJobStatusSucceeded",
+                "NodeExportJobStatusFailed"
+            ]
+        },
+        "domain.NodeExportReq": {
+            "type": "object",
+            "required": [
+                "kb_id"
+            ],
+            "properties": {
+                "kb_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.NodeListItemResp": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "emoji": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "type": "string"
+                },
+                "position": {
+                    "type": "number"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.NodeStatus"
+                },
+                "summary": {
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/domain.NodeType"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "visibility": {
+                    "$ref": "#/definitions/domain.NodeVisibility"
+                }
+            }
+        },
+        "domain.NodeMeta": {
+            "type": "object",
+            "properties": {
+                "emoji": {
+                    "type": "string"
+                },
+                "seo": {
+                    "$ref": "#/definitions/domain.NodeSEO"
+                },
+                "summary": {
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "domain.NodeReplaceJob": {
+            "type": "object",
+            "properties": {
+                "case_sensitive": {
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "find": {
+                    "type": "string"
+                },
+                "folder_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_regex": {
+                    "type": "boolean"
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "node_count": {
+                    "type": "integer"
+                },
+                "replace": {
+                    "type": "string"
+                },
+                "replace_count": {
+                    "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.NodeReplaceJobStatus"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.NodeReplaceJobReq": {
+            "type": "object",
+            "required": [
+                "job_id",
+                "kb_id"
+            ],
+            "properties": {
+                "job_id": {
+                    "type": "string"
+                },
+                "kb_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.NodeReplaceJobStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "running",
+                "succeeded",
+                "failed",
+                "rolled_back"
+            ],
+            "x-enum-varnames": [
+                "NodeReplaceJobStatusPending",
+                "NodeReplaceJobStatusRunning",
+                "NodeReplaceJobStatusSucceeded",
+                "NodeReplaceJobStatusFailed",
+                "NodeReplaceJobStatusRolledBack"
+            ]
+        },
+        "domain.NodeReplacePreviewItem": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "match_count": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "samples": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.NodeReplaceSample"
+                    }
+                }
+            }
+        },
+        "domain.NodeReplacePreviewResp": {
+            "type": "object",
+            "properties": {
+                "match_count": {
+                    "type": "integer"
+                },
+                "node_count": {
+                    "type": "integer"
+                },
+                "nodes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.NodeReplacePreviewItem"
+                    }
+                }
+            }
+        },
+        "domain.NodeReplaceReq": {
+            "type": "object",
+            "required": [
+                "find",
+                "kb_id"
+            ],
+            "properties": {
+                "case_sensitive": {
+                    "type": "boolean"
+                },
+                "find": {
+                    "type": "string"
+                },
+                "folder_ids": {
+                    "description": "documents in these folders and their sub folders, whole kb if empty",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "is_regex": {
+                    "type": "boolean"
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "replace": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.NodeReplaceRollbackResp": {
+            "type": "object",
+            "properties": {
+                "restored_count": {
+                    "type": "integer"
+                },
+                "skipped_node_ids": {
+                    "description": "nodes edited after the job are not restored",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "domain.NodeReplaceSample": {
+            "type": "object",
+            "properties": {
+                "after": {
+                    "type": "string"
+                },
+                "before": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.NodeReview": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "node_id": {
+                    "type": "string"
+                },
+                "node_name": {
+                    "type": "string"
+                },
+                "node_updated_at": {
+                    "description": "version of the node under review",
+                    "type": "string"
+                },
+                "review_comment": {
+                    "type": "string"
+                },
+                "reviewed_at": {
+                    "type": "string"
+                },
+                "reviewer_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.NodeReviewStatus"
+                },
+                "submit_comment": {
+                    "type": "string"
+                },
+                "submitter_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.NodeReviewStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "approved",
+                "rejected",
+                "cancelled"
+            ],
+            "x-enum-varnames": [
+                "NodeReviewStatusPending",
+                "NodeReviewStatusApproved",
+                "NodeReviewStatusRejected",
+                "NodeReviewStatusCancelled"
+            ]
+        },
+        "domain.NodeSEO": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "keywords": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.NodeSearchResult": {
+            "type": "object",
+            "properties": {
+                "emoji": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "summary": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.NodeStaleItem": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "type": "string"
+                },
+                "reviewed_at": {
+                    "type": "string"
+                },
+                "reviewed_by": {
+                    "description": "account of the user who last reviewed it",
+                    "type": "string"
+                },
+                "stale_days": {
+                    "description": "days since the later of the last update and the last review",
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "views": {
+                    "description": "views in the last NodeStaleViewDays days",
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.NodeStatResp": {
+            "type": "object",
+            "properties": {
+                "avg_dwell": {
+                    "description": "milliseconds",
+                    "type": "integer"
+                },
+                "citations": {
+                    "type": "integer"
+                },
+                "node_id": {
+                    "type": "string"
+                },
+                "node_name": {
+                    "type": "string"
+                },
+                "views": {
+                    "type": "integer"
+                },
+                "visitors": {
+                    "description": "sum of daily unique visitors",
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.NodeStatus": {
+            "type": "integer",
+            "enum": [
+                1,
+                2
+            ],
+            "x-enum-varnames": [
+                "NodeStatusDraft",
+                "NodeStatusReleased"
+            ]
+        },
+        "domain.NodeSummaryReq": {
+            "type": "object",
+            "required": [
+                "ids",
+                "kb_id"
+            ],
+            "properties": {
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "kb_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.NodeTemplate": {
+            "type": "object",
+            "properties": {
+                "builtin": {
+                    "type": "boolean"
+                },
+                "category": {
+                    "type": "string"
+                },
+                "children": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.NodeTemplateItem"
+                    }
+                },
+                "content": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "emoji": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/domain.NodeType"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.NodeTemplateItem": {
+            "type": "object",
+            "required": [
+                "name",
+                "type"
+            ],
+            "properties": {
+                "children": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.NodeTemplateItem"
+                    }
+                },
+                "content": {
+                    "type": "string"
+                },
+                "emoji": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "type": {
+                    "enum": [
+                        1,
+                        2
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.NodeType"
+                        }
+                    ]
+                }
+            }
+        },
+        "domain.NodeTemplateListItem": {
+            "type": "object",
+            "properties": {
+                "builtin": {
+                    "type": "boolean"
+                },
+                "category": {
+                    "type": "string"
+                },
+                "children": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.NodeTemplateItem"
+                    }
+                },
+                "content": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "emoji": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "placeholders": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "type": {
+                    "$ref": "#/definitions/domain.NodeType"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.NodeType": {
+            "type": "integer",
+            "enum": [
+                1,
+                2
+            ],
+            "x-enum-varnames": [
+                "NodeTypeFolder",
+                "NodeTypeDocument"
+            ]
+        },
+        "domain.NodeViewer": {
+            "type": "object",
+            "properties": {
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.NodeVisibility": {
+            "type": "integer",
+            "enum": [
+                1,
+                2
+            ],
+            "x-enum-varnames": [
+                "NodeVisibilityPrivate",
+                "NodeVisibilityPublic"
+            ]
+        },
+        "domain.NotifyWebhook": {
+            "type": "object",
+            "properties": {
+                "secret": {
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/domain.NotifyWebhookType"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.NotifyWebhookType": {
+            "type": "string",
+            "enum": [
+                "dingtalk",
+                "feishu"
+            ],
+            "x-enum-varnames": [
+                "NotifyWebhookTypeDingTalk",
+                "NotifyWebhookTypeFeishu"
+            ]
+        },
+        "domain.NotionSettings": {
+            "type": "object",
+            "required": [
+                "page_ids"
+            ],
+            "properties": {
+                "page_ids": {
+                    "description": "ids or urls of the selected pages and databases, imported with the pages nested in them",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "token": {
+                    "description": "internal integration token, the selected pages must be shared with the integration",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.NotnionGetListReq": {
+            "type": "object",
+            "properties": {
+                "cation_title": {
+                    "type": "string"
+                },
+                "integration": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.OIDCStatusResp": {
+            "type": "object",
+            "properties": {
+                "display_name": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "password_login": {
+                    "description": "false if only the built-in admin account may log in with password",
+                    "type": "boolean"
+                }
+            }
+        },
+        "domain.ObjectUploadResp": {
+            "type": "object",
+            "properties": {
+                "key": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.OnboardingChecklistItem": {
+            "type": "object",
+            "properties": {
+                "done": {
+                    "type": "boolean"
+                },
+                "step": {
+                    "$ref": "#/definitions/domain.OnboardingStep"
+                }
+            }
+        },
+        "domain.OnboardingChecklistResp": {
+            "type": "object",
+            "properties": {
+                "completed": {
+                    "type": "boolean"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.OnboardingChecklistItem"
+                    }
+                },
+                "kb_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.OnboardingStep": {
+            "type": "string",
+            "enum": [
+                "model_configured",
+                "content_imported",
+                "content_released",
+                "app_published"
+            ],
+            "x-enum-varnames": [
+                "OnboardingStepModelConfigured",
+                "OnboardingStepContentImported",
+                "OnboardingStepContentReleased",
+                "OnboardingStepAppPublished"
+            ]
+        },
+        "domain.OpenAPISettings": {
+            "type": "object",
+            "required": [
+                "url"
+            ],
+            "properties": {
+                "token": {
+                    "description": "sent as bearer token if the spec is not public",
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.OverdueNode": {
+            "type": "object",
+            "properties": {
+                "fresh_at": {
+                    "description": "later of the last update and the last review",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "overdue_days": {
+                    "type": "integer"
+                },
+                "owner_id": {
+                    "type": "string"
+                },
+                "reminded_at": {
+                    "type": "string"
+                },
+                "review_interval_days": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.OwnerOverdueNodes": {
+            "type": "object",
+            "properties": {
+                "nodes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.OverdueNode"
+                    }
+                },
+                "owner_account": {
+                    "type": "string"
+                },
+                "owner_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Page": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.PageInfo": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ParseURLItem": {
+            "type": "object",
+            "properties": {
+                "desc": {
+                    "type": "string"
+                },
+                "published": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ParseURLReq": {
+            "type": "object",
+            "required": [
+                "url"
+            ],
+            "properties": {
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ParseURLResp": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ParseURLItem"
+                    }
+                }
+            }
+        },
+        "domain.PlatformStatResp": {
+            "type": "object",
+            "properties": {
+                "browser": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.BrowserCount"
+                    }
+                },
+                "device": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.BrowserCount"
+                    }
+                },
+                "os": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.BrowserCount"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.PrefetchRetrievalReq": {
+            "type": "object",
+            "required": [
+                "message"
+            ],
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.PrefetchRetrievalResp": {
+            "type": "object",
+            "properties": {
+                "ready": {
+                    "description": "documents of the draft are cached for the message sent with the same question,\nfalse if the draft is too short or the ip prefetches too often",
+                    "type": "boolean"
+                }
+            }
+        },
+        "domain.PreviewAppPromptReq": {
+            "type": "object",
+            "required": [
+                "app_id"
+            ],
+            "properties": {
+                "app_id": {
+                    "type": "string"
+                },
+                "content": {
+                    "description": "template to preview, the built-in prompt if empty",
+                    "type": "string",
+                    "maxLength": 20000
+                },
+                "locale": {
+                    "type": "string"
+                },
+                "question": {
+                    "description": "answered with the template by the chat model of the kb if set",
+                    "type": "string",
+                    "maxLength": 2000
+                }
+            }
+        },
+        "domain.PreviewAppPromptResp": {
+            "type": "object",
+            "properties": {
+                "answer": {
+                    "description": "answer of the question, error of the model if it failed",
+                    "type": "string"
+                },
+                "documents": {
+                    "description": "documents retrieved for the question",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.NodeCotentChunkSSE"
+                    }
+                },
+                "error": {
+                    "type": "string"
+                },
+                "prompt": {
+                    "description": "system prompt of the template",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.PreviewImportSourceReq": {
+            "type": "object",
+            "required": [
+                "kb_id"
+            ],
+            "properties": {
+                "confluence": {
+                    "$ref": "#/definitions/domain.ConfluenceSettings"
+                },
+                "feed": {
+                    "$ref": "#/definitions/domain.FeedSettings"
+                },
+                "feishu": {
+                    "$ref": "#/definitions/domain.FeishuSettings"
+                },
+                "git": {
+                    "$ref": "#/definitions/domain.GitSettings"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "notion": {
+                    "$ref": "#/definitions/domain.NotionSettings"
+                },
+                "openapi": {
+                    "$ref": "#/definitions/domain.OpenAPISettings"
+                },
+                "type": {
+                    "enum": [
+                        "confluence",
+                        "notion",
+                        "feishu",
+                        "yuque",
+                        "git",
+                        "openapi",
+                        "website",
+                        "feed"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ImportSourceType"
+                        }
+                    ]
+                },
+                "website": {
+                    "$ref": "#/definitions/domain.WebsiteSettings"
+                },
+                "yuque": {
+                    "$ref": "#/definitions/domain.YuqueSettings"
+                }
+            }
+        },
+        "domain.PreviewWebhookReq": {
+            "type": "object",
+            "required": [
+                "event"
+            ],
+            "properties": {
+                "event": {
+                    "enum": [
+                        "conversation.created",
+                        "conversation.message",
+                        "anomaly.detected"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.WebhookEventType"
+                        }
+                    ]
+                },
+                "format": {
+                    "enum": [
+                        "template",
+                        "jsonpath"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.WebhookFormat"
+                        }
+                    ]
+                },
+                "mapping": {
+                    "$ref": "#/definitions/domain.StringMap"
+                },
+                "template": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.PreviewWebhookResp": {
+            "type": "object",
+            "properties": {
+                "event": {
+                    "$ref": "#/definitions/domain.WebhookEvent"
+                },
+                "payload": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.PromptMessage": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.PromptVariable": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ProvenanceSettings": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "description": "record provenance on answers and send it to the client as a provenance event before done",
+                    "type": "boolean"
+                }
+            }
+        },
+        "domain.ProviderModelListItem": {
+            "type": "object",
+            "properties": {
+                "model": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.PublishNodeReq": {
+            "type": "object",
+            "required": [
+                "kb_id",
+                "node_ids"
+            ],
+            "properties": {
+                "kb_id": {
+                    "type": "string"
+                },
+                "message": {
+                    "description": "release message, generated if empty",
+                    "type": "string"
+                },
+                "node_ids": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "domain.PublishedNodeResp": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "has_draft": {
+                    "description": "whether the node has changes not published yet",
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "meta": {
+                    "$ref": "#/definitions/domain.NodeMeta"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "node_id": {
+                    "type": "string"
+                },
+                "published_at": {
+                    "type": "string"
+                },
+                "visibility": {
+                    "$ref": "#/definitions/domain.NodeVisibility"
+                }
+            }
+        },
+        "domain.PushSandboxNodesReq": {
+            "type": "object",
+            "required": [
+                "kb_id",
+                "nodes"
+            ],
+            "properties": {
+                "kb_id": {
+                    "type": "string"
+                },
+                "nodes": {
+                    "type": "array",
+                    "maxItems": 200,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/domain.SandboxNode"
+                    }
+                }
+            }
+        },
+        "domain.PushSandboxNodesResp": {
+            "type": "object",
+            "properties": {
+                "node_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "release_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.QuestionClusterResp": {
+            "type": "object",
+            "properties": {
+                "cluster_id": {
+                    "type": "string"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "questions": {
+                    "description": "most asked paraphrases in the cluster",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "domain.QuestionRoute": {
+            "type": "string",
+            "enum": [
+                "rag",
+                "chitchat",
+                "blocked",
+                "cache",
+                "guardrail"
+            ],
+            "x-enum-comments": {
+                "QuestionRouteBlocked": "refused by the content policy of the kb",
+                "QuestionRouteCache": "served the cached answer of a highly similar question, see AnswerCacheSettings",
+                "QuestionRouteChitChat": "greetings and small talk replied with a canned reply",
+                "QuestionRouteGuardrail": "refused by a guardrail check of the app, see GuardrailSettings",
+                "QuestionRouteRAG": "retrieval and the chat model, including low confidence replies"
+            },
+            "x-enum-varnames": [
+                "QuestionRouteRAG",
+                "QuestionRouteChitChat",
+                "QuestionRouteBlocked",
+                "QuestionRouteCache",
+                "QuestionRouteGuardrail"
+            ]
+        },
+        "domain.Reader": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "disabled": {
+                    "description": "disabled readers can not log in and their tokens are rejected",
+                    "type": "boolean"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "last_login_at": {
+                    "type": "string"
+                },
+                "ldap_dn": {
+                    "description": "dn of readers logging in with the ldap directory, their groups are those of the directory",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ReaderAccess": {
+            "type": "object",
+            "properties": {
+                "groups": {
+                    "description": "groups of viewers allowed if private, any logged in viewer if empty",
+                    "type": "array",
+                    "maxItems": 50,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "private": {
+                    "description": "only logged in viewers, reader accounts or sso users, may use the public site, search and chat.\nbot apps are anonymous and answer without documents of a private kb",
+                    "type": "boolean"
+                }
+            }
+        },
+        "domain.ReaderLoginReq": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ReaderLoginResp": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "reader": {
+                    "$ref": "#/definitions/domain.Reader"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.RecommendNodeListResp": {
+            "type": "object",
+            "properties": {
+                "emoji": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "type": "string"
+                },
+                "position": {
+                    "type": "number"
+                },
+                "recommend_nodes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.RecommendNodeListResp"
+                    }
+                },
+                "summary": {
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/domain.NodeType"
+                }
+            }
+        },
+        "domain.RejectNodeReviewReq": {
+            "type": "object",
+            "required": [
+                "comment",
+                "id",
+                "kb_id"
+            ],
+            "properties": {
+                "comment": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kb_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ReplyNodeCommentReq": {
+            "type": "object",
+            "required": [
+                "content",
+                "kb_id",
+                "parent_id"
+            ],
+            "properties": {
+                "content": {
+                    "type": "string",
+                    "maxLength": 2000
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.RerankScore": {
+            "type": "object",
+            "properties": {
+                "node_id": {
+                    "type": "string"
+                },
+                "rank_after": {
+                    "description": "rank and best chunk relevance from the reranker",
+                    "type": "integer"
+                },
+                "rank_before": {
+                    "description": "rank and best chunk similarity from retrieval",
+                    "type": "integer"
+                },
+                "score_after": {
+                    "type": "number"
+                },
+                "score_before": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.ResetPasswordReq": {
+            "type": "object",
+            "required": [
+                "id",
+                "new_password"
+            ],
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "new_password": {
+                    "type": "string",
+                    "minLength": 8
+                }
+            }
+        },
+        "domain.ResetTwoFactorReq": {
+            "type": "object",
+            "required": [
+                "user_id"
+            ],
+            "properties": {
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Response": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "set when success is false",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ErrorCode"
+                        }
+                    ]
+                },
+                "data": {},
+                "message": {
+                    "type": "string"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "domain.RestoreAppPromptReq": {
+            "type": "object",
+            "required": [
+                "app_id",
+                "version"
+            ],
+            "properties": {
+                "app_id": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer",
+                    "minimum": 1
                 }
             }
         },
-        "domain.AppDetailResp": {
+        "domain.RetentionSettings": {
             "type": "object",
             "properties": {
-                "id": {
-                    "type": "string"
+                "anomaly_days": {
+                    "description": "abnormal chat traffic detected in kbs, anomalies still blocking an ip are kept",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "audit_log_days": {
+                    "description": "entries of the audit log from the start of the chain, the hash of the last removed entry is kept as the\nanchor the remaining chain is verified from",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "conversation_days": {
+                    "description": "conversations with their messages, references and transcript emails, by the start of the conversation",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "funnel_event_days": {
+                    "description": "visit, search, chat and resolution steps of the funnel report",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "geo_stat_days": {
+                    "description": "hourly page visits by location of the geo report",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "guardrail_event_days": {
+                    "description": "matches of the guardrail checks of apps",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "job_run_days": {
+                    "description": "runs of cron jobs shown in the job dashboard",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "message_days": {
+                    "description": "messages of conversations kept longer, by the time of the message",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "revoked_session_days": {
+                    "description": "revoked console sessions by the time they were revoked, expired sessions are removed at once",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "stat_event_days": {
+                    "description": "raw page visits, aggregated stats are kept. at least a day for the realtime stats of the last 24 hours",
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "domain.RetrievalSettings": {
+            "type": "object",
+            "properties": {
+                "hybrid": {
+                    "description": "merge keyword (tsvector) matches with vector results by reciprocal rank fusion,\nso exact error codes and SKUs missed by embeddings are found",
+                    "type": "boolean"
                 },
-                "kb_id": {
-                    "type": "string"
+                "keyword_weight": {
+                    "description": "weight of keyword ranks relative to vector ranks in fusion, 1 when not set",
+                    "type": "number",
+                    "maximum": 10,
+                    "minimum": 0
+                },
+                "query_rewrite": {
+                    "description": "condense follow-up questions with the conversation history into a standalone query before retrieval,\nby the rewrite model of the kb",
+                    "type": "boolean"
                 },
+                "rerank": {
+                    "description": "rerank retrieved chunks by the rerank model of the kb before they are given to the model,\nscores before and after are saved on answers",
+                    "type": "boolean"
+                },
+                "rerank_min_score": {
+                    "description": "chunks the reranker scores below it are dropped, 0-1",
+                    "type": "number",
+                    "maximum": 1,
+                    "minimum": 0
+                },
+                "rerank_top_n": {
+                    "description": "nodes kept after reranking, all if 0",
+                    "type": "integer",
+                    "maximum": 20,
+                    "minimum": 0
+                }
+            }
+        },
+        "domain.RetrievalStage": {
+            "type": "object",
+            "properties": {
                 "name": {
                     "type": "string"
                 },
-                "recommend_nodes": {
+                "nodes": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/domain.RecommendNodeListResp"
+                        "$ref": "#/definitions/domain.RetrievalStageNode"
                     }
+                }
+            }
+        },
+        "domain.RetrievalStageChunk": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string"
                 },
-                "settings": {
-                    "$ref": "#/definitions/domain.AppSettingsResp"
+                "id": {
+                    "type": "string"
                 },
-                "type": {
-                    "$ref": "#/definitions/domain.AppType"
+                "similarity": {
+                    "description": "vector similarity, 0 for keyword matches",
+                    "type": "number"
                 }
             }
         },
-        "domain.AppSettings": {
+        "domain.RetrievalStageNode": {
             "type": "object",
             "properties": {
-                "auto_sitemap": {
-                    "type": "boolean"
+                "chunks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.RetrievalStageChunk"
+                    }
                 },
-                "body_code": {
+                "name": {
                     "type": "string"
                 },
-                "btns": {
-                    "type": "array",
-                    "items": {}
+                "node_id": {
+                    "type": "string"
                 },
-                "catalog_settings": {
-                    "description": "catalog settings",
+                "rerank": {
+                    "description": "scores of the rerank stage, nil before it",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/domain.CatalogSettings"
+                            "$ref": "#/definitions/domain.RerankScore"
                         }
                     ]
+                }
+            }
+        },
+        "domain.RetrievalTrace": {
+            "type": "object",
+            "properties": {
+                "hybrid": {
+                    "type": "boolean"
                 },
-                "desc": {
-                    "description": "seo",
-                    "type": "string"
+                "keyword_query": {
+                    "description": "words and identifiers matched by keyword search, nil if hybrid search is off",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.KeywordQuery"
+                        }
+                    ]
                 },
-                "dingtalk_bot_client_id": {
-                    "description": "DingTalkBot",
+                "query": {
+                    "description": "sent to vector and keyword search, the standalone query if the question was rewritten with the history",
                     "type": "string"
                 },
-                "dingtalk_bot_client_secret": {
+                "rag_rerank_model_id": {
+                    "description": "rerank model of raglite, empty if raglite does not rerank",
                     "type": "string"
                 },
-                "dingtalk_bot_template_id": {
-                    "type": "string"
+                "rerank": {
+                    "type": "boolean"
                 },
-                "discord_bot_token": {
-                    "description": "DisCordBot",
+                "stages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.RetrievalStage"
+                    }
+                }
+            }
+        },
+        "domain.ReviewReminderSettings": {
+            "type": "object",
+            "properties": {
+                "console_url": {
+                    "description": "admin console base url for deep links",
                     "type": "string"
                 },
-                "feishu_bot_app_id": {
-                    "description": "FeishuBot",
-                    "type": "string"
+                "enabled": {
+                    "type": "boolean"
                 },
-                "feishu_bot_app_secret": {
-                    "type": "string"
+                "interval_days": {
+                    "description": "default review interval of owned documents, DefaultReviewIntervalDays if 0",
+                    "type": "integer",
+                    "maximum": 3650,
+                    "minimum": 0
                 },
-                "footer_settings": {
-                    "description": "footer settings",
+                "webhook": {
+                    "description": "push reminders to dingtalk/feishu group, owners are listed by account",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/domain.FooterSettings"
+                            "$ref": "#/definitions/domain.NotifyWebhook"
                         }
                     ]
-                },
-                "head_code": {
-                    "description": "inject code",
-                    "type": "string"
-                },
-                "icon": {
+                }
+            }
+        },
+        "domain.ReviewSettings": {
+            "type": "object",
+            "properties": {
+                "require_review": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "domain.RevokeUserSessionsReq": {
+            "type": "object",
+            "required": [
+                "user_id"
+            ],
+            "properties": {
+                "user_id": {
+                    "description": "members may only revoke their own sessions",
                     "type": "string"
-                },
-                "keyword": {
+                }
+            }
+        },
+        "domain.RewriteImportLinksReq": {
+            "type": "object",
+            "required": [
+                "kb_id",
+                "links"
+            ],
+            "properties": {
+                "kb_id": {
                     "type": "string"
                 },
-                "recommend_node_ids": {
+                "links": {
                     "type": "array",
+                    "minItems": 1,
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/domain.ImportLink"
                     }
+                }
+            }
+        },
+        "domain.RewriteImportLinksResp": {
+            "type": "object",
+            "properties": {
+                "rewrite_count": {
+                    "type": "integer"
                 },
-                "recommend_questions": {
+                "updated_node_ids": {
                     "type": "array",
                     "items": {
                         "type": "string"
                     }
-                },
-                "search_placeholder": {
-                    "type": "string"
-                },
-                "theme_and_style": {
-                    "$ref": "#/definitions/domain.ThemeAndStyle"
-                },
-                "theme_mode": {
-                    "description": "theme",
-                    "type": "string"
-                },
-                "title": {
-                    "description": "nav",
-                    "type": "string"
-                },
-                "wechat_app_agent_id": {
-                    "type": "string"
-                },
-                "wechat_app_corpid": {
-                    "type": "string"
-                },
-                "wechat_app_encodingaeskey": {
+                }
+            }
+        },
+        "domain.RotateAPITokenReq": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "id": {
                     "type": "string"
+                }
+            }
+        },
+        "domain.SandboxEvalQuestion": {
+            "type": "object",
+            "required": [
+                "question"
+            ],
+            "properties": {
+                "expected_answer": {
+                    "description": "reference answer the judge compares the answer with, the answer is judged by the question and documents if empty",
+                    "type": "string",
+                    "maxLength": 10000
                 },
-                "wechat_app_secret": {
-                    "type": "string"
+                "expected_nodes": {
+                    "description": "ids or names of documents one of which must be retrieved in the top k, any document if empty",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "wechat_app_token": {
-                    "description": "WechatAppBot",
-                    "type": "string"
+                "min_confidence": {
+                    "description": "min retrieval score of the question, the confidence gate of the sandbox if not set",
+                    "type": "number",
+                    "maximum": 1,
+                    "minimum": 0
                 },
-                "wechat_service_corpid": {
+                "question": {
                     "type": "string"
-                },
-                "wechat_service_encodingaeskey": {
+                }
+            }
+        },
+        "domain.SandboxEvalReq": {
+            "type": "object",
+            "required": [
+                "kb_id",
+                "questions"
+            ],
+            "properties": {
+                "kb_id": {
                     "type": "string"
                 },
-                "wechat_service_secret": {
-                    "type": "string"
+                "min_pass_rate": {
+                    "description": "share of questions which must pass for the evaluation to pass, all if not set",
+                    "type": "number",
+                    "maximum": 1,
+                    "minimum": 0
                 },
-                "wechat_service_token": {
-                    "description": "WechatServiceBot",
-                    "type": "string"
+                "questions": {
+                    "type": "array",
+                    "maxItems": 50,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/domain.SandboxEvalQuestion"
+                    }
                 },
-                "welcome_str": {
-                    "description": "welcome",
-                    "type": "string"
+                "top_k": {
+                    "description": "retrieved documents checked for expected ones, 3 if not set",
+                    "type": "integer",
+                    "maximum": 20,
+                    "minimum": 1
                 }
             }
         },
-        "domain.AppSettingsResp": {
+        "domain.SandboxEvalResp": {
             "type": "object",
             "properties": {
-                "auto_sitemap": {
+                "pass_rate": {
+                    "type": "number"
+                },
+                "passed": {
                     "type": "boolean"
                 },
-                "body_code": {
-                    "type": "string"
+                "passed_count": {
+                    "type": "integer"
                 },
-                "btns": {
+                "results": {
                     "type": "array",
-                    "items": {}
-                },
-                "catalog_settings": {
-                    "description": "catalog settings",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/domain.CatalogSettings"
-                        }
-                    ]
-                },
-                "desc": {
-                    "description": "seo",
-                    "type": "string"
-                },
-                "dingtalk_bot_client_id": {
-                    "description": "DingTalkBot",
-                    "type": "string"
-                },
-                "dingtalk_bot_client_secret": {
-                    "type": "string"
-                },
-                "dingtalk_bot_template_id": {
-                    "type": "string"
-                },
-                "discord_bot_token": {
-                    "description": "DisCordBot",
-                    "type": "string"
-                },
-                "feishu_bot_app_id": {
-                    "description": "FeishuBot",
-                    "type": "string"
+                    "items": {
+                        "$ref": "#/definitions/domain.SandboxEvalResult"
+                    }
                 },
-                "feishu_bot_app_secret": {
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.SandboxEvalResult": {
+            "type": "object",
+            "properties": {
+                "answer": {
+                    "description": "answer of the chat model, empty if the confidence gate replied reference links only",
                     "type": "string"
                 },
-                "footer_settings": {
-                    "description": "footer settings",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/domain.FooterSettings"
-                        }
-                    ]
+                "faithfulness": {
+                    "description": "scores of the answer by the judge, 0-1",
+                    "type": "number"
                 },
-                "head_code": {
-                    "description": "inject code",
-                    "type": "string"
+                "gated": {
+                    "type": "boolean"
                 },
-                "icon": {
-                    "type": "string"
+                "groundedness": {
+                    "type": "number"
                 },
-                "keyword": {
+                "judge_reason": {
                     "type": "string"
                 },
-                "recommend_node_ids": {
+                "node_ids": {
                     "type": "array",
                     "items": {
                         "type": "string"
                     }
                 },
-                "recommend_questions": {
+                "node_names": {
                     "type": "array",
                     "items": {
                         "type": "string"
                     }
                 },
-                "search_placeholder": {
+                "passed": {
+                    "type": "boolean"
+                },
+                "question": {
                     "type": "string"
                 },
-                "theme_and_style": {
-                    "$ref": "#/definitions/domain.ThemeAndStyle"
+                "reason": {
+                    "description": "why the question failed",
+                    "type": "string"
                 },
-                "theme_mode": {
-                    "description": "theme",
+                "relevance": {
+                    "type": "number"
+                },
+                "retrieval_score": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.SandboxKB": {
+            "type": "object",
+            "properties": {
+                "created_at": {
                     "type": "string"
                 },
-                "title": {
-                    "description": "nav",
+                "expires_at": {
                     "type": "string"
                 },
-                "wechat_app_agent_id": {
+                "id": {
                     "type": "string"
                 },
-                "wechat_app_corpid": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.SandboxNode": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "content": {
                     "type": "string"
                 },
-                "wechat_app_encodingaeskey": {
+                "name": {
                     "type": "string"
+                }
+            }
+        },
+        "domain.SandboxStatusResp": {
+            "type": "object",
+            "properties": {
+                "indexed_count": {
+                    "type": "integer"
                 },
-                "wechat_app_secret": {
+                "node_count": {
+                    "type": "integer"
+                },
+                "ready": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "domain.SaveAppPromptReq": {
+            "type": "object",
+            "required": [
+                "app_id"
+            ],
+            "properties": {
+                "app_id": {
                     "type": "string"
                 },
-                "wechat_app_token": {
-                    "description": "WechatAppBot",
+                "content": {
+                    "description": "empty resets the app to the built-in prompt",
+                    "type": "string",
+                    "maxLength": 20000
+                },
+                "note": {
+                    "type": "string",
+                    "maxLength": 200
+                }
+            }
+        },
+        "domain.ScrapeReq": {
+            "type": "object",
+            "required": [
+                "kb_id"
+            ],
+            "properties": {
+                "kb_id": {
                     "type": "string"
                 },
-                "wechat_service_corpid": {
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ScrapeResp": {
+            "type": "object",
+            "properties": {
+                "content": {
                     "type": "string"
                 },
-                "wechat_service_encodingaeskey": {
+                "title": {
                     "type": "string"
+                }
+            }
+        },
+        "domain.SearchCompletion": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "description": "times asked of query completions",
+                    "type": "integer"
                 },
-                "wechat_service_secret": {
+                "node_id": {
+                    "description": "node of title completions",
                     "type": "string"
                 },
-                "wechat_service_token": {
-                    "description": "WechatServiceBot",
+                "text": {
                     "type": "string"
                 },
-                "welcome_str": {
-                    "description": "welcome",
+                "type": {
+                    "$ref": "#/definitions/domain.SearchCompletionType"
+                },
+                "url": {
                     "type": "string"
                 }
             }
         },
-        "domain.AppType": {
-            "type": "integer",
+        "domain.SearchCompletionType": {
+            "type": "string",
             "enum": [
-                1,
-                2,
-                3,
-                4,
-                5,
-                6,
-                7
+                "node",
+                "query"
             ],
             "x-enum-varnames": [
-                "AppTypeWeb",
-                "AppTypeWidget",
-                "AppTypeDingTalkBot",
-                "AppTypeFeishuBot",
-                "AppTypeWechatBot",
-                "AppTypeWechatServiceBot",
-                "AppTypeDisCordBot"
+                "SearchCompletionTypeNode",
+                "SearchCompletionTypeQuery"
             ]
         },
-        "domain.BrandGroup": {
+        "domain.SearchDocxReq": {
             "type": "object",
             "properties": {
-                "links": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/domain.Link"
-                    }
+                "app_id": {
+                    "type": "string"
+                },
+                "app_secret": {
+                    "type": "string"
                 },
+                "user_access_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.SearchDocxResp": {
+            "type": "object",
+            "properties": {
                 "name": {
                     "type": "string"
+                },
+                "url": {
+                    "type": "string"
                 }
             }
         },
-        "domain.CatalogSettings": {
+        "domain.SearchWikiReq": {
             "type": "object",
             "properties": {
-                "catalog_folder": {
-                    "description": "1: 展开, 2: 折叠, default: 1",
-                    "type": "integer"
+                "app_id": {
+                    "type": "string"
                 },
-                "catalog_visible": {
-                    "description": "1: 显示, 2: 隐藏, default: 1",
-                    "type": "integer"
+                "app_secret": {
+                    "type": "string"
                 },
-                "catalog_width": {
-                    "description": "200 - 300, default: 260",
-                    "type": "integer"
+                "query": {
+                    "type": "string"
+                },
+                "space_id": {
+                    "type": "string"
+                },
+                "user_access_token": {
+                    "type": "string"
                 }
             }
         },
-        "domain.ChatRequest": {
+        "domain.SearchWikiResp": {
+            "type": "object",
+            "properties": {
+                "space_id": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.SendGapReportReq": {
             "type": "object",
             "required": [
-                "app_type",
-                "message"
+                "kb_id"
             ],
             "properties": {
-                "app_type": {
-                    "enum": [
-                        1,
-                        2,
-                        3
-                    ],
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/domain.AppType"
-                        }
-                    ]
+                "kb_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.SendTranscriptEmailReq": {
+            "type": "object",
+            "required": [
+                "conversation_id",
+                "email",
+                "nonce"
+            ],
+            "properties": {
+                "conversation_id": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "nonce": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.SessionListItem": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "type": "string"
+                },
+                "browser": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "current": {
+                    "description": "the session of the request",
+                    "type": "boolean"
+                },
+                "device": {
+                    "$ref": "#/definitions/domain.DeviceType"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_seen_at": {
+                    "description": "last request with the session, updated every minute",
+                    "type": "string"
+                },
+                "os": {
+                    "type": "string"
                 },
-                "conversation_id": {
+                "remote_ip": {
                     "type": "string"
                 },
-                "message": {
+                "revoked_at": {
                     "type": "string"
                 },
-                "nonce": {
+                "user_agent": {
+                    "type": "string"
+                },
+                "user_id": {
                     "type": "string"
                 }
             }
         },
-        "domain.CheckModelReq": {
+        "domain.SetKBMemberReq": {
             "type": "object",
             "required": [
-                "base_url",
-                "model",
-                "provider",
-                "type"
+                "kb_id",
+                "role",
+                "user_id"
             ],
             "properties": {
-                "api_header": {
-                    "type": "string"
-                },
-                "api_key": {
-                    "type": "string"
-                },
-                "api_version": {
-                    "description": "for azure openai",
-                    "type": "string"
-                },
-                "base_url": {
-                    "type": "string"
-                },
-                "model": {
+                "kb_id": {
                     "type": "string"
                 },
-                "provider": {
+                "role": {
                     "enum": [
-                        "OpenAI",
-                        "Ollama",
-                        "DeepSeek",
-                        "SiliconFlow",
-                        "Moonshot",
-                        "Other",
-                        "AzureOpenAI",
-                        "BaiZhiCloud",
-                        "Hunyuan",
-                        "BaiLian",
-                        "Volcengine"
+                        "owner",
+                        "editor",
+                        "analyst",
+                        "support_agent"
                     ],
                     "allOf": [
                         {
-                            "$ref": "#/definitions/domain.ModelProvider"
+                            "$ref": "#/definitions/domain.KBRole"
                         }
                     ]
                 },
-                "type": {
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.SetNodeACLReq": {
+            "type": "object",
+            "required": [
+                "access",
+                "kb_id",
+                "node_ids"
+            ],
+            "properties": {
+                "access": {
                     "enum": [
-                        "chat",
-                        "embedding",
-                        "rerank"
+                        "public",
+                        "logged_in",
+                        "groups"
                     ],
                     "allOf": [
                         {
-                            "$ref": "#/definitions/domain.ModelType"
+                            "$ref": "#/definitions/domain.NodeAccess"
                         }
                     ]
+                },
+                "groups": {
+                    "description": "required for groups access",
+                    "type": "array",
+                    "maxItems": 50,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "kb_id": {
+                    "type": "string"
+                },
+                "node_ids": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "subtree": {
+                    "type": "boolean"
                 }
             }
         },
-        "domain.CheckModelResp": {
+        "domain.SetNodeOwnerReq": {
             "type": "object",
+            "required": [
+                "kb_id",
+                "node_ids"
+            ],
             "properties": {
-                "content": {
+                "kb_id": {
                     "type": "string"
                 },
-                "error": {
+                "node_ids": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "owner_id": {
+                    "description": "empty to remove the owner",
                     "type": "string"
+                },
+                "review_interval_days": {
+                    "description": "0 for the interval of the kb",
+                    "type": "integer",
+                    "maximum": 3650,
+                    "minimum": 0
                 }
             }
         },
-        "domain.ConversationDetailResp": {
+        "domain.ShareNodeComment": {
             "type": "object",
             "properties": {
-                "app_id": {
+                "content": {
                     "type": "string"
                 },
                 "created_at": {
@@ -2843,752 +19325,899 @@ const docTemplate = `{
                 "id": {
                     "type": "string"
                 },
-                "ip_address": {
-                    "$ref": "#/definitions/domain.IPAddress"
+                "is_staff": {
+                    "type": "boolean"
                 },
-                "messages": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/domain.ConversationMessage"
-                    }
+                "nick_name": {
+                    "type": "string"
                 },
-                "references": {
+                "parent_id": {
+                    "type": "string"
+                },
+                "replies": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/domain.ConversationReference"
+                        "$ref": "#/definitions/domain.ShareNodeComment"
                     }
+                }
+            }
+        },
+        "domain.SimpleAuth": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
                 },
-                "remote_ip": {
-                    "type": "string"
-                },
-                "subject": {
+                "password": {
                     "type": "string"
                 }
             }
         },
-        "domain.ConversationListItem": {
+        "domain.SourceAttributionItem": {
             "type": "object",
             "properties": {
-                "app_name": {
-                    "type": "string"
-                },
-                "app_type": {
-                    "$ref": "#/definitions/domain.AppType"
+                "answer_count": {
+                    "type": "integer"
                 },
-                "created_at": {
-                    "type": "string"
+                "conversation_count": {
+                    "type": "integer"
                 },
-                "id": {
-                    "type": "string"
+                "dislike_count": {
+                    "type": "integer"
                 },
-                "ip_address": {
-                    "$ref": "#/definitions/domain.IPAddress"
+                "like_count": {
+                    "type": "integer"
                 },
-                "remote_ip": {
+                "name": {
                     "type": "string"
                 },
-                "subject": {
+                "node_id": {
                     "type": "string"
+                },
+                "satisfaction_rate": {
+                    "description": "likes of rated answers, 0 if none is rated",
+                    "type": "number"
                 }
             }
         },
-        "domain.ConversationMessage": {
+        "domain.SourceAttributionReport": {
             "type": "object",
             "properties": {
-                "app_id": {
-                    "type": "string"
-                },
-                "completion_tokens": {
+                "answer_count": {
+                    "description": "answers of the period and how they were rated",
                     "type": "integer"
                 },
-                "content": {
-                    "type": "string"
+                "days": {
+                    "type": "integer"
                 },
-                "conversation_id": {
-                    "type": "string"
+                "dislike_count": {
+                    "type": "integer"
                 },
-                "created_at": {
-                    "type": "string"
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.SourceAttributionItem"
+                    }
                 },
+                "like_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.StaleNode": {
+            "type": "object",
+            "properties": {
                 "id": {
                     "type": "string"
                 },
-                "model": {
+                "name": {
                     "type": "string"
                 },
-                "prompt_tokens": {
-                    "type": "integer"
-                },
-                "provider": {
-                    "description": "model",
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.StatFunnelEventReq": {
+            "type": "object",
+            "required": [
+                "step"
+            ],
+            "properties": {
+                "step": {
+                    "enum": [
+                        "search",
+                        "resolution"
+                    ],
                     "allOf": [
                         {
-                            "$ref": "#/definitions/domain.ModelProvider"
+                            "$ref": "#/definitions/domain.FunnelStep"
                         }
                     ]
+                }
+            }
+        },
+        "domain.StatNodeDwellReq": {
+            "type": "object",
+            "required": [
+                "duration",
+                "node_id"
+            ],
+            "properties": {
+                "duration": {
+                    "description": "milliseconds on page",
+                    "type": "integer",
+                    "minimum": 1
                 },
-                "remote_ip": {
-                    "description": "stats",
+                "node_id": {
                     "type": "string"
-                },
-                "role": {
-                    "$ref": "#/definitions/schema.RoleType"
-                },
-                "total_tokens": {
-                    "type": "integer"
                 }
             }
         },
-        "domain.ConversationReference": {
+        "domain.StatPageReq": {
             "type": "object",
+            "required": [
+                "scene"
+            ],
             "properties": {
-                "app_id": {
+                "node_id": {
                     "type": "string"
                 },
-                "conversation_id": {
+                "referer": {
+                    "description": "document.referrer of the page, fallback to Referer header if empty",
                     "type": "string"
                 },
-                "name": {
-                    "type": "string"
+                "scene": {
+                    "enum": [
+                        1,
+                        2,
+                        3,
+                        4
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.StatPageScene"
+                        }
+                    ]
                 },
-                "node_id": {
+                "url": {
+                    "description": "location.href of the page, utm parameters are parsed from it",
                     "type": "string"
+                }
+            }
+        },
+        "domain.StatPageScene": {
+            "type": "integer",
+            "enum": [
+                1,
+                2,
+                3,
+                4
+            ],
+            "x-enum-varnames": [
+                "StatPageSceneWelcome",
+                "StatPageSceneNodeDetail",
+                "StatPageSceneChat",
+                "StatPageSceneLogin"
+            ]
+        },
+        "domain.StatRealtimeResp": {
+            "type": "object",
+            "properties": {
+                "active_conversation_count": {
+                    "type": "integer"
                 },
-                "url": {
+                "geo": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "requests_per_minute": {
+                    "type": "integer"
+                },
+                "time": {
                     "type": "string"
                 }
             }
         },
-        "domain.CreateKBReleaseReq": {
+        "domain.StatSettings": {
+            "type": "object",
+            "properties": {
+                "filter_bot_traffic": {
+                    "description": "exclude rows marked as bot from page view and conversation stats",
+                    "type": "boolean"
+                }
+            }
+        },
+        "domain.StringMap": {
+            "type": "object",
+            "additionalProperties": {
+                "type": "string"
+            }
+        },
+        "domain.SubmitNodeReviewReq": {
             "type": "object",
             "required": [
                 "kb_id",
-                "message",
-                "tag"
+                "node_id"
             ],
             "properties": {
-                "kb_id": {
+                "comment": {
                     "type": "string"
                 },
-                "message": {
+                "kb_id": {
                     "type": "string"
                 },
-                "node_ids": {
-                    "description": "create release after these nodes published",
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
-                },
-                "tag": {
+                "node_id": {
                     "type": "string"
                 }
             }
         },
-        "domain.CreateKnowledgeBaseReq": {
+        "domain.SyncBotProfilesReq": {
             "type": "object",
             "required": [
-                "name"
+                "kb_id"
             ],
             "properties": {
-                "hosts": {
+                "app_ids": {
+                    "description": "bot apps of kb to sync, all if empty",
                     "type": "array",
                     "items": {
                         "type": "string"
                     }
                 },
-                "name": {
+                "kb_id": {
                     "type": "string"
+                }
+            }
+        },
+        "domain.SyncBotProfilesResp": {
+            "type": "object",
+            "properties": {
+                "profile": {
+                    "$ref": "#/definitions/domain.BotProfile"
                 },
-                "ports": {
+                "results": {
                     "type": "array",
                     "items": {
-                        "type": "integer"
+                        "$ref": "#/definitions/domain.BotProfileSyncResult"
                     }
+                }
+            }
+        },
+        "domain.TelemetryDeployment": {
+            "type": "object",
+            "properties": {
+                "app_count": {
+                    "type": "integer"
                 },
-                "private_key": {
-                    "type": "string"
+                "kb_count": {
+                    "type": "integer"
                 },
-                "public_key": {
-                    "type": "string"
+                "model_count": {
+                    "type": "integer"
                 },
-                "ssl_ports": {
-                    "type": "array",
-                    "items": {
-                        "type": "integer"
-                    }
+                "node_count": {
+                    "type": "integer"
+                },
+                "user_count": {
+                    "type": "integer"
                 }
             }
         },
-        "domain.CreateModelReq": {
+        "domain.TelemetryFeatureUsage": {
             "type": "object",
-            "required": [
-                "base_url",
-                "model",
-                "provider",
-                "type"
-            ],
             "properties": {
-                "api_header": {
-                    "type": "string"
-                },
-                "api_key": {
-                    "type": "string"
-                },
-                "api_version": {
-                    "description": "for azure openai",
-                    "type": "string"
+                "error_rate": {
+                    "type": "number"
                 },
-                "base_url": {
-                    "type": "string"
+                "errors": {
+                    "type": "integer"
                 },
-                "model": {
+                "feature": {
                     "type": "string"
                 },
-                "provider": {
-                    "enum": [
-                        "OpenAI",
-                        "Ollama",
-                        "DeepSeek",
-                        "SiliconFlow",
-                        "Moonshot",
-                        "Other",
-                        "AzureOpenAI",
-                        "BaiZhiCloud",
-                        "Hunyuan",
-                        "BaiLian",
-                        "Volcengine"
-                    ],
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/domain.ModelProvider"
-                        }
-                    ]
-                },
-                "type": {
-                    "enum": [
-                        "chat",
-                        "embedding",
-                        "rerank"
-                    ],
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/domain.ModelType"
-                        }
-                    ]
+                "requests": {
+                    "type": "integer"
                 }
             }
         },
-        "domain.CreateNodeReq": {
+        "domain.TelemetryReport": {
             "type": "object",
-            "required": [
-                "kb_id",
-                "name",
-                "type"
-            ],
             "properties": {
-                "content": {
-                    "type": "string"
+                "deployment": {
+                    "$ref": "#/definitions/domain.TelemetryDeployment"
                 },
-                "emoji": {
-                    "type": "string"
+                "error_rate": {
+                    "type": "number"
                 },
-                "kb_id": {
-                    "type": "string"
+                "errors": {
+                    "type": "integer"
                 },
-                "name": {
+                "features": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.TelemetryFeatureUsage"
+                    }
+                },
+                "generated_at": {
                     "type": "string"
                 },
-                "parent_id": {
+                "instance_id": {
                     "type": "string"
                 },
-                "type": {
-                    "enum": [
-                        1,
-                        2
-                    ],
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/domain.NodeType"
-                        }
-                    ]
+                "requests": {
+                    "type": "integer"
                 },
-                "visibility": {
-                    "$ref": "#/definitions/domain.NodeVisibility"
+                "since": {
+                    "description": "first day of the usage covered, the report covers TelemetryReportDays days up to today",
+                    "type": "string"
                 }
             }
         },
-        "domain.CreateUserReq": {
+        "domain.TelemetrySettingsResp": {
             "type": "object",
-            "required": [
-                "account",
-                "password"
-            ],
             "properties": {
-                "account": {
+                "enabled": {
+                    "description": "aggregate daily usage of api features in the local database, nothing leaves the deployment",
+                    "type": "boolean"
+                },
+                "instance_id": {
+                    "description": "random id of the deployment in reports, generated when telemetry is first enabled",
                     "type": "string"
                 },
-                "password": {
-                    "type": "string",
-                    "minLength": 8
+                "report_enabled": {
+                    "description": "send the report shown to the operator to the report url of the config once a day, requires enabled",
+                    "type": "boolean"
+                },
+                "report_url": {
+                    "description": "upstream collector configured for the deployment, reports can not be sent if empty",
+                    "type": "string"
+                },
+                "reported_at": {
+                    "type": "string"
                 }
             }
         },
-        "domain.DeleteUserReq": {
+        "domain.TextReq": {
             "type": "object",
             "required": [
-                "user_id"
+                "text"
             ],
             "properties": {
-                "user_id": {
+                "action": {
+                    "description": "action: improve, summary, extend, shorten, etc.",
+                    "type": "string"
+                },
+                "text": {
                     "type": "string"
                 }
             }
         },
-        "domain.EpubResp": {
+        "domain.ThemeAndStyle": {
             "type": "object",
             "properties": {
-                "content": {
-                    "type": "string"
-                },
-                "title": {
+                "bg_image": {
                     "type": "string"
                 }
             }
         },
-        "domain.FooterSettings": {
+        "domain.TrafficSource": {
             "type": "object",
             "properties": {
-                "brand_desc": {
+                "referer": {
                     "type": "string"
                 },
-                "brand_groups": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/domain.BrandGroup"
-                    }
+                "referer_host": {
+                    "type": "string"
                 },
-                "brand_logo": {
+                "utm_campaign": {
                     "type": "string"
                 },
-                "brand_name": {
+                "utm_content": {
                     "type": "string"
                 },
-                "corp_name": {
+                "utm_medium": {
                     "type": "string"
                 },
-                "footer_style": {
+                "utm_source": {
                     "type": "string"
                 },
-                "icp": {
+                "utm_term": {
                     "type": "string"
                 }
             }
         },
-        "domain.GetDocsReq": {
+        "domain.TrafficSourceCount": {
             "type": "object",
-            "required": [
-                "kb_id"
-            ],
             "properties": {
-                "integration": {
-                    "type": "string"
+                "count": {
+                    "type": "integer"
                 },
-                "kb_id": {
+                "name": {
                     "type": "string"
-                },
-                "pages": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/domain.PageInfo"
-                    }
                 }
             }
         },
-        "domain.GetDocxReq": {
-            "type": "object",
-            "required": [
-                "kb_id"
+        "domain.TrafficSourceDimension": {
+            "type": "string",
+            "enum": [
+                "referer_host",
+                "utm_source",
+                "utm_medium",
+                "utm_campaign",
+                "utm_term",
+                "utm_content"
             ],
-            "properties": {
-                "app_id": {
-                    "type": "string"
-                },
-                "app_secret": {
-                    "type": "string"
-                },
-                "kb_id": {
-                    "type": "string"
-                },
-                "urls": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
-                },
-                "user_access_token": {
-                    "type": "string"
-                }
-            }
+            "x-enum-varnames": [
+                "TrafficSourceDimensionRefererHost",
+                "TrafficSourceDimensionUTMSource",
+                "TrafficSourceDimensionUTMMedium",
+                "TrafficSourceDimensionUTMCampaign",
+                "TrafficSourceDimensionUTMTerm",
+                "TrafficSourceDimensionUTMContent"
+            ]
         },
-        "domain.GetDocxResp": {
+        "domain.TrafficSourceType": {
+            "type": "string",
+            "enum": [
+                "page",
+                "conversation"
+            ],
+            "x-enum-varnames": [
+                "TrafficSourceTypePage",
+                "TrafficSourceTypeConversation"
+            ]
+        },
+        "domain.TranscriptEmailStatus": {
+            "type": "string",
+            "enum": [
+                "sent",
+                "failed",
+                "rate_limited"
+            ],
+            "x-enum-varnames": [
+                "TranscriptEmailStatusSent",
+                "TranscriptEmailStatusFailed",
+                "TranscriptEmailStatusRateLimited"
+            ]
+        },
+        "domain.TwoFactorChallengeReq": {
             "type": "object",
+            "required": [
+                "challenge_token"
+            ],
             "properties": {
-                "content": {
-                    "type": "string"
-                },
-                "title": {
+                "challenge_token": {
                     "type": "string"
                 }
             }
-        },
-        "domain.GetKBReleaseListResp": {
-            "type": "object",
-            "properties": {
-                "data": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/domain.KBReleaseListItemResp"
-                    }
-                },
-                "total": {
-                    "type": "integer"
+        },
+        "domain.TwoFactorCodeReq": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
                 }
             }
         },
-        "domain.GetProviderModelListResp": {
+        "domain.TwoFactorConfirmResp": {
             "type": "object",
             "properties": {
-                "models": {
+                "backup_codes": {
+                    "description": "shown once, each code logs in once when the authenticator app is lost",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/domain.ProviderModelListItem"
+                        "type": "string"
                     }
+                },
+                "token": {
+                    "description": "console token when enrolling during login",
+                    "type": "string"
                 }
             }
         },
-        "domain.GetSpaceListReq": {
+        "domain.TwoFactorEnrollResp": {
             "type": "object",
             "properties": {
-                "app_id": {
-                    "type": "string"
-                },
-                "app_secret": {
+                "secret": {
+                    "description": "base32 secret for apps which can not scan the uri",
                     "type": "string"
                 },
-                "user_access_token": {
+                "uri": {
+                    "description": "otpauth uri shown as a qr code",
                     "type": "string"
                 }
             }
         },
-        "domain.GetSpaceListResp": {
+        "domain.TwoFactorLoginReq": {
             "type": "object",
+            "required": [
+                "challenge_token",
+                "code"
+            ],
             "properties": {
-                "name": {
+                "challenge_token": {
                     "type": "string"
                 },
-                "space_id": {
+                "code": {
+                    "description": "code of the authenticator app or a backup code",
                     "type": "string"
                 }
             }
         },
-        "domain.IPAddress": {
+        "domain.TwoFactorStatusResp": {
             "type": "object",
             "properties": {
-                "city": {
-                    "type": "string"
+                "backup_codes_left": {
+                    "type": "integer"
                 },
-                "country": {
-                    "type": "string"
+                "enabled": {
+                    "type": "boolean"
                 },
-                "ip": {
+                "enabled_at": {
                     "type": "string"
                 },
-                "province": {
-                    "type": "string"
+                "required": {
+                    "description": "the policy requires the user to keep two-factor authentication enabled",
+                    "type": "boolean"
                 }
             }
         },
-        "domain.KBReleaseListItemResp": {
+        "domain.TwoFactorStep": {
+            "type": "string",
+            "enum": [
+                "verify",
+                "enroll"
+            ],
+            "x-enum-comments": {
+                "TwoFactorStepEnroll": "enroll an authenticator app, required by the policy",
+                "TwoFactorStepVerify": "enter a code of the authenticator app or a backup code"
+            },
+            "x-enum-varnames": [
+                "TwoFactorStepVerify",
+                "TwoFactorStepEnroll"
+            ]
+        },
+        "domain.UnansweredQuestion": {
             "type": "object",
             "properties": {
-                "created_at": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "string"
-                },
-                "kb_id": {
-                    "type": "string"
-                },
-                "message": {
-                    "type": "string"
+                "count": {
+                    "type": "integer"
                 },
-                "tag": {
+                "question": {
                     "type": "string"
                 }
             }
         },
-        "domain.KnowledgeBaseDetail": {
+        "domain.UpdateAppReq": {
             "type": "object",
             "properties": {
-                "access_settings": {
-                    "$ref": "#/definitions/domain.AccessSettings"
-                },
-                "created_at": {
-                    "type": "string"
-                },
-                "dataset_id": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "string"
-                },
                 "name": {
                     "type": "string"
                 },
-                "updated_at": {
-                    "type": "string"
+                "settings": {
+                    "$ref": "#/definitions/domain.AppSettings"
                 }
             }
         },
-        "domain.KnowledgeBaseListItem": {
+        "domain.UpdateEvalQuestionReq": {
             "type": "object",
+            "required": [
+                "expected_answer",
+                "id",
+                "kb_id",
+                "question"
+            ],
             "properties": {
-                "access_settings": {
-                    "$ref": "#/definitions/domain.AccessSettings"
-                },
-                "created_at": {
-                    "type": "string"
-                },
-                "dataset_id": {
-                    "type": "string"
+                "expected_answer": {
+                    "type": "string",
+                    "maxLength": 10000
                 },
                 "id": {
                     "type": "string"
                 },
-                "name": {
+                "kb_id": {
                     "type": "string"
                 },
-                "updated_at": {
-                    "type": "string"
+                "question": {
+                    "type": "string",
+                    "maxLength": 2000
                 }
             }
         },
-        "domain.Link": {
+        "domain.UpdateEvalSetReq": {
             "type": "object",
+            "required": [
+                "id",
+                "kb_id",
+                "name"
+            ],
             "properties": {
-                "name": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "id": {
                     "type": "string"
                 },
-                "url": {
+                "kb_id": {
                     "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
                 }
             }
         },
-        "domain.LoginReq": {
+        "domain.UpdateGlossaryTermReq": {
             "type": "object",
             "required": [
-                "account",
-                "password"
+                "definition",
+                "id",
+                "kb_id",
+                "term"
             ],
             "properties": {
-                "account": {
+                "aliases": {
+                    "type": "array",
+                    "maxItems": 20,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "definition": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "id": {
                     "type": "string"
                 },
-                "password": {
+                "kb_id": {
                     "type": "string"
-                }
-            }
-        },
-        "domain.LoginResp": {
-            "type": "object",
-            "properties": {
-                "token": {
+                },
+                "node_id": {
                     "type": "string"
+                },
+                "term": {
+                    "type": "string",
+                    "maxLength": 100
                 }
             }
         },
-        "domain.ModelDetailResp": {
+        "domain.UpdateImportSourceReq": {
             "type": "object",
+            "required": [
+                "id",
+                "kb_id"
+            ],
             "properties": {
-                "api_header": {
-                    "type": "string"
-                },
-                "api_key": {
-                    "type": "string"
+                "confluence": {
+                    "description": "empty token or secret keeps the saved one",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ConfluenceSettings"
+                        }
+                    ]
                 },
-                "api_version": {
-                    "description": "for azure openai",
-                    "type": "string"
+                "feed": {
+                    "$ref": "#/definitions/domain.FeedSettings"
                 },
-                "base_url": {
-                    "type": "string"
+                "feishu": {
+                    "$ref": "#/definitions/domain.FeishuSettings"
                 },
-                "completion_tokens": {
-                    "type": "integer"
+                "git": {
+                    "$ref": "#/definitions/domain.GitSettings"
                 },
-                "created_at": {
+                "id": {
                     "type": "string"
                 },
-                "id": {
+                "kb_id": {
                     "type": "string"
                 },
-                "model": {
+                "name": {
                     "type": "string"
                 },
-                "prompt_tokens": {
-                    "type": "integer"
+                "notion": {
+                    "$ref": "#/definitions/domain.NotionSettings"
                 },
-                "provider": {
-                    "$ref": "#/definitions/domain.ModelProvider"
+                "openapi": {
+                    "$ref": "#/definitions/domain.OpenAPISettings"
                 },
-                "total_tokens": {
-                    "type": "integer"
+                "parent_id": {
+                    "type": "string"
                 },
-                "type": {
-                    "$ref": "#/definitions/domain.ModelType"
+                "sync_interval": {
+                    "type": "integer",
+                    "minimum": 0
                 },
-                "updated_at": {
-                    "type": "string"
+                "website": {
+                    "$ref": "#/definitions/domain.WebsiteSettings"
+                },
+                "yuque": {
+                    "$ref": "#/definitions/domain.YuqueSettings"
                 }
             }
         },
-        "domain.ModelListItem": {
+        "domain.UpdateKnowledgeBaseReq": {
             "type": "object",
+            "required": [
+                "id"
+            ],
             "properties": {
-                "api_header": {
-                    "type": "string"
+                "access_settings": {
+                    "$ref": "#/definitions/domain.AccessSettings"
                 },
-                "api_key": {
-                    "type": "string"
+                "anomaly_settings": {
+                    "$ref": "#/definitions/domain.AnomalySettings"
                 },
-                "api_version": {
-                    "description": "for azure openai",
-                    "type": "string"
+                "answer_settings": {
+                    "$ref": "#/definitions/domain.AnswerSettings"
                 },
-                "base_url": {
-                    "type": "string"
+                "branding_settings": {
+                    "$ref": "#/definitions/domain.BrandingSettings"
                 },
-                "completion_tokens": {
-                    "type": "integer"
+                "comment_settings": {
+                    "$ref": "#/definitions/domain.CommentSettings"
+                },
+                "compliance_settings": {
+                    "$ref": "#/definitions/domain.ComplianceSettings"
+                },
+                "digest_settings": {
+                    "$ref": "#/definitions/domain.DigestSettings"
+                },
+                "geo_settings": {
+                    "$ref": "#/definitions/domain.GeoSettings"
                 },
                 "id": {
                     "type": "string"
                 },
-                "model": {
-                    "type": "string"
+                "maintenance_settings": {
+                    "$ref": "#/definitions/domain.MaintenanceSettings"
                 },
-                "prompt_tokens": {
-                    "type": "integer"
+                "model_settings": {
+                    "$ref": "#/definitions/domain.KBModelSettings"
                 },
-                "provider": {
-                    "$ref": "#/definitions/domain.ModelProvider"
+                "name": {
+                    "type": "string"
                 },
-                "total_tokens": {
-                    "type": "integer"
+                "review_reminder_settings": {
+                    "$ref": "#/definitions/domain.ReviewReminderSettings"
                 },
-                "type": {
-                    "$ref": "#/definitions/domain.ModelType"
+                "review_settings": {
+                    "$ref": "#/definitions/domain.ReviewSettings"
+                },
+                "stat_settings": {
+                    "$ref": "#/definitions/domain.StatSettings"
                 }
             }
         },
-        "domain.ModelProvider": {
-            "type": "string",
-            "enum": [
-                "OpenAI",
-                "Ollama",
-                "DeepSeek",
-                "Moonshot",
-                "SiliconFlow",
-                "AzureOpenAI",
-                "BaiZhiCloud",
-                "Hunyuan",
-                "BaiLian",
-                "Volcengine",
-                "Other"
-            ],
-            "x-enum-varnames": [
-                "ModelProviderBrandOpenAI",
-                "ModelProviderBrandOllama",
-                "ModelProviderBrandDeepSeek",
-                "ModelProviderBrandMoonshot",
-                "ModelProviderBrandSiliconFlow",
-                "ModelProviderBrandAzureOpenAI",
-                "ModelProviderBrandBaiZhiCloud",
-                "ModelProviderBrandHunyuan",
-                "ModelProviderBrandBaiLian",
-                "ModelProviderBrandVolcengine",
-                "ModelProviderBrandOther"
-            ]
-        },
-        "domain.ModelType": {
-            "type": "string",
-            "enum": [
-                "chat",
-                "embedding",
-                "rerank"
-            ],
-            "x-enum-varnames": [
-                "ModelTypeChat",
-                "ModelTypeEmbedding",
-                "ModelTypeRerank"
-            ]
+        "domain.UpdateMaintenanceReq": {
+            "type": "object",
+            "properties": {
+                "kb_id": {
+                    "description": "empty for the global switch",
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "read_only": {
+                    "type": "boolean"
+                }
+            }
         },
-        "domain.MoveNodeReq": {
+        "domain.UpdateModelReq": {
             "type": "object",
             "required": [
-                "id"
+                "base_url",
+                "id",
+                "model",
+                "provider",
+                "type"
             ],
             "properties": {
-                "id": {
+                "api_header": {
                     "type": "string"
                 },
-                "next_id": {
+                "api_key": {
                     "type": "string"
                 },
-                "parent_id": {
+                "api_version": {
+                    "description": "for azure openai",
                     "type": "string"
                 },
-                "prev_id": {
+                "base_url": {
+                    "type": "string"
+                },
+                "id": {
                     "type": "string"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "priority": {
+                    "description": "failover pool of chat models, 0 keeps the model out of the pool",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "provider": {
+                    "enum": [
+                        "OpenAI",
+                        "Ollama",
+                        "DeepSeek",
+                        "SiliconFlow",
+                        "Moonshot",
+                        "Other",
+                        "AzureOpenAI",
+                        "BaiZhiCloud",
+                        "Hunyuan",
+                        "BaiLian",
+                        "Volcengine",
+                        "vLLM"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ModelProvider"
+                        }
+                    ]
+                },
+                "type": {
+                    "enum": [
+                        "chat",
+                        "embedding",
+                        "rerank"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ModelType"
+                        }
+                    ]
+                },
+                "weight": {
+                    "description": "share of requests among pooled models of the same priority, 1 if 0",
+                    "type": "integer",
+                    "minimum": 0
                 }
             }
         },
-        "domain.NodeActionReq": {
+        "domain.UpdateNodeDefaultsReq": {
             "type": "object",
             "required": [
-                "action",
-                "ids",
+                "id",
                 "kb_id"
             ],
             "properties": {
-                "action": {
-                    "type": "string",
-                    "enum": [
-                        "delete",
-                        "private",
-                        "public"
-                    ]
+                "apply_to_children": {
+                    "description": "re-apply defaults to child nodes which have not overridden inherited fields",
+                    "type": "boolean"
                 },
-                "ids": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "defaults": {
+                    "$ref": "#/definitions/domain.NodeDefaults"
+                },
+                "id": {
+                    "type": "string"
                 },
                 "kb_id": {
                     "type": "string"
                 }
             }
         },
-        "domain.NodeDetailResp": {
+        "domain.UpdateNodeReq": {
             "type": "object",
+            "required": [
+                "id",
+                "kb_id"
+            ],
             "properties": {
                 "content": {
                     "type": "string"
                 },
-                "created_at": {
+                "emoji": {
                     "type": "string"
                 },
                 "id": {
@@ -3597,33 +20226,48 @@ const docTemplate = `{
                 "kb_id": {
                     "type": "string"
                 },
-                "meta": {
-                    "$ref": "#/definitions/domain.NodeMeta"
-                },
                 "name": {
                     "type": "string"
                 },
-                "parent_id": {
-                    "type": "string"
-                },
-                "status": {
-                    "$ref": "#/definitions/domain.NodeStatus"
-                },
-                "type": {
-                    "$ref": "#/definitions/domain.NodeType"
+                "seo": {
+                    "$ref": "#/definitions/domain.NodeSEO"
                 },
-                "updated_at": {
+                "summary": {
                     "type": "string"
                 },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "visibility": {
                     "$ref": "#/definitions/domain.NodeVisibility"
                 }
             }
         },
-        "domain.NodeListItemResp": {
+        "domain.UpdateNodeTemplateReq": {
             "type": "object",
+            "required": [
+                "id",
+                "kb_id",
+                "name",
+                "type"
+            ],
             "properties": {
-                "created_at": {
+                "category": {
+                    "type": "string"
+                },
+                "children": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.NodeTemplateItem"
+                    }
+                },
+                "content": {
+                    "type": "string"
+                },
+                "description": {
                     "type": "string"
                 },
                 "emoji": {
@@ -3632,578 +20276,707 @@ const docTemplate = `{
                 "id": {
                     "type": "string"
                 },
-                "name": {
+                "kb_id": {
                     "type": "string"
                 },
-                "parent_id": {
+                "name": {
                     "type": "string"
                 },
-                "position": {
-                    "type": "number"
+                "type": {
+                    "enum": [
+                        1,
+                        2
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.NodeType"
+                        }
+                    ]
+                }
+            }
+        },
+        "domain.UpdateReaderReq": {
+            "type": "object",
+            "required": [
+                "id",
+                "kb_id"
+            ],
+            "properties": {
+                "disabled": {
+                    "type": "boolean"
                 },
-                "status": {
-                    "$ref": "#/definitions/domain.NodeStatus"
+                "groups": {
+                    "type": "array",
+                    "maxItems": 50,
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "summary": {
+                "id": {
                     "type": "string"
                 },
-                "type": {
-                    "$ref": "#/definitions/domain.NodeType"
-                },
-                "updated_at": {
+                "kb_id": {
                     "type": "string"
                 },
-                "visibility": {
-                    "$ref": "#/definitions/domain.NodeVisibility"
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "password": {
+                    "description": "the password is kept if empty",
+                    "type": "string",
+                    "minLength": 8
                 }
             }
         },
-        "domain.NodeMeta": {
+        "domain.UpdateTelemetrySettingsReq": {
             "type": "object",
             "properties": {
-                "emoji": {
-                    "type": "string"
+                "enabled": {
+                    "type": "boolean"
                 },
-                "summary": {
-                    "type": "string"
+                "report_enabled": {
+                    "type": "boolean"
                 }
             }
         },
-        "domain.NodeStatus": {
-            "type": "integer",
-            "enum": [
-                1,
-                2
+        "domain.UpdateUserRoleReq": {
+            "type": "object",
+            "required": [
+                "id",
+                "role"
             ],
-            "x-enum-varnames": [
-                "NodeStatusDraft",
-                "NodeStatusReleased"
-            ]
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "role": {
+                    "enum": [
+                        "admin",
+                        "member"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.UserRole"
+                        }
+                    ]
+                }
+            }
         },
-        "domain.NodeSummaryReq": {
+        "domain.UpdateWebhookReq": {
             "type": "object",
             "required": [
-                "ids",
-                "kb_id"
+                "events",
+                "id",
+                "kb_id",
+                "name",
+                "url"
             ],
             "properties": {
-                "ids": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "events": {
                     "type": "array",
+                    "minItems": 1,
                     "items": {
                         "type": "string"
                     }
                 },
+                "format": {
+                    "enum": [
+                        "template",
+                        "jsonpath"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.WebhookFormat"
+                        }
+                    ]
+                },
+                "headers": {
+                    "$ref": "#/definitions/domain.StringMap"
+                },
+                "id": {
+                    "type": "string"
+                },
                 "kb_id": {
                     "type": "string"
-                }
-            }
-        },
-        "domain.NodeType": {
-            "type": "integer",
-            "enum": [
-                1,
-                2
-            ],
-            "x-enum-varnames": [
-                "NodeTypeFolder",
-                "NodeTypeDocument"
-            ]
-        },
-        "domain.NodeVisibility": {
-            "type": "integer",
-            "enum": [
-                1,
-                2
-            ],
-            "x-enum-varnames": [
-                "NodeVisibilityPrivate",
-                "NodeVisibilityPublic"
-            ]
-        },
-        "domain.NotnionGetListReq": {
-            "type": "object",
-            "properties": {
-                "cation_title": {
+                },
+                "mapping": {
+                    "$ref": "#/definitions/domain.StringMap"
+                },
+                "name": {
                     "type": "string"
                 },
-                "integration": {
+                "remove_secret": {
+                    "description": "empty secret keeps the saved one unless the secret is removed",
+                    "type": "boolean"
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "template": {
+                    "type": "string"
+                },
+                "url": {
                     "type": "string"
                 }
             }
         },
-        "domain.ObjectUploadResp": {
+        "domain.UserInfo": {
             "type": "object",
             "properties": {
-                "key": {
+                "email": {
+                    "type": "string"
+                },
+                "from": {
+                    "$ref": "#/definitions/domain.MessageFrom"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "real_name": {
+                    "type": "string"
+                },
+                "user_id": {
                     "type": "string"
                 }
             }
         },
-        "domain.Page": {
+        "domain.UserInfoResp": {
             "type": "object",
             "properties": {
-                "content": {
+                "account": {
                     "type": "string"
                 },
-                "id": {
+                "created_at": {
                     "type": "string"
                 },
-                "parent_id": {
+                "id": {
                     "type": "string"
                 },
-                "title": {
+                "kb_roles": {
+                    "description": "roles on kbs of members",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.UserKBRole"
+                    }
+                },
+                "last_access": {
                     "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/domain.UserRole"
                 }
             }
         },
-        "domain.PageInfo": {
+        "domain.UserKBRole": {
             "type": "object",
             "properties": {
-                "id": {
+                "kb_id": {
                     "type": "string"
                 },
-                "title": {
-                    "type": "string"
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.KBPermission"
+                    }
+                },
+                "role": {
+                    "$ref": "#/definitions/domain.KBRole"
                 }
             }
         },
-        "domain.ParseURLItem": {
+        "domain.UserListItemResp": {
             "type": "object",
             "properties": {
-                "desc": {
+                "account": {
                     "type": "string"
                 },
-                "published": {
+                "id": {
                     "type": "string"
                 },
-                "title": {
+                "last_access": {
                     "type": "string"
                 },
-                "url": {
+                "ldap_dn": {
+                    "description": "roles of directory users follow their ldap groups",
+                    "type": "string"
+                },
+                "oidc_subject": {
+                    "description": "roles of sso users follow their groups at every login",
                     "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/domain.UserRole"
                 }
             }
         },
-        "domain.ParseURLReq": {
-            "type": "object",
-            "required": [
-                "url"
+        "domain.UserRole": {
+            "type": "string",
+            "enum": [
+                "admin",
+                "member"
             ],
+            "x-enum-comments": {
+                "UserRoleAdmin": "every kb and the system settings",
+                "UserRoleMember": "only the kbs the user has a role on, with the permissions of the role"
+            },
+            "x-enum-varnames": [
+                "UserRoleAdmin",
+                "UserRoleMember"
+            ]
+        },
+        "domain.ViewerAuth": {
+            "type": "object",
             "properties": {
-                "url": {
+                "secret": {
                     "type": "string"
                 }
             }
         },
-        "domain.ParseURLResp": {
+        "domain.WarmupState": {
+            "type": "string",
+            "enum": [
+                "running",
+                "ready",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "WarmupStateRunning",
+                "WarmupStateReady",
+                "WarmupStateFailed"
+            ]
+        },
+        "domain.WarmupStatus": {
             "type": "object",
             "properties": {
-                "items": {
+                "actual_dimension": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "expected_dimension": {
+                    "description": "embedding dimension configured for the vector index and reported by the model",
+                    "type": "integer"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "state": {
+                    "$ref": "#/definitions/domain.WarmupState"
+                },
+                "warmed_kb_ids": {
+                    "description": "kbs whose cache and index were preloaded",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/domain.ParseURLItem"
+                        "type": "string"
                     }
                 }
             }
         },
-        "domain.ProviderModelListItem": {
-            "type": "object",
-            "properties": {
-                "model": {
-                    "type": "string"
-                }
-            }
-        },
-        "domain.RecommendNodeListResp": {
+        "domain.Webhook": {
             "type": "object",
             "properties": {
-                "emoji": {
+                "created_at": {
                     "type": "string"
                 },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "format": {
+                    "$ref": "#/definitions/domain.WebhookFormat"
+                },
+                "has_secret": {
+                    "description": "secret is set, the api returns the webhook masked",
+                    "type": "boolean"
+                },
+                "headers": {
+                    "$ref": "#/definitions/domain.StringMap"
+                },
                 "id": {
                     "type": "string"
                 },
-                "name": {
+                "kb_id": {
                     "type": "string"
                 },
-                "parent_id": {
+                "mapping": {
+                    "$ref": "#/definitions/domain.StringMap"
+                },
+                "name": {
                     "type": "string"
                 },
-                "position": {
-                    "type": "number"
+                "secret": {
+                    "description": "hmac-sha256 key of payload signature, no signature if empty",
+                    "type": "string"
                 },
-                "recommend_nodes": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/domain.RecommendNodeListResp"
-                    }
+                "template": {
+                    "type": "string"
                 },
-                "summary": {
+                "updated_at": {
                     "type": "string"
                 },
-                "type": {
-                    "$ref": "#/definitions/domain.NodeType"
+                "url": {
+                    "type": "string"
                 }
             }
         },
-        "domain.ResetPasswordReq": {
+        "domain.WebhookEvent": {
             "type": "object",
-            "required": [
-                "id",
-                "new_password"
-            ],
             "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
                 "id": {
                     "type": "string"
                 },
-                "new_password": {
-                    "type": "string",
-                    "minLength": 8
-                }
-            }
-        },
-        "domain.Response": {
-            "type": "object",
-            "properties": {
-                "data": {},
-                "message": {
+                "kb_id": {
                     "type": "string"
                 },
-                "success": {
-                    "type": "boolean"
+                "type": {
+                    "$ref": "#/definitions/domain.WebhookEventType"
                 }
             }
         },
-        "domain.ScrapeReq": {
+        "domain.WebhookEventType": {
+            "type": "string",
+            "enum": [
+                "conversation.created",
+                "conversation.message",
+                "anomaly.detected"
+            ],
+            "x-enum-varnames": [
+                "WebhookEventConversationCreated",
+                "WebhookEventConversationMessage",
+                "WebhookEventAnomalyDetected"
+            ]
+        },
+        "domain.WebhookFormat": {
+            "type": "string",
+            "enum": [
+                "",
+                "template",
+                "jsonpath"
+            ],
+            "x-enum-varnames": [
+                "WebhookFormatRaw",
+                "WebhookFormatTemplate",
+                "WebhookFormatJSONPath"
+            ]
+        },
+        "domain.WebsiteSettings": {
             "type": "object",
             "required": [
-                "kb_id"
+                "url"
             ],
             "properties": {
-                "kb_id": {
-                    "type": "string"
+                "exclude": {
+                    "description": "regexps of urls neither imported nor followed",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "include": {
+                    "description": "regexps of urls to import, all pages found if empty. pages not included are still followed for links",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "max_depth": {
+                    "description": "link depth followed from the start page, sitemaps list all pages. 2 if 0",
+                    "type": "integer",
+                    "maximum": 10,
+                    "minimum": 0
                 },
                 "url": {
+                    "description": "sitemap.xml or a page of the site, the sitemap.xml of a site root is used if it exists",
                     "type": "string"
                 }
             }
         },
-        "domain.ScrapeResp": {
+        "domain.WikiJSResp": {
             "type": "object",
             "properties": {
                 "content": {
                     "type": "string"
                 },
+                "id": {
+                    "type": "integer"
+                },
                 "title": {
                     "type": "string"
                 }
             }
         },
-        "domain.SearchDocxReq": {
+        "domain.YuqueSettings": {
             "type": "object",
+            "required": [
+                "namespace"
+            ],
             "properties": {
-                "app_id": {
+                "base_url": {
+                    "description": "site of the space, https://www.yuque.com if empty",
                     "type": "string"
                 },
-                "app_secret": {
+                "namespace": {
+                    "description": "namespace or url of the repo, e.g. group/book",
                     "type": "string"
                 },
-                "user_access_token": {
+                "token": {
                     "type": "string"
                 }
             }
         },
-        "domain.SearchDocxResp": {
+        "handler_share.FullTextSearchResults": {
             "type": "object",
             "properties": {
-                "name": {
-                    "type": "string"
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.FullTextSearchResult"
+                    }
                 },
-                "url": {
-                    "type": "string"
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "domain.SearchWikiReq": {
+        "handler_v1.AnomalyListItems": {
             "type": "object",
             "properties": {
-                "app_id": {
-                    "type": "string"
-                },
-                "app_secret": {
-                    "type": "string"
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.AnomalyListItem"
+                    }
                 },
-                "query": {
-                    "type": "string"
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler_v1.AuditLogListItems": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.AuditLogListItem"
+                    }
                 },
-                "space_id": {
-                    "type": "string"
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler_v1.ConversationListItems": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ConversationListItem"
+                    }
                 },
-                "user_access_token": {
-                    "type": "string"
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "domain.SearchWikiResp": {
+        "handler_v1.ConversationRescoreJobListItems": {
             "type": "object",
             "properties": {
-                "space_id": {
-                    "type": "string"
-                },
-                "title": {
-                    "type": "string"
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ConversationRescoreJob"
+                    }
                 },
-                "url": {
-                    "type": "string"
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "domain.SimpleAuth": {
+        "handler_v1.CronRunListItems": {
             "type": "object",
             "properties": {
-                "enabled": {
-                    "type": "boolean"
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.CronRun"
+                    }
                 },
-                "password": {
-                    "type": "string"
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "domain.StatPageReq": {
+        "handler_v1.DataExportJobListItems": {
             "type": "object",
-            "required": [
-                "scene"
-            ],
             "properties": {
-                "node_id": {
-                    "type": "string"
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.DataExportJob"
+                    }
                 },
-                "scene": {
-                    "enum": [
-                        1,
-                        2,
-                        3,
-                        4
-                    ],
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/domain.StatPageScene"
-                        }
-                    ]
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "domain.StatPageScene": {
-            "type": "integer",
-            "enum": [
-                1,
-                2,
-                3,
-                4
-            ],
-            "x-enum-varnames": [
-                "StatPageSceneWelcome",
-                "StatPageSceneNodeDetail",
-                "StatPageSceneChat",
-                "StatPageSceneLogin"
-            ]
-        },
-        "domain.TextReq": {
+        "handler_v1.EvalResultListItems": {
             "type": "object",
-            "required": [
-                "text"
-            ],
             "properties": {
-                "action": {
-                    "description": "action: improve, summary, extend, shorten, etc.",
-                    "type": "string"
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.EvalResult"
+                    }
                 },
-                "text": {
-                    "type": "string"
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "domain.ThemeAndStyle": {
+        "handler_v1.EvalRunListItems": {
             "type": "object",
             "properties": {
-                "bg_image": {
-                    "type": "string"
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.EvalRun"
+                    }
+                },
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "domain.UpdateAppReq": {
+        "handler_v1.GuardrailEventListItems": {
             "type": "object",
             "properties": {
-                "name": {
-                    "type": "string"
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.GuardrailEvent"
+                    }
                 },
-                "settings": {
-                    "$ref": "#/definitions/domain.AppSettings"
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "domain.UpdateKnowledgeBaseReq": {
+        "handler_v1.NodeCommentListItems": {
             "type": "object",
-            "required": [
-                "id"
-            ],
             "properties": {
-                "access_settings": {
-                    "$ref": "#/definitions/domain.AccessSettings"
-                },
-                "id": {
-                    "type": "string"
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.NodeCommentListItem"
+                    }
                 },
-                "name": {
-                    "type": "string"
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "domain.UpdateModelReq": {
+        "handler_v1.NodeExportJobListItems": {
             "type": "object",
-            "required": [
-                "base_url",
-                "id",
-                "model",
-                "provider",
-                "type"
-            ],
             "properties": {
-                "api_header": {
-                    "type": "string"
-                },
-                "api_key": {
-                    "type": "string"
-                },
-                "api_version": {
-                    "description": "for azure openai",
-                    "type": "string"
-                },
-                "base_url": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "string"
-                },
-                "model": {
-                    "type": "string"
-                },
-                "provider": {
-                    "enum": [
-                        "OpenAI",
-                        "Ollama",
-                        "DeepSeek",
-                        "SiliconFlow",
-                        "Moonshot",
-                        "Other",
-                        "AzureOpenAI",
-                        "BaiZhiCloud",
-                        "Hunyuan",
-                        "BaiLian",
-                        "Volcengine"
-                    ],
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/domain.ModelProvider"
-                        }
-                    ]
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.NodeExportJob"
+                    }
                 },
-                "type": {
-                    "enum": [
-                        "chat",
-                        "embedding",
-                        "rerank"
-                    ],
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/domain.ModelType"
-                        }
-                    ]
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "domain.UpdateNodeReq": {
+        "handler_v1.NodeReplaceJobListItems": {
             "type": "object",
-            "required": [
-                "id",
-                "kb_id"
-            ],
             "properties": {
-                "content": {
-                    "type": "string"
-                },
-                "emoji": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "string"
-                },
-                "kb_id": {
-                    "type": "string"
-                },
-                "name": {
-                    "type": "string"
-                },
-                "summary": {
-                    "type": "string"
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.NodeReplaceJob"
+                    }
                 },
-                "visibility": {
-                    "$ref": "#/definitions/domain.NodeVisibility"
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "domain.UserInfoResp": {
+        "handler_v1.NodeReviewListItems": {
             "type": "object",
             "properties": {
-                "account": {
-                    "type": "string"
-                },
-                "created_at": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "string"
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.NodeReview"
+                    }
                 },
-                "last_access": {
-                    "type": "string"
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "domain.UserListItemResp": {
+        "handler_v1.NodeStaleListItems": {
             "type": "object",
             "properties": {
-                "account": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "string"
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.NodeStaleItem"
+                    }
                 },
-                "last_access": {
-                    "type": "string"
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "domain.WikiJSResp": {
+        "handler_v1.ReaderListItems": {
             "type": "object",
             "properties": {
-                "content": {
-                    "type": "string"
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Reader"
+                    }
                 },
-                "id": {
+                "total": {
                     "type": "integer"
-                },
-                "title": {
-                    "type": "string"
                 }
             }
         },
-        "handler_v1.ConversationListItems": {
+        "handler_v1.TranscriptEmailListItems": {
             "type": "object",
             "properties": {
                 "data": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/domain.ConversationListItem"
+                        "$ref": "#/definitions/domain.ConversationTranscriptEmail"
                     }
                 },
                 "total": {
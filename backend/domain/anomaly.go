@@ -0,0 +1,142 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type AnomalyType string
+
+const (
+	// same ip firing too many questions in an hour
+	AnomalyTypeQuestionStorm AnomalyType = "question_storm"
+	// same ip sending inputs over the max length again and again
+	AnomalyTypeLongInput AnomalyType = "long_input"
+	// same ip repeating an identical prompt
+	AnomalyTypeRepeatedPrompt AnomalyType = "repeated_prompt"
+)
+
+const (
+	DefaultAnomalyQuestionsPerHour = 200
+	DefaultAnomalyMaxInputLength   = 8000
+	DefaultAnomalyRepeatLimit      = 20
+	DefaultAnomalyBlockMinutes     = 30
+
+	// window of repeated prompts and long inputs
+	AnomalyWindow = 10 * time.Minute
+	// long inputs of an ip in the window before it is blocked, earlier ones are only refused
+	AnomalyLongInputLimit = 3
+)
+
+const (
+	DefaultAnomalyBlockText = "请求过于频繁，请稍后再试。"
+	DefaultAnomalyLongText  = "问题过长，请精简后再试。"
+)
+
+var (
+	ErrAnomalyBlocked      = NewError(ErrCodeBlocked, "remote ip is temporarily blocked")
+	ErrAnomalyInputTooLong = NewError(ErrCodeInvalidRequest, "question is too long")
+)
+
+// AnomalySettings per kb detection of abnormal chat traffic, zero values use defaults
+type AnomalySettings struct {
+	Disabled         bool `json:"disabled"`
+	QuestionsPerHour int  `json:"questions_per_hour,omitempty"`
+	// max runes of a question
+	MaxInputLength int `json:"max_input_length,omitempty"`
+	// identical questions of an ip in 10 minutes
+	RepeatLimit  int `json:"repeat_limit,omitempty"`
+	BlockMinutes int `json:"block_minutes,omitempty"`
+}
+
+func (s *AnomalySettings) Scan(value any) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New(fmt.Sprint("invalid anomaly settings value type:", value))
+	}
+	return json.Unmarshal(bytes, s)
+}
+
+func (s AnomalySettings) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+// Effective fill defaults of unset thresholds
+func (s AnomalySettings) Effective() AnomalySettings {
+	if s.QuestionsPerHour <= 0 {
+		s.QuestionsPerHour = DefaultAnomalyQuestionsPerHour
+	}
+	if s.MaxInputLength <= 0 {
+		s.MaxInputLength = DefaultAnomalyMaxInputLength
+	}
+	if s.RepeatLimit <= 0 {
+		s.RepeatLimit = DefaultAnomalyRepeatLimit
+	}
+	if s.BlockMinutes <= 0 {
+		s.BlockMinutes = DefaultAnomalyBlockMinutes
+	}
+	return s
+}
+
+// table: conversation_anomalies
+type ConversationAnomaly struct {
+	ID       string      `json:"id" gorm:"primaryKey"`
+	KBID     string      `json:"kb_id"`
+	AppID    string      `json:"app_id"`
+	RemoteIP string      `json:"remote_ip"`
+	Type     AnomalyType `json:"type"`
+	// sample of the offending question
+	Detail string `json:"detail"`
+	// hits counted in the window when detected
+	Count        int64      `json:"count"`
+	BlockedUntil *time.Time `json:"blocked_until"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (ConversationAnomaly) TableName() string {
+	return "conversation_anomalies"
+}
+
+type AnomalyListReq struct {
+	KBID     string      `json:"kb_id" query:"kb_id" validate:"required"`
+	Type     AnomalyType `json:"type" query:"type" validate:"omitempty,oneof=question_storm long_input repeated_prompt"`
+	RemoteIP string      `json:"remote_ip" query:"remote_ip"`
+
+	Pager
+}
+
+type AnomalyListItem struct {
+	*ConversationAnomaly
+	IPAddress *IPAddress `json:"ip_address" gorm:"-"`
+	// block of the ip is still in effect
+	Blocked bool `json:"blocked" gorm:"-"`
+}
+
+type AnomalyTypeCount struct {
+	Type  AnomalyType `json:"type"`
+	Count int64       `json:"count"`
+}
+
+type AnomalyIPCount struct {
+	RemoteIP  string     `json:"remote_ip"`
+	Count     int64      `json:"count"`
+	IPAddress *IPAddress `json:"ip_address" gorm:"-"`
+}
+
+// AnomalyReport anomalies of the kb in the recent days
+type AnomalyReport struct {
+	Days   int                 `json:"days"`
+	Types  []*AnomalyTypeCount `json:"types"`
+	TopIPs []*AnomalyIPCount   `json:"top_ips"`
+	// ips whose block is still in effect
+	Blocked []*ConversationAnomaly `json:"blocked"`
+}
+
+type UnblockAnomalyIPReq struct {
+	KBID     string `json:"kb_id" query:"kb_id" validate:"required"`
+	RemoteIP string `json:"remote_ip" query:"remote_ip" validate:"required,ip"`
+}
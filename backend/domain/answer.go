@@ -0,0 +1,161 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	DefaultMinRetrievalScore    = 0.5
+	DefaultMinGroundedness      = 0.5
+	DefaultLowConfidenceMessage = "抱歉，没有找到足够可信的答案，以下文档可能对你有帮助："
+)
+
+// AnswerSettings per kb settings of how answers are generated
+type AnswerSettings struct {
+	ConfidenceGate ConfidenceGateSettings `json:"confidence_gate"`
+	Retrieval      RetrievalSettings      `json:"retrieval"`
+	Cache          AnswerCacheSettings    `json:"cache"`
+}
+
+// ConfidenceGateSettings reply reference links instead of a speculative answer when retrieval is weak
+type ConfidenceGateSettings struct {
+	Enabled bool `json:"enabled"`
+	// min similarity of the best retrieved chunk, 0-1
+	MinRetrievalScore float64 `json:"min_retrieval_score" validate:"min=0,max=1"`
+	// min share of question terms found in retrieved documents, 0-1
+	MinGroundedness float64 `json:"min_groundedness" validate:"min=0,max=1"`
+	Message         string  `json:"message"`
+}
+
+func (s *AnswerSettings) Scan(value any) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New(fmt.Sprint("invalid answer settings value type:", value))
+	}
+	return json.Unmarshal(bytes, s)
+}
+
+func (s AnswerSettings) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+type AnswerConfidence struct {
+	RetrievalScore float64
+	Groundedness   float64
+	Confident      bool
+}
+
+// Evaluate check whether retrieved documents are good enough to answer the question
+func (s ConfidenceGateSettings) Evaluate(question string, nodes []*RankedNodeChunks) AnswerConfidence {
+	minScore, minGroundedness := s.MinRetrievalScore, s.MinGroundedness
+	if minScore == 0 {
+		minScore = DefaultMinRetrievalScore
+	}
+	if minGroundedness == 0 {
+		minGroundedness = DefaultMinGroundedness
+	}
+	confidence := AnswerConfidence{}
+	var documents strings.Builder
+	for _, node := range nodes {
+		documents.WriteString(strings.ToLower(node.NodeName))
+		documents.WriteString("\n")
+		for _, chunk := range node.Chunks {
+			confidence.RetrievalScore = max(confidence.RetrievalScore, chunk.Similarity)
+			documents.WriteString(strings.ToLower(chunk.Content))
+			documents.WriteString("\n")
+		}
+	}
+	terms := questionTerms(question)
+	if len(terms) == 0 {
+		confidence.Groundedness = 1
+	} else {
+		text := documents.String()
+		found := 0
+		for _, term := range terms {
+			if strings.Contains(text, term) {
+				found++
+			}
+		}
+		confidence.Groundedness = float64(found) / float64(len(terms))
+	}
+	confidence.Confident = len(nodes) > 0 && confidence.RetrievalScore >= minScore && confidence.Groundedness >= minGroundedness
+	return confidence
+}
+
+// Message reply for low confidence questions
+func (s ConfidenceGateSettings) LowConfidenceMessage() string {
+	if s.Message != "" {
+		return s.Message
+	}
+	return DefaultLowConfidenceMessage
+}
+
+// questionTerms split question to lower case words, han characters are split to bigrams
+func questionTerms(question string) []string {
+	terms := make([]string, 0)
+	seen := make(map[string]bool)
+	add := func(term string) {
+		if term != "" && !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+	var word []rune
+	var han []rune
+	flush := func() {
+		if len(word) > 1 {
+			add(string(word))
+		}
+		word = word[:0]
+		if len(han) == 1 {
+			add(string(han))
+		}
+		for i := 0; i+1 < len(han); i++ {
+			add(string(han[i : i+2]))
+		}
+		han = han[:0]
+	}
+	for _, r := range strings.ToLower(question) {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			if len(word) > 0 {
+				flush()
+			}
+			han = append(han, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if len(han) > 0 {
+				flush()
+			}
+			word = append(word, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return terms
+}
+
+type GetAnswerConfidenceStatReq struct {
+	KBID      string `json:"kb_id" query:"kb_id" validate:"required"`
+	StartTime int64  `json:"start_time" query:"start_time"` // unix timestamp, default: 7d ago
+	EndTime   int64  `json:"end_time" query:"end_time"`     // unix timestamp, default: now
+}
+
+type AnswerConfidenceCount struct {
+	Date          time.Time `json:"date"`
+	Answered      int64     `json:"answered"`
+	LowConfidence int64     `json:"low_confidence"`
+}
+
+type AnswerConfidenceStatResp struct {
+	Answered      int64                    `json:"answered"`
+	LowConfidence int64                    `json:"low_confidence"`
+	LowRate       float64                  `json:"low_rate"`
+	Daily         []*AnswerConfidenceCount `json:"daily"`
+}
@@ -0,0 +1,162 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultAnswerCacheMinSimilarity min cosine similarity of a question to a cached one when not set
+	DefaultAnswerCacheMinSimilarity = 0.95
+	// DefaultAnswerCacheTTL how long answers are served from the cache when not set
+	DefaultAnswerCacheTTL = 24 * time.Hour
+	// AnswerCacheCandidates most recent cached answers of an app compared with a question
+	AnswerCacheCandidates = 500
+	// AnswerCacheLookupTimeout questions taking longer to embed are answered by the model
+	AnswerCacheLookupTimeout = 5 * time.Second
+)
+
+// AnswerCacheSettings serve the answer of a recent highly similar question instead of calling the model,
+// cached answers are dropped when a document they were retrieved from changes
+type AnswerCacheSettings struct {
+	Enabled bool `json:"enabled"`
+	// min cosine similarity of question embeddings, 0.95 when not set
+	MinSimilarity float64 `json:"min_similarity" validate:"min=0,max=1"`
+	// hours answers are served from the cache, 24 when not set
+	TTLHours int `json:"ttl_hours" validate:"min=0,max=720"`
+}
+
+func (s AnswerCacheSettings) MinSimilarityOrDefault() float64 {
+	if s.MinSimilarity <= 0 {
+		return DefaultAnswerCacheMinSimilarity
+	}
+	return s.MinSimilarity
+}
+
+func (s AnswerCacheSettings) TTL() time.Duration {
+	if s.TTLHours <= 0 {
+		return DefaultAnswerCacheTTL
+	}
+	return time.Duration(s.TTLHours) * time.Hour
+}
+
+// table: answer_cache_entries, the first answer of a conversation with what was streamed along with it.
+// answers depend on the prompt of the app, the locale, citation style, region and the settings checking and
+// rewriting them, so they are only served to questions asked with the same ones
+type AnswerCacheEntry struct {
+	ID            string        `json:"id" gorm:"primaryKey"`
+	KBID          string        `json:"kb_id"`
+	AppID         string        `json:"app_id"`
+	Locale        string        `json:"locale"`
+	Region        string        `json:"region"`
+	CitationStyle CitationStyle `json:"citation_style"`
+	// version of the settings the answer was generated with, see AnswerSettingsVersion
+	SettingsVersion string `json:"settings_version"`
+	Question        string `json:"question"`
+	Embedding       Vector `json:"-" gorm:"type:jsonb"`
+	// answer message the entry was cached from
+	MessageID string `json:"message_id"`
+	Answer    string `json:"answer"`
+	// documents the answer was retrieved from, the entry is dropped when one of them changes
+	NodeIDs      StringList        `json:"node_ids" gorm:"type:jsonb"`
+	ChunkResults AnswerCacheChunks `json:"chunk_results" gorm:"type:jsonb"`
+	// reference events of the answer, in the order they were sent
+	References AnswerCacheReferences `json:"references" gorm:"type:jsonb"`
+	FollowUps  FollowUpQuestions     `json:"follow_ups" gorm:"type:jsonb"`
+	Confidence float64               `json:"confidence"`
+	// times the answer was served from the cache
+	Hits      int       `json:"hits"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (AnswerCacheEntry) TableName() string {
+	return "answer_cache_entries"
+}
+
+type AnswerCacheChunks []*NodeCotentChunkSSE
+
+func (c *AnswerCacheChunks) Scan(value any) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New(fmt.Sprint("invalid answer cache chunks value type:", value))
+	}
+	return json.Unmarshal(bytes, c)
+}
+
+func (c AnswerCacheChunks) Value() (driver.Value, error) {
+	return json.Marshal(c)
+}
+
+type AnswerCacheReferences []*SSEReference
+
+func (r *AnswerCacheReferences) Scan(value any) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New(fmt.Sprint("invalid answer cache references value type:", value))
+	}
+	return json.Unmarshal(bytes, r)
+}
+
+func (r AnswerCacheReferences) Value() (driver.Value, error) {
+	return json.Marshal(r)
+}
+
+// AnswerCacheKey what a cached answer depends on besides the question and documents
+type AnswerCacheKey struct {
+	KBID          string
+	AppID         string
+	Locale        string
+	Region        string
+	CitationStyle CitationStyle
+	// cached answers are stale once the prompt template, guardrails or content policy change
+	SettingsVersion string
+}
+
+func NewAnswerCacheKey(kbID, appID, locale string, region *GeoRegion, citation CitationStyle, settingsVersion string) *AnswerCacheKey {
+	key := &AnswerCacheKey{KBID: kbID, AppID: appID, Locale: locale, CitationStyle: citation, SettingsVersion: settingsVersion}
+	if region != nil {
+		key.Region = region.Name
+	}
+	return key
+}
+
+// AnswerSettingsVersion hash of the prompt template version of the app and the settings which check and rewrite
+// its answers, the built-in compliance profile of the region is included so changing it changes the version too
+func AnswerSettingsVersion(promptVersion int, guardrails GuardrailSettings, pipeline AnswerPipelineSettings, compliance ComplianceSettings) string {
+	bytes, _ := json.Marshal(struct {
+		PromptVersion int                    `json:"prompt_version"`
+		Guardrails    GuardrailSettings      `json:"guardrails"`
+		Pipeline      AnswerPipelineSettings `json:"pipeline"`
+		Compliance    ComplianceSettings     `json:"compliance"`
+	}{promptVersion, guardrails, pipeline, compliance.Effective()})
+	sum := sha256.Sum256(bytes)
+	return hex.EncodeToString(sum[:8])
+}
+
+// ConversationReferences references of the cached answer saved for a new conversation, nil if they are extracted
+// from the answer
+func (e *AnswerCacheEntry) ConversationReferences(conversationID string) []*ConversationReference {
+	switch e.CitationStyle {
+	case CitationStyleCards:
+		references := make([]*ConversationReference, 0, len(e.References))
+		for _, reference := range e.References {
+			references = append(references, &ConversationReference{
+				ConversationID: conversationID,
+				AppID:          e.AppID,
+				NodeID:         reference.NodeID,
+				Name:           reference.Name,
+				URL:            reference.URL,
+			})
+		}
+		return references
+	case CitationStyleNone:
+		return []*ConversationReference{}
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package domain
+
+import "testing"
+
+func TestAnswerSettingsVersion(t *testing.T) {
+	guardrails := GuardrailSettings{Profanity: GuardrailActionBlock}
+	pipeline := AnswerPipelineSettings{Steps: []AnswerStepType{AnswerStepDisclaimer}}
+	compliance := ComplianceSettings{BlockedReply: "no"}
+	base := AnswerSettingsVersion(1, guardrails, pipeline, compliance)
+	if again := AnswerSettingsVersion(1, guardrails, pipeline, compliance); again != base {
+		t.Fatalf("version of the same settings = %q, want %q", again, base)
+	}
+	tests := []struct {
+		name    string
+		version string
+	}{
+		{name: "prompt template", version: AnswerSettingsVersion(2, guardrails, pipeline, compliance)},
+		{name: "built-in prompt", version: AnswerSettingsVersion(0, guardrails, pipeline, compliance)},
+		{name: "guardrails", version: AnswerSettingsVersion(1, GuardrailSettings{Profanity: GuardrailActionWarn}, pipeline, compliance)},
+		{name: "answer pipeline", version: AnswerSettingsVersion(1, guardrails, AnswerPipelineSettings{}, compliance)},
+		{name: "compliance profile of the region", version: AnswerSettingsVersion(1, guardrails, pipeline, ComplianceSettings{BlockedReply: "no", Region: ComplianceRegionCN})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.version == base {
+				t.Errorf("version did not change with the %s", tt.name)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package domain
+
+import "slices"
+
+type AnswerStepType string
+
+const (
+	// remove reference links which are not from retrieved documents
+	AnswerStepCitationValidation AnswerStepType = "citation_validation"
+	// mask emails, phone numbers, id card and bank card numbers
+	AnswerStepPIIScrub AnswerStepType = "pii_scrub"
+	// adapt markdown to the format of the app
+	AnswerStepFormat AnswerStepType = "format"
+	// trim answer to max length
+	AnswerStepLengthTrim AnswerStepType = "length_trim"
+	// append disclaimers of the kb content policy
+	AnswerStepDisclaimer AnswerStepType = "disclaimer"
+)
+
+// DefaultAnswerSteps steps of apps without pipeline settings
+var DefaultAnswerSteps = []AnswerStepType{AnswerStepDisclaimer}
+
+// RequiredAnswerSteps steps of every app, run last whatever the settings so that no step trims or rewrites them
+var RequiredAnswerSteps = []AnswerStepType{AnswerStepDisclaimer}
+
+type AnswerFormat string
+
+const (
+	AnswerFormatMarkdown AnswerFormat = "markdown"
+	AnswerFormatPlain    AnswerFormat = "plain"
+)
+
+// AnswerPipelineSettings per app post-processing steps of answers, applied in order
+type AnswerPipelineSettings struct {
+	Steps     []AnswerStepType `json:"steps,omitempty"`
+	MaxLength int              `json:"max_length,omitempty"` // max runes of length_trim
+	Format    AnswerFormat     `json:"format,omitempty"`     // target format of format, default markdown
+}
+
+// StepsOrDefault steps of the settings or the default ones, ending with the required steps.
+// the compliance disclaimers can not be left out by an app setting its own steps
+func (s AnswerPipelineSettings) StepsOrDefault() []AnswerStepType {
+	if len(s.Steps) == 0 {
+		return DefaultAnswerSteps
+	}
+	steps := make([]AnswerStepType, 0, len(s.Steps)+len(RequiredAnswerSteps))
+	for _, step := range s.Steps {
+		if !slices.Contains(RequiredAnswerSteps, step) {
+			steps = append(steps, step)
+		}
+	}
+	return append(steps, RequiredAnswerSteps...)
+}
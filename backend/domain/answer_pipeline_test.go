@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAnswerPipelineStepsOrDefault(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []AnswerStepType
+		want  []AnswerStepType
+	}{
+		{name: "no steps", want: []AnswerStepType{AnswerStepDisclaimer}},
+		{
+			name:  "custom steps without the disclaimer",
+			steps: []AnswerStepType{AnswerStepPIIScrub, AnswerStepLengthTrim},
+			want:  []AnswerStepType{AnswerStepPIIScrub, AnswerStepLengthTrim, AnswerStepDisclaimer},
+		},
+		{
+			name:  "disclaimer before trim is moved last",
+			steps: []AnswerStepType{AnswerStepDisclaimer, AnswerStepLengthTrim},
+			want:  []AnswerStepType{AnswerStepLengthTrim, AnswerStepDisclaimer},
+		},
+		{
+			name:  "repeated disclaimer runs once",
+			steps: []AnswerStepType{AnswerStepDisclaimer, AnswerStepFormat, AnswerStepDisclaimer},
+			want:  []AnswerStepType{AnswerStepFormat, AnswerStepDisclaimer},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := AnswerPipelineSettings{Steps: tt.steps}.StepsOrDefault()
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("StepsOrDefault() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
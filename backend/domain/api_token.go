@@ -0,0 +1,149 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// APITokenPrefix prefix of api tokens, to tell them from console tokens and find leaked ones
+const APITokenPrefix = "pwt_"
+
+// APITokenScope what the token may call
+type APITokenScope string
+
+const (
+	// create sandbox kbs, push documents, evaluate questions and tear them down, production kbs are never visible
+	APITokenScopeSandbox APITokenScope = "sandbox"
+	// read nodes of kbs
+	APITokenScopeNodesRead APITokenScope = "nodes:read"
+	// create, update, move and delete nodes and publish releases, also allows reading nodes
+	APITokenScopeNodesWrite APITokenScope = "nodes:write"
+	// read conversations and their messages
+	APITokenScopeConversationsRead APITokenScope = "conversations:read"
+	// read statistics, gap reports and digests
+	APITokenScopeStatsRead APITokenScope = "stats:read"
+)
+
+// APITokenKind whose permissions the token has
+type APITokenKind string
+
+const (
+	// acts as the user who created it with its current roles, limited to the scopes of the token
+	APITokenKindPersonal APITokenKind = "personal"
+	// created by admins for integrations, acts as an admin limited to the scopes and kb of the token while its creator is an admin
+	APITokenKindService APITokenKind = "service"
+)
+
+const DefaultAPITokenMaxSandboxes = 5
+
+var (
+	ErrAPITokenInvalid     = NewError(ErrCodeUnauthorized, "api token is invalid or expired")
+	ErrAPITokenNotFound    = NewError(ErrCodeNotFound, "api token not found")
+	ErrAPITokenScopeDenied = NewError(ErrCodeForbidden, "api token is not allowed to call this api")
+	ErrAPITokenAdminOnly   = NewError(ErrCodeForbidden, "only admins may create service tokens or tokens of the sandbox scope")
+	ErrAPITokenKBScope     = NewError(ErrCodeInvalidRequest, "only service tokens are limited to a kb")
+)
+
+// APITokenScopes scopes of a token
+type APITokenScopes []APITokenScope
+
+func (s *APITokenScopes) Scan(value any) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New(fmt.Sprint("invalid api token scopes value type:", value))
+	}
+	return json.Unmarshal(bytes, s)
+}
+
+func (s APITokenScopes) Value() (driver.Value, error) {
+	if s == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]APITokenScope(s))
+}
+
+// table: api_tokens
+type APIToken struct {
+	ID     string         `json:"id" gorm:"primaryKey"`
+	Name   string         `json:"name"`
+	Kind   APITokenKind   `json:"kind"`
+	Scopes APITokenScopes `json:"scopes" gorm:"type:jsonb"`
+	// the only kb a service token may access, any kb if empty
+	KBID string `json:"kb_id"`
+	// sha256 of the token, the token itself is only shown when created or rotated
+	TokenHash string `json:"-"`
+	// first characters of the token to recognize it
+	Prefix string `json:"prefix"`
+	// sandbox kbs the token may keep at the same time
+	MaxSandboxes int    `json:"max_sandboxes"`
+	CreatedBy    string `json:"created_by"`
+	// never expires if nil
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RotatedAt  *time.Time `json:"rotated_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func (APIToken) TableName() string {
+	return "api_tokens"
+}
+
+// Expired whether the token can't be used any more
+func (t *APIToken) Expired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
+
+// HasScope whether the token has the scope, nodes:write implies nodes:read
+func (t *APIToken) HasScope(scope APITokenScope) bool {
+	if scope == APITokenScopeNodesRead && slices.Contains(t.Scopes, APITokenScopeNodesWrite) {
+		return true
+	}
+	return slices.Contains(t.Scopes, scope)
+}
+
+// AdminOnly service tokens and tokens of the sandbox scope, only valid while their creator is an admin
+func (t *APIToken) AdminOnly() bool {
+	return t.Kind == APITokenKindService || t.HasScope(APITokenScopeSandbox)
+}
+
+// HashAPIToken hash of the token stored and looked up instead of the token
+func HashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+type CreateAPITokenReq struct {
+	Name string `json:"name" validate:"required,max=100"`
+	// personal if not set
+	Kind   APITokenKind    `json:"kind" validate:"omitempty,oneof=personal service"`
+	Scopes []APITokenScope `json:"scopes" validate:"required,min=1,dive,oneof=sandbox nodes:read nodes:write conversations:read stats:read"`
+	// limit a service token to the kb
+	KBID string `json:"kb_id"`
+	// 5 if not set
+	MaxSandboxes int `json:"max_sandboxes" validate:"omitempty,min=1,max=50"`
+	// days until the token expires, never if not set
+	ExpiresInDays int `json:"expires_in_days" validate:"omitempty,min=1,max=365"`
+}
+
+type CreateAPITokenResp struct {
+	*APIToken
+	// the token, shown only once
+	Token string `json:"token"`
+}
+
+type DeleteAPITokenReq struct {
+	ID string `json:"id" query:"id" validate:"required"`
+}
+
+type RotateAPITokenReq struct {
+	ID string `json:"id" validate:"required"`
+}
+
+// ContextKeyAPIToken set on the echo context by the api token middleware, the token of the request
+const ContextKeyAPIToken = "api_token"
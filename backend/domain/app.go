@@ -30,6 +30,15 @@ var AppTypes = []AppType{
 	AppTypeDisCordBot,
 }
 
+// IsBotApp app is a bot in a chat channel
+func (t AppType) IsBotApp() bool {
+	switch t {
+	case AppTypeDingTalkBot, AppTypeFeishuBot, AppTypeWechatBot, AppTypeWechatServiceBot, AppTypeDisCordBot:
+		return true
+	}
+	return false
+}
+
 type App struct {
 	ID   string  `json:"id" gorm:"primaryKey"`
 	KBID string  `json:"kb_id"`
@@ -66,6 +75,24 @@ type AppSettings struct {
 	// FeishuBot
 	FeishuBotAppID     string `json:"feishu_bot_app_id,omitempty"`
 	FeishuBotAppSecret string `json:"feishu_bot_app_secret,omitempty"`
+	// weekly gap report pushed to DingTalk/Feishu group
+	GapReport GapReportSettings `json:"gap_report"`
+	// post-processing steps of answers
+	AnswerPipeline AnswerPipelineSettings `json:"answer_pipeline"`
+	// presentation of answer sources
+	Citation CitationSettings `json:"citation"`
+	// continuation of answers cut off by the max tokens of the model
+	Continuation ContinuationSettings `json:"continuation"`
+	// canned replies of greetings and small talk
+	ChitChat ChitChatSettings `json:"chit_chat"`
+	// provenance metadata of answers
+	Provenance ProvenanceSettings `json:"provenance"`
+	// titles of new conversations
+	ConversationTitle ConversationTitleSettings `json:"conversation_title"`
+	// follow-up questions suggested after answers
+	FollowUp FollowUpSettings `json:"follow_up"`
+	// profanity, prompt injection and off-topic checks of questions and answers
+	Guardrails GuardrailSettings `json:"guardrails"`
 	// WechatAppBot
 	WeChatAppToken          string `json:"wechat_app_token,omitempty"`
 	WeChatAppEncodingAESKey string `json:"wechat_app_encodingaeskey,omitempty"`
@@ -89,6 +116,13 @@ type AppSettings struct {
 	FooterSettings FooterSettings `json:"footer_settings"`
 }
 
+type GapReportSettings struct {
+	Enabled    bool   `json:"enabled,omitempty"`
+	WebhookURL string `json:"webhook_url,omitempty"` // custom group bot webhook
+	Secret     string `json:"secret,omitempty"`      // sign secret of the group bot, optional
+	ConsoleURL string `json:"console_url,omitempty"` // admin console base url for deep links
+}
+
 type ThemeAndStyle struct {
 	BGImage string `json:"bg_image,omitempty"`
 }
@@ -167,6 +201,24 @@ type AppSettingsResp struct {
 	// FeishuBot
 	FeishuBotAppID     string `json:"feishu_bot_app_id,omitempty"`
 	FeishuBotAppSecret string `json:"feishu_bot_app_secret,omitempty"`
+	// weekly gap report pushed to DingTalk/Feishu group
+	GapReport GapReportSettings `json:"gap_report"`
+	// post-processing steps of answers
+	AnswerPipeline AnswerPipelineSettings `json:"answer_pipeline"`
+	// presentation of answer sources
+	Citation CitationSettings `json:"citation"`
+	// continuation of answers cut off by the max tokens of the model
+	Continuation ContinuationSettings `json:"continuation"`
+	// canned replies of greetings and small talk
+	ChitChat ChitChatSettings `json:"chit_chat"`
+	// provenance metadata of answers
+	Provenance ProvenanceSettings `json:"provenance"`
+	// titles of new conversations
+	ConversationTitle ConversationTitleSettings `json:"conversation_title"`
+	// follow-up questions suggested after answers
+	FollowUp FollowUpSettings `json:"follow_up"`
+	// profanity, prompt injection and off-topic checks of questions and answers
+	Guardrails GuardrailSettings `json:"guardrails"`
 
 	// WechatAppBot
 	WeChatAppToken          string `json:"wechat_app_token,omitempty"`
@@ -0,0 +1,196 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+var ErrAuditLogNotFound = NewError(ErrCodeNotFound, "audit log not found")
+
+// SettingKeyAuditLogAnchor anchor of the chain after retention removed its first entries
+const SettingKeyAuditLogAnchor = "audit_log_anchor"
+
+// AuditSnapshotMaxBytes larger request bodies are not recorded as the after snapshot of resources without a snapshot
+const AuditSnapshotMaxBytes = 64 << 10
+
+// auditRedacted value of secrets in snapshots
+const auditRedacted = "******"
+
+// auditSecretKeys keys of snapshots which are never recorded, keys containing password or secret are redacted as well
+var auditSecretKeys = map[string]bool{
+	"api_key":      true,
+	"token":        true,
+	"token_hash":   true,
+	"backup_codes": true,
+	"code":         true,
+}
+
+// table: audit_logs, rows are chained by hash, editing or deleting a row breaks the chain after it
+type AuditLog struct {
+	ID      int64  `json:"id" gorm:"primaryKey"`
+	ActorID string `json:"actor_id"`
+	// account of the actor when the action was taken
+	ActorAccount string `json:"actor_account"`
+	// api token the action was taken with, empty for console logins
+	APITokenID string `json:"api_token_id"`
+	Method     string `json:"method"`
+	// matched route of the api, like /api/v1/node/detail
+	Route        string `json:"route"`
+	ResourceType string `json:"resource_type"`
+	ResourceID   string `json:"resource_id"`
+	KBID         string `json:"kb_id"`
+	// row before the action, empty if the resource has no snapshot or did not exist
+	Before json.RawMessage `json:"before" gorm:"type:jsonb" swaggertype:"object"`
+	// row after the action, the request body if the resource has no snapshot
+	After     json.RawMessage `json:"after" gorm:"type:jsonb" swaggertype:"object"`
+	RemoteIP  string          `json:"remote_ip"`
+	PrevHash  string          `json:"prev_hash"`
+	Hash      string          `json:"hash"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
+
+// AuditLogAnchor last entry removed by retention, the remaining chain starts from its hash
+type AuditLogAnchor struct {
+	ID        int64     `json:"id"`
+	Hash      string    `json:"hash"`
+	RemovedAt time.Time `json:"removed_at"`
+}
+
+// ComputeHash sha256 of the previous hash and the content of the entry, snapshots are hashed in the
+// canonical form of encoding/json so the jsonb normalization of postgres does not change the hash
+func (l *AuditLog) ComputeHash() string {
+	h := sha256.New()
+	for _, field := range []string{
+		l.PrevHash,
+		l.ActorID,
+		l.ActorAccount,
+		l.APITokenID,
+		l.Method,
+		l.Route,
+		l.ResourceType,
+		l.ResourceID,
+		l.KBID,
+		string(CanonicalAuditSnapshot(l.Before)),
+		string(CanonicalAuditSnapshot(l.After)),
+		l.RemoteIP,
+		l.CreatedAt.UTC().Format(time.RFC3339Nano),
+	} {
+		h.Write([]byte(field))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// VerifyAuditChain index of the first entry which was edited or follows a deleted or moved entry, entries in id
+// order chained from prevHash. -1 if the chain is intact
+func VerifyAuditChain(prevHash string, entries []*AuditLog) int {
+	for i, entry := range entries {
+		if entry.PrevHash != prevHash || entry.ComputeHash() != entry.Hash {
+			return i
+		}
+		prevHash = entry.Hash
+	}
+	return -1
+}
+
+// CanonicalAuditSnapshot snapshot with sorted keys and without spaces, nil if empty or not json
+func CanonicalAuditSnapshot(snapshot json.RawMessage) json.RawMessage {
+	if len(snapshot) == 0 {
+		return nil
+	}
+	var value any
+	if err := json.Unmarshal(snapshot, &value); err != nil || value == nil {
+		return nil
+	}
+	canonical, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	return canonical
+}
+
+// RedactAuditSnapshot snapshot of the json value with passwords, secrets and tokens replaced, nil if not json
+func RedactAuditSnapshot(data []byte) json.RawMessage {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil || value == nil {
+		return nil
+	}
+	redacted, err := json.Marshal(redactAuditValue(value))
+	if err != nil {
+		return nil
+	}
+	return redacted
+}
+
+func redactAuditValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			lower := strings.ToLower(key)
+			if auditSecretKeys[lower] || strings.Contains(lower, "password") || strings.Contains(lower, "secret") {
+				if item != nil && item != "" {
+					v[key] = auditRedacted
+				}
+				continue
+			}
+			v[key] = redactAuditValue(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = redactAuditValue(item)
+		}
+	}
+	return value
+}
+
+// AuditResourceType resource of an admin api by its route, like node of /api/v1/node/detail
+func AuditResourceType(route string) string {
+	resource, _, _ := strings.Cut(strings.TrimPrefix(route, "/api/v1/"), "/")
+	return resource
+}
+
+type AuditLogListReq struct {
+	ActorID      string `json:"actor_id" query:"actor_id"`
+	ResourceType string `json:"resource_type" query:"resource_type"`
+	ResourceID   string `json:"resource_id" query:"resource_id"`
+	KBID         string `json:"kb_id" query:"kb_id"`
+	StartTime    int64  `json:"start_time" query:"start_time" validate:"min=0"` // unix timestamp, no limit if not set
+	EndTime      int64  `json:"end_time" query:"end_time" validate:"min=0"`     // unix timestamp, no limit if not set
+	Pager
+}
+
+// AuditLogListItem entry without its snapshots
+type AuditLogListItem struct {
+	ID           int64     `json:"id"`
+	ActorID      string    `json:"actor_id"`
+	ActorAccount string    `json:"actor_account"`
+	APITokenID   string    `json:"api_token_id"`
+	Method       string    `json:"method"`
+	Route        string    `json:"route"`
+	ResourceType string    `json:"resource_type"`
+	ResourceID   string    `json:"resource_id"`
+	KBID         string    `json:"kb_id"`
+	RemoteIP     string    `json:"remote_ip"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type GetAuditLogReq struct {
+	ID int64 `json:"id" query:"id" validate:"required,min=1"`
+}
+
+type AuditLogVerifyResp struct {
+	// entries checked, up to the first broken one
+	Checked int64 `json:"checked"`
+	Valid   bool  `json:"valid"`
+	// first entry which was edited or follows a deleted entry, 0 if valid
+	BrokenID int64 `json:"broken_id"`
+	// last entry removed by retention, the chain is verified from its hash. 0 if none was removed
+	AnchorID int64 `json:"anchor_id"`
+}
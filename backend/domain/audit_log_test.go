@@ -0,0 +1,148 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// newAuditChain entries chained from prevHash as the repository appends them
+func newAuditChain(prevHash string, n int) []*AuditLog {
+	createdAt := time.Date(2026, 1, 2, 3, 4, 5, 600, time.UTC)
+	entries := make([]*AuditLog, 0, n)
+	for i := range n {
+		entry := &AuditLog{
+			ID:           int64(i + 1),
+			ActorID:      "user-1",
+			ActorAccount: "admin",
+			Method:       "POST",
+			Route:        "/api/v1/node/detail",
+			ResourceType: "node",
+			ResourceID:   fmt.Sprintf("node-%d", i),
+			KBID:         "kb-1",
+			Before:       json.RawMessage(fmt.Sprintf(`{"name":"doc %d","status":1}`, i)),
+			After:        json.RawMessage(fmt.Sprintf(`{"name":"doc %d","status":2}`, i)),
+			RemoteIP:     "203.0.113.7",
+			PrevHash:     prevHash,
+			CreatedAt:    createdAt.Add(time.Duration(i) * time.Minute),
+		}
+		entry.Hash = entry.ComputeHash()
+		prevHash = entry.Hash
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestVerifyAuditChain(t *testing.T) {
+	anchor := newAuditChain("", 1)[0].Hash
+	tests := []struct {
+		name     string
+		prevHash string
+		tamper   func([]*AuditLog) []*AuditLog
+		want     int
+	}{
+		{name: "valid chain", want: -1},
+		{name: "empty chain", tamper: func(e []*AuditLog) []*AuditLog { return nil }, want: -1},
+		{name: "valid chain from the anchor", prevHash: anchor, want: -1},
+		{name: "chain of another anchor", prevHash: anchor, tamper: func(e []*AuditLog) []*AuditLog { return newAuditChain("", 5) }, want: 0},
+		{
+			name: "snapshot normalized by jsonb",
+			tamper: func(e []*AuditLog) []*AuditLog {
+				e[2].After = json.RawMessage(`{"status": 2, "name": "doc 2"}`)
+				return e
+			},
+			want: -1,
+		},
+		{name: "modified actor", tamper: func(e []*AuditLog) []*AuditLog { e[2].ActorID = "user-2"; return e }, want: 2},
+		{name: "modified resource", tamper: func(e []*AuditLog) []*AuditLog { e[3].ResourceID = "node-x"; return e }, want: 3},
+		{name: "modified snapshot", tamper: func(e []*AuditLog) []*AuditLog { e[1].After = json.RawMessage(`{"name":"doc 1","status":3}`); return e }, want: 1},
+		{name: "modified time", tamper: func(e []*AuditLog) []*AuditLog { e[4].CreatedAt = e[4].CreatedAt.Add(time.Second); return e }, want: 4},
+		{name: "modified hash", tamper: func(e []*AuditLog) []*AuditLog { e[0].Hash = e[1].Hash; return e }, want: 0},
+		{
+			name: "modified entry rehashed breaks the next entry",
+			tamper: func(e []*AuditLog) []*AuditLog {
+				e[2].ActorID = "user-2"
+				e[2].Hash = e[2].ComputeHash()
+				return e
+			},
+			want: 3,
+		},
+		{name: "removed first row", tamper: func(e []*AuditLog) []*AuditLog { return e[1:] }, want: 0},
+		{name: "removed middle row", tamper: func(e []*AuditLog) []*AuditLog { return append(e[:2], e[3:]...) }, want: 2},
+		{
+			name: "reordered rows",
+			tamper: func(e []*AuditLog) []*AuditLog {
+				e[1], e[2] = e[2], e[1]
+				return e
+			},
+			want: 1,
+		},
+		{
+			name: "reordered rows with swapped ids",
+			tamper: func(e []*AuditLog) []*AuditLog {
+				e[3].ID, e[4].ID = e[4].ID, e[3].ID
+				e[3], e[4] = e[4], e[3]
+				return e
+			},
+			want: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := newAuditChain(tt.prevHash, 5)
+			if tt.tamper != nil {
+				entries = tt.tamper(entries)
+			}
+			if got := VerifyAuditChain(tt.prevHash, entries); got != tt.want {
+				t.Errorf("VerifyAuditChain() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuditLogComputeHash(t *testing.T) {
+	entry := newAuditChain("", 1)[0]
+	if len(entry.Hash) != 64 {
+		t.Fatalf("hash %q is not a sha256 hex", entry.Hash)
+	}
+	// fields are separated so moving text from one field to the next changes the hash
+	moved := *entry
+	moved.ActorID, moved.ActorAccount = entry.ActorID+entry.ActorAccount[:1], entry.ActorAccount[1:]
+	if moved.ComputeHash() == entry.Hash {
+		t.Error("hash did not change when text moved between fields")
+	}
+	// the location of the time does not change the hash
+	local := *entry
+	local.CreatedAt = entry.CreatedAt.In(time.FixedZone("UTC+8", 8*3600))
+	if local.ComputeHash() != entry.Hash {
+		t.Error("hash changed with the location of the time")
+	}
+}
+
+func TestRedactAuditSnapshot(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{name: "password", data: `{"account":"admin","password":"p@ss"}`, want: `{"account":"admin","password":"******"}`},
+		{name: "keys containing password or secret", data: `{"new_password":"x","ClientSecret":"y","name":"z"}`, want: `{"ClientSecret":"******","name":"z","new_password":"******"}`},
+		{name: "secret keys", data: `{"api_key":"k","token":"t","token_hash":"h","backup_codes":["a"],"code":"123456"}`, want: `{"api_key":"******","backup_codes":"******","code":"******","token":"******","token_hash":"******"}`},
+		{name: "secret keys in any case", data: `{"API_KEY":"k","Token":"t"}`, want: `{"API_KEY":"******","Token":"******"}`},
+		{name: "nested objects", data: `{"settings":{"model":{"api_key":"k","base_url":"u"}}}`, want: `{"settings":{"model":{"api_key":"******","base_url":"u"}}}`},
+		{name: "objects in arrays", data: `[{"secret":"s","id":1},{"secret":"","id":2}]`, want: `[{"id":1,"secret":"******"},{"id":2,"secret":""}]`},
+		{name: "empty secrets are kept", data: `{"password":"","api_key":null}`, want: `{"api_key":null,"password":""}`},
+		{name: "no secrets", data: `{"name":"doc","tags":["a","b"]}`, want: `{"name":"doc","tags":["a","b"]}`},
+		{name: "not json", data: `password=p@ss`},
+		{name: "null", data: `null`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RedactAuditSnapshot([]byte(tt.data))
+			if string(got) != tt.want {
+				t.Errorf("RedactAuditSnapshot() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
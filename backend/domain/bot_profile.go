@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// BotAvatarMaxSize max bytes of avatar images pushed to channels
+const BotAvatarMaxSize = 2 << 20
+
+// BrandingSettings profile of the kb bots in connected channels
+type BrandingSettings struct {
+	// prefix of the bot name, e.g. 📘
+	Emoji string `json:"emoji"`
+	// kb name if empty
+	BotName string `json:"bot_name"`
+	// image url, /static-file path or data uri
+	Avatar      string `json:"avatar"`
+	Description string `json:"description"`
+	// push profile to channels when kb name or branding changes
+	AutoSync bool `json:"auto_sync"`
+}
+
+func (s *BrandingSettings) Scan(value any) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New(fmt.Sprint("invalid branding settings value type:", value))
+	}
+	return json.Unmarshal(bytes, s)
+}
+
+func (s BrandingSettings) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+// Profile bot profile of the kb
+func (s BrandingSettings) Profile(kbName string) *BotProfile {
+	name := strings.TrimSpace(s.BotName)
+	if name == "" {
+		name = kbName
+	}
+	if emoji := strings.TrimSpace(s.Emoji); emoji != "" {
+		name = emoji + " " + name
+	}
+	return &BotProfile{
+		Name:        name,
+		Avatar:      s.Avatar,
+		Description: s.Description,
+	}
+}
+
+type BotProfile struct {
+	Name        string `json:"name"`
+	Avatar      string `json:"avatar"`
+	Description string `json:"description"`
+}
+
+type BotProfileSyncStatus string
+
+const (
+	BotProfileSyncStatusSynced      BotProfileSyncStatus = "synced"
+	BotProfileSyncStatusUnsupported BotProfileSyncStatus = "unsupported"
+	BotProfileSyncStatusFailed      BotProfileSyncStatus = "failed"
+)
+
+// BotProfileFields profile fields each channel api allows bots to change, channels not listed can't change any
+var BotProfileFields = map[AppType][]string{
+	AppTypeWechatBot:  {"name", "avatar", "description"},
+	AppTypeDisCordBot: {"name", "avatar", "description"},
+}
+
+type SyncBotProfilesReq struct {
+	KBID string `json:"kb_id" validate:"required"`
+	// bot apps of kb to sync, all if empty
+	AppIDs []string `json:"app_ids"`
+}
+
+type BotProfileSyncResult struct {
+	AppID   string               `json:"app_id"`
+	AppName string               `json:"app_name"`
+	AppType AppType              `json:"app_type"`
+	Status  BotProfileSyncStatus `json:"status"`
+	// fields pushed to the channel
+	Fields []string `json:"fields"`
+	Error  string   `json:"error,omitempty"`
+}
+
+type SyncBotProfilesResp struct {
+	Profile *BotProfile             `json:"profile"`
+	Results []*BotProfileSyncResult `json:"results"`
+}
@@ -12,18 +12,32 @@ type ChatRequest struct {
 	Message        string  `json:"message" validate:"required"`
 	Nonce          string  `json:"nonce"`
 	AppType        AppType `json:"app_type" validate:"required,oneof=1 2 3"`
+	// document.referrer and location.href of the widget host page, for traffic attribution
+	Referer string `json:"referer"`
+	URL     string `json:"url"`
+	// language of the user for the locale variable of prompt templates, the Accept-Language header if not set
+	Locale string `json:"locale"`
 
 	KBID  string `json:"-" validate:"required"`
 	AppID string `json:"-"`
 
 	ModelInfo *Model `json:"-"`
 
-	RemoteIP string           `json:"-"`
-	Info     ConversationInfo `json:"-"`
+	RemoteIP  string           `json:"-"`
+	SessionID string           `json:"-"` // web session for funnel analytics, empty for bots
+	UserAgent string           `json:"-"`
+	Info      ConversationInfo `json:"-"`
+	// viewer of the public site, documents hidden from them are not retrieved. anonymous if nil, as for bots
+	Viewer *NodeViewer `json:"-"`
 }
 
 type ConversationInfo struct {
-	UserInfo UserInfo `json:"user_info"`
+	UserInfo UserInfo      `json:"user_info"`
+	Source   TrafficSource `json:"source"`
+	// device, browser and os of web and widget visitors
+	Platform ClientPlatform `json:"platform"`
+	// helpdesk ticket of historical conversations
+	Historical *HistoricalInfo `json:"historical,omitempty"`
 }
 
 type UserInfo struct {
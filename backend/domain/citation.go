@@ -0,0 +1,180 @@
+package domain
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// CitationStyle how answers of an app present their source documents
+type CitationStyle string
+
+const (
+	// numbered inline links and a reference list after the answer
+	CitationStyleInline CitationStyle = "inline"
+	// footnote marks in the answer and a reference list after the answer
+	CitationStyleFootnote CitationStyle = "footnote"
+	// no citations in the answer text, sources are shown as cards from the retrieved documents
+	CitationStyleCards CitationStyle = "cards"
+	// no citations at all
+	CitationStyleNone CitationStyle = "none"
+)
+
+// CitationSettings per app presentation of answer sources
+type CitationSettings struct {
+	Style CitationStyle `json:"style,omitempty" validate:"omitempty,oneof=inline footnote cards none"`
+}
+
+// StyleOrDefault inline citations of apps without settings
+func (s CitationSettings) StyleOrDefault() CitationStyle {
+	if s.Style == "" {
+		return CitationStyleInline
+	}
+	return s.Style
+}
+
+// referenceListPrompt reference list block of answers, references of messages are extracted from it
+const referenceListPrompt = `  回答结束后，如果有引用列表则按照序号输出，格式如下，没有则不输出
+	---
+	### 引用列表
+	> [1]. [文档标题1](URL1)
+	> [2]. [文档标题2](URL2)
+	> ...
+	> [N]. [文档标题N](URLN)
+	---`
+
+// PromptInstructions citation step of the system prompt
+func (s CitationStyle) PromptInstructions() string {
+	switch s {
+	case CitationStyleFootnote:
+		return `6.如果回答的内容引用了文档，请使用脚注标注回答内容的来源：
+	- 你需要给回答中引用的相关文档添加唯一序号，序号从1开始依次递增，跟回答无关的文档不添加序号
+	- 句号前放置脚注标记，正文中不要输出文档URL
+	- 脚注使用格式 [^文档序号]
+	- 如果多个不同文档支持同一观点，使用组合脚注：[^1][^2][^N]
+` + referenceListPrompt
+	case CitationStyleCards, CitationStyleNone:
+		return `6.回答中不要标注引用标记，也不要输出引用列表或文档URL`
+	default:
+		return `6.如果回答的内容引用了文档，请使用内联引用格式标注回答内容的来源：
+	- 你需要给回答中引用的相关文档添加唯一序号，序号从1开始依次递增，跟回答无关的文档不添加序号
+	- 句号前放置引用标记
+	- 引用使用格式 [[文档序号](URL)]
+	- 如果多个不同文档支持同一观点，使用组合引用：[[文档序号](URL1)],[[文档序号](URL2)],[[文档序号](URLN)]
+` + referenceListPrompt
+	}
+}
+
+// ListsReferences report whether answers end with a reference list block
+func (s CitationStyle) ListsReferences() bool {
+	return s != CitationStyleCards && s != CitationStyleNone
+}
+
+// CardReferences references of the retrieved documents, shown as cards instead of citations in the answer
+func CardReferences(conversationID, appID string, rankedNodes []*RankedNodeChunks, baseURL string) []*ConversationReference {
+	references := make([]*ConversationReference, 0, len(rankedNodes))
+	for _, node := range rankedNodes {
+		references = append(references, &ConversationReference{
+			ConversationID: conversationID,
+			AppID:          appID,
+			NodeID:         node.NodeID,
+			Name:           node.NodeName,
+			URL:            node.GetURL(baseURL),
+		})
+	}
+	return references
+}
+
+// citationPattern inline citations and lines of the reference list block, in the order they stream
+var citationPattern = regexp.MustCompile(`\[\[(\d+)\]\(([^()\s]+)\)\]|(?m:^)(?:>|\\u003e)\s*\[(\d+)\]\.\s*\[[^\]\n]*\]\(([^()\s]+)\)`)
+
+// citedNodeIDPattern node of a cited document url
+var citedNodeIDPattern = regexp.MustCompile(`/node/([\w-]+)`)
+
+// CitationTracker documents cited by an answer while it streams, each retrieved document is reported once when it is
+// first cited. urls of documents not retrieved for the answer are ignored
+type CitationTracker struct {
+	nodes   map[string]*RankedNodeChunks
+	baseURL string
+	seen    map[string]bool
+
+	text string
+	// text before it is scanned, citations do not span lines
+	scanned      int
+	thinkChecked bool
+	thinking     bool
+}
+
+func NewCitationTracker(rankedNodes []*RankedNodeChunks, baseURL string) *CitationTracker {
+	nodes := make(map[string]*RankedNodeChunks, len(rankedNodes))
+	for _, node := range rankedNodes {
+		nodes[node.NodeID] = node
+	}
+	return &CitationTracker{nodes: nodes, baseURL: baseURL, seen: make(map[string]bool)}
+}
+
+// Write append a streamed chunk of the answer, return documents first cited by it
+func (t *CitationTracker) Write(chunk string) []*SSEReference {
+	t.text += chunk
+	const thinkStart, thinkEnd = "<think>", "</think>"
+	if !t.thinkChecked {
+		if len(t.text) < len(thinkStart) && strings.HasPrefix(thinkStart, t.text) {
+			return nil
+		}
+		t.thinkChecked = true
+		t.thinking = strings.HasPrefix(t.text, thinkStart)
+	}
+	// reasoning is not part of the answer
+	if t.thinking {
+		i := strings.Index(t.text[t.scanned:], thinkEnd)
+		if i < 0 {
+			t.scanned = max(t.scanned, len(t.text)-len(thinkEnd))
+			return nil
+		}
+		t.scanned += i + len(thinkEnd)
+		t.thinking = false
+	}
+	segment := t.text[t.scanned:]
+	var references []*SSEReference
+	end := 0
+	for _, match := range citationPattern.FindAllStringSubmatchIndex(segment, -1) {
+		end = match[1]
+		indexGroup, urlGroup := 2, 4
+		if match[2] < 0 {
+			indexGroup, urlGroup = 6, 8
+		}
+		url := segment[match[urlGroup]:match[urlGroup+1]]
+		nodeID := citedNodeIDPattern.FindStringSubmatch(url)
+		if nodeID == nil {
+			continue
+		}
+		node, ok := t.nodes[nodeID[1]]
+		if !ok || t.seen[node.NodeID] {
+			continue
+		}
+		t.seen[node.NodeID] = true
+		index, _ := strconv.Atoi(segment[match[indexGroup]:match[indexGroup+1]])
+		references = append(references, &SSEReference{
+			Index:  index,
+			NodeID: node.NodeID,
+			Name:   node.NodeName,
+			URL:    node.GetURL(t.baseURL),
+		})
+	}
+	t.scanned += max(end, strings.LastIndexByte(segment, '\n')+1)
+	return references
+}
+
+// CardSSEReferences references of the retrieved documents shown as cards, sent before the answer streams
+func CardSSEReferences(rankedNodes []*RankedNodeChunks, baseURL string) []*SSEReference {
+	references := make([]*SSEReference, 0, len(rankedNodes))
+	for i, node := range rankedNodes {
+		references = append(references, &SSEReference{
+			Index:  i + 1,
+			NodeID: node.NodeID,
+			Name:   node.NodeName,
+			URL:    node.GetURL(baseURL),
+		})
+	}
+	return references
+}
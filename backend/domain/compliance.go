@@ -0,0 +1,193 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type ComplianceRegion string
+
+const (
+	ComplianceRegionNone ComplianceRegion = ""
+	ComplianceRegionCN   ComplianceRegion = "cn"
+	ComplianceRegionEU   ComplianceRegion = "eu"
+	ComplianceRegionUS   ComplianceRegion = "us"
+)
+
+const DefaultComplianceBlockedReply = "抱歉，该问题涉及的内容不在可回答的范围内。"
+
+// ComplianceSettings per kb content policy, applied as prompt constraints and answer post filters
+type ComplianceSettings struct {
+	// built-in profile of the deployment region, merged with the custom rules below
+	Region         ComplianceRegion       `json:"region,omitempty"`
+	DisabledTopics []ComplianceTopic      `json:"disabled_topics,omitempty"`
+	Disclaimers    []ComplianceDisclaimer `json:"disclaimers,omitempty"`
+	BlockedReply   string                 `json:"blocked_reply,omitempty"`
+	// phrases the model must never output, like competitor or deprecated product names
+	BannedPhrases []BannedPhrase `json:"banned_phrases,omitempty"`
+	// generation stops at any of them, passed to the model
+	StopSequences []string `json:"stop_sequences,omitempty"`
+}
+
+// BannedPhrase phrase replaced in answers in any case and width, removed if the replacement is empty
+type BannedPhrase struct {
+	Phrase      string `json:"phrase"`
+	Replacement string `json:"replacement"`
+}
+
+// ComplianceTopic questions matching any keyword are refused without calling the model
+type ComplianceTopic struct {
+	Name     string   `json:"name"`
+	Keywords []string `json:"keywords"`
+}
+
+// ComplianceDisclaimer appended to the answer if the question or answer matches any keyword
+type ComplianceDisclaimer struct {
+	Category string   `json:"category"`
+	Keywords []string `json:"keywords"`
+	Text     string   `json:"text"`
+}
+
+type ComplianceProfile struct {
+	Region   ComplianceRegion   `json:"region"`
+	Name     string             `json:"name"`
+	Settings ComplianceSettings `json:"settings"`
+}
+
+var ComplianceProfiles = []ComplianceProfile{
+	{
+		Region: ComplianceRegionCN,
+		Name:   "中国大陆",
+		Settings: ComplianceSettings{
+			Disclaimers: []ComplianceDisclaimer{
+				{Category: "financial", Keywords: []string{"股票", "基金", "理财", "投资", "收益率"}, Text: "以上内容仅供参考，不构成任何投资建议。市场有风险，投资需谨慎。"},
+				{Category: "medical", Keywords: []string{"药", "治疗", "诊断", "症状", "病"}, Text: "以上内容仅供参考，不能替代专业医疗意见，如有不适请及时就医。"},
+			},
+		},
+	},
+	{
+		Region: ComplianceRegionEU,
+		Name:   "European Union",
+		Settings: ComplianceSettings{
+			Disclaimers: []ComplianceDisclaimer{
+				{Category: "ai", Keywords: []string{""}, Text: "This answer was generated by an AI system and may contain errors."},
+				{Category: "financial", Keywords: []string{"stock", "fund", "invest", "loan", "crypto"}, Text: "This is not financial advice. Please consult a licensed advisor."},
+				{Category: "medical", Keywords: []string{"medicine", "drug", "treatment", "diagnosis", "symptom"}, Text: "This is not medical advice. Please consult a healthcare professional."},
+			},
+		},
+	},
+	{
+		Region: ComplianceRegionUS,
+		Name:   "United States",
+		Settings: ComplianceSettings{
+			Disclaimers: []ComplianceDisclaimer{
+				{Category: "financial", Keywords: []string{"stock", "fund", "invest", "loan", "crypto"}, Text: "This is not financial advice. Please consult a licensed advisor."},
+				{Category: "medical", Keywords: []string{"medicine", "drug", "treatment", "diagnosis", "symptom"}, Text: "This is not medical advice. Please consult a healthcare professional."},
+			},
+		},
+	},
+}
+
+func (s *ComplianceSettings) Scan(value any) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New(fmt.Sprint("invalid compliance settings value type:", value))
+	}
+	return json.Unmarshal(bytes, s)
+}
+
+func (s ComplianceSettings) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+// Effective merge the built-in profile of the region with custom rules
+func (s ComplianceSettings) Effective() ComplianceSettings {
+	effective := ComplianceSettings{
+		Region:        s.Region,
+		BlockedReply:  s.BlockedReply,
+		BannedPhrases: s.BannedPhrases,
+		StopSequences: s.StopSequences,
+	}
+	for _, profile := range ComplianceProfiles {
+		if profile.Region == s.Region {
+			effective.DisabledTopics = append(effective.DisabledTopics, profile.Settings.DisabledTopics...)
+			effective.Disclaimers = append(effective.Disclaimers, profile.Settings.Disclaimers...)
+		}
+	}
+	effective.DisabledTopics = append(effective.DisabledTopics, s.DisabledTopics...)
+	effective.Disclaimers = append(effective.Disclaimers, s.Disclaimers...)
+	if effective.BlockedReply == "" {
+		effective.BlockedReply = DefaultComplianceBlockedReply
+	}
+	return effective
+}
+
+// MatchDisabledTopic return the first disabled topic the text mentions
+func (s ComplianceSettings) MatchDisabledTopic(text string) *ComplianceTopic {
+	text = strings.ToLower(text)
+	for i, topic := range s.DisabledTopics {
+		if containsAnyKeyword(text, topic.Keywords) {
+			return &s.DisabledTopics[i]
+		}
+	}
+	return nil
+}
+
+// MatchDisclaimers return disclaimer texts matched by any of texts, an empty keyword matches everything
+func (s ComplianceSettings) MatchDisclaimers(texts ...string) []string {
+	text := strings.ToLower(strings.Join(texts, "\n"))
+	matched := make([]string, 0)
+	for _, disclaimer := range s.Disclaimers {
+		if disclaimer.Text != "" && containsAnyKeyword(text, disclaimer.Keywords) {
+			matched = append(matched, disclaimer.Text)
+		}
+	}
+	return matched
+}
+
+// PromptConstraints extra system prompt rules of the content policy
+func (s ComplianceSettings) PromptConstraints() string {
+	rules := make([]string, 0, 2)
+	if len(s.DisabledTopics) > 0 {
+		topics := make([]string, 0, len(s.DisabledTopics))
+		for _, topic := range s.DisabledTopics {
+			topics = append(topics, topic.Name)
+		}
+		rules = append(rules, fmt.Sprintf("不得回答或讨论以下话题：%s。如用户问题涉及上述话题，请直接回答\"%s\"",
+			strings.Join(topics, "、"), s.BlockedReply))
+	}
+	if phrases := s.bannedPhraseList(); len(phrases) > 0 {
+		rules = append(rules, fmt.Sprintf("回答中不得出现以下词语：%s", strings.Join(phrases, "、")))
+	}
+	if len(rules) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("\n内容合规要求：\n")
+	for i, rule := range rules {
+		fmt.Fprintf(&sb, "%d. %s\n", i+1, rule)
+	}
+	return sb.String()
+}
+
+func (s ComplianceSettings) bannedPhraseList() []string {
+	phrases := make([]string, 0, len(s.BannedPhrases))
+	for _, banned := range s.BannedPhrases {
+		if banned.Phrase != "" {
+			phrases = append(phrases, banned.Phrase)
+		}
+	}
+	return phrases
+}
+
+func containsAnyKeyword(text string, keywords []string) bool {
+	for _, keyword := range keywords {
+		if strings.Contains(text, strings.ToLower(keyword)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,26 @@
+package domain
+
+// DefaultContinuationRounds continuation requests of apps without settings
+const DefaultContinuationRounds = 2
+
+// ContinuationPrompt user message asking the model to go on with an answer cut off by its max tokens
+const ContinuationPrompt = "你的回答因长度限制被截断了，请从中断的地方直接继续输出，不要重复已经输出的内容，也不要添加任何说明。"
+
+// ContinuationSettings per app continuation of answers cut off by the max tokens of the model
+type ContinuationSettings struct {
+	// continuation requests after a truncated answer, 0 disables continuation. 2 if not set
+	MaxRounds *int `json:"max_rounds,omitempty" validate:"omitempty,min=0,max=5"`
+}
+
+// RoundsOrDefault max continuation rounds of the app
+func (s ContinuationSettings) RoundsOrDefault() int {
+	if s.MaxRounds == nil {
+		return DefaultContinuationRounds
+	}
+	return *s.MaxRounds
+}
+
+// IsTruncatedFinish finish reason of a response stopped by the max tokens of the model
+func IsTruncatedFinish(reason string) bool {
+	return reason == "length" || reason == "max_tokens"
+}
@@ -1,6 +1,9 @@
 package domain
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"time"
 
 	"github.com/cloudwego/eino/schema"
@@ -14,10 +17,15 @@ type Conversation struct {
 	AppID string `json:"app_id" gorm:"index"`
 
 	Subject string `json:"subject"` // subject for conversation, now is first question
-
-	RemoteIP  string           `json:"remote_ip"`
-	Info      ConversationInfo `json:"info" gorm:"type:jsonb"`
-	CreatedAt time.Time        `json:"created_at"`
+	// short title generated from the first question, empty on conversations created before titles
+	Title string `json:"title"`
+
+	RemoteIP string           `json:"remote_ip"`
+	Info     ConversationInfo `json:"info" gorm:"type:jsonb"`
+	IsBot    bool             `json:"is_bot"`
+	// imported from a legacy helpdesk, not a chat with an app
+	Historical bool      `json:"historical"`
+	CreatedAt  time.Time `json:"created_at"`
 }
 
 type ConversationMessage struct {
@@ -28,21 +36,98 @@ type ConversationMessage struct {
 	Role    schema.RoleType `json:"role"`
 	Content string          `json:"content"`
 
-	// model
-	Provider         ModelProvider `json:"provider"`
-	Model            string        `json:"model"`
-	PromptTokens     int           `json:"prompt_tokens" gorm:"default:0"`
-	CompletionTokens int           `json:"completion_tokens" gorm:"default:0"`
-	TotalTokens      int           `json:"total_tokens" gorm:"default:0"`
+	// model, of the endpoint which served the answer
+	Provider ModelProvider `json:"provider"`
+	Model    string        `json:"model"`
+	ModelID  string        `json:"model_id"`
+	// endpoints skipped after they timed out, were rate limited or failed before answering
+	Failovers int `json:"failovers" gorm:"default:0"`
+
+	PromptTokens     int `json:"prompt_tokens" gorm:"default:0"`
+	CompletionTokens int `json:"completion_tokens" gorm:"default:0"`
+	TotalTokens      int `json:"total_tokens" gorm:"default:0"`
+
+	// retrieval confidence of assistant answer, low confidence answers are replied with reference links only
+	Confidence    float64 `json:"confidence"`
+	LowConfidence bool    `json:"low_confidence"`
+	// how the question was answered, empty on questions and answers saved before routing
+	Route QuestionRoute `json:"route"`
+
+	// where the answer came from, recorded if provenance is enabled for the app
+	Provenance *AnswerProvenance `json:"provenance,omitempty" gorm:"type:jsonb"`
+	// scores of the documents of the answer before and after the rerank stage, nil if the kb does not rerank
+	RerankScores RerankScores `json:"rerank_scores,omitempty" gorm:"type:jsonb"`
+	// query the documents of the answer were retrieved with, empty if the question was not rewritten
+	RetrievalQuery string `json:"retrieval_query,omitempty"`
+	// version of the prompt template of the app the answer was generated with, 0 for the built-in prompt
+	PromptTemplateVersion int `json:"prompt_template_version" gorm:"default:0"`
+	// follow-up questions suggested after the answer, nil if the app does not suggest them
+	FollowUps FollowUpQuestions `json:"follow_ups,omitempty" gorm:"type:jsonb"`
+
+	// streaming answers are checkpointed, partial answers stay streaming if the server stops
+	Status MessageStatus `json:"status" gorm:"default:completed"`
+
+	// rating of the answer by the end user
+	FeedbackType    MessageFeedbackType `json:"feedback_type"`
+	FeedbackComment string              `json:"feedback_comment"`
 
 	// stats
 	RemoteIP  string    `json:"remote_ip"`
 	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// references of answers not listing them in the content, extracted from the reference list block if nil
+	References []*ConversationReference `json:"-" gorm:"-"`
+}
+
+type MessageStatus string
+
+const (
+	MessageStatusStreaming MessageStatus = "streaming"
+	MessageStatusCompleted MessageStatus = "completed"
+	MessageStatusFailed    MessageStatus = "failed"
+	// streaming message not checkpointed for MessageStreamTimeout, the answer was cut off
+	MessageStatusInterrupted MessageStatus = "interrupted"
+)
+
+const (
+	// MessageCheckpointInterval streamed answer is saved at most once per interval
+	MessageCheckpointInterval = 2 * time.Second
+	MessageStreamTimeout      = 5 * time.Minute
+	// HistoryTokenBudget oldest history messages are dropped from the prompt beyond this many tokens
+	HistoryTokenBudget = 8192
+)
+
+// EffectiveStatus streaming messages not updated for a while are interrupted
+func (m *ConversationMessage) EffectiveStatus(now time.Time) MessageStatus {
+	if m.Status == MessageStatusStreaming && now.Sub(m.UpdatedAt) > MessageStreamTimeout {
+		return MessageStatusInterrupted
+	}
+	return m.Status
+}
+
+// ConversationVersion message and reference counts of a conversation, used as etag of conversation detail
+type ConversationVersion struct {
+	MessageCount   int64      `json:"message_count"`
+	LastMessageAt  *time.Time `json:"last_message_at"`
+	ReferenceCount int64      `json:"reference_count"`
+}
+
+// ETag weak etag of conversation detail, since is the message cursor of the request
+func (v *ConversationVersion) ETag(conversationID, since string) string {
+	lastMessageAt := int64(0)
+	if v.LastMessageAt != nil {
+		lastMessageAt = v.LastMessageAt.UnixNano()
+	}
+	sum := sha256.Sum256(fmt.Appendf(nil, "%s:%s:%d:%d:%d", conversationID, since, v.MessageCount, lastMessageAt, v.ReferenceCount))
+	return fmt.Sprintf(`W/"%s"`, hex.EncodeToString(sum[:16]))
 }
 
 type ConversationReference struct {
 	ConversationID string `json:"conversation_id" gorm:"index"`
 	AppID          string `json:"app_id"`
+	// answer listing the reference, empty for references saved before answers were tracked
+	MessageID string `json:"message_id"`
 
 	NodeID string `json:"node_id"`
 	Name   string `json:"name"`
@@ -53,10 +138,13 @@ type ConversationListReq struct {
 	KBID  string  `json:"kb_id" query:"kb_id" validate:"required"`
 	AppID *string `json:"app_id" query:"app_id"`
 
+	// matches the title or the first question
 	Subject *string `json:"subject" query:"subject"`
 
 	RemoteIP *string `json:"remote_ip" query:"remote_ip"`
 
+	Historical *bool `json:"historical" query:"historical"`
+
 	Pager
 }
 
@@ -65,18 +153,21 @@ type ConversationListItem struct {
 	AppName string  `json:"app_name"`
 	AppType AppType `json:"app_type"`
 	Subject string  `json:"subject"`
+	Title   string  `json:"title"`
 
 	RemoteIP string `json:"remote_ip"`
 
 	IPAddress *IPAddress `json:"ip_address" gorm:"-"`
 
-	CreatedAt time.Time `json:"created_at"`
+	Historical bool      `json:"historical"`
+	CreatedAt  time.Time `json:"created_at"`
 }
 
 type ConversationDetailResp struct {
 	ID       string `json:"id"`
 	AppID    string `json:"app_id"`
 	Subject  string `json:"subject"`
+	Title    string `json:"title"`
 	RemoteIP string `json:"remote_ip"`
 
 	Messages   []*ConversationMessage   `json:"messages" gorm:"-"`
@@ -84,5 +175,14 @@ type ConversationDetailResp struct {
 
 	IPAddress *IPAddress `json:"ip_address" gorm:"-"`
 
-	CreatedAt time.Time `json:"created_at"`
+	Historical bool      `json:"historical"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// DisplayTitle title of the conversation, from the first question if it was created before titles
+func DisplayTitle(title, subject string) string {
+	if title != "" {
+		return title
+	}
+	return HeuristicConversationTitle(subject)
 }
@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+type HistoricalSource string
+
+const (
+	HistoricalSourceCSV     HistoricalSource = "csv"
+	HistoricalSourceZendesk HistoricalSource = "zendesk"
+)
+
+// HistoricalInfo transcript of a legacy helpdesk imported as a historical conversation
+type HistoricalInfo struct {
+	Source     HistoricalSource `json:"source"`
+	ExternalID string           `json:"external_id"` // ticket id, re-importing it is skipped
+	Status     string           `json:"status"`
+	// unresolved tickets are reported as unanswered questions by gap analysis
+	Resolved bool `json:"resolved"`
+}
+
+type ImportTranscriptsReq struct {
+	KBID   string           `json:"kb_id" form:"kb_id" validate:"required"`
+	Format HistoricalSource `json:"format" form:"format" validate:"required,oneof=csv zendesk"`
+}
+
+type ImportTranscriptsResp struct {
+	ConversationCount int      `json:"conversation_count"`
+	MessageCount      int      `json:"message_count"`
+	SkippedCount      int      `json:"skipped_count"` // already imported
+	Warnings          []string `json:"warnings"`
+}
+
+// HistoricalTranscript conversation parsed from a helpdesk export
+type HistoricalTranscript struct {
+	ExternalID string
+	Subject    string
+	Status     string
+	Requester  UserInfo
+	CreatedAt  time.Time
+	Messages   []*HistoricalMessage
+}
+
+type HistoricalMessage struct {
+	Role      schema.RoleType
+	Content   string
+	CreatedAt time.Time
+}
+
+// IsResolved closed helpdesk tickets, tickets without status are resolved if an agent replied
+func (t *HistoricalTranscript) IsResolved() bool {
+	switch strings.ToLower(strings.TrimSpace(t.Status)) {
+	case "solved", "closed", "resolved", "done":
+		return true
+	case "":
+		for _, message := range t.Messages {
+			if message.Role == schema.Assistant {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// TranscriptRole role of a message author in helpdesk exports, empty if unknown
+func TranscriptRole(author string) schema.RoleType {
+	switch strings.ToLower(strings.TrimSpace(author)) {
+	case "user", "customer", "requester", "end-user", "end_user", "visitor", "client":
+		return schema.User
+	case "assistant", "agent", "admin", "support", "staff", "bot", "operator":
+		return schema.Assistant
+	}
+	return ""
+}
@@ -0,0 +1,206 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+const (
+	DefaultConversationRescoreDays  = 7
+	DefaultConversationRescoreLimit = 200
+	MaxConversationRescoreLimit     = 500
+	// ConversationRescoreSampleLimit max answers whose verdict changed listed in the result
+	ConversationRescoreSampleLimit = 20
+)
+
+// ConversationRescoreReq re-score recent answers of kb offline with candidate answer settings, nothing is answered or changed
+type ConversationRescoreReq struct {
+	KBID string `json:"kb_id" validate:"required"`
+	// answers of the recent days, 7 if not set
+	Days int `json:"days" validate:"omitempty,min=1,max=90"`
+	// most recent answers re-scored, 200 if not set
+	Limit int `json:"limit" validate:"omitempty,min=1,max=500"`
+	// retrieval and confidence gate settings to evaluate, the current settings of kb if not set
+	AnswerSettings *AnswerSettings `json:"answer_settings"`
+}
+
+// table: conversation_rescore_jobs
+type ConversationRescoreJob struct {
+	ID    string `json:"id" gorm:"primaryKey"`
+	KBID  string `json:"kb_id"`
+	Days  int    `json:"days"`
+	Limit int    `json:"limit"`
+	// candidate settings of the job, nil for the settings of kb when it runs
+	AnswerSettings *AnswerSettings `json:"answer_settings" gorm:"type:jsonb"`
+	// admin who started the job
+	CreatedBy string                     `json:"created_by"`
+	Status    NodeExportJobStatus        `json:"status"`
+	Result    *ConversationRescoreResult `json:"result" gorm:"type:jsonb"`
+	Error     string                     `json:"error"`
+	CreatedAt time.Time                  `json:"created_at"`
+	UpdatedAt time.Time                  `json:"updated_at"`
+}
+
+func (ConversationRescoreJob) TableName() string {
+	return "conversation_rescore_jobs"
+}
+
+// RescoreAnswer recent answer with the question it answered, as it was scored when answered
+type RescoreAnswer struct {
+	MessageID      string              `json:"message_id"`
+	ConversationID string              `json:"conversation_id"`
+	Question       string              `json:"question"`
+	Confidence     float64             `json:"confidence"`
+	LowConfidence  bool                `json:"low_confidence"`
+	FeedbackType   MessageFeedbackType `json:"feedback_type"`
+	// documents referenced by the answer
+	NodeIDs   StringList `json:"node_ids" gorm:"type:jsonb"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// ConversationRescoreScores confidence of the answers under one version of the settings
+type ConversationRescoreScores struct {
+	LowConfidenceCount int     `json:"low_confidence_count"`
+	LowConfidenceRate  float64 `json:"low_confidence_rate"`
+	AvgRetrievalScore  float64 `json:"avg_retrieval_score"`
+	// share of question terms found in retrieved documents, only known for re-scored answers
+	AvgGroundedness float64 `json:"avg_groundedness,omitempty"`
+	// likes of rated answers, after the settings rated answers which would be gated are left out
+	SatisfactionRate float64 `json:"satisfaction_rate"`
+}
+
+// ConversationRescoreSample answer whose verdict changes under the settings of the job
+type ConversationRescoreSample struct {
+	MessageID      string              `json:"message_id"`
+	ConversationID string              `json:"conversation_id"`
+	Question       string              `json:"question"`
+	FeedbackType   MessageFeedbackType `json:"feedback_type"`
+	LowConfidence  bool                `json:"low_confidence"`
+	// low confidence under the settings of the job
+	NewLowConfidence  bool     `json:"new_low_confidence"`
+	NewRetrievalScore float64  `json:"new_retrieval_score"`
+	NewGroundedness   float64  `json:"new_groundedness"`
+	NewNodeIDs        []string `json:"new_node_ids"`
+}
+
+// ConversationRescoreResult recent answers as they were scored and as they would be scored with the settings of the job
+type ConversationRescoreResult struct {
+	AnswerCount int `json:"answer_count"`
+	// answers skipped because retrieval failed
+	FailedCount int                       `json:"failed_count"`
+	Before      ConversationRescoreScores `json:"before"`
+	After       ConversationRescoreScores `json:"after"`
+	// low confidence answers which would be answered, and answers which would be replied with reference links only
+	NewlyAnswered int `json:"newly_answered"`
+	NewlyGated    int `json:"newly_gated"`
+	LikeCount     int `json:"like_count"`
+	DislikeCount  int `json:"dislike_count"`
+	// rated answers which would be gated, likes lost and dislikes avoided
+	LikedGated    int `json:"liked_gated"`
+	DislikedGated int `json:"disliked_gated"`
+	// answers whose best document is not one they referenced, and how many of them were disliked
+	RetrievalChanged         int                          `json:"retrieval_changed"`
+	DislikedRetrievalChanged int                          `json:"disliked_retrieval_changed"`
+	Samples                  []*ConversationRescoreSample `json:"samples"`
+}
+
+func (r *ConversationRescoreResult) Scan(value any) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New(fmt.Sprint("invalid conversation rescore result value type:", value))
+	}
+	return json.Unmarshal(bytes, r)
+}
+
+func (r ConversationRescoreResult) Value() (driver.Value, error) {
+	return json.Marshal(r)
+}
+
+// Add count an answer scored again with the confidence under the settings of the job
+func (r *ConversationRescoreResult) Add(answer *RescoreAnswer, confidence AnswerConfidence, gated bool, nodeIDs []string) {
+	r.AnswerCount++
+	switch answer.FeedbackType {
+	case MessageFeedbackLike:
+		r.LikeCount++
+	case MessageFeedbackDislike:
+		r.DislikeCount++
+	}
+	if answer.LowConfidence {
+		r.Before.LowConfidenceCount++
+	}
+	if gated {
+		r.After.LowConfidenceCount++
+	}
+	r.Before.AvgRetrievalScore += answer.Confidence
+	r.After.AvgRetrievalScore += confidence.RetrievalScore
+	r.After.AvgGroundedness += confidence.Groundedness
+	switch {
+	case answer.LowConfidence && !gated:
+		r.NewlyAnswered++
+	case !answer.LowConfidence && gated:
+		r.NewlyGated++
+		switch answer.FeedbackType {
+		case MessageFeedbackLike:
+			r.LikedGated++
+		case MessageFeedbackDislike:
+			r.DislikedGated++
+		}
+	}
+	if len(nodeIDs) > 0 && len(answer.NodeIDs) > 0 && !slices.Contains(answer.NodeIDs, nodeIDs[0]) {
+		r.RetrievalChanged++
+		if answer.FeedbackType == MessageFeedbackDislike {
+			r.DislikedRetrievalChanged++
+		}
+	}
+	if answer.LowConfidence != gated && len(r.Samples) < ConversationRescoreSampleLimit {
+		r.Samples = append(r.Samples, &ConversationRescoreSample{
+			MessageID:         answer.MessageID,
+			ConversationID:    answer.ConversationID,
+			Question:          answer.Question,
+			FeedbackType:      answer.FeedbackType,
+			LowConfidence:     answer.LowConfidence,
+			NewLowConfidence:  gated,
+			NewRetrievalScore: confidence.RetrievalScore,
+			NewGroundedness:   confidence.Groundedness,
+			NewNodeIDs:        nodeIDs,
+		})
+	}
+}
+
+// Finish turn sums to averages and rates
+func (r *ConversationRescoreResult) Finish() {
+	if r.Samples == nil {
+		r.Samples = []*ConversationRescoreSample{}
+	}
+	r.Before.SatisfactionRate = SatisfactionRate(int64(r.LikeCount), int64(r.DislikeCount))
+	r.After.SatisfactionRate = SatisfactionRate(int64(r.LikeCount-r.LikedGated), int64(r.DislikeCount-r.DislikedGated))
+	if r.AnswerCount == 0 {
+		return
+	}
+	count := float64(r.AnswerCount)
+	r.Before.LowConfidenceRate = float64(r.Before.LowConfidenceCount) / count
+	r.After.LowConfidenceRate = float64(r.After.LowConfidenceCount) / count
+	r.Before.AvgRetrievalScore /= count
+	r.After.AvgRetrievalScore /= count
+	r.After.AvgGroundedness /= count
+}
+
+type ConversationRescoreJobListReq struct {
+	KBID string `json:"kb_id" query:"kb_id" validate:"required"`
+
+	Pager
+}
+
+type ConversationRescoreJobReq struct {
+	KBID  string `json:"kb_id" query:"kb_id" validate:"required"`
+	JobID string `json:"job_id" query:"job_id" validate:"required"`
+}
+
+// ConversationRescoreJobRequest mq message of conversation rescore job
+type ConversationRescoreJobRequest struct {
+	JobID string `json:"job_id"`
+}
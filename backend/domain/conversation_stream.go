@@ -0,0 +1,29 @@
+package domain
+
+// ConversationStreamBatchSize conversations fetched from the cursor at a time, messages are loaded per batch
+const ConversationStreamBatchSize = 100
+
+// ConversationStreamReq filter of the conversations streamed as ndjson
+type ConversationStreamReq struct {
+	KBID       string `json:"kb_id" query:"kb_id" validate:"required"`
+	AppID      string `json:"app_id" query:"app_id"`
+	RemoteIP   string `json:"remote_ip" query:"remote_ip"`
+	Historical *bool  `json:"historical" query:"historical"`
+	// unix timestamps bounding created_at, unbounded if 0
+	StartTime int64 `json:"start_time" query:"start_time" validate:"min=0"`
+	EndTime   int64 `json:"end_time" query:"end_time" validate:"min=0"`
+	// include messages and references of each conversation
+	WithMessages bool `json:"with_messages" query:"with_messages"`
+}
+
+// ConversationStreamItem one line of the stream
+type ConversationStreamItem struct {
+	*Conversation
+	Messages   []*ConversationMessage   `json:"messages,omitempty"`
+	References []*ConversationReference `json:"references,omitempty"`
+}
+
+// ConversationStreamError last line of a stream failed after it started, the status is already sent
+type ConversationStreamError struct {
+	Error string `json:"error"`
+}
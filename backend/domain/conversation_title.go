@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ConversationTitleMode how titles of new conversations are generated
+type ConversationTitleMode string
+
+const (
+	// first sentence of the first question, cleaned up and truncated
+	ConversationTitleModeHeuristic ConversationTitleMode = "heuristic"
+	// summarized by the chat model after the first answer, the heuristic title is kept until then or if it fails
+	ConversationTitleModeLLM ConversationTitleMode = "llm"
+)
+
+const (
+	// ConversationTitleMaxRunes titles are truncated to this with an ellipsis
+	ConversationTitleMaxRunes = 30
+	ConversationTitleTimeout  = 30 * time.Second
+)
+
+const ConversationTitlePrompt = "你是对话标题生成助手。请根据用户的第一个问题，生成一个简短的对话标题，概括问题的主题。" +
+	"标题使用与问题相同的语言，不超过20个字，不要包含引号、标点结尾或任何解释，只输出标题本身。"
+
+// ConversationTitleSettings per app generation of conversation titles
+type ConversationTitleSettings struct {
+	// heuristic if not set
+	Mode ConversationTitleMode `json:"mode,omitempty" validate:"omitempty,oneof=heuristic llm"`
+}
+
+// ModeOrDefault heuristic titles of apps without settings
+func (s ConversationTitleSettings) ModeOrDefault() ConversationTitleMode {
+	if s.Mode == "" {
+		return ConversationTitleModeHeuristic
+	}
+	return s.Mode
+}
+
+// conversationTitlePrefixes greetings and filler before the question itself
+var conversationTitlePrefixes = []string{
+	"你好", "您好", "hello", "hi", "请问一下", "请问", "我想问一下", "我想问", "想问一下", "想问", "麻烦问一下", "帮我看看", "please",
+}
+
+// HeuristicConversationTitle title of a conversation from its first question: the first line or sentence
+// without greetings, markdown marks and trailing punctuation
+func HeuristicConversationTitle(question string) string {
+	title := strings.TrimSpace(question)
+	if line, _, ok := strings.Cut(title, "\n"); ok {
+		title = line
+	}
+	title = strings.Trim(title, "#>*`_ \t")
+	for trimmed := true; trimmed; {
+		trimmed = false
+		for _, prefix := range conversationTitlePrefixes {
+			if len(title) <= len(prefix) || !strings.EqualFold(title[:len(prefix)], prefix) {
+				continue
+			}
+			rest := title[len(prefix):]
+			// english prefixes are words, hi is not the start of history
+			if next := []rune(rest)[0]; next < unicode.MaxASCII && (unicode.IsLetter(next) || unicode.IsDigit(next)) {
+				continue
+			}
+			title = strings.TrimLeftFunc(rest, func(r rune) bool {
+				return unicode.IsSpace(r) || unicode.IsPunct(r)
+			})
+			trimmed = true
+		}
+	}
+	// the first sentence of questions with context after it
+	if i := strings.IndexAny(title, "。！？!?"); i > 0 {
+		title = title[:i]
+	}
+	title = strings.TrimRightFunc(title, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	})
+	if title == "" {
+		title = strings.TrimSpace(question)
+	}
+	return TruncateConversationTitle(title)
+}
+
+// CleanConversationTitle title generated by the model without reasoning, quotes and trailing punctuation
+func CleanConversationTitle(title string) string {
+	if _, after, ok := strings.Cut(title, "</think>"); ok {
+		title = after
+	}
+	title = strings.TrimSpace(title)
+	if line, _, ok := strings.Cut(title, "\n"); ok {
+		title = line
+	}
+	title = strings.TrimPrefix(title, "标题：")
+	title = strings.Trim(title, " \t\"'“”‘’「」《》*#")
+	title = strings.TrimRightFunc(title, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r) && r != ')' && r != '）'
+	})
+	return TruncateConversationTitle(title)
+}
+
+func TruncateConversationTitle(title string) string {
+	runes := []rune(title)
+	if len(runes) <= ConversationTitleMaxRunes {
+		return title
+	}
+	return strings.TrimRightFunc(string(runes[:ConversationTitleMaxRunes]), unicode.IsSpace) + "…"
+}
@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	CronJobApplyRetention         = "apply_retention"
+	CronJobClusterQuestions       = "cluster_questions"
+	CronJobSendWeeklyGapReports   = "send_weekly_gap_reports"
+	CronJobSendDailyDigests       = "send_daily_digests"
+	CronJobCheckExternalLinks     = "check_external_links"
+	CronJobCheckIndexIntegrity    = "check_index_integrity"
+	CronJobSyncImportSources      = "sync_import_sources"
+	CronJobDetectNearDuplicates   = "detect_near_duplicates"
+	CronJobSendReviewReminders    = "send_review_reminders"
+	CronJobSendTelemetryReport    = "send_telemetry_report"
+	CronJobDeleteExpiredSandboxes = "delete_expired_sandboxes"
+	CronJobSyncLDAPGroups         = "sync_ldap_groups"
+)
+
+type CronRunStatus string
+
+const (
+	CronRunStatusSuccess CronRunStatus = "success"
+	CronRunStatusFailed  CronRunStatus = "failed"
+)
+
+// table: cron_runs
+type CronRun struct {
+	ID         int64         `json:"id" gorm:"primaryKey"`
+	Job        string        `json:"job"`
+	Status     CronRunStatus `json:"status"`
+	Error      string        `json:"error"`
+	StartedAt  time.Time     `json:"started_at"`
+	DurationMS int64         `json:"duration_ms"`
+	// job specific result shown in the job dashboard
+	Result CronRunResult `json:"result,omitempty" gorm:"type:jsonb"`
+}
+
+func (CronRun) TableName() string {
+	return "cron_runs"
+}
+
+type CronRunResult map[string]any
+
+func (r *CronRunResult) Scan(value any) error {
+	if value == nil {
+		*r = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New(fmt.Sprint("invalid cron run result value type:", value))
+	}
+	return json.Unmarshal(bytes, r)
+}
+
+func (r CronRunResult) Value() (driver.Value, error) {
+	if r == nil {
+		return nil, nil
+	}
+	return json.Marshal(r)
+}
+
+type CronRunListReq struct {
+	Job    string        `json:"job" query:"job"`
+	Status CronRunStatus `json:"status" query:"status" validate:"omitempty,oneof=success failed"`
+
+	Pager
+}
+
+type CronJobStatus struct {
+	Job string `json:"job"`
+	// latest run of the job
+	LastRun *CronRun `json:"last_run"`
+	// failed runs since the last successful run
+	ConsecutiveFailures int `json:"consecutive_failures"`
+}
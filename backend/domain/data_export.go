@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// DataExportManifest file of export archives describing the job and the exported tables
+const DataExportManifest = "manifest.json"
+
+type DataExportScope string
+
+const (
+	// everything stored for the kb
+	DataExportScopeKB DataExportScope = "kb"
+	// records of the kb referencing an end user, for right of access requests
+	DataExportScopeEndUser DataExportScope = "end_user"
+)
+
+type DataExportReq struct {
+	KBID  string          `json:"kb_id" validate:"required"`
+	Scope DataExportScope `json:"scope" validate:"required,oneof=kb end_user"`
+	// identifiers of the end user, records matching any of them are exported. at least one is required for end_user scope
+	UserID   string `json:"user_id"`
+	Email    string `json:"email" validate:"omitempty,email"`
+	RemoteIP string `json:"remote_ip" validate:"omitempty,ip"`
+}
+
+// table: data_export_jobs
+type DataExportJob struct {
+	ID       string          `json:"id" gorm:"primaryKey"`
+	KBID     string          `json:"kb_id"`
+	Scope    DataExportScope `json:"scope"`
+	UserID   string          `json:"user_id"`
+	Email    string          `json:"email"`
+	RemoteIP string          `json:"remote_ip"`
+	// admin who requested the export
+	CreatedBy   string              `json:"created_by"`
+	Status      NodeExportJobStatus `json:"status"`
+	RecordCount int                 `json:"record_count"`
+	// archive size in bytes
+	Size  int64  `json:"size"`
+	Key   string `json:"-"`
+	Error string `json:"error"`
+	// signed download url of the archive, set when succeeded
+	URL       string    `json:"url" gorm:"-"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (DataExportJob) TableName() string {
+	return "data_export_jobs"
+}
+
+// ArchiveName file name of the archive when downloaded
+func (j *DataExportJob) ArchiveName() string {
+	return fmt.Sprintf("%s-%s-%s.zip", j.KBID, j.Scope, j.CreatedAt.Format("20060102150405"))
+}
+
+// DataExportTableFile archive path of the rows of a table, one json object per line
+func DataExportTableFile(table string) string {
+	return table + ".jsonl"
+}
+
+// DataExportManifestContent content of DataExportManifest
+type DataExportManifestContent struct {
+	JobID       string          `json:"job_id"`
+	KBID        string          `json:"kb_id"`
+	Scope       DataExportScope `json:"scope"`
+	UserID      string          `json:"user_id,omitempty"`
+	Email       string          `json:"email,omitempty"`
+	RemoteIP    string          `json:"remote_ip,omitempty"`
+	GeneratedAt time.Time       `json:"generated_at"`
+	// row count of each table file
+	Tables map[string]int `json:"tables"`
+}
+
+type DataExportJobListReq struct {
+	KBID string `json:"kb_id" query:"kb_id" validate:"required"`
+
+	Pager
+}
+
+type DataExportJobReq struct {
+	KBID  string `json:"kb_id" query:"kb_id" validate:"required"`
+	JobID string `json:"job_id" query:"job_id" validate:"required"`
+}
+
+// DataExportJobRequest mq message of data export job
+type DataExportJobRequest struct {
+	JobID string `json:"job_id"`
+}
@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+const (
+	DigestItemLimit       = 10
+	DigestTranscriptLimit = 3
+	// questions asked in this period before the digest day are not new
+	DigestNewQuestionLookback = 7 * 24 * time.Hour
+	// messages of example transcripts are truncated to this many runes
+	DigestTranscriptMessageLength = 300
+)
+
+// DigestSettings per kb daily digest of yesterday's conversations
+type DigestSettings struct {
+	Enabled bool `json:"enabled"`
+	// save digest as a private markdown node
+	CreateNode bool   `json:"create_node"`
+	ParentID   string `json:"parent_id"`
+	// push digest to dingtalk/feishu group
+	Webhook    NotifyWebhook `json:"webhook"`
+	ConsoleURL string        `json:"console_url"` // admin console base url for deep links
+}
+
+func (s *DigestSettings) Scan(value any) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New(fmt.Sprint("invalid digest settings value type:", value))
+	}
+	return json.Unmarshal(bytes, s)
+}
+
+func (s DigestSettings) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+type DigestVolume struct {
+	ConversationCount int64 `json:"conversation_count"`
+	MessageCount      int64 `json:"message_count"`
+	VisitorCount      int64 `json:"visitor_count"`
+}
+
+// DigestEscalation conversation the assistant could not answer confidently
+type DigestEscalation struct {
+	ConversationID string    `json:"conversation_id"`
+	Subject        string    `json:"subject"`
+	NoAnswerCount  int64     `json:"no_answer_count"`
+	LowConfidence  int64     `json:"low_confidence_count"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type DigestQuestion struct {
+	Question string `json:"question"`
+	Count    int64  `json:"count"`
+}
+
+type DigestMessage struct {
+	Role    schema.RoleType `json:"role"`
+	Content string          `json:"content"`
+}
+
+type DigestTranscript struct {
+	ConversationID string          `json:"conversation_id"`
+	Subject        string          `json:"subject"`
+	Messages       []DigestMessage `json:"messages"`
+}
+
+type DailyDigest struct {
+	KBID   string    `json:"kb_id"`
+	KBName string    `json:"kb_name"`
+	Date   time.Time `json:"date"`
+
+	Volume DigestVolume `json:"volume"`
+	// volume of the day before, for comparison
+	PrevVolume   DigestVolume        `json:"prev_volume"`
+	Escalations  []*DigestEscalation `json:"escalations"`
+	NewQuestions []*DigestQuestion   `json:"new_questions"`
+	Transcripts  []*DigestTranscript `json:"transcripts"`
+}
+
+type DailyDigestReq struct {
+	KBID string `json:"kb_id" query:"kb_id" validate:"required"`
+	// YYYY-MM-DD, yesterday if empty
+	Date string `json:"date" query:"date"`
+}
+
+type DailyDigestResp struct {
+	Digest   *DailyDigest `json:"digest"`
+	Markdown string       `json:"markdown"`
+}
@@ -1,11 +1,106 @@
 package domain
 
-import "errors"
+import (
+	"context"
+	"errors"
 
-var ErrModelNotConfigured = errors.New("model not configured")
+	"gorm.io/gorm"
+)
 
-var ErrPortHostAlreadyExists = errors.New("port and host already exists")
+// ErrorCode machine readable code of a failed request, returned in responses and logged with the error
+type ErrorCode string
 
-var ErrSyncCaddyConfigFailed = errors.New("failed to sync caddy config")
+const (
+	ErrCodeInvalidRequest     ErrorCode = "INVALID_REQUEST"
+	ErrCodeUnauthorized       ErrorCode = "UNAUTHORIZED"
+	ErrCodeForbidden          ErrorCode = "FORBIDDEN"
+	ErrCodeNotFound           ErrorCode = "NOT_FOUND"
+	ErrCodeKBNotFound         ErrorCode = "KB_NOT_FOUND"
+	ErrCodeNodeNotFound       ErrorCode = "NODE_NOT_FOUND"
+	ErrCodeAppNotFound        ErrorCode = "APP_NOT_FOUND"
+	ErrCodeConflict           ErrorCode = "CONFLICT"
+	ErrCodeRateLimited        ErrorCode = "RATE_LIMITED"
+	ErrCodeQuotaExceeded      ErrorCode = "QUOTA_EXCEEDED"
+	ErrCodeBlocked            ErrorCode = "BLOCKED"
+	ErrCodeReadOnly           ErrorCode = "READ_ONLY"
+	ErrCodeModelNotConfigured ErrorCode = "MODEL_NOT_CONFIGURED"
+	ErrCodeModelTimeout       ErrorCode = "MODEL_TIMEOUT"
+	ErrCodeModelFailed        ErrorCode = "MODEL_FAILED"
+	ErrCodeTimeout            ErrorCode = "TIMEOUT"
+	ErrCodeInternal           ErrorCode = "INTERNAL_ERROR"
+)
 
-var ErrNodeParentIDInIDs = errors.New("node.parent_id in ids, can't delete")
+// CodedError error with a code. a wrapped copy still matches the error it is made from by errors.Is
+type CodedError struct {
+	Code    ErrorCode
+	Message string
+	Err     error
+}
+
+func NewError(code ErrorCode, message string) *CodedError {
+	return &CodedError{Code: code, Message: message}
+}
+
+func (e *CodedError) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *CodedError) Unwrap() error {
+	return e.Err
+}
+
+func (e *CodedError) Is(target error) bool {
+	t, ok := target.(*CodedError)
+	return ok && t.Code == e.Code && t.Message == e.Message
+}
+
+// Wrap copy of the error with its cause
+func (e *CodedError) Wrap(err error) *CodedError {
+	return &CodedError{Code: e.Code, Message: e.Message, Err: err}
+}
+
+// ErrorCodeOf code of the first coded error in the chain, record not found and deadline errors
+// are coded by their kind, others are internal errors
+func ErrorCodeOf(err error) ErrorCode {
+	var coded *CodedError
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &coded):
+		return coded.Code
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrCodeNotFound
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrCodeTimeout
+	}
+	return ErrCodeInternal
+}
+
+var (
+	ErrUnauthorized     = NewError(ErrCodeUnauthorized, "user is not logged in")
+	ErrPermissionDenied = NewError(ErrCodeForbidden, "permission denied")
+	ErrKBNotFound       = NewError(ErrCodeKBNotFound, "knowledge base not found")
+	ErrNodeNotFound     = NewError(ErrCodeNodeNotFound, "node not found")
+	ErrAppNotFound      = NewError(ErrCodeAppNotFound, "app not found")
+)
+
+var ErrModelNotConfigured = NewError(ErrCodeModelNotConfigured, "model not configured")
+
+var ErrModelBudgetExceeded = NewError(ErrCodeQuotaExceeded, "model budget exceeded")
+
+var ErrModelTimeout = NewError(ErrCodeModelTimeout, "model response timed out")
+
+var ErrPortHostAlreadyExists = NewError(ErrCodeConflict, "port and host already exists")
+
+var ErrSyncCaddyConfigFailed = NewError(ErrCodeInternal, "failed to sync caddy config")
+
+var ErrNodeParentIDInIDs = NewError(ErrCodeInvalidRequest, "node.parent_id in ids, can't delete")
+
+var ErrTranscriptEmailRateLimited = NewError(ErrCodeRateLimited, "too many transcript email requests")
+
+var ErrNodeNotPublished = NewError(ErrCodeInvalidRequest, "node has never been published")
+
+var ErrReadOnlyMode = NewError(ErrCodeReadOnly, "system is in read-only mode")
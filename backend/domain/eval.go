@@ -0,0 +1,315 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// MaxEvalSetQuestions max questions of an eval set, a run answers all of them
+	MaxEvalSetQuestions = 200
+	// EvalAnswerTimeout answer and judgement of one question taking longer fail the question
+	EvalAnswerTimeout = 2 * time.Minute
+	// EvalPassScore questions with both faithfulness and relevance at least this pass
+	EvalPassScore = 0.6
+	// EvalTrendLimit max recent runs of the trend
+	EvalTrendLimit = 50
+)
+
+var (
+	ErrEvalSetEmpty          = NewError(ErrCodeInvalidRequest, "eval set has no questions")
+	ErrEvalSetQuestionsLimit = NewError(ErrCodeInvalidRequest, fmt.Sprintf("eval set has at most %d questions", MaxEvalSetQuestions))
+)
+
+// table: eval_sets, golden questions of a kb answered by eval runs
+type EvalSet struct {
+	ID          string    `json:"id" gorm:"primaryKey"`
+	KBID        string    `json:"kb_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (EvalSet) TableName() string {
+	return "eval_sets"
+}
+
+type EvalSetListItem struct {
+	EvalSet
+	QuestionCount int `json:"question_count"`
+	// summary of the latest succeeded run, nil if the set never ran
+	LastRun *EvalRun `json:"last_run" gorm:"-"`
+}
+
+// table: eval_questions
+type EvalQuestion struct {
+	ID    string `json:"id" gorm:"primaryKey"`
+	KBID  string `json:"kb_id"`
+	SetID string `json:"set_id"`
+	// question as a user would ask it
+	Question string `json:"question"`
+	// reference answer the judge compares answers with
+	ExpectedAnswer string    `json:"expected_answer"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+func (EvalQuestion) TableName() string {
+	return "eval_questions"
+}
+
+// table: eval_runs, one run answers every question of the set with the current documents, model and prompt
+type EvalRun struct {
+	ID    string `json:"id" gorm:"primaryKey"`
+	KBID  string `json:"kb_id"`
+	SetID string `json:"set_id"`
+	// app whose prompt template and citation style are used, the built-in prompt if empty
+	AppID string `json:"app_id"`
+	// what changed since the previous run, e.g. new model or prompt
+	Note string `json:"note"`
+	// model and prompt the questions were answered with, filled when the run starts
+	Provider              ModelProvider `json:"provider"`
+	Model                 string        `json:"model"`
+	ModelID               string        `json:"model_id"`
+	PromptTemplateVersion int           `json:"prompt_template_version"`
+	// model judging the answers, the same chat model
+	JudgeModel string              `json:"judge_model"`
+	CreatedBy  string              `json:"created_by"`
+	Status     NodeExportJobStatus `json:"status"`
+	Summary    *EvalRunSummary     `json:"summary" gorm:"type:jsonb"`
+	Error      string              `json:"error"`
+	CreatedAt  time.Time           `json:"created_at"`
+	UpdatedAt  time.Time           `json:"updated_at"`
+}
+
+func (EvalRun) TableName() string {
+	return "eval_runs"
+}
+
+// EvalRunSummary scores of a run averaged over the judged questions
+type EvalRunSummary struct {
+	QuestionCount int `json:"question_count"`
+	// questions not answered or judged because the model failed
+	FailedCount int `json:"failed_count"`
+	// 0-1, answer supported by the retrieved documents
+	AvgFaithfulness float64 `json:"avg_faithfulness"`
+	// 0-1, answer addresses the question and agrees with the expected answer
+	AvgRelevance float64 `json:"avg_relevance"`
+	PassCount    int     `json:"pass_count"`
+	PassRate     float64 `json:"pass_rate"`
+	AvgLatencyMS int64   `json:"avg_latency_ms"`
+}
+
+func (s *EvalRunSummary) Scan(value any) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New(fmt.Sprint("invalid eval run summary value type:", value))
+	}
+	return json.Unmarshal(bytes, s)
+}
+
+func (s EvalRunSummary) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+// Add count a question of the run, failed questions are left out of the averages
+func (s *EvalRunSummary) Add(result *EvalResult) {
+	s.QuestionCount++
+	if result.Error != "" {
+		s.FailedCount++
+		return
+	}
+	s.AvgFaithfulness += result.Faithfulness
+	s.AvgRelevance += result.Relevance
+	s.AvgLatencyMS += result.LatencyMS
+	if result.Passed {
+		s.PassCount++
+	}
+}
+
+// Finish turn sums to averages and rates
+func (s *EvalRunSummary) Finish() {
+	judged := s.QuestionCount - s.FailedCount
+	if judged == 0 {
+		return
+	}
+	s.AvgFaithfulness /= float64(judged)
+	s.AvgRelevance /= float64(judged)
+	s.AvgLatencyMS /= int64(judged)
+	s.PassRate = float64(s.PassCount) / float64(judged)
+}
+
+// table: eval_results, the question and expected answer are copied since the set may change after the run
+type EvalResult struct {
+	ID             string `json:"id" gorm:"primaryKey"`
+	RunID          string `json:"run_id"`
+	QuestionID     string `json:"question_id"`
+	Question       string `json:"question"`
+	ExpectedAnswer string `json:"expected_answer"`
+	Answer         string `json:"answer"`
+	// documents given to the model
+	NodeIDs      StringList `json:"node_ids" gorm:"type:jsonb"`
+	Faithfulness float64    `json:"faithfulness"`
+	Relevance    float64    `json:"relevance"`
+	Passed       bool       `json:"passed"`
+	// reason of the judge for the scores
+	Reason string `json:"reason"`
+	// answer or judgement failed, the question has no scores
+	Error     string    `json:"error"`
+	LatencyMS int64     `json:"latency_ms"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (EvalResult) TableName() string {
+	return "eval_results"
+}
+
+// EvalJudgement scores of an answer by the judge, 1-5 as asked and 0-1 once normalized
+type EvalJudgement struct {
+	Faithfulness float64 `json:"faithfulness"`
+	Relevance    float64 `json:"relevance"`
+	Reason       string  `json:"reason"`
+}
+
+const evalJudgePrompt = `你是一名严格的问答质量评审员。你将看到用户问题、参考答案、检索到的文档以及待评审的回答，请给回答打分：
+
+- faithfulness（忠实度，1-5）：回答中的内容是否都能在检索到的文档中找到依据，编造或与文档矛盾的内容越多分越低；文档不足以回答且回答如实说明时给 5 分
+- relevance（相关性，1-5）：回答是否直接回应了问题，并与参考答案的要点一致，遗漏或错误的要点越多分越低
+
+只输出一个 JSON 对象，不要输出其他内容，格式如下：
+{"faithfulness": 4, "relevance": 5, "reason": "一句话说明扣分原因"}`
+
+// EvalJudgePrompt system prompt of the judge
+func EvalJudgePrompt() string {
+	return evalJudgePrompt
+}
+
+// EvalJudgeMessage user message of the judge with the question, expected answer, documents and answer, the judge
+// is told to judge by the question and documents only if there is no expected answer
+func EvalJudgeMessage(question, expectedAnswer, documents, answer string) string {
+	if expectedAnswer == "" {
+		expectedAnswer = "无，请只根据问题和检索到的文档评审"
+	}
+	return fmt.Sprintf("问题：\n%s\n\n参考答案：\n%s\n\n检索到的文档：\n%s\n\n待评审的回答：\n%s", question, expectedAnswer, documents, answer)
+}
+
+// ParseEvalJudgement judgement of the output of the judge, scores normalized from 1-5 to 0-1
+func ParseEvalJudgement(output string) (*EvalJudgement, error) {
+	if _, after, ok := strings.Cut(output, "</think>"); ok {
+		output = after
+	}
+	start, end := strings.Index(output, "{"), strings.LastIndex(output, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("no judgement in judge output: %q", output)
+	}
+	judgement := &EvalJudgement{}
+	if err := json.Unmarshal([]byte(output[start:end+1]), judgement); err != nil {
+		return nil, fmt.Errorf("parse judgement failed: %w", err)
+	}
+	normalize := func(score float64) float64 {
+		return (min(max(score, 1), 5) - 1) / 4
+	}
+	judgement.Faithfulness = normalize(judgement.Faithfulness)
+	judgement.Relevance = normalize(judgement.Relevance)
+	return judgement, nil
+}
+
+type EvalSetListReq struct {
+	KBID string `json:"kb_id" query:"kb_id" validate:"required"`
+}
+
+type CreateEvalSetReq struct {
+	KBID        string `json:"kb_id" validate:"required"`
+	Name        string `json:"name" validate:"required,max=100"`
+	Description string `json:"description" validate:"max=500"`
+}
+
+type UpdateEvalSetReq struct {
+	ID string `json:"id" validate:"required"`
+	CreateEvalSetReq
+}
+
+type DeleteEvalSetReq struct {
+	KBID string `json:"kb_id" validate:"required"`
+	ID   string `json:"id" validate:"required"`
+}
+
+type EvalQuestionListReq struct {
+	KBID  string `json:"kb_id" query:"kb_id" validate:"required"`
+	SetID string `json:"set_id" query:"set_id" validate:"required"`
+}
+
+type EvalQuestionInput struct {
+	Question       string `json:"question" validate:"required,max=2000"`
+	ExpectedAnswer string `json:"expected_answer" validate:"required,max=10000"`
+}
+
+// CreateEvalQuestionsReq add questions to a set, in batches for imports
+type CreateEvalQuestionsReq struct {
+	KBID      string               `json:"kb_id" validate:"required"`
+	SetID     string               `json:"set_id" validate:"required"`
+	Questions []*EvalQuestionInput `json:"questions" validate:"required,min=1,max=200,dive,required"`
+}
+
+type UpdateEvalQuestionReq struct {
+	KBID string `json:"kb_id" validate:"required"`
+	ID   string `json:"id" validate:"required"`
+	EvalQuestionInput
+}
+
+type DeleteEvalQuestionReq struct {
+	KBID string `json:"kb_id" validate:"required"`
+	ID   string `json:"id" validate:"required"`
+}
+
+// CreateEvalRunReq answer and judge the questions of the set in background
+type CreateEvalRunReq struct {
+	KBID  string `json:"kb_id" validate:"required"`
+	SetID string `json:"set_id" validate:"required"`
+	// app whose prompt template and citation style are evaluated, the built-in prompt if empty
+	AppID string `json:"app_id"`
+	Note  string `json:"note" validate:"max=200"`
+}
+
+type EvalRunListReq struct {
+	KBID string `json:"kb_id" query:"kb_id" validate:"required"`
+	// runs of all sets if empty
+	SetID string `json:"set_id" query:"set_id"`
+
+	Pager
+}
+
+type EvalRunReq struct {
+	KBID  string `json:"kb_id" query:"kb_id" validate:"required"`
+	RunID string `json:"run_id" query:"run_id" validate:"required"`
+}
+
+type EvalResultListReq struct {
+	KBID  string `json:"kb_id" query:"kb_id" validate:"required"`
+	RunID string `json:"run_id" query:"run_id" validate:"required"`
+	// only questions which failed or did not pass
+	FailedOnly bool `json:"failed_only" query:"failed_only"`
+
+	Pager
+}
+
+type EvalTrendReq struct {
+	KBID  string `json:"kb_id" query:"kb_id" validate:"required"`
+	SetID string `json:"set_id" query:"set_id" validate:"required"`
+}
+
+// EvalTrendResp succeeded runs of the set oldest first, with the model and prompt of each to compare changes
+type EvalTrendResp struct {
+	SetID string     `json:"set_id"`
+	Runs  []*EvalRun `json:"runs"`
+}
+
+// EvalRunRequest mq message of eval run
+type EvalRunRequest struct {
+	RunID string `json:"run_id"`
+}
@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// ExternalLinkCheckTTL urls checked within the ttl are not checked again
+	ExternalLinkCheckTTL = 20 * time.Hour
+	// ExternalLinkCheckConcurrency max urls checked at the same time
+	ExternalLinkCheckConcurrency = 8
+	ExternalLinkCheckTimeout     = 10 * time.Second
+)
+
+var externalLinkRegexp = regexp.MustCompile(`https?://[^\s<>"'\[\]{}` + "`" + `]+`)
+
+// NodeExternalLink outbound url in node content
+type NodeExternalLink struct {
+	KBID   string `json:"kb_id"`
+	NodeID string `json:"node_id" gorm:"primaryKey"`
+	URL    string `json:"url" gorm:"primaryKey"`
+}
+
+// ExternalLinkCheck result of the latest check of the url
+type ExternalLinkCheck struct {
+	URL        string    `json:"url" gorm:"primaryKey"`
+	Broken     bool      `json:"broken"`
+	StatusCode int       `json:"status_code"`
+	Error      string    `json:"error"`
+	CheckedAt  time.Time `json:"checked_at"`
+}
+
+// ParseExternalLinks outbound http urls of content, links to the wiki itself are ignored
+func ParseExternalLinks(content, baseURL string) []string {
+	baseHost := ""
+	if u, err := url.Parse(baseURL); err == nil {
+		baseHost = strings.ToLower(u.Host)
+	}
+	seen := make(map[string]bool)
+	links := make([]string, 0)
+	for _, match := range externalLinkRegexp.FindAllString(content, -1) {
+		link := trimExternalLink(match)
+		u, err := url.Parse(link)
+		if err != nil || u.Host == "" {
+			continue
+		}
+		if baseHost != "" && strings.ToLower(u.Host) == baseHost {
+			continue
+		}
+		if seen[link] {
+			continue
+		}
+		seen[link] = true
+		links = append(links, link)
+	}
+	return links
+}
+
+// trimExternalLink drop trailing punctuation and the closing paren of markdown links,
+// parens inside urls like wikipedia pages are kept
+func trimExternalLink(link string) string {
+	for {
+		trimmed := strings.TrimRight(link, ".,;:!?*_~")
+		if strings.HasSuffix(trimmed, ")") && strings.Count(trimmed, ")") > strings.Count(trimmed, "(") {
+			trimmed = strings.TrimSuffix(trimmed, ")")
+		}
+		if trimmed == link {
+			return link
+		}
+		link = trimmed
+	}
+}
+
+type BrokenExternalLinkResp struct {
+	NodeID     string    `json:"node_id"`
+	NodeName   string    `json:"node_name"`
+	URL        string    `json:"url"`
+	StatusCode int       `json:"status_code"`
+	Error      string    `json:"error"`
+	CheckedAt  time.Time `json:"checked_at"`
+}
@@ -0,0 +1,142 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	DefaultFollowUpCount = 3
+	// MaxFollowUpCount chips rendered by the widget, counts saved larger are cut to it
+	MaxFollowUpCount = 3
+	FollowUpTimeout  = 15 * time.Second
+	// FollowUpMaxRunes longer suggestions are dropped
+	FollowUpMaxRunes = 50
+	// FollowUpDocumentLimit retrieved documents given to the model to ground follow-up questions
+	FollowUpDocumentLimit = 5
+	// FollowUpExcerptRunes excerpt of each document given to the model
+	FollowUpExcerptRunes = 300
+)
+
+// document number the model marks a follow-up question with, e.g. "如何重置密码？[2]"
+var followUpDocumentRegex = regexp.MustCompile(`\s*[\[【](\d+)[\]】][\s。.]*$`)
+
+// FollowUpSettings per app follow-up questions suggested after answers
+type FollowUpSettings struct {
+	// generate follow-up questions by the chat model and send them as a suggestions event before done
+	Enabled bool `json:"enabled"`
+	// 3 if not set
+	Count int `json:"count,omitempty" validate:"omitempty,min=1,max=3"`
+}
+
+// CountOrDefault number of follow-up questions of the app
+func (s FollowUpSettings) CountOrDefault() int {
+	if s.Count == 0 {
+		return DefaultFollowUpCount
+	}
+	return min(s.Count, MaxFollowUpCount)
+}
+
+// FollowUpQuestion follow-up question rendered as a chip, with the retrieved document it can be answered from
+type FollowUpQuestion struct {
+	Question string `json:"question"`
+	NodeID   string `json:"node_id"`
+	NodeName string `json:"node_name"`
+}
+
+type FollowUpQuestions []*FollowUpQuestion
+
+func (q *FollowUpQuestions) Scan(value any) error {
+	if value == nil {
+		*q = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New(fmt.Sprint("invalid follow-up questions value type:", value))
+	}
+	return json.Unmarshal(bytes, q)
+}
+
+func (q FollowUpQuestions) Value() (driver.Value, error) {
+	if q == nil {
+		return nil, nil
+	}
+	return json.Marshal([]*FollowUpQuestion(q))
+}
+
+// Texts questions as plain text, for clients of the suggestions field
+func (q FollowUpQuestions) Texts() []string {
+	texts := make([]string, 0, len(q))
+	for _, question := range q {
+		texts = append(texts, question.Question)
+	}
+	return texts
+}
+
+// FollowUpPrompt system prompt of follow-up questions, the question, answer and numbered documents are the user message
+func FollowUpPrompt(count int) string {
+	return fmt.Sprintf("你是问答助手的追问推荐助手。请根据用户的问题、助手的回答和检索到的文档，推荐%d个用户接下来可能会问的问题。"+
+		"每个问题都必须能用其中一篇文档回答，不要推荐文档中没有答案的问题。"+
+		"问题使用与用户问题相同的语言，简短具体，每个不超过30个字，不要重复已经回答的内容。"+
+		"每行输出一个问题，行末用 [序号] 标注能回答它的文档，如：如何重置密码？[2]。不要编号，不要输出任何解释。", count)
+}
+
+// FollowUpMessage user message of follow-up questions with the first documents retrieved for the question,
+// numbered from 1 as referenced by ParseFollowUps
+func FollowUpMessage(question, answer string, rankedNodes []*RankedNodeChunks) string {
+	var documents strings.Builder
+	for i, node := range rankedNodes[:min(len(rankedNodes), FollowUpDocumentLimit)] {
+		excerpt := node.NodeSummary
+		if excerpt == "" && len(node.Chunks) > 0 {
+			excerpt = node.Chunks[0].Content
+		}
+		if runes := []rune(strings.TrimSpace(excerpt)); len(runes) > FollowUpExcerptRunes {
+			excerpt = string(runes[:FollowUpExcerptRunes]) + "..."
+		}
+		fmt.Fprintf(&documents, "[%d] %s\n%s\n\n", i+1, node.NodeName, strings.TrimSpace(excerpt))
+	}
+	return fmt.Sprintf("用户问题：\n%s\n\n助手回答：\n%s\n\n检索到的文档：\n%s", question, answer, strings.TrimSpace(documents.String()))
+}
+
+// ParseFollowUps follow-up questions in the output of the chat model, one per line without numbering and marks,
+// at most count. questions are grounded in the document they are marked with, unmarked questions or questions
+// marked with a document not given to the model are dropped
+func ParseFollowUps(output string, count int, rankedNodes []*RankedNodeChunks) FollowUpQuestions {
+	if _, after, ok := strings.Cut(output, "</think>"); ok {
+		output = after
+	}
+	documents := rankedNodes[:min(len(rankedNodes), FollowUpDocumentLimit)]
+	questions := make(FollowUpQuestions, 0, count)
+	for _, line := range strings.Split(output, "\n") {
+		match := followUpDocumentRegex.FindStringSubmatchIndex(line)
+		if match == nil {
+			continue
+		}
+		index, err := strconv.Atoi(line[match[2]:match[3]])
+		if err != nil || index < 1 || index > len(documents) {
+			continue
+		}
+		line = line[:match[0]]
+		line = strings.TrimLeftFunc(line, func(r rune) bool {
+			return unicode.IsSpace(r) || unicode.IsDigit(r) || strings.ContainsRune("-*•.、)）:：", r)
+		})
+		line = strings.Trim(line, " \t\"'“”‘’*#")
+		if line == "" || len([]rune(line)) > FollowUpMaxRunes {
+			continue
+		}
+		node := documents[index-1]
+		questions = append(questions, &FollowUpQuestion{Question: line, NodeID: node.NodeID, NodeName: node.NodeName})
+		if len(questions) == count {
+			break
+		}
+	}
+	return questions
+}
@@ -0,0 +1,213 @@
+package domain
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// FullTextSnippetRadius runes of context kept around a match in a snippet
+	FullTextSnippetRadius = 40
+	// FullTextSnippetCount max snippets of a result
+	FullTextSnippetCount = 2
+	// FullTextTextSearchConfig text search config of node content, simple works for mixed languages
+	FullTextTextSearchConfig = "simple"
+
+	highlightStart  = "<mark>"
+	highlightStop   = "</mark>"
+	snippetEllipsis = "…"
+)
+
+type FullTextSearchReq struct {
+	Query string `json:"q" query:"q" validate:"required"`
+
+	KBID   string      `json:"-"`
+	Viewer *NodeViewer `json:"-"`
+
+	Pager
+}
+
+// FullTextSearchResult node matched by full-text search, highlights are html escaped with matches wrapped in <mark>
+type FullTextSearchResult struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Emoji     string    `json:"emoji"`
+	URL       string    `json:"url" gorm:"-"`
+	Rank      float64   `json:"rank"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	NameHighlight string   `json:"name_highlight" gorm:"-"`
+	Snippets      []string `json:"snippets" gorm:"-"`
+
+	Content string `json:"-"`
+}
+
+func (r *FullTextSearchResult) GetURL(baseURL string) string {
+	return (&NodeSearchResult{ID: r.ID}).GetURL(baseURL)
+}
+
+// Highlight fill name highlight and content snippets of the result
+func (r *FullTextSearchResult) Highlight(terms []string) {
+	r.NameHighlight = HighlightText(r.Name, terms)
+	r.Snippets = BuildSnippets(r.Content, terms)
+	r.Content = ""
+}
+
+var (
+	markupTagRegex = regexp.MustCompile(`<[^>]*>`)
+	// markdown syntax which is noise in snippets: images, link targets, emphasis, headings and code fences
+	markdownImageRegex  = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)`)
+	markdownLinkRegex   = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	markdownSymbolRegex = regexp.MustCompile("(?m)^#{1,6}\\s+|[*_`~]{1,3}|^>\\s?|^```.*$")
+)
+
+// PlainText text of html or markdown content for snippets
+func PlainText(content string) string {
+	text := markupTagRegex.ReplaceAllString(content, " ")
+	text = markdownImageRegex.ReplaceAllString(text, " ")
+	text = markdownLinkRegex.ReplaceAllString(text, "$1")
+	text = markdownSymbolRegex.ReplaceAllString(text, "")
+	text = html.UnescapeString(text)
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// SearchTerms words of a websearch style query, operators and quotes removed
+func SearchTerms(query string) []string {
+	terms := make([]string, 0)
+	seen := make(map[string]struct{})
+	for _, field := range strings.Fields(query) {
+		// excluded words never match
+		if strings.HasPrefix(field, "-") {
+			continue
+		}
+		field = strings.Trim(field, `"'`)
+		if field == "" || strings.EqualFold(field, "or") {
+			continue
+		}
+		lower := strings.ToLower(field)
+		if _, ok := seen[lower]; ok {
+			continue
+		}
+		seen[lower] = struct{}{}
+		terms = append(terms, field)
+	}
+	return terms
+}
+
+// HighlightText escape text and wrap every match of terms in <mark>
+func HighlightText(text string, terms []string) string {
+	runes := []rune(text)
+	return highlightRange(runes, matchRanges(runes, terms), 0, len(runes))
+}
+
+// BuildSnippets snippets of content around the first matches of terms,
+// the beginning of content when nothing matches
+func BuildSnippets(content string, terms []string) []string {
+	runes := []rune(PlainText(content))
+	if len(runes) == 0 {
+		return []string{}
+	}
+	matches := matchRanges(runes, terms)
+	if len(matches) == 0 {
+		end := min(len(runes), FullTextSnippetRadius*2)
+		snippet := html.EscapeString(string(runes[:end]))
+		if end < len(runes) {
+			snippet += snippetEllipsis
+		}
+		return []string{snippet}
+	}
+	snippets := make([]string, 0, FullTextSnippetCount)
+	windowEnd := -1
+	for _, match := range matches {
+		if len(snippets) >= FullTextSnippetCount {
+			break
+		}
+		if match[0] < windowEnd {
+			continue
+		}
+		start := max(0, match[0]-FullTextSnippetRadius)
+		start = max(start, windowEnd)
+		end := min(len(runes), match[1]+FullTextSnippetRadius)
+		// do not cut a match at the end of the window
+		for _, next := range matches {
+			if next[0] < end && next[1] > end {
+				end = next[1]
+			}
+		}
+		snippet := highlightRange(runes, matches, start, end)
+		if start > 0 {
+			snippet = snippetEllipsis + snippet
+		}
+		if end < len(runes) {
+			snippet += snippetEllipsis
+		}
+		snippets = append(snippets, snippet)
+		windowEnd = end
+	}
+	return snippets
+}
+
+// matchRanges non-overlapping rune ranges of case-insensitive term matches, longest term first at a position
+func matchRanges(runes []rune, terms []string) [][2]int {
+	lowered := make([]rune, len(runes))
+	for i, r := range runes {
+		lowered[i] = unicode.ToLower(r)
+	}
+	needles := make([][]rune, 0, len(terms))
+	for _, term := range terms {
+		needle := []rune(strings.ToLower(term))
+		if len(needle) > 0 {
+			needles = append(needles, needle)
+		}
+	}
+	ranges := make([][2]int, 0)
+	for i := 0; i < len(lowered); {
+		longest := 0
+		for _, needle := range needles {
+			if len(needle) > longest && hasRunePrefix(lowered[i:], needle) {
+				longest = len(needle)
+			}
+		}
+		if longest == 0 {
+			i++
+			continue
+		}
+		ranges = append(ranges, [2]int{i, i + longest})
+		i += longest
+	}
+	return ranges
+}
+
+func hasRunePrefix(s, prefix []rune) bool {
+	if len(prefix) > len(s) {
+		return false
+	}
+	for i := range prefix {
+		if s[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// highlightRange escaped runes[start:end] with matches inside wrapped in <mark>
+func highlightRange(runes []rune, matches [][2]int, start, end int) string {
+	var b strings.Builder
+	pos := start
+	for _, match := range matches {
+		if match[1] <= start || match[0] >= end {
+			continue
+		}
+		matchStart, matchEnd := max(match[0], start), min(match[1], end)
+		b.WriteString(html.EscapeString(string(runes[pos:matchStart])))
+		b.WriteString(highlightStart)
+		b.WriteString(html.EscapeString(string(runes[matchStart:matchEnd])))
+		b.WriteString(highlightStop)
+		pos = matchEnd
+	}
+	b.WriteString(html.EscapeString(string(runes[pos:end])))
+	return b.String()
+}
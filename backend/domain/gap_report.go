@@ -0,0 +1,38 @@
+package domain
+
+import "time"
+
+const (
+	// GapReportPeriod time range covered by each gap report
+	GapReportPeriod = 7 * 24 * time.Hour
+	// GapReportStaleAge documents not updated for this long are reported as stale
+	GapReportStaleAge = 180 * 24 * time.Hour
+	// GapReportItemLimit max items of each section in the gap report
+	GapReportItemLimit = 10
+)
+
+type GapReport struct {
+	KBID                string                `json:"kb_id"`
+	KBName              string                `json:"kb_name"`
+	StartTime           time.Time             `json:"start_time"`
+	EndTime             time.Time             `json:"end_time"`
+	UnansweredQuestions []*UnansweredQuestion `json:"unanswered_questions"`
+	StaleNodes          []*StaleNode          `json:"stale_nodes"`
+	// unresolved tickets imported from a legacy helpdesk, whatever their time
+	HistoricalQuestions []*UnansweredQuestion `json:"historical_questions"`
+}
+
+type UnansweredQuestion struct {
+	Question string `json:"question"`
+	Count    int64  `json:"count"`
+}
+
+type StaleNode struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type SendGapReportReq struct {
+	KBID string `json:"kb_id" validate:"required"`
+}
@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// GeoRegionTagPrefix nodes tagged region:<name> are variants for visitors of the region only
+const GeoRegionTagPrefix = "region:"
+
+// GeoSettings per kb region mapping, visitors get content variants and answers of their region
+type GeoSettings struct {
+	Enabled bool        `json:"enabled"`
+	Regions []GeoRegion `json:"regions"`
+	// region of visitors whose country matches no region, e.g. bots without ip
+	DefaultRegion string `json:"default_region"`
+}
+
+type GeoRegion struct {
+	Name string `json:"name"`
+	// countries resolved by ipdb, e.g. 中国
+	Countries []string `json:"countries"`
+	// extra answer instruction of the region, e.g. quote prices in CNY
+	PromptHint string `json:"prompt_hint"`
+}
+
+func (s *GeoSettings) Scan(value any) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New(fmt.Sprint("invalid geo settings value type:", value))
+	}
+	return json.Unmarshal(bytes, s)
+}
+
+func (s GeoSettings) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+// Resolve region of the visitor country, nil if geo is disabled or no region matches
+func (s GeoSettings) Resolve(country string) *GeoRegion {
+	if !s.Enabled {
+		return nil
+	}
+	country = strings.TrimSpace(country)
+	for i, region := range s.Regions {
+		for _, c := range region.Countries {
+			if country != "" && strings.EqualFold(c, country) {
+				return &s.Regions[i]
+			}
+		}
+	}
+	for i, region := range s.Regions {
+		if region.Name == s.DefaultRegion {
+			return &s.Regions[i]
+		}
+	}
+	return nil
+}
+
+// PromptConstraints extra system prompt of the region
+func (r *GeoRegion) PromptConstraints() string {
+	if r == nil {
+		return ""
+	}
+	if r.PromptHint == "" {
+		return fmt.Sprintf("\n用户所在地区：%s。如文档中不同地区的信息有差异，请以该地区的信息为准。\n", r.Name)
+	}
+	return fmt.Sprintf("\n用户所在地区：%s。%s\n", r.Name, r.PromptHint)
+}
+
+// FilterNodes drop variants of other regions, variants of the region are moved first.
+// nodes without region tags apply to every region
+func (r *GeoRegion) FilterNodes(nodes []*RankedNodeChunks) []*RankedNodeChunks {
+	if r == nil {
+		return nodes
+	}
+	matched := make([]*RankedNodeChunks, 0, len(nodes))
+	common := make([]*RankedNodeChunks, 0, len(nodes))
+	for _, node := range nodes {
+		regions := nodeRegions(node.Tags)
+		switch {
+		case len(regions) == 0:
+			common = append(common, node)
+		case regions[strings.ToLower(r.Name)]:
+			matched = append(matched, node)
+		}
+	}
+	return append(matched, common...)
+}
+
+func nodeRegions(tags []string) map[string]bool {
+	regions := make(map[string]bool)
+	for _, tag := range tags {
+		if name, ok := strings.CutPrefix(strings.ToLower(strings.TrimSpace(tag)), GeoRegionTagPrefix); ok && name != "" {
+			regions[name] = true
+		}
+	}
+	return regions
+}
@@ -0,0 +1,289 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+var ErrGlossaryTermExists = NewError(ErrCodeConflict, "glossary term already exists")
+
+// GlossaryPromptLimit max terms mentioned by the question and documents listed in the prompt
+const GlossaryPromptLimit = 30
+
+// terms are not linked inside these elements of html content
+var glossarySkipTags = map[string]bool{
+	"a": true, "abbr": true, "code": true, "pre": true, "script": true, "style": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+}
+
+// inline code, links, images, raw html tags and bare urls of a markdown line, terms are not linked inside them
+var glossaryMarkdownSkipRegex = regexp.MustCompile("`[^`]*`|!?\\[[^\\]]*\\]\\([^)]*\\)|<[^>]*>|https?://[^\\s)]+")
+
+type GlossaryAliases []string
+
+func (s *GlossaryAliases) Scan(value any) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New(fmt.Sprint("invalid glossary aliases value type:", value))
+	}
+	return json.Unmarshal(bytes, s)
+}
+
+func (s GlossaryAliases) Value() (driver.Value, error) {
+	if s == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]string(s))
+}
+
+// table: glossary_terms
+type GlossaryTerm struct {
+	ID   string `json:"id" gorm:"primaryKey"`
+	KBID string `json:"kb_id"`
+	// standard term, matched case sensitively like its aliases
+	Term    string          `json:"term"`
+	Aliases GlossaryAliases `json:"aliases" gorm:"type:jsonb"`
+	// definition shown on hover of linked terms and given to the model
+	Definition string `json:"definition"`
+	// document explaining the term, linked terms point to it if set
+	NodeID    string    `json:"node_id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (GlossaryTerm) TableName() string {
+	return "glossary_terms"
+}
+
+type CreateGlossaryTermReq struct {
+	KBID       string   `json:"kb_id" validate:"required"`
+	Term       string   `json:"term" validate:"required,max=100"`
+	Aliases    []string `json:"aliases" validate:"max=20,dive,required,max=100"`
+	Definition string   `json:"definition" validate:"required,max=500"`
+	NodeID     string   `json:"node_id"`
+}
+
+type UpdateGlossaryTermReq struct {
+	ID string `json:"id" validate:"required"`
+	CreateGlossaryTermReq
+}
+
+type DeleteGlossaryTermReq struct {
+	KBID string `json:"kb_id" validate:"required"`
+	ID   string `json:"id" validate:"required"`
+}
+
+// GlossaryLinker matches terms and aliases of a glossary in text, longer names first
+type GlossaryLinker struct {
+	regex *regexp.Regexp
+	terms map[string]*GlossaryTerm
+}
+
+func NewGlossaryLinker(terms []*GlossaryTerm) *GlossaryLinker {
+	l := &GlossaryLinker{terms: make(map[string]*GlossaryTerm)}
+	names := make([]string, 0, len(terms))
+	for _, term := range terms {
+		for _, name := range append([]string{term.Term}, term.Aliases...) {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if _, ok := l.terms[name]; !ok {
+				l.terms[name] = term
+				names = append(names, name)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return l
+	}
+	// alternation prefers the first name matching at a position
+	sort.SliceStable(names, func(i, j int) bool {
+		return len(names[i]) > len(names[j])
+	})
+	quoted := make([]string, 0, len(names))
+	for _, name := range names {
+		quoted = append(quoted, regexp.QuoteMeta(name))
+	}
+	l.regex = regexp.MustCompile(strings.Join(quoted, "|"))
+	return l
+}
+
+// Mentioned terms mentioned in the text, in order of first mention
+func (l *GlossaryLinker) Mentioned(text string) []*GlossaryTerm {
+	mentioned := make([]*GlossaryTerm, 0)
+	seen := make(map[string]bool)
+	l.each(text, func(match string) string {
+		term := l.terms[match]
+		if !seen[term.ID] {
+			seen[term.ID] = true
+			mentioned = append(mentioned, term)
+		}
+		return match
+	})
+	return mentioned
+}
+
+// LinkContent link the first mention of each term in document content, html content is detected by its leading tag
+func (l *GlossaryLinker) LinkContent(content string) string {
+	if l.regex == nil {
+		return content
+	}
+	if strings.HasPrefix(strings.TrimSpace(content), "<") {
+		return l.linkHTML(content)
+	}
+	return l.linkMarkdown(content)
+}
+
+// linkHTML wrap mentions in text nodes with links or abbr elements, content is kept as is if it fails to parse
+func (l *GlossaryLinker) linkHTML(content string) string {
+	linked := make(map[string]bool)
+	var b strings.Builder
+	tokenizer := html.NewTokenizer(strings.NewReader(content))
+	skip := 0
+	for {
+		tt := tokenizer.Next()
+		switch tt {
+		case html.ErrorToken:
+			if tokenizer.Err() != io.EOF {
+				return content
+			}
+			return b.String()
+		case html.StartTagToken, html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			if glossarySkipTags[string(name)] {
+				if tt == html.StartTagToken {
+					skip++
+				} else if skip > 0 {
+					skip--
+				}
+			}
+		case html.TextToken:
+			if skip == 0 {
+				b.WriteString(l.link(string(tokenizer.Raw()), linked, glossaryHTMLLink))
+				continue
+			}
+		}
+		b.Write(tokenizer.Raw())
+	}
+}
+
+// linkMarkdown link mentions outside code blocks, headings, links and inline html
+func (l *GlossaryLinker) linkMarkdown(content string) string {
+	linked := make(map[string]bool)
+	lines := strings.Split(content, "\n")
+	fenced := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fenced = !fenced
+			continue
+		}
+		if fenced || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t") {
+			continue
+		}
+		var b strings.Builder
+		last := 0
+		for _, loc := range glossaryMarkdownSkipRegex.FindAllStringIndex(line, -1) {
+			b.WriteString(l.link(line[last:loc[0]], linked, glossaryMarkdownLink))
+			b.WriteString(line[loc[0]:loc[1]])
+			last = loc[1]
+		}
+		b.WriteString(l.link(line[last:], linked, glossaryMarkdownLink))
+		lines[i] = b.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// link replace the first mention of each term not linked yet
+func (l *GlossaryLinker) link(text string, linked map[string]bool, format func(string, *GlossaryTerm) string) string {
+	return l.each(text, func(match string) string {
+		term := l.terms[match]
+		if linked[term.ID] {
+			return match
+		}
+		linked[term.ID] = true
+		return format(match, term)
+	})
+}
+
+// each replace mentions in the text, latin names only match as whole words
+func (l *GlossaryLinker) each(text string, replace func(string) string) string {
+	if l.regex == nil {
+		return text
+	}
+	var b strings.Builder
+	last := 0
+	for _, loc := range l.regex.FindAllStringIndex(text, -1) {
+		if !glossaryWordBoundary(text, loc[0], loc[1]) {
+			continue
+		}
+		b.WriteString(text[last:loc[0]])
+		b.WriteString(replace(text[loc[0]:loc[1]]))
+		last = loc[1]
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+func glossaryWordBoundary(text string, start, end int) bool {
+	first, _ := utf8.DecodeRuneInString(text[start:])
+	if before, _ := utf8.DecodeLastRuneInString(text[:start]); start > 0 && isASCIIWord(first) && isASCIIWord(before) {
+		return false
+	}
+	lastRune, _ := utf8.DecodeLastRuneInString(text[:end])
+	if after, _ := utf8.DecodeRuneInString(text[end:]); end < len(text) && isASCIIWord(lastRune) && isASCIIWord(after) {
+		return false
+	}
+	return true
+}
+
+func isASCIIWord(r rune) bool {
+	return r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+}
+
+func glossaryHTMLLink(match string, term *GlossaryTerm) string {
+	title := html.EscapeString(term.Definition)
+	if term.NodeID != "" {
+		return fmt.Sprintf(`<a class="glossary-term" href="/node/%s" title="%s">%s</a>`, term.NodeID, title, match)
+	}
+	return fmt.Sprintf(`<abbr class="glossary-term" title="%s">%s</abbr>`, title, match)
+}
+
+func glossaryMarkdownLink(match string, term *GlossaryTerm) string {
+	if term.NodeID != "" {
+		title := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", " ").Replace(term.Definition)
+		return fmt.Sprintf(`[%s](/node/%s "%s")`, match, term.NodeID, title)
+	}
+	return glossaryHTMLLink(match, term)
+}
+
+// GlossaryPrompt extra system prompt with definitions of the terms mentioned in the text, so answers use consistent terminology
+func GlossaryPrompt(terms []*GlossaryTerm) string {
+	if len(terms) == 0 {
+		return ""
+	}
+	if len(terms) > GlossaryPromptLimit {
+		terms = terms[:GlossaryPromptLimit]
+	}
+	var sb strings.Builder
+	sb.WriteString("\n术语表（回答时请统一使用以下标准术语，不要使用其别名）：\n")
+	for _, term := range terms {
+		sb.WriteString("- " + term.Term)
+		if len(term.Aliases) > 0 {
+			fmt.Fprintf(&sb, "（别名：%s）", strings.Join(term.Aliases, "、"))
+		}
+		fmt.Fprintf(&sb, "：%s\n", strings.ReplaceAll(term.Definition, "\n", " "))
+	}
+	return sb.String()
+}
@@ -0,0 +1,277 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	DefaultGuardrailBlockedReply = "抱歉，该问题无法回答，请换个问题试试。"
+	// DefaultGuardrailMinTopicScore questions whose best retrieved chunk is less similar are off topic
+	DefaultGuardrailMinTopicScore = 0.3
+	// GuardrailDetailRunes matched text kept on guardrail events
+	GuardrailDetailRunes = 200
+)
+
+// GuardrailCheckType check of the guardrail pipeline
+type GuardrailCheckType string
+
+const (
+	// profanity and blocklist words in the question or the answer
+	GuardrailCheckProfanity GuardrailCheckType = "profanity"
+	// questions trying to override the instructions of the model
+	GuardrailCheckInjection GuardrailCheckType = "injection"
+	// questions not similar to any document of the kb
+	GuardrailCheckOffTopic GuardrailCheckType = "off_topic"
+)
+
+// GuardrailStage when a check runs
+type GuardrailStage string
+
+const (
+	// on the question before retrieval, or after retrieval for the off-topic check, before the model is called
+	GuardrailStageQuestion GuardrailStage = "question"
+	// on the answer before it is sent, answers are buffered if the answer check blocks
+	GuardrailStageAnswer GuardrailStage = "answer"
+)
+
+// GuardrailAction what happens when a check matches, the check is off if empty
+type GuardrailAction string
+
+const (
+	// replace the answer by the blocked reply
+	GuardrailActionBlock GuardrailAction = "block"
+	// answer and send a guardrail event so the client can show a notice
+	GuardrailActionWarn GuardrailAction = "warn"
+	// answer and only record the event
+	GuardrailActionLog GuardrailAction = "log"
+)
+
+// GuardrailSettings per app guardrail policy, every match is recorded as a guardrail event
+type GuardrailSettings struct {
+	// builtin profanity list and the blocklist, on questions and answers
+	Profanity GuardrailAction `json:"profanity" validate:"omitempty,oneof=block warn log"`
+	// prompt injection heuristics, on questions
+	Injection GuardrailAction `json:"injection" validate:"omitempty,oneof=block warn log"`
+	// similarity of the question to the documents of the kb, on questions after retrieval
+	OffTopic GuardrailAction `json:"off_topic" validate:"omitempty,oneof=block warn log"`
+	// words matched by the profanity check besides the builtin list, case insensitive
+	Blocklist []string `json:"blocklist,omitempty" validate:"max=500"`
+	// min vector similarity of the best retrieved chunk, 0.3 if not set
+	MinTopicScore float64 `json:"min_topic_score" validate:"min=0,max=1"`
+	BlockedReply  string  `json:"blocked_reply,omitempty"`
+}
+
+func (s GuardrailSettings) BlockedReplyOrDefault() string {
+	if s.BlockedReply != "" {
+		return s.BlockedReply
+	}
+	return DefaultGuardrailBlockedReply
+}
+
+func (s GuardrailSettings) MinTopicScoreOrDefault() float64 {
+	if s.MinTopicScore <= 0 {
+		return DefaultGuardrailMinTopicScore
+	}
+	return s.MinTopicScore
+}
+
+// BuffersAnswers answers are checked before anything is streamed
+func (s GuardrailSettings) BuffersAnswers() bool {
+	return s.Profanity == GuardrailActionBlock
+}
+
+// GuardrailViolation a matched check
+type GuardrailViolation struct {
+	Check  GuardrailCheckType `json:"check"`
+	Stage  GuardrailStage     `json:"stage"`
+	Action GuardrailAction    `json:"action"`
+	// matched word, pattern or score
+	Detail string `json:"detail"`
+}
+
+// GuardrailViolations matched checks of a stage
+type GuardrailViolations []*GuardrailViolation
+
+// Blocked any of the checks blocks
+func (v GuardrailViolations) Blocked() bool {
+	for _, violation := range v {
+		if violation.Action == GuardrailActionBlock {
+			return true
+		}
+	}
+	return false
+}
+
+// Warnings checks the client is notified of
+func (v GuardrailViolations) Warnings() GuardrailViolations {
+	warnings := make(GuardrailViolations, 0)
+	for _, violation := range v {
+		if violation.Action == GuardrailActionWarn {
+			warnings = append(warnings, violation)
+		}
+	}
+	return warnings
+}
+
+// CheckQuestion profanity and injection checks of the question
+func (s GuardrailSettings) CheckQuestion(question string) GuardrailViolations {
+	violations := make(GuardrailViolations, 0)
+	if s.Profanity != "" {
+		if word := MatchProfanity(question, s.Blocklist); word != "" {
+			violations = append(violations, &GuardrailViolation{Check: GuardrailCheckProfanity, Stage: GuardrailStageQuestion, Action: s.Profanity, Detail: word})
+		}
+	}
+	if s.Injection != "" {
+		if pattern := MatchPromptInjection(question); pattern != "" {
+			violations = append(violations, &GuardrailViolation{Check: GuardrailCheckInjection, Stage: GuardrailStageQuestion, Action: s.Injection, Detail: pattern})
+		}
+	}
+	return violations
+}
+
+// CheckTopic off-topic check of the question by the best vector similarity of the documents retrieved for it
+func (s GuardrailSettings) CheckTopic(nodes []*RankedNodeChunks) GuardrailViolations {
+	violations := make(GuardrailViolations, 0)
+	if s.OffTopic == "" {
+		return violations
+	}
+	score := 0.0
+	for _, node := range nodes {
+		for _, chunk := range node.Chunks {
+			score = max(score, chunk.Similarity)
+		}
+	}
+	if score < s.MinTopicScoreOrDefault() {
+		violations = append(violations, &GuardrailViolation{Check: GuardrailCheckOffTopic, Stage: GuardrailStageQuestion, Action: s.OffTopic, Detail: fmt.Sprintf("%.2f", score)})
+	}
+	return violations
+}
+
+// CheckAnswer profanity check of the answer
+func (s GuardrailSettings) CheckAnswer(answer string) GuardrailViolations {
+	violations := make(GuardrailViolations, 0)
+	if s.Profanity == "" {
+		return violations
+	}
+	if _, after, ok := strings.Cut(answer, "</think>"); ok {
+		answer = after
+	}
+	if word := MatchProfanity(answer, s.Blocklist); word != "" {
+		violations = append(violations, &GuardrailViolation{Check: GuardrailCheckProfanity, Stage: GuardrailStageAnswer, Action: s.Profanity, Detail: word})
+	}
+	return violations
+}
+
+// builtinProfanity common insults, latin words match whole words only so "class" does not match "ass"
+var builtinProfanity = []string{
+	"傻逼", "煞笔", "他妈的", "操你", "草泥马", "狗日的", "王八蛋", "贱人", "婊子", "滚蛋",
+	"fuck", "fucking", "shit", "bitch", "asshole", "bastard", "cunt", "motherfucker",
+}
+
+// MatchProfanity first builtin or blocklist word in the text, empty if none
+func MatchProfanity(text string, blocklist []string) string {
+	lower := strings.ToLower(text)
+	for _, words := range [][]string{blocklist, builtinProfanity} {
+		for _, word := range words {
+			word = strings.ToLower(strings.TrimSpace(word))
+			if word != "" && containsWord(lower, word) {
+				return word
+			}
+		}
+	}
+	return ""
+}
+
+// containsWord word in the text, words of latin letters and digits must not be part of a longer word
+func containsWord(text, word string) bool {
+	if !isLatinWord(word) {
+		return strings.Contains(text, word)
+	}
+	for start := 0; ; {
+		i := strings.Index(text[start:], word)
+		if i < 0 {
+			return false
+		}
+		i += start
+		end := i + len(word)
+		before, _ := utf8.DecodeLastRuneInString(text[:i])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if !isLatinLetterOrDigit(before) && !isLatinLetterOrDigit(after) {
+			return true
+		}
+		start = i + 1
+	}
+}
+
+func isLatinWord(word string) bool {
+	for _, r := range word {
+		if !isLatinLetterOrDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isLatinLetterOrDigit(r rune) bool {
+	return r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// promptInjectionPatterns phrases of questions overriding the instructions of the model or asking for them,
+// named for the events
+var promptInjectionPatterns = []struct {
+	Name    string
+	Pattern *regexp.Regexp
+}{
+	{"ignore_instructions", regexp.MustCompile(`(?i)\b(ignore|disregard|forget|override)\b.{0,30}\b(previous|prior|above|earlier|all|your|system)\b.{0,20}\b(instructions?|prompts?|rules?|directions?)`)},
+	{"ignore_instructions", regexp.MustCompile(`(忽略|无视|忘记|忘掉|不要理会|覆盖).{0,15}(之前|以上|上面|前面|先前|所有|系统|原有).{0,10}(指令|指示|提示词|提示|规则|要求|设定)`)},
+	{"reveal_prompt", regexp.MustCompile(`(?i)\b(reveal|show|print|repeat|output|tell me)\b.{0,30}\b(system prompt|your (instructions|prompt|rules))`)},
+	{"reveal_prompt", regexp.MustCompile(`(输出|显示|打印|重复|告诉我|泄露|透露).{0,10}(你的|系统)?(系统提示词|提示词|系统指令|初始指令|prompt)`)},
+	{"role_override", regexp.MustCompile(`(?i)\b(you are now|act as|pretend to be|from now on you)\b.{0,40}\b(unrestricted|without (any )?(rules|restrictions|limits)|jailbroken|dan)\b`)},
+	{"role_override", regexp.MustCompile(`(从现在开始|现在起|假装|扮演).{0,20}(没有任何?限制|不受.{0,6}限制|越狱|开发者模式)`)},
+	{"jailbreak", regexp.MustCompile(`(?i)\b(dan mode|do anything now|jailbreak (mode|prompt))\b`)},
+	{"chat_markup", regexp.MustCompile(`(?i)<\|im_start\|>|<\|im_end\|>|\[/?INST\]|<</?SYS>>|^\s*#{2,}\s*(system|assistant)\s*:?`)},
+}
+
+// MatchPromptInjection name of the first injection pattern matching the question, empty if none
+func MatchPromptInjection(question string) string {
+	for _, p := range promptInjectionPatterns {
+		if p.Pattern.MatchString(question) {
+			return p.Name
+		}
+	}
+	return ""
+}
+
+// table: guardrail_events, every match of a guardrail check whatever its action
+type GuardrailEvent struct {
+	ID             string             `json:"id" gorm:"primaryKey"`
+	KBID           string             `json:"kb_id"`
+	AppID          string             `json:"app_id"`
+	ConversationID string             `json:"conversation_id"`
+	Check          GuardrailCheckType `json:"check" gorm:"column:check_type"`
+	Stage          GuardrailStage     `json:"stage"`
+	Action         GuardrailAction    `json:"action"`
+	Detail         string             `json:"detail"`
+	// question of the event, cut to GuardrailDetailRunes
+	Question  string    `json:"question"`
+	RemoteIP  string    `json:"remote_ip"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (GuardrailEvent) TableName() string {
+	return "guardrail_events"
+}
+
+type GuardrailEventListReq struct {
+	KBID   string             `json:"kb_id" query:"kb_id" validate:"required"`
+	AppID  string             `json:"app_id" query:"app_id"`
+	Check  GuardrailCheckType `json:"check" query:"check" validate:"omitempty,oneof=profanity injection off_topic"`
+	Action GuardrailAction    `json:"action" query:"action" validate:"omitempty,oneof=block warn log"`
+
+	Pager
+}
@@ -0,0 +1,178 @@
+package domain
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+const (
+	// RRFK rank constant of reciprocal rank fusion, damps the weight of top ranks
+	RRFK = 60
+	// HybridKeywordLimit max nodes retrieved by keyword search
+	HybridKeywordLimit = 10
+	// HybridMaxNodes max nodes kept after fusion
+	HybridMaxNodes = 10
+	// KeywordExcerptRadius runes of content kept around the first keyword match
+	KeywordExcerptRadius = 300
+	// KeywordChunkIDPrefix id prefix of chunks built from keyword matches
+	KeywordChunkIDPrefix = "keyword:"
+)
+
+// RetrievalSettings per kb retrieval pipeline
+type RetrievalSettings struct {
+	// merge keyword (tsvector) matches with vector results by reciprocal rank fusion,
+	// so exact error codes and SKUs missed by embeddings are found
+	Hybrid bool `json:"hybrid"`
+	// weight of keyword ranks relative to vector ranks in fusion, 1 when not set
+	KeywordWeight float64 `json:"keyword_weight" validate:"min=0,max=10"`
+	// rerank retrieved chunks by the rerank model of the kb before they are given to the model,
+	// scores before and after are saved on answers
+	Rerank bool `json:"rerank"`
+	// nodes kept after reranking, all if 0
+	RerankTopN int `json:"rerank_top_n" validate:"min=0,max=20"`
+	// chunks the reranker scores below it are dropped, 0-1
+	RerankMinScore float64 `json:"rerank_min_score" validate:"min=0,max=1"`
+	// condense follow-up questions with the conversation history into a standalone query before retrieval,
+	// by the rewrite model of the kb
+	QueryRewrite bool `json:"query_rewrite"`
+}
+
+func (s RetrievalSettings) EffectiveKeywordWeight() float64 {
+	if s.KeywordWeight <= 0 {
+		return 1
+	}
+	return s.KeywordWeight
+}
+
+// KeywordNodeMatch node release found by keyword search, rank is higher for better matches
+type KeywordNodeMatch struct {
+	ID      string
+	NodeID  string
+	DocID   string
+	Name    string
+	Meta    NodeMeta `gorm:"type:jsonb"`
+	Content string
+	Rank    float64
+}
+
+// ToRankedNodeChunks node with an excerpt of its content around the first match as the only chunk
+func (m *KeywordNodeMatch) ToRankedNodeChunks(query *KeywordQuery) *RankedNodeChunks {
+	return &RankedNodeChunks{
+		NodeID:      m.NodeID,
+		NodeName:    m.Name,
+		NodeSummary: m.Meta.Summary,
+		Tags:        m.Meta.Tags,
+		Chunks: []*NodeContentChunk{{
+			ID:      KeywordChunkIDPrefix + m.ID,
+			DocID:   m.DocID,
+			Name:    m.Name,
+			Content: KeywordExcerpt(m.Content, query.Terms()),
+		}},
+	}
+}
+
+// KeywordQuery keyword part of a question, words are matched by tsvector and identifiers as exact substrings
+type KeywordQuery struct {
+	Words []string `json:"words"`
+	// tokens with digits or inner punctuation, e.g. error codes, SKUs and versions
+	Identifiers []string `json:"identifiers"`
+}
+
+func (q *KeywordQuery) Empty() bool {
+	return len(q.Words) == 0 && len(q.Identifiers) == 0
+}
+
+func (q *KeywordQuery) Terms() []string {
+	return append(slices.Clone(q.Identifiers), q.Words...)
+}
+
+// TSQuery words joined by OR for to_tsquery, words only contain letters and digits
+func (q *KeywordQuery) TSQuery() string {
+	return strings.Join(q.Words, " | ")
+}
+
+var (
+	keywordTokenRegex = regexp.MustCompile(`[\p{L}\p{N}][\p{L}\p{N}._\-/#]*[\p{L}\p{N}]|[\p{L}\p{N}]`)
+	// common english words which match nearly every document
+	keywordStopWords = map[string]bool{
+		"the": true, "and": true, "for": true, "how": true, "what": true, "why": true, "when": true,
+		"where": true, "who": true, "which": true, "can": true, "does": true, "did": true, "not": true,
+		"with": true, "this": true, "that": true, "from": true, "are": true, "was": true, "you": true,
+		"use": true, "get": true, "have": true, "has": true, "about": true, "into": true, "there": true,
+	}
+)
+
+// ParseKeywordQuery split question into words and identifiers, han text is left to vector search
+func ParseKeywordQuery(question string) *KeywordQuery {
+	query := &KeywordQuery{Words: []string{}, Identifiers: []string{}}
+	seen := make(map[string]bool)
+	for _, token := range keywordTokenRegex.FindAllString(question, -1) {
+		if strings.ContainsFunc(token, func(r rune) bool { return unicode.Is(unicode.Han, r) }) {
+			continue
+		}
+		lower := strings.ToLower(token)
+		if seen[lower] {
+			continue
+		}
+		seen[lower] = true
+		isIdentifier := strings.ContainsFunc(token, unicode.IsDigit) || strings.ContainsAny(token, "._-/#")
+		switch {
+		case isIdentifier && len(token) >= 2:
+			query.Identifiers = append(query.Identifiers, token)
+		case !isIdentifier && len(token) >= 3 && !keywordStopWords[lower]:
+			query.Words = append(query.Words, lower)
+		}
+	}
+	return query
+}
+
+// KeywordExcerpt plain text of content around the first match of terms, the beginning when nothing matches
+func KeywordExcerpt(content string, terms []string) string {
+	runes := []rune(PlainText(content))
+	start := 0
+	if matches := matchRanges(runes, terms); len(matches) > 0 {
+		start = max(0, matches[0][0]-KeywordExcerptRadius)
+	}
+	end := min(len(runes), start+KeywordExcerptRadius*2)
+	return string(runes[start:end])
+}
+
+// FuseRankedNodes merge vector and keyword results by reciprocal rank fusion,
+// keyword excerpts are appended to chunks of nodes found by both
+func FuseRankedNodes(vector, keyword []*RankedNodeChunks, keywordWeight float64) []*RankedNodeChunks {
+	scores := make(map[string]float64)
+	nodes := make(map[string]*RankedNodeChunks)
+	order := make([]string, 0, len(vector)+len(keyword))
+	for i, node := range vector {
+		if _, ok := nodes[node.NodeID]; !ok {
+			nodes[node.NodeID] = node
+			order = append(order, node.NodeID)
+		}
+		scores[node.NodeID] += 1.0 / float64(RRFK+i+1)
+	}
+	for i, node := range keyword {
+		if existing, ok := nodes[node.NodeID]; ok {
+			existing.Chunks = append(existing.Chunks, node.Chunks...)
+		} else {
+			nodes[node.NodeID] = node
+			order = append(order, node.NodeID)
+		}
+		scores[node.NodeID] += keywordWeight / float64(RRFK+i+1)
+	}
+	slices.SortStableFunc(order, func(a, b string) int {
+		switch {
+		case scores[a] > scores[b]:
+			return -1
+		case scores[a] < scores[b]:
+			return 1
+		}
+		return 0
+	})
+	fused := make([]*RankedNodeChunks, 0, min(len(order), HybridMaxNodes))
+	for _, nodeID := range order[:min(len(order), HybridMaxNodes)] {
+		fused = append(fused, nodes[nodeID])
+	}
+	return fused
+}
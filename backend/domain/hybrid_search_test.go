@@ -0,0 +1,151 @@
+package domain
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func rankedNodes(ids ...string) []*RankedNodeChunks {
+	nodes := make([]*RankedNodeChunks, 0, len(ids))
+	for _, id := range ids {
+		nodes = append(nodes, &RankedNodeChunks{NodeID: id, Chunks: []*NodeContentChunk{{ID: id + "-chunk"}}})
+	}
+	return nodes
+}
+
+func fusedIDs(nodes []*RankedNodeChunks) []string {
+	ids := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		ids = append(ids, node.NodeID)
+	}
+	return ids
+}
+
+func TestFuseRankedNodes(t *testing.T) {
+	many := make([]string, 0, 15)
+	for i := range 15 {
+		many = append(many, fmt.Sprintf("n%02d", i))
+	}
+	tests := []struct {
+		name    string
+		vector  []string
+		keyword []string
+		weight  float64
+		want    []string
+	}{
+		{name: "empty", want: []string{}},
+		{name: "vector only keeps its order", vector: []string{"a", "b", "c"}, weight: 1, want: []string{"a", "b", "c"}},
+		{name: "keyword only keeps its order", keyword: []string{"a", "b", "c"}, weight: 1, want: []string{"a", "b", "c"}},
+		{name: "node of both lists ranks first", vector: []string{"a", "b", "c"}, keyword: []string{"c", "d"}, weight: 1, want: []string{"c", "a", "b", "d"}},
+		// 1/63 + 0.1/61 of c beats 1/61 of a with k 60, a small k would keep a first
+		{name: "k 60 damps the top rank", vector: []string{"a", "b", "c"}, keyword: []string{"c"}, weight: 0.1, want: []string{"c", "a", "b"}},
+		{name: "ties keep vector before keyword", vector: []string{"a", "b"}, keyword: []string{"c", "d"}, weight: 1, want: []string{"a", "c", "b", "d"}},
+		{name: "keyword weight lifts keyword ranks", vector: []string{"a", "b"}, keyword: []string{"c", "d"}, weight: 2, want: []string{"c", "d", "a", "b"}},
+		{name: "zero keyword weight keeps vector ranks first", vector: []string{"a", "b"}, keyword: []string{"c", "a"}, weight: 0, want: []string{"a", "b", "c"}},
+		{name: "limited to max nodes", vector: many, weight: 1, want: many[:HybridMaxNodes]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fusedIDs(FuseRankedNodes(rankedNodes(tt.vector...), rankedNodes(tt.keyword...), tt.weight))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FuseRankedNodes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFuseRankedNodesDedup(t *testing.T) {
+	vector := rankedNodes("a", "b")
+	keyword := []*RankedNodeChunks{
+		{NodeID: "b", NodeName: "keyword b", Chunks: []*NodeContentChunk{{ID: KeywordChunkIDPrefix + "b"}}},
+		{NodeID: "c", Chunks: []*NodeContentChunk{{ID: KeywordChunkIDPrefix + "c"}}},
+	}
+	fused := FuseRankedNodes(vector, keyword, 1)
+	if got, want := fusedIDs(fused), []string{"b", "a", "c"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("FuseRankedNodes() = %v, want %v", got, want)
+	}
+	// the vector node is kept with the keyword excerpt appended to its chunks
+	if fused[0] != vector[1] {
+		t.Error("node found by both lists is not the vector node")
+	}
+	chunkIDs := make([]string, 0, len(fused[0].Chunks))
+	for _, chunk := range fused[0].Chunks {
+		chunkIDs = append(chunkIDs, chunk.ID)
+	}
+	if want := []string{"b-chunk", KeywordChunkIDPrefix + "b"}; !reflect.DeepEqual(chunkIDs, want) {
+		t.Errorf("chunks of node b = %v, want %v", chunkIDs, want)
+	}
+}
+
+func TestParseKeywordQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		question string
+		want     *KeywordQuery
+		tsquery  string
+	}{
+		{name: "empty", question: "", want: &KeywordQuery{Words: []string{}, Identifiers: []string{}}},
+		{
+			name:     "words and identifiers",
+			question: "How do I fix error E1234 in v2.3.1?",
+			want:     &KeywordQuery{Words: []string{"fix", "error"}, Identifiers: []string{"E1234", "v2.3.1"}},
+			tsquery:  "fix | error",
+		},
+		{
+			name:     "stop words and short words are dropped",
+			question: "What is the API for it",
+			want:     &KeywordQuery{Words: []string{"api"}, Identifiers: []string{}},
+			tsquery:  "api",
+		},
+		{
+			name:     "duplicates in any case are dropped",
+			question: "Docker docker DOCKER sku-1 SKU-1",
+			want:     &KeywordQuery{Words: []string{"docker"}, Identifiers: []string{"sku-1"}},
+			tsquery:  "docker",
+		},
+		{
+			name:     "identifiers keep inner punctuation and drop outer punctuation",
+			question: "(SKU-123-A). /api/v1/node #42 config_file.yaml",
+			want:     &KeywordQuery{Words: []string{}, Identifiers: []string{"SKU-123-A", "api/v1/node", "42", "config_file.yaml"}},
+		},
+		{
+			name:     "single characters are dropped",
+			question: "a 5 x1",
+			want:     &KeywordQuery{Words: []string{}, Identifiers: []string{"x1"}},
+		},
+		{
+			name:     "han text is left to vector search",
+			question: "如何配置 nginx 反向代理 配置nginx",
+			want:     &KeywordQuery{Words: []string{"nginx"}, Identifiers: []string{}},
+			tsquery:  "nginx",
+		},
+		{
+			name:     "other letters are words",
+			question: "Größe ändern",
+			want:     &KeywordQuery{Words: []string{"größe", "ändern"}, Identifiers: []string{}},
+			tsquery:  "größe | ändern",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseKeywordQuery(tt.question)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ParseKeywordQuery() = %+v, want %+v", got, tt.want)
+			}
+			if got.TSQuery() != tt.tsquery {
+				t.Errorf("TSQuery() = %q, want %q", got.TSQuery(), tt.tsquery)
+			}
+			if got.Empty() != (len(tt.want.Words) == 0 && len(tt.want.Identifiers) == 0) {
+				t.Errorf("Empty() = %v", got.Empty())
+			}
+		})
+	}
+}
+
+func TestKeywordQueryTerms(t *testing.T) {
+	query := &KeywordQuery{Words: []string{"error"}, Identifiers: []string{"E1234"}}
+	if got, want := query.Terms(), []string{"E1234", "error"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Terms() = %v, want %v", got, want)
+	}
+}
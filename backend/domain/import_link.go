@@ -0,0 +1,18 @@
+package domain
+
+type ImportLink struct {
+	// source url of the imported page, or page id for notion
+	URL    string `json:"url" validate:"required"`
+	NodeID string `json:"node_id" validate:"required"`
+}
+
+// RewriteImportLinksReq second pass of import, links between imported pages are pointed at their nodes
+type RewriteImportLinksReq struct {
+	KBID  string       `json:"kb_id" validate:"required"`
+	Links []ImportLink `json:"links" validate:"required,min=1,dive"`
+}
+
+type RewriteImportLinksResp struct {
+	RewriteCount   int      `json:"rewrite_count"`
+	UpdatedNodeIDs []string `json:"updated_node_ids"`
+}
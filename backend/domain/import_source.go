@@ -0,0 +1,337 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ImportSourceSyncTimeout sources syncing for longer were interrupted and can be synced again
+const ImportSourceSyncTimeout = time.Hour
+
+const (
+	// WebsiteDefaultMaxDepth link depth crawled from the start page if not set
+	WebsiteDefaultMaxDepth = 2
+	// WebsiteMaxPages max pages imported from a website
+	WebsiteMaxPages = 500
+)
+
+var (
+	ErrImportSourceSyncing = NewError(ErrCodeConflict, "import source is syncing")
+	ErrGitWebhookSignature = NewError(ErrCodeUnauthorized, "invalid git webhook signature or token")
+)
+
+type ImportSourceType string
+
+const (
+	ImportSourceTypeConfluence ImportSourceType = "confluence"
+	ImportSourceTypeNotion     ImportSourceType = "notion"
+	ImportSourceTypeFeishu     ImportSourceType = "feishu"
+	ImportSourceTypeYuque      ImportSourceType = "yuque"
+	ImportSourceTypeGit        ImportSourceType = "git"
+	ImportSourceTypeOpenAPI    ImportSourceType = "openapi"
+	ImportSourceTypeWebsite    ImportSourceType = "website"
+	ImportSourceTypeFeed       ImportSourceType = "feed"
+)
+
+// ListsRecentPages sources listing only their recent pages, like the latest entries of feeds.
+// pages no longer listed are not removed from the source and not marked stale
+func (t ImportSourceType) ListsRecentPages() bool {
+	return t == ImportSourceTypeFeed
+}
+
+type ImportSourceStatus string
+
+const (
+	ImportSourceStatusIdle      ImportSourceStatus = "idle"
+	ImportSourceStatusSyncing   ImportSourceStatus = "syncing"
+	ImportSourceStatusSucceeded ImportSourceStatus = "succeeded"
+	ImportSourceStatusFailed    ImportSourceStatus = "failed"
+)
+
+// table: import_sources
+type ImportSource struct {
+	ID   string           `json:"id" gorm:"primaryKey"`
+	KBID string           `json:"kb_id"`
+	Type ImportSourceType `json:"type"`
+	Name string           `json:"name"`
+	// folder the pages are imported into, kb root if empty
+	ParentID string               `json:"parent_id"`
+	Settings ImportSourceSettings `json:"settings" gorm:"type:jsonb"`
+	// hours between automatic syncs, only synced manually if 0
+	SyncInterval int                `json:"sync_interval"`
+	Status       ImportSourceStatus `json:"status"`
+	Error        string             `json:"error"`
+	// pages of the source and pages created or updated by the last sync
+	PageCount    int `json:"page_count"`
+	ChangedCount int `json:"changed_count"`
+	// imported pages no longer in the source, their nodes are kept
+	StaleCount   int        `json:"stale_count"`
+	LastSyncedAt *time.Time `json:"last_synced_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+func (ImportSource) TableName() string {
+	return "import_sources"
+}
+
+type ImportSourceSettings struct {
+	Confluence *ConfluenceSettings `json:"confluence,omitempty"`
+	Notion     *NotionSettings     `json:"notion,omitempty"`
+	Feishu     *FeishuSettings     `json:"feishu,omitempty"`
+	Yuque      *YuqueSettings      `json:"yuque,omitempty"`
+	Git        *GitSettings        `json:"git,omitempty"`
+	OpenAPI    *OpenAPISettings    `json:"openapi,omitempty"`
+	Website    *WebsiteSettings    `json:"website,omitempty"`
+	Feed       *FeedSettings       `json:"feed,omitempty"`
+}
+
+func (s *ImportSourceSettings) Scan(value any) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New(fmt.Sprint("invalid import source settings value type:", value))
+	}
+	return json.Unmarshal(bytes, s)
+}
+
+func (s ImportSourceSettings) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+// Masked settings without credentials, returned by the api
+func (s ImportSourceSettings) Masked() ImportSourceSettings {
+	if s.Confluence != nil {
+		confluence := *s.Confluence
+		confluence.Token = ""
+		s.Confluence = &confluence
+	}
+	if s.Notion != nil {
+		notion := *s.Notion
+		notion.Token = ""
+		s.Notion = &notion
+	}
+	if s.Feishu != nil {
+		feishu := *s.Feishu
+		feishu.AppSecret = ""
+		s.Feishu = &feishu
+	}
+	if s.Yuque != nil {
+		yuque := *s.Yuque
+		yuque.Token = ""
+		s.Yuque = &yuque
+	}
+	if s.Git != nil {
+		git := *s.Git
+		git.Token = ""
+		git.WebhookSecret = ""
+		s.Git = &git
+	}
+	if s.OpenAPI != nil {
+		openapi := *s.OpenAPI
+		openapi.Token = ""
+		s.OpenAPI = &openapi
+	}
+	return s
+}
+
+type ConfluenceSettings struct {
+	// site url, e.g. https://example.atlassian.net/wiki
+	BaseURL string `json:"base_url" validate:"required,url"`
+	// account email of cloud api tokens, empty for personal access tokens of server
+	Username string `json:"username"`
+	Token    string `json:"token"`
+	SpaceKey string `json:"space_key" validate:"required"`
+}
+
+type NotionSettings struct {
+	// internal integration token, the selected pages must be shared with the integration
+	Token string `json:"token"`
+	// ids or urls of the selected pages and databases, imported with the pages nested in them
+	PageIDs []string `json:"page_ids" validate:"required,min=1"`
+}
+
+// FeishuSettings custom app reading a wiki space or a drive folder with its tenant token
+type FeishuSettings struct {
+	AppID     string `json:"app_id" validate:"required"`
+	AppSecret string `json:"app_secret"`
+	// the app must be a member of the wiki space, or be granted the folder
+	SpaceID     string `json:"space_id" validate:"required_without=FolderToken"`
+	FolderToken string `json:"folder_token"`
+}
+
+// YuqueSettings repo (book) read with a personal or team token
+type YuqueSettings struct {
+	// site of the space, https://www.yuque.com if empty
+	BaseURL string `json:"base_url" validate:"omitempty,url"`
+	Token   string `json:"token"`
+	// namespace or url of the repo, e.g. group/book
+	Namespace string `json:"namespace" validate:"required"`
+}
+
+// GitSettings markdown files of a github or gitlab repo cloned over https, directories are folders
+type GitSettings struct {
+	// https url of the repo, e.g. https://github.com/group/docs.git
+	URL string `json:"url" validate:"required,url"`
+	// default branch if empty
+	Branch string `json:"branch"`
+	// directory of the docs in the repo, the whole repo if empty
+	Path string `json:"path"`
+	// personal or project access token of private repos
+	Token string `json:"token"`
+	// secret of github webhooks or token of gitlab webhooks, push events sync the source
+	WebhookSecret string `json:"webhook_secret"`
+}
+
+// OpenAPISettings json or yaml openapi 3 spec, imported as a document for each tag and each operation
+type OpenAPISettings struct {
+	URL string `json:"url" validate:"required,url"`
+	// sent as bearer token if the spec is not public
+	Token string `json:"token"`
+}
+
+// WebsiteSettings pages of a site listed by its sitemap, or found by following links from the start page
+type WebsiteSettings struct {
+	// sitemap.xml or a page of the site, the sitemap.xml of a site root is used if it exists
+	URL string `json:"url" validate:"required,url"`
+	// link depth followed from the start page, sitemaps list all pages. 2 if 0
+	MaxDepth int `json:"max_depth" validate:"min=0,max=10"`
+	// regexps of urls to import, all pages found if empty. pages not included are still followed for links
+	Include []string `json:"include"`
+	// regexps of urls neither imported nor followed
+	Exclude []string `json:"exclude"`
+}
+
+// FeedSettings rss, atom or json feed, each entry is imported as a document
+type FeedSettings struct {
+	URL string `json:"url" validate:"required,url"`
+	// fetch the page of entries whose feed content is only a summary
+	FetchPage bool `json:"fetch_page"`
+}
+
+type ImportSourceItemKind string
+
+const (
+	ImportSourceItemKindPage       ImportSourceItemKind = "page"
+	ImportSourceItemKindAttachment ImportSourceItemKind = "attachment"
+)
+
+// table: import_source_items, imported pages and attachments with their version, to sync only changes
+type ImportSourceItem struct {
+	SourceID   string               `json:"source_id" gorm:"primaryKey"`
+	Kind       ImportSourceItemKind `json:"kind" gorm:"primaryKey"`
+	ExternalID string               `json:"external_id" gorm:"primaryKey"`
+	// node of the page, or of the page the attachment belongs to
+	NodeID  string `json:"node_id"`
+	Version string `json:"version"`
+	// static file url of images, node attachment id of other files
+	Ref string `json:"ref"`
+	// when the page was no longer found in the source, nil while it is
+	StaleAt   *time.Time `json:"stale_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+func (ImportSourceItem) TableName() string {
+	return "import_source_items"
+}
+
+type CreateImportSourceReq struct {
+	KBID         string              `json:"kb_id" validate:"required"`
+	Type         ImportSourceType    `json:"type" validate:"required,oneof=confluence notion feishu yuque git openapi website feed"`
+	Name         string              `json:"name"` // name of the space or the first page if empty
+	ParentID     string              `json:"parent_id"`
+	SyncInterval int                 `json:"sync_interval" validate:"min=0"`
+	Confluence   *ConfluenceSettings `json:"confluence"`
+	Notion       *NotionSettings     `json:"notion"`
+	Feishu       *FeishuSettings     `json:"feishu"`
+	Yuque        *YuqueSettings      `json:"yuque"`
+	Git          *GitSettings        `json:"git"`
+	OpenAPI      *OpenAPISettings    `json:"openapi"`
+	Website      *WebsiteSettings    `json:"website"`
+	Feed         *FeedSettings       `json:"feed"`
+}
+
+type UpdateImportSourceReq struct {
+	ID           string  `json:"id" validate:"required"`
+	KBID         string  `json:"kb_id" validate:"required"`
+	Name         *string `json:"name"`
+	ParentID     *string `json:"parent_id"`
+	SyncInterval *int    `json:"sync_interval" validate:"omitempty,min=0"`
+	// empty token or secret keeps the saved one
+	Confluence *ConfluenceSettings `json:"confluence"`
+	Notion     *NotionSettings     `json:"notion"`
+	Feishu     *FeishuSettings     `json:"feishu"`
+	Yuque      *YuqueSettings      `json:"yuque"`
+	Git        *GitSettings        `json:"git"`
+	OpenAPI    *OpenAPISettings    `json:"openapi"`
+	Website    *WebsiteSettings    `json:"website"`
+	Feed       *FeedSettings       `json:"feed"`
+}
+
+type ImportSourceListReq struct {
+	KBID string `json:"kb_id" query:"kb_id" validate:"required"`
+}
+
+type ImportSourceReq struct {
+	ID   string `json:"id" query:"id" validate:"required"`
+	KBID string `json:"kb_id" query:"kb_id" validate:"required"`
+}
+
+// PreviewImportSourceReq next sync of a saved source, or the first sync of a new source by its type and settings
+type PreviewImportSourceReq struct {
+	KBID       string              `json:"kb_id" validate:"required"`
+	ID         string              `json:"id"`
+	Type       ImportSourceType    `json:"type" validate:"omitempty,oneof=confluence notion feishu yuque git openapi website feed"`
+	Confluence *ConfluenceSettings `json:"confluence"`
+	Notion     *NotionSettings     `json:"notion"`
+	Feishu     *FeishuSettings     `json:"feishu"`
+	Yuque      *YuqueSettings      `json:"yuque"`
+	Git        *GitSettings        `json:"git"`
+	OpenAPI    *OpenAPISettings    `json:"openapi"`
+	Website    *WebsiteSettings    `json:"website"`
+	Feed       *FeedSettings       `json:"feed"`
+}
+
+// GitWebhookResp push events of other branches are ignored
+type GitWebhookResp struct {
+	Synced bool   `json:"synced"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type ImportPreviewAction string
+
+const (
+	ImportPreviewActionCreate    ImportPreviewAction = "create"
+	ImportPreviewActionUpdate    ImportPreviewAction = "update"
+	ImportPreviewActionUnchanged ImportPreviewAction = "unchanged"
+)
+
+type ImportSourcePreviewPage struct {
+	ID       string              `json:"id"`
+	ParentID string              `json:"parent_id"`
+	Title    string              `json:"title"`
+	Folder   bool                `json:"folder"`
+	Action   ImportPreviewAction `json:"action"`
+}
+
+// ImportSourcePreview pages a sync would create or update, nothing is imported
+type ImportSourcePreview struct {
+	Name        string `json:"name"`
+	CreateCount int    `json:"create_count"`
+	UpdateCount int    `json:"update_count"`
+	// imported pages no longer in the source, marked stale by the sync and their nodes are kept
+	RemovedCount int `json:"removed_count"`
+	// parents before their children
+	Pages []*ImportSourcePreviewPage `json:"pages"`
+}
+
+// ImportSourceStalePage imported page no longer in the source
+type ImportSourceStalePage struct {
+	// id of the page in the source, the url of website pages
+	ExternalID string    `json:"external_id"`
+	NodeID     string    `json:"node_id"`
+	NodeName   string    `json:"node_name"`
+	StaleAt    time.Time `json:"stale_at"`
+}
@@ -0,0 +1,131 @@
+package domain
+
+import "time"
+
+const (
+	// IndexIntegrityGracePeriod releases and documents changed within the period may still be indexing
+	IndexIntegrityGracePeriod = 15 * time.Minute
+	// IndexIntegritySampleLimit max node or doc ids listed for each kind of drift
+	IndexIntegritySampleLimit = 50
+)
+
+type RAGDocumentStatus string
+
+const (
+	RAGDocumentStatusPending RAGDocumentStatus = "pending"
+	RAGDocumentStatusDone    RAGDocumentStatus = "done"
+	RAGDocumentStatusFailed  RAGDocumentStatus = "failed"
+)
+
+// RAGDocument document of a node release in the vector store
+type RAGDocument struct {
+	ID         string            `json:"id"`
+	Status     RAGDocumentStatus `json:"status"`
+	ChunkCount int               `json:"chunk_count"`
+	CreatedAt  time.Time         `json:"created_at"`
+}
+
+type IndexIntegrityReq struct {
+	KBID string `json:"kb_id" query:"kb_id" validate:"required"`
+}
+
+// IndexIntegrityReport drift between published nodes in postgres and documents in the vector store
+type IndexIntegrityReport struct {
+	KBID string `json:"kb_id"`
+	// published nodes which should be indexed
+	NodeCount int `json:"node_count"`
+	// documents and chunks in the vector store
+	DocCount   int `json:"doc_count"`
+	ChunkCount int `json:"chunk_count"`
+	// nodes without document in the vector store
+	MissingNodeIDs []string `json:"missing_node_ids"`
+	MissingCount   int      `json:"missing_count"`
+	// nodes whose document failed parsing or has no chunks
+	EmptyNodeIDs []string `json:"empty_node_ids"`
+	EmptyCount   int      `json:"empty_count"`
+	// documents in the vector store not referenced by any node
+	OrphanDocIDs []string `json:"orphan_doc_ids"`
+	OrphanCount  int      `json:"orphan_count"`
+	// nodes queued for re-indexing and orphan documents deleted by repair
+	ReindexedCount  int  `json:"reindexed_count"`
+	DeletedDocCount int  `json:"deleted_doc_count"`
+	Repaired        bool `json:"repaired"`
+
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+func (r *IndexIntegrityReport) HasDrift() bool {
+	return r.MissingCount > 0 || r.EmptyCount > 0 || r.OrphanCount > 0
+}
+
+func (r *IndexIntegrityReport) addMissing(nodeID string) {
+	r.MissingCount++
+	if len(r.MissingNodeIDs) < IndexIntegritySampleLimit {
+		r.MissingNodeIDs = append(r.MissingNodeIDs, nodeID)
+	}
+}
+
+func (r *IndexIntegrityReport) addEmpty(nodeID string) {
+	r.EmptyCount++
+	if len(r.EmptyNodeIDs) < IndexIntegritySampleLimit {
+		r.EmptyNodeIDs = append(r.EmptyNodeIDs, nodeID)
+	}
+}
+
+func (r *IndexIntegrityReport) addOrphan(docID string) {
+	r.OrphanCount++
+	if len(r.OrphanDocIDs) < IndexIntegritySampleLimit {
+		r.OrphanDocIDs = append(r.OrphanDocIDs, docID)
+	}
+}
+
+// IndexDrift releases to re-index and documents to delete found by CompareIndex
+type IndexDrift struct {
+	Reindex      []*NodeRelease
+	StaleDocIDs  []string
+	OrphanDocIDs []string
+}
+
+// CompareIndex compare latest public node releases with documents in the vector store,
+// releases and documents younger than the grace period are skipped as they may still be indexing
+func CompareIndex(report *IndexIntegrityReport, releases []*NodeRelease, referencedDocIDs []string, docs []*RAGDocument, now time.Time) *IndexDrift {
+	drift := &IndexDrift{}
+	docByID := make(map[string]*RAGDocument, len(docs))
+	for _, doc := range docs {
+		docByID[doc.ID] = doc
+		report.ChunkCount += doc.ChunkCount
+	}
+	report.NodeCount = len(releases)
+	report.DocCount = len(docs)
+	cutoff := now.Add(-IndexIntegrityGracePeriod)
+	for _, release := range releases {
+		if release.UpdatedAt.After(cutoff) {
+			continue
+		}
+		doc, ok := docByID[release.DocID]
+		if release.DocID == "" || !ok {
+			report.addMissing(release.NodeID)
+			drift.Reindex = append(drift.Reindex, release)
+			continue
+		}
+		switch {
+		case doc.Status == RAGDocumentStatusFailed,
+			doc.Status == RAGDocumentStatusDone && doc.ChunkCount == 0 && release.Type != NodeTypeFolder:
+			report.addEmpty(release.NodeID)
+			drift.Reindex = append(drift.Reindex, release)
+			drift.StaleDocIDs = append(drift.StaleDocIDs, doc.ID)
+		}
+	}
+	referenced := make(map[string]struct{}, len(referencedDocIDs))
+	for _, id := range referencedDocIDs {
+		referenced[id] = struct{}{}
+	}
+	for _, doc := range docs {
+		if _, ok := referenced[doc.ID]; ok || doc.CreatedAt.After(cutoff) {
+			continue
+		}
+		report.addOrphan(doc.ID)
+		drift.OrphanDocIDs = append(drift.OrphanDocIDs, doc.ID)
+	}
+	return drift
+}
@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+const SettingKeyIPRules = "ip_rules"
+
+var (
+	ErrIPDenied      = NewError(ErrCodeForbidden, "requests from your ip are not allowed")
+	ErrIPRuleLockout = NewError(ErrCodeInvalidRequest, "the admin rules would block your own ip")
+)
+
+// IPRuleScope apis an ip rule set applies to
+type IPRuleScope string
+
+const (
+	// IPRuleScopeAdmin admin apis under /api/ of the console, api tokens included
+	IPRuleScopeAdmin IPRuleScope = "admin"
+	// IPRuleScopePublic apis of the public site under /share/, chat and app included, and its sitemap.xml and opensearch.xml
+	IPRuleScopePublic IPRuleScope = "public"
+)
+
+// IPRuleSet cidrs or single ips, denied ips are rejected first, every other ip is allowed if allow is empty
+type IPRuleSet struct {
+	Allow []string `json:"allow"`
+	Deny  []string `json:"deny"`
+}
+
+// IPRules ip rules of the admin and the public apis, stored in settings
+type IPRules struct {
+	Admin  IPRuleSet `json:"admin"`
+	Public IPRuleSet `json:"public"`
+}
+
+type UpdateIPRulesReq = IPRules
+
+// IPRuleMatcher rule set with parsed prefixes
+type IPRuleMatcher struct {
+	allow []netip.Prefix
+	deny  []netip.Prefix
+}
+
+// Compile parse the rules, an error names the first invalid rule
+func (s *IPRuleSet) Compile() (*IPRuleMatcher, error) {
+	allow, err := parseIPPrefixes(s.Allow)
+	if err != nil {
+		return nil, err
+	}
+	deny, err := parseIPPrefixes(s.Deny)
+	if err != nil {
+		return nil, err
+	}
+	return &IPRuleMatcher{allow: allow, deny: deny}, nil
+}
+
+func parseIPPrefixes(rules []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(rules))
+	for _, rule := range rules {
+		rule = strings.TrimSpace(rule)
+		if !strings.Contains(rule, "/") {
+			addr, err := netip.ParseAddr(rule)
+			if err != nil {
+				return nil, NewError(ErrCodeInvalidRequest, fmt.Sprintf("invalid ip rule %q", rule))
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(rule)
+		if err != nil {
+			return nil, NewError(ErrCodeInvalidRequest, fmt.Sprintf("invalid ip rule %q", rule))
+		}
+		// ips are matched unmapped, so ipv4-mapped ipv6 rules are matched as ipv4
+		if prefix.Addr().Is4In6() && prefix.Bits() >= 96 {
+			prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// Allows whether requests of the ip pass the rules, invalid ips only pass empty rules
+func (m *IPRuleMatcher) Allows(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return len(m.allow) == 0 && len(m.deny) == 0
+	}
+	addr = addr.Unmap()
+	for _, prefix := range m.deny {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	if len(m.allow) == 0 {
+		return true
+	}
+	for _, prefix := range m.allow {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
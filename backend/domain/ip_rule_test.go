@@ -0,0 +1,53 @@
+package domain
+
+import "testing"
+
+func TestIPRuleMatcherAllows(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules IPRuleSet
+		ip    string
+		want  bool
+	}{
+		{name: "empty rules allow every ip", rules: IPRuleSet{}, ip: "203.0.113.7", want: true},
+		{name: "empty rules allow invalid ip", rules: IPRuleSet{}, ip: "not-an-ip", want: true},
+		{name: "empty allowlist allows ips not denied", rules: IPRuleSet{Deny: []string{"198.51.100.0/24"}}, ip: "203.0.113.7", want: true},
+		{name: "ipv4 cidr deny", rules: IPRuleSet{Deny: []string{"198.51.100.0/24"}}, ip: "198.51.100.20", want: false},
+		{name: "ipv4 single ip deny", rules: IPRuleSet{Deny: []string{"198.51.100.20"}}, ip: "198.51.100.20", want: false},
+		{name: "ipv4 single ip deny does not cover neighbour", rules: IPRuleSet{Deny: []string{"198.51.100.20"}}, ip: "198.51.100.21", want: true},
+		{name: "ipv4 cidr allow", rules: IPRuleSet{Allow: []string{"10.0.0.0/8"}}, ip: "10.1.2.3", want: true},
+		{name: "ip outside allowlist", rules: IPRuleSet{Allow: []string{"10.0.0.0/8"}}, ip: "11.1.2.3", want: false},
+		{name: "unaligned cidr is masked", rules: IPRuleSet{Allow: []string{"10.1.2.3/16"}}, ip: "10.1.200.1", want: true},
+		{name: "deny wins over allow", rules: IPRuleSet{Allow: []string{"10.0.0.0/8"}, Deny: []string{"10.0.0.5"}}, ip: "10.0.0.5", want: false},
+		{name: "deny cidr wins over allowed single ip", rules: IPRuleSet{Allow: []string{"10.0.0.5"}, Deny: []string{"10.0.0.0/24"}}, ip: "10.0.0.5", want: false},
+		{name: "ipv6 cidr allow", rules: IPRuleSet{Allow: []string{"2001:db8::/32"}}, ip: "2001:db8:1::1", want: true},
+		{name: "ipv6 outside allowlist", rules: IPRuleSet{Allow: []string{"2001:db8::/32"}}, ip: "2001:db9::1", want: false},
+		{name: "ipv6 single ip deny", rules: IPRuleSet{Deny: []string{"2001:db8::1"}}, ip: "2001:db8::1", want: false},
+		{name: "ipv4 rules do not match ipv6", rules: IPRuleSet{Allow: []string{"10.0.0.0/8"}}, ip: "2001:db8::1", want: false},
+		{name: "ipv4-mapped ip matches ipv4 deny", rules: IPRuleSet{Deny: []string{"198.51.100.0/24"}}, ip: "::ffff:198.51.100.20", want: false},
+		{name: "ipv4-mapped ip matches ipv4 allow", rules: IPRuleSet{Allow: []string{"10.0.0.0/8"}}, ip: "::ffff:10.1.2.3", want: true},
+		{name: "ipv4-mapped single ip rule matches ipv4", rules: IPRuleSet{Deny: []string{"::ffff:198.51.100.20"}}, ip: "198.51.100.20", want: false},
+		{name: "ipv4-mapped cidr rule matches ipv4", rules: IPRuleSet{Allow: []string{"::ffff:10.0.0.0/104"}}, ip: "10.1.2.3", want: true},
+		{name: "invalid ip does not pass an allowlist", rules: IPRuleSet{Allow: []string{"10.0.0.0/8"}}, ip: "not-an-ip", want: false},
+		{name: "invalid ip does not pass a denylist", rules: IPRuleSet{Deny: []string{"10.0.0.0/8"}}, ip: "", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matcher, err := tt.rules.Compile()
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			if got := matcher.Allows(tt.ip); got != tt.want {
+				t.Errorf("Allows(%q) = %v, want %v", tt.ip, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIPRuleSetCompileInvalid(t *testing.T) {
+	for _, rule := range []string{"10.0.0.0/33", "10.0.0.256", "example.com", "2001:db8::/129"} {
+		if _, err := (&IPRuleSet{Allow: []string{rule}}).Compile(); err == nil {
+			t.Errorf("Compile(%q) error = nil, want invalid rule", rule)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// UserRole global role of an admin console user
+type UserRole string
+
+const (
+	// every kb and the system settings
+	UserRoleAdmin UserRole = "admin"
+	// only the kbs the user has a role on, with the permissions of the role
+	UserRoleMember UserRole = "member"
+)
+
+// KBRole role of a member on a knowledge base
+type KBRole string
+
+const (
+	KBRoleOwner        KBRole = "owner"
+	KBRoleEditor       KBRole = "editor"
+	KBRoleAnalyst      KBRole = "analyst"
+	KBRoleSupportAgent KBRole = "support_agent"
+)
+
+// KBPermission what a role may do on the kb
+type KBPermission string
+
+const (
+	// read documents, releases and the kb, every role
+	KBPermissionView KBPermission = "view"
+	// create, edit, publish and import documents
+	KBPermissionEditNodes KBPermission = "edit_nodes"
+	// export documents
+	KBPermissionExportNodes KBPermission = "export_nodes"
+	// read conversations and feedback of readers
+	KBPermissionViewConversations KBPermission = "view_conversations"
+	// reports and anomalies of questions and traffic
+	KBPermissionViewAnalytics KBPermission = "view_analytics"
+	// kb, app, access and integration settings, and the members of the kb
+	KBPermissionManageSettings KBPermission = "manage_settings"
+	// export all data of the kb, readers and conversations included
+	KBPermissionExportData KBPermission = "export_data"
+)
+
+var kbRolePermissions = map[KBRole][]KBPermission{
+	KBRoleOwner: {
+		KBPermissionView,
+		KBPermissionEditNodes,
+		KBPermissionExportNodes,
+		KBPermissionViewConversations,
+		KBPermissionViewAnalytics,
+		KBPermissionManageSettings,
+		KBPermissionExportData,
+	},
+	KBRoleEditor: {
+		KBPermissionView,
+		KBPermissionEditNodes,
+		KBPermissionExportNodes,
+	},
+	KBRoleAnalyst: {
+		KBPermissionView,
+		KBPermissionViewConversations,
+		KBPermissionViewAnalytics,
+	},
+	KBRoleSupportAgent: {
+		KBPermissionView,
+		KBPermissionViewConversations,
+	},
+}
+
+// Allows whether the role has the permission, unknown roles have none
+func (r KBRole) Allows(permission KBPermission) bool {
+	return slices.Contains(kbRolePermissions[r], permission)
+}
+
+func (r KBRole) Permissions() []KBPermission {
+	return kbRolePermissions[r]
+}
+
+var (
+	ErrAdminRequired    = NewError(ErrCodeForbidden, "admin role required")
+	ErrChangeOwnRole    = NewError(ErrCodeForbidden, "can't change your own role")
+	ErrKBMemberNotFound = NewError(ErrCodeNotFound, "kb member not found")
+)
+
+// table: kb_members, roles of member users on kbs, admins need none
+type KBMember struct {
+	KBID      string    `json:"kb_id" gorm:"primaryKey"`
+	UserID    string    `json:"user_id" gorm:"primaryKey"`
+	Role      KBRole    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (KBMember) TableName() string {
+	return "kb_members"
+}
+
+type KBMemberListReq struct {
+	KBID string `json:"kb_id" query:"kb_id" validate:"required"`
+}
+
+type KBMemberListItem struct {
+	KBMember
+	Account  string   `json:"account"`
+	UserRole UserRole `json:"user_role"`
+}
+
+// SetKBMemberReq add the user to the kb or change its role
+type SetKBMemberReq struct {
+	KBID   string `json:"kb_id" validate:"required"`
+	UserID string `json:"user_id" validate:"required"`
+	Role   KBRole `json:"role" validate:"required,oneof=owner editor analyst support_agent"`
+}
+
+type DeleteKBMemberReq struct {
+	KBID   string `json:"kb_id" query:"kb_id" validate:"required"`
+	UserID string `json:"user_id" query:"user_id" validate:"required"`
+}
+
+type UpdateUserRoleReq struct {
+	ID   string   `json:"id" validate:"required"`
+	Role UserRole `json:"role" validate:"required,oneof=admin member"`
+}
+
+// UserKBRole role of the current user on a kb
+type UserKBRole struct {
+	KBID        string         `json:"kb_id"`
+	Role        KBRole         `json:"role"`
+	Permissions []KBPermission `json:"permissions"`
+}
@@ -5,7 +5,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
+
+	"github.com/samber/lo"
 )
 
 // table: knowledge_bases
@@ -14,9 +17,40 @@ type KnowledgeBase struct {
 	Name string `json:"name"`
 
 	DatasetID string `json:"dataset_id"`
+	// dataset being filled with the embedding model of the kb, it replaces DatasetID once filled
+	PendingDatasetID string `json:"pending_dataset_id,omitempty"`
 
 	// public info for public access
 	AccessSettings AccessSettings `json:"access_settings" gorm:"type:jsonb"`
+	// content policy of model responses
+	ComplianceSettings ComplianceSettings `json:"compliance_settings" gorm:"type:jsonb"`
+	// bot traffic filter of stats
+	StatSettings StatSettings `json:"stat_settings" gorm:"type:jsonb"`
+	// confidence gate of answers
+	AnswerSettings AnswerSettings `json:"answer_settings" gorm:"type:jsonb"`
+	// read-only switch of admin writes
+	MaintenanceSettings MaintenanceSettings `json:"maintenance_settings" gorm:"type:jsonb"`
+	// review workflow of publishing
+	ReviewSettings ReviewSettings `json:"review_settings" gorm:"type:jsonb"`
+	// daily conversation digest
+	DigestSettings DigestSettings `json:"digest_settings" gorm:"type:jsonb"`
+	// region-specific content and answers
+	GeoSettings GeoSettings `json:"geo_settings" gorm:"type:jsonb"`
+	// reader comments on published nodes
+	CommentSettings CommentSettings `json:"comment_settings" gorm:"type:jsonb"`
+	// bot profile in connected channels
+	BrandingSettings BrandingSettings `json:"branding_settings" gorm:"type:jsonb"`
+	// temporary blocks of abnormal chat traffic
+	AnomalySettings AnomalySettings `json:"anomaly_settings" gorm:"type:jsonb"`
+	// reminders of owned documents due for review
+	ReviewReminderSettings ReviewReminderSettings `json:"review_reminder_settings" gorm:"type:jsonb"`
+	// chat, embedding and rerank models
+	ModelSettings KBModelSettings `json:"model_settings" gorm:"type:jsonb"`
+
+	// api token which created the kb as a temporary sandbox, empty for production kbs
+	SandboxTokenID string `json:"sandbox_token_id,omitempty"`
+	// sandbox is torn down after
+	SandboxExpiresAt *time.Time `json:"sandbox_expires_at,omitempty"`
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -31,6 +65,10 @@ type AccessSettings struct {
 	BaseURL    string   `json:"base_url"`
 
 	SimpleAuth SimpleAuth `json:"simple_auth"`
+	// identify viewers of the public site for node acls
+	ViewerAuth ViewerAuth `json:"viewer_auth"`
+	// restrict the public site to reader accounts or sso users
+	ReaderAccess ReaderAccess `json:"reader_access"`
 }
 
 type SimpleAuth struct {
@@ -50,6 +88,28 @@ func (s AccessSettings) Value() (driver.Value, error) {
 	return json.Marshal(s)
 }
 
+// SiteURL url of the public site, the base url or the first host and port the site is served on,
+// empty if the site has no host
+func (s AccessSettings) SiteURL() string {
+	if s.BaseURL != "" {
+		return strings.TrimSuffix(s.BaseURL, "/")
+	}
+	host, ok := lo.Find(s.Hosts, func(host string) bool { return host != "" && !strings.Contains(host, "*") })
+	if !ok {
+		return ""
+	}
+	switch {
+	case len(s.SSLPorts) > 0 && s.SSLPorts[0] == 443:
+		return "https://" + host
+	case len(s.SSLPorts) > 0:
+		return fmt.Sprintf("https://%s:%d", host, s.SSLPorts[0])
+	case len(s.Ports) > 0 && s.Ports[0] != 80:
+		return fmt.Sprintf("http://%s:%d", host, s.Ports[0])
+	default:
+		return "http://" + host
+	}
+}
+
 type CreateKnowledgeBaseReq struct {
 	ID         string   `json:"-"`
 	Name       string   `json:"name" validate:"required"`
@@ -64,6 +124,30 @@ type UpdateKnowledgeBaseReq struct {
 	ID             string          `json:"id" validate:"required"`
 	Name           *string         `json:"name"`
 	AccessSettings *AccessSettings `json:"access_settings"`
+
+	ComplianceSettings *ComplianceSettings `json:"compliance_settings"`
+
+	StatSettings *StatSettings `json:"stat_settings"`
+
+	AnswerSettings *AnswerSettings `json:"answer_settings"`
+
+	MaintenanceSettings *MaintenanceSettings `json:"maintenance_settings"`
+
+	ReviewSettings *ReviewSettings `json:"review_settings"`
+
+	DigestSettings *DigestSettings `json:"digest_settings"`
+
+	GeoSettings *GeoSettings `json:"geo_settings"`
+
+	CommentSettings *CommentSettings `json:"comment_settings"`
+
+	BrandingSettings *BrandingSettings `json:"branding_settings"`
+
+	AnomalySettings *AnomalySettings `json:"anomaly_settings"`
+
+	ReviewReminderSettings *ReviewReminderSettings `json:"review_reminder_settings"`
+
+	ModelSettings *KBModelSettings `json:"model_settings"`
 }
 
 type KnowledgeBaseListItem struct {
@@ -86,6 +170,30 @@ type KnowledgeBaseDetail struct {
 
 	AccessSettings AccessSettings `json:"access_settings" gorm:"type:jsonb"`
 
+	ComplianceSettings ComplianceSettings `json:"compliance_settings" gorm:"type:jsonb"`
+
+	StatSettings StatSettings `json:"stat_settings" gorm:"type:jsonb"`
+
+	AnswerSettings AnswerSettings `json:"answer_settings" gorm:"type:jsonb"`
+
+	MaintenanceSettings MaintenanceSettings `json:"maintenance_settings" gorm:"type:jsonb"`
+
+	ReviewSettings ReviewSettings `json:"review_settings" gorm:"type:jsonb"`
+
+	DigestSettings DigestSettings `json:"digest_settings" gorm:"type:jsonb"`
+
+	GeoSettings GeoSettings `json:"geo_settings" gorm:"type:jsonb"`
+
+	CommentSettings CommentSettings `json:"comment_settings" gorm:"type:jsonb"`
+
+	BrandingSettings BrandingSettings `json:"branding_settings" gorm:"type:jsonb"`
+
+	AnomalySettings AnomalySettings `json:"anomaly_settings" gorm:"type:jsonb"`
+
+	ReviewReminderSettings ReviewReminderSettings `json:"review_reminder_settings" gorm:"type:jsonb"`
+
+	ModelSettings KBModelSettings `json:"model_settings" gorm:"type:jsonb"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
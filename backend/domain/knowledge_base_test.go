@@ -0,0 +1,26 @@
+package domain
+
+import "testing"
+
+func TestAccessSettingsSiteURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings AccessSettings
+		want     string
+	}{
+		{name: "base url", settings: AccessSettings{BaseURL: "https://wiki.example.com/", Hosts: []string{"other.example.com"}, Ports: []int{80}}, want: "https://wiki.example.com"},
+		{name: "default http port", settings: AccessSettings{Hosts: []string{"wiki.example.com"}, Ports: []int{80}}, want: "http://wiki.example.com"},
+		{name: "other http port", settings: AccessSettings{Hosts: []string{"wiki.example.com"}, Ports: []int{8080}}, want: "http://wiki.example.com:8080"},
+		{name: "ssl preferred", settings: AccessSettings{Hosts: []string{"wiki.example.com"}, Ports: []int{80}, SSLPorts: []int{443}}, want: "https://wiki.example.com"},
+		{name: "other ssl port", settings: AccessSettings{Hosts: []string{"wiki.example.com"}, SSLPorts: []int{8443}}, want: "https://wiki.example.com:8443"},
+		{name: "wildcard host skipped", settings: AccessSettings{Hosts: []string{"*", "wiki.example.com"}, Ports: []int{80}}, want: "http://wiki.example.com"},
+		{name: "no host", settings: AccessSettings{Hosts: []string{"*"}, Ports: []int{80}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.settings.SiteURL(); got != tt.want {
+				t.Errorf("SiteURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
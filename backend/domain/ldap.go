@@ -0,0 +1,7 @@
+package domain
+
+var (
+	ErrLDAPLoginFailed  = NewError(ErrCodeUnauthorized, "invalid account or password")
+	ErrLDAPNoRole       = NewError(ErrCodeForbidden, "no role is mapped to the ldap groups of the user")
+	ErrLDAPAccountTaken = NewError(ErrCodeConflict, "account is taken by a local user")
+)
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const SettingKeyMaintenance = "maintenance"
+
+// table: settings
+type Setting struct {
+	Key       string          `json:"key" gorm:"primaryKey"`
+	Value     json.RawMessage `json:"value" gorm:"type:jsonb"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// MaintenanceSettings read-only switch, blocks admin writes while the public site and chat stay available
+type MaintenanceSettings struct {
+	ReadOnly bool   `json:"read_only"`
+	Message  string `json:"message"`
+}
+
+func (s *MaintenanceSettings) Scan(value any) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New(fmt.Sprint("invalid maintenance settings value type:", value))
+	}
+	return json.Unmarshal(bytes, s)
+}
+
+func (s MaintenanceSettings) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+type UpdateMaintenanceReq struct {
+	// empty for the global switch
+	KBID     string `json:"kb_id"`
+	ReadOnly bool   `json:"read_only"`
+	Message  string `json:"message"`
+}
+
+type MaintenanceResp struct {
+	Global MaintenanceSettings  `json:"global"`
+	KB     *MaintenanceSettings `json:"kb,omitempty"`
+}
@@ -0,0 +1,121 @@
+package domain
+
+var ErrMessageNotAnswer = NewError(ErrCodeInvalidRequest, "message is not an answer to a question")
+
+// ChatModelTemperature temperature of chat models answering questions
+const ChatModelTemperature float32 = 0
+
+// retrieval stages recorded by a trace, in order
+const (
+	// chunks of the vector dataset, by similarity
+	RetrievalStageVector = "vector"
+	// keyword matches of hybrid search
+	RetrievalStageKeyword = "keyword"
+	// vector and keyword results merged by reciprocal rank fusion
+	RetrievalStageFused = "fused"
+	// reordered and cut by the rerank stage
+	RetrievalStageReranked = "reranked"
+	// kept for the region of the visitor, the documents given to the model
+	RetrievalStageFiltered = "filtered"
+)
+
+// RetrievalTrace candidates of each retrieval stage of a question
+type RetrievalTrace struct {
+	// sent to vector and keyword search, the standalone query if the question was rewritten with the history
+	Query string `json:"query"`
+	// words and identifiers matched by keyword search, nil if hybrid search is off
+	KeywordQuery *KeywordQuery `json:"keyword_query,omitempty"`
+	Hybrid       bool          `json:"hybrid"`
+	Rerank       bool          `json:"rerank"`
+	// rerank model of raglite, empty if raglite does not rerank
+	RAGRerankModelID string            `json:"rag_rerank_model_id"`
+	Stages           []*RetrievalStage `json:"stages"`
+}
+
+type RetrievalStage struct {
+	Name  string                `json:"name"`
+	Nodes []*RetrievalStageNode `json:"nodes"`
+}
+
+type RetrievalStageNode struct {
+	NodeID string                 `json:"node_id"`
+	Name   string                 `json:"name"`
+	Chunks []*RetrievalStageChunk `json:"chunks"`
+	// scores of the rerank stage, nil before it
+	Rerank *RerankScore `json:"rerank,omitempty"`
+}
+
+type RetrievalStageChunk struct {
+	ID string `json:"id"`
+	// vector similarity, 0 for keyword matches
+	Similarity float64 `json:"similarity"`
+	Content    string  `json:"content"`
+}
+
+// Record snapshot of the nodes after the stage, nodes are changed by later stages. no-op on a nil trace
+func (t *RetrievalTrace) Record(name string, nodes []*RankedNodeChunks) {
+	if t == nil {
+		return
+	}
+	stage := &RetrievalStage{Name: name, Nodes: make([]*RetrievalStageNode, 0, len(nodes))}
+	for _, node := range nodes {
+		stageNode := &RetrievalStageNode{
+			NodeID: node.NodeID,
+			Name:   node.NodeName,
+			Chunks: make([]*RetrievalStageChunk, 0, len(node.Chunks)),
+		}
+		if node.Rerank != nil {
+			rerank := *node.Rerank
+			stageNode.Rerank = &rerank
+		}
+		for _, chunk := range node.Chunks {
+			stageNode.Chunks = append(stageNode.Chunks, &RetrievalStageChunk{
+				ID:         chunk.ID,
+				Similarity: chunk.Similarity,
+				Content:    chunk.Content,
+			})
+		}
+		stage.Nodes = append(stage.Nodes, stageNode)
+	}
+	t.Stages = append(t.Stages, stage)
+}
+
+type MessageDebugReq struct {
+	KBID      string `json:"kb_id" query:"kb_id" validate:"required"`
+	MessageID string `json:"message_id" query:"message_id" validate:"required"`
+}
+
+// MessageDebugResp replay of the retrieval and prompt of an answer with the current documents and settings
+type MessageDebugResp struct {
+	// answer as saved
+	Message  *ConversationMessage `json:"message"`
+	Question string               `json:"question"`
+	// retrieval replayed now, compare with the rerank scores and provenance saved on the answer
+	Retrieval *RetrievalTrace `json:"retrieval"`
+	// prompt of the replay, history trimmed to the token budget as in chats
+	Prompt []*PromptMessage   `json:"prompt"`
+	Model  *MessageDebugModel `json:"model"`
+	// differences of the replay from the chat
+	Notes []string `json:"notes"`
+}
+
+type PromptMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// MessageDebugModel model and parameters of the answer
+type MessageDebugModel struct {
+	Provider    ModelProvider `json:"provider"`
+	Model       string        `json:"model"`
+	ModelID     string        `json:"model_id"`
+	Failovers   int           `json:"failovers"`
+	Temperature float32       `json:"temperature"`
+	// continuation requests allowed after a truncated answer
+	MaxContinuationRounds int           `json:"max_continuation_rounds"`
+	StopSequences         []string      `json:"stop_sequences"`
+	HistoryTokenBudget    int           `json:"history_token_budget"`
+	CitationStyle         CitationStyle `json:"citation_style"`
+	// version of the prompt template of the app, 0 for the built-in prompt
+	PromptTemplateVersion int `json:"prompt_template_version"`
+}
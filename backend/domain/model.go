@@ -1,6 +1,11 @@
 package domain
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 )
 
@@ -15,17 +20,34 @@ const (
 	ModelProviderBrandAzureOpenAI ModelProvider = "AzureOpenAI"
 	ModelProviderBrandBaiZhiCloud ModelProvider = "BaiZhiCloud"
 	ModelProviderBrandHunyuan     ModelProvider = "Hunyuan"
-	ModelProviderBrandBaiLian     ModelProvider = "BaiLian"
+	ModelProviderBrandBaiLian     ModelProvider = "BaiLian" // qwen models of dashscope, by its openai compatible mode
 	ModelProviderBrandVolcengine  ModelProvider = "Volcengine"
+	ModelProviderBrandVLLM        ModelProvider = "vLLM" // self-hosted vllm server
 	ModelProviderBrandOther       ModelProvider = "Other"
 )
 
+// GenAISystem gen_ai.system of the provider in the opentelemetry semantic conventions
+func (p ModelProvider) GenAISystem() string {
+	switch p {
+	case ModelProviderBrandOpenAI:
+		return "openai"
+	case ModelProviderBrandAzureOpenAI:
+		return "az.ai.openai"
+	case ModelProviderBrandDeepSeek:
+		return "deepseek"
+	default:
+		return strings.ToLower(string(p))
+	}
+}
+
 type ModelType string
 
 const (
 	ModelTypeChat      ModelType = "chat"
 	ModelTypeEmbedding ModelType = "embedding"
 	ModelTypeRerank    ModelType = "rerank"
+	// speech recognition model transcribing imported audio and video
+	ModelTypeASR ModelType = "asr"
 )
 
 type Model struct {
@@ -36,7 +58,12 @@ type Model struct {
 	APIHeader  string        `json:"api_header"`
 	BaseURL    string        `json:"base_url"`
 	APIVersion string        `json:"api_version"` // for azure openai
-	Type       ModelType     `json:"type" gorm:"default:chat;uniqueIndex"`
+	// several models of a type may be configured, the earliest is the default of kbs without their own
+	Type ModelType `json:"type" gorm:"default:chat;index"`
+	// chat models with a priority form the failover pool, lower priorities are tried first
+	Priority int `json:"priority" gorm:"default:0"`
+	// share of requests among pooled models of the same priority
+	Weight int `json:"weight" gorm:"default:0"`
 
 	IsActive bool `json:"is_active" gorm:"default:false"`
 
@@ -57,6 +84,8 @@ type ModelListItem struct {
 	BaseURL    string        `json:"base_url"`
 	APIVersion string        `json:"api_version"` // for azure openai
 	Type       ModelType     `json:"type"`
+	Priority   int           `json:"priority"`
+	Weight     int           `json:"weight"`
 
 	PromptTokens     uint64 `json:"prompt_tokens"`
 	CompletionTokens uint64 `json:"completion_tokens"`
@@ -83,13 +112,17 @@ type CheckModelReq struct {
 }
 
 type BaseModelInfo struct {
-	Provider   ModelProvider `json:"provider" validate:"required,oneof=OpenAI Ollama DeepSeek SiliconFlow Moonshot Other AzureOpenAI BaiZhiCloud Hunyuan BaiLian Volcengine"`
+	Provider   ModelProvider `json:"provider" validate:"required,oneof=OpenAI Ollama DeepSeek SiliconFlow Moonshot Other AzureOpenAI BaiZhiCloud Hunyuan BaiLian Volcengine vLLM"`
 	Model      string        `json:"model" validate:"required"`
 	BaseURL    string        `json:"base_url" validate:"required"`
 	APIKey     string        `json:"api_key"`
 	APIHeader  string        `json:"api_header"`
 	APIVersion string        `json:"api_version"` // for azure openai
-	Type       ModelType     `json:"type" validate:"required,oneof=chat embedding rerank"`
+	Type       ModelType     `json:"type" validate:"required,oneof=chat embedding rerank asr"`
+	// failover pool of chat models, 0 keeps the model out of the pool
+	Priority int `json:"priority" validate:"min=0"`
+	// share of requests among pooled models of the same priority, 1 if 0
+	Weight int `json:"weight" validate:"min=0"`
 }
 
 type CheckModelResp struct {
@@ -97,6 +130,28 @@ type CheckModelResp struct {
 	Content string `json:"content"`
 }
 
+type CheckSavedModelReq struct {
+	ID string `json:"id" query:"id" validate:"required"`
+}
+
+type CheckKBModelsReq struct {
+	KBID string `json:"kb_id" query:"kb_id" validate:"required"`
+}
+
+// CheckKBModelsResp connectivity of the models a kb uses, nil if the kb has no model of the type
+type CheckKBModelsResp struct {
+	Chat      *KBModelCheck `json:"chat"`
+	Embedding *KBModelCheck `json:"embedding"`
+	Rerank    *KBModelCheck `json:"rerank"`
+}
+
+type KBModelCheck struct {
+	ModelID  string        `json:"model_id"`
+	Model    string        `json:"model"`
+	Provider ModelProvider `json:"provider"`
+	CheckModelResp
+}
+
 var ModelProviderBrandModelsList = map[ModelProvider][]ProviderModelListItem{
 	ModelProviderBrandOpenAI: {
 		{Model: "gpt-4o"},
@@ -135,11 +190,11 @@ var ModelProviderBrandModelsList = map[ModelProvider][]ProviderModelListItem{
 }
 
 type GetProviderModelListReq struct {
-	Provider  string    `json:"provider" query:"provider" validate:"required,oneof=SiliconFlow OpenAI Ollama DeepSeek Moonshot AzureOpenAI BaiZhiCloud Hunyuan BaiLian Volcengine"`
+	Provider  string    `json:"provider" query:"provider" validate:"required,oneof=SiliconFlow OpenAI Ollama DeepSeek Moonshot AzureOpenAI BaiZhiCloud Hunyuan BaiLian Volcengine vLLM Other"`
 	BaseURL   string    `json:"base_url" query:"base_url" validate:"required"`
 	APIKey    string    `json:"api_key" query:"api_key"`
 	APIHeader string    `json:"api_header" query:"api_header"`
-	Type      ModelType `json:"type" query:"type" validate:"required,oneof=chat embedding rerank"`
+	Type      ModelType `json:"type" query:"type" validate:"required,oneof=chat embedding rerank asr"`
 }
 
 type GetProviderModelListResp struct {
@@ -153,3 +208,50 @@ type ProviderModelListItem struct {
 type ActivateModelReq struct {
 	ModelID string `json:"model_id" validate:"required"`
 }
+
+// ModelFirstChunkTimeout a chat model not streaming within it is skipped for the next model of the failover pool
+const ModelFirstChunkTimeout = 30 * time.Second
+
+// EffectiveWeight weight of the model in load balancing, 1 if not set
+func (m *Model) EffectiveWeight() int {
+	if m.Weight > 0 {
+		return m.Weight
+	}
+	return 1
+}
+
+// KBModelSettings models of the kb, the default model of the type is used if empty or deleted
+type KBModelSettings struct {
+	ChatModelID string `json:"chat_model_id"`
+	// documents are embedded again after the embedding model is changed
+	EmbeddingModelID string `json:"embedding_model_id"`
+	RerankModelID    string `json:"rerank_model_id"`
+	// chat model rewriting follow-up questions into standalone queries, the chat model of the kb if empty
+	RewriteModelID string `json:"rewrite_model_id"`
+}
+
+func (s *KBModelSettings) Scan(value any) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New(fmt.Sprint("invalid model settings value type:", value))
+	}
+	return json.Unmarshal(bytes, s)
+}
+
+func (s KBModelSettings) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+// ModelID model of the type selected for the kb, empty for the default
+func (s KBModelSettings) ModelID(modelType ModelType) string {
+	switch modelType {
+	case ModelTypeChat:
+		return s.ChatModelID
+	case ModelTypeEmbedding:
+		return s.EmbeddingModelID
+	case ModelTypeRerank:
+		return s.RerankModelID
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,163 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type BudgetExceededAction string
+
+const (
+	BudgetExceededActionDisable  BudgetExceededAction = "disable"
+	BudgetExceededActionFallback BudgetExceededAction = "fallback"
+)
+
+const DefaultBudgetExceededMessage = "本月模型用量已达上限，智能问答暂时不可用，请稍后再试或联系管理员。"
+
+// table: model_budgets
+type ModelBudget struct {
+	ModelID string `json:"model_id" gorm:"primaryKey" validate:"required"`
+	// spend limit per month, 0 means unlimited
+	MonthlyBudget float64 `json:"monthly_budget" validate:"min=0"`
+	// token limit per month, 0 means unlimited
+	MonthlyTokenLimit int64 `json:"monthly_token_limit" validate:"min=0"`
+	// price per 1M tokens
+	PromptPrice     float64 `json:"prompt_price" validate:"min=0"`
+	CompletionPrice float64 `json:"completion_price" validate:"min=0"`
+	// usage percentages to notify, e.g. [50, 80, 100]
+	NotifyThresholds IntList       `json:"notify_thresholds" gorm:"type:jsonb"`
+	NotifyWebhook    NotifyWebhook `json:"notify_webhook" gorm:"type:jsonb"`
+
+	ExceededAction  BudgetExceededAction `json:"exceeded_action" validate:"required,oneof=disable fallback"`
+	ExceededMessage string               `json:"exceeded_message"`
+	// cheaper model used when exceeded action is fallback
+	FallbackModel FallbackModel `json:"fallback_model" gorm:"type:jsonb"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Cost of the usage with the budget prices
+func (b *ModelBudget) Cost(promptTokens, completionTokens int64) float64 {
+	return (float64(promptTokens)*b.PromptPrice + float64(completionTokens)*b.CompletionPrice) / 1_000_000
+}
+
+// UsagePercent the larger usage percentage of spend and tokens
+func (b *ModelBudget) UsagePercent(usage *ModelUsageMonthly) float64 {
+	percent := 0.0
+	if b.MonthlyBudget > 0 {
+		percent = max(percent, usage.Cost/b.MonthlyBudget*100)
+	}
+	if b.MonthlyTokenLimit > 0 {
+		percent = max(percent, float64(usage.TotalTokens)/float64(b.MonthlyTokenLimit)*100)
+	}
+	return percent
+}
+
+type NotifyWebhookType string
+
+const (
+	NotifyWebhookTypeDingTalk NotifyWebhookType = "dingtalk"
+	NotifyWebhookTypeFeishu   NotifyWebhookType = "feishu"
+)
+
+type NotifyWebhook struct {
+	Type   NotifyWebhookType `json:"type,omitempty"`
+	URL    string            `json:"url,omitempty"`
+	Secret string            `json:"secret,omitempty"`
+}
+
+func (s *NotifyWebhook) Scan(value any) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New(fmt.Sprint("invalid notify webhook value type:", value))
+	}
+	return json.Unmarshal(bytes, s)
+}
+
+func (s NotifyWebhook) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+type FallbackModel struct {
+	Provider   ModelProvider `json:"provider,omitempty"`
+	Model      string        `json:"model,omitempty"`
+	BaseURL    string        `json:"base_url,omitempty"`
+	APIKey     string        `json:"api_key,omitempty"`
+	APIHeader  string        `json:"api_header,omitempty"`
+	APIVersion string        `json:"api_version,omitempty"`
+	// price per 1M tokens, spend of the fallback model is recorded apart from the budget model
+	PromptPrice     float64 `json:"prompt_price,omitempty" validate:"min=0"`
+	CompletionPrice float64 `json:"completion_price,omitempty" validate:"min=0"`
+}
+
+// Cost of the usage with the fallback model prices
+func (s *FallbackModel) Cost(promptTokens, completionTokens int64) float64 {
+	return (float64(promptTokens)*s.PromptPrice + float64(completionTokens)*s.CompletionPrice) / 1_000_000
+}
+
+func (s *FallbackModel) Scan(value any) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New(fmt.Sprint("invalid fallback model value type:", value))
+	}
+	return json.Unmarshal(bytes, s)
+}
+
+func (s FallbackModel) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+type IntList []int
+
+func (s *IntList) Scan(value any) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New(fmt.Sprint("invalid int list value type:", value))
+	}
+	return json.Unmarshal(bytes, s)
+}
+
+func (s IntList) Value() (driver.Value, error) {
+	if s == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]int(s))
+}
+
+// table: model_usage_monthly
+type ModelUsageMonthly struct {
+	ModelID           string    `json:"model_id" gorm:"primaryKey"`
+	Month             time.Time `json:"month" gorm:"primaryKey;type:date"`
+	PromptTokens      int64     `json:"prompt_tokens"`
+	CompletionTokens  int64     `json:"completion_tokens"`
+	TotalTokens       int64     `json:"total_tokens"`
+	Cost              float64   `json:"cost"`
+	NotifiedThreshold int       `json:"notified_threshold"`
+}
+
+func (ModelUsageMonthly) TableName() string {
+	return "model_usage_monthly"
+}
+
+type GetModelSpendReq struct {
+	Month string `json:"month" query:"month" validate:"omitempty,datetime=2006-01"` // default: current month
+}
+
+type ModelSpendResp struct {
+	ModelID           string    `json:"model_id"`
+	Model             string    `json:"model"`
+	Provider          string    `json:"provider"`
+	Month             time.Time `json:"month"`
+	PromptTokens      int64     `json:"prompt_tokens"`
+	CompletionTokens  int64     `json:"completion_tokens"`
+	TotalTokens       int64     `json:"total_tokens"`
+	Cost              float64   `json:"cost"`
+	MonthlyBudget     float64   `json:"monthly_budget"`
+	MonthlyTokenLimit int64     `json:"monthly_token_limit"`
+	UsagePercent      float64   `json:"usage_percent"`
+	Exceeded          bool      `json:"exceeded"`
+}
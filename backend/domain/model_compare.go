@@ -0,0 +1,54 @@
+package domain
+
+import "time"
+
+// ModelCompareTimeout answers of compared models taking longer are cut off
+const ModelCompareTimeout = 2 * time.Minute
+
+// CompareModelsReq answer one question with 2 or 3 models over the same retrieved documents
+type CompareModelsReq struct {
+	KBID     string `json:"kb_id" validate:"required"`
+	Question string `json:"question" validate:"required"`
+	// citation style of the prompt, inline by default
+	CitationStyle CitationStyle   `json:"citation_style" validate:"omitempty,oneof=inline footnote cards none"`
+	Models        []*CompareModel `json:"models" validate:"required,min=2,max=3,dive,required"`
+}
+
+// CompareModel configured chat model by its id, the fallback model of its budget by the id with :fallback suffix,
+// or a candidate model by its connection
+type CompareModel struct {
+	ModelID    string        `json:"model_id"`
+	Provider   ModelProvider `json:"provider" validate:"required_without=ModelID"`
+	Model      string        `json:"model" validate:"required_without=ModelID"`
+	BaseURL    string        `json:"base_url" validate:"required_without=ModelID"`
+	APIKey     string        `json:"api_key"`
+	APIHeader  string        `json:"api_header"`
+	APIVersion string        `json:"api_version"`
+	// price per 1M tokens, prices of the budget of configured models if 0
+	PromptPrice     float64 `json:"prompt_price" validate:"min=0"`
+	CompletionPrice float64 `json:"completion_price" validate:"min=0"`
+}
+
+type CompareModelResult struct {
+	ModelID  string        `json:"model_id"`
+	Provider ModelProvider `json:"provider"`
+	Model    string        `json:"model"`
+	Answer   string        `json:"answer"`
+	// answer until the model failed
+	Error string `json:"error,omitempty"`
+	// time to the first token and to the whole answer
+	FirstTokenMS     int64   `json:"first_token_ms"`
+	LatencyMS        int64   `json:"latency_ms"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	Cost             float64 `json:"cost"`
+}
+
+type CompareModelsResp struct {
+	Question string `json:"question"`
+	// documents retrieved once and given to all models
+	Documents []*NodeCotentChunkSSE `json:"documents"`
+	// results in the order of the requested models
+	Results []*CompareModelResult `json:"results"`
+}
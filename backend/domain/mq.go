@@ -3,10 +3,31 @@ package domain
 const (
 	// Vector topic (unidirectional)
 	VectorTaskTopic = "apps.panda-wiki.vector.task"
+	// Stat event topic, consumed by stat sink
+	StatEventTopic = "apps.panda-wiki.stat.event"
+	// Bulk find and replace job topic
+	NodeReplaceTopic = "apps.panda-wiki.node.replace"
+	// Export kb to markdown archive job topic
+	NodeExportTopic = "apps.panda-wiki.node.export"
+	// Export data of kb or end user for compliance job topic
+	DataExportTopic = "apps.panda-wiki.data.export"
+	// Re-score recent answers with candidate answer settings job topic
+	ConversationRescoreTopic = "apps.panda-wiki.conversation.rescore"
+	// Answer and judge the golden questions of an eval set job topic
+	EvalRunTopic = "apps.panda-wiki.eval.run"
+	// Webhook event topic, delivered to webhooks of the kb
+	WebhookEventTopic = "apps.panda-wiki.webhook.event"
 )
 
 var TopicConsumerName = map[string]string{
-	VectorTaskTopic: "panda-wiki-vector-consumer",
+	VectorTaskTopic:          "panda-wiki-vector-consumer",
+	StatEventTopic:           "panda-wiki-stat-sink-consumer",
+	NodeReplaceTopic:         "panda-wiki-node-replace-consumer",
+	WebhookEventTopic:        "panda-wiki-webhook-consumer",
+	NodeExportTopic:          "panda-wiki-node-export-consumer",
+	DataExportTopic:          "panda-wiki-data-export-consumer",
+	ConversationRescoreTopic: "panda-wiki-conversation-rescore-consumer",
+	EvalRunTopic:             "panda-wiki-eval-run-consumer",
 }
 
 type NodeReleaseVectorRequest struct {
@@ -14,5 +35,5 @@ type NodeReleaseVectorRequest struct {
 	NodeReleaseID string `json:"node_release_id"`
 	NodeID        string `json:"node_id"`
 	DocID         string `json:"doc_id"` // for delete
-	Action        string `json:"action"` // upsert, delete, summary
+	Action        string `json:"action"` // upsert, delete, summary, rebuild
 }
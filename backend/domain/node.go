@@ -48,18 +48,31 @@ type Node struct {
 	Content string   `json:"content"`
 	Meta    NodeMeta `json:"meta" gorm:"type:jsonb"` // summary
 
+	// identical documents are found by it on import
+	ContentHash string `json:"-"`
+
 	ParentID string  `json:"parent_id"`
 	Position float64 `json:"position"`
 
 	DocID string `json:"doc_id"` // DEPRECATED: for rag service
 
+	// folder defaults of new child nodes
+	Defaults        NodeDefaults    `json:"defaults" gorm:"type:jsonb"`
+	InheritedFields InheritedFields `json:"inherited_fields" gorm:"type:jsonb"`
+
+	// last time a maintainer confirmed the content is still accurate, nil if never reviewed
+	ReviewedAt *time.Time `json:"reviewed_at"`
+	ReviewedBy string     `json:"reviewed_by"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
 type NodeMeta struct {
-	Summary string `json:"summary"`
-	Emoji   string `json:"emoji"`
+	Summary string   `json:"summary"`
+	Emoji   string   `json:"emoji"`
+	Tags    []string `json:"tags,omitempty"`
+	SEO     *NodeSEO `json:"seo,omitempty"`
 }
 
 func (d *NodeMeta) Value() (driver.Value, error) {
@@ -81,9 +94,16 @@ type CreateNodeReq struct {
 
 	Name    string `json:"name" validate:"required"`
 	Content string `json:"content"`
+	// link to this existing identical node instead of duplicating its content
+	LinkToNodeID string `json:"link_to_node_id"`
 
 	Emoji      string          `json:"emoji"`
 	Visibility *NodeVisibility `json:"visibility"`
+	Tags       []string        `json:"tags"`
+	SEO        *NodeSEO        `json:"seo"`
+
+	// fields filled from folder defaults
+	InheritedFields InheritedFields `json:"-"`
 }
 
 type GetNodeListReq struct {
@@ -118,6 +138,12 @@ type NodeDetailResp struct {
 
 	ParentID string `json:"parent_id"`
 
+	Defaults        NodeDefaults    `json:"defaults"`
+	InheritedFields InheritedFields `json:"inherited_fields"`
+
+	ReviewedAt *time.Time `json:"reviewed_at"`
+	ReviewedBy string     `json:"reviewed_by"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -130,13 +156,19 @@ type NodeContentChunk struct {
 	Seq     uint   `json:"seq"`
 	Name    string `json:"name"`
 	Content string `json:"content"`
+
+	Similarity float64 `json:"similarity"`
 }
 
 type RankedNodeChunks struct {
 	NodeID      string
 	NodeName    string
 	NodeSummary string
-	Chunks      []*NodeContentChunk
+	// tags of the node, region variants are selected by them
+	Tags   []string
+	Chunks []*NodeContentChunk
+	// scores of the rerank stage, nil if the kb does not rerank
+	Rerank *RerankScore
 }
 
 func (n *RankedNodeChunks) GetURL(baseURL string) string {
@@ -181,6 +213,8 @@ type UpdateNodeReq struct {
 	Emoji      *string         `json:"emoji"`
 	Visibility *NodeVisibility `json:"visibility"`
 	Summary    *string         `json:"summary"`
+	Tags       *[]string       `json:"tags"`
+	SEO        *NodeSEO        `json:"seo"`
 }
 
 type ShareNodeListItemResp struct {
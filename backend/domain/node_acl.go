@@ -0,0 +1,175 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// ContextKeyViewer set on the echo context by the share auth middleware, the viewer of the public site
+const ContextKeyViewer = "viewer"
+
+// NodeAccess audience of a published document or folder
+type NodeAccess string
+
+const (
+	NodeAccessPublic   NodeAccess = "public"
+	NodeAccessLoggedIn NodeAccess = "logged_in"
+	NodeAccessGroups   NodeAccess = "groups"
+)
+
+type NodeACLGroups []string
+
+func (g *NodeACLGroups) Scan(value any) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New(fmt.Sprint("invalid node acl groups value type:", value))
+	}
+	return json.Unmarshal(bytes, g)
+}
+
+func (g NodeACLGroups) Value() (driver.Value, error) {
+	if g == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]string(g))
+}
+
+// table: node_acls, nodes without a rule are public
+type NodeACL struct {
+	NodeID string     `json:"node_id" gorm:"primaryKey"`
+	KBID   string     `json:"kb_id"`
+	Access NodeAccess `json:"access"`
+	// groups allowed to view the node, for groups access
+	Groups NodeACLGroups `json:"groups" gorm:"type:jsonb"`
+	// the rule also covers all descendants of the node, which must pass it besides their own rules
+	Subtree   bool      `json:"subtree"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (NodeACL) TableName() string {
+	return "node_acls"
+}
+
+// Allows whether the viewer passes the rule, anonymous viewers only pass public rules
+func (a *NodeACL) Allows(viewer *NodeViewer) bool {
+	switch a.Access {
+	case NodeAccessPublic:
+		return true
+	case NodeAccessLoggedIn:
+		return viewer.LoggedIn()
+	case NodeAccessGroups:
+		return viewer.LoggedIn() && slices.ContainsFunc(viewer.Groups, func(group string) bool {
+			return slices.Contains(a.Groups, group)
+		})
+	}
+	return false
+}
+
+type SetNodeACLReq struct {
+	KBID    string     `json:"kb_id" validate:"required"`
+	NodeIDs []string   `json:"node_ids" validate:"required,min=1"`
+	Access  NodeAccess `json:"access" validate:"required,oneof=public logged_in groups"`
+	// required for groups access
+	Groups  []string `json:"groups" validate:"max=50,dive,required,max=100"`
+	Subtree bool     `json:"subtree"`
+}
+
+type NodeACLListReq struct {
+	KBID string `json:"kb_id" query:"kb_id" validate:"required"`
+}
+
+type NodeACLListItem struct {
+	NodeACL
+	NodeName string   `json:"node_name"`
+	NodeType NodeType `json:"node_type"`
+}
+
+// ViewerAuth verify viewer tokens of the public site, signed by the sso portal of the kb with HS256.
+// tokens carry the user id as sub and the groups of the user as groups. every viewer is anonymous if the secret is empty
+type ViewerAuth struct {
+	Secret string `json:"secret"`
+}
+
+// NodeViewer viewer of the public site or chat, anonymous without user id
+type NodeViewer struct {
+	UserID string   `json:"user_id"`
+	Groups []string `json:"groups"`
+}
+
+func (v *NodeViewer) LoggedIn() bool {
+	return v != nil && v.UserID != ""
+}
+
+// NodeACLFilter nodes hidden from a viewer, a nil filter hides nothing
+type NodeACLFilter struct {
+	denied map[string]bool
+	// every node is hidden from viewers who may not read a private kb
+	all bool
+}
+
+// NewDenyAllNodeACLFilter filter hiding every node of a kb the viewer may not read
+func NewDenyAllNodeACLFilter() *NodeACLFilter {
+	return &NodeACLFilter{all: true}
+}
+
+// NewNodeACLFilter hide the nodes with rules the viewer fails, and the descendants of those covering their subtree.
+// parents maps node ids to their parent ids, a node moved since it was published has both parents
+func NewNodeACLFilter(acls []*NodeACL, parents map[string][]string, viewer *NodeViewer) *NodeACLFilter {
+	denied := make(map[string]bool)
+	subtrees := make([]string, 0)
+	for _, acl := range acls {
+		if acl.Allows(viewer) {
+			continue
+		}
+		denied[acl.NodeID] = true
+		if acl.Subtree {
+			subtrees = append(subtrees, acl.NodeID)
+		}
+	}
+	if len(denied) == 0 {
+		return nil
+	}
+	if len(subtrees) > 0 {
+		children := make(map[string][]string)
+		for id, parentIDs := range parents {
+			for _, parentID := range parentIDs {
+				children[parentID] = append(children[parentID], id)
+			}
+		}
+		visited := make(map[string]bool)
+		for len(subtrees) > 0 {
+			id := subtrees[len(subtrees)-1]
+			subtrees = subtrees[:len(subtrees)-1]
+			if visited[id] {
+				continue
+			}
+			visited[id] = true
+			denied[id] = true
+			subtrees = append(subtrees, children[id]...)
+		}
+	}
+	return &NodeACLFilter{denied: denied}
+}
+
+func (f *NodeACLFilter) Allows(nodeID string) bool {
+	return f == nil || !f.all && !f.denied[nodeID]
+}
+
+// FilterNodes drop retrieved documents hidden from the viewer
+func (f *NodeACLFilter) FilterNodes(nodes []*RankedNodeChunks) []*RankedNodeChunks {
+	if f == nil {
+		return nodes
+	}
+	allowed := make([]*RankedNodeChunks, 0, len(nodes))
+	for _, node := range nodes {
+		if f.Allows(node.NodeID) {
+			allowed = append(allowed, node)
+		}
+	}
+	return allowed
+}
@@ -0,0 +1,41 @@
+package domain
+
+import "time"
+
+var (
+	ErrNodeAttachmentNotFound = NewError(ErrCodeNotFound, "node attachment not found")
+	ErrNodeAttachmentTooLarge = NewError(ErrCodeInvalidRequest, "attachment size too large")
+)
+
+// AttachmentBucket private bucket of node attachments, objects are served by signed urls only
+const AttachmentBucket = "node-attachment"
+
+// AttachmentURLExpires lifetime of attachment signed urls
+const AttachmentURLExpires = time.Hour
+
+type NodeAttachment struct {
+	ID          string    `json:"id" gorm:"primaryKey"`
+	KBID        string    `json:"kb_id"`
+	NodeID      string    `json:"node_id"`
+	Name        string    `json:"name"`
+	Key         string    `json:"-"`
+	Size        int64     `json:"size"`
+	ContentType string    `json:"content_type"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type NodeAttachmentListReq struct {
+	KBID   string `json:"kb_id" query:"kb_id" validate:"required"`
+	NodeID string `json:"node_id" query:"node_id" validate:"required"`
+}
+
+type NodeAttachmentListItem struct {
+	*NodeAttachment
+	// signed download url
+	URL string `json:"url"`
+}
+
+type DeleteNodeAttachmentReq struct {
+	KBID string `json:"kb_id" query:"kb_id" validate:"required"`
+	ID   string `json:"id" query:"id" validate:"required"`
+}
@@ -0,0 +1,152 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	ErrCommentDisabled       = NewError(ErrCodeForbidden, "comments are disabled")
+	ErrCommentParentNotFound = NewError(ErrCodeNotFound, "comment to reply is not found")
+	ErrCommentRateLimited    = NewError(ErrCodeRateLimited, "too many comments, please try again later")
+)
+
+const (
+	// CommentLimitPerMinute comments of an ip over this in a minute are rejected
+	CommentLimitPerMinute = 3
+	// CommentMaxLinks comments with more links than this are spam
+	CommentMaxLinks = 2
+)
+
+type NodeCommentStatus string
+
+const (
+	NodeCommentStatusPending  NodeCommentStatus = "pending"
+	NodeCommentStatusApproved NodeCommentStatus = "approved"
+	NodeCommentStatusRejected NodeCommentStatus = "rejected"
+	NodeCommentStatusSpam     NodeCommentStatus = "spam"
+)
+
+// CommentSettings reader comments on published nodes of the kb
+type CommentSettings struct {
+	Enabled bool `json:"enabled"`
+	// comments are pending until approved in the moderation queue
+	RequireModeration bool `json:"require_moderation"`
+	// comments containing any of the words are spam
+	BlockedWords []string `json:"blocked_words"`
+}
+
+func (s *CommentSettings) Scan(value any) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New(fmt.Sprint("invalid comment settings value type:", value))
+	}
+	return json.Unmarshal(bytes, s)
+}
+
+func (s CommentSettings) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+var commentLinkRegexp = regexp.MustCompile(`(?i)https?://|www\.`)
+
+// SpamReason reason the comment is spam, empty if not spam
+func (s CommentSettings) SpamReason(content string) string {
+	lower := strings.ToLower(content)
+	for _, word := range s.BlockedWords {
+		word = strings.TrimSpace(word)
+		if word != "" && strings.Contains(lower, strings.ToLower(word)) {
+			return "blocked word: " + word
+		}
+	}
+	if len(commentLinkRegexp.FindAllStringIndex(content, -1)) > CommentMaxLinks {
+		return "too many links"
+	}
+	return ""
+}
+
+// table: node_comments
+type NodeComment struct {
+	ID     string `json:"id" gorm:"primaryKey"`
+	KBID   string `json:"kb_id"`
+	NodeID string `json:"node_id"`
+	// replied comment, root_id is the first comment of the thread
+	ParentID string `json:"parent_id"`
+	RootID   string `json:"root_id"`
+
+	NickName string `json:"nick_name"`
+	// set for replies of admins
+	UserID  string `json:"user_id"`
+	Content string `json:"content"`
+
+	Status      NodeCommentStatus `json:"status"`
+	SpamReason  string            `json:"spam_reason"`
+	RemoteIP    string            `json:"remote_ip"`
+	ModeratedBy string            `json:"moderated_by"`
+	ModeratedAt *time.Time        `json:"moderated_at"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ShareNodeComment approved comment shown on the public site
+type ShareNodeComment struct {
+	ID        string    `json:"id"`
+	ParentID  string    `json:"parent_id"`
+	NickName  string    `json:"nick_name"`
+	Content   string    `json:"content"`
+	IsStaff   bool      `json:"is_staff"`
+	CreatedAt time.Time `json:"created_at"`
+
+	Replies []*ShareNodeComment `json:"replies,omitempty"`
+}
+
+type CreateNodeCommentReq struct {
+	NodeID   string `json:"node_id" validate:"required"`
+	ParentID string `json:"parent_id"`
+	NickName string `json:"nick_name" validate:"max=50"`
+	Content  string `json:"content" validate:"required,max=2000"`
+
+	KBID      string `json:"-"`
+	RemoteIP  string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+type CreateNodeCommentResp struct {
+	ID     string            `json:"id"`
+	Status NodeCommentStatus `json:"status"`
+}
+
+type NodeCommentListReq struct {
+	KBID   string            `json:"kb_id" query:"kb_id" validate:"required"`
+	NodeID string            `json:"node_id" query:"node_id"`
+	Status NodeCommentStatus `json:"status" query:"status" validate:"omitempty,oneof=pending approved rejected spam"`
+
+	Pager
+}
+
+type NodeCommentListItem struct {
+	*NodeComment
+	NodeName string `json:"node_name"`
+}
+
+type ModerateNodeCommentsReq struct {
+	KBID   string            `json:"kb_id" validate:"required"`
+	IDs    []string          `json:"ids" validate:"required,min=1"`
+	Status NodeCommentStatus `json:"status" validate:"required,oneof=approved rejected spam"`
+}
+
+type ReplyNodeCommentReq struct {
+	KBID     string `json:"kb_id" validate:"required"`
+	ParentID string `json:"parent_id" validate:"required"`
+	Content  string `json:"content" validate:"required,max=2000"`
+}
+
+type DeleteNodeCommentReq struct {
+	KBID string `json:"kb_id" query:"kb_id" validate:"required"`
+	ID   string `json:"id" query:"id" validate:"required"`
+}
@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"strings"
+)
+
+// ContentHash hash of content ignoring case and whitespace, empty for empty content.
+// must match the backfill of migration 000037_add_node_content_hash
+func ContentHash(content string) string {
+	normalized := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '\n', '\r', '\f', '\v':
+			return -1
+		}
+		return r
+	}, strings.ToLower(content))
+	if normalized == "" {
+		return ""
+	}
+	sum := md5.Sum([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// LinkedNodeContent content of a node created instead of a duplicate, it links to the existing node
+func LinkedNodeContent(name, url string) string {
+	return fmt.Sprintf(`<p>本文档与 <a href="%s">%s</a> 内容相同，请查看原文档。</p>`, html.EscapeString(url), html.EscapeString(name))
+}
+
+type NodeDuplicateCheckItem struct {
+	// key of the item in the import, e.g. source url, returned with its duplicates
+	Key     string `json:"key" validate:"required"`
+	Content string `json:"content"`
+}
+
+// NodeDuplicateCheckReq find existing documents identical to documents about to be imported into the kb
+type NodeDuplicateCheckReq struct {
+	KBID  string                   `json:"kb_id" validate:"required"`
+	Items []NodeDuplicateCheckItem `json:"items" validate:"required,min=1,max=500,dive"`
+}
+
+// NodeDuplicate existing document with the same content, in the kb or another kb
+type NodeDuplicate struct {
+	KBID        string `json:"kb_id"`
+	KBName      string `json:"kb_name"`
+	NodeID      string `json:"node_id"`
+	NodeName    string `json:"node_name"`
+	SameKB      bool   `json:"same_kb" gorm:"-"`
+	URL         string `json:"url" gorm:"-"`
+	BaseURL     string `json:"-"`
+	ContentHash string `json:"-"`
+}
+
+type NodeDuplicateCheckResult struct {
+	Key        string           `json:"key"`
+	Duplicates []*NodeDuplicate `json:"duplicates"`
+}
+
+type NodeDuplicateCheckResp struct {
+	// only items with duplicates are returned
+	Items []*NodeDuplicateCheckResult `json:"items"`
+}
@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// fields of nodes inherited from folder defaults
+const (
+	NodeFieldVisibility = "visibility"
+	NodeFieldTags       = "tags"
+	NodeFieldSEO        = "seo"
+)
+
+type NodeSEO struct {
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Keywords    string `json:"keywords,omitempty"`
+}
+
+// NodeDefaults folder level settings of new child nodes, empty fields are inherited from upper folders
+type NodeDefaults struct {
+	Visibility *NodeVisibility `json:"visibility,omitempty" validate:"omitempty,oneof=1 2"`
+	// initial content of new documents
+	Template string   `json:"template,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	SEO      *NodeSEO `json:"seo,omitempty"`
+}
+
+func (d *NodeDefaults) Scan(value any) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New(fmt.Sprint("invalid node defaults value type:", value))
+	}
+	return json.Unmarshal(bytes, d)
+}
+
+func (d NodeDefaults) Value() (driver.Value, error) {
+	return json.Marshal(d)
+}
+
+// Merge override defaults with non-empty fields of the nearer folder
+func (d NodeDefaults) Merge(nearer NodeDefaults) NodeDefaults {
+	if nearer.Visibility != nil {
+		d.Visibility = nearer.Visibility
+	}
+	if nearer.Template != "" {
+		d.Template = nearer.Template
+	}
+	if nearer.Tags != nil {
+		d.Tags = nearer.Tags
+	}
+	if nearer.SEO != nil {
+		d.SEO = nearer.SEO
+	}
+	return d
+}
+
+// InheritedFields fields of node still following folder defaults, removed once overridden
+type InheritedFields []string
+
+func (f *InheritedFields) Scan(value any) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New(fmt.Sprint("invalid inherited fields value type:", value))
+	}
+	return json.Unmarshal(bytes, f)
+}
+
+func (f InheritedFields) Value() (driver.Value, error) {
+	if f == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]string(f))
+}
+
+func (f InheritedFields) Has(field string) bool {
+	return slices.Contains(f, field)
+}
+
+func (f InheritedFields) Without(fields ...string) InheritedFields {
+	return slices.DeleteFunc(slices.Clone(f), func(field string) bool {
+		return slices.Contains(fields, field)
+	})
+}
+
+type UpdateNodeDefaultsReq struct {
+	ID       string       `json:"id" validate:"required"`
+	KBID     string       `json:"kb_id" validate:"required"`
+	Defaults NodeDefaults `json:"defaults"`
+	// re-apply defaults to child nodes which have not overridden inherited fields
+	ApplyToChildren bool `json:"apply_to_children"`
+}
+
+type NodeDefaultsResp struct {
+	// defaults set on the folder
+	Defaults NodeDefaults `json:"defaults"`
+	// defaults inherited from upper folders merged with the folder defaults
+	Effective NodeDefaults `json:"effective"`
+}
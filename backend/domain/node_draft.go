@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+type PublishNodeReq struct {
+	KBID    string   `json:"kb_id" validate:"required"`
+	NodeIDs []string `json:"node_ids" validate:"required,min=1"`
+	// release message, generated if empty
+	Message string `json:"message"`
+}
+
+type DiscardNodeDraftReq struct {
+	KBID string `json:"kb_id" validate:"required"`
+	ID   string `json:"id" validate:"required"`
+}
+
+// PublishedNodeResp content of node served by the public site and rag index
+type PublishedNodeResp struct {
+	ID         string         `json:"id"`
+	NodeID     string         `json:"node_id"`
+	Visibility NodeVisibility `json:"visibility"`
+	Name       string         `json:"name"`
+	Content    string         `json:"content"`
+	Meta       NodeMeta       `json:"meta"`
+	// whether the node has changes not published yet
+	HasDraft    bool      `json:"has_draft"`
+	PublishedAt time.Time `json:"published_at"`
+}
@@ -0,0 +1,183 @@
+package domain
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NodeExportAttachmentDir directory of attachments in export archives, by node id
+const NodeExportAttachmentDir = "_attachments"
+
+type NodeExportJobStatus string
+
+const (
+	NodeExportJobStatusPending   NodeExportJobStatus = "pending"
+	NodeExportJobStatusRunning   NodeExportJobStatus = "running"
+	NodeExportJobStatusSucceeded NodeExportJobStatus = "succeeded"
+	NodeExportJobStatusFailed    NodeExportJobStatus = "failed"
+)
+
+type NodeExportReq struct {
+	KBID string `json:"kb_id" validate:"required"`
+}
+
+// table: node_export_jobs
+type NodeExportJob struct {
+	ID              string              `json:"id" gorm:"primaryKey"`
+	KBID            string              `json:"kb_id"`
+	Status          NodeExportJobStatus `json:"status"`
+	NodeCount       int                 `json:"node_count"`
+	AttachmentCount int                 `json:"attachment_count"`
+	// archive size in bytes
+	Size  int64  `json:"size"`
+	Key   string `json:"-"`
+	Error string `json:"error"`
+	// signed download url of the archive, set when succeeded
+	URL       string    `json:"url" gorm:"-"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (NodeExportJob) TableName() string {
+	return "node_export_jobs"
+}
+
+// ArchiveName file name of the archive when downloaded
+func (j *NodeExportJob) ArchiveName() string {
+	return fmt.Sprintf("%s-%s.zip", j.KBID, j.CreatedAt.Format("20060102150405"))
+}
+
+type NodeExportJobListReq struct {
+	KBID string `json:"kb_id" query:"kb_id" validate:"required"`
+
+	Pager
+}
+
+type NodeExportJobReq struct {
+	KBID  string `json:"kb_id" query:"kb_id" validate:"required"`
+	JobID string `json:"job_id" query:"job_id" validate:"required"`
+}
+
+// NodeExportJobRequest mq message of export job
+type NodeExportJobRequest struct {
+	JobID string `json:"job_id"`
+}
+
+// NodeExportPaths archive path of each node by id: folders are directories, documents are markdown files.
+// siblings with the same name get a numbered suffix, nodes with a missing parent are put at the root
+func NodeExportPaths(nodes []*Node) map[string]string {
+	children := make(map[string][]*Node)
+	byID := make(map[string]*Node, len(nodes))
+	for _, node := range nodes {
+		byID[node.ID] = node
+	}
+	for _, node := range nodes {
+		parentID := node.ParentID
+		if _, ok := byID[parentID]; !ok || parentID == node.ID {
+			parentID = ""
+		}
+		children[parentID] = append(children[parentID], node)
+	}
+	paths := make(map[string]string, len(nodes))
+	usedNames := make(map[string]map[string]bool)
+	var walk func(parentID, dir string)
+	walk = func(parentID, dir string) {
+		siblings := children[parentID]
+		sort.SliceStable(siblings, func(i, j int) bool {
+			return siblings[i].Position < siblings[j].Position
+		})
+		used, ok := usedNames[dir]
+		if !ok {
+			used = make(map[string]bool, len(siblings))
+			usedNames[dir] = used
+		}
+		for _, node := range siblings {
+			if _, ok := paths[node.ID]; ok {
+				continue
+			}
+			ext := ""
+			if node.Type == NodeTypeDocument {
+				ext = ".md"
+			}
+			base := exportFileName(node.Name)
+			name := base + ext
+			for i := 2; used[strings.ToLower(name)]; i++ {
+				name = fmt.Sprintf("%s (%d)%s", base, i, ext)
+			}
+			used[strings.ToLower(name)] = true
+			paths[node.ID] = path.Join(dir, name)
+			if node.Type == NodeTypeFolder {
+				walk(node.ID, paths[node.ID])
+			}
+		}
+	}
+	walk("", "")
+	// nodes in parent cycles are not reachable from the root
+	for _, node := range nodes {
+		if _, ok := paths[node.ID]; !ok {
+			children[""] = []*Node{node}
+			walk("", "")
+		}
+	}
+	return paths
+}
+
+// NodeExportAttachmentPath archive path of an attachment of the node
+func NodeExportAttachmentPath(nodeID, name string) string {
+	return path.Join(NodeExportAttachmentDir, nodeID, exportFileName(name))
+}
+
+// NodeExportMarkdown document as markdown with yaml front-matter
+func NodeExportMarkdown(node *Node, attachments []string) string {
+	var b strings.Builder
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "id: %s\n", strconv.Quote(node.ID))
+	fmt.Fprintf(&b, "title: %s\n", strconv.Quote(node.Name))
+	fmt.Fprintf(&b, "parent: %s\n", strconv.Quote(node.ParentID))
+	fmt.Fprintf(&b, "created_at: %s\n", node.CreatedAt.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "updated_at: %s\n", node.UpdatedAt.UTC().Format(time.RFC3339))
+	if node.Meta.Emoji != "" {
+		fmt.Fprintf(&b, "emoji: %s\n", strconv.Quote(node.Meta.Emoji))
+	}
+	if len(node.Meta.Tags) > 0 {
+		b.WriteString("tags:\n")
+		for _, tag := range node.Meta.Tags {
+			fmt.Fprintf(&b, "  - %s\n", strconv.Quote(tag))
+		}
+	}
+	if len(attachments) > 0 {
+		b.WriteString("attachments:\n")
+		for _, attachment := range attachments {
+			fmt.Fprintf(&b, "  - %s\n", strconv.Quote(attachment))
+		}
+	}
+	b.WriteString("---\n\n")
+	b.WriteString(node.Content)
+	if !strings.HasSuffix(node.Content, "\n") {
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// exportFileName node name safe as a file name on common file systems
+func exportFileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r < 0x20, strings.ContainsRune(`/\:*?"<>|`, r):
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	name = strings.Trim(name, ". ")
+	if runes := []rune(name); len(runes) > 100 {
+		name = string(runes[:100])
+	}
+	if name == "" {
+		return "untitled"
+	}
+	return name
+}
@@ -0,0 +1,50 @@
+package domain
+
+import "time"
+
+const (
+	// NodeStaleDefaultDays documents neither updated nor reviewed in this many days are stale
+	NodeStaleDefaultDays = 180
+	// NodeStaleViewDays views of stale documents are counted over this many days, to find docs read a lot but old
+	NodeStaleViewDays = 30
+)
+
+type NodeStaleOrder string
+
+const (
+	// oldest first
+	NodeStaleOrderAge NodeStaleOrder = "age"
+	// most viewed first
+	NodeStaleOrderViews NodeStaleOrder = "views"
+)
+
+type NodeStaleListReq struct {
+	KBID string `json:"kb_id" query:"kb_id" validate:"required"`
+	// default: NodeStaleDefaultDays
+	Days  int            `json:"days" query:"days" validate:"omitempty,min=1,max=3650"`
+	Order NodeStaleOrder `json:"order" query:"order" validate:"omitempty,oneof=age views"`
+
+	Pager
+}
+
+type NodeStaleItem struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	ParentID string `json:"parent_id"`
+
+	UpdatedAt  time.Time  `json:"updated_at"`
+	ReviewedAt *time.Time `json:"reviewed_at"`
+	// account of the user who last reviewed it
+	ReviewedBy string `json:"reviewed_by"`
+	// days since the later of the last update and the last review
+	StaleDays int `json:"stale_days" gorm:"-"`
+	// views in the last NodeStaleViewDays days
+	Views int64 `json:"views"`
+
+	FreshAt time.Time `json:"-"`
+}
+
+type MarkNodesReviewedReq struct {
+	KBID    string   `json:"kb_id" validate:"required"`
+	NodeIDs []string `json:"node_ids" validate:"required,min=1"`
+}
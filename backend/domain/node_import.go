@@ -0,0 +1,235 @@
+package domain
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// markdownArchiveLinkHost host of archive urls of imported files, relative links between them are resolved against it
+const markdownArchiveLinkHost = "markdown-archive"
+
+type ImportMarkdownArchiveReq struct {
+	KBID string `json:"kb_id" form:"kb_id" validate:"required"`
+	// folder to import into, kb root if empty
+	ParentID string `json:"parent_id" form:"parent_id"`
+	// replace content of existing documents at the same path instead of skipping them
+	Overwrite bool `json:"overwrite" form:"overwrite"`
+}
+
+type ImportMarkdownArchiveResp struct {
+	FolderIDs       []string `json:"folder_ids"`
+	NodeIDs         []string `json:"node_ids"`
+	UpdatedNodeIDs  []string `json:"updated_node_ids"`
+	SkippedPaths    []string `json:"skipped_paths"`
+	AttachmentCount int      `json:"attachment_count"`
+	ImageCount      int      `json:"image_count"`
+	// new and updated documents are published and queued for indexing, unless the kb requires review
+	ReleaseID string   `json:"release_id"`
+	Warnings  []string `json:"warnings"`
+}
+
+type ImportDocumentsReq struct {
+	KBID string `json:"kb_id" form:"kb_id" validate:"required"`
+	// folder to import into, kb root if empty
+	ParentID string `json:"parent_id" form:"parent_id"`
+}
+
+type ImportDocumentsResp struct {
+	// a document for each parsed file, named by its title or file name
+	NodeIDs []string `json:"node_ids"`
+	// files the parsing service failed on, not imported
+	FailedFiles []string `json:"failed_files"`
+	// new documents are published and queued for indexing, unless the kb requires review
+	ReleaseID string   `json:"release_id"`
+	Warnings  []string `json:"warnings"`
+}
+
+type ImportMediaReq struct {
+	KBID string `json:"kb_id" form:"kb_id" validate:"required"`
+	// folder to import into, kb root if empty
+	ParentID string `json:"parent_id" form:"parent_id"`
+	// audio or video to download and transcribe, instead of an uploaded file
+	URL string `json:"url" form:"url" validate:"omitempty,url"`
+	// name of the transcript, file name without extension if empty
+	Name string `json:"name" form:"name"`
+}
+
+type ImportMediaResp struct {
+	NodeID string `json:"node_id"`
+	// length of the media in seconds
+	Duration     float64 `json:"duration"`
+	Language     string  `json:"language"`
+	SegmentCount int     `json:"segment_count"`
+	// the transcript is published and queued for indexing, unless the kb requires review
+	ReleaseID string   `json:"release_id"`
+	Warnings  []string `json:"warnings"`
+}
+
+// MediaTranscriptParagraphSeconds segments are joined into paragraphs of about this length, each led by its time offset
+const MediaTranscriptParagraphSeconds = 60
+
+// MediaTranscriptSegment text spoken between start and end, in seconds from the start of the media
+type MediaTranscriptSegment struct {
+	Start float64
+	End   float64
+	Text  string
+}
+
+// FormatMediaOffset hh:mm:ss of the offset in seconds
+func FormatMediaOffset(seconds float64) string {
+	total := int(max(seconds, 0))
+	return fmt.Sprintf("%02d:%02d:%02d", total/3600, total/60%60, total%60)
+}
+
+// MediaTranscriptMarkdown transcript with paragraphs led by their time offsets, so chunks retrieved for answers keep the
+// offsets they are cited with. offsets link to the moment in the media by a media fragment if its url is known
+func MediaTranscriptMarkdown(name, mediaURL string, segments []MediaTranscriptSegment) string {
+	var sb strings.Builder
+	if mediaURL != "" {
+		sb.WriteString(fmt.Sprintf("原始媒体：[%s](%s)\n\n", name, mediaURL))
+	}
+	for i := 0; i < len(segments); {
+		start := segments[i].Start
+		offset := "**[" + FormatMediaOffset(start) + "]**"
+		if mediaURL != "" {
+			offset = fmt.Sprintf("[[%s]](%s#t=%d)", FormatMediaOffset(start), mediaURL, int(start))
+		}
+		sb.WriteString(offset)
+		prev := ""
+		for ; i < len(segments) && (prev == "" || segments[i].Start-start < MediaTranscriptParagraphSeconds); i++ {
+			text := segments[i].Text
+			if prev == "" || !isCJKBoundary(prev, text) {
+				sb.WriteString(" ")
+			}
+			sb.WriteString(text)
+			prev = text
+		}
+		sb.WriteString("\n\n")
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// isCJKBoundary whether chinese or japanese text, written without spaces between words, is on either side of the boundary
+func isCJKBoundary(before, after string) bool {
+	last, _ := utf8.DecodeLastRuneInString(before)
+	first, _ := utf8.DecodeRuneInString(after)
+	return isCJKRune(last) || isCJKRune(first)
+}
+
+func isCJKRune(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana) || unicode.IsPunct(r) && r > unicode.MaxLatin1
+}
+
+// MarkdownFrontMatter yaml front-matter of imported markdown, as written by kb export
+type MarkdownFrontMatter struct {
+	ID          string   `yaml:"id"`
+	Title       string   `yaml:"title"`
+	Parent      string   `yaml:"parent"`
+	Emoji       string   `yaml:"emoji"`
+	Tags        []string `yaml:"tags"`
+	Attachments []string `yaml:"attachments"`
+}
+
+// ParseMarkdownFrontMatter split front-matter from markdown content, content without front-matter is returned as is
+func ParseMarkdownFrontMatter(content string) (*MarkdownFrontMatter, string, error) {
+	lines := strings.Split(strings.ReplaceAll(strings.TrimPrefix(content, "\ufeff"), "\r\n", "\n"), "\n")
+	if lines[0] != "---" {
+		return &MarkdownFrontMatter{}, content, nil
+	}
+	for i := 1; i < len(lines); i++ {
+		if lines[i] != "---" && lines[i] != "..." {
+			continue
+		}
+		front := &MarkdownFrontMatter{}
+		if err := yaml.Unmarshal([]byte(strings.Join(lines[1:i], "\n")), front); err != nil {
+			return &MarkdownFrontMatter{}, content, err
+		}
+		return front, strings.TrimLeft(strings.Join(lines[i+1:], "\n"), "\n"), nil
+	}
+	return &MarkdownFrontMatter{}, content, nil
+}
+
+// IsMarkdownFile report whether the archive file is an imported markdown document
+func IsMarkdownFile(name string) bool {
+	ext := strings.ToLower(path.Ext(name))
+	return ext == ".md" || ext == ".markdown"
+}
+
+// MarkdownArchiveFiles clean paths of regular files in the archive, without os metadata files and the common root directory
+func MarkdownArchiveFiles(names []string) map[string]string {
+	cleaned := make(map[string]string, len(names))
+	for _, name := range names {
+		p := path.Clean("/" + strings.ReplaceAll(name, "\\", "/"))[1:]
+		if p == "" || strings.HasSuffix(name, "/") || strings.HasPrefix(p, "__MACOSX/") || strings.HasPrefix(path.Base(p), ".") {
+			continue
+		}
+		cleaned[p] = name
+	}
+	// archives of a folder have every file in it
+	root := ""
+	for p := range cleaned {
+		dir, _, ok := strings.Cut(p, "/")
+		if !ok || (root != "" && dir != root) {
+			root = ""
+			break
+		}
+		root = dir
+	}
+	if root == "" {
+		return cleaned
+	}
+	files := make(map[string]string, len(cleaned))
+	for p, name := range cleaned {
+		files[strings.TrimPrefix(p, root+"/")] = name
+	}
+	return files
+}
+
+// SortedMarkdownPaths markdown documents of archive files, parents before children
+func SortedMarkdownPaths(files map[string]string) []string {
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		if IsMarkdownFile(p) && !strings.HasPrefix(p, NodeExportAttachmentDir+"/") {
+			paths = append(paths, p)
+		}
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		di, dj := strings.Count(paths[i], "/"), strings.Count(paths[j], "/")
+		if di != dj {
+			return di < dj
+		}
+		return paths[i] < paths[j]
+	})
+	return paths
+}
+
+// MarkdownDocumentTitle title of the document, file name without extension if front-matter has no title
+func MarkdownDocumentTitle(p string, front *MarkdownFrontMatter) string {
+	if title := strings.TrimSpace(front.Title); title != "" {
+		return title
+	}
+	base := path.Base(p)
+	return strings.TrimSuffix(base, path.Ext(base))
+}
+
+// MarkdownArchiveURL archive url of the file, links between imported documents are matched by it
+func MarkdownArchiveURL(p string) string {
+	return (&url.URL{Scheme: "archive", Host: markdownArchiveLinkHost, Path: "/" + p}).String()
+}
+
+// ResolveArchivePath path in the archive of a relative link of the document, empty if the link leaves the archive
+func ResolveArchivePath(documentPath, link string) string {
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil || u.IsAbs() || u.Host != "" || u.Path == "" || strings.HasPrefix(u.Path, "/") {
+		return ""
+	}
+	resolved := path.Join(path.Dir("/"+documentPath), u.Path)
+	return strings.TrimPrefix(resolved, "/")
+}
@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"regexp"
+	"strings"
+)
+
+// nodeLinkRegexp intra-wiki link to a node, relative /node/{id} or absolute url of the wiki
+var nodeLinkRegexp = regexp.MustCompile(`/node/([0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12})`)
+
+// NodeLink source node links to target node
+type NodeLink struct {
+	KBID     string `json:"kb_id"`
+	SourceID string `json:"source_id" gorm:"primaryKey"`
+	TargetID string `json:"target_id" gorm:"primaryKey"`
+}
+
+// ParseNodeLinks ids of nodes linked in content, self links are ignored
+func ParseNodeLinks(nodeID, content string) []string {
+	seen := make(map[string]bool)
+	targetIDs := make([]string, 0)
+	for _, match := range nodeLinkRegexp.FindAllStringSubmatch(content, -1) {
+		targetID := strings.ToLower(match[1])
+		if targetID == nodeID || seen[targetID] {
+			continue
+		}
+		seen[targetID] = true
+		targetIDs = append(targetIDs, targetID)
+	}
+	return targetIDs
+}
+
+type NodeBacklinkResp struct {
+	ID         string         `json:"id"`
+	Name       string         `json:"name"`
+	Type       NodeType       `json:"type"`
+	Status     NodeStatus     `json:"status"`
+	Visibility NodeVisibility `json:"visibility"`
+	Emoji      string         `json:"emoji"`
+}
+
+// BrokenNodeLinkResp link of source node to a node not existing in the kb
+type BrokenNodeLinkResp struct {
+	SourceID   string `json:"source_id"`
+	SourceName string `json:"source_name"`
+	TargetID   string `json:"target_id"`
+}
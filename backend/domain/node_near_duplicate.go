@@ -0,0 +1,193 @@
+package domain
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+	"regexp"
+	"sort"
+	"time"
+	"unicode"
+)
+
+const (
+	// NearDuplicateThreshold min jaccard similarity of the shingles of two documents reported as near-duplicates
+	NearDuplicateThreshold = 0.8
+	// NearDuplicateShingleSize runes of a shingle, text is compared without spaces so it works for languages without them
+	NearDuplicateShingleSize = 5
+	// NearDuplicateMinRunes shorter documents, e.g. stubs linking to another document, are not compared
+	NearDuplicateMinRunes = 50
+
+	// minhash signature of nearDuplicateBands bands of nearDuplicateRows rows, pairs above the threshold
+	// share a band with a probability above 99.9%
+	nearDuplicateBands = 16
+	nearDuplicateRows  = 4
+)
+
+var nearDuplicateTagRegex = regexp.MustCompile(`<[^>]*>`)
+
+// NodeNearDuplicate pair of documents of the kb with similar content, found by the daily detection job.
+// node_id is less than other_node_id
+type NodeNearDuplicate struct {
+	KBID        string  `json:"kb_id"`
+	NodeID      string  `json:"node_id" gorm:"primaryKey"`
+	OtherNodeID string  `json:"other_node_id" gorm:"primaryKey"`
+	Similarity  float64 `json:"similarity"`
+	// dismissed by a maintainer as not redundant, kept dismissed while the pair is still found
+	Dismissed  bool      `json:"dismissed"`
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+type NearDuplicateListReq struct {
+	KBID             string `json:"kb_id" query:"kb_id" validate:"required"`
+	IncludeDismissed bool   `json:"include_dismissed" query:"include_dismissed"`
+}
+
+type NearDuplicateDetectReq struct {
+	KBID string `json:"kb_id" validate:"required"`
+}
+
+type NearDuplicateDismissReq struct {
+	KBID        string `json:"kb_id" validate:"required"`
+	NodeID      string `json:"node_id" validate:"required"`
+	OtherNodeID string `json:"other_node_id" validate:"required"`
+}
+
+type NearDuplicateResp struct {
+	NodeID        string    `json:"node_id"`
+	NodeName      string    `json:"node_name"`
+	OtherNodeID   string    `json:"other_node_id"`
+	OtherNodeName string    `json:"other_node_name"`
+	Similarity    float64   `json:"similarity"`
+	Dismissed     bool      `json:"dismissed"`
+	DetectedAt    time.Time `json:"detected_at"`
+}
+
+// NearDuplicateShingles hashes of the overlapping runs of runes of the text of the content, without markup, case,
+// spaces and punctuation. nil if the text is shorter than NearDuplicateMinRunes
+func NearDuplicateShingles(content string) map[uint64]struct{} {
+	text := make([]rune, 0, len(content))
+	for _, r := range nearDuplicateTagRegex.ReplaceAllString(content, " ") {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			text = append(text, unicode.ToLower(r))
+		}
+	}
+	if len(text) < NearDuplicateMinRunes {
+		return nil
+	}
+	shingles := make(map[uint64]struct{}, len(text))
+	for i := 0; i+NearDuplicateShingleSize <= len(text); i++ {
+		h := fnv.New64a()
+		h.Write([]byte(string(text[i : i+NearDuplicateShingleSize])))
+		shingles[h.Sum64()] = struct{}{}
+	}
+	return shingles
+}
+
+// FindNearDuplicates pairs of documents whose shingles have a jaccard similarity of at least the threshold.
+// candidates are found by locality sensitive hashing of minhash signatures, and compared by their shingles
+func FindNearDuplicates(kbID string, shingles map[string]map[uint64]struct{}, now time.Time) []*NodeNearDuplicate {
+	ids := make([]string, 0, len(shingles))
+	for id, set := range shingles {
+		if len(set) > 0 {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	buckets := make(map[[2]uint64][]int)
+	for i, id := range ids {
+		signature := minHashSignature(shingles[id])
+		for band := 0; band < nearDuplicateBands; band++ {
+			h := fnv.New64a()
+			rows := make([]byte, 0, 8*nearDuplicateRows)
+			for _, v := range signature[band*nearDuplicateRows : (band+1)*nearDuplicateRows] {
+				rows = binary.LittleEndian.AppendUint64(rows, v)
+			}
+			h.Write(rows)
+			key := [2]uint64{uint64(band), h.Sum64()}
+			buckets[key] = append(buckets[key], i)
+		}
+	}
+	compared := make(map[[2]int]bool)
+	pairs := make([]*NodeNearDuplicate, 0)
+	for _, bucket := range buckets {
+		for x := 0; x < len(bucket); x++ {
+			for y := x + 1; y < len(bucket); y++ {
+				pair := [2]int{bucket[x], bucket[y]}
+				if compared[pair] {
+					continue
+				}
+				compared[pair] = true
+				similarity := jaccard(shingles[ids[pair[0]]], shingles[ids[pair[1]]])
+				if similarity < NearDuplicateThreshold {
+					continue
+				}
+				pairs = append(pairs, &NodeNearDuplicate{
+					KBID:        kbID,
+					NodeID:      ids[pair[0]],
+					OtherNodeID: ids[pair[1]],
+					Similarity:  math.Round(similarity*1000) / 1000,
+					DetectedAt:  now,
+				})
+			}
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].Similarity != pairs[j].Similarity {
+			return pairs[i].Similarity > pairs[j].Similarity
+		}
+		return pairs[i].NodeID+pairs[i].OtherNodeID < pairs[j].NodeID+pairs[j].OtherNodeID
+	})
+	return pairs
+}
+
+// minHashSignature min of each of the seeded hashes over the shingles
+func minHashSignature(shingles map[uint64]struct{}) []uint64 {
+	signature := make([]uint64, nearDuplicateBands*nearDuplicateRows)
+	for i := range signature {
+		signature[i] = math.MaxUint64
+	}
+	for shingle := range shingles {
+		for i := range signature {
+			if h := mix64(shingle ^ (uint64(i+1) * 0x9e3779b97f4a7c15)); h < signature[i] {
+				signature[i] = h
+			}
+		}
+	}
+	return signature
+}
+
+// mix64 finalizer of splitmix64
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+func jaccard(a, b map[uint64]struct{}) float64 {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	shared := 0
+	for shingle := range a {
+		if _, ok := b[shingle]; ok {
+			shared++
+		}
+	}
+	union := len(a) + len(b) - shared
+	if union == 0 {
+		return 0
+	}
+	return float64(shared) / float64(union)
+}
+
+// NearDuplicateOrder order the ids of a pair as they are stored
+func NearDuplicateOrder(nodeID, otherNodeID string) (string, string) {
+	if nodeID > otherNodeID {
+		return otherNodeID, nodeID
+	}
+	return nodeID, otherNodeID
+}
@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultReviewIntervalDays owned documents are due for review this many days after their last update or review
+	DefaultReviewIntervalDays = 90
+	// ReviewReminderRepeat overdue documents are reminded again after this long until they are reviewed
+	ReviewReminderRepeat = 7 * 24 * time.Hour
+	// ReviewReminderItemLimit documents listed per owner in a reminder
+	ReviewReminderItemLimit = 20
+)
+
+// table: node_owners
+type NodeOwner struct {
+	NodeID  string `json:"node_id" gorm:"primaryKey"`
+	KBID    string `json:"kb_id"`
+	OwnerID string `json:"owner_id"`
+	// 0 for the interval of the kb review reminder settings
+	ReviewIntervalDays int `json:"review_interval_days"`
+	// last reminder of the document, reset when the owner changes
+	RemindedAt *time.Time `json:"reminded_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// ReviewReminderSettings per kb daily reminders of owned documents past their review interval
+type ReviewReminderSettings struct {
+	Enabled bool `json:"enabled"`
+	// default review interval of owned documents, DefaultReviewIntervalDays if 0
+	IntervalDays int `json:"interval_days" validate:"min=0,max=3650"`
+	// push reminders to dingtalk/feishu group, owners are listed by account
+	Webhook    NotifyWebhook `json:"webhook"`
+	ConsoleURL string        `json:"console_url"` // admin console base url for deep links
+}
+
+func (s *ReviewReminderSettings) Scan(value any) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New(fmt.Sprint("invalid review reminder settings value type:", value))
+	}
+	return json.Unmarshal(bytes, s)
+}
+
+func (s ReviewReminderSettings) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+// Interval review interval of documents without their own
+func (s ReviewReminderSettings) Interval() int {
+	if s.IntervalDays > 0 {
+		return s.IntervalDays
+	}
+	return DefaultReviewIntervalDays
+}
+
+type SetNodeOwnerReq struct {
+	KBID    string   `json:"kb_id" validate:"required"`
+	NodeIDs []string `json:"node_ids" validate:"required,min=1"`
+	// empty to remove the owner
+	OwnerID string `json:"owner_id"`
+	// 0 for the interval of the kb
+	ReviewIntervalDays int `json:"review_interval_days" validate:"min=0,max=3650"`
+}
+
+type OverdueNodeListReq struct {
+	KBID string `json:"kb_id" query:"kb_id" validate:"required"`
+	// all owners if empty
+	OwnerID string `json:"owner_id" query:"owner_id"`
+}
+
+type OverdueNode struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	OwnerID      string `json:"owner_id"`
+	OwnerAccount string `json:"-"`
+	// later of the last update and the last review
+	FreshAt            time.Time  `json:"fresh_at"`
+	ReviewIntervalDays int        `json:"review_interval_days"`
+	OverdueDays        int        `json:"overdue_days" gorm:"-"`
+	RemindedAt         *time.Time `json:"reminded_at"`
+}
+
+// OwnerOverdueNodes owned documents of an owner past their review interval, most overdue first
+type OwnerOverdueNodes struct {
+	OwnerID      string         `json:"owner_id"`
+	OwnerAccount string         `json:"owner_account"`
+	Nodes        []*OverdueNode `json:"nodes"`
+}
+
+// Due whether the document is to be reminded now, it was not reminded since it became overdue or a while ago
+func (n *OverdueNode) Due(now time.Time) bool {
+	if n.RemindedAt == nil {
+		return true
+	}
+	dueAt := n.FreshAt.AddDate(0, 0, n.ReviewIntervalDays)
+	return n.RemindedAt.Before(dueAt) || now.Sub(*n.RemindedAt) >= ReviewReminderRepeat
+}
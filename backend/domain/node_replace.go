@@ -0,0 +1,210 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"time"
+)
+
+const (
+	// NodeReplacePreviewSamples max samples of each node in preview
+	NodeReplacePreviewSamples = 3
+	// NodeReplaceSampleContext runes around a match in samples
+	NodeReplaceSampleContext = 30
+)
+
+var (
+	ErrNodeReplaceJobNotRollbackable = NewError(ErrCodeConflict, "only succeeded replace jobs can be rolled back")
+	ErrNodeReplaceEmptyMatch         = NewError(ErrCodeInvalidRequest, "pattern must not match empty text")
+)
+
+type NodeReplaceJobStatus string
+
+const (
+	NodeReplaceJobStatusPending    NodeReplaceJobStatus = "pending"
+	NodeReplaceJobStatusRunning    NodeReplaceJobStatus = "running"
+	NodeReplaceJobStatusSucceeded  NodeReplaceJobStatus = "succeeded"
+	NodeReplaceJobStatusFailed     NodeReplaceJobStatus = "failed"
+	NodeReplaceJobStatusRolledBack NodeReplaceJobStatus = "rolled_back"
+)
+
+type NodeReplaceReq struct {
+	KBID          string `json:"kb_id" validate:"required"`
+	Find          string `json:"find" validate:"required"`
+	Replace       string `json:"replace"`
+	IsRegex       bool   `json:"is_regex"`
+	CaseSensitive bool   `json:"case_sensitive"`
+	// documents in these folders and their sub folders, whole kb if empty
+	FolderIDs StringList `json:"folder_ids"`
+}
+
+// Compile pattern of the find text. patterns which may match empty text, like a* or ^, are rejected
+// as they would insert the replacement between every character
+func (r *NodeReplaceReq) Compile() (*regexp.Regexp, error) {
+	pattern := r.Find
+	if !r.IsRegex {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if !r.CaseSensitive {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil, err
+	}
+	if matchesEmpty(parsed.Simplify()) {
+		return nil, ErrNodeReplaceEmptyMatch
+	}
+	return re, nil
+}
+
+// matchesEmpty whether the regexp may match an empty text somewhere, anchors and word boundaries match empty text
+func matchesEmpty(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return len(re.Rune) == 0
+	case syntax.OpCharClass, syntax.OpAnyCharNotNL, syntax.OpAnyChar, syntax.OpNoMatch:
+		return false
+	case syntax.OpCapture, syntax.OpPlus:
+		return matchesEmpty(re.Sub[0])
+	case syntax.OpRepeat:
+		return re.Min == 0 || matchesEmpty(re.Sub[0])
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if !matchesEmpty(sub) {
+				return false
+			}
+		}
+		return true
+	case syntax.OpAlternate:
+		for _, sub := range re.Sub {
+			if matchesEmpty(sub) {
+				return true
+			}
+		}
+		return false
+	}
+	// empty match, star, quest, anchors and word boundaries
+	return true
+}
+
+// ReplaceAll replace matches in text, regex replacement supports $1 style groups
+func (r *NodeReplaceReq) ReplaceAll(re *regexp.Regexp, text string) string {
+	if r.IsRegex {
+		return re.ReplaceAllString(text, r.Replace)
+	}
+	return re.ReplaceAllLiteralString(text, r.Replace)
+}
+
+type NodeReplaceSample struct {
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+type NodeReplacePreviewItem struct {
+	ID         string              `json:"id"`
+	Name       string              `json:"name"`
+	MatchCount int                 `json:"match_count"`
+	Samples    []NodeReplaceSample `json:"samples"`
+}
+
+type NodeReplacePreviewResp struct {
+	NodeCount  int                       `json:"node_count"`
+	MatchCount int                       `json:"match_count"`
+	Nodes      []*NodeReplacePreviewItem `json:"nodes"`
+}
+
+// table: node_replace_jobs
+type NodeReplaceJob struct {
+	ID            string               `json:"id" gorm:"primaryKey"`
+	KBID          string               `json:"kb_id"`
+	Find          string               `json:"find"`
+	Replace       string               `json:"replace"`
+	IsRegex       bool                 `json:"is_regex"`
+	CaseSensitive bool                 `json:"case_sensitive"`
+	FolderIDs     StringList           `json:"folder_ids" gorm:"type:jsonb"`
+	Status        NodeReplaceJobStatus `json:"status"`
+	NodeCount     int                  `json:"node_count"`
+	ReplaceCount  int                  `json:"replace_count"`
+	Error         string               `json:"error"`
+	CreatedAt     time.Time            `json:"created_at"`
+	UpdatedAt     time.Time            `json:"updated_at"`
+}
+
+func (NodeReplaceJob) TableName() string {
+	return "node_replace_jobs"
+}
+
+func (j *NodeReplaceJob) Req() *NodeReplaceReq {
+	return &NodeReplaceReq{
+		KBID:          j.KBID,
+		Find:          j.Find,
+		Replace:       j.Replace,
+		IsRegex:       j.IsRegex,
+		CaseSensitive: j.CaseSensitive,
+		FolderIDs:     j.FolderIDs,
+	}
+}
+
+// table: node_versions
+type NodeVersion struct {
+	ID         int64     `json:"id" gorm:"primaryKey"`
+	KBID       string    `json:"kb_id"`
+	NodeID     string    `json:"node_id"`
+	JobID      string    `json:"job_id"`
+	Name       string    `json:"name"`
+	Content    string    `json:"content"`
+	NewContent string    `json:"new_content"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (NodeVersion) TableName() string {
+	return "node_versions"
+}
+
+type NodeReplaceJobListReq struct {
+	KBID string `json:"kb_id" query:"kb_id" validate:"required"`
+
+	Pager
+}
+
+type NodeReplaceJobReq struct {
+	KBID  string `json:"kb_id" query:"kb_id" validate:"required"`
+	JobID string `json:"job_id" query:"job_id" validate:"required"`
+}
+
+type NodeReplaceRollbackResp struct {
+	RestoredCount int `json:"restored_count"`
+	// nodes edited after the job are not restored
+	SkippedNodeIDs []string `json:"skipped_node_ids"`
+}
+
+// NodeReplaceJobRequest mq message of replace job
+type NodeReplaceJobRequest struct {
+	JobID string `json:"job_id"`
+}
+
+type StringList []string
+
+func (s *StringList) Scan(value any) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New(fmt.Sprint("invalid string list value type:", value))
+	}
+	return json.Unmarshal(bytes, s)
+}
+
+func (s StringList) Value() (driver.Value, error) {
+	if s == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]string(s))
+}
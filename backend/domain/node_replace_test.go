@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNodeReplaceReqCompile(t *testing.T) {
+	tests := []struct {
+		name          string
+		find          string
+		isRegex       bool
+		caseSensitive bool
+		text          string
+		want          string
+		wantErr       error
+	}{
+		{name: "literal", find: "a.b", text: "a.b axb", want: "X axb"},
+		{name: "literal ignoring case", find: "Wiki", text: "wiki WIKI", want: "X X"},
+		{name: "literal in case", find: "Wiki", caseSensitive: true, text: "wiki Wiki", want: "wiki X"},
+		{name: "regex", find: `v\d+`, isRegex: true, text: "v1 v22 v", want: "X X v"},
+		{name: "regex with anchor and text", find: `(?m)^# `, isRegex: true, text: "# a\n# b", want: "Xa\nXb"},
+		{name: "optional part with a required part", find: `colou?r`, isRegex: true, text: "color colour", want: "X X"},
+		{name: "star", find: `a*`, isRegex: true, wantErr: ErrNodeReplaceEmptyMatch},
+		{name: "optional", find: `(foo)?`, isRegex: true, wantErr: ErrNodeReplaceEmptyMatch},
+		{name: "repeat from zero", find: `x{0,3}`, isRegex: true, wantErr: ErrNodeReplaceEmptyMatch},
+		{name: "alternative of empty", find: `foo|`, isRegex: true, wantErr: ErrNodeReplaceEmptyMatch},
+		{name: "line anchor", find: `(?m)^`, isRegex: true, wantErr: ErrNodeReplaceEmptyMatch},
+		{name: "word boundary", find: `\b`, isRegex: true, wantErr: ErrNodeReplaceEmptyMatch},
+		{name: "empty group", find: `()`, isRegex: true, wantErr: ErrNodeReplaceEmptyMatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &NodeReplaceReq{Find: tt.find, Replace: "X", IsRegex: tt.isRegex, CaseSensitive: tt.caseSensitive}
+			re, err := req.Compile()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Compile() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := req.ReplaceAll(re, tt.text); got != tt.want {
+				t.Errorf("ReplaceAll() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if _, err := (&NodeReplaceReq{Find: "(", IsRegex: true}).Compile(); err == nil {
+		t.Error("Compile() of an invalid regex succeeded")
+	}
+}
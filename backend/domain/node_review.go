@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrNodeReviewNotPending   = NewError(ErrCodeConflict, "node review is not pending")
+	ErrNodeReviewSelfReview   = NewError(ErrCodeForbidden, "can't review your own submission")
+	ErrNodeChangedSinceSubmit = NewError(ErrCodeConflict, "node changed since submitted for review, please submit again")
+	ErrNodeReviewNotApproved  = NewError(ErrCodeConflict, "node must be approved in review before publishing")
+)
+
+type NodeReviewStatus string
+
+const (
+	NodeReviewStatusPending   NodeReviewStatus = "pending"
+	NodeReviewStatusApproved  NodeReviewStatus = "approved"
+	NodeReviewStatusRejected  NodeReviewStatus = "rejected"
+	NodeReviewStatusCancelled NodeReviewStatus = "cancelled"
+)
+
+// ReviewSettings per kb review workflow, only approved content is published if required
+type ReviewSettings struct {
+	RequireReview bool `json:"require_review"`
+}
+
+func (s *ReviewSettings) Scan(value any) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New(fmt.Sprint("invalid review settings value type:", value))
+	}
+	return json.Unmarshal(bytes, s)
+}
+
+func (s ReviewSettings) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+// table: node_reviews
+type NodeReview struct {
+	ID       string `json:"id" gorm:"primaryKey"`
+	KBID     string `json:"kb_id"`
+	NodeID   string `json:"node_id"`
+	NodeName string `json:"node_name"`
+	// version of the node under review
+	NodeUpdatedAt time.Time        `json:"node_updated_at"`
+	Status        NodeReviewStatus `json:"status"`
+	SubmitterID   string           `json:"submitter_id"`
+	SubmitComment string           `json:"submit_comment"`
+	ReviewerID    string           `json:"reviewer_id"`
+	ReviewComment string           `json:"review_comment"`
+	CreatedAt     time.Time        `json:"created_at"`
+	ReviewedAt    *time.Time       `json:"reviewed_at"`
+}
+
+func (NodeReview) TableName() string {
+	return "node_reviews"
+}
+
+type SubmitNodeReviewReq struct {
+	KBID    string `json:"kb_id" validate:"required"`
+	NodeID  string `json:"node_id" validate:"required"`
+	Comment string `json:"comment"`
+}
+
+type ApproveNodeReviewReq struct {
+	KBID    string `json:"kb_id" validate:"required"`
+	ID      string `json:"id" validate:"required"`
+	Comment string `json:"comment"`
+}
+
+type RejectNodeReviewReq struct {
+	KBID    string `json:"kb_id" validate:"required"`
+	ID      string `json:"id" validate:"required"`
+	Comment string `json:"comment" validate:"required"`
+}
+
+type NodeReviewListReq struct {
+	KBID   string           `json:"kb_id" query:"kb_id" validate:"required"`
+	NodeID string           `json:"node_id" query:"node_id"`
+	Status NodeReviewStatus `json:"status" query:"status" validate:"omitempty,oneof=pending approved rejected cancelled"`
+
+	Pager
+}
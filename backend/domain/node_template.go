@@ -0,0 +1,232 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const BuiltinNodeTemplatePrefix = "builtin-"
+
+var ErrBuiltinNodeTemplateReadOnly = NewError(ErrCodeForbidden, "built-in node templates can't be modified")
+
+// placeholders like {{title}} in template names and content, filled when a node is created from the template
+var nodeTemplatePlaceholderRegex = regexp.MustCompile(`\{\{\s*([\w.-]+)\s*\}\}`)
+
+// NodeTemplateItem child node created along with the template root
+type NodeTemplateItem struct {
+	Name     string              `json:"name" validate:"required"`
+	Type     NodeType            `json:"type" validate:"required,oneof=1 2"`
+	Emoji    string              `json:"emoji"`
+	Content  string              `json:"content"`
+	Children []*NodeTemplateItem `json:"children" validate:"dive"`
+}
+
+type NodeTemplateItems []*NodeTemplateItem
+
+func (s *NodeTemplateItems) Scan(value any) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New(fmt.Sprint("invalid node template items value type:", value))
+	}
+	return json.Unmarshal(bytes, s)
+}
+
+func (s NodeTemplateItems) Value() (driver.Value, error) {
+	if s == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]*NodeTemplateItem(s))
+}
+
+// table: node_templates
+type NodeTemplate struct {
+	ID          string            `json:"id" gorm:"primaryKey"`
+	KBID        string            `json:"kb_id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Category    string            `json:"category"`
+	Type        NodeType          `json:"type"`
+	Emoji       string            `json:"emoji"`
+	Content     string            `json:"content"`
+	Children    NodeTemplateItems `json:"children" gorm:"type:jsonb"`
+	Builtin     bool              `json:"builtin" gorm:"-"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+func (NodeTemplate) TableName() string {
+	return "node_templates"
+}
+
+// FillNodeTemplatePlaceholders replace {{key}} with values, unknown placeholders are kept
+func FillNodeTemplatePlaceholders(text string, values map[string]string) string {
+	return nodeTemplatePlaceholderRegex.ReplaceAllStringFunc(text, func(match string) string {
+		key := nodeTemplatePlaceholderRegex.FindStringSubmatch(match)[1]
+		if value, ok := values[key]; ok {
+			return value
+		}
+		return match
+	})
+}
+
+// Placeholders placeholders used in the template, in order of appearance
+func (t *NodeTemplate) Placeholders() []string {
+	seen := make(map[string]bool)
+	keys := make([]string, 0)
+	collect := func(text string) {
+		for _, m := range nodeTemplatePlaceholderRegex.FindAllStringSubmatch(text, -1) {
+			if !seen[m[1]] {
+				seen[m[1]] = true
+				keys = append(keys, m[1])
+			}
+		}
+	}
+	var walk func(items []*NodeTemplateItem)
+	walk = func(items []*NodeTemplateItem) {
+		for _, item := range items {
+			collect(item.Name)
+			collect(item.Content)
+			walk(item.Children)
+		}
+	}
+	collect(t.Name)
+	collect(t.Content)
+	walk(t.Children)
+	return keys
+}
+
+type NodeTemplateListItem struct {
+	*NodeTemplate
+	Placeholders []string `json:"placeholders"`
+}
+
+type CreateNodeTemplateReq struct {
+	KBID        string              `json:"kb_id" validate:"required"`
+	Name        string              `json:"name" validate:"required"`
+	Description string              `json:"description"`
+	Category    string              `json:"category"`
+	Type        NodeType            `json:"type" validate:"required,oneof=1 2"`
+	Emoji       string              `json:"emoji"`
+	Content     string              `json:"content"`
+	Children    []*NodeTemplateItem `json:"children" validate:"dive"`
+}
+
+type UpdateNodeTemplateReq struct {
+	ID string `json:"id" validate:"required"`
+	CreateNodeTemplateReq
+}
+
+type DeleteNodeTemplateReq struct {
+	KBID string `json:"kb_id" validate:"required"`
+	ID   string `json:"id" validate:"required"`
+}
+
+type CreateNodeFromTemplateReq struct {
+	KBID       string `json:"kb_id" validate:"required"`
+	TemplateID string `json:"template_id" validate:"required"`
+	ParentID   string `json:"parent_id"`
+	// name of the new node, the template name with placeholders filled if empty
+	Name string `json:"name"`
+	// values of placeholders, {{title}} and {{date}} are filled by default
+	Variables map[string]string `json:"variables"`
+}
+
+// BuiltinNodeTemplates templates available in every kb
+var BuiltinNodeTemplates = []*NodeTemplate{
+	{
+		ID:          BuiltinNodeTemplatePrefix + "runbook",
+		Name:        "{{service}} 运维手册",
+		Description: "服务的日常运维与故障处理步骤",
+		Category:    "runbook",
+		Type:        NodeTypeDocument,
+		Emoji:       "🛠️",
+		Content: strings.Join([]string{
+			"# {{title}}",
+			"",
+			"> 负责人：{{owner}}　更新日期：{{date}}",
+			"",
+			"## 服务概览",
+			"",
+			"简要说明 {{service}} 的作用、依赖和部署位置。",
+			"",
+			"## 监控与告警",
+			"",
+			"| 告警 | 含义 | 处理方式 |",
+			"| --- | --- | --- |",
+			"|  |  |  |",
+			"",
+			"## 常见故障处理",
+			"",
+			"### 故障现象",
+			"",
+			"1. 排查步骤",
+			"2. 恢复步骤",
+			"3. 验证方式",
+			"",
+			"## 升级与回滚",
+			"",
+			"## 联系人",
+			"",
+		}, "\n"),
+		Builtin: true,
+	},
+	{
+		ID:          BuiltinNodeTemplatePrefix + "faq",
+		Name:        "{{product}} 常见问题",
+		Description: "问答形式整理的常见问题",
+		Category:    "faq",
+		Type:        NodeTypeDocument,
+		Emoji:       "❓",
+		Content: strings.Join([]string{
+			"# {{title}}",
+			"",
+			"## 问题一",
+			"",
+			"回答内容。",
+			"",
+			"## 问题二",
+			"",
+			"回答内容。",
+			"",
+			"## 没有找到答案？",
+			"",
+			"请联系 {{contact}}。",
+			"",
+		}, "\n"),
+		Builtin: true,
+	},
+	{
+		ID:          BuiltinNodeTemplatePrefix + "release-note",
+		Name:        "{{version}} 发布说明",
+		Description: "版本发布的新功能、改进与修复",
+		Category:    "release_note",
+		Type:        NodeTypeDocument,
+		Emoji:       "🚀",
+		Content: strings.Join([]string{
+			"# {{title}}",
+			"",
+			"发布日期：{{date}}",
+			"",
+			"## 新功能",
+			"",
+			"- ",
+			"",
+			"## 改进",
+			"",
+			"- ",
+			"",
+			"## 问题修复",
+			"",
+			"- ",
+			"",
+			"## 升级注意事项",
+			"",
+		}, "\n"),
+		Builtin: true,
+	},
+}
@@ -0,0 +1,44 @@
+package domain
+
+import "time"
+
+// OIDCLoginStateTTL time the user has to sign in with the provider
+const OIDCLoginStateTTL = 10 * time.Minute
+
+// OIDCStateCookie cookie of the browser starting a login with the state, checked by the callback against login csrf
+const OIDCStateCookie = "panda_wiki_oidc_state"
+
+var (
+	ErrOIDCDisabled          = NewError(ErrCodeNotFound, "single sign-on is not configured")
+	ErrOIDCStateInvalid      = NewError(ErrCodeUnauthorized, "sign-on request is invalid or expired")
+	ErrOIDCNoRole            = NewError(ErrCodeForbidden, "no role is mapped to the groups of the user")
+	ErrOIDCAccountTaken      = NewError(ErrCodeConflict, "account is taken by a local user")
+	ErrPasswordLoginDisabled = NewError(ErrCodeForbidden, "password login is disabled, sign in with single sign-on")
+)
+
+// OIDCLoginState login in progress, kept until the provider redirects back with its state
+type OIDCLoginState struct {
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+	// path of the console opened after login
+	Redirect string `json:"redirect"`
+}
+
+type OIDCStatusResp struct {
+	Enabled     bool   `json:"enabled"`
+	DisplayName string `json:"display_name,omitempty"`
+	// false if only the built-in admin account may log in with password
+	PasswordLogin bool `json:"password_login"`
+}
+
+type OIDCLoginReq struct {
+	// path of the console opened after login, / if not set
+	Redirect string `json:"redirect" query:"redirect"`
+}
+
+type OIDCCallbackReq struct {
+	Code             string `json:"code" query:"code"`
+	State            string `json:"state" query:"state"`
+	Error            string `json:"error" query:"error"`
+	ErrorDescription string `json:"error_description" query:"error_description"`
+}
@@ -0,0 +1,79 @@
+package domain
+
+type CreateStarterKBReq struct {
+	Name     string   `json:"name" validate:"required"`
+	Ports    []int    `json:"ports"`
+	SSLPorts []int    `json:"ssl_ports"`
+	Hosts    []string `json:"hosts"`
+}
+
+type CreateStarterKBResp struct {
+	KBID    string   `json:"kb_id"`
+	AppID   string   `json:"app_id"`
+	NodeIDs []string `json:"node_ids"`
+}
+
+type OnboardingStep string
+
+const (
+	OnboardingStepModelConfigured OnboardingStep = "model_configured"
+	OnboardingStepContentImported OnboardingStep = "content_imported"
+	OnboardingStepContentReleased OnboardingStep = "content_released"
+	OnboardingStepAppPublished    OnboardingStep = "app_published"
+)
+
+type OnboardingChecklistItem struct {
+	Step OnboardingStep `json:"step"`
+	Done bool           `json:"done"`
+}
+
+type OnboardingChecklistResp struct {
+	KBID      string                     `json:"kb_id"`
+	Items     []*OnboardingChecklistItem `json:"items"`
+	Completed bool                       `json:"completed"`
+}
+
+type StarterNode struct {
+	Name     string
+	Emoji    string
+	Content  string
+	Children []StarterNode
+}
+
+// StarterKBNodes sample structure for starter kb
+var StarterKBNodes = []StarterNode{
+	{
+		Name:  "快速开始",
+		Emoji: "🚀",
+		Children: []StarterNode{
+			{
+				Name:    "欢迎使用 PandaWiki",
+				Emoji:   "👋",
+				Content: "<h1>欢迎使用 PandaWiki</h1><p>这是一篇示例文档，你可以直接编辑或删除它。</p><p>PandaWiki 会根据知识库中已发布的文档回答用户的问题。</p>",
+			},
+			{
+				Name:    "如何导入内容",
+				Emoji:   "📥",
+				Content: "<h1>如何导入内容</h1><p>你可以手动创建文档，也可以通过 URL、RSS、Sitemap、Notion、飞书、EPUB、Wiki.js 等方式批量导入内容。</p>",
+			},
+			{
+				Name:    "如何发布知识库",
+				Emoji:   "📢",
+				Content: "<h1>如何发布知识库</h1><p>编辑完成后，点击“发布”创建一个新版本，已发布的文档才会出现在门户网站和智能问答中。</p>",
+			},
+		},
+	},
+	{
+		Name:    "常见问题",
+		Emoji:   "❓",
+		Content: "<h1>常见问题</h1><p>在这里整理用户最常问的问题和答案。</p>",
+	},
+}
+
+var StarterKBWelcomeStr = "欢迎来到我们的知识库，有什么可以帮你？"
+
+var StarterKBRecommendQuestions = []string{
+	"PandaWiki 是什么？",
+	"如何导入内容？",
+	"如何发布知识库？",
+}
@@ -0,0 +1,53 @@
+package domain
+
+import "github.com/mileusna/useragent"
+
+type DeviceType string
+
+const (
+	DeviceTypeMobile  DeviceType = "mobile"
+	DeviceTypeTablet  DeviceType = "tablet"
+	DeviceTypeDesktop DeviceType = "desktop"
+	DeviceTypeUnknown DeviceType = "unknown"
+)
+
+// ClientPlatform device, browser and os parsed from user agent of the visitor
+type ClientPlatform struct {
+	Device  DeviceType `json:"device"`
+	Browser string     `json:"browser"`
+	OS      string     `json:"os"`
+}
+
+func NewClientPlatform(ua string) ClientPlatform {
+	userAgent := useragent.Parse(ua)
+	device := DeviceTypeUnknown
+	switch {
+	case userAgent.Tablet:
+		device = DeviceTypeTablet
+	case userAgent.Mobile:
+		device = DeviceTypeMobile
+	case userAgent.Desktop:
+		device = DeviceTypeDesktop
+	}
+	return ClientPlatform{
+		Device:  device,
+		Browser: userAgent.Name,
+		OS:      userAgent.OS,
+	}
+}
+
+type GetPlatformStatReq struct {
+	KBID string `json:"kb_id" query:"kb_id" validate:"required"`
+	// conversations of the app type, widget by default
+	AppType AppType `json:"app_type" query:"app_type"`
+	// last days, 7 by default
+	Days int `json:"days" query:"days" validate:"omitempty,min=1,max=90"`
+}
+
+// PlatformStatResp conversation count grouped by device, browser and os
+type PlatformStatResp struct {
+	Total   int64          `json:"total"`
+	Device  []BrowserCount `json:"device"`
+	Browser []BrowserCount `json:"browser"`
+	OS      []BrowserCount `json:"os"`
+}
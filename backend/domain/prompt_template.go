@@ -0,0 +1,169 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultPromptLocale locale variable of chats without the locale of the user, as for bots
+const DefaultPromptLocale = "zh-CN"
+
+var (
+	ErrUnknownPromptVariable  = NewError(ErrCodeInvalidRequest, "unknown variable in prompt template")
+	ErrPromptTemplateConflict = NewError(ErrCodeConflict, "prompt template was saved by someone else, reload and try again")
+)
+
+// variables like {{kb_name}} in prompt templates, filled when the system prompt is built
+var promptVariableRegex = regexp.MustCompile(`\{\{\s*([\w.-]+)\s*\}\}`)
+
+// PromptVariable variable of prompt templates
+type PromptVariable struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// PromptVariables variables available in prompt templates
+var PromptVariables = []PromptVariable{
+	{Name: "kb_name", Description: "知识库名称"},
+	{Name: "app_name", Description: "应用名称"},
+	{Name: "date", Description: "当前日期，如 2006-01-02"},
+	{Name: "locale", Description: "用户语言，如 zh-CN，机器人等未知时为 zh-CN"},
+	{Name: "citation", Description: "应用引用方式对应的引用步骤，模板未使用时追加在末尾"},
+}
+
+// DefaultPromptTemplate built-in system prompt as a template, used by apps without a template
+var DefaultPromptTemplate = fmt.Sprintf(systemPromptTemplate, "{{citation}}")
+
+// table: app_prompt_templates, every save of the template of an app is a new version, the latest is in use
+type AppPromptTemplate struct {
+	ID    string `json:"id" gorm:"primaryKey"`
+	KBID  string `json:"kb_id"`
+	AppID string `json:"app_id"`
+	// from 1 per app
+	Version int `json:"version"`
+	// empty resets the app to the built-in prompt
+	Content   string    `json:"content"`
+	Note      string    `json:"note"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (AppPromptTemplate) TableName() string {
+	return "app_prompt_templates"
+}
+
+// PromptContext values of the variables of a system prompt
+type PromptContext struct {
+	KBName   string
+	AppName  string
+	Locale   string
+	Citation CitationStyle
+	Now      time.Time
+}
+
+// RenderSystemPrompt system prompt of the template, the built-in prompt if the template is empty.
+// citation instructions are appended if the template does not place them, as is the instruction
+// to reply NoAnswerReply if the template does not contain the reply
+func RenderSystemPrompt(template string, c *PromptContext) string {
+	if strings.TrimSpace(template) == "" {
+		return SystemPrompt(c.Citation)
+	}
+	locale := c.Locale
+	if locale == "" {
+		locale = DefaultPromptLocale
+	}
+	values := map[string]string{
+		"kb_name":  c.KBName,
+		"app_name": c.AppName,
+		"date":     c.Now.Format("2006-01-02"),
+		"locale":   locale,
+		"citation": c.Citation.PromptInstructions(),
+	}
+	placed := false
+	prompt := promptVariableRegex.ReplaceAllStringFunc(template, func(match string) string {
+		key := promptVariableRegex.FindStringSubmatch(match)[1]
+		value, ok := values[key]
+		if !ok {
+			return match
+		}
+		if key == "citation" {
+			placed = true
+		}
+		return value
+	})
+	if !placed {
+		prompt = strings.TrimRight(prompt, "\n") + "\n\n" + c.Citation.PromptInstructions() + "\n"
+	}
+	if !strings.Contains(prompt, NoAnswerReply) {
+		prompt = strings.TrimRight(prompt, "\n") + "\n\n" + NoAnswerInstruction + "\n"
+	}
+	return prompt
+}
+
+// ValidatePromptTemplate reject variables which are not in PromptVariables
+func ValidatePromptTemplate(template string) error {
+	for _, match := range promptVariableRegex.FindAllStringSubmatch(template, -1) {
+		known := false
+		for _, variable := range PromptVariables {
+			if variable.Name == match[1] {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("%w: %s", ErrUnknownPromptVariable, match[1])
+		}
+	}
+	return nil
+}
+
+// PromptLocale first language of an Accept-Language header
+func PromptLocale(acceptLanguage string) string {
+	locale, _, _ := strings.Cut(acceptLanguage, ",")
+	locale, _, _ = strings.Cut(locale, ";")
+	return strings.TrimSpace(locale)
+}
+
+type GetAppPromptReq struct {
+	AppID string `json:"app_id" query:"app_id" validate:"required"`
+}
+
+type GetAppPromptResp struct {
+	// latest version of the app, nil if it never saved a template
+	Current *AppPromptTemplate `json:"current"`
+	// built-in template of apps without a template
+	Default   string           `json:"default"`
+	Variables []PromptVariable `json:"variables"`
+}
+
+type SaveAppPromptReq struct {
+	AppID string `json:"app_id" validate:"required"`
+	// empty resets the app to the built-in prompt
+	Content string `json:"content" validate:"max=20000"`
+	Note    string `json:"note" validate:"max=200"`
+}
+
+type RestoreAppPromptReq struct {
+	AppID   string `json:"app_id" validate:"required"`
+	Version int    `json:"version" validate:"required,min=1"`
+}
+
+type PreviewAppPromptReq struct {
+	AppID string `json:"app_id" validate:"required"`
+	// template to preview, the built-in prompt if empty
+	Content string `json:"content" validate:"max=20000"`
+	Locale  string `json:"locale"`
+	// answered with the template by the chat model of the kb if set
+	Question string `json:"question" validate:"max=2000"`
+}
+
+type PreviewAppPromptResp struct {
+	// system prompt of the template
+	Prompt string `json:"prompt"`
+	// answer of the question, error of the model if it failed
+	Answer string `json:"answer,omitempty"`
+	Error  string `json:"error,omitempty"`
+	// documents retrieved for the question
+	Documents []*NodeCotentChunkSSE `json:"documents,omitempty"`
+}
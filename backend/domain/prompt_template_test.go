@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderSystemPrompt(t *testing.T) {
+	c := &PromptContext{KBName: "Docs", AppName: "Bot", Citation: CitationStyleInline, Now: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)}
+	citation := CitationStyleInline.PromptInstructions()
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{name: "built-in prompt", template: "", want: SystemPrompt(CitationStyleInline)},
+		{
+			name:     "citation and no answer instruction appended",
+			template: "You answer about {{kb_name}} on {{date}}.\n",
+			want:     "You answer about Docs on 2026-01-02.\n\n" + citation + "\n\n" + NoAnswerInstruction + "\n",
+		},
+		{
+			name:     "placed citation",
+			template: "{{ app_name }} ({{locale}})\n{{citation}}\nend",
+			want:     "Bot (zh-CN)\n" + citation + "\nend\n\n" + NoAnswerInstruction + "\n",
+		},
+		{
+			name:     "template with the no answer reply",
+			template: "{{citation}}\nReply " + NoAnswerReply + " if unsure.",
+			want:     citation + "\nReply " + NoAnswerReply + " if unsure.",
+		},
+		{
+			name:     "unknown variables are kept",
+			template: "{{citation}} {{other}}",
+			want:     citation + " {{other}}\n\n" + NoAnswerInstruction + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RenderSystemPrompt(tt.template, c)
+			if got != tt.want {
+				t.Errorf("RenderSystemPrompt() = %q, want %q", got, tt.want)
+			}
+			if !strings.Contains(got, NoAnswerReply) {
+				t.Error("prompt does not ask for the no answer reply")
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ProvenanceSettings per app provenance of answers, for downstream systems auditing where an answer came from
+type ProvenanceSettings struct {
+	// record provenance on answers and send it to the client as a provenance event before done
+	Enabled bool `json:"enabled"`
+}
+
+// AnswerProvenance where an answer came from, saved on the answer and included in its webhook events
+type AnswerProvenance struct {
+	// chat model of the answer, empty on low confidence replies which are not generated
+	Provider ModelProvider `json:"provider,omitempty"`
+	Model    string        `json:"model,omitempty"`
+	// latest release of kb when the answer was generated, empty if kb was never released
+	KBID       string `json:"kb_id"`
+	ReleaseID  string `json:"release_id,omitempty"`
+	ReleaseTag string `json:"release_tag,omitempty"`
+	// retrieved documents and chunks in the prompt, by rank
+	NodeIDs     []string  `json:"node_ids"`
+	ChunkIDs    []string  `json:"chunk_ids"`
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
+// NewAnswerProvenance provenance of an answer to the ranked nodes, release is nil if kb was never released
+func NewAnswerProvenance(kbID string, release *KBRelease, model *Model, rankedNodes []*RankedNodeChunks) *AnswerProvenance {
+	p := &AnswerProvenance{
+		KBID:        kbID,
+		NodeIDs:     make([]string, 0, len(rankedNodes)),
+		ChunkIDs:    make([]string, 0),
+		GeneratedAt: time.Now(),
+	}
+	if release != nil {
+		p.ReleaseID = release.ID
+		p.ReleaseTag = release.Tag
+	}
+	if model != nil {
+		p.Provider = model.Provider
+		p.Model = model.Model
+	}
+	for _, node := range rankedNodes {
+		p.NodeIDs = append(p.NodeIDs, node.NodeID)
+		for _, chunk := range node.Chunks {
+			p.ChunkIDs = append(p.ChunkIDs, chunk.ID)
+		}
+	}
+	return p
+}
+
+func (p *AnswerProvenance) Scan(value any) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New(fmt.Sprint("invalid answer provenance value type:", value))
+	}
+	return json.Unmarshal(bytes, p)
+}
+
+func (p AnswerProvenance) Value() (driver.Value, error) {
+	return json.Marshal(p)
+}
@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+const (
+	// QueryRewriteTimeout rewrites taking longer are given up and the question is retrieved as asked
+	QueryRewriteTimeout = 10 * time.Second
+	// QueryRewriteHistoryMessages most recent messages before the question given to the model
+	QueryRewriteHistoryMessages = 6
+	// QueryRewriteMessageRunes each history message given to the model is cut to it
+	QueryRewriteMessageRunes = 500
+	// QueryRewriteMaxRunes longer rewrites are dropped as the model did not follow the prompt
+	QueryRewriteMaxRunes = 200
+)
+
+const queryRewritePrompt = `你是检索查询改写助手。用户在多轮对话中提出了最新的问题，这个问题可能依赖上文，如省略了主语或使用了“它”“那”“Linux 上呢”等指代。
+请结合对话历史，把最新问题改写成一个不依赖上文、可以直接用于文档检索的完整问题：
+- 补全指代和省略的对象、产品、版本、平台等信息
+- 保持用户的语言和原意，不要回答问题，不要添加历史中没有的信息
+- 如果最新问题已经完整，原样输出
+
+只输出改写后的问题，不要输出任何解释。`
+
+// QueryRewritePrompt system prompt of query rewriting
+func QueryRewritePrompt() string {
+	return queryRewritePrompt
+}
+
+// QueryRewriteMessage user message of query rewriting with the recent history before the question
+func QueryRewriteMessage(history []*schema.Message, question string) string {
+	history = history[max(len(history)-QueryRewriteHistoryMessages, 0):]
+	var sb strings.Builder
+	for _, msg := range history {
+		role := "用户"
+		if msg.Role == schema.Assistant {
+			role = "助手"
+		}
+		content := msg.Content
+		if _, after, ok := strings.Cut(content, "</think>"); ok {
+			content = after
+		}
+		content = strings.TrimSpace(content)
+		if runes := []rune(content); len(runes) > QueryRewriteMessageRunes {
+			content = string(runes[:QueryRewriteMessageRunes]) + "..."
+		}
+		fmt.Fprintf(&sb, "%s：%s\n", role, content)
+	}
+	return fmt.Sprintf("对话历史：\n%s\n最新问题：%s", sb.String(), question)
+}
+
+// ParseRewrittenQuery standalone query in the output of the model, empty if the output is not a usable query
+func ParseRewrittenQuery(output string) string {
+	if _, after, ok := strings.Cut(output, "</think>"); ok {
+		output = after
+	}
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		line = strings.TrimPrefix(line, "改写后的问题：")
+		line = strings.Trim(line, " \t\"'“”‘’`")
+		if line == "" {
+			continue
+		}
+		if len([]rune(line)) > QueryRewriteMaxRunes {
+			return ""
+		}
+		return line
+	}
+	return ""
+}
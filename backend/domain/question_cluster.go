@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// QuestionClusterSimilarityThreshold min cosine similarity for a question to join a cluster
+	QuestionClusterSimilarityThreshold = 0.8
+	// QuestionClusterMergeThreshold min cosine similarity between two centroids to merge the clusters
+	QuestionClusterMergeThreshold = 0.9
+	// QuestionClusterSampleSize max paraphrases returned for each cluster
+	QuestionClusterSampleSize = 5
+)
+
+type Vector []float32
+
+func (v *Vector) Scan(value any) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New(fmt.Sprint("invalid vector value type:", value))
+	}
+	return json.Unmarshal(bytes, v)
+}
+
+func (v Vector) Value() (driver.Value, error) {
+	return json.Marshal(v)
+}
+
+// table: stat_question_clusters
+type StatQuestionCluster struct {
+	ID         string    `json:"id" gorm:"primaryKey"`
+	KBID       string    `json:"kb_id" gorm:"index"`
+	Label      string    `json:"label"`
+	LabelScore float64   `json:"label_score"` // similarity between label and centroid
+	Centroid   Vector    `json:"-" gorm:"type:jsonb"`
+	Count      int64     `json:"count"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// table: stat_questions
+type StatQuestion struct {
+	ID             string    `json:"id" gorm:"primaryKey"` // conversation message id
+	KBID           string    `json:"kb_id"`
+	ConversationID string    `json:"conversation_id"`
+	ClusterID      string    `json:"cluster_id" gorm:"index"`
+	Question       string    `json:"question"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type PendingQuestion struct {
+	ID             string    `json:"id"`
+	KBID           string    `json:"kb_id"`
+	ConversationID string    `json:"conversation_id"`
+	Content        string    `json:"content"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type GetQuestionClustersReq struct {
+	KBID      string `json:"kb_id" query:"kb_id" validate:"required"`
+	StartTime int64  `json:"start_time" query:"start_time"` // unix timestamp, default: 24h ago
+	EndTime   int64  `json:"end_time" query:"end_time"`     // unix timestamp, default: now
+	Limit     int    `json:"limit" query:"limit"`           // default: 10, max: 100
+}
+
+type QuestionClusterResp struct {
+	ClusterID string   `json:"cluster_id"`
+	Label     string   `json:"label"`
+	Count     int64    `json:"count"`
+	Questions []string `json:"questions" gorm:"-"` // most asked paraphrases in the cluster
+}
+
+type QuestionClusterSample struct {
+	ClusterID string `json:"cluster_id"`
+	Question  string `json:"question"`
+	Count     int64  `json:"count"`
+}
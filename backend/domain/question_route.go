@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// QuestionRoute how a question was answered, recorded on the answer
+type QuestionRoute string
+
+const (
+	// retrieval and the chat model, including low confidence replies
+	QuestionRouteRAG QuestionRoute = "rag"
+	// greetings and small talk replied with a canned reply
+	QuestionRouteChitChat QuestionRoute = "chitchat"
+	// refused by the content policy of the kb
+	QuestionRouteBlocked QuestionRoute = "blocked"
+	// served the cached answer of a highly similar question, see AnswerCacheSettings
+	QuestionRouteCache QuestionRoute = "cache"
+	// refused by a guardrail check of the app, see GuardrailSettings
+	QuestionRouteGuardrail QuestionRoute = "guardrail"
+)
+
+type ChitChatKind string
+
+const (
+	ChitChatKindGreeting ChitChatKind = "greeting"
+	ChitChatKindThanks   ChitChatKind = "thanks"
+	ChitChatKindFarewell ChitChatKind = "farewell"
+	ChitChatKindAck      ChitChatKind = "ack"
+)
+
+// chitChatMaxRunes longer messages are questions even if they start with a greeting
+const chitChatMaxRunes = 24
+
+var DefaultChitChatReplies = map[ChitChatKind]string{
+	ChitChatKindGreeting: "你好！有什么可以帮你的吗？",
+	ChitChatKindThanks:   "不客气，还有其他问题随时问我。",
+	ChitChatKindFarewell: "再见，欢迎随时回来提问。",
+	ChitChatKindAck:      "好的，还有其他问题随时问我。",
+}
+
+// ChitChatSettings per app routing of greetings and small talk to canned replies, skipping retrieval and the model
+type ChitChatSettings struct {
+	Enabled bool `json:"enabled"`
+	// replies by kind of small talk, the default reply of the kind if not set
+	Replies map[ChitChatKind]string `json:"replies,omitempty"`
+}
+
+// Reply canned reply of the kind
+func (s ChitChatSettings) Reply(kind ChitChatKind) string {
+	if reply := strings.TrimSpace(s.Replies[kind]); reply != "" {
+		return reply
+	}
+	return DefaultChitChatReplies[kind]
+}
+
+var chitChatPhrases = map[string]ChitChatKind{
+	"你好": ChitChatKindGreeting, "您好": ChitChatKindGreeting, "你们好": ChitChatKindGreeting, "大家好": ChitChatKindGreeting,
+	"哈喽": ChitChatKindGreeting, "嗨": ChitChatKindGreeting, "在吗": ChitChatKindGreeting, "在不在": ChitChatKindGreeting,
+	"早上好": ChitChatKindGreeting, "上午好": ChitChatKindGreeting, "中午好": ChitChatKindGreeting, "下午好": ChitChatKindGreeting,
+	"晚上好": ChitChatKindGreeting, "早安": ChitChatKindGreeting, "晚安": ChitChatKindFarewell,
+	"hi": ChitChatKindGreeting, "hello": ChitChatKindGreeting, "hey": ChitChatKindGreeting, "goodmorning": ChitChatKindGreeting,
+	"goodafternoon": ChitChatKindGreeting, "goodevening": ChitChatKindGreeting,
+	"谢谢": ChitChatKindThanks, "多谢": ChitChatKindThanks, "感谢": ChitChatKindThanks, "非常感谢": ChitChatKindThanks,
+	"辛苦了": ChitChatKindThanks, "thanks": ChitChatKindThanks, "thankyou": ChitChatKindThanks, "thx": ChitChatKindThanks,
+	"再见": ChitChatKindFarewell, "拜拜": ChitChatKindFarewell, "回见": ChitChatKindFarewell, "bye": ChitChatKindFarewell,
+	"goodbye": ChitChatKindFarewell, "byebye": ChitChatKindFarewell, "seeyou": ChitChatKindFarewell,
+	"好的": ChitChatKindAck, "好滴": ChitChatKindAck, "收到": ChitChatKindAck, "明白": ChitChatKindAck, "知道了": ChitChatKindAck,
+	"了解": ChitChatKindAck, "嗯": ChitChatKindAck, "ok": ChitChatKindAck, "okay": ChitChatKindAck, "gotit": ChitChatKindAck,
+}
+
+// chitChatFillers words around small talk phrases which do not make them a question
+var chitChatFillers = []string{
+	"你", "您", "们", "大家", "呀", "啊", "啦", "哈", "呢", "哦", "噢", "喔", "嘛", "吧", "哟", "了", "的", "哒",
+	"there", "everyone", "all", "somuch", "verymuch", "alot", "again",
+}
+
+// chitChatPrefixes phrases and fillers, longest first so the longest one at a position is stripped
+var chitChatPrefixes = func() []string {
+	prefixes := make([]string, 0, len(chitChatPhrases)+len(chitChatFillers))
+	for phrase := range chitChatPhrases {
+		prefixes = append(prefixes, phrase)
+	}
+	prefixes = append(prefixes, chitChatFillers...)
+	sort.Slice(prefixes, func(i, j int) bool {
+		if len(prefixes[i]) != len(prefixes[j]) {
+			return len(prefixes[i]) > len(prefixes[j])
+		}
+		return prefixes[i] < prefixes[j]
+	})
+	return prefixes
+}()
+
+// ClassifyChitChat kind of small talk of a message made only of greetings, thanks, farewells or acknowledgements
+// and filler words, ignoring case, spaces, punctuation and emoji. false for anything else, which is retrieved for
+func ClassifyChitChat(message string) (ChitChatKind, bool) {
+	text := strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r) {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, message)
+	if text == "" || utf8.RuneCountInString(text) > chitChatMaxRunes {
+		return "", false
+	}
+	var kind ChitChatKind
+	for text != "" {
+		stripped := false
+		for _, prefix := range chitChatPrefixes {
+			if rest, ok := strings.CutPrefix(text, prefix); ok {
+				if k, ok := chitChatPhrases[prefix]; ok && kind == "" {
+					kind = k
+				}
+				text, stripped = rest, true
+				break
+			}
+		}
+		if !stripped {
+			return "", false
+		}
+	}
+	return kind, kind != ""
+}
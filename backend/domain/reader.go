@@ -0,0 +1,125 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+const (
+	// ReaderTokenIssuer issuer of viewer tokens of reader accounts, signed by the server instead of an sso portal
+	ReaderTokenIssuer = "panda-wiki-reader"
+	ReaderTokenTTL    = 7 * 24 * time.Hour
+	// ReaderLoginLimitPerMinute login attempts of an ip over this in a minute are rejected
+	ReaderLoginLimitPerMinute = 10
+)
+
+var (
+	ErrReaderLoginRequired = NewError(ErrCodeUnauthorized, "reader login required")
+	ErrReaderLoginFailed   = NewError(ErrCodeUnauthorized, "invalid email or password")
+	ErrReaderDisabled      = NewError(ErrCodeForbidden, "reader is disabled")
+	ErrReaderNotFound      = NewError(ErrCodeNotFound, "reader not found")
+	ErrReaderEmailExists   = NewError(ErrCodeConflict, "reader email already exists")
+	ErrReaderLoginLimited  = NewError(ErrCodeRateLimited, "too many login attempts, please try again later")
+)
+
+// ReaderAccess who may read the public site of kb, every viewer if not private
+type ReaderAccess struct {
+	// only logged in viewers, reader accounts or sso users, may use the public site, search and chat.
+	// bot apps are anonymous and answer without documents of a private kb
+	Private bool `json:"private"`
+	// groups of viewers allowed if private, any logged in viewer if empty
+	Groups []string `json:"groups,omitempty" validate:"max=50,dive,required,max=100"`
+}
+
+// Allows whether the viewer may read the kb
+func (a ReaderAccess) Allows(viewer *NodeViewer) bool {
+	if !a.Private {
+		return true
+	}
+	if !viewer.LoggedIn() {
+		return false
+	}
+	return len(a.Groups) == 0 || slices.ContainsFunc(viewer.Groups, func(group string) bool {
+		return slices.Contains(a.Groups, group)
+	})
+}
+
+// table: readers, accounts of end readers of the public site, managed by admins
+type Reader struct {
+	ID    string `json:"id" gorm:"primaryKey"`
+	KBID  string `json:"kb_id"`
+	Email string `json:"email"`
+	Name  string `json:"name"`
+	// bcrypt hash
+	Password string        `json:"-"`
+	Groups   NodeACLGroups `json:"groups" gorm:"type:jsonb"`
+	// dn of readers logging in with the ldap directory, their groups are those of the directory
+	LDAPDN string `json:"ldap_dn,omitempty" gorm:"column:ldap_dn"`
+	// disabled readers can not log in and their tokens are rejected
+	Disabled    bool       `json:"disabled"`
+	LastLoginAt *time.Time `json:"last_login_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func (Reader) TableName() string {
+	return "readers"
+}
+
+// Viewer viewer of the reader, groups are always the current ones of the account
+func (r *Reader) Viewer() *NodeViewer {
+	return &NodeViewer{UserID: r.ID, Groups: r.Groups}
+}
+
+type CreateReaderReq struct {
+	KBID     string   `json:"kb_id" validate:"required"`
+	Email    string   `json:"email" validate:"required,email,max=255"`
+	Name     string   `json:"name" validate:"max=100"`
+	Password string   `json:"password" validate:"required,min=8"`
+	Groups   []string `json:"groups" validate:"max=50,dive,required,max=100"`
+}
+
+type CreateReaderResp struct {
+	ID string `json:"id"`
+}
+
+type UpdateReaderReq struct {
+	ID     string   `json:"id" validate:"required"`
+	KBID   string   `json:"kb_id" validate:"required"`
+	Name   string   `json:"name" validate:"max=100"`
+	Groups []string `json:"groups" validate:"max=50,dive,required,max=100"`
+	// the password is kept if empty
+	Password string `json:"password" validate:"omitempty,min=8"`
+	Disabled bool   `json:"disabled"`
+}
+
+type DeleteReaderReq struct {
+	ID   string `json:"id" query:"id" validate:"required"`
+	KBID string `json:"kb_id" query:"kb_id" validate:"required"`
+}
+
+type ReaderListReq struct {
+	KBID string `json:"kb_id" query:"kb_id" validate:"required"`
+	// part of email or name
+	Search string `json:"search" query:"search"`
+	// readers in the group only if set
+	Group string `json:"group" query:"group"`
+
+	Pager
+}
+
+type ReaderLoginReq struct {
+	Email    string `json:"email" validate:"required"`
+	Password string `json:"password" validate:"required"`
+
+	KBID      string `json:"-"`
+	RemoteIP  string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+// ReaderLoginResp viewer token of the reader, sent as X-Viewer-Token to the share apis
+type ReaderLoginResp struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Reader    *Reader   `json:"reader"`
+}
@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// RerankTimeout answers go on with the retrieval order if the reranker does not respond in time
+const RerankTimeout = 10 * time.Second
+
+// RerankScore scores of a node given to the model, before and after the rerank stage. ranks start at 1
+type RerankScore struct {
+	NodeID string `json:"node_id"`
+	// rank and best chunk similarity from retrieval
+	RankBefore  int     `json:"rank_before"`
+	ScoreBefore float64 `json:"score_before"`
+	// rank and best chunk relevance from the reranker
+	RankAfter  int     `json:"rank_after"`
+	ScoreAfter float64 `json:"score_after"`
+}
+
+// RerankScores scores of the nodes of an answer, nil if the rerank stage was off or failed
+type RerankScores []*RerankScore
+
+func (s *RerankScores) Scan(value any) error {
+	if value == nil {
+		*s = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New(fmt.Sprint("invalid rerank scores value type:", value))
+	}
+	return json.Unmarshal(bytes, s)
+}
+
+func (s RerankScores) Value() (driver.Value, error) {
+	if s == nil {
+		return nil, nil
+	}
+	return json.Marshal([]*RerankScore(s))
+}
+
+// RerankNodes reorder the nodes by the best reranker score of their chunks, scores are of the chunks of all nodes
+// in order. chunks below minScore are dropped with nodes left without chunks, at most topN nodes are kept if set
+func RerankNodes(nodes []*RankedNodeChunks, scores []float64, topN int, minScore float64) []*RankedNodeChunks {
+	type scoredNode struct {
+		node  *RankedNodeChunks
+		score float64
+	}
+	scored := make([]scoredNode, 0, len(nodes))
+	i := 0
+	for rank, node := range nodes {
+		rerank := &RerankScore{NodeID: node.NodeID, RankBefore: rank + 1}
+		chunks := make([]*NodeContentChunk, 0, len(node.Chunks))
+		chunkScores := make(map[*NodeContentChunk]float64, len(node.Chunks))
+		for _, chunk := range node.Chunks {
+			score := scores[i]
+			i++
+			rerank.ScoreBefore = max(rerank.ScoreBefore, chunk.Similarity)
+			if score < minScore {
+				continue
+			}
+			rerank.ScoreAfter = max(rerank.ScoreAfter, score)
+			chunks = append(chunks, chunk)
+			chunkScores[chunk] = score
+		}
+		if len(chunks) == 0 {
+			continue
+		}
+		slices.SortStableFunc(chunks, func(a, b *NodeContentChunk) int {
+			return compareScoresDesc(chunkScores[a], chunkScores[b])
+		})
+		node.Chunks = chunks
+		node.Rerank = rerank
+		scored = append(scored, scoredNode{node: node, score: rerank.ScoreAfter})
+	}
+	slices.SortStableFunc(scored, func(a, b scoredNode) int {
+		return compareScoresDesc(a.score, b.score)
+	})
+	if topN > 0 && len(scored) > topN {
+		scored = scored[:topN]
+	}
+	reranked := make([]*RankedNodeChunks, 0, len(scored))
+	for rank, item := range scored {
+		item.node.Rerank.RankAfter = rank + 1
+		reranked = append(reranked, item.node)
+	}
+	return reranked
+}
+
+func compareScoresDesc(a, b float64) int {
+	switch {
+	case a > b:
+		return -1
+	case a < b:
+		return 1
+	}
+	return 0
+}
+
+// NodesRerankScores scores of the reranked nodes, nil if they were not reranked
+func NodesRerankScores(nodes []*RankedNodeChunks) RerankScores {
+	var scores RerankScores
+	for _, node := range nodes {
+		if node.Rerank != nil {
+			scores = append(scores, node.Rerank)
+		}
+	}
+	return scores
+}
@@ -1,7 +1,8 @@
 package domain
 
 type Response struct {
-	Message string `json:"message"`
-	Success bool   `json:"success"`
-	Data    any    `json:"data,omitempty"`
+	Message string    `json:"message"`
+	Success bool      `json:"success"`
+	Code    ErrorCode `json:"code,omitempty"` // set when success is false
+	Data    any       `json:"data,omitempty"`
 }
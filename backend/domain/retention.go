@@ -0,0 +1,49 @@
+package domain
+
+import "time"
+
+const SettingKeyRetention = "retention"
+
+// RetentionSettings days each kind of data is kept before the hourly retention job removes it, 0 keeps it forever
+type RetentionSettings struct {
+	// conversations with their messages, references and transcript emails, by the start of the conversation
+	ConversationDays int `json:"conversation_days" validate:"min=0"`
+	// messages of conversations kept longer, by the time of the message
+	MessageDays int `json:"message_days" validate:"min=0"`
+	// raw page visits, aggregated stats are kept. at least a day for the realtime stats of the last 24 hours
+	StatEventDays int `json:"stat_event_days" validate:"min=1"`
+	// hourly page visits by location of the geo report
+	GeoStatDays int `json:"geo_stat_days" validate:"min=0"`
+	// visit, search, chat and resolution steps of the funnel report
+	FunnelEventDays int `json:"funnel_event_days" validate:"min=0"`
+	// runs of cron jobs shown in the job dashboard
+	JobRunDays int `json:"job_run_days" validate:"min=0"`
+	// entries of the audit log from the start of the chain, the hash of the last removed entry is kept as the
+	// anchor the remaining chain is verified from
+	AuditLogDays int `json:"audit_log_days" validate:"min=0"`
+	// matches of the guardrail checks of apps
+	GuardrailEventDays int `json:"guardrail_event_days" validate:"min=0"`
+	// abnormal chat traffic detected in kbs, anomalies still blocking an ip are kept
+	AnomalyDays int `json:"anomaly_days" validate:"min=0"`
+	// revoked console sessions by the time they were revoked, expired sessions are removed at once
+	RevokedSessionDays int `json:"revoked_session_days" validate:"min=0"`
+}
+
+// DefaultRetentionSettings retention before it is configured, as data was removed by the former cleanup job
+var DefaultRetentionSettings = RetentionSettings{
+	StatEventDays:      1,
+	GeoStatDays:        90,
+	FunnelEventDays:    90,
+	JobRunDays:         30,
+	GuardrailEventDays: 90,
+	AnomalyDays:        90,
+}
+
+// RetentionCutoff data created before the cutoff is removed, false if kept forever. the cutoff is at the start
+// of the hour so hourly stats cover whole hours
+func RetentionCutoff(now time.Time, days int) (time.Time, bool) {
+	if days <= 0 {
+		return time.Time{}, false
+	}
+	return now.AddDate(0, 0, -days).Truncate(time.Hour), true
+}
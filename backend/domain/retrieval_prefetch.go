@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// RetrievalPrefetchTTL lifetime of documents retrieved for a draft question
+	RetrievalPrefetchTTL = 2 * time.Minute
+	// RetrievalPrefetchMinRunes drafts shorter than this are not retrieved
+	RetrievalPrefetchMinRunes = 4
+	// RetrievalPrefetchLimitPerMinute prefetches of an ip per minute, the widget debounces keystrokes
+	RetrievalPrefetchLimitPerMinute = 60
+)
+
+// PrefetchRetrievalReq draft question of the widget while the user is typing
+type PrefetchRetrievalReq struct {
+	Message string `json:"message" validate:"required"`
+
+	KBID      string `json:"-" validate:"required"`
+	RemoteIP  string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+type PrefetchRetrievalResp struct {
+	// documents of the draft are cached for the message sent with the same question,
+	// false if the draft is too short or the ip prefetches too often
+	Ready bool `json:"ready"`
+}
+
+// NormalizeQuestion key of questions retrieving the same documents, ignoring case, spaces and trailing punctuation
+func NormalizeQuestion(question string) string {
+	normalized := strings.ToLower(strings.Join(strings.Fields(question), " "))
+	return strings.TrimRightFunc(normalized, func(r rune) bool {
+		return unicode.IsPunct(r) || unicode.IsSpace(r)
+	})
+}
@@ -0,0 +1,185 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+const (
+	DefaultSandboxTTL = 2 * time.Hour
+	// documents pushed in one request
+	MaxSandboxPushNodes = 200
+	// questions evaluated in one request
+	MaxSandboxEvalQuestions = 50
+	DefaultSandboxEvalTopK  = 3
+	// questions answered and judged at the same time
+	SandboxEvalConcurrency = 4
+)
+
+var (
+	ErrSandboxNotFound = NewError(ErrCodeKBNotFound, "sandbox kb not found")
+	ErrSandboxLimit    = NewError(ErrCodeQuotaExceeded, "sandbox kb limit of the token is reached")
+)
+
+type CreateSandboxReq struct {
+	Name string `json:"name" validate:"required,max=100"`
+	// minutes until the sandbox is torn down if the pipeline does not, 120 if not set
+	TTLMinutes int `json:"ttl_minutes" validate:"omitempty,min=5,max=1440"`
+	// retrieval and confidence gate settings of the sandbox, defaults if not set
+	AnswerSettings *AnswerSettings `json:"answer_settings"`
+}
+
+type SandboxKB struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type SandboxKBReq struct {
+	KBID string `json:"kb_id" query:"kb_id" validate:"required"`
+}
+
+type SandboxNode struct {
+	Name    string `json:"name" validate:"required"`
+	Content string `json:"content"`
+}
+
+// PushSandboxNodesReq create documents in the sandbox and publish them, they are indexed in background
+type PushSandboxNodesReq struct {
+	KBID  string         `json:"kb_id" validate:"required"`
+	Nodes []*SandboxNode `json:"nodes" validate:"required,min=1,max=200,dive"`
+}
+
+type PushSandboxNodesResp struct {
+	NodeIDs   []string `json:"node_ids"`
+	ReleaseID string   `json:"release_id"`
+}
+
+// SandboxStatusResp whether the published documents of the sandbox are indexed and questions can be evaluated
+type SandboxStatusResp struct {
+	NodeCount    int64 `json:"node_count"`
+	IndexedCount int64 `json:"indexed_count"`
+	Ready        bool  `json:"ready"`
+}
+
+type SandboxEvalQuestion struct {
+	Question string `json:"question" validate:"required"`
+	// ids or names of documents one of which must be retrieved in the top k, any document if empty
+	ExpectedNodes []string `json:"expected_nodes"`
+	// min retrieval score of the question, the confidence gate of the sandbox if not set
+	MinConfidence float64 `json:"min_confidence" validate:"min=0,max=1"`
+	// reference answer the judge compares the answer with, the answer is judged by the question and documents if empty
+	ExpectedAnswer string `json:"expected_answer" validate:"max=10000"`
+}
+
+// SandboxEvalReq answer the questions from the sandbox as chat would, check the retrieved documents against
+// expectations and score the answers with the chat model as judge
+type SandboxEvalReq struct {
+	KBID      string                 `json:"kb_id" validate:"required"`
+	Questions []*SandboxEvalQuestion `json:"questions" validate:"required,min=1,max=50,dive"`
+	// retrieved documents checked for expected ones, 3 if not set
+	TopK int `json:"top_k" validate:"omitempty,min=1,max=20"`
+	// share of questions which must pass for the evaluation to pass, all if not set
+	MinPassRate float64 `json:"min_pass_rate" validate:"min=0,max=1"`
+}
+
+type SandboxEvalResult struct {
+	Question       string   `json:"question"`
+	Passed         bool     `json:"passed"`
+	RetrievalScore float64  `json:"retrieval_score"`
+	Groundedness   float64  `json:"groundedness"`
+	Gated          bool     `json:"gated"`
+	NodeIDs        []string `json:"node_ids"`
+	NodeNames      []string `json:"node_names"`
+	// answer of the chat model, empty if the confidence gate replied reference links only
+	Answer string `json:"answer"`
+	// scores of the answer by the judge, 0-1
+	Faithfulness float64 `json:"faithfulness"`
+	Relevance    float64 `json:"relevance"`
+	JudgeReason  string  `json:"judge_reason,omitempty"`
+	// why the question failed
+	Reason string `json:"reason,omitempty"`
+}
+
+type SandboxEvalResp struct {
+	Total       int                  `json:"total"`
+	PassedCount int                  `json:"passed_count"`
+	PassRate    float64              `json:"pass_rate"`
+	Passed      bool                 `json:"passed"`
+	Results     []*SandboxEvalResult `json:"results"`
+}
+
+// Evaluate check the top k retrieved documents of the question against its expectations,
+// gated whether the confidence gate of the sandbox would reply reference links only
+func (q *SandboxEvalQuestion) Evaluate(confidence AnswerConfidence, gated bool, nodes []*RankedNodeChunks, topK int) *SandboxEvalResult {
+	result := &SandboxEvalResult{
+		Question:       q.Question,
+		RetrievalScore: confidence.RetrievalScore,
+		Groundedness:   confidence.Groundedness,
+		Gated:          gated,
+		NodeIDs:        []string{},
+		NodeNames:      []string{},
+	}
+	if len(nodes) > topK {
+		nodes = nodes[:topK]
+	}
+	matched := len(q.ExpectedNodes) == 0 && len(nodes) > 0
+	for _, node := range nodes {
+		result.NodeIDs = append(result.NodeIDs, node.NodeID)
+		result.NodeNames = append(result.NodeNames, node.NodeName)
+		if slices.Contains(q.ExpectedNodes, node.NodeID) || slices.Contains(q.ExpectedNodes, node.NodeName) {
+			matched = true
+		}
+	}
+	switch {
+	case len(nodes) == 0:
+		result.Reason = "no document retrieved"
+	case !matched:
+		result.Reason = "expected documents not in top results"
+	case q.MinConfidence > 0 && confidence.RetrievalScore < q.MinConfidence:
+		result.Reason = "retrieval score below min confidence"
+	case q.MinConfidence == 0 && gated:
+		result.Reason = "answer gated by confidence gate"
+	default:
+		result.Passed = true
+	}
+	return result
+}
+
+// Judge record the scores of the answer, the question fails if either is below EvalPassScore
+func (r *SandboxEvalResult) Judge(answer string, judgement *EvalJudgement) {
+	r.Answer = answer
+	r.Faithfulness = judgement.Faithfulness
+	r.Relevance = judgement.Relevance
+	r.JudgeReason = judgement.Reason
+	if r.Passed && (judgement.Faithfulness < EvalPassScore || judgement.Relevance < EvalPassScore) {
+		r.Passed = false
+		r.Reason = "answer scored below pass score"
+	}
+}
+
+// Fail fail the question because the answer could not be generated or judged
+func (r *SandboxEvalResult) Fail(reason string) {
+	r.Passed = false
+	r.Reason = reason
+}
+
+// NewSandboxEvalResp summary of the results, passed if the pass rate reaches minPassRate, or all passed if it is 0
+func NewSandboxEvalResp(results []*SandboxEvalResult, minPassRate float64) *SandboxEvalResp {
+	resp := &SandboxEvalResp{Total: len(results), Results: results}
+	for _, result := range results {
+		if result.Passed {
+			resp.PassedCount++
+		}
+	}
+	if resp.Total > 0 {
+		resp.PassRate = float64(resp.PassedCount) / float64(resp.Total)
+	}
+	if minPassRate == 0 {
+		resp.Passed = resp.PassedCount == resp.Total
+	} else {
+		resp.Passed = resp.PassRate >= minPassRate
+	}
+	return resp
+}
@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	DefaultNodeSearchLimit = 10
+	MaxNodeSearchLimit     = 50
+	// OpenSearchShortNameMaxLen max length of ShortName in opensearch description
+	OpenSearchShortNameMaxLen = 16
+)
+
+type NodeSearchReq struct {
+	Query string `json:"q" query:"q" validate:"required"`
+	Limit int    `json:"limit" query:"limit" validate:"omitempty,min=1,max=50"`
+
+	KBID   string      `json:"-"`
+	Viewer *NodeViewer `json:"-"`
+}
+
+type NodeSearchResult struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Summary   string    `json:"summary"`
+	Emoji     string    `json:"emoji"`
+	URL       string    `json:"url" gorm:"-"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (n *NodeSearchResult) GetURL(baseURL string) string {
+	return fmt.Sprintf("%s/node/%s", baseURL, n.ID)
+}
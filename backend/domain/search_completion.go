@@ -0,0 +1,190 @@
+package domain
+
+import (
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	DefaultSearchCompletionLimit = 8
+	// PopularQueryMinCount questions asked fewer times are not suggested, they may contain personal data
+	PopularQueryMinCount = 3
+	// PopularQueryMaxLen longer questions are not useful completions
+	PopularQueryMaxLen = 64
+	// PopularQueryWindow questions asked in the window are suggested
+	PopularQueryWindow = 90 * 24 * time.Hour
+	// PopularQueryLimit max popular questions loaded as candidates
+	PopularQueryLimit = 500
+	// SearchCompletionCacheTTL candidates of a kb are cached for the ttl
+	SearchCompletionCacheTTL = 5 * time.Minute
+)
+
+type SearchCompletionType string
+
+const (
+	SearchCompletionTypeNode  SearchCompletionType = "node"
+	SearchCompletionTypeQuery SearchCompletionType = "query"
+)
+
+type SearchCompletionReq struct {
+	Query string `json:"q" query:"q" validate:"required"`
+	Limit int    `json:"limit" query:"limit" validate:"omitempty,min=1,max=20"`
+
+	KBID   string      `json:"-"`
+	Viewer *NodeViewer `json:"-"`
+}
+
+type SearchCompletion struct {
+	Text string               `json:"text"`
+	Type SearchCompletionType `json:"type"`
+	// node of title completions
+	NodeID string `json:"node_id,omitempty"`
+	URL    string `json:"url,omitempty"`
+	// times asked of query completions
+	Count int64 `json:"count,omitempty"`
+
+	score float64
+}
+
+// PopularQuery question asked repeatedly in a kb
+type PopularQuery struct {
+	Text  string
+	Count int64
+}
+
+// RankSearchCompletions candidates matching query by prefix, word prefix, substring or within a few typos,
+// better matches first and popular queries first among equal matches
+func RankSearchCompletions(query string, candidates []*SearchCompletion, limit int) []*SearchCompletion {
+	q := []rune(strings.ToLower(strings.TrimSpace(query)))
+	if len(q) == 0 {
+		return []*SearchCompletion{}
+	}
+	matched := make([]*SearchCompletion, 0)
+	seen := make(map[string]bool)
+	for _, candidate := range candidates {
+		key := strings.ToLower(candidate.Text)
+		if seen[key] {
+			continue
+		}
+		score := completionScore(q, []rune(key))
+		if score <= 0 {
+			continue
+		}
+		seen[key] = true
+		c := *candidate
+		c.score = score
+		matched = append(matched, &c)
+	}
+	slices.SortStableFunc(matched, func(a, b *SearchCompletion) int {
+		switch {
+		case a.score != b.score:
+			if a.score > b.score {
+				return -1
+			}
+			return 1
+		case a.Count != b.Count:
+			if a.Count > b.Count {
+				return -1
+			}
+			return 1
+		}
+		return len([]rune(a.Text)) - len([]rune(b.Text))
+	})
+	return matched[:min(len(matched), limit)]
+}
+
+// completionScore 0 when text does not match query
+func completionScore(query, text []rune) float64 {
+	switch {
+	case hasRunePrefix(text, query):
+		return 4
+	case wordHasPrefix(text, query):
+		return 3
+	case strings.Contains(string(text), string(query)):
+		return 2
+	}
+	// typo tolerance: the beginning of text or of a word is close to query
+	allowed := typoAllowance(len(query))
+	if allowed == 0 {
+		return 0
+	}
+	best := -1
+	for _, start := range wordStarts(text) {
+		// prefixes one rune shorter or longer tolerate a missing or an extra rune
+		for n := len(query) - 1; n <= len(query)+1; n++ {
+			prefix := text[start:min(len(text), start+n)]
+			if d := editDistance(query, prefix); d <= allowed && (best < 0 || d < best) {
+				best = d
+			}
+		}
+	}
+	if best < 0 {
+		return 0
+	}
+	return 1 - float64(best)/float64(allowed+1)
+}
+
+// typoAllowance edits tolerated for a query of n runes, short queries must match exactly
+func typoAllowance(n int) int {
+	switch {
+	case n >= 8:
+		return 2
+	case n >= 4:
+		return 1
+	}
+	return 0
+}
+
+func wordStarts(text []rune) []int {
+	starts := make([]int, 0)
+	for i, r := range text {
+		if !isWordRune(r) {
+			continue
+		}
+		// every han character starts a word, han text is not separated by spaces
+		if i == 0 || !isWordRune(text[i-1]) || unicode.Is(unicode.Han, r) {
+			starts = append(starts, i)
+		}
+	}
+	return starts
+}
+
+func wordHasPrefix(text, query []rune) bool {
+	for _, start := range wordStarts(text) {
+		if start > 0 && hasRunePrefix(text[start:], query) {
+			return true
+		}
+	}
+	return false
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// editDistance optimal string alignment distance, adjacent transpositions count as one edit
+func editDistance(a, b []rune) int {
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(b)]
+}
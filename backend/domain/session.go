@@ -0,0 +1,82 @@
+package domain
+
+import "time"
+
+// SessionTTL console sessions expire with their token
+const SessionTTL = 24 * time.Hour
+
+const (
+	// ClaimSessionID claim of console tokens carrying the id of their session
+	ClaimSessionID = "sid"
+	// ContextKeySessionID set on the echo context by the auth middleware, the session of the request
+	ContextKeySessionID = "session_id"
+)
+
+var (
+	ErrSessionNotFound = NewError(ErrCodeNotFound, "session not found")
+	ErrSessionRevoked  = NewError(ErrCodeUnauthorized, "session is revoked or expired, log in again")
+)
+
+// SessionClient client logging in, recorded on its session
+type SessionClient struct {
+	RemoteIP  string
+	UserAgent string
+}
+
+// table: user_sessions, a console login, revoked sessions are kept until they expire
+type UserSession struct {
+	ID        string     `json:"id" gorm:"primaryKey"`
+	UserID    string     `json:"user_id"`
+	RemoteIP  string     `json:"remote_ip"`
+	UserAgent string     `json:"user_agent"`
+	Device    DeviceType `json:"device"`
+	Browser   string     `json:"browser"`
+	OS        string     `json:"os" gorm:"column:os"`
+	// last request with the session, updated every minute
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func (UserSession) TableName() string {
+	return "user_sessions"
+}
+
+// NewUserSession session of the user logging in with the client
+func NewUserSession(id, userID string, client *SessionClient, now time.Time) *UserSession {
+	platform := NewClientPlatform(client.UserAgent)
+	return &UserSession{
+		ID:         id,
+		UserID:     userID,
+		RemoteIP:   client.RemoteIP,
+		UserAgent:  client.UserAgent,
+		Device:     platform.Device,
+		Browser:    platform.Browser,
+		OS:         platform.OS,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(SessionTTL),
+		CreatedAt:  now,
+	}
+}
+
+type SessionListReq struct {
+	// sessions of the user, every user for admins if empty, members only get their own
+	UserID string `json:"user_id" query:"user_id"`
+}
+
+type SessionListItem struct {
+	*UserSession
+	Account string `json:"account"`
+	// the session of the request
+	Current bool `json:"current"`
+}
+
+type RevokeSessionReq struct {
+	ID string `json:"id" query:"id" validate:"required"`
+}
+
+type RevokeUserSessionsReq struct {
+	// members may only revoke their own sessions
+	UserID string `json:"user_id" validate:"required"`
+}
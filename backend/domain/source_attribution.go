@@ -0,0 +1,65 @@
+package domain
+
+type MessageFeedbackType string
+
+const (
+	MessageFeedbackLike    MessageFeedbackType = "like"
+	MessageFeedbackDislike MessageFeedbackType = "dislike"
+)
+
+const (
+	DefaultSourceAttributionDays = 30
+	// SourceAttributionItemLimit max documents of the attribution report
+	SourceAttributionItemLimit = 50
+)
+
+var ErrFeedbackMessageNotFound = NewError(ErrCodeNotFound, "answer of the conversation not found")
+
+// MessageFeedbackReq end user rating an answer of the conversation
+type MessageFeedbackReq struct {
+	ConversationID string `json:"conversation_id" validate:"required"`
+	Nonce          string `json:"nonce" validate:"required"`
+	// latest answer of the conversation if empty
+	MessageID string              `json:"message_id"`
+	Type      MessageFeedbackType `json:"type" validate:"required,oneof=like dislike"`
+	Comment   string              `json:"comment" validate:"max=500"`
+
+	KBID string `json:"-"`
+	// web session for funnel analytics, a like resolves it
+	SessionID string `json:"-"`
+}
+
+type SourceAttributionReq struct {
+	KBID string `json:"kb_id" query:"kb_id" validate:"required"`
+	Days int    `json:"days" query:"days" validate:"omitempty,min=1,max=365"`
+}
+
+// SourceAttributionItem answers citing the document and the feedback they got
+type SourceAttributionItem struct {
+	NodeID            string `json:"node_id"`
+	Name              string `json:"name"`
+	ConversationCount int64  `json:"conversation_count"`
+	AnswerCount       int64  `json:"answer_count"`
+	LikeCount         int64  `json:"like_count"`
+	DislikeCount      int64  `json:"dislike_count"`
+	// likes of rated answers, 0 if none is rated
+	SatisfactionRate float64 `json:"satisfaction_rate" gorm:"-"`
+}
+
+// SourceAttributionReport documents most cited by answers of the recent days, most cited first
+type SourceAttributionReport struct {
+	Days int `json:"days"`
+	// answers of the period and how they were rated
+	AnswerCount  int64                    `json:"answer_count"`
+	LikeCount    int64                    `json:"like_count"`
+	DislikeCount int64                    `json:"dislike_count"`
+	Items        []*SourceAttributionItem `json:"items"`
+}
+
+// SatisfactionRate likes of rated answers, 0 if none is rated
+func SatisfactionRate(likes, dislikes int64) float64 {
+	if likes+dislikes == 0 {
+		return 0
+	}
+	return float64(likes) / float64(likes+dislikes)
+}
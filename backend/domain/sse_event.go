@@ -1,8 +1,64 @@
 package domain
 
+// event types of chat streams besides data, error and done
+const (
+	// a document cited by the answer, sent as soon as the streamed answer cites it
+	SSEEventReference = "reference"
+	// follow-up questions, sent after the answer and before done
+	SSEEventSuggestions = "suggestions"
+	// a guardrail check with the warn action matched the question or the answer
+	SSEEventGuardrail = "guardrail"
+)
+
 type SSEEvent struct {
 	Type        string              `json:"type"`
 	Content     string              `json:"content"`
 	ChunkResult *NodeCotentChunkSSE `json:"chunk_result,omitempty"`
 	Error       string              `json:"error,omitempty"`
+	Code        ErrorCode           `json:"code,omitempty"` // code of error events
+	// provenance of the answer, on provenance events
+	Provenance *AnswerProvenance `json:"provenance,omitempty"`
+	// cited document, on reference events
+	Reference *SSEReference `json:"reference,omitempty"`
+	// follow-up questions, on suggestions events
+	Suggestions []string `json:"suggestions,omitempty"`
+	// follow-up questions with the documents answering them, rendered as chips, on suggestions events
+	FollowUps FollowUpQuestions `json:"follow_ups,omitempty"`
+	// matched check, on guardrail events
+	Guardrail *GuardrailViolation `json:"guardrail,omitempty"`
+	// completion metadata of the answer, on done events
+	Metadata *SSEDoneMetadata `json:"metadata,omitempty"`
+}
+
+// SSEReference document cited by a streamed answer
+type SSEReference struct {
+	// number of the citation in the answer, position of the card for cards citations
+	Index  int    `json:"index"`
+	NodeID string `json:"node_id"`
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+}
+
+// SSEDoneMetadata answer of a chat stream once it is complete
+type SSEDoneMetadata struct {
+	ConversationID string        `json:"conversation_id"`
+	MessageID      string        `json:"message_id"`
+	Route          QuestionRoute `json:"route"`
+	// model which served the answer and the models skipped before it, empty for answers without the model
+	Provider  ModelProvider `json:"provider,omitempty"`
+	Model     string        `json:"model,omitempty"`
+	ModelID   string        `json:"model_id,omitempty"`
+	Failovers int           `json:"failovers"`
+	// version of the prompt template of the app, 0 for the built-in prompt
+	PromptTemplateVersion int `json:"prompt_template_version"`
+
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+
+	Confidence    float64 `json:"confidence"`
+	LowConfidence bool    `json:"low_confidence"`
+	// milliseconds until the first chunk of the answer and until the answer completed
+	FirstChunkMs int64 `json:"first_chunk_ms"`
+	DurationMs   int64 `json:"duration_ms"`
 }
@@ -24,12 +24,22 @@ type StatPage struct {
 	BrowserOS   string        `json:"browser_os"`
 	Referer     string        `json:"referer"`
 	RefererHost string        `json:"referer_host"`
+	UTMSource   string        `json:"utm_source"`
+	UTMMedium   string        `json:"utm_medium"`
+	UTMCampaign string        `json:"utm_campaign"`
+	UTMTerm     string        `json:"utm_term"`
+	UTMContent  string        `json:"utm_content"`
+	IsBot       bool          `json:"is_bot"`
 	CreatedAt   time.Time     `json:"created_at"`
 }
 
 type StatPageReq struct {
 	Scene  StatPageScene `json:"scene" validate:"required,oneof=1 2 3 4"`
 	NodeID string        `json:"node_id"`
+	// document.referrer of the page, fallback to Referer header if empty
+	Referer string `json:"referer"`
+	// location.href of the page, utm parameters are parsed from it
+	URL string `json:"url"`
 }
 
 type HotPageResp struct {
@@ -79,3 +89,10 @@ type ConversationDistributionResp struct {
 	AppID   string  `json:"app_id"`
 	Count   int     `json:"count"`
 }
+
+type StatRealtimeResp struct {
+	ActiveConversationCount int64            `json:"active_conversation_count"`
+	RequestsPerMinute       int64            `json:"requests_per_minute"`
+	Geo                     map[string]int64 `json:"geo"`
+	Time                    time.Time        `json:"time"`
+}
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+const (
+	// BotPageViewLimitPerMinute page views of an ip over this in a minute are marked as bot
+	BotPageViewLimitPerMinute = 60
+	// BotConversationLimitPerMinute conversations of an ip over this in a minute are marked as bot
+	BotConversationLimitPerMinute = 10
+)
+
+// StatSettings per kb stat settings
+type StatSettings struct {
+	// exclude rows marked as bot from page view and conversation stats
+	FilterBotTraffic bool `json:"filter_bot_traffic"`
+}
+
+func (s *StatSettings) Scan(value any) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New(fmt.Sprint("invalid stat settings value type:", value))
+	}
+	return json.Unmarshal(bytes, s)
+}
+
+func (s StatSettings) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type StatEventType string
+
+const (
+	StatEventTypePage         StatEventType = "page"
+	StatEventTypeDwell        StatEventType = "dwell"
+	StatEventTypeFunnel       StatEventType = "funnel"
+	StatEventTypeConversation StatEventType = "conversation"
+)
+
+// StatEvent stat event shipped to external analytics through mq
+type StatEvent struct {
+	ID        string          `json:"id"`
+	Type      StatEventType   `json:"type"`
+	KBID      string          `json:"kb_id"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"created_at"`
+}
@@ -0,0 +1,52 @@
+package domain
+
+import "time"
+
+type FunnelStep string
+
+const (
+	FunnelStepVisit      FunnelStep = "visit"
+	FunnelStepSearch     FunnelStep = "search"
+	FunnelStepChat       FunnelStep = "chat"
+	FunnelStepResolution FunnelStep = "resolution"
+)
+
+// FunnelSteps in funnel order
+var FunnelSteps = []FunnelStep{
+	FunnelStepVisit,
+	FunnelStepSearch,
+	FunnelStepChat,
+	FunnelStepResolution,
+}
+
+// table: stat_funnel_events
+type StatFunnelEvent struct {
+	ID        int64      `json:"id" gorm:"primaryKey;autoIncrement"`
+	KBID      string     `json:"kb_id"`
+	SessionID string     `json:"session_id"`
+	Step      FunnelStep `json:"step"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// StatFunnelEventReq steps reported by the frontend, visit, chat and resolution by a liked answer are recorded by the server
+type StatFunnelEventReq struct {
+	Step FunnelStep `json:"step" validate:"required,oneof=search resolution"`
+}
+
+type GetFunnelStatReq struct {
+	KBID      string `json:"kb_id" query:"kb_id" validate:"required"`
+	StartTime int64  `json:"start_time" query:"start_time"` // unix timestamp, default: 7d ago
+	EndTime   int64  `json:"end_time" query:"end_time"`     // unix timestamp, default: now
+}
+
+type FunnelStepStat struct {
+	Step FunnelStep `json:"step"`
+	// sessions reached this step
+	Sessions int64 `json:"sessions"`
+	// sessions reached this step and all previous steps
+	Converted int64 `json:"converted"`
+	// converted / converted of previous step
+	ConversionRate float64 `json:"conversion_rate"`
+	// converted / sessions of the first step
+	OverallRate float64 `json:"overall_rate"`
+}
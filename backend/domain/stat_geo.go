@@ -0,0 +1,47 @@
+package domain
+
+import "time"
+
+// WebAppIDCacheTTL page visits are counted to the web app of the kb, its id is cached for the ttl
+const WebAppIDCacheTTL = 10 * time.Minute
+
+// table: stat_geo_hourly
+type StatGeoHourly struct {
+	KBID     string    `json:"kb_id" gorm:"primaryKey"`
+	AppID    string    `json:"app_id" gorm:"primaryKey"`
+	Hour     time.Time `json:"hour" gorm:"primaryKey"`
+	Country  string    `json:"country" gorm:"primaryKey"`
+	Province string    `json:"province" gorm:"primaryKey"`
+	City     string    `json:"city" gorm:"primaryKey"`
+	Count    int64     `json:"count"`
+}
+
+func (StatGeoHourly) TableName() string {
+	return "stat_geo_hourly"
+}
+
+type GetGeoStatReq struct {
+	KBID      string `json:"kb_id" query:"kb_id" validate:"required"`
+	AppID     string `json:"app_id" query:"app_id"`         // optional, all apps if empty
+	StartTime int64  `json:"start_time" query:"start_time"` // unix timestamp, default: 24h ago
+	EndTime   int64  `json:"end_time" query:"end_time"`     // unix timestamp, default: now
+}
+
+type GeoCount struct {
+	Country  string `json:"country"`
+	Province string `json:"province"`
+	City     string `json:"city"`
+	Count    int64  `json:"count"`
+}
+
+// GeoStatNode name/value tree of country > province > city, can be rendered by map charts directly
+type GeoStatNode struct {
+	Name     string         `json:"name"`
+	Value    int64          `json:"value"`
+	Children []*GeoStatNode `json:"children,omitempty"`
+}
+
+type GeoStatResp struct {
+	Total     int64          `json:"total"`
+	Countries []*GeoStatNode `json:"countries"`
+}
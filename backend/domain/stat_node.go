@@ -0,0 +1,42 @@
+package domain
+
+import "time"
+
+// StatNodeMaxDwell dwell time longer than this is truncated, the page was probably left open
+const StatNodeMaxDwell = 30 * time.Minute
+
+// table: stat_node_daily
+type StatNodeDaily struct {
+	KBID       string    `json:"kb_id"`
+	NodeID     string    `json:"node_id" gorm:"primaryKey"`
+	Date       time.Time `json:"date" gorm:"primaryKey;type:date"`
+	Views      int64     `json:"views"`
+	Visitors   int64     `json:"visitors"`    // unique visitors of the day
+	DwellTotal int64     `json:"dwell_total"` // milliseconds
+	DwellCount int64     `json:"dwell_count"`
+	Citations  int64     `json:"citations"` // times cited as rag reference
+}
+
+func (StatNodeDaily) TableName() string {
+	return "stat_node_daily"
+}
+
+type StatNodeDwellReq struct {
+	NodeID   string `json:"node_id" validate:"required"`
+	Duration int64  `json:"duration" validate:"required,min=1"` // milliseconds on page
+}
+
+type GetNodeStatsReq struct {
+	KBID   string `json:"kb_id" query:"kb_id" validate:"required"`
+	NodeID string `json:"node_id" query:"node_id"`                       // optional, all nodes if empty
+	Days   int    `json:"days" query:"days" validate:"omitempty,max=90"` // default: 7
+}
+
+type NodeStatResp struct {
+	NodeID    string `json:"node_id"`
+	NodeName  string `json:"node_name" gorm:"-"`
+	Views     int64  `json:"views"`
+	Visitors  int64  `json:"visitors"`  // sum of daily unique visitors
+	AvgDwell  int64  `json:"avg_dwell"` // milliseconds
+	Citations int64  `json:"citations"`
+}
@@ -0,0 +1,97 @@
+package domain
+
+import "time"
+
+const SettingKeyTelemetry = "telemetry"
+
+// TelemetryReportDays days of usage covered by the telemetry report
+const TelemetryReportDays = 7
+
+// TelemetryUsageKeepDays days of usage kept in the local database
+const TelemetryUsageKeepDays = 30
+
+// ContextKeyResponseError set on the echo context by handlers responding with an error, counted as failed by telemetry
+const ContextKeyResponseError = "response_error"
+
+// TelemetrySettings anonymous usage telemetry, off until the operator opts in
+type TelemetrySettings struct {
+	// aggregate daily usage of api features in the local database, nothing leaves the deployment
+	Enabled bool `json:"enabled"`
+	// send the report shown to the operator to the report url of the config once a day, requires enabled
+	ReportEnabled bool `json:"report_enabled"`
+	// random id of the deployment in reports, generated when telemetry is first enabled
+	InstanceID string     `json:"instance_id"`
+	ReportedAt *time.Time `json:"reported_at"`
+}
+
+type UpdateTelemetrySettingsReq struct {
+	Enabled       bool `json:"enabled"`
+	ReportEnabled bool `json:"report_enabled"`
+}
+
+type TelemetrySettingsResp struct {
+	TelemetrySettings
+	// upstream collector configured for the deployment, reports can not be sent if empty
+	ReportURL string `json:"report_url"`
+}
+
+// table: telemetry_usage_daily
+type TelemetryUsage struct {
+	Day time.Time `json:"day" gorm:"primaryKey;type:date"`
+	// method and route of the api, without ids or query
+	Feature  string `json:"feature" gorm:"primaryKey"`
+	Requests int64  `json:"requests"`
+	Errors   int64  `json:"errors"`
+}
+
+func (TelemetryUsage) TableName() string {
+	return "telemetry_usage_daily"
+}
+
+// TelemetryFeature feature name of a request by its route, not the requested url
+func TelemetryFeature(method, route string) string {
+	return method + " " + route
+}
+
+// TelemetryCounter usage of a feature since the last flush
+type TelemetryCounter struct {
+	Requests int64
+	Errors   int64
+}
+
+type TelemetryFeatureUsage struct {
+	Feature   string  `json:"feature"`
+	Requests  int64   `json:"requests"`
+	Errors    int64   `json:"errors"`
+	ErrorRate float64 `json:"error_rate"`
+}
+
+// TelemetryDeployment size of the deployment, counts only
+type TelemetryDeployment struct {
+	KBCount    int64 `json:"kb_count"`
+	NodeCount  int64 `json:"node_count"`
+	AppCount   int64 `json:"app_count"`
+	UserCount  int64 `json:"user_count"`
+	ModelCount int64 `json:"model_count"`
+}
+
+// TelemetryReport anonymous metrics of the deployment, exactly what is sent upstream when reporting is enabled
+type TelemetryReport struct {
+	InstanceID  string    `json:"instance_id"`
+	GeneratedAt time.Time `json:"generated_at"`
+	// first day of the usage covered, the report covers TelemetryReportDays days up to today
+	Since      string                   `json:"since"`
+	Deployment TelemetryDeployment      `json:"deployment"`
+	Requests   int64                    `json:"requests"`
+	Errors     int64                    `json:"errors"`
+	ErrorRate  float64                  `json:"error_rate"`
+	Features   []*TelemetryFeatureUsage `json:"features"`
+}
+
+// TelemetryErrorRate errors per request, 0 without requests
+func TelemetryErrorRate(requests, errors int64) float64 {
+	if requests == 0 {
+		return 0
+	}
+	return float64(errors) / float64(requests)
+}
@@ -0,0 +1,64 @@
+package domain
+
+import "net/url"
+
+type TrafficSourceDimension string
+
+const (
+	TrafficSourceDimensionRefererHost TrafficSourceDimension = "referer_host"
+	TrafficSourceDimensionUTMSource   TrafficSourceDimension = "utm_source"
+	TrafficSourceDimensionUTMMedium   TrafficSourceDimension = "utm_medium"
+	TrafficSourceDimensionUTMCampaign TrafficSourceDimension = "utm_campaign"
+	TrafficSourceDimensionUTMTerm     TrafficSourceDimension = "utm_term"
+	TrafficSourceDimensionUTMContent  TrafficSourceDimension = "utm_content"
+)
+
+type TrafficSourceType string
+
+const (
+	TrafficSourceTypePage         TrafficSourceType = "page"
+	TrafficSourceTypeConversation TrafficSourceType = "conversation"
+)
+
+// TrafficSource where a visitor came from, referer and utm parameters of the landing url
+type TrafficSource struct {
+	Referer     string `json:"referer"`
+	RefererHost string `json:"referer_host"`
+	UTMSource   string `json:"utm_source"`
+	UTMMedium   string `json:"utm_medium"`
+	UTMCampaign string `json:"utm_campaign"`
+	UTMTerm     string `json:"utm_term"`
+	UTMContent  string `json:"utm_content"`
+}
+
+// NewTrafficSource parse referer host from referer and utm parameters from landing url
+func NewTrafficSource(referer, landingURL string) TrafficSource {
+	source := TrafficSource{Referer: referer}
+	if referer != "" {
+		if refererURL, err := url.Parse(referer); err == nil {
+			source.RefererHost = refererURL.Host
+		}
+	}
+	if landingURL != "" {
+		if u, err := url.Parse(landingURL); err == nil {
+			query := u.Query()
+			source.UTMSource = query.Get("utm_source")
+			source.UTMMedium = query.Get("utm_medium")
+			source.UTMCampaign = query.Get("utm_campaign")
+			source.UTMTerm = query.Get("utm_term")
+			source.UTMContent = query.Get("utm_content")
+		}
+	}
+	return source
+}
+
+type GetTrafficSourcesReq struct {
+	KBID      string                 `json:"kb_id" query:"kb_id" validate:"required"`
+	Type      TrafficSourceType      `json:"type" query:"type" validate:"required,oneof=page conversation"`
+	Dimension TrafficSourceDimension `json:"dimension" query:"dimension" validate:"required,oneof=referer_host utm_source utm_medium utm_campaign utm_term utm_content"`
+}
+
+type TrafficSourceCount struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
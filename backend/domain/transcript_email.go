@@ -0,0 +1,49 @@
+package domain
+
+import "time"
+
+type TranscriptEmailStatus string
+
+const (
+	TranscriptEmailStatusSent        TranscriptEmailStatus = "sent"
+	TranscriptEmailStatusFailed      TranscriptEmailStatus = "failed"
+	TranscriptEmailStatusRateLimited TranscriptEmailStatus = "rate_limited"
+)
+
+const (
+	// TranscriptEmailLimitPerIP max transcript emails a client can request per hour
+	TranscriptEmailLimitPerIP = 5
+	// TranscriptEmailLimitPerConversation max transcript emails of a conversation per day
+	TranscriptEmailLimitPerConversation = 3
+)
+
+type ConversationTranscriptEmail struct {
+	ID             int64                 `json:"id" gorm:"primaryKey"`
+	KBID           string                `json:"kb_id"`
+	ConversationID string                `json:"conversation_id"`
+	Email          string                `json:"email"`
+	RemoteIP       string                `json:"remote_ip"`
+	Status         TranscriptEmailStatus `json:"status"`
+	Error          string                `json:"error"`
+	CreatedAt      time.Time             `json:"created_at"`
+}
+
+func (ConversationTranscriptEmail) TableName() string {
+	return "conversation_transcript_emails"
+}
+
+type SendTranscriptEmailReq struct {
+	ConversationID string `json:"conversation_id" validate:"required"`
+	Nonce          string `json:"nonce" validate:"required"`
+	Email          string `json:"email" validate:"required,email"`
+
+	KBID     string `json:"-"`
+	RemoteIP string `json:"-"`
+}
+
+type TranscriptEmailListReq struct {
+	KBID           string `json:"kb_id" query:"kb_id" validate:"required"`
+	ConversationID string `json:"conversation_id" query:"conversation_id"`
+
+	Pager
+}
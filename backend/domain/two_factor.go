@@ -0,0 +1,123 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	TwoFactorPolicyOptional = "optional"
+	TwoFactorPolicyOwners   = "owners"
+	TwoFactorPolicyAll      = "all"
+)
+
+const (
+	// TwoFactorChallengeTTL time the user has to enter the code after the password
+	TwoFactorChallengeTTL = 5 * time.Minute
+	// TwoFactorMaxAttempts wrong codes before the challenge is dropped and the password must be entered again
+	TwoFactorMaxAttempts     = 5
+	TwoFactorBackupCodeCount = 10
+)
+
+var (
+	ErrTwoFactorInvalidCode      = NewError(ErrCodeUnauthorized, "invalid two-factor code")
+	ErrTwoFactorChallengeInvalid = NewError(ErrCodeUnauthorized, "two-factor login is invalid or expired, log in again")
+	ErrTwoFactorNotEnrolled      = NewError(ErrCodeNotFound, "two-factor authentication is not enabled")
+	ErrTwoFactorAlreadyEnabled   = NewError(ErrCodeConflict, "two-factor authentication is already enabled")
+	ErrTwoFactorRequired         = NewError(ErrCodeForbidden, "two-factor authentication is required for your role")
+)
+
+// TwoFactorStep step left to log in after the password
+type TwoFactorStep string
+
+const (
+	// TwoFactorStepVerify enter a code of the authenticator app or a backup code
+	TwoFactorStepVerify TwoFactorStep = "verify"
+	// TwoFactorStepEnroll enroll an authenticator app, required by the policy
+	TwoFactorStepEnroll TwoFactorStep = "enroll"
+)
+
+// TwoFactorBackupCodes sha256 of unused backup codes
+type TwoFactorBackupCodes []string
+
+func (c *TwoFactorBackupCodes) Scan(value any) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New(fmt.Sprint("invalid backup codes value type:", value))
+	}
+	return json.Unmarshal(bytes, c)
+}
+
+func (c TwoFactorBackupCodes) Value() (driver.Value, error) {
+	if c == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]string(c))
+}
+
+// table: user_two_factors, a row without enabled is an enrollment waiting for its first code
+type UserTwoFactor struct {
+	UserID string `json:"user_id" gorm:"primaryKey"`
+	// base32 totp secret
+	Secret      string               `json:"-"`
+	Enabled     bool                 `json:"enabled"`
+	BackupCodes TwoFactorBackupCodes `json:"-" gorm:"type:jsonb"`
+	// last time step a code was accepted for, codes of it and earlier steps are rejected so they can not be replayed
+	LastUsedStep int64      `json:"-"`
+	EnabledAt    *time.Time `json:"enabled_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+func (UserTwoFactor) TableName() string {
+	return "user_two_factors"
+}
+
+// TwoFactorChallenge login waiting for its second factor, kept in the cache under a random token
+type TwoFactorChallenge struct {
+	UserID string        `json:"user_id"`
+	Step   TwoFactorStep `json:"step"`
+}
+
+type TwoFactorLoginReq struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	// code of the authenticator app or a backup code
+	Code string `json:"code" validate:"required"`
+}
+
+type TwoFactorChallengeReq struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+}
+
+type TwoFactorCodeReq struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type TwoFactorEnrollResp struct {
+	// base32 secret for apps which can not scan the uri
+	Secret string `json:"secret"`
+	// otpauth uri shown as a qr code
+	URI string `json:"uri"`
+}
+
+type TwoFactorConfirmResp struct {
+	// shown once, each code logs in once when the authenticator app is lost
+	BackupCodes []string `json:"backup_codes"`
+	// console token when enrolling during login
+	Token string `json:"token,omitempty"`
+}
+
+type TwoFactorStatusResp struct {
+	Enabled bool `json:"enabled"`
+	// the policy requires the user to keep two-factor authentication enabled
+	Required        bool       `json:"required"`
+	BackupCodesLeft int        `json:"backup_codes_left"`
+	EnabledAt       *time.Time `json:"enabled_at,omitempty"`
+}
+
+type ResetTwoFactorReq struct {
+	UserID string `json:"user_id" validate:"required"`
+}
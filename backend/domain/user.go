@@ -4,10 +4,17 @@ import (
 	"time"
 )
 
+var ErrUserNotFound = NewError(ErrCodeNotFound, "user not found")
+
 type User struct {
-	ID         string    `json:"id" gorm:"primaryKey"`
-	Account    string    `json:"account" gorm:"uniqueIndex"`
-	Password   string    `json:"password"`
+	ID       string   `json:"id" gorm:"primaryKey"`
+	Account  string   `json:"account" gorm:"uniqueIndex"`
+	Password string   `json:"password"`
+	Role     UserRole `json:"role"`
+	// issuer and subject of users signed in with the oidc provider, empty for local users
+	OIDCSubject string `json:"oidc_subject"`
+	// dn of users logging in with the ldap directory, empty for local users
+	LDAPDN     string    `json:"ldap_dn" gorm:"column:ldap_dn"`
 	CreatedAt  time.Time `json:"created_at"`
 	LastAccess time.Time `json:"last_access" gorm:"default:null"`
 }
@@ -15,6 +22,8 @@ type User struct {
 type CreateUserReq struct {
 	Account  string `json:"account" validate:"required"`
 	Password string `json:"password" validate:"required,min=8"`
+	// admin if empty, members only access the kbs they are added to
+	Role UserRole `json:"role" validate:"omitempty,oneof=admin member"`
 }
 
 type LoginReq struct {
@@ -23,19 +32,31 @@ type LoginReq struct {
 }
 
 type LoginResp struct {
+	// empty if a second factor is needed
 	Token string `json:"token"`
+	// step left to log in, with the challenge token
+	TwoFactor      TwoFactorStep `json:"two_factor,omitempty"`
+	ChallengeToken string        `json:"challenge_token,omitempty"`
 }
 
 type UserInfoResp struct {
 	ID         string     `json:"id"`
 	Account    string     `json:"account"`
+	Role       UserRole   `json:"role"`
 	LastAccess *time.Time `json:"last_access,omitempty"`
 	CreatedAt  time.Time  `json:"created_at"`
+	// roles on kbs of members
+	KBRoles []*UserKBRole `json:"kb_roles,omitempty" gorm:"-"`
 }
 
 type UserListItemResp struct {
-	ID         string     `json:"id"`
-	Account    string     `json:"account"`
+	ID      string   `json:"id"`
+	Account string   `json:"account"`
+	Role    UserRole `json:"role"`
+	// roles of sso users follow their groups at every login
+	OIDCSubject string `json:"oidc_subject,omitempty"`
+	// roles of directory users follow their ldap groups
+	LDAPDN     string     `json:"ldap_dn,omitempty" gorm:"column:ldap_dn"`
 	LastAccess *time.Time `json:"last_access,omitempty"`
 }
 
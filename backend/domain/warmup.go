@@ -0,0 +1,36 @@
+package domain
+
+import "time"
+
+const (
+	// WarmupProbeText text embedded and queried to warm up the vector index
+	WarmupProbeText = "PandaWiki warm-up probe"
+	// WarmupActivityWindow kbs with conversations in the window are preloaded
+	WarmupActivityWindow = 7 * 24 * time.Hour
+	// WarmupRetryInterval interval to retry warm-up after a transient failure
+	WarmupRetryInterval = 30 * time.Second
+)
+
+type WarmupState string
+
+const (
+	WarmupStateRunning WarmupState = "running"
+	WarmupStateReady   WarmupState = "ready"
+	WarmupStateFailed  WarmupState = "failed"
+)
+
+type WarmupStatus struct {
+	State WarmupState `json:"state"`
+	Error string      `json:"error,omitempty"`
+	// embedding dimension configured for the vector index and reported by the model
+	ExpectedDimension int `json:"expected_dimension"`
+	ActualDimension   int `json:"actual_dimension,omitempty"`
+	// kbs whose cache and index were preloaded
+	WarmedKBIDs []string   `json:"warmed_kb_ids"`
+	StartedAt   time.Time  `json:"started_at"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+}
+
+func (s *WarmupStatus) Ready() bool {
+	return s.State == WarmupStateReady
+}
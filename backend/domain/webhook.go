@@ -0,0 +1,141 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type WebhookEventType string
+
+const (
+	WebhookEventConversationCreated WebhookEventType = "conversation.created"
+	WebhookEventConversationMessage WebhookEventType = "conversation.message"
+	// abnormal chat traffic blocked, data is the anomaly
+	WebhookEventAnomalyDetected WebhookEventType = "anomaly.detected"
+)
+
+var WebhookEventTypes = []WebhookEventType{
+	WebhookEventConversationCreated,
+	WebhookEventConversationMessage,
+	WebhookEventAnomalyDetected,
+}
+
+type WebhookFormat string
+
+const (
+	// raw event as json
+	WebhookFormatRaw WebhookFormat = ""
+	// go text/template rendered with the event
+	WebhookFormatTemplate WebhookFormat = "template"
+	// json object of output field to jsonpath of the event
+	WebhookFormatJSONPath WebhookFormat = "jsonpath"
+)
+
+// table: webhooks
+type Webhook struct {
+	ID     string     `json:"id" gorm:"primaryKey"`
+	KBID   string     `json:"kb_id"`
+	Name   string     `json:"name"`
+	URL    string     `json:"url"`
+	Events StringList `json:"events" gorm:"type:jsonb"`
+	// hmac-sha256 key of payload signature, no signature if empty
+	Secret string `json:"secret"`
+	// secret is set, the api returns the webhook masked
+	HasSecret bool          `json:"has_secret" gorm:"-"`
+	Headers   StringMap     `json:"headers" gorm:"type:jsonb"`
+	Format    WebhookFormat `json:"format"`
+	Template  string        `json:"template"`
+	Mapping   StringMap     `json:"mapping" gorm:"type:jsonb"`
+	Enabled   bool          `json:"enabled"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (Webhook) TableName() string {
+	return "webhooks"
+}
+
+// Subscribes report whether the webhook wants the event
+func (w *Webhook) Subscribes(event WebhookEventType) bool {
+	for _, e := range w.Events {
+		if WebhookEventType(e) == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Masked webhook without the secret, returned by the api
+func (w Webhook) Masked() *Webhook {
+	w.HasSecret = w.Secret != ""
+	w.Secret = ""
+	return &w
+}
+
+type StringMap map[string]string
+
+func (m *StringMap) Scan(value any) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New(fmt.Sprint("invalid string map value type:", value))
+	}
+	return json.Unmarshal(bytes, m)
+}
+
+func (m StringMap) Value() (driver.Value, error) {
+	if m == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(map[string]string(m))
+}
+
+// WebhookEvent event delivered to webhooks, templates and jsonpaths are evaluated on its json form
+type WebhookEvent struct {
+	ID        string           `json:"id"`
+	Type      WebhookEventType `json:"type"`
+	KBID      string           `json:"kb_id"`
+	Data      json.RawMessage  `json:"data"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+type CreateWebhookReq struct {
+	KBID     string        `json:"kb_id" validate:"required"`
+	Name     string        `json:"name" validate:"required"`
+	URL      string        `json:"url" validate:"required,url"`
+	Events   []string      `json:"events" validate:"required,min=1,dive,oneof=conversation.created conversation.message anomaly.detected"`
+	Secret   string        `json:"secret"`
+	Headers  StringMap     `json:"headers"`
+	Format   WebhookFormat `json:"format" validate:"omitempty,oneof=template jsonpath"`
+	Template string        `json:"template"`
+	Mapping  StringMap     `json:"mapping"`
+	Enabled  bool          `json:"enabled"`
+}
+
+type UpdateWebhookReq struct {
+	ID string `json:"id" validate:"required"`
+	CreateWebhookReq
+	// empty secret keeps the saved one unless the secret is removed
+	RemoveSecret bool `json:"remove_secret"`
+}
+
+type DeleteWebhookReq struct {
+	KBID string `json:"kb_id" validate:"required"`
+	ID   string `json:"id" validate:"required"`
+}
+
+// PreviewWebhookReq render payload of the webhook settings with a sample event
+type PreviewWebhookReq struct {
+	Event    WebhookEventType `json:"event" validate:"required,oneof=conversation.created conversation.message anomaly.detected"`
+	Format   WebhookFormat    `json:"format" validate:"omitempty,oneof=template jsonpath"`
+	Template string           `json:"template"`
+	Mapping  StringMap        `json:"mapping"`
+}
+
+type PreviewWebhookResp struct {
+	Event   *WebhookEvent `json:"event"`
+	Payload string        `json:"payload"`
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -39,7 +40,18 @@ func (h *BaseHandler) NewResponseWithData(c echo.Context, data any) error {
 	})
 }
 
+// Viewer viewer of the public site identified by the share auth middleware, anonymous if not identified
+func (h *BaseHandler) Viewer(c echo.Context) *domain.NodeViewer {
+	if viewer, ok := c.Get(domain.ContextKeyViewer).(*domain.NodeViewer); ok {
+		return viewer
+	}
+	return &domain.NodeViewer{}
+}
+
+// NewResponseWithError respond the message with the code of the error, requests rejected by
+// binding, validation or checks of the handler without an error are invalid requests
 func (h *BaseHandler) NewResponseWithError(c echo.Context, msg string, err error) error {
+	code := ErrorCode(err)
 	traceID := ""
 	if h.config.GetBool("apm.enabled") {
 		span := trace.SpanFromContext(c.Request().Context())
@@ -48,9 +60,35 @@ func (h *BaseHandler) NewResponseWithError(c echo.Context, msg string, err error
 	} else {
 		traceID = uuid.New().String()
 	}
-	h.baseLogger.LogAttrs(c.Request().Context(), slog.LevelError, msg, slog.String("trace_id", traceID), slog.Any("error", err))
+	c.Set(domain.ContextKeyResponseError, code)
+	h.baseLogger.LogAttrs(c.Request().Context(), slog.LevelError, msg, slog.String("trace_id", traceID), slog.String("code", string(code)), slog.Any("error", err))
 	return c.JSON(http.StatusOK, domain.Response{
 		Success: false,
+		Code:    code,
 		Message: fmt.Sprintf("%s [trace_id: %s]", msg, traceID),
 	})
 }
+
+// ErrorCode code of the error, bad requests of echo are invalid requests
+func ErrorCode(err error) domain.ErrorCode {
+	var httpErr *echo.HTTPError
+	switch {
+	case err == nil:
+		return domain.ErrCodeInvalidRequest
+	case errors.As(err, &httpErr):
+		switch httpErr.Code {
+		case http.StatusUnauthorized:
+			return domain.ErrCodeUnauthorized
+		case http.StatusForbidden:
+			return domain.ErrCodeForbidden
+		case http.StatusNotFound:
+			return domain.ErrCodeNotFound
+		case http.StatusTooManyRequests:
+			return domain.ErrCodeRateLimited
+		}
+		if httpErr.Code < http.StatusInternalServerError {
+			return domain.ErrCodeInvalidRequest
+		}
+	}
+	return domain.ErrorCodeOf(err)
+}
@@ -0,0 +1,40 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/mq"
+	"github.com/chaitin/panda-wiki/mq/types"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type ConversationRescoreMQHandler struct {
+	logger                     *log.Logger
+	conversationRescoreUsecase *usecase.ConversationRescoreUsecase
+}
+
+func NewConversationRescoreMQHandler(consumer mq.MQConsumer, logger *log.Logger, conversationRescoreUsecase *usecase.ConversationRescoreUsecase) (*ConversationRescoreMQHandler, error) {
+	h := &ConversationRescoreMQHandler{
+		logger:                     logger.WithModule("handler.mq.conversation_rescore"),
+		conversationRescoreUsecase: conversationRescoreUsecase,
+	}
+	if err := consumer.RegisterHandler(domain.ConversationRescoreTopic, h.HandleConversationRescoreJob); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func (h *ConversationRescoreMQHandler) HandleConversationRescoreJob(ctx context.Context, msg types.Message) error {
+	var request domain.ConversationRescoreJobRequest
+	if err := json.Unmarshal(msg.GetData(), &request); err != nil {
+		h.logger.Error("unmarshal conversation rescore job request failed", log.Error(err))
+		return nil
+	}
+	if err := h.conversationRescoreUsecase.RunJob(ctx, request.JobID); err != nil {
+		h.logger.Error("run conversation rescore job failed", log.String("job_id", request.JobID), log.Error(err))
+	}
+	return nil
+}
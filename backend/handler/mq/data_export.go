@@ -0,0 +1,40 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/mq"
+	"github.com/chaitin/panda-wiki/mq/types"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type DataExportMQHandler struct {
+	logger            *log.Logger
+	dataExportUsecase *usecase.DataExportUsecase
+}
+
+func NewDataExportMQHandler(consumer mq.MQConsumer, logger *log.Logger, dataExportUsecase *usecase.DataExportUsecase) (*DataExportMQHandler, error) {
+	h := &DataExportMQHandler{
+		logger:            logger.WithModule("handler.mq.data_export"),
+		dataExportUsecase: dataExportUsecase,
+	}
+	if err := consumer.RegisterHandler(domain.DataExportTopic, h.HandleDataExportJob); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func (h *DataExportMQHandler) HandleDataExportJob(ctx context.Context, msg types.Message) error {
+	var request domain.DataExportJobRequest
+	if err := json.Unmarshal(msg.GetData(), &request); err != nil {
+		h.logger.Error("unmarshal data export job request failed", log.Error(err))
+		return nil
+	}
+	if err := h.dataExportUsecase.RunJob(ctx, request.JobID); err != nil {
+		h.logger.Error("run data export job failed", log.String("job_id", request.JobID), log.Error(err))
+	}
+	return nil
+}
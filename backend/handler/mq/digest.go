@@ -0,0 +1,44 @@
+package mq
+
+import (
+	"context"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type DigestCronHandler struct {
+	logger        *log.Logger
+	digestUsecase *usecase.DigestUsecase
+	cronUsecase   *usecase.CronUsecase
+}
+
+func NewDigestCronHandler(logger *log.Logger, digestUsecase *usecase.DigestUsecase, cronUsecase *usecase.CronUsecase) *DigestCronHandler {
+	h := &DigestCronHandler{
+		digestUsecase: digestUsecase,
+		cronUsecase:   cronUsecase,
+		logger:        logger.WithModule("handler.mq.digest"),
+	}
+	cron := cron.New()
+	cron.AddFunc("0 8 * * *", h.SendDailyDigests)
+	h.logger.Info("add cron job", log.String("cron_id", "send_daily_digests"))
+	cron.Start()
+	h.logger.Info("start cron job")
+	return h
+}
+
+// send digest of yesterday's conversations, execute every day 08:00
+func (h *DigestCronHandler) SendDailyDigests() {
+	h.cronUsecase.Run(domain.CronJobSendDailyDigests, func(ctx context.Context) error {
+		h.logger.Info("send daily digests start")
+		if err := h.digestUsecase.SendDailyDigests(ctx); err != nil {
+			h.logger.Error("send daily digests failed", log.Error(err))
+			return err
+		}
+		h.logger.Info("send daily digests successful")
+		return nil
+	})
+}
@@ -0,0 +1,40 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/mq"
+	"github.com/chaitin/panda-wiki/mq/types"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type EvalMQHandler struct {
+	logger      *log.Logger
+	evalUsecase *usecase.EvalUsecase
+}
+
+func NewEvalMQHandler(consumer mq.MQConsumer, logger *log.Logger, evalUsecase *usecase.EvalUsecase) (*EvalMQHandler, error) {
+	h := &EvalMQHandler{
+		logger:      logger.WithModule("handler.mq.eval"),
+		evalUsecase: evalUsecase,
+	}
+	if err := consumer.RegisterHandler(domain.EvalRunTopic, h.HandleEvalRun); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func (h *EvalMQHandler) HandleEvalRun(ctx context.Context, msg types.Message) error {
+	var request domain.EvalRunRequest
+	if err := json.Unmarshal(msg.GetData(), &request); err != nil {
+		h.logger.Error("unmarshal eval run request failed", log.Error(err))
+		return nil
+	}
+	if err := h.evalUsecase.RunEval(ctx, request.RunID); err != nil {
+		h.logger.Error("run eval failed", log.String("run_id", request.RunID), log.Error(err))
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package mq
+
+import (
+	"context"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type ExternalLinkCronHandler struct {
+	logger              *log.Logger
+	externalLinkUsecase *usecase.ExternalLinkUsecase
+	cronUsecase         *usecase.CronUsecase
+}
+
+func NewExternalLinkCronHandler(logger *log.Logger, externalLinkUsecase *usecase.ExternalLinkUsecase, cronUsecase *usecase.CronUsecase) *ExternalLinkCronHandler {
+	h := &ExternalLinkCronHandler{
+		externalLinkUsecase: externalLinkUsecase,
+		cronUsecase:         cronUsecase,
+		logger:              logger.WithModule("handler.mq.external_link"),
+	}
+	cron := cron.New()
+	cron.AddFunc("30 3 * * *", h.CheckExternalLinks)
+	h.logger.Info("add cron job", log.String("cron_id", "check_external_links"))
+	cron.Start()
+	h.logger.Info("start cron job")
+	return h
+}
+
+// check outbound links of nodes, execute every day 03:30
+func (h *ExternalLinkCronHandler) CheckExternalLinks() {
+	h.cronUsecase.Run(domain.CronJobCheckExternalLinks, func(ctx context.Context) error {
+		h.logger.Info("check external links start")
+		if err := h.externalLinkUsecase.CheckExternalLinks(ctx); err != nil {
+			h.logger.Error("check external links failed", log.Error(err))
+			return err
+		}
+		h.logger.Info("check external links successful")
+		return nil
+	})
+}
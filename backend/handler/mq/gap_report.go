@@ -0,0 +1,44 @@
+package mq
+
+import (
+	"context"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type GapReportCronHandler struct {
+	logger           *log.Logger
+	gapReportUsecase *usecase.GapReportUsecase
+	cronUsecase      *usecase.CronUsecase
+}
+
+func NewGapReportCronHandler(logger *log.Logger, gapReportUsecase *usecase.GapReportUsecase, cronUsecase *usecase.CronUsecase) *GapReportCronHandler {
+	h := &GapReportCronHandler{
+		gapReportUsecase: gapReportUsecase,
+		cronUsecase:      cronUsecase,
+		logger:           logger.WithModule("handler.mq.gap_report"),
+	}
+	cron := cron.New()
+	cron.AddFunc("0 10 * * 1", h.SendWeeklyGapReports)
+	h.logger.Info("add cron job", log.String("cron_id", "send_weekly_gap_reports"))
+	cron.Start()
+	h.logger.Info("start cron job")
+	return h
+}
+
+// send gap reports to dingtalk/feishu groups, execute every monday 10:00
+func (h *GapReportCronHandler) SendWeeklyGapReports() {
+	h.cronUsecase.Run(domain.CronJobSendWeeklyGapReports, func(ctx context.Context) error {
+		h.logger.Info("send weekly gap reports start")
+		if err := h.gapReportUsecase.SendWeeklyGapReports(ctx); err != nil {
+			h.logger.Error("send weekly gap reports failed", log.Error(err))
+			return err
+		}
+		h.logger.Info("send weekly gap reports successful")
+		return nil
+	})
+}
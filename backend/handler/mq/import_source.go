@@ -0,0 +1,45 @@
+package mq
+
+import (
+	"context"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type ImportSourceCronHandler struct {
+	logger              *log.Logger
+	importSourceUsecase *usecase.ImportSourceUsecase
+	cronUsecase         *usecase.CronUsecase
+}
+
+func NewImportSourceCronHandler(logger *log.Logger, importSourceUsecase *usecase.ImportSourceUsecase, cronUsecase *usecase.CronUsecase) *ImportSourceCronHandler {
+	h := &ImportSourceCronHandler{
+		importSourceUsecase: importSourceUsecase,
+		cronUsecase:         cronUsecase,
+		logger:              logger.WithModule("handler.mq.import_source"),
+	}
+	cron := cron.New()
+	cron.AddFunc("*/10 * * * *", h.SyncImportSources)
+	h.logger.Info("add cron job", log.String("cron_id", "sync_import_sources"))
+	cron.Start()
+	h.logger.Info("start cron job")
+	return h
+}
+
+// sync import sources whose sync interval has passed, execute every 10 minutes
+func (h *ImportSourceCronHandler) SyncImportSources() {
+	h.cronUsecase.RunWithResult(domain.CronJobSyncImportSources, func(ctx context.Context) (domain.CronRunResult, error) {
+		h.logger.Info("sync import sources start")
+		result, err := h.importSourceUsecase.SyncDueSources(ctx)
+		if err != nil {
+			h.logger.Error("sync import sources failed", log.Error(err))
+			return result, err
+		}
+		h.logger.Info("sync import sources successful", log.Any("result", result))
+		return result, nil
+	})
+}
@@ -0,0 +1,45 @@
+package mq
+
+import (
+	"context"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type IndexIntegrityCronHandler struct {
+	logger                *log.Logger
+	indexIntegrityUsecase *usecase.IndexIntegrityUsecase
+	cronUsecase           *usecase.CronUsecase
+}
+
+func NewIndexIntegrityCronHandler(logger *log.Logger, indexIntegrityUsecase *usecase.IndexIntegrityUsecase, cronUsecase *usecase.CronUsecase) *IndexIntegrityCronHandler {
+	h := &IndexIntegrityCronHandler{
+		indexIntegrityUsecase: indexIntegrityUsecase,
+		cronUsecase:           cronUsecase,
+		logger:                logger.WithModule("handler.mq.index_integrity"),
+	}
+	cron := cron.New()
+	cron.AddFunc("0 4 * * *", h.CheckIndexIntegrity)
+	h.logger.Info("add cron job", log.String("cron_id", "check_index_integrity"))
+	cron.Start()
+	h.logger.Info("start cron job")
+	return h
+}
+
+// compare postgres with vector store and repair drift, execute every day 04:00
+func (h *IndexIntegrityCronHandler) CheckIndexIntegrity() {
+	h.cronUsecase.RunWithResult(domain.CronJobCheckIndexIntegrity, func(ctx context.Context) (domain.CronRunResult, error) {
+		h.logger.Info("check index integrity start")
+		result, err := h.indexIntegrityUsecase.CheckAll(ctx)
+		if err != nil {
+			h.logger.Error("check index integrity failed", log.Error(err))
+			return result, err
+		}
+		h.logger.Info("check index integrity successful", log.Any("result", result))
+		return result, nil
+	})
+}
@@ -0,0 +1,48 @@
+package mq
+
+import (
+	"context"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type LDAPSyncCronHandler struct {
+	logger      *log.Logger
+	ldapUsecase *usecase.LDAPUsecase
+	cronUsecase *usecase.CronUsecase
+}
+
+func NewLDAPSyncCronHandler(logger *log.Logger, ldapUsecase *usecase.LDAPUsecase, cronUsecase *usecase.CronUsecase) *LDAPSyncCronHandler {
+	h := &LDAPSyncCronHandler{
+		ldapUsecase: ldapUsecase,
+		cronUsecase: cronUsecase,
+		logger:      logger.WithModule("handler.mq.ldap"),
+	}
+	if !ldapUsecase.Enabled() {
+		h.logger.Info("ldap is not configured, skip cron job", log.String("cron_id", "sync_ldap_groups"))
+		return h
+	}
+	cron := cron.New()
+	cron.AddFunc("30 */1 * * *", h.SyncGroups)
+	h.logger.Info("add cron job", log.String("cron_id", "sync_ldap_groups"))
+	cron.Start()
+	h.logger.Info("start cron job")
+	return h
+}
+
+// sync roles and groups of directory accounts, so accounts removed from the directory are revoked without waiting for a login, execute every hour
+func (h *LDAPSyncCronHandler) SyncGroups() {
+	h.cronUsecase.RunWithResult(domain.CronJobSyncLDAPGroups, func(ctx context.Context) (domain.CronRunResult, error) {
+		result, err := h.ldapUsecase.Sync(ctx)
+		if err != nil {
+			h.logger.Error("sync ldap groups failed", log.Error(err))
+			return result, err
+		}
+		h.logger.Info("ldap groups synced", log.Any("result", result))
+		return result, nil
+	})
+}
@@ -0,0 +1,40 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/mq"
+	"github.com/chaitin/panda-wiki/mq/types"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type NodeExportMQHandler struct {
+	logger            *log.Logger
+	nodeExportUsecase *usecase.NodeExportUsecase
+}
+
+func NewNodeExportMQHandler(consumer mq.MQConsumer, logger *log.Logger, nodeExportUsecase *usecase.NodeExportUsecase) (*NodeExportMQHandler, error) {
+	h := &NodeExportMQHandler{
+		logger:            logger.WithModule("handler.mq.node_export"),
+		nodeExportUsecase: nodeExportUsecase,
+	}
+	if err := consumer.RegisterHandler(domain.NodeExportTopic, h.HandleNodeExportJob); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func (h *NodeExportMQHandler) HandleNodeExportJob(ctx context.Context, msg types.Message) error {
+	var request domain.NodeExportJobRequest
+	if err := json.Unmarshal(msg.GetData(), &request); err != nil {
+		h.logger.Error("unmarshal node export job request failed", log.Error(err))
+		return nil
+	}
+	if err := h.nodeExportUsecase.RunJob(ctx, request.JobID); err != nil {
+		h.logger.Error("run node export job failed", log.String("job_id", request.JobID), log.Error(err))
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package mq
+
+import (
+	"context"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type NearDuplicateCronHandler struct {
+	logger               *log.Logger
+	nearDuplicateUsecase *usecase.NearDuplicateUsecase
+	cronUsecase          *usecase.CronUsecase
+}
+
+func NewNearDuplicateCronHandler(logger *log.Logger, nearDuplicateUsecase *usecase.NearDuplicateUsecase, cronUsecase *usecase.CronUsecase) *NearDuplicateCronHandler {
+	h := &NearDuplicateCronHandler{
+		nearDuplicateUsecase: nearDuplicateUsecase,
+		cronUsecase:          cronUsecase,
+		logger:               logger.WithModule("handler.mq.near_duplicate"),
+	}
+	cron := cron.New()
+	cron.AddFunc("30 4 * * *", h.DetectNearDuplicates)
+	h.logger.Info("add cron job", log.String("cron_id", "detect_near_duplicates"))
+	cron.Start()
+	h.logger.Info("start cron job")
+	return h
+}
+
+// report documents with similar content, execute every day 04:30
+func (h *NearDuplicateCronHandler) DetectNearDuplicates() {
+	h.cronUsecase.RunWithResult(domain.CronJobDetectNearDuplicates, func(ctx context.Context) (domain.CronRunResult, error) {
+		h.logger.Info("detect near-duplicates start")
+		result, err := h.nearDuplicateUsecase.DetectAll(ctx)
+		if err != nil {
+			h.logger.Error("detect near-duplicates failed", log.Error(err))
+			return result, err
+		}
+		h.logger.Info("detect near-duplicates successful", log.Any("result", result))
+		return result, nil
+	})
+}
@@ -0,0 +1,45 @@
+package mq
+
+import (
+	"context"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type ReviewReminderCronHandler struct {
+	logger           *log.Logger
+	nodeOwnerUsecase *usecase.NodeOwnerUsecase
+	cronUsecase      *usecase.CronUsecase
+}
+
+func NewReviewReminderCronHandler(logger *log.Logger, nodeOwnerUsecase *usecase.NodeOwnerUsecase, cronUsecase *usecase.CronUsecase) *ReviewReminderCronHandler {
+	h := &ReviewReminderCronHandler{
+		nodeOwnerUsecase: nodeOwnerUsecase,
+		cronUsecase:      cronUsecase,
+		logger:           logger.WithModule("handler.mq.review_reminder"),
+	}
+	cron := cron.New()
+	cron.AddFunc("0 9 * * *", h.SendReviewReminders)
+	h.logger.Info("add cron job", log.String("cron_id", "send_review_reminders"))
+	cron.Start()
+	h.logger.Info("start cron job")
+	return h
+}
+
+// remind owners of documents past their review interval, execute every day 09:00
+func (h *ReviewReminderCronHandler) SendReviewReminders() {
+	h.cronUsecase.RunWithResult(domain.CronJobSendReviewReminders, func(ctx context.Context) (domain.CronRunResult, error) {
+		h.logger.Info("send review reminders start")
+		result, err := h.nodeOwnerUsecase.SendReviewReminders(ctx)
+		if err != nil {
+			h.logger.Error("send review reminders failed", log.Error(err))
+			return result, err
+		}
+		h.logger.Info("send review reminders successful", log.Any("result", result))
+		return result, nil
+	})
+}
@@ -0,0 +1,44 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/mq"
+	"github.com/chaitin/panda-wiki/mq/types"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type NodeReplaceMQHandler struct {
+	logger             *log.Logger
+	nodeReplaceUsecase *usecase.NodeReplaceUsecase
+}
+
+func NewNodeReplaceMQHandler(consumer mq.MQConsumer, logger *log.Logger, nodeReplaceUsecase *usecase.NodeReplaceUsecase) (*NodeReplaceMQHandler, error) {
+	h := &NodeReplaceMQHandler{
+		logger:             logger.WithModule("handler.mq.node_replace"),
+		nodeReplaceUsecase: nodeReplaceUsecase,
+	}
+	if err := consumer.RegisterHandler(domain.NodeReplaceTopic, h.HandleNodeReplaceJob); err != nil {
+		return nil, err
+	}
+	// jobs of a consumer which stopped while running them would stay running
+	if err := nodeReplaceUsecase.RecoverJobs(context.Background()); err != nil {
+		h.logger.Error("recover node replace jobs failed", log.Error(err))
+	}
+	return h, nil
+}
+
+func (h *NodeReplaceMQHandler) HandleNodeReplaceJob(ctx context.Context, msg types.Message) error {
+	var request domain.NodeReplaceJobRequest
+	if err := json.Unmarshal(msg.GetData(), &request); err != nil {
+		h.logger.Error("unmarshal node replace job request failed", log.Error(err))
+		return nil
+	}
+	if err := h.nodeReplaceUsecase.RunJob(ctx, request.JobID); err != nil {
+		h.logger.Error("run node replace job failed", log.String("job_id", request.JobID), log.Error(err))
+	}
+	return nil
+}
@@ -6,22 +6,85 @@ import (
 	"github.com/chaitin/panda-wiki/repo/mq"
 	"github.com/chaitin/panda-wiki/repo/pg"
 	"github.com/chaitin/panda-wiki/store/rag"
+	"github.com/chaitin/panda-wiki/store/s3"
+	"github.com/chaitin/panda-wiki/store/statsink"
 	"github.com/chaitin/panda-wiki/usecase"
 )
 
 type MQHandlers struct {
-	RAGMQHandler    *RAGMQHandler
-	StatCronHandler *StatCronHandler
+	RAGMQHandler                 *RAGMQHandler
+	RetentionCronHandler         *RetentionCronHandler
+	QuestionClusterCronHandler   *QuestionClusterCronHandler
+	GapReportCronHandler         *GapReportCronHandler
+	StatSinkMQHandler            *StatSinkMQHandler
+	NodeReplaceMQHandler         *NodeReplaceMQHandler
+	WebhookMQHandler             *WebhookMQHandler
+	DigestCronHandler            *DigestCronHandler
+	ExternalLinkCronHandler      *ExternalLinkCronHandler
+	IndexIntegrityCronHandler    *IndexIntegrityCronHandler
+	NodeExportMQHandler          *NodeExportMQHandler
+	ImportSourceCronHandler      *ImportSourceCronHandler
+	NearDuplicateCronHandler     *NearDuplicateCronHandler
+	ReviewReminderCronHandler    *ReviewReminderCronHandler
+	DataExportMQHandler          *DataExportMQHandler
+	TelemetryCronHandler         *TelemetryCronHandler
+	ConversationRescoreMQHandler *ConversationRescoreMQHandler
+	EvalMQHandler                *EvalMQHandler
+	SandboxCronHandler           *SandboxCronHandler
+	LDAPSyncCronHandler          *LDAPSyncCronHandler
 }
 
 var ProviderSet = wire.NewSet(
 	pg.ProviderSet,
 	rag.ProviderSet,
 	mq.ProviderSet,
+	statsink.ProviderSet,
+	s3.ProviderSet,
 	usecase.NewLLMUsecase,
+	usecase.NewQuestionClusterUsecase,
+	usecase.NewGapReportUsecase,
+	usecase.NewCronUsecase,
+	usecase.NewNodeReplaceUsecase,
+	usecase.NewWebhookUsecase,
+	usecase.NewDigestUsecase,
+	usecase.NewExternalLinkUsecase,
+	usecase.NewIndexIntegrityUsecase,
+	usecase.NewNodeExportUsecase,
+	usecase.NewImportSourceUsecase,
+	usecase.NewNodeUsecase,
+	usecase.NewKnowledgeBaseUsecase,
+	usecase.NewNodeAttachmentUsecase,
+	usecase.NewBotProfileUsecase,
+	usecase.NewNearDuplicateUsecase,
+	usecase.NewRetentionUsecase,
+	usecase.NewNodeOwnerUsecase,
+	usecase.NewDataExportUsecase,
+	usecase.NewTelemetryUsecase,
+	usecase.NewConversationRescoreUsecase,
+	usecase.NewEvalUsecase,
+	usecase.NewSandboxUsecase,
+	usecase.NewLDAPUsecase,
 
 	NewRAGMQHandler,
-	NewStatCronHandler,
+	NewRetentionCronHandler,
+	NewQuestionClusterCronHandler,
+	NewGapReportCronHandler,
+	NewStatSinkMQHandler,
+	NewNodeReplaceMQHandler,
+	NewWebhookMQHandler,
+	NewDigestCronHandler,
+	NewExternalLinkCronHandler,
+	NewIndexIntegrityCronHandler,
+	NewNodeExportMQHandler,
+	NewImportSourceCronHandler,
+	NewNearDuplicateCronHandler,
+	NewReviewReminderCronHandler,
+	NewDataExportMQHandler,
+	NewTelemetryCronHandler,
+	NewConversationRescoreMQHandler,
+	NewEvalMQHandler,
+	NewSandboxCronHandler,
+	NewLDAPSyncCronHandler,
 
 	wire.Struct(new(MQHandlers), "*"),
 )
@@ -0,0 +1,46 @@
+package mq
+
+import (
+	"context"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type QuestionClusterCronHandler struct {
+	logger                 *log.Logger
+	questionClusterUsecase *usecase.QuestionClusterUsecase
+	cronUsecase            *usecase.CronUsecase
+}
+
+func NewQuestionClusterCronHandler(logger *log.Logger, questionClusterUsecase *usecase.QuestionClusterUsecase, cronUsecase *usecase.CronUsecase) *QuestionClusterCronHandler {
+	h := &QuestionClusterCronHandler{
+		questionClusterUsecase: questionClusterUsecase,
+		cronUsecase:            cronUsecase,
+		logger:                 logger.WithModule("handler.mq.question_cluster"),
+	}
+	cron := cron.New()
+	cron.AddFunc("*/10 * * * *", h.ClusterQuestions)
+	h.logger.Info("add cron job", log.String("cron_id", "cluster_questions"))
+	cron.Start()
+	h.logger.Info("start cron job")
+	return h
+}
+
+// cluster questions asked in the last 7 days, execute every 10 minutes
+func (h *QuestionClusterCronHandler) ClusterQuestions() {
+	h.cronUsecase.Run(domain.CronJobClusterQuestions, func(ctx context.Context) error {
+		h.logger.Info("cluster questions start")
+		count, err := h.questionClusterUsecase.ClusterPendingQuestions(ctx, time.Now().Add(-7*24*time.Hour), 500)
+		if err != nil {
+			h.logger.Error("cluster questions failed", log.Error(err))
+			return err
+		}
+		h.logger.Info("cluster questions successful", log.Int("count", count))
+		return nil
+	})
+}
@@ -3,11 +3,14 @@ package mq
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 
 	"github.com/chaitin/panda-wiki/domain"
 	"github.com/chaitin/panda-wiki/log"
 	"github.com/chaitin/panda-wiki/mq"
 	"github.com/chaitin/panda-wiki/mq/types"
+	"github.com/chaitin/panda-wiki/repo/cache"
+	mqRepo "github.com/chaitin/panda-wiki/repo/mq"
 	"github.com/chaitin/panda-wiki/repo/pg"
 	"github.com/chaitin/panda-wiki/store/rag"
 	"github.com/chaitin/panda-wiki/usecase"
@@ -21,9 +24,14 @@ type RAGMQHandler struct {
 	kbRepo     *pg.KnowledgeBaseRepository
 	modelRepo  *pg.ModelRepository
 	llmUsecase *usecase.LLMUsecase
+	// answers cached from a node are dropped once its new release is searchable
+	answerCacheRepo *pg.AnswerCacheRepository
+	// releases changed while a dataset was rebuilt are upserted again once it is switched
+	ragRepo *mqRepo.RAGRepository
+	kbCache *cache.KBRepo
 }
 
-func NewRAGMQHandler(consumer mq.MQConsumer, logger *log.Logger, rag rag.RAGService, nodeRepo *pg.NodeRepository, kbRepo *pg.KnowledgeBaseRepository, llmUsecase *usecase.LLMUsecase, modelRepo *pg.ModelRepository) (*RAGMQHandler, error) {
+func NewRAGMQHandler(consumer mq.MQConsumer, logger *log.Logger, rag rag.RAGService, nodeRepo *pg.NodeRepository, kbRepo *pg.KnowledgeBaseRepository, llmUsecase *usecase.LLMUsecase, modelRepo *pg.ModelRepository, answerCacheRepo *pg.AnswerCacheRepository, ragRepo *mqRepo.RAGRepository, kbCache *cache.KBRepo) (*RAGMQHandler, error) {
 	h := &RAGMQHandler{
 		consumer:   consumer,
 		logger:     logger.WithModule("mq.rag"),
@@ -32,10 +40,18 @@ func NewRAGMQHandler(consumer mq.MQConsumer, logger *log.Logger, rag rag.RAGServ
 		kbRepo:     kbRepo,
 		llmUsecase: llmUsecase,
 		modelRepo:  modelRepo,
+
+		answerCacheRepo: answerCacheRepo,
+		ragRepo:         ragRepo,
+		kbCache:         kbCache,
 	}
 	if err := consumer.RegisterHandler(domain.VectorTaskTopic, h.HandleNodeContentVectorRequest); err != nil {
 		return nil, err
 	}
+	// rebuilds of a consumer which stopped while filling the datasets would never switch them
+	if err := h.recoverRebuilds(context.Background()); err != nil {
+		h.logger.Error("recover dataset rebuilds failed", log.Error(err))
+	}
 	return h, nil
 }
 
@@ -85,6 +101,10 @@ func (h *RAGMQHandler) HandleNodeContentVectorRequest(ctx context.Context, msg t
 			}
 		}
 
+		if err := h.answerCacheRepo.DeleteNodesAnswerCache(ctx, kb.ID, []string{nodeRelease.NodeID}); err != nil {
+			h.logger.Warn("drop answer cache of node failed", log.String("node_id", nodeRelease.NodeID), log.Error(err))
+		}
+
 		h.logger.Info("upsert node content vector success", log.Any("updated_ids", request.NodeReleaseID))
 	case "delete":
 		h.logger.Info("delete node content vector request", log.Any("request", request))
@@ -109,7 +129,7 @@ func (h *RAGMQHandler) HandleNodeContentVectorRequest(ctx context.Context, msg t
 			h.logger.Info("node is folder, skip summary", log.Any("node_id", request.NodeID))
 			return nil
 		}
-		model, err := h.modelRepo.GetChatModel(ctx)
+		model, err := h.modelRepo.GetKBModel(ctx, request.KBID, domain.ModelTypeChat)
 		if err != nil {
 			h.logger.Error("get chat model failed", log.Error(err))
 			return nil
@@ -124,7 +144,123 @@ func (h *RAGMQHandler) HandleNodeContentVectorRequest(ctx context.Context, msg t
 			return nil
 		}
 		h.logger.Info("summary node content vector success", log.Any("summary_id", request.NodeReleaseID), log.Any("summary", summary))
+	case "rebuild":
+		h.logger.Info("rebuild dataset request", log.String("kb_id", request.KBID))
+		if err := h.rebuildDataset(ctx, request.KBID); err != nil {
+			h.logger.Error("rebuild dataset failed", log.String("kb_id", request.KBID), log.Error(err))
+			return nil
+		}
+		h.logger.Info("rebuild dataset success", log.String("kb_id", request.KBID))
 	}
 
 	return nil
 }
+
+// rebuildDataset fill a new dataset with the published documents of the kb embedded by the embedding model of the kb,
+// the kb searches its current dataset until the new one is filled and switched
+func (h *RAGMQHandler) rebuildDataset(ctx context.Context, kbID string) error {
+	kb, err := h.kbRepo.GetKnowledgeBaseByID(ctx, kbID)
+	if err != nil {
+		return fmt.Errorf("get kb failed: %w", err)
+	}
+	datasetID, err := h.rag.CreateKnowledgeBase(ctx, kb.ModelSettings.EmbeddingModelID)
+	if err != nil {
+		return fmt.Errorf("create new dataset failed: %w", err)
+	}
+	replaced, err := h.kbRepo.SetPendingDatasetID(ctx, kbID, datasetID)
+	if err != nil {
+		h.deleteDataset(ctx, kbID, datasetID)
+		return fmt.Errorf("set pending dataset failed: %w", err)
+	}
+	if replaced != "" {
+		// dataset of an earlier rebuild which did not finish
+		h.deleteDataset(ctx, kbID, replaced)
+	}
+
+	docIDs := make(map[string]string)
+	if err := h.nodeRepo.TraverseNodesByCursor(ctx, kbID, func(release *domain.NodeRelease) error {
+		nodeRelease, err := h.nodeRepo.GetNodeReleaseByID(ctx, release.ID)
+		if err != nil {
+			return err
+		}
+		docID, err := h.rag.UpsertRecords(ctx, datasetID, nodeRelease)
+		if err != nil {
+			// upserted again once the dataset is switched
+			h.logger.Warn("upsert node release into new dataset failed", log.String("node_release_id", release.ID), log.Error(err))
+			return nil
+		}
+		docIDs[release.ID] = docID
+		return nil
+	}); err != nil {
+		// the pending dataset is filled again when consumers restart
+		return fmt.Errorf("fill new dataset failed: %w", err)
+	}
+
+	oldDatasetID, switched, err := h.kbRepo.SwitchDataset(ctx, kbID, datasetID, docIDs)
+	if err != nil {
+		return fmt.Errorf("switch dataset failed: %w", err)
+	}
+	if !switched {
+		h.logger.Info("dataset rebuild superseded", log.String("kb_id", kbID), log.String("dataset_id", datasetID))
+		h.deleteDataset(ctx, kbID, datasetID)
+		return nil
+	}
+	if err := h.kbCache.DeleteKB(ctx, kbID); err != nil {
+		h.logger.Warn("drop kb cache failed", log.String("kb_id", kbID), log.Error(err))
+	}
+	h.deleteDataset(ctx, kbID, oldDatasetID)
+
+	// releases published or withdrawn while the dataset was filled
+	requests := make([]*domain.NodeReleaseVectorRequest, 0)
+	current := make(map[string]struct{})
+	if err := h.nodeRepo.TraverseNodesByCursor(ctx, kbID, func(release *domain.NodeRelease) error {
+		current[release.ID] = struct{}{}
+		if _, ok := docIDs[release.ID]; !ok {
+			requests = append(requests, &domain.NodeReleaseVectorRequest{
+				KBID:          kbID,
+				NodeReleaseID: release.ID,
+				Action:        "upsert",
+			})
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("get releases changed during rebuild failed: %w", err)
+	}
+	staleDocIDs := make([]string, 0)
+	for releaseID, docID := range docIDs {
+		if _, ok := current[releaseID]; !ok {
+			staleDocIDs = append(staleDocIDs, docID)
+		}
+	}
+	if len(staleDocIDs) > 0 {
+		if err := h.rag.DeleteRecords(ctx, datasetID, staleDocIDs); err != nil {
+			return fmt.Errorf("delete records withdrawn during rebuild failed: %w", err)
+		}
+	}
+	return h.ragRepo.AsyncUpdateNodeReleaseVector(ctx, requests)
+}
+
+// recoverRebuilds rebuild again the datasets of kbs whose pending dataset was not switched
+func (h *RAGMQHandler) recoverRebuilds(ctx context.Context) error {
+	kbIDs, err := h.kbRepo.GetRebuildingKnowledgeBaseIDs(ctx)
+	if err != nil {
+		return err
+	}
+	requests := make([]*domain.NodeReleaseVectorRequest, 0, len(kbIDs))
+	for _, kbID := range kbIDs {
+		requests = append(requests, &domain.NodeReleaseVectorRequest{
+			KBID:   kbID,
+			Action: "rebuild",
+		})
+	}
+	return h.ragRepo.AsyncUpdateNodeReleaseVector(ctx, requests)
+}
+
+func (h *RAGMQHandler) deleteDataset(ctx context.Context, kbID, datasetID string) {
+	if datasetID == "" {
+		return
+	}
+	if err := h.rag.DeleteKnowledgeBase(ctx, datasetID); err != nil {
+		h.logger.Warn("delete dataset failed", log.String("kb_id", kbID), log.String("dataset_id", datasetID), log.Error(err))
+	}
+}
@@ -0,0 +1,45 @@
+package mq
+
+import (
+	"context"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type RetentionCronHandler struct {
+	logger           *log.Logger
+	retentionUsecase *usecase.RetentionUsecase
+	cronUsecase      *usecase.CronUsecase
+}
+
+func NewRetentionCronHandler(logger *log.Logger, retentionUsecase *usecase.RetentionUsecase, cronUsecase *usecase.CronUsecase) *RetentionCronHandler {
+	h := &RetentionCronHandler{
+		retentionUsecase: retentionUsecase,
+		cronUsecase:      cronUsecase,
+		logger:           logger.WithModule("handler.mq.retention"),
+	}
+	cron := cron.New()
+	cron.AddFunc("1 */1 * * *", h.ApplyRetention)
+	h.logger.Info("add cron job", log.String("cron_id", "apply_retention"))
+	cron.Start()
+	h.logger.Info("start cron job")
+	return h
+}
+
+// remove conversations, messages, events, cron runs, audit logs and revoked sessions older than their retention, execute every hour
+func (h *RetentionCronHandler) ApplyRetention() {
+	h.cronUsecase.RunWithResult(domain.CronJobApplyRetention, func(ctx context.Context) (domain.CronRunResult, error) {
+		h.logger.Info("apply retention start")
+		result, err := h.retentionUsecase.ApplyRetention(ctx)
+		if err != nil {
+			h.logger.Error("apply retention failed", log.Error(err))
+			return result, err
+		}
+		h.logger.Info("apply retention successful", log.Any("result", result))
+		return result, nil
+	})
+}
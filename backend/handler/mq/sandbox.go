@@ -0,0 +1,47 @@
+package mq
+
+import (
+	"context"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type SandboxCronHandler struct {
+	logger         *log.Logger
+	sandboxUsecase *usecase.SandboxUsecase
+	cronUsecase    *usecase.CronUsecase
+}
+
+func NewSandboxCronHandler(logger *log.Logger, sandboxUsecase *usecase.SandboxUsecase, cronUsecase *usecase.CronUsecase) *SandboxCronHandler {
+	h := &SandboxCronHandler{
+		sandboxUsecase: sandboxUsecase,
+		cronUsecase:    cronUsecase,
+		logger:         logger.WithModule("handler.mq.sandbox"),
+	}
+	cron := cron.New()
+	cron.AddFunc("*/10 * * * *", h.DeleteExpiredSandboxes)
+	h.logger.Info("add cron job", log.String("cron_id", "delete_expired_sandboxes"))
+	cron.Start()
+	h.logger.Info("start cron job")
+	return h
+}
+
+// tear down sandbox kbs ci pipelines left behind after they expired, execute every 10 minutes
+func (h *SandboxCronHandler) DeleteExpiredSandboxes() {
+	h.cronUsecase.RunWithResult(domain.CronJobDeleteExpiredSandboxes, func(ctx context.Context) (domain.CronRunResult, error) {
+		deleted, err := h.sandboxUsecase.DeleteExpiredSandboxes(ctx)
+		result := domain.CronRunResult{"deleted": deleted}
+		if err != nil {
+			h.logger.Error("delete expired sandboxes failed", log.Error(err))
+			return result, err
+		}
+		if deleted > 0 {
+			h.logger.Info("expired sandboxes deleted", log.Int("count", deleted))
+		}
+		return result, nil
+	})
+}
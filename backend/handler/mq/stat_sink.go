@@ -0,0 +1,98 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/chaitin/panda-wiki/config"
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/mq"
+	"github.com/chaitin/panda-wiki/mq/types"
+	"github.com/chaitin/panda-wiki/store/statsink"
+)
+
+const statSinkMaxRetry = 3
+
+// StatSinkMQHandler buffer stat events from mq and write them to sink in batches.
+// events are acked once buffered, so a batch in memory may be lost if the consumer exits
+type StatSinkMQHandler struct {
+	logger        *log.Logger
+	sink          statsink.Sink
+	batchSize     int
+	flushInterval time.Duration
+
+	mu     sync.Mutex
+	buffer []*domain.StatEvent
+	// serialize writes to keep batches in order
+	writeMu sync.Mutex
+}
+
+func NewStatSinkMQHandler(consumer mq.MQConsumer, config *config.Config, sink statsink.Sink, logger *log.Logger) (*StatSinkMQHandler, error) {
+	h := &StatSinkMQHandler{
+		logger:        logger.WithModule("handler.mq.stat_sink"),
+		sink:          sink,
+		batchSize:     max(config.StatSink.BatchSize, 1),
+		flushInterval: time.Duration(max(config.StatSink.FlushInterval, 1)) * time.Second,
+	}
+	if sink == nil {
+		h.logger.Info("stat sink is disabled")
+		return h, nil
+	}
+	if err := consumer.RegisterHandler(domain.StatEventTopic, h.HandleStatEvent); err != nil {
+		return nil, err
+	}
+	go h.flushLoop()
+	h.logger.Info("stat sink started", log.String("sink", sink.Name()), log.Int("batch_size", h.batchSize))
+	return h, nil
+}
+
+func (h *StatSinkMQHandler) HandleStatEvent(ctx context.Context, msg types.Message) error {
+	var event domain.StatEvent
+	if err := json.Unmarshal(msg.GetData(), &event); err != nil {
+		h.logger.Error("unmarshal stat event failed", log.Error(err))
+		return nil
+	}
+	h.mu.Lock()
+	h.buffer = append(h.buffer, &event)
+	full := len(h.buffer) >= h.batchSize
+	h.mu.Unlock()
+	if full {
+		h.flush(ctx)
+	}
+	return nil
+}
+
+func (h *StatSinkMQHandler) flushLoop() {
+	ticker := time.NewTicker(h.flushInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		h.flush(context.Background())
+	}
+}
+
+func (h *StatSinkMQHandler) flush(ctx context.Context) {
+	h.writeMu.Lock()
+	defer h.writeMu.Unlock()
+
+	h.mu.Lock()
+	events := h.buffer
+	h.buffer = nil
+	h.mu.Unlock()
+	if len(events) == 0 {
+		return
+	}
+
+	var err error
+	for i := 0; i < statSinkMaxRetry; i++ {
+		if err = h.sink.Write(ctx, events); err == nil {
+			h.logger.Debug("write stat events to sink", log.Int("count", len(events)))
+			return
+		}
+		h.logger.Warn("write stat events to sink failed, retrying", log.Int("attempt", i+1), log.Error(err))
+		time.Sleep(time.Duration(i+1) * time.Second)
+	}
+	h.logger.Error("drop stat events after retries", log.Int("count", len(events)), log.Error(err))
+}
@@ -0,0 +1,45 @@
+package mq
+
+import (
+	"context"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type TelemetryCronHandler struct {
+	logger           *log.Logger
+	telemetryUsecase *usecase.TelemetryUsecase
+	cronUsecase      *usecase.CronUsecase
+}
+
+func NewTelemetryCronHandler(logger *log.Logger, telemetryUsecase *usecase.TelemetryUsecase, cronUsecase *usecase.CronUsecase) *TelemetryCronHandler {
+	h := &TelemetryCronHandler{
+		telemetryUsecase: telemetryUsecase,
+		cronUsecase:      cronUsecase,
+		logger:           logger.WithModule("handler.mq.telemetry"),
+	}
+	cron := cron.New()
+	cron.AddFunc("30 4 * * *", h.SendTelemetryReport)
+	h.logger.Info("add cron job", log.String("cron_id", "send_telemetry_report"))
+	cron.Start()
+	h.logger.Info("start cron job")
+	return h
+}
+
+// remove old telemetry usage and report upstream if the operator opted in, execute every day 04:30
+func (h *TelemetryCronHandler) SendTelemetryReport() {
+	h.cronUsecase.RunWithResult(domain.CronJobSendTelemetryReport, func(ctx context.Context) (domain.CronRunResult, error) {
+		h.logger.Info("send telemetry report start")
+		result, err := h.telemetryUsecase.SendReport(ctx)
+		if err != nil {
+			h.logger.Error("send telemetry report failed", log.Error(err))
+			return result, err
+		}
+		h.logger.Info("send telemetry report successful", log.Any("result", result))
+		return result, nil
+	})
+}
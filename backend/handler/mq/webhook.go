@@ -0,0 +1,38 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/mq"
+	"github.com/chaitin/panda-wiki/mq/types"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type WebhookMQHandler struct {
+	logger         *log.Logger
+	webhookUsecase *usecase.WebhookUsecase
+}
+
+func NewWebhookMQHandler(consumer mq.MQConsumer, logger *log.Logger, webhookUsecase *usecase.WebhookUsecase) (*WebhookMQHandler, error) {
+	h := &WebhookMQHandler{
+		logger:         logger.WithModule("handler.mq.webhook"),
+		webhookUsecase: webhookUsecase,
+	}
+	if err := consumer.RegisterHandler(domain.WebhookEventTopic, h.HandleWebhookEvent); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func (h *WebhookMQHandler) HandleWebhookEvent(ctx context.Context, msg types.Message) error {
+	var event domain.WebhookEvent
+	if err := json.Unmarshal(msg.GetData(), &event); err != nil {
+		h.logger.Error("unmarshal webhook event failed", log.Error(err))
+		return nil
+	}
+	h.webhookUsecase.DeliverEvent(ctx, &event)
+	return nil
+}
@@ -33,12 +33,14 @@ func NewShareAppHandler(
 		usecase:     usecase,
 	}
 
+	// web info is shown on the login page of private kbs, so viewers are identified without authorizing
 	share := e.Group("share/v1/app",
+		h.BaseHandler.ShareAuthMiddleware.Identify,
 		func(next echo.HandlerFunc) echo.HandlerFunc {
 			return func(c echo.Context) error {
 				c.Response().Header().Set("Access-Control-Allow-Origin", "*")
 				c.Response().Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-				c.Response().Header().Set("Access-Control-Allow-Headers", "Content-Type, Origin, Accept")
+				c.Response().Header().Set("Access-Control-Allow-Headers", "Content-Type, Origin, Accept, X-Viewer-Token")
 				if c.Request().Method == "OPTIONS" {
 					return c.NoContent(http.StatusOK)
 				}
@@ -72,7 +74,7 @@ func (h *ShareAppHandler) GetWebAppInfo(c echo.Context) error {
 	if kbID == "" {
 		return h.NewResponseWithError(c, "kb_id is required", nil)
 	}
-	appInfo, err := h.usecase.GetWebAppInfo(c.Request().Context(), kbID)
+	appInfo, err := h.usecase.GetWebAppInfo(c.Request().Context(), kbID, h.Viewer(c))
 	if err != nil {
 		return h.NewResponseWithError(c, err.Error(), err)
 	}
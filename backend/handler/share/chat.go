@@ -2,6 +2,7 @@ package share
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -10,6 +11,7 @@ import (
 	"github.com/chaitin/panda-wiki/domain"
 	"github.com/chaitin/panda-wiki/handler"
 	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/store/mail"
 	"github.com/chaitin/panda-wiki/usecase"
 )
 
@@ -20,6 +22,7 @@ type ShareChatHandler struct {
 	chatUsecase         *usecase.ChatUsecase
 	conversationUsecase *usecase.ConversationUsecase
 	modelUsecase        *usecase.ModelUsecase
+	transcriptUsecase   *usecase.TranscriptEmailUsecase
 }
 
 func NewShareChatHandler(
@@ -30,6 +33,7 @@ func NewShareChatHandler(
 	chatUsecase *usecase.ChatUsecase,
 	conversationUsecase *usecase.ConversationUsecase,
 	modelUsecase *usecase.ModelUsecase,
+	transcriptUsecase *usecase.TranscriptEmailUsecase,
 ) *ShareChatHandler {
 	h := &ShareChatHandler{
 		BaseHandler:         baseHandler,
@@ -38,6 +42,7 @@ func NewShareChatHandler(
 		chatUsecase:         chatUsecase,
 		conversationUsecase: conversationUsecase,
 		modelUsecase:        modelUsecase,
+		transcriptUsecase:   transcriptUsecase,
 	}
 
 	share := e.Group("share/v1/chat",
@@ -46,7 +51,7 @@ func NewShareChatHandler(
 			return func(c echo.Context) error {
 				c.Response().Header().Set("Access-Control-Allow-Origin", "*")
 				c.Response().Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-				c.Response().Header().Set("Access-Control-Allow-Headers", "Content-Type, Origin, Accept")
+				c.Response().Header().Set("Access-Control-Allow-Headers", "Content-Type, Origin, Accept, X-Viewer-Token")
 				if c.Request().Method == "OPTIONS" {
 					return c.NoContent(http.StatusOK)
 				}
@@ -54,6 +59,9 @@ func NewShareChatHandler(
 			}
 		})
 	share.POST("/message", h.ChatMessage)
+	share.POST("/transcript_email", h.SendTranscriptEmail)
+	share.POST("/feedback", h.SubmitFeedback)
+	share.POST("/prefetch", h.PrefetchRetrieval)
 
 	return h
 }
@@ -73,15 +81,29 @@ func (h *ShareChatHandler) ChatMessage(c echo.Context) error {
 	var req domain.ChatRequest
 	if err := c.Bind(&req); err != nil {
 		h.logger.Error("parse request failed", log.Error(err))
-		return h.sendErrMsg(c, "parse request failed")
+		return h.sendErrMsg(c, "parse request failed", domain.ErrCodeInvalidRequest)
 	}
 	req.KBID = c.Request().Header.Get("X-KB-ID") // get from caddy header
 	if err := c.Validate(&req); err != nil {
 		h.logger.Error("validate request failed", log.Error(err))
-		return h.sendErrMsg(c, "validate request failed")
+		return h.sendErrMsg(c, "validate request failed", domain.ErrCodeInvalidRequest)
 	}
 
 	req.RemoteIP = c.RealIP()
+	req.UserAgent = c.Request().UserAgent()
+	req.Viewer = h.Viewer(c)
+	if req.Locale == "" {
+		req.Locale = domain.PromptLocale(c.Request().Header.Get("Accept-Language"))
+	}
+	referer := req.Referer
+	if referer == "" {
+		referer = c.Request().Referer()
+	}
+	req.Info.Source = domain.NewTrafficSource(referer, req.URL)
+	req.Info.Platform = domain.NewClientPlatform(req.UserAgent)
+	if sessionIDCookie, err := c.Request().Cookie("x-pw-session-id"); err == nil {
+		req.SessionID = sessionIDCookie.Value
+	}
 
 	c.Response().Header().Set("Content-Type", "text/event-stream")
 	c.Response().Header().Set("Cache-Control", "no-cache")
@@ -90,7 +112,7 @@ func (h *ShareChatHandler) ChatMessage(c echo.Context) error {
 
 	eventCh, err := h.chatUsecase.Chat(c.Request().Context(), &req)
 	if err != nil {
-		return h.sendErrMsg(c, err.Error())
+		return h.sendErrMsg(c, err.Error(), handler.ErrorCode(err))
 	}
 
 	for event := range eventCh {
@@ -104,8 +126,96 @@ func (h *ShareChatHandler) ChatMessage(c echo.Context) error {
 	return nil
 }
 
-func (h *ShareChatHandler) sendErrMsg(c echo.Context, errMsg string) error {
-	return h.writeSSEEvent(c, domain.SSEEvent{Type: "error", Content: errMsg})
+// SendTranscriptEmail send conversation transcript to email
+//
+//	@Summary		SendTranscriptEmail
+//	@Description	send a copy of the conversation with reference links to the email of end user
+//	@Tags			share_chat
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		domain.SendTranscriptEmailReq	true	"request"
+//	@Success		200		{object}	domain.Response
+//	@Router			/share/v1/chat/transcript_email [post]
+func (h *ShareChatHandler) SendTranscriptEmail(c echo.Context) error {
+	var req domain.SendTranscriptEmailReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	req.KBID = c.Request().Header.Get("X-KB-ID")
+	req.RemoteIP = c.RealIP()
+	if err := h.transcriptUsecase.SendTranscript(c.Request().Context(), &req); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrTranscriptEmailRateLimited):
+			return h.NewResponseWithError(c, "请求过于频繁，请稍后再试", err)
+		case errors.Is(err, mail.ErrMailNotConfigured):
+			return h.NewResponseWithError(c, "邮件服务未配置", err)
+		}
+		return h.NewResponseWithError(c, "发送邮件失败", err)
+	}
+	return h.NewResponseWithData(c, nil)
+}
+
+// SubmitFeedback rate an answer
+//
+//	@Summary		SubmitFeedback
+//	@Description	like or dislike an answer of the conversation, the latest answer if message_id is empty, rating again replaces the previous one
+//	@Tags			share_chat
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		domain.MessageFeedbackReq	true	"request"
+//	@Success		200		{object}	domain.Response
+//	@Router			/share/v1/chat/feedback [post]
+func (h *ShareChatHandler) SubmitFeedback(c echo.Context) error {
+	var req domain.MessageFeedbackReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	req.KBID = c.Request().Header.Get("X-KB-ID")
+	if sessionIDCookie, err := c.Request().Cookie("x-pw-session-id"); err == nil {
+		req.SessionID = sessionIDCookie.Value
+	}
+	if err := h.conversationUsecase.SubmitFeedback(c.Request().Context(), &req); err != nil {
+		return h.NewResponseWithError(c, "提交反馈失败", err)
+	}
+	return h.NewResponseWithData(c, nil)
+}
+
+// PrefetchRetrieval retrieve documents of a draft question
+//
+//	@Summary		PrefetchRetrieval
+//	@Description	called by the widget while the user is typing, debounced, to retrieve documents of the draft question in advance. a message sent with the same question, ignoring case, spaces and trailing punctuation, reuses them within 2 minutes
+//	@Tags			share_chat
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		domain.PrefetchRetrievalReq	true	"request"
+//	@Success		200		{object}	domain.Response{data=domain.PrefetchRetrievalResp}
+//	@Router			/share/v1/chat/prefetch [post]
+func (h *ShareChatHandler) PrefetchRetrieval(c echo.Context) error {
+	var req domain.PrefetchRetrievalReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	req.KBID = c.Request().Header.Get("X-KB-ID")
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	req.RemoteIP = c.RealIP()
+	req.UserAgent = c.Request().UserAgent()
+	resp, err := h.chatUsecase.PrefetchRetrieval(c.Request().Context(), &req)
+	if err != nil {
+		return h.NewResponseWithError(c, "prefetch retrieval failed", err)
+	}
+	return h.NewResponseWithData(c, resp)
+}
+
+func (h *ShareChatHandler) sendErrMsg(c echo.Context, errMsg string, code domain.ErrorCode) error {
+	return h.writeSSEEvent(c, domain.SSEEvent{Type: "error", Content: errMsg, Code: code})
 }
 
 func (h *ShareChatHandler) writeSSEEvent(c echo.Context, data any) error {
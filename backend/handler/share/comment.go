@@ -0,0 +1,99 @@
+package share
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/handler"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type ShareCommentHandler struct {
+	*handler.BaseHandler
+	usecase        *usecase.NodeCommentUsecase
+	nodeACLUsecase *usecase.NodeACLUsecase
+	logger         *log.Logger
+}
+
+func NewShareCommentHandler(echo *echo.Echo, baseHandler *handler.BaseHandler, usecase *usecase.NodeCommentUsecase, nodeACLUsecase *usecase.NodeACLUsecase, logger *log.Logger) *ShareCommentHandler {
+	h := &ShareCommentHandler{
+		BaseHandler:    baseHandler,
+		usecase:        usecase,
+		nodeACLUsecase: nodeACLUsecase,
+		logger:         logger.WithModule("handler.share.comment"),
+	}
+
+	group := echo.Group("share/v1/comment",
+		h.BaseHandler.ShareAuthMiddleware.Authorize,
+	)
+	group.GET("/list", h.GetNodeComments)
+	group.POST("", h.CreateNodeComment)
+
+	return h
+}
+
+// GetNodeComments
+//
+//	@Summary		GetNodeComments
+//	@Description	approved comment threads of published node
+//	@Tags			share_comment
+//	@Accept			json
+//	@Produce		json
+//	@Param			X-KB-ID	header		string	true	"kb id"
+//	@Param			node_id	query		string	true	"node id"
+//	@Success		200		{object}	domain.Response{data=[]domain.ShareNodeComment}
+//	@Router			/share/v1/comment/list [get]
+func (h *ShareCommentHandler) GetNodeComments(c echo.Context) error {
+	kbID := c.Request().Header.Get("X-KB-ID")
+	if kbID == "" {
+		return h.NewResponseWithError(c, "kb_id is required", nil)
+	}
+	nodeID := c.QueryParam("node_id")
+	if nodeID == "" {
+		return h.NewResponseWithError(c, "node_id is required", nil)
+	}
+	if err := h.nodeACLUsecase.CheckNode(c.Request().Context(), kbID, nodeID, h.Viewer(c)); err != nil {
+		return h.NewResponseWithError(c, "failed to get node comments", err)
+	}
+	comments, err := h.usecase.GetShareNodeComments(c.Request().Context(), kbID, nodeID)
+	if err != nil {
+		return h.NewResponseWithError(c, "failed to get node comments", err)
+	}
+	return h.NewResponseWithData(c, comments)
+}
+
+// CreateNodeComment
+//
+//	@Summary		CreateNodeComment
+//	@Description	comment on published node, pending or spam comments are shown after approved
+//	@Tags			share_comment
+//	@Accept			json
+//	@Produce		json
+//	@Param			X-KB-ID	header		string						true	"kb id"
+//	@Param			request	body		domain.CreateNodeCommentReq	true	"request"
+//	@Success		200		{object}	domain.Response{data=domain.CreateNodeCommentResp}
+//	@Router			/share/v1/comment [post]
+func (h *ShareCommentHandler) CreateNodeComment(c echo.Context) error {
+	req := &domain.CreateNodeCommentReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "bind request body failed", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	req.KBID = c.Request().Header.Get("X-KB-ID")
+	if req.KBID == "" {
+		return h.NewResponseWithError(c, "kb_id is required", nil)
+	}
+	if err := h.nodeACLUsecase.CheckNode(c.Request().Context(), req.KBID, req.NodeID, h.Viewer(c)); err != nil {
+		return h.NewResponseWithError(c, "failed to create node comment", err)
+	}
+	req.RemoteIP = c.RealIP()
+	req.UserAgent = c.Request().UserAgent()
+	resp, err := h.usecase.CreateShareNodeComment(c.Request().Context(), req)
+	if err != nil {
+		return h.NewResponseWithError(c, "failed to create node comment", err)
+	}
+	return h.NewResponseWithData(c, resp)
+}
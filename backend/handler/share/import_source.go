@@ -0,0 +1,75 @@
+package share
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/handler"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+// max size of push events, github sends up to 25MB
+const maxGitWebhookBodySize = 25 * 1024 * 1024
+
+type ShareImportSourceHandler struct {
+	*handler.BaseHandler
+	logger  *log.Logger
+	usecase *usecase.ImportSourceUsecase
+}
+
+func NewShareImportSourceHandler(
+	e *echo.Echo,
+	baseHandler *handler.BaseHandler,
+	logger *log.Logger,
+	usecase *usecase.ImportSourceUsecase,
+) *ShareImportSourceHandler {
+	h := &ShareImportSourceHandler{
+		BaseHandler: baseHandler,
+		logger:      logger.WithModule("handler.share.import_source"),
+		usecase:     usecase,
+	}
+
+	group := e.Group("share/v1/import_source")
+	group.POST("/git/webhook", h.GitWebhook)
+
+	return h
+}
+
+// GitWebhook push events of github or gitlab
+//
+//	@Summary		GitWebhook
+//	@Description	webhook of a git import source, push events of its branch start a sync. github webhooks are signed with the webhook secret, gitlab webhooks send it as secret token
+//	@Tags			share_import_source
+//	@Accept			json
+//	@Produce		json
+//	@Param			X-KB-ID	header		string	true	"kb id"
+//	@Param			id		query		string	true	"import source id"
+//	@Success		200		{object}	domain.Response{data=domain.GitWebhookResp}
+//	@Router			/share/v1/import_source/git/webhook [post]
+func (h *ShareImportSourceHandler) GitWebhook(c echo.Context) error {
+	kbID := c.Request().Header.Get("X-KB-ID")
+	if kbID == "" {
+		return h.NewResponseWithError(c, "kb_id is required", nil)
+	}
+	id := c.QueryParam("id")
+	if id == "" {
+		return h.NewResponseWithError(c, "id is required", nil)
+	}
+	body, err := io.ReadAll(io.LimitReader(c.Request().Body, maxGitWebhookBodySize))
+	if err != nil {
+		return h.NewResponseWithError(c, "read request body failed", err)
+	}
+	resp, err := h.usecase.HandleGitWebhook(c.Request().Context(), kbID, id, c.Request().Header, body)
+	if err != nil {
+		if errors.Is(err, domain.ErrGitWebhookSignature) {
+			return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+		}
+		return h.NewResponseWithError(c, "handle git webhook failed", err)
+	}
+	return h.NewResponseWithData(c, resp)
+}
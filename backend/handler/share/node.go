@@ -3,6 +3,7 @@ package share
 import (
 	"github.com/labstack/echo/v4"
 
+	"github.com/chaitin/panda-wiki/domain"
 	"github.com/chaitin/panda-wiki/handler"
 	"github.com/chaitin/panda-wiki/log"
 	"github.com/chaitin/panda-wiki/usecase"
@@ -12,18 +13,29 @@ type ShareNodeHandler struct {
 	*handler.BaseHandler
 	logger  *log.Logger
 	usecase *usecase.NodeUsecase
+
+	attachmentUsecase *usecase.NodeAttachmentUsecase
+	glossaryUsecase   *usecase.GlossaryUsecase
+	nodeACLUsecase    *usecase.NodeACLUsecase
 }
 
 func NewShareNodeHandler(
 	baseHandler *handler.BaseHandler,
 	echo *echo.Echo,
 	usecase *usecase.NodeUsecase,
+	attachmentUsecase *usecase.NodeAttachmentUsecase,
+	glossaryUsecase *usecase.GlossaryUsecase,
+	nodeACLUsecase *usecase.NodeACLUsecase,
 	logger *log.Logger,
 ) *ShareNodeHandler {
 	h := &ShareNodeHandler{
 		BaseHandler: baseHandler,
 		logger:      logger.WithModule("handler.share.node"),
 		usecase:     usecase,
+
+		attachmentUsecase: attachmentUsecase,
+		glossaryUsecase:   glossaryUsecase,
+		nodeACLUsecase:    nodeACLUsecase,
 	}
 
 	group := echo.Group("share/v1/node",
@@ -31,6 +43,7 @@ func NewShareNodeHandler(
 	)
 	group.GET("/list", h.GetNodeList)
 	group.GET("/detail", h.GetNodeDetail)
+	group.GET("/attachment/list", h.GetNodeAttachmentList)
 
 	return h
 }
@@ -55,6 +68,19 @@ func (h *ShareNodeHandler) GetNodeList(c echo.Context) error {
 	if err != nil {
 		return h.NewResponseWithError(c, "failed to get node list", err)
 	}
+	aclFilter, err := h.nodeACLUsecase.ViewerFilter(c.Request().Context(), kbID, h.Viewer(c))
+	if err != nil {
+		return h.NewResponseWithError(c, "failed to get node acls", err)
+	}
+	if aclFilter != nil {
+		visible := make([]*domain.ShareNodeListItemResp, 0, len(nodes))
+		for _, node := range nodes {
+			if aclFilter.Allows(node.ID) {
+				visible = append(visible, node)
+			}
+		}
+		nodes = visible
+	}
 
 	return h.NewResponseWithData(c, nodes)
 }
@@ -80,9 +106,44 @@ func (h *ShareNodeHandler) GetNodeDetail(c echo.Context) error {
 		return h.NewResponseWithError(c, "id is required", nil)
 	}
 
+	if err := h.nodeACLUsecase.CheckNode(c.Request().Context(), kbID, id, h.Viewer(c)); err != nil {
+		return h.NewResponseWithError(c, "failed to get node detail", err)
+	}
 	node, err := h.usecase.GetNodeReleaseDetailByKBIDAndID(c.Request().Context(), kbID, id)
 	if err != nil {
 		return h.NewResponseWithError(c, "failed to get node detail", err)
 	}
+	node.Content = h.glossaryUsecase.LinkContent(c.Request().Context(), kbID, node.Content)
 	return h.NewResponseWithData(c, node)
 }
+
+// GetNodeAttachmentList
+//
+//	@Summary		GetNodeAttachmentList
+//	@Description	attachments of published node with signed download urls
+//	@Tags			share_node
+//	@Accept			json
+//	@Produce		json
+//	@Param			X-KB-ID	header		string	true	"kb id"
+//	@Param			id		query		string	true	"node id"
+//	@Success		200		{object}	domain.Response{data=[]domain.NodeAttachmentListItem}
+//	@Router			/share/v1/node/attachment/list [get]
+func (h *ShareNodeHandler) GetNodeAttachmentList(c echo.Context) error {
+	kbID := c.Request().Header.Get("X-KB-ID")
+	if kbID == "" {
+		return h.NewResponseWithError(c, "kb_id is required", nil)
+	}
+	id := c.QueryParam("id")
+	if id == "" {
+		return h.NewResponseWithError(c, "id is required", nil)
+	}
+
+	if err := h.nodeACLUsecase.CheckNode(c.Request().Context(), kbID, id, h.Viewer(c)); err != nil {
+		return h.NewResponseWithError(c, "failed to get node attachment list", err)
+	}
+	attachments, err := h.attachmentUsecase.GetPublishedNodeAttachmentList(c.Request().Context(), kbID, id)
+	if err != nil {
+		return h.NewResponseWithError(c, "failed to get node attachment list", err)
+	}
+	return h.NewResponseWithData(c, attachments)
+}
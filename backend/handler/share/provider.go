@@ -3,11 +3,15 @@ package share
 import "github.com/google/wire"
 
 type ShareHandler struct {
-	ShareNodeHandler    *ShareNodeHandler
-	ShareAppHandler     *ShareAppHandler
-	ShareChatHandler    *ShareChatHandler
-	ShareSitemapHandler *ShareSitemapHandler
-	ShareStatHandler    *ShareStatHandler
+	ShareNodeHandler         *ShareNodeHandler
+	ShareAppHandler          *ShareAppHandler
+	ShareChatHandler         *ShareChatHandler
+	ShareSitemapHandler      *ShareSitemapHandler
+	ShareStatHandler         *ShareStatHandler
+	ShareSearchHandler       *ShareSearchHandler
+	ShareCommentHandler      *ShareCommentHandler
+	ShareImportSourceHandler *ShareImportSourceHandler
+	ShareReaderHandler       *ShareReaderHandler
 }
 
 var ProviderSet = wire.NewSet(
@@ -16,6 +20,10 @@ var ProviderSet = wire.NewSet(
 	NewShareChatHandler,
 	NewShareSitemapHandler,
 	NewShareStatHandler,
+	NewShareSearchHandler,
+	NewShareCommentHandler,
+	NewShareImportSourceHandler,
+	NewShareReaderHandler,
 
 	wire.Struct(new(ShareHandler), "*"),
 )
@@ -0,0 +1,97 @@
+package share
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/handler"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type ShareReaderHandler struct {
+	*handler.BaseHandler
+	logger  *log.Logger
+	usecase *usecase.ReaderUsecase
+}
+
+func NewShareReaderHandler(
+	e *echo.Echo,
+	baseHandler *handler.BaseHandler,
+	logger *log.Logger,
+	usecase *usecase.ReaderUsecase,
+) *ShareReaderHandler {
+	h := &ShareReaderHandler{
+		BaseHandler: baseHandler,
+		logger:      logger.WithModule("handler.share.reader"),
+		usecase:     usecase,
+	}
+
+	// login is open to readers of private kbs
+	group := e.Group("share/v1/reader",
+		h.BaseHandler.ShareAuthMiddleware.Identify,
+		func(next echo.HandlerFunc) echo.HandlerFunc {
+			return func(c echo.Context) error {
+				c.Response().Header().Set("Access-Control-Allow-Origin", "*")
+				c.Response().Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+				c.Response().Header().Set("Access-Control-Allow-Headers", "Content-Type, Origin, Accept, X-Viewer-Token")
+				if c.Request().Method == "OPTIONS" {
+					return c.NoContent(http.StatusOK)
+				}
+				return next(c)
+			}
+		})
+	group.POST("/login", h.Login)
+	group.GET("/info", h.GetViewerInfo)
+
+	return h
+}
+
+// Login
+//
+//	@Summary		Login
+//	@Description	log in a reader account, the token is sent as X-Viewer-Token header to the share apis
+//	@Tags			share_reader
+//	@Accept			json
+//	@Produce		json
+//	@Param			X-KB-ID	header		string					true	"kb id"
+//	@Param			request	body		domain.ReaderLoginReq	true	"request"
+//	@Success		200		{object}	domain.Response{data=domain.ReaderLoginResp}
+//	@Router			/share/v1/reader/login [post]
+func (h *ShareReaderHandler) Login(c echo.Context) error {
+	req := &domain.ReaderLoginReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "bind request body failed", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	req.KBID = c.Request().Header.Get("X-KB-ID")
+	if req.KBID == "" {
+		return h.NewResponseWithError(c, "kb_id is required", nil)
+	}
+	req.RemoteIP = c.RealIP()
+	req.UserAgent = c.Request().UserAgent()
+	resp, err := h.usecase.Login(c.Request().Context(), req)
+	if err != nil {
+		return h.NewResponseWithError(c, "reader login failed", err)
+	}
+	return h.NewResponseWithData(c, resp)
+}
+
+// GetViewerInfo
+//
+//	@Summary		GetViewerInfo
+//	@Description	viewer identified by the X-Viewer-Token header, a reader account or a user of the sso portal. anonymous viewers have no user id
+//	@Tags			share_reader
+//	@Accept			json
+//	@Produce		json
+//	@Param			X-KB-ID			header		string	true	"kb id"
+//	@Param			X-Viewer-Token	header		string	false	"viewer token"
+//	@Success		200				{object}	domain.Response{data=domain.NodeViewer}
+//	@Router			/share/v1/reader/info [get]
+func (h *ShareReaderHandler) GetViewerInfo(c echo.Context) error {
+	return h.NewResponseWithData(c, h.Viewer(c))
+}
@@ -0,0 +1,166 @@
+package share
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/handler"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type ShareSearchHandler struct {
+	*handler.BaseHandler
+	usecase *usecase.SearchUsecase
+	logger  *log.Logger
+}
+
+func NewShareSearchHandler(echo *echo.Echo, baseHandler *handler.BaseHandler, usecase *usecase.SearchUsecase, logger *log.Logger) *ShareSearchHandler {
+	h := &ShareSearchHandler{
+		BaseHandler: baseHandler,
+		usecase:     usecase,
+		logger:      logger.WithModule("handler.share.search"),
+	}
+
+	echo.GET("/opensearch.xml", h.GetOpenSearchDescription)
+
+	group := echo.Group("share/v1/search",
+		h.BaseHandler.ShareAuthMiddleware.Authorize,
+	)
+	group.GET("", h.SearchNodes)
+	group.GET("/suggest", h.GetSearchSuggestions)
+	group.GET("/fulltext", h.FullTextSearchNodes)
+	group.GET("/complete", h.GetSearchCompletions)
+
+	return h
+}
+
+// GetOpenSearchDescription opensearch description document of kb
+func (h *ShareSearchHandler) GetOpenSearchDescription(c echo.Context) error {
+	kbID := c.Request().Header.Get("X-KB-ID")
+	if kbID == "" {
+		return h.NewResponseWithError(c, "kb_id is required", nil)
+	}
+	doc, err := h.usecase.GetOpenSearchDescription(c.Request().Context(), kbID)
+	if err != nil {
+		return h.NewResponseWithError(c, "failed to generate opensearch description", err)
+	}
+	return c.Blob(http.StatusOK, "application/opensearchdescription+xml; charset=UTF-8", []byte(doc))
+}
+
+// SearchNodes
+//
+//	@Summary		SearchNodes
+//	@Description	keyword search of published documents, name matches first
+//	@Tags			share_search
+//	@Accept			json
+//	@Produce		json
+//	@Param			X-KB-ID	header		string					true	"kb id"
+//	@Param			req		query		domain.NodeSearchReq	true	"search request"
+//	@Success		200		{object}	domain.Response{data=[]domain.NodeSearchResult}
+//	@Router			/share/v1/search [get]
+func (h *ShareSearchHandler) SearchNodes(c echo.Context) error {
+	var req domain.NodeSearchReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	req.KBID = c.Request().Header.Get("X-KB-ID")
+	req.Viewer = h.Viewer(c)
+	nodes, err := h.usecase.SearchNodes(c.Request().Context(), &req)
+	if err != nil {
+		return h.NewResponseWithError(c, "failed to search nodes", err)
+	}
+	return h.NewResponseWithData(c, nodes)
+}
+
+type FullTextSearchResults = domain.PaginatedResult[[]*domain.FullTextSearchResult]
+
+// FullTextSearchNodes
+//
+//	@Summary		FullTextSearchNodes
+//	@Description	full-text search of published documents ranked by relevance, with highlighted name and content snippets
+//	@Tags			share_search
+//	@Accept			json
+//	@Produce		json
+//	@Param			X-KB-ID	header		string						true	"kb id"
+//	@Param			req		query		domain.FullTextSearchReq	true	"search request"
+//	@Success		200		{object}	domain.Response{data=FullTextSearchResults}
+//	@Router			/share/v1/search/fulltext [get]
+func (h *ShareSearchHandler) FullTextSearchNodes(c echo.Context) error {
+	var req domain.FullTextSearchReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	req.KBID = c.Request().Header.Get("X-KB-ID")
+	req.Viewer = h.Viewer(c)
+	results, err := h.usecase.FullTextSearchNodes(c.Request().Context(), &req)
+	if err != nil {
+		return h.NewResponseWithError(c, "failed to search nodes", err)
+	}
+	return h.NewResponseWithData(c, results)
+}
+
+// GetSearchCompletions
+//
+//	@Summary		GetSearchCompletions
+//	@Description	completions of a partial query from document titles and popular questions for the search box, tolerating typos
+//	@Tags			share_search
+//	@Accept			json
+//	@Produce		json
+//	@Param			X-KB-ID	header		string						true	"kb id"
+//	@Param			req		query		domain.SearchCompletionReq	true	"completion request"
+//	@Success		200		{object}	domain.Response{data=[]domain.SearchCompletion}
+//	@Router			/share/v1/search/complete [get]
+func (h *ShareSearchHandler) GetSearchCompletions(c echo.Context) error {
+	var req domain.SearchCompletionReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	req.KBID = c.Request().Header.Get("X-KB-ID")
+	req.Viewer = h.Viewer(c)
+	completions, err := h.usecase.GetSearchCompletions(c.Request().Context(), &req)
+	if err != nil {
+		return h.NewResponseWithError(c, "failed to get search completions", err)
+	}
+	return h.NewResponseWithData(c, completions)
+}
+
+// GetSearchSuggestions
+//
+//	@Summary		GetSearchSuggestions
+//	@Description	search suggestions of browsers in opensearch suggestions format
+//	@Tags			share_search
+//	@Accept			json
+//	@Produce		json
+//	@Param			X-KB-ID	header	string	true	"kb id"
+//	@Param			q		query	string	true	"search terms"
+//	@Success		200		{array}	any
+//	@Router			/share/v1/search/suggest [get]
+func (h *ShareSearchHandler) GetSearchSuggestions(c echo.Context) error {
+	req := &domain.NodeSearchReq{
+		Query:  c.QueryParam("q"),
+		KBID:   c.Request().Header.Get("X-KB-ID"),
+		Viewer: h.Viewer(c),
+	}
+	if req.Query == "" {
+		return c.JSON(http.StatusOK, []any{"", []string{}, []string{}, []string{}})
+	}
+	suggestions, err := h.usecase.GetSearchSuggestions(c.Request().Context(), req)
+	if err != nil {
+		h.logger.Error("failed to get search suggestions", log.Error(err))
+		return c.JSON(http.StatusOK, []any{req.Query, []string{}, []string{}, []string{}})
+	}
+	c.Response().Header().Set(echo.HeaderContentType, "application/x-suggestions+json; charset=UTF-8")
+	return c.JSON(http.StatusOK, suggestions)
+}
@@ -36,7 +36,7 @@ func (h *ShareSitemapHandler) GetSitemap(c echo.Context) error {
 	if kbID == "" {
 		return h.NewResponseWithError(c, "kb_id is required", nil)
 	}
-	appInfo, err := h.appUsecase.GetWebAppInfo(c.Request().Context(), kbID)
+	appInfo, err := h.appUsecase.GetWebAppInfo(c.Request().Context(), kbID, nil)
 	if err != nil {
 		return h.NewResponseWithError(c, "web app not found", err)
 	}
@@ -1,7 +1,6 @@
 package share
 
 import (
-	"net/url"
 	"time"
 
 	"github.com/labstack/echo/v4"
@@ -25,6 +24,8 @@ func NewShareStatHandler(baseHandler *handler.BaseHandler, echo *echo.Echo, useC
 
 	group := echo.Group("/share/v1/stat")
 	group.POST("/page", h.RecordPage)
+	group.POST("/dwell", h.RecordDwell)
+	group.POST("/funnel", h.RecordFunnelEvent)
 	return h
 }
 
@@ -53,14 +54,11 @@ func (h *ShareStatHandler) RecordPage(c echo.Context) error {
 	userAgent := useragent.Parse(ua)
 	browserName := userAgent.Name
 	browserOS := userAgent.OS
-	referer := c.Request().Referer()
-	refererHost := ""
-	if referer != "" {
-		refererURL, err := url.Parse(referer)
-		if err == nil {
-			refererHost = refererURL.Host
-		}
+	referer := req.Referer
+	if referer == "" {
+		referer = c.Request().Referer()
 	}
+	source := domain.NewTrafficSource(referer, req.URL)
 	sessionIDCookie, err := c.Request().Cookie("x-pw-session-id")
 	if err != nil {
 		return h.NewResponseWithError(c, "get session id failed", err)
@@ -77,8 +75,13 @@ func (h *ShareStatHandler) RecordPage(c echo.Context) error {
 		UA:          ua,
 		BrowserName: browserName,
 		BrowserOS:   browserOS,
-		Referer:     referer,
-		RefererHost: refererHost,
+		Referer:     source.Referer,
+		RefererHost: source.RefererHost,
+		UTMSource:   source.UTMSource,
+		UTMMedium:   source.UTMMedium,
+		UTMCampaign: source.UTMCampaign,
+		UTMTerm:     source.UTMTerm,
+		UTMContent:  source.UTMContent,
 		CreatedAt:   time.Now(),
 	}
 	if err := h.useCase.RecordPage(c.Request().Context(), stat); err != nil {
@@ -86,3 +89,57 @@ func (h *ShareStatHandler) RecordPage(c echo.Context) error {
 	}
 	return h.NewResponseWithData(c, nil)
 }
+
+// RecordDwell record time spent on node detail page
+//
+//	@Summary		RecordDwell
+//	@Description	RecordDwell
+//	@Tags			share_stat
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		domain.StatNodeDwellReq	true	"request"
+//	@Success		200		{object}	domain.Response
+//	@Router			/share/v1/stat/dwell [post]
+func (h *ShareStatHandler) RecordDwell(c echo.Context) error {
+	req := &domain.StatNodeDwellReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "bind request body failed", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	kbID := c.Request().Header.Get("X-KB-ID")
+	if err := h.useCase.RecordNodeDwell(c.Request().Context(), kbID, req); err != nil {
+		return h.NewResponseWithError(c, "record dwell failed", err)
+	}
+	return h.NewResponseWithData(c, nil)
+}
+
+// RecordFunnelEvent record search or resolution step of funnel
+//
+//	@Summary		RecordFunnelEvent
+//	@Description	RecordFunnelEvent
+//	@Tags			share_stat
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		domain.StatFunnelEventReq	true	"request"
+//	@Success		200		{object}	domain.Response
+//	@Router			/share/v1/stat/funnel [post]
+func (h *ShareStatHandler) RecordFunnelEvent(c echo.Context) error {
+	req := &domain.StatFunnelEventReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "bind request body failed", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	sessionIDCookie, err := c.Request().Cookie("x-pw-session-id")
+	if err != nil {
+		return h.NewResponseWithError(c, "get session id failed", err)
+	}
+	kbID := c.Request().Header.Get("X-KB-ID")
+	if err := h.useCase.RecordFunnelEvent(c.Request().Context(), kbID, sessionIDCookie.Value, req.Step); err != nil {
+		return h.NewResponseWithError(c, "record funnel event failed", err)
+	}
+	return h.NewResponseWithData(c, nil)
+}
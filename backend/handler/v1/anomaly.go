@@ -0,0 +1,114 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/handler"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/middleware"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type AnomalyHandler struct {
+	*handler.BaseHandler
+	usecase *usecase.AnomalyUsecase
+	logger  *log.Logger
+	auth    middleware.AuthMiddleware
+}
+
+func NewAnomalyHandler(
+	baseHandler *handler.BaseHandler,
+	echo *echo.Echo,
+	usecase *usecase.AnomalyUsecase,
+	auth middleware.AuthMiddleware,
+	logger *log.Logger,
+) *AnomalyHandler {
+	h := &AnomalyHandler{
+		BaseHandler: baseHandler,
+		usecase:     usecase,
+		logger:      logger.WithModule("handler.v1.anomaly"),
+		auth:        auth,
+	}
+
+	group := echo.Group("/api/v1/anomaly", h.auth.Authorize)
+	group.GET("/list", h.GetAnomalyList)
+	group.GET("/report", h.GetAnomalyReport)
+	// lift a temporary block before it expires
+	group.DELETE("/block", h.UnblockIP)
+
+	return h
+}
+
+type AnomalyListItems = domain.PaginatedResult[[]*domain.AnomalyListItem]
+
+// GetAnomalyList get detected anomalies
+//
+//	@Summary		GetAnomalyList
+//	@Description	abnormal chat traffic detected in kb, newest first
+//	@Tags			anomaly
+//	@Accept			json
+//	@Produce		json
+//	@Param			params	query		domain.AnomalyListReq	true	"params"
+//	@Success		200		{object}	domain.Response{data=AnomalyListItems}
+//	@Router			/api/v1/anomaly/list [get]
+func (h *AnomalyHandler) GetAnomalyList(c echo.Context) error {
+	req := &domain.AnomalyListReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request params is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request params failed", err)
+	}
+	anomalies, err := h.usecase.GetAnomalyList(c.Request().Context(), req)
+	if err != nil {
+		return h.NewResponseWithError(c, "get anomaly list failed", err)
+	}
+	return h.NewResponseWithData(c, anomalies)
+}
+
+// GetAnomalyReport get anomalies report
+//
+//	@Summary		GetAnomalyReport
+//	@Description	anomalies of the last 7 days by type and ip, and the ips still blocked
+//	@Tags			anomaly
+//	@Accept			json
+//	@Produce		json
+//	@Param			kb_id	query		string	true	"kb id"
+//	@Success		200		{object}	domain.Response{data=domain.AnomalyReport}
+//	@Router			/api/v1/anomaly/report [get]
+func (h *AnomalyHandler) GetAnomalyReport(c echo.Context) error {
+	kbID := c.QueryParam("kb_id")
+	if kbID == "" {
+		return h.NewResponseWithError(c, "kb_id is required", nil)
+	}
+	report, err := h.usecase.GetAnomalyReport(c.Request().Context(), kbID)
+	if err != nil {
+		return h.NewResponseWithError(c, "get anomaly report failed", err)
+	}
+	return h.NewResponseWithData(c, report)
+}
+
+// UnblockIP unblock ip
+//
+//	@Summary		UnblockIP
+//	@Description	lift the block of an ip and reset its counters
+//	@Tags			anomaly
+//	@Accept			json
+//	@Produce		json
+//	@Param			params	query		domain.UnblockAnomalyIPReq	true	"params"
+//	@Success		200		{object}	domain.Response
+//	@Router			/api/v1/anomaly/block [delete]
+func (h *AnomalyHandler) UnblockIP(c echo.Context) error {
+	req := &domain.UnblockAnomalyIPReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request params is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request params failed", err)
+	}
+	if err := h.usecase.UnblockIP(c.Request().Context(), req); err != nil {
+		return h.NewResponseWithError(c, "unblock ip failed", err)
+	}
+	return h.NewResponseWithData(c, nil)
+}
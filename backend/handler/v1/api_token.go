@@ -0,0 +1,148 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/handler"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/middleware"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type APITokenHandler struct {
+	*handler.BaseHandler
+	usecase *usecase.APITokenUsecase
+	logger  *log.Logger
+	auth    middleware.AuthMiddleware
+}
+
+func NewAPITokenHandler(
+	baseHandler *handler.BaseHandler,
+	echo *echo.Echo,
+	usecase *usecase.APITokenUsecase,
+	auth middleware.AuthMiddleware,
+	logger *log.Logger,
+) *APITokenHandler {
+	h := &APITokenHandler{
+		BaseHandler: baseHandler,
+		usecase:     usecase,
+		logger:      logger.WithModule("handler.v1.api_token"),
+		auth:        auth,
+	}
+
+	group := echo.Group("/api/v1/api_token", h.auth.Authorize)
+	group.POST("", h.CreateAPIToken)
+	group.GET("/list", h.GetAPITokenList)
+	group.POST("/rotate", h.RotateAPIToken)
+	group.DELETE("", h.DeleteAPIToken)
+
+	return h
+}
+
+// CreateAPIToken create api token for ci pipelines and integrations
+//
+//	@Summary		CreateAPIToken
+//	@Description	create personal or service api token limited to its scopes, the token is only returned in this response
+//	@Tags			api_token
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.CreateAPITokenReq	true	"create api token request"
+//	@Success		200		{object}	domain.Response{data=domain.CreateAPITokenResp}
+//	@Router			/api/v1/api_token [post]
+func (h *APITokenHandler) CreateAPIToken(c echo.Context) error {
+	userID, ok := h.auth.MustGetUserID(c)
+	if !ok {
+		return h.NewResponseWithError(c, "user not found", nil)
+	}
+	req := &domain.CreateAPITokenReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	resp, err := h.usecase.CreateAPIToken(c.Request().Context(), req, userID)
+	if err != nil {
+		return h.NewResponseWithError(c, "create api token failed", err)
+	}
+	return h.NewResponseWithData(c, resp)
+}
+
+// GetAPITokenList get api tokens
+//
+//	@Summary		GetAPITokenList
+//	@Description	every api token for admins, members only get their own
+//	@Tags			api_token
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	domain.Response{data=[]domain.APIToken}
+//	@Router			/api/v1/api_token/list [get]
+func (h *APITokenHandler) GetAPITokenList(c echo.Context) error {
+	userID, ok := h.auth.MustGetUserID(c)
+	if !ok {
+		return h.NewResponseWithError(c, "user not found", nil)
+	}
+	tokens, err := h.usecase.GetAPITokenList(c.Request().Context(), userID)
+	if err != nil {
+		return h.NewResponseWithError(c, "get api token list failed", err)
+	}
+	return h.NewResponseWithData(c, tokens)
+}
+
+// RotateAPIToken replace api token
+//
+//	@Summary		RotateAPIToken
+//	@Description	replace the token keeping its scopes, the previous token stops working at once and the new one is only returned in this response
+//	@Tags			api_token
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.RotateAPITokenReq	true	"rotate api token request"
+//	@Success		200		{object}	domain.Response{data=domain.CreateAPITokenResp}
+//	@Router			/api/v1/api_token/rotate [post]
+func (h *APITokenHandler) RotateAPIToken(c echo.Context) error {
+	userID, ok := h.auth.MustGetUserID(c)
+	if !ok {
+		return h.NewResponseWithError(c, "user not found", nil)
+	}
+	req := &domain.RotateAPITokenReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	resp, err := h.usecase.RotateAPIToken(c.Request().Context(), req, userID)
+	if err != nil {
+		return h.NewResponseWithError(c, "rotate api token failed", err)
+	}
+	return h.NewResponseWithData(c, resp)
+}
+
+// DeleteAPIToken revoke api token
+//
+//	@Summary		DeleteAPIToken
+//	@Description	revoke api token and delete its sandbox kbs
+//	@Tags			api_token
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	query		string	true	"api token id"
+//	@Success		200	{object}	domain.Response
+//	@Router			/api/v1/api_token [delete]
+func (h *APITokenHandler) DeleteAPIToken(c echo.Context) error {
+	var req domain.DeleteAPITokenReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	userID, ok := h.auth.MustGetUserID(c)
+	if !ok {
+		return h.NewResponseWithError(c, "user not found", nil)
+	}
+	if err := h.usecase.DeleteAPIToken(c.Request().Context(), &req, userID); err != nil {
+		return h.NewResponseWithError(c, "delete api token failed", err)
+	}
+	return h.NewResponseWithData(c, nil)
+}
@@ -0,0 +1,113 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/handler"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/middleware"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type AuditHandler struct {
+	*handler.BaseHandler
+	usecase *usecase.AuditUsecase
+	logger  *log.Logger
+	auth    middleware.AuthMiddleware
+}
+
+func NewAuditHandler(
+	baseHandler *handler.BaseHandler,
+	echo *echo.Echo,
+	usecase *usecase.AuditUsecase,
+	auth middleware.AuthMiddleware,
+	audit *middleware.AuditMiddleware,
+	logger *log.Logger,
+) *AuditHandler {
+	h := &AuditHandler{
+		BaseHandler: baseHandler,
+		usecase:     usecase,
+		logger:      logger.WithModule("handler.v1.audit"),
+		auth:        auth,
+	}
+
+	// record admin writes in the audit log
+	echo.Use(audit.Record)
+
+	group := echo.Group("/api/v1/audit", h.auth.Authorize)
+	group.GET("/list", h.GetAuditLogList)
+	group.GET("/detail", h.GetAuditLog)
+	group.GET("/verify", h.VerifyAuditLogs)
+
+	return h
+}
+
+type AuditLogListItems = domain.PaginatedResult[[]*domain.AuditLogListItem]
+
+// GetAuditLogList get audit log
+//
+//	@Summary		GetAuditLogList
+//	@Description	writes to the admin apis, latest first, filtered by actor, resource, kb and time range
+//	@Tags			audit
+//	@Accept			json
+//	@Produce		json
+//	@Param			req	query		domain.AuditLogListReq	true	"audit log list request"
+//	@Success		200	{object}	domain.Response{data=AuditLogListItems}
+//	@Router			/api/v1/audit/list [get]
+func (h *AuditHandler) GetAuditLogList(c echo.Context) error {
+	var req domain.AuditLogListReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	logs, err := h.usecase.GetAuditLogList(c.Request().Context(), &req)
+	if err != nil {
+		return h.NewResponseWithError(c, "get audit log list failed", err)
+	}
+	return h.NewResponseWithData(c, logs)
+}
+
+// GetAuditLog get audit log entry
+//
+//	@Summary		GetAuditLog
+//	@Description	entry with the resource before and after the write and its hash
+//	@Tags			audit
+//	@Accept			json
+//	@Produce		json
+//	@Param			req	query		domain.GetAuditLogReq	true	"audit log request"
+//	@Success		200	{object}	domain.Response{data=domain.AuditLog}
+//	@Router			/api/v1/audit/detail [get]
+func (h *AuditHandler) GetAuditLog(c echo.Context) error {
+	var req domain.GetAuditLogReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	entry, err := h.usecase.GetAuditLog(c.Request().Context(), req.ID)
+	if err != nil {
+		return h.NewResponseWithError(c, "get audit log failed", err)
+	}
+	return h.NewResponseWithData(c, entry)
+}
+
+// VerifyAuditLogs verify audit log
+//
+//	@Summary		VerifyAuditLogs
+//	@Description	recompute the hash chain of the audit log from the anchor left by retention, the first edited entry or the entry after a deleted one is returned
+//	@Tags			audit
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	domain.Response{data=domain.AuditLogVerifyResp}
+//	@Router			/api/v1/audit/verify [get]
+func (h *AuditHandler) VerifyAuditLogs(c echo.Context) error {
+	resp, err := h.usecase.VerifyAuditLogs(c.Request().Context())
+	if err != nil {
+		return h.NewResponseWithError(c, "verify audit log failed", err)
+	}
+	return h.NewResponseWithData(c, resp)
+}
@@ -0,0 +1,63 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/handler"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/middleware"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type BotProfileHandler struct {
+	*handler.BaseHandler
+	usecase *usecase.BotProfileUsecase
+	logger  *log.Logger
+	auth    middleware.AuthMiddleware
+}
+
+func NewBotProfileHandler(
+	baseHandler *handler.BaseHandler,
+	echo *echo.Echo,
+	usecase *usecase.BotProfileUsecase,
+	auth middleware.AuthMiddleware,
+	logger *log.Logger,
+) *BotProfileHandler {
+	h := &BotProfileHandler{
+		BaseHandler: baseHandler,
+		usecase:     usecase,
+		logger:      logger.WithModule("handler.v1.bot_profile"),
+		auth:        auth,
+	}
+
+	group := echo.Group("/api/v1/app/bot_profile", h.auth.Authorize)
+	group.POST("/sync", h.SyncBotProfiles)
+
+	return h
+}
+
+// SyncBotProfiles push kb branding to bot channels
+//
+//	@Summary		SyncBotProfiles
+//	@Description	push bot name, avatar and description from kb branding settings to connected bot channels, channels not allowing bots to change their profile are reported unsupported
+//	@Tags			app
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.SyncBotProfilesReq	true	"sync bot profiles"
+//	@Success		200		{object}	domain.Response{data=domain.SyncBotProfilesResp}
+//	@Router			/api/v1/app/bot_profile/sync [post]
+func (h *BotProfileHandler) SyncBotProfiles(c echo.Context) error {
+	req := &domain.SyncBotProfilesReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	resp, err := h.usecase.SyncBotProfiles(c.Request().Context(), req)
+	if err != nil {
+		return h.NewResponseWithError(c, "sync bot profiles failed", err)
+	}
+	return h.NewResponseWithData(c, resp)
+}
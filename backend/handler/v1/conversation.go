@@ -1,6 +1,9 @@
 package v1
 
 import (
+	"encoding/json"
+	"net/http"
+
 	"github.com/labstack/echo/v4"
 
 	"github.com/chaitin/panda-wiki/domain"
@@ -15,18 +18,28 @@ type ConversationHandler struct {
 	logger  *log.Logger
 	auth    middleware.AuthMiddleware
 	usecase *usecase.ConversationUsecase
+
+	transcriptUsecase *usecase.TranscriptEmailUsecase
+	importUsecase     *usecase.ConversationImportUsecase
 }
 
-func NewConversationHandler(echo *echo.Echo, baseHandler *handler.BaseHandler, logger *log.Logger, auth middleware.AuthMiddleware, usecase *usecase.ConversationUsecase) *ConversationHandler {
+func NewConversationHandler(echo *echo.Echo, baseHandler *handler.BaseHandler, logger *log.Logger, auth middleware.AuthMiddleware, usecase *usecase.ConversationUsecase, transcriptUsecase *usecase.TranscriptEmailUsecase, importUsecase *usecase.ConversationImportUsecase) *ConversationHandler {
 	handler := &ConversationHandler{
 		BaseHandler: baseHandler,
 		logger:      logger.WithModule("handler_conversation"),
 		auth:        auth,
 		usecase:     usecase,
+
+		transcriptUsecase: transcriptUsecase,
+		importUsecase:     importUsecase,
 	}
 	group := echo.Group("/api/v1/conversation", handler.auth.Authorize)
 	group.GET("", handler.GetConversationList)
 	group.GET("/detail", handler.GetConversationDetail)
+	group.GET("/stream", handler.StreamConversations)
+	group.GET("/transcript_emails", handler.GetTranscriptEmailList)
+	group.POST("/import", handler.ImportTranscripts)
+	group.GET("/source_attribution", handler.GetSourceAttributionReport)
 
 	return handler
 }
@@ -62,24 +75,167 @@ func (h *ConversationHandler) GetConversationList(c echo.Context) error {
 // get conversation detail
 //
 //	@Summary		get conversation detail
-//	@Description	get conversation detail
+//	@Description	get conversation detail, supports If-None-Match and returns only the since message, messages after it and messages still streaming
 //	@Tags			conversation
 //	@Accept			json
 //	@Produce		json
 //	@Param			X-SafePoint-User-ID	header		string	true	"user id"
+//	@Param			If-None-Match		header		string	false	"etag of last response"
 //	@Param			id					query		string	true	"conversation id"
+//	@Param			since				query		string	false	"id of the last message already fetched, messages of the same id replace fetched ones"
 //	@Success		200					{object}	domain.Response{data=domain.ConversationDetailResp}
+//	@Success		304					"conversation not changed"
 //	@Router			/api/v1/conversation/detail [get]
 func (h *ConversationHandler) GetConversationDetail(c echo.Context) error {
 	conversationID := c.QueryParam("id")
 	if conversationID == "" {
 		return h.NewResponseWithError(c, "conversation id is required", nil)
 	}
+	since := c.QueryParam("since")
+
+	etag, err := h.usecase.GetConversationDetailETag(c.Request().Context(), conversationID, since)
+	if err != nil {
+		return h.NewResponseWithError(c, "failed to get conversation detail", err)
+	}
+	// revalidate on every request, the console polls the detail on focus
+	c.Response().Header().Set("Cache-Control", "private, no-cache")
+	c.Response().Header().Set("ETag", etag)
+	if c.Request().Header.Get("If-None-Match") == etag {
+		return c.NoContent(http.StatusNotModified)
+	}
 
-	conversation, err := h.usecase.GetConversationDetail(c.Request().Context(), conversationID)
+	conversation, err := h.usecase.GetConversationDetail(c.Request().Context(), conversationID, since)
 	if err != nil {
 		return h.NewResponseWithError(c, "failed to get conversation detail", err)
 	}
 
 	return h.NewResponseWithData(c, conversation)
 }
+
+// stream conversations
+//
+//	@Summary		stream conversations
+//	@Description	stream all conversations matching the filter as newline delimited json, oldest first, without pagination.
+//	@Description	a stream failed after it started ends with a line of {"error": "..."}
+//	@Tags			conversation
+//	@Accept			json
+//	@Produce		application/x-ndjson
+//	@Param			req	query		domain.ConversationStreamReq	true	"conversation stream request"
+//	@Success		200	{object}	domain.ConversationStreamItem
+//	@Router			/api/v1/conversation/stream [get]
+func (h *ConversationHandler) StreamConversations(c echo.Context) error {
+	var request domain.ConversationStreamReq
+	if err := c.Bind(&request); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	if err := c.Validate(&request); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+
+	c.Response().Header().Set("Content-Type", "application/x-ndjson")
+	c.Response().Header().Set("Cache-Control", "no-cache")
+	c.Response().WriteHeader(http.StatusOK)
+
+	encoder := json.NewEncoder(c.Response())
+	count := 0
+	err := h.usecase.StreamConversations(c.Request().Context(), &request, func(item *domain.ConversationStreamItem) error {
+		if err := encoder.Encode(item); err != nil {
+			return err
+		}
+		count++
+		if count%domain.ConversationStreamBatchSize == 0 {
+			c.Response().Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		h.logger.Error("stream conversations failed", log.String("kb_id", request.KBID), log.Int("count", count), log.Error(err))
+		// the client may be gone already
+		_ = encoder.Encode(&domain.ConversationStreamError{Error: err.Error()})
+	}
+	c.Response().Flush()
+	return nil
+}
+
+type TranscriptEmailListItems = domain.PaginatedResult[[]domain.ConversationTranscriptEmail]
+
+// get transcript email list
+//
+//	@Summary		get transcript email list
+//	@Description	get log of conversation transcripts sent to end users by email
+//	@Tags			conversation
+//	@Accept			json
+//	@Produce		json
+//	@Param			req	query		domain.TranscriptEmailListReq	true	"transcript email list request"
+//	@Success		200	{object}	domain.Response{data=TranscriptEmailListItems}
+//	@Router			/api/v1/conversation/transcript_emails [get]
+func (h *ConversationHandler) GetTranscriptEmailList(c echo.Context) error {
+	var req domain.TranscriptEmailListReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	records, err := h.transcriptUsecase.GetTranscriptEmailList(c.Request().Context(), &req)
+	if err != nil {
+		return h.NewResponseWithError(c, "failed to get transcript email list", err)
+	}
+	return h.NewResponseWithData(c, records)
+}
+
+// import helpdesk transcripts
+//
+//	@Summary		import helpdesk transcripts
+//	@Description	import support transcripts of a legacy helpdesk as historical conversations, csv has one message per row with conversation_id, role, content and created_at columns, zendesk is a json export of tickets with comments
+//	@Tags			conversation
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Param			file	formData	file	true	"transcript export"
+//	@Param			kb_id	formData	string	true	"kb id"
+//	@Param			format	formData	string	true	"csv or zendesk"
+//	@Success		200		{object}	domain.Response{data=domain.ImportTranscriptsResp}
+//	@Router			/api/v1/conversation/import [post]
+func (h *ConversationHandler) ImportTranscripts(c echo.Context) error {
+	var req domain.ImportTranscriptsReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	file, err := c.FormFile("file")
+	if err != nil {
+		return h.NewResponseWithError(c, "get file failed", err)
+	}
+	resp, err := h.importUsecase.ImportTranscripts(c.Request().Context(), &req, file)
+	if err != nil {
+		return h.NewResponseWithError(c, "failed to import transcripts", err)
+	}
+	return h.NewResponseWithData(c, resp)
+}
+
+// get source attribution report
+//
+//	@Summary		get source attribution report
+//	@Description	documents most cited by answers of the recent days, 30 by default, with likes and dislikes of those answers
+//	@Tags			conversation
+//	@Accept			json
+//	@Produce		json
+//	@Param			req	query		domain.SourceAttributionReq	true	"source attribution request"
+//	@Success		200	{object}	domain.Response{data=domain.SourceAttributionReport}
+//	@Router			/api/v1/conversation/source_attribution [get]
+func (h *ConversationHandler) GetSourceAttributionReport(c echo.Context) error {
+	var req domain.SourceAttributionReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	report, err := h.usecase.GetSourceAttributionReport(c.Request().Context(), &req)
+	if err != nil {
+		return h.NewResponseWithError(c, "failed to get source attribution report", err)
+	}
+	return h.NewResponseWithData(c, report)
+}
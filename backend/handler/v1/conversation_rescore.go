@@ -0,0 +1,121 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/handler"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/middleware"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type ConversationRescoreHandler struct {
+	*handler.BaseHandler
+	usecase *usecase.ConversationRescoreUsecase
+	logger  *log.Logger
+	auth    middleware.AuthMiddleware
+}
+
+func NewConversationRescoreHandler(
+	baseHandler *handler.BaseHandler,
+	echo *echo.Echo,
+	usecase *usecase.ConversationRescoreUsecase,
+	auth middleware.AuthMiddleware,
+	logger *log.Logger,
+) *ConversationRescoreHandler {
+	h := &ConversationRescoreHandler{
+		BaseHandler: baseHandler,
+		usecase:     usecase,
+		logger:      logger.WithModule("handler.v1.conversation_rescore"),
+		auth:        auth,
+	}
+
+	group := echo.Group("/api/v1/conversation/rescore", h.auth.Authorize)
+	group.POST("", h.CreateConversationRescoreJob)
+	group.GET("/jobs", h.GetConversationRescoreJobList)
+	group.GET("/job", h.GetConversationRescoreJob)
+
+	return h
+}
+
+// CreateConversationRescoreJob re-score recent answers with candidate settings in background
+//
+//	@Summary		CreateConversationRescoreJob
+//	@Description	create async job retrieving documents of recent questions again with candidate answer settings and evaluating the confidence gate offline, to estimate the change of low confidence rate and satisfaction before the settings are saved
+//	@Tags			conversation
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.ConversationRescoreReq	true	"conversation rescore request"
+//	@Success		200		{object}	domain.Response{data=domain.ConversationRescoreJob}
+//	@Router			/api/v1/conversation/rescore [post]
+func (h *ConversationRescoreHandler) CreateConversationRescoreJob(c echo.Context) error {
+	userID, ok := h.auth.MustGetUserID(c)
+	if !ok {
+		return h.NewResponseWithError(c, "user not found", nil)
+	}
+	req := &domain.ConversationRescoreReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	job, err := h.usecase.CreateJob(c.Request().Context(), req, userID)
+	if err != nil {
+		return h.NewResponseWithError(c, "create conversation rescore job failed", err)
+	}
+	return h.NewResponseWithData(c, job)
+}
+
+type ConversationRescoreJobListItems = domain.PaginatedResult[[]*domain.ConversationRescoreJob]
+
+// GetConversationRescoreJobList get conversation rescore jobs of kb
+//
+//	@Summary		GetConversationRescoreJobList
+//	@Description	GetConversationRescoreJobList
+//	@Tags			conversation
+//	@Accept			json
+//	@Produce		json
+//	@Param			req	query		domain.ConversationRescoreJobListReq	true	"conversation rescore job list request"
+//	@Success		200	{object}	domain.Response{data=ConversationRescoreJobListItems}
+//	@Router			/api/v1/conversation/rescore/jobs [get]
+func (h *ConversationRescoreHandler) GetConversationRescoreJobList(c echo.Context) error {
+	var req domain.ConversationRescoreJobListReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	jobs, err := h.usecase.GetJobList(c.Request().Context(), &req)
+	if err != nil {
+		return h.NewResponseWithError(c, "get conversation rescore job list failed", err)
+	}
+	return h.NewResponseWithData(c, jobs)
+}
+
+// GetConversationRescoreJob get status and result of conversation rescore job
+//
+//	@Summary		GetConversationRescoreJob
+//	@Description	status of conversation rescore job, with the scores before and after the candidate settings when succeeded
+//	@Tags			conversation
+//	@Accept			json
+//	@Produce		json
+//	@Param			req	query		domain.ConversationRescoreJobReq	true	"conversation rescore job request"
+//	@Success		200	{object}	domain.Response{data=domain.ConversationRescoreJob}
+//	@Router			/api/v1/conversation/rescore/job [get]
+func (h *ConversationRescoreHandler) GetConversationRescoreJob(c echo.Context) error {
+	var req domain.ConversationRescoreJobReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	job, err := h.usecase.GetJob(c.Request().Context(), &req)
+	if err != nil {
+		return h.NewResponseWithError(c, "get conversation rescore job failed", err)
+	}
+	return h.NewResponseWithData(c, job)
+}
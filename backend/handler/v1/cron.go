@@ -0,0 +1,83 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/handler"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/middleware"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type CronHandler struct {
+	*handler.BaseHandler
+	usecase *usecase.CronUsecase
+	logger  *log.Logger
+	auth    middleware.AuthMiddleware
+}
+
+func NewCronHandler(
+	baseHandler *handler.BaseHandler,
+	echo *echo.Echo,
+	usecase *usecase.CronUsecase,
+	auth middleware.AuthMiddleware,
+	logger *log.Logger,
+) *CronHandler {
+	h := &CronHandler{
+		BaseHandler: baseHandler,
+		usecase:     usecase,
+		logger:      logger.WithModule("handler.v1.cron"),
+		auth:        auth,
+	}
+
+	group := echo.Group("/api/v1/cron", h.auth.Authorize)
+	group.GET("/jobs", h.GetCronJobStatus)
+	group.GET("/runs", h.GetCronRunList)
+
+	return h
+}
+
+// GetCronJobStatus get last run and consecutive failures of cron jobs
+//
+//	@Summary		GetCronJobStatus
+//	@Description	last run and consecutive failures of each cron job
+//	@Tags			cron
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	domain.Response{data=[]domain.CronJobStatus}
+//	@Router			/api/v1/cron/jobs [get]
+func (h *CronHandler) GetCronJobStatus(c echo.Context) error {
+	statuses, err := h.usecase.GetCronJobStatus(c.Request().Context())
+	if err != nil {
+		return h.NewResponseWithError(c, "get cron job status failed", err)
+	}
+	return h.NewResponseWithData(c, statuses)
+}
+
+type CronRunListItems = domain.PaginatedResult[[]*domain.CronRun]
+
+// GetCronRunList get run history of cron jobs
+//
+//	@Summary		GetCronRunList
+//	@Description	run history of cron jobs, newest first
+//	@Tags			cron
+//	@Accept			json
+//	@Produce		json
+//	@Param			req	query		domain.CronRunListReq	true	"cron run list request"
+//	@Success		200	{object}	domain.Response{data=CronRunListItems}
+//	@Router			/api/v1/cron/runs [get]
+func (h *CronHandler) GetCronRunList(c echo.Context) error {
+	var req domain.CronRunListReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	runs, err := h.usecase.GetCronRunList(c.Request().Context(), &req)
+	if err != nil {
+		return h.NewResponseWithError(c, "get cron run list failed", err)
+	}
+	return h.NewResponseWithData(c, runs)
+}
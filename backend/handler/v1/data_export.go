@@ -0,0 +1,121 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/handler"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/middleware"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type DataExportHandler struct {
+	*handler.BaseHandler
+	usecase *usecase.DataExportUsecase
+	logger  *log.Logger
+	auth    middleware.AuthMiddleware
+}
+
+func NewDataExportHandler(
+	baseHandler *handler.BaseHandler,
+	echo *echo.Echo,
+	usecase *usecase.DataExportUsecase,
+	auth middleware.AuthMiddleware,
+	logger *log.Logger,
+) *DataExportHandler {
+	h := &DataExportHandler{
+		BaseHandler: baseHandler,
+		usecase:     usecase,
+		logger:      logger.WithModule("handler.v1.data_export"),
+		auth:        auth,
+	}
+
+	group := echo.Group("/api/v1/data/export", h.auth.Authorize)
+	group.POST("", h.CreateDataExportJob)
+	group.GET("/jobs", h.GetDataExportJobList)
+	group.GET("/job", h.GetDataExportJob)
+
+	return h
+}
+
+// CreateDataExportJob export data of kb or end user in background
+//
+//	@Summary		CreateDataExportJob
+//	@Description	create async job exporting everything stored for the kb, or the records referencing an end user, as json lines files of a zip archive for compliance requests
+//	@Tags			data_export
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.DataExportReq	true	"data export request"
+//	@Success		200		{object}	domain.Response{data=domain.DataExportJob}
+//	@Router			/api/v1/data/export [post]
+func (h *DataExportHandler) CreateDataExportJob(c echo.Context) error {
+	userID, ok := h.auth.MustGetUserID(c)
+	if !ok {
+		return h.NewResponseWithError(c, "user not found", nil)
+	}
+	req := &domain.DataExportReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	job, err := h.usecase.CreateJob(c.Request().Context(), req, userID)
+	if err != nil {
+		return h.NewResponseWithError(c, "create data export job failed", err)
+	}
+	return h.NewResponseWithData(c, job)
+}
+
+type DataExportJobListItems = domain.PaginatedResult[[]*domain.DataExportJob]
+
+// GetDataExportJobList get data export jobs of kb
+//
+//	@Summary		GetDataExportJobList
+//	@Description	GetDataExportJobList
+//	@Tags			data_export
+//	@Accept			json
+//	@Produce		json
+//	@Param			req	query		domain.DataExportJobListReq	true	"data export job list request"
+//	@Success		200	{object}	domain.Response{data=DataExportJobListItems}
+//	@Router			/api/v1/data/export/jobs [get]
+func (h *DataExportHandler) GetDataExportJobList(c echo.Context) error {
+	var req domain.DataExportJobListReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	jobs, err := h.usecase.GetJobList(c.Request().Context(), &req)
+	if err != nil {
+		return h.NewResponseWithError(c, "get data export job list failed", err)
+	}
+	return h.NewResponseWithData(c, jobs)
+}
+
+// GetDataExportJob get status of data export job
+//
+//	@Summary		GetDataExportJob
+//	@Description	status of data export job, with download url of the archive when succeeded
+//	@Tags			data_export
+//	@Accept			json
+//	@Produce		json
+//	@Param			req	query		domain.DataExportJobReq	true	"data export job request"
+//	@Success		200	{object}	domain.Response{data=domain.DataExportJob}
+//	@Router			/api/v1/data/export/job [get]
+func (h *DataExportHandler) GetDataExportJob(c echo.Context) error {
+	var req domain.DataExportJobReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	job, err := h.usecase.GetJob(c.Request().Context(), &req)
+	if err != nil {
+		return h.NewResponseWithError(c, "get data export job failed", err)
+	}
+	return h.NewResponseWithData(c, job)
+}
@@ -0,0 +1,88 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/handler"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/middleware"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type DigestHandler struct {
+	*handler.BaseHandler
+	usecase *usecase.DigestUsecase
+	logger  *log.Logger
+	auth    middleware.AuthMiddleware
+}
+
+func NewDigestHandler(
+	baseHandler *handler.BaseHandler,
+	echo *echo.Echo,
+	usecase *usecase.DigestUsecase,
+	auth middleware.AuthMiddleware,
+	logger *log.Logger,
+) *DigestHandler {
+	h := &DigestHandler{
+		BaseHandler: baseHandler,
+		usecase:     usecase,
+		logger:      logger.WithModule("handler.v1.digest"),
+		auth:        auth,
+	}
+
+	group := echo.Group("/api/v1/digest", h.auth.Authorize)
+	group.GET("", h.GetDailyDigest)
+	group.POST("/send", h.SendDailyDigest)
+
+	return h
+}
+
+// GetDailyDigest preview daily digest of kb
+//
+//	@Summary		GetDailyDigest
+//	@Description	volume, escalations, new questions and example transcripts of conversations of a day
+//	@Tags			digest
+//	@Accept			json
+//	@Produce		json
+//	@Param			req	query		domain.DailyDigestReq	true	"digest request"
+//	@Success		200	{object}	domain.Response{data=domain.DailyDigestResp}
+//	@Router			/api/v1/digest [get]
+func (h *DigestHandler) GetDailyDigest(c echo.Context) error {
+	var req domain.DailyDigestReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "request params is invalid", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "validate request params failed", err)
+	}
+	resp, err := h.usecase.PreviewDailyDigest(c.Request().Context(), &req)
+	if err != nil {
+		return h.NewResponseWithError(c, "get daily digest failed", err)
+	}
+	return h.NewResponseWithData(c, resp)
+}
+
+// SendDailyDigest save and push daily digest of kb now
+//
+//	@Summary		SendDailyDigest
+//	@Description	save digest as node and push it to group webhook, as configured in kb digest settings
+//	@Tags			digest
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.DailyDigestReq	true	"digest request"
+//	@Success		200		{object}	domain.Response
+//	@Router			/api/v1/digest/send [post]
+func (h *DigestHandler) SendDailyDigest(c echo.Context) error {
+	var req domain.DailyDigestReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	if err := h.usecase.SendDailyDigestNow(c.Request().Context(), &req); err != nil {
+		return h.NewResponseWithError(c, "send daily digest failed", err)
+	}
+	return h.NewResponseWithData(c, nil)
+}
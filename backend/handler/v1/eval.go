@@ -0,0 +1,379 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/handler"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/middleware"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type EvalHandler struct {
+	*handler.BaseHandler
+	usecase *usecase.EvalUsecase
+	logger  *log.Logger
+	auth    middleware.AuthMiddleware
+}
+
+func NewEvalHandler(
+	baseHandler *handler.BaseHandler,
+	echo *echo.Echo,
+	usecase *usecase.EvalUsecase,
+	auth middleware.AuthMiddleware,
+	logger *log.Logger,
+) *EvalHandler {
+	h := &EvalHandler{
+		BaseHandler: baseHandler,
+		usecase:     usecase,
+		logger:      logger.WithModule("handler.v1.eval"),
+		auth:        auth,
+	}
+
+	group := echo.Group("/api/v1/eval", h.auth.Authorize)
+	group.GET("/set/list", h.GetEvalSetList)
+	group.POST("/set", h.CreateEvalSet)
+	group.PUT("/set", h.UpdateEvalSet)
+	group.DELETE("/set", h.DeleteEvalSet)
+	group.GET("/question/list", h.GetEvalQuestionList)
+	group.POST("/question", h.CreateEvalQuestions)
+	group.PUT("/question", h.UpdateEvalQuestion)
+	group.DELETE("/question", h.DeleteEvalQuestion)
+	group.POST("/run", h.CreateEvalRun)
+	group.GET("/run/list", h.GetEvalRunList)
+	group.GET("/run", h.GetEvalRun)
+	group.GET("/run/results", h.GetEvalResultList)
+	group.GET("/trend", h.GetEvalTrend)
+
+	return h
+}
+
+// GetEvalSetList get eval set list
+//
+//	@Summary		GetEvalSetList
+//	@Description	eval sets of the kb with their question count and the summary of their latest run
+//	@Tags			eval
+//	@Accept			json
+//	@Produce		json
+//	@Param			req	query		domain.EvalSetListReq	true	"eval set list request"
+//	@Success		200	{object}	domain.Response{data=[]domain.EvalSetListItem}
+//	@Router			/api/v1/eval/set/list [get]
+func (h *EvalHandler) GetEvalSetList(c echo.Context) error {
+	req := &domain.EvalSetListReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request params is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request params failed", err)
+	}
+	resp, err := h.usecase.GetEvalSetList(c.Request().Context(), req)
+	if err != nil {
+		return h.NewResponseWithError(c, "get eval set list failed", err)
+	}
+	return h.NewResponseWithData(c, resp)
+}
+
+// CreateEvalSet create eval set
+//
+//	@Summary		CreateEvalSet
+//	@Description	create eval set of golden questions
+//	@Tags			eval
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.CreateEvalSetReq	true	"eval set"
+//	@Success		200		{object}	domain.Response{data=domain.EvalSet}
+//	@Router			/api/v1/eval/set [post]
+func (h *EvalHandler) CreateEvalSet(c echo.Context) error {
+	req := &domain.CreateEvalSetReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	resp, err := h.usecase.CreateEvalSet(c.Request().Context(), req)
+	if err != nil {
+		return h.NewResponseWithError(c, "create eval set failed", err)
+	}
+	return h.NewResponseWithData(c, resp)
+}
+
+// UpdateEvalSet update eval set
+//
+//	@Summary		UpdateEvalSet
+//	@Description	update name and description of eval set
+//	@Tags			eval
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.UpdateEvalSetReq	true	"eval set"
+//	@Success		200		{object}	domain.Response
+//	@Router			/api/v1/eval/set [put]
+func (h *EvalHandler) UpdateEvalSet(c echo.Context) error {
+	req := &domain.UpdateEvalSetReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	if err := h.usecase.UpdateEvalSet(c.Request().Context(), req); err != nil {
+		return h.NewResponseWithError(c, "update eval set failed", err)
+	}
+	return h.NewResponseWithData(c, nil)
+}
+
+// DeleteEvalSet delete eval set
+//
+//	@Summary		DeleteEvalSet
+//	@Description	delete eval set with its questions, runs and results
+//	@Tags			eval
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.DeleteEvalSetReq	true	"eval set"
+//	@Success		200		{object}	domain.Response
+//	@Router			/api/v1/eval/set [delete]
+func (h *EvalHandler) DeleteEvalSet(c echo.Context) error {
+	req := &domain.DeleteEvalSetReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	if err := h.usecase.DeleteEvalSet(c.Request().Context(), req); err != nil {
+		return h.NewResponseWithError(c, "delete eval set failed", err)
+	}
+	return h.NewResponseWithData(c, nil)
+}
+
+// GetEvalQuestionList get eval question list
+//
+//	@Summary		GetEvalQuestionList
+//	@Description	questions of eval set in the order they were added
+//	@Tags			eval
+//	@Accept			json
+//	@Produce		json
+//	@Param			req	query		domain.EvalQuestionListReq	true	"eval question list request"
+//	@Success		200	{object}	domain.Response{data=[]domain.EvalQuestion}
+//	@Router			/api/v1/eval/question/list [get]
+func (h *EvalHandler) GetEvalQuestionList(c echo.Context) error {
+	req := &domain.EvalQuestionListReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request params is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request params failed", err)
+	}
+	resp, err := h.usecase.GetEvalQuestions(c.Request().Context(), req)
+	if err != nil {
+		return h.NewResponseWithError(c, "get eval question list failed", err)
+	}
+	return h.NewResponseWithData(c, resp)
+}
+
+// CreateEvalQuestions create eval questions
+//
+//	@Summary		CreateEvalQuestions
+//	@Description	add questions with expected answers to eval set, a set has at most 200 questions
+//	@Tags			eval
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.CreateEvalQuestionsReq	true	"eval questions"
+//	@Success		200		{object}	domain.Response{data=[]domain.EvalQuestion}
+//	@Router			/api/v1/eval/question [post]
+func (h *EvalHandler) CreateEvalQuestions(c echo.Context) error {
+	req := &domain.CreateEvalQuestionsReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	resp, err := h.usecase.CreateEvalQuestions(c.Request().Context(), req)
+	if err != nil {
+		return h.NewResponseWithError(c, "create eval questions failed", err)
+	}
+	return h.NewResponseWithData(c, resp)
+}
+
+// UpdateEvalQuestion update eval question
+//
+//	@Summary		UpdateEvalQuestion
+//	@Description	update question and expected answer, results of past runs keep the copy they were judged with
+//	@Tags			eval
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.UpdateEvalQuestionReq	true	"eval question"
+//	@Success		200		{object}	domain.Response
+//	@Router			/api/v1/eval/question [put]
+func (h *EvalHandler) UpdateEvalQuestion(c echo.Context) error {
+	req := &domain.UpdateEvalQuestionReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	if err := h.usecase.UpdateEvalQuestion(c.Request().Context(), req); err != nil {
+		return h.NewResponseWithError(c, "update eval question failed", err)
+	}
+	return h.NewResponseWithData(c, nil)
+}
+
+// DeleteEvalQuestion delete eval question
+//
+//	@Summary		DeleteEvalQuestion
+//	@Description	delete question of eval set
+//	@Tags			eval
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.DeleteEvalQuestionReq	true	"eval question"
+//	@Success		200		{object}	domain.Response
+//	@Router			/api/v1/eval/question [delete]
+func (h *EvalHandler) DeleteEvalQuestion(c echo.Context) error {
+	req := &domain.DeleteEvalQuestionReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	if err := h.usecase.DeleteEvalQuestion(c.Request().Context(), req); err != nil {
+		return h.NewResponseWithError(c, "delete eval question failed", err)
+	}
+	return h.NewResponseWithData(c, nil)
+}
+
+// CreateEvalRun create eval run
+//
+//	@Summary		CreateEvalRun
+//	@Description	create async run answering every question of the set with the current documents, chat model of the kb and prompt of the app, and scoring faithfulness and relevance of the answers by the model as judge
+//	@Tags			eval
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.CreateEvalRunReq	true	"eval run request"
+//	@Success		200		{object}	domain.Response{data=domain.EvalRun}
+//	@Router			/api/v1/eval/run [post]
+func (h *EvalHandler) CreateEvalRun(c echo.Context) error {
+	userID, ok := h.auth.MustGetUserID(c)
+	if !ok {
+		return h.NewResponseWithError(c, "user not found", nil)
+	}
+	req := &domain.CreateEvalRunReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	resp, err := h.usecase.CreateEvalRun(c.Request().Context(), req, userID)
+	if err != nil {
+		return h.NewResponseWithError(c, "create eval run failed", err)
+	}
+	return h.NewResponseWithData(c, resp)
+}
+
+type EvalRunListItems = domain.PaginatedResult[[]*domain.EvalRun]
+
+// GetEvalRunList get eval run list
+//
+//	@Summary		GetEvalRunList
+//	@Description	eval runs of the kb or of one set, newest first
+//	@Tags			eval
+//	@Accept			json
+//	@Produce		json
+//	@Param			req	query		domain.EvalRunListReq	true	"eval run list request"
+//	@Success		200	{object}	domain.Response{data=EvalRunListItems}
+//	@Router			/api/v1/eval/run/list [get]
+func (h *EvalHandler) GetEvalRunList(c echo.Context) error {
+	req := &domain.EvalRunListReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request params is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request params failed", err)
+	}
+	resp, err := h.usecase.GetEvalRunList(c.Request().Context(), req)
+	if err != nil {
+		return h.NewResponseWithError(c, "get eval run list failed", err)
+	}
+	return h.NewResponseWithData(c, resp)
+}
+
+// GetEvalRun get eval run
+//
+//	@Summary		GetEvalRun
+//	@Description	status of eval run with the model and prompt version it used, and its summary when succeeded
+//	@Tags			eval
+//	@Accept			json
+//	@Produce		json
+//	@Param			req	query		domain.EvalRunReq	true	"eval run request"
+//	@Success		200	{object}	domain.Response{data=domain.EvalRun}
+//	@Router			/api/v1/eval/run [get]
+func (h *EvalHandler) GetEvalRun(c echo.Context) error {
+	req := &domain.EvalRunReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request params is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request params failed", err)
+	}
+	resp, err := h.usecase.GetEvalRun(c.Request().Context(), req)
+	if err != nil {
+		return h.NewResponseWithError(c, "get eval run failed", err)
+	}
+	return h.NewResponseWithData(c, resp)
+}
+
+type EvalResultListItems = domain.PaginatedResult[[]*domain.EvalResult]
+
+// GetEvalResultList get eval result list
+//
+//	@Summary		GetEvalResultList
+//	@Description	answers and scores of eval run, failed and lowest scored first
+//	@Tags			eval
+//	@Accept			json
+//	@Produce		json
+//	@Param			req	query		domain.EvalResultListReq	true	"eval result list request"
+//	@Success		200	{object}	domain.Response{data=EvalResultListItems}
+//	@Router			/api/v1/eval/run/results [get]
+func (h *EvalHandler) GetEvalResultList(c echo.Context) error {
+	req := &domain.EvalResultListReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request params is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request params failed", err)
+	}
+	resp, err := h.usecase.GetEvalResultList(c.Request().Context(), req)
+	if err != nil {
+		return h.NewResponseWithError(c, "get eval result list failed", err)
+	}
+	return h.NewResponseWithData(c, resp)
+}
+
+// GetEvalTrend get eval trend
+//
+//	@Summary		GetEvalTrend
+//	@Description	scores of the recent succeeded runs of eval set oldest first, with the model and prompt version of each run to compare changes
+//	@Tags			eval
+//	@Accept			json
+//	@Produce		json
+//	@Param			req	query		domain.EvalTrendReq	true	"eval trend request"
+//	@Success		200	{object}	domain.Response{data=domain.EvalTrendResp}
+//	@Router			/api/v1/eval/trend [get]
+func (h *EvalHandler) GetEvalTrend(c echo.Context) error {
+	req := &domain.EvalTrendReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request params is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request params failed", err)
+	}
+	resp, err := h.usecase.GetEvalTrend(c.Request().Context(), req)
+	if err != nil {
+		return h.NewResponseWithError(c, "get eval trend failed", err)
+	}
+	return h.NewResponseWithData(c, resp)
+}
@@ -0,0 +1,59 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/handler"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/middleware"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type ExternalLinkHandler struct {
+	*handler.BaseHandler
+	usecase *usecase.ExternalLinkUsecase
+	logger  *log.Logger
+	auth    middleware.AuthMiddleware
+}
+
+func NewExternalLinkHandler(
+	baseHandler *handler.BaseHandler,
+	echo *echo.Echo,
+	usecase *usecase.ExternalLinkUsecase,
+	auth middleware.AuthMiddleware,
+	logger *log.Logger,
+) *ExternalLinkHandler {
+	h := &ExternalLinkHandler{
+		BaseHandler: baseHandler,
+		usecase:     usecase,
+		logger:      logger.WithModule("handler.v1.external_link"),
+		auth:        auth,
+	}
+
+	group := echo.Group("/api/v1/node/external_link", h.auth.Authorize)
+	group.GET("/broken", h.GetBrokenExternalLinks)
+
+	return h
+}
+
+// GetBrokenExternalLinks get broken external links
+//
+//	@Summary		GetBrokenExternalLinks
+//	@Description	outbound links of nodes found broken by the daily link check, with last checked time
+//	@Tags			node
+//	@Accept			json
+//	@Produce		json
+//	@Param			kb_id	query		string	true	"kb id"
+//	@Success		200		{object}	domain.Response{data=[]domain.BrokenExternalLinkResp}
+//	@Router			/api/v1/node/external_link/broken [get]
+func (h *ExternalLinkHandler) GetBrokenExternalLinks(c echo.Context) error {
+	kbID := c.QueryParam("kb_id")
+	if kbID == "" {
+		return h.NewResponseWithError(c, "kb_id is required", nil)
+	}
+	links, err := h.usecase.GetBrokenExternalLinks(c.Request().Context(), kbID)
+	if err != nil {
+		return h.NewResponseWithError(c, "get broken external links failed", err)
+	}
+	return h.NewResponseWithData(c, links)
+}
@@ -0,0 +1,85 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/handler"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/middleware"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type GapReportHandler struct {
+	*handler.BaseHandler
+	usecase *usecase.GapReportUsecase
+	logger  *log.Logger
+	auth    middleware.AuthMiddleware
+}
+
+func NewGapReportHandler(
+	baseHandler *handler.BaseHandler,
+	echo *echo.Echo,
+	usecase *usecase.GapReportUsecase,
+	auth middleware.AuthMiddleware,
+	logger *log.Logger,
+) *GapReportHandler {
+	h := &GapReportHandler{
+		BaseHandler: baseHandler,
+		usecase:     usecase,
+		logger:      logger.WithModule("handler.v1.gap_report"),
+		auth:        auth,
+	}
+
+	group := echo.Group("/api/v1/gap_report", h.auth.Authorize)
+	group.GET("", h.GetGapReport)
+	group.POST("/send", h.SendGapReport)
+
+	return h
+}
+
+// GetGapReport preview gap report of kb
+//
+//	@Summary		GetGapReport
+//	@Description	unanswered questions of last week, stale documents and unresolved tickets imported from a helpdesk
+//	@Tags			gap_report
+//	@Accept			json
+//	@Produce		json
+//	@Param			kb_id	query		string	true	"kb_id"
+//	@Success		200		{object}	domain.Response{data=domain.GapReport}
+//	@Router			/api/v1/gap_report [get]
+func (h *GapReportHandler) GetGapReport(c echo.Context) error {
+	kbID := c.QueryParam("kb_id")
+	if kbID == "" {
+		return h.NewResponseWithError(c, "kb_id is required", nil)
+	}
+	report, err := h.usecase.GetGapReport(c.Request().Context(), kbID)
+	if err != nil {
+		return h.NewResponseWithError(c, "get gap report failed", err)
+	}
+	return h.NewResponseWithData(c, report)
+}
+
+// SendGapReport send gap report to configured dingtalk/feishu groups now
+//
+//	@Summary		SendGapReport
+//	@Description	SendGapReport
+//	@Tags			gap_report
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.SendGapReportReq	true	"body"
+//	@Success		200		{object}	domain.Response
+//	@Router			/api/v1/gap_report/send [post]
+func (h *GapReportHandler) SendGapReport(c echo.Context) error {
+	var req domain.SendGapReportReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	if err := h.usecase.SendGapReport(c.Request().Context(), req.KBID); err != nil {
+		return h.NewResponseWithError(c, "send gap report failed", err)
+	}
+	return h.NewResponseWithData(c, nil)
+}
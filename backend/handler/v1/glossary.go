@@ -0,0 +1,136 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/handler"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/middleware"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type GlossaryHandler struct {
+	*handler.BaseHandler
+	usecase *usecase.GlossaryUsecase
+	logger  *log.Logger
+	auth    middleware.AuthMiddleware
+}
+
+func NewGlossaryHandler(
+	baseHandler *handler.BaseHandler,
+	echo *echo.Echo,
+	usecase *usecase.GlossaryUsecase,
+	auth middleware.AuthMiddleware,
+	logger *log.Logger,
+) *GlossaryHandler {
+	h := &GlossaryHandler{
+		BaseHandler: baseHandler,
+		usecase:     usecase,
+		logger:      logger.WithModule("handler.v1.glossary"),
+		auth:        auth,
+	}
+
+	group := echo.Group("/api/v1/glossary", h.auth.Authorize)
+	group.GET("/list", h.GetGlossaryTermList)
+	group.POST("", h.CreateGlossaryTerm)
+	group.PUT("", h.UpdateGlossaryTerm)
+	group.DELETE("", h.DeleteGlossaryTerm)
+
+	return h
+}
+
+// GetGlossaryTermList get glossary terms
+//
+//	@Summary		GetGlossaryTermList
+//	@Description	glossary terms of kb
+//	@Tags			glossary
+//	@Accept			json
+//	@Produce		json
+//	@Param			kb_id	query		string	true	"kb id"
+//	@Success		200		{object}	domain.Response{data=[]domain.GlossaryTerm}
+//	@Router			/api/v1/glossary/list [get]
+func (h *GlossaryHandler) GetGlossaryTermList(c echo.Context) error {
+	kbID := c.QueryParam("kb_id")
+	if kbID == "" {
+		return h.NewResponseWithError(c, "kb_id is required", nil)
+	}
+	terms, err := h.usecase.GetGlossaryTermList(c.Request().Context(), kbID)
+	if err != nil {
+		return h.NewResponseWithError(c, "get glossary term list failed", err)
+	}
+	return h.NewResponseWithData(c, terms)
+}
+
+// CreateGlossaryTerm create glossary term
+//
+//	@Summary		CreateGlossaryTerm
+//	@Description	create glossary term, its first mention in published documents is linked and its definition is given to the model when mentioned
+//	@Tags			glossary
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.CreateGlossaryTermReq	true	"term"
+//	@Success		200		{object}	domain.Response{data=map[string]string}
+//	@Router			/api/v1/glossary [post]
+func (h *GlossaryHandler) CreateGlossaryTerm(c echo.Context) error {
+	req := &domain.CreateGlossaryTermReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	id, err := h.usecase.CreateGlossaryTerm(c.Request().Context(), req)
+	if err != nil {
+		return h.NewResponseWithError(c, "create glossary term failed", err)
+	}
+	return h.NewResponseWithData(c, map[string]string{"id": id})
+}
+
+// UpdateGlossaryTerm update glossary term
+//
+//	@Summary		UpdateGlossaryTerm
+//	@Description	update glossary term
+//	@Tags			glossary
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.UpdateGlossaryTermReq	true	"term"
+//	@Success		200		{object}	domain.Response
+//	@Router			/api/v1/glossary [put]
+func (h *GlossaryHandler) UpdateGlossaryTerm(c echo.Context) error {
+	req := &domain.UpdateGlossaryTermReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	if err := h.usecase.UpdateGlossaryTerm(c.Request().Context(), req); err != nil {
+		return h.NewResponseWithError(c, "update glossary term failed", err)
+	}
+	return h.NewResponseWithData(c, nil)
+}
+
+// DeleteGlossaryTerm delete glossary term
+//
+//	@Summary		DeleteGlossaryTerm
+//	@Description	delete glossary term
+//	@Tags			glossary
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.DeleteGlossaryTermReq	true	"term"
+//	@Success		200		{object}	domain.Response
+//	@Router			/api/v1/glossary [delete]
+func (h *GlossaryHandler) DeleteGlossaryTerm(c echo.Context) error {
+	req := &domain.DeleteGlossaryTermReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	if err := h.usecase.DeleteGlossaryTerm(c.Request().Context(), req); err != nil {
+		return h.NewResponseWithError(c, "delete glossary term failed", err)
+	}
+	return h.NewResponseWithData(c, nil)
+}
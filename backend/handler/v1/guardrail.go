@@ -0,0 +1,65 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/handler"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/middleware"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type GuardrailHandler struct {
+	*handler.BaseHandler
+	usecase *usecase.GuardrailUsecase
+	logger  *log.Logger
+	auth    middleware.AuthMiddleware
+}
+
+func NewGuardrailHandler(
+	baseHandler *handler.BaseHandler,
+	echo *echo.Echo,
+	usecase *usecase.GuardrailUsecase,
+	auth middleware.AuthMiddleware,
+	logger *log.Logger,
+) *GuardrailHandler {
+	h := &GuardrailHandler{
+		BaseHandler: baseHandler,
+		usecase:     usecase,
+		logger:      logger.WithModule("handler.v1.guardrail"),
+		auth:        auth,
+	}
+
+	group := echo.Group("/api/v1/guardrail", h.auth.Authorize)
+	group.GET("/events", h.GetGuardrailEventList)
+
+	return h
+}
+
+type GuardrailEventListItems = domain.PaginatedResult[[]*domain.GuardrailEvent]
+
+// GetGuardrailEventList get guardrail events
+//
+//	@Summary		GetGuardrailEventList
+//	@Description	questions and answers matched by the guardrail checks of the apps of kb, newest first
+//	@Tags			guardrail
+//	@Accept			json
+//	@Produce		json
+//	@Param			params	query		domain.GuardrailEventListReq	true	"params"
+//	@Success		200		{object}	domain.Response{data=GuardrailEventListItems}
+//	@Router			/api/v1/guardrail/events [get]
+func (h *GuardrailHandler) GetGuardrailEventList(c echo.Context) error {
+	req := &domain.GuardrailEventListReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request params is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request params failed", err)
+	}
+	events, err := h.usecase.GetGuardrailEventList(c.Request().Context(), req)
+	if err != nil {
+		return h.NewResponseWithError(c, "get guardrail event list failed", err)
+	}
+	return h.NewResponseWithData(c, events)
+}
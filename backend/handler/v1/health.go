@@ -0,0 +1,78 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/handler"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type HealthHandler struct {
+	*handler.BaseHandler
+	warmupUsecase *usecase.WarmupUsecase
+	logger        *log.Logger
+}
+
+func NewHealthHandler(
+	baseHandler *handler.BaseHandler,
+	echo *echo.Echo,
+	warmupUsecase *usecase.WarmupUsecase,
+	logger *log.Logger,
+) *HealthHandler {
+	h := &HealthHandler{
+		BaseHandler:   baseHandler,
+		warmupUsecase: warmupUsecase,
+		logger:        logger.WithModule("handler.v1.health"),
+	}
+
+	// preload indexes and verify embedding model before serving traffic
+	h.warmupUsecase.Start(context.Background())
+
+	group := echo.Group("/api/v1/health")
+	group.GET("/live", h.Live)
+	group.GET("/ready", h.Ready)
+
+	return h
+}
+
+// Live liveness probe
+//
+//	@Summary		Live
+//	@Description	process is up and serving http
+//	@Tags			health
+//	@Produce		json
+//	@Success		200	{object}	domain.Response
+//	@Router			/api/v1/health/live [get]
+func (h *HealthHandler) Live(c echo.Context) error {
+	return h.NewResponseWithData(c, nil)
+}
+
+// Ready readiness probe
+//
+//	@Summary		Ready
+//	@Description	ready after warm-up preloaded hot kb indexes and verified embedding dimension, 503 with the error otherwise
+//	@Tags			health
+//	@Produce		json
+//	@Success		200	{object}	domain.Response{data=domain.WarmupStatus}
+//	@Failure		503	{object}	domain.Response{data=domain.WarmupStatus}
+//	@Router			/api/v1/health/ready [get]
+func (h *HealthHandler) Ready(c echo.Context) error {
+	status := h.warmupUsecase.GetStatus()
+	if !status.Ready() {
+		message := "warm-up is running"
+		if status.State == domain.WarmupStateFailed {
+			message = status.Error
+		}
+		return c.JSON(http.StatusServiceUnavailable, domain.Response{
+			Success: false,
+			Message: message,
+			Data:    status,
+		})
+	}
+	return h.NewResponseWithData(c, status)
+}
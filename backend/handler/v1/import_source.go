@@ -0,0 +1,217 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/handler"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/middleware"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type ImportSourceHandler struct {
+	*handler.BaseHandler
+	usecase *usecase.ImportSourceUsecase
+	logger  *log.Logger
+	auth    middleware.AuthMiddleware
+}
+
+func NewImportSourceHandler(
+	baseHandler *handler.BaseHandler,
+	echo *echo.Echo,
+	usecase *usecase.ImportSourceUsecase,
+	auth middleware.AuthMiddleware,
+	logger *log.Logger,
+) *ImportSourceHandler {
+	h := &ImportSourceHandler{
+		BaseHandler: baseHandler,
+		usecase:     usecase,
+		logger:      logger.WithModule("handler.v1.import_source"),
+		auth:        auth,
+	}
+
+	group := echo.Group("/api/v1/import_source", h.auth.Authorize)
+	group.POST("", h.CreateImportSource)
+	group.GET("/list", h.GetImportSourceList)
+	group.PUT("", h.UpdateImportSource)
+	group.DELETE("", h.DeleteImportSource)
+	group.POST("/sync", h.SyncImportSource)
+	group.POST("/preview", h.PreviewImportSource)
+	group.GET("/stale_pages", h.GetImportSourceStalePages)
+
+	return h
+}
+
+// CreateImportSource add a confluence space, notion pages, feishu docs, a yuque repo, a git repo, an openapi spec, a website or a feed to import
+//
+//	@Summary		CreateImportSource
+//	@Description	add a confluence cloud or server space, notion pages and databases shared with an integration, a feishu wiki space or drive folder readable by a custom app, a yuque repo readable by a token, markdown files of a github or gitlab repo, an openapi 3 spec imported as a document for each tag and operation, pages of a website listed by its sitemap or found by following links within a depth and include and exclude rules, or entries of an rss, atom or json feed, credentials are checked by reading them. sources with a sync interval are synced automatically, git sources with a webhook secret also on push events. pages removed from a source are marked stale, their nodes are kept, entries dropped from feeds are not
+//	@Tags			import_source
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.CreateImportSourceReq	true	"import source"
+//	@Success		200		{object}	domain.Response{data=domain.ImportSource}
+//	@Router			/api/v1/import_source [post]
+func (h *ImportSourceHandler) CreateImportSource(c echo.Context) error {
+	var req domain.CreateImportSourceReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	source, err := h.usecase.CreateSource(c.Request().Context(), &req)
+	if err != nil {
+		return h.NewResponseWithError(c, "create import source failed", err)
+	}
+	return h.NewResponseWithData(c, source)
+}
+
+// GetImportSourceList get import sources of kb
+//
+//	@Summary		GetImportSourceList
+//	@Description	import sources of kb with status of their last sync, without credentials
+//	@Tags			import_source
+//	@Accept			json
+//	@Produce		json
+//	@Param			req	query		domain.ImportSourceListReq	true	"import source list request"
+//	@Success		200	{object}	domain.Response{data=[]domain.ImportSource}
+//	@Router			/api/v1/import_source/list [get]
+func (h *ImportSourceHandler) GetImportSourceList(c echo.Context) error {
+	var req domain.ImportSourceListReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	sources, err := h.usecase.GetSourceList(c.Request().Context(), req.KBID)
+	if err != nil {
+		return h.NewResponseWithError(c, "get import source list failed", err)
+	}
+	return h.NewResponseWithData(c, sources)
+}
+
+// UpdateImportSource update import source
+//
+//	@Summary		UpdateImportSource
+//	@Description	update name, target folder, sync interval or connection of import source, an empty token or app secret keeps the saved one
+//	@Tags			import_source
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.UpdateImportSourceReq	true	"import source"
+//	@Success		200		{object}	domain.Response
+//	@Router			/api/v1/import_source [put]
+func (h *ImportSourceHandler) UpdateImportSource(c echo.Context) error {
+	var req domain.UpdateImportSourceReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	if err := h.usecase.UpdateSource(c.Request().Context(), &req); err != nil {
+		return h.NewResponseWithError(c, "update import source failed", err)
+	}
+	return h.NewResponseWithData(c, nil)
+}
+
+// DeleteImportSource delete import source
+//
+//	@Summary		DeleteImportSource
+//	@Description	delete import source, imported documents are kept
+//	@Tags			import_source
+//	@Accept			json
+//	@Produce		json
+//	@Param			req	query		domain.ImportSourceReq	true	"import source"
+//	@Success		200	{object}	domain.Response
+//	@Router			/api/v1/import_source [delete]
+func (h *ImportSourceHandler) DeleteImportSource(c echo.Context) error {
+	var req domain.ImportSourceReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	if err := h.usecase.DeleteSource(c.Request().Context(), &req); err != nil {
+		return h.NewResponseWithError(c, "delete import source failed", err)
+	}
+	return h.NewResponseWithData(c, nil)
+}
+
+// SyncImportSource sync import source in background
+//
+//	@Summary		SyncImportSource
+//	@Description	import new pages and pages changed since the last sync with their attachments in background, synced documents are published
+//	@Tags			import_source
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.ImportSourceReq	true	"import source"
+//	@Success		200		{object}	domain.Response{data=domain.ImportSource}
+//	@Router			/api/v1/import_source/sync [post]
+func (h *ImportSourceHandler) SyncImportSource(c echo.Context) error {
+	var req domain.ImportSourceReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	source, err := h.usecase.SyncSource(c.Request().Context(), &req)
+	if err != nil {
+		return h.NewResponseWithError(c, "sync import source failed", err)
+	}
+	return h.NewResponseWithData(c, source)
+}
+
+// PreviewImportSource dry run of a sync
+//
+//	@Summary		PreviewImportSource
+//	@Description	pages a sync of the saved source, or of new settings, would create, update or leave unchanged and the nodes it would remove, nothing is written
+//	@Tags			import_source
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.PreviewImportSourceReq	true	"saved source id, or type and settings of a new source"
+//	@Success		200		{object}	domain.Response{data=domain.ImportSourcePreview}
+//	@Router			/api/v1/import_source/preview [post]
+func (h *ImportSourceHandler) PreviewImportSource(c echo.Context) error {
+	var req domain.PreviewImportSourceReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	preview, err := h.usecase.PreviewSource(c.Request().Context(), &req)
+	if err != nil {
+		return h.NewResponseWithError(c, "preview import source failed", err)
+	}
+	return h.NewResponseWithData(c, preview)
+}
+
+// GetImportSourceStalePages pages removed from the source
+//
+//	@Summary		GetImportSourceStalePages
+//	@Description	imported pages no longer found in the source by the last sync, their nodes are kept until deleted in the kb
+//	@Tags			import_source
+//	@Accept			json
+//	@Produce		json
+//	@Param			req	query		domain.ImportSourceReq	true	"import source"
+//	@Success		200	{object}	domain.Response{data=[]domain.ImportSourceStalePage}
+//	@Router			/api/v1/import_source/stale_pages [get]
+func (h *ImportSourceHandler) GetImportSourceStalePages(c echo.Context) error {
+	var req domain.ImportSourceReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	pages, err := h.usecase.GetStalePages(c.Request().Context(), &req)
+	if err != nil {
+		return h.NewResponseWithError(c, "get stale pages failed", err)
+	}
+	return h.NewResponseWithData(c, pages)
+}
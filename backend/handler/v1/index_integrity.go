@@ -0,0 +1,89 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/handler"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/middleware"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type IndexIntegrityHandler struct {
+	*handler.BaseHandler
+	usecase *usecase.IndexIntegrityUsecase
+	logger  *log.Logger
+	auth    middleware.AuthMiddleware
+}
+
+func NewIndexIntegrityHandler(
+	baseHandler *handler.BaseHandler,
+	echo *echo.Echo,
+	usecase *usecase.IndexIntegrityUsecase,
+	auth middleware.AuthMiddleware,
+	logger *log.Logger,
+) *IndexIntegrityHandler {
+	h := &IndexIntegrityHandler{
+		BaseHandler: baseHandler,
+		usecase:     usecase,
+		logger:      logger.WithModule("handler.v1.index_integrity"),
+		auth:        auth,
+	}
+
+	group := echo.Group("/api/v1/knowledge_base/index", h.auth.Authorize)
+	group.GET("/integrity", h.CheckIndexIntegrity)
+	group.POST("/repair", h.RepairIndex)
+
+	return h
+}
+
+// CheckIndexIntegrity check index integrity of kb
+//
+//	@Summary		CheckIndexIntegrity
+//	@Description	compare published nodes with vector store documents of kb and report drift
+//	@Tags			knowledge_base
+//	@Accept			json
+//	@Produce		json
+//	@Param			req	query		domain.IndexIntegrityReq	true	"index integrity request"
+//	@Success		200	{object}	domain.Response{data=domain.IndexIntegrityReport}
+//	@Router			/api/v1/knowledge_base/index/integrity [get]
+func (h *IndexIntegrityHandler) CheckIndexIntegrity(c echo.Context) error {
+	var req domain.IndexIntegrityReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "request params is invalid", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "validate request params failed", err)
+	}
+	report, err := h.usecase.CheckKB(c.Request().Context(), req.KBID, false)
+	if err != nil {
+		return h.NewResponseWithError(c, "check index integrity failed", err)
+	}
+	return h.NewResponseWithData(c, report)
+}
+
+// RepairIndex repair index of kb
+//
+//	@Summary		RepairIndex
+//	@Description	re-index nodes missing in vector store and delete orphan documents of kb
+//	@Tags			knowledge_base
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.IndexIntegrityReq	true	"index integrity request"
+//	@Success		200		{object}	domain.Response{data=domain.IndexIntegrityReport}
+//	@Router			/api/v1/knowledge_base/index/repair [post]
+func (h *IndexIntegrityHandler) RepairIndex(c echo.Context) error {
+	var req domain.IndexIntegrityReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "request params is invalid", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "validate request params failed", err)
+	}
+	report, err := h.usecase.CheckKB(c.Request().Context(), req.KBID, true)
+	if err != nil {
+		return h.NewResponseWithError(c, "repair index failed", err)
+	}
+	return h.NewResponseWithData(c, report)
+}
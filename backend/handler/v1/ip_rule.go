@@ -0,0 +1,81 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/handler"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/middleware"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type IPRuleHandler struct {
+	*handler.BaseHandler
+	usecase *usecase.IPRuleUsecase
+	logger  *log.Logger
+	auth    middleware.AuthMiddleware
+}
+
+func NewIPRuleHandler(
+	baseHandler *handler.BaseHandler,
+	echo *echo.Echo,
+	usecase *usecase.IPRuleUsecase,
+	auth middleware.AuthMiddleware,
+	ipRule *middleware.IPRuleMiddleware,
+	logger *log.Logger,
+) *IPRuleHandler {
+	h := &IPRuleHandler{
+		BaseHandler: baseHandler,
+		usecase:     usecase,
+		logger:      logger.WithModule("handler.v1.ip_rule"),
+		auth:        auth,
+	}
+
+	// reject blocked ips before any other middleware
+	echo.Pre(ipRule.Check)
+
+	group := echo.Group("/api/v1/ip_rules", h.auth.Authorize)
+	group.GET("", h.GetIPRules)
+	group.PUT("", h.UpdateIPRules)
+
+	return h
+}
+
+// GetIPRules get ip rules
+//
+//	@Summary		GetIPRules
+//	@Description	cidr allow and deny rules of the admin apis and of the public site apis, every ip is allowed if not set
+//	@Tags			ip_rule
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	domain.Response{data=domain.IPRules}
+//	@Router			/api/v1/ip_rules [get]
+func (h *IPRuleHandler) GetIPRules(c echo.Context) error {
+	rules, err := h.usecase.GetIPRules(c.Request().Context())
+	if err != nil {
+		return h.NewResponseWithError(c, "get ip rules failed", err)
+	}
+	return h.NewResponseWithData(c, rules)
+}
+
+// UpdateIPRules update ip rules
+//
+//	@Summary		UpdateIPRules
+//	@Description	replace the ip rules, denied ips are rejected first and only allowed ips pass if allow is not empty. rejected if the admin rules would block the ip of the request
+//	@Tags			ip_rule
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.IPRules	true	"ip rules"
+//	@Success		200		{object}	domain.Response
+//	@Router			/api/v1/ip_rules [put]
+func (h *IPRuleHandler) UpdateIPRules(c echo.Context) error {
+	req := &domain.UpdateIPRulesReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := h.usecase.UpdateIPRules(c.Request().Context(), req, c.RealIP()); err != nil {
+		return h.NewResponseWithError(c, "update ip rules failed", err)
+	}
+	return h.NewResponseWithData(c, nil)
+}
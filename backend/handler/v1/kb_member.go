@@ -0,0 +1,113 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/handler"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/middleware"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type KBMemberHandler struct {
+	*handler.BaseHandler
+	usecase *usecase.KBMemberUsecase
+	logger  *log.Logger
+	auth    middleware.AuthMiddleware
+}
+
+func NewKBMemberHandler(
+	baseHandler *handler.BaseHandler,
+	echo *echo.Echo,
+	usecase *usecase.KBMemberUsecase,
+	auth middleware.AuthMiddleware,
+	logger *log.Logger,
+) *KBMemberHandler {
+	h := &KBMemberHandler{
+		BaseHandler: baseHandler,
+		usecase:     usecase,
+		logger:      logger.WithModule("handler.v1.kb_member"),
+		auth:        auth,
+	}
+
+	group := echo.Group("/api/v1/knowledge_base/member", h.auth.Authorize)
+	group.GET("/list", h.GetKBMemberList)
+	group.PUT("", h.SetKBMember)
+	group.DELETE("", h.DeleteKBMember)
+
+	return h
+}
+
+// GetKBMemberList get members of kb
+//
+//	@Summary		GetKBMemberList
+//	@Description	member users of kb with their roles, admins may access every kb without a role
+//	@Tags			knowledge_base
+//	@Accept			json
+//	@Produce		json
+//	@Param			params	query		domain.KBMemberListReq	true	"params"
+//	@Success		200		{object}	domain.Response{data=[]domain.KBMemberListItem}
+//	@Router			/api/v1/knowledge_base/member/list [get]
+func (h *KBMemberHandler) GetKBMemberList(c echo.Context) error {
+	req := &domain.KBMemberListReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request params is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request params failed", err)
+	}
+	members, err := h.usecase.GetKBMemberList(c.Request().Context(), req)
+	if err != nil {
+		return h.NewResponseWithError(c, "get kb member list failed", err)
+	}
+	return h.NewResponseWithData(c, members)
+}
+
+// SetKBMember set role of user on kb
+//
+//	@Summary		SetKBMember
+//	@Description	add the user to kb with the role, or change its role. owners manage settings and members, editors edit documents, analysts view conversations and reports, support agents view conversations
+//	@Tags			knowledge_base
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.SetKBMemberReq	true	"set kb member request"
+//	@Success		200		{object}	domain.Response
+//	@Router			/api/v1/knowledge_base/member [put]
+func (h *KBMemberHandler) SetKBMember(c echo.Context) error {
+	req := &domain.SetKBMemberReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	if err := h.usecase.SetKBMember(c.Request().Context(), req); err != nil {
+		return h.NewResponseWithError(c, "set kb member failed", err)
+	}
+	return h.NewResponseWithData(c, nil)
+}
+
+// DeleteKBMember remove user from kb
+//
+//	@Summary		DeleteKBMember
+//	@Description	remove the role of the user on kb, members without a role can't access the kb
+//	@Tags			knowledge_base
+//	@Accept			json
+//	@Produce		json
+//	@Param			params	query		domain.DeleteKBMemberReq	true	"params"
+//	@Success		200		{object}	domain.Response
+//	@Router			/api/v1/knowledge_base/member [delete]
+func (h *KBMemberHandler) DeleteKBMember(c echo.Context) error {
+	req := &domain.DeleteKBMemberReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request params is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request params failed", err)
+	}
+	if err := h.usecase.DeleteKBMember(c.Request().Context(), req); err != nil {
+		return h.NewResponseWithError(c, "delete kb member failed", err)
+	}
+	return h.NewResponseWithData(c, nil)
+}
@@ -19,6 +19,8 @@ type KnowledgeBaseHandler struct {
 	llmUsecase *usecase.LLMUsecase
 	logger     *log.Logger
 	auth       middleware.AuthMiddleware
+
+	kbMemberUsecase *usecase.KBMemberUsecase
 }
 
 func NewKnowledgeBaseHandler(
@@ -26,6 +28,7 @@ func NewKnowledgeBaseHandler(
 	echo *echo.Echo,
 	usecase *usecase.KnowledgeBaseUsecase,
 	llmUsecase *usecase.LLMUsecase,
+	kbMemberUsecase *usecase.KBMemberUsecase,
 	auth middleware.AuthMiddleware,
 	logger *log.Logger,
 ) *KnowledgeBaseHandler {
@@ -35,6 +38,8 @@ func NewKnowledgeBaseHandler(
 		usecase:     usecase,
 		llmUsecase:  llmUsecase,
 		auth:        auth,
+
+		kbMemberUsecase: kbMemberUsecase,
 	}
 
 	group := echo.Group("/api/v1/knowledge_base", h.auth.Authorize)
@@ -43,6 +48,8 @@ func NewKnowledgeBaseHandler(
 	group.GET("/detail", h.GetKnowledgeBaseDetail)
 	group.PUT("/detail", h.UpdateKnowledgeBase)
 	group.DELETE("/detail", h.DeleteKnowledgeBase)
+	// built-in content policy profiles
+	group.GET("/compliance_profiles", h.GetComplianceProfiles)
 	// release
 	group.POST("/release", h.CreateKBRelease)
 	group.GET("/release/list", h.GetKBReleaseList)
@@ -83,10 +90,10 @@ func (h *KnowledgeBaseHandler) CreateKnowledgeBase(c echo.Context) error {
 	did, err := h.usecase.CreateKnowledgeBase(c.Request().Context(), &req)
 	if err != nil {
 		if errors.Is(err, domain.ErrPortHostAlreadyExists) {
-			return h.NewResponseWithError(c, "端口或域名已被其他知识库占用", nil)
+			return h.NewResponseWithError(c, "端口或域名已被其他知识库占用", err)
 		}
 		if errors.Is(err, domain.ErrSyncCaddyConfigFailed) {
-			return h.NewResponseWithError(c, "端口可能已被其他程序占用，请检查", nil)
+			return h.NewResponseWithError(c, "端口可能已被其他程序占用，请检查", err)
 		}
 		return h.NewResponseWithError(c, "failed to create knowledge base", err)
 	}
@@ -99,17 +106,34 @@ func (h *KnowledgeBaseHandler) CreateKnowledgeBase(c echo.Context) error {
 // GetKnowledgeBaseList
 //
 //	@Summary		GetKnowledgeBaseList
-//	@Description	GetKnowledgeBaseList
+//	@Description	knowledge bases of the user, all for admins and those with a role for members
 //	@Tags			knowledge_base
 //	@Accept			json
 //	@Produce		json
 //	@Success		200	{object}	domain.Response{data=[]domain.KnowledgeBaseListItem}
 //	@Router			/api/v1/knowledge_base/list [get]
 func (h *KnowledgeBaseHandler) GetKnowledgeBaseList(c echo.Context) error {
-	knowledgeBases, err := h.usecase.GetKnowledgeBaseList(c.Request().Context())
+	ctx := c.Request().Context()
+	knowledgeBases, err := h.usecase.GetKnowledgeBaseList(ctx)
 	if err != nil {
 		return h.NewResponseWithError(c, "failed to get knowledge base list", err)
 	}
+	userID, ok := h.auth.MustGetUserID(c)
+	if !ok {
+		return h.NewResponseWithError(c, "failed to get user", domain.ErrUnauthorized)
+	}
+	roles, err := h.kbMemberUsecase.GetUserKBRoles(ctx, userID)
+	if err != nil {
+		return h.NewResponseWithError(c, "failed to get knowledge base list", err)
+	}
+	// members only see the kbs they have a role on
+	if roles != nil {
+		knowledgeBases = lo.Filter(knowledgeBases, func(kb *domain.KnowledgeBaseListItem, _ int) bool {
+			return lo.ContainsBy(roles, func(role *domain.UserKBRole) bool {
+				return role.KBID == kb.ID
+			})
+		})
+	}
 
 	return h.NewResponseWithData(c, knowledgeBases)
 }
@@ -137,10 +161,10 @@ func (h *KnowledgeBaseHandler) UpdateKnowledgeBase(c echo.Context) error {
 	err := h.usecase.UpdateKnowledgeBase(c.Request().Context(), &req)
 	if err != nil {
 		if errors.Is(err, domain.ErrPortHostAlreadyExists) {
-			return h.NewResponseWithError(c, "端口或域名已被其他知识库占用", nil)
+			return h.NewResponseWithError(c, "端口或域名已被其他知识库占用", err)
 		}
 		if errors.Is(err, domain.ErrSyncCaddyConfigFailed) {
-			return h.NewResponseWithError(c, "端口可能已被其他程序占用，请检查", nil)
+			return h.NewResponseWithError(c, "端口可能已被其他程序占用，请检查", err)
 		}
 		return h.NewResponseWithError(c, "failed to update knowledge base", err)
 	}
@@ -251,3 +275,16 @@ func (h *KnowledgeBaseHandler) GetKBReleaseList(c echo.Context) error {
 
 	return h.NewResponseWithData(c, resp)
 }
+
+// GetComplianceProfiles
+//
+//	@Summary		GetComplianceProfiles
+//	@Description	built-in content policy profiles selectable per deployment region
+//	@Tags			knowledge_base
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	domain.Response{data=[]domain.ComplianceProfile}
+//	@Router			/api/v1/knowledge_base/compliance_profiles [get]
+func (h *KnowledgeBaseHandler) GetComplianceProfiles(c echo.Context) error {
+	return h.NewResponseWithData(c, domain.ComplianceProfiles)
+}
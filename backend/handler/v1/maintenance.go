@@ -0,0 +1,85 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/handler"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/middleware"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type MaintenanceHandler struct {
+	*handler.BaseHandler
+	usecase *usecase.MaintenanceUsecase
+	logger  *log.Logger
+	auth    middleware.AuthMiddleware
+}
+
+func NewMaintenanceHandler(
+	baseHandler *handler.BaseHandler,
+	echo *echo.Echo,
+	usecase *usecase.MaintenanceUsecase,
+	auth middleware.AuthMiddleware,
+	readOnly *middleware.ReadOnlyMiddleware,
+	logger *log.Logger,
+) *MaintenanceHandler {
+	h := &MaintenanceHandler{
+		BaseHandler: baseHandler,
+		usecase:     usecase,
+		logger:      logger.WithModule("handler.v1.maintenance"),
+		auth:        auth,
+	}
+
+	// block admin writes while read-only mode is on
+	echo.Use(readOnly.Check)
+
+	group := echo.Group("/api/v1/maintenance", h.auth.Authorize)
+	group.GET("", h.GetMaintenance)
+	group.PUT("", h.UpdateMaintenance)
+
+	return h
+}
+
+// GetMaintenance get read-only mode settings
+//
+//	@Summary		GetMaintenance
+//	@Description	global read-only mode, and read-only mode of the kb if kb_id is given
+//	@Tags			maintenance
+//	@Accept			json
+//	@Produce		json
+//	@Param			kb_id	query		string	false	"kb id"
+//	@Success		200		{object}	domain.Response{data=domain.MaintenanceResp}
+//	@Router			/api/v1/maintenance [get]
+func (h *MaintenanceHandler) GetMaintenance(c echo.Context) error {
+	resp, err := h.usecase.GetMaintenance(c.Request().Context(), c.QueryParam("kb_id"))
+	if err != nil {
+		return h.NewResponseWithError(c, "get maintenance settings failed", err)
+	}
+	return h.NewResponseWithData(c, resp)
+}
+
+// UpdateMaintenance switch read-only mode
+//
+//	@Summary		UpdateMaintenance
+//	@Description	switch read-only mode globally, or of a kb if kb_id is given
+//	@Tags			maintenance
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.UpdateMaintenanceReq	true	"maintenance settings"
+//	@Success		200		{object}	domain.Response
+//	@Router			/api/v1/maintenance [put]
+func (h *MaintenanceHandler) UpdateMaintenance(c echo.Context) error {
+	req := &domain.UpdateMaintenanceReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	if err := h.usecase.UpdateMaintenance(c.Request().Context(), req); err != nil {
+		return h.NewResponseWithError(c, "update maintenance settings failed", err)
+	}
+	return h.NewResponseWithData(c, nil)
+}
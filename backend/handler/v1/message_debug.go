@@ -0,0 +1,64 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/handler"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/middleware"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type MessageDebugHandler struct {
+	*handler.BaseHandler
+	usecase *usecase.MessageDebugUsecase
+	logger  *log.Logger
+	auth    middleware.AuthMiddleware
+}
+
+func NewMessageDebugHandler(
+	baseHandler *handler.BaseHandler,
+	echo *echo.Echo,
+	usecase *usecase.MessageDebugUsecase,
+	auth middleware.AuthMiddleware,
+	logger *log.Logger,
+) *MessageDebugHandler {
+	h := &MessageDebugHandler{
+		BaseHandler: baseHandler,
+		usecase:     usecase,
+		logger:      logger.WithModule("handler.v1.message_debug"),
+		auth:        auth,
+	}
+
+	group := echo.Group("/api/v1/conversation/message", h.auth.Authorize)
+	group.GET("/debug", h.DebugMessage)
+
+	return h
+}
+
+// DebugMessage replay retrieval of an answer
+//
+//	@Summary		DebugMessage
+//	@Description	replay retrieval and prompt of an answer with the current documents and settings: the query, candidates of each retrieval stage with scores, the final prompt and model parameters
+//	@Tags			conversation
+//	@Accept			json
+//	@Produce		json
+//	@Param			kb_id		query		string	true	"kb id"
+//	@Param			message_id	query		string	true	"answer message id"
+//	@Success		200			{object}	domain.Response{data=domain.MessageDebugResp}
+//	@Router			/api/v1/conversation/message/debug [get]
+func (h *MessageDebugHandler) DebugMessage(c echo.Context) error {
+	req := &domain.MessageDebugReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request params is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request params failed", err)
+	}
+	resp, err := h.usecase.DebugMessage(c.Request().Context(), req)
+	if err != nil {
+		return h.NewResponseWithError(c, "debug message failed", err)
+	}
+	return h.NewResponseWithData(c, resp)
+}
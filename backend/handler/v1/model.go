@@ -17,23 +17,35 @@ type ModelHandler struct {
 	auth       middleware.AuthMiddleware
 	usecase    *usecase.ModelUsecase
 	llmUsecase *usecase.LLMUsecase
+
+	compareUsecase *usecase.ModelCompareUsecase
 }
 
-func NewModelHandler(echo *echo.Echo, baseHandler *handler.BaseHandler, logger *log.Logger, auth middleware.AuthMiddleware, usecase *usecase.ModelUsecase, llmUsecase *usecase.LLMUsecase) *ModelHandler {
+func NewModelHandler(echo *echo.Echo, baseHandler *handler.BaseHandler, logger *log.Logger, auth middleware.AuthMiddleware, usecase *usecase.ModelUsecase, llmUsecase *usecase.LLMUsecase, compareUsecase *usecase.ModelCompareUsecase) *ModelHandler {
 	handler := &ModelHandler{
 		BaseHandler: baseHandler,
 		logger:      logger.WithModule("handler.v1.model"),
 		auth:        auth,
 		usecase:     usecase,
 		llmUsecase:  llmUsecase,
+
+		compareUsecase: compareUsecase,
 	}
 	group := echo.Group("/api/v1/model", handler.auth.Authorize)
 	group.GET("/list", handler.GetModelList)
 	group.GET("/detail", handler.GetModelDetail)
 	group.POST("", handler.CreateModel)
 	group.POST("/check", handler.CheckModel)
+	// connectivity of configured models
+	group.GET("/check/saved", handler.CheckSavedModel)
+	group.GET("/check/kb", handler.CheckKBModels)
+	group.POST("/compare", handler.CompareModels)
 	group.POST("/provider/supported", handler.GetProviderSupportedModelList)
 	group.PUT("", handler.UpdateModel)
+	// monthly budget and spend
+	group.GET("/budget", handler.GetModelBudget)
+	group.PUT("/budget", handler.UpdateModelBudget)
+	group.GET("/spend", handler.GetModelSpend)
 
 	return handler
 }
@@ -110,6 +122,8 @@ func (h *ModelHandler) CreateModel(c echo.Context) error {
 		BaseURL:    req.BaseURL,
 		APIVersion: req.APIVersion,
 		Type:       req.Type,
+		Priority:   req.Priority,
+		Weight:     req.Weight,
 	}
 	if err := h.usecase.Create(ctx, model); err != nil {
 		return h.NewResponseWithError(c, "create model failed", err)
@@ -167,6 +181,81 @@ func (h *ModelHandler) CheckModel(c echo.Context) error {
 	return h.NewResponseWithData(c, model)
 }
 
+// check saved model
+//
+//	@Summary		check saved model
+//	@Description	check connectivity of a configured model
+//	@Tags			model
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	query		string	true	"model id"
+//	@Success		200	{object}	domain.Response{data=domain.CheckModelResp}
+//	@Router			/api/v1/model/check/saved [get]
+func (h *ModelHandler) CheckSavedModel(c echo.Context) error {
+	var req domain.CheckSavedModelReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	resp, err := h.llmUsecase.CheckSavedModel(c.Request().Context(), req.ID)
+	if err != nil {
+		return h.NewResponseWithError(c, "check model failed", err)
+	}
+	return h.NewResponseWithData(c, resp)
+}
+
+// check kb models
+//
+//	@Summary		check kb models
+//	@Description	check connectivity of the chat, embedding and rerank models used by kb, the default models if kb selects none
+//	@Tags			model
+//	@Accept			json
+//	@Produce		json
+//	@Param			kb_id	query		string	true	"kb id"
+//	@Success		200		{object}	domain.Response{data=domain.CheckKBModelsResp}
+//	@Router			/api/v1/model/check/kb [get]
+func (h *ModelHandler) CheckKBModels(c echo.Context) error {
+	var req domain.CheckKBModelsReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	resp, err := h.llmUsecase.CheckKBModels(c.Request().Context(), req.KBID)
+	if err != nil {
+		return h.NewResponseWithError(c, "check kb models failed", err)
+	}
+	return h.NewResponseWithData(c, resp)
+}
+
+// compare models
+//
+//	@Summary		compare models
+//	@Description	answer one question with 2 or 3 chat models at the same time over the same retrieved documents, with latency, usage and cost of each answer
+//	@Tags			model
+//	@Accept			json
+//	@Produce		json
+//	@Param			req	body		domain.CompareModelsReq	true	"compare models request"
+//	@Success		200	{object}	domain.Response{data=domain.CompareModelsResp}
+//	@Router			/api/v1/model/compare [post]
+func (h *ModelHandler) CompareModels(c echo.Context) error {
+	var req domain.CompareModelsReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	resp, err := h.compareUsecase.CompareModels(c.Request().Context(), &req)
+	if err != nil {
+		return h.NewResponseWithError(c, "compare models failed", err)
+	}
+	return h.NewResponseWithData(c, resp)
+}
+
 // get provider supported model list
 //
 //	@Summary		get provider supported model list
@@ -193,3 +282,74 @@ func (h *ModelHandler) GetProviderSupportedModelList(c echo.Context) error {
 	}
 	return h.NewResponseWithData(c, models)
 }
+
+// get model budget
+//
+//	@Summary		get model budget
+//	@Description	get monthly budget settings of model
+//	@Tags			model
+//	@Accept			json
+//	@Produce		json
+//	@Param			model_id	query		string	true	"model id"
+//	@Success		200			{object}	domain.Response{data=domain.ModelBudget}
+//	@Router			/api/v1/model/budget [get]
+func (h *ModelHandler) GetModelBudget(c echo.Context) error {
+	modelID := c.QueryParam("model_id")
+	if modelID == "" {
+		return h.NewResponseWithError(c, "model_id is required", nil)
+	}
+	budget, err := h.usecase.GetBudget(c.Request().Context(), modelID)
+	if err != nil {
+		return h.NewResponseWithError(c, "get model budget failed", err)
+	}
+	return h.NewResponseWithData(c, budget)
+}
+
+// update model budget
+//
+//	@Summary		update model budget
+//	@Description	update monthly budget settings of model
+//	@Tags			model
+//	@Accept			json
+//	@Produce		json
+//	@Param			model	body		domain.ModelBudget	true	"model budget"
+//	@Success		200		{object}	domain.Response
+//	@Router			/api/v1/model/budget [put]
+func (h *ModelHandler) UpdateModelBudget(c echo.Context) error {
+	var req domain.ModelBudget
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	if err := h.usecase.UpdateBudget(c.Request().Context(), &req); err != nil {
+		return h.NewResponseWithError(c, "update model budget failed", err)
+	}
+	return h.NewResponseWithData(c, nil)
+}
+
+// get model spend
+//
+//	@Summary		get model spend
+//	@Description	get token usage, spend and budget of chat models in month
+//	@Tags			model
+//	@Accept			json
+//	@Produce		json
+//	@Param			month	query		string	false	"month, e.g. 2025-06, default current month"
+//	@Success		200		{object}	domain.Response{data=[]domain.ModelSpendResp}
+//	@Router			/api/v1/model/spend [get]
+func (h *ModelHandler) GetModelSpend(c echo.Context) error {
+	var req domain.GetModelSpendReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	spends, err := h.usecase.GetSpend(c.Request().Context(), &req)
+	if err != nil {
+		return h.NewResponseWithError(c, "get model spend failed", err)
+	}
+	return h.NewResponseWithData(c, spends)
+}
@@ -12,15 +12,17 @@ import (
 
 type NodeHandler struct {
 	*handler.BaseHandler
-	logger  *log.Logger
-	usecase *usecase.NodeUsecase
-	auth    middleware.AuthMiddleware
+	logger    *log.Logger
+	usecase   *usecase.NodeUsecase
+	kbUsecase *usecase.KnowledgeBaseUsecase
+	auth      middleware.AuthMiddleware
 }
 
 func NewNodeHandler(
 	baseHandler *handler.BaseHandler,
 	echo *echo.Echo,
 	usecase *usecase.NodeUsecase,
+	kbUsecase *usecase.KnowledgeBaseUsecase,
 	auth middleware.AuthMiddleware,
 	logger *log.Logger,
 ) *NodeHandler {
@@ -28,6 +30,7 @@ func NewNodeHandler(
 		BaseHandler: baseHandler,
 		logger:      logger.WithModule("handler.v1.node"),
 		usecase:     usecase,
+		kbUsecase:   kbUsecase,
 		auth:        auth,
 	}
 
@@ -43,6 +46,27 @@ func NewNodeHandler(
 
 	group.GET("/recommend_nodes", h.RecommendNodes)
 
+	// draft and published content
+	group.GET("/published", h.GetPublishedNode)
+	group.POST("/publish", h.PublishNodes)
+	group.POST("/discard_draft", h.DiscardNodeDraft)
+
+	// second pass of import
+	group.POST("/rewrite_import_links", h.RewriteImportLinks)
+	// identical documents of any kb, imports link to them instead of duplicating
+	group.POST("/duplicates/check", h.CheckNodeDuplicates)
+
+	group.GET("/defaults", h.GetNodeDefaults)
+	group.PUT("/defaults", h.UpdateNodeDefaults)
+
+	// internal link graph
+	group.GET("/backlinks", h.GetNodeBacklinks)
+	group.GET("/broken_links", h.GetBrokenNodeLinks)
+
+	// content freshness
+	group.GET("/stale", h.GetStaleNodes)
+	group.POST("/mark_reviewed", h.MarkNodesReviewed)
+
 	return h
 }
 
@@ -142,7 +166,7 @@ func (h *NodeHandler) NodeAction(c echo.Context) error {
 	ctx := c.Request().Context()
 	if err := h.usecase.NodeAction(ctx, req); err != nil {
 		if err == domain.ErrNodeParentIDInIDs {
-			return h.NewResponseWithError(c, "文件夹下有子文件，不能删除~", nil)
+			return h.NewResponseWithError(c, "文件夹下有子文件，不能删除~", err)
 		}
 		return h.NewResponseWithError(c, "node action failed", err)
 	}
@@ -255,3 +279,281 @@ func (h *NodeHandler) RecommendNodes(c echo.Context) error {
 	}
 	return h.NewResponseWithData(c, nodes)
 }
+
+// Get Node Defaults
+//
+//	@Summary		Get Node Defaults
+//	@Description	defaults of folder and effective defaults inherited by new child nodes
+//	@Tags			node
+//	@Accept			json
+//	@Produce		json
+//	@Param			kb_id	query		string	true	"kb id"
+//	@Param			id		query		string	true	"folder id"
+//	@Success		200		{object}	domain.Response{data=domain.NodeDefaultsResp}
+//	@Router			/api/v1/node/defaults [get]
+func (h *NodeHandler) GetNodeDefaults(c echo.Context) error {
+	kbID := c.QueryParam("kb_id")
+	if kbID == "" {
+		return h.NewResponseWithError(c, "kb_id is required", nil)
+	}
+	id := c.QueryParam("id")
+	if id == "" {
+		return h.NewResponseWithError(c, "id is required", nil)
+	}
+	defaults, err := h.usecase.GetNodeDefaults(c.Request().Context(), kbID, id)
+	if err != nil {
+		return h.NewResponseWithError(c, "get node defaults failed", err)
+	}
+	return h.NewResponseWithData(c, defaults)
+}
+
+// Update Node Defaults
+//
+//	@Summary		Update Node Defaults
+//	@Description	set folder defaults inherited by new child nodes
+//	@Tags			node
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.UpdateNodeDefaultsReq	true	"defaults"
+//	@Success		200		{object}	domain.Response
+//	@Router			/api/v1/node/defaults [put]
+func (h *NodeHandler) UpdateNodeDefaults(c echo.Context) error {
+	req := &domain.UpdateNodeDefaultsReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	if err := h.usecase.UpdateNodeDefaults(c.Request().Context(), req); err != nil {
+		return h.NewResponseWithError(c, "update node defaults failed", err)
+	}
+	return h.NewResponseWithData(c, nil)
+}
+
+// Get Published Node
+//
+//	@Summary		Get Published Node
+//	@Description	content of node served by the public site and rag index
+//	@Tags			node
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	query		string	true	"node id"
+//	@Success		200	{object}	domain.Response{data=domain.PublishedNodeResp}
+//	@Router			/api/v1/node/published [get]
+func (h *NodeHandler) GetPublishedNode(c echo.Context) error {
+	id := c.QueryParam("id")
+	if id == "" {
+		return h.NewResponseWithError(c, "node id is required", nil)
+	}
+	node, err := h.usecase.GetPublished(c.Request().Context(), id)
+	if err != nil {
+		return h.NewResponseWithError(c, "get published node failed", err)
+	}
+	return h.NewResponseWithData(c, node)
+}
+
+// Publish Nodes
+//
+//	@Summary		Publish Nodes
+//	@Description	publish drafts of nodes and create a release
+//	@Tags			node
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.PublishNodeReq	true	"nodes"
+//	@Success		200		{object}	domain.Response{data=map[string]string}
+//	@Router			/api/v1/node/publish [post]
+func (h *NodeHandler) PublishNodes(c echo.Context) error {
+	req := &domain.PublishNodeReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	releaseID, err := h.kbUsecase.PublishNodes(c.Request().Context(), req)
+	if err != nil {
+		return h.NewResponseWithError(c, "publish nodes failed", err)
+	}
+	return h.NewResponseWithData(c, map[string]string{
+		"release_id": releaseID,
+	})
+}
+
+// Discard Node Draft
+//
+//	@Summary		Discard Node Draft
+//	@Description	drop unpublished changes and restore the latest published content
+//	@Tags			node
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.DiscardNodeDraftReq	true	"node"
+//	@Success		200		{object}	domain.Response
+//	@Router			/api/v1/node/discard_draft [post]
+func (h *NodeHandler) DiscardNodeDraft(c echo.Context) error {
+	req := &domain.DiscardNodeDraftReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	if err := h.usecase.DiscardDraft(c.Request().Context(), req); err != nil {
+		return h.NewResponseWithError(c, "discard node draft failed", err)
+	}
+	return h.NewResponseWithData(c, nil)
+}
+
+// Rewrite Import Links
+//
+//	@Summary		Rewrite Import Links
+//	@Description	point links between imported pages at their new nodes, called after all pages of an import are created
+//	@Tags			node
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.RewriteImportLinksReq	true	"source url to node map"
+//	@Success		200		{object}	domain.Response{data=domain.RewriteImportLinksResp}
+//	@Router			/api/v1/node/rewrite_import_links [post]
+func (h *NodeHandler) RewriteImportLinks(c echo.Context) error {
+	req := &domain.RewriteImportLinksReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	resp, err := h.usecase.RewriteImportLinks(c.Request().Context(), req)
+	if err != nil {
+		return h.NewResponseWithError(c, "rewrite import links failed", err)
+	}
+	return h.NewResponseWithData(c, resp)
+}
+
+// Check Node Duplicates
+//
+//	@Summary		Check Node Duplicates
+//	@Description	find existing documents of any kb identical to documents about to be imported, create with link_to_node_id to link instead of duplicating
+//	@Tags			node
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.NodeDuplicateCheckReq	true	"contents to import"
+//	@Success		200		{object}	domain.Response{data=domain.NodeDuplicateCheckResp}
+//	@Router			/api/v1/node/duplicates/check [post]
+func (h *NodeHandler) CheckNodeDuplicates(c echo.Context) error {
+	req := &domain.NodeDuplicateCheckReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	resp, err := h.usecase.CheckDuplicates(c.Request().Context(), req)
+	if err != nil {
+		return h.NewResponseWithError(c, "check node duplicates failed", err)
+	}
+	return h.NewResponseWithData(c, resp)
+}
+
+// Get Node Backlinks
+//
+//	@Summary		Get Node Backlinks
+//	@Description	nodes linking to the node
+//	@Tags			node
+//	@Accept			json
+//	@Produce		json
+//	@Param			kb_id	query		string	true	"kb id"
+//	@Param			id		query		string	true	"node id"
+//	@Success		200		{object}	domain.Response{data=[]domain.NodeBacklinkResp}
+//	@Router			/api/v1/node/backlinks [get]
+func (h *NodeHandler) GetNodeBacklinks(c echo.Context) error {
+	kbID := c.QueryParam("kb_id")
+	if kbID == "" {
+		return h.NewResponseWithError(c, "kb_id is required", nil)
+	}
+	id := c.QueryParam("id")
+	if id == "" {
+		return h.NewResponseWithError(c, "id is required", nil)
+	}
+	backlinks, err := h.usecase.GetNodeBacklinks(c.Request().Context(), kbID, id)
+	if err != nil {
+		return h.NewResponseWithError(c, "get node backlinks failed", err)
+	}
+	return h.NewResponseWithData(c, backlinks)
+}
+
+// Get Broken Node Links
+//
+//	@Summary		Get Broken Node Links
+//	@Description	internal links of kb pointing to deleted nodes
+//	@Tags			node
+//	@Accept			json
+//	@Produce		json
+//	@Param			kb_id	query		string	true	"kb id"
+//	@Success		200		{object}	domain.Response{data=[]domain.BrokenNodeLinkResp}
+//	@Router			/api/v1/node/broken_links [get]
+func (h *NodeHandler) GetBrokenNodeLinks(c echo.Context) error {
+	kbID := c.QueryParam("kb_id")
+	if kbID == "" {
+		return h.NewResponseWithError(c, "kb_id is required", nil)
+	}
+	links, err := h.usecase.GetBrokenNodeLinks(c.Request().Context(), kbID)
+	if err != nil {
+		return h.NewResponseWithError(c, "get broken node links failed", err)
+	}
+	return h.NewResponseWithData(c, links)
+}
+
+type NodeStaleListItems = domain.PaginatedResult[[]*domain.NodeStaleItem]
+
+// Get Stale Nodes
+//
+//	@Summary		Get Stale Nodes
+//	@Description	documents of kb neither updated nor reviewed in the given days, ordered by age or by recent views to find docs read a lot but old
+//	@Tags			node
+//	@Accept			json
+//	@Produce		json
+//	@Param			req	query		domain.NodeStaleListReq	true	"stale node list request"
+//	@Success		200	{object}	domain.Response{data=NodeStaleListItems}
+//	@Router			/api/v1/node/stale [get]
+func (h *NodeHandler) GetStaleNodes(c echo.Context) error {
+	req := &domain.NodeStaleListReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request params is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request params failed", err)
+	}
+	nodes, err := h.usecase.GetStaleNodes(c.Request().Context(), req)
+	if err != nil {
+		return h.NewResponseWithError(c, "get stale nodes failed", err)
+	}
+	return h.NewResponseWithData(c, nodes)
+}
+
+// Mark Nodes Reviewed
+//
+//	@Summary		Mark Nodes Reviewed
+//	@Description	record the content of the nodes is still accurate, they leave the stale report until the given days pass again
+//	@Tags			node
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.MarkNodesReviewedReq	true	"mark reviewed request"
+//	@Success		200		{object}	domain.Response
+//	@Router			/api/v1/node/mark_reviewed [post]
+func (h *NodeHandler) MarkNodesReviewed(c echo.Context) error {
+	userID, ok := h.auth.MustGetUserID(c)
+	if !ok {
+		return h.NewResponseWithError(c, "user not found", nil)
+	}
+	req := &domain.MarkNodesReviewedReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	if err := h.usecase.MarkNodesReviewed(c.Request().Context(), req, userID); err != nil {
+		return h.NewResponseWithError(c, "mark nodes reviewed failed", err)
+	}
+	return h.NewResponseWithData(c, nil)
+}
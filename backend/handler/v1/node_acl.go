@@ -0,0 +1,88 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/handler"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/middleware"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type NodeACLHandler struct {
+	*handler.BaseHandler
+	usecase *usecase.NodeACLUsecase
+	logger  *log.Logger
+	auth    middleware.AuthMiddleware
+}
+
+func NewNodeACLHandler(
+	baseHandler *handler.BaseHandler,
+	echo *echo.Echo,
+	usecase *usecase.NodeACLUsecase,
+	auth middleware.AuthMiddleware,
+	logger *log.Logger,
+) *NodeACLHandler {
+	h := &NodeACLHandler{
+		BaseHandler: baseHandler,
+		usecase:     usecase,
+		logger:      logger.WithModule("handler.v1.node_acl"),
+		auth:        auth,
+	}
+
+	group := echo.Group("/api/v1/node/acl", h.auth.Authorize)
+	group.PUT("", h.SetNodeACL)
+	group.GET("/list", h.GetNodeACLList)
+
+	return h
+}
+
+// SetNodeACL set acl of nodes
+//
+//	@Summary		SetNodeACL
+//	@Description	restrict the nodes on the public site and in chat retrieval to logged in viewers or viewer groups, public access removes the restriction. subtree also restricts all descendants
+//	@Tags			node
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.SetNodeACLReq	true	"set acl request"
+//	@Success		200		{object}	domain.Response
+//	@Router			/api/v1/node/acl [put]
+func (h *NodeACLHandler) SetNodeACL(c echo.Context) error {
+	var req domain.SetNodeACLReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	if err := h.usecase.SetNodeACL(c.Request().Context(), &req); err != nil {
+		return h.NewResponseWithError(c, "set node acl failed", err)
+	}
+	return h.NewResponseWithData(c, nil)
+}
+
+// GetNodeACLList get acls of kb
+//
+//	@Summary		GetNodeACLList
+//	@Description	restricted nodes of kb with their acls, nodes not listed are public
+//	@Tags			node
+//	@Accept			json
+//	@Produce		json
+//	@Param			req	query		domain.NodeACLListReq	true	"node acl list request"
+//	@Success		200	{object}	domain.Response{data=[]domain.NodeACLListItem}
+//	@Router			/api/v1/node/acl/list [get]
+func (h *NodeACLHandler) GetNodeACLList(c echo.Context) error {
+	var req domain.NodeACLListReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "request params is invalid", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "validate request params failed", err)
+	}
+	items, err := h.usecase.GetNodeACLList(c.Request().Context(), &req)
+	if err != nil {
+		return h.NewResponseWithError(c, "get node acl list failed", err)
+	}
+	return h.NewResponseWithData(c, items)
+}
@@ -0,0 +1,118 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/handler"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/middleware"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type NodeAttachmentHandler struct {
+	*handler.BaseHandler
+	usecase *usecase.NodeAttachmentUsecase
+	logger  *log.Logger
+	auth    middleware.AuthMiddleware
+}
+
+func NewNodeAttachmentHandler(
+	baseHandler *handler.BaseHandler,
+	echo *echo.Echo,
+	usecase *usecase.NodeAttachmentUsecase,
+	auth middleware.AuthMiddleware,
+	logger *log.Logger,
+) *NodeAttachmentHandler {
+	h := &NodeAttachmentHandler{
+		BaseHandler: baseHandler,
+		usecase:     usecase,
+		logger:      logger.WithModule("handler.v1.node_attachment"),
+		auth:        auth,
+	}
+
+	group := echo.Group("/api/v1/node/attachment", h.auth.Authorize)
+	group.POST("/upload", h.UploadNodeAttachment)
+	group.GET("/list", h.GetNodeAttachmentList)
+	group.DELETE("", h.DeleteNodeAttachment)
+
+	return h
+}
+
+// UploadNodeAttachment upload node attachment
+//
+//	@Summary		UploadNodeAttachment
+//	@Description	upload file attached to node, stored in private bucket
+//	@Tags			node_attachment
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Param			kb_id	formData	string	true	"kb id"
+//	@Param			node_id	formData	string	true	"node id"
+//	@Param			file	formData	file	true	"file"
+//	@Success		200		{object}	domain.Response{data=domain.NodeAttachmentListItem}
+//	@Router			/api/v1/node/attachment/upload [post]
+func (h *NodeAttachmentHandler) UploadNodeAttachment(c echo.Context) error {
+	kbID := c.FormValue("kb_id")
+	nodeID := c.FormValue("node_id")
+	if kbID == "" || nodeID == "" {
+		return h.NewResponseWithError(c, "kb_id and node_id are required", nil)
+	}
+	file, err := c.FormFile("file")
+	if err != nil {
+		return h.NewResponseWithError(c, "failed to get file", err)
+	}
+	attachment, err := h.usecase.UploadNodeAttachment(c.Request().Context(), kbID, nodeID, file)
+	if err != nil {
+		return h.NewResponseWithError(c, "upload node attachment failed", err)
+	}
+	return h.NewResponseWithData(c, attachment)
+}
+
+// GetNodeAttachmentList get node attachments
+//
+//	@Summary		GetNodeAttachmentList
+//	@Description	attachments of node with signed download urls
+//	@Tags			node_attachment
+//	@Accept			json
+//	@Produce		json
+//	@Param			params	query		domain.NodeAttachmentListReq	true	"params"
+//	@Success		200		{object}	domain.Response{data=[]domain.NodeAttachmentListItem}
+//	@Router			/api/v1/node/attachment/list [get]
+func (h *NodeAttachmentHandler) GetNodeAttachmentList(c echo.Context) error {
+	req := &domain.NodeAttachmentListReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request params is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request params failed", err)
+	}
+	attachments, err := h.usecase.GetNodeAttachmentList(c.Request().Context(), req.KBID, req.NodeID)
+	if err != nil {
+		return h.NewResponseWithError(c, "get node attachment list failed", err)
+	}
+	return h.NewResponseWithData(c, attachments)
+}
+
+// DeleteNodeAttachment delete node attachment
+//
+//	@Summary		DeleteNodeAttachment
+//	@Description	delete node attachment and its stored object
+//	@Tags			node_attachment
+//	@Accept			json
+//	@Produce		json
+//	@Param			params	query		domain.DeleteNodeAttachmentReq	true	"params"
+//	@Success		200		{object}	domain.Response
+//	@Router			/api/v1/node/attachment [delete]
+func (h *NodeAttachmentHandler) DeleteNodeAttachment(c echo.Context) error {
+	req := &domain.DeleteNodeAttachmentReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request params is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request params failed", err)
+	}
+	if err := h.usecase.DeleteNodeAttachment(c.Request().Context(), req); err != nil {
+		return h.NewResponseWithError(c, "delete node attachment failed", err)
+	}
+	return h.NewResponseWithData(c, nil)
+}
@@ -0,0 +1,149 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/handler"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/middleware"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type NodeCommentHandler struct {
+	*handler.BaseHandler
+	usecase *usecase.NodeCommentUsecase
+	logger  *log.Logger
+	auth    middleware.AuthMiddleware
+}
+
+func NewNodeCommentHandler(
+	baseHandler *handler.BaseHandler,
+	echo *echo.Echo,
+	usecase *usecase.NodeCommentUsecase,
+	auth middleware.AuthMiddleware,
+	logger *log.Logger,
+) *NodeCommentHandler {
+	h := &NodeCommentHandler{
+		BaseHandler: baseHandler,
+		usecase:     usecase,
+		logger:      logger.WithModule("handler.v1.node_comment"),
+		auth:        auth,
+	}
+
+	group := echo.Group("/api/v1/node/comment", h.auth.Authorize)
+	group.GET("/list", h.GetNodeCommentList)
+	group.POST("/moderate", h.ModerateNodeComments)
+	group.POST("/reply", h.ReplyNodeComment)
+	group.DELETE("", h.DeleteNodeComment)
+
+	return h
+}
+
+type NodeCommentListItems = domain.PaginatedResult[[]*domain.NodeCommentListItem]
+
+// GetNodeCommentList get node comments
+//
+//	@Summary		GetNodeCommentList
+//	@Description	comments of kb for moderation, filter status=pending for the moderation queue
+//	@Tags			node_comment
+//	@Accept			json
+//	@Produce		json
+//	@Param			params	query		domain.NodeCommentListReq	true	"params"
+//	@Success		200		{object}	domain.Response{data=NodeCommentListItems}
+//	@Router			/api/v1/node/comment/list [get]
+func (h *NodeCommentHandler) GetNodeCommentList(c echo.Context) error {
+	req := &domain.NodeCommentListReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request params is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request params failed", err)
+	}
+	comments, err := h.usecase.GetNodeCommentList(c.Request().Context(), req)
+	if err != nil {
+		return h.NewResponseWithError(c, "get node comment list failed", err)
+	}
+	return h.NewResponseWithData(c, comments)
+}
+
+// ModerateNodeComments approve, reject or mark comments as spam
+//
+//	@Summary		ModerateNodeComments
+//	@Description	approve, reject or mark comments as spam, only approved comments are shown on the site
+//	@Tags			node_comment
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.ModerateNodeCommentsReq	true	"moderate request"
+//	@Success		200		{object}	domain.Response
+//	@Router			/api/v1/node/comment/moderate [post]
+func (h *NodeCommentHandler) ModerateNodeComments(c echo.Context) error {
+	req := &domain.ModerateNodeCommentsReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	userID, ok := h.auth.MustGetUserID(c)
+	if !ok {
+		return h.NewResponseWithError(c, "failed to get user", domain.ErrUnauthorized)
+	}
+	if err := h.usecase.ModerateNodeComments(c.Request().Context(), req, userID); err != nil {
+		return h.NewResponseWithError(c, "moderate node comments failed", err)
+	}
+	return h.NewResponseWithData(c, nil)
+}
+
+// ReplyNodeComment reply to a comment as staff
+//
+//	@Summary		ReplyNodeComment
+//	@Description	reply to an approved comment, shown as staff reply on the site
+//	@Tags			node_comment
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.ReplyNodeCommentReq	true	"reply request"
+//	@Success		200		{object}	domain.Response{data=map[string]string}
+//	@Router			/api/v1/node/comment/reply [post]
+func (h *NodeCommentHandler) ReplyNodeComment(c echo.Context) error {
+	req := &domain.ReplyNodeCommentReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	userID, ok := h.auth.MustGetUserID(c)
+	if !ok {
+		return h.NewResponseWithError(c, "failed to get user", domain.ErrUnauthorized)
+	}
+	id, err := h.usecase.ReplyNodeComment(c.Request().Context(), req, userID)
+	if err != nil {
+		return h.NewResponseWithError(c, "reply node comment failed", err)
+	}
+	return h.NewResponseWithData(c, map[string]string{"id": id})
+}
+
+// DeleteNodeComment delete comment and its replies
+//
+//	@Summary		DeleteNodeComment
+//	@Description	delete comment and its replies
+//	@Tags			node_comment
+//	@Accept			json
+//	@Produce		json
+//	@Param			params	query		domain.DeleteNodeCommentReq	true	"params"
+//	@Success		200		{object}	domain.Response
+//	@Router			/api/v1/node/comment [delete]
+func (h *NodeCommentHandler) DeleteNodeComment(c echo.Context) error {
+	req := &domain.DeleteNodeCommentReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request params is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request params failed", err)
+	}
+	if err := h.usecase.DeleteNodeComment(c.Request().Context(), req); err != nil {
+		return h.NewResponseWithError(c, "delete node comment failed", err)
+	}
+	return h.NewResponseWithData(c, nil)
+}
@@ -0,0 +1,117 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/handler"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/middleware"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type NodeExportHandler struct {
+	*handler.BaseHandler
+	usecase *usecase.NodeExportUsecase
+	logger  *log.Logger
+	auth    middleware.AuthMiddleware
+}
+
+func NewNodeExportHandler(
+	baseHandler *handler.BaseHandler,
+	echo *echo.Echo,
+	usecase *usecase.NodeExportUsecase,
+	auth middleware.AuthMiddleware,
+	logger *log.Logger,
+) *NodeExportHandler {
+	h := &NodeExportHandler{
+		BaseHandler: baseHandler,
+		usecase:     usecase,
+		logger:      logger.WithModule("handler.v1.node_export"),
+		auth:        auth,
+	}
+
+	group := echo.Group("/api/v1/node/export", h.auth.Authorize)
+	group.POST("", h.CreateExportJob)
+	group.GET("/jobs", h.GetExportJobList)
+	group.GET("/job", h.GetExportJob)
+
+	return h
+}
+
+// CreateExportJob export kb to markdown zip archive in background
+//
+//	@Summary		CreateExportJob
+//	@Description	create async job exporting all documents as markdown files with front-matter and their attachments to a zip archive
+//	@Tags			node
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.NodeExportReq	true	"export kb"
+//	@Success		200		{object}	domain.Response{data=domain.NodeExportJob}
+//	@Router			/api/v1/node/export [post]
+func (h *NodeExportHandler) CreateExportJob(c echo.Context) error {
+	req := &domain.NodeExportReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	job, err := h.usecase.CreateJob(c.Request().Context(), req)
+	if err != nil {
+		return h.NewResponseWithError(c, "create export job failed", err)
+	}
+	return h.NewResponseWithData(c, job)
+}
+
+type NodeExportJobListItems = domain.PaginatedResult[[]*domain.NodeExportJob]
+
+// GetExportJobList get export jobs of kb
+//
+//	@Summary		GetExportJobList
+//	@Description	GetExportJobList
+//	@Tags			node
+//	@Accept			json
+//	@Produce		json
+//	@Param			req	query		domain.NodeExportJobListReq	true	"export job list request"
+//	@Success		200	{object}	domain.Response{data=NodeExportJobListItems}
+//	@Router			/api/v1/node/export/jobs [get]
+func (h *NodeExportHandler) GetExportJobList(c echo.Context) error {
+	var req domain.NodeExportJobListReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	jobs, err := h.usecase.GetJobList(c.Request().Context(), &req)
+	if err != nil {
+		return h.NewResponseWithError(c, "get export job list failed", err)
+	}
+	return h.NewResponseWithData(c, jobs)
+}
+
+// GetExportJob get status of export job
+//
+//	@Summary		GetExportJob
+//	@Description	status of export job, with download url of the archive when succeeded
+//	@Tags			node
+//	@Accept			json
+//	@Produce		json
+//	@Param			req	query		domain.NodeExportJobReq	true	"export job request"
+//	@Success		200	{object}	domain.Response{data=domain.NodeExportJob}
+//	@Router			/api/v1/node/export/job [get]
+func (h *NodeExportHandler) GetExportJob(c echo.Context) error {
+	var req domain.NodeExportJobReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	job, err := h.usecase.GetJob(c.Request().Context(), &req)
+	if err != nil {
+		return h.NewResponseWithError(c, "get export job failed", err)
+	}
+	return h.NewResponseWithData(c, job)
+}
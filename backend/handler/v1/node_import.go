@@ -0,0 +1,145 @@
+package v1
+
+import (
+	"mime/multipart"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/handler"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/middleware"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type NodeImportHandler struct {
+	*handler.BaseHandler
+	usecase *usecase.NodeImportUsecase
+	logger  *log.Logger
+	auth    middleware.AuthMiddleware
+}
+
+func NewNodeImportHandler(
+	baseHandler *handler.BaseHandler,
+	echo *echo.Echo,
+	usecase *usecase.NodeImportUsecase,
+	auth middleware.AuthMiddleware,
+	logger *log.Logger,
+) *NodeImportHandler {
+	h := &NodeImportHandler{
+		BaseHandler: baseHandler,
+		usecase:     usecase,
+		logger:      logger.WithModule("handler.v1.node_import"),
+		auth:        auth,
+	}
+
+	group := echo.Group("/api/v1/node/import", h.auth.Authorize)
+	group.POST("/markdown", h.ImportMarkdownArchive)
+	group.POST("/documents", h.ImportDocuments)
+	group.POST("/media", h.ImportMedia)
+
+	return h
+}
+
+// ImportMarkdownArchive import zip of markdown files
+//
+//	@Summary		ImportMarkdownArchive
+//	@Description	import zip of markdown files with optional front-matter, directories become folders, documents at existing paths are skipped unless overwrite, imported documents are published for indexing
+//	@Tags			node
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Param			file		formData	file	true	"zip archive"
+//	@Param			kb_id		formData	string	true	"kb id"
+//	@Param			parent_id	formData	string	false	"folder to import into"
+//	@Param			overwrite	formData	bool	false	"overwrite documents at existing paths"
+//	@Success		200			{object}	domain.Response{data=domain.ImportMarkdownArchiveResp}
+//	@Router			/api/v1/node/import/markdown [post]
+func (h *NodeImportHandler) ImportMarkdownArchive(c echo.Context) error {
+	req := &domain.ImportMarkdownArchiveReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	file, err := c.FormFile("file")
+	if err != nil {
+		return h.NewResponseWithError(c, "get file failed", err)
+	}
+	resp, err := h.usecase.ImportMarkdownArchive(c.Request().Context(), req, file)
+	if err != nil {
+		return h.NewResponseWithError(c, "import markdown archive failed", err)
+	}
+	return h.NewResponseWithData(c, resp)
+}
+
+// ImportDocuments import pdf, word and powerpoint files
+//
+//	@Summary		ImportDocuments
+//	@Description	convert pdf, docx and pptx files to documents by the layout aware parsing service, keeping headings and tables, the original file is added as an attachment of its document. files failing to parse are skipped, imported documents are published for indexing
+//	@Tags			node
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Param			file		formData	file	true	"pdf, docx or pptx files, the field may be repeated"
+//	@Param			kb_id		formData	string	true	"kb id"
+//	@Param			parent_id	formData	string	false	"folder to import into"
+//	@Success		200			{object}	domain.Response{data=domain.ImportDocumentsResp}
+//	@Router			/api/v1/node/import/documents [post]
+func (h *NodeImportHandler) ImportDocuments(c echo.Context) error {
+	req := &domain.ImportDocumentsReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	form, err := c.MultipartForm()
+	if err != nil {
+		return h.NewResponseWithError(c, "get file failed", err)
+	}
+	files := form.File["file"]
+	if len(files) == 0 {
+		return h.NewResponseWithError(c, "file is required", nil)
+	}
+	resp, err := h.usecase.ImportDocuments(c.Request().Context(), req, files)
+	if err != nil {
+		return h.NewResponseWithError(c, "import documents failed", err)
+	}
+	return h.NewResponseWithData(c, resp)
+}
+
+// ImportMedia transcribe audio or video
+//
+//	@Summary		ImportMedia
+//	@Description	transcribe an uploaded or downloaded audio or video file by the asr model and create a transcript document, paragraphs are led by their time offsets linked to the moment in the media so answers can cite them. the transcript is published for indexing
+//	@Tags			node
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Param			file		formData	file	false	"audio or video file, required if url is empty"
+//	@Param			url			formData	string	false	"url of the audio or video to download"
+//	@Param			kb_id		formData	string	true	"kb id"
+//	@Param			parent_id	formData	string	false	"folder to import into"
+//	@Param			name		formData	string	false	"name of the transcript"
+//	@Success		200			{object}	domain.Response{data=domain.ImportMediaResp}
+//	@Router			/api/v1/node/import/media [post]
+func (h *NodeImportHandler) ImportMedia(c echo.Context) error {
+	req := &domain.ImportMediaReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	var file *multipart.FileHeader
+	if req.URL == "" {
+		var err error
+		if file, err = c.FormFile("file"); err != nil {
+			return h.NewResponseWithError(c, "file or url is required", err)
+		}
+	}
+	resp, err := h.usecase.ImportMedia(c.Request().Context(), req, file)
+	if err != nil {
+		return h.NewResponseWithError(c, "import media failed", err)
+	}
+	return h.NewResponseWithData(c, resp)
+}
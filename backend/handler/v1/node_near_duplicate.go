@@ -0,0 +1,114 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/handler"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/middleware"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type NearDuplicateHandler struct {
+	*handler.BaseHandler
+	usecase *usecase.NearDuplicateUsecase
+	logger  *log.Logger
+	auth    middleware.AuthMiddleware
+}
+
+func NewNearDuplicateHandler(
+	baseHandler *handler.BaseHandler,
+	echo *echo.Echo,
+	usecase *usecase.NearDuplicateUsecase,
+	auth middleware.AuthMiddleware,
+	logger *log.Logger,
+) *NearDuplicateHandler {
+	h := &NearDuplicateHandler{
+		BaseHandler: baseHandler,
+		usecase:     usecase,
+		logger:      logger.WithModule("handler.v1.near_duplicate"),
+		auth:        auth,
+	}
+
+	group := echo.Group("/api/v1/node/near_duplicates", h.auth.Authorize)
+	group.GET("", h.GetNearDuplicates)
+	group.POST("/detect", h.DetectNearDuplicates)
+	group.POST("/dismiss", h.DismissNearDuplicate)
+
+	return h
+}
+
+// GetNearDuplicates get near-duplicate documents
+//
+//	@Summary		GetNearDuplicates
+//	@Description	pairs of documents with similar content found by the daily detection job, most similar first, to be merged or deleted
+//	@Tags			node
+//	@Accept			json
+//	@Produce		json
+//	@Param			req	query		domain.NearDuplicateListReq	true	"near-duplicate list request"
+//	@Success		200	{object}	domain.Response{data=[]domain.NearDuplicateResp}
+//	@Router			/api/v1/node/near_duplicates [get]
+func (h *NearDuplicateHandler) GetNearDuplicates(c echo.Context) error {
+	var req domain.NearDuplicateListReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "request params is invalid", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "validate request params failed", err)
+	}
+	pairs, err := h.usecase.GetNearDuplicates(c.Request().Context(), &req)
+	if err != nil {
+		return h.NewResponseWithError(c, "get near-duplicates failed", err)
+	}
+	return h.NewResponseWithData(c, pairs)
+}
+
+// DetectNearDuplicates detect near-duplicate documents
+//
+//	@Summary		DetectNearDuplicates
+//	@Description	compare the documents of kb now instead of waiting for the daily job, returns the pairs found
+//	@Tags			node
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.NearDuplicateDetectReq	true	"near-duplicate detect request"
+//	@Success		200		{object}	domain.Response{data=[]domain.NearDuplicateResp}
+//	@Router			/api/v1/node/near_duplicates/detect [post]
+func (h *NearDuplicateHandler) DetectNearDuplicates(c echo.Context) error {
+	var req domain.NearDuplicateDetectReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	pairs, err := h.usecase.DetectKB(c.Request().Context(), req.KBID)
+	if err != nil {
+		return h.NewResponseWithError(c, "detect near-duplicates failed", err)
+	}
+	return h.NewResponseWithData(c, pairs)
+}
+
+// DismissNearDuplicate dismiss near-duplicate pair
+//
+//	@Summary		DismissNearDuplicate
+//	@Description	keep a pair found not redundant out of the report, it stays dismissed while it is found again
+//	@Tags			node
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.NearDuplicateDismissReq	true	"near-duplicate dismiss request"
+//	@Success		200		{object}	domain.Response
+//	@Router			/api/v1/node/near_duplicates/dismiss [post]
+func (h *NearDuplicateHandler) DismissNearDuplicate(c echo.Context) error {
+	var req domain.NearDuplicateDismissReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	if err := h.usecase.DismissNearDuplicate(c.Request().Context(), &req); err != nil {
+		return h.NewResponseWithError(c, "dismiss near-duplicate failed", err)
+	}
+	return h.NewResponseWithData(c, nil)
+}
@@ -0,0 +1,88 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/handler"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/middleware"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type NodeOwnerHandler struct {
+	*handler.BaseHandler
+	usecase *usecase.NodeOwnerUsecase
+	logger  *log.Logger
+	auth    middleware.AuthMiddleware
+}
+
+func NewNodeOwnerHandler(
+	baseHandler *handler.BaseHandler,
+	echo *echo.Echo,
+	usecase *usecase.NodeOwnerUsecase,
+	auth middleware.AuthMiddleware,
+	logger *log.Logger,
+) *NodeOwnerHandler {
+	h := &NodeOwnerHandler{
+		BaseHandler: baseHandler,
+		usecase:     usecase,
+		logger:      logger.WithModule("handler.v1.node_owner"),
+		auth:        auth,
+	}
+
+	group := echo.Group("/api/v1/node/owner", h.auth.Authorize)
+	group.PUT("", h.SetNodeOwner)
+	group.GET("/overdue", h.GetOverdueNodes)
+
+	return h
+}
+
+// SetNodeOwner set owner of nodes
+//
+//	@Summary		SetNodeOwner
+//	@Description	assign the owner and review interval to the nodes, the owner is reminded when they are due for review. empty owner_id removes the owner
+//	@Tags			node
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.SetNodeOwnerReq	true	"set owner request"
+//	@Success		200		{object}	domain.Response
+//	@Router			/api/v1/node/owner [put]
+func (h *NodeOwnerHandler) SetNodeOwner(c echo.Context) error {
+	var req domain.SetNodeOwnerReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	if err := h.usecase.SetNodeOwner(c.Request().Context(), &req); err != nil {
+		return h.NewResponseWithError(c, "set node owner failed", err)
+	}
+	return h.NewResponseWithData(c, nil)
+}
+
+// GetOverdueNodes get overdue nodes of owners
+//
+//	@Summary		GetOverdueNodes
+//	@Description	owned documents neither updated nor reviewed within their review interval, by owner, most overdue first
+//	@Tags			node
+//	@Accept			json
+//	@Produce		json
+//	@Param			req	query		domain.OverdueNodeListReq	true	"overdue node list request"
+//	@Success		200	{object}	domain.Response{data=[]domain.OwnerOverdueNodes}
+//	@Router			/api/v1/node/owner/overdue [get]
+func (h *NodeOwnerHandler) GetOverdueNodes(c echo.Context) error {
+	var req domain.OverdueNodeListReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "request params is invalid", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "validate request params failed", err)
+	}
+	owners, err := h.usecase.GetOverdueNodes(c.Request().Context(), &req)
+	if err != nil {
+		return h.NewResponseWithError(c, "get overdue nodes failed", err)
+	}
+	return h.NewResponseWithData(c, owners)
+}
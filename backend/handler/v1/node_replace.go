@@ -0,0 +1,169 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/handler"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/middleware"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type NodeReplaceHandler struct {
+	*handler.BaseHandler
+	usecase *usecase.NodeReplaceUsecase
+	logger  *log.Logger
+	auth    middleware.AuthMiddleware
+}
+
+func NewNodeReplaceHandler(
+	baseHandler *handler.BaseHandler,
+	echo *echo.Echo,
+	usecase *usecase.NodeReplaceUsecase,
+	auth middleware.AuthMiddleware,
+	logger *log.Logger,
+) *NodeReplaceHandler {
+	h := &NodeReplaceHandler{
+		BaseHandler: baseHandler,
+		usecase:     usecase,
+		logger:      logger.WithModule("handler.v1.node_replace"),
+		auth:        auth,
+	}
+
+	group := echo.Group("/api/v1/node/replace", h.auth.Authorize)
+	group.POST("/preview", h.PreviewReplace)
+	group.POST("", h.CreateReplaceJob)
+	group.GET("/jobs", h.GetReplaceJobList)
+	group.GET("/job", h.GetReplaceJob)
+	group.POST("/rollback", h.RollbackReplaceJob)
+
+	return h
+}
+
+// PreviewReplace preview nodes affected by find and replace
+//
+//	@Summary		PreviewReplace
+//	@Description	nodes and samples affected by find and replace, nothing is changed
+//	@Tags			node
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.NodeReplaceReq	true	"find and replace"
+//	@Success		200		{object}	domain.Response{data=domain.NodeReplacePreviewResp}
+//	@Router			/api/v1/node/replace/preview [post]
+func (h *NodeReplaceHandler) PreviewReplace(c echo.Context) error {
+	req := &domain.NodeReplaceReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	preview, err := h.usecase.Preview(c.Request().Context(), req)
+	if err != nil {
+		return h.NewResponseWithError(c, "preview replace failed", err)
+	}
+	return h.NewResponseWithData(c, preview)
+}
+
+// CreateReplaceJob find and replace text of documents in background
+//
+//	@Summary		CreateReplaceJob
+//	@Description	create async find and replace job, versions of changed nodes are saved for rollback
+//	@Tags			node
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.NodeReplaceReq	true	"find and replace"
+//	@Success		200		{object}	domain.Response{data=domain.NodeReplaceJob}
+//	@Router			/api/v1/node/replace [post]
+func (h *NodeReplaceHandler) CreateReplaceJob(c echo.Context) error {
+	req := &domain.NodeReplaceReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	job, err := h.usecase.CreateJob(c.Request().Context(), req)
+	if err != nil {
+		return h.NewResponseWithError(c, "create replace job failed", err)
+	}
+	return h.NewResponseWithData(c, job)
+}
+
+type NodeReplaceJobListItems = domain.PaginatedResult[[]*domain.NodeReplaceJob]
+
+// GetReplaceJobList get find and replace jobs of kb
+//
+//	@Summary		GetReplaceJobList
+//	@Description	GetReplaceJobList
+//	@Tags			node
+//	@Accept			json
+//	@Produce		json
+//	@Param			req	query		domain.NodeReplaceJobListReq	true	"replace job list request"
+//	@Success		200	{object}	domain.Response{data=NodeReplaceJobListItems}
+//	@Router			/api/v1/node/replace/jobs [get]
+func (h *NodeReplaceHandler) GetReplaceJobList(c echo.Context) error {
+	var req domain.NodeReplaceJobListReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	jobs, err := h.usecase.GetJobList(c.Request().Context(), &req)
+	if err != nil {
+		return h.NewResponseWithError(c, "get replace job list failed", err)
+	}
+	return h.NewResponseWithData(c, jobs)
+}
+
+// GetReplaceJob get status of find and replace job
+//
+//	@Summary		GetReplaceJob
+//	@Description	GetReplaceJob
+//	@Tags			node
+//	@Accept			json
+//	@Produce		json
+//	@Param			req	query		domain.NodeReplaceJobReq	true	"replace job request"
+//	@Success		200	{object}	domain.Response{data=domain.NodeReplaceJob}
+//	@Router			/api/v1/node/replace/job [get]
+func (h *NodeReplaceHandler) GetReplaceJob(c echo.Context) error {
+	var req domain.NodeReplaceJobReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	job, err := h.usecase.GetJob(c.Request().Context(), &req)
+	if err != nil {
+		return h.NewResponseWithError(c, "get replace job failed", err)
+	}
+	return h.NewResponseWithData(c, job)
+}
+
+// RollbackReplaceJob restore nodes changed by find and replace job
+//
+//	@Summary		RollbackReplaceJob
+//	@Description	restore content of nodes changed by the job, nodes edited after the job are skipped
+//	@Tags			node
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.NodeReplaceJobReq	true	"replace job"
+//	@Success		200		{object}	domain.Response{data=domain.NodeReplaceRollbackResp}
+//	@Router			/api/v1/node/replace/rollback [post]
+func (h *NodeReplaceHandler) RollbackReplaceJob(c echo.Context) error {
+	req := &domain.NodeReplaceJobReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	resp, err := h.usecase.Rollback(c.Request().Context(), req)
+	if err != nil {
+		return h.NewResponseWithError(c, "rollback replace job failed", err)
+	}
+	return h.NewResponseWithData(c, resp)
+}
@@ -0,0 +1,178 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/handler"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/middleware"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type NodeReviewHandler struct {
+	*handler.BaseHandler
+	usecase *usecase.NodeReviewUsecase
+	logger  *log.Logger
+	auth    middleware.AuthMiddleware
+}
+
+func NewNodeReviewHandler(
+	baseHandler *handler.BaseHandler,
+	echo *echo.Echo,
+	usecase *usecase.NodeReviewUsecase,
+	auth middleware.AuthMiddleware,
+	logger *log.Logger,
+) *NodeReviewHandler {
+	h := &NodeReviewHandler{
+		BaseHandler: baseHandler,
+		usecase:     usecase,
+		logger:      logger.WithModule("handler.v1.node_review"),
+		auth:        auth,
+	}
+
+	group := echo.Group("/api/v1/node/review", h.auth.Authorize)
+	group.POST("/submit", h.SubmitNodeReview)
+	group.POST("/approve", h.ApproveNodeReview)
+	group.POST("/reject", h.RejectNodeReview)
+	group.GET("/list", h.GetNodeReviewList)
+	group.GET("/detail", h.GetNodeReview)
+
+	return h
+}
+
+// SubmitNodeReview submit current version of node for review
+//
+//	@Summary		SubmitNodeReview
+//	@Description	submit current version of node for review, earlier pending reviews of the node are cancelled
+//	@Tags			node_review
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.SubmitNodeReviewReq	true	"submit request"
+//	@Success		200		{object}	domain.Response{data=domain.NodeReview}
+//	@Router			/api/v1/node/review/submit [post]
+func (h *NodeReviewHandler) SubmitNodeReview(c echo.Context) error {
+	req := &domain.SubmitNodeReviewReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	userID, ok := h.auth.MustGetUserID(c)
+	if !ok {
+		return h.NewResponseWithError(c, "failed to get user", domain.ErrUnauthorized)
+	}
+	review, err := h.usecase.Submit(c.Request().Context(), req, userID)
+	if err != nil {
+		return h.NewResponseWithError(c, "submit node review failed", err)
+	}
+	return h.NewResponseWithData(c, review)
+}
+
+// ApproveNodeReview approve review and publish the node
+//
+//	@Summary		ApproveNodeReview
+//	@Description	approve review, the reviewed version of node is published and re-indexed
+//	@Tags			node_review
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.ApproveNodeReviewReq	true	"approve request"
+//	@Success		200		{object}	domain.Response
+//	@Router			/api/v1/node/review/approve [post]
+func (h *NodeReviewHandler) ApproveNodeReview(c echo.Context) error {
+	req := &domain.ApproveNodeReviewReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	userID, ok := h.auth.MustGetUserID(c)
+	if !ok {
+		return h.NewResponseWithError(c, "failed to get user", domain.ErrUnauthorized)
+	}
+	if err := h.usecase.Approve(c.Request().Context(), req, userID); err != nil {
+		return h.NewResponseWithError(c, "approve node review failed", err)
+	}
+	return h.NewResponseWithData(c, nil)
+}
+
+// RejectNodeReview reject review with comment
+//
+//	@Summary		RejectNodeReview
+//	@Description	reject review, comment is required
+//	@Tags			node_review
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.RejectNodeReviewReq	true	"reject request"
+//	@Success		200		{object}	domain.Response
+//	@Router			/api/v1/node/review/reject [post]
+func (h *NodeReviewHandler) RejectNodeReview(c echo.Context) error {
+	req := &domain.RejectNodeReviewReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	userID, ok := h.auth.MustGetUserID(c)
+	if !ok {
+		return h.NewResponseWithError(c, "failed to get user", domain.ErrUnauthorized)
+	}
+	if err := h.usecase.Reject(c.Request().Context(), req, userID); err != nil {
+		return h.NewResponseWithError(c, "reject node review failed", err)
+	}
+	return h.NewResponseWithData(c, nil)
+}
+
+type NodeReviewListItems = domain.PaginatedResult[[]*domain.NodeReview]
+
+// GetNodeReviewList get reviews of kb
+//
+//	@Summary		GetNodeReviewList
+//	@Description	reviews of kb, newest first
+//	@Tags			node_review
+//	@Accept			json
+//	@Produce		json
+//	@Param			req	query		domain.NodeReviewListReq	true	"review list request"
+//	@Success		200	{object}	domain.Response{data=NodeReviewListItems}
+//	@Router			/api/v1/node/review/list [get]
+func (h *NodeReviewHandler) GetNodeReviewList(c echo.Context) error {
+	req := &domain.NodeReviewListReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request params is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request params failed", err)
+	}
+	reviews, err := h.usecase.GetReviewList(c.Request().Context(), req)
+	if err != nil {
+		return h.NewResponseWithError(c, "get node review list failed", err)
+	}
+	return h.NewResponseWithData(c, reviews)
+}
+
+// GetNodeReview get review detail
+//
+//	@Summary		GetNodeReview
+//	@Description	review detail
+//	@Tags			node_review
+//	@Accept			json
+//	@Produce		json
+//	@Param			kb_id	query		string	true	"kb id"
+//	@Param			id		query		string	true	"review id"
+//	@Success		200		{object}	domain.Response{data=domain.NodeReview}
+//	@Router			/api/v1/node/review/detail [get]
+func (h *NodeReviewHandler) GetNodeReview(c echo.Context) error {
+	kbID := c.QueryParam("kb_id")
+	id := c.QueryParam("id")
+	if kbID == "" || id == "" {
+		return h.NewResponseWithError(c, "kb_id and id are required", nil)
+	}
+	review, err := h.usecase.GetReview(c.Request().Context(), kbID, id)
+	if err != nil {
+		return h.NewResponseWithError(c, "get node review failed", err)
+	}
+	return h.NewResponseWithData(c, review)
+}
@@ -0,0 +1,187 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/handler"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/middleware"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type NodeTemplateHandler struct {
+	*handler.BaseHandler
+	usecase *usecase.NodeTemplateUsecase
+	logger  *log.Logger
+	auth    middleware.AuthMiddleware
+}
+
+func NewNodeTemplateHandler(
+	baseHandler *handler.BaseHandler,
+	echo *echo.Echo,
+	usecase *usecase.NodeTemplateUsecase,
+	auth middleware.AuthMiddleware,
+	logger *log.Logger,
+) *NodeTemplateHandler {
+	h := &NodeTemplateHandler{
+		BaseHandler: baseHandler,
+		usecase:     usecase,
+		logger:      logger.WithModule("handler.v1.node_template"),
+		auth:        auth,
+	}
+
+	group := echo.Group("/api/v1/node/template", h.auth.Authorize)
+	group.GET("/list", h.GetNodeTemplateList)
+	group.GET("/detail", h.GetNodeTemplate)
+	group.POST("", h.CreateNodeTemplate)
+	group.PUT("", h.UpdateNodeTemplate)
+	group.DELETE("", h.DeleteNodeTemplate)
+	group.POST("/create_node", h.CreateNodeFromTemplate)
+
+	return h
+}
+
+// GetNodeTemplateList get node templates
+//
+//	@Summary		GetNodeTemplateList
+//	@Description	built-in templates followed by templates of kb
+//	@Tags			node_template
+//	@Accept			json
+//	@Produce		json
+//	@Param			kb_id	query		string	true	"kb id"
+//	@Success		200		{object}	domain.Response{data=[]domain.NodeTemplateListItem}
+//	@Router			/api/v1/node/template/list [get]
+func (h *NodeTemplateHandler) GetNodeTemplateList(c echo.Context) error {
+	kbID := c.QueryParam("kb_id")
+	if kbID == "" {
+		return h.NewResponseWithError(c, "kb_id is required", nil)
+	}
+	templates, err := h.usecase.GetNodeTemplateList(c.Request().Context(), kbID)
+	if err != nil {
+		return h.NewResponseWithError(c, "get node template list failed", err)
+	}
+	return h.NewResponseWithData(c, templates)
+}
+
+// GetNodeTemplate get node template detail
+//
+//	@Summary		GetNodeTemplate
+//	@Description	node template detail
+//	@Tags			node_template
+//	@Accept			json
+//	@Produce		json
+//	@Param			kb_id	query		string	true	"kb id"
+//	@Param			id		query		string	true	"template id"
+//	@Success		200		{object}	domain.Response{data=domain.NodeTemplate}
+//	@Router			/api/v1/node/template/detail [get]
+func (h *NodeTemplateHandler) GetNodeTemplate(c echo.Context) error {
+	kbID := c.QueryParam("kb_id")
+	id := c.QueryParam("id")
+	if kbID == "" || id == "" {
+		return h.NewResponseWithError(c, "kb_id and id are required", nil)
+	}
+	template, err := h.usecase.GetNodeTemplate(c.Request().Context(), kbID, id)
+	if err != nil {
+		return h.NewResponseWithError(c, "get node template failed", err)
+	}
+	return h.NewResponseWithData(c, template)
+}
+
+// CreateNodeTemplate create node template
+//
+//	@Summary		CreateNodeTemplate
+//	@Description	create node template, {{name}} placeholders in names and content are filled on use
+//	@Tags			node_template
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.CreateNodeTemplateReq	true	"template"
+//	@Success		200		{object}	domain.Response{data=map[string]string}
+//	@Router			/api/v1/node/template [post]
+func (h *NodeTemplateHandler) CreateNodeTemplate(c echo.Context) error {
+	req := &domain.CreateNodeTemplateReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	id, err := h.usecase.CreateNodeTemplate(c.Request().Context(), req)
+	if err != nil {
+		return h.NewResponseWithError(c, "create node template failed", err)
+	}
+	return h.NewResponseWithData(c, map[string]string{"id": id})
+}
+
+// UpdateNodeTemplate update node template
+//
+//	@Summary		UpdateNodeTemplate
+//	@Description	update node template, built-in templates are read-only
+//	@Tags			node_template
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.UpdateNodeTemplateReq	true	"template"
+//	@Success		200		{object}	domain.Response
+//	@Router			/api/v1/node/template [put]
+func (h *NodeTemplateHandler) UpdateNodeTemplate(c echo.Context) error {
+	req := &domain.UpdateNodeTemplateReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	if err := h.usecase.UpdateNodeTemplate(c.Request().Context(), req); err != nil {
+		return h.NewResponseWithError(c, "update node template failed", err)
+	}
+	return h.NewResponseWithData(c, nil)
+}
+
+// DeleteNodeTemplate delete node template
+//
+//	@Summary		DeleteNodeTemplate
+//	@Description	delete node template, built-in templates are read-only
+//	@Tags			node_template
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.DeleteNodeTemplateReq	true	"template"
+//	@Success		200		{object}	domain.Response
+//	@Router			/api/v1/node/template [delete]
+func (h *NodeTemplateHandler) DeleteNodeTemplate(c echo.Context) error {
+	req := &domain.DeleteNodeTemplateReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	if err := h.usecase.DeleteNodeTemplate(c.Request().Context(), req); err != nil {
+		return h.NewResponseWithError(c, "delete node template failed", err)
+	}
+	return h.NewResponseWithData(c, nil)
+}
+
+// CreateNodeFromTemplate create node from template
+//
+//	@Summary		CreateNodeFromTemplate
+//	@Description	copy structure and placeholder content of template into a new node
+//	@Tags			node_template
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.CreateNodeFromTemplateReq	true	"create request"
+//	@Success		200		{object}	domain.Response{data=map[string]string}
+//	@Router			/api/v1/node/template/create_node [post]
+func (h *NodeTemplateHandler) CreateNodeFromTemplate(c echo.Context) error {
+	req := &domain.CreateNodeFromTemplateReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	id, err := h.usecase.CreateNodeFromTemplate(c.Request().Context(), req)
+	if err != nil {
+		return h.NewResponseWithError(c, "create node from template failed", err)
+	}
+	return h.NewResponseWithData(c, map[string]string{"id": id})
+}
@@ -0,0 +1,122 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/handler"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type OIDCHandler struct {
+	*handler.BaseHandler
+	usecase *usecase.OIDCUsecase
+	logger  *log.Logger
+}
+
+func NewOIDCHandler(e *echo.Echo, baseHandler *handler.BaseHandler, logger *log.Logger, usecase *usecase.OIDCUsecase) *OIDCHandler {
+	h := &OIDCHandler{
+		BaseHandler: baseHandler,
+		usecase:     usecase,
+		logger:      logger.WithModule("handler.v1.oidc"),
+	}
+	// called before login, not authorized
+	group := e.Group("/api/v1/user/oidc")
+	group.GET("/status", h.GetOIDCStatus)
+	group.GET("/login", h.OIDCLogin)
+	group.GET("/callback", h.OIDCCallback)
+
+	return h
+}
+
+// GetOIDCStatus
+//
+//	@Summary		GetOIDCStatus
+//	@Description	whether the login page offers single sign-on and password login
+//	@Tags			user
+//	@Produce		json
+//	@Success		200	{object}	domain.Response{data=domain.OIDCStatusResp}
+//	@Router			/api/v1/user/oidc/status [get]
+func (h *OIDCHandler) GetOIDCStatus(c echo.Context) error {
+	return h.NewResponseWithData(c, h.usecase.Status())
+}
+
+// OIDCLogin
+//
+//	@Summary		OIDCLogin
+//	@Description	redirect to the login page of the oidc provider, which redirects back to the callback
+//	@Tags			user
+//	@Param			req	query	domain.OIDCLoginReq	false	"oidc login request"
+//	@Success		302
+//	@Router			/api/v1/user/oidc/login [get]
+func (h *OIDCHandler) OIDCLogin(c echo.Context) error {
+	var req domain.OIDCLoginReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	loginURL, state, err := h.usecase.LoginURL(c.Request().Context(), &req)
+	if err != nil {
+		return h.NewResponseWithError(c, "failed to start sso login", err)
+	}
+	c.SetCookie(oidcStateCookie(c, state, int(domain.OIDCLoginStateTTL.Seconds())))
+	return c.Redirect(http.StatusFound, loginURL)
+}
+
+// OIDCCallback
+//
+//	@Summary		OIDCCallback
+//	@Description	finish the login with the code of the oidc provider, redirect to the login page of the console with the token, the two-factor challenge or the error in the fragment
+//	@Tags			user
+//	@Param			req	query	domain.OIDCCallbackReq	true	"oidc callback request"
+//	@Success		302
+//	@Router			/api/v1/user/oidc/callback [get]
+func (h *OIDCHandler) OIDCCallback(c echo.Context) error {
+	var req domain.OIDCCallbackReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	browserState := ""
+	if cookie, err := c.Cookie(domain.OIDCStateCookie); err == nil {
+		browserState = cookie.Value
+	}
+	c.SetCookie(oidcStateCookie(c, "", -1))
+	resp, redirect, err := h.usecase.Callback(c.Request().Context(), &req, browserState, sessionClient(c))
+	// the token is passed in the fragment, which is not sent to servers or kept in their logs
+	fragment := url.Values{}
+	if redirect != "" {
+		fragment.Set("redirect", redirect)
+	}
+	if err != nil {
+		h.logger.Error("sso login failed", log.Error(err))
+		message := "sso login failed"
+		var coded *domain.CodedError
+		if errors.As(err, &coded) {
+			message = coded.Message
+		}
+		fragment.Set("error", message)
+	} else if resp.TwoFactor != "" {
+		fragment.Set("two_factor", string(resp.TwoFactor))
+		fragment.Set("challenge_token", resp.ChallengeToken)
+	} else {
+		fragment.Set("token", resp.Token)
+	}
+	return c.Redirect(http.StatusFound, "/login#"+fragment.Encode())
+}
+
+// oidcStateCookie cookie binding the login to the browser, sent back on the top level redirect of the provider only
+func oidcStateCookie(c echo.Context, state string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     domain.OIDCStateCookie,
+		Value:    state,
+		Path:     "/api/v1/user/oidc",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   c.Scheme() == "https",
+		SameSite: http.SameSiteLaxMode,
+	}
+}
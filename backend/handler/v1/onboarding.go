@@ -0,0 +1,86 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/handler"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/middleware"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type OnboardingHandler struct {
+	*handler.BaseHandler
+	usecase *usecase.OnboardingUsecase
+	logger  *log.Logger
+	auth    middleware.AuthMiddleware
+}
+
+func NewOnboardingHandler(
+	baseHandler *handler.BaseHandler,
+	echo *echo.Echo,
+	usecase *usecase.OnboardingUsecase,
+	auth middleware.AuthMiddleware,
+	logger *log.Logger,
+) *OnboardingHandler {
+	h := &OnboardingHandler{
+		BaseHandler: baseHandler,
+		usecase:     usecase,
+		logger:      logger.WithModule("handler.v1.onboarding"),
+		auth:        auth,
+	}
+
+	group := echo.Group("/api/v1/onboarding", h.auth.Authorize)
+	group.POST("/starter_kb", h.CreateStarterKB)
+	group.GET("/checklist", h.GetChecklist)
+
+	return h
+}
+
+// CreateStarterKB create a kb with sample structure and example app
+//
+//	@Summary		CreateStarterKB
+//	@Description	CreateStarterKB
+//	@Tags			onboarding
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.CreateStarterKBReq	true	"CreateStarterKB Request"
+//	@Success		200		{object}	domain.Response{data=domain.CreateStarterKBResp}
+//	@Router			/api/v1/onboarding/starter_kb [post]
+func (h *OnboardingHandler) CreateStarterKB(c echo.Context) error {
+	var req domain.CreateStarterKBReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	resp, err := h.usecase.CreateStarterKB(c.Request().Context(), &req)
+	if err != nil {
+		return h.NewResponseWithError(c, "create starter kb failed", err)
+	}
+	return h.NewResponseWithData(c, resp)
+}
+
+// GetChecklist get setup completeness checklist of kb
+//
+//	@Summary		GetChecklist
+//	@Description	GetChecklist
+//	@Tags			onboarding
+//	@Accept			json
+//	@Produce		json
+//	@Param			kb_id	query		string	true	"kb_id"
+//	@Success		200		{object}	domain.Response{data=domain.OnboardingChecklistResp}
+//	@Router			/api/v1/onboarding/checklist [get]
+func (h *OnboardingHandler) GetChecklist(c echo.Context) error {
+	kbID := c.QueryParam("kb_id")
+	if kbID == "" {
+		return h.NewResponseWithError(c, "kb_id is required", nil)
+	}
+	checklist, err := h.usecase.GetChecklist(c.Request().Context(), kbID)
+	if err != nil {
+		return h.NewResponseWithError(c, "get onboarding checklist failed", err)
+	}
+	return h.NewResponseWithData(c, checklist)
+}
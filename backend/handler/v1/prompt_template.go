@@ -0,0 +1,167 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/handler"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/middleware"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type PromptTemplateHandler struct {
+	*handler.BaseHandler
+	usecase *usecase.PromptTemplateUsecase
+	logger  *log.Logger
+	auth    middleware.AuthMiddleware
+}
+
+func NewPromptTemplateHandler(
+	baseHandler *handler.BaseHandler,
+	echo *echo.Echo,
+	usecase *usecase.PromptTemplateUsecase,
+	auth middleware.AuthMiddleware,
+	logger *log.Logger,
+) *PromptTemplateHandler {
+	h := &PromptTemplateHandler{
+		BaseHandler: baseHandler,
+		usecase:     usecase,
+		logger:      logger.WithModule("handler.v1.prompt_template"),
+		auth:        auth,
+	}
+
+	group := echo.Group("/api/v1/app/prompt", h.auth.Authorize)
+	group.GET("", h.GetAppPrompt)
+	group.GET("/versions", h.GetAppPromptVersions)
+	group.POST("", h.SaveAppPrompt)
+	group.POST("/restore", h.RestoreAppPrompt)
+	group.POST("/preview", h.PreviewAppPrompt)
+
+	return h
+}
+
+// GetAppPrompt get prompt template of app
+//
+//	@Summary		GetAppPrompt
+//	@Description	latest prompt template of the app, the built-in template and the variables available in templates
+//	@Tags			app
+//	@Accept			json
+//	@Produce		json
+//	@Param			app_id	query		string	true	"app id"
+//	@Success		200		{object}	domain.Response{data=domain.GetAppPromptResp}
+//	@Router			/api/v1/app/prompt [get]
+func (h *PromptTemplateHandler) GetAppPrompt(c echo.Context) error {
+	req := &domain.GetAppPromptReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request params is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request params failed", err)
+	}
+	resp, err := h.usecase.GetAppPrompt(c.Request().Context(), req.AppID)
+	if err != nil {
+		return h.NewResponseWithError(c, "get app prompt failed", err)
+	}
+	return h.NewResponseWithData(c, resp)
+}
+
+// GetAppPromptVersions get prompt template versions of app
+//
+//	@Summary		GetAppPromptVersions
+//	@Description	saved prompt template versions of the app, latest first
+//	@Tags			app
+//	@Accept			json
+//	@Produce		json
+//	@Param			app_id	query		string	true	"app id"
+//	@Success		200		{object}	domain.Response{data=[]domain.AppPromptTemplate}
+//	@Router			/api/v1/app/prompt/versions [get]
+func (h *PromptTemplateHandler) GetAppPromptVersions(c echo.Context) error {
+	req := &domain.GetAppPromptReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request params is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request params failed", err)
+	}
+	versions, err := h.usecase.GetAppPromptVersions(c.Request().Context(), req.AppID)
+	if err != nil {
+		return h.NewResponseWithError(c, "get app prompt versions failed", err)
+	}
+	return h.NewResponseWithData(c, versions)
+}
+
+// SaveAppPrompt save prompt template of app
+//
+//	@Summary		SaveAppPrompt
+//	@Description	save the prompt template as a new version of the app, used by its next chats. empty content resets the app to the built-in prompt
+//	@Tags			app
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.SaveAppPromptReq	true	"prompt template"
+//	@Success		200		{object}	domain.Response{data=domain.AppPromptTemplate}
+//	@Router			/api/v1/app/prompt [post]
+func (h *PromptTemplateHandler) SaveAppPrompt(c echo.Context) error {
+	req := &domain.SaveAppPromptReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	template, err := h.usecase.SaveAppPrompt(c.Request().Context(), req)
+	if err != nil {
+		return h.NewResponseWithError(c, "save app prompt failed", err)
+	}
+	return h.NewResponseWithData(c, template)
+}
+
+// RestoreAppPrompt restore prompt template version of app
+//
+//	@Summary		RestoreAppPrompt
+//	@Description	save the content of an earlier version as a new version of the app
+//	@Tags			app
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.RestoreAppPromptReq	true	"version"
+//	@Success		200		{object}	domain.Response{data=domain.AppPromptTemplate}
+//	@Router			/api/v1/app/prompt/restore [post]
+func (h *PromptTemplateHandler) RestoreAppPrompt(c echo.Context) error {
+	req := &domain.RestoreAppPromptReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	template, err := h.usecase.RestoreAppPrompt(c.Request().Context(), req)
+	if err != nil {
+		return h.NewResponseWithError(c, "restore app prompt failed", err)
+	}
+	return h.NewResponseWithData(c, template)
+}
+
+// PreviewAppPrompt preview prompt template of app
+//
+//	@Summary		PreviewAppPrompt
+//	@Description	render the prompt template for the app without saving it, and answer the question with it by the chat model of the kb if given
+//	@Tags			app
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.PreviewAppPromptReq	true	"prompt template"
+//	@Success		200		{object}	domain.Response{data=domain.PreviewAppPromptResp}
+//	@Router			/api/v1/app/prompt/preview [post]
+func (h *PromptTemplateHandler) PreviewAppPrompt(c echo.Context) error {
+	req := &domain.PreviewAppPromptReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	resp, err := h.usecase.PreviewAppPrompt(c.Request().Context(), req)
+	if err != nil {
+		return h.NewResponseWithError(c, "preview app prompt failed", err)
+	}
+	return h.NewResponseWithData(c, resp)
+}
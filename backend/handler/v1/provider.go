@@ -19,6 +19,47 @@ type APIHandlers struct {
 	CrawlerHandler       *CrawlerHandler
 	CreationHandler      *CreationHandler
 	StatHandler          *StatHandler
+	OnboardingHandler    *OnboardingHandler
+	GapReportHandler     *GapReportHandler
+	CronHandler          *CronHandler
+	NodeReplaceHandler   *NodeReplaceHandler
+	MaintenanceHandler   *MaintenanceHandler
+	NodeReviewHandler    *NodeReviewHandler
+	WebhookHandler       *WebhookHandler
+	DigestHandler        *DigestHandler
+	NodeTemplateHandler  *NodeTemplateHandler
+
+	NodeAttachmentHandler      *NodeAttachmentHandler
+	ExternalLinkHandler        *ExternalLinkHandler
+	NodeCommentHandler         *NodeCommentHandler
+	HealthHandler              *HealthHandler
+	IndexIntegrityHandler      *IndexIntegrityHandler
+	NodeExportHandler          *NodeExportHandler
+	BotProfileHandler          *BotProfileHandler
+	NodeImportHandler          *NodeImportHandler
+	ImportSourceHandler        *ImportSourceHandler
+	AnomalyHandler             *AnomalyHandler
+	NearDuplicateHandler       *NearDuplicateHandler
+	RetentionHandler           *RetentionHandler
+	NodeOwnerHandler           *NodeOwnerHandler
+	NodeACLHandler             *NodeACLHandler
+	ReaderHandler              *ReaderHandler
+	DataExportHandler          *DataExportHandler
+	GlossaryHandler            *GlossaryHandler
+	TelemetryHandler           *TelemetryHandler
+	KBMemberHandler            *KBMemberHandler
+	ConversationRescoreHandler *ConversationRescoreHandler
+	OIDCHandler                *OIDCHandler
+	TwoFactorHandler           *TwoFactorHandler
+	APITokenHandler            *APITokenHandler
+	SandboxHandler             *SandboxHandler
+	AuditHandler               *AuditHandler
+	SessionHandler             *SessionHandler
+	IPRuleHandler              *IPRuleHandler
+	PromptTemplateHandler      *PromptTemplateHandler
+	MessageDebugHandler        *MessageDebugHandler
+	EvalHandler                *EvalHandler
+	GuardrailHandler           *GuardrailHandler
 }
 
 var ProviderSet = wire.NewSet(
@@ -36,6 +77,46 @@ var ProviderSet = wire.NewSet(
 	NewCrawlerHandler,
 	NewCreationHandler,
 	NewStatHandler,
+	NewOnboardingHandler,
+	NewGapReportHandler,
+	NewCronHandler,
+	NewNodeReplaceHandler,
+	NewMaintenanceHandler,
+	NewNodeReviewHandler,
+	NewWebhookHandler,
+	NewDigestHandler,
+	NewNodeTemplateHandler,
+	NewNodeAttachmentHandler,
+	NewExternalLinkHandler,
+	NewNodeCommentHandler,
+	NewHealthHandler,
+	NewIndexIntegrityHandler,
+	NewNodeExportHandler,
+	NewBotProfileHandler,
+	NewNodeImportHandler,
+	NewImportSourceHandler,
+	NewAnomalyHandler,
+	NewNearDuplicateHandler,
+	NewRetentionHandler,
+	NewNodeOwnerHandler,
+	NewNodeACLHandler,
+	NewReaderHandler,
+	NewDataExportHandler,
+	NewGlossaryHandler,
+	NewTelemetryHandler,
+	NewKBMemberHandler,
+	NewConversationRescoreHandler,
+	NewOIDCHandler,
+	NewTwoFactorHandler,
+	NewAPITokenHandler,
+	NewSandboxHandler,
+	NewAuditHandler,
+	NewSessionHandler,
+	NewIPRuleHandler,
+	NewPromptTemplateHandler,
+	NewMessageDebugHandler,
+	NewEvalHandler,
+	NewGuardrailHandler,
 
 	wire.Struct(new(APIHandlers), "*"),
 )
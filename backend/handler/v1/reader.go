@@ -0,0 +1,141 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/handler"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/middleware"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type ReaderHandler struct {
+	*handler.BaseHandler
+	usecase *usecase.ReaderUsecase
+	logger  *log.Logger
+	auth    middleware.AuthMiddleware
+}
+
+func NewReaderHandler(
+	baseHandler *handler.BaseHandler,
+	echo *echo.Echo,
+	usecase *usecase.ReaderUsecase,
+	auth middleware.AuthMiddleware,
+	logger *log.Logger,
+) *ReaderHandler {
+	h := &ReaderHandler{
+		BaseHandler: baseHandler,
+		usecase:     usecase,
+		logger:      logger.WithModule("handler.v1.reader"),
+		auth:        auth,
+	}
+
+	group := echo.Group("/api/v1/reader", h.auth.Authorize)
+	group.GET("/list", h.GetReaderList)
+	group.POST("", h.CreateReader)
+	group.PUT("", h.UpdateReader)
+	group.DELETE("", h.DeleteReader)
+
+	return h
+}
+
+type ReaderListItems = domain.PaginatedResult[[]*domain.Reader]
+
+// GetReaderList get readers of kb
+//
+//	@Summary		GetReaderList
+//	@Description	reader accounts of kb, newest first
+//	@Tags			reader
+//	@Accept			json
+//	@Produce		json
+//	@Param			params	query		domain.ReaderListReq	true	"params"
+//	@Success		200		{object}	domain.Response{data=ReaderListItems}
+//	@Router			/api/v1/reader/list [get]
+func (h *ReaderHandler) GetReaderList(c echo.Context) error {
+	req := &domain.ReaderListReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request params is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request params failed", err)
+	}
+	readers, err := h.usecase.GetReaderList(c.Request().Context(), req)
+	if err != nil {
+		return h.NewResponseWithError(c, "get reader list failed", err)
+	}
+	return h.NewResponseWithData(c, readers)
+}
+
+// CreateReader create reader account
+//
+//	@Summary		CreateReader
+//	@Description	create a reader account of kb, readers log in to the public site with email and password
+//	@Tags			reader
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.CreateReaderReq	true	"create reader request"
+//	@Success		200		{object}	domain.Response{data=domain.CreateReaderResp}
+//	@Router			/api/v1/reader [post]
+func (h *ReaderHandler) CreateReader(c echo.Context) error {
+	req := &domain.CreateReaderReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	id, err := h.usecase.CreateReader(c.Request().Context(), req)
+	if err != nil {
+		return h.NewResponseWithError(c, "create reader failed", err)
+	}
+	return h.NewResponseWithData(c, domain.CreateReaderResp{ID: id})
+}
+
+// UpdateReader update reader account
+//
+//	@Summary		UpdateReader
+//	@Description	update name, groups, status and password of a reader, changes apply to tokens already issued
+//	@Tags			reader
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.UpdateReaderReq	true	"update reader request"
+//	@Success		200		{object}	domain.Response
+//	@Router			/api/v1/reader [put]
+func (h *ReaderHandler) UpdateReader(c echo.Context) error {
+	req := &domain.UpdateReaderReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	if err := h.usecase.UpdateReader(c.Request().Context(), req); err != nil {
+		return h.NewResponseWithError(c, "update reader failed", err)
+	}
+	return h.NewResponseWithData(c, nil)
+}
+
+// DeleteReader delete reader account
+//
+//	@Summary		DeleteReader
+//	@Description	delete reader account, its tokens are rejected afterwards
+//	@Tags			reader
+//	@Accept			json
+//	@Produce		json
+//	@Param			params	query		domain.DeleteReaderReq	true	"params"
+//	@Success		200		{object}	domain.Response
+//	@Router			/api/v1/reader [delete]
+func (h *ReaderHandler) DeleteReader(c echo.Context) error {
+	req := &domain.DeleteReaderReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request params is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request params failed", err)
+	}
+	if err := h.usecase.DeleteReader(c.Request().Context(), req); err != nil {
+		return h.NewResponseWithError(c, "delete reader failed", err)
+	}
+	return h.NewResponseWithData(c, nil)
+}
@@ -0,0 +1,80 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/handler"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/middleware"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type RetentionHandler struct {
+	*handler.BaseHandler
+	usecase *usecase.RetentionUsecase
+	logger  *log.Logger
+	auth    middleware.AuthMiddleware
+}
+
+func NewRetentionHandler(
+	baseHandler *handler.BaseHandler,
+	echo *echo.Echo,
+	usecase *usecase.RetentionUsecase,
+	auth middleware.AuthMiddleware,
+	logger *log.Logger,
+) *RetentionHandler {
+	h := &RetentionHandler{
+		BaseHandler: baseHandler,
+		usecase:     usecase,
+		logger:      logger.WithModule("handler.v1.retention"),
+		auth:        auth,
+	}
+
+	group := echo.Group("/api/v1/retention", h.auth.Authorize)
+	group.GET("", h.GetRetention)
+	group.PUT("", h.UpdateRetention)
+
+	return h
+}
+
+// GetRetention get data retention settings
+//
+//	@Summary		GetRetention
+//	@Description	days conversations, messages, stat events, job runs, audit logs, guardrail events, anomalies and revoked sessions are kept, 0 keeps them forever
+//	@Tags			retention
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	domain.Response{data=domain.RetentionSettings}
+//	@Router			/api/v1/retention [get]
+func (h *RetentionHandler) GetRetention(c echo.Context) error {
+	settings, err := h.usecase.GetRetention(c.Request().Context())
+	if err != nil {
+		return h.NewResponseWithError(c, "get retention settings failed", err)
+	}
+	return h.NewResponseWithData(c, settings)
+}
+
+// UpdateRetention update data retention settings
+//
+//	@Summary		UpdateRetention
+//	@Description	data older than its retention is removed by the hourly retention job
+//	@Tags			retention
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.RetentionSettings	true	"retention settings"
+//	@Success		200		{object}	domain.Response
+//	@Router			/api/v1/retention [put]
+func (h *RetentionHandler) UpdateRetention(c echo.Context) error {
+	req := &domain.RetentionSettings{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	if err := h.usecase.UpdateRetention(c.Request().Context(), req); err != nil {
+		return h.NewResponseWithError(c, "update retention settings failed", err)
+	}
+	return h.NewResponseWithData(c, nil)
+}
@@ -0,0 +1,187 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/handler"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/middleware"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+// SandboxHandler apis of ci pipelines, authorized by api tokens of the sandbox scope instead of console logins
+type SandboxHandler struct {
+	*handler.BaseHandler
+	usecase *usecase.SandboxUsecase
+	logger  *log.Logger
+}
+
+func NewSandboxHandler(
+	baseHandler *handler.BaseHandler,
+	echo *echo.Echo,
+	usecase *usecase.SandboxUsecase,
+	apiToken *middleware.APITokenMiddleware,
+	logger *log.Logger,
+) *SandboxHandler {
+	h := &SandboxHandler{
+		BaseHandler: baseHandler,
+		usecase:     usecase,
+		logger:      logger.WithModule("handler.v1.sandbox"),
+	}
+
+	group := echo.Group("/api/v1/sandbox", apiToken.Authorize(domain.APITokenScopeSandbox))
+	group.POST("/kb", h.CreateSandbox)
+	group.GET("/kb/list", h.GetSandboxList)
+	group.DELETE("/kb", h.DeleteSandbox)
+	group.POST("/nodes", h.PushSandboxNodes)
+	group.GET("/status", h.GetSandboxStatus)
+	group.POST("/eval", h.EvalSandbox)
+
+	return h
+}
+
+func (h *SandboxHandler) apiToken(c echo.Context) *domain.APIToken {
+	return c.Get(domain.ContextKeyAPIToken).(*domain.APIToken)
+}
+
+// CreateSandbox create sandbox kb
+//
+//	@Summary		CreateSandbox
+//	@Description	create temporary kb of the api token, torn down when it expires if not deleted
+//	@Tags			sandbox
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.CreateSandboxReq	true	"create sandbox request"
+//	@Success		200		{object}	domain.Response{data=domain.SandboxKB}
+//	@Router			/api/v1/sandbox/kb [post]
+func (h *SandboxHandler) CreateSandbox(c echo.Context) error {
+	req := &domain.CreateSandboxReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	sandbox, err := h.usecase.CreateSandbox(c.Request().Context(), h.apiToken(c), req)
+	if err != nil {
+		return h.NewResponseWithError(c, "create sandbox failed", err)
+	}
+	return h.NewResponseWithData(c, sandbox)
+}
+
+// GetSandboxList get sandbox kbs of the api token
+//
+//	@Summary		GetSandboxList
+//	@Description	GetSandboxList
+//	@Tags			sandbox
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	domain.Response{data=[]domain.SandboxKB}
+//	@Router			/api/v1/sandbox/kb/list [get]
+func (h *SandboxHandler) GetSandboxList(c echo.Context) error {
+	sandboxes, err := h.usecase.GetSandboxList(c.Request().Context(), h.apiToken(c))
+	if err != nil {
+		return h.NewResponseWithError(c, "get sandbox list failed", err)
+	}
+	return h.NewResponseWithData(c, sandboxes)
+}
+
+// DeleteSandbox tear down sandbox kb
+//
+//	@Summary		DeleteSandbox
+//	@Description	DeleteSandbox
+//	@Tags			sandbox
+//	@Accept			json
+//	@Produce		json
+//	@Param			kb_id	query		string	true	"sandbox kb id"
+//	@Success		200		{object}	domain.Response
+//	@Router			/api/v1/sandbox/kb [delete]
+func (h *SandboxHandler) DeleteSandbox(c echo.Context) error {
+	var req domain.SandboxKBReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	if err := h.usecase.DeleteSandbox(c.Request().Context(), h.apiToken(c), &req); err != nil {
+		return h.NewResponseWithError(c, "delete sandbox failed", err)
+	}
+	return h.NewResponseWithData(c, nil)
+}
+
+// PushSandboxNodes push documents to sandbox kb
+//
+//	@Summary		PushSandboxNodes
+//	@Description	create documents in the sandbox kb and publish them, poll the status until they are indexed
+//	@Tags			sandbox
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.PushSandboxNodesReq	true	"push sandbox nodes request"
+//	@Success		200		{object}	domain.Response{data=domain.PushSandboxNodesResp}
+//	@Router			/api/v1/sandbox/nodes [post]
+func (h *SandboxHandler) PushSandboxNodes(c echo.Context) error {
+	req := &domain.PushSandboxNodesReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	resp, err := h.usecase.PushNodes(c.Request().Context(), h.apiToken(c), req)
+	if err != nil {
+		return h.NewResponseWithError(c, "push sandbox nodes failed", err)
+	}
+	return h.NewResponseWithData(c, resp)
+}
+
+// GetSandboxStatus get index status of sandbox kb
+//
+//	@Summary		GetSandboxStatus
+//	@Description	how many published documents of the sandbox kb are indexed, ready when all are
+//	@Tags			sandbox
+//	@Accept			json
+//	@Produce		json
+//	@Param			kb_id	query		string	true	"sandbox kb id"
+//	@Success		200		{object}	domain.Response{data=domain.SandboxStatusResp}
+//	@Router			/api/v1/sandbox/status [get]
+func (h *SandboxHandler) GetSandboxStatus(c echo.Context) error {
+	var req domain.SandboxKBReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	status, err := h.usecase.GetStatus(c.Request().Context(), h.apiToken(c), &req)
+	if err != nil {
+		return h.NewResponseWithError(c, "get sandbox status failed", err)
+	}
+	return h.NewResponseWithData(c, status)
+}
+
+// EvalSandbox evaluate questions against sandbox kb
+//
+//	@Summary		EvalSandbox
+//	@Description	answer the questions from the sandbox kb as chat would with its chat model and the built-in prompt, check the retrieved documents against the expected documents and confidence and score the answers with the model as judge, passed tells the pipeline whether to fail
+//	@Tags			sandbox
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.SandboxEvalReq	true	"sandbox eval request"
+//	@Success		200		{object}	domain.Response{data=domain.SandboxEvalResp}
+//	@Router			/api/v1/sandbox/eval [post]
+func (h *SandboxHandler) EvalSandbox(c echo.Context) error {
+	req := &domain.SandboxEvalReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	resp, err := h.usecase.Eval(c.Request().Context(), h.apiToken(c), req)
+	if err != nil {
+		return h.NewResponseWithError(c, "eval sandbox failed", err)
+	}
+	return h.NewResponseWithData(c, resp)
+}
@@ -0,0 +1,131 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/handler"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/middleware"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type SessionHandler struct {
+	*handler.BaseHandler
+	usecase *usecase.SessionUsecase
+	logger  *log.Logger
+	auth    middleware.AuthMiddleware
+}
+
+func NewSessionHandler(
+	baseHandler *handler.BaseHandler,
+	echo *echo.Echo,
+	usecase *usecase.SessionUsecase,
+	auth middleware.AuthMiddleware,
+	logger *log.Logger,
+) *SessionHandler {
+	h := &SessionHandler{
+		BaseHandler: baseHandler,
+		usecase:     usecase,
+		logger:      logger.WithModule("handler.v1.session"),
+		auth:        auth,
+	}
+
+	group := echo.Group("/api/v1/user/session", h.auth.Authorize)
+	group.GET("/list", h.GetSessionList)
+	group.DELETE("", h.RevokeSession)
+	group.POST("/revoke_all", h.RevokeUserSessions)
+
+	return h
+}
+
+// sessionClient client of the login request, recorded on its session
+func sessionClient(c echo.Context) *domain.SessionClient {
+	return &domain.SessionClient{
+		RemoteIP:  c.RealIP(),
+		UserAgent: c.Request().UserAgent(),
+	}
+}
+
+// GetSessionList
+//
+//	@Summary		GetSessionList
+//	@Description	active console sessions with their device and ip, admins get those of every user, members only get their own
+//	@Tags			user
+//	@Accept			json
+//	@Produce		json
+//	@Param			req	query		domain.SessionListReq	false	"session list request"
+//	@Success		200	{object}	domain.Response{data=[]domain.SessionListItem}
+//	@Router			/api/v1/user/session/list [get]
+func (h *SessionHandler) GetSessionList(c echo.Context) error {
+	var req domain.SessionListReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	userID, ok := h.auth.MustGetUserID(c)
+	if !ok {
+		return h.NewResponseWithError(c, "failed to get user", domain.ErrUnauthorized)
+	}
+	currentID, _ := c.Get(domain.ContextKeySessionID).(string)
+	sessions, err := h.usecase.GetSessions(c.Request().Context(), &req, userID, currentID)
+	if err != nil {
+		return h.NewResponseWithError(c, "failed to get sessions", err)
+	}
+	return h.NewResponseWithData(c, sessions)
+}
+
+// RevokeSession
+//
+//	@Summary		RevokeSession
+//	@Description	log out the session at once, members may only revoke their own sessions
+//	@Tags			user
+//	@Accept			json
+//	@Produce		json
+//	@Param			req	query		domain.RevokeSessionReq	true	"revoke session request"
+//	@Success		200	{object}	domain.Response
+//	@Router			/api/v1/user/session [delete]
+func (h *SessionHandler) RevokeSession(c echo.Context) error {
+	var req domain.RevokeSessionReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	userID, ok := h.auth.MustGetUserID(c)
+	if !ok {
+		return h.NewResponseWithError(c, "failed to get user", domain.ErrUnauthorized)
+	}
+	if err := h.usecase.RevokeSession(c.Request().Context(), &req, userID); err != nil {
+		return h.NewResponseWithError(c, "failed to revoke session", err)
+	}
+	return h.NewResponseWithData(c, nil)
+}
+
+// RevokeUserSessions
+//
+//	@Summary		RevokeUserSessions
+//	@Description	log out every session of the user at once, like after its credentials leaked, members may only revoke their own sessions
+//	@Tags			user
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.RevokeUserSessionsReq	true	"revoke user sessions request"
+//	@Success		200		{object}	domain.Response
+//	@Router			/api/v1/user/session/revoke_all [post]
+func (h *SessionHandler) RevokeUserSessions(c echo.Context) error {
+	var req domain.RevokeUserSessionsReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	userID, ok := h.auth.MustGetUserID(c)
+	if !ok {
+		return h.NewResponseWithError(c, "failed to get user", domain.ErrUnauthorized)
+	}
+	if err := h.usecase.RevokeUserSessions(c.Request().Context(), &req, userID); err != nil {
+		return h.NewResponseWithError(c, "failed to revoke sessions", err)
+	}
+	return h.NewResponseWithData(c, nil)
+}
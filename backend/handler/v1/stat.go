@@ -1,27 +1,37 @@
 package v1
 
 import (
+	"encoding/json"
+	"fmt"
+	"time"
+
 	"github.com/labstack/echo/v4"
 
+	"github.com/chaitin/panda-wiki/domain"
 	"github.com/chaitin/panda-wiki/handler"
 	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/middleware"
 	"github.com/chaitin/panda-wiki/usecase"
 )
 
 type StatHandler struct {
 	*handler.BaseHandler
-	usecase *usecase.StatUseCase
-	logger  *log.Logger
+	usecase                *usecase.StatUseCase
+	questionClusterUsecase *usecase.QuestionClusterUsecase
+	logger                 *log.Logger
+	auth                   middleware.AuthMiddleware
 }
 
-func NewStatHandler(baseHandler *handler.BaseHandler, echo *echo.Echo, usecase *usecase.StatUseCase, logger *log.Logger) *StatHandler {
+func NewStatHandler(baseHandler *handler.BaseHandler, echo *echo.Echo, usecase *usecase.StatUseCase, questionClusterUsecase *usecase.QuestionClusterUsecase, auth middleware.AuthMiddleware, logger *log.Logger) *StatHandler {
 	h := &StatHandler{
-		BaseHandler: baseHandler,
-		usecase:     usecase,
-		logger:      logger.WithModule("handler.v1.stat"),
+		BaseHandler:            baseHandler,
+		usecase:                usecase,
+		questionClusterUsecase: questionClusterUsecase,
+		logger:                 logger.WithModule("handler.v1.stat"),
+		auth:                   auth,
 	}
 
-	group := echo.Group("/api/v1/stat")
+	group := echo.Group("/api/v1/stat", h.auth.Authorize)
 	group.GET("/hot_pages", h.GetHotPages)
 	group.GET("/referer_hosts", h.GetRefererHosts)
 	group.GET("/browsers", h.GetBrowsers)
@@ -32,8 +42,24 @@ func NewStatHandler(baseHandler *handler.BaseHandler, echo *echo.Echo, usecase *
 	group.GET("/instant_pages", h.GetInstantPages)
 	// geo (24h)
 	group.GET("/geo_count", h.GetGeoCount)
+	// geo tree of country > province > city (default 24h, optional app)
+	group.GET("/geo", h.GetGeoStat)
 	// conversation (24h)
 	group.GET("/conversation_distribution", h.GetConversationDistribution)
+	// realtime (push every 5s via SSE)
+	group.GET("/realtime", h.GetRealtimeStat)
+	// per node views, visitors, dwell time and citations (default 7d)
+	group.GET("/nodes", h.GetNodeStats)
+	// funnel of visit -> search -> chat -> resolution (default 7d)
+	group.GET("/funnel", h.GetFunnelStat)
+	// answered and low confidence answers per day (default 7d)
+	group.GET("/answer_confidence", h.GetAnswerConfidenceStat)
+	// traffic sources by referer host or utm parameter (24h)
+	group.GET("/traffic_sources", h.GetTrafficSources)
+	// top question clusters (default 24h)
+	group.GET("/question_clusters", h.GetQuestionClusters)
+	// device, browser and os of widget conversations
+	group.GET("/platforms", h.GetPlatformStat)
 	return h
 }
 
@@ -212,3 +238,213 @@ func (h *StatHandler) GetConversationDistribution(c echo.Context) error {
 	}
 	return h.NewResponseWithData(c, distribution)
 }
+
+// GetRealtimeStat push realtime stat via SSE
+//
+//	@Summary		GetRealtimeStat
+//	@Description	push active conversations, requests per minute and geo breakdown every 5 seconds
+//	@Tags			stat
+//	@Accept			json
+//	@Produce		text/event-stream
+//	@Param			kb_id	query		string	true	"kb_id"
+//	@Success		200		{object}	domain.StatRealtimeResp
+//	@Router			/api/v1/stat/realtime [get]
+func (h *StatHandler) GetRealtimeStat(c echo.Context) error {
+	kbID := c.QueryParam("kb_id")
+	if kbID == "" {
+		return h.NewResponseWithError(c, "kb_id is required", nil)
+	}
+
+	c.Response().Header().Set("Content-Type", "text/event-stream")
+	c.Response().Header().Set("Cache-Control", "no-cache")
+	c.Response().Header().Set("Connection", "keep-alive")
+	c.Response().Header().Set("Transfer-Encoding", "chunked")
+
+	statCh := h.usecase.SubscribeRealtimeStat(c.Request().Context(), kbID, 5*time.Second)
+	for stat := range statCh {
+		jsonContent, err := json.Marshal(stat)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(c.Response(), "data: %s\n\n", jsonContent); err != nil {
+			return err
+		}
+		c.Response().Flush()
+	}
+	return nil
+}
+
+// GetQuestionClusters get top question clusters
+//
+//	@Summary		GetQuestionClusters
+//	@Description	get top question clusters of kb in time range
+//	@Tags			stat
+//	@Accept			json
+//	@Produce		json
+//	@Param			params	query		domain.GetQuestionClustersReq	true	"params"
+//	@Success		200		{object}	domain.Response{data=[]domain.QuestionClusterResp}
+//	@Router			/api/v1/stat/question_clusters [get]
+func (h *StatHandler) GetQuestionClusters(c echo.Context) error {
+	var req domain.GetQuestionClustersReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "request params is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request params failed", err)
+	}
+	clusters, err := h.questionClusterUsecase.GetTopQuestionClusters(c.Request().Context(), &req)
+	if err != nil {
+		return h.NewResponseWithError(c, "get question clusters failed", err)
+	}
+	return h.NewResponseWithData(c, clusters)
+}
+
+// GetTrafficSources get page visits or conversations grouped by referer host or utm parameter
+//
+//	@Summary		GetTrafficSources
+//	@Description	get page visits or conversations of last 24 hours grouped by referer host or utm parameter
+//	@Tags			stat
+//	@Accept			json
+//	@Produce		json
+//	@Param			params	query		domain.GetTrafficSourcesReq	true	"params"
+//	@Success		200		{object}	domain.Response{data=[]domain.TrafficSourceCount}
+//	@Router			/api/v1/stat/traffic_sources [get]
+func (h *StatHandler) GetTrafficSources(c echo.Context) error {
+	var req domain.GetTrafficSourcesReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "request params is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request params failed", err)
+	}
+	sources, err := h.usecase.GetTrafficSources(c.Request().Context(), &req)
+	if err != nil {
+		return h.NewResponseWithError(c, "get traffic sources failed", err)
+	}
+	return h.NewResponseWithData(c, sources)
+}
+
+// GetNodeStats get per node analytics
+//
+//	@Summary		GetNodeStats
+//	@Description	views, unique visitors, average dwell time and rag citations of nodes
+//	@Tags			stat
+//	@Accept			json
+//	@Produce		json
+//	@Param			params	query		domain.GetNodeStatsReq	true	"params"
+//	@Success		200		{object}	domain.Response{data=[]domain.NodeStatResp}
+//	@Router			/api/v1/stat/nodes [get]
+func (h *StatHandler) GetNodeStats(c echo.Context) error {
+	var req domain.GetNodeStatsReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "request params is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request params failed", err)
+	}
+	stats, err := h.usecase.GetNodeStats(c.Request().Context(), &req)
+	if err != nil {
+		return h.NewResponseWithError(c, "get node stats failed", err)
+	}
+	return h.NewResponseWithData(c, stats)
+}
+
+// GetGeoStat get visitor locations as country > province > city tree
+//
+//	@Summary		GetGeoStat
+//	@Description	visitor locations in time range, grouped as country > province > city name/value tree for map charts
+//	@Tags			stat
+//	@Accept			json
+//	@Produce		json
+//	@Param			params	query		domain.GetGeoStatReq	true	"params"
+//	@Success		200		{object}	domain.Response{data=domain.GeoStatResp}
+//	@Router			/api/v1/stat/geo [get]
+func (h *StatHandler) GetGeoStat(c echo.Context) error {
+	var req domain.GetGeoStatReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "request params is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request params failed", err)
+	}
+	stat, err := h.usecase.GetGeoStat(c.Request().Context(), &req)
+	if err != nil {
+		return h.NewResponseWithError(c, "get geo stat failed", err)
+	}
+	return h.NewResponseWithData(c, stat)
+}
+
+// GetFunnelStat get conversion rates of visit -> search -> chat -> resolution
+//
+//	@Summary		GetFunnelStat
+//	@Description	sessions and conversion rates of each funnel step
+//	@Tags			stat
+//	@Accept			json
+//	@Produce		json
+//	@Param			params	query		domain.GetFunnelStatReq	true	"params"
+//	@Success		200		{object}	domain.Response{data=[]domain.FunnelStepStat}
+//	@Router			/api/v1/stat/funnel [get]
+func (h *StatHandler) GetFunnelStat(c echo.Context) error {
+	var req domain.GetFunnelStatReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "request params is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request params failed", err)
+	}
+	stats, err := h.usecase.GetFunnelStat(c.Request().Context(), &req)
+	if err != nil {
+		return h.NewResponseWithError(c, "get funnel stat failed", err)
+	}
+	return h.NewResponseWithData(c, stats)
+}
+
+// GetAnswerConfidenceStat get count of low confidence answers
+//
+//	@Summary		GetAnswerConfidenceStat
+//	@Description	daily count of answers and low confidence answers replied with reference links only
+//	@Tags			stat
+//	@Accept			json
+//	@Produce		json
+//	@Param			params	query		domain.GetAnswerConfidenceStatReq	true	"params"
+//	@Success		200		{object}	domain.Response{data=domain.AnswerConfidenceStatResp}
+//	@Router			/api/v1/stat/answer_confidence [get]
+func (h *StatHandler) GetAnswerConfidenceStat(c echo.Context) error {
+	var req domain.GetAnswerConfidenceStatReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "request params is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request params failed", err)
+	}
+	stat, err := h.usecase.GetAnswerConfidenceStat(c.Request().Context(), &req)
+	if err != nil {
+		return h.NewResponseWithError(c, "get answer confidence stat failed", err)
+	}
+	return h.NewResponseWithData(c, stat)
+}
+
+// GetPlatformStat get conversations grouped by device, browser and os
+//
+//	@Summary		GetPlatformStat
+//	@Description	device, browser and os breakdown of conversations, widget conversations of last 7 days by default
+//	@Tags			stat
+//	@Accept			json
+//	@Produce		json
+//	@Param			params	query		domain.GetPlatformStatReq	true	"params"
+//	@Success		200		{object}	domain.Response{data=domain.PlatformStatResp}
+//	@Router			/api/v1/stat/platforms [get]
+func (h *StatHandler) GetPlatformStat(c echo.Context) error {
+	var req domain.GetPlatformStatReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "request params is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request params failed", err)
+	}
+	stat, err := h.usecase.GetPlatformStat(c.Request().Context(), &req)
+	if err != nil {
+		return h.NewResponseWithError(c, "get platform stat failed", err)
+	}
+	return h.NewResponseWithData(c, stat)
+}
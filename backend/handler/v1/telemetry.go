@@ -0,0 +1,99 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/handler"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/middleware"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type TelemetryHandler struct {
+	*handler.BaseHandler
+	usecase *usecase.TelemetryUsecase
+	logger  *log.Logger
+	auth    middleware.AuthMiddleware
+}
+
+func NewTelemetryHandler(
+	baseHandler *handler.BaseHandler,
+	echo *echo.Echo,
+	usecase *usecase.TelemetryUsecase,
+	auth middleware.AuthMiddleware,
+	telemetry *middleware.TelemetryMiddleware,
+	logger *log.Logger,
+) *TelemetryHandler {
+	h := &TelemetryHandler{
+		BaseHandler: baseHandler,
+		usecase:     usecase,
+		logger:      logger.WithModule("handler.v1.telemetry"),
+		auth:        auth,
+	}
+
+	// count usage of api features, kept only if telemetry is enabled
+	echo.Use(telemetry.Count)
+
+	group := echo.Group("/api/v1/telemetry", h.auth.Authorize)
+	group.GET("", h.GetTelemetrySettings)
+	group.PUT("", h.UpdateTelemetrySettings)
+	group.GET("/report", h.GetTelemetryReport)
+
+	return h
+}
+
+// GetTelemetrySettings get telemetry settings
+//
+//	@Summary		GetTelemetrySettings
+//	@Description	whether anonymous usage is aggregated locally and reported upstream, both are off until enabled
+//	@Tags			telemetry
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	domain.Response{data=domain.TelemetrySettingsResp}
+//	@Router			/api/v1/telemetry [get]
+func (h *TelemetryHandler) GetTelemetrySettings(c echo.Context) error {
+	settings, err := h.usecase.GetSettings(c.Request().Context())
+	if err != nil {
+		return h.NewResponseWithError(c, "get telemetry settings failed", err)
+	}
+	return h.NewResponseWithData(c, settings)
+}
+
+// UpdateTelemetrySettings update telemetry settings
+//
+//	@Summary		UpdateTelemetrySettings
+//	@Description	reporting upstream requires telemetry enabled, usage aggregated so far is removed when telemetry is disabled
+//	@Tags			telemetry
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.UpdateTelemetrySettingsReq	true	"telemetry settings"
+//	@Success		200		{object}	domain.Response
+//	@Router			/api/v1/telemetry [put]
+func (h *TelemetryHandler) UpdateTelemetrySettings(c echo.Context) error {
+	req := &domain.UpdateTelemetrySettingsReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := h.usecase.UpdateSettings(c.Request().Context(), req); err != nil {
+		return h.NewResponseWithError(c, "update telemetry settings failed", err)
+	}
+	return h.NewResponseWithData(c, nil)
+}
+
+// GetTelemetryReport get telemetry report
+//
+//	@Summary		GetTelemetryReport
+//	@Description	anonymous feature usage, error rates and size of the deployment over the last 7 days, exactly what is sent upstream when reporting is enabled
+//	@Tags			telemetry
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	domain.Response{data=domain.TelemetryReport}
+//	@Router			/api/v1/telemetry/report [get]
+func (h *TelemetryHandler) GetTelemetryReport(c echo.Context) error {
+	report, err := h.usecase.GetReport(c.Request().Context())
+	if err != nil {
+		return h.NewResponseWithError(c, "get telemetry report failed", err)
+	}
+	return h.NewResponseWithData(c, report)
+}
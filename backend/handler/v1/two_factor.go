@@ -0,0 +1,272 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/handler"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/middleware"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type TwoFactorHandler struct {
+	*handler.BaseHandler
+	usecase     *usecase.TwoFactorUsecase
+	userUsecase *usecase.UserUsecase
+	logger      *log.Logger
+	auth        middleware.AuthMiddleware
+}
+
+func NewTwoFactorHandler(e *echo.Echo, baseHandler *handler.BaseHandler, logger *log.Logger, usecase *usecase.TwoFactorUsecase, userUsecase *usecase.UserUsecase, auth middleware.AuthMiddleware) *TwoFactorHandler {
+	h := &TwoFactorHandler{
+		BaseHandler: baseHandler,
+		usecase:     usecase,
+		userUsecase: userUsecase,
+		logger:      logger.WithModule("handler.v1.two_factor"),
+		auth:        auth,
+	}
+	group := e.Group("/api/v1/user/2fa")
+	// second step of login with the challenge token, not authorized
+	group.POST("/login", h.TwoFactorLogin)
+	group.POST("/login/enroll", h.TwoFactorLoginEnroll)
+	group.POST("/login/confirm", h.TwoFactorLoginConfirm)
+
+	group.GET("", h.GetTwoFactorStatus, h.auth.Authorize)
+	group.POST("/enroll", h.EnrollTwoFactor, h.auth.Authorize)
+	group.POST("/confirm", h.ConfirmTwoFactor, h.auth.Authorize)
+	group.POST("/backup_codes", h.RegenerateBackupCodes, h.auth.Authorize)
+	group.DELETE("", h.DisableTwoFactor, h.auth.Authorize)
+	group.DELETE("/reset", h.ResetTwoFactor, h.auth.Authorize)
+
+	return h
+}
+
+// TwoFactorLogin
+//
+//	@Summary		TwoFactorLogin
+//	@Description	console token after the password, with a code of the authenticator app or a backup code
+//	@Tags			user
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.TwoFactorLoginReq	true	"two-factor login request"
+//	@Success		200		{object}	domain.Response{data=domain.LoginResp}
+//	@Router			/api/v1/user/2fa/login [post]
+func (h *TwoFactorHandler) TwoFactorLogin(c echo.Context) error {
+	var req domain.TwoFactorLoginReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	resp, err := h.userUsecase.VerifyTwoFactorLogin(c.Request().Context(), &req, sessionClient(c))
+	if err != nil {
+		return h.NewResponseWithError(c, "failed to login", err)
+	}
+	return h.NewResponseWithData(c, resp)
+}
+
+// TwoFactorLoginEnroll
+//
+//	@Summary		TwoFactorLoginEnroll
+//	@Description	secret of the authenticator app of a user who must enroll before logging in
+//	@Tags			user
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.TwoFactorChallengeReq	true	"two-factor challenge"
+//	@Success		200		{object}	domain.Response{data=domain.TwoFactorEnrollResp}
+//	@Router			/api/v1/user/2fa/login/enroll [post]
+func (h *TwoFactorHandler) TwoFactorLoginEnroll(c echo.Context) error {
+	var req domain.TwoFactorChallengeReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	resp, err := h.usecase.EnrollLogin(c.Request().Context(), &req)
+	if err != nil {
+		return h.NewResponseWithError(c, "failed to enroll two-factor authentication", err)
+	}
+	return h.NewResponseWithData(c, resp)
+}
+
+// TwoFactorLoginConfirm
+//
+//	@Summary		TwoFactorLoginConfirm
+//	@Description	enable the enrollment started at login with the first code, return the backup codes and the console token
+//	@Tags			user
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.TwoFactorLoginReq	true	"two-factor login request"
+//	@Success		200		{object}	domain.Response{data=domain.TwoFactorConfirmResp}
+//	@Router			/api/v1/user/2fa/login/confirm [post]
+func (h *TwoFactorHandler) TwoFactorLoginConfirm(c echo.Context) error {
+	var req domain.TwoFactorLoginReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	resp, err := h.userUsecase.ConfirmTwoFactorLogin(c.Request().Context(), &req, sessionClient(c))
+	if err != nil {
+		return h.NewResponseWithError(c, "failed to confirm two-factor authentication", err)
+	}
+	return h.NewResponseWithData(c, resp)
+}
+
+// GetTwoFactorStatus
+//
+//	@Summary		GetTwoFactorStatus
+//	@Description	two-factor authentication of the current user and whether the policy requires it
+//	@Tags			user
+//	@Produce		json
+//	@Success		200	{object}	domain.Response{data=domain.TwoFactorStatusResp}
+//	@Router			/api/v1/user/2fa [get]
+func (h *TwoFactorHandler) GetTwoFactorStatus(c echo.Context) error {
+	userID, ok := h.auth.MustGetUserID(c)
+	if !ok {
+		return h.NewResponseWithError(c, "failed to get user", domain.ErrUnauthorized)
+	}
+	resp, err := h.usecase.GetStatus(c.Request().Context(), userID)
+	if err != nil {
+		return h.NewResponseWithError(c, "failed to get two-factor status", err)
+	}
+	return h.NewResponseWithData(c, resp)
+}
+
+// EnrollTwoFactor
+//
+//	@Summary		EnrollTwoFactor
+//	@Description	new secret of the authenticator app of the current user, enabled by confirming its first code
+//	@Tags			user
+//	@Produce		json
+//	@Success		200	{object}	domain.Response{data=domain.TwoFactorEnrollResp}
+//	@Router			/api/v1/user/2fa/enroll [post]
+func (h *TwoFactorHandler) EnrollTwoFactor(c echo.Context) error {
+	userID, ok := h.auth.MustGetUserID(c)
+	if !ok {
+		return h.NewResponseWithError(c, "failed to get user", domain.ErrUnauthorized)
+	}
+	resp, err := h.usecase.Enroll(c.Request().Context(), userID)
+	if err != nil {
+		return h.NewResponseWithError(c, "failed to enroll two-factor authentication", err)
+	}
+	return h.NewResponseWithData(c, resp)
+}
+
+// ConfirmTwoFactor
+//
+//	@Summary		ConfirmTwoFactor
+//	@Description	enable the enrollment of the current user with the first code, return the backup codes
+//	@Tags			user
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.TwoFactorCodeReq	true	"code of the authenticator app"
+//	@Success		200		{object}	domain.Response{data=domain.TwoFactorConfirmResp}
+//	@Router			/api/v1/user/2fa/confirm [post]
+func (h *TwoFactorHandler) ConfirmTwoFactor(c echo.Context) error {
+	var req domain.TwoFactorCodeReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	userID, ok := h.auth.MustGetUserID(c)
+	if !ok {
+		return h.NewResponseWithError(c, "failed to get user", domain.ErrUnauthorized)
+	}
+	codes, err := h.usecase.Confirm(c.Request().Context(), userID, req.Code)
+	if err != nil {
+		return h.NewResponseWithError(c, "failed to confirm two-factor authentication", err)
+	}
+	return h.NewResponseWithData(c, domain.TwoFactorConfirmResp{BackupCodes: codes})
+}
+
+// RegenerateBackupCodes
+//
+//	@Summary		RegenerateBackupCodes
+//	@Description	replace the backup codes of the current user, unused ones stop working
+//	@Tags			user
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.TwoFactorCodeReq	true	"code of the authenticator app or a backup code"
+//	@Success		200		{object}	domain.Response{data=domain.TwoFactorConfirmResp}
+//	@Router			/api/v1/user/2fa/backup_codes [post]
+func (h *TwoFactorHandler) RegenerateBackupCodes(c echo.Context) error {
+	var req domain.TwoFactorCodeReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	userID, ok := h.auth.MustGetUserID(c)
+	if !ok {
+		return h.NewResponseWithError(c, "failed to get user", domain.ErrUnauthorized)
+	}
+	codes, err := h.usecase.RegenerateBackupCodes(c.Request().Context(), userID, req.Code)
+	if err != nil {
+		return h.NewResponseWithError(c, "failed to regenerate backup codes", err)
+	}
+	return h.NewResponseWithData(c, domain.TwoFactorConfirmResp{BackupCodes: codes})
+}
+
+// DisableTwoFactor
+//
+//	@Summary		DisableTwoFactor
+//	@Description	turn off two-factor authentication of the current user, rejected if the policy requires it
+//	@Tags			user
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.TwoFactorCodeReq	true	"code of the authenticator app or a backup code"
+//	@Success		200		{object}	domain.Response
+//	@Router			/api/v1/user/2fa [delete]
+func (h *TwoFactorHandler) DisableTwoFactor(c echo.Context) error {
+	var req domain.TwoFactorCodeReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	userID, ok := h.auth.MustGetUserID(c)
+	if !ok {
+		return h.NewResponseWithError(c, "failed to get user", domain.ErrUnauthorized)
+	}
+	if err := h.usecase.Disable(c.Request().Context(), userID, req.Code); err != nil {
+		return h.NewResponseWithError(c, "failed to disable two-factor authentication", err)
+	}
+	return h.NewResponseWithData(c, nil)
+}
+
+// ResetTwoFactor
+//
+//	@Summary		ResetTwoFactor
+//	@Description	remove two-factor authentication of a user who lost its authenticator app and backup codes, only for admins
+//	@Tags			user
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.ResetTwoFactorReq	true	"reset two-factor request"
+//	@Success		200		{object}	domain.Response
+//	@Router			/api/v1/user/2fa/reset [delete]
+func (h *TwoFactorHandler) ResetTwoFactor(c echo.Context) error {
+	var req domain.ResetTwoFactorReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	userID, ok := h.auth.MustGetUserID(c)
+	if !ok {
+		return h.NewResponseWithError(c, "failed to get user", domain.ErrUnauthorized)
+	}
+	if err := h.usecase.Reset(c.Request().Context(), userID, &req); err != nil {
+		return h.NewResponseWithError(c, "failed to reset two-factor authentication", err)
+	}
+	return h.NewResponseWithData(c, nil)
+}
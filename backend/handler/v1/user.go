@@ -36,6 +36,7 @@ func NewUserHandler(e *echo.Echo, baseHandler *handler.BaseHandler, logger *log.
 	group.GET("/list", h.ListUsers, h.auth.Authorize)
 	group.PUT("/reset_password", h.ResetPassword, h.auth.Authorize)
 	group.DELETE("/delete", h.DeleteUser, h.auth.Authorize)
+	group.PUT("/role", h.UpdateUserRole, h.auth.Authorize)
 
 	return h
 }
@@ -64,6 +65,7 @@ func (h *UserHandler) CreateUser(c echo.Context) error {
 		ID:       uuid.New().String(),
 		Account:  req.Account,
 		Password: req.Password,
+		Role:     req.Role,
 	})
 	if err != nil {
 		return h.NewResponseWithError(c, "failed to create user", err)
@@ -75,7 +77,7 @@ func (h *UserHandler) CreateUser(c echo.Context) error {
 // Login
 //
 //	@Summary		Login
-//	@Description	Login
+//	@Description	token of the console, or a challenge token and the two-factor step left to log in
 //	@Tags			user
 //	@Accept			json
 //	@Produce		json
@@ -92,12 +94,12 @@ func (h *UserHandler) Login(c echo.Context) error {
 		return h.NewResponseWithError(c, "invalid request", err)
 	}
 
-	token, err := h.usecase.VerifyUserAndGenerateToken(c.Request().Context(), req)
+	resp, err := h.usecase.VerifyUserAndGenerateToken(c.Request().Context(), req, sessionClient(c))
 	if err != nil {
 		return h.NewResponseWithError(c, "failed to login", err)
 	}
 
-	return h.NewResponseWithData(c, domain.LoginResp{Token: token})
+	return h.NewResponseWithData(c, resp)
 }
 
 // GetUser
@@ -111,7 +113,7 @@ func (h *UserHandler) Login(c echo.Context) error {
 func (h *UserHandler) GetUserInfo(c echo.Context) error {
 	userID, ok := h.auth.MustGetUserID(c)
 	if !ok {
-		return h.NewResponseWithError(c, "failed to get user", nil)
+		return h.NewResponseWithError(c, "failed to get user", domain.ErrUnauthorized)
 	}
 
 	user, err := h.usecase.GetUser(c.Request().Context(), userID)
@@ -161,7 +163,7 @@ func (h *UserHandler) ResetPassword(c echo.Context) error {
 
 	userID, ok := h.auth.MustGetUserID(c)
 	if !ok {
-		return h.NewResponseWithError(c, "failed to get user", nil)
+		return h.NewResponseWithError(c, "failed to get user", domain.ErrUnauthorized)
 	}
 
 	user, err := h.usecase.GetUser(c.Request().Context(), userID)
@@ -171,8 +173,8 @@ func (h *UserHandler) ResetPassword(c echo.Context) error {
 	if user.Account == "admin" && userID == req.ID {
 		return h.NewResponseWithError(c, "请修改安装目录下 .env 文件中的 ADMIN_PASSWORD，并重启 panda-wiki-api 容器使更改生效。", nil)
 	}
-	if user.Account != "admin" && userID != req.ID {
-		return h.NewResponseWithError(c, "只有管理员可以重置其他用户密码", nil)
+	if user.Role != domain.UserRoleAdmin && userID != req.ID {
+		return h.NewResponseWithError(c, "只有管理员可以重置其他用户密码", domain.ErrPermissionDenied)
 	}
 	err = h.usecase.ResetPassword(c.Request().Context(), &req)
 	if err != nil {
@@ -200,7 +202,7 @@ func (h *UserHandler) DeleteUser(c echo.Context) error {
 
 	userID, ok := h.auth.MustGetUserID(c)
 	if !ok {
-		return h.NewResponseWithError(c, "failed to get user", nil)
+		return h.NewResponseWithError(c, "failed to get user", domain.ErrUnauthorized)
 	}
 	if userID == req.UserID {
 		return h.NewResponseWithError(c, "cannot delete yourself", nil)
@@ -210,8 +212,8 @@ func (h *UserHandler) DeleteUser(c echo.Context) error {
 	if err != nil {
 		return h.NewResponseWithError(c, "failed to get user", err)
 	}
-	if user.Account != "admin" {
-		return h.NewResponseWithError(c, "只有管理员可以删除用户", nil)
+	if user.Role != domain.UserRoleAdmin {
+		return h.NewResponseWithError(c, "只有管理员可以删除用户", domain.ErrPermissionDenied)
 	}
 
 	err = h.usecase.DeleteUser(c.Request().Context(), req.UserID)
@@ -221,3 +223,31 @@ func (h *UserHandler) DeleteUser(c echo.Context) error {
 
 	return h.NewResponseWithData(c, nil)
 }
+
+// UpdateUserRole
+//
+//	@Summary		UpdateUserRole
+//	@Description	change the global role of another user, admins may access every kb and members only the kbs they are added to
+//	@Tags			user
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.UpdateUserRoleReq	true	"UpdateUserRole Request"
+//	@Success		200		{object}	domain.Response
+//	@Router			/api/v1/user/role [put]
+func (h *UserHandler) UpdateUserRole(c echo.Context) error {
+	var req domain.UpdateUserRoleReq
+	if err := c.Bind(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.NewResponseWithError(c, "invalid request", err)
+	}
+	userID, ok := h.auth.MustGetUserID(c)
+	if !ok {
+		return h.NewResponseWithError(c, "failed to get user", domain.ErrUnauthorized)
+	}
+	if err := h.usecase.UpdateUserRole(c.Request().Context(), userID, &req); err != nil {
+		return h.NewResponseWithError(c, "failed to update user role", err)
+	}
+	return h.NewResponseWithData(c, nil)
+}
@@ -0,0 +1,163 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/handler"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/middleware"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+type WebhookHandler struct {
+	*handler.BaseHandler
+	usecase *usecase.WebhookUsecase
+	logger  *log.Logger
+	auth    middleware.AuthMiddleware
+}
+
+func NewWebhookHandler(
+	baseHandler *handler.BaseHandler,
+	echo *echo.Echo,
+	usecase *usecase.WebhookUsecase,
+	auth middleware.AuthMiddleware,
+	logger *log.Logger,
+) *WebhookHandler {
+	h := &WebhookHandler{
+		BaseHandler: baseHandler,
+		usecase:     usecase,
+		logger:      logger.WithModule("handler.v1.webhook"),
+		auth:        auth,
+	}
+
+	group := echo.Group("/api/v1/webhook", h.auth.Authorize)
+	group.GET("/list", h.GetWebhookList)
+	group.POST("", h.CreateWebhook)
+	group.PUT("", h.UpdateWebhook)
+	group.DELETE("", h.DeleteWebhook)
+	// render payload with a sample event
+	group.POST("/preview", h.PreviewWebhook)
+
+	return h
+}
+
+// GetWebhookList get webhooks of kb
+//
+//	@Summary		GetWebhookList
+//	@Description	webhooks of kb
+//	@Tags			webhook
+//	@Accept			json
+//	@Produce		json
+//	@Param			kb_id	query		string	true	"kb id"
+//	@Success		200		{object}	domain.Response{data=[]domain.Webhook}
+//	@Router			/api/v1/webhook/list [get]
+func (h *WebhookHandler) GetWebhookList(c echo.Context) error {
+	kbID := c.QueryParam("kb_id")
+	if kbID == "" {
+		return h.NewResponseWithError(c, "kb_id is required", nil)
+	}
+	webhooks, err := h.usecase.GetWebhookList(c.Request().Context(), kbID)
+	if err != nil {
+		return h.NewResponseWithError(c, "get webhook list failed", err)
+	}
+	return h.NewResponseWithData(c, webhooks)
+}
+
+// CreateWebhook create webhook
+//
+//	@Summary		CreateWebhook
+//	@Description	create webhook, payload is the raw event unless a template or jsonpath mapping is given
+//	@Tags			webhook
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.CreateWebhookReq	true	"webhook"
+//	@Success		200		{object}	domain.Response{data=map[string]string}
+//	@Router			/api/v1/webhook [post]
+func (h *WebhookHandler) CreateWebhook(c echo.Context) error {
+	req := &domain.CreateWebhookReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	id, err := h.usecase.CreateWebhook(c.Request().Context(), req)
+	if err != nil {
+		return h.NewResponseWithError(c, "create webhook failed", err)
+	}
+	return h.NewResponseWithData(c, map[string]string{"id": id})
+}
+
+// UpdateWebhook update webhook
+//
+//	@Summary		UpdateWebhook
+//	@Description	update webhook
+//	@Tags			webhook
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.UpdateWebhookReq	true	"webhook"
+//	@Success		200		{object}	domain.Response
+//	@Router			/api/v1/webhook [put]
+func (h *WebhookHandler) UpdateWebhook(c echo.Context) error {
+	req := &domain.UpdateWebhookReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	if err := h.usecase.UpdateWebhook(c.Request().Context(), req); err != nil {
+		return h.NewResponseWithError(c, "update webhook failed", err)
+	}
+	return h.NewResponseWithData(c, nil)
+}
+
+// DeleteWebhook delete webhook
+//
+//	@Summary		DeleteWebhook
+//	@Description	delete webhook
+//	@Tags			webhook
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.DeleteWebhookReq	true	"webhook"
+//	@Success		200		{object}	domain.Response
+//	@Router			/api/v1/webhook [delete]
+func (h *WebhookHandler) DeleteWebhook(c echo.Context) error {
+	req := &domain.DeleteWebhookReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	if err := h.usecase.DeleteWebhook(c.Request().Context(), req); err != nil {
+		return h.NewResponseWithError(c, "delete webhook failed", err)
+	}
+	return h.NewResponseWithData(c, nil)
+}
+
+// PreviewWebhook render payload with a sample event
+//
+//	@Summary		PreviewWebhook
+//	@Description	render payload of template or jsonpath mapping with a sample event
+//	@Tags			webhook
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.PreviewWebhookReq	true	"payload settings"
+//	@Success		200		{object}	domain.Response{data=domain.PreviewWebhookResp}
+//	@Router			/api/v1/webhook/preview [post]
+func (h *WebhookHandler) PreviewWebhook(c echo.Context) error {
+	req := &domain.PreviewWebhookReq{}
+	if err := c.Bind(req); err != nil {
+		return h.NewResponseWithError(c, "request body is invalid", err)
+	}
+	if err := c.Validate(req); err != nil {
+		return h.NewResponseWithError(c, "validate request body failed", err)
+	}
+	resp, err := h.usecase.PreviewWebhook(c.Request().Context(), req)
+	if err != nil {
+		return h.NewResponseWithError(c, "preview webhook failed", err)
+	}
+	return h.NewResponseWithData(c, resp)
+}
@@ -0,0 +1,142 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+// APITokenMiddleware authorize apis called by ci pipelines with api tokens instead of console logins
+type APITokenMiddleware struct {
+	logger          *log.Logger
+	apiTokenUsecase *usecase.APITokenUsecase
+}
+
+func NewAPITokenMiddleware(logger *log.Logger, apiTokenUsecase *usecase.APITokenUsecase) *APITokenMiddleware {
+	return &APITokenMiddleware{
+		logger:          logger.WithModule("middleware.api_token"),
+		apiTokenUsecase: apiTokenUsecase,
+	}
+}
+
+// Authorize require a bearer api token of the scope and set it on the context
+func (m *APITokenMiddleware) Authorize(scope domain.APITokenScope) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			token, ok := strings.CutPrefix(c.Request().Header.Get("Authorization"), "Bearer ")
+			if !ok || token == "" {
+				return c.JSON(http.StatusUnauthorized, domain.Response{
+					Success: false,
+					Code:    domain.ErrCodeUnauthorized,
+					Message: domain.ErrAPITokenInvalid.Error(),
+				})
+			}
+			apiToken, err := m.apiTokenUsecase.Authenticate(c.Request().Context(), token)
+			if err != nil {
+				if !errors.Is(err, domain.ErrAPITokenInvalid) {
+					m.logger.Error("authenticate api token failed", log.Error(err))
+				}
+				return c.JSON(http.StatusUnauthorized, domain.Response{
+					Success: false,
+					Code:    domain.ErrCodeUnauthorized,
+					Message: domain.ErrAPITokenInvalid.Error(),
+				})
+			}
+			if !apiToken.HasScope(scope) {
+				return c.JSON(http.StatusForbidden, domain.Response{
+					Success: false,
+					Code:    domain.ErrCodeForbidden,
+					Message: domain.ErrAPITokenScopeDenied.Error(),
+				})
+			}
+			c.Set(domain.ContextKeyAPIToken, apiToken)
+			return next(c)
+		}
+	}
+}
+
+// apiTokenScopeRule scope api tokens need to call the admin apis under the prefix
+type apiTokenScopeRule struct {
+	prefix string
+	// the rule only applies to reads
+	read  bool
+	scope domain.APITokenScope
+}
+
+// apiTokenScopeRules the first matching rule applies, apis without a rule can not be called with api tokens
+var apiTokenScopeRules = []apiTokenScopeRule{
+	{prefix: "/api/v1/node", read: true, scope: domain.APITokenScopeNodesRead},
+	{prefix: "/api/v1/node", scope: domain.APITokenScopeNodesWrite},
+	{prefix: "/api/v1/knowledge_base/release", scope: domain.APITokenScopeNodesWrite},
+	{prefix: "/api/v1/conversation", read: true, scope: domain.APITokenScopeConversationsRead},
+	{prefix: "/api/v1/gap_report", read: true, scope: domain.APITokenScopeStatsRead},
+	{prefix: "/api/v1/digest", read: true, scope: domain.APITokenScopeStatsRead},
+	{prefix: "/api/v1/stat", read: true, scope: domain.APITokenScopeStatsRead},
+}
+
+// apiTokenScopeOf scope needed to call the api with an api token, empty if api tokens may not call it
+func apiTokenScopeOf(method, path string) domain.APITokenScope {
+	read := method == http.MethodGet || method == http.MethodHead
+	for _, rule := range apiTokenScopeRules {
+		if strings.HasPrefix(path, rule.prefix) && (read || !rule.read) {
+			return rule.scope
+		}
+	}
+	return ""
+}
+
+// authorizeAPIToken authorize a call of an admin api with an api token instead of a console login.
+// the request is made as the creator of the token, personal tokens are limited by its roles and service tokens by their kb
+func (m *JWTMiddleware) authorizeAPIToken(c echo.Context, token string, next echo.HandlerFunc) error {
+	req := c.Request()
+	apiToken, err := m.apiTokenUsecase.Authenticate(req.Context(), token)
+	if err != nil {
+		if !errors.Is(err, domain.ErrAPITokenInvalid) {
+			m.logger.Error("authenticate api token failed", log.Error(err))
+		}
+		return c.JSON(http.StatusUnauthorized, domain.Response{
+			Success: false,
+			Code:    domain.ErrCodeUnauthorized,
+			Message: domain.ErrAPITokenInvalid.Error(),
+		})
+	}
+	scope := apiTokenScopeOf(req.Method, req.URL.Path)
+	if scope == "" || !apiToken.HasScope(scope) {
+		m.logger.Warn("api token scope denied", log.String("token_id", apiToken.ID), log.String("scope", string(scope)),
+			log.String("method", req.Method), log.String("path", req.URL.Path))
+		return c.JSON(http.StatusForbidden, domain.Response{
+			Success: false,
+			Code:    domain.ErrCodeForbidden,
+			Message: domain.ErrAPITokenScopeDenied.Error(),
+		})
+	}
+	// handlers read the user of the request from the claims of console logins
+	c.Set("user", jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"id": apiToken.CreatedBy}))
+	c.Set(domain.ContextKeyAPIToken, apiToken)
+	if apiToken.Kind != domain.APITokenKindService {
+		return m.checkPermission(next)(c)
+	}
+	if apiToken.KBID != "" {
+		kbID, err := requestRuleKBID(c, m.kbMemberUsecase, kbPermissionRuleOf(req.URL.Path))
+		if err != nil {
+			m.logger.Error("get kb of request failed", log.String("path", req.URL.Path), log.Error(err))
+		}
+		if kbID != apiToken.KBID {
+			m.logger.Warn("api token kb denied", log.String("token_id", apiToken.ID), log.String("kb_id", kbID),
+				log.String("method", req.Method), log.String("path", req.URL.Path))
+			return c.JSON(http.StatusForbidden, domain.Response{
+				Success: false,
+				Code:    domain.ErrCodeForbidden,
+				Message: domain.ErrPermissionDenied.Error(),
+			})
+		}
+	}
+	return next(c)
+}
@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+// AuditMiddleware record successful writes of logged in users and api tokens to the admin apis in the audit log,
+// with the row of the resource before and after the write if it has a snapshot and the request body otherwise
+type AuditMiddleware struct {
+	logger       *log.Logger
+	auth         AuthMiddleware
+	auditUsecase *usecase.AuditUsecase
+}
+
+func NewAuditMiddleware(logger *log.Logger, auth AuthMiddleware, auditUsecase *usecase.AuditUsecase) *AuditMiddleware {
+	return &AuditMiddleware{
+		logger:       logger.WithModule("middleware.audit"),
+		auth:         auth,
+		auditUsecase: auditUsecase,
+	}
+}
+
+func (m *AuditMiddleware) Record(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		switch req.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			return next(c)
+		}
+		route := c.Path()
+		if !strings.HasPrefix(route, "/api/v1/") {
+			return next(c)
+		}
+		ctx := req.Context()
+		entry := &domain.AuditLog{
+			Method:       req.Method,
+			Route:        route,
+			ResourceType: domain.AuditResourceType(route),
+			ResourceID:   requestParam(c, "id"),
+			KBID:         requestKBID(c),
+			RemoteIP:     c.RealIP(),
+		}
+		snapshot := m.auditUsecase.HasSnapshot(entry.ResourceType) && entry.ResourceID != ""
+		var body []byte
+		if snapshot {
+			entry.Before = m.auditUsecase.Snapshot(ctx, entry.ResourceType, entry.ResourceID)
+		} else {
+			body = auditRequestBody(req)
+		}
+
+		err := next(c)
+		if err != nil || c.Response().Status >= http.StatusBadRequest || c.Get(domain.ContextKeyResponseError) != nil {
+			return err
+		}
+		apiToken, _ := c.Get(domain.ContextKeyAPIToken).(*domain.APIToken)
+		if apiToken != nil {
+			entry.APITokenID = apiToken.ID
+		}
+		userID, ok := m.auth.MustGetUserID(c)
+		switch {
+		case ok:
+			entry.ActorID = userID
+		case apiToken != nil:
+			// sandbox apis authenticate the token without a user
+			entry.ActorID = apiToken.CreatedBy
+		default:
+			return nil
+		}
+
+		// the response is sent already, the entry is written even if the client goes away
+		ctx = context.WithoutCancel(ctx)
+		if snapshot {
+			entry.After = m.auditUsecase.Snapshot(ctx, entry.ResourceType, entry.ResourceID)
+		} else if body != nil {
+			entry.After = domain.RedactAuditSnapshot(body)
+		}
+		if err := m.auditUsecase.Record(ctx, entry); err != nil {
+			m.logger.Error("record audit log failed", log.String("user_id", entry.ActorID), log.String("method", entry.Method),
+				log.String("route", entry.Route), log.Error(err))
+		}
+		return nil
+	}
+}
+
+// auditRequestBody json body of the request if it is not too large, the body is restored for the handler
+func auditRequestBody(req *http.Request) []byte {
+	if req.Body == nil || !strings.HasPrefix(req.Header.Get(echo.HeaderContentType), echo.MIMEApplicationJSON) {
+		return nil
+	}
+	body, err := io.ReadAll(io.LimitReader(req.Body, domain.AuditSnapshotMaxBytes+1))
+	req.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), req.Body))
+	if err != nil || len(body) > domain.AuditSnapshotMaxBytes || !json.Valid(body) {
+		return nil
+	}
+	return body
+}
+
+// requestKBID kb_id of a write request to record, from query or json body, the body is restored for the handler
+func requestKBID(c echo.Context) string {
+	if kbID := c.QueryParam("kb_id"); kbID != "" {
+		return kbID
+	}
+	req := c.Request()
+	if req.Body == nil || !strings.HasPrefix(req.Header.Get(echo.HeaderContentType), echo.MIMEApplicationJSON) {
+		return ""
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return ""
+	}
+	var payload struct {
+		KBID string `json:"kb_id"`
+		ID   string `json:"id"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return ""
+	}
+	// knowledge base apis carry the kb id as id
+	if payload.KBID == "" && strings.HasPrefix(req.URL.Path, "/api/v1/knowledge_base") {
+		return payload.ID
+	}
+	return payload.KBID
+}
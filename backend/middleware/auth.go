@@ -8,6 +8,7 @@ import (
 	"github.com/chaitin/panda-wiki/config"
 	"github.com/chaitin/panda-wiki/log"
 	"github.com/chaitin/panda-wiki/repo/pg"
+	"github.com/chaitin/panda-wiki/usecase"
 )
 
 type AuthMiddleware interface {
@@ -15,10 +16,10 @@ type AuthMiddleware interface {
 	MustGetUserID(c echo.Context) (string, bool)
 }
 
-func NewAuthMiddleware(config *config.Config, logger *log.Logger, userAccessRepo *pg.UserAccessRepository) (AuthMiddleware, error) {
+func NewAuthMiddleware(config *config.Config, logger *log.Logger, userAccessRepo *pg.UserAccessRepository, kbMemberUsecase *usecase.KBMemberUsecase, apiTokenUsecase *usecase.APITokenUsecase, sessionUsecase *usecase.SessionUsecase) (AuthMiddleware, error) {
 	switch config.Auth.Type {
 	case "jwt":
-		return NewJWTMiddleware(config, logger, userAccessRepo), nil
+		return NewJWTMiddleware(config, logger, userAccessRepo, kbMemberUsecase, apiTokenUsecase, sessionUsecase), nil
 	default:
 		return nil, fmt.Errorf("invalid auth type: %s", config.Auth.Type)
 	}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+// IPRuleMiddleware reject requests of ips blocked by the rules of the admin apis or the public apis, before routing
+type IPRuleMiddleware struct {
+	logger        *log.Logger
+	ipRuleUsecase *usecase.IPRuleUsecase
+}
+
+func NewIPRuleMiddleware(logger *log.Logger, ipRuleUsecase *usecase.IPRuleUsecase) *IPRuleMiddleware {
+	return &IPRuleMiddleware{
+		logger:        logger.WithModule("middleware.ip_rule"),
+		ipRuleUsecase: ipRuleUsecase,
+	}
+}
+
+func (m *IPRuleMiddleware) Check(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var scope domain.IPRuleScope
+		switch path := c.Request().URL.Path; {
+		case strings.HasPrefix(path, "/api/"):
+			scope = domain.IPRuleScopeAdmin
+		case strings.HasPrefix(path, "/share/"), path == "/sitemap.xml", path == "/opensearch.xml":
+			scope = domain.IPRuleScopePublic
+		default:
+			return next(c)
+		}
+		ip := c.RealIP()
+		if err := m.ipRuleUsecase.CheckIP(c.Request().Context(), scope, ip); err != nil {
+			m.logger.Warn("ip denied", log.String("ip", ip), log.String("scope", string(scope)), log.String("path", c.Request().URL.Path))
+			return c.JSON(http.StatusForbidden, domain.Response{
+				Success: false,
+				Code:    domain.ErrCodeForbidden,
+				Message: err.Error(),
+			})
+		}
+		return next(c)
+	}
+}
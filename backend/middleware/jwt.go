@@ -1,7 +1,9 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
+	"strings"
 
 	"github.com/golang-jwt/jwt/v5"
 	echoMiddleware "github.com/labstack/echo-jwt/v4"
@@ -11,6 +13,7 @@ import (
 	"github.com/chaitin/panda-wiki/domain"
 	"github.com/chaitin/panda-wiki/log"
 	"github.com/chaitin/panda-wiki/repo/pg"
+	"github.com/chaitin/panda-wiki/usecase"
 )
 
 type JWTMiddleware struct {
@@ -18,9 +21,14 @@ type JWTMiddleware struct {
 	jwtMiddleware  echo.MiddlewareFunc
 	logger         *log.Logger
 	userAccessRepo *pg.UserAccessRepository
+	// roles of members on kbs, checked for every admin api
+	kbMemberUsecase kbPermissionChecker
+	apiTokenUsecase *usecase.APITokenUsecase
+	// console tokens stop working once their session is revoked
+	sessionUsecase *usecase.SessionUsecase
 }
 
-func NewJWTMiddleware(config *config.Config, logger *log.Logger, userAccessRepo *pg.UserAccessRepository) *JWTMiddleware {
+func NewJWTMiddleware(config *config.Config, logger *log.Logger, userAccessRepo *pg.UserAccessRepository, kbMemberUsecase *usecase.KBMemberUsecase, apiTokenUsecase *usecase.APITokenUsecase, sessionUsecase *usecase.SessionUsecase) *JWTMiddleware {
 	jwtMiddleware := echoMiddleware.WithConfig(echoMiddleware.Config{
 		SigningKey: []byte(config.Auth.JWT.Secret),
 		ErrorHandler: func(c echo.Context, err error) error {
@@ -32,17 +40,25 @@ func NewJWTMiddleware(config *config.Config, logger *log.Logger, userAccessRepo
 		},
 	})
 	return &JWTMiddleware{
-		config:         config,
-		jwtMiddleware:  jwtMiddleware,
-		logger:         logger.WithModule("middleware.jwt"),
-		userAccessRepo: userAccessRepo,
+		config:          config,
+		jwtMiddleware:   jwtMiddleware,
+		logger:          logger.WithModule("middleware.jwt"),
+		userAccessRepo:  userAccessRepo,
+		kbMemberUsecase: kbMemberUsecase,
+		apiTokenUsecase: apiTokenUsecase,
+		sessionUsecase:  sessionUsecase,
 	}
 }
 
 func (m *JWTMiddleware) Authorize(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
+		// integrations call the admin apis with api tokens instead of console logins
+		if token, ok := strings.CutPrefix(c.Request().Header.Get("Authorization"), "Bearer "); ok && strings.HasPrefix(token, domain.APITokenPrefix) {
+			return m.authorizeAPIToken(c, token, next)
+		}
+
 		// First apply JWT middleware
-		if err := m.jwtMiddleware(next)(c); err != nil {
+		if err := m.jwtMiddleware(m.checkSession(m.checkPermission(next)))(c); err != nil {
 			return err
 		}
 
@@ -68,3 +84,37 @@ func (m *JWTMiddleware) MustGetUserID(c echo.Context) (string, bool) {
 	id, ok := claims["id"].(string)
 	return id, ok
 }
+
+// checkSession reject console tokens of revoked or expired sessions, and tokens issued before sessions were recorded
+func (m *JWTMiddleware) checkSession(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		user, _ := c.Get("user").(*jwt.Token)
+		var sessionID string
+		if user != nil {
+			if claims, ok := user.Claims.(jwt.MapClaims); ok {
+				sessionID, _ = claims[domain.ClaimSessionID].(string)
+			}
+		}
+		var err error = domain.ErrSessionRevoked
+		if sessionID != "" {
+			err = m.sessionUsecase.CheckSession(c.Request().Context(), sessionID)
+		}
+		if errors.Is(err, domain.ErrSessionRevoked) {
+			return c.JSON(http.StatusUnauthorized, domain.Response{
+				Success: false,
+				Code:    domain.ErrCodeUnauthorized,
+				Message: "Unauthorized",
+			})
+		}
+		if err != nil {
+			m.logger.Error("check session failed", log.String("session_id", sessionID), log.Error(err))
+			return c.JSON(http.StatusInternalServerError, domain.Response{
+				Success: false,
+				Code:    domain.ErrCodeInternal,
+				Message: "failed to check session",
+			})
+		}
+		c.Set(domain.ContextKeySessionID, sessionID)
+		return next(c)
+	}
+}
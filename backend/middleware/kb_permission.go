@@ -0,0 +1,231 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+)
+
+// kbResourceResolver kbs of rows of resources, implemented by usecase.KBMemberUsecase
+type kbResourceResolver interface {
+	GetResourceKBID(ctx context.Context, model any, id string) (string, error)
+}
+
+// kbPermissionChecker roles of members on kbs, implemented by usecase.KBMemberUsecase
+type kbPermissionChecker interface {
+	kbResourceResolver
+	IsAdmin(ctx context.Context, userID string) (bool, error)
+	CheckPermission(ctx context.Context, userID, kbID string, permission domain.KBPermission) error
+}
+
+// permissionAnyUser apis not scoped to a kb which every logged in user may call
+const permissionAnyUser domain.KBPermission = "*"
+
+// kbPermissionRule permissions of members on the admin apis under the path prefix
+type kbPermissionRule struct {
+	prefix string
+	// permission on the kb of the request for reads and for writes, only admins may call the apis if empty
+	read, write domain.KBPermission
+	// param carrying the kb id, kb_id if empty
+	kbIDParam string
+	// the id param is of a row of the model, its kb is checked instead of the kb_id param
+	resource any
+	// param carrying the id of the resource, id if empty
+	resourceParam string
+}
+
+// kbPermissionRules the first rule with a matching prefix applies, apis without a rule are only for admins
+var kbPermissionRules = []kbPermissionRule{
+	{prefix: "/api/v1/user/create"},
+	{prefix: "/api/v1/user/role"},
+	{prefix: "/api/v1/user/2fa/reset"},
+	// reset_password and delete are checked by the handler
+	{prefix: "/api/v1/user", read: permissionAnyUser, write: permissionAnyUser},
+	// members manage their personal tokens, checked by the usecase
+	{prefix: "/api/v1/api_token", read: permissionAnyUser, write: permissionAnyUser},
+	// filtered to the kbs of the member
+	{prefix: "/api/v1/knowledge_base/list", read: permissionAnyUser},
+	{prefix: "/api/v1/knowledge_base/compliance_profiles", read: permissionAnyUser},
+	{prefix: "/api/v1/knowledge_base/member", read: domain.KBPermissionView, write: domain.KBPermissionManageSettings},
+	{prefix: "/api/v1/knowledge_base/detail", read: domain.KBPermissionView, write: domain.KBPermissionManageSettings, kbIDParam: "id"},
+	{prefix: "/api/v1/knowledge_base/release", read: domain.KBPermissionView, write: domain.KBPermissionEditNodes},
+	{prefix: "/api/v1/knowledge_base/index", read: domain.KBPermissionView, write: domain.KBPermissionManageSettings},
+	// node apis whose id param is a node, its kb is checked whether or not the request carries a kb id
+	{prefix: "/api/v1/node/detail", read: domain.KBPermissionView, write: domain.KBPermissionEditNodes, resource: &domain.Node{}},
+	{prefix: "/api/v1/node/move", write: domain.KBPermissionEditNodes, resource: &domain.Node{}},
+	{prefix: "/api/v1/node/published", read: domain.KBPermissionView, resource: &domain.Node{}},
+	{prefix: "/api/v1/node/discard_draft", write: domain.KBPermissionEditNodes, resource: &domain.Node{}},
+	{prefix: "/api/v1/node/backlinks", read: domain.KBPermissionView, resource: &domain.Node{}},
+	{prefix: "/api/v1/node/defaults", read: domain.KBPermissionView, write: domain.KBPermissionManageSettings, resource: &domain.Node{}},
+	{prefix: "/api/v1/node/acl", read: domain.KBPermissionView, write: domain.KBPermissionManageSettings},
+	{prefix: "/api/v1/node/export", read: domain.KBPermissionExportNodes, write: domain.KBPermissionExportNodes},
+	{prefix: "/api/v1/node", read: domain.KBPermissionView, write: domain.KBPermissionEditNodes},
+	{prefix: "/api/v1/glossary", read: domain.KBPermissionView, write: domain.KBPermissionEditNodes},
+	{prefix: "/api/v1/import_source", read: domain.KBPermissionView, write: domain.KBPermissionEditNodes},
+	{prefix: "/api/v1/conversation/rescore", read: domain.KBPermissionViewAnalytics, write: domain.KBPermissionViewAnalytics},
+	// replays documents hidden by node acls and the full prompt
+	{prefix: "/api/v1/conversation/message/debug"},
+	{prefix: "/api/v1/conversation/detail", read: domain.KBPermissionViewConversations, resource: &domain.Conversation{}},
+	{prefix: "/api/v1/conversation", read: domain.KBPermissionViewConversations, write: domain.KBPermissionManageSettings},
+	{prefix: "/api/v1/eval", read: domain.KBPermissionViewAnalytics, write: domain.KBPermissionManageSettings},
+	{prefix: "/api/v1/gap_report", read: domain.KBPermissionViewAnalytics, write: domain.KBPermissionViewAnalytics},
+	{prefix: "/api/v1/digest", read: domain.KBPermissionViewAnalytics, write: domain.KBPermissionViewAnalytics},
+	{prefix: "/api/v1/stat", read: domain.KBPermissionViewAnalytics},
+	{prefix: "/api/v1/anomaly", read: domain.KBPermissionViewAnalytics, write: domain.KBPermissionManageSettings},
+	{prefix: "/api/v1/guardrail", read: domain.KBPermissionViewAnalytics, write: domain.KBPermissionManageSettings},
+	{prefix: "/api/v1/onboarding/checklist", read: domain.KBPermissionView},
+	{prefix: "/api/v1/app/bot_profile", read: domain.KBPermissionManageSettings, write: domain.KBPermissionManageSettings},
+	{prefix: "/api/v1/app/detail", read: domain.KBPermissionManageSettings},
+	// prompt apis carry only the app id
+	{prefix: "/api/v1/app/prompt", read: domain.KBPermissionManageSettings, write: domain.KBPermissionManageSettings, resource: &domain.App{}, resourceParam: "app_id"},
+	{prefix: "/api/v1/app", read: domain.KBPermissionManageSettings, write: domain.KBPermissionManageSettings, resource: &domain.App{}},
+	{prefix: "/api/v1/reader", read: domain.KBPermissionManageSettings, write: domain.KBPermissionManageSettings},
+	{prefix: "/api/v1/webhook", read: domain.KBPermissionManageSettings, write: domain.KBPermissionManageSettings},
+	{prefix: "/api/v1/retention", read: domain.KBPermissionManageSettings, write: domain.KBPermissionManageSettings},
+	{prefix: "/api/v1/data/export", read: domain.KBPermissionExportData, write: domain.KBPermissionExportData},
+	// global read-only mode is shown to every user, writes without a kb id are only for admins
+	{prefix: "/api/v1/maintenance", read: permissionAnyUser, write: domain.KBPermissionManageSettings},
+	{prefix: "/api/v1/crawler", read: permissionAnyUser, write: permissionAnyUser},
+	{prefix: "/api/v1/file", read: permissionAnyUser, write: permissionAnyUser},
+}
+
+// kbPermissionRuleOf rule of the api, an empty rule only for admins if none matches
+func kbPermissionRuleOf(path string) kbPermissionRule {
+	for _, rule := range kbPermissionRules {
+		if strings.HasPrefix(path, rule.prefix) {
+			return rule
+		}
+	}
+	return kbPermissionRule{}
+}
+
+// checkPermission reject requests of members without the permission of the api on the kb of the request
+func (m *JWTMiddleware) checkPermission(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		// tokens without a user, like tokens of other kinds signed with the same secret, are never let through
+		userID, ok := m.MustGetUserID(c)
+		if !ok || userID == "" {
+			return c.JSON(http.StatusUnauthorized, domain.Response{
+				Success: false,
+				Code:    domain.ErrCodeUnauthorized,
+				Message: "Unauthorized",
+			})
+		}
+		req := c.Request()
+		rule := kbPermissionRuleOf(req.URL.Path)
+		permission := rule.write
+		switch req.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			permission = rule.read
+		}
+		if permission == permissionAnyUser {
+			return next(c)
+		}
+		ctx := req.Context()
+		admin, err := m.kbMemberUsecase.IsAdmin(ctx, userID)
+		if err != nil {
+			if errors.Is(err, domain.ErrUserNotFound) {
+				return c.JSON(http.StatusUnauthorized, domain.Response{
+					Success: false,
+					Code:    domain.ErrCodeUnauthorized,
+					Message: "Unauthorized",
+				})
+			}
+			m.logger.Error("get user role failed", log.String("user_id", userID), log.Error(err))
+			return c.JSON(http.StatusInternalServerError, domain.Response{
+				Success: false,
+				Code:    domain.ErrCodeInternal,
+				Message: "failed to check permission",
+			})
+		}
+		if admin {
+			return next(c)
+		}
+		var kbID string
+		if permission != "" {
+			kbID, err = requestRuleKBID(c, m.kbMemberUsecase, rule)
+			if err != nil {
+				m.logger.Error("get kb of request failed", log.String("path", req.URL.Path), log.Error(err))
+			}
+		}
+		if kbID == "" {
+			return m.denyPermission(c, userID, kbID, permission)
+		}
+		if err := m.kbMemberUsecase.CheckPermission(ctx, userID, kbID, permission); err != nil {
+			if !errors.Is(err, domain.ErrPermissionDenied) {
+				m.logger.Error("check kb permission failed", log.String("user_id", userID), log.String("kb_id", kbID), log.Error(err))
+			}
+			return m.denyPermission(c, userID, kbID, permission)
+		}
+		return next(c)
+	}
+}
+
+func (m *JWTMiddleware) denyPermission(c echo.Context, userID, kbID string, permission domain.KBPermission) error {
+	m.logger.Warn("permission denied", log.String("user_id", userID), log.String("kb_id", kbID),
+		log.String("permission", string(permission)), log.String("method", c.Request().Method), log.String("path", c.Request().URL.Path))
+	return c.JSON(http.StatusForbidden, domain.Response{
+		Success: false,
+		Code:    domain.ErrCodeForbidden,
+		Message: domain.ErrPermissionDenied.Error(),
+	})
+}
+
+// requestRuleKBID kb of the request, that of the resource of the id if the rule has one
+func requestRuleKBID(c echo.Context, resources kbResourceResolver, rule kbPermissionRule) (string, error) {
+	if rule.resource != nil {
+		param := rule.resourceParam
+		if param == "" {
+			param = "id"
+		}
+		if id := requestParam(c, param); id != "" {
+			kbID, err := resources.GetResourceKBID(c.Request().Context(), rule.resource, id)
+			if err != nil || kbID != "" {
+				return kbID, err
+			}
+		}
+	}
+	param := rule.kbIDParam
+	if param == "" {
+		param = "kb_id"
+	}
+	return requestParam(c, param), nil
+}
+
+// requestParam string param of the request from query, form or json body, the body is restored for the handler
+func requestParam(c echo.Context, name string) string {
+	if value := c.QueryParam(name); value != "" {
+		return value
+	}
+	req := c.Request()
+	contentType := req.Header.Get(echo.HeaderContentType)
+	switch {
+	case strings.HasPrefix(contentType, echo.MIMEMultipartForm), strings.HasPrefix(contentType, echo.MIMEApplicationForm):
+		return c.FormValue(name)
+	case strings.HasPrefix(contentType, echo.MIMEApplicationJSON) && req.Body != nil:
+		body, err := io.ReadAll(req.Body)
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		if err != nil {
+			return ""
+		}
+		var payload map[string]json.RawMessage
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return ""
+		}
+		var value string
+		if err := json.Unmarshal(payload[name], &value); err != nil {
+			return ""
+		}
+		return value
+	}
+	return ""
+}
@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/config"
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+)
+
+// fakeKBMembers roles of users on kbs and kbs of nodes
+type fakeKBMembers struct {
+	admins    map[string]bool
+	roles     map[string]domain.KBRole // user id/kb id
+	nodeKBIDs map[string]string
+	appKBIDs  map[string]string
+}
+
+func (f *fakeKBMembers) IsAdmin(ctx context.Context, userID string) (bool, error) {
+	return f.admins[userID], nil
+}
+
+func (f *fakeKBMembers) CheckPermission(ctx context.Context, userID, kbID string, permission domain.KBPermission) error {
+	role, ok := f.roles[userID+"/"+kbID]
+	if !ok || !role.Allows(permission) {
+		return domain.ErrPermissionDenied
+	}
+	return nil
+}
+
+func (f *fakeKBMembers) GetResourceKBID(ctx context.Context, model any, id string) (string, error) {
+	switch model.(type) {
+	case *domain.Node:
+		return f.nodeKBIDs[id], nil
+	case *domain.App:
+		return f.appKBIDs[id], nil
+	}
+	return "", nil
+}
+
+func TestCheckPermissionNodeByID(t *testing.T) {
+	m := &JWTMiddleware{
+		logger: log.NewLogger(&config.Config{}),
+		kbMemberUsecase: &fakeKBMembers{
+			roles: map[string]domain.KBRole{
+				"owner/kb-1":   domain.KBRoleOwner,
+				"editor/kb-1":  domain.KBRoleEditor,
+				"analyst/kb-1": domain.KBRoleAnalyst,
+			},
+			nodeKBIDs: map[string]string{"node-1": "kb-1", "node-2": "kb-2"},
+			appKBIDs:  map[string]string{"app-1": "kb-1", "app-2": "kb-2"},
+		},
+	}
+	tests := []struct {
+		name   string
+		userID string
+		method string
+		target string
+		body   string
+		want   int
+	}{
+		{name: "editor moves node of its kb", userID: "editor", method: http.MethodPost, target: "/api/v1/node/move", body: `{"id":"node-1","parent_id":"","prev_id":"","next_id":""}`, want: http.StatusOK},
+		{name: "editor can not move node of another kb", userID: "editor", method: http.MethodPost, target: "/api/v1/node/move", body: `{"id":"node-2"}`, want: http.StatusForbidden},
+		{name: "kb id of the body does not override the kb of the node", userID: "editor", method: http.MethodPost, target: "/api/v1/node/discard_draft", body: `{"id":"node-2","kb_id":"kb-1"}`, want: http.StatusForbidden},
+		{name: "analyst can not move node", userID: "analyst", method: http.MethodPost, target: "/api/v1/node/move", body: `{"id":"node-1"}`, want: http.StatusForbidden},
+		{name: "analyst reads published node by id", userID: "analyst", method: http.MethodGet, target: "/api/v1/node/published?id=node-1", want: http.StatusOK},
+		{name: "non member can not read published node", userID: "stranger", method: http.MethodGet, target: "/api/v1/node/published?id=node-1", want: http.StatusForbidden},
+		{name: "token without a user is denied", userID: "", method: http.MethodGet, target: "/api/v1/user", want: http.StatusUnauthorized},
+		{name: "token without a user can not read stats", userID: "", method: http.MethodGet, target: "/api/v1/stat/funnel?kb_id=kb-1", want: http.StatusUnauthorized},
+		{name: "analyst reads question clusters", userID: "analyst", method: http.MethodGet, target: "/api/v1/stat/question_clusters?kb_id=kb-1", want: http.StatusOK},
+		{name: "editor can not read question clusters", userID: "editor", method: http.MethodGet, target: "/api/v1/stat/question_clusters?kb_id=kb-1", want: http.StatusForbidden},
+		{name: "owner reads prompt of an app of its kb", userID: "owner", method: http.MethodGet, target: "/api/v1/app/prompt?app_id=app-1", want: http.StatusOK},
+		{name: "owner saves prompt of an app of its kb", userID: "owner", method: http.MethodPost, target: "/api/v1/app/prompt", body: `{"app_id":"app-1","template":"{{citation}}"}`, want: http.StatusOK},
+		{name: "owner can not save prompt of an app of another kb", userID: "owner", method: http.MethodPost, target: "/api/v1/app/prompt/restore", body: `{"app_id":"app-2","kb_id":"kb-1","version":1}`, want: http.StatusForbidden},
+		{name: "editor can not read prompt", userID: "editor", method: http.MethodGet, target: "/api/v1/app/prompt/versions?app_id=app-1", want: http.StatusForbidden},
+		{name: "analyst can not read stats of another kb", userID: "analyst", method: http.MethodGet, target: "/api/v1/stat/funnel?kb_id=kb-2", want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.body != "" {
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			}
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(req, rec)
+			claims := jwt.MapClaims{"sub": "reader-1"}
+			if tt.userID != "" {
+				claims["id"] = tt.userID
+			}
+			c.Set("user", jwt.NewWithClaims(jwt.SigningMethodHS256, claims))
+			var handlerBody string
+			err := m.checkPermission(func(c echo.Context) error {
+				body, _ := io.ReadAll(c.Request().Body)
+				handlerBody = string(body)
+				return c.NoContent(http.StatusOK)
+			})(c)
+			if err != nil {
+				t.Fatalf("checkPermission() error = %v", err)
+			}
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if tt.want == http.StatusOK && handlerBody != tt.body {
+				t.Errorf("handler body = %q, want %q", handlerBody, tt.body)
+			}
+		})
+	}
+}
@@ -5,4 +5,9 @@ import "github.com/google/wire"
 var ProviderSet = wire.NewSet(
 	NewAuthMiddleware,
 	NewShareAuthMiddleware,
+	NewReadOnlyMiddleware,
+	NewTelemetryMiddleware,
+	NewAPITokenMiddleware,
+	NewAuditMiddleware,
+	NewIPRuleMiddleware,
 )
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+// paths still writable in read-only mode
+var readOnlyAllowedPaths = []string{
+	"/api/v1/maintenance",
+	"/api/v1/user/login",
+}
+
+// writableChecker read-only mode of kbs, implemented by usecase.MaintenanceUsecase
+type writableChecker interface {
+	CheckWritable(ctx context.Context, kbID string) error
+}
+
+// ReadOnlyMiddleware block admin api writes during migrations or backups, public site and chat are not affected
+type ReadOnlyMiddleware struct {
+	logger             *log.Logger
+	maintenanceUsecase writableChecker
+	kbMemberUsecase    kbResourceResolver
+}
+
+func NewReadOnlyMiddleware(logger *log.Logger, maintenanceUsecase *usecase.MaintenanceUsecase, kbMemberUsecase *usecase.KBMemberUsecase) *ReadOnlyMiddleware {
+	return &ReadOnlyMiddleware{
+		logger:             logger.WithModule("middleware.read_only"),
+		maintenanceUsecase: maintenanceUsecase,
+		kbMemberUsecase:    kbMemberUsecase,
+	}
+}
+
+func (m *ReadOnlyMiddleware) Check(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		switch req.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			return next(c)
+		}
+		path := req.URL.Path
+		if !strings.HasPrefix(path, "/api/v1/") {
+			return next(c)
+		}
+		for _, allowed := range readOnlyAllowedPaths {
+			if strings.HasPrefix(path, allowed) {
+				return next(c)
+			}
+		}
+		// the kb is found as the permission check finds it, from the resource of the id, the query, a form or a json body.
+		// a kb which can not be found is checked as unknown, writes are blocked then while any kb is read-only
+		kbID, err := requestRuleKBID(c, m.kbMemberUsecase, kbPermissionRuleOf(path))
+		if err != nil {
+			m.logger.Error("get kb of request failed", log.String("path", path), log.Error(err))
+			kbID = ""
+		}
+		if err := m.maintenanceUsecase.CheckWritable(req.Context(), kbID); err != nil {
+			m.logger.Warn("write blocked", log.String("method", req.Method), log.String("path", path), log.Error(err))
+			return c.JSON(http.StatusServiceUnavailable, domain.Response{
+				Success: false,
+				Message: err.Error(),
+			})
+		}
+		return next(c)
+	}
+}
@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/config"
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+)
+
+// fakeMaintenance kbs in read-only mode, writes of unknown kbs are locked while any is read-only
+type fakeMaintenance struct {
+	readOnlyKBIDs []string
+}
+
+func (f *fakeMaintenance) CheckWritable(ctx context.Context, kbID string) error {
+	if kbID == "" && len(f.readOnlyKBIDs) > 0 || slices.Contains(f.readOnlyKBIDs, kbID) {
+		return domain.ErrReadOnlyMode
+	}
+	return nil
+}
+
+func TestReadOnlyCheck(t *testing.T) {
+	m := &ReadOnlyMiddleware{
+		logger:             log.NewLogger(&config.Config{}),
+		maintenanceUsecase: &fakeMaintenance{readOnlyKBIDs: []string{"kb-1"}},
+		kbMemberUsecase: &fakeKBMembers{
+			nodeKBIDs: map[string]string{"node-1": "kb-1", "node-2": "kb-2"},
+			appKBIDs:  map[string]string{"app-1": "kb-1", "app-2": "kb-2"},
+		},
+	}
+	upload := func(kbID string) (string, string) {
+		var body bytes.Buffer
+		w := multipart.NewWriter(&body)
+		_ = w.WriteField("kb_id", kbID)
+		part, _ := w.CreateFormFile("file", "doc.md")
+		_, _ = part.Write([]byte("# doc"))
+		_ = w.Close()
+		return body.String(), w.FormDataContentType()
+	}
+	readOnlyUpload, readOnlyUploadType := upload("kb-1")
+	writableUpload, writableUploadType := upload("kb-2")
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		body        string
+		contentType string
+		want        int
+	}{
+		{name: "reads pass", method: http.MethodGet, target: "/api/v1/node/list?kb_id=kb-1", want: http.StatusOK},
+		{name: "json write to read-only kb", method: http.MethodPost, target: "/api/v1/node", body: `{"kb_id":"kb-1","name":"doc"}`, contentType: echo.MIMEApplicationJSON, want: http.StatusServiceUnavailable},
+		{name: "json write to writable kb", method: http.MethodPost, target: "/api/v1/node", body: `{"kb_id":"kb-2","name":"doc"}`, contentType: echo.MIMEApplicationJSON, want: http.StatusOK},
+		{name: "delete with kb id in query", method: http.MethodDelete, target: "/api/v1/webhook?kb_id=kb-1&id=hook-1", want: http.StatusServiceUnavailable},
+		{name: "node of read-only kb by id", method: http.MethodPost, target: "/api/v1/node/move", body: `{"id":"node-1","kb_id":"kb-2"}`, contentType: echo.MIMEApplicationJSON, want: http.StatusServiceUnavailable},
+		{name: "node of writable kb by id", method: http.MethodPost, target: "/api/v1/node/move", body: `{"id":"node-2"}`, contentType: echo.MIMEApplicationJSON, want: http.StatusOK},
+		{name: "delete app of read-only kb", method: http.MethodDelete, target: "/api/v1/app?id=app-1", want: http.StatusServiceUnavailable},
+		{name: "update app of writable kb", method: http.MethodPut, target: "/api/v1/app?id=app-2", body: `{"name":"bot"}`, contentType: echo.MIMEApplicationJSON, want: http.StatusOK},
+		{name: "kb detail by id", method: http.MethodPut, target: "/api/v1/knowledge_base/detail", body: `{"id":"kb-1","name":"kb"}`, contentType: echo.MIMEApplicationJSON, want: http.StatusServiceUnavailable},
+		{name: "write of unknown kb while a kb is read-only", method: http.MethodPost, target: "/api/v1/model", body: `{"model":"x"}`, contentType: echo.MIMEApplicationJSON, want: http.StatusServiceUnavailable},
+		{name: "multipart upload to read-only kb", method: http.MethodPost, target: "/api/v1/file/upload", body: readOnlyUpload, contentType: readOnlyUploadType, want: http.StatusServiceUnavailable},
+		{name: "multipart upload to writable kb", method: http.MethodPost, target: "/api/v1/file/upload", body: writableUpload, contentType: writableUploadType, want: http.StatusOK},
+		{name: "maintenance api stays writable", method: http.MethodPost, target: "/api/v1/maintenance", body: `{"kb_id":"kb-1"}`, contentType: echo.MIMEApplicationJSON, want: http.StatusOK},
+		{name: "public apis are not affected", method: http.MethodPost, target: "/share/v1/chat/message?kb_id=kb-1", want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set(echo.HeaderContentType, tt.contentType)
+			}
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(req, rec)
+			var handlerBody string
+			err := m.Check(func(c echo.Context) error {
+				body, _ := io.ReadAll(c.Request().Body)
+				handlerBody = string(body)
+				return c.NoContent(http.StatusOK)
+			})(c)
+			if err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if tt.want == http.StatusOK && tt.contentType == echo.MIMEApplicationJSON && handlerBody != tt.body {
+				t.Errorf("handler body = %q, want %q", handlerBody, tt.body)
+			}
+		})
+	}
+}
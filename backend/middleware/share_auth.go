@@ -11,14 +11,16 @@ import (
 )
 
 type ShareAuthMiddleware struct {
-	logger    *log.Logger
-	kbUsecase *usecase.KnowledgeBaseUsecase
+	logger        *log.Logger
+	kbUsecase     *usecase.KnowledgeBaseUsecase
+	readerUsecase *usecase.ReaderUsecase
 }
 
-func NewShareAuthMiddleware(logger *log.Logger, kbUsecase *usecase.KnowledgeBaseUsecase) *ShareAuthMiddleware {
+func NewShareAuthMiddleware(logger *log.Logger, kbUsecase *usecase.KnowledgeBaseUsecase, readerUsecase *usecase.ReaderUsecase) *ShareAuthMiddleware {
 	return &ShareAuthMiddleware{
-		logger:    logger.WithModule("middleware.share_auth"),
-		kbUsecase: kbUsecase,
+		logger:        logger.WithModule("middleware.share_auth"),
+		kbUsecase:     kbUsecase,
+		readerUsecase: readerUsecase,
 	}
 }
 
@@ -50,6 +52,43 @@ func (h *ShareAuthMiddleware) Authorize(next echo.HandlerFunc) echo.HandlerFunc
 				})
 			}
 		}
+		viewer := h.identify(c, kb)
+		// private kbs are only read by logged in readers of the allowed groups
+		if !kb.AccessSettings.ReaderAccess.Allows(viewer) {
+			return c.JSON(http.StatusUnauthorized, domain.Response{
+				Success: false,
+				Code:    domain.ErrCodeUnauthorized,
+				Message: domain.ErrReaderLoginRequired.Error(),
+			})
+		}
+		return next(c)
+	}
+}
+
+// Identify set the viewer of public apis without authorizing the request, anonymous if kb is not found
+func (h *ShareAuthMiddleware) Identify(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		kbID := c.Request().Header.Get("X-KB-ID")
+		if kbID == "" {
+			return next(c)
+		}
+		kb, err := h.kbUsecase.GetKnowledgeBase(c.Request().Context(), kbID)
+		if err != nil {
+			h.logger.Warn("get knowledge base failed", log.String("kb_id", kbID), log.Error(err))
+			return next(c)
+		}
+		h.identify(c, kb)
 		return next(c)
 	}
 }
+
+// identify set the viewer of the request by its viewer token.
+// invalid or expired viewer tokens fall back to anonymous, public content stays available
+func (h *ShareAuthMiddleware) identify(c echo.Context, kb *domain.KnowledgeBase) *domain.NodeViewer {
+	viewer, err := h.readerUsecase.ParseViewerToken(c.Request().Context(), kb.ID, kb.AccessSettings.ViewerAuth.Secret, c.Request().Header.Get("X-Viewer-Token"))
+	if err != nil {
+		h.logger.Warn("parse viewer token failed", log.String("kb_id", kb.ID), log.Error(err))
+	}
+	c.Set(domain.ContextKeyViewer, viewer)
+	return viewer
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/usecase"
+)
+
+const telemetryFlushInterval = time.Minute
+
+// TelemetryMiddleware count requests and errors of api features in memory, the counters are flushed to
+// the telemetry usecase every minute and dropped there unless the operator enabled telemetry
+type TelemetryMiddleware struct {
+	logger           *log.Logger
+	telemetryUsecase *usecase.TelemetryUsecase
+
+	mu       sync.Mutex
+	counters map[string]domain.TelemetryCounter
+}
+
+func NewTelemetryMiddleware(logger *log.Logger, telemetryUsecase *usecase.TelemetryUsecase) *TelemetryMiddleware {
+	m := &TelemetryMiddleware{
+		logger:           logger.WithModule("middleware.telemetry"),
+		telemetryUsecase: telemetryUsecase,
+		counters:         make(map[string]domain.TelemetryCounter),
+	}
+	go m.flushLoop()
+	return m
+}
+
+func (m *TelemetryMiddleware) Count(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		err := next(c)
+		// routes of matched apis only, never the requested url with its ids and query
+		route := c.Path()
+		if !strings.HasPrefix(route, "/api/") && !strings.HasPrefix(route, "/share/") {
+			return err
+		}
+		failed := err != nil || c.Response().Status >= http.StatusInternalServerError || c.Get(domain.ContextKeyResponseError) != nil
+		feature := domain.TelemetryFeature(c.Request().Method, route)
+		m.mu.Lock()
+		counter := m.counters[feature]
+		counter.Requests++
+		if failed {
+			counter.Errors++
+		}
+		m.counters[feature] = counter
+		m.mu.Unlock()
+		return err
+	}
+}
+
+func (m *TelemetryMiddleware) flushLoop() {
+	ticker := time.NewTicker(telemetryFlushInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		m.flush(context.Background())
+	}
+}
+
+func (m *TelemetryMiddleware) flush(ctx context.Context) {
+	m.mu.Lock()
+	counters := m.counters
+	m.counters = make(map[string]domain.TelemetryCounter)
+	m.mu.Unlock()
+	if len(counters) == 0 {
+		return
+	}
+	if err := m.telemetryUsecase.SaveUsage(ctx, counters); err != nil {
+		m.logger.Error("save telemetry usage failed", log.Int("features", len(counters)), log.Error(err))
+	}
+}
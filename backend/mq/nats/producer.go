@@ -30,6 +30,26 @@ func (p *MQProducer) EnsureStreams() error {
 			name:     "scraper",
 			subjects: []string{"apps.panda-wiki.scraper.>"},
 		},
+		{
+			name:     "stat",
+			subjects: []string{"apps.panda-wiki.stat.>"},
+		},
+		{
+			name:     "node",
+			subjects: []string{"apps.panda-wiki.node.>"},
+		},
+		{
+			name:     "conversation",
+			subjects: []string{"apps.panda-wiki.conversation.>"},
+		},
+		{
+			name:     "webhook",
+			subjects: []string{"apps.panda-wiki.webhook.>"},
+		},
+		{
+			name:     "eval",
+			subjects: []string{"apps.panda-wiki.eval.>"},
+		},
 	}
 
 	for _, stream := range streams {
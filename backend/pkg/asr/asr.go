@@ -0,0 +1,147 @@
+// Package asr client of openai compatible speech recognition apis, transcribing audio and video files to text
+// segments with their time offsets.
+package asr
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// transcribing an hour of audio may take minutes
+const transcribeTimeout = 20 * time.Minute
+
+// Exts audio and video file extensions accepted by whisper compatible apis
+var Exts = []string{".mp3", ".mp4", ".mpeg", ".mpga", ".m4a", ".wav", ".webm", ".ogg", ".oga", ".flac", ".mov", ".mkv"}
+
+var ErrUnsupportedFile = errors.New("unsupported media type")
+
+var client = &http.Client{Timeout: transcribeTimeout}
+
+type Model struct {
+	Model   string
+	BaseURL string
+	APIKey  string
+	Headers map[string]string
+}
+
+// Segment text spoken between start and end, in seconds from the start of the media
+type Segment struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+	Text  string  `json:"text"`
+}
+
+type Transcript struct {
+	Language string  `json:"language"`
+	Duration float64 `json:"duration"`
+	Text     string  `json:"text"`
+	// empty if the api does not return segments, the whole text is then at offset 0
+	Segments []Segment `json:"segments"`
+}
+
+// Supported whether the media file with the name can be transcribed
+func Supported(filename string) bool {
+	ext := strings.ToLower(path.Ext(filename))
+	for _, e := range Exts {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// Transcribe upload the media to the audio transcriptions endpoint of the model, asking for verbose json to get segments
+func Transcribe(ctx context.Context, model *Model, filename string, media io.Reader) (*Transcript, error) {
+	body, writer := io.Pipe()
+	form := multipart.NewWriter(writer)
+	go func() {
+		err := form.WriteField("model", model.Model)
+		if err == nil {
+			err = form.WriteField("response_format", "verbose_json")
+		}
+		if err == nil {
+			err = form.WriteField("timestamp_granularities[]", "segment")
+		}
+		var part io.Writer
+		if err == nil {
+			part, err = form.CreateFormFile("file", path.Base(filename))
+		}
+		if err == nil {
+			_, err = io.Copy(part, media)
+		}
+		if err == nil {
+			err = form.Close()
+		}
+		writer.CloseWithError(err)
+	}()
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(model.BaseURL, "/")+"/audio/transcriptions", body)
+	if err != nil {
+		body.Close()
+		return nil, fmt.Errorf("new request failed: %w", err)
+	}
+	request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", model.APIKey))
+	request.Header.Set("Content-Type", form.FormDataContentType())
+	for k, v := range model.Headers {
+		request.Header.Set(k, v)
+	}
+	resp, err := client.Do(request)
+	if err != nil {
+		body.Close()
+		return nil, fmt.Errorf("send request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("request failed: %s %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	var transcript Transcript
+	if err := json.NewDecoder(resp.Body).Decode(&transcript); err != nil {
+		return nil, fmt.Errorf("decode response failed: %w", err)
+	}
+	segments := make([]Segment, 0, len(transcript.Segments))
+	for _, segment := range transcript.Segments {
+		segment.Text = strings.TrimSpace(segment.Text)
+		if segment.Text != "" {
+			segments = append(segments, segment)
+		}
+	}
+	transcript.Segments = segments
+	transcript.Text = strings.TrimSpace(transcript.Text)
+	if len(segments) == 0 && transcript.Text != "" {
+		transcript.Segments = []Segment{{End: transcript.Duration, Text: transcript.Text}}
+	}
+	return &transcript, nil
+}
+
+// Check transcribe a second of silence to check the model is reachable
+func Check(ctx context.Context, model *Model) error {
+	_, err := Transcribe(ctx, model, "check.wav", bytes.NewReader(silentWAV(time.Second)))
+	return err
+}
+
+// silentWAV 16khz mono 16 bit pcm wav of silence
+func silentWAV(d time.Duration) []byte {
+	const sampleRate = 16000
+	dataSize := uint32(sampleRate * 2 * d / time.Second)
+	buf := &bytes.Buffer{}
+	buf.WriteString("RIFF")
+	_ = binary.Write(buf, binary.LittleEndian, 36+dataSize)
+	buf.WriteString("WAVEfmt ")
+	for _, field := range []any{uint32(16), uint16(1), uint16(1), uint32(sampleRate), uint32(sampleRate * 2), uint16(2), uint16(16)} {
+		_ = binary.Write(buf, binary.LittleEndian, field)
+	}
+	buf.WriteString("data")
+	_ = binary.Write(buf, binary.LittleEndian, dataSize)
+	buf.Write(make([]byte, dataSize))
+	return buf.Bytes()
+}
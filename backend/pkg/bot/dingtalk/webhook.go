@@ -0,0 +1,57 @@
+package dingtalk
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// SendWebhookMarkdown send markdown message to dingtalk group by custom bot webhook
+func SendWebhookMarkdown(ctx context.Context, webhookURL, secret, title, text string) error {
+	if secret != "" {
+		timestamp := time.Now().UnixMilli()
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(fmt.Appendf(nil, "%d\n%s", timestamp, secret))
+		sign := url.QueryEscape(base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+		webhookURL = fmt.Sprintf("%s&timestamp=%d&sign=%s", webhookURL, timestamp, sign)
+	}
+	body, err := json.Marshal(map[string]any{
+		"msgtype": "markdown",
+		"markdown": map[string]string{
+			"title": title,
+			"text":  text,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		ErrCode int    `json:"errcode"`
+		ErrMsg  string `json:"errmsg"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("decode dingtalk webhook response failed: %w", err)
+	}
+	if result.ErrCode != 0 {
+		return fmt.Errorf("dingtalk webhook failed: %d %s", result.ErrCode, result.ErrMsg)
+	}
+	return nil
+}
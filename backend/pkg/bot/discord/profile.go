@@ -0,0 +1,39 @@
+package discord
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// UpdateProfile set username, avatar and application description of the bot, empty fields are unchanged.
+// discord limits username changes to a few per hour
+func (d *DiscordClient) UpdateProfile(name string, avatar []byte, description string) error {
+	if name != "" || len(avatar) > 0 {
+		avatarURI := ""
+		if len(avatar) > 0 {
+			avatarURI = fmt.Sprintf("data:%s;base64,%s", http.DetectContentType(avatar), base64.StdEncoding.EncodeToString(avatar))
+		} else {
+			// keep the current avatar, an empty avatar removes it
+			user, err := d.dg.User("@me")
+			if err != nil {
+				return fmt.Errorf("get discord bot user failed: %w", err)
+			}
+			avatarURI = user.Avatar
+		}
+		if _, err := d.dg.UserUpdate(name, avatarURI, ""); err != nil {
+			return fmt.Errorf("update discord bot user failed: %w", err)
+		}
+	}
+	if description != "" {
+		data := struct {
+			Description string `json:"description"`
+		}{description}
+		if _, err := d.dg.RequestWithBucketID(http.MethodPatch, discordgo.EndpointApplication("@me"), data, discordgo.EndpointApplications); err != nil {
+			return fmt.Errorf("update discord application failed: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package feishu
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// SendWebhookMarkdown send markdown card to feishu group by custom bot webhook
+func SendWebhookMarkdown(ctx context.Context, webhookURL, secret, title, text string) error {
+	payload := map[string]any{
+		"msg_type": "interactive",
+		"card": map[string]any{
+			"header": map[string]any{
+				"title": map[string]string{
+					"tag":     "plain_text",
+					"content": title,
+				},
+			},
+			"elements": []map[string]string{
+				{
+					"tag":     "markdown",
+					"content": text,
+				},
+			},
+		},
+	}
+	if secret != "" {
+		timestamp := time.Now().Unix()
+		mac := hmac.New(sha256.New, fmt.Appendf(nil, "%d\n%s", timestamp, secret))
+		payload["timestamp"] = strconv.FormatInt(timestamp, 10)
+		payload["sign"] = base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("decode feishu webhook response failed: %w", err)
+	}
+	if result.Code != 0 {
+		return fmt.Errorf("feishu webhook failed: %d %s", result.Code, result.Msg)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package wechat
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+)
+
+type apiResult struct {
+	Errcode int    `json:"errcode"`
+	Errmsg  string `json:"errmsg"`
+	MediaID string `json:"media_id"`
+}
+
+// UpdateAgentProfile set name, description and logo of the wecom app, empty fields are unchanged
+func (cfg *WechatConfig) UpdateAgentProfile(name, description string, logo []byte, logoName string) error {
+	token, err := cfg.GetAccessToken()
+	if err != nil {
+		return err
+	}
+	agentID, err := strconv.Atoi(cfg.AgentID)
+	if err != nil {
+		return fmt.Errorf("invalid agent id %q: %w", cfg.AgentID, err)
+	}
+	data := map[string]any{"agentid": agentID}
+	if name != "" {
+		data["name"] = name
+	}
+	if description != "" {
+		data["description"] = description
+	}
+	if len(logo) > 0 {
+		mediaID, err := uploadImage(token, logo, logoName)
+		if err != nil {
+			return err
+		}
+		data["logo_mediaid"] = mediaID
+	}
+	body, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("https://qyapi.weixin.qq.com/cgi-bin/agent/set?access_token=%s", token)
+	resp, err := http.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("set wecom agent failed: %w", err)
+	}
+	defer resp.Body.Close()
+	_, err = decodeResult(resp.Body)
+	return err
+}
+
+// uploadImage upload temporary image media, the media id is valid for 3 days
+func uploadImage(token string, image []byte, filename string) (string, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("media", filename)
+	if err != nil {
+		return "", err
+	}
+	if _, err := part.Write(image); err != nil {
+		return "", err
+	}
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+	url := fmt.Sprintf("https://qyapi.weixin.qq.com/cgi-bin/media/upload?access_token=%s&type=image", token)
+	resp, err := http.Post(url, writer.FormDataContentType(), &body)
+	if err != nil {
+		return "", fmt.Errorf("upload wecom media failed: %w", err)
+	}
+	defer resp.Body.Close()
+	result, err := decodeResult(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return result.MediaID, nil
+}
+
+func decodeResult(r io.Reader) (*apiResult, error) {
+	var result apiResult
+	if err := json.NewDecoder(r).Decode(&result); err != nil {
+		return nil, fmt.Errorf("json decode wechat resp failed: %w", err)
+	}
+	if result.Errcode != 0 {
+		return nil, fmt.Errorf("wechat api failed: %s (code: %d)", result.Errmsg, result.Errcode)
+	}
+	return &result, nil
+}
@@ -0,0 +1,221 @@
+// Package confluence read pages and attachments of a space through the rest api of Confluence Cloud and Server.
+package confluence
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// pageLimit results of each list request, cloud caps larger limits
+const pageLimit = 50
+
+type Client struct {
+	baseURL    string
+	username   string
+	token      string
+	httpClient *http.Client
+}
+
+// NewClient client of the site at baseURL, e.g. https://example.atlassian.net/wiki.
+// cloud authenticates with the account email and an api token, server with a personal access token and no username
+func NewClient(baseURL, username, token string) *Client {
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		username:   username,
+		token:      token,
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+type Space struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+}
+
+type Page struct {
+	ID       string
+	Title    string
+	Version  int
+	ParentID string // empty for top level pages of the space
+}
+
+type Attachment struct {
+	ID          string
+	Title       string
+	Version     int
+	MediaType   string
+	Size        int64
+	DownloadURL string
+}
+
+type content struct {
+	ID        string `json:"id"`
+	Title     string `json:"title"`
+	Ancestors []struct {
+		ID string `json:"id"`
+	} `json:"ancestors"`
+	Version struct {
+		Number int `json:"number"`
+	} `json:"version"`
+	Body struct {
+		Storage struct {
+			Value string `json:"value"`
+		} `json:"storage"`
+	} `json:"body"`
+	Metadata struct {
+		MediaType string `json:"mediaType"`
+	} `json:"metadata"`
+	Extensions struct {
+		MediaType string `json:"mediaType"`
+		FileSize  int64  `json:"fileSize"`
+	} `json:"extensions"`
+	Links struct {
+		Download string `json:"download"`
+	} `json:"_links"`
+}
+
+type contentList struct {
+	Results []*content `json:"results"`
+	Size    int        `json:"size"`
+	Links   struct {
+		Next string `json:"next"`
+	} `json:"_links"`
+}
+
+// GetSpace check access to the space
+func (c *Client) GetSpace(ctx context.Context, key string) (*Space, error) {
+	space := &Space{}
+	if err := c.get(ctx, "/rest/api/space/"+url.PathEscape(key), nil, space); err != nil {
+		return nil, err
+	}
+	return space, nil
+}
+
+// ListPages current pages of the space with their parent and version, without body
+func (c *Client) ListPages(ctx context.Context, spaceKey string) ([]*Page, error) {
+	query := url.Values{
+		"spaceKey": {spaceKey},
+		"type":     {"page"},
+		"status":   {"current"},
+		"expand":   {"ancestors,version"},
+	}
+	items, err := c.list(ctx, "/rest/api/content", query)
+	if err != nil {
+		return nil, err
+	}
+	pages := make([]*Page, 0, len(items))
+	for _, item := range items {
+		page := &Page{ID: item.ID, Title: item.Title, Version: item.Version.Number}
+		if len(item.Ancestors) > 0 {
+			page.ParentID = item.Ancestors[len(item.Ancestors)-1].ID
+		}
+		pages = append(pages, page)
+	}
+	return pages, nil
+}
+
+// GetPageBody storage format xhtml of the page
+func (c *Client) GetPageBody(ctx context.Context, id string) (string, error) {
+	item := &content{}
+	if err := c.get(ctx, "/rest/api/content/"+url.PathEscape(id), url.Values{"expand": {"body.storage"}}, item); err != nil {
+		return "", err
+	}
+	return item.Body.Storage.Value, nil
+}
+
+// ListAttachments current attachments of the page
+func (c *Client) ListAttachments(ctx context.Context, pageID string) ([]*Attachment, error) {
+	items, err := c.list(ctx, "/rest/api/content/"+url.PathEscape(pageID)+"/child/attachment", url.Values{"expand": {"version"}})
+	if err != nil {
+		return nil, err
+	}
+	attachments := make([]*Attachment, 0, len(items))
+	for _, item := range items {
+		mediaType := item.Extensions.MediaType
+		if mediaType == "" {
+			mediaType = item.Metadata.MediaType
+		}
+		attachments = append(attachments, &Attachment{
+			ID:          item.ID,
+			Title:       item.Title,
+			Version:     item.Version.Number,
+			MediaType:   mediaType,
+			Size:        item.Extensions.FileSize,
+			DownloadURL: item.Links.Download,
+		})
+	}
+	return attachments, nil
+}
+
+// Download content of the attachment, the caller closes the body
+func (c *Client) Download(ctx context.Context, attachment *Attachment) (io.ReadCloser, int64, error) {
+	resp, err := c.do(ctx, attachment.DownloadURL, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	return resp.Body, resp.ContentLength, nil
+}
+
+func (c *Client) list(ctx context.Context, path string, query url.Values) ([]*content, error) {
+	items := make([]*content, 0)
+	query.Set("limit", strconv.Itoa(pageLimit))
+	for start := 0; ; {
+		query.Set("start", strconv.Itoa(start))
+		result := &contentList{}
+		if err := c.get(ctx, path, query, result); err != nil {
+			return nil, err
+		}
+		items = append(items, result.Results...)
+		if result.Links.Next == "" || result.Size == 0 {
+			return items, nil
+		}
+		start += result.Size
+	}
+}
+
+func (c *Client) get(ctx context.Context, path string, query url.Values, v any) error {
+	resp, err := c.do(ctx, path, query)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decode confluence response failed: %w", err)
+	}
+	return nil
+}
+
+// do get path relative to the base url, non 2xx responses are errors
+func (c *Client) do(ctx context.Context, path string, query url.Values) (*http.Response, error) {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("confluence %s %s: %s", path, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return resp, nil
+}
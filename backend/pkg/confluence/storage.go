@@ -0,0 +1,270 @@
+package confluence
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	cdataRegex = regexp.MustCompile(`(?s)<!\[CDATA\[(.*?)\]\]>`)
+	// the html parser ignores self-closing of unknown elements, so ac and ri elements are closed explicitly
+	selfClosingRegex = regexp.MustCompile(`<((?:ac|ri):[a-zA-Z-]+)([^<>]*?)\s*/>`)
+)
+
+// Resolver urls of pages and attachments referenced by storage format, empty if the target is not imported
+type Resolver interface {
+	PageURL(spaceKey, title string) string
+	AttachmentURL(filename string) string
+}
+
+// ConvertStorage convert storage format xhtml of a page to plain html.
+// images, links, code blocks and panels are converted, other macros keep their rich text body or are dropped
+func ConvertStorage(storage string, resolver Resolver) (string, error) {
+	storage = cdataRegex.ReplaceAllStringFunc(storage, func(match string) string {
+		return html.EscapeString(cdataRegex.FindStringSubmatch(match)[1])
+	})
+	storage = selfClosingRegex.ReplaceAllString(storage, "<$1$2></$1>")
+	body := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(storage), body)
+	if err != nil {
+		return "", err
+	}
+	for _, n := range nodes {
+		body.AppendChild(n)
+	}
+	c := &converter{resolver: resolver}
+	c.convertChildren(body)
+	var buf bytes.Buffer
+	for n := body.FirstChild; n != nil; n = n.NextSibling {
+		if err := html.Render(&buf, n); err != nil {
+			return "", err
+		}
+	}
+	return buf.String(), nil
+}
+
+type converter struct {
+	resolver Resolver
+}
+
+// convertChildren replace confluence elements under parent by their html
+func (c *converter) convertChildren(parent *html.Node) {
+	for n := parent.FirstChild; n != nil; {
+		next := n.NextSibling
+		if n.Type == html.ElementNode && strings.Contains(n.Data, ":") {
+			for _, replacement := range c.convert(n) {
+				parent.InsertBefore(replacement, n)
+			}
+			parent.RemoveChild(n)
+		} else {
+			c.convertChildren(n)
+		}
+		n = next
+	}
+}
+
+func (c *converter) convert(n *html.Node) []*html.Node {
+	switch n.Data {
+	case "ac:structured-macro", "ac:macro":
+		return c.macro(n)
+	case "ac:image":
+		return c.image(n)
+	case "ac:link":
+		return c.link(n)
+	case "ac:emoticon":
+		if fallback := attr(n, "ac:emoji-fallback"); fallback != "" {
+			return []*html.Node{text(fallback)}
+		}
+		return nil
+	case "ac:task-list":
+		list := element("ul")
+		for _, task := range children(n, "ac:task") {
+			item := element("li")
+			if body := child(task, "ac:task-body"); body != nil {
+				c.convertChildren(body)
+				appendAll(item, detach(body))
+			}
+			list.AppendChild(item)
+		}
+		return []*html.Node{list}
+	case "ac:parameter", "ac:placeholder", "ac:task-id", "ac:task-status":
+		return nil
+	default:
+		c.convertChildren(n)
+		return detach(n)
+	}
+}
+
+func (c *converter) macro(n *html.Node) []*html.Node {
+	name := attr(n, "ac:name")
+	if name == "code" || name == "noformat" {
+		code := element("code")
+		if language := param(n, "language"); language != "" {
+			code.Attr = []html.Attribute{{Key: "class", Val: "language-" + language}}
+		}
+		code.AppendChild(text(textContent(child(n, "ac:plain-text-body"))))
+		pre := element("pre")
+		pre.AppendChild(code)
+		return []*html.Node{pre}
+	}
+	body := child(n, "ac:rich-text-body")
+	if body == nil {
+		// toc, children, jira and other dynamic macros have nothing to import
+		return nil
+	}
+	c.convertChildren(body)
+	nodes := detach(body)
+	if title := param(n, "title"); title != "" {
+		strong := element("strong")
+		strong.AppendChild(text(title))
+		p := element("p")
+		p.AppendChild(strong)
+		nodes = append([]*html.Node{p}, nodes...)
+	}
+	switch name {
+	case "info", "note", "tip", "warning", "panel":
+		quote := element("blockquote")
+		appendAll(quote, nodes)
+		return []*html.Node{quote}
+	}
+	return nodes
+}
+
+func (c *converter) image(n *html.Node) []*html.Node {
+	src := ""
+	if attachment := child(n, "ri:attachment"); attachment != nil {
+		src = c.resolver.AttachmentURL(attr(attachment, "ri:filename"))
+	} else if u := child(n, "ri:url"); u != nil {
+		src = attr(u, "ri:value")
+	}
+	if src == "" {
+		return nil
+	}
+	img := element("img")
+	img.Attr = []html.Attribute{{Key: "src", Val: src}, {Key: "alt", Val: attr(n, "ac:alt")}}
+	if width := attr(n, "ac:width"); width != "" {
+		img.Attr = append(img.Attr, html.Attribute{Key: "width", Val: width})
+	}
+	return []*html.Node{img}
+}
+
+func (c *converter) link(n *html.Node) []*html.Node {
+	href, label := "", ""
+	targeted := true
+	if page := child(n, "ri:page"); page != nil {
+		label = attr(page, "ri:content-title")
+		href = c.resolver.PageURL(attr(page, "ri:space-key"), label)
+	} else if attachment := child(n, "ri:attachment"); attachment != nil {
+		label = attr(attachment, "ri:filename")
+		href = c.resolver.AttachmentURL(label)
+	} else if u := child(n, "ri:url"); u != nil {
+		label = attr(u, "ri:value")
+		href = label
+	} else {
+		targeted = false
+	}
+	// anchors without target are in the same page
+	if anchor := attr(n, "ac:anchor"); anchor != "" && (href != "" || !targeted) {
+		href += "#" + anchor
+	}
+	var nodes []*html.Node
+	if body := child(n, "ac:link-body"); body != nil {
+		c.convertChildren(body)
+		nodes = detach(body)
+	} else if body := child(n, "ac:plain-text-link-body"); body != nil {
+		label = textContent(body)
+	}
+	if len(nodes) == 0 && label != "" {
+		nodes = []*html.Node{text(label)}
+	}
+	if href == "" {
+		return nodes
+	}
+	a := element("a")
+	a.Attr = []html.Attribute{{Key: "href", Val: href}}
+	appendAll(a, nodes)
+	return []*html.Node{a}
+}
+
+func element(tag string) *html.Node {
+	return &html.Node{Type: html.ElementNode, Data: tag, DataAtom: atom.Lookup([]byte(tag))}
+}
+
+func text(s string) *html.Node {
+	return &html.Node{Type: html.TextNode, Data: s}
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// child first child element with the tag
+func child(n *html.Node, tag string) *html.Node {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.Data == tag {
+			return c
+		}
+	}
+	return nil
+}
+
+func children(n *html.Node, tag string) []*html.Node {
+	var nodes []*html.Node
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.Data == tag {
+			nodes = append(nodes, c)
+		}
+	}
+	return nodes
+}
+
+// param value of the macro parameter
+func param(n *html.Node, name string) string {
+	for _, p := range children(n, "ac:parameter") {
+		if attr(p, "ac:name") == name {
+			return strings.TrimSpace(textContent(p))
+		}
+	}
+	return ""
+}
+
+func textContent(n *html.Node) string {
+	if n == nil {
+		return ""
+	}
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var sb strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		sb.WriteString(textContent(c))
+	}
+	return sb.String()
+}
+
+// detach remove and return children of n
+func detach(n *html.Node) []*html.Node {
+	var nodes []*html.Node
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		n.RemoveChild(c)
+		nodes = append(nodes, c)
+		c = next
+	}
+	return nodes
+}
+
+func appendAll(parent *html.Node, nodes []*html.Node) {
+	for _, n := range nodes {
+		parent.AppendChild(n)
+	}
+}
@@ -0,0 +1,99 @@
+// Package docparser client of the layout aware document parsing service, converting pdf, word and powerpoint
+// files to markdown with their headings and tables.
+package docparser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// parsing large files with ocr may take minutes
+const parseTimeout = 10 * time.Minute
+
+// Exts file extensions the service parses
+var Exts = []string{".pdf", ".docx", ".pptx"}
+
+var ErrUnsupportedFile = errors.New("unsupported document type")
+
+type Client struct {
+	url    string
+	client *http.Client
+}
+
+// NewClient client of the parse endpoint of the service
+func NewClient(url string) *Client {
+	return &Client{url: url, client: &http.Client{Timeout: parseTimeout}}
+}
+
+// Document markdown of a parsed file, headings of the file are markdown headings and tables are markdown tables
+type Document struct {
+	// title in the metadata of the file or its first heading, empty if it has none
+	Title    string `json:"title"`
+	Markdown string `json:"markdown"`
+}
+
+// Supported whether the service parses files with the name
+func Supported(filename string) bool {
+	ext := strings.ToLower(path.Ext(filename))
+	for _, e := range Exts {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse upload the file to the service as multipart form data
+func (c *Client) Parse(ctx context.Context, filename string, file io.Reader) (*Document, error) {
+	if !Supported(filename) {
+		return nil, ErrUnsupportedFile
+	}
+	body, writer := io.Pipe()
+	form := multipart.NewWriter(writer)
+	go func() {
+		part, err := form.CreateFormFile("file", filename)
+		if err == nil {
+			_, err = io.Copy(part, file)
+		}
+		if err == nil {
+			err = form.Close()
+		}
+		writer.CloseWithError(err)
+	}()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, body)
+	if err != nil {
+		body.Close()
+		return nil, err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("parse %s: %s", filename, resp.Status)
+	}
+	var result struct {
+		Err  int      `json:"err"`
+		Msg  string   `json:"msg"`
+		Data Document `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode parse result failed: %w", err)
+	}
+	if result.Err != 0 {
+		return nil, fmt.Errorf("parse %s: %s", filename, result.Msg)
+	}
+	result.Data.Title = strings.TrimSpace(result.Data.Title)
+	result.Data.Markdown = strings.TrimSpace(result.Data.Markdown)
+	return &result.Data, nil
+}
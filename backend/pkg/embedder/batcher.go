@@ -0,0 +1,190 @@
+package embedder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// timeout of one batch, it serves several callers so their contexts do not apply
+const batchTimeout = 2 * time.Minute
+
+var ErrBatcherClosed = errors.New("embedder is closed")
+
+type BatcherConfig struct {
+	// texts of a batch, requests of the same model are merged until it is reached
+	BatchSize int
+	// max wait of a request for others to join its batch
+	BatchWait time.Duration
+	// batches embedded at the same time, 1 keeps a single gpu busy without contention
+	Workers int
+	// requests waiting for a batch, callers block when it is full
+	QueueSize int
+}
+
+type job struct {
+	model  *Model
+	texts  []string
+	result chan jobResult
+}
+
+type jobResult struct {
+	embeddings [][]float32
+	err        error
+}
+
+type batch struct {
+	model *Model
+	jobs  []*job
+	size  int
+}
+
+// Batcher merge concurrent embed requests of the same model into batches and embed them by a fixed number of workers
+type Batcher struct {
+	embed   EmbedFunc
+	config  BatcherConfig
+	queue   chan *job
+	batches chan *batch
+	done    chan struct{}
+}
+
+func NewBatcher(embed EmbedFunc, config BatcherConfig) *Batcher {
+	if config.BatchSize <= 0 {
+		config.BatchSize = 64
+	}
+	if config.Workers <= 0 {
+		config.Workers = 1
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = 1024
+	}
+	b := &Batcher{
+		embed:   embed,
+		config:  config,
+		queue:   make(chan *job, config.QueueSize),
+		batches: make(chan *batch),
+		done:    make(chan struct{}),
+	}
+	go b.collect()
+	for range config.Workers {
+		go b.work()
+	}
+	return b
+}
+
+// Embed queue the texts and wait for their embeddings
+func (b *Batcher) Embed(ctx context.Context, model *Model, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return [][]float32{}, nil
+	}
+	j := &job{model: model, texts: texts, result: make(chan jobResult, 1)}
+	select {
+	case b.queue <- j:
+	case <-b.done:
+		return nil, ErrBatcherClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	select {
+	case r := <-j.result:
+		return r.embeddings, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Close stop accepting requests, queued ones are dropped
+func (b *Batcher) Close() {
+	close(b.done)
+}
+
+// collect group queued jobs by model, a group is flushed once it is full or its oldest job waited long enough
+func (b *Batcher) collect() {
+	pending := make(map[string]*batch)
+	deadlines := make(map[string]time.Time)
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+	flush := func(key string) bool {
+		bt := pending[key]
+		delete(pending, key)
+		delete(deadlines, key)
+		select {
+		case b.batches <- bt:
+			return true
+		case <-b.done:
+			return false
+		}
+	}
+	resetTimer := func() {
+		next := time.Now().Add(time.Hour)
+		for _, deadline := range deadlines {
+			if deadline.Before(next) {
+				next = deadline
+			}
+		}
+		timer.Reset(time.Until(next))
+	}
+	for {
+		select {
+		case <-b.done:
+			return
+		case j := <-b.queue:
+			key := j.model.key()
+			bt, ok := pending[key]
+			if !ok {
+				bt = &batch{model: j.model}
+				pending[key] = bt
+				deadlines[key] = time.Now().Add(b.config.BatchWait)
+			}
+			bt.jobs = append(bt.jobs, j)
+			bt.size += len(j.texts)
+			if bt.size >= b.config.BatchSize && !flush(key) {
+				return
+			}
+			resetTimer()
+		case <-timer.C:
+			now := time.Now()
+			for key, deadline := range deadlines {
+				if !deadline.After(now) && !flush(key) {
+					return
+				}
+			}
+			resetTimer()
+		}
+	}
+}
+
+func (b *Batcher) work() {
+	for {
+		select {
+		case <-b.done:
+			return
+		case bt := <-b.batches:
+			b.run(bt)
+		}
+	}
+}
+
+// run embed texts of all jobs of the batch in one call and hand each job its part
+func (b *Batcher) run(bt *batch) {
+	texts := make([]string, 0, bt.size)
+	for _, j := range bt.jobs {
+		texts = append(texts, j.texts...)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), batchTimeout)
+	defer cancel()
+	embeddings, err := b.embed(ctx, bt.model, texts)
+	if err == nil && len(embeddings) != len(texts) {
+		err = fmt.Errorf("embedding count mismatch: want %d, got %d", len(texts), len(embeddings))
+	}
+	offset := 0
+	for _, j := range bt.jobs {
+		if err != nil {
+			j.result <- jobResult{err: err}
+			continue
+		}
+		j.result <- jobResult{embeddings: embeddings[offset : offset+len(j.texts)]}
+		offset += len(j.texts)
+	}
+}
@@ -0,0 +1,42 @@
+package embedder
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Client client of the embedder service
+type Client struct {
+	conn *grpc.ClientConn
+}
+
+// NewClient client of the service at addr, e.g. panda-wiki-embedder:9000, it connects lazily on the first request
+func NewClient(addr string) (*Client, error) {
+	conn, err := grpc.NewClient(addr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.CallContentSubtype(codecName)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{conn: conn}, nil
+}
+
+// Embed embeddings of texts in their order
+func (c *Client) Embed(ctx context.Context, model *Model, texts []string) ([][]float32, error) {
+	resp := &EmbedResponse{}
+	if err := c.conn.Invoke(ctx, embedMethod, &EmbedRequest{Model: *model, Texts: texts}, resp); err != nil {
+		return nil, fmt.Errorf("embedder: %w", err)
+	}
+	if len(resp.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("embedding count mismatch: want %d, got %d", len(texts), len(resp.Embeddings))
+	}
+	return resp.Embeddings, nil
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
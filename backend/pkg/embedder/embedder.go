@@ -0,0 +1,108 @@
+// Package embedder embedding service run as a separate worker, the api and consumer delegate embedding to it over grpc.
+// messages are encoded as json, so the service needs no generated protobuf code.
+package embedder
+
+import (
+	"context"
+	"encoding/json"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+)
+
+const (
+	codecName   = "json"
+	serviceName = "pandawiki.embedder.Embedder"
+	embedMethod = "/" + serviceName + "/Embed"
+)
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return codecName
+}
+
+// Model openai compatible embedding api
+type Model struct {
+	Model   string            `json:"model"`
+	BaseURL string            `json:"base_url"`
+	APIKey  string            `json:"api_key"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// key requests of the same key are batched together
+func (m *Model) key() string {
+	headers, _ := json.Marshal(m.Headers)
+	return m.BaseURL + "\x00" + m.Model + "\x00" + m.APIKey + "\x00" + string(headers)
+}
+
+type EmbedRequest struct {
+	Model Model    `json:"model"`
+	Texts []string `json:"texts"`
+}
+
+type EmbedResponse struct {
+	Embeddings [][]float32 `json:"embeddings"`
+}
+
+// EmbedFunc embed texts with the model, embeddings are in the order of texts
+type EmbedFunc func(ctx context.Context, model *Model, texts []string) ([][]float32, error)
+
+type embedderServer interface {
+	Embed(ctx context.Context, req *EmbedRequest) (*EmbedResponse, error)
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*embedderServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Embed",
+			Handler:    embedHandler,
+		},
+	},
+	Metadata: "embedder",
+}
+
+func embedHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	req := &EmbedRequest{}
+	if err := dec(req); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(embedderServer).Embed(ctx, req)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: embedMethod}
+	return interceptor(ctx, req, info, func(ctx context.Context, req any) (any, error) {
+		return srv.(embedderServer).Embed(ctx, req.(*EmbedRequest))
+	})
+}
+
+// Register serve embed requests of the batcher on the grpc server
+func Register(s *grpc.Server, batcher *Batcher) {
+	s.RegisterService(&serviceDesc, &server{batcher: batcher})
+}
+
+type server struct {
+	batcher *Batcher
+}
+
+func (s *server) Embed(ctx context.Context, req *EmbedRequest) (*EmbedResponse, error) {
+	embeddings, err := s.batcher.Embed(ctx, &req.Model, req.Texts)
+	if err != nil {
+		return nil, err
+	}
+	return &EmbedResponse{Embeddings: embeddings}, nil
+}
@@ -0,0 +1,70 @@
+package embedder
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+// texts of each request to the embedding api
+const apiBatchSize = 32
+
+// EmbedOpenAI get embeddings of texts by openai compatible embedding api
+func EmbedOpenAI(ctx context.Context, model *Model, texts []string) ([][]float32, error) {
+	embeddings := make([][]float32, 0, len(texts))
+	for _, batch := range lo.Chunk(texts, apiBatchSize) {
+		body, err := json.Marshal(map[string]any{
+			"model":           model.Model,
+			"input":           batch,
+			"encoding_format": "float",
+		})
+		if err != nil {
+			return nil, fmt.Errorf("marshal request body failed: %w", err)
+		}
+		request, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(model.BaseURL, "/")+"/embeddings", bytes.NewBuffer(body))
+		if err != nil {
+			return nil, fmt.Errorf("new request failed: %w", err)
+		}
+		request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", model.APIKey))
+		request.Header.Set("Content-Type", "application/json")
+		for k, v := range model.Headers {
+			request.Header.Set(k, v)
+		}
+		resp, err := http.DefaultClient.Do(request)
+		if err != nil {
+			return nil, fmt.Errorf("send request failed: %w", err)
+		}
+		var result struct {
+			Data []struct {
+				Index     int       `json:"index"`
+				Embedding []float32 `json:"embedding"`
+			} `json:"data"`
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("request failed: %s", resp.Status)
+		}
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("decode response failed: %w", err)
+		}
+		if len(result.Data) != len(batch) {
+			return nil, fmt.Errorf("embedding count mismatch: want %d, got %d", len(batch), len(result.Data))
+		}
+		batchEmbeddings := make([][]float32, len(batch))
+		for _, item := range result.Data {
+			if item.Index < 0 || item.Index >= len(batch) {
+				return nil, fmt.Errorf("invalid embedding index: %d", item.Index)
+			}
+			batchEmbeddings[item.Index] = item.Embedding
+		}
+		embeddings = append(embeddings, batchEmbeddings...)
+	}
+	return embeddings, nil
+}
@@ -0,0 +1,191 @@
+// Package feishudoc read documents of a Feishu/Lark wiki space or drive folder with the tenant token of an app.
+package feishudoc
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/Wsine/feishu2md/core"
+	lark "github.com/larksuite/oapi-sdk-go/v3"
+	larkdrive1 "github.com/larksuite/oapi-sdk-go/v3/service/drive/v1"
+	larkwiki2 "github.com/larksuite/oapi-sdk-go/v3/service/wiki/v2"
+)
+
+const (
+	// results of each list request, the api caps larger sizes
+	wikiPageSize  = 50
+	drivePageSize = 200
+
+	DocTypeDocx   = "docx"
+	DocTypeFolder = "folder"
+)
+
+type Client struct {
+	client *lark.Client
+	docs   *core.Client
+}
+
+// NewClient client of a custom app, the app must be added to the wiki space or granted the folder
+func NewClient(appID, appSecret string) *Client {
+	return &Client{
+		client: lark.NewClient(appID, appSecret),
+		docs:   core.NewClient(appID, appSecret),
+	}
+}
+
+// Page wiki node or drive file, only docx documents have content
+type Page struct {
+	// node token of wiki nodes, file token of drive files
+	ID       string
+	ParentID string
+	Title    string
+	DocType  string
+	DocToken string
+	// edit time of the document
+	Version string
+}
+
+// GetSpaceName name of the wiki space, checks access to it
+func (c *Client) GetSpaceName(ctx context.Context, spaceID string) (string, error) {
+	resp, err := c.client.Wiki.V2.Space.Get(ctx, larkwiki2.NewGetSpaceReqBuilder().SpaceId(spaceID).Build())
+	if err != nil {
+		return "", err
+	}
+	if !resp.Success() {
+		return "", fmt.Errorf("get wiki space failed: %s", resp.Msg)
+	}
+	if resp.Data.Space == nil || resp.Data.Space.Name == nil {
+		return "", nil
+	}
+	return *resp.Data.Space.Name, nil
+}
+
+// ListWikiPages all nodes of the space, parents before their children
+func (c *Client) ListWikiPages(ctx context.Context, spaceID string) ([]*Page, error) {
+	pages := make([]*Page, 0)
+	parents := []string{""}
+	for len(parents) > 0 {
+		parentID := parents[0]
+		parents = parents[1:]
+		for pageToken := ""; ; {
+			builder := larkwiki2.NewListSpaceNodeReqBuilder().SpaceId(spaceID).PageSize(wikiPageSize)
+			if parentID != "" {
+				builder.ParentNodeToken(parentID)
+			}
+			if pageToken != "" {
+				builder.PageToken(pageToken)
+			}
+			resp, err := c.client.Wiki.V2.SpaceNode.List(ctx, builder.Build())
+			if err != nil {
+				return nil, err
+			}
+			if !resp.Success() {
+				return nil, fmt.Errorf("list wiki nodes failed: %s", resp.Msg)
+			}
+			for _, node := range resp.Data.Items {
+				page := &Page{
+					ID:       deref(node.NodeToken),
+					ParentID: parentID,
+					Title:    deref(node.Title),
+					DocType:  deref(node.ObjType),
+					DocToken: deref(node.ObjToken),
+					Version:  deref(node.ObjEditTime),
+				}
+				pages = append(pages, page)
+				if node.HasChild != nil && *node.HasChild {
+					parents = append(parents, page.ID)
+				}
+			}
+			if resp.Data.HasMore == nil || !*resp.Data.HasMore || deref(resp.Data.PageToken) == "" {
+				break
+			}
+			pageToken = *resp.Data.PageToken
+		}
+	}
+	return pages, nil
+}
+
+// CheckFolder check access to the drive folder
+func (c *Client) CheckFolder(ctx context.Context, folderToken string) error {
+	resp, err := c.client.Drive.V1.File.List(ctx, larkdrive1.NewListFileReqBuilder().FolderToken(folderToken).PageSize(1).Build())
+	if err != nil {
+		return err
+	}
+	if !resp.Success() {
+		return fmt.Errorf("list drive files failed: %s", resp.Msg)
+	}
+	return nil
+}
+
+// ListFolderPages files of the folder and its sub folders, parents before their children
+func (c *Client) ListFolderPages(ctx context.Context, folderToken string) ([]*Page, error) {
+	pages := make([]*Page, 0)
+	folders := []string{folderToken}
+	for len(folders) > 0 {
+		folder := folders[0]
+		folders = folders[1:]
+		for pageToken := ""; ; {
+			builder := larkdrive1.NewListFileReqBuilder().FolderToken(folder).PageSize(drivePageSize)
+			if pageToken != "" {
+				builder.PageToken(pageToken)
+			}
+			resp, err := c.client.Drive.V1.File.List(ctx, builder.Build())
+			if err != nil {
+				return nil, err
+			}
+			if !resp.Success() {
+				return nil, fmt.Errorf("list drive files failed: %s", resp.Msg)
+			}
+			for _, file := range resp.Data.Files {
+				page := &Page{
+					ID:       deref(file.Token),
+					Title:    deref(file.Name),
+					DocType:  deref(file.Type),
+					DocToken: deref(file.Token),
+					Version:  deref(file.ModifiedTime),
+				}
+				// files of the root folder are top level pages
+				if folder != folderToken {
+					page.ParentID = folder
+				}
+				pages = append(pages, page)
+				if page.DocType == DocTypeFolder {
+					folders = append(folders, page.ID)
+				}
+			}
+			if resp.Data.HasMore == nil || !*resp.Data.HasMore || deref(resp.Data.NextPageToken) == "" {
+				break
+			}
+			pageToken = *resp.Data.NextPageToken
+		}
+	}
+	return pages, nil
+}
+
+// GetDocument markdown of the docx document with html tags for layout, images are referenced by their tokens
+func (c *Client) GetDocument(ctx context.Context, docToken string) (string, []string, error) {
+	docx, blocks, err := c.docs.GetDocxContent(ctx, docToken)
+	if err != nil {
+		return "", nil, err
+	}
+	parser := core.NewParser(core.OutputConfig{UseHTMLTags: true})
+	content := parser.ParseDocxContent(docx, blocks)
+	return content, parser.ImgTokens, nil
+}
+
+// DownloadImage name and content of the image of the token
+func (c *Client) DownloadImage(ctx context.Context, token string) (string, []byte, error) {
+	filename, data, err := c.docs.DownloadImageRaw(ctx, token, "")
+	if err != nil {
+		return "", nil, err
+	}
+	return path.Base(filename), data, nil
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,180 @@
+// Package gitrepo shallow clone of a git repo by the git command, files are listed with the hash of their blob.
+package gitrepo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	ErrBranchNotFound = errors.New("branch not found in git repo")
+	ErrNotRegularFile = errors.New("not a regular file of the git repo")
+)
+
+// regularModes modes of regular and executable files, symlinks (120000) and submodules are skipped
+var regularModes = map[string]bool{"100644": true, "100755": true}
+
+// File file of the repo, the blob hash changes with its content
+type File struct {
+	Path string
+	Blob string
+}
+
+// Name name of the repo from its url, e.g. docs of https://github.com/group/docs.git
+func Name(repoURL string) string {
+	u, err := url.Parse(repoURL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(path.Base(strings.TrimSuffix(u.Path, "/")), ".git")
+}
+
+// authURL https url with the token as credentials, github and gitlab both accept tokens as password
+func authURL(repoURL, token string) (string, error) {
+	u, err := url.Parse(repoURL)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return "", fmt.Errorf("unsupported git url scheme: %s", u.Scheme)
+	}
+	if token != "" {
+		username := "oauth2"
+		if strings.HasSuffix(u.Host, "github.com") {
+			username = "x-access-token"
+		}
+		u.User = url.UserPassword(username, token)
+	}
+	return u.String(), nil
+}
+
+// CheckBranch check access to the repo and that the branch exists, the default branch if empty
+func CheckBranch(ctx context.Context, repoURL, branch, token string) error {
+	remote, err := authURL(repoURL, token)
+	if err != nil {
+		return err
+	}
+	args := []string{"ls-remote", "--heads", remote}
+	if branch != "" {
+		args = append(args, "refs/heads/"+branch)
+	}
+	out, err := run(ctx, "", token, args...)
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(out)) == 0 {
+		return ErrBranchNotFound
+	}
+	return nil
+}
+
+// Clone latest commit of the branch into dir, without history
+func Clone(ctx context.Context, repoURL, branch, token, dir string) error {
+	remote, err := authURL(repoURL, token)
+	if err != nil {
+		return err
+	}
+	args := []string{"clone", "--depth", "1", "--single-branch", "--no-tags"}
+	if branch != "" {
+		args = append(args, "--branch", branch)
+	}
+	_, err = run(ctx, "", token, append(args, "--", remote, dir)...)
+	return err
+}
+
+// ListFiles files of the cloned repo under the directory, all files if it is empty
+func ListFiles(ctx context.Context, dir, subPath string) ([]*File, error) {
+	args := []string{"ls-tree", "-r", "-z", "HEAD"}
+	if subPath != "" {
+		args = append(args, "--", subPath)
+	}
+	out, err := run(ctx, dir, "", args...)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]*File, 0)
+	for _, entry := range strings.Split(string(out), "\x00") {
+		// <mode> SP <type> SP <hash> TAB <path>
+		meta, filePath, ok := strings.Cut(entry, "\t")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(meta)
+		if len(fields) != 3 || fields[1] != "blob" || !regularModes[fields[0]] {
+			continue
+		}
+		files = append(files, &File{Path: filePath, Blob: fields[2]})
+	}
+	return files, nil
+}
+
+// Open file of the cloned repo by its slash separated path, only regular files under dir are opened so
+// symlinks can not point the import at files of the server
+func Open(dir, filePath string) (*os.File, error) {
+	clean := path.Clean("/" + filePath)[1:]
+	if clean == "" || clean != filePath {
+		return nil, fmt.Errorf("%w: %s", ErrNotRegularFile, filePath)
+	}
+	current := dir
+	for _, part := range strings.Split(clean, "/") {
+		current = filepath.Join(current, part)
+		info, err := os.Lstat(current)
+		if err != nil {
+			return nil, err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return nil, fmt.Errorf("%w: %s", ErrNotRegularFile, filePath)
+		}
+	}
+	file, err := os.Open(current)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		file.Close()
+		return nil, fmt.Errorf("%w: %s", ErrNotRegularFile, filePath)
+	}
+	return file, nil
+}
+
+// ReadFile content of the regular file of the cloned repo, like Open
+func ReadFile(dir, filePath string) ([]byte, error) {
+	file, err := Open(dir, filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
+// run git in dir, the token is masked in errors as it may be part of the remote url
+func run(ctx context.Context, dir, token string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_LFS_SKIP_SMUDGE=1")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if token != "" {
+			msg = strings.ReplaceAll(msg, token, "***")
+		}
+		return nil, fmt.Errorf("git %s failed: %w: %s", args[0], err, msg)
+	}
+	return out, nil
+}
@@ -0,0 +1,103 @@
+package gitrepo
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// newTestRepo repo with a regular file, an executable, symlinks to a server file and a symlinked directory
+func newTestRepo(t *testing.T) (string, string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	secret := filepath.Join(t.TempDir(), "config.yml")
+	if err := os.WriteFile(secret, []byte("jwt: secret"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{"docs/readme.md": "# readme", "docs/run.sh": "#!/bin/sh"}
+	for name, content := range files {
+		full := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Chmod(filepath.Join(dir, "docs", "run.sh"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(secret, filepath.Join(dir, "docs", "leak.md")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("readme.md", filepath.Join(dir, "docs", "alias.md")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Dir(secret), filepath.Join(dir, "server")); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "-A"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "init"},
+	} {
+		if _, err := run(context.Background(), dir, "", args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir, secret
+}
+
+func TestListFilesSkipsSymlinks(t *testing.T) {
+	dir, _ := newTestRepo(t)
+	files, err := ListFiles(context.Background(), dir, "")
+	if err != nil {
+		t.Fatalf("ListFiles() error = %v", err)
+	}
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		paths = append(paths, file.Path)
+	}
+	slices.Sort(paths)
+	if want := []string{"docs/readme.md", "docs/run.sh"}; !slices.Equal(paths, want) {
+		t.Errorf("ListFiles() = %v, want %v", paths, want)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	dir, _ := newTestRepo(t)
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr error
+	}{
+		{name: "regular file", path: "docs/readme.md", want: "# readme"},
+		{name: "executable file", path: "docs/run.sh", want: "#!/bin/sh"},
+		{name: "symlink to a server file", path: "docs/leak.md", wantErr: ErrNotRegularFile},
+		{name: "symlink in the repo", path: "docs/alias.md", wantErr: ErrNotRegularFile},
+		{name: "file under a symlinked directory", path: "server/config.yml", wantErr: ErrNotRegularFile},
+		{name: "directory", path: "docs", wantErr: ErrNotRegularFile},
+		{name: "parent path", path: "../config.yml", wantErr: ErrNotRegularFile},
+		{name: "unclean path", path: "docs/../docs/readme.md", wantErr: ErrNotRegularFile},
+		{name: "missing file", path: "docs/missing.md", wantErr: os.ErrNotExist},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := ReadFile(dir, tt.path)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ReadFile() error = %v, want %v", err, tt.wantErr)
+			}
+			if string(data) != tt.want {
+				t.Errorf("ReadFile() = %q, want %q", data, tt.want)
+			}
+		})
+	}
+}
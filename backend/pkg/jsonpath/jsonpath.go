@@ -0,0 +1,135 @@
+// Package jsonpath evaluate a subset of JSONPath on decoded json values.
+//
+// Supported: root $, child .name or ['name'], index [0] (negative from the end) and wildcard [*] or .*
+package jsonpath
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type step struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// Get value at path of data, data is the result of json.Unmarshal into any.
+// paths with a wildcard return a list of all matches
+func Get(data any, path string) (any, error) {
+	steps, err := parse(path)
+	if err != nil {
+		return nil, err
+	}
+	values := []any{data}
+	multi := false
+	for _, s := range steps {
+		next := make([]any, 0, len(values))
+		for _, v := range values {
+			next = append(next, s.apply(v)...)
+		}
+		values = next
+		if s.wildcard {
+			multi = true
+		}
+	}
+	if multi {
+		return values, nil
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+	return values[0], nil
+}
+
+func (s step) apply(v any) []any {
+	switch {
+	case s.wildcard:
+		switch t := v.(type) {
+		case []any:
+			return t
+		case map[string]any:
+			keys := make([]string, 0, len(t))
+			for k := range t {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			res := make([]any, 0, len(keys))
+			for _, k := range keys {
+				res = append(res, t[k])
+			}
+			return res
+		}
+	case s.isIndex:
+		if list, ok := v.([]any); ok {
+			i := s.index
+			if i < 0 {
+				i += len(list)
+			}
+			if i >= 0 && i < len(list) {
+				return []any{list[i]}
+			}
+		}
+	default:
+		if m, ok := v.(map[string]any); ok {
+			if child, ok := m[s.key]; ok {
+				return []any{child}
+			}
+		}
+	}
+	return nil
+}
+
+func parse(path string) ([]step, error) {
+	path = strings.TrimSpace(path)
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("jsonpath must start with $: %s", path)
+	}
+	rest := path[1:]
+	steps := make([]step, 0)
+	for len(rest) > 0 {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			name := rest[:end]
+			if name == "" {
+				return nil, fmt.Errorf("empty name in jsonpath: %s", path)
+			}
+			if name == "*" {
+				steps = append(steps, step{wildcard: true})
+			} else {
+				steps = append(steps, step{key: name})
+			}
+			rest = rest[end:]
+		case '[':
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed bracket in jsonpath: %s", path)
+			}
+			inner := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+			switch {
+			case inner == "*":
+				steps = append(steps, step{wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				steps = append(steps, step{key: inner[1 : len(inner)-1]})
+			default:
+				i, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("invalid index %q in jsonpath: %s", inner, path)
+				}
+				steps = append(steps, step{index: i, isIndex: true})
+			}
+		default:
+			return nil, fmt.Errorf("unexpected %q in jsonpath: %s", rest[0], path)
+		}
+	}
+	return steps, nil
+}
@@ -0,0 +1,353 @@
+// Package notion read pages, databases and blocks shared with an integration through the Notion api.
+package notion
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	baseURL    = "https://api.notion.com/v1"
+	apiVersion = "2022-06-28"
+	// results of each list request, the api caps larger sizes
+	pageSize = 100
+	// max levels of blocks between a page and the pages inside them
+	maxBlockDepth = 16
+)
+
+var idRegex = regexp.MustCompile(`([0-9a-fA-F]{8})-?([0-9a-fA-F]{4})-?([0-9a-fA-F]{4})-?([0-9a-fA-F]{4})-?([0-9a-fA-F]{12})`)
+
+var ErrNotFound = errors.New("notion object not found or not shared with the integration")
+
+type Client struct {
+	token      string
+	httpClient *http.Client
+}
+
+// NewClient client of an internal integration token, pages are visible only after being shared with the integration
+func NewClient(token string) *Client {
+	return &Client{
+		token:      token,
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// Page page or database, rows of a database are pages whose parent is the database
+type Page struct {
+	ID    string
+	Title string
+	// page or database containing the page, empty for selected roots
+	ParentID       string
+	Database       bool
+	LastEditedTime time.Time
+}
+
+// ParseID id of a page or database from its id or url, dashed and in lower case
+func ParseID(s string) string {
+	s = strings.TrimSpace(s)
+	if u, err := url.Parse(s); err == nil && u.Host != "" {
+		s = strings.TrimSuffix(u.Path, "/")
+	}
+	matches := idRegex.FindAllStringSubmatch(s, -1)
+	if len(matches) == 0 {
+		return ""
+	}
+	// page urls end with the id after the title slug
+	m := matches[len(matches)-1]
+	return strings.ToLower(strings.Join(m[1:], "-"))
+}
+
+type parent struct {
+	Type       string `json:"type"`
+	PageID     string `json:"page_id"`
+	DatabaseID string `json:"database_id"`
+	BlockID    string `json:"block_id"`
+}
+
+type object struct {
+	Object         string                     `json:"object"`
+	ID             string                     `json:"id"`
+	Parent         parent                     `json:"parent"`
+	LastEditedTime time.Time                  `json:"last_edited_time"`
+	Archived       bool                       `json:"archived"`
+	InTrash        bool                       `json:"in_trash"`
+	Title          []RichText                 `json:"title"`
+	Properties     map[string]json.RawMessage `json:"properties"`
+}
+
+func (o *object) page() *Page {
+	page := &Page{
+		ID:             o.ID,
+		Database:       o.Object == "database",
+		LastEditedTime: o.LastEditedTime,
+	}
+	if page.Database {
+		page.Title = PlainText(o.Title)
+		return page
+	}
+	// the title property of database rows has the name of the column
+	for _, raw := range o.Properties {
+		property := struct {
+			Type  string     `json:"type"`
+			Title []RichText `json:"title"`
+		}{}
+		if json.Unmarshal(raw, &property) == nil && property.Type == "title" {
+			page.Title = PlainText(property.Title)
+			break
+		}
+	}
+	return page
+}
+
+type listResult struct {
+	Results    []json.RawMessage `json:"results"`
+	HasMore    bool              `json:"has_more"`
+	NextCursor string            `json:"next_cursor"`
+}
+
+// GetPage page or database of the id
+func (c *Client) GetPage(ctx context.Context, id string) (*Page, error) {
+	o, err := c.getObject(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return o.page(), nil
+}
+
+// getObject ids do not tell pages from databases, databases are tried if the page request fails
+func (c *Client) getObject(ctx context.Context, id string) (*object, error) {
+	o := &object{}
+	err := c.do(ctx, http.MethodGet, "/pages/"+id, nil, o)
+	if err == nil {
+		return o, nil
+	}
+	if c.do(ctx, http.MethodGet, "/databases/"+id, nil, o) == nil {
+		return o, nil
+	}
+	return nil, err
+}
+
+// ListPages the roots and the pages and databases nested in them, parents of roots are cleared
+func (c *Client) ListPages(ctx context.Context, rootIDs []string) ([]*Page, error) {
+	objects := make(map[string]*object)
+	for cursor := ""; ; {
+		body := map[string]any{"page_size": pageSize}
+		if cursor != "" {
+			body["start_cursor"] = cursor
+		}
+		result := &listResult{}
+		if err := c.do(ctx, http.MethodPost, "/search", body, result); err != nil {
+			return nil, err
+		}
+		for _, raw := range result.Results {
+			o := &object{}
+			if err := json.Unmarshal(raw, o); err != nil {
+				return nil, fmt.Errorf("decode notion search result failed: %w", err)
+			}
+			if !o.Archived && !o.InTrash {
+				objects[o.ID] = o
+			}
+		}
+		if !result.HasMore || result.NextCursor == "" {
+			break
+		}
+		cursor = result.NextCursor
+	}
+
+	roots := make(map[string]bool, len(rootIDs))
+	for _, id := range rootIDs {
+		if _, ok := objects[id]; !ok {
+			// the search index lags behind, fetch roots directly
+			o, err := c.getObject(ctx, id)
+			if err != nil {
+				return nil, fmt.Errorf("get notion page %s failed: %w", id, err)
+			}
+			objects[o.ID] = o
+		}
+		roots[id] = true
+	}
+
+	parents := make(map[string]string, len(objects))
+	blockParents := make(map[string]string)
+	for id, o := range objects {
+		parentID, err := c.resolveParent(ctx, o.Parent, blockParents)
+		if err != nil {
+			return nil, err
+		}
+		parents[id] = parentID
+	}
+	// pages under a root, checked along their parents
+	included := make(map[string]bool)
+	var isIncluded func(id string, depth int) bool
+	isIncluded = func(id string, depth int) bool {
+		if roots[id] {
+			return true
+		}
+		if v, ok := included[id]; ok {
+			return v
+		}
+		v := false
+		if parentID, ok := parents[id]; ok && parentID != "" && depth < len(objects) {
+			v = isIncluded(parentID, depth+1)
+		}
+		included[id] = v
+		return v
+	}
+	pages := make([]*Page, 0)
+	for id, o := range objects {
+		if !isIncluded(id, 0) {
+			continue
+		}
+		page := o.page()
+		if !roots[id] {
+			page.ParentID = parents[id]
+		}
+		pages = append(pages, page)
+	}
+	return pages, nil
+}
+
+// resolveParent page or database containing the object, following blocks like toggles and columns in between
+func (c *Client) resolveParent(ctx context.Context, p parent, blockParents map[string]string) (string, error) {
+	// blocks passed through, cached with the resolved parent
+	var blockIDs []string
+	parentID := ""
+loop:
+	for depth := 0; depth < maxBlockDepth; depth++ {
+		switch p.Type {
+		case "page_id":
+			parentID = p.PageID
+			break loop
+		case "database_id":
+			parentID = p.DatabaseID
+			break loop
+		case "block_id":
+			if id, ok := blockParents[p.BlockID]; ok {
+				parentID = id
+				break loop
+			}
+			blockIDs = append(blockIDs, p.BlockID)
+			block := &struct {
+				Parent parent `json:"parent"`
+			}{}
+			if err := c.do(ctx, http.MethodGet, "/blocks/"+p.BlockID, nil, block); err != nil {
+				if !errors.Is(err, ErrNotFound) {
+					return "", err
+				}
+				break loop
+			}
+			p = block.Parent
+		default:
+			break loop
+		}
+	}
+	for _, id := range blockIDs {
+		blockParents[id] = parentID
+	}
+	return parentID, nil
+}
+
+// GetBlocks blocks of the page with their children, pages and databases inside are not expanded
+func (c *Client) GetBlocks(ctx context.Context, id string) ([]*Block, error) {
+	return c.getBlocks(ctx, id, 0)
+}
+
+func (c *Client) getBlocks(ctx context.Context, id string, depth int) ([]*Block, error) {
+	blocks := make([]*Block, 0)
+	query := url.Values{"page_size": {fmt.Sprint(pageSize)}}
+	for {
+		result := &listResult{}
+		if err := c.do(ctx, http.MethodGet, "/blocks/"+id+"/children?"+query.Encode(), nil, result); err != nil {
+			return nil, err
+		}
+		for _, raw := range result.Results {
+			block := &Block{}
+			if err := json.Unmarshal(raw, block); err != nil {
+				return nil, fmt.Errorf("decode notion block failed: %w", err)
+			}
+			blocks = append(blocks, block)
+		}
+		if !result.HasMore || result.NextCursor == "" {
+			break
+		}
+		query.Set("start_cursor", result.NextCursor)
+	}
+	if depth >= maxBlockDepth {
+		return blocks, nil
+	}
+	for _, block := range blocks {
+		if !block.HasChildren || block.Type == "child_page" || block.Type == "child_database" {
+			continue
+		}
+		children, err := c.getBlocks(ctx, block.ID, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		block.Children = children
+	}
+	return blocks, nil
+}
+
+// Download content of a file hosted by notion, the caller closes the body
+func (c *Client) Download(ctx context.Context, fileURL string) (io.ReadCloser, int64, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, nil)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	// file urls are signed, the token must not be sent to them
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, 0, "", fmt.Errorf("download notion file: %s", resp.Status)
+	}
+	return resp.Body, resp.ContentLength, resp.Header.Get("Content-Type"), nil
+}
+
+// do request path of the api, not found responses are ErrNotFound and other non 2xx responses are errors
+func (c *Client) do(ctx context.Context, method, path string, body, v any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Notion-Version", apiVersion)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("notion %s %s: %s", method, path, strings.TrimSpace(string(data)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decode notion response failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,356 @@
+package notion
+
+import (
+	"encoding/json"
+	"html"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Resolver urls of pages and files referenced by blocks, empty if the target is not imported
+type Resolver interface {
+	PageURL(id string) string
+	// FileURL url of a file hosted by notion, by the id of its block
+	FileURL(blockID string) string
+}
+
+type RichText struct {
+	Type        string `json:"type"`
+	PlainText   string `json:"plain_text"`
+	Href        string `json:"href"`
+	Annotations struct {
+		Bold          bool `json:"bold"`
+		Italic        bool `json:"italic"`
+		Strikethrough bool `json:"strikethrough"`
+		Underline     bool `json:"underline"`
+		Code          bool `json:"code"`
+	} `json:"annotations"`
+	Mention *struct {
+		Type string `json:"type"`
+		Page *struct {
+			ID string `json:"id"`
+		} `json:"page"`
+		Database *struct {
+			ID string `json:"id"`
+		} `json:"database"`
+	} `json:"mention"`
+}
+
+func PlainText(texts []RichText) string {
+	var sb strings.Builder
+	for _, t := range texts {
+		sb.WriteString(t.PlainText)
+	}
+	return sb.String()
+}
+
+// FileObject file of image, video and file blocks, hosted by notion or external
+type FileObject struct {
+	Type     string `json:"type"`
+	External *struct {
+		URL string `json:"url"`
+	} `json:"external"`
+	File *struct {
+		URL string `json:"url"`
+	} `json:"file"`
+}
+
+// BlockContent fields of all block types, each type sets its own
+type BlockContent struct {
+	RichText        []RichText   `json:"rich_text"`
+	Caption         []RichText   `json:"caption"`
+	Checked         bool         `json:"checked"`
+	Language        string       `json:"language"`
+	Title           string       `json:"title"`
+	URL             string       `json:"url"`
+	Expression      string       `json:"expression"`
+	Name            string       `json:"name"`
+	HasColumnHeader bool         `json:"has_column_header"`
+	Cells           [][]RichText `json:"cells"`
+	Icon            *struct {
+		Emoji string `json:"emoji"`
+	} `json:"icon"`
+	FileObject
+}
+
+type Block struct {
+	ID             string       `json:"id"`
+	Type           string       `json:"type"`
+	HasChildren    bool         `json:"has_children"`
+	LastEditedTime time.Time    `json:"last_edited_time"`
+	Content        BlockContent `json:"-"`
+	Children       []*Block     `json:"-"`
+}
+
+func (b *Block) UnmarshalJSON(data []byte) error {
+	type block Block
+	if err := json.Unmarshal(data, (*block)(b)); err != nil {
+		return err
+	}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	if raw, ok := fields[b.Type]; ok {
+		return json.Unmarshal(raw, &b.Content)
+	}
+	return nil
+}
+
+// HostedFileURL url of the file hosted by notion, which expires in an hour and is imported
+func (b *Block) HostedFileURL() string {
+	switch b.Type {
+	case "image", "file", "pdf", "video", "audio":
+		if b.Content.FileObject.Type == "file" && b.Content.File != nil {
+			return b.Content.File.URL
+		}
+	}
+	return ""
+}
+
+// FileName name of the file of the block, from its name, caption or url
+func (b *Block) FileName() string {
+	if b.Content.Name != "" {
+		return b.Content.Name
+	}
+	if caption := PlainText(b.Content.Caption); caption != "" {
+		return caption
+	}
+	u := b.HostedFileURL()
+	if u == "" && b.Content.External != nil {
+		u = b.Content.External.URL
+	}
+	u, _, _ = strings.Cut(u, "?")
+	name := u[strings.LastIndex(u, "/")+1:]
+	if unescaped, err := url.PathUnescape(name); err == nil {
+		return unescaped
+	}
+	return name
+}
+
+// Render html of the blocks
+func Render(blocks []*Block, resolver Resolver) string {
+	r := &renderer{resolver: resolver}
+	r.blocks(blocks)
+	return r.sb.String()
+}
+
+type renderer struct {
+	resolver Resolver
+	sb       strings.Builder
+}
+
+func (r *renderer) blocks(blocks []*Block) {
+	for i := 0; i < len(blocks); {
+		// consecutive list items of the same type are one list
+		switch list := listTag(blocks[i].Type); list {
+		case "":
+			r.block(blocks[i])
+			i++
+		default:
+			if blocks[i].Type == "to_do" {
+				r.sb.WriteString(`<ul data-type="taskList">`)
+			} else {
+				r.sb.WriteString("<" + list + ">")
+			}
+			j := i
+			for ; j < len(blocks) && blocks[j].Type == blocks[i].Type; j++ {
+				r.listItem(blocks[j])
+			}
+			r.sb.WriteString("</" + list + ">")
+			i = j
+		}
+	}
+}
+
+func listTag(blockType string) string {
+	switch blockType {
+	case "bulleted_list_item", "to_do":
+		return "ul"
+	case "numbered_list_item":
+		return "ol"
+	}
+	return ""
+}
+
+func (r *renderer) listItem(b *Block) {
+	if b.Type == "to_do" {
+		checked := "false"
+		if b.Content.Checked {
+			checked = "true"
+		}
+		r.sb.WriteString(`<li data-type="taskItem" data-checked="` + checked + `">`)
+	} else {
+		r.sb.WriteString("<li>")
+	}
+	r.sb.WriteString("<p>")
+	r.richText(b.Content.RichText)
+	r.sb.WriteString("</p>")
+	r.blocks(b.Children)
+	r.sb.WriteString("</li>")
+}
+
+func (r *renderer) block(b *Block) {
+	c := &b.Content
+	switch b.Type {
+	case "paragraph":
+		r.wrap("p", c.RichText)
+		r.blocks(b.Children)
+	case "heading_1", "heading_2", "heading_3":
+		r.wrap("h"+b.Type[len(b.Type)-1:], c.RichText)
+		// toggleable headings have children
+		r.blocks(b.Children)
+	case "quote":
+		r.sb.WriteString("<blockquote>")
+		r.wrap("p", c.RichText)
+		r.blocks(b.Children)
+		r.sb.WriteString("</blockquote>")
+	case "callout":
+		r.sb.WriteString("<blockquote><p>")
+		if c.Icon != nil && c.Icon.Emoji != "" {
+			r.sb.WriteString(html.EscapeString(c.Icon.Emoji) + " ")
+		}
+		r.richText(c.RichText)
+		r.sb.WriteString("</p>")
+		r.blocks(b.Children)
+		r.sb.WriteString("</blockquote>")
+	case "toggle":
+		r.sb.WriteString("<details><summary>")
+		r.richText(c.RichText)
+		r.sb.WriteString("</summary>")
+		r.blocks(b.Children)
+		r.sb.WriteString("</details>")
+	case "code":
+		r.sb.WriteString("<pre><code")
+		if c.Language != "" && c.Language != "plain text" {
+			r.sb.WriteString(` class="language-` + html.EscapeString(c.Language) + `"`)
+		}
+		r.sb.WriteString(">" + html.EscapeString(PlainText(c.RichText)) + "</code></pre>")
+	case "equation":
+		r.sb.WriteString("<p><code>" + html.EscapeString(c.Expression) + "</code></p>")
+	case "divider":
+		r.sb.WriteString("<hr>")
+	case "image":
+		if src := r.fileURL(b); src != "" {
+			r.sb.WriteString(`<img src="` + html.EscapeString(src) + `" alt="` + html.EscapeString(PlainText(c.Caption)) + `">`)
+		}
+	case "file", "pdf", "video", "audio":
+		if src := r.fileURL(b); src != "" {
+			r.link(src, b.FileName())
+		} else if name := b.FileName(); name != "" {
+			// imported as an attachment of the node
+			r.wrap("p", []RichText{{PlainText: name}})
+		}
+	case "bookmark", "embed", "link_preview":
+		label := PlainText(c.Caption)
+		if label == "" {
+			label = c.URL
+		}
+		r.link(c.URL, label)
+	case "child_page", "child_database":
+		// nested pages are nodes of their own
+		if href := r.resolver.PageURL(b.ID); href != "" {
+			r.link(href, c.Title)
+		}
+	case "table":
+		r.table(b)
+	case "column_list", "column", "synced_block", "template":
+		r.blocks(b.Children)
+	}
+}
+
+func (r *renderer) table(b *Block) {
+	r.sb.WriteString("<table><tbody>")
+	for i, row := range b.Children {
+		cell := "td"
+		if i == 0 && b.Content.HasColumnHeader {
+			cell = "th"
+		}
+		r.sb.WriteString("<tr>")
+		for _, texts := range row.Content.Cells {
+			r.sb.WriteString("<" + cell + ">")
+			r.richText(texts)
+			r.sb.WriteString("</" + cell + ">")
+		}
+		r.sb.WriteString("</tr>")
+	}
+	r.sb.WriteString("</tbody></table>")
+}
+
+// fileURL imported url of hosted files, external files are linked as is
+func (r *renderer) fileURL(b *Block) string {
+	if b.HostedFileURL() != "" {
+		return r.resolver.FileURL(b.ID)
+	}
+	if b.Content.External != nil {
+		return b.Content.External.URL
+	}
+	return ""
+}
+
+func (r *renderer) link(href, label string) {
+	if label == "" {
+		label = href
+	}
+	r.sb.WriteString(`<p><a href="` + html.EscapeString(href) + `">` + html.EscapeString(label) + "</a></p>")
+}
+
+func (r *renderer) wrap(tag string, texts []RichText) {
+	r.sb.WriteString("<" + tag + ">")
+	r.richText(texts)
+	r.sb.WriteString("</" + tag + ">")
+}
+
+func (r *renderer) richText(texts []RichText) {
+	for _, t := range texts {
+		s := html.EscapeString(t.PlainText)
+		a := t.Annotations
+		if a.Code {
+			s = "<code>" + s + "</code>"
+		}
+		if a.Bold {
+			s = "<strong>" + s + "</strong>"
+		}
+		if a.Italic {
+			s = "<em>" + s + "</em>"
+		}
+		if a.Strikethrough {
+			s = "<s>" + s + "</s>"
+		}
+		if a.Underline {
+			s = "<u>" + s + "</u>"
+		}
+		if href := r.href(t); href != "" {
+			s = `<a href="` + html.EscapeString(href) + `">` + s + "</a>"
+		}
+		r.sb.WriteString(s)
+	}
+}
+
+// href links to pages of the workspace point at their nodes if imported
+func (r *renderer) href(t RichText) string {
+	if t.Mention != nil {
+		if t.Mention.Page != nil {
+			if href := r.resolver.PageURL(ParseID(t.Mention.Page.ID)); href != "" {
+				return href
+			}
+		}
+		if t.Mention.Database != nil {
+			if href := r.resolver.PageURL(ParseID(t.Mention.Database.ID)); href != "" {
+				return href
+			}
+		}
+	}
+	if t.Href == "" {
+		return ""
+	}
+	if strings.HasPrefix(t.Href, "/") || strings.Contains(t.Href, "notion.so") || strings.Contains(t.Href, "notion.site") {
+		if id := ParseID(t.Href); id != "" {
+			if href := r.resolver.PageURL(id); href != "" {
+				return href
+			}
+		}
+	}
+	return t.Href
+}
@@ -0,0 +1,302 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// testKey signing key of the providers, generated once as rsa keys are slow to generate
+var testKey = sync.OnceValues(func() (*rsa.PrivateKey, error) { return rsa.GenerateKey(rand.Reader, 2048) })
+
+// testProvider provider serving discovery, keys, tokens and userinfo, the id token is built by idToken for each exchange
+type testProvider struct {
+	*httptest.Server
+	key      *rsa.PrivateKey
+	issuer   string
+	idToken  func(p *testProvider) string
+	userinfo map[string]any
+	// form of the last token request
+	form url.Values
+}
+
+func newTestProvider(t *testing.T) *testProvider {
+	t.Helper()
+	key, err := testKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &testProvider{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 p.issuer,
+			"authorization_endpoint": p.URL + "/auth?tenant=corp",
+			"token_endpoint":         p.URL + "/token",
+			"userinfo_endpoint":      p.URL + "/userinfo",
+			"jwks_uri":               p.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kid": "key-1",
+			"kty": "RSA",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		p.form = r.PostForm
+		_ = json.NewEncoder(w).Encode(map[string]string{"access_token": "access", "id_token": p.idToken(p)})
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(p.userinfo)
+	})
+	p.Server = httptest.NewServer(mux)
+	p.issuer = p.URL
+	t.Cleanup(p.Close)
+	return p
+}
+
+func (p *testProvider) sign(t *testing.T, method jwt.SigningMethod, kid string, key any, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func (p *testProvider) claims(override map[string]any) jwt.MapClaims {
+	claims := jwt.MapClaims{
+		"iss":   p.issuer,
+		"aud":   "wiki",
+		"sub":   "user-1",
+		"nonce": "nonce-1",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"iat":   time.Now().Unix(),
+	}
+	for k, v := range override {
+		if v == nil {
+			delete(claims, k)
+		} else {
+			claims[k] = v
+		}
+	}
+	return claims
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	p := newTestProvider(t)
+	c := NewClient(p.URL+"/", "wiki", "secret", "https://wiki.example.com/callback", []string{"openid", "email"})
+	raw, err := c.AuthCodeURL(context.Background(), "state-1", "nonce-1", "verifier-1")
+	if err != nil {
+		t.Fatalf("AuthCodeURL() error = %v", err)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Path != "/auth" {
+		t.Errorf("path = %q, want /auth", u.Path)
+	}
+	challenge := sha256.Sum256([]byte("verifier-1"))
+	want := url.Values{
+		"tenant":                {"corp"},
+		"response_type":         {"code"},
+		"client_id":             {"wiki"},
+		"redirect_uri":          {"https://wiki.example.com/callback"},
+		"scope":                 {"openid email"},
+		"state":                 {"state-1"},
+		"nonce":                 {"nonce-1"},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+	if got := u.Query(); !reflect.DeepEqual(got, want) {
+		t.Errorf("query = %v, want %v", got, want)
+	}
+}
+
+func TestDiscoverIssuerMismatch(t *testing.T) {
+	p := newTestProvider(t)
+	p.issuer = "https://evil.example.com"
+	c := NewClient(p.URL, "wiki", "secret", "https://wiki.example.com/callback", nil)
+	if _, err := c.AuthCodeURL(context.Background(), "state", "nonce", "verifier"); err == nil || !strings.Contains(err.Error(), "issuer") {
+		t.Errorf("AuthCodeURL() error = %v, want issuer mismatch", err)
+	}
+}
+
+func TestExchange(t *testing.T) {
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		idToken  func(t *testing.T, p *testProvider) string
+		userinfo map[string]any
+		want     Claims
+		wantErr  string
+	}{
+		{
+			name: "valid id token",
+			idToken: func(t *testing.T, p *testProvider) string {
+				return p.sign(t, jwt.SigningMethodRS256, "key-1", p.key, p.claims(map[string]any{"groups": []string{"wiki-admins"}}))
+			},
+			want: Claims{"sub": "user-1", "groups": []any{"wiki-admins"}},
+		},
+		{
+			name: "token without kid signed by the only key",
+			idToken: func(t *testing.T, p *testProvider) string {
+				return p.sign(t, jwt.SigningMethodRS256, "", p.key, p.claims(map[string]any{"groups": "staff"}))
+			},
+			want: Claims{"sub": "user-1", "groups": "staff"},
+		},
+		{
+			name: "missing groups read from userinfo",
+			idToken: func(t *testing.T, p *testProvider) string {
+				return p.sign(t, jwt.SigningMethodRS256, "key-1", p.key, p.claims(nil))
+			},
+			userinfo: map[string]any{"sub": "user-1", "groups": []string{"staff"}, "nonce": "other"},
+			want:     Claims{"sub": "user-1", "groups": []any{"staff"}},
+		},
+		{
+			name: "userinfo of another subject",
+			idToken: func(t *testing.T, p *testProvider) string {
+				return p.sign(t, jwt.SigningMethodRS256, "key-1", p.key, p.claims(nil))
+			},
+			userinfo: map[string]any{"sub": "user-2", "groups": []string{"wiki-admins"}},
+			wantErr:  "subject of userinfo mismatch",
+		},
+		{
+			name: "nonce of another login",
+			idToken: func(t *testing.T, p *testProvider) string {
+				return p.sign(t, jwt.SigningMethodRS256, "key-1", p.key, p.claims(map[string]any{"nonce": "nonce-2", "groups": "staff"}))
+			},
+			wantErr: "nonce",
+		},
+		{
+			name: "token for another client",
+			idToken: func(t *testing.T, p *testProvider) string {
+				return p.sign(t, jwt.SigningMethodRS256, "key-1", p.key, p.claims(map[string]any{"aud": "other", "groups": "staff"}))
+			},
+			wantErr: "audience",
+		},
+		{
+			name: "token of another issuer",
+			idToken: func(t *testing.T, p *testProvider) string {
+				return p.sign(t, jwt.SigningMethodRS256, "key-1", p.key, p.claims(map[string]any{"iss": "https://evil.example.com", "groups": "staff"}))
+			},
+			wantErr: "issuer",
+		},
+		{
+			name: "expired token",
+			idToken: func(t *testing.T, p *testProvider) string {
+				return p.sign(t, jwt.SigningMethodRS256, "key-1", p.key, p.claims(map[string]any{"exp": time.Now().Add(-2 * leeway).Unix(), "groups": "staff"}))
+			},
+			wantErr: "expired",
+		},
+		{
+			name: "token without expiry",
+			idToken: func(t *testing.T, p *testProvider) string {
+				return p.sign(t, jwt.SigningMethodRS256, "key-1", p.key, p.claims(map[string]any{"exp": nil, "groups": "staff"}))
+			},
+			wantErr: "exp",
+		},
+		{
+			name: "token signed by another key",
+			idToken: func(t *testing.T, p *testProvider) string {
+				return p.sign(t, jwt.SigningMethodRS256, "key-1", otherKey, p.claims(map[string]any{"groups": "staff"}))
+			},
+			wantErr: "verification error",
+		},
+		{
+			name: "unknown kid",
+			idToken: func(t *testing.T, p *testProvider) string {
+				return p.sign(t, jwt.SigningMethodRS256, "key-2", p.key, p.claims(map[string]any{"groups": "staff"}))
+			},
+			wantErr: "signing key key-2 not found",
+		},
+		{
+			name: "hmac token signed with the client secret",
+			idToken: func(t *testing.T, p *testProvider) string {
+				return p.sign(t, jwt.SigningMethodHS256, "key-1", []byte("secret"), p.claims(map[string]any{"groups": "staff"}))
+			},
+			wantErr: "signing method HS256 is invalid",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestProvider(t)
+			p.idToken = func(p *testProvider) string { return tt.idToken(t, p) }
+			p.userinfo = tt.userinfo
+			c := NewClient(p.URL, "wiki", "secret", "https://wiki.example.com/callback", nil)
+			claims, err := c.Exchange(context.Background(), "code-1", "nonce-1", "verifier-1", "groups")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Exchange() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Exchange() error = %v", err)
+			}
+			if got := p.form.Get("code_verifier"); got != "verifier-1" {
+				t.Errorf("code_verifier = %q, want verifier-1", got)
+			}
+			if got := p.form.Get("code"); got != "code-1" {
+				t.Errorf("code = %q, want code-1", got)
+			}
+			for name, want := range tt.want {
+				if !reflect.DeepEqual(claims[name], want) {
+					t.Errorf("claim %s = %#v, want %#v", name, claims[name], want)
+				}
+			}
+			if claims.String("nonce") != "nonce-1" {
+				t.Errorf("nonce = %q, claims of the id token must not be replaced by userinfo", claims.String("nonce"))
+			}
+		})
+	}
+}
+
+func TestClaimsStrings(t *testing.T) {
+	claims := Claims{"list": []any{"a", 1, "b"}, "single": "c", "number": 2.0}
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{name: "list", want: []string{"a", "b"}},
+		{name: "single", want: []string{"c"}},
+		{name: "number"},
+		{name: "missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := claims.Strings(tt.name); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Strings() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
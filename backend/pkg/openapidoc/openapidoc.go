@@ -0,0 +1,431 @@
+// Package openapidoc render an OpenAPI 3 spec as markdown documents, one for each tag and each operation.
+package openapidoc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const (
+	// max size of a spec
+	maxSpecSize = 20 * 1024 * 1024
+	// levels of nested properties listed in field tables
+	maxSchemaDepth = 3
+)
+
+// methods in the order operations of a path are listed
+var methods = []string{
+	http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+	http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodConnect,
+}
+
+// Resolver urls of the documents of operations, empty if not imported
+type Resolver interface {
+	OperationURL(id string) string
+}
+
+// Page document of a tag or an operation, operations are children of their first tag
+type Page struct {
+	ID       string
+	ParentID string
+	Title    string
+	// content by the resolver, the content of a tag links to its operations
+	Render func(resolver Resolver) string
+}
+
+// Fetch spec at the url, the token is sent as bearer token if set
+func Fetch(ctx context.Context, specURL, token string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, specURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	req.Header.Set("Accept", "application/json, application/yaml, */*")
+	resp, err := (&http.Client{Timeout: 60 * time.Second}).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch openapi spec: %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSpecSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxSpecSize {
+		return nil, fmt.Errorf("openapi spec is larger than %d bytes", maxSpecSize)
+	}
+	return data, nil
+}
+
+// Load parse a json or yaml spec, refs to other files are not followed
+func Load(data []byte) (*openapi3.T, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse openapi spec failed: %w", err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return nil, fmt.Errorf("unsupported openapi version: %q, only openapi 3 is supported", doc.OpenAPI)
+	}
+	return doc, nil
+}
+
+type operation struct {
+	id     string
+	method string
+	path   string
+	item   *openapi3.PathItem
+	op     *openapi3.Operation
+}
+
+// Pages documents of the tags and operations of the spec. ids of operations are their method and path,
+// so renaming an operation id keeps its document. operations without tags are top level documents
+func Pages(doc *openapi3.T) []*Page {
+	tagDescriptions := make(map[string]string)
+	tagNames := make([]string, 0)
+	for _, tag := range doc.Tags {
+		if tag == nil {
+			continue
+		}
+		if _, ok := tagDescriptions[tag.Name]; !ok {
+			tagNames = append(tagNames, tag.Name)
+		}
+		tagDescriptions[tag.Name] = tag.Description
+	}
+	paths := make([]string, 0, len(doc.Paths))
+	for p := range doc.Paths {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	operations := make([]*operation, 0)
+	byTag := make(map[string][]*operation)
+	for _, p := range paths {
+		item := doc.Paths[p]
+		if item == nil {
+			continue
+		}
+		for _, method := range methods {
+			op := item.GetOperation(method)
+			if op == nil {
+				continue
+			}
+			o := &operation{id: method + " " + p, method: method, path: p, item: item, op: op}
+			operations = append(operations, o)
+			if len(op.Tags) > 0 {
+				tag := op.Tags[0]
+				if _, ok := tagDescriptions[tag]; !ok {
+					tagDescriptions[tag] = ""
+					tagNames = append(tagNames, tag)
+				}
+				byTag[tag] = append(byTag[tag], o)
+			}
+		}
+	}
+
+	pages := make([]*Page, 0, len(tagNames)+len(operations))
+	for _, name := range tagNames {
+		ops := byTag[name]
+		if len(ops) == 0 {
+			continue
+		}
+		description := tagDescriptions[name]
+		pages = append(pages, &Page{
+			ID:    "tag:" + name,
+			Title: name,
+			Render: func(resolver Resolver) string {
+				return renderTag(description, ops, resolver)
+			},
+		})
+	}
+	for _, o := range operations {
+		page := &Page{
+			ID:    o.id,
+			Title: o.title(),
+			Render: func(Resolver) string {
+				return renderOperation(o)
+			},
+		}
+		if len(o.op.Tags) > 0 {
+			page.ParentID = "tag:" + o.op.Tags[0]
+		}
+		pages = append(pages, page)
+	}
+	return pages
+}
+
+func (o *operation) title() string {
+	if o.op.Summary != "" {
+		return o.op.Summary
+	}
+	if o.op.OperationID != "" {
+		return o.op.OperationID
+	}
+	return o.id
+}
+
+func renderTag(description string, ops []*operation, resolver Resolver) string {
+	var sb strings.Builder
+	if description != "" {
+		sb.WriteString(description + "\n\n")
+	}
+	sb.WriteString("| 接口 | 说明 |\n| --- | --- |\n")
+	for _, o := range ops {
+		endpoint := "`" + o.id + "`"
+		if resolver != nil {
+			if href := resolver.OperationURL(o.id); href != "" {
+				endpoint = "[" + endpoint + "](" + href + ")"
+			}
+		}
+		sb.WriteString("| " + endpoint + " | " + cell(o.title()) + " |\n")
+	}
+	return sb.String()
+}
+
+func renderOperation(o *operation) string {
+	var sb strings.Builder
+	sb.WriteString("`" + o.method + "` `" + o.path + "`\n\n")
+	if o.op.Deprecated {
+		sb.WriteString("> 该接口已废弃\n\n")
+	}
+	if o.op.Description != "" {
+		sb.WriteString(o.op.Description + "\n\n")
+	}
+	if o.op.OperationID != "" {
+		sb.WriteString("Operation ID: `" + o.op.OperationID + "`\n\n")
+	}
+
+	if params := o.parameters(); len(params) > 0 {
+		sb.WriteString("## 请求参数\n\n| 名称 | 位置 | 类型 | 必填 | 说明 |\n| --- | --- | --- | --- | --- |\n")
+		for _, p := range params {
+			sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s |\n", cell(p.Name), p.In, cell(schemaType(p.Schema)), yesNo(p.Required), cell(p.Description)))
+		}
+		sb.WriteString("\n")
+	}
+
+	if o.op.RequestBody != nil && o.op.RequestBody.Value != nil {
+		body := o.op.RequestBody.Value
+		sb.WriteString("## 请求体\n\n")
+		if body.Description != "" {
+			sb.WriteString(body.Description + "\n\n")
+		}
+		renderContent(&sb, body.Content)
+	}
+
+	if len(o.op.Responses) > 0 {
+		sb.WriteString("## 响应\n\n")
+		codes := make([]string, 0, len(o.op.Responses))
+		for code := range o.op.Responses {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+		for _, code := range codes {
+			ref := o.op.Responses[code]
+			if ref == nil || ref.Value == nil {
+				continue
+			}
+			sb.WriteString("### " + code + "\n\n")
+			if ref.Value.Description != nil && *ref.Value.Description != "" {
+				sb.WriteString(*ref.Value.Description + "\n\n")
+			}
+			renderContent(&sb, ref.Value.Content)
+		}
+	}
+	return strings.TrimSpace(sb.String()) + "\n"
+}
+
+// parameters of the path overridden by those of the operation with the same name and location
+func (o *operation) parameters() []*openapi3.Parameter {
+	params := make([]*openapi3.Parameter, 0)
+	index := make(map[string]int)
+	for _, refs := range []openapi3.Parameters{o.item.Parameters, o.op.Parameters} {
+		for _, ref := range refs {
+			if ref == nil || ref.Value == nil {
+				continue
+			}
+			key := ref.Value.In + ":" + ref.Value.Name
+			if i, ok := index[key]; ok {
+				params[i] = ref.Value
+				continue
+			}
+			index[key] = len(params)
+			params = append(params, ref.Value)
+		}
+	}
+	return params
+}
+
+// renderContent fields and example of each media type
+func renderContent(sb *strings.Builder, content openapi3.Content) {
+	mediaTypes := make([]string, 0, len(content))
+	for mediaType := range content {
+		mediaTypes = append(mediaTypes, mediaType)
+	}
+	sort.Strings(mediaTypes)
+	for _, mediaType := range mediaTypes {
+		media := content[mediaType]
+		if media == nil {
+			continue
+		}
+		sb.WriteString("`" + mediaType + "`")
+		if media.Schema != nil {
+			sb.WriteString(" " + cell(schemaType(media.Schema)))
+		}
+		sb.WriteString("\n\n")
+		if rows := schemaFields(media.Schema); len(rows) > 0 {
+			sb.WriteString("| 字段 | 类型 | 必填 | 说明 |\n| --- | --- | --- | --- |\n")
+			for _, row := range rows {
+				sb.WriteString(row + "\n")
+			}
+			sb.WriteString("\n")
+		}
+		if example := mediaExample(media); example != nil {
+			if data, err := json.MarshalIndent(example, "", "  "); err == nil {
+				sb.WriteString("```json\n" + string(data) + "\n```\n\n")
+			}
+		}
+	}
+}
+
+func mediaExample(media *openapi3.MediaType) any {
+	if media.Example != nil {
+		return media.Example
+	}
+	names := make([]string, 0, len(media.Examples))
+	for name := range media.Examples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if ref := media.Examples[name]; ref != nil && ref.Value != nil && ref.Value.Value != nil {
+			return ref.Value.Value
+		}
+	}
+	if media.Schema != nil && media.Schema.Value != nil {
+		return media.Schema.Value.Example
+	}
+	return nil
+}
+
+// schemaFields table rows of the properties of an object schema, or of the items of an array of objects
+func schemaFields(ref *openapi3.SchemaRef) []string {
+	rows := make([]string, 0)
+	if ref == nil || ref.Value == nil {
+		return rows
+	}
+	schema := ref.Value
+	if schema.Type == openapi3.TypeArray && schema.Items != nil && schema.Items.Value != nil {
+		return appendFields(rows, "[]", schema.Items.Value, 1, map[*openapi3.Schema]bool{schema: true})
+	}
+	return appendFields(rows, "", schema, 1, map[*openapi3.Schema]bool{})
+}
+
+// appendFields properties of the schema and its allOf schemas, nested objects are prefixed by their parent
+func appendFields(rows []string, prefix string, schema *openapi3.Schema, depth int, seen map[*openapi3.Schema]bool) []string {
+	if depth > maxSchemaDepth || seen[schema] {
+		return rows
+	}
+	seen[schema] = true
+	defer delete(seen, schema)
+	for _, all := range schema.AllOf {
+		if all != nil && all.Value != nil {
+			rows = appendFields(rows, prefix, all.Value, depth, seen)
+		}
+	}
+	required := make(map[string]bool, len(schema.Required))
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		property := schema.Properties[name]
+		if property == nil || property.Value == nil {
+			continue
+		}
+		field := name
+		if prefix != "" {
+			field = strings.TrimSuffix(prefix, ".") + "." + name
+		}
+		description := property.Value.Description
+		if len(property.Value.Enum) > 0 {
+			values := make([]string, 0, len(property.Value.Enum))
+			for _, v := range property.Value.Enum {
+				values = append(values, fmt.Sprint(v))
+			}
+			description = strings.TrimSpace(description + " 可选值: " + strings.Join(values, ", "))
+		}
+		rows = append(rows, fmt.Sprintf("| %s | %s | %s | %s |", cell(field), cell(schemaType(property)), yesNo(required[name]), cell(description)))
+		child := property.Value
+		if child.Type == openapi3.TypeArray && child.Items != nil && child.Items.Value != nil {
+			rows = appendFields(rows, field+"[]", child.Items.Value, depth+1, seen)
+		} else {
+			rows = appendFields(rows, field, child, depth+1, seen)
+		}
+	}
+	return rows
+}
+
+// schemaType type of the schema, named by its component if it is a ref
+func schemaType(ref *openapi3.SchemaRef) string {
+	if ref == nil {
+		return ""
+	}
+	if ref.Ref != "" {
+		return ref.Ref[strings.LastIndex(ref.Ref, "/")+1:]
+	}
+	schema := ref.Value
+	if schema == nil {
+		return ""
+	}
+	switch {
+	case schema.Type == openapi3.TypeArray:
+		return "array<" + schemaType(schema.Items) + ">"
+	case schema.Type != "" && schema.Format != "":
+		return schema.Type + "(" + schema.Format + ")"
+	case schema.Type != "":
+		return schema.Type
+	case len(schema.OneOf) > 0:
+		return unionType(schema.OneOf)
+	case len(schema.AnyOf) > 0:
+		return unionType(schema.AnyOf)
+	case len(schema.AllOf) > 0 || len(schema.Properties) > 0:
+		return openapi3.TypeObject
+	}
+	return "any"
+}
+
+func unionType(refs openapi3.SchemaRefs) string {
+	types := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		types = append(types, schemaType(ref))
+	}
+	return strings.Join(types, " | ")
+}
+
+func yesNo(v bool) string {
+	if v {
+		return "是"
+	}
+	return "否"
+}
+
+// cell text in a table cell, pipes and line breaks would break the table
+func cell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	s = strings.ReplaceAll(s, "\r\n", " ")
+	return strings.ReplaceAll(s, "\n", " ")
+}
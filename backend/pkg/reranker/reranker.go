@@ -0,0 +1,71 @@
+// Package reranker scores documents against a query by rerank apis in the format of bge-reranker services and Cohere
+package reranker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Model rerank api, base url of the /rerank endpoint
+type Model struct {
+	Model   string
+	BaseURL string
+	APIKey  string
+	Headers map[string]string
+}
+
+// Rerank relevance scores of the documents to the query, in the order of the documents
+func Rerank(ctx context.Context, model *Model, query string, documents []string) ([]float64, error) {
+	if len(documents) == 0 {
+		return []float64{}, nil
+	}
+	body, err := json.Marshal(map[string]any{
+		"model":            model.Model,
+		"query":            query,
+		"documents":        documents,
+		"top_n":            len(documents),
+		"return_documents": false,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal request body failed: %w", err)
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(model.BaseURL, "/")+"/rerank", bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("new request failed: %w", err)
+	}
+	request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", model.APIKey))
+	request.Header.Set("Content-Type", "application/json")
+	for k, v := range model.Headers {
+		request.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("send request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("request failed: %s", resp.Status)
+	}
+	var result struct {
+		Results []struct {
+			Index          int     `json:"index"`
+			RelevanceScore float64 `json:"relevance_score"`
+		} `json:"results"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode response failed: %w", err)
+	}
+	// documents missing from the results score 0
+	scores := make([]float64, len(documents))
+	for _, item := range result.Results {
+		if item.Index < 0 || item.Index >= len(documents) {
+			return nil, fmt.Errorf("invalid rerank index: %d", item.Index)
+		}
+		scores[item.Index] = item.RelevanceScore
+	}
+	return scores, nil
+}
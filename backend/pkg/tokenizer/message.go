@@ -0,0 +1,48 @@
+package tokenizer
+
+import (
+	"github.com/cloudwego/eino/schema"
+)
+
+const (
+	// tokens of the chat format around each message and priming the reply, as counted by openai
+	tokensPerMessage = 3
+	tokensPerReply   = 3
+)
+
+// CountMessages number of prompt tokens of chat messages for the model
+func CountMessages(model string, messages []*schema.Message) int {
+	t := ForModel(model)
+	total := tokensPerReply
+	for _, message := range messages {
+		total += countMessage(t, message)
+	}
+	return total
+}
+
+func countMessage(t Tokenizer, message *schema.Message) int {
+	return tokensPerMessage + len(t.Encode(string(message.Role))) + len(t.Encode(message.Content))
+}
+
+// TrimHistory drop the oldest history messages until history fits in budget tokens.
+// the first (system) and the last (question) messages are always kept
+func TrimHistory(model string, messages []*schema.Message, budget int) []*schema.Message {
+	if len(messages) <= 2 {
+		return messages
+	}
+	t := ForModel(model)
+	history := messages[1 : len(messages)-1]
+	used := 0
+	keep := len(history)
+	for i := len(history) - 1; i >= 0; i-- {
+		used += countMessage(t, history[i])
+		if used > budget {
+			break
+		}
+		keep = i
+	}
+	trimmed := make([]*schema.Message, 0, len(messages)-keep)
+	trimmed = append(trimmed, messages[0])
+	trimmed = append(trimmed, history[keep:]...)
+	return append(trimmed, messages[len(messages)-1])
+}
@@ -0,0 +1,178 @@
+// Package tokenizer count and split text in tokens of the model which consumes it.
+//
+// OpenAI model families use their tiktoken encodings (bpe ranks are embedded, nothing is downloaded).
+// Other families fall back to cl100k_base, which is much closer to their bpe tokenizers than
+// counting characters. Register adds tokenizers of other families, e.g. sentencepiece models.
+package tokenizer
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/pkoukk/tiktoken-go"
+	tiktoken_loader "github.com/pkoukk/tiktoken-go-loader"
+)
+
+// Tokenizer encodes text to token ids of a model family
+type Tokenizer interface {
+	Name() string
+	Encode(text string) []int
+	Decode(tokens []int) string
+}
+
+// Factory creates a tokenizer, it is called once on first use
+type Factory func() (Tokenizer, error)
+
+type family struct {
+	name     string
+	match    func(model string) bool
+	factory  Factory
+	once     sync.Once
+	instance Tokenizer
+	err      error
+}
+
+func (f *family) get() (Tokenizer, error) {
+	f.once.Do(func() {
+		f.instance, f.err = f.factory()
+	})
+	return f.instance, f.err
+}
+
+var (
+	mutex    sync.RWMutex
+	families []*family
+	fallback = &family{
+		name:    tiktoken.MODEL_CL100K_BASE,
+		match:   prefixMatcher("gpt-4", "gpt-3.5", "text-embedding-"),
+		factory: tiktokenFactory(tiktoken.MODEL_CL100K_BASE),
+	}
+)
+
+func init() {
+	tiktoken.SetBpeLoader(tiktoken_loader.NewOfflineLoader())
+
+	register(fallback)
+	// newer families share prefixes with older ones, so they are registered later
+	Register(tiktoken.MODEL_O200K_BASE, prefixMatcher("gpt-4o", "gpt-4.1", "gpt-4.5", "gpt-5", "o1", "o3", "o4", "chatgpt-"),
+		tiktokenFactory(tiktoken.MODEL_O200K_BASE))
+}
+
+// Register add tokenizer of a model family, families registered later take precedence
+func Register(name string, match func(model string) bool, factory Factory) {
+	register(&family{name: name, match: match, factory: factory})
+}
+
+func register(f *family) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	families = append([]*family{f}, families...)
+}
+
+// ForModel tokenizer of the model family, cl100k_base if the family is unknown or fails to load
+func ForModel(model string) Tokenizer {
+	name := normalizeModel(model)
+	mutex.RLock()
+	candidates := families
+	mutex.RUnlock()
+	for _, f := range candidates {
+		if !f.match(name) {
+			continue
+		}
+		if t, err := f.get(); err == nil {
+			return t
+		}
+		break
+	}
+	t, err := fallback.get()
+	if err != nil {
+		// embedded ranks can not fail to load unless the binary is broken
+		panic(fmt.Sprintf("load fallback tokenizer failed: %v", err))
+	}
+	return t
+}
+
+// Count number of tokens of text for the model
+func Count(model, text string) int {
+	if text == "" {
+		return 0
+	}
+	return len(ForModel(model).Encode(text))
+}
+
+// Truncate keep the first maxTokens tokens of text
+func Truncate(t Tokenizer, text string, maxTokens int) string {
+	tokens := t.Encode(text)
+	if len(tokens) <= maxTokens {
+		return text
+	}
+	return t.Decode(tokens[:max(maxTokens, 0)])
+}
+
+// Split text into pieces of at most size tokens, adjacent pieces share overlap tokens.
+// pieces are cut at token boundaries, a multi-byte character split by a token boundary may be lost at the cut
+func Split(t Tokenizer, text string, size, overlap int) []string {
+	if size <= 0 {
+		return []string{text}
+	}
+	overlap = min(max(overlap, 0), size-1)
+	tokens := t.Encode(text)
+	pieces := make([]string, 0, len(tokens)/size+1)
+	for start := 0; start < len(tokens); start += size - overlap {
+		end := min(start+size, len(tokens))
+		pieces = append(pieces, t.Decode(tokens[start:end]))
+		if end == len(tokens) {
+			break
+		}
+	}
+	return pieces
+}
+
+type tiktokenTokenizer struct {
+	name     string
+	encoding *tiktoken.Tiktoken
+}
+
+func tiktokenFactory(encoding string) Factory {
+	return func() (Tokenizer, error) {
+		e, err := tiktoken.GetEncoding(encoding)
+		if err != nil {
+			return nil, err
+		}
+		return &tiktokenTokenizer{name: encoding, encoding: e}, nil
+	}
+}
+
+func (t *tiktokenTokenizer) Name() string {
+	return t.name
+}
+
+func (t *tiktokenTokenizer) Encode(text string) []int {
+	// special tokens in user content are counted as plain text
+	return t.encoding.EncodeOrdinary(text)
+}
+
+func (t *tiktokenTokenizer) Decode(tokens []int) string {
+	return t.encoding.Decode(tokens)
+}
+
+func prefixMatcher(prefixes ...string) func(model string) bool {
+	return func(model string) bool {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(model, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// normalizeModel lower case model name without provider prefix, e.g. openai/gpt-4o
+func normalizeModel(model string) string {
+	model = strings.ToLower(strings.TrimSpace(model))
+	if i := strings.LastIndex(model, "/"); i >= 0 {
+		model = model[i+1:]
+	}
+	return model
+}
@@ -0,0 +1,109 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret base32 of the ascii sha1 seed "12345678901234567890" of the test vectors of RFC 6238
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCodeRFC6238(t *testing.T) {
+	// the 8 digit codes of the rfc truncated to 6 digits
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1111111111, want: "050471"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+		{unix: 20000000000, want: "353130"},
+	}
+	for _, tt := range tests {
+		t.Run(time.Unix(tt.unix, 0).UTC().Format(time.RFC3339), func(t *testing.T) {
+			got, err := Code(rfcSecret, Step(time.Unix(tt.unix, 0)))
+			if err != nil {
+				t.Fatalf("Code() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Code() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCodeSecretFormats(t *testing.T) {
+	want, _ := Code(rfcSecret, 1)
+	for _, secret := range []string{strings.ToLower(rfcSecret), rfcSecret + "===="} {
+		if got, err := Code(secret, 1); err != nil || got != want {
+			t.Errorf("Code(%q) = %q, %v, want %q", secret, got, err, want)
+		}
+	}
+	if _, err := Code("not base32!", 1); err == nil {
+		t.Error("Code() of invalid secret error = nil")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := Step(now)
+	codeAt := func(step int64) string {
+		code, err := Code(rfcSecret, step)
+		if err != nil {
+			t.Fatalf("Code() error = %v", err)
+		}
+		return code
+	}
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		wantStep int64
+		wantOK   bool
+	}{
+		{name: "current step", secret: rfcSecret, code: codeAt(current), wantStep: current, wantOK: true},
+		{name: "one step behind", secret: rfcSecret, code: codeAt(current - 1), wantStep: current - 1, wantOK: true},
+		{name: "one step ahead", secret: rfcSecret, code: codeAt(current + 1), wantStep: current + 1, wantOK: true},
+		{name: "two steps behind", secret: rfcSecret, code: codeAt(current - 2)},
+		{name: "two steps ahead", secret: rfcSecret, code: codeAt(current + 2)},
+		{name: "wrong code", secret: rfcSecret, code: "000000"},
+		{name: "short code", secret: rfcSecret, code: codeAt(current)[:5]},
+		{name: "8 digit code of the rfc", secret: rfcSecret, code: "14050471"},
+		{name: "invalid secret", secret: "not base32!", code: codeAt(current)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := Validate(tt.secret, tt.code, now)
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Errorf("Validate() = %d, %v, want %d, %v", step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret() error = %v", err)
+	}
+	if len(secret) != 32 {
+		t.Errorf("len(secret) = %d, want 32", len(secret))
+	}
+	if _, err := Code(secret, 0); err != nil {
+		t.Errorf("Code() of generated secret error = %v", err)
+	}
+	if other, _ := GenerateSecret(); other == secret {
+		t.Error("GenerateSecret() returned the same secret twice")
+	}
+}
+
+func TestURI(t *testing.T) {
+	got := URI("Panda Wiki", "admin", rfcSecret)
+	want := "otpauth://totp/Panda%20Wiki:admin?algorithm=SHA1&digits=6&issuer=Panda+Wiki&period=30&secret=" + rfcSecret
+	if got != want {
+		t.Errorf("URI() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,356 @@
+// Package website crawl pages of a site from its sitemap, or by following links from a start page, as markdown.
+package website
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	htmltomarkdown "github.com/JohannesKaufmann/html-to-markdown/v2"
+	"github.com/JohannesKaufmann/html-to-markdown/v2/converter"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github.com/chaitin/panda-wiki/utils"
+)
+
+const (
+	// max size of a page
+	maxPageSize = 5 * 1024 * 1024
+	userAgent   = "PandaWiki-Crawler/1.0"
+)
+
+var ErrNotHTML = errors.New("not an html page")
+
+// Options of a crawl, pages not matching include are still followed for links, excluded ones are not fetched
+type Options struct {
+	// sitemap.xml, or a page of the site. the sitemap.xml of a site root is used if it exists
+	URL string
+	// link depth from the start page, ignored for sitemaps
+	MaxDepth int
+	MaxPages int
+	Include  []*regexp.Regexp
+	Exclude  []*regexp.Regexp
+}
+
+type Page struct {
+	URL      string
+	Title    string
+	Markdown string
+}
+
+// CompilePatterns regexps of include or exclude rules, matched against full urls
+func CompilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	res := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		if strings.TrimSpace(p) == "" {
+			continue
+		}
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid url pattern %q: %w", p, err)
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
+
+// Name host of the site
+func Name(siteURL string) string {
+	u, err := url.Parse(siteURL)
+	if err != nil || u.Host == "" {
+		return siteURL
+	}
+	return u.Host
+}
+
+// Check fetch the start page or sitemap
+func Check(ctx context.Context, siteURL string) error {
+	if isSitemapURL(siteURL) {
+		if _, err := utils.ParseSitemap(siteURL); err != nil {
+			return err
+		}
+		return nil
+	}
+	_, err := fetch(ctx, siteURL)
+	return err
+}
+
+// Crawl pages of the site in the order they are found, pages failed to fetch are skipped
+func Crawl(ctx context.Context, opts Options) ([]*Page, error) {
+	start, err := url.Parse(opts.URL)
+	if err != nil {
+		return nil, err
+	}
+	if links := sitemapLinks(opts.URL, start); links != nil {
+		return crawlSitemap(ctx, links, opts), nil
+	}
+	return crawlLinks(ctx, start, opts)
+}
+
+// FetchPage the page as markdown
+func FetchPage(ctx context.Context, pageURL string) (*Page, error) {
+	doc, err := fetch(ctx, pageURL)
+	if err != nil {
+		return nil, err
+	}
+	return newPage(pageURL, doc), nil
+}
+
+func isSitemapURL(siteURL string) bool {
+	u, err := url.Parse(siteURL)
+	return err == nil && strings.HasSuffix(strings.ToLower(u.Path), ".xml")
+}
+
+// sitemapLinks urls of the sitemap if the url is a sitemap or the root of a site with one, nil otherwise
+func sitemapLinks(siteURL string, start *url.URL) []string {
+	if isSitemapURL(siteURL) {
+		links, err := utils.ParseSitemap(siteURL)
+		if err != nil {
+			return nil
+		}
+		return links
+	}
+	if start.Path != "" && start.Path != "/" {
+		return nil
+	}
+	root := *start
+	root.Path = "/sitemap.xml"
+	root.RawQuery = ""
+	links, err := utils.ParseSitemap(root.String())
+	if err != nil || len(links) == 0 {
+		return nil
+	}
+	return links
+}
+
+func crawlSitemap(ctx context.Context, links []string, opts Options) []*Page {
+	pages := make([]*Page, 0)
+	seen := make(map[string]bool)
+	for _, link := range links {
+		if len(pages) >= opts.MaxPages || ctx.Err() != nil {
+			break
+		}
+		u, err := url.Parse(link)
+		if err != nil {
+			continue
+		}
+		key := pageKey(u)
+		if seen[key] || !matches(key, opts.Include, true) || matches(key, opts.Exclude, false) {
+			continue
+		}
+		seen[key] = true
+		doc, err := fetch(ctx, key)
+		if err != nil {
+			continue
+		}
+		pages = append(pages, newPage(key, doc))
+	}
+	return pages
+}
+
+func crawlLinks(ctx context.Context, start *url.URL, opts Options) ([]*Page, error) {
+	type queued struct {
+		url   string
+		depth int
+	}
+	startKey := pageKey(start)
+	doc, err := fetch(ctx, startKey)
+	if err != nil {
+		return nil, err
+	}
+	pages := make([]*Page, 0)
+	seen := map[string]bool{startKey: true}
+	queue := make([]queued, 0)
+	visit := func(key string, depth int, doc *html.Node) {
+		if matches(key, opts.Include, true) {
+			pages = append(pages, newPage(key, doc))
+		}
+		if depth >= opts.MaxDepth {
+			return
+		}
+		base, _ := url.Parse(key)
+		for _, link := range links(doc, base) {
+			if seen[link] || link == "" {
+				continue
+			}
+			seen[link] = true
+			if matches(link, opts.Exclude, false) {
+				continue
+			}
+			queue = append(queue, queued{url: link, depth: depth + 1})
+		}
+	}
+	visit(startKey, 0, doc)
+	for len(queue) > 0 && len(pages) < opts.MaxPages && ctx.Err() == nil {
+		next := queue[0]
+		queue = queue[1:]
+		doc, err := fetch(ctx, next.url)
+		if err != nil {
+			continue
+		}
+		visit(next.url, next.depth, doc)
+	}
+	return pages, nil
+}
+
+func matches(link string, patterns []*regexp.Regexp, empty bool) bool {
+	if len(patterns) == 0 {
+		return empty
+	}
+	for _, re := range patterns {
+		if re.MatchString(link) {
+			return true
+		}
+	}
+	return false
+}
+
+// pageKey url without fragment, pages are identified by it
+func pageKey(u *url.URL) string {
+	c := *u
+	c.Fragment = ""
+	c.RawFragment = ""
+	if c.Path == "" {
+		c.Path = "/"
+	}
+	return c.String()
+}
+
+// links of the page on the same host, without files which are not pages
+func links(doc *html.Node, base *url.URL) []string {
+	res := make([]string, 0)
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.DataAtom == atom.A {
+			for _, attr := range n.Attr {
+				if attr.Key != "href" {
+					continue
+				}
+				u, err := base.Parse(strings.TrimSpace(attr.Val))
+				if err != nil || (u.Scheme != "http" && u.Scheme != "https") || !strings.EqualFold(u.Host, base.Host) {
+					continue
+				}
+				if ext := strings.ToLower(path.Ext(u.Path)); ext != "" && ext != ".html" && ext != ".htm" {
+					continue
+				}
+				res = append(res, pageKey(u))
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return res
+}
+
+func fetch(ctx context.Context, pageURL string) (*html.Node, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := (&http.Client{Timeout: 30 * time.Second}).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch %s: %s", pageURL, resp.Status)
+	}
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != "" && mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, ErrNotHTML
+	}
+	return html.Parse(io.LimitReader(resp.Body, maxPageSize))
+}
+
+func newPage(pageURL string, doc *html.Node) *Page {
+	page := &Page{URL: pageURL, Title: title(doc)}
+	if page.Title == "" {
+		page.Title = pageURL
+	}
+	content := mainContent(doc)
+	markdown, err := htmltomarkdown.ConvertNode(content, converter.WithDomain(pageURL))
+	if err == nil {
+		page.Markdown = strings.TrimSpace(string(markdown))
+	}
+	return page
+}
+
+func title(doc *html.Node) string {
+	if n := find(doc, atom.Title); n != nil && n.FirstChild != nil {
+		return strings.TrimSpace(n.FirstChild.Data)
+	}
+	if n := find(doc, atom.H1); n != nil {
+		return strings.TrimSpace(text(n))
+	}
+	return ""
+}
+
+// mainContent main or article element if the page has one, or the body without navigation, scripts and styles
+func mainContent(doc *html.Node) *html.Node {
+	content := find(doc, atom.Main)
+	if content == nil {
+		content = find(doc, atom.Article)
+	}
+	if content == nil {
+		content = find(doc, atom.Body)
+	}
+	if content == nil {
+		content = doc
+	}
+	removeElements(content, atom.Script, atom.Style, atom.Noscript, atom.Nav, atom.Header, atom.Footer, atom.Aside, atom.Form)
+	return content
+}
+
+func removeElements(n *html.Node, atoms ...atom.Atom) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		removed := false
+		if c.Type == html.ElementNode {
+			for _, a := range atoms {
+				if c.DataAtom == a {
+					n.RemoveChild(c)
+					removed = true
+					break
+				}
+			}
+		}
+		if !removed {
+			removeElements(c, atoms...)
+		}
+		c = next
+	}
+}
+
+func find(n *html.Node, a atom.Atom) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == a {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := find(c, a); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func text(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var sb strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		sb.WriteString(text(c))
+	}
+	return sb.String()
+}
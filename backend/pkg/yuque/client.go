@@ -0,0 +1,179 @@
+// Package yuque read the toc and documents of a Yuque repo (book) through the open api.
+package yuque
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultBaseURL = "https://www.yuque.com"
+	// results of each list request, the api caps larger sizes
+	pageSize = 100
+
+	TocTypeDoc   = "DOC"
+	TocTypeTitle = "TITLE"
+	TocTypeLink  = "LINK"
+)
+
+var ErrNotFound = errors.New("yuque repo or document not found or not readable by the token")
+
+type Client struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// NewClient client of a personal or team token, baseURL is the site of the space, e.g. https://example.yuque.com
+func NewClient(baseURL, token string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		token:      token,
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// ParseNamespace namespace of a repo from its namespace or url, e.g. group/book
+func ParseNamespace(s string) string {
+	s = strings.TrimSpace(s)
+	if u, err := url.Parse(s); err == nil && u.Host != "" {
+		s = u.Path
+	}
+	parts := strings.Split(strings.Trim(s, "/"), "/")
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return ""
+	}
+	return parts[0] + "/" + parts[1]
+}
+
+type Repo struct {
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+// TocItem entry of the toc, titles group entries without a document of their own
+type TocItem struct {
+	Type       string `json:"type"`
+	Title      string `json:"title"`
+	UUID       string `json:"uuid"`
+	ParentUUID string `json:"parent_uuid"`
+	// slug of documents, url of links
+	URL   string `json:"url"`
+	DocID int64  `json:"doc_id"`
+}
+
+type Doc struct {
+	ID     int64  `json:"id"`
+	Slug   string `json:"slug"`
+	Title  string `json:"title"`
+	Format string `json:"format"`
+	// markdown of the document, lake documents have it converted by yuque
+	Body             string    `json:"body"`
+	ContentUpdatedAt time.Time `json:"content_updated_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// GetRepo repo of the namespace, checks access to it
+func (c *Client) GetRepo(ctx context.Context, namespace string) (*Repo, error) {
+	repo := &Repo{}
+	if err := c.get(ctx, "/repos/"+namespace, repo); err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+// GetToc entries of the toc of the repo in display order, parents before their children
+func (c *Client) GetToc(ctx context.Context, namespace string) ([]*TocItem, error) {
+	items := make([]*TocItem, 0)
+	if err := c.get(ctx, "/repos/"+namespace+"/toc", &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// ListDocs documents of the repo without their body
+func (c *Client) ListDocs(ctx context.Context, namespace string) ([]*Doc, error) {
+	docs := make([]*Doc, 0)
+	for offset := 0; ; offset += pageSize {
+		query := url.Values{"offset": {fmt.Sprint(offset)}, "limit": {fmt.Sprint(pageSize)}}
+		page := make([]*Doc, 0)
+		if err := c.get(ctx, "/repos/"+namespace+"/docs?"+query.Encode(), &page); err != nil {
+			return nil, err
+		}
+		docs = append(docs, page...)
+		if len(page) < pageSize {
+			return docs, nil
+		}
+	}
+}
+
+// GetDoc document of the repo by its slug
+func (c *Client) GetDoc(ctx context.Context, namespace, slug string) (*Doc, error) {
+	doc := &Doc{}
+	if err := c.get(ctx, "/repos/"+namespace+"/docs/"+url.PathEscape(slug), doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// Download content of an image of a document, the caller closes the body
+func (c *Client) Download(ctx context.Context, fileURL string) (io.ReadCloser, int64, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, nil)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	// the cdn refuses requests without a yuque referer
+	req.Header.Set("Referer", c.baseURL+"/")
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, 0, "", fmt.Errorf("download yuque file: %s", resp.Status)
+	}
+	return resp.Body, resp.ContentLength, resp.Header.Get("Content-Type"), nil
+}
+
+// get request path of the api v2 and decode its data, not found responses are ErrNotFound
+func (c *Client) get(ctx context.Context, path string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/api/v2"+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Auth-Token", c.token)
+	req.Header.Set("User-Agent", "PandaWiki")
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("yuque GET %s: %s", path, strings.TrimSpace(string(data)))
+	}
+	result := struct {
+		Data json.RawMessage `json:"data"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("decode yuque response failed: %w", err)
+	}
+	if err := json.Unmarshal(result.Data, v); err != nil {
+		return fmt.Errorf("decode yuque response failed: %w", err)
+	}
+	return nil
+}
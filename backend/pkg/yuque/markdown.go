@@ -0,0 +1,89 @@
+package yuque
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	anchorRegex = regexp.MustCompile(`<a name="[^"]*"></a>`)
+	// links and images, images start with !
+	linkRegex = regexp.MustCompile(`(!?\[[^\]]*\]\()([^)\s]+)((?:\s+"[^"]*")?\))`)
+	// :::tips, :::info, :::warning, :::danger, :::success and colored blocks
+	calloutRegex = regexp.MustCompile(`(?ms)^:::(\w+)[ \t]*\n(.*?)\n:::[ \t]*$`)
+)
+
+// Resolver urls of documents and images referenced by a document, empty if the target is not imported
+type Resolver interface {
+	DocURL(namespace, slug string) string
+	ImageURL(src string) string
+}
+
+// ImageURLs sources of the images of the markdown, without the size and color fragment of yuque
+func ImageURLs(body string) []string {
+	urls := make([]string, 0)
+	for _, m := range linkRegex.FindAllStringSubmatch(body, -1) {
+		if strings.HasPrefix(m[1], "!") {
+			urls = append(urls, imageSource(m[2]))
+		}
+	}
+	return urls
+}
+
+// ConvertMarkdown plain markdown of a yuque document: anchors are dropped, callouts become quotes,
+// images point at the imported files and links to documents of imported repos at their nodes
+func ConvertMarkdown(body string, resolver Resolver) string {
+	body = strings.ReplaceAll(body, "\r\n", "\n")
+	body = anchorRegex.ReplaceAllString(body, "")
+	body = calloutRegex.ReplaceAllStringFunc(body, func(match string) string {
+		content := calloutRegex.FindStringSubmatch(match)[2]
+		lines := strings.Split(content, "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimRight("> "+line, " ")
+		}
+		return strings.Join(lines, "\n")
+	})
+	return linkRegex.ReplaceAllStringFunc(body, func(match string) string {
+		parts := linkRegex.FindStringSubmatch(match)
+		if strings.HasPrefix(parts[1], "!") {
+			src := imageSource(parts[2])
+			if ref := resolver.ImageURL(src); ref != "" {
+				src = ref
+			}
+			return parts[1] + src + parts[3]
+		}
+		namespace, slug, fragment := docTarget(parts[2])
+		if slug == "" {
+			return match
+		}
+		href := resolver.DocURL(namespace, slug)
+		if href == "" {
+			return match
+		}
+		return parts[1] + href + fragment + parts[3]
+	})
+}
+
+// imageSource drop the fragment yuque appends to images with their size and color
+func imageSource(src string) string {
+	src, _, _ = strings.Cut(src, "#")
+	return src
+}
+
+// docTarget namespace and slug of a link to a yuque document, e.g. https://www.yuque.com/group/book/slug#anchor
+func docTarget(href string) (string, string, string) {
+	u, err := url.Parse(href)
+	if err != nil || !strings.Contains(u.Host, "yuque.com") {
+		return "", "", ""
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) != 3 {
+		return "", "", ""
+	}
+	fragment := ""
+	if u.Fragment != "" {
+		fragment = "#" + u.Fragment
+	}
+	return parts[0] + "/" + parts[1], parts[2], fragment
+}
@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/store/cache"
+)
+
+type AnomalyRepo struct {
+	cache  *cache.Cache
+	logger *log.Logger
+}
+
+func NewAnomalyCache(cache *cache.Cache, logger *log.Logger) *AnomalyRepo {
+	return &AnomalyRepo{
+		cache:  cache,
+		logger: logger.WithModule("repo.cache.anomaly"),
+	}
+}
+
+func anomalyKey(kbID, ip, name string) string {
+	return fmt.Sprintf("anomaly:%s:%s:%s", kbID, ip, name)
+}
+
+// Incr count a hit of the ip in fixed window, return hits in the window
+func (r *AnomalyRepo) Incr(ctx context.Context, kbID, ip, name string, window time.Duration) (int64, error) {
+	key := anomalyKey(kbID, ip, name)
+	count, err := r.cache.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if count == 1 {
+		if err := r.cache.Expire(ctx, key, window).Err(); err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
+
+// Block refuse the ip for ttl
+func (r *AnomalyRepo) Block(ctx context.Context, kbID, ip string, ttl time.Duration) error {
+	return r.cache.Set(ctx, anomalyKey(kbID, ip, "block"), "1", ttl).Err()
+}
+
+func (r *AnomalyRepo) IsBlocked(ctx context.Context, kbID, ip string) (bool, error) {
+	err := r.cache.Get(ctx, anomalyKey(kbID, ip, "block")).Err()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Unblock lift the block and reset all counters of the ip, or it would be blocked again by the next hit
+func (r *AnomalyRepo) Unblock(ctx context.Context, kbID, ip string) error {
+	var keys []string
+	iter := r.cache.Scan(ctx, 0, anomalyKey(kbID, ip, "*"), 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	return r.cache.Del(ctx, keys...).Err()
+}
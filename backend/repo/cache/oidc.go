@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/store/cache"
+)
+
+// OIDCStateRepo logins in progress keyed by their state, each state is used once
+type OIDCStateRepo struct {
+	cache  *cache.Cache
+	logger *log.Logger
+}
+
+func NewOIDCStateCache(cache *cache.Cache, logger *log.Logger) *OIDCStateRepo {
+	return &OIDCStateRepo{
+		cache:  cache,
+		logger: logger.WithModule("repo.cache.oidc"),
+	}
+}
+
+func oidcStateKey(state string) string {
+	return fmt.Sprintf("oidc_state:%s", state)
+}
+
+func (r *OIDCStateRepo) Set(ctx context.Context, state string, login *domain.OIDCLoginState) error {
+	data, err := json.Marshal(login)
+	if err != nil {
+		return err
+	}
+	return r.cache.Set(ctx, oidcStateKey(state), data, domain.OIDCLoginStateTTL).Err()
+}
+
+// Take the login of the state and remove it, nil if unknown or expired
+func (r *OIDCStateRepo) Take(ctx context.Context, state string) (*domain.OIDCLoginState, error) {
+	data, err := r.cache.GetDel(ctx, oidcStateKey(state)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	login := &domain.OIDCLoginState{}
+	if err := json.Unmarshal(data, login); err != nil {
+		return nil, err
+	}
+	return login, nil
+}
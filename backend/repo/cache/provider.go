@@ -10,4 +10,11 @@ var ProviderSet = wire.NewSet(
 	cache.NewCache,
 	NewKBRepo,
 	NewGeoCache,
+	NewVisitorCache,
+	NewRateLimitCache,
+	NewAnomalyCache,
+	NewRetrievalCache,
+	NewOIDCStateCache,
+	NewTwoFactorChallengeCache,
+	NewSessionCache,
 )
@@ -0,0 +1,37 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/store/cache"
+)
+
+type RateLimitRepo struct {
+	cache  *cache.Cache
+	logger *log.Logger
+}
+
+func NewRateLimitCache(cache *cache.Cache, logger *log.Logger) *RateLimitRepo {
+	return &RateLimitRepo{
+		cache:  cache,
+		logger: logger.WithModule("repo.cache.rate_limit"),
+	}
+}
+
+// Allow count a hit of key in fixed window, return false if the limit is exceeded
+func (r *RateLimitRepo) Allow(ctx context.Context, key string, limit int64, window time.Duration) (bool, error) {
+	key = fmt.Sprintf("rate_limit:%s", key)
+	count, err := r.cache.Incr(ctx, key).Result()
+	if err != nil {
+		return false, err
+	}
+	if count == 1 {
+		if err := r.cache.Expire(ctx, key, window).Err(); err != nil {
+			return true, err
+		}
+	}
+	return count <= limit, nil
+}
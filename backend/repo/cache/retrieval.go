@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/store/cache"
+)
+
+// RetrievalRepo documents retrieved for draft questions, keyed by the normalized question
+type RetrievalRepo struct {
+	cache  *cache.Cache
+	logger *log.Logger
+}
+
+func NewRetrievalCache(cache *cache.Cache, logger *log.Logger) *RetrievalRepo {
+	return &RetrievalRepo{
+		cache:  cache,
+		logger: logger.WithModule("repo.cache.retrieval"),
+	}
+}
+
+func retrievalKey(kbID, question string) string {
+	sum := sha1.Sum([]byte(question))
+	return fmt.Sprintf("retrieval:%s:%s", kbID, hex.EncodeToString(sum[:]))
+}
+
+// Get ranked nodes of the question, nil if not retrieved or expired
+func (r *RetrievalRepo) Get(ctx context.Context, kbID, question string) ([]*domain.RankedNodeChunks, error) {
+	data, err := r.cache.Get(ctx, retrievalKey(kbID, question)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	nodes := make([]*domain.RankedNodeChunks, 0)
+	if err := json.Unmarshal(data, &nodes); err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
+
+func (r *RetrievalRepo) Set(ctx context.Context, kbID, question string, nodes []*domain.RankedNodeChunks, ttl time.Duration) error {
+	data, err := json.Marshal(nodes)
+	if err != nil {
+		return err
+	}
+	return r.cache.Set(ctx, retrievalKey(kbID, question), data, ttl).Err()
+}
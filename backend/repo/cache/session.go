@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/store/cache"
+)
+
+// SessionRepo whether console sessions are active, checked on every request, keyed by session id.
+// states missing after a restart of redis are loaded from postgres again
+type SessionRepo struct {
+	cache  *cache.Cache
+	logger *log.Logger
+}
+
+func NewSessionCache(cache *cache.Cache, logger *log.Logger) *SessionRepo {
+	return &SessionRepo{
+		cache:  cache,
+		logger: logger.WithModule("repo.cache.session"),
+	}
+}
+
+func sessionKey(id string) string {
+	return fmt.Sprintf("session:%s", id)
+}
+
+// Get whether the session is active, found is false if the state is not cached
+func (r *SessionRepo) Get(ctx context.Context, id string) (active bool, found bool, err error) {
+	value, err := r.cache.Get(ctx, sessionKey(id)).Result()
+	if errors.Is(err, redis.Nil) {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, err
+	}
+	return value == "1", true, nil
+}
+
+// Set cache the state of the session until it expires
+func (r *SessionRepo) Set(ctx context.Context, id string, active bool, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl < time.Second {
+		ttl = time.Second
+	}
+	value := "0"
+	if active {
+		value = "1"
+	}
+	return r.cache.Set(ctx, sessionKey(id), value, ttl).Err()
+}
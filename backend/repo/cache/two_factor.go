@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/store/cache"
+)
+
+// TwoFactorChallengeRepo logins waiting for their second factor keyed by their challenge token
+type TwoFactorChallengeRepo struct {
+	cache  *cache.Cache
+	logger *log.Logger
+}
+
+func NewTwoFactorChallengeCache(cache *cache.Cache, logger *log.Logger) *TwoFactorChallengeRepo {
+	return &TwoFactorChallengeRepo{
+		cache:  cache,
+		logger: logger.WithModule("repo.cache.two_factor"),
+	}
+}
+
+func twoFactorChallengeKey(token string) string {
+	return fmt.Sprintf("two_factor_challenge:%s", token)
+}
+
+func twoFactorAttemptsKey(token string) string {
+	return fmt.Sprintf("two_factor_attempts:%s", token)
+}
+
+func (r *TwoFactorChallengeRepo) Set(ctx context.Context, token string, challenge *domain.TwoFactorChallenge) error {
+	data, err := json.Marshal(challenge)
+	if err != nil {
+		return err
+	}
+	return r.cache.Set(ctx, twoFactorChallengeKey(token), data, domain.TwoFactorChallengeTTL).Err()
+}
+
+// Get the challenge of the token, nil if unknown or expired
+func (r *TwoFactorChallengeRepo) Get(ctx context.Context, token string) (*domain.TwoFactorChallenge, error) {
+	data, err := r.cache.Get(ctx, twoFactorChallengeKey(token)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	challenge := &domain.TwoFactorChallenge{}
+	if err := json.Unmarshal(data, challenge); err != nil {
+		return nil, err
+	}
+	return challenge, nil
+}
+
+// Fail count a wrong code for the challenge, return the number of wrong codes so far
+func (r *TwoFactorChallengeRepo) Fail(ctx context.Context, token string) (int64, error) {
+	key := twoFactorAttemptsKey(token)
+	count, err := r.cache.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if count == 1 {
+		if err := r.cache.Expire(ctx, key, domain.TwoFactorChallengeTTL).Err(); err != nil {
+			return 0, err
+		}
+	}
+	return count, nil
+}
+
+func (r *TwoFactorChallengeRepo) Delete(ctx context.Context, token string) error {
+	return r.cache.Del(ctx, twoFactorChallengeKey(token), twoFactorAttemptsKey(token)).Err()
+}
@@ -0,0 +1,37 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/store/cache"
+)
+
+type VisitorRepo struct {
+	cache  *cache.Cache
+	logger *log.Logger
+}
+
+func NewVisitorCache(cache *cache.Cache, logger *log.Logger) *VisitorRepo {
+	return &VisitorRepo{
+		cache:  cache,
+		logger: logger.WithModule("repo.cache.visitor"),
+	}
+}
+
+// AddNodeVisitor record visitor of node today, return true if it is the first visit of the day
+func (r *VisitorRepo) AddNodeVisitor(ctx context.Context, nodeID, visitor string) (bool, error) {
+	key := fmt.Sprintf("visitor:%s:%s", nodeID, time.Now().Format("2006-01-02"))
+	added, err := r.cache.PFAdd(ctx, key, visitor).Result()
+	if err != nil {
+		return false, err
+	}
+	if added == 1 {
+		if err := r.cache.Expire(ctx, key, 25*time.Hour).Err(); err != nil {
+			return true, err
+		}
+	}
+	return added == 1, nil
+}
@@ -0,0 +1,25 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/mq"
+)
+
+type ConversationRescoreRepository struct {
+	producer mq.MQProducer
+}
+
+func NewConversationRescoreRepository(producer mq.MQProducer) *ConversationRescoreRepository {
+	return &ConversationRescoreRepository{producer: producer}
+}
+
+func (r *ConversationRescoreRepository) AsyncRunRescoreJob(ctx context.Context, kbID, jobID string) error {
+	requestBytes, err := json.Marshal(&domain.ConversationRescoreJobRequest{JobID: jobID})
+	if err != nil {
+		return err
+	}
+	return r.producer.Produce(ctx, domain.ConversationRescoreTopic, kbID, requestBytes)
+}
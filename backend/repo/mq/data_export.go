@@ -0,0 +1,25 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/mq"
+)
+
+type DataExportRepository struct {
+	producer mq.MQProducer
+}
+
+func NewDataExportRepository(producer mq.MQProducer) *DataExportRepository {
+	return &DataExportRepository{producer: producer}
+}
+
+func (r *DataExportRepository) AsyncRunExportJob(ctx context.Context, kbID, jobID string) error {
+	requestBytes, err := json.Marshal(&domain.DataExportJobRequest{JobID: jobID})
+	if err != nil {
+		return err
+	}
+	return r.producer.Produce(ctx, domain.DataExportTopic, kbID, requestBytes)
+}
@@ -0,0 +1,25 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/mq"
+)
+
+type EvalRepository struct {
+	producer mq.MQProducer
+}
+
+func NewEvalRepository(producer mq.MQProducer) *EvalRepository {
+	return &EvalRepository{producer: producer}
+}
+
+func (r *EvalRepository) AsyncRunEval(ctx context.Context, kbID, runID string) error {
+	requestBytes, err := json.Marshal(&domain.EvalRunRequest{RunID: runID})
+	if err != nil {
+		return err
+	}
+	return r.producer.Produce(ctx, domain.EvalRunTopic, kbID, requestBytes)
+}
@@ -0,0 +1,25 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/mq"
+)
+
+type NodeExportRepository struct {
+	producer mq.MQProducer
+}
+
+func NewNodeExportRepository(producer mq.MQProducer) *NodeExportRepository {
+	return &NodeExportRepository{producer: producer}
+}
+
+func (r *NodeExportRepository) AsyncRunExportJob(ctx context.Context, kbID, jobID string) error {
+	requestBytes, err := json.Marshal(&domain.NodeExportJobRequest{JobID: jobID})
+	if err != nil {
+		return err
+	}
+	return r.producer.Produce(ctx, domain.NodeExportTopic, kbID, requestBytes)
+}
@@ -0,0 +1,25 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/mq"
+)
+
+type NodeReplaceRepository struct {
+	producer mq.MQProducer
+}
+
+func NewNodeReplaceRepository(producer mq.MQProducer) *NodeReplaceRepository {
+	return &NodeReplaceRepository{producer: producer}
+}
+
+func (r *NodeReplaceRepository) AsyncRunReplaceJob(ctx context.Context, kbID, jobID string) error {
+	requestBytes, err := json.Marshal(&domain.NodeReplaceJobRequest{JobID: jobID})
+	if err != nil {
+		return err
+	}
+	return r.producer.Produce(ctx, domain.NodeReplaceTopic, kbID, requestBytes)
+}
@@ -12,4 +12,11 @@ var ProviderSet = wire.NewSet(
 
 	cache.ProviderSet,
 	NewRAGRepository,
+	NewStatEventRepository,
+	NewNodeReplaceRepository,
+	NewNodeExportRepository,
+	NewDataExportRepository,
+	NewConversationRescoreRepository,
+	NewEvalRepository,
+	NewWebhookRepository,
 )
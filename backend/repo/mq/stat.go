@@ -0,0 +1,48 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/chaitin/panda-wiki/config"
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/mq"
+)
+
+type StatEventRepository struct {
+	producer mq.MQProducer
+	config   *config.Config
+}
+
+func NewStatEventRepository(producer mq.MQProducer, config *config.Config) *StatEventRepository {
+	return &StatEventRepository{producer: producer, config: config}
+}
+
+// AsyncPublishStatEvent publish stat event to stat sink, do nothing if stat sink is disabled
+func (r *StatEventRepository) AsyncPublishStatEvent(ctx context.Context, eventType domain.StatEventType, kbID string, data any) error {
+	if r.config.StatSink.Type == "" {
+		return nil
+	}
+	dataBytes, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	id, err := uuid.NewV7()
+	if err != nil {
+		return err
+	}
+	eventBytes, err := json.Marshal(&domain.StatEvent{
+		ID:        id.String(),
+		Type:      eventType,
+		KBID:      kbID,
+		Data:      dataBytes,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	return r.producer.Produce(ctx, domain.StatEventTopic, kbID, eventBytes)
+}
@@ -0,0 +1,43 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/mq"
+)
+
+type WebhookRepository struct {
+	producer mq.MQProducer
+}
+
+func NewWebhookRepository(producer mq.MQProducer) *WebhookRepository {
+	return &WebhookRepository{producer: producer}
+}
+
+// AsyncPublishWebhookEvent publish event to be delivered to webhooks of the kb
+func (r *WebhookRepository) AsyncPublishWebhookEvent(ctx context.Context, eventType domain.WebhookEventType, kbID string, data any) error {
+	dataBytes, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	id, err := uuid.NewV7()
+	if err != nil {
+		return err
+	}
+	eventBytes, err := json.Marshal(&domain.WebhookEvent{
+		ID:        id.String(),
+		Type:      eventType,
+		KBID:      kbID,
+		Data:      dataBytes,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	return r.producer.Produce(ctx, domain.WebhookEventTopic, kbID, eventBytes)
+}
@@ -0,0 +1,105 @@
+package pg
+
+import (
+	"context"
+	"time"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/store/pg"
+)
+
+type AnomalyRepository struct {
+	db *pg.DB
+}
+
+func NewAnomalyRepository(db *pg.DB) *AnomalyRepository {
+	return &AnomalyRepository{db: db}
+}
+
+func (r *AnomalyRepository) CreateAnomaly(ctx context.Context, anomaly *domain.ConversationAnomaly) error {
+	return r.db.WithContext(ctx).Create(anomaly).Error
+}
+
+func (r *AnomalyRepository) GetAnomalyList(ctx context.Context, req *domain.AnomalyListReq) ([]*domain.AnomalyListItem, uint64, error) {
+	query := r.db.WithContext(ctx).
+		Model(&domain.ConversationAnomaly{}).
+		Where("kb_id = ?", req.KBID)
+	if req.Type != "" {
+		query = query.Where("type = ?", req.Type)
+	}
+	if req.RemoteIP != "" {
+		query = query.Where("remote_ip = ?", req.RemoteIP)
+	}
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	anomalies := []*domain.ConversationAnomaly{}
+	if err := query.
+		Offset(req.Offset()).
+		Limit(req.Limit()).
+		Order("created_at DESC").
+		Find(&anomalies).Error; err != nil {
+		return nil, 0, err
+	}
+	items := make([]*domain.AnomalyListItem, 0, len(anomalies))
+	for _, anomaly := range anomalies {
+		items = append(items, &domain.AnomalyListItem{ConversationAnomaly: anomaly})
+	}
+	return items, uint64(count), nil
+}
+
+func (r *AnomalyRepository) GetAnomalyTypeCounts(ctx context.Context, kbID string, since time.Time) ([]*domain.AnomalyTypeCount, error) {
+	counts := []*domain.AnomalyTypeCount{}
+	if err := r.db.WithContext(ctx).
+		Model(&domain.ConversationAnomaly{}).
+		Select("type, COUNT(*) AS count").
+		Where("kb_id = ?", kbID).
+		Where("created_at >= ?", since).
+		Group("type").
+		Order("count DESC").
+		Find(&counts).Error; err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+func (r *AnomalyRepository) GetTopAnomalyIPs(ctx context.Context, kbID string, since time.Time, limit int) ([]*domain.AnomalyIPCount, error) {
+	counts := []*domain.AnomalyIPCount{}
+	if err := r.db.WithContext(ctx).
+		Model(&domain.ConversationAnomaly{}).
+		Select("remote_ip, COUNT(*) AS count").
+		Where("kb_id = ?", kbID).
+		Where("created_at >= ?", since).
+		Group("remote_ip").
+		Order("count DESC").
+		Limit(limit).
+		Find(&counts).Error; err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// GetActiveBlocks latest anomaly of each ip whose block is still in effect
+func (r *AnomalyRepository) GetActiveBlocks(ctx context.Context, kbID string) ([]*domain.ConversationAnomaly, error) {
+	anomalies := []*domain.ConversationAnomaly{}
+	if err := r.db.WithContext(ctx).
+		Raw(`SELECT DISTINCT ON (remote_ip) * FROM conversation_anomalies
+			WHERE kb_id = ? AND blocked_until > ?
+			ORDER BY remote_ip, blocked_until DESC`, kbID, time.Now()).
+		Scan(&anomalies).Error; err != nil {
+		return nil, err
+	}
+	return anomalies, nil
+}
+
+// EndBlock end the blocks of the ip, kept as history of the report
+func (r *AnomalyRepository) EndBlock(ctx context.Context, kbID, remoteIP string) error {
+	now := time.Now()
+	return r.db.WithContext(ctx).
+		Model(&domain.ConversationAnomaly{}).
+		Where("kb_id = ?", kbID).
+		Where("remote_ip = ?", remoteIP).
+		Where("blocked_until > ?", now).
+		Update("blocked_until", now).Error
+}
@@ -0,0 +1,66 @@
+package pg
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/store/pg"
+)
+
+type AnswerCacheRepository struct {
+	db *pg.DB
+}
+
+func NewAnswerCacheRepository(db *pg.DB) *AnswerCacheRepository {
+	return &AnswerCacheRepository{db: db}
+}
+
+// GetAnswerCacheCandidates unexpired entries asked with the same app, locale, citation style and region, newest first
+func (r *AnswerCacheRepository) GetAnswerCacheCandidates(ctx context.Context, key *domain.AnswerCacheKey, limit int) ([]*domain.AnswerCacheEntry, error) {
+	entries := []*domain.AnswerCacheEntry{}
+	if err := r.db.WithContext(ctx).
+		Where("kb_id = ?", key.KBID).
+		Where("app_id = ?", key.AppID).
+		Where("locale = ?", key.Locale).
+		Where("region = ?", key.Region).
+		Where("citation_style = ?", key.CitationStyle).
+		Where("settings_version = ?", key.SettingsVersion).
+		Where("expires_at > ?", time.Now()).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// CreateAnswerCacheEntry save the entry and drop expired entries of the kb
+func (r *AnswerCacheRepository) CreateAnswerCacheEntry(ctx context.Context, entry *domain.AnswerCacheEntry) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("kb_id = ?", entry.KBID).Where("expires_at <= ?", time.Now()).Delete(&domain.AnswerCacheEntry{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(entry).Error
+	})
+}
+
+func (r *AnswerCacheRepository) IncrAnswerCacheHits(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.AnswerCacheEntry{}).
+		Where("id = ?", id).
+		Update("hits", gorm.Expr("hits + 1")).Error
+}
+
+// DeleteNodesAnswerCache drop entries of the kb answered from any of the nodes
+func (r *AnswerCacheRepository) DeleteNodesAnswerCache(ctx context.Context, kbID string, nodeIDs []string) error {
+	if len(nodeIDs) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).
+		Where("kb_id = ?", kbID).
+		Where("jsonb_exists_any(node_ids, ARRAY[?]::text[])", nodeIDs).
+		Delete(&domain.AnswerCacheEntry{}).Error
+}
@@ -0,0 +1,83 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/store/pg"
+)
+
+type APITokenRepository struct {
+	db *pg.DB
+}
+
+func NewAPITokenRepository(db *pg.DB) *APITokenRepository {
+	return &APITokenRepository{db: db}
+}
+
+func (r *APITokenRepository) CreateAPIToken(ctx context.Context, token *domain.APIToken) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+func (r *APITokenRepository) GetAPIToken(ctx context.Context, id string) (*domain.APIToken, error) {
+	token := &domain.APIToken{}
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrAPITokenNotFound
+		}
+		return nil, err
+	}
+	return token, nil
+}
+
+// GetAPITokenByHash token of the hash, nil if not found
+func (r *APITokenRepository) GetAPITokenByHash(ctx context.Context, hash string) (*domain.APIToken, error) {
+	var tokens []*domain.APIToken
+	if err := r.db.WithContext(ctx).Where("token_hash = ?", hash).Limit(1).Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	return tokens[0], nil
+}
+
+// GetAPITokenList tokens created by the user, every token if user id is empty
+func (r *APITokenRepository) GetAPITokenList(ctx context.Context, userID string) ([]*domain.APIToken, error) {
+	tokens := []*domain.APIToken{}
+	query := r.db.WithContext(ctx).Order("created_at DESC")
+	if userID != "" {
+		query = query.Where("created_by = ?", userID)
+	}
+	if err := query.Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// RotateAPIToken replace the hash of the token, the previous token stops working at once
+func (r *APITokenRepository) RotateAPIToken(ctx context.Context, id, hash, prefix string, now time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.APIToken{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"token_hash": hash,
+			"prefix":     prefix,
+			"rotated_at": now,
+		}).Error
+}
+
+func (r *APITokenRepository) TouchAPIToken(ctx context.Context, id string, now time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.APIToken{}).
+		Where("id = ?", id).
+		Update("last_used_at", now).Error
+}
+
+func (r *APITokenRepository) DeleteAPIToken(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&domain.APIToken{}).Error
+}
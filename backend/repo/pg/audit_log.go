@@ -0,0 +1,111 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/store/pg"
+)
+
+type AuditLogRepository struct {
+	db *pg.DB
+}
+
+func NewAuditLogRepository(db *pg.DB) *AuditLogRepository {
+	return &AuditLogRepository{db: db}
+}
+
+// CreateAuditLog append the entry to the chain, the table is locked against other writers
+// so every entry is hashed with the hash of the entry before it
+func (r *AuditLogRepository) CreateAuditLog(ctx context.Context, entry *domain.AuditLog) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("LOCK TABLE audit_logs IN SHARE ROW EXCLUSIVE MODE").Error; err != nil {
+			return err
+		}
+		var hashes []string
+		if err := tx.Model(&domain.AuditLog{}).
+			Order("id DESC").
+			Limit(1).
+			Pluck("hash", &hashes).Error; err != nil {
+			return err
+		}
+		entry.PrevHash = ""
+		if len(hashes) > 0 {
+			entry.PrevHash = hashes[0]
+		}
+		entry.Hash = entry.ComputeHash()
+		return tx.Create(entry).Error
+	})
+}
+
+func (r *AuditLogRepository) GetAuditLogList(ctx context.Context, req *domain.AuditLogListReq) ([]*domain.AuditLogListItem, uint64, error) {
+	query := r.db.WithContext(ctx).Model(&domain.AuditLog{})
+	if req.ActorID != "" {
+		query = query.Where("actor_id = ?", req.ActorID)
+	}
+	if req.ResourceType != "" {
+		query = query.Where("resource_type = ?", req.ResourceType)
+	}
+	if req.ResourceID != "" {
+		query = query.Where("resource_id = ?", req.ResourceID)
+	}
+	if req.KBID != "" {
+		query = query.Where("kb_id = ?", req.KBID)
+	}
+	if req.StartTime > 0 {
+		query = query.Where("created_at >= ?", time.Unix(req.StartTime, 0))
+	}
+	if req.EndTime > 0 {
+		query = query.Where("created_at < ?", time.Unix(req.EndTime, 0))
+	}
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	items := []*domain.AuditLogListItem{}
+	if err := query.
+		Offset(req.Offset()).
+		Limit(req.Limit()).
+		Order("id DESC").
+		Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+	return items, uint64(count), nil
+}
+
+func (r *AuditLogRepository) GetAuditLog(ctx context.Context, id int64) (*domain.AuditLog, error) {
+	entry := &domain.AuditLog{}
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(entry).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrAuditLogNotFound
+		}
+		return nil, err
+	}
+	return entry, nil
+}
+
+// GetAuditLogsAfter entries with ids greater than afterID in order of the chain
+func (r *AuditLogRepository) GetAuditLogsAfter(ctx context.Context, afterID int64, limit int) ([]*domain.AuditLog, error) {
+	var entries []*domain.AuditLog
+	if err := r.db.WithContext(ctx).
+		Where("id > ?", afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// GetSnapshot load the row of the model with the id into it, false if there is no such row
+func (r *AuditLogRepository) GetSnapshot(ctx context.Context, model any, id string) (bool, error) {
+	err := r.db.WithContext(ctx).Where("id = ?", id).Take(model).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
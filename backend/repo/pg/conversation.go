@@ -2,7 +2,11 @@ package pg
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
+	"time"
 
+	"github.com/cloudwego/eino/schema"
 	"gorm.io/gorm"
 
 	"github.com/chaitin/panda-wiki/domain"
@@ -29,10 +33,54 @@ func (r *ConversationRepository) CreateConversationMessage(ctx context.Context,
 	})
 }
 
+// CheckpointConversationMessage save partial content of a streaming message
+func (r *ConversationRepository) CheckpointConversationMessage(ctx context.Context, id, content string) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.ConversationMessage{}).
+		Where("id = ?", id).
+		Where("status = ?", domain.MessageStatusStreaming).
+		Updates(map[string]any{
+			"content":    content,
+			"updated_at": time.Now(),
+		}).Error
+}
+
+// UpdateMessageFollowUps save follow-up questions suggested after the answer
+func (r *ConversationRepository) UpdateMessageFollowUps(ctx context.Context, id string, followUps domain.FollowUpQuestions) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.ConversationMessage{}).
+		Where("id = ?", id).
+		Update("follow_ups", followUps).Error
+}
+
+// FinishConversationMessage save final content, usage and status of a streaming message
+func (r *ConversationRepository) FinishConversationMessage(ctx context.Context, conversationMessage *domain.ConversationMessage, references []*domain.ConversationReference) error {
+	conversationMessage.UpdatedAt = time.Now()
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.ConversationMessage{}).
+			Where("id = ?", conversationMessage.ID).
+			Select("content", "provider", "model", "model_id", "failovers", "prompt_tokens", "completion_tokens", "total_tokens", "confidence", "low_confidence", "route", "provenance", "rerank_scores", "status", "updated_at").
+			Updates(conversationMessage).Error; err != nil {
+			return err
+		}
+		if len(references) > 0 {
+			return tx.Create(references).Error
+		}
+		return nil
+	})
+}
+
 func (r *ConversationRepository) CreateConversation(ctx context.Context, conversation *domain.Conversation) error {
 	return r.db.WithContext(ctx).Create(conversation).Error
 }
 
+func (r *ConversationRepository) UpdateConversationTitle(ctx context.Context, conversationID, title string) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.Conversation{}).
+		Where("id = ?", conversationID).
+		Update("title", title).Error
+}
+
 func (r *ConversationRepository) GetConversationList(ctx context.Context, request *domain.ConversationListReq) ([]*domain.ConversationListItem, uint64, error) {
 	conversations := []*domain.ConversationListItem{}
 	query := r.db.WithContext(ctx).
@@ -43,11 +91,14 @@ func (r *ConversationRepository) GetConversationList(ctx context.Context, reques
 		query = query.Where("conversations.app_id = ?", *request.AppID)
 	}
 	if request.Subject != nil && *request.Subject != "" {
-		query = query.Where("conversations.subject like ?", "%"+*request.Subject+"%")
+		query = query.Where("conversations.title like ? OR conversations.subject like ?", "%"+*request.Subject+"%", "%"+*request.Subject+"%")
 	}
 	if request.RemoteIP != nil && *request.RemoteIP != "" {
 		query = query.Where("conversations.remote_ip like ?", "%"+*request.RemoteIP+"%")
 	}
+	if request.Historical != nil {
+		query = query.Where("conversations.historical = ?", *request.Historical)
+	}
 	var count int64
 	if err := query.Count(&count).Error; err != nil {
 		return nil, 0, err
@@ -64,6 +115,70 @@ func (r *ConversationRepository) GetConversationList(ctx context.Context, reques
 	return conversations, uint64(count), nil
 }
 
+// TraverseConversations pass conversations matching the request to the callback in batches, oldest first.
+// rows are read by a server side cursor in a read only transaction, so memory is bounded by the batch size
+func (r *ConversationRepository) TraverseConversations(ctx context.Context, request *domain.ConversationStreamReq, batchSize int, callback func([]*domain.Conversation) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(&domain.Conversation{}).
+			Select("*").
+			Where("kb_id = ?", request.KBID)
+		if request.AppID != "" {
+			query = query.Where("app_id = ?", request.AppID)
+		}
+		if request.RemoteIP != "" {
+			query = query.Where("remote_ip = ?", request.RemoteIP)
+		}
+		if request.Historical != nil {
+			query = query.Where("historical = ?", *request.Historical)
+		}
+		if request.StartTime > 0 {
+			query = query.Where("created_at >= ?", time.Unix(request.StartTime, 0))
+		}
+		if request.EndTime > 0 {
+			query = query.Where("created_at < ?", time.Unix(request.EndTime, 0))
+		}
+		if err := tx.Exec("DECLARE conversation_stream NO SCROLL CURSOR FOR ?", query.Order("created_at ASC, id ASC")).Error; err != nil {
+			return err
+		}
+		for {
+			conversations := []*domain.Conversation{}
+			if err := tx.Raw(fmt.Sprintf("FETCH %d FROM conversation_stream", batchSize)).Scan(&conversations).Error; err != nil {
+				return err
+			}
+			if len(conversations) == 0 {
+				return nil
+			}
+			if err := callback(conversations); err != nil {
+				return err
+			}
+		}
+	}, &sql.TxOptions{ReadOnly: true})
+}
+
+// GetMessagesByConversationIDs messages of the conversations in order of creation
+func (r *ConversationRepository) GetMessagesByConversationIDs(ctx context.Context, conversationIDs []string) ([]*domain.ConversationMessage, error) {
+	messages := []*domain.ConversationMessage{}
+	if err := r.db.WithContext(ctx).
+		Model(&domain.ConversationMessage{}).
+		Where("conversation_id IN ?", conversationIDs).
+		Order("created_at ASC").
+		Find(&messages).Error; err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+func (r *ConversationRepository) GetReferencesByConversationIDs(ctx context.Context, conversationIDs []string) ([]*domain.ConversationReference, error) {
+	references := []*domain.ConversationReference{}
+	if err := r.db.WithContext(ctx).
+		Model(&domain.ConversationReference{}).
+		Where("conversation_id IN ?", conversationIDs).
+		Find(&references).Error; err != nil {
+		return nil, err
+	}
+	return references, nil
+}
+
 func (r *ConversationRepository) GetConversationDetail(ctx context.Context, conversationID string) (*domain.ConversationDetailResp, error) {
 	conversation := &domain.ConversationDetailResp{}
 	if err := r.db.WithContext(ctx).
@@ -98,6 +213,50 @@ func (r *ConversationRepository) GetConversationMessagesByID(ctx context.Context
 	return messages, nil
 }
 
+// GetKBConversationMessage message of a conversation of the kb
+func (r *ConversationRepository) GetKBConversationMessage(ctx context.Context, kbID, id string) (*domain.ConversationMessage, error) {
+	message := &domain.ConversationMessage{}
+	if err := r.db.WithContext(ctx).
+		Model(&domain.ConversationMessage{}).
+		Where("id = ?", id).
+		Where("conversation_id IN (SELECT id FROM conversations WHERE kb_id = ?)", kbID).
+		First(message).Error; err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+// GetConversationMessagesSince the message and messages created after it, with earlier messages still streaming,
+// so answers fetched while streaming are sent again once completed. All messages if the message is not found
+func (r *ConversationRepository) GetConversationMessagesSince(ctx context.Context, conversationID, sinceID string) ([]*domain.ConversationMessage, error) {
+	messages := []*domain.ConversationMessage{}
+	if err := r.db.WithContext(ctx).
+		Model(&domain.ConversationMessage{}).
+		Where("conversation_id = ?", conversationID).
+		Where("(created_at >= COALESCE((SELECT created_at FROM conversation_messages WHERE id = ? AND conversation_id = ?), '-infinity') OR status = ?)",
+			sinceID, conversationID, domain.MessageStatusStreaming).
+		Order("created_at asc").
+		Find(&messages).Error; err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// GetConversationVersion changes whenever messages or references are added to the conversation
+func (r *ConversationRepository) GetConversationVersion(ctx context.Context, conversationID string) (*domain.ConversationVersion, error) {
+	version := &domain.ConversationVersion{}
+	if err := r.db.WithContext(ctx).
+		Raw(`SELECT
+			(SELECT COUNT(*) FROM conversation_messages WHERE conversation_id = @id) AS message_count,
+			(SELECT MAX(COALESCE(updated_at, created_at)) FROM conversation_messages WHERE conversation_id = @id) AS last_message_at,
+			(SELECT COUNT(*) FROM conversation_references WHERE conversation_id = @id) AS reference_count`,
+			sql.Named("id", conversationID)).
+		Scan(version).Error; err != nil {
+		return nil, err
+	}
+	return version, nil
+}
+
 func (r *ConversationRepository) ValidateConversationNonce(ctx context.Context, conversationID, nonce string) error {
 	conversation := &domain.Conversation{}
 	if err := r.db.WithContext(ctx).
@@ -116,6 +275,7 @@ func (r *ConversationRepository) GetConversationDistribution(ctx context.Context
 		Model(&domain.Conversation{}).
 		Select("app_id", "COUNT(*) AS count").
 		Where("kb_id = ?", kbID).
+		Scopes(excludeBot("conversations", kbID)).
 		Where("created_at > now() - interval '24h'").
 		Group("app_id").
 		Find(&distribution).Error; err != nil {
@@ -129,9 +289,189 @@ func (r *ConversationRepository) GetConversationCount(ctx context.Context, kbID
 	if err := r.db.WithContext(ctx).
 		Model(&domain.Conversation{}).
 		Where("kb_id = ?", kbID).
+		Scopes(excludeBot("conversations", kbID)).
 		Where("created_at > now() - interval '24h'").
 		Count(&count).Error; err != nil {
 		return 0, err
 	}
 	return count, nil
 }
+
+// GetActiveConversationCount count conversations which have new messages since the given time
+func (r *ConversationRepository) GetActiveConversationCount(ctx context.Context, kbID string, since time.Time) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).
+		Model(&domain.ConversationMessage{}).
+		Joins("JOIN conversations ON conversations.id = conversation_messages.conversation_id").
+		Where("conversations.kb_id = ?", kbID).
+		Scopes(excludeBot("conversations", kbID)).
+		Where("conversation_messages.created_at >= ?", since).
+		Distinct("conversation_messages.conversation_id").
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetUnansweredQuestions get user questions which the assistant replied with no answer, most asked first
+func (r *ConversationRepository) GetUnansweredQuestions(ctx context.Context, kbID string, start, end time.Time, limit int) ([]*domain.UnansweredQuestion, error) {
+	var questions []*domain.UnansweredQuestion
+	if err := r.db.WithContext(ctx).Raw(`
+		SELECT q.content AS question, COUNT(*) AS count
+		FROM conversation_messages a
+		JOIN conversations c ON c.id = a.conversation_id
+		JOIN LATERAL (
+			SELECT u.content FROM conversation_messages u
+			WHERE u.conversation_id = a.conversation_id AND u.role = ? AND u.created_at <= a.created_at
+			ORDER BY u.created_at DESC LIMIT 1
+		) q ON true
+		WHERE c.kb_id = ? AND a.role = ? AND a.created_at >= ? AND a.created_at < ? AND a.content LIKE ?
+		GROUP BY q.content
+		ORDER BY count DESC
+		LIMIT ?`,
+		schema.User, kbID, schema.Assistant, start, end, "%"+domain.NoAnswerReply+"%", limit,
+	).Scan(&questions).Error; err != nil {
+		return nil, err
+	}
+	return questions, nil
+}
+
+// GetConversationVolume conversations, messages and distinct visitors of the kb in the period
+func (r *ConversationRepository) GetConversationVolume(ctx context.Context, kbID string, start, end time.Time) (*domain.DigestVolume, error) {
+	volume := &domain.DigestVolume{}
+	if err := r.db.WithContext(ctx).Raw(`
+		SELECT
+			(SELECT COUNT(*) FROM conversations WHERE kb_id = @kb_id AND created_at >= @start AND created_at < @end) AS conversation_count,
+			(SELECT COUNT(*) FROM conversation_messages m JOIN conversations c ON c.id = m.conversation_id
+				WHERE c.kb_id = @kb_id AND m.created_at >= @start AND m.created_at < @end) AS message_count,
+			(SELECT COUNT(DISTINCT remote_ip) FROM conversations WHERE kb_id = @kb_id AND created_at >= @start AND created_at < @end) AS visitor_count`,
+		sql.Named("kb_id", kbID), sql.Named("start", start), sql.Named("end", end),
+	).Scan(volume).Error; err != nil {
+		return nil, err
+	}
+	return volume, nil
+}
+
+// GetEscalatedConversations conversations with unanswered or low confidence replies in the period, most unanswered first
+func (r *ConversationRepository) GetEscalatedConversations(ctx context.Context, kbID string, start, end time.Time, limit int) ([]*domain.DigestEscalation, error) {
+	var escalations []*domain.DigestEscalation
+	if err := r.db.WithContext(ctx).Raw(`
+		SELECT c.id AS conversation_id, c.subject, c.created_at,
+			COUNT(*) FILTER (WHERE m.content LIKE ?) AS no_answer_count,
+			COUNT(*) FILTER (WHERE m.low_confidence) AS low_confidence
+		FROM conversation_messages m
+		JOIN conversations c ON c.id = m.conversation_id
+		WHERE c.kb_id = ? AND m.role = ? AND m.created_at >= ? AND m.created_at < ?
+			AND (m.content LIKE ? OR m.low_confidence)
+		GROUP BY c.id, c.subject, c.created_at
+		ORDER BY no_answer_count DESC, low_confidence DESC, c.created_at ASC
+		LIMIT ?`,
+		"%"+domain.NoAnswerReply+"%", kbID, schema.Assistant, start, end, "%"+domain.NoAnswerReply+"%", limit,
+	).Scan(&escalations).Error; err != nil {
+		return nil, err
+	}
+	return escalations, nil
+}
+
+// GetNewQuestions user questions of the period not asked since lookback, most asked first
+func (r *ConversationRepository) GetNewQuestions(ctx context.Context, kbID string, lookback, start, end time.Time, limit int) ([]*domain.DigestQuestion, error) {
+	var questions []*domain.DigestQuestion
+	if err := r.db.WithContext(ctx).Raw(`
+		SELECT MIN(m.content) AS question, COUNT(*) AS count
+		FROM conversation_messages m
+		JOIN conversations c ON c.id = m.conversation_id
+		WHERE c.kb_id = @kb_id AND m.role = @role AND m.created_at >= @start AND m.created_at < @end
+			AND NOT EXISTS (
+				SELECT 1 FROM conversation_messages p
+				JOIN conversations pc ON pc.id = p.conversation_id
+				WHERE pc.kb_id = @kb_id AND p.role = @role AND p.created_at >= @lookback AND p.created_at < @start
+					AND lower(trim(p.content)) = lower(trim(m.content))
+			)
+		GROUP BY lower(trim(m.content))
+		ORDER BY count DESC, question ASC
+		LIMIT @limit`,
+		sql.Named("kb_id", kbID), sql.Named("role", schema.User), sql.Named("lookback", lookback),
+		sql.Named("start", start), sql.Named("end", end), sql.Named("limit", limit),
+	).Scan(&questions).Error; err != nil {
+		return nil, err
+	}
+	return questions, nil
+}
+
+// GetBusiestConversationIDs conversations with most messages in the period
+func (r *ConversationRepository) GetBusiestConversationIDs(ctx context.Context, kbID string, start, end time.Time, limit int) ([]string, error) {
+	var ids []string
+	if err := r.db.WithContext(ctx).Raw(`
+		SELECT c.id
+		FROM conversations c
+		JOIN conversation_messages m ON m.conversation_id = c.id
+		WHERE c.kb_id = ? AND c.created_at >= ? AND c.created_at < ? AND NOT c.is_bot
+		GROUP BY c.id
+		ORDER BY COUNT(*) DESC, c.id
+		LIMIT ?`,
+		kbID, start, end, limit,
+	).Scan(&ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// GetActiveKBIDs kbs with most conversations since the time
+func (r *ConversationRepository) GetActiveKBIDs(ctx context.Context, since time.Time, limit int) ([]string, error) {
+	var ids []string
+	if err := r.db.WithContext(ctx).Raw(`
+		SELECT kb_id
+		FROM conversations
+		WHERE created_at >= ?
+		GROUP BY kb_id
+		ORDER BY COUNT(*) DESC, kb_id
+		LIMIT ?`,
+		since, limit,
+	).Scan(&ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// CreateHistoricalConversations insert imported conversations with their messages in one transaction
+func (r *ConversationRepository) CreateHistoricalConversations(ctx context.Context, conversations []*domain.Conversation, messages []*domain.ConversationMessage) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.CreateInBatches(conversations, 100).Error; err != nil {
+			return err
+		}
+		if len(messages) > 0 {
+			return tx.CreateInBatches(messages, 500).Error
+		}
+		return nil
+	})
+}
+
+// GetHistoricalExternalIDs ticket ids of the source already imported into the kb
+func (r *ConversationRepository) GetHistoricalExternalIDs(ctx context.Context, kbID string, source domain.HistoricalSource) ([]string, error) {
+	var ids []string
+	if err := r.db.WithContext(ctx).
+		Model(&domain.Conversation{}).
+		Where("kb_id = ? AND historical", kbID).
+		Where("info->'historical'->>'source' = ?", source).
+		Pluck("info->'historical'->>'external_id'", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// GetHistoricalUnansweredQuestions subjects of unresolved historical conversations
+func (r *ConversationRepository) GetHistoricalUnansweredQuestions(ctx context.Context, kbID string, limit int) ([]*domain.UnansweredQuestion, error) {
+	var questions []*domain.UnansweredQuestion
+	if err := r.db.WithContext(ctx).Raw(`
+		SELECT subject AS question, COUNT(*) AS count
+		FROM conversations
+		WHERE kb_id = ? AND historical AND subject <> '' AND (info->'historical'->>'resolved')::boolean IS NOT TRUE
+		GROUP BY subject
+		ORDER BY count DESC
+		LIMIT ?`,
+		kbID, limit,
+	).Scan(&questions).Error; err != nil {
+		return nil, err
+	}
+	return questions, nil
+}
@@ -0,0 +1,92 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/store/pg"
+)
+
+type ConversationRescoreRepository struct {
+	db *pg.DB
+}
+
+func NewConversationRescoreRepository(db *pg.DB) *ConversationRescoreRepository {
+	return &ConversationRescoreRepository{db: db}
+}
+
+func (r *ConversationRescoreRepository) CreateRescoreJob(ctx context.Context, job *domain.ConversationRescoreJob) error {
+	return r.db.WithContext(ctx).Create(job).Error
+}
+
+func (r *ConversationRescoreRepository) GetRescoreJob(ctx context.Context, kbID, id string) (*domain.ConversationRescoreJob, error) {
+	job := &domain.ConversationRescoreJob{}
+	query := r.db.WithContext(ctx).Model(&domain.ConversationRescoreJob{}).Where("id = ?", id)
+	if kbID != "" {
+		query = query.Where("kb_id = ?", kbID)
+	}
+	if err := query.First(job).Error; err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func (r *ConversationRescoreRepository) UpdateRescoreJob(ctx context.Context, id string, updates map[string]any) error {
+	updates["updated_at"] = time.Now()
+	return r.db.WithContext(ctx).
+		Model(&domain.ConversationRescoreJob{}).
+		Where("id = ?", id).
+		Updates(updates).Error
+}
+
+func (r *ConversationRescoreRepository) GetRescoreJobList(ctx context.Context, req *domain.ConversationRescoreJobListReq) ([]*domain.ConversationRescoreJob, uint64, error) {
+	query := r.db.WithContext(ctx).
+		Model(&domain.ConversationRescoreJob{}).
+		Where("kb_id = ?", req.KBID)
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	jobs := []*domain.ConversationRescoreJob{}
+	if err := query.
+		Offset(req.Offset()).
+		Limit(req.Limit()).
+		Order("created_at DESC").
+		Find(&jobs).Error; err != nil {
+		return nil, 0, err
+	}
+	return jobs, uint64(count), nil
+}
+
+// GetRescoreAnswers completed rag answers of the kb since the time, newest first, with the question each answered
+// and the documents it referenced
+func (r *ConversationRescoreRepository) GetRescoreAnswers(ctx context.Context, kbID string, since time.Time, limit int) ([]*domain.RescoreAnswer, error) {
+	var answers []*domain.RescoreAnswer
+	if err := r.db.WithContext(ctx).Raw(`
+		SELECT a.id AS message_id, a.conversation_id, q.content AS question, a.confidence, a.low_confidence, a.feedback_type,
+			COALESCE((SELECT jsonb_agg(DISTINCT ref.node_id) FROM conversation_references ref
+				WHERE ref.message_id = a.id AND ref.node_id <> ''), '[]'::jsonb) AS node_ids,
+			a.created_at
+		FROM conversation_messages a
+		JOIN conversations c ON c.id = a.conversation_id
+		JOIN LATERAL (
+			SELECT u.content FROM conversation_messages u
+			WHERE u.conversation_id = a.conversation_id AND u.role = @user AND u.created_at <= a.created_at
+			ORDER BY u.created_at DESC LIMIT 1
+		) q ON true
+		WHERE c.kb_id = @kb_id AND NOT c.historical AND a.role = @assistant AND a.created_at >= @since
+			AND a.route IN ('', @route) AND a.status = @status
+		ORDER BY a.created_at DESC
+		LIMIT @limit`,
+		sql.Named("kb_id", kbID), sql.Named("user", schema.User), sql.Named("assistant", schema.Assistant),
+		sql.Named("since", since), sql.Named("route", domain.QuestionRouteRAG),
+		sql.Named("status", domain.MessageStatusCompleted), sql.Named("limit", limit),
+	).Scan(&answers).Error; err != nil {
+		return nil, err
+	}
+	return answers, nil
+}
@@ -0,0 +1,69 @@
+package pg
+
+import (
+	"context"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/store/pg"
+)
+
+type CronRepository struct {
+	db *pg.DB
+}
+
+func NewCronRepository(db *pg.DB) *CronRepository {
+	return &CronRepository{db: db}
+}
+
+func (r *CronRepository) CreateCronRun(ctx context.Context, run *domain.CronRun) error {
+	return r.db.WithContext(ctx).Create(run).Error
+}
+
+func (r *CronRepository) GetCronRunList(ctx context.Context, req *domain.CronRunListReq) ([]*domain.CronRun, uint64, error) {
+	query := r.db.WithContext(ctx).Model(&domain.CronRun{})
+	if req.Job != "" {
+		query = query.Where("job = ?", req.Job)
+	}
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	runs := []*domain.CronRun{}
+	if err := query.
+		Offset(req.Offset()).
+		Limit(req.Limit()).
+		Order("started_at DESC").
+		Find(&runs).Error; err != nil {
+		return nil, 0, err
+	}
+	return runs, uint64(count), nil
+}
+
+// GetRecentCronRuns latest runs of the job, newest first
+func (r *CronRepository) GetRecentCronRuns(ctx context.Context, job string, limit int) ([]*domain.CronRun, error) {
+	runs := []*domain.CronRun{}
+	if err := r.db.WithContext(ctx).
+		Model(&domain.CronRun{}).
+		Where("job = ?", job).
+		Order("started_at DESC").
+		Limit(limit).
+		Find(&runs).Error; err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+func (r *CronRepository) GetCronJobs(ctx context.Context) ([]string, error) {
+	var jobs []string
+	if err := r.db.WithContext(ctx).
+		Model(&domain.CronRun{}).
+		Distinct("job").
+		Order("job").
+		Pluck("job", &jobs).Error; err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
@@ -0,0 +1,218 @@
+package pg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/store/pg"
+)
+
+// conversations of the kb started by the end user, matched by any of the identifiers given
+const endUserConversations = `SELECT id FROM conversations WHERE kb_id = @kb_id AND (
+	(@user_id <> '' AND info->'user_info'->>'user_id' = @user_id) OR
+	(@email <> '' AND info->'user_info'->>'email' = @email) OR
+	(@remote_ip <> '' AND remote_ip = @remote_ip))`
+
+// web sessions of the end user, linked to funnel events
+const endUserSessions = `SELECT session_id FROM stat_pages WHERE kb_id = @kb_id AND session_id <> '' AND (
+	(@user_id <> '' AND user_id = @user_id) OR
+	(@remote_ip <> '' AND ip = @remote_ip))`
+
+type dataExportTable struct {
+	name string
+	// condition of the rows of the kb
+	kb string
+	// condition of the rows referencing the end user, tables without it are left out of end user exports
+	endUser string
+	// columns or paths in jsonb columns left out of the export, like credentials, e.g. digest_settings.webhook.secret
+	omit []string
+}
+
+// dataExportTables tables exported in archive order
+var dataExportTables = []dataExportTable{
+	{
+		name: "knowledge_bases",
+		kb:   "id = @kb_id",
+		// urls of dingtalk and feishu webhooks carry their access token
+		omit: []string{
+			"access_settings",
+			"digest_settings.webhook.url",
+			"digest_settings.webhook.secret",
+			"review_reminder_settings.webhook.url",
+			"review_reminder_settings.webhook.secret",
+		},
+	},
+	{name: "apps", kb: "kb_id = @kb_id", omit: []string{"settings"}},
+	{name: "nodes", kb: "kb_id = @kb_id"},
+	{name: "node_releases", kb: "kb_id = @kb_id"},
+	{name: "kb_releases", kb: "kb_id = @kb_id"},
+	{name: "kb_release_node_releases", kb: "kb_id = @kb_id"},
+	{name: "node_versions", kb: "kb_id = @kb_id"},
+	{name: "node_attachments", kb: "kb_id = @kb_id"},
+	{name: "node_reviews", kb: "kb_id = @kb_id"},
+	{name: "node_owners", kb: "kb_id = @kb_id"},
+	{name: "node_acls", kb: "kb_id = @kb_id"},
+	{name: "readers", kb: "kb_id = @kb_id", omit: []string{"password"}},
+	{name: "node_templates", kb: "kb_id = @kb_id"},
+	{name: "glossary_terms", kb: "kb_id = @kb_id"},
+	{
+		name:    "node_comments",
+		kb:      "kb_id = @kb_id",
+		endUser: "kb_id = @kb_id AND @remote_ip <> '' AND remote_ip = @remote_ip",
+	},
+	{
+		name:    "conversations",
+		kb:      "kb_id = @kb_id",
+		endUser: "id IN (" + endUserConversations + ")",
+	},
+	{
+		name:    "conversation_messages",
+		kb:      "conversation_id IN (SELECT id FROM conversations WHERE kb_id = @kb_id)",
+		endUser: "conversation_id IN (" + endUserConversations + ")",
+	},
+	{
+		name:    "conversation_references",
+		kb:      "conversation_id IN (SELECT id FROM conversations WHERE kb_id = @kb_id)",
+		endUser: "conversation_id IN (" + endUserConversations + ")",
+	},
+	{
+		name: "conversation_transcript_emails",
+		kb:   "kb_id = @kb_id",
+		endUser: "kb_id = @kb_id AND (conversation_id IN (" + endUserConversations + ") OR " +
+			"(@email <> '' AND email = @email) OR (@remote_ip <> '' AND remote_ip = @remote_ip))",
+	},
+	{
+		name:    "conversation_anomalies",
+		kb:      "kb_id = @kb_id",
+		endUser: "kb_id = @kb_id AND @remote_ip <> '' AND remote_ip = @remote_ip",
+	},
+	{
+		name:    "stat_questions",
+		kb:      "kb_id = @kb_id",
+		endUser: "conversation_id IN (" + endUserConversations + ")",
+	},
+	{
+		name:    "stat_pages",
+		kb:      "kb_id = @kb_id",
+		endUser: "kb_id = @kb_id AND ((@user_id <> '' AND user_id = @user_id) OR (@remote_ip <> '' AND ip = @remote_ip))",
+	},
+	{
+		name:    "stat_funnel_events",
+		kb:      "kb_id = @kb_id",
+		endUser: "kb_id = @kb_id AND session_id IN (" + endUserSessions + ")",
+	},
+	{name: "stat_node_daily", kb: "kb_id = @kb_id"},
+}
+
+type DataExportRepository struct {
+	db *pg.DB
+}
+
+func NewDataExportRepository(db *pg.DB) *DataExportRepository {
+	return &DataExportRepository{db: db}
+}
+
+func (r *DataExportRepository) CreateExportJob(ctx context.Context, job *domain.DataExportJob) error {
+	return r.db.WithContext(ctx).Create(job).Error
+}
+
+func (r *DataExportRepository) GetExportJob(ctx context.Context, kbID, id string) (*domain.DataExportJob, error) {
+	job := &domain.DataExportJob{}
+	query := r.db.WithContext(ctx).Model(&domain.DataExportJob{}).Where("id = ?", id)
+	if kbID != "" {
+		query = query.Where("kb_id = ?", kbID)
+	}
+	if err := query.First(job).Error; err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func (r *DataExportRepository) UpdateExportJob(ctx context.Context, id string, updates map[string]any) error {
+	updates["updated_at"] = time.Now()
+	return r.db.WithContext(ctx).
+		Model(&domain.DataExportJob{}).
+		Where("id = ?", id).
+		Updates(updates).Error
+}
+
+func (r *DataExportRepository) GetExportJobList(ctx context.Context, req *domain.DataExportJobListReq) ([]*domain.DataExportJob, uint64, error) {
+	query := r.db.WithContext(ctx).
+		Model(&domain.DataExportJob{}).
+		Where("kb_id = ?", req.KBID)
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	jobs := []*domain.DataExportJob{}
+	if err := query.
+		Offset(req.Offset()).
+		Limit(req.Limit()).
+		Order("created_at DESC").
+		Find(&jobs).Error; err != nil {
+		return nil, 0, err
+	}
+	return jobs, uint64(count), nil
+}
+
+// GetExportTables tables exported for the scope, in archive order
+func (r *DataExportRepository) GetExportTables(scope domain.DataExportScope) []string {
+	tables := make([]string, 0, len(dataExportTables))
+	for _, table := range dataExportTables {
+		if scope == domain.DataExportScopeEndUser && table.endUser == "" {
+			continue
+		}
+		tables = append(tables, table.name)
+	}
+	return tables
+}
+
+// TraverseExportRows pass rows of the table in scope of the job as json objects to the callback by pg cursor
+func (r *DataExportRepository) TraverseExportRows(ctx context.Context, job *domain.DataExportJob, name string, callback func(json.RawMessage) error) (int, error) {
+	var table *dataExportTable
+	for i := range dataExportTables {
+		if dataExportTables[i].name == name {
+			table = &dataExportTables[i]
+		}
+	}
+	if table == nil {
+		return 0, fmt.Errorf("table %s is not exported", name)
+	}
+	condition := table.kb
+	if job.Scope == domain.DataExportScopeEndUser {
+		condition = table.endUser
+	}
+	row := "to_jsonb(t)"
+	for _, path := range table.omit {
+		row += fmt.Sprintf(" #- '{%s}'", strings.ReplaceAll(path, ".", ","))
+	}
+	rows, err := r.db.WithContext(ctx).
+		Raw(fmt.Sprintf(`SELECT %s FROM "%s" t WHERE %s`, row, table.name, condition), map[string]any{
+			"kb_id":     job.KBID,
+			"user_id":   job.UserID,
+			"email":     job.Email,
+			"remote_ip": job.RemoteIP,
+		}).
+		Rows()
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return count, err
+		}
+		if err := callback(data); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, rows.Err()
+}
@@ -0,0 +1,223 @@
+package pg
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/store/pg"
+)
+
+type EvalRepository struct {
+	db *pg.DB
+}
+
+func NewEvalRepository(db *pg.DB) *EvalRepository {
+	return &EvalRepository{db: db}
+}
+
+func (r *EvalRepository) CreateEvalSet(ctx context.Context, set *domain.EvalSet) error {
+	return r.db.WithContext(ctx).Create(set).Error
+}
+
+func (r *EvalRepository) UpdateEvalSet(ctx context.Context, set *domain.EvalSet) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.EvalSet{}).
+		Where("id = ?", set.ID).
+		Where("kb_id = ?", set.KBID).
+		Select("name", "description", "updated_at").
+		Updates(set).Error
+}
+
+func (r *EvalRepository) GetEvalSet(ctx context.Context, kbID, id string) (*domain.EvalSet, error) {
+	set := &domain.EvalSet{}
+	if err := r.db.WithContext(ctx).
+		Where("id = ?", id).
+		Where("kb_id = ?", kbID).
+		First(set).Error; err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// DeleteEvalSet delete the set with its questions, runs and their results
+func (r *EvalRepository) DeleteEvalSet(ctx context.Context, kbID, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		runIDs := tx.Model(&domain.EvalRun{}).Select("id").Where("set_id = ?", id).Where("kb_id = ?", kbID)
+		if err := tx.Where("run_id IN (?)", runIDs).Delete(&domain.EvalResult{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("set_id = ?", id).Where("kb_id = ?", kbID).Delete(&domain.EvalRun{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("set_id = ?", id).Where("kb_id = ?", kbID).Delete(&domain.EvalQuestion{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Where("kb_id = ?", kbID).Delete(&domain.EvalSet{}).Error
+	})
+}
+
+// GetEvalSetList sets of the kb with their question count, newest first
+func (r *EvalRepository) GetEvalSetList(ctx context.Context, kbID string) ([]*domain.EvalSetListItem, error) {
+	sets := []*domain.EvalSetListItem{}
+	if err := r.db.WithContext(ctx).
+		Model(&domain.EvalSet{}).
+		Select("eval_sets.*, (SELECT COUNT(*) FROM eval_questions q WHERE q.set_id = eval_sets.id) AS question_count").
+		Where("kb_id = ?", kbID).
+		Order("created_at DESC").
+		Find(&sets).Error; err != nil {
+		return nil, err
+	}
+	return sets, nil
+}
+
+// GetLatestEvalRuns latest succeeded run of each set of the kb
+func (r *EvalRepository) GetLatestEvalRuns(ctx context.Context, kbID string) ([]*domain.EvalRun, error) {
+	runs := []*domain.EvalRun{}
+	if err := r.db.WithContext(ctx).Raw(`
+		SELECT DISTINCT ON (set_id) * FROM eval_runs
+		WHERE kb_id = ? AND status = ?
+		ORDER BY set_id, created_at DESC`,
+		kbID, domain.NodeExportJobStatusSucceeded,
+	).Scan(&runs).Error; err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+func (r *EvalRepository) CountEvalQuestions(ctx context.Context, setID string) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).
+		Model(&domain.EvalQuestion{}).
+		Where("set_id = ?", setID).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetEvalQuestions questions of the set in the order they were added
+func (r *EvalRepository) GetEvalQuestions(ctx context.Context, setID string) ([]*domain.EvalQuestion, error) {
+	questions := []*domain.EvalQuestion{}
+	if err := r.db.WithContext(ctx).
+		Where("set_id = ?", setID).
+		Order("created_at ASC, id ASC").
+		Find(&questions).Error; err != nil {
+		return nil, err
+	}
+	return questions, nil
+}
+
+func (r *EvalRepository) CreateEvalQuestions(ctx context.Context, questions []*domain.EvalQuestion) error {
+	return r.db.WithContext(ctx).Create(&questions).Error
+}
+
+func (r *EvalRepository) UpdateEvalQuestion(ctx context.Context, question *domain.EvalQuestion) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.EvalQuestion{}).
+		Where("id = ?", question.ID).
+		Where("kb_id = ?", question.KBID).
+		Select("question", "expected_answer", "updated_at").
+		Updates(question).Error
+}
+
+func (r *EvalRepository) DeleteEvalQuestion(ctx context.Context, kbID, id string) error {
+	return r.db.WithContext(ctx).
+		Where("id = ?", id).
+		Where("kb_id = ?", kbID).
+		Delete(&domain.EvalQuestion{}).Error
+}
+
+func (r *EvalRepository) CreateEvalRun(ctx context.Context, run *domain.EvalRun) error {
+	return r.db.WithContext(ctx).Create(run).Error
+}
+
+func (r *EvalRepository) GetEvalRun(ctx context.Context, kbID, id string) (*domain.EvalRun, error) {
+	run := &domain.EvalRun{}
+	query := r.db.WithContext(ctx).Model(&domain.EvalRun{}).Where("id = ?", id)
+	if kbID != "" {
+		query = query.Where("kb_id = ?", kbID)
+	}
+	if err := query.First(run).Error; err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+func (r *EvalRepository) UpdateEvalRun(ctx context.Context, id string, updates map[string]any) error {
+	updates["updated_at"] = time.Now()
+	return r.db.WithContext(ctx).
+		Model(&domain.EvalRun{}).
+		Where("id = ?", id).
+		Updates(updates).Error
+}
+
+func (r *EvalRepository) GetEvalRunList(ctx context.Context, req *domain.EvalRunListReq) ([]*domain.EvalRun, uint64, error) {
+	query := r.db.WithContext(ctx).
+		Model(&domain.EvalRun{}).
+		Where("kb_id = ?", req.KBID)
+	if req.SetID != "" {
+		query = query.Where("set_id = ?", req.SetID)
+	}
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	runs := []*domain.EvalRun{}
+	if err := query.
+		Offset(req.Offset()).
+		Limit(req.Limit()).
+		Order("created_at DESC").
+		Find(&runs).Error; err != nil {
+		return nil, 0, err
+	}
+	return runs, uint64(count), nil
+}
+
+// GetEvalTrend most recent succeeded runs of the set, oldest first
+func (r *EvalRepository) GetEvalTrend(ctx context.Context, kbID, setID string, limit int) ([]*domain.EvalRun, error) {
+	runs := []*domain.EvalRun{}
+	if err := r.db.WithContext(ctx).
+		Model(&domain.EvalRun{}).
+		Where("kb_id = ?", kbID).
+		Where("set_id = ?", setID).
+		Where("status = ?", domain.NodeExportJobStatusSucceeded).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&runs).Error; err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(runs)-1; i < j; i, j = i+1, j-1 {
+		runs[i], runs[j] = runs[j], runs[i]
+	}
+	return runs, nil
+}
+
+func (r *EvalRepository) CreateEvalResult(ctx context.Context, result *domain.EvalResult) error {
+	return r.db.WithContext(ctx).Create(result).Error
+}
+
+// GetEvalResultList results of the run, worst first
+func (r *EvalRepository) GetEvalResultList(ctx context.Context, req *domain.EvalResultListReq) ([]*domain.EvalResult, uint64, error) {
+	query := r.db.WithContext(ctx).
+		Model(&domain.EvalResult{}).
+		Where("run_id = ?", req.RunID)
+	if req.FailedOnly {
+		query = query.Where("error <> '' OR NOT passed")
+	}
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	results := []*domain.EvalResult{}
+	if err := query.
+		Offset(req.Offset()).
+		Limit(req.Limit()).
+		Order("error <> '' DESC, faithfulness + relevance ASC, created_at ASC").
+		Find(&results).Error; err != nil {
+		return nil, 0, err
+	}
+	return results, uint64(count), nil
+}
@@ -0,0 +1,92 @@
+package pg
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/store/pg"
+)
+
+type ExternalLinkRepository struct {
+	db *pg.DB
+}
+
+func NewExternalLinkRepository(db *pg.DB) *ExternalLinkRepository {
+	return &ExternalLinkRepository{db: db}
+}
+
+// GetDocumentContents id and content of document nodes of the kb
+func (r *ExternalLinkRepository) GetDocumentContents(ctx context.Context, kbID string) ([]*domain.Node, error) {
+	var nodes []*domain.Node
+	if err := r.db.WithContext(ctx).
+		Model(&domain.Node{}).
+		Where("kb_id = ?", kbID).
+		Where("type = ?", domain.NodeTypeDocument).
+		Select("id, kb_id, content").
+		Find(&nodes).Error; err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
+
+// ReplaceKBExternalLinks replace outbound urls of all nodes of the kb
+func (r *ExternalLinkRepository) ReplaceKBExternalLinks(ctx context.Context, kbID string, links []*domain.NodeExternalLink) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("kb_id = ?", kbID).Delete(&domain.NodeExternalLink{}).Error; err != nil {
+			return err
+		}
+		if len(links) == 0 {
+			return nil
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&links, 500).Error
+	})
+}
+
+// GetUncheckedURLs urls of the kb never checked or checked before the time
+func (r *ExternalLinkRepository) GetUncheckedURLs(ctx context.Context, kbID string, checkedBefore time.Time) ([]string, error) {
+	var urls []string
+	if err := r.db.WithContext(ctx).
+		Model(&domain.NodeExternalLink{}).
+		Joins("LEFT JOIN external_link_checks ON external_link_checks.url = node_external_links.url").
+		Where("node_external_links.kb_id = ?", kbID).
+		Where("external_link_checks.url IS NULL OR external_link_checks.checked_at < ?", checkedBefore).
+		Distinct("node_external_links.url").
+		Pluck("node_external_links.url", &urls).Error; err != nil {
+		return nil, err
+	}
+	return urls, nil
+}
+
+func (r *ExternalLinkRepository) SaveExternalLinkCheck(ctx context.Context, check *domain.ExternalLinkCheck) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{UpdateAll: true}).
+		Create(check).Error
+}
+
+// GetBrokenExternalLinks broken outbound urls of the kb with nodes linking to them
+func (r *ExternalLinkRepository) GetBrokenExternalLinks(ctx context.Context, kbID string) ([]*domain.BrokenExternalLinkResp, error) {
+	links := []*domain.BrokenExternalLinkResp{}
+	if err := r.db.WithContext(ctx).
+		Model(&domain.NodeExternalLink{}).
+		Joins("JOIN external_link_checks ON external_link_checks.url = node_external_links.url").
+		Joins("JOIN nodes ON nodes.id = node_external_links.node_id").
+		Where("node_external_links.kb_id = ?", kbID).
+		Where("external_link_checks.broken").
+		Select("node_external_links.node_id, nodes.name as node_name, node_external_links.url, external_link_checks.status_code, external_link_checks.error, external_link_checks.checked_at").
+		Order("nodes.name ASC, node_external_links.url ASC").
+		Find(&links).Error; err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+// RemoveStaleExternalLinkChecks remove check results of urls not linked by any node
+func (r *ExternalLinkRepository) RemoveStaleExternalLinkChecks(ctx context.Context) error {
+	return r.db.WithContext(ctx).
+		Where("NOT EXISTS (SELECT 1 FROM node_external_links WHERE node_external_links.url = external_link_checks.url)").
+		Delete(&domain.ExternalLinkCheck{}).Error
+}
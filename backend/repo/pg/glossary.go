@@ -0,0 +1,62 @@
+package pg
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/store/pg"
+)
+
+type GlossaryRepository struct {
+	db *pg.DB
+}
+
+func NewGlossaryRepository(db *pg.DB) *GlossaryRepository {
+	return &GlossaryRepository{db: db}
+}
+
+func (r *GlossaryRepository) CreateGlossaryTerm(ctx context.Context, term *domain.GlossaryTerm) error {
+	if err := r.db.WithContext(ctx).Create(term).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return domain.ErrGlossaryTermExists
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *GlossaryRepository) UpdateGlossaryTerm(ctx context.Context, term *domain.GlossaryTerm) error {
+	if err := r.db.WithContext(ctx).
+		Model(&domain.GlossaryTerm{}).
+		Where("id = ?", term.ID).
+		Where("kb_id = ?", term.KBID).
+		Select("term", "aliases", "definition", "node_id", "updated_at").
+		Updates(term).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return domain.ErrGlossaryTermExists
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *GlossaryRepository) DeleteGlossaryTerm(ctx context.Context, kbID, id string) error {
+	return r.db.WithContext(ctx).
+		Where("id = ?", id).
+		Where("kb_id = ?", kbID).
+		Delete(&domain.GlossaryTerm{}).Error
+}
+
+func (r *GlossaryRepository) GetGlossaryTermList(ctx context.Context, kbID string) ([]*domain.GlossaryTerm, error) {
+	terms := []*domain.GlossaryTerm{}
+	if err := r.db.WithContext(ctx).
+		Where("kb_id = ?", kbID).
+		Order("term ASC").
+		Find(&terms).Error; err != nil {
+		return nil, err
+	}
+	return terms, nil
+}
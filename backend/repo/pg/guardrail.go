@@ -0,0 +1,48 @@
+package pg
+
+import (
+	"context"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/store/pg"
+)
+
+type GuardrailRepository struct {
+	db *pg.DB
+}
+
+func NewGuardrailRepository(db *pg.DB) *GuardrailRepository {
+	return &GuardrailRepository{db: db}
+}
+
+func (r *GuardrailRepository) CreateGuardrailEvents(ctx context.Context, events []*domain.GuardrailEvent) error {
+	return r.db.WithContext(ctx).Create(&events).Error
+}
+
+func (r *GuardrailRepository) GetGuardrailEventList(ctx context.Context, req *domain.GuardrailEventListReq) ([]*domain.GuardrailEvent, uint64, error) {
+	query := r.db.WithContext(ctx).
+		Model(&domain.GuardrailEvent{}).
+		Where("kb_id = ?", req.KBID)
+	if req.AppID != "" {
+		query = query.Where("app_id = ?", req.AppID)
+	}
+	if req.Check != "" {
+		query = query.Where("check_type = ?", req.Check)
+	}
+	if req.Action != "" {
+		query = query.Where("action = ?", req.Action)
+	}
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	events := []*domain.GuardrailEvent{}
+	if err := query.
+		Offset(req.Offset()).
+		Limit(req.Limit()).
+		Order("created_at DESC").
+		Find(&events).Error; err != nil {
+		return nil, 0, err
+	}
+	return events, uint64(count), nil
+}
@@ -0,0 +1,162 @@
+package pg
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/store/pg"
+)
+
+type ImportSourceRepository struct {
+	db *pg.DB
+}
+
+func NewImportSourceRepository(db *pg.DB) *ImportSourceRepository {
+	return &ImportSourceRepository{db: db}
+}
+
+func (r *ImportSourceRepository) CreateImportSource(ctx context.Context, source *domain.ImportSource) error {
+	return r.db.WithContext(ctx).Create(source).Error
+}
+
+func (r *ImportSourceRepository) GetImportSource(ctx context.Context, kbID, id string) (*domain.ImportSource, error) {
+	source := &domain.ImportSource{}
+	if err := r.db.WithContext(ctx).
+		Model(&domain.ImportSource{}).
+		Where("id = ? AND kb_id = ?", id, kbID).
+		First(source).Error; err != nil {
+		return nil, err
+	}
+	return source, nil
+}
+
+func (r *ImportSourceRepository) GetImportSourceList(ctx context.Context, kbID string) ([]*domain.ImportSource, error) {
+	sources := []*domain.ImportSource{}
+	if err := r.db.WithContext(ctx).
+		Model(&domain.ImportSource{}).
+		Where("kb_id = ?", kbID).
+		Order("created_at ASC").
+		Find(&sources).Error; err != nil {
+		return nil, err
+	}
+	return sources, nil
+}
+
+func (r *ImportSourceRepository) UpdateImportSource(ctx context.Context, id string, updates map[string]any) error {
+	updates["updated_at"] = time.Now()
+	return r.db.WithContext(ctx).
+		Model(&domain.ImportSource{}).
+		Where("id = ?", id).
+		Updates(updates).Error
+}
+
+// GetDueImportSources sources whose sync interval has passed since their last sync
+func (r *ImportSourceRepository) GetDueImportSources(ctx context.Context) ([]*domain.ImportSource, error) {
+	sources := []*domain.ImportSource{}
+	if err := r.db.WithContext(ctx).
+		Model(&domain.ImportSource{}).
+		Where("sync_interval > 0").
+		Where("last_synced_at IS NULL OR last_synced_at + sync_interval * INTERVAL '1 hour' <= ?", time.Now()).
+		Order("last_synced_at ASC NULLS FIRST").
+		Find(&sources).Error; err != nil {
+		return nil, err
+	}
+	return sources, nil
+}
+
+// StartSync mark the source syncing, false if it is already syncing
+func (r *ImportSourceRepository) StartSync(ctx context.Context, id string) (bool, error) {
+	now := time.Now()
+	result := r.db.WithContext(ctx).
+		Model(&domain.ImportSource{}).
+		Where("id = ?", id).
+		Where("(status <> ? OR updated_at < ?)", domain.ImportSourceStatusSyncing, now.Add(-domain.ImportSourceSyncTimeout)).
+		Updates(map[string]any{
+			"status":     domain.ImportSourceStatusSyncing,
+			"error":      "",
+			"updated_at": now,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// DeleteImportSource delete the source and its items, imported nodes are kept
+func (r *ImportSourceRepository) DeleteImportSource(ctx context.Context, kbID, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND kb_id = ?", id, kbID).Delete(&domain.ImportSource{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Where("source_id = ?", id).Delete(&domain.ImportSourceItem{}).Error
+	})
+}
+
+func (r *ImportSourceRepository) GetItems(ctx context.Context, sourceID string) ([]*domain.ImportSourceItem, error) {
+	items := []*domain.ImportSourceItem{}
+	if err := r.db.WithContext(ctx).
+		Model(&domain.ImportSourceItem{}).
+		Where("source_id = ?", sourceID).
+		Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *ImportSourceRepository) SaveItem(ctx context.Context, item *domain.ImportSourceItem) error {
+	item.UpdatedAt = time.Now()
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{UpdateAll: true}).
+		Create(item).Error
+}
+
+// MarkStaleItems mark pages not listed stale and listed ones not stale again, return count of stale pages
+func (r *ImportSourceRepository) MarkStaleItems(ctx context.Context, sourceID string, listed []string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		pages := func() *gorm.DB {
+			return tx.Model(&domain.ImportSourceItem{}).
+				Where("source_id = ? AND kind = ?", sourceID, domain.ImportSourceItemKindPage)
+		}
+		if len(listed) > 0 {
+			if err := pages().
+				Where("stale_at IS NOT NULL AND external_id IN ?", listed).
+				Update("stale_at", nil).Error; err != nil {
+				return err
+			}
+		}
+		stale := pages().Where("stale_at IS NULL")
+		if len(listed) > 0 {
+			stale = stale.Where("external_id NOT IN ?", listed)
+		}
+		if err := stale.Update("stale_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return pages().Where("stale_at IS NOT NULL").Count(&count).Error
+	})
+	return count, err
+}
+
+// GetStalePages pages no longer in the source whose node still exists, latest first
+func (r *ImportSourceRepository) GetStalePages(ctx context.Context, sourceID string) ([]*domain.ImportSourceStalePage, error) {
+	pages := []*domain.ImportSourceStalePage{}
+	if err := r.db.WithContext(ctx).
+		Model(&domain.ImportSourceItem{}).
+		Select("import_source_items.external_id, import_source_items.node_id, nodes.name AS node_name, import_source_items.stale_at").
+		Joins("JOIN nodes ON nodes.id = import_source_items.node_id").
+		Where("import_source_items.source_id = ? AND import_source_items.kind = ?", sourceID, domain.ImportSourceItemKindPage).
+		Where("import_source_items.stale_at IS NOT NULL").
+		Order("import_source_items.stale_at DESC").
+		Find(&pages).Error; err != nil {
+		return nil, err
+	}
+	return pages, nil
+}
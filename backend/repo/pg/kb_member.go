@@ -0,0 +1,136 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/store/pg"
+)
+
+type KBMemberRepository struct {
+	db *pg.DB
+}
+
+func NewKBMemberRepository(db *pg.DB) *KBMemberRepository {
+	return &KBMemberRepository{db: db}
+}
+
+// SetKBMember add the user to the kb, or change its role if it is already a member
+func (r *KBMemberRepository) SetKBMember(ctx context.Context, member *domain.KBMember) error {
+	now := time.Now()
+	member.CreatedAt = now
+	member.UpdatedAt = now
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "kb_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role", "updated_at"}),
+	}).Create(member).Error
+}
+
+// SyncUserKBRoles set the roles of the user on the managed kbs, it is removed from managed kbs without a role.
+// roles on other kbs are kept
+func (r *KBMemberRepository) SyncUserKBRoles(ctx context.Context, userID string, managedKBIDs []string, roles map[string]domain.KBRole) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		removed := make([]string, 0, len(managedKBIDs))
+		for _, kbID := range managedKBIDs {
+			if _, ok := roles[kbID]; !ok {
+				removed = append(removed, kbID)
+			}
+		}
+		if len(removed) > 0 {
+			if err := tx.Where("user_id = ? AND kb_id IN ?", userID, removed).Delete(&domain.KBMember{}).Error; err != nil {
+				return err
+			}
+		}
+		now := time.Now()
+		for kbID, role := range roles {
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "kb_id"}, {Name: "user_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"role", "updated_at"}),
+			}).Create(&domain.KBMember{
+				KBID:      kbID,
+				UserID:    userID,
+				Role:      role,
+				CreatedAt: now,
+				UpdatedAt: now,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *KBMemberRepository) GetKBMember(ctx context.Context, kbID, userID string) (*domain.KBMember, error) {
+	var member domain.KBMember
+	if err := r.db.WithContext(ctx).
+		Where("kb_id = ?", kbID).
+		Where("user_id = ?", userID).
+		First(&member).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrKBMemberNotFound
+		}
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (r *KBMemberRepository) GetKBMemberList(ctx context.Context, kbID string) ([]*domain.KBMemberListItem, error) {
+	members := []*domain.KBMemberListItem{}
+	if err := r.db.WithContext(ctx).
+		Model(&domain.KBMember{}).
+		Select("kb_members.*, users.account, users.role AS user_role").
+		Joins("JOIN users ON users.id = kb_members.user_id").
+		Where("kb_members.kb_id = ?", kbID).
+		Order("kb_members.created_at ASC").
+		Find(&members).Error; err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// GetUserKBMembers roles of the user on every kb it is a member of
+func (r *KBMemberRepository) GetUserKBMembers(ctx context.Context, userID string) ([]*domain.KBMember, error) {
+	members := []*domain.KBMember{}
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at ASC").
+		Find(&members).Error; err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+func (r *KBMemberRepository) DeleteKBMember(ctx context.Context, kbID, userID string) error {
+	res := r.db.WithContext(ctx).
+		Where("kb_id = ?", kbID).
+		Where("user_id = ?", userID).
+		Delete(&domain.KBMember{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrKBMemberNotFound
+	}
+	return nil
+}
+
+// GetResourceKBID kb of the row of model with the id, empty if there is no such row
+func (r *KBMemberRepository) GetResourceKBID(ctx context.Context, model any, id string) (string, error) {
+	var kbIDs []string
+	if err := r.db.WithContext(ctx).
+		Model(model).
+		Where("id = ?", id).
+		Limit(1).
+		Pluck("kb_id", &kbIDs).Error; err != nil {
+		return "", err
+	}
+	if len(kbIDs) == 0 {
+		return "", nil
+	}
+	return kbIDs[0], nil
+}
@@ -14,6 +14,7 @@ import (
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/chaitin/panda-wiki/config"
 	"github.com/chaitin/panda-wiki/domain"
@@ -160,7 +161,7 @@ func (r *KnowledgeBaseRepository) SyncKBAccessSettingsToCaddy(ctx context.Contex
 							{
 								"match": []map[string]any{
 									{
-										"path": []string{"/share/v1/node/detail", "/share/v1/app/wechat/app", "/share/v1/app/wechat/service", "/sitemap.xml"},
+										"path": []string{"/share/v1/node/detail", "/share/v1/app/wechat/app", "/share/v1/app/wechat/service", "/sitemap.xml", "/opensearch.xml"},
 									},
 								},
 								"handle": []map[string]any{
@@ -294,6 +295,7 @@ func (r *KnowledgeBaseRepository) CreateKnowledgeBase(ctx context.Context, kb *d
 		// get all kb list
 		var kbs []*domain.KnowledgeBaseListItem
 		if err := tx.Model(&domain.KnowledgeBase{}).
+			Where("sandbox_token_id = ''").
 			Order("created_at ASC").
 			Find(&kbs).Error; err != nil {
 			return err
@@ -390,6 +392,7 @@ func (r *KnowledgeBaseRepository) GetKnowledgeBaseList(ctx context.Context) ([]*
 	var kbs []*domain.KnowledgeBaseListItem
 	if err := r.db.WithContext(ctx).
 		Model(&domain.KnowledgeBase{}).
+		Where("sandbox_token_id = ''").
 		Order("created_at ASC").
 		Find(&kbs).Error; err != nil {
 		return nil, err
@@ -397,11 +400,163 @@ func (r *KnowledgeBaseRepository) GetKnowledgeBaseList(ctx context.Context) ([]*
 	return kbs, nil
 }
 
-func (r *KnowledgeBaseRepository) UpdateDatasetID(ctx context.Context, kbID, datasetID string) error {
-	return r.db.WithContext(ctx).
+// GetKnowledgeBasesOfEmbeddingModel kbs embedding documents with the model, kbs with the default model included
+func (r *KnowledgeBaseRepository) GetKnowledgeBasesOfEmbeddingModel(ctx context.Context, modelID string) ([]*domain.KnowledgeBase, error) {
+	var kbs []*domain.KnowledgeBase
+	if err := r.db.WithContext(ctx).
+		Where("sandbox_token_id = ''").
+		Where("COALESCE(model_settings->>'embedding_model_id', '') IN ('', ?)", modelID).
+		Order("created_at ASC").
+		Find(&kbs).Error; err != nil {
+		return nil, err
+	}
+	return kbs, nil
+}
+
+// GetEmbeddingModelIDs embedding models selected by kbs, kbs with the default model not included
+func (r *KnowledgeBaseRepository) GetEmbeddingModelIDs(ctx context.Context) ([]string, error) {
+	var ids []string
+	if err := r.db.WithContext(ctx).
+		Model(&domain.KnowledgeBase{}).
+		Where("COALESCE(model_settings->>'embedding_model_id', '') <> ''").
+		Distinct().
+		Pluck("model_settings->>'embedding_model_id'", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// GetReadOnlyKnowledgeBaseIDs kbs whose writes are locked by their maintenance settings
+func (r *KnowledgeBaseRepository) GetReadOnlyKnowledgeBaseIDs(ctx context.Context) ([]string, error) {
+	var ids []string
+	if err := r.db.WithContext(ctx).
+		Model(&domain.KnowledgeBase{}).
+		Where("(maintenance_settings->>'read_only')::boolean").
+		Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// CreateSandboxKnowledgeBase create a sandbox kb of an api token, it has no app and is not served by caddy.
+// fails with ErrSandboxLimit if the token already has max sandboxes
+func (r *KnowledgeBaseRepository) CreateSandboxKnowledgeBase(ctx context.Context, kb *domain.KnowledgeBase, maxSandboxes int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&domain.KnowledgeBase{}).
+			Where("sandbox_token_id = ?", kb.SandboxTokenID).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count >= int64(maxSandboxes) {
+			return domain.ErrSandboxLimit
+		}
+		return tx.Create(kb).Error
+	})
+}
+
+// GetSandboxKnowledgeBaseList sandbox kbs of the api token, of all tokens if tokenID is empty
+func (r *KnowledgeBaseRepository) GetSandboxKnowledgeBaseList(ctx context.Context, tokenID string) ([]*domain.KnowledgeBase, error) {
+	query := r.db.WithContext(ctx).Where("sandbox_token_id <> ''")
+	if tokenID != "" {
+		query = query.Where("sandbox_token_id = ?", tokenID)
+	}
+	var kbs []*domain.KnowledgeBase
+	if err := query.Order("created_at ASC").Find(&kbs).Error; err != nil {
+		return nil, err
+	}
+	return kbs, nil
+}
+
+// GetExpiredSandboxKnowledgeBaseIDs sandbox kbs expired before now
+func (r *KnowledgeBaseRepository) GetExpiredSandboxKnowledgeBaseIDs(ctx context.Context, now time.Time) ([]string, error) {
+	var ids []string
+	if err := r.db.WithContext(ctx).
+		Model(&domain.KnowledgeBase{}).
+		Where("sandbox_token_id <> ''").
+		Where("sandbox_expires_at < ?", now).
+		Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// SetPendingDatasetID start filling the dataset for the kb, return the dataset of an earlier rebuild it replaces
+func (r *KnowledgeBaseRepository) SetPendingDatasetID(ctx context.Context, kbID, datasetID string) (string, error) {
+	var replaced string
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var kb domain.KnowledgeBase
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "pending_dataset_id").
+			Where("id = ?", kbID).
+			First(&kb).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return domain.ErrKBNotFound.Wrap(err)
+			}
+			return err
+		}
+		replaced = kb.PendingDatasetID
+		return tx.Model(&domain.KnowledgeBase{}).
+			Where("id = ?", kbID).
+			Update("pending_dataset_id", datasetID).Error
+	})
+	return replaced, err
+}
+
+// SwitchDataset replace the dataset of the kb with the filled pending dataset and record the docs of the releases in it,
+// return the replaced dataset, nothing is switched if a later rebuild replaced the pending dataset
+func (r *KnowledgeBaseRepository) SwitchDataset(ctx context.Context, kbID, datasetID string, docIDs map[string]string) (string, bool, error) {
+	var oldDatasetID string
+	switched := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var kb domain.KnowledgeBase
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "dataset_id", "pending_dataset_id").
+			Where("id = ?", kbID).
+			First(&kb).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		if kb.PendingDatasetID != datasetID {
+			return nil
+		}
+		if err := tx.Model(&domain.KnowledgeBase{}).
+			Where("id = ?", kbID).
+			Updates(map[string]any{
+				"dataset_id":         datasetID,
+				"pending_dataset_id": "",
+			}).Error; err != nil {
+			return err
+		}
+		for releaseID, docID := range docIDs {
+			if err := tx.Model(&domain.NodeRelease{}).
+				Where("kb_id = ? AND id = ?", kbID, releaseID).
+				Update("doc_id", docID).Error; err != nil {
+				return err
+			}
+		}
+		oldDatasetID = kb.DatasetID
+		switched = true
+		return nil
+	})
+	if err != nil {
+		return "", false, err
+	}
+	return oldDatasetID, switched, nil
+}
+
+// GetRebuildingKnowledgeBaseIDs kbs whose pending dataset is being filled
+func (r *KnowledgeBaseRepository) GetRebuildingKnowledgeBaseIDs(ctx context.Context) ([]string, error) {
+	var ids []string
+	if err := r.db.WithContext(ctx).
 		Model(&domain.KnowledgeBase{}).
-		Where("id = ?", kbID).
-		Update("dataset_id", datasetID).Error
+		Where("pending_dataset_id <> ''").
+		Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
 }
 
 func (r *KnowledgeBaseRepository) UpdateKnowledgeBase(ctx context.Context, req *domain.UpdateKnowledgeBaseReq) error {
@@ -412,6 +567,42 @@ func (r *KnowledgeBaseRepository) UpdateKnowledgeBase(ctx context.Context, req *
 	if req.AccessSettings != nil {
 		updateMap["access_settings"] = req.AccessSettings
 	}
+	if req.ComplianceSettings != nil {
+		updateMap["compliance_settings"] = req.ComplianceSettings
+	}
+	if req.StatSettings != nil {
+		updateMap["stat_settings"] = req.StatSettings
+	}
+	if req.AnswerSettings != nil {
+		updateMap["answer_settings"] = req.AnswerSettings
+	}
+	if req.MaintenanceSettings != nil {
+		updateMap["maintenance_settings"] = req.MaintenanceSettings
+	}
+	if req.ReviewSettings != nil {
+		updateMap["review_settings"] = req.ReviewSettings
+	}
+	if req.DigestSettings != nil {
+		updateMap["digest_settings"] = req.DigestSettings
+	}
+	if req.GeoSettings != nil {
+		updateMap["geo_settings"] = req.GeoSettings
+	}
+	if req.CommentSettings != nil {
+		updateMap["comment_settings"] = req.CommentSettings
+	}
+	if req.BrandingSettings != nil {
+		updateMap["branding_settings"] = req.BrandingSettings
+	}
+	if req.AnomalySettings != nil {
+		updateMap["anomaly_settings"] = req.AnomalySettings
+	}
+	if req.ReviewReminderSettings != nil {
+		updateMap["review_reminder_settings"] = req.ReviewReminderSettings
+	}
+	if req.ModelSettings != nil {
+		updateMap["model_settings"] = req.ModelSettings
+	}
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		if err := tx.Model(&domain.KnowledgeBase{}).Where("id = ?", req.ID).Updates(updateMap).Error; err != nil {
 			return err
@@ -419,6 +610,7 @@ func (r *KnowledgeBaseRepository) UpdateKnowledgeBase(ctx context.Context, req *
 		// get all kb list
 		var kbs []*domain.KnowledgeBaseListItem
 		if err := tx.Model(&domain.KnowledgeBase{}).
+			Where("sandbox_token_id = ''").
 			Order("created_at ASC").
 			Find(&kbs).Error; err != nil {
 			return err
@@ -436,6 +628,9 @@ func (r *KnowledgeBaseRepository) UpdateKnowledgeBase(ctx context.Context, req *
 func (r *KnowledgeBaseRepository) GetKnowledgeBaseByID(ctx context.Context, kbID string) (*domain.KnowledgeBase, error) {
 	var kb domain.KnowledgeBase
 	if err := r.db.WithContext(ctx).Where("id = ?", kbID).First(&kb).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrKBNotFound.Wrap(err)
+		}
 		return nil, err
 	}
 	return &kb, nil
@@ -446,6 +641,44 @@ func (r *KnowledgeBaseRepository) DeleteKnowledgeBase(ctx context.Context, kbID
 		if err := tx.Where("kb_id = ?", kbID).Delete(&domain.Node{}).Error; err != nil {
 			return err
 		}
+		if err := tx.Where("kb_id = ?", kbID).Delete(&domain.NodeLink{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("kb_id = ?", kbID).Delete(&domain.NodeComment{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("kb_id = ?", kbID).Delete(&domain.NodeOwner{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("kb_id = ?", kbID).Delete(&domain.NodeACL{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("kb_id = ?", kbID).Delete(&domain.Reader{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("kb_id = ?", kbID).Delete(&domain.KBMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("kb_id = ?", kbID).Delete(&domain.GlossaryTerm{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("kb_id = ?", kbID).Delete(&domain.AppPromptTemplate{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("run_id IN (?)", tx.Model(&domain.EvalRun{}).Select("id").Where("kb_id = ?", kbID)).
+			Delete(&domain.EvalResult{}).Error; err != nil {
+			return err
+		}
+		for _, model := range []any{&domain.EvalRun{}, &domain.EvalQuestion{}, &domain.EvalSet{}} {
+			if err := tx.Where("kb_id = ?", kbID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		for _, model := range []any{&domain.AnswerCacheEntry{}, &domain.GuardrailEvent{}} {
+			if err := tx.Where("kb_id = ?", kbID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
 		if err := tx.Where("kb_id = ?", kbID).Delete(&domain.App{}).Error; err != nil {
 			return err
 		}
@@ -455,6 +688,7 @@ func (r *KnowledgeBaseRepository) DeleteKnowledgeBase(ctx context.Context, kbID
 		// get all kb list
 		var kbs []*domain.KnowledgeBaseListItem
 		if err := tx.Model(&domain.KnowledgeBase{}).
+			Where("sandbox_token_id = ''").
 			Order("created_at ASC").
 			Find(&kbs).Error; err != nil {
 			return err
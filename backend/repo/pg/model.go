@@ -2,6 +2,7 @@ package pg
 
 import (
 	"context"
+	"errors"
 
 	"github.com/cloudwego/eino/schema"
 	"gorm.io/gorm"
@@ -71,6 +72,8 @@ func (r *ModelRepository) Update(ctx context.Context, req *domain.UpdateModelReq
 			"api_version": req.APIVersion,
 			"provider":    req.Provider,
 			"type":        req.Type,
+			"priority":    req.Priority,
+			"weight":      req.Weight,
 		}).Error
 }
 
@@ -85,11 +88,18 @@ func (r *ModelRepository) Delete(ctx context.Context, id string) error {
 	})
 }
 
+// GetChatModel default chat model
 func (r *ModelRepository) GetChatModel(ctx context.Context) (*domain.Model, error) {
+	return r.GetModelByType(ctx, domain.ModelTypeChat)
+}
+
+// GetModel model of the id and the type
+func (r *ModelRepository) GetModel(ctx context.Context, id string, modelType domain.ModelType) (*domain.Model, error) {
 	var model domain.Model
 	if err := r.db.WithContext(ctx).
 		Model(&domain.Model{}).
-		Where("type = ?", domain.ModelTypeChat).
+		Where("id = ?", id).
+		Where("type = ?", modelType).
 		First(&model).Error; err != nil {
 		return nil, err
 	}
@@ -111,3 +121,47 @@ func (r *ModelRepository) UpdateUsage(ctx context.Context, modelID string, usage
 		return nil
 	})
 }
+
+// GetModelByType default model of the type, the earliest configured
+func (r *ModelRepository) GetModelByType(ctx context.Context, modelType domain.ModelType) (*domain.Model, error) {
+	var model domain.Model
+	if err := r.db.WithContext(ctx).
+		Model(&domain.Model{}).
+		Where("type = ?", modelType).
+		Order("created_at ASC").
+		First(&model).Error; err != nil {
+		return nil, err
+	}
+	return &model, nil
+}
+
+// GetFailoverChatModels chat models of the failover pool, by priority
+func (r *ModelRepository) GetFailoverChatModels(ctx context.Context) ([]*domain.Model, error) {
+	var models []*domain.Model
+	if err := r.db.WithContext(ctx).
+		Model(&domain.Model{}).
+		Where("type = ?", domain.ModelTypeChat).
+		Where("priority > 0").
+		Order("priority ASC, created_at ASC").
+		Find(&models).Error; err != nil {
+		return nil, err
+	}
+	return models, nil
+}
+
+// GetKBModel model of the type selected for the kb, the default model if none is selected or it was deleted
+func (r *ModelRepository) GetKBModel(ctx context.Context, kbID string, modelType domain.ModelType) (*domain.Model, error) {
+	var model domain.Model
+	err := r.db.WithContext(ctx).
+		Model(&domain.Model{}).
+		Where("type = ?", modelType).
+		Where("id = (SELECT model_settings->>? FROM knowledge_bases WHERE id = ?)", string(modelType)+"_model_id", kbID).
+		First(&model).Error
+	if err == nil {
+		return &model, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	return r.GetModelByType(ctx, modelType)
+}
@@ -0,0 +1,92 @@
+package pg
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/chaitin/panda-wiki/domain"
+)
+
+func (r *ModelRepository) GetBudget(ctx context.Context, modelID string) (*domain.ModelBudget, error) {
+	var budget domain.ModelBudget
+	if err := r.db.WithContext(ctx).
+		Model(&domain.ModelBudget{}).
+		Where("model_id = ?", modelID).
+		First(&budget).Error; err != nil {
+		return nil, err
+	}
+	return &budget, nil
+}
+
+func (r *ModelRepository) GetBudgets(ctx context.Context) ([]*domain.ModelBudget, error) {
+	var budgets []*domain.ModelBudget
+	if err := r.db.WithContext(ctx).
+		Model(&domain.ModelBudget{}).
+		Find(&budgets).Error; err != nil {
+		return nil, err
+	}
+	return budgets, nil
+}
+
+func (r *ModelRepository) UpsertBudget(ctx context.Context, budget *domain.ModelBudget) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "model_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"monthly_budget", "monthly_token_limit", "prompt_price", "completion_price", "notify_thresholds", "notify_webhook", "exceeded_action", "exceeded_message", "fallback_model", "updated_at"}),
+	}).Create(budget).Error
+}
+
+// IncrMonthlyUsage add usage to the month and return the accumulated usage
+func (r *ModelRepository) IncrMonthlyUsage(ctx context.Context, usage *domain.ModelUsageMonthly) (*domain.ModelUsageMonthly, error) {
+	if err := r.db.WithContext(ctx).Clauses(
+		clause.OnConflict{
+			Columns: []clause.Column{{Name: "model_id"}, {Name: "month"}},
+			DoUpdates: clause.Assignments(map[string]any{
+				"prompt_tokens":     gorm.Expr("model_usage_monthly.prompt_tokens + EXCLUDED.prompt_tokens"),
+				"completion_tokens": gorm.Expr("model_usage_monthly.completion_tokens + EXCLUDED.completion_tokens"),
+				"total_tokens":      gorm.Expr("model_usage_monthly.total_tokens + EXCLUDED.total_tokens"),
+				"cost":              gorm.Expr("model_usage_monthly.cost + EXCLUDED.cost"),
+			}),
+		},
+		clause.Returning{},
+	).Create(usage).Error; err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
+
+func (r *ModelRepository) GetMonthlyUsage(ctx context.Context, modelID string, month time.Time) (*domain.ModelUsageMonthly, error) {
+	usage := &domain.ModelUsageMonthly{ModelID: modelID, Month: month}
+	if err := r.db.WithContext(ctx).
+		Model(&domain.ModelUsageMonthly{}).
+		Where("model_id = ? AND month = ?", modelID, month).
+		Find(usage).Error; err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
+
+func (r *ModelRepository) GetMonthlyUsages(ctx context.Context, month time.Time) ([]*domain.ModelUsageMonthly, error) {
+	var usages []*domain.ModelUsageMonthly
+	if err := r.db.WithContext(ctx).
+		Model(&domain.ModelUsageMonthly{}).
+		Where("month = ?", month).
+		Find(&usages).Error; err != nil {
+		return nil, err
+	}
+	return usages, nil
+}
+
+// UpdateNotifiedThreshold set notified threshold only if it is larger than the current one, return false if already notified
+func (r *ModelRepository) UpdateNotifiedThreshold(ctx context.Context, modelID string, month time.Time, threshold int) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&domain.ModelUsageMonthly{}).
+		Where("model_id = ? AND month = ? AND notified_threshold < ?", modelID, month, threshold).
+		Update("notified_threshold", threshold)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
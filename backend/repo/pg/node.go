@@ -2,7 +2,9 @@ package pg
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -71,21 +73,28 @@ func (r *NodeRepository) Create(ctx context.Context, req *domain.CreateNodeReq)
 			visibility = domain.NodeVisibilityPublic
 		}
 		node := &domain.Node{
-			ID:         nodeIDStr,
-			KBID:       req.KBID,
-			Name:       req.Name,
-			Content:    req.Content,
-			Meta:       domain.NodeMeta{Emoji: req.Emoji},
-			Type:       req.Type,
-			ParentID:   req.ParentID,
-			Position:   newPos,
-			Status:     domain.NodeStatusDraft,
-			Visibility: visibility,
-			CreatedAt:  now,
-			UpdatedAt:  now,
-		}
-
-		return tx.Create(node).Error
+			ID:          nodeIDStr,
+			KBID:        req.KBID,
+			Name:        req.Name,
+			Content:     req.Content,
+			ContentHash: domain.ContentHash(req.Content),
+			Meta:        domain.NodeMeta{Emoji: req.Emoji, Tags: req.Tags, SEO: req.SEO},
+			Type:        req.Type,
+			ParentID:    req.ParentID,
+			Position:    newPos,
+			Status:      domain.NodeStatusDraft,
+			Visibility:  visibility,
+
+			InheritedFields: req.InheritedFields,
+
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+
+		if err := tx.Create(node).Error; err != nil {
+			return err
+		}
+		return saveNodeLinks(tx, req.KBID, nodeIDStr, req.Content)
 	})
 	if err != nil {
 		return "", err
@@ -144,11 +153,12 @@ func (r *NodeRepository) UpdateNodeContent(ctx context.Context, req *domain.Upda
 	}
 	if req.Content != nil {
 		updateMap["content"] = *req.Content
+		updateMap["content_hash"] = domain.ContentHash(*req.Content)
 		updateStatus = true
 	}
 
 	// Handle multiple meta field updates
-	if req.Emoji != nil || req.Summary != nil {
+	if req.Emoji != nil || req.Summary != nil || req.Tags != nil || req.SEO != nil {
 		metaExpr := "meta"
 		var args []interface{}
 
@@ -166,6 +176,20 @@ func (r *NodeRepository) UpdateNodeContent(ctx context.Context, req *domain.Upda
 			updateStatus = true
 		}
 
+		if req.Tags != nil {
+			metaExpr = "jsonb_set(" + metaExpr + ", '{tags}', ?::jsonb)"
+			tags, _ := json.Marshal(*req.Tags)
+			args = append(args, string(tags))
+			updateStatus = true
+		}
+
+		if req.SEO != nil {
+			metaExpr = "jsonb_set(" + metaExpr + ", '{seo}', ?::jsonb)"
+			seo, _ := json.Marshal(req.SEO)
+			args = append(args, string(seo))
+			updateStatus = true
+		}
+
 		updateMap["meta"] = gorm.Expr(metaExpr, args...)
 	}
 
@@ -176,14 +200,36 @@ func (r *NodeRepository) UpdateNodeContent(ctx context.Context, req *domain.Upda
 	if updateStatus {
 		updateMap["status"] = domain.NodeStatusDraft
 	}
-	if len(updateMap) > 0 {
-		return r.db.WithContext(ctx).
-			Model(&domain.Node{}).
+	// fields set explicitly no longer follow folder defaults
+	overridden := make([]string, 0)
+	if req.Visibility != nil {
+		overridden = append(overridden, domain.NodeFieldVisibility)
+	}
+	if req.Tags != nil {
+		overridden = append(overridden, domain.NodeFieldTags)
+	}
+	if req.SEO != nil {
+		overridden = append(overridden, domain.NodeFieldSEO)
+	}
+	if len(overridden) > 0 {
+		expr := "inherited_fields" + strings.Repeat(" - ?::text", len(overridden))
+		updateMap["inherited_fields"] = gorm.Expr(expr, lo.ToAnySlice(overridden)...)
+	}
+	if len(updateMap) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.Node{}).
 			Where("id = ?", req.ID).
 			Where("kb_id = ?", req.KBID).
-			Updates(updateMap).Error
-	}
-	return nil
+			Updates(updateMap).Error; err != nil {
+			return err
+		}
+		if req.Content != nil {
+			return saveNodeLinks(tx, req.KBID, req.ID, *req.Content)
+		}
+		return nil
+	})
 }
 
 func (r *NodeRepository) GetByID(ctx context.Context, id string) (*domain.NodeDetailResp, error) {
@@ -192,6 +238,9 @@ func (r *NodeRepository) GetByID(ctx context.Context, id string) (*domain.NodeDe
 		Model(&domain.Node{}).
 		Where("id = ?", id).
 		First(&node).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNodeNotFound.Wrap(err)
+		}
 		return nil, err
 	}
 	return node, nil
@@ -219,6 +268,19 @@ func (r *NodeRepository) Delete(ctx context.Context, kbID string, ids []string)
 			Delete(&nodes).Error; err != nil {
 			return err
 		}
+		if err := tx.Where("node_id IN ?", ids).Delete(&domain.NodeComment{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("node_id IN ?", ids).Delete(&domain.NodeOwner{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("node_id IN ?", ids).Delete(&domain.NodeACL{}).Error; err != nil {
+			return err
+		}
+		// delete outgoing links, links to the nodes are kept as broken links
+		if err := tx.Where("source_id IN ?", ids).Delete(&domain.NodeLink{}).Error; err != nil {
+			return err
+		}
 		// delete node release
 		var nodeReleases []*domain.NodeRelease
 		if err := tx.Model(&domain.NodeRelease{}).
@@ -250,11 +312,28 @@ func (r *NodeRepository) GetNodeByID(ctx context.Context, id string) (*domain.No
 		Model(&domain.Node{}).
 		Where("id = ?", id).
 		First(&node).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNodeNotFound.Wrap(err)
+		}
 		return nil, err
 	}
 	return node, nil
 }
 
+// NodeReleaseExists whether the node of the kb was ever published
+func (r *NodeRepository) NodeReleaseExists(ctx context.Context, kbID, nodeID string) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).
+		Model(&domain.NodeRelease{}).
+		Where("kb_id = ?", kbID).
+		Where("node_id = ?", nodeID).
+		Limit(1).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
 func (r *NodeRepository) GetNodeNameByNodeIDs(ctx context.Context, ids []string) (map[string]string, error) {
 	nodesMap := make(map[string]string)
 	for _, chunk := range lo.Chunk(ids, 1000) {
@@ -399,6 +478,9 @@ func (r *NodeRepository) GetNodeReleaseDetailByKBIDAndID(ctx context.Context, kb
 		Where("node_releases.visibility = ?", domain.NodeVisibilityPublic).
 		Select("node_releases.*").
 		First(&node).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNodeNotFound.Wrap(err)
+		}
 		return nil, err
 	}
 	return node, nil
@@ -473,10 +555,11 @@ func (r *NodeRepository) UpdateNodeSummary(ctx context.Context, kbID, nodeID, su
 		}).Error
 }
 
-// traverse all nodes by pg cursor
-func (r *NodeRepository) TraverseNodesByCursor(ctx context.Context, callback func(*domain.NodeRelease) error) error {
+// traverse the latest public releases of the nodes of the kb by pg cursor
+func (r *NodeRepository) TraverseNodesByCursor(ctx context.Context, kbID string, callback func(*domain.NodeRelease) error) error {
 	rows, err := r.db.WithContext(ctx).
 		Model(&domain.NodeRelease{}).
+		Where("kb_id = ?", kbID).
 		Where("visibility = ?", domain.NodeVisibilityPublic).
 		Select("DISTINCT ON (node_id) id, node_id, kb_id").
 		Order("node_id, updated_at DESC").
@@ -580,3 +663,347 @@ func (r *NodeRepository) GetOldNodeDocIDsByNodeID(ctx context.Context, nodeRelea
 	}
 	return docIDs, nil
 }
+
+func (r *NodeRepository) GetNodeCount(ctx context.Context, kbID string) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).
+		Model(&domain.Node{}).
+		Where("kb_id = ?", kbID).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetStaleNodes get documents neither updated nor reviewed since before, oldest first
+func (r *NodeRepository) GetStaleNodes(ctx context.Context, kbID string, before time.Time, limit int) ([]*domain.StaleNode, error) {
+	var nodes []*domain.StaleNode
+	if err := r.db.WithContext(ctx).
+		Model(&domain.Node{}).
+		Where("kb_id = ?", kbID).
+		Where("type = ?", domain.NodeTypeDocument).
+		Where("GREATEST(updated_at, reviewed_at) < ?", before).
+		Select("id, name, updated_at").
+		Order("GREATEST(updated_at, reviewed_at) ASC").
+		Limit(limit).
+		Find(&nodes).Error; err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
+
+// SearchNodeReleases search public documents of the latest kb release by name and content, name matches first
+func (r *NodeRepository) SearchNodeReleases(ctx context.Context, kbID, query string, limit int) ([]*domain.NodeSearchResult, error) {
+	var kbRelease *domain.KBRelease
+	if err := r.db.WithContext(ctx).
+		Model(&domain.KBRelease{}).
+		Where("kb_id = ?", kbID).
+		Order("created_at DESC").
+		First(&kbRelease).Error; err != nil {
+		return nil, err
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(query)
+	pattern := "%" + escaped + "%"
+	var nodes []*domain.NodeSearchResult
+	if err := r.db.WithContext(ctx).
+		Model(&domain.KBReleaseNodeRelease{}).
+		Joins("LEFT JOIN node_releases ON node_releases.id = kb_release_node_releases.node_release_id").
+		Where("kb_release_node_releases.kb_id = ?", kbID).
+		Where("kb_release_node_releases.release_id = ?", kbRelease.ID).
+		Where("node_releases.visibility = ?", domain.NodeVisibilityPublic).
+		Where("node_releases.type = ?", domain.NodeTypeDocument).
+		Where("node_releases.name ILIKE ? OR node_releases.content ILIKE ?", pattern, pattern).
+		Select("node_releases.node_id as id, node_releases.name, node_releases.meta->>'summary' as summary, node_releases.meta->>'emoji' as emoji, node_releases.updated_at").
+		Order(gorm.Expr("CASE WHEN node_releases.name ILIKE ? THEN 0 WHEN node_releases.name ILIKE ? THEN 1 WHEN node_releases.name ILIKE ? THEN 2 ELSE 3 END", escaped, escaped+"%", pattern)).
+		Order("node_releases.updated_at DESC").
+		Limit(limit).
+		Find(&nodes).Error; err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
+
+// nodeReleaseTSVector same expression as idx_node_releases_fulltext so the index is used
+const nodeReleaseTSVector = "to_tsvector('simple', coalesce(node_releases.name, '') || ' ' || coalesce(node_releases.content, ''))"
+
+// FullTextSearchNodeReleases full-text search of public documents of the latest kb release ranked by relevance.
+// every term must also match as substring, so words not split by the text search parser (e.g. chinese) are found
+func (r *NodeRepository) FullTextSearchNodeReleases(ctx context.Context, req *domain.FullTextSearchReq, terms []string) ([]*domain.FullTextSearchResult, uint64, error) {
+	var kbRelease *domain.KBRelease
+	if err := r.db.WithContext(ctx).
+		Model(&domain.KBRelease{}).
+		Where("kb_id = ?", req.KBID).
+		Order("created_at DESC").
+		First(&kbRelease).Error; err != nil {
+		return nil, 0, err
+	}
+	tsQuery := gorm.Expr("websearch_to_tsquery(?, ?)", domain.FullTextTextSearchConfig, req.Query)
+	query := r.db.WithContext(ctx).
+		Model(&domain.KBReleaseNodeRelease{}).
+		Joins("JOIN node_releases ON node_releases.id = kb_release_node_releases.node_release_id").
+		Where("kb_release_node_releases.kb_id = ?", req.KBID).
+		Where("kb_release_node_releases.release_id = ?", kbRelease.ID).
+		Where("node_releases.visibility = ?", domain.NodeVisibilityPublic).
+		Where("node_releases.type = ?", domain.NodeTypeDocument)
+	escaper := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	patterns := make([]string, 0, len(terms))
+	for _, term := range terms {
+		patterns = append(patterns, "%"+escaper.Replace(term)+"%")
+	}
+	match := nodeReleaseTSVector + " @@ ?"
+	matchArgs := []any{tsQuery}
+	if len(patterns) > 0 {
+		conditions := make([]string, 0, len(patterns))
+		for _, pattern := range patterns {
+			conditions = append(conditions, "(node_releases.name ILIKE ? OR node_releases.content ILIKE ?)")
+			matchArgs = append(matchArgs, pattern, pattern)
+		}
+		match = "(" + match + " OR (" + strings.Join(conditions, " AND ") + "))"
+	}
+	query = query.Where(match, matchArgs...)
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	// name matches rank above content matches
+	nameBoost := "0"
+	args := []any{tsQuery}
+	for _, pattern := range patterns {
+		nameBoost += " + CASE WHEN node_releases.name ILIKE ? THEN 1 ELSE 0 END"
+		args = append(args, pattern)
+	}
+	var results []*domain.FullTextSearchResult
+	if err := query.
+		Select("node_releases.node_id as id, node_releases.name, node_releases.meta->>'emoji' as emoji, node_releases.content, node_releases.updated_at, "+
+			"ts_rank("+nodeReleaseTSVector+", ?) + "+nameBoost+" as rank", args...).
+		Order("rank DESC").
+		Order("node_releases.updated_at DESC").
+		Offset(req.Offset()).
+		Limit(req.Limit()).
+		Find(&results).Error; err != nil {
+		return nil, 0, err
+	}
+	return results, uint64(count), nil
+}
+
+// KeywordSearchNodeReleases public node releases in the vector index of the kb matching the keyword query,
+// ranked by tsvector rank with a bonus for each identifier found as exact substring
+func (r *NodeRepository) KeywordSearchNodeReleases(ctx context.Context, kbID string, keywords *domain.KeywordQuery, limit int) ([]*domain.KeywordNodeMatch, error) {
+	conditions := make([]string, 0, len(keywords.Identifiers)+1)
+	rank := "0"
+	var conditionArgs, rankArgs []any
+	if len(keywords.Words) > 0 {
+		tsQuery := gorm.Expr("to_tsquery(?, ?)", domain.FullTextTextSearchConfig, keywords.TSQuery())
+		conditions = append(conditions, nodeReleaseTSVector+" @@ ?")
+		conditionArgs = append(conditionArgs, tsQuery)
+		rank = "ts_rank_cd(" + nodeReleaseTSVector + ", ?)"
+		rankArgs = append(rankArgs, tsQuery)
+	}
+	escaper := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	for _, identifier := range keywords.Identifiers {
+		pattern := "%" + escaper.Replace(identifier) + "%"
+		conditions = append(conditions, "(node_releases.name ILIKE ? OR node_releases.content ILIKE ?)")
+		conditionArgs = append(conditionArgs, pattern, pattern)
+		rank += " + CASE WHEN node_releases.name ILIKE ? OR node_releases.content ILIKE ? THEN 1 ELSE 0 END"
+		rankArgs = append(rankArgs, pattern, pattern)
+	}
+	if len(conditions) == 0 {
+		return []*domain.KeywordNodeMatch{}, nil
+	}
+	var matches []*domain.KeywordNodeMatch
+	if err := r.db.WithContext(ctx).
+		Model(&domain.NodeRelease{}).
+		Where("node_releases.kb_id = ?", kbID).
+		Where("node_releases.doc_id != ''").
+		Where("node_releases.visibility = ?", domain.NodeVisibilityPublic).
+		Where("("+strings.Join(conditions, " OR ")+")", conditionArgs...).
+		Select("node_releases.id, node_releases.node_id, node_releases.doc_id, node_releases.name, node_releases.meta, node_releases.content, "+rank+" as rank", rankArgs...).
+		Order("rank DESC").
+		Order("node_releases.updated_at DESC").
+		Limit(limit).
+		Find(&matches).Error; err != nil {
+		return nil, err
+	}
+	return matches, nil
+}
+
+// GetNodesByContentHashes documents of all kbs with any of the content hashes
+func (r *NodeRepository) GetNodesByContentHashes(ctx context.Context, hashes []string) ([]*domain.NodeDuplicate, error) {
+	duplicates := []*domain.NodeDuplicate{}
+	if len(hashes) == 0 {
+		return duplicates, nil
+	}
+	if err := r.db.WithContext(ctx).
+		Model(&domain.Node{}).
+		Joins("JOIN knowledge_bases ON knowledge_bases.id = nodes.kb_id").
+		Where("nodes.content_hash IN ?", hashes).
+		Where("nodes.type = ?", domain.NodeTypeDocument).
+		Select("nodes.kb_id, knowledge_bases.name as kb_name, nodes.id as node_id, nodes.name as node_name, " +
+			"knowledge_bases.access_settings->>'base_url' as base_url, nodes.content_hash").
+		Order("nodes.created_at ASC").
+		Find(&duplicates).Error; err != nil {
+		return nil, err
+	}
+	return duplicates, nil
+}
+
+// GetNodeDefaultsChain defaults of the node and its upper folders, from root to the node
+func (r *NodeRepository) GetNodeDefaultsChain(ctx context.Context, kbID, id string) ([]domain.NodeDefaults, error) {
+	var rows []struct {
+		Defaults domain.NodeDefaults
+	}
+	if err := r.db.WithContext(ctx).Raw(`
+		WITH RECURSIVE ancestors AS (
+			SELECT id, parent_id, defaults, 0 AS depth FROM nodes WHERE id = ? AND kb_id = ?
+			UNION ALL
+			SELECT n.id, n.parent_id, n.defaults, a.depth + 1 FROM nodes n
+			JOIN ancestors a ON n.id = a.parent_id
+			WHERE n.kb_id = ? AND a.depth < 64
+		)
+		SELECT defaults FROM ancestors ORDER BY depth DESC`, id, kbID, kbID).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return lo.Map(rows, func(row struct{ Defaults domain.NodeDefaults }, _ int) domain.NodeDefaults {
+		return row.Defaults
+	}), nil
+}
+
+func (r *NodeRepository) UpdateNodeDefaults(ctx context.Context, kbID, id string, defaults domain.NodeDefaults) error {
+	result := r.db.WithContext(ctx).
+		Model(&domain.Node{}).
+		Where("id = ?", id).
+		Where("kb_id = ?", kbID).
+		Where("type = ?", domain.NodeTypeFolder).
+		Update("defaults", defaults)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetNodeTree nodes of kb without content, for walking folders
+func (r *NodeRepository) GetNodeTree(ctx context.Context, kbID string) ([]*domain.Node, error) {
+	var nodes []*domain.Node
+	if err := r.db.WithContext(ctx).
+		Model(&domain.Node{}).
+		Where("kb_id = ?", kbID).
+		Select("id, kb_id, type, visibility, parent_id, meta, defaults, inherited_fields").
+		Find(&nodes).Error; err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
+
+// GetExportNodes nodes of kb with content, as edited including drafts
+func (r *NodeRepository) GetExportNodes(ctx context.Context, kbID string) ([]*domain.Node, error) {
+	var nodes []*domain.Node
+	if err := r.db.WithContext(ctx).
+		Model(&domain.Node{}).
+		Where("kb_id = ?", kbID).
+		Select("id, kb_id, type, name, content, meta, parent_id, position, created_at, updated_at").
+		Find(&nodes).Error; err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
+
+// UpdateInheritedNodeFields set fields of the node still following folder defaults
+func (r *NodeRepository) UpdateInheritedNodeFields(ctx context.Context, kbID, id string, inherited domain.InheritedFields, defaults domain.NodeDefaults) error {
+	updateMap := map[string]any{}
+	if inherited.Has(domain.NodeFieldVisibility) && defaults.Visibility != nil {
+		updateMap["visibility"] = *defaults.Visibility
+	}
+	metaExpr := "meta"
+	var args []any
+	if inherited.Has(domain.NodeFieldTags) {
+		tags, _ := json.Marshal(defaults.Tags)
+		metaExpr = "jsonb_set(" + metaExpr + ", '{tags}', ?::jsonb)"
+		args = append(args, string(tags))
+	}
+	if inherited.Has(domain.NodeFieldSEO) {
+		seo, _ := json.Marshal(defaults.SEO)
+		metaExpr = "jsonb_set(" + metaExpr + ", '{seo}', ?::jsonb)"
+		args = append(args, string(seo))
+	}
+	if len(args) > 0 {
+		updateMap["meta"] = gorm.Expr(metaExpr, args...)
+	}
+	if len(updateMap) == 0 {
+		return nil
+	}
+	updateMap["status"] = domain.NodeStatusDraft
+	return r.db.WithContext(ctx).
+		Model(&domain.Node{}).
+		Where("id = ?", id).
+		Where("kb_id = ?", kbID).
+		Updates(updateMap).Error
+}
+
+// DiscardNodeDraft restore the node to its latest published release
+func (r *NodeRepository) DiscardNodeDraft(ctx context.Context, kbID, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var nodeRelease *domain.NodeRelease
+		if err := tx.Model(&domain.NodeRelease{}).
+			Where("node_id = ?", id).
+			Where("kb_id = ?", kbID).
+			Order("updated_at DESC").
+			First(&nodeRelease).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return domain.ErrNodeNotPublished
+			}
+			return err
+		}
+		if err := tx.Model(&domain.Node{}).
+			Where("id = ?", id).
+			Where("kb_id = ?", kbID).
+			Updates(map[string]any{
+				"name":         nodeRelease.Name,
+				"content":      nodeRelease.Content,
+				"content_hash": domain.ContentHash(nodeRelease.Content),
+				"meta":         &nodeRelease.Meta,
+				"visibility":   nodeRelease.Visibility,
+				"status":       domain.NodeStatusReleased,
+				"updated_at":   time.Now(),
+			}).Error; err != nil {
+			return err
+		}
+		return saveNodeLinks(tx, kbID, id, nodeRelease.Content)
+	})
+}
+
+// GetIndexedNodeReleases latest release of each node of the kb which is public and should be in the vector store
+func (r *NodeRepository) GetIndexedNodeReleases(ctx context.Context, kbID string) ([]*domain.NodeRelease, error) {
+	var releases []*domain.NodeRelease
+	if err := r.db.WithContext(ctx).Raw(`
+		SELECT id, node_id, kb_id, doc_id, type, updated_at
+		FROM (
+			SELECT DISTINCT ON (node_id) id, node_id, kb_id, doc_id, type, visibility, updated_at
+			FROM node_releases
+			WHERE kb_id = ?
+			ORDER BY node_id, updated_at DESC
+		) latest
+		WHERE visibility = ?`,
+		kbID, domain.NodeVisibilityPublic,
+	).Scan(&releases).Error; err != nil {
+		return nil, err
+	}
+	return releases, nil
+}
+
+// GetNodeReleaseDocIDs doc ids referenced by any release of the kb
+func (r *NodeRepository) GetNodeReleaseDocIDs(ctx context.Context, kbID string) ([]string, error) {
+	var docIDs []string
+	if err := r.db.WithContext(ctx).
+		Model(&domain.NodeRelease{}).
+		Where("kb_id = ?", kbID).
+		Where("doc_id != ''").
+		Distinct().
+		Pluck("doc_id", &docIDs).Error; err != nil {
+		return nil, err
+	}
+	return docIDs, nil
+}
@@ -0,0 +1,85 @@
+package pg
+
+import (
+	"context"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/store/pg"
+)
+
+type NodeACLRepository struct {
+	db *pg.DB
+}
+
+func NewNodeACLRepository(db *pg.DB) *NodeACLRepository {
+	return &NodeACLRepository{db: db}
+}
+
+// SetNodeACLs set the rule of the nodes of kb, returns the count of nodes found
+func (r *NodeACLRepository) SetNodeACLs(ctx context.Context, kbID string, nodeIDs []string, access domain.NodeAccess, groups domain.NodeACLGroups, subtree bool) (int64, error) {
+	res := r.db.WithContext(ctx).Exec(
+		`INSERT INTO node_acls (node_id, kb_id, access, groups, subtree, created_at, updated_at)
+		SELECT id, kb_id, ?, ?, ?, NOW(), NOW() FROM nodes WHERE kb_id = ? AND id IN ?
+		ON CONFLICT (node_id) DO UPDATE SET access = EXCLUDED.access, groups = EXCLUDED.groups,
+			subtree = EXCLUDED.subtree, updated_at = EXCLUDED.updated_at`,
+		access, groups, subtree, kbID, nodeIDs,
+	)
+	return res.RowsAffected, res.Error
+}
+
+func (r *NodeACLRepository) RemoveNodeACLs(ctx context.Context, kbID string, nodeIDs []string) error {
+	return r.db.WithContext(ctx).
+		Where("kb_id = ?", kbID).
+		Where("node_id IN ?", nodeIDs).
+		Delete(&domain.NodeACL{}).Error
+}
+
+func (r *NodeACLRepository) GetNodeACLs(ctx context.Context, kbID string) ([]*domain.NodeACL, error) {
+	acls := []*domain.NodeACL{}
+	if err := r.db.WithContext(ctx).
+		Model(&domain.NodeACL{}).
+		Where("kb_id = ?", kbID).
+		Find(&acls).Error; err != nil {
+		return nil, err
+	}
+	return acls, nil
+}
+
+// GetNodeACLList rules of kb with their nodes, by node name
+func (r *NodeACLRepository) GetNodeACLList(ctx context.Context, kbID string) ([]*domain.NodeACLListItem, error) {
+	items := []*domain.NodeACLListItem{}
+	if err := r.db.WithContext(ctx).
+		Model(&domain.NodeACL{}).
+		Joins("JOIN nodes ON nodes.id = node_acls.node_id").
+		Where("node_acls.kb_id = ?", kbID).
+		Select("node_acls.*, nodes.name AS node_name, nodes.type AS node_type").
+		Order("nodes.name ASC").
+		Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// GetNodeParents parent ids of the nodes of kb, as edited and as in the latest release, for rules covering subtrees
+func (r *NodeACLRepository) GetNodeParents(ctx context.Context, kbID string) (map[string][]string, error) {
+	var rows []struct {
+		ID       string
+		ParentID string
+	}
+	if err := r.db.WithContext(ctx).Raw(
+		`SELECT id, parent_id FROM nodes WHERE kb_id = ? AND parent_id <> ''
+		UNION
+		SELECT node_releases.node_id AS id, node_releases.parent_id FROM kb_release_node_releases
+		JOIN node_releases ON node_releases.id = kb_release_node_releases.node_release_id
+		WHERE kb_release_node_releases.kb_id = ? AND node_releases.parent_id <> '' AND kb_release_node_releases.release_id =
+			(SELECT id FROM kb_releases WHERE kb_id = ? ORDER BY created_at DESC LIMIT 1)`,
+		kbID, kbID, kbID,
+	).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	parents := make(map[string][]string)
+	for _, row := range rows {
+		parents[row.ID] = append(parents[row.ID], row.ParentID)
+	}
+	return parents, nil
+}
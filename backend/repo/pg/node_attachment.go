@@ -0,0 +1,95 @@
+package pg
+
+import (
+	"context"
+
+	"gorm.io/gorm/clause"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/store/pg"
+)
+
+type NodeAttachmentRepository struct {
+	db *pg.DB
+}
+
+func NewNodeAttachmentRepository(db *pg.DB) *NodeAttachmentRepository {
+	return &NodeAttachmentRepository{db: db}
+}
+
+func (r *NodeAttachmentRepository) CreateNodeAttachment(ctx context.Context, attachment *domain.NodeAttachment) error {
+	return r.db.WithContext(ctx).Create(attachment).Error
+}
+
+func (r *NodeAttachmentRepository) GetNodeAttachmentList(ctx context.Context, kbID, nodeID string) ([]*domain.NodeAttachment, error) {
+	attachments := []*domain.NodeAttachment{}
+	if err := r.db.WithContext(ctx).
+		Where("kb_id = ?", kbID).
+		Where("node_id = ?", nodeID).
+		Order("created_at ASC").
+		Find(&attachments).Error; err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
+
+// DeleteNodeAttachment return the deleted attachment for object cleanup
+func (r *NodeAttachmentRepository) DeleteNodeAttachment(ctx context.Context, kbID, id string) (*domain.NodeAttachment, error) {
+	var attachments []*domain.NodeAttachment
+	if err := r.db.WithContext(ctx).
+		Where("id = ?", id).
+		Where("kb_id = ?", kbID).
+		Clauses(clause.Returning{}).
+		Delete(&attachments).Error; err != nil {
+		return nil, err
+	}
+	if len(attachments) == 0 {
+		return nil, domain.ErrNodeAttachmentNotFound
+	}
+	return attachments[0], nil
+}
+
+// DeleteNodeAttachmentsByNodeIDs return object keys of the deleted attachments
+func (r *NodeAttachmentRepository) DeleteNodeAttachmentsByNodeIDs(ctx context.Context, kbID string, nodeIDs []string) ([]string, error) {
+	var attachments []*domain.NodeAttachment
+	if err := r.db.WithContext(ctx).
+		Where("kb_id = ?", kbID).
+		Where("node_id IN ?", nodeIDs).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "key"}}}).
+		Delete(&attachments).Error; err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(attachments))
+	for _, attachment := range attachments {
+		keys = append(keys, attachment.Key)
+	}
+	return keys, nil
+}
+
+// DeleteNodeAttachmentsByKBID return object keys of the deleted attachments
+func (r *NodeAttachmentRepository) DeleteNodeAttachmentsByKBID(ctx context.Context, kbID string) ([]string, error) {
+	var attachments []*domain.NodeAttachment
+	if err := r.db.WithContext(ctx).
+		Where("kb_id = ?", kbID).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "key"}}}).
+		Delete(&attachments).Error; err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(attachments))
+	for _, attachment := range attachments {
+		keys = append(keys, attachment.Key)
+	}
+	return keys, nil
+}
+
+// GetKBAttachments attachments of all nodes of kb
+func (r *NodeAttachmentRepository) GetKBAttachments(ctx context.Context, kbID string) ([]*domain.NodeAttachment, error) {
+	attachments := []*domain.NodeAttachment{}
+	if err := r.db.WithContext(ctx).
+		Where("kb_id = ?", kbID).
+		Order("node_id, created_at ASC").
+		Find(&attachments).Error; err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
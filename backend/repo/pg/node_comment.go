@@ -0,0 +1,93 @@
+package pg
+
+import (
+	"context"
+	"time"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/store/pg"
+)
+
+type NodeCommentRepository struct {
+	db *pg.DB
+}
+
+func NewNodeCommentRepository(db *pg.DB) *NodeCommentRepository {
+	return &NodeCommentRepository{db: db}
+}
+
+func (r *NodeCommentRepository) CreateNodeComment(ctx context.Context, comment *domain.NodeComment) error {
+	return r.db.WithContext(ctx).Create(comment).Error
+}
+
+func (r *NodeCommentRepository) GetNodeComment(ctx context.Context, kbID, id string) (*domain.NodeComment, error) {
+	comment := &domain.NodeComment{}
+	if err := r.db.WithContext(ctx).
+		Where("id = ?", id).
+		Where("kb_id = ?", kbID).
+		First(comment).Error; err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// GetApprovedNodeComments approved comments of the node in posted order
+func (r *NodeCommentRepository) GetApprovedNodeComments(ctx context.Context, kbID, nodeID string) ([]*domain.NodeComment, error) {
+	comments := []*domain.NodeComment{}
+	if err := r.db.WithContext(ctx).
+		Where("kb_id = ?", kbID).
+		Where("node_id = ?", nodeID).
+		Where("status = ?", domain.NodeCommentStatusApproved).
+		Order("created_at ASC").
+		Find(&comments).Error; err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+func (r *NodeCommentRepository) GetNodeCommentList(ctx context.Context, req *domain.NodeCommentListReq) ([]*domain.NodeCommentListItem, uint64, error) {
+	query := r.db.WithContext(ctx).
+		Model(&domain.NodeComment{}).
+		Joins("LEFT JOIN nodes ON nodes.id = node_comments.node_id").
+		Where("node_comments.kb_id = ?", req.KBID)
+	if req.NodeID != "" {
+		query = query.Where("node_comments.node_id = ?", req.NodeID)
+	}
+	if req.Status != "" {
+		query = query.Where("node_comments.status = ?", req.Status)
+	}
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	comments := []*domain.NodeCommentListItem{}
+	if err := query.
+		Select("node_comments.*, nodes.name as node_name").
+		Offset(req.Offset()).
+		Limit(req.Limit()).
+		Order("node_comments.created_at DESC").
+		Find(&comments).Error; err != nil {
+		return nil, 0, err
+	}
+	return comments, uint64(count), nil
+}
+
+func (r *NodeCommentRepository) UpdateNodeCommentsStatus(ctx context.Context, kbID string, ids []string, status domain.NodeCommentStatus, moderatedBy string) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.NodeComment{}).
+		Where("kb_id = ?", kbID).
+		Where("id IN ?", ids).
+		Updates(map[string]any{
+			"status":       status,
+			"moderated_by": moderatedBy,
+			"moderated_at": time.Now(),
+		}).Error
+}
+
+// DeleteNodeComment delete the comment and its replies
+func (r *NodeCommentRepository) DeleteNodeComment(ctx context.Context, kbID, id string) error {
+	return r.db.WithContext(ctx).
+		Where("kb_id = ?", kbID).
+		Where("id = ? OR root_id = ? OR parent_id = ?", id, id, id).
+		Delete(&domain.NodeComment{}).Error
+}
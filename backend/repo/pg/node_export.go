@@ -0,0 +1,60 @@
+package pg
+
+import (
+	"context"
+	"time"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/store/pg"
+)
+
+type NodeExportRepository struct {
+	db *pg.DB
+}
+
+func NewNodeExportRepository(db *pg.DB) *NodeExportRepository {
+	return &NodeExportRepository{db: db}
+}
+
+func (r *NodeExportRepository) CreateExportJob(ctx context.Context, job *domain.NodeExportJob) error {
+	return r.db.WithContext(ctx).Create(job).Error
+}
+
+func (r *NodeExportRepository) GetExportJob(ctx context.Context, kbID, id string) (*domain.NodeExportJob, error) {
+	job := &domain.NodeExportJob{}
+	query := r.db.WithContext(ctx).Model(&domain.NodeExportJob{}).Where("id = ?", id)
+	if kbID != "" {
+		query = query.Where("kb_id = ?", kbID)
+	}
+	if err := query.First(job).Error; err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func (r *NodeExportRepository) UpdateExportJob(ctx context.Context, id string, updates map[string]any) error {
+	updates["updated_at"] = time.Now()
+	return r.db.WithContext(ctx).
+		Model(&domain.NodeExportJob{}).
+		Where("id = ?", id).
+		Updates(updates).Error
+}
+
+func (r *NodeExportRepository) GetExportJobList(ctx context.Context, req *domain.NodeExportJobListReq) ([]*domain.NodeExportJob, uint64, error) {
+	query := r.db.WithContext(ctx).
+		Model(&domain.NodeExportJob{}).
+		Where("kb_id = ?", req.KBID)
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	jobs := []*domain.NodeExportJob{}
+	if err := query.
+		Offset(req.Offset()).
+		Limit(req.Limit()).
+		Order("created_at DESC").
+		Find(&jobs).Error; err != nil {
+		return nil, 0, err
+	}
+	return jobs, uint64(count), nil
+}
@@ -0,0 +1,53 @@
+package pg
+
+import (
+	"context"
+	"time"
+
+	"github.com/chaitin/panda-wiki/domain"
+)
+
+// GetStaleNodeList documents of kb neither updated nor reviewed since before, with their views since viewsSince
+func (r *NodeRepository) GetStaleNodeList(ctx context.Context, req *domain.NodeStaleListReq, before, viewsSince time.Time) ([]*domain.NodeStaleItem, uint64, error) {
+	query := r.db.WithContext(ctx).
+		Model(&domain.Node{}).
+		Where("nodes.kb_id = ?", req.KBID).
+		Where("nodes.type = ?", domain.NodeTypeDocument).
+		Where("GREATEST(nodes.updated_at, nodes.reviewed_at) < ?", before)
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	order := "fresh_at ASC, nodes.id ASC"
+	if req.Order == domain.NodeStaleOrderViews {
+		order = "views DESC, " + order
+	}
+	nodes := []*domain.NodeStaleItem{}
+	if err := query.
+		Joins("LEFT JOIN users ON users.id = nodes.reviewed_by").
+		Joins(`LEFT JOIN (SELECT node_id, SUM(views) AS views FROM stat_node_daily
+			WHERE kb_id = ? AND date >= ? GROUP BY node_id) node_views ON node_views.node_id = nodes.id`, req.KBID, viewsSince).
+		Select(`nodes.id, nodes.name, nodes.parent_id, nodes.updated_at, nodes.reviewed_at,
+			COALESCE(users.account, '') AS reviewed_by, COALESCE(node_views.views, 0) AS views,
+			GREATEST(nodes.updated_at, nodes.reviewed_at) AS fresh_at`).
+		Order(order).
+		Offset(req.Offset()).
+		Limit(req.Limit()).
+		Find(&nodes).Error; err != nil {
+		return nil, 0, err
+	}
+	return nodes, uint64(count), nil
+}
+
+// MarkNodesReviewed set the review time and reviewer of the nodes of kb, updated_at is left as is
+func (r *NodeRepository) MarkNodesReviewed(ctx context.Context, kbID string, nodeIDs []string, userID string) (int64, error) {
+	res := r.db.WithContext(ctx).
+		Model(&domain.Node{}).
+		Where("kb_id = ?", kbID).
+		Where("id IN ?", nodeIDs).
+		UpdateColumns(map[string]any{
+			"reviewed_at": time.Now(),
+			"reviewed_by": userID,
+		})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,69 @@
+package pg
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/store/pg"
+)
+
+type NodeLinkRepository struct {
+	db *pg.DB
+}
+
+func NewNodeLinkRepository(db *pg.DB) *NodeLinkRepository {
+	return &NodeLinkRepository{db: db}
+}
+
+// saveNodeLinks replace outgoing links of the node by links parsed from its content
+func saveNodeLinks(tx *gorm.DB, kbID, nodeID, content string) error {
+	if err := tx.Where("source_id = ?", nodeID).Delete(&domain.NodeLink{}).Error; err != nil {
+		return err
+	}
+	targetIDs := domain.ParseNodeLinks(nodeID, content)
+	if len(targetIDs) == 0 {
+		return nil
+	}
+	links := make([]*domain.NodeLink, 0, len(targetIDs))
+	for _, targetID := range targetIDs {
+		links = append(links, &domain.NodeLink{KBID: kbID, SourceID: nodeID, TargetID: targetID})
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&links).Error
+}
+
+// GetBacklinks nodes linking to the node
+func (r *NodeLinkRepository) GetBacklinks(ctx context.Context, kbID, nodeID string) ([]*domain.NodeBacklinkResp, error) {
+	backlinks := []*domain.NodeBacklinkResp{}
+	if err := r.db.WithContext(ctx).
+		Model(&domain.NodeLink{}).
+		Joins("JOIN nodes ON nodes.id = node_links.source_id").
+		Where("node_links.kb_id = ?", kbID).
+		Where("node_links.target_id = ?", nodeID).
+		Select("nodes.id, nodes.name, nodes.type, nodes.status, nodes.visibility, nodes.meta->>'emoji' as emoji").
+		Order("nodes.name ASC").
+		Find(&backlinks).Error; err != nil {
+		return nil, err
+	}
+	return backlinks, nil
+}
+
+// GetBrokenLinks links of the kb to nodes not existing any more
+func (r *NodeLinkRepository) GetBrokenLinks(ctx context.Context, kbID string) ([]*domain.BrokenNodeLinkResp, error) {
+	links := []*domain.BrokenNodeLinkResp{}
+	if err := r.db.WithContext(ctx).
+		Model(&domain.NodeLink{}).
+		Joins("JOIN nodes AS sources ON sources.id = node_links.source_id").
+		// links to nodes of other kbs, e.g. to an identical document linked on import, are not broken
+		Joins("LEFT JOIN nodes AS targets ON targets.id = node_links.target_id").
+		Where("node_links.kb_id = ?", kbID).
+		Where("targets.id IS NULL").
+		Select("node_links.source_id, sources.name as source_name, node_links.target_id").
+		Order("sources.name ASC, node_links.target_id ASC").
+		Find(&links).Error; err != nil {
+		return nil, err
+	}
+	return links, nil
+}
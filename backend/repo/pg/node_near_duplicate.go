@@ -0,0 +1,81 @@
+package pg
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/store/pg"
+)
+
+type NearDuplicateRepository struct {
+	db *pg.DB
+}
+
+func NewNearDuplicateRepository(db *pg.DB) *NearDuplicateRepository {
+	return &NearDuplicateRepository{db: db}
+}
+
+// GetDocumentContents id and content of document nodes of the kb
+func (r *NearDuplicateRepository) GetDocumentContents(ctx context.Context, kbID string) ([]*domain.Node, error) {
+	var nodes []*domain.Node
+	if err := r.db.WithContext(ctx).
+		Model(&domain.Node{}).
+		Where("kb_id = ?", kbID).
+		Where("type = ?", domain.NodeTypeDocument).
+		Select("id, content").
+		Find(&nodes).Error; err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
+
+// ReplaceKBNearDuplicates save pairs found by a detection started at detectedAt, pairs not found anymore are removed.
+// dismissed pairs found again stay dismissed
+func (r *NearDuplicateRepository) ReplaceKBNearDuplicates(ctx context.Context, kbID string, pairs []*domain.NodeNearDuplicate, detectedAt time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(pairs) > 0 {
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "node_id"}, {Name: "other_node_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"similarity", "detected_at"}),
+			}).CreateInBatches(&pairs, 500).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Where("kb_id = ?", kbID).
+			Where("detected_at < ?", detectedAt).
+			Delete(&domain.NodeNearDuplicate{}).Error
+	})
+}
+
+// GetNearDuplicates pairs of the kb with names of their nodes, most similar first
+func (r *NearDuplicateRepository) GetNearDuplicates(ctx context.Context, req *domain.NearDuplicateListReq) ([]*domain.NearDuplicateResp, error) {
+	pairs := []*domain.NearDuplicateResp{}
+	query := r.db.WithContext(ctx).
+		Model(&domain.NodeNearDuplicate{}).
+		Joins("JOIN nodes ON nodes.id = node_near_duplicates.node_id").
+		Joins("JOIN nodes AS other_nodes ON other_nodes.id = node_near_duplicates.other_node_id").
+		Where("node_near_duplicates.kb_id = ?", req.KBID)
+	if !req.IncludeDismissed {
+		query = query.Where("NOT node_near_duplicates.dismissed")
+	}
+	if err := query.
+		Select("node_near_duplicates.node_id, nodes.name AS node_name, node_near_duplicates.other_node_id, other_nodes.name AS other_node_name, node_near_duplicates.similarity, node_near_duplicates.dismissed, node_near_duplicates.detected_at").
+		Order("node_near_duplicates.similarity DESC, nodes.name ASC").
+		Find(&pairs).Error; err != nil {
+		return nil, err
+	}
+	return pairs, nil
+}
+
+// DismissNearDuplicate mark the pair as not redundant, false if the pair is not found
+func (r *NearDuplicateRepository) DismissNearDuplicate(ctx context.Context, kbID, nodeID, otherNodeID string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&domain.NodeNearDuplicate{}).
+		Where("kb_id = ? AND node_id = ? AND other_node_id = ?", kbID, nodeID, otherNodeID).
+		Update("dismissed", true)
+	return result.RowsAffected > 0, result.Error
+}
@@ -0,0 +1,69 @@
+package pg
+
+import (
+	"context"
+	"time"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/store/pg"
+)
+
+type NodeOwnerRepository struct {
+	db *pg.DB
+}
+
+func NewNodeOwnerRepository(db *pg.DB) *NodeOwnerRepository {
+	return &NodeOwnerRepository{db: db}
+}
+
+// SetNodeOwners assign the owner to the nodes of kb, reminders start over. returns the count of nodes found
+func (r *NodeOwnerRepository) SetNodeOwners(ctx context.Context, kbID string, nodeIDs []string, ownerID string, intervalDays int) (int64, error) {
+	res := r.db.WithContext(ctx).Exec(
+		`INSERT INTO node_owners (node_id, kb_id, owner_id, review_interval_days, created_at, updated_at)
+		SELECT id, kb_id, ?, ?, NOW(), NOW() FROM nodes WHERE kb_id = ? AND id IN ?
+		ON CONFLICT (node_id) DO UPDATE SET owner_id = EXCLUDED.owner_id, review_interval_days = EXCLUDED.review_interval_days,
+			reminded_at = NULL, updated_at = EXCLUDED.updated_at`,
+		ownerID, intervalDays, kbID, nodeIDs,
+	)
+	return res.RowsAffected, res.Error
+}
+
+func (r *NodeOwnerRepository) RemoveNodeOwners(ctx context.Context, kbID string, nodeIDs []string) error {
+	return r.db.WithContext(ctx).
+		Where("kb_id = ?", kbID).
+		Where("node_id IN ?", nodeIDs).
+		Delete(&domain.NodeOwner{}).Error
+}
+
+// GetOverdueNodes owned documents of kb neither updated nor reviewed within their review interval at now,
+// by owner account then most overdue first. all owners if ownerID is empty
+func (r *NodeOwnerRepository) GetOverdueNodes(ctx context.Context, kbID, ownerID string, defaultIntervalDays int, now time.Time) ([]*domain.OverdueNode, error) {
+	query := r.db.WithContext(ctx).
+		Model(&domain.NodeOwner{}).
+		Joins("JOIN nodes ON nodes.id = node_owners.node_id").
+		Joins("LEFT JOIN users ON users.id = node_owners.owner_id").
+		Where("node_owners.kb_id = ?", kbID).
+		Where("nodes.type = ?", domain.NodeTypeDocument).
+		Where("GREATEST(nodes.updated_at, nodes.reviewed_at) + make_interval(days => COALESCE(NULLIF(node_owners.review_interval_days, 0), ?)) < ?",
+			defaultIntervalDays, now)
+	if ownerID != "" {
+		query = query.Where("node_owners.owner_id = ?", ownerID)
+	}
+	nodes := []*domain.OverdueNode{}
+	if err := query.
+		Select(`nodes.id, nodes.name, node_owners.owner_id, COALESCE(users.account, '') AS owner_account,
+			GREATEST(nodes.updated_at, nodes.reviewed_at) AS fresh_at,
+			COALESCE(NULLIF(node_owners.review_interval_days, 0), ?) AS review_interval_days, node_owners.reminded_at`, defaultIntervalDays).
+		Order("owner_account ASC, node_owners.owner_id ASC, fresh_at ASC").
+		Find(&nodes).Error; err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
+
+func (r *NodeOwnerRepository) MarkReminded(ctx context.Context, nodeIDs []string, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.NodeOwner{}).
+		Where("node_id IN ?", nodeIDs).
+		UpdateColumn("reminded_at", at).Error
+}
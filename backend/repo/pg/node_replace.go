@@ -0,0 +1,239 @@
+package pg
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/store/pg"
+)
+
+type NodeReplaceRepository struct {
+	db *pg.DB
+}
+
+func NewNodeReplaceRepository(db *pg.DB) *NodeReplaceRepository {
+	return &NodeReplaceRepository{db: db}
+}
+
+func (r *NodeReplaceRepository) CreateReplaceJob(ctx context.Context, job *domain.NodeReplaceJob) error {
+	return r.db.WithContext(ctx).Create(job).Error
+}
+
+func (r *NodeReplaceRepository) GetReplaceJob(ctx context.Context, kbID, id string) (*domain.NodeReplaceJob, error) {
+	job := &domain.NodeReplaceJob{}
+	query := r.db.WithContext(ctx).Model(&domain.NodeReplaceJob{}).Where("id = ?", id)
+	if kbID != "" {
+		query = query.Where("kb_id = ?", kbID)
+	}
+	if err := query.First(job).Error; err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func (r *NodeReplaceRepository) UpdateReplaceJob(ctx context.Context, id string, updates map[string]any) error {
+	updates["updated_at"] = time.Now()
+	return r.db.WithContext(ctx).
+		Model(&domain.NodeReplaceJob{}).
+		Where("id = ?", id).
+		Updates(updates).Error
+}
+
+// ClaimReplaceJob mark a pending job running, false if it is not pending so it runs only once
+func (r *NodeReplaceRepository) ClaimReplaceJob(ctx context.Context, id string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&domain.NodeReplaceJob{}).
+		Where("id = ?", id).
+		Where("status = ?", domain.NodeReplaceJobStatusPending).
+		Updates(map[string]any{
+			"status":     domain.NodeReplaceJobStatusRunning,
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// RecoverRunningReplaceJobs settle jobs left running by a consumer which stopped, return those set back to pending.
+// contents are replaced in one transaction with the versions, so a job with versions was done and succeeded,
+// one without changed nothing and can run again
+func (r *NodeReplaceRepository) RecoverRunningReplaceJobs(ctx context.Context) ([]*domain.NodeReplaceJob, error) {
+	var pending []*domain.NodeReplaceJob
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var jobs []*domain.NodeReplaceJob
+		if err := tx.Model(&domain.NodeReplaceJob{}).
+			Where("status = ?", domain.NodeReplaceJobStatusRunning).
+			Find(&jobs).Error; err != nil {
+			return err
+		}
+		for _, job := range jobs {
+			var nodeCount int64
+			if err := tx.Model(&domain.NodeVersion{}).
+				Where("job_id = ?", job.ID).
+				Count(&nodeCount).Error; err != nil {
+				return err
+			}
+			updates := map[string]any{
+				"status":     domain.NodeReplaceJobStatusPending,
+				"updated_at": time.Now(),
+			}
+			if nodeCount > 0 {
+				updates["status"] = domain.NodeReplaceJobStatusSucceeded
+				updates["node_count"] = nodeCount
+			}
+			if err := tx.Model(&domain.NodeReplaceJob{}).
+				Where("id = ?", job.ID).
+				Where("status = ?", domain.NodeReplaceJobStatusRunning).
+				Updates(updates).Error; err != nil {
+				return err
+			}
+			if nodeCount == 0 {
+				pending = append(pending, job)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pending, nil
+}
+
+func (r *NodeReplaceRepository) GetReplaceJobList(ctx context.Context, req *domain.NodeReplaceJobListReq) ([]*domain.NodeReplaceJob, uint64, error) {
+	query := r.db.WithContext(ctx).
+		Model(&domain.NodeReplaceJob{}).
+		Where("kb_id = ?", req.KBID)
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	jobs := []*domain.NodeReplaceJob{}
+	if err := query.
+		Offset(req.Offset()).
+		Limit(req.Limit()).
+		Order("created_at DESC").
+		Find(&jobs).Error; err != nil {
+		return nil, 0, err
+	}
+	return jobs, uint64(count), nil
+}
+
+// GetReplaceDocuments documents of kb in the folders and their sub folders, all documents if no folders
+func (r *NodeReplaceRepository) GetReplaceDocuments(ctx context.Context, kbID string, folderIDs []string) ([]*domain.Node, error) {
+	var nodes []*domain.Node
+	if len(folderIDs) == 0 {
+		if err := r.db.WithContext(ctx).
+			Model(&domain.Node{}).
+			Where("kb_id = ?", kbID).
+			Where("type = ?", domain.NodeTypeDocument).
+			Select("id, kb_id, name, content").
+			Order("name").
+			Find(&nodes).Error; err != nil {
+			return nil, err
+		}
+		return nodes, nil
+	}
+	if err := r.db.WithContext(ctx).Raw(`
+		WITH RECURSIVE descendants AS (
+			SELECT id, type, name, content, 0 AS depth FROM nodes WHERE kb_id = ? AND id IN ?
+			UNION ALL
+			SELECT n.id, n.type, n.name, n.content, d.depth + 1 FROM nodes n
+			JOIN descendants d ON n.parent_id = d.id
+			WHERE n.kb_id = ? AND d.depth < 64
+		)
+		SELECT DISTINCT id, ? AS kb_id, name, content FROM descendants WHERE type = ? ORDER BY name`,
+		kbID, folderIDs, kbID, kbID, domain.NodeTypeDocument).
+		Scan(&nodes).Error; err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
+
+// ReplaceNodeContents save versions of nodes and write replaced content, nodes become drafts.
+// nodes edited since their content was read are left alone and returned, the edit is not overwritten
+func (r *NodeReplaceRepository) ReplaceNodeContents(ctx context.Context, versions []*domain.NodeVersion) ([]string, error) {
+	skippedNodeIDs := []string{}
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, version := range versions {
+			result := tx.Model(&domain.Node{}).
+				Where("id = ?", version.NodeID).
+				Where("kb_id = ?", version.KBID).
+				Where("content = ?", version.Content).
+				Updates(map[string]any{
+					"content":      version.NewContent,
+					"content_hash": domain.ContentHash(version.NewContent),
+					"status":       domain.NodeStatusDraft,
+					"updated_at":   time.Now(),
+				})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				skippedNodeIDs = append(skippedNodeIDs, version.NodeID)
+				continue
+			}
+			if err := tx.Create(version).Error; err != nil {
+				return err
+			}
+			if err := saveNodeLinks(tx, version.KBID, version.NodeID, version.NewContent); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return skippedNodeIDs, nil
+}
+
+// RollbackNodeContents restore content of nodes changed by the job, unless edited after the job
+func (r *NodeReplaceRepository) RollbackNodeContents(ctx context.Context, kbID, jobID string) (*domain.NodeReplaceRollbackResp, error) {
+	resp := &domain.NodeReplaceRollbackResp{SkippedNodeIDs: []string{}}
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var versions []*domain.NodeVersion
+		if err := tx.Model(&domain.NodeVersion{}).
+			Where("kb_id = ?", kbID).
+			Where("job_id = ?", jobID).
+			Find(&versions).Error; err != nil {
+			return err
+		}
+		for _, version := range versions {
+			result := tx.Model(&domain.Node{}).
+				Where("id = ?", version.NodeID).
+				Where("kb_id = ?", kbID).
+				Where("content = ?", version.NewContent).
+				Updates(map[string]any{
+					"content":      version.Content,
+					"content_hash": domain.ContentHash(version.Content),
+					"status":       domain.NodeStatusDraft,
+					"updated_at":   time.Now(),
+				})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				resp.SkippedNodeIDs = append(resp.SkippedNodeIDs, version.NodeID)
+				continue
+			}
+			if err := saveNodeLinks(tx, kbID, version.NodeID, version.Content); err != nil {
+				return err
+			}
+			resp.RestoredCount++
+		}
+		return tx.Model(&domain.NodeReplaceJob{}).
+			Where("id = ?", jobID).
+			Updates(map[string]any{
+				"status":     domain.NodeReplaceJobStatusRolledBack,
+				"updated_at": time.Now(),
+			}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
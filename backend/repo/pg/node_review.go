@@ -0,0 +1,105 @@
+package pg
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/store/pg"
+)
+
+type NodeReviewRepository struct {
+	db *pg.DB
+}
+
+func NewNodeReviewRepository(db *pg.DB) *NodeReviewRepository {
+	return &NodeReviewRepository{db: db}
+}
+
+// CreateNodeReview create a pending review, earlier pending reviews of the node are cancelled
+func (r *NodeReviewRepository) CreateNodeReview(ctx context.Context, review *domain.NodeReview) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.NodeReview{}).
+			Where("node_id = ?", review.NodeID).
+			Where("status = ?", domain.NodeReviewStatusPending).
+			Update("status", domain.NodeReviewStatusCancelled).Error; err != nil {
+			return err
+		}
+		return tx.Create(review).Error
+	})
+}
+
+func (r *NodeReviewRepository) GetNodeReview(ctx context.Context, kbID, id string) (*domain.NodeReview, error) {
+	review := &domain.NodeReview{}
+	if err := r.db.WithContext(ctx).
+		Where("id = ?", id).
+		Where("kb_id = ?", kbID).
+		First(review).Error; err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// FinishNodeReview set result of a pending review, returns false if the review is no longer pending
+func (r *NodeReviewRepository) FinishNodeReview(ctx context.Context, id string, status domain.NodeReviewStatus, reviewerID, comment string) (bool, error) {
+	res := r.db.WithContext(ctx).
+		Model(&domain.NodeReview{}).
+		Where("id = ?", id).
+		Where("status = ?", domain.NodeReviewStatusPending).
+		Updates(map[string]any{
+			"status":         status,
+			"reviewer_id":    reviewerID,
+			"review_comment": comment,
+			"reviewed_at":    time.Now(),
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+func (r *NodeReviewRepository) GetNodeReviewList(ctx context.Context, req *domain.NodeReviewListReq) ([]*domain.NodeReview, uint64, error) {
+	query := r.db.WithContext(ctx).
+		Model(&domain.NodeReview{}).
+		Where("kb_id = ?", req.KBID)
+	if req.NodeID != "" {
+		query = query.Where("node_id = ?", req.NodeID)
+	}
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	reviews := []*domain.NodeReview{}
+	if err := query.
+		Offset(req.Offset()).
+		Limit(req.Limit()).
+		Order("created_at DESC").
+		Find(&reviews).Error; err != nil {
+		return nil, 0, err
+	}
+	return reviews, uint64(count), nil
+}
+
+// GetUnapprovedNodeIDs nodes of ids whose current version has no approved review
+func (r *NodeReviewRepository) GetUnapprovedNodeIDs(ctx context.Context, kbID string, nodeIDs []string) ([]string, error) {
+	var ids []string
+	if err := r.db.WithContext(ctx).
+		Model(&domain.Node{}).
+		Where("kb_id = ?", kbID).
+		Where("id IN ?", nodeIDs).
+		Where(`NOT EXISTS (
+			SELECT 1 FROM node_reviews
+			WHERE node_reviews.node_id = nodes.id
+			AND node_reviews.status = ?
+			AND node_reviews.node_updated_at = nodes.updated_at
+		)`, domain.NodeReviewStatusApproved).
+		Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
@@ -0,0 +1,58 @@
+package pg
+
+import (
+	"context"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/store/pg"
+)
+
+type NodeTemplateRepository struct {
+	db *pg.DB
+}
+
+func NewNodeTemplateRepository(db *pg.DB) *NodeTemplateRepository {
+	return &NodeTemplateRepository{db: db}
+}
+
+func (r *NodeTemplateRepository) CreateNodeTemplate(ctx context.Context, template *domain.NodeTemplate) error {
+	return r.db.WithContext(ctx).Create(template).Error
+}
+
+func (r *NodeTemplateRepository) UpdateNodeTemplate(ctx context.Context, template *domain.NodeTemplate) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.NodeTemplate{}).
+		Where("id = ?", template.ID).
+		Where("kb_id = ?", template.KBID).
+		Select("name", "description", "category", "type", "emoji", "content", "children", "updated_at").
+		Updates(template).Error
+}
+
+func (r *NodeTemplateRepository) DeleteNodeTemplate(ctx context.Context, kbID, id string) error {
+	return r.db.WithContext(ctx).
+		Where("id = ?", id).
+		Where("kb_id = ?", kbID).
+		Delete(&domain.NodeTemplate{}).Error
+}
+
+func (r *NodeTemplateRepository) GetNodeTemplate(ctx context.Context, kbID, id string) (*domain.NodeTemplate, error) {
+	template := &domain.NodeTemplate{}
+	if err := r.db.WithContext(ctx).
+		Where("id = ?", id).
+		Where("kb_id = ?", kbID).
+		First(template).Error; err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+func (r *NodeTemplateRepository) GetNodeTemplateList(ctx context.Context, kbID string) ([]*domain.NodeTemplate, error) {
+	templates := []*domain.NodeTemplate{}
+	if err := r.db.WithContext(ctx).
+		Where("kb_id = ?", kbID).
+		Order("category ASC, created_at ASC").
+		Find(&templates).Error; err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
@@ -0,0 +1,77 @@
+package pg
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/store/pg"
+)
+
+type PromptTemplateRepository struct {
+	db *pg.DB
+}
+
+func NewPromptTemplateRepository(db *pg.DB) *PromptTemplateRepository {
+	return &PromptTemplateRepository{db: db}
+}
+
+// CreateAppPromptTemplate save the template as the next version of its app
+func (r *PromptTemplateRepository) CreateAppPromptTemplate(ctx context.Context, template *domain.AppPromptTemplate) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var version int
+		if err := tx.Model(&domain.AppPromptTemplate{}).
+			Where("app_id = ?", template.AppID).
+			Select("COALESCE(MAX(version), 0)").
+			Scan(&version).Error; err != nil {
+			return err
+		}
+		template.Version = version + 1
+		return tx.Create(template).Error
+	})
+	// saved by someone else at the same time
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return domain.ErrPromptTemplateConflict
+	}
+	return err
+}
+
+// GetLatestAppPromptTemplate template in use by the app, nil if it never saved one
+func (r *PromptTemplateRepository) GetLatestAppPromptTemplate(ctx context.Context, appID string) (*domain.AppPromptTemplate, error) {
+	template := &domain.AppPromptTemplate{}
+	if err := r.db.WithContext(ctx).
+		Where("app_id = ?", appID).
+		Order("version DESC").
+		First(template).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return template, nil
+}
+
+func (r *PromptTemplateRepository) GetAppPromptTemplate(ctx context.Context, appID string, version int) (*domain.AppPromptTemplate, error) {
+	template := &domain.AppPromptTemplate{}
+	if err := r.db.WithContext(ctx).
+		Where("app_id = ?", appID).
+		Where("version = ?", version).
+		First(template).Error; err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// GetAppPromptTemplateVersions versions of the app, latest first
+func (r *PromptTemplateRepository) GetAppPromptTemplateVersions(ctx context.Context, appID string) ([]*domain.AppPromptTemplate, error) {
+	templates := []*domain.AppPromptTemplate{}
+	if err := r.db.WithContext(ctx).
+		Where("app_id = ?", appID).
+		Order("version DESC").
+		Find(&templates).Error; err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
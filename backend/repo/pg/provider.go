@@ -17,4 +17,35 @@ var ProviderSet = wire.NewSet(
 	NewModelRepository,
 	NewKnowledgeBaseRepository,
 	NewStatRepository,
+	NewCronRepository,
+	NewNodeReplaceRepository,
+	NewSettingRepository,
+	NewNodeReviewRepository,
+	NewWebhookRepository,
+	NewNodeTemplateRepository,
+	NewNodeAttachmentRepository,
+	NewNodeLinkRepository,
+	NewExternalLinkRepository,
+	NewNearDuplicateRepository,
+	NewNodeCommentRepository,
+	NewNodeExportRepository,
+	NewImportSourceRepository,
+	NewAnomalyRepository,
+	NewRetentionRepository,
+	NewNodeOwnerRepository,
+	NewDataExportRepository,
+	NewConversationRescoreRepository,
+	NewEvalRepository,
+	NewAnswerCacheRepository,
+	NewGuardrailRepository,
+	NewGlossaryRepository,
+	NewTelemetryRepository,
+	NewAuditLogRepository,
+	NewSessionRepository,
+	NewNodeACLRepository,
+	NewReaderRepository,
+	NewKBMemberRepository,
+	NewAPITokenRepository,
+	NewTwoFactorRepository,
+	NewPromptTemplateRepository,
 )
@@ -0,0 +1,191 @@
+package pg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/store/pg"
+)
+
+type ReaderRepository struct {
+	db *pg.DB
+}
+
+func NewReaderRepository(db *pg.DB) *ReaderRepository {
+	return &ReaderRepository{db: db}
+}
+
+// CreateReader create reader with the plain password of the reader hashed
+func (r *ReaderRepository) CreateReader(ctx context.Context, reader *domain.Reader) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(reader.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	reader.Password = string(hashedPassword)
+	return r.db.WithContext(ctx).Create(reader).Error
+}
+
+func (r *ReaderRepository) EmailExists(ctx context.Context, kbID, email string) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).
+		Model(&domain.Reader{}).
+		Where("kb_id = ?", kbID).
+		Where("email = ?", email).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *ReaderRepository) GetReader(ctx context.Context, kbID, id string) (*domain.Reader, error) {
+	var reader domain.Reader
+	if err := r.db.WithContext(ctx).
+		Where("kb_id = ?", kbID).
+		Where("id = ?", id).
+		First(&reader).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrReaderNotFound
+		}
+		return nil, err
+	}
+	return &reader, nil
+}
+
+func (r *ReaderRepository) GetReaderList(ctx context.Context, req *domain.ReaderListReq) ([]*domain.Reader, uint64, error) {
+	query := r.db.WithContext(ctx).
+		Model(&domain.Reader{}).
+		Where("kb_id = ?", req.KBID)
+	if req.Search != "" {
+		pattern := "%" + strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(req.Search) + "%"
+		query = query.Where("email ILIKE ? OR name ILIKE ?", pattern, pattern)
+	}
+	if req.Group != "" {
+		group, err := json.Marshal([]string{req.Group})
+		if err != nil {
+			return nil, 0, err
+		}
+		query = query.Where("groups @> ?::jsonb", string(group))
+	}
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	readers := []*domain.Reader{}
+	if err := query.
+		Offset(req.Offset()).
+		Limit(req.Limit()).
+		Order("created_at DESC").
+		Find(&readers).Error; err != nil {
+		return nil, 0, err
+	}
+	return readers, uint64(count), nil
+}
+
+// UpdateReader update name, groups and status of the reader, and the password if not empty
+func (r *ReaderRepository) UpdateReader(ctx context.Context, req *domain.UpdateReaderReq) error {
+	updates := map[string]any{
+		"name":       req.Name,
+		"groups":     domain.NodeACLGroups(req.Groups),
+		"disabled":   req.Disabled,
+		"updated_at": time.Now(),
+	}
+	if req.Password != "" {
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return fmt.Errorf("failed to hash password: %w", err)
+		}
+		updates["password"] = string(hashedPassword)
+	}
+	res := r.db.WithContext(ctx).
+		Model(&domain.Reader{}).
+		Where("kb_id = ?", req.KBID).
+		Where("id = ?", req.ID).
+		Updates(updates)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrReaderNotFound
+	}
+	return nil
+}
+
+func (r *ReaderRepository) DeleteReader(ctx context.Context, kbID, id string) error {
+	return r.db.WithContext(ctx).
+		Where("kb_id = ?", kbID).
+		Where("id = ?", id).
+		Delete(&domain.Reader{}).Error
+}
+
+// VerifyReader reader of the email with the password, the same error is returned for unknown emails and wrong passwords
+func (r *ReaderRepository) VerifyReader(ctx context.Context, kbID, email, password string) (*domain.Reader, error) {
+	var reader domain.Reader
+	if err := r.db.WithContext(ctx).
+		Where("kb_id = ?", kbID).
+		Where("email = ?", email).
+		First(&reader).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrReaderLoginFailed
+		}
+		return nil, err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(reader.Password), []byte(password)); err != nil {
+		return nil, domain.ErrReaderLoginFailed
+	}
+	return &reader, nil
+}
+
+func (r *ReaderRepository) UpdateLastLogin(ctx context.Context, id string, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.Reader{}).
+		Where("id = ?", id).
+		Update("last_login_at", at).Error
+}
+
+// GetReaderByLDAPDN reader of the kb logged in with the ldap directory before, nil if none
+func (r *ReaderRepository) GetReaderByLDAPDN(ctx context.Context, kbID, dn string) (*domain.Reader, error) {
+	var readers []*domain.Reader
+	if err := r.db.WithContext(ctx).
+		Where("kb_id = ?", kbID).
+		Where("ldap_dn = ?", dn).
+		Limit(1).
+		Find(&readers).Error; err != nil {
+		return nil, err
+	}
+	if len(readers) == 0 {
+		return nil, nil
+	}
+	return readers[0], nil
+}
+
+// GetLDAPReaders readers of all kbs from the ldap directory, whose groups are synced
+func (r *ReaderRepository) GetLDAPReaders(ctx context.Context) ([]*domain.Reader, error) {
+	var readers []*domain.Reader
+	if err := r.db.WithContext(ctx).Where("ldap_dn <> ''").Find(&readers).Error; err != nil {
+		return nil, err
+	}
+	return readers, nil
+}
+
+// SyncLDAPReader set the groups of the directory on the reader, disable it if it left the directory
+func (r *ReaderRepository) SyncLDAPReader(ctx context.Context, id string, groups []string, disabled bool) error {
+	updates := map[string]any{
+		"groups":     domain.NodeACLGroups(groups),
+		"updated_at": time.Now(),
+	}
+	if disabled {
+		updates["disabled"] = true
+	}
+	return r.db.WithContext(ctx).
+		Model(&domain.Reader{}).
+		Where("id = ?", id).
+		Updates(updates).Error
+}
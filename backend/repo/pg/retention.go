@@ -0,0 +1,131 @@
+package pg
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/store/pg"
+)
+
+// RetentionRepository removal of data older than its retention
+type RetentionRepository struct {
+	db *pg.DB
+}
+
+func NewRetentionRepository(db *pg.DB) *RetentionRepository {
+	return &RetentionRepository{db: db}
+}
+
+// RemoveConversations remove conversations started before the time with their messages, references and transcript emails
+func (r *RetentionRepository) RemoveConversations(ctx context.Context, before time.Time) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		ids := tx.Model(&domain.Conversation{}).Select("id").Where("created_at < ?", before)
+		if err := tx.Where("conversation_id IN (?)", ids).Delete(&domain.ConversationReference{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("conversation_id IN (?)", ids).Delete(&domain.ConversationMessage{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("conversation_id IN (?)", ids).Delete(&domain.ConversationTranscriptEmail{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("created_at < ?", before).Delete(&domain.Conversation{})
+		count = result.RowsAffected
+		return result.Error
+	})
+	return count, err
+}
+
+// RemoveMessages remove messages created before the time with the references listed by them, conversations are kept
+func (r *RetentionRepository) RemoveMessages(ctx context.Context, before time.Time) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		ids := tx.Model(&domain.ConversationMessage{}).Select("id").Where("created_at < ?", before)
+		if err := tx.Where("message_id IN (?)", ids).Delete(&domain.ConversationReference{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("created_at < ?", before).Delete(&domain.ConversationMessage{})
+		count = result.RowsAffected
+		return result.Error
+	})
+	return count, err
+}
+
+func (r *RetentionRepository) RemoveStatPages(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("created_at < ?", before).Delete(&domain.StatPage{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *RetentionRepository) RemoveGeoStats(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("hour < ?", before).Delete(&domain.StatGeoHourly{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *RetentionRepository) RemoveFunnelEvents(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("created_at < ?", before).Delete(&domain.StatFunnelEvent{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *RetentionRepository) RemoveCronRuns(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("started_at < ?", before).Delete(&domain.CronRun{})
+	return result.RowsAffected, result.Error
+}
+
+// RemoveAuditLogs remove entries from the start of the chain up to the last one created before the time, so the
+// remaining chain has no gap. the last removed entry is kept as the anchor of the chain in the same transaction
+func (r *RetentionRepository) RemoveAuditLogs(ctx context.Context, before time.Time) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("LOCK TABLE audit_logs IN SHARE ROW EXCLUSIVE MODE").Error; err != nil {
+			return err
+		}
+		var last []*domain.AuditLog
+		if err := tx.Where("created_at < ?", before).Order("id DESC").Limit(1).Find(&last).Error; err != nil {
+			return err
+		}
+		if len(last) == 0 {
+			return nil
+		}
+		now := time.Now()
+		anchor, err := json.Marshal(&domain.AuditLogAnchor{ID: last[0].ID, Hash: last[0].Hash, RemovedAt: now})
+		if err != nil {
+			return err
+		}
+		if err := tx.Exec(
+			`INSERT INTO settings (key, value, updated_at) VALUES (?, ?::jsonb, ?)
+			ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, updated_at = EXCLUDED.updated_at`,
+			domain.SettingKeyAuditLogAnchor, string(anchor), now,
+		).Error; err != nil {
+			return err
+		}
+		result := tx.Where("id <= ?", last[0].ID).Delete(&domain.AuditLog{})
+		count = result.RowsAffected
+		return result.Error
+	})
+	return count, err
+}
+
+func (r *RetentionRepository) RemoveGuardrailEvents(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("created_at < ?", before).Delete(&domain.GuardrailEvent{})
+	return result.RowsAffected, result.Error
+}
+
+// RemoveAnomalies remove anomalies detected before the time which no longer block their ip
+func (r *RetentionRepository) RemoveAnomalies(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("created_at < ?", before).
+		Where("blocked_until IS NULL OR blocked_until < ?", time.Now()).
+		Delete(&domain.ConversationAnomaly{})
+	return result.RowsAffected, result.Error
+}
+
+// RemoveRevokedSessions remove sessions revoked before the time, active sessions are kept until they expire
+func (r *RetentionRepository) RemoveRevokedSessions(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("revoked_at < ?", before).Delete(&domain.UserSession{})
+	return result.RowsAffected, result.Error
+}
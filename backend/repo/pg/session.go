@@ -0,0 +1,132 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/store/pg"
+)
+
+type SessionRepository struct {
+	db     *pg.DB
+	logger *log.Logger
+	// last request of sessions since the last sync
+	lastSeen sync.Map
+}
+
+func NewSessionRepository(db *pg.DB, logger *log.Logger) *SessionRepository {
+	repo := &SessionRepository{
+		db:     db,
+		logger: logger.WithModule("repo.pg.session"),
+	}
+	go repo.startSyncTask()
+	return repo
+}
+
+func (r *SessionRepository) CreateSession(ctx context.Context, session *domain.UserSession) error {
+	return r.db.WithContext(ctx).Create(session).Error
+}
+
+func (r *SessionRepository) GetSession(ctx context.Context, id string) (*domain.UserSession, error) {
+	session := &domain.UserSession{}
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrSessionNotFound
+		}
+		return nil, err
+	}
+	return session, nil
+}
+
+// GetActiveSessions sessions neither revoked nor expired, of every user if userID is empty, latest first
+func (r *SessionRepository) GetActiveSessions(ctx context.Context, userID string) ([]*domain.SessionListItem, error) {
+	query := r.db.WithContext(ctx).
+		Table("user_sessions").
+		Select("user_sessions.*, users.account").
+		Joins("JOIN users ON users.id = user_sessions.user_id").
+		Where("user_sessions.revoked_at IS NULL").
+		Where("user_sessions.expires_at > ?", time.Now())
+	if userID != "" {
+		query = query.Where("user_sessions.user_id = ?", userID)
+	}
+	var rows []*struct {
+		domain.UserSession
+		Account string
+	}
+	if err := query.Order("user_sessions.last_seen_at DESC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	items := make([]*domain.SessionListItem, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, &domain.SessionListItem{UserSession: &row.UserSession, Account: row.Account})
+	}
+	return items, nil
+}
+
+// RevokeSessions revoke the active sessions of the user, or only the session of the id if given, return the revoked sessions
+func (r *SessionRepository) RevokeSessions(ctx context.Context, userID, id string) ([]*domain.UserSession, error) {
+	var sessions []*domain.UserSession
+	query := r.db.WithContext(ctx).
+		Model(&sessions).
+		Clauses(clause.Returning{}).
+		Where("user_id = ?", userID).
+		Where("revoked_at IS NULL").
+		Where("expires_at > ?", time.Now())
+	if id != "" {
+		query = query.Where("id = ?", id)
+	}
+	if err := query.Update("revoked_at", time.Now()).Error; err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// Touch record a request of the session, written to the database every minute
+func (r *SessionRepository) Touch(id string) {
+	r.lastSeen.Store(id, time.Now())
+}
+
+func (r *SessionRepository) startSyncTask() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for range ticker.C {
+		r.syncLastSeen()
+		if err := r.db.Where("expires_at < ?", time.Now()).Delete(&domain.UserSession{}).Error; err != nil {
+			r.logger.Error("delete expired sessions failed", log.Error(err))
+		}
+	}
+}
+
+func (r *SessionRepository) syncLastSeen() {
+	seen := make(map[string]time.Time)
+	r.lastSeen.Range(func(key, value any) bool {
+		seen[key.(string)] = value.(time.Time)
+		return true
+	})
+	if len(seen) == 0 {
+		return
+	}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		for id, at := range seen {
+			if err := tx.Model(&domain.UserSession{}).Where("id = ?", id).Update("last_seen_at", at).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("sync session last seen failed", log.Error(err), log.Int("update_count", len(seen)))
+		return
+	}
+	for id, at := range seen {
+		// sessions seen again since are synced next time
+		r.lastSeen.CompareAndDelete(id, at)
+	}
+}
@@ -0,0 +1,50 @@
+package pg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/store/pg"
+)
+
+type SettingRepository struct {
+	db *pg.DB
+}
+
+func NewSettingRepository(db *pg.DB) *SettingRepository {
+	return &SettingRepository{db: db}
+}
+
+// GetSetting unmarshal setting of key into v, v is left untouched if the key is not set
+func (r *SettingRepository) GetSetting(ctx context.Context, key string, v any) error {
+	var value string
+	err := r.db.WithContext(ctx).
+		Model(&domain.Setting{}).
+		Where("key = ?", key).
+		Select("value::text").
+		Take(&value).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	return json.Unmarshal([]byte(value), v)
+}
+
+func (r *SettingRepository) UpdateSetting(ctx context.Context, key string, v any) error {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Exec(
+		`INSERT INTO settings (key, value, updated_at) VALUES (?, ?::jsonb, ?)
+		ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, updated_at = EXCLUDED.updated_at`,
+		key, string(value), time.Now(),
+	).Error
+}
@@ -0,0 +1,85 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+
+	"github.com/chaitin/panda-wiki/domain"
+)
+
+// SetMessageFeedback rate the answer of the conversation, the latest answer if messageID is empty
+func (r *ConversationRepository) SetMessageFeedback(ctx context.Context, conversationID, messageID string, feedbackType domain.MessageFeedbackType, comment string) error {
+	query := r.db.WithContext(ctx).
+		Model(&domain.ConversationMessage{}).
+		Where("conversation_id = ?", conversationID).
+		Where("role = ?", schema.Assistant)
+	if messageID != "" {
+		query = query.Where("id = ?", messageID)
+	} else {
+		query = query.Where("id = (SELECT id FROM conversation_messages WHERE conversation_id = ? AND role = ? ORDER BY created_at DESC LIMIT 1)", conversationID, schema.Assistant)
+	}
+	result := query.Updates(map[string]any{
+		"feedback_type":    feedbackType,
+		"feedback_comment": comment,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrFeedbackMessageNotFound
+	}
+	return nil
+}
+
+// GetAnswerFeedbackCounts answers of the kb since the time and how many of them were liked or disliked
+func (r *ConversationRepository) GetAnswerFeedbackCounts(ctx context.Context, kbID string, since time.Time) (*domain.SourceAttributionReport, error) {
+	report := &domain.SourceAttributionReport{}
+	if err := r.db.WithContext(ctx).Raw(`
+		SELECT COUNT(*) AS answer_count,
+			COUNT(*) FILTER (WHERE m.feedback_type = @like) AS like_count,
+			COUNT(*) FILTER (WHERE m.feedback_type = @dislike) AS dislike_count
+		FROM conversation_messages m
+		JOIN conversations c ON c.id = m.conversation_id
+		WHERE c.kb_id = @kb_id AND NOT c.historical AND m.role = @role AND m.created_at >= @since`,
+		sql.Named("kb_id", kbID), sql.Named("role", schema.Assistant), sql.Named("since", since),
+		sql.Named("like", domain.MessageFeedbackLike), sql.Named("dislike", domain.MessageFeedbackDislike),
+	).Scan(report).Error; err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// GetSourceAttribution documents of the kb cited by answers since the time with the feedback of those answers, most cited first.
+// references listed in the answer only have the url of the document, references saved before answers were tracked count all answers of their conversation
+func (r *ConversationRepository) GetSourceAttribution(ctx context.Context, kbID string, since time.Time, limit int) ([]*domain.SourceAttributionItem, error) {
+	var items []*domain.SourceAttributionItem
+	if err := r.db.WithContext(ctx).Raw(`
+		WITH refs AS (
+			SELECT DISTINCT r.conversation_id, r.message_id,
+				COALESCE(NULLIF(r.node_id, ''), substring(r.url from '/node/([0-9A-Za-z-]+)')) AS node_id
+			FROM conversation_references r
+			JOIN conversations c ON c.id = r.conversation_id
+			WHERE c.kb_id = @kb_id AND NOT c.historical AND c.created_at >= @since
+		)
+		SELECT n.id AS node_id, n.name,
+			COUNT(DISTINCT refs.conversation_id) AS conversation_count,
+			COUNT(DISTINCT m.id) AS answer_count,
+			COUNT(DISTINCT m.id) FILTER (WHERE m.feedback_type = @like) AS like_count,
+			COUNT(DISTINCT m.id) FILTER (WHERE m.feedback_type = @dislike) AS dislike_count
+		FROM refs
+		JOIN nodes n ON n.id = refs.node_id AND n.kb_id = @kb_id
+		LEFT JOIN conversation_messages m ON m.conversation_id = refs.conversation_id AND m.role = @role
+			AND (refs.message_id = '' OR m.id = refs.message_id)
+		GROUP BY n.id, n.name
+		ORDER BY answer_count DESC, conversation_count DESC, n.name
+		LIMIT @limit`,
+		sql.Named("kb_id", kbID), sql.Named("since", since), sql.Named("role", schema.Assistant), sql.Named("limit", limit),
+		sql.Named("like", domain.MessageFeedbackLike), sql.Named("dislike", domain.MessageFeedbackDislike),
+	).Scan(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
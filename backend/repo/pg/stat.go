@@ -2,6 +2,10 @@ package pg
 
 import (
 	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
 
 	"github.com/chaitin/panda-wiki/domain"
 	"github.com/chaitin/panda-wiki/store/pg"
@@ -23,6 +27,7 @@ func (r *StatRepository) GetHotPages(ctx context.Context, kbID string) ([]*domai
 	var hotPages []*domain.HotPageResp
 	if err := r.db.WithContext(ctx).Model(&domain.StatPage{}).
 		Where("kb_id = ?", kbID).
+		Scopes(excludeBot("stat_pages", kbID)).
 		Group("scene, node_id").
 		Select("scene, node_id, COUNT(*) as count").
 		Order("count DESC").
@@ -37,6 +42,7 @@ func (r *StatRepository) GetHotScene(ctx context.Context, kbID string) (map[doma
 	var scenes map[domain.StatPageScene]int64
 	if err := r.db.WithContext(ctx).Model(&domain.StatPage{}).
 		Where("kb_id = ?", kbID).
+		Scopes(excludeBot("stat_pages", kbID)).
 		Group("scene").
 		Select("scene, COUNT(*) as count").
 		Order("count DESC").
@@ -51,6 +57,7 @@ func (r *StatRepository) GetHotRefererHosts(ctx context.Context, kbID string) ([
 	var hotRefererHosts []*domain.HotRefererHostResp
 	if err := r.db.WithContext(ctx).Model(&domain.StatPage{}).
 		Where("kb_id = ?", kbID).
+		Scopes(excludeBot("stat_pages", kbID)).
 		Group("referer_host").
 		Select("referer_host, COUNT(*) as count").
 		Order("count DESC").
@@ -68,6 +75,7 @@ func (r *StatRepository) GetHotBrowsers(ctx context.Context, kbID string) (*doma
 
 	query := r.db.WithContext(ctx).Model(&domain.StatPage{}).
 		Where("kb_id = ?", kbID).
+		Scopes(excludeBot("stat_pages", kbID)).
 		Group("browser_name").
 		Select("browser_name as name, COUNT(*) as count")
 	if err := query.Order("count DESC").Limit(10).Find(&browserCount).Error; err != nil {
@@ -76,6 +84,7 @@ func (r *StatRepository) GetHotBrowsers(ctx context.Context, kbID string) (*doma
 
 	query = r.db.WithContext(ctx).Model(&domain.StatPage{}).
 		Where("kb_id = ?", kbID).
+		Scopes(excludeBot("stat_pages", kbID)).
 		Group("browser_os").
 		Select("browser_os as name, COUNT(*) as count")
 	if err := query.Order("count DESC").Limit(10).Find(&osCount).Error; err != nil {
@@ -94,6 +103,7 @@ func (r *StatRepository) GetCount(ctx context.Context, kbID string) (*domain.Sta
 	var count domain.StatPageCountResp
 	if err := r.db.WithContext(ctx).Model(&domain.StatPage{}).
 		Where("kb_id = ?", kbID).
+		Scopes(excludeBot("stat_pages", kbID)).
 		Select("COUNT(DISTINCT ip) as ip_count, COUNT(DISTINCT session_id) as session_count, COUNT(*) as page_visit_count").
 		Scan(&count).Error; err != nil {
 		return nil, err
@@ -105,6 +115,7 @@ func (r *StatRepository) GetInstantCount(ctx context.Context, kbID string) ([]*d
 	var instantCount []*domain.InstantCountResp
 	if err := r.db.WithContext(ctx).Model(&domain.StatPage{}).
 		Where("kb_id = ? AND created_at >= NOW() - INTERVAL '30 minutes'", kbID).
+		Scopes(excludeBot("stat_pages", kbID)).
 		Select("date_trunc('minute', created_at) as time, COUNT(*) as count").
 		Group("time").
 		Order("time ASC").
@@ -118,6 +129,7 @@ func (r *StatRepository) GetInstantPages(ctx context.Context, kbID string) ([]*d
 	var instantPages []*domain.InstantPageResp
 	if err := r.db.WithContext(ctx).Model(&domain.StatPage{}).
 		Where("kb_id = ?", kbID).
+		Scopes(excludeBot("stat_pages", kbID)).
 		Where("scene = ?", domain.StatPageSceneNodeDetail).
 		Select("node_id, ip, created_at").
 		Order("created_at DESC").
@@ -128,11 +140,84 @@ func (r *StatRepository) GetInstantPages(ctx context.Context, kbID string) ([]*d
 	return instantPages, nil
 }
 
-func (r *StatRepository) RemoveOldData(ctx context.Context) error {
+// GetRequestCountSince get page visit count since the given time
+func (r *StatRepository) GetRequestCountSince(ctx context.Context, kbID string, since time.Time) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&domain.StatPage{}).
+		Where("kb_id = ?", kbID).
+		Scopes(excludeBot("stat_pages", kbID)).
+		Where("created_at >= ?", since).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetPageTrafficSources get page visit count of last 24 hours grouped by referer host or utm parameter
+func (r *StatRepository) GetPageTrafficSources(ctx context.Context, kbID string, dimension domain.TrafficSourceDimension) ([]*domain.TrafficSourceCount, error) {
+	var sources []*domain.TrafficSourceCount
+	column := string(dimension)
 	if err := r.db.WithContext(ctx).Model(&domain.StatPage{}).
-		Where("created_at < date_trunc('hour', now()) - interval '24h'").
-		Delete(&domain.StatPage{}).Error; err != nil {
-		return err
+		Where("kb_id = ?", kbID).
+		Where("created_at > now() - interval '24h'").
+		Scopes(excludeBot("stat_pages", kbID)).
+		Where(fmt.Sprintf("COALESCE(%s, '') != ''", column)).
+		Group(column).
+		Select(fmt.Sprintf("%s as name, COUNT(*) as count", column)).
+		Order("count DESC").
+		Limit(10).
+		Find(&sources).Error; err != nil {
+		return nil, err
+	}
+	return sources, nil
+}
+
+// GetConversationTrafficSources get conversation count of last 24 hours grouped by referer host or utm parameter
+func (r *StatRepository) GetConversationTrafficSources(ctx context.Context, kbID string, dimension domain.TrafficSourceDimension) ([]*domain.TrafficSourceCount, error) {
+	var sources []*domain.TrafficSourceCount
+	column := fmt.Sprintf("info->'source'->>'%s'", dimension)
+	if err := r.db.WithContext(ctx).Model(&domain.Conversation{}).
+		Where("kb_id = ?", kbID).
+		Scopes(excludeBot("conversations", kbID)).
+		Where("created_at > now() - interval '24h'").
+		Where(fmt.Sprintf("COALESCE(%s, '') != ''", column)).
+		Group(column).
+		Select(fmt.Sprintf("%s as name, COUNT(*) as count", column)).
+		Order("count DESC").
+		Limit(10).
+		Find(&sources).Error; err != nil {
+		return nil, err
+	}
+	return sources, nil
+}
+
+// GetConversationPlatforms get conversation count of the app type since the time grouped by device, browser and os
+func (r *StatRepository) GetConversationPlatforms(ctx context.Context, kbID string, appType domain.AppType, since time.Time) (*domain.PlatformStatResp, error) {
+	query := func() *gorm.DB {
+		return r.db.WithContext(ctx).Model(&domain.Conversation{}).
+			Where("conversations.kb_id = ?", kbID).
+			Scopes(excludeBot("conversations", kbID)).
+			Where("conversations.created_at > ?", since).
+			Where("conversations.app_id IN (SELECT id FROM apps WHERE kb_id = ? AND type = ?)", kbID, appType)
+	}
+	resp := &domain.PlatformStatResp{}
+	if err := query().Count(&resp.Total).Error; err != nil {
+		return nil, err
+	}
+	for column, counts := range map[string]*[]domain.BrowserCount{
+		"device":  &resp.Device,
+		"browser": &resp.Browser,
+		"os":      &resp.OS,
+	} {
+		field := fmt.Sprintf("COALESCE(NULLIF(info->'platform'->>'%s', ''), 'unknown')", column)
+		if err := query().
+			Group(field).
+			Select(fmt.Sprintf("%s as name, COUNT(*) as count", field)).
+			Order("count DESC").
+			Limit(10).
+			Find(counts).Error; err != nil {
+			return nil, err
+		}
 	}
-	return nil
+	return resp, nil
 }
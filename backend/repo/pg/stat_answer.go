@@ -0,0 +1,29 @@
+package pg
+
+import (
+	"context"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+
+	"github.com/chaitin/panda-wiki/domain"
+)
+
+// GetAnswerConfidenceCounts get daily count of assistant answers and low confidence answers
+func (r *StatRepository) GetAnswerConfidenceCounts(ctx context.Context, kbID string, start, end time.Time) ([]*domain.AnswerConfidenceCount, error) {
+	var counts []*domain.AnswerConfidenceCount
+	if err := r.db.WithContext(ctx).
+		Model(&domain.ConversationMessage{}).
+		Joins("JOIN conversations ON conversations.id = conversation_messages.conversation_id").
+		Where("conversations.kb_id = ?", kbID).
+		Scopes(excludeBot("conversations", kbID)).
+		Where("conversation_messages.role = ?", schema.Assistant).
+		Where("conversation_messages.created_at >= ? AND conversation_messages.created_at < ?", start, end).
+		Select("date_trunc('day', conversation_messages.created_at) AS date, COUNT(*) AS answered, COUNT(*) FILTER (WHERE conversation_messages.low_confidence) AS low_confidence").
+		Group("date").
+		Order("date ASC").
+		Find(&counts).Error; err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
@@ -0,0 +1,17 @@
+package pg
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// excludeBot exclude rows marked as bot if the kb filters bot traffic in stat settings
+func excludeBot(table, kbID string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(fmt.Sprintf(`(NOT %s.is_bot OR NOT EXISTS (
+			SELECT 1 FROM knowledge_bases
+			WHERE knowledge_bases.id = ? AND COALESCE((knowledge_bases.stat_settings->>'filter_bot_traffic')::boolean, false)
+		))`, table), kbID)
+	}
+}
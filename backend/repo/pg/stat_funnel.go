@@ -0,0 +1,48 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chaitin/panda-wiki/domain"
+)
+
+func (r *StatRepository) CreateFunnelEvent(ctx context.Context, event *domain.StatFunnelEvent) error {
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+// GetFunnelCounts get sessions reached each step and sessions reached each step after all previous steps
+func (r *StatRepository) GetFunnelCounts(ctx context.Context, kbID string, start, end time.Time) (reached, converted []int64, err error) {
+	flags := make([]string, 0, len(domain.FunnelSteps))
+	columns := make([]string, 0, len(domain.FunnelSteps)*2)
+	for i, step := range domain.FunnelSteps {
+		flags = append(flags, fmt.Sprintf("bool_or(step = '%s') AS s%d", step, i))
+		columns = append(columns, fmt.Sprintf("COUNT(*) FILTER (WHERE s%d)", i))
+		conds := make([]string, 0, i+1)
+		for j := 0; j <= i; j++ {
+			conds = append(conds, fmt.Sprintf("s%d", j))
+		}
+		columns = append(columns, fmt.Sprintf("COUNT(*) FILTER (WHERE %s)", strings.Join(conds, " AND ")))
+	}
+	query := fmt.Sprintf(`
+		SELECT %s FROM (
+			SELECT session_id, %s FROM stat_funnel_events
+			WHERE kb_id = ? AND created_at >= ? AND created_at < ?
+			GROUP BY session_id
+		) t`, strings.Join(columns, ", "), strings.Join(flags, ", "))
+	counts := make([]int64, len(columns))
+	dest := make([]any, len(columns))
+	for i := range counts {
+		dest[i] = &counts[i]
+	}
+	if err := r.db.WithContext(ctx).Raw(query, kbID, start, end).Row().Scan(dest...); err != nil {
+		return nil, nil, err
+	}
+	for i := range domain.FunnelSteps {
+		reached = append(reached, counts[i*2])
+		converted = append(converted, counts[i*2+1])
+	}
+	return reached, converted, nil
+}
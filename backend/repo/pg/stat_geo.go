@@ -0,0 +1,47 @@
+package pg
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/chaitin/panda-wiki/domain"
+)
+
+// IncrGeoStat add one visit of the location to the current hour
+func (r *StatRepository) IncrGeoStat(ctx context.Context, kbID, appID string, ipAddress *domain.IPAddress) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "kb_id"}, {Name: "app_id"}, {Name: "hour"}, {Name: "country"}, {Name: "province"}, {Name: "city"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"count": gorm.Expr("stat_geo_hourly.count + EXCLUDED.count"),
+		}),
+	}).Create(&domain.StatGeoHourly{
+		KBID:     kbID,
+		AppID:    appID,
+		Hour:     time.Now().Truncate(time.Hour),
+		Country:  ipAddress.Country,
+		Province: ipAddress.Province,
+		City:     ipAddress.City,
+		Count:    1,
+	}).Error
+}
+
+func (r *StatRepository) GetGeoCounts(ctx context.Context, kbID, appID string, start, end time.Time) ([]*domain.GeoCount, error) {
+	var counts []*domain.GeoCount
+	query := r.db.WithContext(ctx).Model(&domain.StatGeoHourly{}).
+		Where("kb_id = ?", kbID).
+		Where("hour >= ? AND hour < ?", start.Truncate(time.Hour), end)
+	if appID != "" {
+		query = query.Where("app_id = ?", appID)
+	}
+	if err := query.
+		Group("country, province, city").
+		Select("country, province, city, SUM(count) as count").
+		Order("count DESC").
+		Find(&counts).Error; err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
@@ -0,0 +1,71 @@
+package pg
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/chaitin/panda-wiki/domain"
+)
+
+// IncrNodeStat add the non-zero counters of stat to the daily row of the node
+func (r *StatRepository) IncrNodeStat(ctx context.Context, stat *domain.StatNodeDaily) error {
+	if stat.Date.IsZero() {
+		stat.Date = today()
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "node_id"}, {Name: "date"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"views":       gorm.Expr("stat_node_daily.views + EXCLUDED.views"),
+			"visitors":    gorm.Expr("stat_node_daily.visitors + EXCLUDED.visitors"),
+			"dwell_total": gorm.Expr("stat_node_daily.dwell_total + EXCLUDED.dwell_total"),
+			"dwell_count": gorm.Expr("stat_node_daily.dwell_count + EXCLUDED.dwell_count"),
+			"citations":   gorm.Expr("stat_node_daily.citations + EXCLUDED.citations"),
+		}),
+	}).Create(stat).Error
+}
+
+// IncrNodeCitations add one citation to each node of today
+func (r *StatRepository) IncrNodeCitations(ctx context.Context, kbID string, nodeIDs []string) error {
+	if len(nodeIDs) == 0 {
+		return nil
+	}
+	date := today()
+	stats := make([]*domain.StatNodeDaily, 0, len(nodeIDs))
+	for _, nodeID := range nodeIDs {
+		stats = append(stats, &domain.StatNodeDaily{KBID: kbID, NodeID: nodeID, Date: date, Citations: 1})
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "node_id"}, {Name: "date"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"citations": gorm.Expr("stat_node_daily.citations + EXCLUDED.citations"),
+		}),
+	}).Create(&stats).Error
+}
+
+func (r *StatRepository) GetNodeStats(ctx context.Context, kbID, nodeID string, since time.Time) ([]*domain.NodeStatResp, error) {
+	var stats []*domain.NodeStatResp
+	query := r.db.WithContext(ctx).Model(&domain.StatNodeDaily{}).
+		Where("kb_id = ?", kbID).
+		Where("date >= ?", since)
+	if nodeID != "" {
+		query = query.Where("node_id = ?", nodeID)
+	}
+	if err := query.
+		Group("node_id").
+		Select(`node_id, SUM(views) as views, SUM(visitors) as visitors, SUM(citations) as citations,
+			CASE WHEN SUM(dwell_count) > 0 THEN SUM(dwell_total) / SUM(dwell_count) ELSE 0 END as avg_dwell`).
+		Order("views DESC").
+		Limit(100).
+		Find(&stats).Error; err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func today() time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+}
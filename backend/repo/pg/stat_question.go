@@ -0,0 +1,144 @@
+package pg
+
+import (
+	"context"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/chaitin/panda-wiki/domain"
+)
+
+// GetPendingQuestions get user questions created after since or imported from a helpdesk, which are not clustered yet
+func (r *StatRepository) GetPendingQuestions(ctx context.Context, since time.Time, limit int) ([]*domain.PendingQuestion, error) {
+	var questions []*domain.PendingQuestion
+	if err := r.db.WithContext(ctx).
+		Model(&domain.ConversationMessage{}).
+		Joins("JOIN conversations ON conversations.id = conversation_messages.conversation_id").
+		Joins("LEFT JOIN stat_questions ON stat_questions.id = conversation_messages.id").
+		Where("conversation_messages.role = ?", schema.User).
+		// historical conversations are imported with their original time
+		Where("(conversation_messages.created_at >= ? OR conversations.historical)", since).
+		Where("stat_questions.id IS NULL").
+		Select("conversation_messages.id, conversations.kb_id, conversation_messages.conversation_id, conversation_messages.content, conversation_messages.created_at").
+		Order("conversation_messages.created_at ASC").
+		Limit(limit).
+		Find(&questions).Error; err != nil {
+		return nil, err
+	}
+	return questions, nil
+}
+
+func (r *StatRepository) GetQuestionClusters(ctx context.Context, kbID string) ([]*domain.StatQuestionCluster, error) {
+	var clusters []*domain.StatQuestionCluster
+	if err := r.db.WithContext(ctx).
+		Model(&domain.StatQuestionCluster{}).
+		Where("kb_id = ?", kbID).
+		Find(&clusters).Error; err != nil {
+		return nil, err
+	}
+	return clusters, nil
+}
+
+// SaveQuestionClusters upsert clusters and insert clustered questions in one transaction
+func (r *StatRepository) SaveQuestionClusters(ctx context.Context, clusters []*domain.StatQuestionCluster, questions []*domain.StatQuestion) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(clusters) > 0 {
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"label", "label_score", "centroid", "count", "updated_at"}),
+			}).Create(&clusters).Error; err != nil {
+				return err
+			}
+		}
+		if len(questions) > 0 {
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+				CreateInBatches(&questions, 100).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *StatRepository) GetTopQuestionClusters(ctx context.Context, kbID string, start, end time.Time, limit int) ([]*domain.QuestionClusterResp, error) {
+	var clusters []*domain.QuestionClusterResp
+	if err := r.db.WithContext(ctx).
+		Model(&domain.StatQuestion{}).
+		Joins("JOIN stat_question_clusters ON stat_question_clusters.id = stat_questions.cluster_id").
+		Where("stat_questions.kb_id = ?", kbID).
+		Where("stat_questions.created_at >= ? AND stat_questions.created_at < ?", start, end).
+		Group("stat_questions.cluster_id, stat_question_clusters.label").
+		Select("stat_questions.cluster_id, stat_question_clusters.label, COUNT(*) as count").
+		Order("count DESC").
+		Limit(limit).
+		Find(&clusters).Error; err != nil {
+		return nil, err
+	}
+	return clusters, nil
+}
+
+// MergeQuestionClusters move questions of from cluster into the into cluster and delete the from cluster
+func (r *StatRepository) MergeQuestionClusters(ctx context.Context, from string, into *domain.StatQuestionCluster) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.StatQuestion{}).
+			Where("cluster_id = ?", from).
+			Update("cluster_id", into.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&domain.StatQuestionCluster{}).
+			Where("id = ?", into.ID).
+			Updates(map[string]any{
+				"label":       into.Label,
+				"label_score": into.LabelScore,
+				"centroid":    into.Centroid,
+				"count":       into.Count,
+				"updated_at":  into.UpdatedAt,
+			}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&domain.StatQuestionCluster{}, "id = ?", from).Error
+	})
+}
+
+// GetQuestionClusterSamples get distinct questions of clusters in time range, most asked first
+func (r *StatRepository) GetQuestionClusterSamples(ctx context.Context, clusterIDs []string, start, end time.Time) ([]*domain.QuestionClusterSample, error) {
+	var samples []*domain.QuestionClusterSample
+	if len(clusterIDs) == 0 {
+		return samples, nil
+	}
+	if err := r.db.WithContext(ctx).
+		Model(&domain.StatQuestion{}).
+		Where("cluster_id IN ?", clusterIDs).
+		Where("created_at >= ? AND created_at < ?", start, end).
+		Group("cluster_id, question").
+		Select("cluster_id, question, COUNT(*) as count").
+		Order("count DESC").
+		Find(&samples).Error; err != nil {
+		return nil, err
+	}
+	return samples, nil
+}
+
+// GetPopularQueries short user questions of the kb asked at least minCount times since the time, most asked first
+func (r *StatRepository) GetPopularQueries(ctx context.Context, kbID string, since time.Time, minCount, maxLen, limit int) ([]*domain.PopularQuery, error) {
+	var queries []*domain.PopularQuery
+	if err := r.db.WithContext(ctx).
+		Model(&domain.ConversationMessage{}).
+		Joins("JOIN conversations ON conversations.id = conversation_messages.conversation_id").
+		Where("conversations.kb_id = ?", kbID).
+		Where("conversation_messages.role = ?", schema.User).
+		Where("conversation_messages.created_at >= ?", since).
+		Where("char_length(conversation_messages.content) <= ?", maxLen).
+		Group("lower(trim(conversation_messages.content))").
+		Having("COUNT(*) >= ?", minCount).
+		Select("MIN(trim(conversation_messages.content)) as text, COUNT(*) as count").
+		Order("count DESC").
+		Limit(limit).
+		Find(&queries).Error; err != nil {
+		return nil, err
+	}
+	return queries, nil
+}
@@ -0,0 +1,85 @@
+package pg
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/store/pg"
+)
+
+type TelemetryRepository struct {
+	db *pg.DB
+}
+
+func NewTelemetryRepository(db *pg.DB) *TelemetryRepository {
+	return &TelemetryRepository{db: db}
+}
+
+// AddUsage add the counters to the rows of today
+func (r *TelemetryRepository) AddUsage(ctx context.Context, counters map[string]domain.TelemetryCounter) error {
+	if len(counters) == 0 {
+		return nil
+	}
+	day := today()
+	usages := make([]*domain.TelemetryUsage, 0, len(counters))
+	for feature, counter := range counters {
+		usages = append(usages, &domain.TelemetryUsage{
+			Day:      day,
+			Feature:  feature,
+			Requests: counter.Requests,
+			Errors:   counter.Errors,
+		})
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "day"}, {Name: "feature"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"requests": gorm.Expr("telemetry_usage_daily.requests + EXCLUDED.requests"),
+			"errors":   gorm.Expr("telemetry_usage_daily.errors + EXCLUDED.errors"),
+		}),
+	}).Create(&usages).Error
+}
+
+// GetFeatureUsage usage of each feature since the day, most used first
+func (r *TelemetryRepository) GetFeatureUsage(ctx context.Context, since time.Time) ([]*domain.TelemetryFeatureUsage, error) {
+	features := make([]*domain.TelemetryFeatureUsage, 0)
+	if err := r.db.WithContext(ctx).
+		Model(&domain.TelemetryUsage{}).
+		Select("feature, SUM(requests) AS requests, SUM(errors) AS errors").
+		Where("day >= ?", since).
+		Group("feature").
+		Order("requests DESC, feature").
+		Find(&features).Error; err != nil {
+		return nil, err
+	}
+	return features, nil
+}
+
+// GetDeployment count kbs, documents, apps, users and models of the deployment
+func (r *TelemetryRepository) GetDeployment(ctx context.Context) (*domain.TelemetryDeployment, error) {
+	deployment := &domain.TelemetryDeployment{}
+	if err := r.db.WithContext(ctx).Raw(`SELECT
+		(SELECT COUNT(*) FROM knowledge_bases) AS kb_count,
+		(SELECT COUNT(*) FROM nodes) AS node_count,
+		(SELECT COUNT(*) FROM apps) AS app_count,
+		(SELECT COUNT(*) FROM users) AS user_count,
+		(SELECT COUNT(*) FROM models) AS model_count`).
+		Scan(deployment).Error; err != nil {
+		return nil, err
+	}
+	return deployment, nil
+}
+
+// RemoveUsage remove usage of days before the day
+func (r *TelemetryRepository) RemoveUsage(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("day < ?", before).Delete(&domain.TelemetryUsage{})
+	return result.RowsAffected, result.Error
+}
+
+// ClearUsage remove all aggregated usage
+func (r *TelemetryRepository) ClearUsage(ctx context.Context) error {
+	return r.db.WithContext(ctx).Exec("DELETE FROM telemetry_usage_daily").Error
+}
@@ -0,0 +1,46 @@
+package pg
+
+import (
+	"context"
+
+	"github.com/chaitin/panda-wiki/domain"
+)
+
+func (r *ConversationRepository) CreateTranscriptEmail(ctx context.Context, record *domain.ConversationTranscriptEmail) error {
+	return r.db.WithContext(ctx).Create(record).Error
+}
+
+func (r *ConversationRepository) GetTranscriptEmailList(ctx context.Context, req *domain.TranscriptEmailListReq) ([]*domain.ConversationTranscriptEmail, uint64, error) {
+	query := r.db.WithContext(ctx).
+		Model(&domain.ConversationTranscriptEmail{}).
+		Where("kb_id = ?", req.KBID)
+	if req.ConversationID != "" {
+		query = query.Where("conversation_id = ?", req.ConversationID)
+	}
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	records := []*domain.ConversationTranscriptEmail{}
+	if err := query.
+		Offset(req.Offset()).
+		Limit(req.Limit()).
+		Order("created_at DESC").
+		Find(&records).Error; err != nil {
+		return nil, 0, err
+	}
+	return records, uint64(count), nil
+}
+
+func (r *ConversationRepository) GetConversationByNonce(ctx context.Context, kbID, conversationID, nonce string) (*domain.Conversation, error) {
+	conversation := &domain.Conversation{}
+	if err := r.db.WithContext(ctx).
+		Model(&domain.Conversation{}).
+		Where("id = ?", conversationID).
+		Where("kb_id = ?", kbID).
+		Where("nonce = ?", nonce).
+		First(conversation).Error; err != nil {
+		return nil, err
+	}
+	return conversation, nil
+}
@@ -0,0 +1,98 @@
+package pg
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/store/pg"
+)
+
+type TwoFactorRepository struct {
+	db *pg.DB
+}
+
+func NewTwoFactorRepository(db *pg.DB) *TwoFactorRepository {
+	return &TwoFactorRepository{db: db}
+}
+
+// GetTwoFactor two-factor authentication of the user, nil if it never enrolled
+func (r *TwoFactorRepository) GetTwoFactor(ctx context.Context, userID string) (*domain.UserTwoFactor, error) {
+	var twoFactors []*domain.UserTwoFactor
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Limit(1).Find(&twoFactors).Error; err != nil {
+		return nil, err
+	}
+	if len(twoFactors) == 0 {
+		return nil, nil
+	}
+	return twoFactors[0], nil
+}
+
+// StartEnrollment replace the secret of a pending enrollment, enabled ones are kept
+func (r *TwoFactorRepository) StartEnrollment(ctx context.Context, userID, secret string) error {
+	now := time.Now()
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.Assignments(map[string]any{"secret": secret, "updated_at": now}),
+			Where:     clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "user_two_factors.enabled = false"}}},
+		}).
+		Create(&domain.UserTwoFactor{UserID: userID, Secret: secret, CreatedAt: now, UpdatedAt: now}).Error
+}
+
+// Enable enable a pending enrollment with the step of its first code, false if it was enabled meanwhile
+func (r *TwoFactorRepository) Enable(ctx context.Context, userID string, step int64, backupCodes domain.TwoFactorBackupCodes) (bool, error) {
+	now := time.Now()
+	result := r.db.WithContext(ctx).
+		Model(&domain.UserTwoFactor{}).
+		Where("user_id = ?", userID).
+		Where("enabled = false").
+		Updates(map[string]any{
+			"enabled":        true,
+			"last_used_step": step,
+			"backup_codes":   backupCodes,
+			"enabled_at":     now,
+			"updated_at":     now,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// UseStep record the step of an accepted code, false if a code of the step or a later one was used already
+func (r *TwoFactorRepository) UseStep(ctx context.Context, userID string, step int64) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&domain.UserTwoFactor{}).
+		Where("user_id = ?", userID).
+		Where("enabled = true").
+		Where("last_used_step < ?", step).
+		Updates(map[string]any{"last_used_step": step, "updated_at": time.Now()})
+	return result.RowsAffected == 1, result.Error
+}
+
+// UseBackupCode remove the backup code of the hash, false if it is not an unused code of the user
+func (r *TwoFactorRepository) UseBackupCode(ctx context.Context, userID, hash string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&domain.UserTwoFactor{}).
+		Where("user_id = ?", userID).
+		Where("enabled = true").
+		Where("jsonb_exists(backup_codes, ?)", hash).
+		Updates(map[string]any{
+			"backup_codes": gorm.Expr("backup_codes - ?", hash),
+			"updated_at":   time.Now(),
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *TwoFactorRepository) UpdateBackupCodes(ctx context.Context, userID string, backupCodes domain.TwoFactorBackupCodes) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.UserTwoFactor{}).
+		Where("user_id = ?", userID).
+		Where("enabled = true").
+		Updates(map[string]any{"backup_codes": backupCodes, "updated_at": time.Now()}).Error
+}
+
+func (r *TwoFactorRepository) DeleteTwoFactor(ctx context.Context, userID string) error {
+	return r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&domain.UserTwoFactor{}).Error
+}
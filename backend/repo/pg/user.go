@@ -45,8 +45,11 @@ func (r *UserRepository) UpsertDefaultUser(ctx context.Context, user *domain.Use
 			}
 			return nil
 		}
-		// User exists, update password
-		return tx.Model(&existingUser).Update("password", user.Password).Error
+		// User exists, update password, the default user is always an admin
+		return tx.Model(&existingUser).Updates(map[string]any{
+			"password": user.Password,
+			"role":     domain.UserRoleAdmin,
+		}).Error
 	})
 }
 
@@ -71,6 +74,41 @@ func (r *UserRepository) VerifyUser(ctx context.Context, account string, passwor
 	return &user, nil
 }
 
+// GetUserByOIDCSubject user signed in with the oidc provider before, gorm.ErrRecordNotFound if none
+func (r *UserRepository) GetUserByOIDCSubject(ctx context.Context, subject string) (*domain.User, error) {
+	var user domain.User
+	if err := r.db.WithContext(ctx).Where("oidc_subject = ?", subject).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetUserByLDAPDN user logged in with the ldap directory before, gorm.ErrRecordNotFound if none
+func (r *UserRepository) GetUserByLDAPDN(ctx context.Context, dn string) (*domain.User, error) {
+	var user domain.User
+	if err := r.db.WithContext(ctx).Where("ldap_dn = ?", dn).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetLDAPUsers users of the ldap directory, whose roles are synced
+func (r *UserRepository) GetLDAPUsers(ctx context.Context) ([]*domain.User, error) {
+	var users []*domain.User
+	if err := r.db.WithContext(ctx).Where("ldap_dn <> ''").Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (r *UserRepository) AccountExists(ctx context.Context, account string) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&domain.User{}).Where("account = ?", account).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
 func (r *UserRepository) GetUser(ctx context.Context, userID string) (*domain.UserInfoResp, error) {
 	var user domain.UserInfoResp
 	err := r.db.WithContext(ctx).
@@ -103,6 +141,44 @@ func (r *UserRepository) UpdateUserPassword(ctx context.Context, userID string,
 	return r.db.WithContext(ctx).Model(&domain.User{}).Where("id = ?", userID).Update("password", string(hashedPassword)).Error
 }
 
+func (r *UserRepository) GetUserRole(ctx context.Context, userID string) (domain.UserRole, error) {
+	var roles []domain.UserRole
+	if err := r.db.WithContext(ctx).
+		Model(&domain.User{}).
+		Where("id = ?", userID).
+		Limit(1).
+		Pluck("role", &roles).Error; err != nil {
+		return "", err
+	}
+	if len(roles) == 0 {
+		return "", gorm.ErrRecordNotFound
+	}
+	return roles[0], nil
+}
+
+func (r *UserRepository) UpdateUserRole(ctx context.Context, userID string, role domain.UserRole) error {
+	res := r.db.WithContext(ctx).Model(&domain.User{}).Where("id = ?", userID).Update("role", role)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// DeleteUser delete the user, its roles on kbs, its two-factor authentication and its api tokens
 func (r *UserRepository) DeleteUser(ctx context.Context, userID string) error {
-	return r.db.WithContext(ctx).Model(&domain.User{}).Where("id = ?", userID).Delete(&domain.User{}).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&domain.KBMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&domain.UserTwoFactor{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("created_by = ?", userID).Delete(&domain.APIToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&domain.User{}).Where("id = ?", userID).Delete(&domain.User{}).Error
+	})
 }
@@ -0,0 +1,76 @@
+package pg
+
+import (
+	"context"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/store/pg"
+)
+
+type WebhookRepository struct {
+	db *pg.DB
+}
+
+func NewWebhookRepository(db *pg.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+func (r *WebhookRepository) CreateWebhook(ctx context.Context, webhook *domain.Webhook) error {
+	return r.db.WithContext(ctx).Create(webhook).Error
+}
+
+// UpdateWebhook the secret is kept unless updateSecret
+func (r *WebhookRepository) UpdateWebhook(ctx context.Context, webhook *domain.Webhook, updateSecret bool) error {
+	columns := []any{"url", "events", "headers", "format", "template", "mapping", "enabled", "updated_at"}
+	if updateSecret {
+		columns = append(columns, "secret")
+	}
+	return r.db.WithContext(ctx).
+		Model(&domain.Webhook{}).
+		Where("id = ?", webhook.ID).
+		Where("kb_id = ?", webhook.KBID).
+		Select("name", columns...).
+		Updates(webhook).Error
+}
+
+func (r *WebhookRepository) DeleteWebhook(ctx context.Context, kbID, id string) error {
+	return r.db.WithContext(ctx).
+		Where("id = ?", id).
+		Where("kb_id = ?", kbID).
+		Delete(&domain.Webhook{}).Error
+}
+
+func (r *WebhookRepository) GetWebhook(ctx context.Context, kbID, id string) (*domain.Webhook, error) {
+	webhook := &domain.Webhook{}
+	if err := r.db.WithContext(ctx).
+		Where("id = ?", id).
+		Where("kb_id = ?", kbID).
+		First(webhook).Error; err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+func (r *WebhookRepository) GetWebhookList(ctx context.Context, kbID string) ([]*domain.Webhook, error) {
+	webhooks := []*domain.Webhook{}
+	if err := r.db.WithContext(ctx).
+		Where("kb_id = ?", kbID).
+		Order("created_at ASC").
+		Find(&webhooks).Error; err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// GetSubscribedWebhooks enabled webhooks of kb subscribed to the event
+func (r *WebhookRepository) GetSubscribedWebhooks(ctx context.Context, kbID string, event domain.WebhookEventType) ([]*domain.Webhook, error) {
+	webhooks := []*domain.Webhook{}
+	if err := r.db.WithContext(ctx).
+		Where("kb_id = ?", kbID).
+		Where("enabled = ?", true).
+		Where("events @> ?::jsonb", `["`+string(event)+`"]`).
+		Find(&webhooks).Error; err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
@@ -0,0 +1,58 @@
+package grpc
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/chaitin/panda-wiki/config"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/pkg/embedder"
+)
+
+// max size of a request or response, a batch of long texts or high dimension embeddings is large
+const maxMessageSize = 64 * 1024 * 1024
+
+type GRPCServer struct {
+	Server  *grpc.Server
+	Batcher *embedder.Batcher
+}
+
+// NewEmbedderBatcher batcher of the embedder service embedding by openai compatible apis
+func NewEmbedderBatcher(config *config.Config) *embedder.Batcher {
+	return embedder.NewBatcher(embedder.EmbedOpenAI, embedder.BatcherConfig{
+		BatchSize: config.Embedding.BatchSize,
+		BatchWait: time.Duration(config.Embedding.BatchWait) * time.Millisecond,
+		Workers:   config.Embedding.Workers,
+		QueueSize: config.Embedding.QueueSize,
+	})
+}
+
+func NewGRPCServer(logger *log.Logger, batcher *embedder.Batcher) *grpc.Server {
+	s := grpc.NewServer(
+		grpc.MaxRecvMsgSize(maxMessageSize),
+		grpc.MaxSendMsgSize(maxMessageSize),
+		grpc.UnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			start := time.Now()
+			resp, err := handler(ctx, req)
+			latency := time.Since(start).Milliseconds()
+			if err == nil {
+				logger.LogAttrs(ctx, slog.LevelDebug, "REQUEST",
+					slog.String("method", info.FullMethod),
+					slog.Int("latency", int(latency)),
+				)
+			} else {
+				logger.LogAttrs(ctx, slog.LevelError, "REQUEST_ERROR",
+					slog.String("method", info.FullMethod),
+					slog.Int("latency", int(latency)),
+					slog.String("err", err.Error()),
+				)
+			}
+			return resp, err
+		}),
+	)
+	embedder.Register(s, batcher)
+	return s
+}
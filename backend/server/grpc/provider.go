@@ -0,0 +1,11 @@
+package grpc
+
+import (
+	"github.com/google/wire"
+)
+
+var ProviderSet = wire.NewSet(
+	NewEmbedderBatcher,
+	NewGRPCServer,
+	wire.Struct(new(GRPCServer), "*"),
+)
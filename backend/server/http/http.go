@@ -2,9 +2,12 @@ package http
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
+	"strings"
 
 	"github.com/go-playground/validator"
 	"github.com/labstack/echo/v4"
@@ -43,6 +46,8 @@ func NewEcho(logger *log.Logger, config *config.Config) *echo.Echo {
 	}
 	// register validator
 	e.Validator = &echoValidator{validator: validator.New()}
+	// ip rules, rate limits and stats rely on the client ip, forwarded headers are only trusted from the proxies
+	e.IPExtractor = newIPExtractor(logger, config.HTTP.TrustedProxies)
 
 	if config.GetBool("apm.enabled") {
 		e.Use(middlewareOtel.Middleware(config.GetString("apm.service_name")))
@@ -87,3 +92,44 @@ func NewEcho(logger *log.Logger, config *config.Config) *echo.Echo {
 
 	return e
 }
+
+// newIPExtractor client ip from X-Forwarded-For of requests of the trusted proxies, from the connection otherwise
+func newIPExtractor(logger *log.Logger, trustedProxies []string) echo.IPExtractor {
+	ranges := make([]echo.TrustOption, 0, len(trustedProxies))
+	for _, proxy := range trustedProxies {
+		if proxy = strings.TrimSpace(proxy); proxy == "" {
+			continue
+		}
+		ipNet, err := parseTrustedProxy(proxy)
+		if err != nil {
+			logger.Error("invalid trusted proxy is ignored", log.String("proxy", proxy), log.Error(err))
+			continue
+		}
+		ranges = append(ranges, echo.TrustIPRange(ipNet))
+	}
+	if len(ranges) == 0 {
+		return echo.ExtractIPDirect()
+	}
+	// only the configured proxies, not every loopback or private address
+	options := append([]echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}, ranges...)
+	return echo.ExtractIPFromXFFHeader(options...)
+}
+
+func parseTrustedProxy(proxy string) (*net.IPNet, error) {
+	if strings.Contains(proxy, "/") {
+		_, ipNet, err := net.ParseCIDR(proxy)
+		return ipNet, err
+	}
+	ip := net.ParseIP(proxy)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid ip %q", proxy)
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}, nil
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+}
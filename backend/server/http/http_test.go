@@ -0,0 +1,40 @@
+package http
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chaitin/panda-wiki/config"
+	"github.com/chaitin/panda-wiki/log"
+)
+
+func TestIPExtractor(t *testing.T) {
+	logger := log.NewLogger(&config.Config{})
+	tests := []struct {
+		name       string
+		proxies    []string
+		remoteAddr string
+		xff        string
+		want       string
+	}{
+		{name: "no proxy ignores forged header", remoteAddr: "203.0.113.7:5000", xff: "10.0.0.1", want: "203.0.113.7"},
+		{name: "untrusted peer ignores forged header", proxies: []string{"169.254.15.0/24"}, remoteAddr: "203.0.113.7:5000", xff: "10.0.0.1", want: "203.0.113.7"},
+		{name: "private peer not configured is untrusted", proxies: []string{"169.254.15.0/24"}, remoteAddr: "192.168.1.2:5000", xff: "10.0.0.1", want: "192.168.1.2"},
+		{name: "trusted proxy forwards client ip", proxies: []string{"169.254.15.0/24"}, remoteAddr: "169.254.15.2:5000", xff: "203.0.113.7", want: "203.0.113.7"},
+		{name: "client prepended value is not trusted", proxies: []string{"169.254.15.0/24"}, remoteAddr: "169.254.15.2:5000", xff: "10.0.0.1, 203.0.113.7", want: "203.0.113.7"},
+		{name: "trusted single ip proxy", proxies: []string{"169.254.15.2"}, remoteAddr: "169.254.15.2:5000", xff: "203.0.113.7", want: "203.0.113.7"},
+		{name: "trusted ipv6 proxy", proxies: []string{"fd00::/8"}, remoteAddr: "[fd00::2]:5000", xff: "2001:db8::7", want: "2001:db8::7"},
+		{name: "invalid proxies fall back to the connection", proxies: []string{"not-a-cidr"}, remoteAddr: "203.0.113.7:5000", xff: "10.0.0.1", want: "203.0.113.7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/user", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("X-Forwarded-For", tt.xff)
+			req.Header.Set("X-Real-IP", "10.0.0.2")
+			if got := newIPExtractor(logger, tt.proxies)(req); got != tt.want {
+				t.Errorf("ip = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
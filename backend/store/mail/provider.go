@@ -0,0 +1,5 @@
+package mail
+
+import "github.com/google/wire"
+
+var ProviderSet = wire.NewSet(NewMailer)
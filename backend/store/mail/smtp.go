@@ -0,0 +1,96 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/chaitin/panda-wiki/config"
+)
+
+var ErrMailNotConfigured = errors.New("smtp is not configured")
+
+type Mailer struct {
+	config *config.Config
+}
+
+func NewMailer(config *config.Config) *Mailer {
+	return &Mailer{config: config}
+}
+
+func (m *Mailer) Enabled() bool {
+	return m.config.SMTP.Host != "" && m.config.SMTP.From != ""
+}
+
+// SendHTML send html mail, port 465 uses implicit tls, others upgrade with STARTTLS if supported
+func (m *Mailer) SendHTML(ctx context.Context, to, subject, body string) error {
+	if !m.Enabled() {
+		return ErrMailNotConfigured
+	}
+	cfg := m.config.SMTP
+	addr := net.JoinHostPort(cfg.Host, fmt.Sprintf("%d", cfg.Port))
+
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	var conn net.Conn
+	var err error
+	if cfg.Port == 465 {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: cfg.Host}}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("dial smtp server failed: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	client, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("create smtp client failed: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok && cfg.Port != 465 {
+		if err := client.StartTLS(&tls.Config{ServerName: cfg.Host}); err != nil {
+			return fmt.Errorf("smtp starttls failed: %w", err)
+		}
+	}
+	if cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)); err != nil {
+			return fmt.Errorf("smtp auth failed: %w", err)
+		}
+	}
+	if err := client.Mail(cfg.From); err != nil {
+		return fmt.Errorf("smtp mail from failed: %w", err)
+	}
+	if err := client.Rcpt(to); err != nil {
+		return fmt.Errorf("smtp rcpt to failed: %w", err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp data failed: %w", err)
+	}
+	headers := []string{
+		"From: " + cfg.From,
+		"To: " + to,
+		"Subject: " + mime.BEncoding.Encode("UTF-8", subject),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: text/html; charset=UTF-8",
+		"Content-Transfer-Encoding: 8bit",
+	}
+	if _, err := w.Write([]byte(strings.Join(headers, "\r\n") + "\r\n\r\n" + body)); err != nil {
+		return fmt.Errorf("write mail body failed: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("send mail failed: %w", err)
+	}
+	return client.Quit()
+}
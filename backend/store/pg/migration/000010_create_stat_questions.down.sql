@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS stat_questions;
+DROP TABLE IF EXISTS stat_question_clusters;
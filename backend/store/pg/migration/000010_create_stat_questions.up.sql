@@ -0,0 +1,25 @@
+-- create table stat_question_clusters for top question analytics
+CREATE TABLE IF NOT EXISTS stat_question_clusters (
+    id TEXT PRIMARY KEY,
+    kb_id TEXT NOT NULL,
+    label TEXT NOT NULL,
+    centroid JSONB NOT NULL,
+    count BIGINT NOT NULL DEFAULT 0,
+    created_at timestamptz NOT NULL DEFAULT NOW(),
+    updated_at timestamptz NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stat_question_clusters_kb_id ON stat_question_clusters(kb_id);
+
+-- create table stat_questions, one row per embedded user question
+CREATE TABLE IF NOT EXISTS stat_questions (
+    id TEXT PRIMARY KEY,
+    kb_id TEXT NOT NULL,
+    conversation_id TEXT NOT NULL,
+    cluster_id TEXT NOT NULL,
+    question TEXT NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stat_questions_kb_id_created_at ON stat_questions(kb_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_stat_questions_cluster_id ON stat_questions(cluster_id);
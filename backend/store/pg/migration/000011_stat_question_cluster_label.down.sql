@@ -0,0 +1 @@
+ALTER TABLE "public"."stat_question_clusters" DROP COLUMN "label_score";
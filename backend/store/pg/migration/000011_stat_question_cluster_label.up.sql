@@ -0,0 +1,2 @@
+-- similarity between the cluster label and the centroid, used to pick a representative label
+ALTER TABLE "public"."stat_question_clusters" ADD COLUMN "label_score" double precision NOT NULL DEFAULT 0;
@@ -0,0 +1,5 @@
+ALTER TABLE "public"."stat_pages" DROP COLUMN "utm_source";
+ALTER TABLE "public"."stat_pages" DROP COLUMN "utm_medium";
+ALTER TABLE "public"."stat_pages" DROP COLUMN "utm_campaign";
+ALTER TABLE "public"."stat_pages" DROP COLUMN "utm_term";
+ALTER TABLE "public"."stat_pages" DROP COLUMN "utm_content";
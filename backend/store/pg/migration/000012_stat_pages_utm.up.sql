@@ -0,0 +1,6 @@
+-- utm parameters of the landing url for traffic attribution
+ALTER TABLE "public"."stat_pages" ADD COLUMN "utm_source" text NULL;
+ALTER TABLE "public"."stat_pages" ADD COLUMN "utm_medium" text NULL;
+ALTER TABLE "public"."stat_pages" ADD COLUMN "utm_campaign" text NULL;
+ALTER TABLE "public"."stat_pages" ADD COLUMN "utm_term" text NULL;
+ALTER TABLE "public"."stat_pages" ADD COLUMN "utm_content" text NULL;
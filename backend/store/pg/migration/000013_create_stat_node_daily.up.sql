@@ -0,0 +1,14 @@
+-- create table stat_node_daily, per node daily views, dwell time and rag citations
+CREATE TABLE IF NOT EXISTS stat_node_daily (
+    kb_id TEXT NOT NULL,
+    node_id TEXT NOT NULL,
+    date DATE NOT NULL,
+    views BIGINT NOT NULL DEFAULT 0,
+    visitors BIGINT NOT NULL DEFAULT 0,
+    dwell_total BIGINT NOT NULL DEFAULT 0,
+    dwell_count BIGINT NOT NULL DEFAULT 0,
+    citations BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (node_id, date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stat_node_daily_kb_id_date ON stat_node_daily(kb_id, date);
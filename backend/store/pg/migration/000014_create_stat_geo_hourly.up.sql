@@ -0,0 +1,13 @@
+-- create table stat_geo_hourly, visitor locations aggregated by hour and app
+CREATE TABLE IF NOT EXISTS stat_geo_hourly (
+    kb_id TEXT NOT NULL,
+    app_id TEXT NOT NULL,
+    hour timestamptz NOT NULL,
+    country TEXT NOT NULL,
+    province TEXT NOT NULL,
+    city TEXT NOT NULL,
+    count BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (kb_id, app_id, hour, country, province, city)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stat_geo_hourly_kb_id_hour ON stat_geo_hourly(kb_id, hour);
@@ -0,0 +1,2 @@
+-- per kb content policy
+ALTER TABLE "public"."knowledge_bases" ADD COLUMN "compliance_settings" jsonb NOT NULL DEFAULT '{}';
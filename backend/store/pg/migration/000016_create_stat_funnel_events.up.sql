@@ -0,0 +1,10 @@
+-- create table stat_funnel_events, visit -> search -> chat -> resolution steps of sessions
+CREATE TABLE IF NOT EXISTS stat_funnel_events (
+    id BIGSERIAL PRIMARY KEY,
+    kb_id TEXT NOT NULL,
+    session_id TEXT NOT NULL,
+    step TEXT NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stat_funnel_events_kb_id_created_at ON stat_funnel_events(kb_id, created_at);
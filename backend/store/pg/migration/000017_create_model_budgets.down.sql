@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS model_usage_monthly;
+DROP TABLE IF EXISTS model_budgets;
@@ -0,0 +1,27 @@
+-- create table model_budgets, monthly budget and exceeded action per model
+CREATE TABLE IF NOT EXISTS model_budgets (
+    model_id TEXT PRIMARY KEY,
+    monthly_budget DOUBLE PRECISION NOT NULL DEFAULT 0,
+    monthly_token_limit BIGINT NOT NULL DEFAULT 0,
+    prompt_price DOUBLE PRECISION NOT NULL DEFAULT 0,
+    completion_price DOUBLE PRECISION NOT NULL DEFAULT 0,
+    notify_thresholds JSONB NOT NULL DEFAULT '[]',
+    notify_webhook JSONB NOT NULL DEFAULT '{}',
+    exceeded_action TEXT NOT NULL DEFAULT 'disable',
+    exceeded_message TEXT NOT NULL DEFAULT '',
+    fallback_model JSONB NOT NULL DEFAULT '{}',
+    created_at timestamptz NOT NULL DEFAULT NOW(),
+    updated_at timestamptz NOT NULL DEFAULT NOW()
+);
+
+-- create table model_usage_monthly, token usage and spend per model per month
+CREATE TABLE IF NOT EXISTS model_usage_monthly (
+    model_id TEXT NOT NULL,
+    month DATE NOT NULL,
+    prompt_tokens BIGINT NOT NULL DEFAULT 0,
+    completion_tokens BIGINT NOT NULL DEFAULT 0,
+    total_tokens BIGINT NOT NULL DEFAULT 0,
+    cost DOUBLE PRECISION NOT NULL DEFAULT 0,
+    notified_threshold INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (model_id, month)
+);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS conversation_transcript_emails (
+    id BIGSERIAL PRIMARY KEY,
+    kb_id TEXT NOT NULL,
+    conversation_id TEXT NOT NULL,
+    email TEXT NOT NULL,
+    remote_ip TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_conversation_transcript_emails_kb_id_created_at ON conversation_transcript_emails (kb_id, created_at);
@@ -0,0 +1,3 @@
+ALTER TABLE "public"."conversations" DROP COLUMN IF EXISTS "is_bot";
+ALTER TABLE "public"."stat_pages" DROP COLUMN IF EXISTS "is_bot";
+ALTER TABLE "public"."knowledge_bases" DROP COLUMN IF EXISTS "stat_settings";
@@ -0,0 +1,4 @@
+-- mark bot traffic instead of dropping it, filtered from stats by kb setting
+ALTER TABLE "public"."knowledge_bases" ADD COLUMN "stat_settings" jsonb NOT NULL DEFAULT '{}';
+ALTER TABLE "public"."stat_pages" ADD COLUMN "is_bot" boolean NOT NULL DEFAULT false;
+ALTER TABLE "public"."conversations" ADD COLUMN "is_bot" boolean NOT NULL DEFAULT false;
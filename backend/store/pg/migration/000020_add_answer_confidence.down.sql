@@ -0,0 +1,3 @@
+ALTER TABLE "public"."conversation_messages" DROP COLUMN IF EXISTS "low_confidence";
+ALTER TABLE "public"."conversation_messages" DROP COLUMN IF EXISTS "confidence";
+ALTER TABLE "public"."knowledge_bases" DROP COLUMN IF EXISTS "answer_settings";
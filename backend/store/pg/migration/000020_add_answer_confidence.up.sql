@@ -0,0 +1,4 @@
+-- confidence gated answers
+ALTER TABLE "public"."knowledge_bases" ADD COLUMN "answer_settings" jsonb NOT NULL DEFAULT '{}';
+ALTER TABLE "public"."conversation_messages" ADD COLUMN "confidence" double precision NOT NULL DEFAULT 0;
+ALTER TABLE "public"."conversation_messages" ADD COLUMN "low_confidence" boolean NOT NULL DEFAULT false;
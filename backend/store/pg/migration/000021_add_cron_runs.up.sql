@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS cron_runs (
+    id BIGSERIAL PRIMARY KEY,
+    job TEXT NOT NULL,
+    status TEXT NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    started_at TIMESTAMPTZ NOT NULL,
+    duration_ms BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_cron_runs_job_started_at ON cron_runs (job, started_at);
@@ -0,0 +1,2 @@
+ALTER TABLE "public"."nodes" DROP COLUMN IF EXISTS "inherited_fields";
+ALTER TABLE "public"."nodes" DROP COLUMN IF EXISTS "defaults";
@@ -0,0 +1,3 @@
+-- folder defaults inherited by new child nodes, and fields of nodes still following them
+ALTER TABLE "public"."nodes" ADD COLUMN "defaults" jsonb NOT NULL DEFAULT '{}';
+ALTER TABLE "public"."nodes" ADD COLUMN "inherited_fields" jsonb NOT NULL DEFAULT '[]';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS node_versions;
+DROP TABLE IF EXISTS node_replace_jobs;
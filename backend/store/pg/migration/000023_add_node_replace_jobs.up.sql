@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS node_replace_jobs (
+    id TEXT PRIMARY KEY,
+    kb_id TEXT NOT NULL,
+    find TEXT NOT NULL,
+    replace TEXT NOT NULL DEFAULT '',
+    is_regex BOOLEAN NOT NULL DEFAULT false,
+    case_sensitive BOOLEAN NOT NULL DEFAULT false,
+    folder_ids jsonb NOT NULL DEFAULT '[]',
+    status TEXT NOT NULL,
+    node_count INT NOT NULL DEFAULT 0,
+    replace_count INT NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_node_replace_jobs_kb_id_created_at ON node_replace_jobs (kb_id, created_at);
+
+-- content of nodes before bulk changes, for rollback
+CREATE TABLE IF NOT EXISTS node_versions (
+    id BIGSERIAL PRIMARY KEY,
+    kb_id TEXT NOT NULL,
+    node_id TEXT NOT NULL,
+    job_id TEXT NOT NULL DEFAULT '',
+    name TEXT NOT NULL,
+    content TEXT NOT NULL,
+    -- content written by the job, rollback skips nodes edited since
+    new_content TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_node_versions_job_id ON node_versions (job_id);
+CREATE INDEX IF NOT EXISTS idx_node_versions_node_id ON node_versions (node_id);
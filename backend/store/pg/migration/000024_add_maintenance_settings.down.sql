@@ -0,0 +1,2 @@
+ALTER TABLE "public"."knowledge_bases" DROP COLUMN IF EXISTS "maintenance_settings";
+DROP TABLE IF EXISTS "public"."settings";
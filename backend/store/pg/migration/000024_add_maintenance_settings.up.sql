@@ -0,0 +1,9 @@
+-- global settings, one row per key
+CREATE TABLE IF NOT EXISTS "public"."settings" (
+    "key" text NOT NULL,
+    "value" jsonb NOT NULL DEFAULT '{}',
+    "updated_at" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("key")
+);
+-- per kb read-only switch
+ALTER TABLE "public"."knowledge_bases" ADD COLUMN "maintenance_settings" jsonb NOT NULL DEFAULT '{}';
@@ -0,0 +1,2 @@
+ALTER TABLE "public"."knowledge_bases" DROP COLUMN IF EXISTS "review_settings";
+DROP TABLE IF EXISTS "public"."node_reviews";
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS "public"."node_reviews" (
+    "id" text NOT NULL,
+    "kb_id" text NOT NULL,
+    "node_id" text NOT NULL,
+    "node_name" text NOT NULL DEFAULT '',
+    -- version of the node under review, approval is void once the node changes
+    "node_updated_at" timestamptz NOT NULL,
+    "status" text NOT NULL,
+    "submitter_id" text NOT NULL,
+    "submit_comment" text NOT NULL DEFAULT '',
+    "reviewer_id" text NOT NULL DEFAULT '',
+    "review_comment" text NOT NULL DEFAULT '',
+    "created_at" timestamptz NOT NULL,
+    "reviewed_at" timestamptz NULL,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_node_reviews_kb_id_status" ON "public"."node_reviews" ("kb_id", "status");
+CREATE INDEX IF NOT EXISTS "idx_node_reviews_node_id" ON "public"."node_reviews" ("node_id");
+
+ALTER TABLE "public"."knowledge_bases" ADD COLUMN "review_settings" jsonb NOT NULL DEFAULT '{}';
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS "public"."webhooks" (
+    "id" text NOT NULL,
+    "kb_id" text NOT NULL,
+    "name" text NOT NULL DEFAULT '',
+    "url" text NOT NULL,
+    "events" jsonb NOT NULL DEFAULT '[]',
+    "secret" text NOT NULL DEFAULT '',
+    "headers" jsonb NOT NULL DEFAULT '{}',
+    -- payload format: empty for the raw event, template or jsonpath
+    "format" text NOT NULL DEFAULT '',
+    "template" text NOT NULL DEFAULT '',
+    "mapping" jsonb NOT NULL DEFAULT '{}',
+    "enabled" boolean NOT NULL DEFAULT true,
+    "created_at" timestamptz NOT NULL,
+    "updated_at" timestamptz NOT NULL,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_webhooks_kb_id" ON "public"."webhooks" ("kb_id");
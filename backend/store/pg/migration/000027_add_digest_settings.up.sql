@@ -0,0 +1,2 @@
+-- daily conversation digest of support standups
+ALTER TABLE "public"."knowledge_bases" ADD COLUMN "digest_settings" jsonb NOT NULL DEFAULT '{}';
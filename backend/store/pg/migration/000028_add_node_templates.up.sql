@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS "public"."node_templates" (
+    "id" text NOT NULL,
+    "kb_id" text NOT NULL,
+    "name" text NOT NULL,
+    "description" text NOT NULL DEFAULT '',
+    "category" text NOT NULL DEFAULT '',
+    "type" smallint NOT NULL DEFAULT 2,
+    "emoji" text NOT NULL DEFAULT '',
+    "content" text NOT NULL DEFAULT '',
+    "children" jsonb NOT NULL DEFAULT '[]',
+    "created_at" timestamptz NOT NULL,
+    "updated_at" timestamptz NOT NULL,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_node_templates_kb_id" ON "public"."node_templates" ("kb_id");
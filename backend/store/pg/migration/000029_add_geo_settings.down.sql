@@ -0,0 +1 @@
+ALTER TABLE "public"."knowledge_bases" DROP COLUMN IF EXISTS "geo_settings";
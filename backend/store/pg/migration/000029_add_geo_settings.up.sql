@@ -0,0 +1,2 @@
+-- region mapping of visitor countries to region-specific content
+ALTER TABLE "public"."knowledge_bases" ADD COLUMN "geo_settings" jsonb NOT NULL DEFAULT '{}';
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS "public"."node_attachments" (
+    "id" text NOT NULL,
+    "kb_id" text NOT NULL,
+    "node_id" text NOT NULL,
+    "name" text NOT NULL,
+    "key" text NOT NULL,
+    "size" bigint NOT NULL DEFAULT 0,
+    "content_type" text NOT NULL DEFAULT '',
+    "created_at" timestamptz NOT NULL,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_node_attachments_kb_id_node_id" ON "public"."node_attachments" ("kb_id", "node_id");
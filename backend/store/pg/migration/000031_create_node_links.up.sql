@@ -0,0 +1,18 @@
+-- intra-wiki links between nodes, target may point to a deleted node
+CREATE TABLE IF NOT EXISTS "public"."node_links" (
+    "kb_id" text NOT NULL,
+    "source_id" text NOT NULL,
+    "target_id" text NOT NULL,
+    PRIMARY KEY ("source_id", "target_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_node_links_kb_id_target_id" ON "public"."node_links" ("kb_id", "target_id");
+
+-- backfill links of existing nodes
+INSERT INTO "public"."node_links" ("kb_id", "source_id", "target_id")
+SELECT DISTINCT links.kb_id, links.id, links.target_id
+FROM (
+    SELECT kb_id, id, (regexp_matches(content, '/node/([0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12})', 'g'))[1] AS target_id
+    FROM "public"."nodes"
+) AS links
+WHERE links.target_id != links.id
+ON CONFLICT DO NOTHING;
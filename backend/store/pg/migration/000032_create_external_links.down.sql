@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "public"."external_link_checks";
+DROP TABLE IF EXISTS "public"."node_external_links";
@@ -0,0 +1,18 @@
+-- outbound urls of nodes, refreshed by the link check job
+CREATE TABLE IF NOT EXISTS "public"."node_external_links" (
+    "kb_id" text NOT NULL,
+    "node_id" text NOT NULL,
+    "url" text NOT NULL,
+    PRIMARY KEY ("node_id", "url")
+);
+CREATE INDEX IF NOT EXISTS "idx_node_external_links_kb_id" ON "public"."node_external_links" ("kb_id");
+
+-- check results shared by all nodes linking to the url
+CREATE TABLE IF NOT EXISTS "public"."external_link_checks" (
+    "url" text NOT NULL,
+    "broken" boolean NOT NULL DEFAULT false,
+    "status_code" integer NOT NULL DEFAULT 0,
+    "error" text NOT NULL DEFAULT '',
+    "checked_at" timestamptz NOT NULL,
+    PRIMARY KEY ("url")
+);
@@ -0,0 +1,2 @@
+ALTER TABLE "public"."conversation_messages" DROP COLUMN IF EXISTS "updated_at";
+ALTER TABLE "public"."conversation_messages" DROP COLUMN IF EXISTS "status";
@@ -0,0 +1,4 @@
+-- assistant answers are saved while streaming, content is checkpointed until completed
+ALTER TABLE "public"."conversation_messages" ADD COLUMN "status" text NOT NULL DEFAULT 'completed';
+ALTER TABLE "public"."conversation_messages" ADD COLUMN "updated_at" timestamptz NULL;
+UPDATE "public"."conversation_messages" SET "updated_at" = "created_at";
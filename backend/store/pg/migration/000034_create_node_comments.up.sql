@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS "public"."node_comments" (
+    "id" text NOT NULL,
+    "kb_id" text NOT NULL,
+    "node_id" text NOT NULL,
+    "parent_id" text NOT NULL DEFAULT '',
+    "root_id" text NOT NULL DEFAULT '',
+    "nick_name" text NOT NULL DEFAULT '',
+    "user_id" text NOT NULL DEFAULT '',
+    "content" text NOT NULL,
+    "status" text NOT NULL,
+    "spam_reason" text NOT NULL DEFAULT '',
+    "remote_ip" text NOT NULL DEFAULT '',
+    "moderated_by" text NOT NULL DEFAULT '',
+    "moderated_at" timestamptz NULL,
+    "created_at" timestamptz NOT NULL,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_node_comments_kb_id_status" ON "public"."node_comments" ("kb_id", "status");
+CREATE INDEX IF NOT EXISTS "idx_node_comments_node_id" ON "public"."node_comments" ("node_id");
+
+ALTER TABLE "public"."knowledge_bases" ADD COLUMN "comment_settings" jsonb NOT NULL DEFAULT '{}';
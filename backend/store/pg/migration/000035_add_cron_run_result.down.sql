@@ -0,0 +1 @@
+ALTER TABLE "public"."cron_runs" DROP COLUMN IF EXISTS "result";
@@ -0,0 +1,2 @@
+-- job specific result of a run, e.g. drift found by the index integrity check
+ALTER TABLE "public"."cron_runs" ADD COLUMN "result" jsonb NULL;
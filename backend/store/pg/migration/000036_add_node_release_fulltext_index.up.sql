@@ -0,0 +1,2 @@
+-- full-text search of published nodes, the expression must match NodeRepository.FullTextSearchNodeReleases
+CREATE INDEX IF NOT EXISTS "idx_node_releases_fulltext" ON "public"."node_releases" USING gin (to_tsvector('simple', coalesce("name", '') || ' ' || coalesce("content", '')));
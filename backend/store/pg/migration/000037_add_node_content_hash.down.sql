@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS "public"."idx_nodes_content_hash";
+ALTER TABLE "public"."nodes" DROP COLUMN IF EXISTS "content_hash";
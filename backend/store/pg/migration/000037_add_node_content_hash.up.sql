@@ -0,0 +1,5 @@
+-- hash of normalized content, imports look up identical documents across kbs by it.
+-- must match domain.ContentHash: md5 of lower case content without whitespace
+ALTER TABLE "public"."nodes" ADD COLUMN "content_hash" text NOT NULL DEFAULT '';
+UPDATE "public"."nodes" SET "content_hash" = md5(lower(regexp_replace("content", '[ \t\n\r\f\v]+', '', 'g'))) WHERE "content" <> '';
+CREATE INDEX IF NOT EXISTS "idx_nodes_content_hash" ON "public"."nodes" ("content_hash");
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS "public"."node_export_jobs" (
+    "id" text PRIMARY KEY,
+    "kb_id" text NOT NULL,
+    "status" text NOT NULL,
+    "node_count" int NOT NULL DEFAULT 0,
+    "attachment_count" int NOT NULL DEFAULT 0,
+    "size" bigint NOT NULL DEFAULT 0,
+    -- object key of the archive in the attachment bucket
+    "key" text NOT NULL DEFAULT '',
+    "error" text NOT NULL DEFAULT '',
+    "created_at" timestamptz NOT NULL DEFAULT NOW(),
+    "updated_at" timestamptz NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS "idx_node_export_jobs_kb_id_created_at" ON "public"."node_export_jobs" ("kb_id", "created_at");
@@ -0,0 +1 @@
+ALTER TABLE "public"."knowledge_bases" ADD COLUMN "branding_settings" jsonb NOT NULL DEFAULT '{}';
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS "idx_conversations_historical_kb_id";
+ALTER TABLE "public"."conversations" DROP COLUMN IF EXISTS "historical";
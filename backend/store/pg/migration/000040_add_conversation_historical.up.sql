@@ -0,0 +1,2 @@
+ALTER TABLE "public"."conversations" ADD COLUMN "historical" boolean NOT NULL DEFAULT false;
+CREATE INDEX IF NOT EXISTS "idx_conversations_historical_kb_id" ON "public"."conversations" ("kb_id") WHERE "historical";
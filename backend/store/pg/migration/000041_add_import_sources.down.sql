@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "public"."import_source_items";
+DROP TABLE IF EXISTS "public"."import_sources";
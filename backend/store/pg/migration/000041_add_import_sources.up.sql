@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS "public"."import_sources" (
+    "id" text PRIMARY KEY,
+    "kb_id" text NOT NULL,
+    "type" text NOT NULL,
+    "name" text NOT NULL DEFAULT '',
+    "parent_id" text NOT NULL DEFAULT '',
+    "settings" jsonb NOT NULL DEFAULT '{}',
+    "status" text NOT NULL,
+    "error" text NOT NULL DEFAULT '',
+    "page_count" int NOT NULL DEFAULT 0,
+    "changed_count" int NOT NULL DEFAULT 0,
+    "last_synced_at" timestamptz,
+    "created_at" timestamptz NOT NULL DEFAULT NOW(),
+    "updated_at" timestamptz NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS "idx_import_sources_kb_id" ON "public"."import_sources" ("kb_id");
+
+CREATE TABLE IF NOT EXISTS "public"."import_source_items" (
+    "source_id" text NOT NULL,
+    "kind" text NOT NULL,
+    "external_id" text NOT NULL,
+    "node_id" text NOT NULL,
+    "version" text NOT NULL DEFAULT '',
+    "ref" text NOT NULL DEFAULT '',
+    "updated_at" timestamptz NOT NULL DEFAULT NOW(),
+    PRIMARY KEY ("source_id", "kind", "external_id")
+);
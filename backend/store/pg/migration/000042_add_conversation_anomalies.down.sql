@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "public"."conversation_anomalies";
+ALTER TABLE "public"."knowledge_bases" DROP COLUMN IF EXISTS "anomaly_settings";
@@ -0,0 +1,15 @@
+ALTER TABLE "public"."knowledge_bases" ADD COLUMN "anomaly_settings" jsonb NOT NULL DEFAULT '{}';
+
+CREATE TABLE IF NOT EXISTS "public"."conversation_anomalies" (
+    "id" text PRIMARY KEY,
+    "kb_id" text NOT NULL,
+    "app_id" text NOT NULL DEFAULT '',
+    "remote_ip" text NOT NULL,
+    "type" text NOT NULL,
+    "detail" text NOT NULL DEFAULT '',
+    "count" bigint NOT NULL DEFAULT 0,
+    "blocked_until" timestamptz,
+    "created_at" timestamptz NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS "idx_conversation_anomalies_kb_id_created_at" ON "public"."conversation_anomalies" ("kb_id", "created_at");
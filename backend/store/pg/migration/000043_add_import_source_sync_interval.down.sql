@@ -0,0 +1 @@
+ALTER TABLE "public"."import_sources" DROP COLUMN IF EXISTS "sync_interval";
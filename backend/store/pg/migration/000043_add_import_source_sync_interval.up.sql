@@ -0,0 +1 @@
+ALTER TABLE "public"."import_sources" ADD COLUMN "sync_interval" int NOT NULL DEFAULT 0;
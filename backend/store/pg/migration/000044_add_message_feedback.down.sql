@@ -0,0 +1,3 @@
+ALTER TABLE "public"."conversation_references" DROP COLUMN IF EXISTS "message_id";
+ALTER TABLE "public"."conversation_messages" DROP COLUMN IF EXISTS "feedback_comment";
+ALTER TABLE "public"."conversation_messages" DROP COLUMN IF EXISTS "feedback_type";
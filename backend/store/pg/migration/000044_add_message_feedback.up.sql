@@ -0,0 +1,3 @@
+ALTER TABLE "public"."conversation_messages" ADD COLUMN "feedback_type" text NOT NULL DEFAULT '';
+ALTER TABLE "public"."conversation_messages" ADD COLUMN "feedback_comment" text NOT NULL DEFAULT '';
+ALTER TABLE "public"."conversation_references" ADD COLUMN "message_id" text NOT NULL DEFAULT '';
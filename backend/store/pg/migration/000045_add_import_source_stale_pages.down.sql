@@ -0,0 +1,2 @@
+ALTER TABLE "public"."import_source_items" DROP COLUMN IF EXISTS "stale_at";
+ALTER TABLE "public"."import_sources" DROP COLUMN IF EXISTS "stale_count";
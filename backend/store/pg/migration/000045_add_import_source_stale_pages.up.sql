@@ -0,0 +1,2 @@
+ALTER TABLE "public"."import_sources" ADD COLUMN "stale_count" int NOT NULL DEFAULT 0;
+ALTER TABLE "public"."import_source_items" ADD COLUMN "stale_at" timestamptz;
@@ -0,0 +1 @@
+ALTER TABLE "public"."conversation_messages" ADD COLUMN "route" text NOT NULL DEFAULT '';
@@ -0,0 +1,11 @@
+-- pairs of documents with similar content, refreshed by the near-duplicate detection job
+CREATE TABLE IF NOT EXISTS "public"."node_near_duplicates" (
+    "kb_id" text NOT NULL,
+    "node_id" text NOT NULL,
+    "other_node_id" text NOT NULL,
+    "similarity" double precision NOT NULL DEFAULT 0,
+    "dismissed" boolean NOT NULL DEFAULT false,
+    "detected_at" timestamptz NOT NULL,
+    PRIMARY KEY ("node_id", "other_node_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_node_near_duplicates_kb_id" ON "public"."node_near_duplicates" ("kb_id");
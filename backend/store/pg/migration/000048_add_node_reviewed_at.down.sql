@@ -0,0 +1,2 @@
+ALTER TABLE "public"."nodes" DROP COLUMN IF EXISTS "reviewed_by";
+ALTER TABLE "public"."nodes" DROP COLUMN IF EXISTS "reviewed_at";
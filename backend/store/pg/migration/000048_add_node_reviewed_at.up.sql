@@ -0,0 +1,2 @@
+ALTER TABLE "public"."nodes" ADD COLUMN "reviewed_at" timestamptz;
+ALTER TABLE "public"."nodes" ADD COLUMN "reviewed_by" text NOT NULL DEFAULT '';
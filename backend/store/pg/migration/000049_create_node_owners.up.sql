@@ -0,0 +1,13 @@
+-- owners of documents, reminded when their documents are due for review
+CREATE TABLE IF NOT EXISTS "public"."node_owners" (
+    "node_id" text PRIMARY KEY,
+    "kb_id" text NOT NULL,
+    "owner_id" text NOT NULL,
+    "review_interval_days" integer NOT NULL DEFAULT 0,
+    "reminded_at" timestamptz,
+    "created_at" timestamptz NOT NULL DEFAULT NOW(),
+    "updated_at" timestamptz NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS "idx_node_owners_kb_id_owner_id" ON "public"."node_owners" ("kb_id", "owner_id");
+
+ALTER TABLE "public"."knowledge_bases" ADD COLUMN "review_reminder_settings" jsonb NOT NULL DEFAULT '{}';
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS "public"."data_export_jobs" (
+    "id" text PRIMARY KEY,
+    "kb_id" text NOT NULL,
+    "scope" text NOT NULL,
+    -- identifiers of the end user for end_user scope
+    "user_id" text NOT NULL DEFAULT '',
+    "email" text NOT NULL DEFAULT '',
+    "remote_ip" text NOT NULL DEFAULT '',
+    "created_by" text NOT NULL DEFAULT '',
+    "status" text NOT NULL,
+    "record_count" int NOT NULL DEFAULT 0,
+    "size" bigint NOT NULL DEFAULT 0,
+    -- object key of the archive in the attachment bucket
+    "key" text NOT NULL DEFAULT '',
+    "error" text NOT NULL DEFAULT '',
+    "created_at" timestamptz NOT NULL DEFAULT NOW(),
+    "updated_at" timestamptz NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS "idx_data_export_jobs_kb_id_created_at" ON "public"."data_export_jobs" ("kb_id", "created_at");
@@ -0,0 +1,13 @@
+-- terminology of a kb, auto-linked in published documents and given to the model
+CREATE TABLE IF NOT EXISTS "public"."glossary_terms" (
+    "id" text PRIMARY KEY,
+    "kb_id" text NOT NULL,
+    "term" text NOT NULL,
+    "aliases" jsonb NOT NULL DEFAULT '[]',
+    "definition" text NOT NULL DEFAULT '',
+    -- document explaining the term
+    "node_id" text NOT NULL DEFAULT '',
+    "created_at" timestamptz NOT NULL DEFAULT NOW(),
+    "updated_at" timestamptz NOT NULL DEFAULT NOW()
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_glossary_terms_kb_id_term" ON "public"."glossary_terms" ("kb_id", "term");
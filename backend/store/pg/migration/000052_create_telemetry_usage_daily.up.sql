@@ -0,0 +1,9 @@
+-- anonymous usage of api features aggregated per day, kept only while telemetry is enabled
+CREATE TABLE IF NOT EXISTS "public"."telemetry_usage_daily" (
+    "day" date NOT NULL,
+    -- method and route of the api
+    "feature" text NOT NULL,
+    "requests" bigint NOT NULL DEFAULT 0,
+    "errors" bigint NOT NULL DEFAULT 0,
+    PRIMARY KEY ("day", "feature")
+);
@@ -0,0 +1,12 @@
+-- audience of published nodes, nodes without a rule are public
+CREATE TABLE IF NOT EXISTS "public"."node_acls" (
+    "node_id" text PRIMARY KEY,
+    "kb_id" text NOT NULL,
+    "access" text NOT NULL,
+    "groups" jsonb NOT NULL DEFAULT '[]',
+    -- the rule also covers all descendants of the node
+    "subtree" boolean NOT NULL DEFAULT false,
+    "created_at" timestamptz NOT NULL DEFAULT NOW(),
+    "updated_at" timestamptz NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS "idx_node_acls_kb_id" ON "public"."node_acls" ("kb_id");
@@ -0,0 +1,14 @@
+-- accounts of end readers of the public site, emails are lower case
+CREATE TABLE IF NOT EXISTS "public"."readers" (
+    "id" text PRIMARY KEY,
+    "kb_id" text NOT NULL,
+    "email" text NOT NULL,
+    "name" text NOT NULL DEFAULT '',
+    "password" text NOT NULL,
+    "groups" jsonb NOT NULL DEFAULT '[]',
+    "disabled" boolean NOT NULL DEFAULT false,
+    "last_login_at" timestamptz,
+    "created_at" timestamptz NOT NULL DEFAULT NOW(),
+    "updated_at" timestamptz NOT NULL DEFAULT NOW()
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_readers_kb_id_email" ON "public"."readers" ("kb_id", "email");
@@ -0,0 +1 @@
+ALTER TABLE "public"."conversations" DROP COLUMN IF EXISTS "title";
@@ -0,0 +1 @@
+ALTER TABLE "public"."conversations" ADD COLUMN "title" text NOT NULL DEFAULT '';
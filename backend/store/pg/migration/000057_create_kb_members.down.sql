@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "public"."kb_members";
+ALTER TABLE "public"."users" DROP COLUMN IF EXISTS "role";
@@ -0,0 +1,12 @@
+-- existing users keep access to every kb
+ALTER TABLE "public"."users" ADD COLUMN IF NOT EXISTS "role" text NOT NULL DEFAULT 'admin';
+-- roles of member users on kbs
+CREATE TABLE IF NOT EXISTS "public"."kb_members" (
+    "kb_id" text NOT NULL,
+    "user_id" text NOT NULL,
+    "role" text NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT NOW(),
+    "updated_at" timestamptz NOT NULL DEFAULT NOW(),
+    PRIMARY KEY ("kb_id", "user_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_kb_members_user_id" ON "public"."kb_members" ("user_id");
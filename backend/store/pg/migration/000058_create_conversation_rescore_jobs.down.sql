@@ -0,0 +1 @@
+DROP TABLE IF EXISTS "public"."conversation_rescore_jobs";
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS "public"."conversation_rescore_jobs" (
+    "id" text PRIMARY KEY,
+    "kb_id" text NOT NULL,
+    "days" int NOT NULL,
+    "limit" int NOT NULL,
+    -- candidate answer settings, null for the settings of the kb
+    "answer_settings" jsonb,
+    "created_by" text NOT NULL DEFAULT '',
+    "status" text NOT NULL,
+    "result" jsonb,
+    "error" text NOT NULL DEFAULT '',
+    "created_at" timestamptz NOT NULL DEFAULT NOW(),
+    "updated_at" timestamptz NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS "idx_conversation_rescore_jobs_kb_id_created_at" ON "public"."conversation_rescore_jobs" ("kb_id", "created_at");
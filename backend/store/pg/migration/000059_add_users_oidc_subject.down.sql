@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "idx_uniq_users_oidc_subject";
+
+ALTER TABLE "public"."users" DROP COLUMN IF EXISTS "oidc_subject";
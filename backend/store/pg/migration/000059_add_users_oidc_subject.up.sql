@@ -0,0 +1,3 @@
+ALTER TABLE "public"."users" ADD COLUMN IF NOT EXISTS "oidc_subject" text NOT NULL DEFAULT '';
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_uniq_users_oidc_subject" ON "public"."users" ("oidc_subject") WHERE "oidc_subject" <> '';
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS "idx_knowledge_bases_sandbox_token_id";
+
+ALTER TABLE "public"."knowledge_bases" DROP COLUMN IF EXISTS "sandbox_expires_at";
+ALTER TABLE "public"."knowledge_bases" DROP COLUMN IF EXISTS "sandbox_token_id";
+
+DROP TABLE IF EXISTS "public"."api_tokens";
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS "public"."api_tokens" (
+    "id" text PRIMARY KEY,
+    "name" text NOT NULL,
+    "scope" text NOT NULL,
+    -- sha256 of the token
+    "token_hash" text NOT NULL,
+    "prefix" text NOT NULL,
+    "max_sandboxes" int NOT NULL DEFAULT 0,
+    "created_by" text NOT NULL DEFAULT '',
+    "expires_at" timestamptz,
+    "last_used_at" timestamptz,
+    "created_at" timestamptz NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_uniq_api_tokens_token_hash" ON "public"."api_tokens" ("token_hash");
+
+ALTER TABLE "public"."knowledge_bases" ADD COLUMN IF NOT EXISTS "sandbox_token_id" text NOT NULL DEFAULT '';
+ALTER TABLE "public"."knowledge_bases" ADD COLUMN IF NOT EXISTS "sandbox_expires_at" timestamptz;
+
+CREATE INDEX IF NOT EXISTS "idx_knowledge_bases_sandbox_token_id" ON "public"."knowledge_bases" ("sandbox_token_id") WHERE "sandbox_token_id" <> '';
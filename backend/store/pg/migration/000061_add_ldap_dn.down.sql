@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS "idx_uniq_readers_kb_id_ldap_dn";
+DROP INDEX IF EXISTS "idx_uniq_users_ldap_dn";
+
+ALTER TABLE "public"."readers" DROP COLUMN IF EXISTS "ldap_dn";
+ALTER TABLE "public"."users" DROP COLUMN IF EXISTS "ldap_dn";
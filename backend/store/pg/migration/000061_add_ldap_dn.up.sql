@@ -0,0 +1,5 @@
+ALTER TABLE "public"."users" ADD COLUMN IF NOT EXISTS "ldap_dn" text NOT NULL DEFAULT '';
+ALTER TABLE "public"."readers" ADD COLUMN IF NOT EXISTS "ldap_dn" text NOT NULL DEFAULT '';
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_uniq_users_ldap_dn" ON "public"."users" ("ldap_dn") WHERE "ldap_dn" <> '';
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_uniq_readers_kb_id_ldap_dn" ON "public"."readers" ("kb_id", "ldap_dn") WHERE "ldap_dn" <> '';
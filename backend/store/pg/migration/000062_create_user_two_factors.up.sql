@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS "public"."user_two_factors" (
+    "user_id" text PRIMARY KEY,
+    -- base32 totp secret
+    "secret" text NOT NULL,
+    "enabled" boolean NOT NULL DEFAULT false,
+    -- sha256 of unused backup codes
+    "backup_codes" jsonb NOT NULL DEFAULT '[]',
+    "last_used_step" bigint NOT NULL DEFAULT 0,
+    "enabled_at" timestamptz,
+    "created_at" timestamptz NOT NULL DEFAULT NOW(),
+    "updated_at" timestamptz NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS "idx_api_tokens_created_by";
+
+ALTER TABLE "public"."api_tokens" ADD COLUMN IF NOT EXISTS "scope" text NOT NULL DEFAULT 'sandbox';
+
+-- only the sandbox scope existed before
+DELETE FROM "public"."api_tokens" WHERE NOT "scopes" ? 'sandbox';
+
+ALTER TABLE "public"."api_tokens" DROP COLUMN IF EXISTS "rotated_at";
+ALTER TABLE "public"."api_tokens" DROP COLUMN IF EXISTS "kb_id";
+ALTER TABLE "public"."api_tokens" DROP COLUMN IF EXISTS "scopes";
+ALTER TABLE "public"."api_tokens" DROP COLUMN IF EXISTS "kind";
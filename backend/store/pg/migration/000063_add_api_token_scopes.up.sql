@@ -0,0 +1,11 @@
+ALTER TABLE "public"."api_tokens" ADD COLUMN IF NOT EXISTS "kind" text NOT NULL DEFAULT 'service';
+ALTER TABLE "public"."api_tokens" ADD COLUMN IF NOT EXISTS "scopes" jsonb NOT NULL DEFAULT '[]';
+ALTER TABLE "public"."api_tokens" ADD COLUMN IF NOT EXISTS "kb_id" text NOT NULL DEFAULT '';
+ALTER TABLE "public"."api_tokens" ADD COLUMN IF NOT EXISTS "rotated_at" timestamptz;
+
+-- tokens created before are sandbox tokens of ci pipelines created by admins
+UPDATE "public"."api_tokens" SET "scopes" = jsonb_build_array("scope") WHERE "scopes" = '[]';
+
+ALTER TABLE "public"."api_tokens" DROP COLUMN IF EXISTS "scope";
+
+CREATE INDEX IF NOT EXISTS "idx_api_tokens_created_by" ON "public"."api_tokens" ("created_by");
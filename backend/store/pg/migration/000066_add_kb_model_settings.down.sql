@@ -0,0 +1,4 @@
+ALTER TABLE "public"."knowledge_bases" DROP COLUMN IF EXISTS "model_settings";
+
+DROP INDEX IF EXISTS "idx_models_type";
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_models_type" ON "public"."models" ("type");
@@ -0,0 +1,5 @@
+-- several models of a type may be configured, kbs select theirs in model_settings
+DROP INDEX IF EXISTS "idx_models_type";
+CREATE INDEX IF NOT EXISTS "idx_models_type" ON "public"."models" ("type");
+
+ALTER TABLE "public"."knowledge_bases" ADD COLUMN IF NOT EXISTS "model_settings" jsonb NOT NULL DEFAULT '{}';
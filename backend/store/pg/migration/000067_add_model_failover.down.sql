@@ -0,0 +1,5 @@
+ALTER TABLE "public"."conversation_messages" DROP COLUMN IF EXISTS "failovers";
+ALTER TABLE "public"."conversation_messages" DROP COLUMN IF EXISTS "model_id";
+
+ALTER TABLE "public"."models" DROP COLUMN IF EXISTS "weight";
+ALTER TABLE "public"."models" DROP COLUMN IF EXISTS "priority";
@@ -0,0 +1,7 @@
+-- chat models with a priority form the failover pool, load balanced by weight within a priority
+ALTER TABLE "public"."models" ADD COLUMN IF NOT EXISTS "priority" int NOT NULL DEFAULT 0;
+ALTER TABLE "public"."models" ADD COLUMN IF NOT EXISTS "weight" int NOT NULL DEFAULT 0;
+
+-- the endpoint which served each answer
+ALTER TABLE "public"."conversation_messages" ADD COLUMN IF NOT EXISTS "model_id" text NOT NULL DEFAULT '';
+ALTER TABLE "public"."conversation_messages" ADD COLUMN IF NOT EXISTS "failovers" int NOT NULL DEFAULT 0;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS app_prompt_templates (
+    id TEXT PRIMARY KEY,
+    kb_id TEXT NOT NULL,
+    app_id TEXT NOT NULL,
+    version INT NOT NULL,
+    -- empty resets the app to the built-in prompt
+    content TEXT NOT NULL DEFAULT '',
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_app_prompt_templates_app_id_version ON app_prompt_templates (app_id, version);
+CREATE INDEX IF NOT EXISTS idx_app_prompt_templates_kb_id ON app_prompt_templates (kb_id);
@@ -0,0 +1,2 @@
+-- scores of the documents of each answer before and after the rerank stage
+ALTER TABLE "public"."conversation_messages" ADD COLUMN IF NOT EXISTS "rerank_scores" jsonb;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS "public"."eval_results";
+DROP TABLE IF EXISTS "public"."eval_runs";
+DROP TABLE IF EXISTS "public"."eval_questions";
+DROP TABLE IF EXISTS "public"."eval_sets";
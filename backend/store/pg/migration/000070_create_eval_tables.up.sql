@@ -0,0 +1,64 @@
+CREATE TABLE IF NOT EXISTS "public"."eval_sets" (
+    "id" text PRIMARY KEY,
+    "kb_id" text NOT NULL,
+    "name" text NOT NULL,
+    "description" text NOT NULL DEFAULT '',
+    "created_at" timestamptz NOT NULL DEFAULT NOW(),
+    "updated_at" timestamptz NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS "idx_eval_sets_kb_id" ON "public"."eval_sets" ("kb_id");
+
+CREATE TABLE IF NOT EXISTS "public"."eval_questions" (
+    "id" text PRIMARY KEY,
+    "kb_id" text NOT NULL,
+    "set_id" text NOT NULL,
+    "question" text NOT NULL,
+    "expected_answer" text NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT NOW(),
+    "updated_at" timestamptz NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS "idx_eval_questions_set_id" ON "public"."eval_questions" ("set_id");
+
+CREATE TABLE IF NOT EXISTS "public"."eval_runs" (
+    "id" text PRIMARY KEY,
+    "kb_id" text NOT NULL,
+    "set_id" text NOT NULL,
+    "app_id" text NOT NULL DEFAULT '',
+    "note" text NOT NULL DEFAULT '',
+    -- model and prompt of the answers, filled when the run starts
+    "provider" text NOT NULL DEFAULT '',
+    "model" text NOT NULL DEFAULT '',
+    "model_id" text NOT NULL DEFAULT '',
+    "prompt_template_version" int NOT NULL DEFAULT 0,
+    "judge_model" text NOT NULL DEFAULT '',
+    "created_by" text NOT NULL DEFAULT '',
+    "status" text NOT NULL,
+    "summary" jsonb,
+    "error" text NOT NULL DEFAULT '',
+    "created_at" timestamptz NOT NULL DEFAULT NOW(),
+    "updated_at" timestamptz NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS "idx_eval_runs_kb_id_created_at" ON "public"."eval_runs" ("kb_id", "created_at");
+CREATE INDEX IF NOT EXISTS "idx_eval_runs_set_id_created_at" ON "public"."eval_runs" ("set_id", "created_at");
+
+CREATE TABLE IF NOT EXISTS "public"."eval_results" (
+    "id" text PRIMARY KEY,
+    "run_id" text NOT NULL,
+    "question_id" text NOT NULL,
+    "question" text NOT NULL,
+    "expected_answer" text NOT NULL,
+    "answer" text NOT NULL DEFAULT '',
+    "node_ids" jsonb NOT NULL DEFAULT '[]'::jsonb,
+    "faithfulness" double precision NOT NULL DEFAULT 0,
+    "relevance" double precision NOT NULL DEFAULT 0,
+    "passed" boolean NOT NULL DEFAULT false,
+    "reason" text NOT NULL DEFAULT '',
+    "error" text NOT NULL DEFAULT '',
+    "latency_ms" bigint NOT NULL DEFAULT 0,
+    "created_at" timestamptz NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS "idx_eval_results_run_id" ON "public"."eval_results" ("run_id");
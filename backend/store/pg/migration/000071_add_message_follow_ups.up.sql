@@ -0,0 +1,2 @@
+-- follow-up questions suggested after each answer, with the documents answering them
+ALTER TABLE "public"."conversation_messages" ADD COLUMN IF NOT EXISTS "follow_ups" jsonb;
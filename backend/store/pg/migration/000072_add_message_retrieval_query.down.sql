@@ -0,0 +1 @@
+ALTER TABLE "public"."conversation_messages" DROP COLUMN IF EXISTS "retrieval_query";
@@ -0,0 +1,2 @@
+-- standalone query follow-up questions were rewritten to for retrieval
+ALTER TABLE "public"."conversation_messages" ADD COLUMN IF NOT EXISTS "retrieval_query" text NOT NULL DEFAULT '';
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS "public"."answer_cache_entries" (
+    "id" text PRIMARY KEY,
+    "kb_id" text NOT NULL,
+    "app_id" text NOT NULL,
+    "locale" text NOT NULL DEFAULT '',
+    "region" text NOT NULL DEFAULT '',
+    "citation_style" text NOT NULL DEFAULT '',
+    "question" text NOT NULL,
+    "embedding" jsonb NOT NULL,
+    "message_id" text NOT NULL DEFAULT '',
+    "answer" text NOT NULL,
+    -- documents of the answer, the entry is dropped when one of them changes
+    "node_ids" jsonb NOT NULL DEFAULT '[]',
+    "chunk_results" jsonb,
+    "references" jsonb,
+    "follow_ups" jsonb,
+    "confidence" double precision NOT NULL DEFAULT 0,
+    "hits" int NOT NULL DEFAULT 0,
+    "expires_at" timestamptz NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS "idx_answer_cache_entries_kb_id_app_id" ON "public"."answer_cache_entries" ("kb_id", "app_id", "created_at");
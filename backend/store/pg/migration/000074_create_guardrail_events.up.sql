@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS "public"."guardrail_events" (
+    "id" text PRIMARY KEY,
+    "kb_id" text NOT NULL,
+    "app_id" text NOT NULL,
+    "conversation_id" text NOT NULL DEFAULT '',
+    "check_type" text NOT NULL,
+    "stage" text NOT NULL,
+    "action" text NOT NULL,
+    "detail" text NOT NULL DEFAULT '',
+    "question" text NOT NULL DEFAULT '',
+    "remote_ip" text NOT NULL DEFAULT '',
+    "created_at" timestamptz NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS "idx_guardrail_events_kb_id_created_at" ON "public"."guardrail_events" ("kb_id", "created_at");
@@ -0,0 +1,2 @@
+-- version of the prompt template of the app an answer was generated with, 0 for the built-in prompt
+ALTER TABLE "public"."conversation_messages" ADD COLUMN IF NOT EXISTS "prompt_template_version" int NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+-- dataset filled in background after the embedding model of the kb changed, searches use dataset_id until it is filled
+ALTER TABLE "public"."knowledge_bases" ADD COLUMN IF NOT EXISTS "pending_dataset_id" text NOT NULL DEFAULT '';
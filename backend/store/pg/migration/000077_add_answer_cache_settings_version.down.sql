@@ -0,0 +1 @@
+ALTER TABLE "public"."answer_cache_entries" DROP COLUMN IF EXISTS "settings_version";
@@ -0,0 +1,4 @@
+-- answers are only served with the prompt template, guardrails and content policy they were generated with,
+-- entries cached before are dropped as their settings are unknown
+DELETE FROM "public"."answer_cache_entries";
+ALTER TABLE "public"."answer_cache_entries" ADD COLUMN IF NOT EXISTS "settings_version" text NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+-- clusters were created with label score 1 which no question could beat, let later questions relabel them
+UPDATE "public"."stat_question_clusters" SET "label_score" = 0 WHERE "label_score" >= 1;
@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	htmltomarkdown "github.com/JohannesKaufmann/html-to-markdown/v2"
 	"github.com/google/uuid"
@@ -18,8 +20,9 @@ import (
 )
 
 type CTRAG struct {
-	client *rag.Client
-	logger *log.Logger
+	client    *rag.Client
+	dimension int
+	logger    *log.Logger
 }
 
 func NewCTRAG(config *config.Config, logger *log.Logger) (*CTRAG, error) {
@@ -28,14 +31,16 @@ func NewCTRAG(config *config.Config, logger *log.Logger) (*CTRAG, error) {
 		config.RAG.CTRAG.APIKey,
 	)
 	return &CTRAG{
-		client: client,
-		logger: logger.WithModule("store.vector.ct"),
+		client:    client,
+		dimension: config.RAG.Dimension,
+		logger:    logger.WithModule("store.vector.ct"),
 	}, nil
 }
 
-func (s *CTRAG) CreateKnowledgeBase(ctx context.Context) (string, error) {
+func (s *CTRAG) CreateKnowledgeBase(ctx context.Context, embeddingModelID string) (string, error) {
 	dataset, err := s.client.CreateDataset(ctx, rag.CreateDatasetRequest{
-		Name: uuid.New().String(),
+		Name:           uuid.New().String(),
+		EmbeddingModel: embeddingModelID,
 	})
 	if err != nil {
 		return "", err
@@ -43,11 +48,12 @@ func (s *CTRAG) CreateKnowledgeBase(ctx context.Context) (string, error) {
 	return dataset.ID, nil
 }
 
-func (s *CTRAG) QueryRecords(ctx context.Context, datasetIDs []string, query string) ([]*domain.NodeContentChunk, error) {
+func (s *CTRAG) QueryRecords(ctx context.Context, datasetIDs []string, query string, rerankModelID string) ([]*domain.NodeContentChunk, error) {
 	chunks, _, err := s.client.RetrieveChunks(ctx, rag.RetrievalRequest{
 		DatasetIDs: datasetIDs,
 		Question:   query,
 		TopK:       10,
+		RerankID:   rerankModelID,
 		// SimilarityThreshold: 0.2,
 	})
 	if err != nil {
@@ -60,6 +66,8 @@ func (s *CTRAG) QueryRecords(ctx context.Context, datasetIDs []string, query str
 			ID:      chunk.ID,
 			Content: chunk.Content,
 			DocID:   chunk.DocumentID,
+
+			Similarity: chunk.Similarity,
 		}
 	}
 	return nodeChunks, nil
@@ -103,6 +111,39 @@ func (s *CTRAG) DeleteRecords(ctx context.Context, datasetID string, docIDs []st
 	return nil
 }
 
+// ListRecords all documents of the dataset with their parse status
+func (s *CTRAG) ListRecords(ctx context.Context, datasetID string) ([]*domain.RAGDocument, error) {
+	const pageSize = 100
+	records := make([]*domain.RAGDocument, 0)
+	for page := 1; ; page++ {
+		docs, total, err := s.client.ListDocuments(ctx, datasetID, map[string]string{
+			"page":      strconv.Itoa(page),
+			"page_size": strconv.Itoa(pageSize),
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, doc := range docs {
+			status := domain.RAGDocumentStatusPending
+			switch doc.Run {
+			case "DONE":
+				status = domain.RAGDocumentStatusDone
+			case "FAIL", "CANCEL":
+				status = domain.RAGDocumentStatusFailed
+			}
+			records = append(records, &domain.RAGDocument{
+				ID:         doc.ID,
+				Status:     status,
+				ChunkCount: doc.ChunkCount,
+				CreatedAt:  time.UnixMilli(doc.CreateTime),
+			})
+		}
+		if len(docs) < pageSize || len(records) >= total {
+			return records, nil
+		}
+	}
+}
+
 func (s *CTRAG) DeleteKnowledgeBase(ctx context.Context, datasetID string) error {
 	if err := s.client.DeleteDatasets(ctx, []string{datasetID}); err != nil {
 		return err
@@ -120,7 +161,7 @@ func (s *CTRAG) AddModel(ctx context.Context, model *domain.Model) (string, erro
 		MaxTokens: 8192,
 		IsDefault: true,
 		Enabled:   true,
-		Config:    json.RawMessage(`{"max_context": 8192, "chunk_size": 1024, "chunk_overlap": 128, "dimension": 1536}`),
+		Config:    s.modelConfig(),
 	}
 	modelConfig, err := s.client.AddModelConfig(ctx, addReq)
 	if err != nil {
@@ -139,7 +180,7 @@ func (s *CTRAG) UpdateModel(ctx context.Context, model *domain.Model) error {
 		MaxTokens: 8192,
 		IsDefault: true,
 		Enabled:   true,
-		Config:    json.RawMessage(`{"max_context": 8192, "chunk_size": 1024, "chunk_overlap": 128, "dimension": 1536}`),
+		Config:    s.modelConfig(),
 	}
 	_, err := s.client.AddModelConfig(ctx, updateReq)
 	if err != nil {
@@ -178,3 +219,7 @@ func (s *CTRAG) GetModelList(ctx context.Context) ([]*domain.Model, error) {
 	}
 	return models, nil
 }
+
+func (s *CTRAG) modelConfig() json.RawMessage {
+	return json.RawMessage(fmt.Sprintf(`{"max_context": 8192, "chunk_size": 1024, "chunk_overlap": 128, "dimension": %d}`, s.dimension))
+}
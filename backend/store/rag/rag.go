@@ -13,10 +13,13 @@ import (
 )
 
 type RAGService interface {
-	CreateKnowledgeBase(ctx context.Context) (string, error)
+	// CreateKnowledgeBase dataset embedding with the model, the default embedding model if empty
+	CreateKnowledgeBase(ctx context.Context, embeddingModelID string) (string, error)
 	UpsertRecords(ctx context.Context, datasetID string, nodeRelease *domain.NodeRelease) (string, error)
-	QueryRecords(ctx context.Context, datasetIDs []string, query string) ([]*domain.NodeContentChunk, error)
+	// QueryRecords chunks reranked by the model, the default rerank model if empty
+	QueryRecords(ctx context.Context, datasetIDs []string, query string, rerankModelID string) ([]*domain.NodeContentChunk, error)
 	DeleteRecords(ctx context.Context, datasetID string, docIDs []string) error
+	ListRecords(ctx context.Context, datasetID string) ([]*domain.RAGDocument, error)
 	DeleteKnowledgeBase(ctx context.Context, datasetID string) error
 
 	GetModelList(ctx context.Context) ([]*domain.Model, error)
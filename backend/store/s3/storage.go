@@ -0,0 +1,94 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+
+	"github.com/chaitin/panda-wiki/domain"
+)
+
+// ObjectStorage private object storage, objects are only served through signed urls.
+// implement it to keep attachments in another backend than S3/MinIO
+type ObjectStorage interface {
+	PutObject(ctx context.Context, key string, reader io.Reader, size int64, contentType string) error
+	GetObject(ctx context.Context, key string) (io.ReadCloser, error)
+	RemoveObjects(ctx context.Context, keys []string) error
+	// SignURL download url of the object, saved as filename
+	SignURL(ctx context.Context, key, filename string, expires time.Duration) (string, error)
+}
+
+// MinioObjectStorage object storage in a private bucket of minio or any S3 compatible service
+type MinioObjectStorage struct {
+	client *MinioClient
+	bucket string
+}
+
+func NewAttachmentStorage(client *MinioClient) (ObjectStorage, error) {
+	ctx := context.Background()
+	bucket := domain.AttachmentBucket
+	exists, err := client.BucketExists(ctx, bucket)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		// no bucket policy, objects are private
+		if err := client.MakeBucket(ctx, bucket, minio.MakeBucketOptions{
+			Region: "us-east-1",
+		}); err != nil {
+			return nil, fmt.Errorf("make bucket: %w", err)
+		}
+	}
+	return &MinioObjectStorage{client: client, bucket: bucket}, nil
+}
+
+func (s *MinioObjectStorage) PutObject(ctx context.Context, key string, reader io.Reader, size int64, contentType string) error {
+	_, err := s.client.Client.PutObject(ctx, s.bucket, key, reader, size, minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+	return err
+}
+
+func (s *MinioObjectStorage) GetObject(ctx context.Context, key string) (io.ReadCloser, error) {
+	object, err := s.client.Client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	// minio returns errors of missing objects on first read
+	if _, err := object.Stat(); err != nil {
+		object.Close()
+		return nil, err
+	}
+	return object, nil
+}
+
+func (s *MinioObjectStorage) RemoveObjects(ctx context.Context, keys []string) error {
+	objectsCh := make(chan minio.ObjectInfo, len(keys))
+	for _, key := range keys {
+		objectsCh <- minio.ObjectInfo{Key: key}
+	}
+	close(objectsCh)
+	for err := range s.client.Client.RemoveObjects(ctx, s.bucket, objectsCh, minio.RemoveObjectsOptions{}) {
+		if err.Err != nil {
+			return fmt.Errorf("remove object %s: %w", err.ObjectName, err.Err)
+		}
+	}
+	return nil
+}
+
+func (s *MinioObjectStorage) SignURL(ctx context.Context, key, filename string, expires time.Duration) (string, error) {
+	params := url.Values{}
+	if filename != "" {
+		params.Set("response-content-disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	}
+	u, err := s.client.PresignedGetObject(ctx, s.bucket, key, expires, params)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
@@ -0,0 +1,178 @@
+package statsink
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/chaitin/panda-wiki/config"
+	"github.com/chaitin/panda-wiki/domain"
+)
+
+const bigQueryScope = "https://www.googleapis.com/auth/bigquery.insertdata"
+
+type serviceAccountKey struct {
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// BigQuerySink stream batch with tabledata.insertAll, authorized by service account key.
+// columns of the table are the same as ClickHouseSink
+type BigQuerySink struct {
+	config     config.BigQuerySinkConfig
+	email      string
+	tokenURI   string
+	privateKey *rsa.PrivateKey
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+func NewBigQuerySink(cfg config.BigQuerySinkConfig) (*BigQuerySink, error) {
+	if cfg.ProjectID == "" || cfg.Dataset == "" || cfg.Table == "" {
+		return nil, fmt.Errorf("stat sink bigquery project_id, dataset and table are required")
+	}
+	keyBytes, err := os.ReadFile(cfg.CredentialsFile)
+	if err != nil {
+		return nil, fmt.Errorf("read bigquery credentials failed: %w", err)
+	}
+	var key serviceAccountKey
+	if err := json.Unmarshal(keyBytes, &key); err != nil {
+		return nil, fmt.Errorf("parse bigquery credentials failed: %w", err)
+	}
+	block, _ := pem.Decode([]byte(key.PrivateKey))
+	if block == nil {
+		return nil, fmt.Errorf("invalid bigquery private key")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse bigquery private key failed: %w", err)
+	}
+	privateKey, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("bigquery private key is not rsa")
+	}
+	if key.TokenURI == "" {
+		key.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+	return &BigQuerySink{
+		config:     cfg,
+		email:      key.ClientEmail,
+		tokenURI:   key.TokenURI,
+		privateKey: privateKey,
+	}, nil
+}
+
+func (s *BigQuerySink) Name() string {
+	return "bigquery"
+}
+
+func (s *BigQuerySink) Write(ctx context.Context, events []*domain.StatEvent) error {
+	token, err := s.getAccessToken(ctx)
+	if err != nil {
+		return err
+	}
+	rows := make([]map[string]any, 0, len(events))
+	for _, event := range events {
+		rows = append(rows, map[string]any{
+			"insertId": event.ID,
+			"json": map[string]any{
+				"id":         event.ID,
+				"type":       event.Type,
+				"kb_id":      event.KBID,
+				"data":       string(event.Data),
+				"created_at": event.CreatedAt.UTC().Format(time.RFC3339Nano),
+			},
+		})
+	}
+	body, err := json.Marshal(map[string]any{"rows": rows})
+	if err != nil {
+		return err
+	}
+	insertURL := fmt.Sprintf("https://bigquery.googleapis.com/bigquery/v2/projects/%s/datasets/%s/tables/%s/insertAll",
+		url.PathEscape(s.config.ProjectID), url.PathEscape(s.config.Dataset), url.PathEscape(s.config.Table))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, insertURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := checkResponse(resp); err != nil {
+		return err
+	}
+	var result struct {
+		InsertErrors []json.RawMessage `json:"insertErrors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return err
+	}
+	if len(result.InsertErrors) > 0 {
+		return fmt.Errorf("bigquery insert %d rows failed: %s", len(result.InsertErrors), string(result.InsertErrors[0]))
+	}
+	return nil
+}
+
+// getAccessToken exchange signed jwt for access token, cached until near expiry
+func (s *BigQuerySink) getAccessToken(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.accessToken != "" && time.Now().Before(s.expiresAt.Add(-time.Minute)) {
+		return s.accessToken, nil
+	}
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   s.email,
+		"scope": bigQueryScope,
+		"aud":   s.tokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(s.privateKey)
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{}
+	form.Set("grant_type", "urn:ietf:params:oauth:grant-type:jwt-bearer")
+	form.Set("assertion", assertion)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.tokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if err := checkResponse(resp); err != nil {
+		return "", fmt.Errorf("get bigquery access token failed: %w", err)
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", err
+	}
+	s.accessToken = token.AccessToken
+	s.expiresAt = now.Add(time.Duration(token.ExpiresIn) * time.Second)
+	return s.accessToken, nil
+}
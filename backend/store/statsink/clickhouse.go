@@ -0,0 +1,70 @@
+package statsink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/chaitin/panda-wiki/config"
+	"github.com/chaitin/panda-wiki/domain"
+)
+
+// ClickHouseSink insert batch through clickhouse http interface, the table is expected as:
+//
+//	CREATE TABLE panda_wiki_stat_events (
+//	    id String,
+//	    type LowCardinality(String),
+//	    kb_id String,
+//	    data String,
+//	    created_at DateTime64(3)
+//	) ENGINE = MergeTree ORDER BY (kb_id, created_at)
+type ClickHouseSink struct {
+	config config.ClickHouseSinkConfig
+}
+
+type clickHouseRow struct {
+	ID        string `json:"id"`
+	Type      string `json:"type"`
+	KBID      string `json:"kb_id"`
+	Data      string `json:"data"`
+	CreatedAt string `json:"created_at"`
+}
+
+func (s *ClickHouseSink) Name() string {
+	return "clickhouse"
+}
+
+func (s *ClickHouseSink) Write(ctx context.Context, events []*domain.StatEvent) error {
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, event := range events {
+		if err := encoder.Encode(&clickHouseRow{
+			ID:        event.ID,
+			Type:      string(event.Type),
+			KBID:      event.KBID,
+			Data:      string(event.Data),
+			CreatedAt: event.CreatedAt.UTC().Format("2006-01-02 15:04:05.000"),
+		}); err != nil {
+			return err
+		}
+	}
+	query := url.Values{}
+	query.Set("query", fmt.Sprintf("INSERT INTO %s.%s FORMAT JSONEachRow", s.config.Database, s.config.Table))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.URL+"/?"+query.Encode(), &body)
+	if err != nil {
+		return err
+	}
+	if s.config.Username != "" {
+		req.Header.Set("X-ClickHouse-User", s.config.Username)
+		req.Header.Set("X-ClickHouse-Key", s.config.Password)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return checkResponse(resp)
+}
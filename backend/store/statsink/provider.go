@@ -0,0 +1,5 @@
+package statsink
+
+import "github.com/google/wire"
+
+var ProviderSet = wire.NewSet(NewSink)
@@ -0,0 +1,51 @@
+package statsink
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/chaitin/panda-wiki/config"
+	"github.com/chaitin/panda-wiki/domain"
+)
+
+// Sink ship batches of stat events to external analytics
+type Sink interface {
+	Name() string
+	Write(ctx context.Context, events []*domain.StatEvent) error
+}
+
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// NewSink create sink by config, return nil if stat sink is disabled
+func NewSink(config *config.Config) (Sink, error) {
+	cfg := config.StatSink
+	switch cfg.Type {
+	case "":
+		return nil, nil
+	case "http":
+		if cfg.HTTP.URL == "" {
+			return nil, fmt.Errorf("stat sink http url is required")
+		}
+		return &HTTPSink{config: cfg.HTTP}, nil
+	case "clickhouse":
+		if cfg.ClickHouse.URL == "" {
+			return nil, fmt.Errorf("stat sink clickhouse url is required")
+		}
+		return &ClickHouseSink{config: cfg.ClickHouse}, nil
+	case "bigquery":
+		return NewBigQuerySink(cfg.BigQuery)
+	default:
+		return nil, fmt.Errorf("invalid stat sink type: %s", cfg.Type)
+	}
+}
+
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	body := make([]byte, 512)
+	n, _ := resp.Body.Read(body)
+	return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body[:n]))
+}
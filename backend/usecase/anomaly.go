@@ -0,0 +1,194 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/repo/cache"
+	"github.com/chaitin/panda-wiki/repo/ipdb"
+	"github.com/chaitin/panda-wiki/repo/mq"
+	"github.com/chaitin/panda-wiki/repo/pg"
+)
+
+const (
+	anomalyReportDays   = 7
+	anomalyReportTopIPs = 10
+	anomalyDetailRunes  = 200
+)
+
+// AnomalyUsecase detect abnormal chat traffic of an ip and block it for a while
+type AnomalyUsecase struct {
+	repo        *pg.AnomalyRepository
+	cacheRepo   *cache.AnomalyRepo
+	webhookRepo *mq.WebhookRepository
+	ipRepo      *ipdb.IPAddressRepo
+	logger      *log.Logger
+}
+
+func NewAnomalyUsecase(repo *pg.AnomalyRepository, cacheRepo *cache.AnomalyRepo, webhookRepo *mq.WebhookRepository, ipRepo *ipdb.IPAddressRepo, logger *log.Logger) *AnomalyUsecase {
+	return &AnomalyUsecase{
+		repo:        repo,
+		cacheRepo:   cacheRepo,
+		webhookRepo: webhookRepo,
+		ipRepo:      ipRepo,
+		logger:      logger.WithModule("usecase.anomaly"),
+	}
+}
+
+// Check count the question of the ip, return ErrAnomalyBlocked if the ip is or gets blocked,
+// ErrAnomalyInputTooLong if only this question is refused.
+// detection fails open, cache errors never refuse a question
+func (u *AnomalyUsecase) Check(ctx context.Context, settings domain.AnomalySettings, kbID, appID, ip, message string) error {
+	if settings.Disabled || ip == "" {
+		return nil
+	}
+	settings = settings.Effective()
+	blocked, err := u.cacheRepo.IsBlocked(ctx, kbID, ip)
+	if err != nil {
+		u.logger.Warn("check anomaly block failed", log.Error(err), log.String("ip", ip))
+		return nil
+	}
+	if blocked {
+		return domain.ErrAnomalyBlocked
+	}
+	if utf8.RuneCountInString(message) > settings.MaxInputLength {
+		count, err := u.cacheRepo.Incr(ctx, kbID, ip, "long", domain.AnomalyWindow)
+		if err != nil {
+			u.logger.Warn("count long input failed", log.Error(err), log.String("ip", ip))
+		} else if count >= domain.AnomalyLongInputLimit {
+			u.detect(ctx, settings, kbID, appID, ip, domain.AnomalyTypeLongInput, message, count)
+			return domain.ErrAnomalyBlocked
+		}
+		return domain.ErrAnomalyInputTooLong
+	}
+	count, err := u.cacheRepo.Incr(ctx, kbID, ip, "questions", time.Hour)
+	if err != nil {
+		u.logger.Warn("count questions failed", log.Error(err), log.String("ip", ip))
+		return nil
+	}
+	if count > int64(settings.QuestionsPerHour) {
+		u.detect(ctx, settings, kbID, appID, ip, domain.AnomalyTypeQuestionStorm, message, count)
+		return domain.ErrAnomalyBlocked
+	}
+	count, err = u.cacheRepo.Incr(ctx, kbID, ip, "repeat:"+promptHash(message), domain.AnomalyWindow)
+	if err != nil {
+		u.logger.Warn("count repeated prompt failed", log.Error(err), log.String("ip", ip))
+		return nil
+	}
+	if count > int64(settings.RepeatLimit) {
+		u.detect(ctx, settings, kbID, appID, ip, domain.AnomalyTypeRepeatedPrompt, message, count)
+		return domain.ErrAnomalyBlocked
+	}
+	return nil
+}
+
+// detect block the ip, record the anomaly and alert admins by webhook
+func (u *AnomalyUsecase) detect(ctx context.Context, settings domain.AnomalySettings, kbID, appID, ip string, anomalyType domain.AnomalyType, message string, count int64) {
+	blockFor := time.Duration(settings.BlockMinutes) * time.Minute
+	if err := u.cacheRepo.Block(ctx, kbID, ip, blockFor); err != nil {
+		u.logger.Error("block anomaly ip failed", log.Error(err), log.String("ip", ip))
+	}
+	now := time.Now()
+	anomaly := &domain.ConversationAnomaly{
+		ID:           uuid.New().String(),
+		KBID:         kbID,
+		AppID:        appID,
+		RemoteIP:     ip,
+		Type:         anomalyType,
+		Detail:       truncateRunes(message, anomalyDetailRunes),
+		Count:        count,
+		BlockedUntil: lo.ToPtr(now.Add(blockFor)),
+		CreatedAt:    now,
+	}
+	u.logger.Warn("chat anomaly detected", log.String("kb_id", kbID), log.String("ip", ip), log.String("type", string(anomalyType)), log.Int64("count", count))
+	if err := u.repo.CreateAnomaly(ctx, anomaly); err != nil {
+		u.logger.Error("create anomaly failed", log.Error(err))
+	}
+	if err := u.webhookRepo.AsyncPublishWebhookEvent(ctx, domain.WebhookEventAnomalyDetected, kbID, anomaly); err != nil {
+		u.logger.Error("publish anomaly webhook event failed", log.Error(err))
+	}
+}
+
+func (u *AnomalyUsecase) GetAnomalyList(ctx context.Context, req *domain.AnomalyListReq) (*domain.PaginatedResult[[]*domain.AnomalyListItem], error) {
+	items, total, err := u.repo.GetAnomalyList(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	addresses, err := u.ipRepo.GetIPAddresses(ctx, lo.Uniq(lo.Map(items, func(item *domain.AnomalyListItem, _ int) string {
+		return item.RemoteIP
+	})))
+	if err != nil {
+		u.logger.Warn("get ip addresses failed", log.Error(err))
+	}
+	now := time.Now()
+	for _, item := range items {
+		item.IPAddress = addresses[item.RemoteIP]
+		item.Blocked = item.BlockedUntil != nil && item.BlockedUntil.After(now)
+	}
+	return domain.NewPaginatedResult(items, total), nil
+}
+
+// GetAnomalyReport anomalies by type and ip of the recent days and the ips still blocked
+func (u *AnomalyUsecase) GetAnomalyReport(ctx context.Context, kbID string) (*domain.AnomalyReport, error) {
+	since := time.Now().AddDate(0, 0, -anomalyReportDays)
+	types, err := u.repo.GetAnomalyTypeCounts(ctx, kbID, since)
+	if err != nil {
+		return nil, err
+	}
+	topIPs, err := u.repo.GetTopAnomalyIPs(ctx, kbID, since, anomalyReportTopIPs)
+	if err != nil {
+		return nil, err
+	}
+	blocked, err := u.repo.GetActiveBlocks(ctx, kbID)
+	if err != nil {
+		return nil, err
+	}
+	addresses, err := u.ipRepo.GetIPAddresses(ctx, lo.Map(topIPs, func(item *domain.AnomalyIPCount, _ int) string {
+		return item.RemoteIP
+	}))
+	if err != nil {
+		u.logger.Warn("get ip addresses failed", log.Error(err))
+	}
+	for _, item := range topIPs {
+		item.IPAddress = addresses[item.RemoteIP]
+	}
+	return &domain.AnomalyReport{
+		Days:    anomalyReportDays,
+		Types:   types,
+		TopIPs:  topIPs,
+		Blocked: blocked,
+	}, nil
+}
+
+// UnblockIP lift the block of the ip before it expires
+func (u *AnomalyUsecase) UnblockIP(ctx context.Context, req *domain.UnblockAnomalyIPReq) error {
+	if err := u.cacheRepo.Unblock(ctx, req.KBID, req.RemoteIP); err != nil {
+		return fmt.Errorf("unblock ip failed: %w", err)
+	}
+	return u.repo.EndBlock(ctx, req.KBID, req.RemoteIP)
+}
+
+// promptHash identical prompts ignoring case and whitespace
+func promptHash(message string) string {
+	normalized := strings.ToLower(strings.Join(strings.Fields(message), " "))
+	sum := sha1.Sum([]byte(normalized))
+	return hex.EncodeToString(sum[:8])
+}
+
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "..."
+}
@@ -0,0 +1,105 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/repo/pg"
+	"github.com/chaitin/panda-wiki/utils"
+)
+
+type AnswerCacheUsecase struct {
+	repo       *pg.AnswerCacheRepository
+	modelRepo  *pg.ModelRepository
+	llmUsecase *LLMUsecase
+	logger     *log.Logger
+}
+
+func NewAnswerCacheUsecase(repo *pg.AnswerCacheRepository, modelRepo *pg.ModelRepository, llmUsecase *LLMUsecase, logger *log.Logger) *AnswerCacheUsecase {
+	return &AnswerCacheUsecase{
+		repo:       repo,
+		modelRepo:  modelRepo,
+		llmUsecase: llmUsecase,
+		logger:     logger.WithModule("usecase.answer_cache"),
+	}
+}
+
+// Lookup cached answer of the most similar recent question asked with the same key, nil if none is similar enough
+// or one of its documents is hidden from the viewer. the embedding of the question is returned to cache its answer,
+// nil if embedding failed
+func (u *AnswerCacheUsecase) Lookup(
+	ctx context.Context,
+	settings domain.AnswerCacheSettings,
+	key *domain.AnswerCacheKey,
+	aclFilter *domain.NodeACLFilter,
+	question string,
+) (*domain.AnswerCacheEntry, domain.Vector) {
+	ctx, cancel := context.WithTimeout(ctx, domain.AnswerCacheLookupTimeout)
+	defer cancel()
+	embedding, err := u.embed(ctx, key.KBID, question)
+	if err != nil {
+		u.logger.Warn("embed question for answer cache failed", log.String("kb_id", key.KBID), log.Error(err))
+		return nil, nil
+	}
+	candidates, err := u.repo.GetAnswerCacheCandidates(ctx, key, domain.AnswerCacheCandidates)
+	if err != nil {
+		u.logger.Warn("get answer cache candidates failed", log.String("kb_id", key.KBID), log.Error(err))
+		return nil, embedding
+	}
+	var best *domain.AnswerCacheEntry
+	bestScore := settings.MinSimilarityOrDefault()
+	for _, candidate := range candidates {
+		// entries of another embedding model are not comparable
+		if len(candidate.Embedding) != len(embedding) {
+			continue
+		}
+		if score := utils.CosineSimilarity(candidate.Embedding, embedding); score >= bestScore {
+			best, bestScore = candidate, score
+		}
+	}
+	if best == nil {
+		return nil, embedding
+	}
+	for _, nodeID := range best.NodeIDs {
+		if !aclFilter.Allows(nodeID) {
+			return nil, embedding
+		}
+	}
+	u.logger.Info("answer cache hit", log.String("kb_id", key.KBID), log.String("entry_id", best.ID), log.Any("similarity", bestScore))
+	if err := u.repo.IncrAnswerCacheHits(ctx, best.ID); err != nil {
+		u.logger.Warn("incr answer cache hits failed", log.String("entry_id", best.ID), log.Error(err))
+	}
+	return best, embedding
+}
+
+// Save cache the answer of the question for the ttl of the settings
+func (u *AnswerCacheUsecase) Save(ctx context.Context, settings domain.AnswerCacheSettings, entry *domain.AnswerCacheEntry) {
+	entry.ID = uuid.New().String()
+	entry.CreatedAt = time.Now()
+	entry.ExpiresAt = entry.CreatedAt.Add(settings.TTL())
+	if err := u.repo.CreateAnswerCacheEntry(ctx, entry); err != nil {
+		u.logger.Warn("save answer cache entry failed", log.String("kb_id", entry.KBID), log.String("message_id", entry.MessageID), log.Error(err))
+	}
+}
+
+func (u *AnswerCacheUsecase) embed(ctx context.Context, kbID, question string) (domain.Vector, error) {
+	model, err := u.modelRepo.GetKBModel(ctx, kbID, domain.ModelTypeEmbedding)
+	if err != nil {
+		return nil, fmt.Errorf("get embedding model failed: %w", err)
+	}
+	embeddings, err := u.llmUsecase.Embed(ctx, model, []string{strings.TrimSpace(question)})
+	if err != nil {
+		return nil, err
+	}
+	if len(embeddings) == 0 {
+		return nil, errors.New("no embedding of question")
+	}
+	return embeddings[0], nil
+}
@@ -0,0 +1,77 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+)
+
+// AnswerContext answer being finalized and what post processors may need
+type AnswerContext struct {
+	Question    string
+	Answer      string
+	Settings    domain.AnswerPipelineSettings
+	Compliance  domain.ComplianceSettings
+	RankedNodes []*domain.RankedNodeChunks
+	BaseURL     string
+}
+
+// AnswerPostProcessor a step of answer pipeline
+type AnswerPostProcessor interface {
+	// Rewrites report whether the step may change text already generated,
+	// answers are buffered instead of streamed if any step rewrites
+	Rewrites() bool
+	Process(ctx context.Context, answer *AnswerContext) error
+}
+
+var answerPostProcessors = map[domain.AnswerStepType]AnswerPostProcessor{
+	domain.AnswerStepCitationValidation: citationValidationStep{},
+	domain.AnswerStepPIIScrub:           piiScrubStep{},
+	domain.AnswerStepFormat:             formatStep{},
+	domain.AnswerStepLengthTrim:         lengthTrimStep{},
+	domain.AnswerStepDisclaimer:         disclaimerStep{},
+}
+
+// RegisterAnswerPostProcessor add or replace a step of answer pipeline
+func RegisterAnswerPostProcessor(step domain.AnswerStepType, processor AnswerPostProcessor) {
+	answerPostProcessors[step] = processor
+}
+
+type AnswerPipeline struct {
+	steps  []domain.AnswerStepType
+	logger *log.Logger
+}
+
+// NewAnswerPipeline build pipeline of the app settings, unknown steps are skipped
+func NewAnswerPipeline(settings domain.AnswerPipelineSettings, logger *log.Logger) *AnswerPipeline {
+	steps := make([]domain.AnswerStepType, 0)
+	for _, step := range settings.StepsOrDefault() {
+		if _, ok := answerPostProcessors[step]; !ok {
+			logger.Warn("unknown answer pipeline step", log.String("step", string(step)))
+			continue
+		}
+		steps = append(steps, step)
+	}
+	return &AnswerPipeline{steps: steps, logger: logger}
+}
+
+func (p *AnswerPipeline) Rewrites() bool {
+	for _, step := range p.steps {
+		if answerPostProcessors[step].Rewrites() {
+			return true
+		}
+	}
+	return false
+}
+
+// Run apply steps in order, a failed step is skipped and leaves the answer unchanged
+func (p *AnswerPipeline) Run(ctx context.Context, answer *AnswerContext) {
+	for _, step := range p.steps {
+		before := answer.Answer
+		if err := answerPostProcessors[step].Process(ctx, answer); err != nil {
+			p.logger.Warn("answer pipeline step failed", log.String("step", string(step)), log.Error(err))
+			answer.Answer = before
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/chaitin/panda-wiki/domain"
+)
+
+var (
+	referenceLineRegex = regexp.MustCompile(`(?m)^(?:>|\\u003e)\s*\[(\d+)\]\.\s*\[(.*?)\]\((.*?)\)\s*$\n?`)
+
+	piiPatterns = []struct {
+		regex *regexp.Regexp
+		mask  string
+	}{
+		{regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`), "[邮箱]"},
+		{regexp.MustCompile(`\b[1-9]\d{5}(?:19|20)\d{2}(?:0[1-9]|1[0-2])(?:0[1-9]|[12]\d|3[01])\d{3}[\dXx]\b`), "[身份证号]"},
+		{regexp.MustCompile(`\b\d{16,19}\b`), "[银行卡号]"},
+		{regexp.MustCompile(`(?:\+?86[- ]?)?\b1[3-9]\d{9}\b`), "[手机号]"},
+	}
+
+	markdownPatterns = []struct {
+		regex   *regexp.Regexp
+		replace string
+	}{
+		{regexp.MustCompile("(?m)^```.*$\n?"), ""},
+		{regexp.MustCompile(`!\[(.*?)\]\((.*?)\)`), "$1 ($2)"},
+		{regexp.MustCompile(`\[(.*?)\]\((.*?)\)`), "$1 ($2)"},
+		{regexp.MustCompile(`(?m)^#{1,6}\s+`), ""},
+		{regexp.MustCompile(`(?m)^>\s?`), ""},
+		{regexp.MustCompile(`\*\*(.*?)\*\*`), "$1"},
+		{regexp.MustCompile("`([^`]*)`"), "$1"},
+	}
+
+	sentenceEnds = []string{"。", "！", "？", ". ", "! ", "? ", "\n"}
+)
+
+// citationValidationStep remove reference links which are not from retrieved documents
+type citationValidationStep struct{}
+
+func (citationValidationStep) Rewrites() bool { return true }
+
+func (citationValidationStep) Process(_ context.Context, answer *AnswerContext) error {
+	valid := make(map[string]bool, len(answer.RankedNodes))
+	for _, node := range answer.RankedNodes {
+		valid[node.GetURL(answer.BaseURL)] = true
+	}
+	answer.Answer = referenceLineRegex.ReplaceAllStringFunc(answer.Answer, func(line string) string {
+		match := referenceLineRegex.FindStringSubmatch(line)
+		if len(match) == 4 && valid[match[3]] {
+			return line
+		}
+		return ""
+	})
+	return nil
+}
+
+// piiScrubStep mask personal information in the answer
+type piiScrubStep struct{}
+
+func (piiScrubStep) Rewrites() bool { return true }
+
+func (piiScrubStep) Process(_ context.Context, answer *AnswerContext) error {
+	for _, pattern := range piiPatterns {
+		answer.Answer = pattern.regex.ReplaceAllString(answer.Answer, pattern.mask)
+	}
+	return nil
+}
+
+// formatStep adapt markdown answer to the format of the app
+type formatStep struct{}
+
+func (formatStep) Rewrites() bool { return true }
+
+func (formatStep) Process(_ context.Context, answer *AnswerContext) error {
+	switch answer.Settings.Format {
+	case "", domain.AnswerFormatMarkdown:
+		return nil
+	case domain.AnswerFormatPlain:
+		text := thinkBlockRegex.ReplaceAllString(answer.Answer, "")
+		for _, pattern := range markdownPatterns {
+			text = pattern.regex.ReplaceAllString(text, pattern.replace)
+		}
+		answer.Answer = strings.TrimSpace(text)
+		return nil
+	default:
+		return fmt.Errorf("unknown answer format: %s", answer.Settings.Format)
+	}
+}
+
+// lengthTrimStep trim answer to max length at the last sentence end
+type lengthTrimStep struct{}
+
+func (lengthTrimStep) Rewrites() bool { return true }
+
+func (lengthTrimStep) Process(_ context.Context, answer *AnswerContext) error {
+	maxLength := answer.Settings.MaxLength
+	runes := []rune(answer.Answer)
+	if maxLength <= 0 || len(runes) <= maxLength {
+		return nil
+	}
+	trimmed := string(runes[:maxLength])
+	cut := -1
+	for _, end := range sentenceEnds {
+		if i := strings.LastIndex(trimmed, end); i > cut {
+			cut = i + len(end)
+		}
+	}
+	// keep the cut if it doesn't drop too much
+	if cut > len(trimmed)/2 {
+		trimmed = trimmed[:cut]
+	}
+	answer.Answer = strings.TrimRight(trimmed, " \n") + "……"
+	return nil
+}
+
+// disclaimerStep append disclaimers of the kb content policy
+type disclaimerStep struct{}
+
+func (disclaimerStep) Rewrites() bool { return false }
+
+func (disclaimerStep) Process(_ context.Context, answer *AnswerContext) error {
+	for _, disclaimer := range answer.Compliance.MatchDisclaimers(answer.Question, answer.Answer) {
+		answer.Answer += "\n\n> " + disclaimer
+	}
+	return nil
+}
@@ -0,0 +1,231 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/repo/pg"
+)
+
+type APITokenUsecase struct {
+	repo           *pg.APITokenRepository
+	userRepo       *pg.UserRepository
+	kbRepo         *pg.KnowledgeBaseRepository
+	sandboxUsecase *SandboxUsecase
+	logger         *log.Logger
+}
+
+func NewAPITokenUsecase(repo *pg.APITokenRepository, userRepo *pg.UserRepository, kbRepo *pg.KnowledgeBaseRepository, sandboxUsecase *SandboxUsecase, logger *log.Logger) *APITokenUsecase {
+	return &APITokenUsecase{
+		repo:           repo,
+		userRepo:       userRepo,
+		kbRepo:         kbRepo,
+		sandboxUsecase: sandboxUsecase,
+		logger:         logger.WithModule("usecase.api_token"),
+	}
+}
+
+// CreateAPIToken create a token, only its hash is stored so it is returned only here.
+// members only create personal tokens, sandbox kbs are not limited by roles so their scope is for admins
+func (u *APITokenUsecase) CreateAPIToken(ctx context.Context, req *domain.CreateAPITokenReq, userID string) (*domain.CreateAPITokenResp, error) {
+	apiToken := &domain.APIToken{
+		ID:           uuid.New().String(),
+		Name:         req.Name,
+		Kind:         req.Kind,
+		Scopes:       slices.Compact(slices.Sorted(slices.Values(req.Scopes))),
+		KBID:         req.KBID,
+		MaxSandboxes: req.MaxSandboxes,
+		CreatedBy:    userID,
+		CreatedAt:    time.Now(),
+	}
+	if apiToken.Kind == "" {
+		apiToken.Kind = domain.APITokenKindPersonal
+	}
+	admin, err := u.isAdmin(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !admin && apiToken.AdminOnly() {
+		return nil, domain.ErrAPITokenAdminOnly
+	}
+	if apiToken.KBID != "" {
+		if apiToken.Kind != domain.APITokenKindService {
+			return nil, domain.ErrAPITokenKBScope
+		}
+		if _, err := u.kbRepo.GetKnowledgeBaseByID(ctx, apiToken.KBID); err != nil {
+			return nil, err
+		}
+	}
+	if apiToken.MaxSandboxes == 0 {
+		apiToken.MaxSandboxes = domain.DefaultAPITokenMaxSandboxes
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := apiToken.CreatedAt.AddDate(0, 0, req.ExpiresInDays)
+		apiToken.ExpiresAt = &expiresAt
+	}
+	token, err := newAPIToken()
+	if err != nil {
+		return nil, err
+	}
+	apiToken.TokenHash = domain.HashAPIToken(token)
+	apiToken.Prefix = apiTokenPrefix(token)
+	if err := u.repo.CreateAPIToken(ctx, apiToken); err != nil {
+		return nil, err
+	}
+	u.logger.Info("api token created", log.String("token_id", apiToken.ID), log.String("name", apiToken.Name),
+		log.String("kind", string(apiToken.Kind)), log.Any("scopes", apiToken.Scopes), log.String("user_id", userID))
+	return &domain.CreateAPITokenResp{APIToken: apiToken, Token: token}, nil
+}
+
+// GetAPITokenList every token for admins, members only see their own
+func (u *APITokenUsecase) GetAPITokenList(ctx context.Context, userID string) ([]*domain.APIToken, error) {
+	admin, err := u.isAdmin(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if admin {
+		return u.repo.GetAPITokenList(ctx, "")
+	}
+	return u.repo.GetAPITokenList(ctx, userID)
+}
+
+// RotateAPIToken replace the token keeping its scopes, the previous token stops working at once
+func (u *APITokenUsecase) RotateAPIToken(ctx context.Context, req *domain.RotateAPITokenReq, userID string) (*domain.CreateAPITokenResp, error) {
+	apiToken, err := u.managedAPIToken(ctx, req.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+	token, err := newAPIToken()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	apiToken.TokenHash = domain.HashAPIToken(token)
+	apiToken.Prefix = apiTokenPrefix(token)
+	apiToken.RotatedAt = &now
+	if err := u.repo.RotateAPIToken(ctx, apiToken.ID, apiToken.TokenHash, apiToken.Prefix, now); err != nil {
+		return nil, err
+	}
+	u.logger.Info("api token rotated", log.String("token_id", apiToken.ID), log.String("user_id", userID))
+	return &domain.CreateAPITokenResp{APIToken: apiToken, Token: token}, nil
+}
+
+// DeleteAPIToken revoke the token and tear down its sandboxes
+func (u *APITokenUsecase) DeleteAPIToken(ctx context.Context, req *domain.DeleteAPITokenReq, userID string) error {
+	if _, err := u.managedAPIToken(ctx, req.ID, userID); err != nil {
+		return err
+	}
+	if err := u.sandboxUsecase.DeleteTokenSandboxes(ctx, req.ID); err != nil {
+		return err
+	}
+	if err := u.repo.DeleteAPIToken(ctx, req.ID); err != nil {
+		return err
+	}
+	u.logger.Info("api token deleted", log.String("token_id", req.ID), log.String("user_id", userID))
+	return nil
+}
+
+// RevokeUserAPITokens revoke the tokens created by the user and tear down their sandboxes, only the admin only ones
+// if adminOnly, when the user is no longer an admin
+func (u *APITokenUsecase) RevokeUserAPITokens(ctx context.Context, userID string, adminOnly bool) error {
+	tokens, err := u.repo.GetAPITokenList(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, apiToken := range tokens {
+		if adminOnly && !apiToken.AdminOnly() {
+			continue
+		}
+		if err := u.sandboxUsecase.DeleteTokenSandboxes(ctx, apiToken.ID); err != nil {
+			return err
+		}
+		if err := u.repo.DeleteAPIToken(ctx, apiToken.ID); err != nil {
+			return err
+		}
+		u.logger.Info("api token revoked with its creator", log.String("token_id", apiToken.ID), log.String("user_id", userID))
+	}
+	return nil
+}
+
+// managedAPIToken token the user may rotate or revoke, admins manage every token and members their own
+func (u *APITokenUsecase) managedAPIToken(ctx context.Context, id, userID string) (*domain.APIToken, error) {
+	apiToken, err := u.repo.GetAPIToken(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if apiToken.CreatedBy == userID {
+		return apiToken, nil
+	}
+	admin, err := u.isAdmin(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !admin {
+		return nil, domain.ErrAPITokenNotFound
+	}
+	return apiToken, nil
+}
+
+func (u *APITokenUsecase) isAdmin(ctx context.Context, userID string) (bool, error) {
+	role, err := u.userRepo.GetUserRole(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, domain.ErrUserNotFound
+		}
+		return false, err
+	}
+	return role == domain.UserRoleAdmin, nil
+}
+
+func newAPIToken() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return domain.APITokenPrefix + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// apiTokenPrefix first characters of the token shown to recognize it
+func apiTokenPrefix(token string) string {
+	return token[:len(domain.APITokenPrefix)+6]
+}
+
+// Authenticate token of the bearer token, ErrAPITokenInvalid if unknown or expired
+func (u *APITokenUsecase) Authenticate(ctx context.Context, token string) (*domain.APIToken, error) {
+	if !strings.HasPrefix(token, domain.APITokenPrefix) {
+		return nil, domain.ErrAPITokenInvalid
+	}
+	apiToken, err := u.repo.GetAPITokenByHash(ctx, domain.HashAPIToken(token))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if apiToken == nil || apiToken.Expired(now) {
+		return nil, domain.ErrAPITokenInvalid
+	}
+	// requests are made as the creator, admin only tokens stop working once it is demoted or deleted
+	if apiToken.AdminOnly() {
+		admin, err := u.isAdmin(ctx, apiToken.CreatedBy)
+		if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+			return nil, err
+		}
+		if !admin {
+			u.logger.Warn("api token of a creator who is no longer an admin", log.String("token_id", apiToken.ID), log.String("user_id", apiToken.CreatedBy))
+			return nil, domain.ErrAPITokenInvalid
+		}
+	}
+	if err := u.repo.TouchAPIToken(ctx, apiToken.ID, now); err != nil {
+		u.logger.Warn("update last used time of api token failed", log.String("token_id", apiToken.ID), log.Error(err))
+	}
+	return apiToken, nil
+}
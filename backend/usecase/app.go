@@ -18,6 +18,7 @@ type AppUsecase struct {
 	repo          *pg.AppRepository
 	nodeUsecase   *NodeUsecase
 	chatUsecase   *ChatUsecase
+	aclUsecase    *NodeACLUsecase
 	logger        *log.Logger
 	config        *config.Config
 	dingTalkBots  map[string]*dingtalk.DingTalkClient
@@ -34,11 +35,13 @@ func NewAppUsecase(
 	logger *log.Logger,
 	config *config.Config,
 	chatUsecase *ChatUsecase,
+	aclUsecase *NodeACLUsecase,
 ) *AppUsecase {
 	u := &AppUsecase{
 		repo:         repo,
 		nodeUsecase:  nodeUsecase,
 		chatUsecase:  chatUsecase,
+		aclUsecase:   aclUsecase,
 		logger:       logger.WithModule("usecase.app"),
 		config:       config,
 		dingTalkBots: make(map[string]*dingtalk.DingTalkClient),
@@ -299,6 +302,18 @@ func (u *AppUsecase) GetAppDetailByKBIDAndAppType(ctx context.Context, kbID stri
 		// FeishuBot
 		FeishuBotAppID:     app.Settings.FeishuBotAppID,
 		FeishuBotAppSecret: app.Settings.FeishuBotAppSecret,
+		// gap report
+		GapReport: app.Settings.GapReport,
+		// answer pipeline
+		AnswerPipeline: app.Settings.AnswerPipeline,
+		Citation:       app.Settings.Citation,
+		Continuation:   app.Settings.Continuation,
+		ChitChat:       app.Settings.ChitChat,
+		Provenance:     app.Settings.Provenance,
+
+		ConversationTitle: app.Settings.ConversationTitle,
+		FollowUp:          app.Settings.FollowUp,
+		Guardrails:        app.Settings.Guardrails,
 
 		// WechatBot
 		WeChatAppToken:          app.Settings.WeChatAppToken,
@@ -335,7 +350,8 @@ func (u *AppUsecase) GetAppDetailByKBIDAndAppType(ctx context.Context, kbID stri
 	return appDetailResp, nil
 }
 
-func (u *AppUsecase) GetWebAppInfo(ctx context.Context, kbID string) (*domain.AppInfoResp, error) {
+// GetWebAppInfo web app info of the public site, recommended nodes hidden from the viewer are left out
+func (u *AppUsecase) GetWebAppInfo(ctx context.Context, kbID string, viewer *domain.NodeViewer) (*domain.AppInfoResp, error) {
 	app, err := u.repo.GetOrCreateApplByKBIDAndType(ctx, kbID, domain.AppTypeWeb)
 	if err != nil {
 		return nil, err
@@ -372,7 +388,26 @@ func (u *AppUsecase) GetWebAppInfo(ctx context.Context, kbID string) (*domain.Ap
 		if err != nil {
 			return nil, err
 		}
-		appInfo.RecommendNodes = nodes
+		aclFilter, err := u.aclUsecase.ViewerFilter(ctx, kbID, viewer)
+		if err != nil {
+			return nil, err
+		}
+		appInfo.RecommendNodes = filterRecommendNodes(nodes, aclFilter)
 	}
 	return appInfo, nil
 }
+
+func filterRecommendNodes(nodes []*domain.RecommendNodeListResp, aclFilter *domain.NodeACLFilter) []*domain.RecommendNodeListResp {
+	if aclFilter == nil {
+		return nodes
+	}
+	visible := make([]*domain.RecommendNodeListResp, 0, len(nodes))
+	for _, node := range nodes {
+		if !aclFilter.Allows(node.ID) {
+			continue
+		}
+		node.RecommendNodes = filterRecommendNodes(node.RecommendNodes, aclFilter)
+		visible = append(visible, node)
+	}
+	return visible
+}
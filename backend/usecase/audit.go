@@ -0,0 +1,123 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/repo/pg"
+)
+
+// auditVerifyBatch entries loaded at a time when verifying the chain
+const auditVerifyBatch = 500
+
+// auditSnapshotModels resources whose rows are recorded before and after an action, by resource type
+var auditSnapshotModels = map[string]func() any{
+	"node":           func() any { return &domain.Node{} },
+	"knowledge_base": func() any { return &domain.KnowledgeBase{} },
+	"app":            func() any { return &domain.App{} },
+	"user":           func() any { return &domain.User{} },
+	"model":          func() any { return &domain.Model{} },
+	"webhook":        func() any { return &domain.Webhook{} },
+}
+
+type AuditUsecase struct {
+	repo        *pg.AuditLogRepository
+	userRepo    *pg.UserRepository
+	settingRepo *pg.SettingRepository
+	logger      *log.Logger
+}
+
+func NewAuditUsecase(repo *pg.AuditLogRepository, userRepo *pg.UserRepository, settingRepo *pg.SettingRepository, logger *log.Logger) *AuditUsecase {
+	return &AuditUsecase{
+		repo:        repo,
+		userRepo:    userRepo,
+		settingRepo: settingRepo,
+		logger:      logger.WithModule("usecase.audit"),
+	}
+}
+
+// HasSnapshot whether rows of the resource type are recorded before and after actions
+func (u *AuditUsecase) HasSnapshot(resourceType string) bool {
+	_, ok := auditSnapshotModels[resourceType]
+	return ok
+}
+
+// Snapshot redacted row of the resource, nil if the resource type has no snapshot or the row does not exist
+func (u *AuditUsecase) Snapshot(ctx context.Context, resourceType, id string) json.RawMessage {
+	newModel, ok := auditSnapshotModels[resourceType]
+	if !ok || id == "" {
+		return nil
+	}
+	model := newModel()
+	found, err := u.repo.GetSnapshot(ctx, model, id)
+	if err != nil {
+		u.logger.Error("get audit snapshot failed", log.String("resource_type", resourceType), log.String("id", id), log.Error(err))
+		return nil
+	}
+	if !found {
+		return nil
+	}
+	data, err := json.Marshal(model)
+	if err != nil {
+		return nil
+	}
+	return domain.RedactAuditSnapshot(data)
+}
+
+// Record append the action to the audit log
+func (u *AuditUsecase) Record(ctx context.Context, entry *domain.AuditLog) error {
+	if user, err := u.userRepo.GetUser(ctx, entry.ActorID); err == nil {
+		entry.ActorAccount = user.Account
+	}
+	// postgres keeps microseconds, the hash is of the stored time
+	entry.CreatedAt = time.Now().Truncate(time.Microsecond)
+	return u.repo.CreateAuditLog(ctx, entry)
+}
+
+func (u *AuditUsecase) GetAuditLogList(ctx context.Context, req *domain.AuditLogListReq) (*domain.PaginatedResult[[]*domain.AuditLogListItem], error) {
+	items, total, err := u.repo.GetAuditLogList(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewPaginatedResult(items, total), nil
+}
+
+func (u *AuditUsecase) GetAuditLog(ctx context.Context, id int64) (*domain.AuditLog, error) {
+	return u.repo.GetAuditLog(ctx, id)
+}
+
+// VerifyAuditLogs recompute the chain from the first entry, the first entry whose hash does not match
+// its content or whose previous hash is not that of the entry before it breaks the chain. the chain starts from
+// the anchor if retention removed its first entries. removing the latest entries is not detected
+func (u *AuditUsecase) VerifyAuditLogs(ctx context.Context) (*domain.AuditLogVerifyResp, error) {
+	anchor := &domain.AuditLogAnchor{}
+	if err := u.settingRepo.GetSetting(ctx, domain.SettingKeyAuditLogAnchor, anchor); err != nil {
+		return nil, err
+	}
+	resp := &domain.AuditLogVerifyResp{Valid: true, AnchorID: anchor.ID}
+	lastID := anchor.ID
+	prevHash := anchor.Hash
+	for {
+		entries, err := u.repo.GetAuditLogsAfter(ctx, lastID, auditVerifyBatch)
+		if err != nil {
+			return nil, err
+		}
+		if broken := domain.VerifyAuditChain(prevHash, entries); broken >= 0 {
+			resp.Checked += int64(broken + 1)
+			resp.Valid = false
+			resp.BrokenID = entries[broken].ID
+			return resp, nil
+		}
+		resp.Checked += int64(len(entries))
+		if len(entries) > 0 {
+			prevHash = entries[len(entries)-1].Hash
+			lastID = entries[len(entries)-1].ID
+		}
+		if len(entries) < auditVerifyBatch {
+			return resp, nil
+		}
+	}
+}
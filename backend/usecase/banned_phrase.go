@@ -0,0 +1,108 @@
+package usecase
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/width"
+
+	"github.com/chaitin/panda-wiki/domain"
+)
+
+// BannedPhraseFilter replace banned phrases of the kb in a streamed answer, in any case and in full-width or
+// half-width forms. the end of the stream which may be the start of a phrase is held back until the next chunk or Flush
+type BannedPhraseFilter struct {
+	regex        *regexp.Regexp
+	replacements map[string]string
+	// runes held back, one less than the longest phrase
+	hold    int
+	pending string
+}
+
+// NewBannedPhraseFilter nil if there are no phrases, a nil filter passes chunks through
+func NewBannedPhraseFilter(phrases []domain.BannedPhrase) *BannedPhraseFilter {
+	f := &BannedPhraseFilter{replacements: make(map[string]string, len(phrases))}
+	quoted := make([]string, 0, len(phrases))
+	for _, banned := range phrases {
+		if banned.Phrase == "" {
+			continue
+		}
+		key := foldBannedPhrase(banned.Phrase)
+		if _, ok := f.replacements[key]; ok {
+			continue
+		}
+		f.replacements[key] = banned.Replacement
+		quoted = append(quoted, bannedPhrasePattern(key))
+		f.hold = max(f.hold, utf8.RuneCountInString(banned.Phrase)-1, utf8.RuneCountInString(key)-1)
+	}
+	if len(quoted) == 0 {
+		return nil
+	}
+	f.regex = regexp.MustCompile("(?i)" + strings.Join(quoted, "|"))
+	return f
+}
+
+// Write filtered text which can be sent, may be empty while a phrase may be in progress
+func (f *BannedPhraseFilter) Write(chunk string) string {
+	if f == nil {
+		return chunk
+	}
+	f.pending += chunk
+	cut := len(f.pending)
+	for i := 0; i < f.hold && cut > 0; i++ {
+		_, size := utf8.DecodeLastRuneInString(f.pending[:cut])
+		cut -= size
+	}
+	// a phrase across the cut is held back whole
+	for _, loc := range f.regex.FindAllStringIndex(f.pending, -1) {
+		if loc[0] < cut && loc[1] > cut {
+			cut = loc[0]
+			break
+		}
+	}
+	out := f.pending[:cut]
+	f.pending = f.pending[cut:]
+	return f.replace(out)
+}
+
+// Flush filtered text held back at the end of the stream
+func (f *BannedPhraseFilter) Flush() string {
+	if f == nil {
+		return ""
+	}
+	out := f.pending
+	f.pending = ""
+	return f.replace(out)
+}
+
+func (f *BannedPhraseFilter) replace(text string) string {
+	return f.regex.ReplaceAllStringFunc(text, func(match string) string {
+		return f.replacements[foldBannedPhrase(match)]
+	})
+}
+
+// foldBannedPhrase lower case phrase with full-width letters narrowed and half-width katakana widened
+func foldBannedPhrase(phrase string) string {
+	return strings.ToLower(width.Fold.String(phrase))
+}
+
+// bannedPhrasePattern pattern of the folded phrase matching each rune in its folded, wide and narrow form
+func bannedPhrasePattern(folded string) string {
+	var b strings.Builder
+	for _, r := range folded {
+		b.WriteString("[")
+		seen := make(map[rune]bool, 3)
+		props := width.LookupRune(r)
+		for _, variant := range []rune{r, props.Wide(), props.Narrow()} {
+			if variant == 0 || seen[variant] {
+				continue
+			}
+			seen[variant] = true
+			fmt.Fprintf(&b, `\x{%x}`, variant)
+		}
+		b.WriteString("]")
+	}
+	return b.String()
+}
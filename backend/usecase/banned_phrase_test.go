@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chaitin/panda-wiki/domain"
+)
+
+func TestBannedPhraseFilter(t *testing.T) {
+	secret := []domain.BannedPhrase{{Phrase: "secret", Replacement: "[redacted]"}}
+	tests := []struct {
+		name    string
+		phrases []domain.BannedPhrase
+		chunks  []string
+		want    string
+	}{
+		{name: "no match flushes through", phrases: secret, chunks: []string{"hello ", "wor", "ld"}, want: "hello world"},
+		{name: "phrase in a chunk", phrases: secret, chunks: []string{"the secret code"}, want: "the [redacted] code"},
+		{name: "phrase split across chunks", phrases: secret, chunks: []string{"the sec", "ret code"}, want: "the [redacted] code"},
+		{name: "phrase split across many chunks", phrases: secret, chunks: []string{"s", "ec", "r", "e", "t!"}, want: "[redacted]!"},
+		{name: "phrase at end of stream", phrases: secret, chunks: []string{"it is ", "secret"}, want: "it is [redacted]"},
+		{name: "prefix of phrase at end of stream", phrases: secret, chunks: []string{"it is ", "secr"}, want: "it is secr"},
+		{name: "repeated phrases", phrases: secret, chunks: []string{"secretsec", "ret secret"}, want: "[redacted][redacted] [redacted]"},
+		{name: "case variant", phrases: secret, chunks: []string{"a SeC", "rEt"}, want: "a [redacted]"},
+		{name: "full-width variant", phrases: secret, chunks: []string{"a ｓｅｃ", "ｒｅｔ"}, want: "a [redacted]"},
+		{name: "full-width upper case variant", phrases: secret, chunks: []string{"ＳＥＣＲＥＴ"}, want: "[redacted]"},
+		{name: "mixed width variant", phrases: secret, chunks: []string{"Ｓecｒet"}, want: "[redacted]"},
+		{
+			name:    "full-width phrase matches narrow text",
+			phrases: []domain.BannedPhrase{{Phrase: "ＡＰＩ　ＫＥＹ", Replacement: "credential"}},
+			chunks:  []string{"the api", " key is"},
+			want:    "the credential is",
+		},
+		{
+			name:    "half-width katakana phrase matches full-width text",
+			phrases: []domain.BannedPhrase{{Phrase: "ｶﾀｶﾅ", Replacement: "x"}},
+			chunks:  []string{"カタ", "カナです"},
+			want:    "xです",
+		},
+		{
+			name:    "han phrase split inside chunks",
+			phrases: []domain.BannedPhrase{{Phrase: "机密", Replacement: "**"}},
+			chunks:  []string{"这是机", "密文件"},
+			want:    "这是**文件",
+		},
+		{
+			name:    "empty replacement removes the phrase",
+			phrases: []domain.BannedPhrase{{Phrase: "internal only"}},
+			chunks:  []string{"this is internal", " only."},
+			want:    "this is .",
+		},
+		{
+			name:    "phrases with regexp characters are literal",
+			phrases: []domain.BannedPhrase{{Phrase: "a.b[c]", Replacement: "x"}},
+			chunks:  []string{"a.b[c] axb[c]"},
+			want:    "x axb[c]",
+		},
+		{
+			name:    "first of duplicate phrases wins",
+			phrases: []domain.BannedPhrase{{Phrase: "Secret", Replacement: "first"}, {Phrase: "ＳＥＣＲＥＴ", Replacement: "second"}},
+			chunks:  []string{"secret"},
+			want:    "first",
+		},
+		{
+			name:    "each phrase has its replacement",
+			phrases: []domain.BannedPhrase{{Phrase: "alpha", Replacement: "A"}, {Phrase: "beta", Replacement: "B"}},
+			chunks:  []string{"alp", "ha be", "ta"},
+			want:    "A B",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewBannedPhraseFilter(tt.phrases)
+			var b strings.Builder
+			for _, chunk := range tt.chunks {
+				b.WriteString(f.Write(chunk))
+			}
+			b.WriteString(f.Flush())
+			if got := b.String(); got != tt.want {
+				t.Errorf("filtered = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBannedPhraseFilterHoldBack(t *testing.T) {
+	f := NewBannedPhraseFilter([]domain.BannedPhrase{{Phrase: "secret", Replacement: "[redacted]"}})
+	// the start of the phrase is held back until the chunk which completes it
+	if got := f.Write("the sec"); got != "th" {
+		t.Errorf("Write() = %q, want %q", got, "th")
+	}
+	if got := f.Write("ret code"); got != "e [redacted]" {
+		t.Errorf("Write() = %q, want %q", got, "e [redacted]")
+	}
+	if got := f.Flush(); got != " code" {
+		t.Errorf("Flush() = %q, want %q", got, " code")
+	}
+	if got := f.Flush(); got != "" {
+		t.Errorf("second Flush() = %q, want empty", got)
+	}
+}
+
+func TestBannedPhraseFilterNil(t *testing.T) {
+	for _, phrases := range [][]domain.BannedPhrase{nil, {{Phrase: "", Replacement: "x"}}} {
+		f := NewBannedPhraseFilter(phrases)
+		if f != nil {
+			t.Fatalf("NewBannedPhraseFilter(%v) = %v, want nil", phrases, f)
+		}
+		if got := f.Write("secret"); got != "secret" {
+			t.Errorf("nil Write() = %q, want %q", got, "secret")
+		}
+		if got := f.Flush(); got != "" {
+			t.Errorf("nil Flush() = %q, want empty", got)
+		}
+	}
+}
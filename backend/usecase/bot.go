@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/repo/cache"
+	"github.com/chaitin/panda-wiki/utils"
+)
+
+// BotDetector detect bot traffic by user agent and request frequency of ip
+type BotDetector struct {
+	rateLimitRepo *cache.RateLimitRepo
+	logger        *log.Logger
+}
+
+func NewBotDetector(rateLimitRepo *cache.RateLimitRepo, logger *log.Logger) *BotDetector {
+	return &BotDetector{
+		rateLimitRepo: rateLimitRepo,
+		logger:        logger.WithModule("usecase.bot"),
+	}
+}
+
+// IsBot report whether the request is from a bot, requests of an ip over limit per minute in the scene are bots
+func (d *BotDetector) IsBot(ctx context.Context, scene, ip, ua string, limitPerMinute int64) bool {
+	if utils.IsBotUserAgent(ua) {
+		return true
+	}
+	if ip == "" {
+		return false
+	}
+	allowed, err := d.rateLimitRepo.Allow(ctx, "bot:"+scene+":"+ip, limitPerMinute, time.Minute)
+	if err != nil {
+		d.logger.Warn("check request frequency failed", log.Error(err), log.String("ip", ip))
+		return false
+	}
+	return !allowed
+}
@@ -0,0 +1,212 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/pkg/bot/discord"
+	"github.com/chaitin/panda-wiki/pkg/bot/wechat"
+	"github.com/chaitin/panda-wiki/repo/pg"
+	"github.com/chaitin/panda-wiki/store/s3"
+)
+
+var errBotProfileUnsupported = errors.New("channel api does not allow bots to change their profile")
+
+type BotProfileUsecase struct {
+	appRepo     *pg.AppRepository
+	kbRepo      *pg.KnowledgeBaseRepository
+	minioClient *s3.MinioClient
+	httpClient  *http.Client
+	logger      *log.Logger
+}
+
+func NewBotProfileUsecase(appRepo *pg.AppRepository, kbRepo *pg.KnowledgeBaseRepository, minioClient *s3.MinioClient, logger *log.Logger) *BotProfileUsecase {
+	return &BotProfileUsecase{
+		appRepo:     appRepo,
+		kbRepo:      kbRepo,
+		minioClient: minioClient,
+		httpClient:  &http.Client{Timeout: 30 * time.Second},
+		logger:      logger.WithModule("usecase.bot_profile"),
+	}
+}
+
+// SyncBotProfiles push branding of kb to its bot apps, channels not allowing profile changes are reported unsupported
+func (u *BotProfileUsecase) SyncBotProfiles(ctx context.Context, req *domain.SyncBotProfilesReq) (*domain.SyncBotProfilesResp, error) {
+	kb, err := u.kbRepo.GetKnowledgeBaseByID(ctx, req.KBID)
+	if err != nil {
+		return nil, err
+	}
+	apps, err := u.appRepo.GetAppList(ctx, req.KBID)
+	if err != nil {
+		return nil, err
+	}
+	profile := kb.BrandingSettings.Profile(kb.Name)
+	var avatar []byte
+	if profile.Avatar != "" {
+		if avatar, err = u.loadAvatar(ctx, profile.Avatar); err != nil {
+			return nil, fmt.Errorf("load avatar failed: %w", err)
+		}
+	}
+	resp := &domain.SyncBotProfilesResp{Profile: profile, Results: []*domain.BotProfileSyncResult{}}
+	for _, app := range apps {
+		if !app.Type.IsBotApp() || (len(req.AppIDs) > 0 && !slices.Contains(req.AppIDs, app.ID)) {
+			continue
+		}
+		resp.Results = append(resp.Results, u.syncApp(app, profile, avatar))
+	}
+	slices.SortFunc(resp.Results, func(a, b *domain.BotProfileSyncResult) int {
+		return strings.Compare(a.AppID, b.AppID)
+	})
+	return resp, nil
+}
+
+// AutoSyncBotProfiles sync bot profiles in background if auto sync is enabled in branding of kb
+func (u *BotProfileUsecase) AutoSyncBotProfiles(kbID string) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+		kb, err := u.kbRepo.GetKnowledgeBaseByID(ctx, kbID)
+		if err != nil {
+			u.logger.Error("get kb for bot profile sync failed", log.String("kb_id", kbID), log.Error(err))
+			return
+		}
+		if !kb.BrandingSettings.AutoSync {
+			return
+		}
+		resp, err := u.SyncBotProfiles(ctx, &domain.SyncBotProfilesReq{KBID: kbID})
+		if err != nil {
+			u.logger.Error("sync bot profiles failed", log.String("kb_id", kbID), log.Error(err))
+			return
+		}
+		for _, result := range resp.Results {
+			if result.Status == domain.BotProfileSyncStatusFailed {
+				u.logger.Warn("sync bot profile failed", log.String("kb_id", kbID), log.String("app_id", result.AppID), log.String("error", result.Error))
+			}
+		}
+	}()
+}
+
+func (u *BotProfileUsecase) syncApp(app *domain.App, profile *domain.BotProfile, avatar []byte) *domain.BotProfileSyncResult {
+	result := &domain.BotProfileSyncResult{
+		AppID:   app.ID,
+		AppName: app.Name,
+		AppType: app.Type,
+		Fields:  []string{},
+	}
+	fields, ok := domain.BotProfileFields[app.Type]
+	if !ok {
+		result.Status = domain.BotProfileSyncStatusUnsupported
+		result.Error = errBotProfileUnsupported.Error()
+		return result
+	}
+	var err error
+	switch app.Type {
+	case domain.AppTypeWechatBot:
+		err = u.syncWechatApp(app, profile, avatar)
+	case domain.AppTypeDisCordBot:
+		err = u.syncDiscordBot(app, profile, avatar)
+	}
+	if err != nil {
+		result.Status = domain.BotProfileSyncStatusFailed
+		result.Error = err.Error()
+		return result
+	}
+	result.Status = domain.BotProfileSyncStatusSynced
+	for _, field := range fields {
+		if (field == "avatar" && len(avatar) == 0) || (field == "description" && profile.Description == "") {
+			continue
+		}
+		result.Fields = append(result.Fields, field)
+	}
+	return result
+}
+
+func (u *BotProfileUsecase) syncWechatApp(app *domain.App, profile *domain.BotProfile, avatar []byte) error {
+	settings := app.Settings
+	if settings.WeChatAppCorpID == "" || settings.WeChatAppSecret == "" || settings.WeChatAppAgentID == "" {
+		return errors.New("wecom app is not configured")
+	}
+	cfg, err := wechat.NewWechatConfig(context.Background(), settings.WeChatAppCorpID, settings.WeChatAppToken, settings.WeChatAppEncodingAESKey,
+		app.KBID, settings.WeChatAppSecret, settings.WeChatAppAgentID, u.logger)
+	if err != nil {
+		return err
+	}
+	return cfg.UpdateAgentProfile(profile.Name, profile.Description, avatar, "avatar"+avatarExt(avatar))
+}
+
+func (u *BotProfileUsecase) syncDiscordBot(app *domain.App, profile *domain.BotProfile, avatar []byte) error {
+	if app.Settings.DisCordBotToken == "" {
+		return errors.New("discord bot is not configured")
+	}
+	client, err := discord.NewDiscordClient(u.logger, app.Settings.DisCordBotToken, nil)
+	if err != nil {
+		return err
+	}
+	return client.UpdateProfile(profile.Name, avatar, profile.Description)
+}
+
+// loadAvatar image bytes of data uri, uploaded /static-file path or http url
+func (u *BotProfileUsecase) loadAvatar(ctx context.Context, avatar string) ([]byte, error) {
+	var reader io.Reader
+	switch {
+	case strings.HasPrefix(avatar, "data:"):
+		_, encoded, ok := strings.Cut(avatar, ";base64,")
+		if !ok {
+			return nil, errors.New("avatar data uri is not base64")
+		}
+		return base64.StdEncoding.DecodeString(encoded)
+	case strings.HasPrefix(avatar, "/"+domain.Bucket+"/"):
+		object, err := u.minioClient.GetObject(ctx, domain.Bucket, strings.TrimPrefix(avatar, "/"+domain.Bucket+"/"), minio.GetObjectOptions{})
+		if err != nil {
+			return nil, err
+		}
+		defer object.Close()
+		reader = object
+	case strings.HasPrefix(avatar, "http://"), strings.HasPrefix(avatar, "https://"):
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, avatar, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := u.httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("download avatar failed: %s", resp.Status)
+		}
+		reader = resp.Body
+	default:
+		return nil, fmt.Errorf("unsupported avatar %q", avatar)
+	}
+	data, err := io.ReadAll(io.LimitReader(reader, domain.BotAvatarMaxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > domain.BotAvatarMaxSize {
+		return nil, fmt.Errorf("avatar is larger than %d bytes", domain.BotAvatarMaxSize)
+	}
+	return data, nil
+}
+
+// avatarExt file extension of the image, channels check the extension of uploads
+func avatarExt(image []byte) string {
+	switch http.DetectContentType(image) {
+	case "image/png":
+		return ".png"
+	case "image/gif":
+		return ".gif"
+	}
+	return ".jpg"
+}
@@ -2,14 +2,21 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
+	einomodel "github.com/cloudwego/eino/components/model"
 	"github.com/cloudwego/eino/schema"
 	"github.com/google/uuid"
+	"github.com/samber/lo"
 	"gorm.io/gorm"
 
 	"github.com/chaitin/panda-wiki/domain"
 	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/pkg/tokenizer"
+	"github.com/chaitin/panda-wiki/repo/ipdb"
 	"github.com/chaitin/panda-wiki/repo/pg"
 )
 
@@ -18,15 +25,33 @@ type ChatUsecase struct {
 	conversationUsecase *ConversationUsecase
 	modelUsecase        *ModelUsecase
 	appRepo             *pg.AppRepository
+	statRepo            *pg.StatRepository
+	kbRepo              *pg.KnowledgeBaseRepository
+	botDetector         *BotDetector
+	anomalyUsecase      *AnomalyUsecase
+	ipRepo              *ipdb.IPAddressRepo
+	nodeACLUsecase      *NodeACLUsecase
+	promptTemplateRepo  *pg.PromptTemplateRepository
+	answerCacheUsecase  *AnswerCacheUsecase
+	guardrailUsecase    *GuardrailUsecase
 	logger              *log.Logger
 }
 
-func NewChatUsecase(llmUsecase *LLMUsecase, conversationUsecase *ConversationUsecase, modelUsecase *ModelUsecase, appRepo *pg.AppRepository, logger *log.Logger) *ChatUsecase {
+func NewChatUsecase(llmUsecase *LLMUsecase, conversationUsecase *ConversationUsecase, modelUsecase *ModelUsecase, appRepo *pg.AppRepository, statRepo *pg.StatRepository, kbRepo *pg.KnowledgeBaseRepository, botDetector *BotDetector, anomalyUsecase *AnomalyUsecase, ipRepo *ipdb.IPAddressRepo, nodeACLUsecase *NodeACLUsecase, promptTemplateRepo *pg.PromptTemplateRepository, answerCacheUsecase *AnswerCacheUsecase, guardrailUsecase *GuardrailUsecase, logger *log.Logger) *ChatUsecase {
 	u := &ChatUsecase{
 		llmUsecase:          llmUsecase,
 		conversationUsecase: conversationUsecase,
 		modelUsecase:        modelUsecase,
 		appRepo:             appRepo,
+		statRepo:            statRepo,
+		kbRepo:              kbRepo,
+		botDetector:         botDetector,
+		anomalyUsecase:      anomalyUsecase,
+		ipRepo:              ipRepo,
+		nodeACLUsecase:      nodeACLUsecase,
+		promptTemplateRepo:  promptTemplateRepo,
+		answerCacheUsecase:  answerCacheUsecase,
+		guardrailUsecase:    guardrailUsecase,
 		logger:              logger.WithModule("usecase.chat"),
 	}
 	return u
@@ -36,28 +61,56 @@ func (u *ChatUsecase) Chat(ctx context.Context, req *domain.ChatRequest) (<-chan
 	eventCh := make(chan domain.SSEEvent, 100)
 	go func() {
 		defer close(eventCh)
+		startedAt := time.Now()
 		// 1. get app detail and validate app
 		app, err := u.appRepo.GetOrCreateApplByKBIDAndType(ctx, req.KBID, req.AppType)
 		if err != nil {
-			eventCh <- domain.SSEEvent{Type: "error", Content: "app not found"}
+			eventCh <- domain.SSEEvent{Type: "error", Content: "app not found", Code: domain.ErrCodeAppNotFound}
 			return
 		}
 		req.KBID = app.KBID
 		req.AppID = app.ID
 		req.AppType = app.Type
+		kb, err := u.kbRepo.GetKnowledgeBaseByID(ctx, req.KBID)
+		if err != nil {
+			u.logger.Error("failed to get kb", log.Error(err))
+			eventCh <- domain.SSEEvent{Type: "error", Content: "failed to get kb", Code: domain.ErrorCodeOf(err)}
+			return
+		}
+		// refuse abnormal traffic before anything is saved or sent to the model
+		if err := u.anomalyUsecase.Check(ctx, kb.AnomalySettings, req.KBID, req.AppID, req.RemoteIP, req.Message); err != nil {
+			if errors.Is(err, domain.ErrAnomalyInputTooLong) {
+				eventCh <- domain.SSEEvent{Type: "error", Content: domain.DefaultAnomalyLongText, Code: domain.ErrCodeInvalidRequest}
+			} else {
+				eventCh <- domain.SSEEvent{Type: "error", Content: domain.DefaultAnomalyBlockText, Code: domain.ErrCodeBlocked}
+			}
+			return
+		}
 		// 2. get model and validate model
-		model, err := u.modelUsecase.GetChatModel(ctx)
+		models, err := u.modelUsecase.GetKBChatModels(ctx, kb)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				eventCh <- domain.SSEEvent{Type: "error", Content: "请前往管理后台，点击右上角的“系统设置”配置推理大模型。", Code: domain.ErrCodeModelNotConfigured}
+			} else {
+				eventCh <- domain.SSEEvent{Type: "error", Content: "模型获取失败", Code: domain.ErrorCodeOf(err)}
+			}
+			return
+		}
+		models, exceededMessage, err := u.modelUsecase.ResolveChatModels(ctx, models)
 		if err != nil {
-			if err == gorm.ErrRecordNotFound {
-				eventCh <- domain.SSEEvent{Type: "error", Content: "请前往管理后台，点击右上角的“系统设置”配置推理大模型。"}
+			if errors.Is(err, domain.ErrModelBudgetExceeded) {
+				eventCh <- domain.SSEEvent{Type: "error", Content: exceededMessage, Code: domain.ErrCodeQuotaExceeded}
 			} else {
-				eventCh <- domain.SSEEvent{Type: "error", Content: "模型获取失败"}
+				u.logger.Error("failed to check model budget", log.Error(err))
+				eventCh <- domain.SSEEvent{Type: "error", Content: "模型获取失败", Code: domain.ErrorCodeOf(err)}
 			}
 			return
 		}
-		req.ModelInfo = model
+		// the first model until the answer is served, failover models are tried in order
+		req.ModelInfo = models[0]
 		// 3. conversation management
-		if req.ConversationID == "" {
+		newConversation := req.ConversationID == ""
+		if newConversation {
 			id, err := uuid.NewV7()
 			if err != nil {
 				u.logger.Error("failed to generate conversation uuid", log.Error(err))
@@ -66,6 +119,11 @@ func (u *ChatUsecase) Chat(ctx context.Context, req *domain.ChatRequest) (<-chan
 			conversationID := id.String()
 			req.ConversationID = conversationID
 			nonce := uuid.New().String()
+			// only web and widget chats come from browsers, bot apps are trusted
+			isBot := false
+			if req.AppType == domain.AppTypeWeb || req.AppType == domain.AppTypeWidget {
+				isBot = u.botDetector.IsBot(ctx, "conversation", req.RemoteIP, req.UserAgent, domain.BotConversationLimitPerMinute)
+			}
 			eventCh <- domain.SSEEvent{Type: "conversation_id", Content: conversationID}
 			eventCh <- domain.SSEEvent{Type: "nonce", Content: nonce}
 			err = u.conversationUsecase.CreateConversation(ctx, &domain.Conversation{
@@ -74,24 +132,36 @@ func (u *ChatUsecase) Chat(ctx context.Context, req *domain.ChatRequest) (<-chan
 				AppID:     req.AppID,
 				KBID:      req.KBID,
 				Subject:   req.Message,
+				Title:     domain.HeuristicConversationTitle(req.Message),
 				RemoteIP:  req.RemoteIP,
 				Info:      req.Info,
+				IsBot:     isBot,
 				CreatedAt: time.Now(),
 			})
 			if err != nil {
 				u.logger.Error("failed to create chat conversation", log.Error(err))
-				eventCh <- domain.SSEEvent{Type: "error", Content: "failed to create chat conversation"}
+				eventCh <- domain.SSEEvent{Type: "error", Content: "failed to create chat conversation", Code: domain.ErrCodeInternal}
 				return
 			}
+			if req.SessionID != "" && !isBot {
+				if err := u.statRepo.CreateFunnelEvent(ctx, &domain.StatFunnelEvent{
+					KBID:      req.KBID,
+					SessionID: req.SessionID,
+					Step:      domain.FunnelStepChat,
+					CreatedAt: time.Now(),
+				}); err != nil {
+					u.logger.Warn("failed to record funnel chat", log.Error(err))
+				}
+			}
 		} else {
 			if req.Nonce == "" {
-				eventCh <- domain.SSEEvent{Type: "error", Content: "nonce is required"}
+				eventCh <- domain.SSEEvent{Type: "error", Content: "nonce is required", Code: domain.ErrCodeInvalidRequest}
 				return
 			}
 			err := u.conversationUsecase.ValidateConversationNonce(ctx, req.ConversationID, req.Nonce)
 			if err != nil {
 				u.logger.Error("failed to validate chat conversation nonce", log.Error(err))
-				eventCh <- domain.SSEEvent{Type: "error", Content: "validate chat conversation nonce failed"}
+				eventCh <- domain.SSEEvent{Type: "error", Content: "validate chat conversation nonce failed", Code: domain.ErrCodeInvalidRequest}
 				return
 			}
 		}
@@ -105,69 +175,612 @@ func (u *ChatUsecase) Chat(ctx context.Context, req *domain.ChatRequest) (<-chan
 			RemoteIP:       req.RemoteIP,
 		}); err != nil {
 			u.logger.Error("failed to save user question to conversation message", log.Error(err))
-			eventCh <- domain.SSEEvent{Type: "error", Content: "failed to save user question to conversation message"}
+			eventCh <- domain.SSEEvent{Type: "error", Content: "failed to save user question to conversation message", Code: domain.ErrCodeInternal}
 			return
 		}
+		// refuse questions about disabled topics of the kb content policy
+		compliance := kb.ComplianceSettings.Effective()
+		if topic := compliance.MatchDisabledTopic(req.Message); topic != nil {
+			u.logger.Info("question blocked by content policy", log.String("kb_id", req.KBID), log.String("topic", topic.Name))
+			eventCh <- domain.SSEEvent{Type: "data", Content: compliance.BlockedReply}
+			messageID := uuid.New().String()
+			if err := u.conversationUsecase.CreateChatConversationMessage(ctx, req.KBID, &domain.ConversationMessage{
+				ID:             messageID,
+				ConversationID: req.ConversationID,
+				AppID:          req.AppID,
+				Role:           schema.Assistant,
+				Content:        compliance.BlockedReply,
+				Route:          domain.QuestionRouteBlocked,
+				RemoteIP:       req.RemoteIP,
+			}); err != nil {
+				u.logger.Error("failed to save assistant answer to conversation message", log.Error(err))
+			}
+			eventCh <- domain.SSEEvent{Type: "done", Metadata: &domain.SSEDoneMetadata{
+				ConversationID: req.ConversationID,
+				MessageID:      messageID,
+				Route:          domain.QuestionRouteBlocked,
+				DurationMs:     time.Since(startedAt).Milliseconds(),
+			}}
+			return
+		}
+		// guardrail checks of the app on the question, before retrieval and the model
+		guardrails := app.Settings.Guardrails
+		if violations := guardrails.CheckQuestion(req.Message); len(violations) > 0 {
+			if u.applyGuardrails(ctx, eventCh, req, guardrails, violations, startedAt) {
+				return
+			}
+		}
+		// greetings and small talk get a canned reply without retrieval or the model
+		if chitChat := app.Settings.ChitChat; chitChat.Enabled {
+			if kind, ok := domain.ClassifyChitChat(req.Message); ok {
+				reply := chitChat.Reply(kind)
+				u.logger.Info("question routed to canned reply", log.String("kb_id", req.KBID), log.String("kind", string(kind)))
+				eventCh <- domain.SSEEvent{Type: "data", Content: reply}
+				messageID := uuid.New().String()
+				if err := u.conversationUsecase.CreateChatConversationMessage(ctx, req.KBID, &domain.ConversationMessage{
+					ID:             messageID,
+					ConversationID: req.ConversationID,
+					AppID:          req.AppID,
+					Role:           schema.Assistant,
+					Content:        reply,
+					Route:          domain.QuestionRouteChitChat,
+					RemoteIP:       req.RemoteIP,
+				}); err != nil {
+					u.logger.Error("failed to save assistant answer to conversation message", log.Error(err))
+				}
+				eventCh <- domain.SSEEvent{Type: "done", Metadata: &domain.SSEDoneMetadata{
+					ConversationID: req.ConversationID,
+					MessageID:      messageID,
+					Route:          domain.QuestionRouteChitChat,
+					DurationMs:     time.Since(startedAt).Milliseconds(),
+				}}
+				return
+			}
+		}
 		// 4. retrieve documents and format prompt
-		messages, rankedNodes, err := u.llmUsecase.FormatConversationMessages(ctx, req.ConversationID, req.KBID)
+		region := u.resolveRegion(ctx, kb, req.RemoteIP)
+		citation := app.Settings.Citation.StyleOrDefault()
+		aclFilter, err := u.nodeACLUsecase.ViewerFilter(ctx, req.KBID, req.Viewer)
+		if err != nil {
+			u.logger.Error("failed to get node acl of viewer", log.Error(err))
+			eventCh <- domain.SSEEvent{Type: "error", Content: "failed to format chat messages", Code: domain.ErrCodeInternal}
+			return
+		}
+		systemPrompt, promptVersion := u.systemPrompt(ctx, app, kb, citation, req.Locale)
+		messages, rankedNodes, query, err := u.llmUsecase.FormatConversationMessages(ctx, req.ConversationID, req.KBID, region, aclFilter, systemPrompt)
 		if err != nil {
 			u.logger.Error("failed to format chat messages", log.Error(err))
-			eventCh <- domain.SSEEvent{Type: "error", Content: "failed to format chat messages"}
+			eventCh <- domain.SSEEvent{Type: "error", Content: "failed to format chat messages", Code: domain.ErrCodeInternal}
 			return
 		}
+		// recorded only if the follow-up question was rewritten for retrieval
+		retrievalQuery := ""
+		if query != req.Message {
+			retrievalQuery = query
+		}
+		if violations := guardrails.CheckTopic(rankedNodes); len(violations) > 0 {
+			if u.applyGuardrails(ctx, eventCh, req, guardrails, violations, startedAt) {
+				return
+			}
+		}
+		// first questions highly similar to a recent one are served its cached answer without the model, once they
+		// passed the guardrails. answers cached with other prompt templates, guardrails or content policies are stale
+		cacheSettings := kb.AnswerSettings.Cache
+		settingsVersion := domain.AnswerSettingsVersion(promptVersion, guardrails, app.Settings.AnswerPipeline, kb.ComplianceSettings)
+		cacheKey := domain.NewAnswerCacheKey(req.KBID, req.AppID, req.Locale, region, citation, settingsVersion)
+		var questionEmbedding domain.Vector
+		if cacheSettings.Enabled && newConversation {
+			var entry *domain.AnswerCacheEntry
+			entry, questionEmbedding = u.answerCacheUsecase.Lookup(ctx, cacheSettings, cacheKey, aclFilter, req.Message)
+			if entry != nil {
+				for _, chunkResult := range entry.ChunkResults {
+					eventCh <- domain.SSEEvent{Type: "chunk_result", ChunkResult: chunkResult}
+				}
+				if err := u.statRepo.IncrNodeCitations(ctx, req.KBID, entry.NodeIDs); err != nil {
+					u.logger.Warn("failed to incr node citations", log.Error(err))
+				}
+				// cards are shown before the answer, cited documents after the text citing them
+				if citation == domain.CitationStyleCards {
+					for _, reference := range entry.References {
+						eventCh <- domain.SSEEvent{Type: domain.SSEEventReference, Reference: reference}
+					}
+				}
+				eventCh <- domain.SSEEvent{Type: "data", Content: entry.Answer}
+				if citation != domain.CitationStyleCards {
+					for _, reference := range entry.References {
+						eventCh <- domain.SSEEvent{Type: domain.SSEEventReference, Reference: reference}
+					}
+				}
+				messageID := uuid.New().String()
+				if err := u.conversationUsecase.CreateChatConversationMessage(ctx, req.KBID, &domain.ConversationMessage{
+					ID:             messageID,
+					ConversationID: req.ConversationID,
+					AppID:          req.AppID,
+					Role:           schema.Assistant,
+					Content:        entry.Answer,
+					Confidence:     entry.Confidence,
+					Route:          domain.QuestionRouteCache,
+					FollowUps:      entry.FollowUps,
+					RemoteIP:       req.RemoteIP,
+					References:     entry.ConversationReferences(req.ConversationID),
+				}); err != nil {
+					u.logger.Error("failed to save assistant answer to conversation message", log.Error(err))
+				}
+				if len(entry.FollowUps) > 0 {
+					eventCh <- domain.SSEEvent{Type: domain.SSEEventSuggestions, Suggestions: entry.FollowUps.Texts(), FollowUps: entry.FollowUps}
+				}
+				eventCh <- domain.SSEEvent{Type: "done", Metadata: &domain.SSEDoneMetadata{
+					ConversationID: req.ConversationID,
+					MessageID:      messageID,
+					Route:          domain.QuestionRouteCache,
+					Confidence:     entry.Confidence,
+					DurationMs:     time.Since(startedAt).Milliseconds(),
+				}}
+				return
+			}
+		}
+		// long conversations keep the most recent history which fits in the budget
+		messages = tokenizer.TrimHistory(string(req.ModelInfo.Model), messages, domain.HistoryTokenBudget)
+		chunkResults := make(domain.AnswerCacheChunks, 0, len(rankedNodes))
 		for _, node := range rankedNodes {
 			chunkResult := domain.NodeCotentChunkSSE{
 				NodeID:  node.NodeID,
 				Name:    node.NodeName,
 				Summary: node.NodeSummary,
 			}
+			chunkResults = append(chunkResults, &chunkResult)
 			eventCh <- domain.SSEEvent{Type: "chunk_result", ChunkResult: &chunkResult}
 		}
+		if err := u.statRepo.IncrNodeCitations(ctx, req.KBID, lo.Uniq(lo.Map(rankedNodes, func(node *domain.RankedNodeChunks, _ int) string {
+			return node.NodeID
+		}))); err != nil {
+			u.logger.Warn("failed to incr node citations", log.Error(err))
+		}
+		// reply reference links only when retrieval is not confident enough for an answer
+		gate := kb.AnswerSettings.ConfidenceGate
+		confidence := gate.Evaluate(query, rankedNodes)
+		if gate.Enabled && !confidence.Confident {
+			u.logger.Info("low confidence answer", log.String("kb_id", req.KBID), log.Any("retrieval_score", confidence.RetrievalScore), log.Any("groundedness", confidence.Groundedness))
+			reply := gate.LowConfidenceMessage()
+			var references []*domain.ConversationReference
+			if citation == domain.CitationStyleCards {
+				// retrieved documents are already shown as cards
+				references = domain.CardReferences(req.ConversationID, req.AppID, rankedNodes, kb.AccessSettings.BaseURL)
+			} else {
+				reply = lowConfidenceReply(reply, rankedNodes, kb.AccessSettings.BaseURL)
+			}
+			eventCh <- domain.SSEEvent{Type: "data", Content: reply}
+			// the reply lists or shows every retrieved document
+			if citation != domain.CitationStyleNone {
+				for _, reference := range domain.CardSSEReferences(rankedNodes, kb.AccessSettings.BaseURL) {
+					eventCh <- domain.SSEEvent{Type: domain.SSEEventReference, Reference: reference}
+				}
+			}
+			var provenance *domain.AnswerProvenance
+			if app.Settings.Provenance.Enabled {
+				provenance = u.answerProvenance(ctx, req.KBID, nil, rankedNodes)
+			}
+			messageID := uuid.New().String()
+			if err := u.conversationUsecase.CreateChatConversationMessage(ctx, req.KBID, &domain.ConversationMessage{
+				ID:             messageID,
+				ConversationID: req.ConversationID,
+				AppID:          req.AppID,
+				Role:           schema.Assistant,
+				Content:        reply,
+				Confidence:     confidence.RetrievalScore,
+				LowConfidence:  true,
+				Route:          domain.QuestionRouteRAG,
+				Provenance:     provenance,
+				RerankScores:   domain.NodesRerankScores(rankedNodes),
+				RetrievalQuery: retrievalQuery,
+				RemoteIP:       req.RemoteIP,
+				References:     references,
+			}); err != nil {
+				u.logger.Error("failed to save assistant answer to conversation message", log.Error(err))
+			}
+			if provenance != nil {
+				eventCh <- domain.SSEEvent{Type: "provenance", Provenance: provenance}
+			}
+			eventCh <- domain.SSEEvent{Type: "done", Metadata: &domain.SSEDoneMetadata{
+				ConversationID: req.ConversationID,
+				MessageID:      messageID,
+				Route:          domain.QuestionRouteRAG,
+				Confidence:     confidence.RetrievalScore,
+				LowConfidence:  true,
+				DurationMs:     time.Since(startedAt).Milliseconds(),
+			}}
+			return
+		}
 		// 5. LLM inference (streaming callback), message storage, token statistics
 		answer := ""
 		usage := schema.TokenUsage{}
-		chatModel, err := u.llmUsecase.GetChatModel(ctx, req.ModelInfo)
-		if err != nil {
-			u.logger.Error("failed to get chat model", log.Error(err))
-			eventCh <- domain.SSEEvent{Type: "error", Content: "failed to get chat model"}
+		// the answer is saved before streaming and checkpointed, partial answers survive a crash
+		answerMessage := &domain.ConversationMessage{
+			ID:             uuid.New().String(),
+			ConversationID: req.ConversationID,
+			AppID:          req.AppID,
+			Role:           schema.Assistant,
+			Provider:       req.ModelInfo.Provider,
+			Model:          string(req.ModelInfo.Model),
+			ModelID:        req.ModelInfo.ID,
+			Confidence:     confidence.RetrievalScore,
+			Route:          domain.QuestionRouteRAG,
+			RerankScores:   domain.NodesRerankScores(rankedNodes),
+			RetrievalQuery: retrievalQuery,
+			RemoteIP:       req.RemoteIP,
+
+			PromptTemplateVersion: promptVersion,
+		}
+		if err := u.conversationUsecase.StartChatConversationMessage(ctx, answerMessage); err != nil {
+			u.logger.Error("failed to save assistant answer to conversation message", log.Error(err))
+			eventCh <- domain.SSEEvent{Type: "error", Content: "failed to save assistant answer to conversation message", Code: domain.ErrCodeInternal}
 			return
 		}
-		chatErr := u.llmUsecase.ChatWithAgent(ctx, chatModel, messages, &usage, func(ctx context.Context, dataType, chunk string) error {
+		// answers are buffered when a post-processing step may rewrite streamed text
+		pipeline := NewAnswerPipeline(app.Settings.AnswerPipeline, u.logger)
+		buffered := pipeline.Rewrites() || guardrails.BuffersAnswers()
+		checkpointAt := time.Now()
+		// answers cut off by the max tokens of the model are continued up to the cap of the app
+		maxRounds := app.Settings.Continuation.RoundsOrDefault()
+		// banned phrases of the kb are replaced before anything is sent
+		bannedFilter := NewBannedPhraseFilter(compliance.BannedPhrases)
+		var modelOpts []einomodel.Option
+		if len(compliance.StopSequences) > 0 {
+			modelOpts = append(modelOpts, einomodel.WithStop(compliance.StopSequences))
+		}
+		// cited documents are sent as the answer cites them, cards of the retrieved documents before the answer
+		citations := domain.NewCitationTracker(rankedNodes, kb.AccessSettings.BaseURL)
+		// kept with the answer if it is cached
+		sentReferences := make(domain.AnswerCacheReferences, 0)
+		sendAnswer := func(chunk string) {
+			eventCh <- domain.SSEEvent{Type: "data", Content: chunk}
+			if !citation.ListsReferences() {
+				return
+			}
+			for _, reference := range citations.Write(chunk) {
+				sentReferences = append(sentReferences, reference)
+				eventCh <- domain.SSEEvent{Type: domain.SSEEventReference, Reference: reference}
+			}
+		}
+		if citation == domain.CitationStyleCards {
+			for _, reference := range domain.CardSSEReferences(rankedNodes, kb.AccessSettings.BaseURL) {
+				sentReferences = append(sentReferences, reference)
+				eventCh <- domain.SSEEvent{Type: domain.SSEEventReference, Reference: reference}
+			}
+		}
+		var firstChunkAt time.Time
+		servedModel, failovers, chatErr := u.llmUsecase.ChatWithFailover(ctx, models, messages, maxRounds, &usage, func(ctx context.Context, dataType, chunk string) error {
+			if dataType == "data" {
+				if chunk = bannedFilter.Write(chunk); chunk == "" {
+					return nil
+				}
+			}
+			if firstChunkAt.IsZero() {
+				firstChunkAt = time.Now()
+			}
 			answer += chunk
-			eventCh <- domain.SSEEvent{Type: dataType, Content: chunk}
+			switch {
+			case dataType != "data":
+				eventCh <- domain.SSEEvent{Type: dataType, Content: chunk}
+			case !buffered:
+				sendAnswer(chunk)
+			}
+			if time.Since(checkpointAt) >= domain.MessageCheckpointInterval {
+				checkpointAt = time.Now()
+				if err := u.conversationUsecase.CheckpointChatConversationMessage(ctx, answerMessage.ID, answer); err != nil {
+					u.logger.Warn("failed to checkpoint assistant answer", log.String("message_id", answerMessage.ID), log.Error(err))
+				}
+			}
 			return nil
-		})
+		}, modelOpts...)
+		if rest := bannedFilter.Flush(); rest != "" {
+			answer += rest
+			if !buffered {
+				sendAnswer(rest)
+			}
+		}
+		// 6. answer post-processing
+		var answerWarnings domain.GuardrailViolations
+		if chatErr == nil {
+			answerCtx := &AnswerContext{
+				Question:    req.Message,
+				Answer:      answer,
+				Settings:    app.Settings.AnswerPipeline,
+				Compliance:  compliance,
+				RankedNodes: rankedNodes,
+				BaseURL:     kb.AccessSettings.BaseURL,
+			}
+			pipeline.Run(ctx, answerCtx)
+			// guardrail checks of the app on the answer, blocked answers are only buffered and never sent
+			if violations := guardrails.CheckAnswer(answerCtx.Answer); len(violations) > 0 {
+				u.guardrailUsecase.Record(ctx, req, violations)
+				if violations.Blocked() {
+					answerCtx.Answer = guardrails.BlockedReplyOrDefault()
+					answerMessage.Route = domain.QuestionRouteGuardrail
+				}
+				answerWarnings = violations.Warnings()
+			}
+			if buffered {
+				sendAnswer(answerCtx.Answer)
+			} else if suffix, ok := strings.CutPrefix(answerCtx.Answer, answer); ok && suffix != "" {
+				sendAnswer(suffix)
+			}
+			answer = answerCtx.Answer
+		}
+		req.ModelInfo = servedModel
 		// save assistant answer to conversation message
-		if err := u.conversationUsecase.CreateChatConversationMessage(ctx, req.KBID, &domain.ConversationMessage{
-			ID:               uuid.New().String(),
-			ConversationID:   req.ConversationID,
-			AppID:            req.AppID,
-			Role:             schema.Assistant,
-			Content:          answer,
-			Provider:         req.ModelInfo.Provider,
-			Model:            string(req.ModelInfo.Model),
-			PromptTokens:     usage.PromptTokens,
-			CompletionTokens: usage.CompletionTokens,
-			TotalTokens:      usage.TotalTokens,
-			RemoteIP:         req.RemoteIP,
-		}); err != nil {
+		answerMessage.Content = answer
+		answerMessage.Provider = servedModel.Provider
+		answerMessage.Model = servedModel.Model
+		answerMessage.ModelID = servedModel.ID
+		answerMessage.Failovers = failovers
+		answerMessage.PromptTokens = usage.PromptTokens
+		answerMessage.CompletionTokens = usage.CompletionTokens
+		answerMessage.TotalTokens = usage.TotalTokens
+		answerMessage.Status = domain.MessageStatusCompleted
+		switch citation {
+		case domain.CitationStyleCards:
+			answerMessage.References = domain.CardReferences(req.ConversationID, req.AppID, rankedNodes, kb.AccessSettings.BaseURL)
+		case domain.CitationStyleNone:
+			answerMessage.References = []*domain.ConversationReference{}
+		}
+		if chatErr != nil {
+			answerMessage.Status = domain.MessageStatusFailed
+		}
+		if app.Settings.Provenance.Enabled {
+			answerMessage.Provenance = u.answerProvenance(ctx, req.KBID, req.ModelInfo, rankedNodes)
+		}
+		if err := u.conversationUsecase.FinishChatConversationMessage(ctx, req.KBID, answerMessage); err != nil {
 			u.logger.Error("failed to save assistant answer to conversation message", log.Error(err))
-			eventCh <- domain.SSEEvent{Type: "error", Content: "failed to save assistant answer to conversation message"}
+			eventCh <- domain.SSEEvent{Type: "error", Content: "failed to save assistant answer to conversation message", Code: domain.ErrCodeInternal}
 			return
 		}
 		// update model usage
 		if err := u.modelUsecase.UpdateUsage(ctx, req.ModelInfo.ID, &usage); err != nil {
 			u.logger.Error("failed to update model usage", log.Error(err))
-			eventCh <- domain.SSEEvent{Type: "error", Content: "failed to update model usage"}
+			eventCh <- domain.SSEEvent{Type: "error", Content: "failed to update model usage", Code: domain.ErrCodeInternal}
 			return
 		}
 
 		if chatErr != nil {
-			u.logger.Error("对话失败", log.Error(chatErr))
-			eventCh <- domain.SSEEvent{Type: "error", Content: "对话失败，请稍后再试"}
+			code := domain.ErrCodeModelFailed
+			if errors.Is(chatErr, context.DeadlineExceeded) {
+				code = domain.ErrCodeModelTimeout
+			}
+			u.logger.Error("对话失败", log.String("code", string(code)), log.Error(chatErr))
+			eventCh <- domain.SSEEvent{Type: "error", Content: "对话失败，请稍后再试", Code: code}
 			return
 		}
-		eventCh <- domain.SSEEvent{Type: "done"}
+		if answerMessage.Provenance != nil {
+			eventCh <- domain.SSEEvent{Type: "provenance", Provenance: answerMessage.Provenance}
+		}
+		for _, warning := range answerWarnings {
+			eventCh <- domain.SSEEvent{Type: domain.SSEEventGuardrail, Guardrail: warning}
+		}
+		blocked := answerMessage.Route == domain.QuestionRouteGuardrail
+		if newConversation && app.Settings.ConversationTitle.ModeOrDefault() == domain.ConversationTitleModeLLM {
+			u.generateConversationTitle(req.ConversationID, req.Message, req.ModelInfo)
+		}
+		if followUp := app.Settings.FollowUp; followUp.Enabled && len(rankedNodes) > 0 && !blocked {
+			if followUps := u.generateFollowUps(ctx, req.Message, answer, rankedNodes, req.ModelInfo, followUp.CountOrDefault()); len(followUps) > 0 {
+				answerMessage.FollowUps = followUps
+				if err := u.conversationUsecase.UpdateMessageFollowUps(ctx, answerMessage.ID, followUps); err != nil {
+					u.logger.Warn("failed to save follow-up questions", log.String("message_id", answerMessage.ID), log.Error(err))
+				}
+				eventCh <- domain.SSEEvent{Type: domain.SSEEventSuggestions, Suggestions: followUps.Texts(), FollowUps: followUps}
+			}
+		}
+		// the question was embedded by the cache lookup, answers without documents are not cached
+		if questionEmbedding != nil && len(rankedNodes) > 0 && !blocked {
+			u.answerCacheUsecase.Save(ctx, cacheSettings, &domain.AnswerCacheEntry{
+				KBID:            cacheKey.KBID,
+				AppID:           cacheKey.AppID,
+				Locale:          cacheKey.Locale,
+				Region:          cacheKey.Region,
+				CitationStyle:   cacheKey.CitationStyle,
+				SettingsVersion: cacheKey.SettingsVersion,
+				Question:        req.Message,
+				Embedding:       questionEmbedding,
+				MessageID:       answerMessage.ID,
+				Answer:          answer,
+				NodeIDs: lo.Uniq(lo.Map(rankedNodes, func(node *domain.RankedNodeChunks, _ int) string {
+					return node.NodeID
+				})),
+				ChunkResults: chunkResults,
+				References:   sentReferences,
+				FollowUps:    answerMessage.FollowUps,
+				Confidence:   answerMessage.Confidence,
+			})
+		}
+		metadata := &domain.SSEDoneMetadata{
+			ConversationID:   req.ConversationID,
+			MessageID:        answerMessage.ID,
+			Route:            answerMessage.Route,
+			Provider:         answerMessage.Provider,
+			Model:            answerMessage.Model,
+			ModelID:          answerMessage.ModelID,
+			Failovers:        failovers,
+			PromptTokens:     usage.PromptTokens,
+			CompletionTokens: usage.CompletionTokens,
+			TotalTokens:      usage.TotalTokens,
+			Confidence:       answerMessage.Confidence,
+			DurationMs:       time.Since(startedAt).Milliseconds(),
+
+			PromptTemplateVersion: answerMessage.PromptTemplateVersion,
+		}
+		if !firstChunkAt.IsZero() {
+			metadata.FirstChunkMs = firstChunkAt.Sub(startedAt).Milliseconds()
+		}
+		eventCh <- domain.SSEEvent{Type: "done", Metadata: metadata}
 	}()
 	return eventCh, nil
 }
+
+// PrefetchRetrieval retrieve documents of the draft question while the user is typing,
+// so the message sent with it only waits on the model
+func (u *ChatUsecase) PrefetchRetrieval(ctx context.Context, req *domain.PrefetchRetrievalReq) (*domain.PrefetchRetrievalResp, error) {
+	if u.botDetector.IsBot(ctx, "prefetch", req.RemoteIP, req.UserAgent, domain.RetrievalPrefetchLimitPerMinute) {
+		return &domain.PrefetchRetrievalResp{}, nil
+	}
+	ready, err := u.llmUsecase.PrefetchRetrieval(ctx, req.KBID, req.Message)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.PrefetchRetrievalResp{Ready: ready}, nil
+}
+
+// applyGuardrails record the matched checks of the question, reply the blocked reply of the app if any of them blocks
+// and send warnings otherwise. return true if the question was blocked
+func (u *ChatUsecase) applyGuardrails(
+	ctx context.Context,
+	eventCh chan<- domain.SSEEvent,
+	req *domain.ChatRequest,
+	guardrails domain.GuardrailSettings,
+	violations domain.GuardrailViolations,
+	startedAt time.Time,
+) bool {
+	u.guardrailUsecase.Record(ctx, req, violations)
+	if !violations.Blocked() {
+		for _, warning := range violations.Warnings() {
+			eventCh <- domain.SSEEvent{Type: domain.SSEEventGuardrail, Guardrail: warning}
+		}
+		return false
+	}
+	reply := guardrails.BlockedReplyOrDefault()
+	eventCh <- domain.SSEEvent{Type: "data", Content: reply}
+	messageID := uuid.New().String()
+	if err := u.conversationUsecase.CreateChatConversationMessage(ctx, req.KBID, &domain.ConversationMessage{
+		ID:             messageID,
+		ConversationID: req.ConversationID,
+		AppID:          req.AppID,
+		Role:           schema.Assistant,
+		Content:        reply,
+		Route:          domain.QuestionRouteGuardrail,
+		RemoteIP:       req.RemoteIP,
+	}); err != nil {
+		u.logger.Error("failed to save assistant answer to conversation message", log.Error(err))
+	}
+	eventCh <- domain.SSEEvent{Type: "done", Metadata: &domain.SSEDoneMetadata{
+		ConversationID: req.ConversationID,
+		MessageID:      messageID,
+		Route:          domain.QuestionRouteGuardrail,
+		DurationMs:     time.Since(startedAt).Milliseconds(),
+	}}
+	return true
+}
+
+// lowConfidenceReply format message with reference links of retrieved documents, in the same reference block format of answers
+func lowConfidenceReply(message string, rankedNodes []*domain.RankedNodeChunks, baseURL string) string {
+	reply := strings.Builder{}
+	reply.WriteString(message)
+	if len(rankedNodes) > 0 {
+		reply.WriteString("\n\n")
+	}
+	for i, node := range rankedNodes {
+		reply.WriteString(fmt.Sprintf("> [%d]. [%s](%s)\n", i+1, node.NodeName, node.GetURL(baseURL)))
+	}
+	return reply.String()
+}
+
+// generateConversationTitle replace the heuristic title of a new conversation by a title of the chat model in background,
+// the heuristic title is kept if generation fails
+func (u *ChatUsecase) generateConversationTitle(conversationID, question string, model *domain.Model) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), domain.ConversationTitleTimeout)
+		defer cancel()
+		title, err := u.llmUsecase.GenerateConversationTitle(ctx, model, question)
+		if err != nil {
+			u.logger.Warn("failed to generate conversation title", log.String("conversation_id", conversationID), log.Error(err))
+			return
+		}
+		if title == "" {
+			return
+		}
+		if err := u.conversationUsecase.UpdateConversationTitle(ctx, conversationID, title); err != nil {
+			u.logger.Warn("failed to update conversation title", log.String("conversation_id", conversationID), log.Error(err))
+		}
+	}()
+}
+
+// systemPrompt system prompt of the latest template of the app and its version, the built-in prompt and 0 if it has
+// none or it fails to load
+func (u *ChatUsecase) systemPrompt(ctx context.Context, app *domain.App, kb *domain.KnowledgeBase, citation domain.CitationStyle, locale string) (string, int) {
+	promptContext := &domain.PromptContext{
+		KBName:   kb.Name,
+		AppName:  app.Name,
+		Locale:   locale,
+		Citation: citation,
+		Now:      time.Now(),
+	}
+	template, err := u.promptTemplateRepo.GetLatestAppPromptTemplate(ctx, app.ID)
+	if err != nil {
+		u.logger.Warn("failed to get prompt template of app", log.String("app_id", app.ID), log.Error(err))
+	}
+	if template == nil {
+		return domain.RenderSystemPrompt("", promptContext), 0
+	}
+	return domain.RenderSystemPrompt(template.Content, promptContext), template.Version
+}
+
+// systemPromptVersion system prompt of the template version of the app, the built-in prompt for version 0
+func (u *ChatUsecase) systemPromptVersion(ctx context.Context, app *domain.App, kb *domain.KnowledgeBase, citation domain.CitationStyle, locale string, version int) (string, error) {
+	promptContext := &domain.PromptContext{
+		KBName:   kb.Name,
+		AppName:  app.Name,
+		Locale:   locale,
+		Citation: citation,
+		Now:      time.Now(),
+	}
+	if version == 0 {
+		return domain.RenderSystemPrompt("", promptContext), nil
+	}
+	template, err := u.promptTemplateRepo.GetAppPromptTemplate(ctx, app.ID, version)
+	if err != nil {
+		return "", fmt.Errorf("get prompt template version %d of app failed: %w", version, err)
+	}
+	return domain.RenderSystemPrompt(template.Content, promptContext), nil
+}
+
+// generateFollowUps follow-up questions of the answer grounded in its documents, none if generation fails or times out
+func (u *ChatUsecase) generateFollowUps(ctx context.Context, question, answer string, rankedNodes []*domain.RankedNodeChunks, model *domain.Model, count int) domain.FollowUpQuestions {
+	ctx, cancel := context.WithTimeout(ctx, domain.FollowUpTimeout)
+	defer cancel()
+	if _, after, ok := strings.Cut(answer, "</think>"); ok {
+		answer = after
+	}
+	followUps, err := u.llmUsecase.GenerateFollowUps(ctx, model, question, strings.TrimSpace(answer), rankedNodes, count)
+	if err != nil {
+		u.logger.Warn("failed to generate follow-up questions", log.Error(err))
+		return nil
+	}
+	return followUps
+}
+
+// answerProvenance provenance of an answer generated now, a kb never released has no release
+func (u *ChatUsecase) answerProvenance(ctx context.Context, kbID string, model *domain.Model, rankedNodes []*domain.RankedNodeChunks) *domain.AnswerProvenance {
+	release, err := u.kbRepo.GetLatestRelease(ctx, kbID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			u.logger.Warn("failed to get latest release of answer provenance", log.String("kb_id", kbID), log.Error(err))
+		}
+		release = nil
+	}
+	return domain.NewAnswerProvenance(kbID, release, model, rankedNodes)
+}
+
+// resolveRegion region of the visitor by ip, the kb default region is used if the ip is unknown
+func (u *ChatUsecase) resolveRegion(ctx context.Context, kb *domain.KnowledgeBase, remoteIP string) *domain.GeoRegion {
+	if !kb.GeoSettings.Enabled {
+		return nil
+	}
+	country := ""
+	if remoteIP != "" {
+		ipAddress, err := u.ipRepo.GetIPAddress(ctx, remoteIP)
+		if err != nil {
+			u.logger.Warn("failed to get ip address", log.String("remote_ip", remoteIP), log.Error(err))
+		} else {
+			country = ipAddress.Country
+		}
+	}
+	return kb.GeoSettings.Resolve(country)
+}
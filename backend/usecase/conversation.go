@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"regexp"
+	"time"
 
 	"github.com/samber/lo"
 
@@ -11,36 +12,82 @@ import (
 	"github.com/chaitin/panda-wiki/log"
 	"github.com/chaitin/panda-wiki/repo/cache"
 	"github.com/chaitin/panda-wiki/repo/ipdb"
+	"github.com/chaitin/panda-wiki/repo/mq"
 	"github.com/chaitin/panda-wiki/repo/pg"
 )
 
 type ConversationUsecase struct {
-	repo         *pg.ConversationRepository
-	nodeRepo     *pg.NodeRepository
-	geoCacheRepo *cache.GeoRepo
-	logger       *log.Logger
-	ipRepo       *ipdb.IPAddressRepo
+	repo          *pg.ConversationRepository
+	nodeRepo      *pg.NodeRepository
+	statRepo      *pg.StatRepository
+	geoCacheRepo  *cache.GeoRepo
+	statEventRepo *mq.StatEventRepository
+	webhookRepo   *mq.WebhookRepository
+	kbUsecase     *KnowledgeBaseUsecase
+	logger        *log.Logger
+	ipRepo        *ipdb.IPAddressRepo
 }
 
 func NewConversationUsecase(
 	repo *pg.ConversationRepository,
 	nodeRepo *pg.NodeRepository,
+	statRepo *pg.StatRepository,
 	geoCacheRepo *cache.GeoRepo,
+	statEventRepo *mq.StatEventRepository,
+	webhookRepo *mq.WebhookRepository,
+	kbUsecase *KnowledgeBaseUsecase,
 	logger *log.Logger,
 	ipRepo *ipdb.IPAddressRepo,
 ) *ConversationUsecase {
 	return &ConversationUsecase{
-		repo:         repo,
-		nodeRepo:     nodeRepo,
-		geoCacheRepo: geoCacheRepo,
-		ipRepo:       ipRepo,
-		logger:       logger.WithModule("usecase.conversation"),
+		repo:          repo,
+		nodeRepo:      nodeRepo,
+		statRepo:      statRepo,
+		geoCacheRepo:  geoCacheRepo,
+		statEventRepo: statEventRepo,
+		webhookRepo:   webhookRepo,
+		kbUsecase:     kbUsecase,
+		ipRepo:        ipRepo,
+		logger:        logger.WithModule("usecase.conversation"),
 	}
 }
 
 func (u *ConversationUsecase) CreateChatConversationMessage(ctx context.Context, kbID string, conversation *domain.ConversationMessage) error {
-	references := extractReferencesBlock(conversation.ID, conversation.AppID, conversation.Content)
-	return u.repo.CreateConversationMessage(ctx, conversation, references)
+	references := messageReferences(conversation)
+	if err := u.repo.CreateConversationMessage(ctx, conversation, references); err != nil {
+		return err
+	}
+	if err := u.webhookRepo.AsyncPublishWebhookEvent(ctx, domain.WebhookEventConversationMessage, kbID, conversation); err != nil {
+		u.logger.Warn("publish webhook event failed", log.Error(err), log.String("conversation_id", conversation.ConversationID))
+	}
+	return nil
+}
+
+// StartChatConversationMessage save an empty streaming answer, checkpointed while the answer streams
+func (u *ConversationUsecase) StartChatConversationMessage(ctx context.Context, conversation *domain.ConversationMessage) error {
+	conversation.Status = domain.MessageStatusStreaming
+	return u.repo.CreateConversationMessage(ctx, conversation, nil)
+}
+
+func (u *ConversationUsecase) CheckpointChatConversationMessage(ctx context.Context, id, content string) error {
+	return u.repo.CheckpointConversationMessage(ctx, id, content)
+}
+
+// UpdateMessageFollowUps save follow-up questions suggested after an answer
+func (u *ConversationUsecase) UpdateMessageFollowUps(ctx context.Context, id string, followUps domain.FollowUpQuestions) error {
+	return u.repo.UpdateMessageFollowUps(ctx, id, followUps)
+}
+
+// FinishChatConversationMessage save the final answer of a streaming message
+func (u *ConversationUsecase) FinishChatConversationMessage(ctx context.Context, kbID string, conversation *domain.ConversationMessage) error {
+	references := messageReferences(conversation)
+	if err := u.repo.FinishConversationMessage(ctx, conversation, references); err != nil {
+		return err
+	}
+	if err := u.webhookRepo.AsyncPublishWebhookEvent(ctx, domain.WebhookEventConversationMessage, kbID, conversation); err != nil {
+		u.logger.Warn("publish webhook event failed", log.Error(err), log.String("conversation_id", conversation.ConversationID))
+	}
+	return nil
 }
 
 func (u *ConversationUsecase) GetConversationList(ctx context.Context, request *domain.ConversationListReq) (*domain.PaginatedResult[[]*domain.ConversationListItem], error) {
@@ -51,6 +98,7 @@ func (u *ConversationUsecase) GetConversationList(ctx context.Context, request *
 	// get ip address
 	ipAddressMap := make(map[string]*domain.IPAddress)
 	lo.Map(conversations, func(conversation *domain.ConversationListItem, _ int) *domain.ConversationListItem {
+		conversation.Title = domain.DisplayTitle(conversation.Title, conversation.Subject)
 		if _, ok := ipAddressMap[conversation.RemoteIP]; !ok {
 			ipAddress, err := u.ipRepo.GetIPAddress(ctx, conversation.RemoteIP)
 			if err != nil {
@@ -67,11 +115,60 @@ func (u *ConversationUsecase) GetConversationList(ctx context.Context, request *
 	return domain.NewPaginatedResult(conversations, total), nil
 }
 
-func (u *ConversationUsecase) GetConversationDetail(ctx context.Context, conversationID string) (*domain.ConversationDetailResp, error) {
+// StreamConversations pass conversations matching the request to the callback one by one, oldest first,
+// messages and references are loaded per batch of the cursor if requested
+func (u *ConversationUsecase) StreamConversations(ctx context.Context, request *domain.ConversationStreamReq, callback func(*domain.ConversationStreamItem) error) error {
+	return u.repo.TraverseConversations(ctx, request, domain.ConversationStreamBatchSize, func(conversations []*domain.Conversation) error {
+		messages := make(map[string][]*domain.ConversationMessage)
+		references := make(map[string][]*domain.ConversationReference)
+		if request.WithMessages {
+			ids := lo.Map(conversations, func(conversation *domain.Conversation, _ int) string {
+				return conversation.ID
+			})
+			batchMessages, err := u.repo.GetMessagesByConversationIDs(ctx, ids)
+			if err != nil {
+				return err
+			}
+			for _, message := range batchMessages {
+				messages[message.ConversationID] = append(messages[message.ConversationID], message)
+			}
+			batchReferences, err := u.repo.GetReferencesByConversationIDs(ctx, ids)
+			if err != nil {
+				return err
+			}
+			for _, reference := range batchReferences {
+				references[reference.ConversationID] = append(references[reference.ConversationID], reference)
+			}
+		}
+		for _, conversation := range conversations {
+			if err := callback(&domain.ConversationStreamItem{
+				Conversation: conversation,
+				Messages:     messages[conversation.ID],
+				References:   references[conversation.ID],
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetConversationDetailETag etag of the conversation detail, changes when messages or references are added
+func (u *ConversationUsecase) GetConversationDetailETag(ctx context.Context, conversationID, since string) (string, error) {
+	version, err := u.repo.GetConversationVersion(ctx, conversationID)
+	if err != nil {
+		return "", err
+	}
+	return version.ETag(conversationID, since), nil
+}
+
+// GetConversationDetail messages from the since message on only if since is set, clients replace messages of the same id
+func (u *ConversationUsecase) GetConversationDetail(ctx context.Context, conversationID, since string) (*domain.ConversationDetailResp, error) {
 	conversation, err := u.repo.GetConversationDetail(ctx, conversationID)
 	if err != nil {
 		return nil, err
 	}
+	conversation.Title = domain.DisplayTitle(conversation.Title, conversation.Subject)
 	// get ip address
 	ipAddress, err := u.ipRepo.GetIPAddress(ctx, conversation.RemoteIP)
 	if err != nil {
@@ -80,10 +177,19 @@ func (u *ConversationUsecase) GetConversationDetail(ctx context.Context, convers
 		conversation.IPAddress = ipAddress
 	}
 	// get messages
-	messages, err := u.repo.GetConversationMessagesByID(ctx, conversationID)
+	var messages []*domain.ConversationMessage
+	if since != "" {
+		messages, err = u.repo.GetConversationMessagesSince(ctx, conversationID, since)
+	} else {
+		messages, err = u.repo.GetConversationMessagesByID(ctx, conversationID)
+	}
 	if err != nil {
 		return nil, err
 	}
+	now := time.Now()
+	for _, message := range messages {
+		message.Status = message.EffectiveStatus(now)
+	}
 	conversation.Messages = messages
 	// get references
 	references, err := u.repo.GetConversationReferences(ctx, conversationID)
@@ -94,6 +200,18 @@ func (u *ConversationUsecase) GetConversationDetail(ctx context.Context, convers
 	return conversation, nil
 }
 
+// messageReferences references of the message given by the answer style, or listed in its content
+func messageReferences(message *domain.ConversationMessage) []*domain.ConversationReference {
+	references := message.References
+	if references == nil {
+		references = extractReferencesBlock(message.ConversationID, message.AppID, message.Content)
+	}
+	for _, reference := range references {
+		reference.MessageID = message.ID
+	}
+	return references
+}
+
 func extractReferencesBlock(conversationID, appID, text string) []*domain.ConversationReference {
 	// match whole reference block
 	reBlock := regexp.MustCompile(`(?ms)((?:>|\\u003e)\s*\[\d+\]\.\s*\[.*?\]\(.*?\)\s*\n?)+$`)
@@ -136,6 +254,18 @@ func (u *ConversationUsecase) CreateConversation(ctx context.Context, conversati
 	if err := u.repo.CreateConversation(ctx, conversation); err != nil {
 		return err
 	}
+	// nonce is a credential of the conversation, never ship it out
+	event := *conversation
+	event.Nonce = ""
+	if err := u.statEventRepo.AsyncPublishStatEvent(ctx, domain.StatEventTypeConversation, conversation.KBID, &event); err != nil {
+		u.logger.Warn("publish stat event failed", log.Error(err), log.String("conversation_id", conversation.ID))
+	}
+	if err := u.webhookRepo.AsyncPublishWebhookEvent(ctx, domain.WebhookEventConversationCreated, conversation.KBID, &event); err != nil {
+		u.logger.Warn("publish webhook event failed", log.Error(err), log.String("conversation_id", conversation.ID))
+	}
+	if conversation.IsBot && u.kbUsecase.IsBotTrafficFiltered(ctx, conversation.KBID) {
+		return nil
+	}
 	remoteIP := conversation.RemoteIP
 	ipAddress, err := u.ipRepo.GetIPAddress(ctx, remoteIP)
 	if err != nil {
@@ -145,6 +275,62 @@ func (u *ConversationUsecase) CreateConversation(ctx context.Context, conversati
 		if err := u.geoCacheRepo.SetGeo(ctx, conversation.KBID, location); err != nil {
 			u.logger.Warn("set geo cache failed", log.Error(err), log.String("conversation_id", conversation.ID), log.String("ip", remoteIP))
 		}
+		if err := u.statRepo.IncrGeoStat(ctx, conversation.KBID, conversation.AppID, ipAddress); err != nil {
+			u.logger.Warn("incr geo stat failed", log.Error(err), log.String("conversation_id", conversation.ID), log.String("ip", remoteIP))
+		}
+	}
+	return nil
+}
+
+func (u *ConversationUsecase) UpdateConversationTitle(ctx context.Context, conversationID, title string) error {
+	return u.repo.UpdateConversationTitle(ctx, conversationID, title)
+}
+
+// SubmitFeedback rate an answer, the nonce proves the end user owns the conversation
+func (u *ConversationUsecase) SubmitFeedback(ctx context.Context, req *domain.MessageFeedbackReq) error {
+	conversation, err := u.repo.GetConversationByNonce(ctx, req.KBID, req.ConversationID, req.Nonce)
+	if err != nil {
+		return fmt.Errorf("conversation not found: %w", err)
+	}
+	if err := u.repo.SetMessageFeedback(ctx, req.ConversationID, req.MessageID, req.Type, req.Comment); err != nil {
+		return err
+	}
+	// a helpful answer is the last step of the funnel of the session
+	if req.Type == domain.MessageFeedbackLike && req.SessionID != "" && !conversation.IsBot {
+		event := &domain.StatFunnelEvent{
+			KBID:      conversation.KBID,
+			SessionID: req.SessionID,
+			Step:      domain.FunnelStepResolution,
+			CreatedAt: time.Now(),
+		}
+		if err := u.statRepo.CreateFunnelEvent(ctx, event); err != nil {
+			u.logger.Warn("failed to record funnel resolution", log.String("conversation_id", req.ConversationID), log.Error(err))
+		} else if err := u.statEventRepo.AsyncPublishStatEvent(ctx, domain.StatEventTypeFunnel, conversation.KBID, event); err != nil {
+			u.logger.Warn("publish stat event failed", log.Error(err), log.String("type", string(domain.StatEventTypeFunnel)), log.String("kb_id", conversation.KBID))
+		}
 	}
 	return nil
 }
+
+// GetSourceAttributionReport documents most cited by answers of the recent days with the feedback of those answers
+func (u *ConversationUsecase) GetSourceAttributionReport(ctx context.Context, req *domain.SourceAttributionReq) (*domain.SourceAttributionReport, error) {
+	days := req.Days
+	if days <= 0 {
+		days = domain.DefaultSourceAttributionDays
+	}
+	since := time.Now().AddDate(0, 0, -days)
+	report, err := u.repo.GetAnswerFeedbackCounts(ctx, req.KBID, since)
+	if err != nil {
+		return nil, err
+	}
+	items, err := u.repo.GetSourceAttribution(ctx, req.KBID, since, domain.SourceAttributionItemLimit)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		item.SatisfactionRate = domain.SatisfactionRate(item.LikeCount, item.DislikeCount)
+	}
+	report.Days = days
+	report.Items = items
+	return report, nil
+}
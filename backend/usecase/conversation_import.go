@@ -0,0 +1,416 @@
+package usecase
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/google/uuid"
+
+	"github.com/chaitin/panda-wiki/config"
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/repo/pg"
+)
+
+// historicalImportBatchSize conversations inserted in each transaction
+const historicalImportBatchSize = 200
+
+var ErrTranscriptFileTooLarge = errors.New("transcript file size too large")
+
+type ConversationImportUsecase struct {
+	repo   *pg.ConversationRepository
+	config *config.Config
+	logger *log.Logger
+}
+
+func NewConversationImportUsecase(repo *pg.ConversationRepository, config *config.Config, logger *log.Logger) *ConversationImportUsecase {
+	return &ConversationImportUsecase{
+		repo:   repo,
+		config: config,
+		logger: logger.WithModule("usecase.conversation_import"),
+	}
+}
+
+// ImportTranscripts import support transcripts of a legacy helpdesk as historical conversations of the kb.
+// their user questions are picked up by question clustering, unresolved tickets by gap analysis
+func (u *ConversationImportUsecase) ImportTranscripts(ctx context.Context, req *domain.ImportTranscriptsReq, file *multipart.FileHeader) (*domain.ImportTranscriptsResp, error) {
+	if file.Size > u.config.S3.MaxFileSize {
+		return nil, ErrTranscriptFileTooLarge
+	}
+	src, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer src.Close()
+
+	var transcripts []*domain.HistoricalTranscript
+	var warnings []string
+	switch req.Format {
+	case domain.HistoricalSourceCSV:
+		transcripts, warnings, err = parseTranscriptCSV(src)
+	case domain.HistoricalSourceZendesk:
+		transcripts, warnings, err = parseZendeskExport(src)
+	default:
+		err = fmt.Errorf("unsupported transcript format: %s", req.Format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	importedIDs, err := u.repo.GetHistoricalExternalIDs(ctx, req.KBID, req.Format)
+	if err != nil {
+		return nil, err
+	}
+	imported := make(map[string]bool, len(importedIDs))
+	for _, id := range importedIDs {
+		imported[id] = true
+	}
+
+	resp := &domain.ImportTranscriptsResp{Warnings: warnings}
+	conversations := make([]*domain.Conversation, 0, historicalImportBatchSize)
+	messages := make([]*domain.ConversationMessage, 0)
+	flush := func() error {
+		if len(conversations) == 0 {
+			return nil
+		}
+		if err := u.repo.CreateHistoricalConversations(ctx, conversations, messages); err != nil {
+			return err
+		}
+		resp.ConversationCount += len(conversations)
+		resp.MessageCount += len(messages)
+		conversations, messages = conversations[:0], messages[:0]
+		return nil
+	}
+	for _, transcript := range transcripts {
+		if imported[transcript.ExternalID] {
+			resp.SkippedCount++
+			continue
+		}
+		imported[transcript.ExternalID] = true
+		conversation, conversationMessages := newHistoricalConversation(req, transcript)
+		if conversation == nil {
+			resp.Warnings = append(resp.Warnings, fmt.Sprintf("transcript %s has no customer message", transcript.ExternalID))
+			continue
+		}
+		conversations = append(conversations, conversation)
+		messages = append(messages, conversationMessages...)
+		if len(conversations) >= historicalImportBatchSize {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	u.logger.Info("import historical transcripts", log.String("kb_id", req.KBID), log.String("format", string(req.Format)),
+		log.Int("conversations", resp.ConversationCount), log.Int("skipped", resp.SkippedCount))
+	return resp, nil
+}
+
+// newHistoricalConversation nil if the transcript has no user message, there is no question to learn from
+func newHistoricalConversation(req *domain.ImportTranscriptsReq, transcript *domain.HistoricalTranscript) (*domain.Conversation, []*domain.ConversationMessage) {
+	subject := strings.TrimSpace(transcript.Subject)
+	hasQuestion := false
+	for _, message := range transcript.Messages {
+		if message.Role == schema.User {
+			hasQuestion = true
+			if subject == "" {
+				subject = message.Content
+			}
+			break
+		}
+	}
+	if !hasQuestion {
+		return nil, nil
+	}
+	createdAt := transcript.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = transcript.Messages[0].CreatedAt
+	}
+	conversation := &domain.Conversation{
+		ID:      uuid.New().String(),
+		Nonce:   uuid.New().String(),
+		KBID:    req.KBID,
+		Subject: subject,
+		Info: domain.ConversationInfo{
+			UserInfo: transcript.Requester,
+			Historical: &domain.HistoricalInfo{
+				Source:     req.Format,
+				ExternalID: transcript.ExternalID,
+				Status:     transcript.Status,
+				Resolved:   transcript.IsResolved(),
+			},
+		},
+		Historical: true,
+		CreatedAt:  createdAt,
+	}
+	messages := make([]*domain.ConversationMessage, 0, len(transcript.Messages))
+	for _, message := range transcript.Messages {
+		messages = append(messages, &domain.ConversationMessage{
+			ID:             uuid.New().String(),
+			ConversationID: conversation.ID,
+			Role:           message.Role,
+			Content:        message.Content,
+			Status:         domain.MessageStatusCompleted,
+			CreatedAt:      message.CreatedAt,
+			UpdatedAt:      message.CreatedAt,
+		})
+	}
+	return conversation, messages
+}
+
+var transcriptCSVColumns = map[string][]string{
+	"conversation": {"conversation_id", "ticket_id", "ticket", "id"},
+	"role":         {"role", "author_type", "sender_type", "author"},
+	"content":      {"content", "message", "body", "text"},
+	"created_at":   {"created_at", "timestamp", "time", "date"},
+	"subject":      {"subject", "title"},
+	"status":       {"status"},
+	"name":         {"requester_name", "name"},
+	"email":        {"requester_email", "email"},
+}
+
+// parseTranscriptCSV one message per row, rows of a conversation are grouped by its id and ordered by time
+func parseTranscriptCSV(r io.Reader) ([]*domain.HistoricalTranscript, []string, error) {
+	reader := csv.NewReader(bufio.NewReader(r))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("read csv header failed: %w", err)
+	}
+	names := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if _, ok := names[name]; !ok {
+			names[name] = i
+		}
+	}
+	// the first alias found in the header, in order of the aliases
+	columns := make(map[string]int)
+	for column, aliases := range transcriptCSVColumns {
+		for _, alias := range aliases {
+			if i, ok := names[alias]; ok {
+				columns[column] = i
+				break
+			}
+		}
+	}
+	for _, column := range []string{"conversation", "role", "content", "created_at"} {
+		if _, ok := columns[column]; !ok {
+			return nil, nil, fmt.Errorf("csv column %s is required", transcriptCSVColumns[column][0])
+		}
+	}
+	field := func(record []string, column string) string {
+		i, ok := columns[column]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var warnings []string
+	transcripts := make(map[string]*domain.HistoricalTranscript)
+	order := make([]string, 0)
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("read csv line %d failed: %w", line, err)
+		}
+		id := field(record, "conversation")
+		content := field(record, "content")
+		if id == "" || content == "" {
+			continue
+		}
+		role := domain.TranscriptRole(field(record, "role"))
+		if role == "" {
+			warnings = append(warnings, fmt.Sprintf("line %d: unknown role %q", line, field(record, "role")))
+			continue
+		}
+		createdAt, err := parseTranscriptTime(field(record, "created_at"))
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("line %d: %v", line, err))
+			continue
+		}
+		transcript, ok := transcripts[id]
+		if !ok {
+			transcript = &domain.HistoricalTranscript{ExternalID: id}
+			transcripts[id] = transcript
+			order = append(order, id)
+		}
+		if subject := field(record, "subject"); subject != "" && transcript.Subject == "" {
+			transcript.Subject = subject
+		}
+		if status := field(record, "status"); status != "" {
+			transcript.Status = status
+		}
+		if role == schema.User {
+			if name := field(record, "name"); name != "" && transcript.Requester.NickName == "" {
+				transcript.Requester.NickName = name
+			}
+			if email := field(record, "email"); email != "" && transcript.Requester.Email == "" {
+				transcript.Requester.Email = email
+			}
+		}
+		transcript.Messages = append(transcript.Messages, &domain.HistoricalMessage{Role: role, Content: content, CreatedAt: createdAt})
+	}
+	result := make([]*domain.HistoricalTranscript, 0, len(order))
+	for _, id := range order {
+		transcript := transcripts[id]
+		sort.SliceStable(transcript.Messages, func(i, j int) bool {
+			return transcript.Messages[i].CreatedAt.Before(transcript.Messages[j].CreatedAt)
+		})
+		transcript.CreatedAt = transcript.Messages[0].CreatedAt
+		result = append(result, transcript)
+	}
+	return result, warnings, nil
+}
+
+var transcriptTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006/01/02 15:04:05",
+	"2006/01/02 15:04",
+	"2006-01-02",
+	"2006/01/02",
+}
+
+// parseTranscriptTime helpdesk export time, times without zone are local, numbers are unix seconds or milliseconds
+func parseTranscriptTime(value string) (time.Time, error) {
+	if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if n > 1e12 {
+			return time.UnixMilli(n), nil
+		}
+		return time.Unix(n, 0), nil
+	}
+	for _, layout := range transcriptTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", value)
+}
+
+// zendeskTicket ticket of zendesk json exports, with comments side-loaded
+type zendeskTicket struct {
+	ID          any    `json:"id"`
+	Subject     string `json:"subject"`
+	Description string `json:"description"`
+	Status      string `json:"status"`
+	RequesterID any    `json:"requester_id"`
+	Requester   *struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	} `json:"requester"`
+	CreatedAt time.Time `json:"created_at"`
+	Comments  []struct {
+		AuthorID  any       `json:"author_id"`
+		Body      string    `json:"body"`
+		PlainBody string    `json:"plain_body"`
+		Public    *bool     `json:"public"`
+		CreatedAt time.Time `json:"created_at"`
+	} `json:"comments"`
+
+	// tickets api responses wrap tickets
+	Tickets []*zendeskTicket `json:"tickets"`
+}
+
+// parseZendeskExport tickets of a zendesk json export, one ticket per line, a json array or a tickets api response.
+// comments of the requester are user messages, other public comments are agent replies
+func parseZendeskExport(r io.Reader) ([]*domain.HistoricalTranscript, []string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	// ticket ids are kept as exported, not as floats
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var tickets []*zendeskTicket
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		if err := decoder.Decode(&tickets); err != nil {
+			return nil, nil, fmt.Errorf("invalid zendesk export: %w", err)
+		}
+	} else {
+		for {
+			ticket := &zendeskTicket{}
+			if err := decoder.Decode(ticket); errors.Is(err, io.EOF) {
+				break
+			} else if err != nil {
+				return nil, nil, fmt.Errorf("invalid zendesk export: %w", err)
+			}
+			if len(ticket.Tickets) > 0 {
+				tickets = append(tickets, ticket.Tickets...)
+			} else {
+				tickets = append(tickets, ticket)
+			}
+		}
+	}
+
+	var warnings []string
+	transcripts := make([]*domain.HistoricalTranscript, 0, len(tickets))
+	for _, ticket := range tickets {
+		if ticket.ID == nil {
+			warnings = append(warnings, "ticket without id is skipped")
+			continue
+		}
+		transcript := &domain.HistoricalTranscript{
+			ExternalID: fmt.Sprint(ticket.ID),
+			Subject:    strings.TrimSpace(ticket.Subject),
+			Status:     ticket.Status,
+			CreatedAt:  ticket.CreatedAt,
+		}
+		if ticket.Requester != nil {
+			transcript.Requester = domain.UserInfo{NickName: ticket.Requester.Name, Email: ticket.Requester.Email}
+		}
+		requesterID := fmt.Sprint(ticket.RequesterID)
+		for _, comment := range ticket.Comments {
+			if comment.Public != nil && !*comment.Public {
+				continue
+			}
+			content := strings.TrimSpace(comment.PlainBody)
+			if content == "" {
+				content = strings.TrimSpace(comment.Body)
+			}
+			if content == "" {
+				continue
+			}
+			role := schema.Assistant
+			if fmt.Sprint(comment.AuthorID) == requesterID {
+				role = schema.User
+			}
+			transcript.Messages = append(transcript.Messages, &domain.HistoricalMessage{Role: role, Content: content, CreatedAt: comment.CreatedAt})
+		}
+		// exports without comments still have the first message of the requester
+		if len(transcript.Messages) == 0 && strings.TrimSpace(ticket.Description) != "" {
+			transcript.Messages = append(transcript.Messages, &domain.HistoricalMessage{
+				Role:      schema.User,
+				Content:   strings.TrimSpace(ticket.Description),
+				CreatedAt: ticket.CreatedAt,
+			})
+		}
+		if len(transcript.Messages) == 0 {
+			warnings = append(warnings, fmt.Sprintf("ticket %s has no message", transcript.ExternalID))
+			continue
+		}
+		transcripts = append(transcripts, transcript)
+	}
+	return transcripts, warnings, nil
+}
@@ -0,0 +1,156 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/repo/mq"
+	"github.com/chaitin/panda-wiki/repo/pg"
+)
+
+type ConversationRescoreUsecase struct {
+	repo       *pg.ConversationRescoreRepository
+	mqRepo     *mq.ConversationRescoreRepository
+	kbRepo     *pg.KnowledgeBaseRepository
+	llmUsecase *LLMUsecase
+	logger     *log.Logger
+}
+
+func NewConversationRescoreUsecase(
+	repo *pg.ConversationRescoreRepository,
+	mqRepo *mq.ConversationRescoreRepository,
+	kbRepo *pg.KnowledgeBaseRepository,
+	llmUsecase *LLMUsecase,
+	logger *log.Logger,
+) *ConversationRescoreUsecase {
+	return &ConversationRescoreUsecase{
+		repo:       repo,
+		mqRepo:     mqRepo,
+		kbRepo:     kbRepo,
+		llmUsecase: llmUsecase,
+		logger:     logger.WithModule("usecase.conversation_rescore"),
+	}
+}
+
+// CreateJob create conversation rescore job and run it in the consumer
+func (u *ConversationRescoreUsecase) CreateJob(ctx context.Context, req *domain.ConversationRescoreReq, userID string) (*domain.ConversationRescoreJob, error) {
+	if _, err := u.kbRepo.GetKnowledgeBaseByID(ctx, req.KBID); err != nil {
+		return nil, err
+	}
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, err
+	}
+	days, limit := req.Days, req.Limit
+	if days == 0 {
+		days = domain.DefaultConversationRescoreDays
+	}
+	if limit == 0 {
+		limit = domain.DefaultConversationRescoreLimit
+	}
+	now := time.Now()
+	job := &domain.ConversationRescoreJob{
+		ID:             id.String(),
+		KBID:           req.KBID,
+		Days:           days,
+		Limit:          limit,
+		AnswerSettings: req.AnswerSettings,
+		CreatedBy:      userID,
+		Status:         domain.NodeExportJobStatusPending,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := u.repo.CreateRescoreJob(ctx, job); err != nil {
+		return nil, err
+	}
+	if err := u.mqRepo.AsyncRunRescoreJob(ctx, job.KBID, job.ID); err != nil {
+		u.failJob(ctx, job.ID, err)
+		return nil, err
+	}
+	return job, nil
+}
+
+// RunJob retrieve documents of recent questions again with the settings of the job and evaluate the confidence gate,
+// the answers are compared to how they were scored and rated, jobs not pending are skipped
+func (u *ConversationRescoreUsecase) RunJob(ctx context.Context, jobID string) error {
+	job, err := u.repo.GetRescoreJob(ctx, "", jobID)
+	if err != nil {
+		return err
+	}
+	if job.Status != domain.NodeExportJobStatusPending {
+		u.logger.Info("skip conversation rescore job", log.String("job_id", jobID), log.String("status", string(job.Status)))
+		return nil
+	}
+	if err := u.repo.UpdateRescoreJob(ctx, jobID, map[string]any{"status": domain.NodeExportJobStatusRunning}); err != nil {
+		return err
+	}
+	kb, err := u.kbRepo.GetKnowledgeBaseByID(ctx, job.KBID)
+	if err != nil {
+		u.failJob(ctx, jobID, fmt.Errorf("get kb failed: %w", err))
+		return err
+	}
+	// retrieval runs on a copy of the kb, the candidate settings are never saved
+	candidate := *kb
+	if job.AnswerSettings != nil {
+		candidate.AnswerSettings = *job.AnswerSettings
+	}
+	gate := candidate.AnswerSettings.ConfidenceGate
+	answers, err := u.repo.GetRescoreAnswers(ctx, job.KBID, time.Now().AddDate(0, 0, -job.Days), min(job.Limit, domain.MaxConversationRescoreLimit))
+	if err != nil {
+		u.failJob(ctx, jobID, fmt.Errorf("get answers failed: %w", err))
+		return err
+	}
+	result := &domain.ConversationRescoreResult{}
+	for _, answer := range answers {
+		if err := ctx.Err(); err != nil {
+			u.failJob(ctx, jobID, err)
+			return err
+		}
+		rankedNodes, err := u.llmUsecase.RetrieveNodes(ctx, &candidate, answer.Question)
+		if err != nil {
+			u.logger.Warn("retrieve documents of question failed", log.String("job_id", jobID), log.String("message_id", answer.MessageID), log.Error(err))
+			result.FailedCount++
+			continue
+		}
+		confidence := gate.Evaluate(answer.Question, rankedNodes)
+		nodeIDs := lo.Map(rankedNodes, func(node *domain.RankedNodeChunks, _ int) string {
+			return node.NodeID
+		})
+		result.Add(answer, confidence, gate.Enabled && !confidence.Confident, nodeIDs)
+	}
+	result.Finish()
+	u.logger.Info("conversation rescore job succeeded", log.String("job_id", jobID), log.Int("answer_count", result.AnswerCount),
+		log.Int("newly_gated", result.NewlyGated), log.Int("newly_answered", result.NewlyAnswered))
+	return u.repo.UpdateRescoreJob(ctx, jobID, map[string]any{
+		"status": domain.NodeExportJobStatusSucceeded,
+		"result": result,
+	})
+}
+
+func (u *ConversationRescoreUsecase) failJob(ctx context.Context, jobID string, jobErr error) {
+	u.logger.Error("conversation rescore job failed", log.String("job_id", jobID), log.Error(jobErr))
+	if err := u.repo.UpdateRescoreJob(ctx, jobID, map[string]any{
+		"status": domain.NodeExportJobStatusFailed,
+		"error":  jobErr.Error(),
+	}); err != nil {
+		u.logger.Error("update conversation rescore job failed", log.String("job_id", jobID), log.Error(err))
+	}
+}
+
+func (u *ConversationRescoreUsecase) GetJob(ctx context.Context, req *domain.ConversationRescoreJobReq) (*domain.ConversationRescoreJob, error) {
+	return u.repo.GetRescoreJob(ctx, req.KBID, req.JobID)
+}
+
+func (u *ConversationRescoreUsecase) GetJobList(ctx context.Context, req *domain.ConversationRescoreJobListReq) (*domain.PaginatedResult[[]*domain.ConversationRescoreJob], error) {
+	jobs, total, err := u.repo.GetRescoreJobList(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewPaginatedResult(jobs, total), nil
+}
@@ -29,6 +29,10 @@ func (u *CreationUsecase) TextCreation(ctx context.Context, req *domain.TextReq,
 		u.logger.Error("get chat model failed", log.Error(err))
 		return domain.ErrModelNotConfigured
 	}
+	model, _, err = u.model.ResolveChatModel(ctx, model)
+	if err != nil {
+		return err
+	}
 	chatModel, err := u.llm.GetChatModel(ctx, model)
 	if err != nil {
 		return fmt.Errorf("get chat model failed: %w", err)
@@ -62,9 +66,12 @@ func (u *CreationUsecase) TextCreation(ctx context.Context, req *domain.TextReq,
 		},
 	}
 	usage := &schema.TokenUsage{}
-	err = u.llm.ChatWithAgent(ctx, chatModel, messages, usage, onChunk)
+	err = u.llm.ChatWithAgent(ctx, chatModel, model.Model, messages, usage, onChunk)
 	if err != nil {
 		return fmt.Errorf("chat with llm failed: %w", err)
 	}
+	if err := u.model.UpdateUsage(ctx, model.ID, usage); err != nil {
+		u.logger.Error("update model usage failed", log.Error(err))
+	}
 	return nil
 }
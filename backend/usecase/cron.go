@@ -0,0 +1,133 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/chaitin/panda-wiki/config"
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/pkg/bot/dingtalk"
+	"github.com/chaitin/panda-wiki/pkg/bot/feishu"
+	"github.com/chaitin/panda-wiki/repo/pg"
+)
+
+type CronUsecase struct {
+	repo   *pg.CronRepository
+	config *config.Config
+	logger *log.Logger
+}
+
+func NewCronUsecase(repo *pg.CronRepository, config *config.Config, logger *log.Logger) *CronUsecase {
+	return &CronUsecase{
+		repo:   repo,
+		config: config,
+		logger: logger.WithModule("usecase.cron"),
+	}
+}
+
+// Run execute the job and record the run, alert when the job fails repeatedly
+func (u *CronUsecase) Run(job string, fn func(ctx context.Context) error) {
+	u.RunWithResult(job, func(ctx context.Context) (domain.CronRunResult, error) {
+		return nil, fn(ctx)
+	})
+}
+
+// RunWithResult same as Run, the result returned by the job is recorded with the run
+func (u *CronUsecase) RunWithResult(job string, fn func(ctx context.Context) (domain.CronRunResult, error)) {
+	ctx := context.Background()
+	run := &domain.CronRun{
+		Job:       job,
+		Status:    domain.CronRunStatusSuccess,
+		StartedAt: time.Now(),
+	}
+	result, err := fn(ctx)
+	run.Result = result
+	run.DurationMS = time.Since(run.StartedAt).Milliseconds()
+	if err != nil {
+		run.Status = domain.CronRunStatusFailed
+		run.Error = err.Error()
+	}
+	if err := u.repo.CreateCronRun(ctx, run); err != nil {
+		u.logger.Warn("failed to record cron run", log.String("job", job), log.Error(err))
+		return
+	}
+	if run.Status == domain.CronRunStatusFailed {
+		u.checkFailures(ctx, job, run)
+	}
+}
+
+// checkFailures alert once when consecutive failures of the job reach the threshold
+func (u *CronUsecase) checkFailures(ctx context.Context, job string, run *domain.CronRun) {
+	threshold := u.config.Cron.AlertAfterFailures
+	if threshold <= 0 {
+		return
+	}
+	runs, err := u.repo.GetRecentCronRuns(ctx, job, threshold+1)
+	if err != nil {
+		u.logger.Warn("failed to get recent cron runs", log.String("job", job), log.Error(err))
+		return
+	}
+	if consecutiveFailures(runs) != threshold {
+		return
+	}
+	u.logger.Error("cron job failed repeatedly", log.String("job", job), log.Int("failures", threshold), log.String("error", run.Error))
+	webhook := u.config.Cron.AlertWebhook
+	if webhook.URL == "" {
+		return
+	}
+	title := "定时任务失败告警"
+	text := fmt.Sprintf("定时任务 %s 已连续失败 %d 次\n\n- 最近执行：%s\n- 错误：%s\n",
+		job, threshold, run.StartedAt.Format(time.DateTime), run.Error)
+	switch domain.NotifyWebhookType(webhook.Type) {
+	case domain.NotifyWebhookTypeFeishu:
+		err = feishu.SendWebhookMarkdown(ctx, webhook.URL, webhook.Secret, title, text)
+	default:
+		err = dingtalk.SendWebhookMarkdown(ctx, webhook.URL, webhook.Secret, title, "### "+title+"\n\n"+text)
+	}
+	if err != nil {
+		u.logger.Error("send cron alert failed", log.String("job", job), log.Error(err))
+	}
+}
+
+func (u *CronUsecase) GetCronRunList(ctx context.Context, req *domain.CronRunListReq) (*domain.PaginatedResult[[]*domain.CronRun], error) {
+	runs, total, err := u.repo.GetCronRunList(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewPaginatedResult(runs, total), nil
+}
+
+// GetCronJobStatus last run and consecutive failures of each job ever run
+func (u *CronUsecase) GetCronJobStatus(ctx context.Context) ([]*domain.CronJobStatus, error) {
+	jobs, err := u.repo.GetCronJobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]*domain.CronJobStatus, 0, len(jobs))
+	for _, job := range jobs {
+		runs, err := u.repo.GetRecentCronRuns(ctx, job, 100)
+		if err != nil {
+			return nil, err
+		}
+		status := &domain.CronJobStatus{Job: job, ConsecutiveFailures: consecutiveFailures(runs)}
+		if len(runs) > 0 {
+			status.LastRun = runs[0]
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// consecutiveFailures count failed runs before the latest success, runs are newest first
+func consecutiveFailures(runs []*domain.CronRun) int {
+	count := 0
+	for _, run := range runs {
+		if run.Status != domain.CronRunStatusFailed {
+			break
+		}
+		count++
+	}
+	return count
+}
@@ -0,0 +1,218 @@
+package usecase
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/repo/mq"
+	"github.com/chaitin/panda-wiki/repo/pg"
+	"github.com/chaitin/panda-wiki/store/s3"
+)
+
+var ErrDataExportEndUserRequired = errors.New("user_id, email or remote_ip of the end user is required")
+
+type DataExportUsecase struct {
+	repo    *pg.DataExportRepository
+	mqRepo  *mq.DataExportRepository
+	storage s3.ObjectStorage
+	logger  *log.Logger
+}
+
+func NewDataExportUsecase(
+	repo *pg.DataExportRepository,
+	mqRepo *mq.DataExportRepository,
+	storage s3.ObjectStorage,
+	logger *log.Logger,
+) *DataExportUsecase {
+	return &DataExportUsecase{
+		repo:    repo,
+		mqRepo:  mqRepo,
+		storage: storage,
+		logger:  logger.WithModule("usecase.data_export"),
+	}
+}
+
+// CreateJob create data export job and run it in the consumer
+func (u *DataExportUsecase) CreateJob(ctx context.Context, req *domain.DataExportReq, userID string) (*domain.DataExportJob, error) {
+	if req.Scope == domain.DataExportScopeEndUser && req.UserID == "" && req.Email == "" && req.RemoteIP == "" {
+		return nil, ErrDataExportEndUserRequired
+	}
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	job := &domain.DataExportJob{
+		ID:        id.String(),
+		KBID:      req.KBID,
+		Scope:     req.Scope,
+		CreatedBy: userID,
+		Status:    domain.NodeExportJobStatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if req.Scope == domain.DataExportScopeEndUser {
+		job.UserID = req.UserID
+		job.Email = req.Email
+		job.RemoteIP = req.RemoteIP
+	}
+	if err := u.repo.CreateExportJob(ctx, job); err != nil {
+		return nil, err
+	}
+	if err := u.mqRepo.AsyncRunExportJob(ctx, job.KBID, job.ID); err != nil {
+		u.failJob(ctx, job.ID, err)
+		return nil, err
+	}
+	return job, nil
+}
+
+// RunJob write rows in scope of the job to a zip archive of json lines files in object storage, jobs not pending are skipped
+func (u *DataExportUsecase) RunJob(ctx context.Context, jobID string) error {
+	job, err := u.repo.GetExportJob(ctx, "", jobID)
+	if err != nil {
+		return err
+	}
+	if job.Status != domain.NodeExportJobStatusPending {
+		u.logger.Info("skip data export job", log.String("job_id", jobID), log.String("status", string(job.Status)))
+		return nil
+	}
+	if err := u.repo.UpdateExportJob(ctx, jobID, map[string]any{"status": domain.NodeExportJobStatusRunning}); err != nil {
+		return err
+	}
+	// the archive is spooled to disk, object storage needs its size up front
+	file, err := os.CreateTemp("", "panda-wiki-data-export-*.zip")
+	if err != nil {
+		u.failJob(ctx, jobID, err)
+		return err
+	}
+	defer func() {
+		file.Close()
+		os.Remove(file.Name())
+	}()
+	recordCount, err := u.writeArchive(ctx, job, file)
+	if err != nil {
+		u.failJob(ctx, jobID, err)
+		return err
+	}
+	size, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		u.failJob(ctx, jobID, err)
+		return err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		u.failJob(ctx, jobID, err)
+		return err
+	}
+	key := fmt.Sprintf("data-export/%s/%s.zip", job.KBID, job.ID)
+	if err := u.storage.PutObject(ctx, key, file, size, "application/zip"); err != nil {
+		u.failJob(ctx, jobID, fmt.Errorf("upload archive failed: %w", err))
+		return err
+	}
+	u.logger.Info("data export job succeeded", log.String("job_id", jobID), log.String("scope", string(job.Scope)), log.Int("record_count", recordCount))
+	return u.repo.UpdateExportJob(ctx, jobID, map[string]any{
+		"status":       domain.NodeExportJobStatusSucceeded,
+		"record_count": recordCount,
+		"size":         size,
+		"key":          key,
+	})
+}
+
+// writeArchive write each exported table as a json lines file, with a manifest of the job and row counts
+func (u *DataExportUsecase) writeArchive(ctx context.Context, job *domain.DataExportJob, w io.Writer) (int, error) {
+	archive := zip.NewWriter(w)
+	now := time.Now()
+	manifest := &domain.DataExportManifestContent{
+		JobID:       job.ID,
+		KBID:        job.KBID,
+		Scope:       job.Scope,
+		UserID:      job.UserID,
+		Email:       job.Email,
+		RemoteIP:    job.RemoteIP,
+		GeneratedAt: now,
+		Tables:      make(map[string]int),
+	}
+	recordCount := 0
+	for _, table := range u.repo.GetExportTables(job.Scope) {
+		f, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     domain.DataExportTableFile(table),
+			Method:   zip.Deflate,
+			Modified: now,
+		})
+		if err != nil {
+			return 0, err
+		}
+		count, err := u.repo.TraverseExportRows(ctx, job, table, func(row json.RawMessage) error {
+			if _, err := f.Write(row); err != nil {
+				return err
+			}
+			_, err := io.WriteString(f, "\n")
+			return err
+		})
+		if err != nil {
+			return 0, fmt.Errorf("export table %s failed: %w", table, err)
+		}
+		manifest.Tables[table] = count
+		recordCount += count
+	}
+	f, err := archive.CreateHeader(&zip.FileHeader{
+		Name:     domain.DataExportManifest,
+		Method:   zip.Deflate,
+		Modified: now,
+	})
+	if err != nil {
+		return 0, err
+	}
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(manifest); err != nil {
+		return 0, err
+	}
+	if err := archive.Close(); err != nil {
+		return 0, err
+	}
+	return recordCount, nil
+}
+
+func (u *DataExportUsecase) failJob(ctx context.Context, jobID string, jobErr error) {
+	u.logger.Error("data export job failed", log.String("job_id", jobID), log.Error(jobErr))
+	if err := u.repo.UpdateExportJob(ctx, jobID, map[string]any{
+		"status": domain.NodeExportJobStatusFailed,
+		"error":  jobErr.Error(),
+	}); err != nil {
+		u.logger.Error("update data export job failed", log.String("job_id", jobID), log.Error(err))
+	}
+}
+
+// GetJob status of data export job, with download url of the archive when succeeded
+func (u *DataExportUsecase) GetJob(ctx context.Context, req *domain.DataExportJobReq) (*domain.DataExportJob, error) {
+	job, err := u.repo.GetExportJob(ctx, req.KBID, req.JobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status == domain.NodeExportJobStatusSucceeded && job.Key != "" {
+		url, err := u.storage.SignURL(ctx, job.Key, job.ArchiveName(), domain.AttachmentURLExpires)
+		if err != nil {
+			return nil, fmt.Errorf("sign archive url failed: %w", err)
+		}
+		job.URL = url
+	}
+	return job, nil
+}
+
+func (u *DataExportUsecase) GetJobList(ctx context.Context, req *domain.DataExportJobListReq) (*domain.PaginatedResult[[]*domain.DataExportJob], error) {
+	jobs, total, err := u.repo.GetExportJobList(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewPaginatedResult(jobs, total), nil
+}
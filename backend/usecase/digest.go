@@ -0,0 +1,271 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/samber/lo"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/pkg/bot/dingtalk"
+	"github.com/chaitin/panda-wiki/pkg/bot/feishu"
+	"github.com/chaitin/panda-wiki/repo/pg"
+)
+
+type DigestUsecase struct {
+	kbRepo           *pg.KnowledgeBaseRepository
+	conversationRepo *pg.ConversationRepository
+	nodeUsecase      *NodeUsecase
+	logger           *log.Logger
+}
+
+func NewDigestUsecase(kbRepo *pg.KnowledgeBaseRepository, conversationRepo *pg.ConversationRepository, nodeUsecase *NodeUsecase, logger *log.Logger) *DigestUsecase {
+	return &DigestUsecase{
+		kbRepo:           kbRepo,
+		conversationRepo: conversationRepo,
+		nodeUsecase:      nodeUsecase,
+		logger:           logger.WithModule("usecase.digest"),
+	}
+}
+
+// GetDailyDigest digest of conversations of the kb on the day of date
+func (u *DigestUsecase) GetDailyDigest(ctx context.Context, kbID string, date time.Time) (*domain.DailyDigest, error) {
+	kb, err := u.kbRepo.GetKnowledgeBaseByID(ctx, kbID)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	end := start.AddDate(0, 0, 1)
+	digest := &domain.DailyDigest{
+		KBID:   kbID,
+		KBName: kb.Name,
+		Date:   start,
+	}
+	volume, err := u.conversationRepo.GetConversationVolume(ctx, kbID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("get conversation volume failed: %w", err)
+	}
+	digest.Volume = *volume
+	prevVolume, err := u.conversationRepo.GetConversationVolume(ctx, kbID, start.AddDate(0, 0, -1), start)
+	if err != nil {
+		return nil, fmt.Errorf("get conversation volume failed: %w", err)
+	}
+	digest.PrevVolume = *prevVolume
+	if digest.Escalations, err = u.conversationRepo.GetEscalatedConversations(ctx, kbID, start, end, domain.DigestItemLimit); err != nil {
+		return nil, fmt.Errorf("get escalated conversations failed: %w", err)
+	}
+	if digest.NewQuestions, err = u.conversationRepo.GetNewQuestions(ctx, kbID, start.Add(-domain.DigestNewQuestionLookback), start, end, domain.DigestItemLimit); err != nil {
+		return nil, fmt.Errorf("get new questions failed: %w", err)
+	}
+	// escalations are the most worth reading, then the longest conversations
+	transcriptIDs := lo.Map(digest.Escalations, func(e *domain.DigestEscalation, _ int) string { return e.ConversationID })
+	busiest, err := u.conversationRepo.GetBusiestConversationIDs(ctx, kbID, start, end, domain.DigestTranscriptLimit)
+	if err != nil {
+		return nil, fmt.Errorf("get busiest conversations failed: %w", err)
+	}
+	transcriptIDs = lo.Uniq(append(transcriptIDs, busiest...))
+	if len(transcriptIDs) > domain.DigestTranscriptLimit {
+		transcriptIDs = transcriptIDs[:domain.DigestTranscriptLimit]
+	}
+	for _, id := range transcriptIDs {
+		transcript, err := u.getTranscript(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		digest.Transcripts = append(digest.Transcripts, transcript)
+	}
+	return digest, nil
+}
+
+func (u *DigestUsecase) getTranscript(ctx context.Context, conversationID string) (*domain.DigestTranscript, error) {
+	conversation, err := u.conversationRepo.GetConversationDetail(ctx, conversationID)
+	if err != nil {
+		return nil, fmt.Errorf("get conversation %s failed: %w", conversationID, err)
+	}
+	messages, err := u.conversationRepo.GetConversationMessagesByID(ctx, conversationID)
+	if err != nil {
+		return nil, fmt.Errorf("get conversation messages %s failed: %w", conversationID, err)
+	}
+	transcript := &domain.DigestTranscript{
+		ConversationID: conversationID,
+		Subject:        conversation.Subject,
+	}
+	for _, message := range messages {
+		content := strings.TrimSpace(thinkBlockRegex.ReplaceAllString(message.Content, ""))
+		if r := []rune(content); len(r) > domain.DigestTranscriptMessageLength {
+			content = string(r[:domain.DigestTranscriptMessageLength]) + "..."
+		}
+		transcript.Messages = append(transcript.Messages, domain.DigestMessage{Role: message.Role, Content: content})
+	}
+	return transcript, nil
+}
+
+// PreviewDailyDigest digest and its markdown, without saving or pushing it
+func (u *DigestUsecase) PreviewDailyDigest(ctx context.Context, req *domain.DailyDigestReq) (*domain.DailyDigestResp, error) {
+	date, err := parseDigestDate(req.Date)
+	if err != nil {
+		return nil, err
+	}
+	kb, err := u.kbRepo.GetKnowledgeBaseByID(ctx, req.KBID)
+	if err != nil {
+		return nil, err
+	}
+	digest, err := u.GetDailyDigest(ctx, req.KBID, date)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.DailyDigestResp{
+		Digest:   digest,
+		Markdown: renderDailyDigest(digest, strings.TrimRight(kb.DigestSettings.ConsoleURL, "/")),
+	}, nil
+}
+
+// SendDailyDigestNow send digest of the day as configured, regardless of whether the daily job is enabled
+func (u *DigestUsecase) SendDailyDigestNow(ctx context.Context, req *domain.DailyDigestReq) error {
+	date, err := parseDigestDate(req.Date)
+	if err != nil {
+		return err
+	}
+	kb, err := u.kbRepo.GetKnowledgeBaseByID(ctx, req.KBID)
+	if err != nil {
+		return err
+	}
+	if !kb.DigestSettings.CreateNode && kb.DigestSettings.Webhook.URL == "" {
+		return fmt.Errorf("digest is not configured")
+	}
+	return u.SendDailyDigest(ctx, kb, date)
+}
+
+func parseDigestDate(date string) (time.Time, error) {
+	if date == "" {
+		return time.Now().AddDate(0, 0, -1), nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", date, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %s: %w", date, err)
+	}
+	return t, nil
+}
+
+// SendDailyDigests save and push yesterday's digest of all kbs with digest enabled
+func (u *DigestUsecase) SendDailyDigests(ctx context.Context) error {
+	kbs, err := u.kbRepo.GetKnowledgeBaseList(ctx)
+	if err != nil {
+		return err
+	}
+	yesterday := time.Now().AddDate(0, 0, -1)
+	failed := 0
+	for _, item := range kbs {
+		kb, err := u.kbRepo.GetKnowledgeBaseByID(ctx, item.ID)
+		if err != nil {
+			return err
+		}
+		if !kb.DigestSettings.Enabled {
+			continue
+		}
+		if err := u.SendDailyDigest(ctx, kb, yesterday); err != nil {
+			u.logger.Error("send daily digest failed", log.String("kb_id", kb.ID), log.Error(err))
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("send daily digest of %d kbs failed", failed)
+	}
+	return nil
+}
+
+// SendDailyDigest save digest as a node and push it to the group webhook, as configured in kb digest settings
+func (u *DigestUsecase) SendDailyDigest(ctx context.Context, kb *domain.KnowledgeBase, date time.Time) error {
+	settings := kb.DigestSettings
+	digest, err := u.GetDailyDigest(ctx, kb.ID, date)
+	if err != nil {
+		return err
+	}
+	title := fmt.Sprintf("【%s】问答日报 %s", digest.KBName, digest.Date.Format("2006-01-02"))
+	text := renderDailyDigest(digest, strings.TrimRight(settings.ConsoleURL, "/"))
+	if settings.CreateNode {
+		// created like any node so defaults of the folder apply, the digest itself stays private
+		visibility := domain.NodeVisibilityPrivate
+		if _, err := u.nodeUsecase.Create(ctx, &domain.CreateNodeReq{
+			KBID:       kb.ID,
+			ParentID:   settings.ParentID,
+			Type:       domain.NodeTypeDocument,
+			Name:       title,
+			Content:    text,
+			Visibility: &visibility,
+		}); err != nil {
+			return fmt.Errorf("create digest node failed: %w", err)
+		}
+	}
+	webhook := settings.Webhook
+	if webhook.URL == "" {
+		return nil
+	}
+	switch webhook.Type {
+	case domain.NotifyWebhookTypeFeishu:
+		return feishu.SendWebhookMarkdown(ctx, webhook.URL, webhook.Secret, title, text)
+	default:
+		return dingtalk.SendWebhookMarkdown(ctx, webhook.URL, webhook.Secret, title, "### "+title+"\n\n"+text)
+	}
+}
+
+func renderDailyDigest(digest *domain.DailyDigest, consoleURL string) string {
+	var sb strings.Builder
+	sb.WriteString("**问答量**\n\n")
+	fmt.Fprintf(&sb, "- 会话数：%d%s\n", digest.Volume.ConversationCount, digestTrend(digest.Volume.ConversationCount, digest.PrevVolume.ConversationCount))
+	fmt.Fprintf(&sb, "- 消息数：%d%s\n", digest.Volume.MessageCount, digestTrend(digest.Volume.MessageCount, digest.PrevVolume.MessageCount))
+	fmt.Fprintf(&sb, "- 访客数：%d%s\n\n", digest.Volume.VisitorCount, digestTrend(digest.Volume.VisitorCount, digest.PrevVolume.VisitorCount))
+
+	sb.WriteString("**需要关注的会话**\n\n")
+	if len(digest.Escalations) == 0 {
+		sb.WriteString("无\n")
+	}
+	for i, e := range digest.Escalations {
+		fmt.Fprintf(&sb, "%d. %s（未回答 %d 次，低置信度 %d 次）\n", i+1, strings.TrimSpace(e.Subject), e.NoAnswerCount, e.LowConfidence)
+	}
+	if len(digest.Escalations) > 0 && consoleURL != "" {
+		fmt.Fprintf(&sb, "\n[查看问答记录](%s/conversation)\n", consoleURL)
+	}
+	sb.WriteString("\n")
+
+	sb.WriteString("**新出现的问题**\n\n")
+	if len(digest.NewQuestions) == 0 {
+		sb.WriteString("无\n")
+	}
+	for i, q := range digest.NewQuestions {
+		fmt.Fprintf(&sb, "%d. %s（%d 次）\n", i+1, strings.TrimSpace(q.Question), q.Count)
+	}
+	sb.WriteString("\n")
+
+	sb.WriteString("**会话示例**\n\n")
+	if len(digest.Transcripts) == 0 {
+		sb.WriteString("无\n")
+	}
+	for _, t := range digest.Transcripts {
+		fmt.Fprintf(&sb, "*%s*\n\n", strings.TrimSpace(t.Subject))
+		for _, m := range t.Messages {
+			role := "用户"
+			if m.Role == schema.Assistant {
+				role = "AI"
+			}
+			fmt.Fprintf(&sb, "> **%s**：%s\n>\n", role, strings.ReplaceAll(m.Content, "\n", " "))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+func digestTrend(cur, prev int64) string {
+	switch {
+	case prev == 0:
+		return ""
+	case cur >= prev:
+		return fmt.Sprintf("（较前一日 +%.0f%%）", float64(cur-prev)*100/float64(prev))
+	default:
+		return fmt.Sprintf("（较前一日 -%.0f%%）", float64(prev-cur)*100/float64(prev))
+	}
+}
@@ -0,0 +1,399 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/repo/mq"
+	"github.com/chaitin/panda-wiki/repo/pg"
+)
+
+type EvalUsecase struct {
+	repo               *pg.EvalRepository
+	mqRepo             *mq.EvalRepository
+	kbRepo             *pg.KnowledgeBaseRepository
+	appRepo            *pg.AppRepository
+	promptTemplateRepo *pg.PromptTemplateRepository
+	modelRepo          *pg.ModelRepository
+	llmUsecase         *LLMUsecase
+	logger             *log.Logger
+}
+
+func NewEvalUsecase(
+	repo *pg.EvalRepository,
+	mqRepo *mq.EvalRepository,
+	kbRepo *pg.KnowledgeBaseRepository,
+	appRepo *pg.AppRepository,
+	promptTemplateRepo *pg.PromptTemplateRepository,
+	modelRepo *pg.ModelRepository,
+	llmUsecase *LLMUsecase,
+	logger *log.Logger,
+) *EvalUsecase {
+	return &EvalUsecase{
+		repo:               repo,
+		mqRepo:             mqRepo,
+		kbRepo:             kbRepo,
+		appRepo:            appRepo,
+		promptTemplateRepo: promptTemplateRepo,
+		modelRepo:          modelRepo,
+		llmUsecase:         llmUsecase,
+		logger:             logger.WithModule("usecase.eval"),
+	}
+}
+
+// GetEvalSetList sets of the kb with the summary of their latest succeeded run
+func (u *EvalUsecase) GetEvalSetList(ctx context.Context, req *domain.EvalSetListReq) ([]*domain.EvalSetListItem, error) {
+	sets, err := u.repo.GetEvalSetList(ctx, req.KBID)
+	if err != nil {
+		return nil, err
+	}
+	runs, err := u.repo.GetLatestEvalRuns(ctx, req.KBID)
+	if err != nil {
+		return nil, err
+	}
+	lastRuns := lo.KeyBy(runs, func(run *domain.EvalRun) string {
+		return run.SetID
+	})
+	for _, set := range sets {
+		set.LastRun = lastRuns[set.ID]
+	}
+	return sets, nil
+}
+
+func (u *EvalUsecase) CreateEvalSet(ctx context.Context, req *domain.CreateEvalSetReq) (*domain.EvalSet, error) {
+	if _, err := u.kbRepo.GetKnowledgeBaseByID(ctx, req.KBID); err != nil {
+		return nil, err
+	}
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	set := &domain.EvalSet{
+		ID:          id.String(),
+		KBID:        req.KBID,
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := u.repo.CreateEvalSet(ctx, set); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+func (u *EvalUsecase) UpdateEvalSet(ctx context.Context, req *domain.UpdateEvalSetReq) error {
+	return u.repo.UpdateEvalSet(ctx, &domain.EvalSet{
+		ID:          req.ID,
+		KBID:        req.KBID,
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		UpdatedAt:   time.Now(),
+	})
+}
+
+func (u *EvalUsecase) DeleteEvalSet(ctx context.Context, req *domain.DeleteEvalSetReq) error {
+	return u.repo.DeleteEvalSet(ctx, req.KBID, req.ID)
+}
+
+func (u *EvalUsecase) GetEvalQuestions(ctx context.Context, req *domain.EvalQuestionListReq) ([]*domain.EvalQuestion, error) {
+	if _, err := u.repo.GetEvalSet(ctx, req.KBID, req.SetID); err != nil {
+		return nil, err
+	}
+	return u.repo.GetEvalQuestions(ctx, req.SetID)
+}
+
+// CreateEvalQuestions add questions to the set, up to MaxEvalSetQuestions in total
+func (u *EvalUsecase) CreateEvalQuestions(ctx context.Context, req *domain.CreateEvalQuestionsReq) ([]*domain.EvalQuestion, error) {
+	if _, err := u.repo.GetEvalSet(ctx, req.KBID, req.SetID); err != nil {
+		return nil, err
+	}
+	count, err := u.repo.CountEvalQuestions(ctx, req.SetID)
+	if err != nil {
+		return nil, err
+	}
+	if int(count)+len(req.Questions) > domain.MaxEvalSetQuestions {
+		return nil, domain.ErrEvalSetQuestionsLimit
+	}
+	questions := make([]*domain.EvalQuestion, 0, len(req.Questions))
+	now := time.Now()
+	for i, input := range req.Questions {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return nil, err
+		}
+		// keep the order of the batch, questions are listed and run by created_at
+		createdAt := now.Add(time.Duration(i) * time.Microsecond)
+		questions = append(questions, &domain.EvalQuestion{
+			ID:             id.String(),
+			KBID:           req.KBID,
+			SetID:          req.SetID,
+			Question:       strings.TrimSpace(input.Question),
+			ExpectedAnswer: strings.TrimSpace(input.ExpectedAnswer),
+			CreatedAt:      createdAt,
+			UpdatedAt:      createdAt,
+		})
+	}
+	if err := u.repo.CreateEvalQuestions(ctx, questions); err != nil {
+		return nil, err
+	}
+	return questions, nil
+}
+
+func (u *EvalUsecase) UpdateEvalQuestion(ctx context.Context, req *domain.UpdateEvalQuestionReq) error {
+	return u.repo.UpdateEvalQuestion(ctx, &domain.EvalQuestion{
+		ID:             req.ID,
+		KBID:           req.KBID,
+		Question:       strings.TrimSpace(req.Question),
+		ExpectedAnswer: strings.TrimSpace(req.ExpectedAnswer),
+		UpdatedAt:      time.Now(),
+	})
+}
+
+func (u *EvalUsecase) DeleteEvalQuestion(ctx context.Context, req *domain.DeleteEvalQuestionReq) error {
+	return u.repo.DeleteEvalQuestion(ctx, req.KBID, req.ID)
+}
+
+// CreateEvalRun create eval run of the set and run it in the consumer
+func (u *EvalUsecase) CreateEvalRun(ctx context.Context, req *domain.CreateEvalRunReq, userID string) (*domain.EvalRun, error) {
+	if _, err := u.repo.GetEvalSet(ctx, req.KBID, req.SetID); err != nil {
+		return nil, err
+	}
+	count, err := u.repo.CountEvalQuestions(ctx, req.SetID)
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, domain.ErrEvalSetEmpty
+	}
+	if req.AppID != "" {
+		app, err := u.appRepo.GetAppDetail(ctx, req.AppID)
+		if err != nil {
+			return nil, err
+		}
+		if app.KBID != req.KBID {
+			return nil, gorm.ErrRecordNotFound
+		}
+	}
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	run := &domain.EvalRun{
+		ID:        id.String(),
+		KBID:      req.KBID,
+		SetID:     req.SetID,
+		AppID:     req.AppID,
+		Note:      req.Note,
+		CreatedBy: userID,
+		Status:    domain.NodeExportJobStatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := u.repo.CreateEvalRun(ctx, run); err != nil {
+		return nil, err
+	}
+	if err := u.mqRepo.AsyncRunEval(ctx, run.KBID, run.ID); err != nil {
+		u.failRun(ctx, run.ID, err)
+		return nil, err
+	}
+	return run, nil
+}
+
+// RunEval answer every question of the set with the chat model of the kb and the prompt of the run, and score the
+// answers with the same model as judge. runs not pending are skipped
+func (u *EvalUsecase) RunEval(ctx context.Context, runID string) error {
+	run, err := u.repo.GetEvalRun(ctx, "", runID)
+	if err != nil {
+		return err
+	}
+	if run.Status != domain.NodeExportJobStatusPending {
+		u.logger.Info("skip eval run", log.String("run_id", runID), log.String("status", string(run.Status)))
+		return nil
+	}
+	kb, err := u.kbRepo.GetKnowledgeBaseByID(ctx, run.KBID)
+	if err != nil {
+		u.failRun(ctx, runID, fmt.Errorf("get kb failed: %w", err))
+		return err
+	}
+	// the primary chat model of the kb, failover models are not tried so runs stay comparable
+	model, err := u.modelRepo.GetKBModel(ctx, kb.ID, domain.ModelTypeChat)
+	if err != nil {
+		u.failRun(ctx, runID, fmt.Errorf("get chat model failed: %w", err))
+		return err
+	}
+	systemPrompt, version, err := u.systemPrompt(ctx, run, kb)
+	if err != nil {
+		u.failRun(ctx, runID, fmt.Errorf("get prompt failed: %w", err))
+		return err
+	}
+	if err := u.repo.UpdateEvalRun(ctx, runID, map[string]any{
+		"status":                  domain.NodeExportJobStatusRunning,
+		"provider":                model.Provider,
+		"model":                   model.Model,
+		"model_id":                model.ID,
+		"prompt_template_version": version,
+		"judge_model":             model.Model,
+	}); err != nil {
+		return err
+	}
+	questions, err := u.repo.GetEvalQuestions(ctx, run.SetID)
+	if err != nil {
+		u.failRun(ctx, runID, fmt.Errorf("get questions failed: %w", err))
+		return err
+	}
+	summary := &domain.EvalRunSummary{}
+	for _, question := range questions {
+		if err := ctx.Err(); err != nil {
+			u.failRun(ctx, runID, err)
+			return err
+		}
+		result := u.evalQuestion(ctx, run, kb, model, systemPrompt, question)
+		if err := u.repo.CreateEvalResult(ctx, result); err != nil {
+			u.failRun(ctx, runID, fmt.Errorf("save result failed: %w", err))
+			return err
+		}
+		summary.Add(result)
+	}
+	summary.Finish()
+	u.logger.Info("eval run succeeded", log.String("run_id", runID), log.Int("question_count", summary.QuestionCount),
+		log.Int("failed_count", summary.FailedCount), log.Int("pass_count", summary.PassCount))
+	return u.repo.UpdateEvalRun(ctx, runID, map[string]any{
+		"status":  domain.NodeExportJobStatusSucceeded,
+		"summary": summary,
+	})
+}
+
+// systemPrompt system prompt of the latest prompt template of the app of the run with its version,
+// the built-in prompt if the run has no app
+func (u *EvalUsecase) systemPrompt(ctx context.Context, run *domain.EvalRun, kb *domain.KnowledgeBase) (string, int, error) {
+	promptContext := &domain.PromptContext{
+		KBName:   kb.Name,
+		Citation: domain.CitationStyleInline,
+		Now:      time.Now(),
+	}
+	if run.AppID == "" {
+		return domain.RenderSystemPrompt("", promptContext), 0, nil
+	}
+	app, err := u.appRepo.GetAppDetail(ctx, run.AppID)
+	if err != nil {
+		return "", 0, err
+	}
+	promptContext.AppName = app.Name
+	promptContext.Citation = app.Settings.Citation.StyleOrDefault()
+	template, err := u.promptTemplateRepo.GetLatestAppPromptTemplate(ctx, app.ID)
+	if err != nil {
+		return "", 0, err
+	}
+	if template == nil {
+		return domain.RenderSystemPrompt("", promptContext), 0, nil
+	}
+	return domain.RenderSystemPrompt(template.Content, promptContext), template.Version, nil
+}
+
+// evalQuestion answer the question as a chat without history would and judge the answer, errors are kept on the result
+func (u *EvalUsecase) evalQuestion(
+	ctx context.Context,
+	run *domain.EvalRun,
+	kb *domain.KnowledgeBase,
+	model *domain.Model,
+	systemPrompt string,
+	question *domain.EvalQuestion,
+) *domain.EvalResult {
+	ctx, cancel := context.WithTimeout(ctx, domain.EvalAnswerTimeout)
+	defer cancel()
+	id, _ := uuid.NewV7()
+	result := &domain.EvalResult{
+		ID:             id.String(),
+		RunID:          run.ID,
+		QuestionID:     question.ID,
+		Question:       question.Question,
+		ExpectedAnswer: question.ExpectedAnswer,
+		NodeIDs:        domain.StringList{},
+		CreatedAt:      time.Now(),
+	}
+	fail := func(err error) *domain.EvalResult {
+		u.logger.Warn("eval question failed", log.String("run_id", run.ID), log.String("question_id", question.ID), log.Error(err))
+		result.Error = err.Error()
+		return result
+	}
+	startedAt := time.Now()
+	rankedNodes, err := u.llmUsecase.RetrieveNodes(ctx, kb, question.Question)
+	if err != nil {
+		return fail(fmt.Errorf("retrieve documents failed: %w", err))
+	}
+	for _, node := range rankedNodes {
+		result.NodeIDs = append(result.NodeIDs, node.NodeID)
+	}
+	answer, err := u.llmUsecase.GenerateRetrievedAnswer(ctx, kb, model, systemPrompt, question.Question, rankedNodes)
+	if err != nil {
+		return fail(err)
+	}
+	result.LatencyMS = time.Since(startedAt).Milliseconds()
+	result.Answer = answer
+	documents := domain.FormatNodeChunks(rankedNodes, kb.AccessSettings.BaseURL)
+	judgement, err := u.llmUsecase.JudgeAnswer(ctx, model, question.Question, question.ExpectedAnswer, documents, result.Answer)
+	if err != nil {
+		return fail(fmt.Errorf("judge answer failed: %w", err))
+	}
+	result.Faithfulness = judgement.Faithfulness
+	result.Relevance = judgement.Relevance
+	result.Reason = judgement.Reason
+	result.Passed = judgement.Faithfulness >= domain.EvalPassScore && judgement.Relevance >= domain.EvalPassScore
+	return result
+}
+
+func (u *EvalUsecase) failRun(ctx context.Context, runID string, runErr error) {
+	u.logger.Error("eval run failed", log.String("run_id", runID), log.Error(runErr))
+	if err := u.repo.UpdateEvalRun(ctx, runID, map[string]any{
+		"status": domain.NodeExportJobStatusFailed,
+		"error":  runErr.Error(),
+	}); err != nil {
+		u.logger.Error("update eval run failed", log.String("run_id", runID), log.Error(err))
+	}
+}
+
+func (u *EvalUsecase) GetEvalRun(ctx context.Context, req *domain.EvalRunReq) (*domain.EvalRun, error) {
+	return u.repo.GetEvalRun(ctx, req.KBID, req.RunID)
+}
+
+func (u *EvalUsecase) GetEvalRunList(ctx context.Context, req *domain.EvalRunListReq) (*domain.PaginatedResult[[]*domain.EvalRun], error) {
+	runs, total, err := u.repo.GetEvalRunList(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewPaginatedResult(runs, total), nil
+}
+
+func (u *EvalUsecase) GetEvalResultList(ctx context.Context, req *domain.EvalResultListReq) (*domain.PaginatedResult[[]*domain.EvalResult], error) {
+	if _, err := u.repo.GetEvalRun(ctx, req.KBID, req.RunID); err != nil {
+		return nil, err
+	}
+	results, total, err := u.repo.GetEvalResultList(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewPaginatedResult(results, total), nil
+}
+
+// GetEvalTrend scores of the recent succeeded runs of the set, with the model and prompt version of each run
+func (u *EvalUsecase) GetEvalTrend(ctx context.Context, req *domain.EvalTrendReq) (*domain.EvalTrendResp, error) {
+	if _, err := u.repo.GetEvalSet(ctx, req.KBID, req.SetID); err != nil {
+		return nil, err
+	}
+	runs, err := u.repo.GetEvalTrend(ctx, req.KBID, req.SetID, domain.EvalTrendLimit)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.EvalTrendResp{SetID: req.SetID, Runs: runs}, nil
+}
@@ -0,0 +1,159 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/repo/pg"
+	"github.com/chaitin/panda-wiki/utils"
+)
+
+type ExternalLinkUsecase struct {
+	repo   *pg.ExternalLinkRepository
+	kbRepo *pg.KnowledgeBaseRepository
+	client *http.Client
+	logger *log.Logger
+}
+
+func NewExternalLinkUsecase(repo *pg.ExternalLinkRepository, kbRepo *pg.KnowledgeBaseRepository, logger *log.Logger) *ExternalLinkUsecase {
+	return &ExternalLinkUsecase{
+		repo:   repo,
+		kbRepo: kbRepo,
+		client: &http.Client{
+			Timeout: domain.ExternalLinkCheckTimeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= 5 {
+					return errors.New("too many redirects")
+				}
+				return nil
+			},
+		},
+		logger: logger.WithModule("usecase.external_link"),
+	}
+}
+
+func (u *ExternalLinkUsecase) GetBrokenExternalLinks(ctx context.Context, kbID string) ([]*domain.BrokenExternalLinkResp, error) {
+	return u.repo.GetBrokenExternalLinks(ctx, kbID)
+}
+
+// CheckExternalLinks refresh outbound urls of all kbs and check urls not checked recently
+func (u *ExternalLinkUsecase) CheckExternalLinks(ctx context.Context) error {
+	kbs, err := u.kbRepo.GetKnowledgeBaseList(ctx)
+	if err != nil {
+		return fmt.Errorf("get kb list failed: %w", err)
+	}
+	var errs []error
+	for _, kb := range kbs {
+		if err := u.checkKBExternalLinks(ctx, kb); err != nil {
+			u.logger.Error("check kb external links failed", log.String("kb_id", kb.ID), log.Error(err))
+			errs = append(errs, err)
+		}
+	}
+	if err := u.repo.RemoveStaleExternalLinkChecks(ctx); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+func (u *ExternalLinkUsecase) checkKBExternalLinks(ctx context.Context, kb *domain.KnowledgeBaseListItem) error {
+	nodes, err := u.repo.GetDocumentContents(ctx, kb.ID)
+	if err != nil {
+		return err
+	}
+	links := make([]*domain.NodeExternalLink, 0)
+	for _, node := range nodes {
+		for _, link := range domain.ParseExternalLinks(node.Content, kb.AccessSettings.BaseURL) {
+			links = append(links, &domain.NodeExternalLink{KBID: kb.ID, NodeID: node.ID, URL: link})
+		}
+	}
+	if err := u.repo.ReplaceKBExternalLinks(ctx, kb.ID, links); err != nil {
+		return err
+	}
+	urls, err := u.repo.GetUncheckedURLs(ctx, kb.ID, time.Now().Add(-domain.ExternalLinkCheckTTL))
+	if err != nil {
+		return err
+	}
+	u.logger.Info("check external links", log.String("kb_id", kb.ID), log.Int("links", len(links)), log.Int("unchecked_urls", len(urls)))
+
+	sem := make(chan struct{}, domain.ExternalLinkCheckConcurrency)
+	var wg sync.WaitGroup
+	for _, link := range urls {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(link string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			check := u.checkURL(ctx, link)
+			if err := u.repo.SaveExternalLinkCheck(ctx, check); err != nil {
+				u.logger.Warn("save external link check failed", log.String("url", link), log.Error(err))
+			}
+		}(link)
+	}
+	wg.Wait()
+	return nil
+}
+
+// checkURL HEAD the url, fallback to GET for servers not supporting HEAD
+func (u *ExternalLinkUsecase) checkURL(ctx context.Context, link string) *domain.ExternalLinkCheck {
+	check := &domain.ExternalLinkCheck{URL: link, CheckedAt: time.Now()}
+	if isPrivateLinkHost(link) {
+		// intranet links can't be verified from the server
+		check.Error = "private address, not checked"
+		return check
+	}
+	statusCode, err := u.request(ctx, http.MethodHead, link)
+	if err != nil || statusCode == http.StatusMethodNotAllowed || statusCode == http.StatusForbidden || statusCode == http.StatusNotImplemented {
+		statusCode, err = u.request(ctx, http.MethodGet, link)
+	}
+	check.StatusCode = statusCode
+	if err != nil {
+		check.Broken = true
+		check.Error = err.Error()
+		return check
+	}
+	// rate limited is not a broken link
+	check.Broken = statusCode >= http.StatusBadRequest && statusCode != http.StatusTooManyRequests
+	return check
+}
+
+func (u *ExternalLinkUsecase) request(ctx context.Context, method, link string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, link, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", "PandaWiki-LinkChecker/1.0")
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	return resp.StatusCode, nil
+}
+
+func isPrivateLinkHost(link string) bool {
+	u, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".local") {
+		return true
+	}
+	if net.ParseIP(host) != nil {
+		return utils.IsPrivateOrReservedIP(host)
+	}
+	return false
+}
@@ -0,0 +1,166 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/pkg/bot/dingtalk"
+	"github.com/chaitin/panda-wiki/pkg/bot/feishu"
+	"github.com/chaitin/panda-wiki/repo/pg"
+)
+
+type GapReportUsecase struct {
+	kbRepo           *pg.KnowledgeBaseRepository
+	appRepo          *pg.AppRepository
+	conversationRepo *pg.ConversationRepository
+	nodeRepo         *pg.NodeRepository
+	logger           *log.Logger
+}
+
+func NewGapReportUsecase(kbRepo *pg.KnowledgeBaseRepository, appRepo *pg.AppRepository, conversationRepo *pg.ConversationRepository, nodeRepo *pg.NodeRepository, logger *log.Logger) *GapReportUsecase {
+	return &GapReportUsecase{
+		kbRepo:           kbRepo,
+		appRepo:          appRepo,
+		conversationRepo: conversationRepo,
+		nodeRepo:         nodeRepo,
+		logger:           logger.WithModule("usecase.gap_report"),
+	}
+}
+
+// GetGapReport combine unanswered questions of last week, stale documents and unresolved historical tickets of the kb
+func (u *GapReportUsecase) GetGapReport(ctx context.Context, kbID string) (*domain.GapReport, error) {
+	kb, err := u.kbRepo.GetKnowledgeBaseByID(ctx, kbID)
+	if err != nil {
+		return nil, err
+	}
+	end := time.Now()
+	start := end.Add(-domain.GapReportPeriod)
+	questions, err := u.conversationRepo.GetUnansweredQuestions(ctx, kbID, start, end, domain.GapReportItemLimit)
+	if err != nil {
+		return nil, fmt.Errorf("get unanswered questions failed: %w", err)
+	}
+	staleNodes, err := u.nodeRepo.GetStaleNodes(ctx, kbID, end.Add(-domain.GapReportStaleAge), domain.GapReportItemLimit)
+	if err != nil {
+		return nil, fmt.Errorf("get stale nodes failed: %w", err)
+	}
+	historicalQuestions, err := u.conversationRepo.GetHistoricalUnansweredQuestions(ctx, kbID, domain.GapReportItemLimit)
+	if err != nil {
+		return nil, fmt.Errorf("get historical unanswered questions failed: %w", err)
+	}
+	return &domain.GapReport{
+		KBID:                kbID,
+		KBName:              kb.Name,
+		StartTime:           start,
+		EndTime:             end,
+		UnansweredQuestions: questions,
+		StaleNodes:          staleNodes,
+		HistoricalQuestions: historicalQuestions,
+	}, nil
+}
+
+// SendGapReport push gap report of the kb to all configured dingtalk/feishu groups
+func (u *GapReportUsecase) SendGapReport(ctx context.Context, kbID string) error {
+	apps, err := u.appRepo.GetAppList(ctx, kbID)
+	if err != nil {
+		return err
+	}
+	var report *domain.GapReport
+	sent := 0
+	for _, app := range apps {
+		if !isGapReportEnabled(app) {
+			continue
+		}
+		if report == nil {
+			if report, err = u.GetGapReport(ctx, kbID); err != nil {
+				return err
+			}
+		}
+		if err := sendGapReport(ctx, app, report); err != nil {
+			return fmt.Errorf("send gap report to app %s failed: %w", app.ID, err)
+		}
+		sent++
+	}
+	if sent == 0 {
+		return errors.New("gap report is not configured")
+	}
+	return nil
+}
+
+// SendWeeklyGapReports push gap reports of all kbs with gap report enabled
+func (u *GapReportUsecase) SendWeeklyGapReports(ctx context.Context) error {
+	apps, err := u.appRepo.GetAppsByTypes(ctx, []domain.AppType{domain.AppTypeDingTalkBot, domain.AppTypeFeishuBot})
+	if err != nil {
+		return err
+	}
+	reports := make(map[string]*domain.GapReport)
+	for _, app := range apps {
+		if !isGapReportEnabled(app) {
+			continue
+		}
+		report, ok := reports[app.KBID]
+		if !ok {
+			report, err = u.GetGapReport(ctx, app.KBID)
+			if err != nil {
+				u.logger.Error("get gap report failed", log.String("kb_id", app.KBID), log.Error(err))
+				continue
+			}
+			reports[app.KBID] = report
+		}
+		if err := sendGapReport(ctx, app, report); err != nil {
+			u.logger.Error("send gap report failed", log.String("kb_id", app.KBID), log.String("app_id", app.ID), log.Error(err))
+		}
+	}
+	return nil
+}
+
+func isGapReportEnabled(app *domain.App) bool {
+	if app.Type != domain.AppTypeDingTalkBot && app.Type != domain.AppTypeFeishuBot {
+		return false
+	}
+	return app.Settings.GapReport.Enabled && app.Settings.GapReport.WebhookURL != ""
+}
+
+func sendGapReport(ctx context.Context, app *domain.App, report *domain.GapReport) error {
+	settings := app.Settings.GapReport
+	title := fmt.Sprintf("【%s】知识库缺口周报", report.KBName)
+	text := renderGapReport(report, strings.TrimRight(settings.ConsoleURL, "/"))
+	if app.Type == domain.AppTypeFeishuBot {
+		return feishu.SendWebhookMarkdown(ctx, settings.WebhookURL, settings.Secret, title, text)
+	}
+	return dingtalk.SendWebhookMarkdown(ctx, settings.WebhookURL, settings.Secret, title, "### "+title+"\n\n"+text)
+}
+
+func renderGapReport(report *domain.GapReport, consoleURL string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "统计周期：%s ~ %s\n\n", report.StartTime.Format("2006-01-02"), report.EndTime.Format("2006-01-02"))
+
+	sb.WriteString("**未能回答的问题**\n\n")
+	if len(report.UnansweredQuestions) == 0 {
+		sb.WriteString("无\n\n")
+	}
+	for i, q := range report.UnansweredQuestions {
+		fmt.Fprintf(&sb, "%d. %s（%d 次）\n", i+1, strings.TrimSpace(q.Question), q.Count)
+	}
+	if len(report.UnansweredQuestions) > 0 && consoleURL != "" {
+		fmt.Fprintf(&sb, "\n[查看问答记录](%s/conversation)\n", consoleURL)
+	}
+	sb.WriteString("\n")
+
+	fmt.Fprintf(&sb, "**超过 %d 天未更新的文档**\n\n", int(domain.GapReportStaleAge.Hours()/24))
+	if len(report.StaleNodes) == 0 {
+		sb.WriteString("无\n")
+	}
+	for i, node := range report.StaleNodes {
+		name := node.Name
+		if consoleURL != "" {
+			name = fmt.Sprintf("[%s](%s/doc/editor/%s)", node.Name, consoleURL, node.ID)
+		}
+		fmt.Fprintf(&sb, "%d. %s（最后更新 %s）\n", i+1, name, node.UpdatedAt.Format("2006-01-02"))
+	}
+	return sb.String()
+}
@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/repo/pg"
+)
+
+type GlossaryUsecase struct {
+	repo   *pg.GlossaryRepository
+	logger *log.Logger
+}
+
+func NewGlossaryUsecase(repo *pg.GlossaryRepository, logger *log.Logger) *GlossaryUsecase {
+	return &GlossaryUsecase{
+		repo:   repo,
+		logger: logger.WithModule("usecase.glossary"),
+	}
+}
+
+func (u *GlossaryUsecase) GetGlossaryTermList(ctx context.Context, kbID string) ([]*domain.GlossaryTerm, error) {
+	return u.repo.GetGlossaryTermList(ctx, kbID)
+}
+
+func (u *GlossaryUsecase) CreateGlossaryTerm(ctx context.Context, req *domain.CreateGlossaryTermReq) (string, error) {
+	now := time.Now()
+	term := &domain.GlossaryTerm{
+		ID:        uuid.New().String(),
+		CreatedAt: now,
+	}
+	fillGlossaryTerm(term, req, now)
+	if err := u.repo.CreateGlossaryTerm(ctx, term); err != nil {
+		return "", err
+	}
+	return term.ID, nil
+}
+
+func (u *GlossaryUsecase) UpdateGlossaryTerm(ctx context.Context, req *domain.UpdateGlossaryTermReq) error {
+	term := &domain.GlossaryTerm{ID: req.ID}
+	fillGlossaryTerm(term, &req.CreateGlossaryTermReq, time.Now())
+	return u.repo.UpdateGlossaryTerm(ctx, term)
+}
+
+func fillGlossaryTerm(term *domain.GlossaryTerm, req *domain.CreateGlossaryTermReq, now time.Time) {
+	term.KBID = req.KBID
+	term.Term = strings.TrimSpace(req.Term)
+	term.Aliases = make(domain.GlossaryAliases, 0, len(req.Aliases))
+	for _, alias := range req.Aliases {
+		if alias = strings.TrimSpace(alias); alias != "" && alias != term.Term {
+			term.Aliases = append(term.Aliases, alias)
+		}
+	}
+	term.Definition = strings.TrimSpace(req.Definition)
+	term.NodeID = req.NodeID
+	term.UpdatedAt = now
+}
+
+func (u *GlossaryUsecase) DeleteGlossaryTerm(ctx context.Context, req *domain.DeleteGlossaryTermReq) error {
+	return u.repo.DeleteGlossaryTerm(ctx, req.KBID, req.ID)
+}
+
+// LinkContent link glossary terms mentioned in published content, content is kept as is if the glossary is unavailable
+func (u *GlossaryUsecase) LinkContent(ctx context.Context, kbID, content string) string {
+	terms, err := u.repo.GetGlossaryTermList(ctx, kbID)
+	if err != nil {
+		u.logger.Warn("get glossary terms failed", log.String("kb_id", kbID), log.Error(err))
+		return content
+	}
+	if len(terms) == 0 {
+		return content
+	}
+	return domain.NewGlossaryLinker(terms).LinkContent(content)
+}
@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/repo/pg"
+)
+
+type GuardrailUsecase struct {
+	repo   *pg.GuardrailRepository
+	logger *log.Logger
+}
+
+func NewGuardrailUsecase(repo *pg.GuardrailRepository, logger *log.Logger) *GuardrailUsecase {
+	return &GuardrailUsecase{
+		repo:   repo,
+		logger: logger.WithModule("usecase.guardrail"),
+	}
+}
+
+// Record save the matched checks of the chat as guardrail events, failures are only logged
+func (u *GuardrailUsecase) Record(ctx context.Context, req *domain.ChatRequest, violations domain.GuardrailViolations) {
+	question := []rune(req.Message)
+	if len(question) > domain.GuardrailDetailRunes {
+		question = question[:domain.GuardrailDetailRunes]
+	}
+	events := make([]*domain.GuardrailEvent, 0, len(violations))
+	for _, violation := range violations {
+		u.logger.Info("guardrail matched", log.String("kb_id", req.KBID), log.String("app_id", req.AppID),
+			log.String("check", string(violation.Check)), log.String("action", string(violation.Action)), log.String("detail", violation.Detail))
+		events = append(events, &domain.GuardrailEvent{
+			ID:             uuid.New().String(),
+			KBID:           req.KBID,
+			AppID:          req.AppID,
+			ConversationID: req.ConversationID,
+			Check:          violation.Check,
+			Stage:          violation.Stage,
+			Action:         violation.Action,
+			Detail:         violation.Detail,
+			Question:       string(question),
+			RemoteIP:       req.RemoteIP,
+			CreatedAt:      time.Now(),
+		})
+	}
+	if err := u.repo.CreateGuardrailEvents(ctx, events); err != nil {
+		u.logger.Warn("save guardrail events failed", log.String("kb_id", req.KBID), log.Error(err))
+	}
+}
+
+func (u *GuardrailUsecase) GetGuardrailEventList(ctx context.Context, req *domain.GuardrailEventListReq) (*domain.PaginatedResult[[]*domain.GuardrailEvent], error) {
+	events, total, err := u.repo.GetGuardrailEventList(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewPaginatedResult(events, total), nil
+}
@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	htmltomarkdown "github.com/JohannesKaufmann/html-to-markdown/v2"
+	"github.com/JohannesKaufmann/html-to-markdown/v2/converter"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/pkg/website"
+	"github.com/chaitin/panda-wiki/utils"
+)
+
+// feedPages entries of the feed, versions are hashes of their title, link and content so edited entries are updated.
+// pages fetched for summary entries are not fetched again until the entry changes
+func (u *ImportSourceUsecase) feedPages(ctx context.Context, source *domain.ImportSource) ([]*importPage, pageSyncer, error) {
+	settings := source.Settings.Feed
+	if settings == nil {
+		return nil, nil, errors.New("feed settings are required")
+	}
+	feed, err := utils.ParseFeed(settings.URL)
+	if err != nil {
+		return nil, nil, err
+	}
+	pages := make([]*importPage, 0, len(feed.Items))
+	entries := make(map[string]utils.FeedItem, len(feed.Items))
+	for _, item := range feed.Items {
+		// entries are identified by their guid, or their link if they have none
+		id := item.ID
+		if id == "" {
+			id = item.Link
+		}
+		if _, ok := entries[id]; ok || id == "" {
+			continue
+		}
+		title := strings.TrimSpace(item.Title)
+		if title == "" {
+			title = item.Link
+		}
+		hash := sha256.Sum256([]byte(title + "\n" + item.Link + "\n" + item.Description + "\n" + item.Content))
+		pages = append(pages, &importPage{
+			ID:      id,
+			Title:   title,
+			Version: hex.EncodeToString(hash[:]),
+		})
+		entries[id] = item
+	}
+	return pages, func(page *importPage, nodeIDs map[string]string, _ map[string]*domain.ImportSourceItem) error {
+		nodeID := nodeIDs[page.ID]
+		content, err := u.feedEntryContent(ctx, settings, entries[page.ID])
+		if err != nil {
+			return err
+		}
+		if err := u.nodeUsecase.Update(ctx, &domain.UpdateNodeReq{
+			ID:      nodeID,
+			KBID:    source.KBID,
+			Name:    &page.Title,
+			Content: &content,
+		}); err != nil {
+			return err
+		}
+		return u.savePageItem(ctx, source, page, nodeID)
+	}, nil
+}
+
+// feedEntryContent markdown of the entry with a link to its page, the page is fetched if enabled and the feed only has a summary
+func (u *ImportSourceUsecase) feedEntryContent(ctx context.Context, settings *domain.FeedSettings, entry utils.FeedItem) (string, error) {
+	content := ""
+	if entry.Content == "" && settings.FetchPage && entry.Link != "" {
+		page, err := website.FetchPage(ctx, entry.Link)
+		if err != nil {
+			u.logger.Warn("fetch feed entry page failed, summary is imported", log.String("link", entry.Link), log.Error(err))
+		} else {
+			content = page.Markdown
+		}
+	}
+	if content == "" {
+		html := entry.Content
+		if html == "" {
+			html = entry.Description
+		}
+		var opts []converter.ConvertOptionFunc
+		if entry.Link != "" {
+			opts = append(opts, converter.WithDomain(entry.Link))
+		}
+		markdown, err := htmltomarkdown.ConvertString(html, opts...)
+		if err != nil {
+			return "", fmt.Errorf("convert feed entry failed: %w", err)
+		}
+		content = strings.TrimSpace(markdown)
+	}
+	if entry.Link != "" {
+		content += fmt.Sprintf("\n\n原文链接：[%s](%s)", entry.Link, entry.Link)
+	}
+	return content, nil
+}
@@ -0,0 +1,338 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/pkg/gitrepo"
+)
+
+// images referenced by html in markdown
+var htmlImgSrcRegex = regexp.MustCompile(`(<img\s[^>]*?src=["'])([^"']+)(["'])`)
+
+// gitDocsPath directory of the docs relative to the root of the repo, empty for the whole repo
+func gitDocsPath(p string) string {
+	return strings.Trim(path.Clean("/"+strings.TrimSpace(p)), "/")
+}
+
+func isGitMarkdown(filePath string) bool {
+	switch strings.ToLower(path.Ext(filePath)) {
+	case ".md", ".markdown":
+		return true
+	}
+	return false
+}
+
+// gitPages markdown files under the docs path and the directories containing them as folders,
+// ids are paths relative to the docs path and versions are blob hashes of the files
+func (u *ImportSourceUsecase) gitPages(ctx context.Context, source *domain.ImportSource) ([]*importPage, pageSyncer, error) {
+	settings := source.Settings.Git
+	if settings == nil {
+		return nil, nil, errors.New("git settings are required")
+	}
+	dir, err := os.MkdirTemp("", "panda-wiki-git-")
+	if err != nil {
+		return nil, nil, err
+	}
+	// the clone is read until the sync or preview is done, both cancel their context when they return
+	context.AfterFunc(ctx, func() {
+		if err := os.RemoveAll(dir); err != nil {
+			u.logger.Warn("remove git clone failed", log.String("dir", dir), log.Error(err))
+		}
+	})
+	if err := gitrepo.Clone(ctx, settings.URL, settings.Branch, settings.Token, dir); err != nil {
+		return nil, nil, err
+	}
+	root := gitDocsPath(settings.Path)
+	// images outside the docs path may be referenced too
+	files, err := gitrepo.ListFiles(ctx, dir, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	blobs := make(map[string]string, len(files))
+	importPages := make([]*importPage, 0)
+	folders := make(map[string]bool)
+	for _, file := range files {
+		blobs[file.Path] = file.Blob
+		rel := file.Path
+		if root != "" {
+			if !strings.HasPrefix(file.Path, root+"/") {
+				continue
+			}
+			rel = strings.TrimPrefix(file.Path, root+"/")
+		}
+		if !isGitMarkdown(rel) {
+			continue
+		}
+		parentID := gitParentDir(rel)
+		importPages = append(importPages, &importPage{
+			ID:       rel,
+			ParentID: parentID,
+			Title:    strings.TrimSuffix(path.Base(rel), path.Ext(rel)),
+			Version:  file.Blob,
+		})
+		for d := parentID; d != "" && !folders[d]; d = gitParentDir(d) {
+			folders[d] = true
+			importPages = append(importPages, &importPage{
+				ID:       d,
+				ParentID: gitParentDir(d),
+				Title:    path.Base(d),
+				Folder:   true,
+			})
+		}
+	}
+	return importPages, func(page *importPage, nodeIDs map[string]string, attachmentItems map[string]*domain.ImportSourceItem) error {
+		return u.syncGitPage(ctx, source, dir, root, blobs, page, nodeIDs, attachmentItems)
+	}, nil
+}
+
+func gitParentDir(p string) string {
+	if d := path.Dir(p); d != "." {
+		return d
+	}
+	return ""
+}
+
+// syncGitPage import the markdown file with images of the repo it references, folders only have their name
+func (u *ImportSourceUsecase) syncGitPage(ctx context.Context, source *domain.ImportSource, dir, root string, blobs map[string]string, page *importPage, nodeIDs map[string]string, attachmentItems map[string]*domain.ImportSourceItem) error {
+	nodeID := nodeIDs[page.ID]
+	req := &domain.UpdateNodeReq{
+		ID:   nodeID,
+		KBID: source.KBID,
+		Name: &page.Title,
+	}
+	if !page.Folder {
+		filePath := path.Join(root, page.ID)
+		data, err := gitrepo.ReadFile(dir, filePath)
+		if err != nil {
+			return err
+		}
+		content := string(data)
+		images := make(map[string]string)
+		for _, target := range gitImageTargets(filePath, content) {
+			blob, ok := blobs[target]
+			if !ok {
+				continue
+			}
+			if _, ok := images[target]; ok {
+				continue
+			}
+			ref, err := u.importGitImage(ctx, source, dir, nodeID, target, blob, attachmentItems[target])
+			if err != nil {
+				return fmt.Errorf("import image %s failed: %w", target, err)
+			}
+			images[target] = ref
+		}
+		content = rewriteGitLinks(content, filePath, root, images, nodeIDs)
+		req.Content = &content
+	}
+	if err := u.nodeUsecase.Update(ctx, req); err != nil {
+		return err
+	}
+	return u.savePageItem(ctx, source, page, nodeID)
+}
+
+// importGitImage upload the image of the repo again only if its blob changed
+func (u *ImportSourceUsecase) importGitImage(ctx context.Context, source *domain.ImportSource, dir, nodeID, target, blob string, previous *domain.ImportSourceItem) (string, error) {
+	if previous != nil && previous.Version == blob && previous.Ref != "" {
+		return previous.Ref, nil
+	}
+	mediaType := mime.TypeByExtension(strings.ToLower(path.Ext(target)))
+	if !isImageMediaType(mediaType) {
+		return "", nil
+	}
+	file, err := gitrepo.Open(dir, target)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return "", err
+	}
+	if info.Size() > u.config.S3.MaxFileSize {
+		u.logger.Warn("skip large git image", log.String("path", target), log.Int64("size", info.Size()))
+		return "", nil
+	}
+	ref, err := u.importFile(ctx, source.KBID, nodeID, path.Base(target), mediaType, file, info.Size(), previous)
+	if err != nil {
+		return "", err
+	}
+	if err := u.repo.SaveItem(ctx, &domain.ImportSourceItem{
+		SourceID:   source.ID,
+		Kind:       domain.ImportSourceItemKindAttachment,
+		ExternalID: target,
+		NodeID:     nodeID,
+		Version:    blob,
+		Ref:        ref,
+	}); err != nil {
+		return "", err
+	}
+	return ref, nil
+}
+
+// gitImageTargets paths in the repo of images referenced by the markdown file
+func gitImageTargets(filePath, content string) []string {
+	targets := make([]string, 0)
+	for _, m := range markdownLinkRegex.FindAllStringSubmatch(content, -1) {
+		if !strings.HasPrefix(m[1], "!") {
+			continue
+		}
+		if target, _ := gitLinkTarget(filePath, m[2]); target != "" {
+			targets = append(targets, target)
+		}
+	}
+	for _, m := range htmlImgSrcRegex.FindAllStringSubmatch(content, -1) {
+		if target, _ := gitLinkTarget(filePath, m[2]); target != "" {
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+// rewriteGitLinks images point at the uploaded files, links to files and directories under the docs path at their nodes
+func rewriteGitLinks(content, filePath, root string, images, nodeIDs map[string]string) string {
+	rewrite := func(link string, image bool) string {
+		target, fragment := gitLinkTarget(filePath, link)
+		if target == "" {
+			return link
+		}
+		if image {
+			if ref := images[target]; ref != "" {
+				return ref
+			}
+			return link
+		}
+		rel := target
+		if root != "" {
+			if !strings.HasPrefix(target, root+"/") {
+				return link
+			}
+			rel = strings.TrimPrefix(target, root+"/")
+		}
+		if nodeID, ok := nodeIDs[rel]; ok {
+			return "/node/" + nodeID + fragment
+		}
+		return link
+	}
+	content = markdownLinkRegex.ReplaceAllStringFunc(content, func(match string) string {
+		parts := markdownLinkRegex.FindStringSubmatch(match)
+		return parts[1] + rewrite(parts[2], strings.HasPrefix(parts[1], "!")) + parts[3]
+	})
+	content = htmlImgSrcRegex.ReplaceAllStringFunc(content, func(match string) string {
+		parts := htmlImgSrcRegex.FindStringSubmatch(match)
+		return parts[1] + rewrite(parts[2], true) + parts[3]
+	})
+	return htmlHrefRegex.ReplaceAllStringFunc(content, func(match string) string {
+		parts := htmlHrefRegex.FindStringSubmatch(match)
+		return parts[1] + rewrite(parts[2], false) + parts[3]
+	})
+}
+
+// gitLinkTarget path in the repo of a relative link of the file and the fragment of the link,
+// empty for urls, anchors and paths outside the repo. links starting with / are relative to the root of the repo
+func gitLinkTarget(filePath, link string) (string, string) {
+	u, err := url.Parse(link)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "" {
+		return "", ""
+	}
+	target := u.Path
+	if strings.HasPrefix(target, "/") {
+		target = path.Clean(target)
+	} else {
+		target = path.Join("/", path.Dir(filePath), target)
+	}
+	// joined under / so that .. can not leave the repo
+	target = strings.Trim(target, "/")
+	if target == "" {
+		return "", ""
+	}
+	fragment := ""
+	if u.Fragment != "" {
+		fragment = "#" + u.Fragment
+	}
+	return target, fragment
+}
+
+// HandleGitWebhook sync the git source on push events of its branch from github or gitlab,
+// github signs the payload with the webhook secret and gitlab sends it as token
+func (u *ImportSourceUsecase) HandleGitWebhook(ctx context.Context, kbID, id string, header http.Header, body []byte) (*domain.GitWebhookResp, error) {
+	source, err := u.repo.GetImportSource(ctx, kbID, id)
+	if err != nil {
+		return nil, err
+	}
+	settings := source.Settings.Git
+	if source.Type != domain.ImportSourceTypeGit || settings == nil || settings.WebhookSecret == "" {
+		return nil, domain.ErrGitWebhookSignature
+	}
+	if !verifyGitWebhook(header, body, settings.WebhookSecret) {
+		return nil, domain.ErrGitWebhookSignature
+	}
+	event := header.Get("X-GitHub-Event")
+	if event == "" {
+		event = header.Get("X-Gitlab-Event")
+	}
+	if event != "push" && event != "Push Hook" {
+		return &domain.GitWebhookResp{Reason: fmt.Sprintf("event %s is ignored", event)}, nil
+	}
+	var payload struct {
+		Ref        string `json:"ref"`
+		Repository struct {
+			DefaultBranch string `json:"default_branch"`
+		} `json:"repository"`
+		Project struct {
+			DefaultBranch string `json:"default_branch"`
+		} `json:"project"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("decode push event failed: %w", err)
+	}
+	branch := settings.Branch
+	if branch == "" {
+		branch = payload.Repository.DefaultBranch
+	}
+	if branch == "" {
+		branch = payload.Project.DefaultBranch
+	}
+	if payload.Ref != "refs/heads/"+branch {
+		return &domain.GitWebhookResp{Reason: fmt.Sprintf("push to %s is ignored", payload.Ref)}, nil
+	}
+	started, err := u.repo.StartSync(ctx, source.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !started {
+		// the next scheduled or manual sync picks the push up
+		return &domain.GitWebhookResp{Reason: domain.ErrImportSourceSyncing.Error()}, nil
+	}
+	go u.runSync(context.WithoutCancel(ctx), source)
+	return &domain.GitWebhookResp{Synced: true}, nil
+}
+
+func verifyGitWebhook(header http.Header, body []byte, secret string) bool {
+	if signature := header.Get("X-Hub-Signature-256"); signature != "" {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+		return hmac.Equal([]byte(signature), []byte(expected))
+	}
+	if token := header.Get("X-Gitlab-Token"); token != "" {
+		return subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+)
+
+var (
+	// link text may hold one level of brackets, e.g. [see [1]](url)
+	markdownLinkRegex = regexp.MustCompile(`(!?\[(?:[^\[\]]|\[[^\[\]]*\])*\]\()([^)\s]+)((?:\s+"[^"]*")?\))`)
+	htmlHrefRegex     = regexp.MustCompile(`(href=["'])([^"']+)(["'])`)
+	// notion page ids are 32 hex chars, with or without dashes, at the end of page urls
+	notionPageIDRegex = regexp.MustCompile(`([0-9a-fA-F]{8}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{12})$`)
+)
+
+// RewriteImportLinks point links between imported pages at the new nodes, after all pages are created
+func (u *NodeUsecase) RewriteImportLinks(ctx context.Context, req *domain.RewriteImportLinksReq) (*domain.RewriteImportLinksResp, error) {
+	nodeByKey := make(map[string]string, len(req.Links))
+	for _, link := range req.Links {
+		if key := importLinkKey(link.URL, nil); key != "" {
+			nodeByKey[key] = link.NodeID
+		}
+	}
+	resp := &domain.RewriteImportLinksResp{UpdatedNodeIDs: []string{}}
+	for _, link := range req.Links {
+		node, err := u.nodeRepo.GetByID(ctx, link.NodeID)
+		if err != nil {
+			return nil, fmt.Errorf("get node %s failed: %w", link.NodeID, err)
+		}
+		if node.KBID != req.KBID {
+			continue
+		}
+		base, _ := url.Parse(link.URL)
+		content, count := rewriteImportLinks(node.Content, base, nodeByKey)
+		if count == 0 {
+			continue
+		}
+		if err := u.nodeRepo.UpdateNodeContent(ctx, &domain.UpdateNodeReq{
+			ID:      node.ID,
+			KBID:    req.KBID,
+			Content: &content,
+		}); err != nil {
+			return nil, err
+		}
+		resp.RewriteCount += count
+		resp.UpdatedNodeIDs = append(resp.UpdatedNodeIDs, node.ID)
+	}
+	u.logger.Info("rewrite import links", log.String("kb_id", req.KBID), log.Int("rewrite_count", resp.RewriteCount), log.Int("node_count", len(resp.UpdatedNodeIDs)))
+	return resp, nil
+}
+
+func rewriteImportLinks(content string, base *url.URL, nodeByKey map[string]string) (string, int) {
+	count := 0
+	rewrite := func(re *regexp.Regexp) {
+		content = re.ReplaceAllStringFunc(content, func(match string) string {
+			parts := re.FindStringSubmatch(match)
+			// images are not links between pages
+			if strings.HasPrefix(parts[1], "!") {
+				return match
+			}
+			target := parts[2]
+			nodeID, ok := nodeByKey[importLinkKey(target, base)]
+			if !ok {
+				return match
+			}
+			count++
+			newTarget := "/node/" + nodeID
+			if i := strings.Index(target, "#"); i >= 0 {
+				newTarget += target[i:]
+			}
+			return parts[1] + newTarget + parts[3]
+		})
+	}
+	rewrite(markdownLinkRegex)
+	rewrite(htmlHrefRegex)
+	return content, count
+}
+
+// importLinkKey normalize link to match source urls, relative links are resolved against base
+func importLinkKey(link string, base *url.URL) string {
+	link = strings.TrimSpace(link)
+	if link == "" || strings.HasPrefix(link, "#") || strings.HasPrefix(link, "mailto:") {
+		return ""
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	if base != nil && !u.IsAbs() && base.IsAbs() {
+		u = base.ResolveReference(u)
+	}
+	u.Fragment = ""
+	// notion links are matched by page id, whatever the host and title slug
+	if id := notionPageIDRegex.FindString(strings.TrimSuffix(u.Path, "/")); id != "" && (u.Host == "" || strings.Contains(u.Host, "notion")) {
+		return "notion:" + strings.ToLower(strings.ReplaceAll(id, "-", ""))
+	}
+	if !u.IsAbs() {
+		return ""
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+	path := strings.TrimSuffix(u.EscapedPath(), "/")
+	key := host + path
+	if u.RawQuery != "" {
+		key += "?" + u.RawQuery
+	}
+	return key
+}
@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestRewriteImportLinks(t *testing.T) {
+	base, _ := url.Parse("https://docs.example.com/guide/start")
+	nodeByKey := map[string]string{
+		"docs.example.com/guide/install":          "n1",
+		"docs.example.com/guide/faq":              "n2",
+		"notion:0123456789abcdef0123456789abcdef": "n3",
+	}
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		count   int
+	}{
+		{
+			name:    "absolute link",
+			content: "see [install](https://docs.example.com/guide/install)",
+			want:    "see [install](/node/n1)",
+			count:   1,
+		},
+		{
+			name:    "relative link with fragment and title",
+			content: `[faq](faq#top "FAQ")`,
+			want:    `[faq](/node/n2#top "FAQ")`,
+			count:   1,
+		},
+		{
+			name:    "nested brackets in link text",
+			content: "[see [1]](https://docs.example.com/guide/install) and [[faq]](faq)",
+			want:    "[see [1]](/node/n1) and [[faq]](/node/n2)",
+			count:   2,
+		},
+		{
+			name:    "image link is left alone",
+			content: "![install](https://docs.example.com/guide/install)",
+			want:    "![install](https://docs.example.com/guide/install)",
+			count:   0,
+		},
+		{
+			name:    "image inside nested link text is left alone",
+			content: "![a [b]](faq)",
+			want:    "![a [b]](faq)",
+			count:   0,
+		},
+		{
+			name:    "notion link by page id",
+			content: "[page](https://www.notion.so/Page-0123456789abcdef0123456789abcdef)",
+			want:    "[page](/node/n3)",
+			count:   1,
+		},
+		{
+			name:    "html href",
+			content: `<a href="https://docs.example.com/guide/faq/">faq</a>`,
+			want:    `<a href="/node/n2">faq</a>`,
+			count:   1,
+		},
+		{
+			name:    "unknown link is kept",
+			content: "[other](https://other.example.com/page) [anchor](#top)",
+			want:    "[other](https://other.example.com/page) [anchor](#top)",
+			count:   0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, count := rewriteImportLinks(tt.content, base, nodeByKey)
+			if got != tt.want || count != tt.count {
+				t.Errorf("rewriteImportLinks() = %q, %d, want %q, %d", got, count, tt.want, tt.count)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/pkg/openapidoc"
+)
+
+// openapiPages documents of the tags and operations of the spec, versions are hashes of their title and content,
+// so only operations changed in the spec are updated
+func (u *ImportSourceUsecase) openapiPages(ctx context.Context, source *domain.ImportSource) ([]*importPage, pageSyncer, error) {
+	settings := source.Settings.OpenAPI
+	if settings == nil {
+		return nil, nil, errors.New("openapi settings are required")
+	}
+	data, err := openapidoc.Fetch(ctx, settings.URL, settings.Token)
+	if err != nil {
+		return nil, nil, err
+	}
+	doc, err := openapidoc.Load(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	pages := openapidoc.Pages(doc)
+	importPages := make([]*importPage, 0, len(pages))
+	renders := make(map[string]func(openapidoc.Resolver) string, len(pages))
+	for _, page := range pages {
+		// tags link to operations by their ids before nodes are known
+		hash := sha256.Sum256([]byte(page.Title + "\n" + page.Render(nil)))
+		importPages = append(importPages, &importPage{
+			ID:       page.ID,
+			ParentID: page.ParentID,
+			Title:    page.Title,
+			Version:  hex.EncodeToString(hash[:]),
+		})
+		renders[page.ID] = page.Render
+	}
+	return importPages, func(page *importPage, nodeIDs map[string]string, _ map[string]*domain.ImportSourceItem) error {
+		nodeID := nodeIDs[page.ID]
+		content := renders[page.ID](&openapiResolver{nodeIDs: nodeIDs})
+		if err := u.nodeUsecase.Update(ctx, &domain.UpdateNodeReq{
+			ID:      nodeID,
+			KBID:    source.KBID,
+			Name:    &page.Title,
+			Content: &content,
+		}); err != nil {
+			return err
+		}
+		return u.savePageItem(ctx, source, page, nodeID)
+	}, nil
+}
+
+// openapiResolver operations point at their nodes
+type openapiResolver struct {
+	nodeIDs map[string]string
+}
+
+func (r *openapiResolver) OperationURL(id string) string {
+	if nodeID, ok := r.nodeIDs[id]; ok {
+		return "/node/" + nodeID
+	}
+	return ""
+}
@@ -0,0 +1,1292 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minio/minio-go/v7"
+	"github.com/samber/lo"
+
+	"github.com/chaitin/panda-wiki/config"
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/pkg/confluence"
+	"github.com/chaitin/panda-wiki/pkg/feishudoc"
+	"github.com/chaitin/panda-wiki/pkg/gitrepo"
+	"github.com/chaitin/panda-wiki/pkg/notion"
+	"github.com/chaitin/panda-wiki/pkg/openapidoc"
+	"github.com/chaitin/panda-wiki/pkg/website"
+	"github.com/chaitin/panda-wiki/pkg/yuque"
+	"github.com/chaitin/panda-wiki/repo/pg"
+	"github.com/chaitin/panda-wiki/store/s3"
+	"github.com/chaitin/panda-wiki/utils"
+)
+
+type ImportSourceUsecase struct {
+	repo              *pg.ImportSourceRepository
+	nodeUsecase       *NodeUsecase
+	kbUsecase         *KnowledgeBaseUsecase
+	attachmentUsecase *NodeAttachmentUsecase
+	s3Client          *s3.MinioClient
+	config            *config.Config
+	logger            *log.Logger
+}
+
+func NewImportSourceUsecase(
+	repo *pg.ImportSourceRepository,
+	nodeUsecase *NodeUsecase,
+	kbUsecase *KnowledgeBaseUsecase,
+	attachmentUsecase *NodeAttachmentUsecase,
+	s3Client *s3.MinioClient,
+	config *config.Config,
+	logger *log.Logger,
+) *ImportSourceUsecase {
+	return &ImportSourceUsecase{
+		repo:              repo,
+		nodeUsecase:       nodeUsecase,
+		kbUsecase:         kbUsecase,
+		attachmentUsecase: attachmentUsecase,
+		s3Client:          s3Client,
+		config:            config,
+		logger:            logger.WithModule("usecase.import_source"),
+	}
+}
+
+// CreateSource save the source after checking its credentials, pages are imported by SyncSource
+func (u *ImportSourceUsecase) CreateSource(ctx context.Context, req *domain.CreateImportSourceReq) (*domain.ImportSource, error) {
+	settings := domain.ImportSourceSettings{Confluence: req.Confluence, Notion: req.Notion, Feishu: req.Feishu, Yuque: req.Yuque, Git: req.Git, OpenAPI: req.OpenAPI, Website: req.Website, Feed: req.Feed}
+	name, err := checkImportSettings(ctx, req.Type, &settings)
+	if err != nil {
+		return nil, err
+	}
+	if req.Name != "" {
+		name = req.Name
+	}
+	now := time.Now()
+	source := &domain.ImportSource{
+		ID:           uuid.New().String(),
+		KBID:         req.KBID,
+		Type:         req.Type,
+		Name:         name,
+		ParentID:     req.ParentID,
+		Settings:     settings,
+		SyncInterval: req.SyncInterval,
+		Status:       domain.ImportSourceStatusIdle,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := u.repo.CreateImportSource(ctx, source); err != nil {
+		return nil, err
+	}
+	source.Settings = source.Settings.Masked()
+	return source, nil
+}
+
+func (u *ImportSourceUsecase) GetSourceList(ctx context.Context, kbID string) ([]*domain.ImportSource, error) {
+	sources, err := u.repo.GetImportSourceList(ctx, kbID)
+	if err != nil {
+		return nil, err
+	}
+	for _, source := range sources {
+		source.Settings = source.Settings.Masked()
+	}
+	return sources, nil
+}
+
+func (u *ImportSourceUsecase) UpdateSource(ctx context.Context, req *domain.UpdateImportSourceReq) error {
+	source, err := u.repo.GetImportSource(ctx, req.KBID, req.ID)
+	if err != nil {
+		return err
+	}
+	updates := map[string]any{}
+	if req.Name != nil {
+		updates["name"] = *req.Name
+	}
+	if req.ParentID != nil {
+		updates["parent_id"] = *req.ParentID
+	}
+	if req.SyncInterval != nil {
+		updates["sync_interval"] = *req.SyncInterval
+	}
+	if req.Confluence != nil || req.Notion != nil || req.Feishu != nil || req.Yuque != nil || req.Git != nil || req.OpenAPI != nil || req.Website != nil || req.Feed != nil {
+		settings := domain.ImportSourceSettings{}
+		if req.Confluence != nil {
+			confluence := *req.Confluence
+			if confluence.Token == "" && source.Settings.Confluence != nil {
+				confluence.Token = source.Settings.Confluence.Token
+			}
+			settings.Confluence = &confluence
+		}
+		if req.Notion != nil {
+			notion := *req.Notion
+			if notion.Token == "" && source.Settings.Notion != nil {
+				notion.Token = source.Settings.Notion.Token
+			}
+			settings.Notion = &notion
+		}
+		if req.Feishu != nil {
+			feishu := *req.Feishu
+			if feishu.AppSecret == "" && source.Settings.Feishu != nil {
+				feishu.AppSecret = source.Settings.Feishu.AppSecret
+			}
+			settings.Feishu = &feishu
+		}
+		if req.Yuque != nil {
+			yuque := *req.Yuque
+			if yuque.Token == "" && source.Settings.Yuque != nil {
+				yuque.Token = source.Settings.Yuque.Token
+			}
+			settings.Yuque = &yuque
+		}
+		if req.Git != nil {
+			git := *req.Git
+			if source.Settings.Git != nil {
+				if git.Token == "" {
+					git.Token = source.Settings.Git.Token
+				}
+				if git.WebhookSecret == "" {
+					git.WebhookSecret = source.Settings.Git.WebhookSecret
+				}
+			}
+			settings.Git = &git
+		}
+		if req.OpenAPI != nil {
+			openapi := *req.OpenAPI
+			if openapi.Token == "" && source.Settings.OpenAPI != nil {
+				openapi.Token = source.Settings.OpenAPI.Token
+			}
+			settings.OpenAPI = &openapi
+		}
+		settings.Website = req.Website
+		settings.Feed = req.Feed
+		if _, err := checkImportSettings(ctx, source.Type, &settings); err != nil {
+			return err
+		}
+		updates["settings"] = settings
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+	return u.repo.UpdateImportSource(ctx, source.ID, updates)
+}
+
+// GetStalePages imported pages no longer in the source, with their nodes still in the kb
+func (u *ImportSourceUsecase) GetStalePages(ctx context.Context, req *domain.ImportSourceReq) ([]*domain.ImportSourceStalePage, error) {
+	source, err := u.repo.GetImportSource(ctx, req.KBID, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	return u.repo.GetStalePages(ctx, source.ID)
+}
+
+func (u *ImportSourceUsecase) DeleteSource(ctx context.Context, req *domain.ImportSourceReq) error {
+	return u.repo.DeleteImportSource(ctx, req.KBID, req.ID)
+}
+
+// SyncSource import pages changed since the last sync in background, removed pages are marked stale and their nodes are kept
+func (u *ImportSourceUsecase) SyncSource(ctx context.Context, req *domain.ImportSourceReq) (*domain.ImportSource, error) {
+	source, err := u.repo.GetImportSource(ctx, req.KBID, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	started, err := u.repo.StartSync(ctx, source.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !started {
+		return nil, domain.ErrImportSourceSyncing
+	}
+	go u.runSync(context.WithoutCancel(ctx), source)
+	source.Status = domain.ImportSourceStatusSyncing
+	source.Error = ""
+	source.Settings = source.Settings.Masked()
+	return source, nil
+}
+
+// SyncDueSources sync sources whose sync interval has passed one by one, sources syncing already are skipped
+func (u *ImportSourceUsecase) SyncDueSources(ctx context.Context) (domain.CronRunResult, error) {
+	sources, err := u.repo.GetDueImportSources(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get due import sources failed: %w", err)
+	}
+	var errs []error
+	synced := 0
+	for _, source := range sources {
+		started, err := u.repo.StartSync(ctx, source.ID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("source %s: %w", source.ID, err))
+			continue
+		}
+		if !started {
+			continue
+		}
+		if err := u.runSync(ctx, source); err != nil {
+			errs = append(errs, fmt.Errorf("source %s: %w", source.ID, err))
+			continue
+		}
+		synced++
+	}
+	result := domain.CronRunResult{
+		"due_count":    len(sources),
+		"synced_count": synced,
+		"failed_count": len(errs),
+	}
+	return result, errors.Join(errs...)
+}
+
+func (u *ImportSourceUsecase) runSync(ctx context.Context, source *domain.ImportSource) error {
+	ctx, cancel := context.WithTimeout(ctx, domain.ImportSourceSyncTimeout)
+	defer cancel()
+	result := &importSyncResult{}
+	pages, syncPage, err := u.sourcePages(ctx, source)
+	if err == nil {
+		result, err = u.syncPages(ctx, source, pages, syncPage)
+	}
+	now := time.Now()
+	updates := map[string]any{
+		"status":         domain.ImportSourceStatusSucceeded,
+		"page_count":     result.pageCount,
+		"changed_count":  len(result.changedNodeIDs),
+		"stale_count":    result.staleCount,
+		"last_synced_at": now,
+	}
+	if err != nil {
+		u.logger.Error("sync import source failed", log.String("source_id", source.ID), log.Error(err))
+		updates["status"] = domain.ImportSourceStatusFailed
+		updates["error"] = err.Error()
+	}
+	// the sync may have timed out
+	if err := u.repo.UpdateImportSource(context.WithoutCancel(ctx), source.ID, updates); err != nil {
+		u.logger.Error("update import source failed", log.String("source_id", source.ID), log.Error(err))
+	}
+	return err
+}
+
+// sourcePages pages of the source and the syncer importing their content
+func (u *ImportSourceUsecase) sourcePages(ctx context.Context, source *domain.ImportSource) ([]*importPage, pageSyncer, error) {
+	switch source.Type {
+	case domain.ImportSourceTypeNotion:
+		return u.notionPages(ctx, source)
+	case domain.ImportSourceTypeFeishu:
+		return u.feishuPages(ctx, source)
+	case domain.ImportSourceTypeYuque:
+		return u.yuquePages(ctx, source)
+	case domain.ImportSourceTypeGit:
+		return u.gitPages(ctx, source)
+	case domain.ImportSourceTypeOpenAPI:
+		return u.openapiPages(ctx, source)
+	case domain.ImportSourceTypeWebsite:
+		return u.websitePages(ctx, source)
+	case domain.ImportSourceTypeFeed:
+		return u.feedPages(ctx, source)
+	default:
+		return u.confluencePages(ctx, source)
+	}
+}
+
+type importSyncResult struct {
+	pageCount      int
+	changedNodeIDs []string
+	staleCount     int
+}
+
+// importPage page of a source, imported as a node under the node of its parent page
+type importPage struct {
+	ID       string
+	ParentID string
+	Title    string
+	Version  string
+	// pages without content, like notion databases
+	Folder bool
+}
+
+// pageSyncer import content of a new or changed page into its node and save its item with the version
+type pageSyncer func(page *importPage, nodeIDs map[string]string, attachmentItems map[string]*domain.ImportSourceItem) error
+
+// syncPages create nodes of new pages, then sync content of new and changed pages.
+// pages and attachments are saved one by one, so a failed sync resumes where it stopped
+func (u *ImportSourceUsecase) syncPages(ctx context.Context, source *domain.ImportSource, pages []*importPage, syncPage pageSyncer) (*importSyncResult, error) {
+	result := &importSyncResult{pageCount: len(pages)}
+	items, err := u.repo.GetItems(ctx, source.ID)
+	if err != nil {
+		return result, err
+	}
+	pageItems := make(map[string]*domain.ImportSourceItem)
+	attachmentItems := make(map[string]*domain.ImportSourceItem)
+	for _, item := range items {
+		if item.Kind == domain.ImportSourceItemKindPage {
+			pageItems[item.ExternalID] = item
+		} else {
+			attachmentItems[item.ExternalID] = item
+		}
+	}
+	existing, err := u.existingNodeIDs(ctx, source.KBID)
+	if err != nil {
+		return result, err
+	}
+
+	nodeIDs := make(map[string]string, len(pages))
+	changed := make([]*importPage, 0)
+	for _, page := range sortImportPages(pages) {
+		item := pageItems[page.ID]
+		// pages whose node was deleted in the kb are imported again
+		if item != nil && existing[item.NodeID] {
+			nodeIDs[page.ID] = item.NodeID
+		} else {
+			parentID := source.ParentID
+			if id, ok := nodeIDs[page.ParentID]; ok {
+				parentID = id
+			}
+			nodeType := domain.NodeTypeDocument
+			if page.Folder {
+				nodeType = domain.NodeTypeFolder
+			}
+			id, err := u.nodeUsecase.Create(ctx, &domain.CreateNodeReq{
+				KBID:     source.KBID,
+				ParentID: parentID,
+				Type:     nodeType,
+				Name:     page.Title,
+			})
+			if err != nil {
+				return result, fmt.Errorf("create node of page %s failed: %w", page.ID, err)
+			}
+			nodeIDs[page.ID] = id
+			// saved without version, so the page is not created twice if the sync stops before its content
+			item = &domain.ImportSourceItem{
+				SourceID:   source.ID,
+				Kind:       domain.ImportSourceItemKindPage,
+				ExternalID: page.ID,
+				NodeID:     id,
+			}
+			if err := u.repo.SaveItem(ctx, item); err != nil {
+				return result, err
+			}
+		}
+		if item.Version != page.Version {
+			changed = append(changed, page)
+		}
+	}
+
+	var syncErr error
+	for _, page := range changed {
+		if err := syncPage(page, nodeIDs, attachmentItems); err != nil {
+			syncErr = fmt.Errorf("sync page %s failed: %w", page.ID, err)
+			break
+		}
+		result.changedNodeIDs = append(result.changedNodeIDs, nodeIDs[page.ID])
+	}
+	// synced pages are published even if the sync stopped, the next sync does not see them as changed
+	if err := u.publishSyncedNodes(ctx, source, result.changedNodeIDs); err != nil {
+		return result, err
+	}
+	if syncErr != nil {
+		return result, syncErr
+	}
+
+	// removed pages are kept stale, they are not stale again if they come back
+	if !source.Type.ListsRecentPages() {
+		staleCount, err := u.repo.MarkStaleItems(ctx, source.ID, lo.Keys(nodeIDs))
+		if err != nil {
+			return result, err
+		}
+		result.staleCount = int(staleCount)
+	}
+	u.logger.Info("sync import source", log.String("source_id", source.ID), log.Int("pages", len(pages)),
+		log.Int("changed", len(result.changedNodeIDs)), log.Int("stale", result.staleCount))
+	return result, nil
+}
+
+// existingNodeIDs nodes of the kb, pages whose node was deleted are imported again
+func (u *ImportSourceUsecase) existingNodeIDs(ctx context.Context, kbID string) (map[string]bool, error) {
+	nodes, err := u.nodeUsecase.GetList(ctx, &domain.GetNodeListReq{KBID: kbID})
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		existing[node.ID] = true
+	}
+	return existing, nil
+}
+
+// PreviewSource pages the next sync of a saved source, or the first sync of new settings, would create or update.
+// only the source is read, nothing is imported
+func (u *ImportSourceUsecase) PreviewSource(ctx context.Context, req *domain.PreviewImportSourceReq) (*domain.ImportSourcePreview, error) {
+	preview := &domain.ImportSourcePreview{Pages: make([]*domain.ImportSourcePreviewPage, 0)}
+	source := &domain.ImportSource{KBID: req.KBID, Type: req.Type}
+	pageItems := make(map[string]*domain.ImportSourceItem)
+	existing := make(map[string]bool)
+	if req.ID != "" {
+		var err error
+		if source, err = u.repo.GetImportSource(ctx, req.KBID, req.ID); err != nil {
+			return nil, err
+		}
+		items, err := u.repo.GetItems(ctx, source.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			if item.Kind == domain.ImportSourceItemKindPage {
+				pageItems[item.ExternalID] = item
+			}
+		}
+		if existing, err = u.existingNodeIDs(ctx, source.KBID); err != nil {
+			return nil, err
+		}
+		preview.Name = source.Name
+	} else {
+		source.Settings = domain.ImportSourceSettings{Confluence: req.Confluence, Notion: req.Notion, Feishu: req.Feishu, Yuque: req.Yuque, Git: req.Git, OpenAPI: req.OpenAPI, Website: req.Website, Feed: req.Feed}
+		name, err := checkImportSettings(ctx, source.Type, &source.Settings)
+		if err != nil {
+			return nil, err
+		}
+		preview.Name = name
+	}
+	pages, _, err := u.sourcePages(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	listed := make(map[string]bool, len(pages))
+	for _, page := range sortImportPages(pages) {
+		listed[page.ID] = true
+		action := domain.ImportPreviewActionCreate
+		if item := pageItems[page.ID]; item != nil && existing[item.NodeID] {
+			action = domain.ImportPreviewActionUnchanged
+			if item.Version != page.Version {
+				action = domain.ImportPreviewActionUpdate
+			}
+		}
+		switch action {
+		case domain.ImportPreviewActionCreate:
+			preview.CreateCount++
+		case domain.ImportPreviewActionUpdate:
+			preview.UpdateCount++
+		}
+		preview.Pages = append(preview.Pages, &domain.ImportSourcePreviewPage{
+			ID:       page.ID,
+			ParentID: page.ParentID,
+			Title:    page.Title,
+			Folder:   page.Folder,
+			Action:   action,
+		})
+	}
+	for id := range pageItems {
+		if !listed[id] && !source.Type.ListsRecentPages() {
+			preview.RemovedCount++
+		}
+	}
+	return preview, nil
+}
+
+// savePageItem save the page as synced at the version
+func (u *ImportSourceUsecase) savePageItem(ctx context.Context, source *domain.ImportSource, page *importPage, nodeID string) error {
+	return u.repo.SaveItem(ctx, &domain.ImportSourceItem{
+		SourceID:   source.ID,
+		Kind:       domain.ImportSourceItemKindPage,
+		ExternalID: page.ID,
+		NodeID:     nodeID,
+		Version:    page.Version,
+	})
+}
+
+func (u *ImportSourceUsecase) confluencePages(ctx context.Context, source *domain.ImportSource) ([]*importPage, pageSyncer, error) {
+	settings := source.Settings.Confluence
+	if settings == nil {
+		return nil, nil, errors.New("confluence settings are required")
+	}
+	client := confluenceClient(settings)
+	pages, err := client.ListPages(ctx, settings.SpaceKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	importPages := make([]*importPage, 0, len(pages))
+	for _, page := range pages {
+		importPages = append(importPages, &importPage{
+			ID:       page.ID,
+			ParentID: page.ParentID,
+			Title:    page.Title,
+			Version:  strconv.Itoa(page.Version),
+		})
+	}
+	// links in storage format target pages by title, resolved once all nodes are created
+	var titles map[string]string
+	return importPages, func(page *importPage, nodeIDs map[string]string, attachmentItems map[string]*domain.ImportSourceItem) error {
+		if titles == nil {
+			titles = make(map[string]string, len(importPages))
+			for _, p := range importPages {
+				titles[p.Title] = nodeIDs[p.ID]
+			}
+		}
+		return u.syncConfluencePage(ctx, client, source, page, nodeIDs[page.ID], titles, attachmentItems)
+	}, nil
+}
+
+func (u *ImportSourceUsecase) publishSyncedNodes(ctx context.Context, source *domain.ImportSource, nodeIDs []string) error {
+	if len(nodeIDs) == 0 {
+		return nil
+	}
+	_, err := u.kbUsecase.PublishNodes(ctx, &domain.PublishNodeReq{
+		KBID:    source.KBID,
+		NodeIDs: nodeIDs,
+		Message: fmt.Sprintf("同步 %s", source.Name),
+	})
+	if errors.Is(err, domain.ErrNodeReviewNotApproved) {
+		u.logger.Info("synced pages are kept as drafts until approved", log.String("source_id", source.ID))
+		return nil
+	}
+	return err
+}
+
+func (u *ImportSourceUsecase) syncConfluencePage(ctx context.Context, client *confluence.Client, source *domain.ImportSource, page *importPage, nodeID string, titles map[string]string, attachmentItems map[string]*domain.ImportSourceItem) error {
+	body, err := client.GetPageBody(ctx, page.ID)
+	if err != nil {
+		return err
+	}
+	attachments, err := client.ListAttachments(ctx, page.ID)
+	if err != nil {
+		return err
+	}
+	images := make(map[string]string)
+	for _, attachment := range attachments {
+		item := attachmentItems[attachment.ID]
+		version := strconv.Itoa(attachment.Version)
+		if item != nil && item.NodeID == nodeID && item.Version == version {
+			if isImageMediaType(attachment.MediaType) {
+				images[attachment.Title] = item.Ref
+			}
+			continue
+		}
+		if attachment.Size > u.config.S3.MaxFileSize {
+			u.logger.Warn("skip large confluence attachment", log.String("page_id", page.ID), log.String("attachment", attachment.Title))
+			continue
+		}
+		ref, err := u.importConfluenceAttachment(ctx, client, source.KBID, nodeID, attachment, item)
+		if err != nil {
+			return fmt.Errorf("import attachment %s failed: %w", attachment.Title, err)
+		}
+		if isImageMediaType(attachment.MediaType) {
+			images[attachment.Title] = ref
+		}
+		if err := u.repo.SaveItem(ctx, &domain.ImportSourceItem{
+			SourceID:   source.ID,
+			Kind:       domain.ImportSourceItemKindAttachment,
+			ExternalID: attachment.ID,
+			NodeID:     nodeID,
+			Version:    version,
+			Ref:        ref,
+		}); err != nil {
+			return err
+		}
+	}
+
+	content, err := confluence.ConvertStorage(body, &confluenceResolver{
+		spaceKey: source.Settings.Confluence.SpaceKey,
+		titles:   titles,
+		images:   images,
+	})
+	if err != nil {
+		return err
+	}
+	if err := u.nodeUsecase.Update(ctx, &domain.UpdateNodeReq{
+		ID:      nodeID,
+		KBID:    source.KBID,
+		Name:    &page.Title,
+		Content: &content,
+	}); err != nil {
+		return err
+	}
+	return u.savePageItem(ctx, source, page, nodeID)
+}
+
+func (u *ImportSourceUsecase) importConfluenceAttachment(ctx context.Context, client *confluence.Client, kbID, nodeID string, attachment *confluence.Attachment, previous *domain.ImportSourceItem) (string, error) {
+	reader, size, err := client.Download(ctx, attachment)
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+	if size < 0 {
+		size = attachment.Size
+	}
+	return u.importFile(ctx, kbID, nodeID, attachment.Title, attachment.MediaType, reader, size, previous)
+}
+
+// importFile upload images as static files referenced by content, other files as node attachments replacing their previous version
+func (u *ImportSourceUsecase) importFile(ctx context.Context, kbID, nodeID, filename, mediaType string, reader io.Reader, size int64, previous *domain.ImportSourceItem) (string, error) {
+	if isImageMediaType(mediaType) {
+		ext := strings.ToLower(path.Ext(filename))
+		key := fmt.Sprintf("%s/%s%s", kbID, uuid.New().String(), ext)
+		if _, err := u.s3Client.PutObject(ctx, domain.Bucket, key, reader, size, minio.PutObjectOptions{
+			ContentType: mediaType,
+			UserMetadata: map[string]string{
+				"originalname": filename,
+			},
+		}); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("/%s/%s", domain.Bucket, key), nil
+	}
+	if previous != nil && previous.Ref != "" && !strings.HasPrefix(previous.Ref, "/") {
+		if err := u.attachmentUsecase.DeleteNodeAttachment(ctx, &domain.DeleteNodeAttachmentReq{KBID: kbID, ID: previous.Ref}); err != nil {
+			u.logger.Warn("delete previous attachment version failed", log.String("attachment_id", previous.Ref), log.Error(err))
+		}
+	}
+	nodeAttachment, err := u.attachmentUsecase.AddNodeAttachment(ctx, kbID, nodeID, filename, reader, size, mediaType)
+	if err != nil {
+		return "", err
+	}
+	return nodeAttachment.ID, nil
+}
+
+// checkImportSettings check credentials of the source and return its default name, notion page and yuque repo urls and git paths are normalized
+func checkImportSettings(ctx context.Context, sourceType domain.ImportSourceType, settings *domain.ImportSourceSettings) (string, error) {
+	switch sourceType {
+	case domain.ImportSourceTypeConfluence:
+		if settings.Confluence == nil {
+			return "", errors.New("confluence settings are required")
+		}
+		space, err := confluenceClient(settings.Confluence).GetSpace(ctx, settings.Confluence.SpaceKey)
+		if err != nil {
+			return "", fmt.Errorf("get confluence space failed: %w", err)
+		}
+		return space.Name, nil
+	case domain.ImportSourceTypeNotion:
+		if settings.Notion == nil || len(settings.Notion.PageIDs) == 0 {
+			return "", errors.New("notion settings are required")
+		}
+		client := notion.NewClient(settings.Notion.Token)
+		name := ""
+		ids := make([]string, 0, len(settings.Notion.PageIDs))
+		for _, raw := range settings.Notion.PageIDs {
+			id := notion.ParseID(raw)
+			if id == "" {
+				return "", fmt.Errorf("invalid notion page id: %s", raw)
+			}
+			page, err := client.GetPage(ctx, id)
+			if err != nil {
+				return "", fmt.Errorf("get notion page %s failed: %w", raw, err)
+			}
+			if name == "" {
+				name = page.Title
+			}
+			ids = append(ids, id)
+		}
+		settings.Notion.PageIDs = lo.Uniq(ids)
+		return name, nil
+	case domain.ImportSourceTypeFeishu:
+		if settings.Feishu == nil || (settings.Feishu.SpaceID == "" && settings.Feishu.FolderToken == "") {
+			return "", errors.New("feishu settings are required")
+		}
+		client := feishudoc.NewClient(settings.Feishu.AppID, settings.Feishu.AppSecret)
+		if settings.Feishu.SpaceID != "" {
+			name, err := client.GetSpaceName(ctx, settings.Feishu.SpaceID)
+			if err != nil {
+				return "", fmt.Errorf("get feishu wiki space failed: %w", err)
+			}
+			return name, nil
+		}
+		if err := client.CheckFolder(ctx, settings.Feishu.FolderToken); err != nil {
+			return "", fmt.Errorf("get feishu folder failed: %w", err)
+		}
+		return "飞书云文档", nil
+	case domain.ImportSourceTypeYuque:
+		if settings.Yuque == nil {
+			return "", errors.New("yuque settings are required")
+		}
+		namespace := yuque.ParseNamespace(settings.Yuque.Namespace)
+		if namespace == "" {
+			return "", fmt.Errorf("invalid yuque repo: %s", settings.Yuque.Namespace)
+		}
+		repo, err := yuqueClient(settings.Yuque).GetRepo(ctx, namespace)
+		if err != nil {
+			return "", fmt.Errorf("get yuque repo failed: %w", err)
+		}
+		settings.Yuque.Namespace = namespace
+		return repo.Name, nil
+	case domain.ImportSourceTypeGit:
+		if settings.Git == nil {
+			return "", errors.New("git settings are required")
+		}
+		settings.Git.Path = gitDocsPath(settings.Git.Path)
+		if err := gitrepo.CheckBranch(ctx, settings.Git.URL, settings.Git.Branch, settings.Git.Token); err != nil {
+			return "", fmt.Errorf("check git repo failed: %w", err)
+		}
+		return gitrepo.Name(settings.Git.URL), nil
+	case domain.ImportSourceTypeOpenAPI:
+		if settings.OpenAPI == nil {
+			return "", errors.New("openapi settings are required")
+		}
+		data, err := openapidoc.Fetch(ctx, settings.OpenAPI.URL, settings.OpenAPI.Token)
+		if err != nil {
+			return "", fmt.Errorf("fetch openapi spec failed: %w", err)
+		}
+		doc, err := openapidoc.Load(data)
+		if err != nil {
+			return "", err
+		}
+		if doc.Info != nil && doc.Info.Title != "" {
+			return doc.Info.Title, nil
+		}
+		return "OpenAPI", nil
+	case domain.ImportSourceTypeWebsite:
+		if settings.Website == nil {
+			return "", errors.New("website settings are required")
+		}
+		for _, patterns := range [][]string{settings.Website.Include, settings.Website.Exclude} {
+			if _, err := website.CompilePatterns(patterns); err != nil {
+				return "", err
+			}
+		}
+		if err := website.Check(ctx, settings.Website.URL); err != nil {
+			return "", fmt.Errorf("fetch website failed: %w", err)
+		}
+		return website.Name(settings.Website.URL), nil
+	case domain.ImportSourceTypeFeed:
+		if settings.Feed == nil {
+			return "", errors.New("feed settings are required")
+		}
+		feed, err := utils.ParseFeed(settings.Feed.URL)
+		if err != nil {
+			return "", fmt.Errorf("parse feed failed: %w", err)
+		}
+		if feed.Title != "" {
+			return feed.Title, nil
+		}
+		return website.Name(settings.Feed.URL), nil
+	}
+	return "", fmt.Errorf("unsupported import source type: %s", sourceType)
+}
+
+func confluenceClient(settings *domain.ConfluenceSettings) *confluence.Client {
+	return confluence.NewClient(settings.BaseURL, settings.Username, settings.Token)
+}
+
+func isImageMediaType(mediaType string) bool {
+	return strings.HasPrefix(mediaType, "image/")
+}
+
+// sortImportPages parents before their children, pages of the same depth keep their order
+func sortImportPages(pages []*importPage) []*importPage {
+	byID := make(map[string]*importPage, len(pages))
+	for _, page := range pages {
+		byID[page.ID] = page
+	}
+	depths := make(map[string]int, len(pages))
+	var depth func(page *importPage, seen int) int
+	depth = func(page *importPage, seen int) int {
+		if d, ok := depths[page.ID]; ok {
+			return d
+		}
+		d := 0
+		if parent, ok := byID[page.ParentID]; ok && seen < len(pages) {
+			d = depth(parent, seen+1) + 1
+		}
+		depths[page.ID] = d
+		return d
+	}
+	sorted := make([]*importPage, len(pages))
+	copy(sorted, pages)
+	for _, page := range sorted {
+		depth(page, 0)
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return depths[sorted[i].ID] < depths[sorted[j].ID]
+	})
+	return sorted
+}
+
+// confluenceResolver links to pages of the space point at their nodes, images at the uploaded files
+type confluenceResolver struct {
+	spaceKey string
+	titles   map[string]string
+	images   map[string]string
+}
+
+func (r *confluenceResolver) PageURL(spaceKey, title string) string {
+	if spaceKey != "" && !strings.EqualFold(spaceKey, r.spaceKey) {
+		return ""
+	}
+	if nodeID, ok := r.titles[title]; ok {
+		return "/node/" + nodeID
+	}
+	return ""
+}
+
+func (r *confluenceResolver) AttachmentURL(filename string) string {
+	return r.images[filename]
+}
+
+func (u *ImportSourceUsecase) notionPages(ctx context.Context, source *domain.ImportSource) ([]*importPage, pageSyncer, error) {
+	settings := source.Settings.Notion
+	if settings == nil {
+		return nil, nil, errors.New("notion settings are required")
+	}
+	client := notion.NewClient(settings.Token)
+	pages, err := client.ListPages(ctx, settings.PageIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	importPages := make([]*importPage, 0, len(pages))
+	for _, page := range pages {
+		title := page.Title
+		if title == "" {
+			title = "未命名"
+		}
+		importPages = append(importPages, &importPage{
+			ID:       page.ID,
+			ParentID: page.ParentID,
+			Title:    title,
+			Version:  notionVersion(page.LastEditedTime),
+			Folder:   page.Database,
+		})
+	}
+	return importPages, func(page *importPage, nodeIDs map[string]string, attachmentItems map[string]*domain.ImportSourceItem) error {
+		return u.syncNotionPage(ctx, client, source, page, nodeIDs, attachmentItems)
+	}, nil
+}
+
+// syncNotionPage import blocks of the page and the files hosted by notion, databases only have their name
+func (u *ImportSourceUsecase) syncNotionPage(ctx context.Context, client *notion.Client, source *domain.ImportSource, page *importPage, nodeIDs map[string]string, attachmentItems map[string]*domain.ImportSourceItem) error {
+	nodeID := nodeIDs[page.ID]
+	req := &domain.UpdateNodeReq{
+		ID:   nodeID,
+		KBID: source.KBID,
+		Name: &page.Title,
+	}
+	if !page.Folder {
+		blocks, err := client.GetBlocks(ctx, page.ID)
+		if err != nil {
+			return err
+		}
+		files := make(map[string]string)
+		var importFiles func(blocks []*notion.Block) error
+		importFiles = func(blocks []*notion.Block) error {
+			for _, block := range blocks {
+				if block.HostedFileURL() != "" {
+					ref, err := u.importNotionFile(ctx, client, source, nodeID, block, attachmentItems[block.ID])
+					if err != nil {
+						return fmt.Errorf("import file of block %s failed: %w", block.ID, err)
+					}
+					files[block.ID] = ref
+				}
+				if err := importFiles(block.Children); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if err := importFiles(blocks); err != nil {
+			return err
+		}
+		content := notion.Render(blocks, &notionResolver{nodeIDs: nodeIDs, files: files})
+		req.Content = &content
+	}
+	if err := u.nodeUsecase.Update(ctx, req); err != nil {
+		return err
+	}
+	return u.savePageItem(ctx, source, page, nodeID)
+}
+
+// importNotionFile download a file before its signed url expires, unchanged blocks keep the imported file
+func (u *ImportSourceUsecase) importNotionFile(ctx context.Context, client *notion.Client, source *domain.ImportSource, nodeID string, block *notion.Block, previous *domain.ImportSourceItem) (string, error) {
+	version := notionVersion(block.LastEditedTime)
+	if previous != nil && previous.NodeID == nodeID && previous.Version == version {
+		return previous.Ref, nil
+	}
+	reader, size, mediaType, err := client.Download(ctx, block.HostedFileURL())
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+	if size > u.config.S3.MaxFileSize {
+		u.logger.Warn("skip large notion file", log.String("block_id", block.ID), log.Int64("size", size))
+		return "", nil
+	}
+	var body io.Reader = reader
+	if size < 0 {
+		data, err := io.ReadAll(io.LimitReader(reader, u.config.S3.MaxFileSize+1))
+		if err != nil {
+			return "", err
+		}
+		if int64(len(data)) > u.config.S3.MaxFileSize {
+			u.logger.Warn("skip large notion file", log.String("block_id", block.ID))
+			return "", nil
+		}
+		body, size = bytes.NewReader(data), int64(len(data))
+	}
+	filename := block.FileName()
+	mediaType, _, _ = strings.Cut(mediaType, ";")
+	// images are served from s3 with a generic type
+	if block.Type == "image" && !isImageMediaType(mediaType) {
+		if t := mime.TypeByExtension(strings.ToLower(path.Ext(filename))); isImageMediaType(t) {
+			mediaType = t
+		}
+	}
+	ref, err := u.importFile(ctx, source.KBID, nodeID, filename, mediaType, body, size, previous)
+	if err != nil {
+		return "", err
+	}
+	if err := u.repo.SaveItem(ctx, &domain.ImportSourceItem{
+		SourceID:   source.ID,
+		Kind:       domain.ImportSourceItemKindAttachment,
+		ExternalID: block.ID,
+		NodeID:     nodeID,
+		Version:    version,
+		Ref:        ref,
+	}); err != nil {
+		return "", err
+	}
+	return ref, nil
+}
+
+func notionVersion(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// notionResolver links to imported pages point at their nodes, hosted images at the uploaded files
+type notionResolver struct {
+	nodeIDs map[string]string
+	files   map[string]string
+}
+
+func (r *notionResolver) PageURL(id string) string {
+	if nodeID, ok := r.nodeIDs[id]; ok {
+		return "/node/" + nodeID
+	}
+	return ""
+}
+
+func (r *notionResolver) FileURL(blockID string) string {
+	// other files are node attachments
+	if ref := r.files[blockID]; strings.HasPrefix(ref, "/") {
+		return ref
+	}
+	return ""
+}
+
+// feishu docs and wiki nodes linked from documents, by the token at the end of their url
+var feishuDocLinkRegex = regexp.MustCompile(`(?:feishu\.cn|larksuite\.com|larkoffice\.com)/(?:wiki|docx)/([A-Za-z0-9]+)`)
+
+func (u *ImportSourceUsecase) feishuPages(ctx context.Context, source *domain.ImportSource) ([]*importPage, pageSyncer, error) {
+	settings := source.Settings.Feishu
+	if settings == nil {
+		return nil, nil, errors.New("feishu settings are required")
+	}
+	client := feishudoc.NewClient(settings.AppID, settings.AppSecret)
+	var pages []*feishudoc.Page
+	var err error
+	if settings.SpaceID != "" {
+		pages, err = client.ListWikiPages(ctx, settings.SpaceID)
+	} else {
+		pages, err = client.ListFolderPages(ctx, settings.FolderToken)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	parents := make(map[string]bool, len(pages))
+	for _, page := range pages {
+		parents[page.ParentID] = true
+	}
+	importPages := make([]*importPage, 0, len(pages))
+	docTokens := make(map[string]string, len(pages))
+	for _, page := range pages {
+		folder := page.DocType != feishudoc.DocTypeDocx
+		// sheets, bitables and files have no markdown, only those with children are kept as folders
+		if folder && page.DocType != feishudoc.DocTypeFolder && !parents[page.ID] {
+			continue
+		}
+		title := page.Title
+		if title == "" {
+			title = "未命名"
+		}
+		importPages = append(importPages, &importPage{
+			ID:       page.ID,
+			ParentID: page.ParentID,
+			Title:    title,
+			Version:  page.Version,
+			Folder:   folder,
+		})
+		if !folder {
+			docTokens[page.ID] = page.DocToken
+		}
+	}
+	return importPages, func(page *importPage, nodeIDs map[string]string, attachmentItems map[string]*domain.ImportSourceItem) error {
+		return u.syncFeishuPage(ctx, client, source, page, docTokens, nodeIDs, attachmentItems)
+	}, nil
+}
+
+// syncFeishuPage import markdown of the document and its images, folders only have their name
+func (u *ImportSourceUsecase) syncFeishuPage(ctx context.Context, client *feishudoc.Client, source *domain.ImportSource, page *importPage, docTokens, nodeIDs map[string]string, attachmentItems map[string]*domain.ImportSourceItem) error {
+	nodeID := nodeIDs[page.ID]
+	req := &domain.UpdateNodeReq{
+		ID:   nodeID,
+		KBID: source.KBID,
+		Name: &page.Title,
+	}
+	if !page.Folder {
+		content, imgTokens, err := client.GetDocument(ctx, docTokens[page.ID])
+		if err != nil {
+			return err
+		}
+		images := make(map[string]string, len(imgTokens))
+		for _, token := range imgTokens {
+			ref, err := u.importFeishuImage(ctx, client, source, nodeID, token, attachmentItems[token])
+			if err != nil {
+				return fmt.Errorf("import image %s failed: %w", token, err)
+			}
+			if ref != "" {
+				images[token] = ref
+			}
+		}
+		content = rewriteFeishuLinks(content, images, docTokens, nodeIDs)
+		req.Content = &content
+	}
+	if err := u.nodeUsecase.Update(ctx, req); err != nil {
+		return err
+	}
+	return u.savePageItem(ctx, source, page, nodeID)
+}
+
+// importFeishuImage upload the image once, tokens of feishu images never change content
+func (u *ImportSourceUsecase) importFeishuImage(ctx context.Context, client *feishudoc.Client, source *domain.ImportSource, nodeID, token string, previous *domain.ImportSourceItem) (string, error) {
+	if previous != nil && previous.Ref != "" {
+		return previous.Ref, nil
+	}
+	filename, data, err := client.DownloadImage(ctx, token)
+	if err != nil {
+		return "", err
+	}
+	if int64(len(data)) > u.config.S3.MaxFileSize {
+		u.logger.Warn("skip large feishu image", log.String("token", token), log.Int("size", len(data)))
+		return "", nil
+	}
+	mediaType := mime.TypeByExtension(strings.ToLower(path.Ext(filename)))
+	if !isImageMediaType(mediaType) {
+		mediaType = "image/png"
+	}
+	ref, err := u.importFile(ctx, source.KBID, nodeID, filename, mediaType, bytes.NewReader(data), int64(len(data)), previous)
+	if err != nil {
+		return "", err
+	}
+	if err := u.repo.SaveItem(ctx, &domain.ImportSourceItem{
+		SourceID:   source.ID,
+		Kind:       domain.ImportSourceItemKindAttachment,
+		ExternalID: token,
+		NodeID:     nodeID,
+		Version:    token,
+		Ref:        ref,
+	}); err != nil {
+		return "", err
+	}
+	return ref, nil
+}
+
+// rewriteFeishuLinks point images at the uploaded files and links to imported documents at their nodes
+func rewriteFeishuLinks(content string, images, docTokens, nodeIDs map[string]string) string {
+	nodeByToken := make(map[string]string, len(nodeIDs)+len(docTokens))
+	for id, nodeID := range nodeIDs {
+		nodeByToken[id] = nodeID
+	}
+	// docx urls use the token of the document instead of the wiki node
+	for id, docToken := range docTokens {
+		if nodeID, ok := nodeIDs[id]; ok {
+			nodeByToken[docToken] = nodeID
+		}
+	}
+	rewrite := func(re *regexp.Regexp) {
+		content = re.ReplaceAllStringFunc(content, func(match string) string {
+			parts := re.FindStringSubmatch(match)
+			if strings.HasPrefix(parts[1], "!") {
+				if ref, ok := images[parts[2]]; ok {
+					return parts[1] + ref + parts[3]
+				}
+				return match
+			}
+			m := feishuDocLinkRegex.FindStringSubmatch(parts[2])
+			if m == nil {
+				return match
+			}
+			nodeID, ok := nodeByToken[m[1]]
+			if !ok {
+				return match
+			}
+			return parts[1] + "/node/" + nodeID + parts[3]
+		})
+	}
+	rewrite(markdownLinkRegex)
+	rewrite(htmlHrefRegex)
+	return content
+}
+
+func yuqueClient(settings *domain.YuqueSettings) *yuque.Client {
+	return yuque.NewClient(settings.BaseURL, settings.Token)
+}
+
+// yuquePages documents and titles of the toc, titles are folders and links are skipped
+func (u *ImportSourceUsecase) yuquePages(ctx context.Context, source *domain.ImportSource) ([]*importPage, pageSyncer, error) {
+	settings := source.Settings.Yuque
+	if settings == nil {
+		return nil, nil, errors.New("yuque settings are required")
+	}
+	client := yuqueClient(settings)
+	toc, err := client.GetToc(ctx, settings.Namespace)
+	if err != nil {
+		return nil, nil, err
+	}
+	// the toc has no edit time of documents
+	docs, err := client.ListDocs(ctx, settings.Namespace)
+	if err != nil {
+		return nil, nil, err
+	}
+	versions := make(map[string]string, len(docs))
+	for _, doc := range docs {
+		updatedAt := doc.ContentUpdatedAt
+		if updatedAt.IsZero() {
+			updatedAt = doc.UpdatedAt
+		}
+		versions[doc.Slug] = updatedAt.UTC().Format(time.RFC3339Nano)
+	}
+	importPages := make([]*importPage, 0, len(toc))
+	slugs := make(map[string]string, len(toc))
+	pageBySlug := make(map[string]string, len(toc))
+	for _, item := range toc {
+		if item.Type != yuque.TocTypeDoc && item.Type != yuque.TocTypeTitle {
+			continue
+		}
+		title := item.Title
+		if title == "" {
+			title = "未命名"
+		}
+		page := &importPage{
+			ID:       item.UUID,
+			ParentID: item.ParentUUID,
+			Title:    title,
+			Folder:   item.Type == yuque.TocTypeTitle,
+		}
+		if !page.Folder {
+			page.Version = versions[item.URL]
+			slugs[page.ID] = item.URL
+			pageBySlug[item.URL] = page.ID
+		}
+		importPages = append(importPages, page)
+	}
+	return importPages, func(page *importPage, nodeIDs map[string]string, attachmentItems map[string]*domain.ImportSourceItem) error {
+		return u.syncYuquePage(ctx, client, source, page, slugs[page.ID], &yuqueResolver{
+			namespace:  settings.Namespace,
+			pageBySlug: pageBySlug,
+			nodeIDs:    nodeIDs,
+		}, attachmentItems)
+	}, nil
+}
+
+// syncYuquePage import markdown of the document and its images, titles only have their name
+func (u *ImportSourceUsecase) syncYuquePage(ctx context.Context, client *yuque.Client, source *domain.ImportSource, page *importPage, slug string, resolver *yuqueResolver, attachmentItems map[string]*domain.ImportSourceItem) error {
+	nodeID := resolver.nodeIDs[page.ID]
+	req := &domain.UpdateNodeReq{
+		ID:   nodeID,
+		KBID: source.KBID,
+		Name: &page.Title,
+	}
+	if !page.Folder {
+		doc, err := client.GetDoc(ctx, source.Settings.Yuque.Namespace, slug)
+		if err != nil {
+			return err
+		}
+		resolver.images = make(map[string]string)
+		for _, src := range lo.Uniq(yuque.ImageURLs(doc.Body)) {
+			ref, err := u.importYuqueImage(ctx, client, source, nodeID, src, attachmentItems[src])
+			if err != nil {
+				return fmt.Errorf("import image %s failed: %w", src, err)
+			}
+			if ref != "" {
+				resolver.images[src] = ref
+			}
+		}
+		content := yuque.ConvertMarkdown(doc.Body, resolver)
+		req.Content = &content
+	}
+	if err := u.nodeUsecase.Update(ctx, req); err != nil {
+		return err
+	}
+	return u.savePageItem(ctx, source, page, nodeID)
+}
+
+// importYuqueImage upload the image once, urls of the yuque cdn never change content
+func (u *ImportSourceUsecase) importYuqueImage(ctx context.Context, client *yuque.Client, source *domain.ImportSource, nodeID, src string, previous *domain.ImportSourceItem) (string, error) {
+	if previous != nil && previous.Ref != "" {
+		return previous.Ref, nil
+	}
+	if !strings.HasPrefix(src, "http://") && !strings.HasPrefix(src, "https://") {
+		return "", nil
+	}
+	reader, size, mediaType, err := client.Download(ctx, src)
+	if err != nil {
+		// images hosted elsewhere are kept as links
+		u.logger.Warn("download yuque image failed", log.String("url", src), log.Error(err))
+		return "", nil
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(io.LimitReader(reader, u.config.S3.MaxFileSize+1))
+	if err != nil {
+		return "", err
+	}
+	if int64(len(data)) > u.config.S3.MaxFileSize {
+		u.logger.Warn("skip large yuque image", log.String("url", src), log.Int64("size", size))
+		return "", nil
+	}
+	filename, _, _ := strings.Cut(path.Base(src), "?")
+	mediaType, _, _ = strings.Cut(mediaType, ";")
+	if !isImageMediaType(mediaType) {
+		mediaType = mime.TypeByExtension(strings.ToLower(path.Ext(filename)))
+	}
+	if !isImageMediaType(mediaType) {
+		mediaType = "image/png"
+	}
+	ref, err := u.importFile(ctx, source.KBID, nodeID, filename, mediaType, bytes.NewReader(data), int64(len(data)), previous)
+	if err != nil {
+		return "", err
+	}
+	if err := u.repo.SaveItem(ctx, &domain.ImportSourceItem{
+		SourceID:   source.ID,
+		Kind:       domain.ImportSourceItemKindAttachment,
+		ExternalID: src,
+		NodeID:     nodeID,
+		Version:    src,
+		Ref:        ref,
+	}); err != nil {
+		return "", err
+	}
+	return ref, nil
+}
+
+// yuqueResolver links to documents of the repo point at their nodes, images at the uploaded files
+type yuqueResolver struct {
+	namespace  string
+	pageBySlug map[string]string
+	nodeIDs    map[string]string
+	images     map[string]string
+}
+
+func (r *yuqueResolver) DocURL(namespace, slug string) string {
+	if namespace != r.namespace {
+		return ""
+	}
+	if nodeID, ok := r.nodeIDs[r.pageBySlug[slug]]; ok {
+		return "/node/" + nodeID
+	}
+	return ""
+}
+
+func (r *yuqueResolver) ImageURL(src string) string {
+	return r.images[src]
+}
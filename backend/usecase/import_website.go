@@ -0,0 +1,75 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/pkg/website"
+)
+
+// websitePages pages of the site in its sitemap or found by links, versions are hashes of their title and content,
+// so a recrawl only updates pages changed on the site
+func (u *ImportSourceUsecase) websitePages(ctx context.Context, source *domain.ImportSource) ([]*importPage, pageSyncer, error) {
+	settings := source.Settings.Website
+	if settings == nil {
+		return nil, nil, errors.New("website settings are required")
+	}
+	include, err := website.CompilePatterns(settings.Include)
+	if err != nil {
+		return nil, nil, err
+	}
+	exclude, err := website.CompilePatterns(settings.Exclude)
+	if err != nil {
+		return nil, nil, err
+	}
+	maxDepth := settings.MaxDepth
+	if maxDepth == 0 {
+		maxDepth = domain.WebsiteDefaultMaxDepth
+	}
+	pages, err := website.Crawl(ctx, website.Options{
+		URL:      settings.URL,
+		MaxDepth: maxDepth,
+		MaxPages: domain.WebsiteMaxPages,
+		Include:  include,
+		Exclude:  exclude,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	importPages := make([]*importPage, 0, len(pages))
+	contents := make(map[string]string, len(pages))
+	for _, page := range pages {
+		hash := sha256.Sum256([]byte(page.Title + "\n" + page.Markdown))
+		importPages = append(importPages, &importPage{
+			ID:      page.URL,
+			Title:   page.Title,
+			Version: hex.EncodeToString(hash[:]),
+		})
+		contents[page.URL] = page.Markdown
+	}
+	return importPages, func(page *importPage, nodeIDs map[string]string, _ map[string]*domain.ImportSourceItem) error {
+		nodeID := nodeIDs[page.ID]
+		// links between imported pages point at their nodes
+		nodeByKey := make(map[string]string, len(nodeIDs))
+		for pageURL, id := range nodeIDs {
+			if key := importLinkKey(pageURL, nil); key != "" {
+				nodeByKey[key] = id
+			}
+		}
+		base, _ := url.Parse(page.ID)
+		content, _ := rewriteImportLinks(contents[page.ID], base, nodeByKey)
+		if err := u.nodeUsecase.Update(ctx, &domain.UpdateNodeReq{
+			ID:      nodeID,
+			KBID:    source.KBID,
+			Name:    &page.Title,
+			Content: &content,
+		}); err != nil {
+			return err
+		}
+		return u.savePageItem(ctx, source, page, nodeID)
+	}, nil
+}
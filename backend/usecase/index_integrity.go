@@ -0,0 +1,144 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/chaitin/panda-wiki/config"
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/repo/mq"
+	"github.com/chaitin/panda-wiki/repo/pg"
+	"github.com/chaitin/panda-wiki/store/rag"
+)
+
+type IndexIntegrityUsecase struct {
+	kbRepo   *pg.KnowledgeBaseRepository
+	nodeRepo *pg.NodeRepository
+	ragRepo  *mq.RAGRepository
+	rag      rag.RAGService
+	config   *config.Config
+	logger   *log.Logger
+}
+
+func NewIndexIntegrityUsecase(kbRepo *pg.KnowledgeBaseRepository, nodeRepo *pg.NodeRepository, ragRepo *mq.RAGRepository, rag rag.RAGService, config *config.Config, logger *log.Logger) *IndexIntegrityUsecase {
+	return &IndexIntegrityUsecase{
+		kbRepo:   kbRepo,
+		nodeRepo: nodeRepo,
+		ragRepo:  ragRepo,
+		rag:      rag,
+		config:   config,
+		logger:   logger.WithModule("usecase.index_integrity"),
+	}
+}
+
+// CheckKB compare published nodes of the kb with its vector store documents, repair the drift if asked
+func (u *IndexIntegrityUsecase) CheckKB(ctx context.Context, kbID string, repair bool) (*domain.IndexIntegrityReport, error) {
+	kb, err := u.kbRepo.GetKnowledgeBaseByID(ctx, kbID)
+	if err != nil {
+		return nil, err
+	}
+	return u.check(ctx, kb.ID, kb.DatasetID, repair)
+}
+
+// CheckAll check every kb, the summary is recorded as the result of the cron run
+func (u *IndexIntegrityUsecase) CheckAll(ctx context.Context) (domain.CronRunResult, error) {
+	kbs, err := u.kbRepo.GetKnowledgeBaseList(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get knowledge base list failed: %w", err)
+	}
+	repair := u.config.Cron.RepairIndex
+	var (
+		errs                   []error
+		missing, empty, orphan int
+		reindexed, deleted     int
+		drifted                = make([]*domain.IndexIntegrityReport, 0)
+	)
+	for _, kb := range kbs {
+		report, err := u.check(ctx, kb.ID, kb.DatasetID, repair)
+		if err != nil {
+			u.logger.Error("check index integrity failed", log.String("kb_id", kb.ID), log.Error(err))
+			errs = append(errs, fmt.Errorf("kb %s: %w", kb.ID, err))
+			continue
+		}
+		if !report.HasDrift() {
+			continue
+		}
+		missing += report.MissingCount
+		empty += report.EmptyCount
+		orphan += report.OrphanCount
+		reindexed += report.ReindexedCount
+		deleted += report.DeletedDocCount
+		drifted = append(drifted, report)
+	}
+	result := domain.CronRunResult{
+		"kb_count":          len(kbs),
+		"drifted_kb_count":  len(drifted),
+		"missing_count":     missing,
+		"empty_count":       empty,
+		"orphan_count":      orphan,
+		"reindexed_count":   reindexed,
+		"deleted_doc_count": deleted,
+		"repaired":          repair,
+		"reports":           drifted,
+	}
+	return result, errors.Join(errs...)
+}
+
+func (u *IndexIntegrityUsecase) check(ctx context.Context, kbID, datasetID string, repair bool) (*domain.IndexIntegrityReport, error) {
+	report := &domain.IndexIntegrityReport{
+		KBID:           kbID,
+		MissingNodeIDs: []string{},
+		EmptyNodeIDs:   []string{},
+		OrphanDocIDs:   []string{},
+		CheckedAt:      time.Now(),
+	}
+	releases, err := u.nodeRepo.GetIndexedNodeReleases(ctx, kbID)
+	if err != nil {
+		return nil, fmt.Errorf("get node releases failed: %w", err)
+	}
+	docIDs, err := u.nodeRepo.GetNodeReleaseDocIDs(ctx, kbID)
+	if err != nil {
+		return nil, fmt.Errorf("get node release doc ids failed: %w", err)
+	}
+	docs, err := u.rag.ListRecords(ctx, datasetID)
+	if err != nil {
+		return nil, fmt.Errorf("list vector store documents failed: %w", err)
+	}
+	drift := domain.CompareIndex(report, releases, docIDs, docs, report.CheckedAt)
+	if report.HasDrift() {
+		u.logger.Warn("vector index drift found", log.String("kb_id", kbID),
+			log.Int("missing", report.MissingCount), log.Int("empty", report.EmptyCount), log.Int("orphan", report.OrphanCount))
+	}
+	if !repair || !report.HasDrift() {
+		return report, nil
+	}
+	// documents which failed parsing are deleted before their node is re-indexed
+	deleteDocIDs := append(drift.StaleDocIDs, drift.OrphanDocIDs...)
+	if len(deleteDocIDs) > 0 {
+		if err := u.rag.DeleteRecords(ctx, datasetID, deleteDocIDs); err != nil {
+			return nil, fmt.Errorf("delete stale documents failed: %w", err)
+		}
+		report.DeletedDocCount = len(deleteDocIDs)
+	}
+	if len(drift.Reindex) > 0 {
+		requests := make([]*domain.NodeReleaseVectorRequest, 0, len(drift.Reindex))
+		for _, release := range drift.Reindex {
+			requests = append(requests, &domain.NodeReleaseVectorRequest{
+				KBID:          kbID,
+				NodeReleaseID: release.ID,
+				Action:        "upsert",
+			})
+		}
+		if err := u.ragRepo.AsyncUpdateNodeReleaseVector(ctx, requests); err != nil {
+			return nil, fmt.Errorf("queue re-index failed: %w", err)
+		}
+		report.ReindexedCount = len(requests)
+	}
+	report.Repaired = true
+	u.logger.Info("vector index drift repaired", log.String("kb_id", kbID),
+		log.Int("reindexed", report.ReindexedCount), log.Int("deleted", report.DeletedDocCount))
+	return report, nil
+}
@@ -0,0 +1,129 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chaitin/panda-wiki/config"
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/repo/pg"
+)
+
+// ipRuleCacheTTL rules are checked on every request, changes reach other instances after this time
+const ipRuleCacheTTL = 10 * time.Second
+
+type IPRuleUsecase struct {
+	settingRepo *pg.SettingRepository
+	config      *config.Config
+	logger      *log.Logger
+
+	mu       sync.Mutex
+	matchers map[domain.IPRuleScope]*domain.IPRuleMatcher
+	loadedAt time.Time
+}
+
+func NewIPRuleUsecase(settingRepo *pg.SettingRepository, config *config.Config, logger *log.Logger) *IPRuleUsecase {
+	return &IPRuleUsecase{
+		settingRepo: settingRepo,
+		config:      config,
+		logger:      logger.WithModule("usecase.ip_rule"),
+	}
+}
+
+// GetIPRules ip rules of the admin and the public apis, every ip is allowed if not set
+func (u *IPRuleUsecase) GetIPRules(ctx context.Context) (*domain.IPRules, error) {
+	rules := &domain.IPRules{}
+	if err := u.settingRepo.GetSetting(ctx, domain.SettingKeyIPRules, rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// UpdateIPRules replace the ip rules, ErrIPRuleLockout if the admin rules would block the ip updating them
+func (u *IPRuleUsecase) UpdateIPRules(ctx context.Context, req *domain.UpdateIPRulesReq, remoteIP string) error {
+	rules := &domain.IPRules{
+		Admin:  normalizeIPRuleSet(req.Admin),
+		Public: normalizeIPRuleSet(req.Public),
+	}
+	matchers, err := compileIPRules(rules)
+	if err != nil {
+		return err
+	}
+	if !u.config.HTTP.IgnoreIPRules && !matchers[domain.IPRuleScopeAdmin].Allows(remoteIP) {
+		return domain.ErrIPRuleLockout
+	}
+	if err := u.settingRepo.UpdateSetting(ctx, domain.SettingKeyIPRules, rules); err != nil {
+		return err
+	}
+	u.mu.Lock()
+	u.matchers = matchers
+	u.loadedAt = time.Now()
+	u.mu.Unlock()
+	u.logger.Info("ip rules updated", log.Any("admin", rules.Admin), log.Any("public", rules.Public))
+	return nil
+}
+
+// CheckIP ErrIPDenied if the rules of the scope block the ip, ignored if configured so
+func (u *IPRuleUsecase) CheckIP(ctx context.Context, scope domain.IPRuleScope, ip string) error {
+	if u.config.HTTP.IgnoreIPRules {
+		return nil
+	}
+	matcher := u.getMatchers(ctx)[scope]
+	if matcher != nil && !matcher.Allows(ip) {
+		return domain.ErrIPDenied
+	}
+	return nil
+}
+
+// getMatchers cached rules, the rules loaded before are kept if loading fails
+func (u *IPRuleUsecase) getMatchers(ctx context.Context) map[domain.IPRuleScope]*domain.IPRuleMatcher {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.matchers != nil && time.Since(u.loadedAt) < ipRuleCacheTTL {
+		return u.matchers
+	}
+	u.loadedAt = time.Now()
+	rules, err := u.GetIPRules(ctx)
+	if err != nil {
+		u.logger.Error("load ip rules failed", log.Error(err))
+		return u.matchers
+	}
+	matchers, err := compileIPRules(rules)
+	if err != nil {
+		u.logger.Error("compile ip rules failed", log.Error(err))
+		return u.matchers
+	}
+	u.matchers = matchers
+	return matchers
+}
+
+func compileIPRules(rules *domain.IPRules) (map[domain.IPRuleScope]*domain.IPRuleMatcher, error) {
+	admin, err := rules.Admin.Compile()
+	if err != nil {
+		return nil, err
+	}
+	public, err := rules.Public.Compile()
+	if err != nil {
+		return nil, err
+	}
+	return map[domain.IPRuleScope]*domain.IPRuleMatcher{
+		domain.IPRuleScopeAdmin:  admin,
+		domain.IPRuleScopePublic: public,
+	}, nil
+}
+
+func normalizeIPRuleSet(set domain.IPRuleSet) domain.IPRuleSet {
+	normalize := func(rules []string) []string {
+		normalized := make([]string, 0, len(rules))
+		for _, rule := range rules {
+			if rule = strings.TrimSpace(rule); rule != "" {
+				normalized = append(normalized, rule)
+			}
+		}
+		return normalized
+	}
+	return domain.IPRuleSet{Allow: normalize(set.Allow), Deny: normalize(set.Deny)}
+}
@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"gorm.io/gorm"
+
+	"github.com/chaitin/panda-wiki/config"
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/repo/pg"
+)
+
+type KBMemberUsecase struct {
+	repo     *pg.KBMemberRepository
+	userRepo *pg.UserRepository
+	logger   *log.Logger
+}
+
+func NewKBMemberUsecase(repo *pg.KBMemberRepository, userRepo *pg.UserRepository, logger *log.Logger) *KBMemberUsecase {
+	return &KBMemberUsecase{
+		repo:     repo,
+		userRepo: userRepo,
+		logger:   logger.WithModule("usecase.kb_member"),
+	}
+}
+
+func (u *KBMemberUsecase) IsAdmin(ctx context.Context, userID string) (bool, error) {
+	role, err := u.userRepo.GetUserRole(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, domain.ErrUserNotFound
+		}
+		return false, err
+	}
+	return role == domain.UserRoleAdmin, nil
+}
+
+// CheckPermission return ErrPermissionDenied unless the user is an admin or has a role on the kb with the permission
+func (u *KBMemberUsecase) CheckPermission(ctx context.Context, userID, kbID string, permission domain.KBPermission) error {
+	admin, err := u.IsAdmin(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if admin {
+		return nil
+	}
+	if kbID == "" {
+		return domain.ErrPermissionDenied
+	}
+	member, err := u.repo.GetKBMember(ctx, kbID, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrKBMemberNotFound) {
+			return domain.ErrPermissionDenied
+		}
+		return err
+	}
+	if !member.Role.Allows(permission) {
+		return domain.ErrPermissionDenied
+	}
+	return nil
+}
+
+// GetResourceKBID kb of the node, conversation or app of the id, for apis not carrying the kb id
+func (u *KBMemberUsecase) GetResourceKBID(ctx context.Context, model any, id string) (string, error) {
+	return u.repo.GetResourceKBID(ctx, model, id)
+}
+
+// GetUserKBRoles roles of the user on its kbs, nil for admins who have every permission on every kb
+func (u *KBMemberUsecase) GetUserKBRoles(ctx context.Context, userID string) ([]*domain.UserKBRole, error) {
+	admin, err := u.IsAdmin(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if admin {
+		return nil, nil
+	}
+	members, err := u.repo.GetUserKBMembers(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	roles := make([]*domain.UserKBRole, 0, len(members))
+	for _, member := range members {
+		roles = append(roles, &domain.UserKBRole{
+			KBID:        member.KBID,
+			Role:        member.Role,
+			Permissions: member.Role.Permissions(),
+		})
+	}
+	return roles, nil
+}
+
+func (u *KBMemberUsecase) GetKBMemberList(ctx context.Context, req *domain.KBMemberListReq) ([]*domain.KBMemberListItem, error) {
+	return u.repo.GetKBMemberList(ctx, req.KBID)
+}
+
+func (u *KBMemberUsecase) SetKBMember(ctx context.Context, req *domain.SetKBMemberReq) error {
+	if _, err := u.userRepo.GetUserRole(ctx, req.UserID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.ErrUserNotFound
+		}
+		return err
+	}
+	if err := u.repo.SetKBMember(ctx, &domain.KBMember{
+		KBID:   req.KBID,
+		UserID: req.UserID,
+		Role:   req.Role,
+	}); err != nil {
+		return err
+	}
+	u.logger.Info("kb member set", log.String("kb_id", req.KBID), log.String("user_id", req.UserID), log.String("role", string(req.Role)))
+	return nil
+}
+
+func (u *KBMemberUsecase) DeleteKBMember(ctx context.Context, req *domain.DeleteKBMemberReq) error {
+	if err := u.repo.DeleteKBMember(ctx, req.KBID, req.UserID); err != nil {
+		return err
+	}
+	u.logger.Info("kb member removed", log.String("kb_id", req.KBID), log.String("user_id", req.UserID))
+	return nil
+}
+
+// groupRoles global role and kb roles of a user of an identity provider, in tells whether the user is in a configured group.
+// the role with most permissions wins on a kb of several groups
+func groupRoles(adminGroups []string, mappings []config.GroupKBRoleConfig, in func(group string) bool) (domain.UserRole, map[string]domain.KBRole) {
+	role := domain.UserRoleMember
+	if slices.ContainsFunc(adminGroups, in) {
+		role = domain.UserRoleAdmin
+	}
+	kbRoles := make(map[string]domain.KBRole)
+	for _, mapping := range mappings {
+		kbRole := domain.KBRole(mapping.Role)
+		if kbRole.Permissions() == nil || !in(mapping.Group) {
+			continue
+		}
+		if current, ok := kbRoles[mapping.KBID]; !ok || len(kbRole.Permissions()) > len(current.Permissions()) {
+			kbRoles[mapping.KBID] = kbRole
+		}
+	}
+	return role, kbRoles
+}
+
+// managedKBIDs kbs whose roles follow the groups of the identity provider
+func managedKBIDs(mappings []config.GroupKBRoleConfig) []string {
+	kbIDs := make([]string, 0, len(mappings))
+	for _, mapping := range mappings {
+		kbIDs = append(kbIDs, mapping.KBID)
+	}
+	return kbIDs
+}
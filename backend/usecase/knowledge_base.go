@@ -2,10 +2,12 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 
 	"github.com/chaitin/panda-wiki/config"
 	"github.com/chaitin/panda-wiki/domain"
@@ -17,31 +19,41 @@ import (
 )
 
 type KnowledgeBaseUsecase struct {
-	repo     *pg.KnowledgeBaseRepository
-	nodeRepo *pg.NodeRepository
-	ragRepo  *mq.RAGRepository
-	rag      rag.RAGService
-	kbCache  *cache.KBRepo
-	logger   *log.Logger
-	config   *config.Config
+	repo       *pg.KnowledgeBaseRepository
+	nodeRepo   *pg.NodeRepository
+	ragRepo    *mq.RAGRepository
+	reviewRepo *pg.NodeReviewRepository
+	modelRepo  *pg.ModelRepository
+	rag        rag.RAGService
+	kbCache    *cache.KBRepo
+	logger     *log.Logger
+	config     *config.Config
+
+	attachmentUsecase *NodeAttachmentUsecase
+	botProfileUsecase *BotProfileUsecase
 }
 
-func NewKnowledgeBaseUsecase(repo *pg.KnowledgeBaseRepository, nodeRepo *pg.NodeRepository, ragRepo *mq.RAGRepository, rag rag.RAGService, kbCache *cache.KBRepo, logger *log.Logger, config *config.Config) (*KnowledgeBaseUsecase, error) {
+func NewKnowledgeBaseUsecase(repo *pg.KnowledgeBaseRepository, nodeRepo *pg.NodeRepository, ragRepo *mq.RAGRepository, reviewRepo *pg.NodeReviewRepository, modelRepo *pg.ModelRepository, rag rag.RAGService, kbCache *cache.KBRepo, attachmentUsecase *NodeAttachmentUsecase, botProfileUsecase *BotProfileUsecase, logger *log.Logger, config *config.Config) (*KnowledgeBaseUsecase, error) {
 	u := &KnowledgeBaseUsecase{
-		repo:     repo,
-		nodeRepo: nodeRepo,
-		ragRepo:  ragRepo,
-		rag:      rag,
-		logger:   logger.WithModule("usecase.knowledge_base"),
-		config:   config,
-		kbCache:  kbCache,
+		repo:       repo,
+		nodeRepo:   nodeRepo,
+		ragRepo:    ragRepo,
+		reviewRepo: reviewRepo,
+		modelRepo:  modelRepo,
+		rag:        rag,
+		logger:     logger.WithModule("usecase.knowledge_base"),
+		config:     config,
+		kbCache:    kbCache,
+
+		attachmentUsecase: attachmentUsecase,
+		botProfileUsecase: botProfileUsecase,
 	}
 	return u, nil
 }
 
 func (u *KnowledgeBaseUsecase) CreateKnowledgeBase(ctx context.Context, req *domain.CreateKnowledgeBaseReq) (string, error) {
 	// create kb in vector store
-	datasetID, err := u.rag.CreateKnowledgeBase(ctx)
+	datasetID, err := u.rag.CreateKnowledgeBase(ctx, "")
 	if err != nil {
 		return "", err
 	}
@@ -72,16 +84,95 @@ func (u *KnowledgeBaseUsecase) GetKnowledgeBaseList(ctx context.Context) ([]*dom
 	return knowledgeBases, nil
 }
 
+// GetEmbeddingModelIDs embedding models selected by kbs instead of the default model
+func (u *KnowledgeBaseUsecase) GetEmbeddingModelIDs(ctx context.Context) ([]string, error) {
+	return u.repo.GetEmbeddingModelIDs(ctx)
+}
+
+// GetReadOnlyKnowledgeBaseIDs kbs in read-only mode
+func (u *KnowledgeBaseUsecase) GetReadOnlyKnowledgeBaseIDs(ctx context.Context) ([]string, error) {
+	return u.repo.GetReadOnlyKnowledgeBaseIDs(ctx)
+}
+
 func (u *KnowledgeBaseUsecase) UpdateKnowledgeBase(ctx context.Context, req *domain.UpdateKnowledgeBaseReq) error {
+	var rebuild *domain.KnowledgeBase
+	if req.ModelSettings != nil {
+		if err := u.checkModelSettings(ctx, req.ModelSettings); err != nil {
+			return err
+		}
+		kb, err := u.repo.GetKnowledgeBaseByID(ctx, req.ID)
+		if err != nil {
+			return err
+		}
+		if kb.ModelSettings.EmbeddingModelID != req.ModelSettings.EmbeddingModelID {
+			rebuild = kb
+		}
+	}
 	if err := u.repo.UpdateKnowledgeBase(ctx, req); err != nil {
 		return err
 	}
+	if rebuild != nil {
+		if err := rebuildKBDatasets(ctx, u.ragRepo, []*domain.KnowledgeBase{rebuild}); err != nil {
+			return err
+		}
+	}
 	if err := u.kbCache.DeleteKB(ctx, req.ID); err != nil {
 		return err
 	}
+	if req.Name != nil || req.BrandingSettings != nil {
+		u.botProfileUsecase.AutoSyncBotProfiles(req.ID)
+	}
 	return nil
 }
 
+// checkModelSettings selected models exist and are of their types
+func (u *KnowledgeBaseUsecase) checkModelSettings(ctx context.Context, settings *domain.KBModelSettings) error {
+	for _, modelType := range []domain.ModelType{domain.ModelTypeChat, domain.ModelTypeEmbedding, domain.ModelTypeRerank} {
+		id := settings.ModelID(modelType)
+		if id == "" {
+			continue
+		}
+		if _, err := u.modelRepo.GetModel(ctx, id, modelType); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return domain.NewError(domain.ErrCodeInvalidRequest, fmt.Sprintf("%s model %s not found", modelType, id))
+			}
+			return err
+		}
+	}
+	if id := settings.RewriteModelID; id != "" {
+		if _, err := u.modelRepo.GetModel(ctx, id, domain.ModelTypeChat); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return domain.NewError(domain.ErrCodeInvalidRequest, fmt.Sprintf("rewrite model %s not found", id))
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// rebuildKBDatasets rebuild the datasets of the kbs in background with the embedding models of the kbs,
+// searches use the current datasets until the new ones are filled
+func rebuildKBDatasets(ctx context.Context, ragRepo *mq.RAGRepository, kbs []*domain.KnowledgeBase) error {
+	requests := make([]*domain.NodeReleaseVectorRequest, 0, len(kbs))
+	for _, kb := range kbs {
+		requests = append(requests, &domain.NodeReleaseVectorRequest{
+			KBID:   kb.ID,
+			Action: "rebuild",
+		})
+	}
+	return ragRepo.AsyncUpdateNodeReleaseVector(ctx, requests)
+}
+
+// IsBotTrafficFiltered report whether bot traffic is excluded from stats of the kb
+func (u *KnowledgeBaseUsecase) IsBotTrafficFiltered(ctx context.Context, kbID string) bool {
+	kb, err := u.GetKnowledgeBase(ctx, kbID)
+	if err != nil {
+		u.logger.Warn("get kb stat settings failed", log.Error(err), log.String("kb_id", kbID))
+		return false
+	}
+	return kb.StatSettings.FilterBotTraffic
+}
+
 func (u *KnowledgeBaseUsecase) GetKnowledgeBase(ctx context.Context, kbID string) (*domain.KnowledgeBase, error) {
 	kb, err := u.kbCache.GetKB(ctx, kbID)
 	if err != nil {
@@ -101,21 +192,36 @@ func (u *KnowledgeBaseUsecase) GetKnowledgeBase(ctx context.Context, kbID string
 }
 
 func (u *KnowledgeBaseUsecase) DeleteKnowledgeBase(ctx context.Context, kbID string) error {
+	kb, err := u.repo.GetKnowledgeBaseByID(ctx, kbID)
+	if err != nil {
+		return err
+	}
 	if err := u.repo.DeleteKnowledgeBase(ctx, kbID); err != nil {
 		return err
 	}
 	// delete vector store
-	if err := u.rag.DeleteKnowledgeBase(ctx, kbID); err != nil {
+	if err := u.rag.DeleteKnowledgeBase(ctx, kb.DatasetID); err != nil {
 		return err
 	}
+	if kb.PendingDatasetID != "" {
+		if err := u.rag.DeleteKnowledgeBase(ctx, kb.PendingDatasetID); err != nil {
+			u.logger.Warn("failed to delete pending dataset of kb", log.String("kb_id", kbID), log.Error(err))
+		}
+	}
 	if err := u.kbCache.DeleteKB(ctx, kbID); err != nil {
 		return err
 	}
+	if err := u.attachmentUsecase.DeleteKBAttachments(ctx, kbID); err != nil {
+		u.logger.Warn("failed to delete kb attachments", log.String("kb_id", kbID), log.Error(err))
+	}
 	return nil
 }
 
 func (u *KnowledgeBaseUsecase) CreateKBRelease(ctx context.Context, req *domain.CreateKBReleaseReq) (string, error) {
 	if len(req.NodeIDs) > 0 {
+		if err := u.checkNodesApproved(ctx, req.KBID, req.NodeIDs); err != nil {
+			return "", err
+		}
 		// create published nodes
 		releaseIDs, err := u.nodeRepo.CreateNodeReleases(ctx, req.KBID, req.NodeIDs)
 		if err != nil {
@@ -151,6 +257,41 @@ func (u *KnowledgeBaseUsecase) CreateKBRelease(ctx context.Context, req *domain.
 	return release.ID, nil
 }
 
+// checkNodesApproved only approved versions of nodes are published if the kb requires review
+func (u *KnowledgeBaseUsecase) checkNodesApproved(ctx context.Context, kbID string, nodeIDs []string) error {
+	kb, err := u.GetKnowledgeBase(ctx, kbID)
+	if err != nil {
+		return err
+	}
+	if !kb.ReviewSettings.RequireReview {
+		return nil
+	}
+	unapproved, err := u.reviewRepo.GetUnapprovedNodeIDs(ctx, kbID, nodeIDs)
+	if err != nil {
+		return err
+	}
+	if len(unapproved) > 0 {
+		return fmt.Errorf("%w: %d nodes not approved", domain.ErrNodeReviewNotApproved, len(unapproved))
+	}
+	return nil
+}
+
+// PublishNodes publish drafts of the nodes and create a release, so the public site and rag index serve them
+func (u *KnowledgeBaseUsecase) PublishNodes(ctx context.Context, req *domain.PublishNodeReq) (string, error) {
+	now := time.Now()
+	message := req.Message
+	if message == "" {
+		message = fmt.Sprintf("发布 %d 篇文档", len(req.NodeIDs))
+	}
+	return u.CreateKBRelease(ctx, &domain.CreateKBReleaseReq{
+		KBID:    req.KBID,
+		Message: message,
+		// publishes in the same second get distinct tags
+		Tag:     fmt.Sprintf("publish-%s-%s", now.Format("20060102150405"), uuid.New().String()[:8]),
+		NodeIDs: req.NodeIDs,
+	})
+}
+
 func (u *KnowledgeBaseUsecase) GetKBReleaseList(ctx context.Context, req *domain.GetKBReleaseListReq) (*domain.GetKBReleaseListResp, error) {
 	total, releases, err := u.repo.GetKBReleaseList(ctx, req.KBID)
 	if err != nil {
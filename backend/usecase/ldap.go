@@ -0,0 +1,416 @@
+package usecase
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/chaitin/panda-wiki/config"
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/repo/pg"
+)
+
+const ldapTimeout = 10 * time.Second
+
+// LDAPUsecase password login of admins and readers against the ldap directory, for deployments which can not use oidc
+type LDAPUsecase struct {
+	config       config.LDAPConfig
+	userRepo     *pg.UserRepository
+	kbMemberRepo *pg.KBMemberRepository
+	readerRepo   *pg.ReaderRepository
+	logger       *log.Logger
+}
+
+func NewLDAPUsecase(
+	config *config.Config,
+	userRepo *pg.UserRepository,
+	kbMemberRepo *pg.KBMemberRepository,
+	readerRepo *pg.ReaderRepository,
+	logger *log.Logger,
+) *LDAPUsecase {
+	u := &LDAPUsecase{
+		config:       config.Auth.LDAP,
+		userRepo:     userRepo,
+		kbMemberRepo: kbMemberRepo,
+		readerRepo:   readerRepo,
+		logger:       logger.WithModule("usecase.ldap"),
+	}
+	for _, mapping := range u.config.KBRoles {
+		if domain.KBRole(mapping.Role).Permissions() == nil {
+			u.logger.Warn("unknown kb role of ldap group ignored", log.String("group", mapping.Group), log.String("role", mapping.Role))
+		}
+	}
+	for _, group := range append(slices.Clone(u.config.AdminGroups), groupsOfMappings(u.config.KBRoles)...) {
+		if _, err := ldap.ParseDN(group); err != nil || group == "" {
+			u.logger.Warn("ldap group which is not a dn never matches", log.String("group", group))
+		}
+	}
+	if strings.HasPrefix(strings.ToLower(u.config.URL), "ldap://") && !u.config.StartTLS {
+		u.logger.Warn("ldap passwords are sent in cleartext, use ldaps or start_tls", log.String("url", u.config.URL))
+	}
+	return u
+}
+
+func (u *LDAPUsecase) Enabled() bool {
+	return u.config.URL != ""
+}
+
+func (u *LDAPUsecase) ReaderLoginEnabled() bool {
+	return u.Enabled() && u.config.ReaderLogin
+}
+
+// Login authenticate the account with the directory and return its user, created on first login.
+// the role is set from the groups of the directory at every login
+func (u *LDAPUsecase) Login(ctx context.Context, account, password string) (*domain.User, error) {
+	entry, groups, err := u.authenticate(ctx, account, password)
+	if err != nil {
+		return nil, err
+	}
+	role, kbRoles := u.groupRoles(groups)
+	if role == domain.UserRoleMember && len(kbRoles) == 0 {
+		u.logger.Warn("ldap user without role rejected", log.String("dn", entry.DN), log.Any("groups", groups))
+		return nil, domain.ErrLDAPNoRole
+	}
+	user, err := u.userRepo.GetUserByLDAPDN(ctx, entry.DN)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if user == nil {
+		if user, err = u.createUser(ctx, entry, account, role); err != nil {
+			return nil, err
+		}
+	} else if user.Role != role {
+		if err := u.userRepo.UpdateUserRole(ctx, user.ID, role); err != nil {
+			return nil, err
+		}
+		u.logger.Info("ldap user role updated", log.String("user_id", user.ID), log.String("role", string(role)))
+	}
+	if err := u.kbMemberRepo.SyncUserKBRoles(ctx, user.ID, managedKBIDs(u.config.KBRoles), kbRoles); err != nil {
+		return nil, err
+	}
+	u.logger.Info("ldap user logged in", log.String("user_id", user.ID), log.String("account", user.Account))
+	return user, nil
+}
+
+func (u *LDAPUsecase) createUser(ctx context.Context, entry *ldap.Entry, account string, role domain.UserRole) (*domain.User, error) {
+	if value := entry.GetEqualFoldAttributeValue(u.config.AccountAttr); value != "" {
+		account = value
+	}
+	// local accounts are never taken over by the directory
+	exists, err := u.userRepo.AccountExists(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, domain.ErrLDAPAccountTaken
+	}
+	// directory users log in against the directory, the local password is random until an admin resets it
+	user := &domain.User{
+		ID:       uuid.New().String(),
+		Account:  account,
+		Password: uuid.New().String(),
+		Role:     role,
+		LDAPDN:   entry.DN,
+	}
+	if err := u.userRepo.CreateUser(ctx, user); err != nil {
+		return nil, err
+	}
+	u.logger.Info("ldap user created", log.String("user_id", user.ID), log.String("account", account), log.String("role", string(role)))
+	return user, nil
+}
+
+// ReaderLogin authenticate a reader of the kb with the directory, the reader is created on first login
+// and its groups are the names of its groups in the directory
+func (u *LDAPUsecase) ReaderLogin(ctx context.Context, kbID, account, password string) (*domain.Reader, error) {
+	if !u.ReaderLoginEnabled() {
+		return nil, domain.ErrReaderLoginFailed
+	}
+	entry, groups, err := u.authenticate(ctx, account, password)
+	if err != nil {
+		if errors.Is(err, domain.ErrLDAPLoginFailed) {
+			return nil, domain.ErrReaderLoginFailed
+		}
+		return nil, err
+	}
+	names := readerGroups(groups)
+	reader, err := u.readerRepo.GetReaderByLDAPDN(ctx, kbID, entry.DN)
+	if err != nil {
+		return nil, err
+	}
+	if reader != nil {
+		if err := u.readerRepo.SyncLDAPReader(ctx, reader.ID, names, false); err != nil {
+			return nil, err
+		}
+		reader.Groups = names
+		return reader, nil
+	}
+	email := normalizeReaderEmail(entry.GetEqualFoldAttributeValue(u.config.EmailAttr))
+	if email == "" {
+		email = normalizeReaderEmail(account)
+	}
+	exists, err := u.readerRepo.EmailExists(ctx, kbID, email)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, domain.ErrReaderEmailExists
+	}
+	now := time.Now()
+	reader = &domain.Reader{
+		ID:        uuid.New().String(),
+		KBID:      kbID,
+		Email:     email,
+		Name:      entry.GetEqualFoldAttributeValue(u.config.NameAttr),
+		Password:  uuid.New().String(),
+		Groups:    names,
+		LDAPDN:    entry.DN,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := u.readerRepo.CreateReader(ctx, reader); err != nil {
+		return nil, err
+	}
+	u.logger.Info("ldap reader created", log.String("kb_id", kbID), log.String("reader_id", reader.ID), log.String("email", email))
+	return reader, nil
+}
+
+// Sync set roles of directory users and groups of directory readers from the directory.
+// users removed from the directory lose their roles and such readers are disabled
+func (u *LDAPUsecase) Sync(ctx context.Context) (domain.CronRunResult, error) {
+	conn, err := u.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	// groups of each dn, nil if it left the directory
+	directory := make(map[string][]string)
+	lookup := func(dn string) ([]string, error) {
+		if groups, ok := directory[dn]; ok {
+			return groups, nil
+		}
+		result, err := conn.Search(ldap.NewSearchRequest(dn, ldap.ScopeBaseObject, ldap.NeverDerefAliases, 0, 0, false,
+			"(objectClass=*)", u.attributes(u.config.GroupAttr), nil))
+		if err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+			return nil, err
+		}
+		var groups []string
+		if result != nil && len(result.Entries) > 0 {
+			if groups, err = u.groups(conn, result.Entries[0]); err != nil {
+				return nil, err
+			}
+		}
+		directory[dn] = groups
+		return groups, nil
+	}
+	users, err := u.userRepo.GetLDAPUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	managed := managedKBIDs(u.config.KBRoles)
+	revoked := 0
+	for _, user := range users {
+		groups, err := lookup(user.LDAPDN)
+		if err != nil {
+			return nil, fmt.Errorf("look up ldap user %s failed: %w", user.LDAPDN, err)
+		}
+		role, kbRoles := u.groupRoles(groups)
+		if user.Role != role {
+			if err := u.userRepo.UpdateUserRole(ctx, user.ID, role); err != nil {
+				return nil, err
+			}
+		}
+		if err := u.kbMemberRepo.SyncUserKBRoles(ctx, user.ID, managed, kbRoles); err != nil {
+			return nil, err
+		}
+		if groups == nil {
+			revoked++
+			u.logger.Info("roles of user removed from ldap revoked", log.String("user_id", user.ID), log.String("dn", user.LDAPDN))
+		}
+	}
+	readers, err := u.readerRepo.GetLDAPReaders(ctx)
+	if err != nil {
+		return nil, err
+	}
+	disabled := 0
+	for _, reader := range readers {
+		groups, err := lookup(reader.LDAPDN)
+		if err != nil {
+			return nil, fmt.Errorf("look up ldap reader %s failed: %w", reader.LDAPDN, err)
+		}
+		if err := u.readerRepo.SyncLDAPReader(ctx, reader.ID, readerGroups(groups), groups == nil); err != nil {
+			return nil, err
+		}
+		if groups == nil && !reader.Disabled {
+			disabled++
+			u.logger.Info("reader removed from ldap disabled", log.String("reader_id", reader.ID), log.String("dn", reader.LDAPDN))
+		}
+	}
+	return domain.CronRunResult{
+		"users":            len(users),
+		"readers":          len(readers),
+		"revoked_users":    revoked,
+		"disabled_readers": disabled,
+	}, nil
+}
+
+// authenticate find the entry of the account with the service account and bind as it with the password.
+// groups are read before binding as the user, who may not read them
+func (u *LDAPUsecase) authenticate(ctx context.Context, account, password string) (*ldap.Entry, []string, error) {
+	account = strings.TrimSpace(account)
+	if account == "" || password == "" {
+		return nil, nil, domain.ErrLDAPLoginFailed
+	}
+	conn, err := u.connect()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer conn.Close()
+	// the size limit of 2 is enough to tell an account matching several entries
+	result, err := conn.Search(ldap.NewSearchRequest(u.config.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 2, 0, false,
+		strings.ReplaceAll(u.config.UserFilter, "{account}", ldap.EscapeFilter(account)),
+		u.attributes(u.config.AccountAttr, u.config.EmailAttr, u.config.NameAttr, u.config.GroupAttr), nil))
+	if err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+		return nil, nil, fmt.Errorf("search ldap user failed: %w", err)
+	}
+	if result == nil || len(result.Entries) == 0 {
+		return nil, nil, domain.ErrLDAPLoginFailed
+	}
+	if err != nil || len(result.Entries) > 1 {
+		u.logger.Warn("ldap account matches several entries", log.String("account", account))
+		return nil, nil, domain.ErrLDAPLoginFailed
+	}
+	entry := result.Entries[0]
+	groups, err := u.groups(conn, entry)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, nil, domain.ErrLDAPLoginFailed
+		}
+		return nil, nil, fmt.Errorf("bind ldap user failed: %w", err)
+	}
+	return entry, groups, nil
+}
+
+// connect dial the directory, upgrade ldap:// with StartTLS if configured and bind as the service account
+func (u *LDAPUsecase) connect() (*ldap.Conn, error) {
+	parsed, err := url.Parse(u.config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ldap url: %w", err)
+	}
+	tlsConfig := &tls.Config{
+		ServerName:         parsed.Hostname(),
+		InsecureSkipVerify: u.config.InsecureSkipVerify,
+		MinVersion:         tls.VersionTLS12,
+	}
+	conn, err := ldap.DialURL(u.config.URL, ldap.DialWithDialer(&net.Dialer{Timeout: ldapTimeout}), ldap.DialWithTLSConfig(tlsConfig))
+	if err != nil {
+		return nil, fmt.Errorf("connect ldap failed: %w", err)
+	}
+	conn.SetTimeout(ldapTimeout)
+	if parsed.Scheme == "ldap" && u.config.StartTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("start tls with ldap failed: %w", err)
+		}
+	}
+	if u.config.BindDN != "" {
+		if err := conn.Bind(u.config.BindDN, u.config.BindPassword); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("bind ldap service account failed: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+// groups dns of the groups of the entry, from its group attribute and the group search if configured
+func (u *LDAPUsecase) groups(conn *ldap.Conn, entry *ldap.Entry) ([]string, error) {
+	groups := []string{}
+	if u.config.GroupAttr != "" {
+		groups = append(groups, entry.GetEqualFoldAttributeValues(u.config.GroupAttr)...)
+	}
+	if u.config.GroupFilter != "" {
+		result, err := conn.Search(ldap.NewSearchRequest(u.config.GroupBaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
+			strings.ReplaceAll(u.config.GroupFilter, "{dn}", ldap.EscapeFilter(entry.DN)), []string{"cn"}, nil))
+		if err != nil {
+			return nil, fmt.Errorf("search ldap groups failed: %w", err)
+		}
+		for _, group := range result.Entries {
+			if !slices.Contains(groups, group.DN) {
+				groups = append(groups, group.DN)
+			}
+		}
+	}
+	return groups, nil
+}
+
+// attributes the configured attributes to read, empty ones are left out
+func (u *LDAPUsecase) attributes(names ...string) []string {
+	attributes := make([]string, 0, len(names))
+	for _, name := range names {
+		if name != "" {
+			attributes = append(attributes, name)
+		}
+	}
+	return attributes
+}
+
+// groupRoles groups are matched by their full dn, case insensitive.
+// a name alone would let a group of the same name in any other ou grant the role
+func (u *LDAPUsecase) groupRoles(groups []string) (domain.UserRole, map[string]domain.KBRole) {
+	return groupRoles(u.config.AdminGroups, u.config.KBRoles, func(group string) bool {
+		return slices.ContainsFunc(groups, func(dn string) bool {
+			return ldapDNEqual(dn, group)
+		})
+	})
+}
+
+// ldapDNEqual whether both are the same dn, ignoring case and spacing. values which are not dns never match
+func ldapDNEqual(a, b string) bool {
+	dnA, err := ldap.ParseDN(a)
+	if err != nil || len(dnA.RDNs) == 0 {
+		return false
+	}
+	dnB, err := ldap.ParseDN(b)
+	if err != nil {
+		return false
+	}
+	return dnA.EqualFold(dnB)
+}
+
+// groupsOfMappings groups of the kb role mappings
+func groupsOfMappings(mappings []config.GroupKBRoleConfig) []string {
+	groups := make([]string, 0, len(mappings))
+	for _, mapping := range mappings {
+		groups = append(groups, mapping.Group)
+	}
+	return groups
+}
+
+// readerGroups names of the groups set on readers, as used by node acls and reader access.
+// the name is the value of the first rdn, e.g. staff of cn=staff,ou=groups,dc=example,dc=com
+func readerGroups(groups []string) []string {
+	names := make([]string, 0, len(groups))
+	for _, group := range groups {
+		dn, err := ldap.ParseDN(group)
+		if err != nil || len(dn.RDNs) == 0 || len(dn.RDNs[0].Attributes) == 0 {
+			continue
+		}
+		if name := strings.TrimSpace(dn.RDNs[0].Attributes[0].Value); name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"net"
+	"reflect"
+	"testing"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+
+	"github.com/chaitin/panda-wiki/config"
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+)
+
+func TestLDAPGroupRoles(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Auth.LDAP.AdminGroups = []string{"cn=wiki-admins,ou=groups,dc=example,dc=com"}
+	cfg.Auth.LDAP.KBRoles = []config.GroupKBRoleConfig{
+		{Group: "cn=editors,ou=groups,dc=example,dc=com", KBID: "kb-1", Role: string(domain.KBRoleEditor)},
+		{Group: "editors", KBID: "kb-2", Role: string(domain.KBRoleOwner)},
+	}
+	u := &LDAPUsecase{config: cfg.Auth.LDAP, logger: log.NewLogger(cfg)}
+	tests := []struct {
+		name    string
+		groups  []string
+		role    domain.UserRole
+		kbRoles map[string]domain.KBRole
+	}{
+		{name: "no groups", role: domain.UserRoleMember, kbRoles: map[string]domain.KBRole{}},
+		{name: "admin group", groups: []string{"cn=wiki-admins,ou=groups,dc=example,dc=com"}, role: domain.UserRoleAdmin, kbRoles: map[string]domain.KBRole{}},
+		{name: "dn in other case and spacing", groups: []string{"CN=Wiki-Admins, OU=Groups, DC=example, DC=com"}, role: domain.UserRoleAdmin, kbRoles: map[string]domain.KBRole{}},
+		{name: "group of the same name in another ou", groups: []string{"cn=wiki-admins,ou=sales,dc=example,dc=com"}, role: domain.UserRoleMember, kbRoles: map[string]domain.KBRole{}},
+		{name: "group under the admin group", groups: []string{"cn=x,cn=wiki-admins,ou=groups,dc=example,dc=com"}, role: domain.UserRoleMember, kbRoles: map[string]domain.KBRole{}},
+		{
+			name:    "kb role by dn, mapping by name never matches",
+			groups:  []string{"cn=editors,ou=groups,dc=example,dc=com", "cn=editors,ou=sales,dc=example,dc=com"},
+			role:    domain.UserRoleMember,
+			kbRoles: map[string]domain.KBRole{"kb-1": domain.KBRoleEditor},
+		},
+		{name: "group which is not a dn", groups: []string{"wiki-admins", "editors"}, role: domain.UserRoleMember, kbRoles: map[string]domain.KBRole{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			role, kbRoles := u.groupRoles(tt.groups)
+			if role != tt.role {
+				t.Errorf("role = %q, want %q", role, tt.role)
+			}
+			if !reflect.DeepEqual(kbRoles, tt.kbRoles) {
+				t.Errorf("kb roles = %v, want %v", kbRoles, tt.kbRoles)
+			}
+		})
+	}
+}
+
+func TestLDAPReaderGroups(t *testing.T) {
+	got := readerGroups([]string{
+		"cn=staff,ou=groups,dc=example,dc=com",
+		"CN=Sales\\, EMEA,OU=Groups,DC=example,DC=com",
+		"cn=staff,ou=other,dc=example,dc=com",
+		"not a dn",
+		"",
+	})
+	if want := []string{"staff", "Sales, EMEA"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("readerGroups() = %v, want %v", got, want)
+	}
+}
+
+func TestLDAPConnectStartTLS(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	// the directory reads the first request and refuses it by closing the connection
+	requests := make(chan ber.Tag, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		packet, err := ber.ReadPacket(conn)
+		if err != nil || len(packet.Children) < 2 {
+			requests <- 0xff
+			return
+		}
+		requests <- packet.Children[1].Tag
+	}()
+
+	cfg := &config.Config{}
+	cfg.Auth.LDAP = config.LDAPConfig{
+		URL:          "ldap://" + listener.Addr().String(),
+		StartTLS:     true,
+		BindDN:       "cn=wiki,dc=example,dc=com",
+		BindPassword: "secret",
+	}
+	u := &LDAPUsecase{config: cfg.Auth.LDAP, logger: log.NewLogger(cfg)}
+	if conn, err := u.connect(); err == nil {
+		conn.Close()
+		t.Fatal("connect() succeeded without tls")
+	}
+	if tag := <-requests; tag != ldap.ApplicationExtendedRequest {
+		t.Errorf("first request = %d, want the StartTLS extended request %d before binding", tag, ldap.ApplicationExtendedRequest)
+	}
+}
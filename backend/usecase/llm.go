@@ -4,25 +4,31 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"slices"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/cloudwego/eino-ext/components/model/deepseek"
-	"github.com/cloudwego/eino-ext/components/model/ollama"
-	"github.com/cloudwego/eino-ext/components/model/openai"
 	"github.com/cloudwego/eino/components/model"
 	"github.com/cloudwego/eino/components/prompt"
 	"github.com/cloudwego/eino/schema"
-	"github.com/ollama/ollama/api"
 	"github.com/samber/lo"
+	"gorm.io/gorm"
 
+	"github.com/chaitin/panda-wiki/apm"
 	"github.com/chaitin/panda-wiki/config"
 	"github.com/chaitin/panda-wiki/domain"
 	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/pkg/asr"
+	"github.com/chaitin/panda-wiki/pkg/embedder"
+	"github.com/chaitin/panda-wiki/pkg/reranker"
+	"github.com/chaitin/panda-wiki/pkg/tokenizer"
+	"github.com/chaitin/panda-wiki/repo/cache"
 	"github.com/chaitin/panda-wiki/repo/pg"
 	"github.com/chaitin/panda-wiki/store/rag"
 	"github.com/chaitin/panda-wiki/utils"
@@ -34,227 +40,568 @@ type LLMUsecase struct {
 	kbRepo           *pg.KnowledgeBaseRepository
 	nodeRepo         *pg.NodeRepository
 	modelRepo        *pg.ModelRepository
-	config           *config.Config
-	logger           *log.Logger
+	retrievalCache   *cache.RetrievalRepo
+	glossaryRepo     *pg.GlossaryRepository
+	// nil if embedding runs in process
+	embedder *embedder.Client
+	config   *config.Config
+	logger   *log.Logger
 }
 
-func NewLLMUsecase(config *config.Config, rag rag.RAGService, conversationRepo *pg.ConversationRepository, kbRepo *pg.KnowledgeBaseRepository, nodeRepo *pg.NodeRepository, modelRepo *pg.ModelRepository, logger *log.Logger) *LLMUsecase {
-	return &LLMUsecase{
+func NewLLMUsecase(config *config.Config, rag rag.RAGService, conversationRepo *pg.ConversationRepository, kbRepo *pg.KnowledgeBaseRepository, nodeRepo *pg.NodeRepository, modelRepo *pg.ModelRepository, retrievalCache *cache.RetrievalRepo, glossaryRepo *pg.GlossaryRepository, logger *log.Logger) *LLMUsecase {
+	u := &LLMUsecase{
 		config:           config,
 		rag:              rag,
 		conversationRepo: conversationRepo,
 		kbRepo:           kbRepo,
 		nodeRepo:         nodeRepo,
 		modelRepo:        modelRepo,
+		retrievalCache:   retrievalCache,
+		glossaryRepo:     glossaryRepo,
 		logger:           logger.WithModule("usecase.llm"),
 	}
+	if addr := config.Embedding.Addr; addr != "" {
+		client, err := embedder.NewClient(addr)
+		if err != nil {
+			u.logger.Error("create embedder client failed, embedding in process", log.String("addr", addr), log.Error(err))
+		} else {
+			u.embedder = client
+		}
+	}
+	return u
 }
 
+// GetChatModel chat model built by the provider of the model, traced
 func (u *LLMUsecase) GetChatModel(ctx context.Context, model *domain.Model) (model.BaseChatModel, error) {
-	// config chat model
-	var temprature float32 = 0.0
-	config := &openai.ChatModelConfig{
-		APIKey:      model.APIKey,
+	temprature := domain.ChatModelTemperature
+	chatModel, err := modelProviderOf(model.Provider).ChatModel(ctx, model, temprature)
+	if err != nil {
+		return nil, fmt.Errorf("create chat model failed: %w", err)
+	}
+	return apm.TraceChatModel(chatModel, apm.GenAIModel{
+		System:      model.Provider.GenAISystem(),
+		Model:       model.Model,
 		BaseURL:     model.BaseURL,
-		Model:       string(model.Model),
 		Temperature: &temprature,
+	}), nil
+}
+
+func (u *LLMUsecase) FormatConversationMessages(
+	ctx context.Context,
+	conversationID string,
+	kbID string,
+	region *domain.GeoRegion,
+	aclFilter *domain.NodeACLFilter,
+	systemPrompt string,
+) ([]*schema.Message, []*domain.RankedNodeChunks, string, error) {
+	msgs, err := u.conversationRepo.GetConversationMessagesByID(ctx, conversationID)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("get conversation messages failed: %w", err)
 	}
-	if model.Provider == domain.ModelProviderBrandAzureOpenAI {
-		config.ByAzure = true
-		config.APIVersion = model.APIVersion
-		if config.APIVersion == "" {
-			config.APIVersion = "2024-10-21"
+	return u.formatMessages(ctx, kbID, HistoryMessages(msgs), region, aclFilter, systemPrompt)
+}
+
+// HistoryMessages questions and answers of conversation messages as model messages
+func HistoryMessages(msgs []*domain.ConversationMessage) []*schema.Message {
+	historyMessages := make([]*schema.Message, 0)
+	for _, msg := range msgs {
+		switch msg.Role {
+		case schema.Assistant:
+			historyMessages = append(historyMessages, schema.AssistantMessage(msg.Content, nil))
+		case schema.User:
+			historyMessages = append(historyMessages, schema.UserMessage(msg.Content))
+		default:
+			continue
 		}
 	}
-	if model.APIHeader != "" {
-		client := getHttpClientWithAPIHeaderMap(model.APIHeader)
-		if client != nil {
-			config.HTTPClient = client
-		}
+	return historyMessages
+}
+
+// FormatQuestionMessages prompt of a single question without conversation, with the documents retrieved for it.
+// for admins, documents hidden by node acls are retrieved too
+func (u *LLMUsecase) FormatQuestionMessages(ctx context.Context, kbID, question, systemPrompt string) ([]*schema.Message, []*domain.RankedNodeChunks, error) {
+	messages, rankedNodes, _, err := u.formatMessages(ctx, kbID, []*schema.Message{schema.UserMessage(question)}, nil, nil, systemPrompt)
+	return messages, rankedNodes, err
+}
+
+// formatMessages prompt answering the last message of the history with the documents retrieved for it
+// which the acl filter allows, the system prompt of the app is rendered already, see domain.RenderSystemPrompt.
+// the query the documents were retrieved with is returned, the question rewritten with the history if the kb rewrites
+func (u *LLMUsecase) formatMessages(
+	ctx context.Context,
+	kbID string,
+	historyMessages []*schema.Message,
+	region *domain.GeoRegion,
+	aclFilter *domain.NodeACLFilter,
+	systemPrompt string,
+) ([]*schema.Message, []*domain.RankedNodeChunks, string, error) {
+	messages := make([]*schema.Message, 0)
+	if len(historyMessages) == 0 {
+		return messages, make([]*domain.RankedNodeChunks, 0), "", nil
 	}
-	switch model.Provider {
-	case domain.ModelProviderBrandDeepSeek:
-		config := &deepseek.ChatModelConfig{
-			BaseURL:     model.BaseURL,
-			APIKey:      model.APIKey,
-			Model:       string(model.Model),
-			Temperature: temprature,
-		}
-		chatModel, err := deepseek.NewChatModel(ctx, config)
-		if err != nil {
-			return nil, fmt.Errorf("create chat model failed: %w", err)
+
+	// query dataset id from kb
+	kb, err := u.kbRepo.GetKnowledgeBaseByID(ctx, kbID)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("get kb failed: %w", err)
+	}
+	query := u.RewriteQuery(ctx, kb, historyMessages)
+	rankedNodes, err := u.cachedRankedNodes(ctx, kb, query)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	// documents hidden from the viewer never reach the prompt or the references
+	rankedNodes = aclFilter.FilterNodes(rankedNodes)
+	// region variants of the visitor
+	rankedNodes = region.FilterNodes(rankedNodes)
+	u.logger.Info("ranked nodes", log.Int("rankedNodesCount", len(rankedNodes)))
+	messages, err = u.FormatRetrievedMessages(ctx, kb, historyMessages, rankedNodes, region, systemPrompt)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	return messages, rankedNodes, query, nil
+}
+
+// RewriteQuery standalone retrieval query of the last message of the history, condensed with the previous turns
+// by the rewrite model of the kb. the question as asked if the kb does not rewrite, it is the first turn
+// or the rewrite failed, retrieval is never blocked on it
+func (u *LLMUsecase) RewriteQuery(ctx context.Context, kb *domain.KnowledgeBase, historyMessages []*schema.Message) string {
+	question := historyMessages[len(historyMessages)-1].Content
+	if !kb.AnswerSettings.Retrieval.QueryRewrite || len(historyMessages) < 2 {
+		return question
+	}
+	rewriteModel, err := u.rewriteModel(ctx, kb)
+	if err != nil {
+		u.logger.Warn("get rewrite model failed, retrieve with the question", log.String("kb_id", kb.ID), log.Error(err))
+		return question
+	}
+	chatModel, err := u.GetChatModel(ctx, rewriteModel)
+	if err != nil {
+		u.logger.Warn("create rewrite model failed, retrieve with the question", log.String("kb_id", kb.ID), log.Error(err))
+		return question
+	}
+	ctx, cancel := context.WithTimeout(ctx, domain.QueryRewriteTimeout)
+	defer cancel()
+	output, err := u.Generate(ctx, chatModel, []*schema.Message{
+		schema.SystemMessage(domain.QueryRewritePrompt()),
+		schema.UserMessage(domain.QueryRewriteMessage(historyMessages[:len(historyMessages)-1], question)),
+	})
+	if err != nil {
+		u.logger.Warn("rewrite query failed, retrieve with the question", log.String("kb_id", kb.ID), log.Error(err))
+		return question
+	}
+	query := domain.ParseRewrittenQuery(output)
+	if query == "" {
+		return question
+	}
+	u.logger.Info("query rewritten", log.String("question", question), log.String("query", query))
+	return query
+}
+
+// rewriteModel chat model selected for query rewriting, the chat model of the kb if none is selected or it was deleted
+func (u *LLMUsecase) rewriteModel(ctx context.Context, kb *domain.KnowledgeBase) (*domain.Model, error) {
+	if id := kb.ModelSettings.RewriteModelID; id != "" {
+		model, err := u.modelRepo.GetModel(ctx, id, domain.ModelTypeChat)
+		if err == nil {
+			return model, nil
 		}
-		return chatModel, nil
-	case domain.ModelProviderBrandOllama:
-		baseUrl, err := utils.URLRemovePath(config.BaseURL)
-		if err != nil {
-			return nil, fmt.Errorf("ollama url parse failed: %w", err)
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
 		}
+	}
+	return u.modelRepo.GetKBModel(ctx, kb.ID, domain.ModelTypeChat)
+}
 
-		chatModel, err := ollama.NewChatModel(ctx, &ollama.ChatModelConfig{
-			BaseURL: baseUrl,
-			Timeout: config.Timeout,
-			Model:   config.Model,
-			Options: &api.Options{
-				Temperature: temprature,
-			},
-		})
-		if err != nil {
-			return nil, fmt.Errorf("create chat model failed: %w", err)
-		}
-		return chatModel, nil
-	default:
-		chatModel, err := openai.NewChatModel(ctx, config)
+// FormatRetrievedMessages prompt answering the last message of the history with the documents already retrieved
+// and filtered for it
+func (u *LLMUsecase) FormatRetrievedMessages(
+	ctx context.Context,
+	kb *domain.KnowledgeBase,
+	historyMessages []*schema.Message,
+	rankedNodes []*domain.RankedNodeChunks,
+	region *domain.GeoRegion,
+	systemPrompt string,
+) ([]*schema.Message, error) {
+	question := historyMessages[len(historyMessages)-1].Content
+	documents := domain.FormatNodeChunks(rankedNodes, kb.AccessSettings.BaseURL)
+	u.logger.Info("documents", log.String("documents", documents))
+
+	// the system prompt is edited by admins and not a go template
+	systemMessage := schema.SystemMessage(systemPrompt + kb.ComplianceSettings.Effective().PromptConstraints() + region.PromptConstraints() + u.glossaryPrompt(ctx, kb.ID, question+"\n"+documents))
+	template := prompt.FromMessages(schema.GoTemplate, schema.UserMessage(domain.UserQuestionFormatter))
+
+	formattedMessages, err := template.Format(ctx, map[string]any{
+		"CurrentDate": time.Now().Format("2006-01-02"),
+		"Question":    question,
+		"Documents":   documents,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("format messages failed: %w", err)
+	}
+	messages := append([]*schema.Message{systemMessage}, historyMessages[:len(historyMessages)-1]...)
+	return append(messages, formattedMessages...), nil
+}
+
+// glossaryPrompt definitions of the glossary terms mentioned in the question and documents, empty if the glossary is unavailable
+func (u *LLMUsecase) glossaryPrompt(ctx context.Context, kbID, text string) string {
+	terms, err := u.glossaryRepo.GetGlossaryTermList(ctx, kbID)
+	if err != nil {
+		u.logger.Warn("get glossary terms failed", log.String("kb_id", kbID), log.Error(err))
+		return ""
+	}
+	if len(terms) == 0 {
+		return ""
+	}
+	return domain.GlossaryPrompt(domain.NewGlossaryLinker(terms).Mentioned(text))
+}
+
+// cachedRankedNodes documents of the question, retrieved already if the draft was prefetched while typing
+func (u *LLMUsecase) cachedRankedNodes(ctx context.Context, kb *domain.KnowledgeBase, question string) ([]*domain.RankedNodeChunks, error) {
+	if key := domain.NormalizeQuestion(question); key != "" {
+		rankedNodes, err := u.retrievalCache.Get(ctx, kb.ID, key)
 		if err != nil {
-			return nil, fmt.Errorf("create chat model failed: %w", err)
+			u.logger.Warn("get prefetched documents failed", log.String("kb_id", kb.ID), log.Error(err))
+		} else if rankedNodes != nil {
+			u.logger.Info("use prefetched documents", log.Int("rankedNodesCount", len(rankedNodes)))
+			return rankedNodes, nil
 		}
-		return chatModel, nil
 	}
+	return u.retrieveNodes(ctx, kb, question, nil)
 }
 
-func (u *LLMUsecase) FormatConversationMessages(
-	ctx context.Context,
-	conversationID string,
-	kbID string,
-) ([]*schema.Message, []*domain.RankedNodeChunks, error) {
-	messages := make([]*schema.Message, 0)
-	rankedNodes := make([]*domain.RankedNodeChunks, 0)
+// PrefetchRetrieval retrieve documents of a draft question and cache them for the message sent with it,
+// return false if the draft is too short
+func (u *LLMUsecase) PrefetchRetrieval(ctx context.Context, kbID, question string) (bool, error) {
+	key := domain.NormalizeQuestion(question)
+	if utf8.RuneCountInString(key) < domain.RetrievalPrefetchMinRunes {
+		return false, nil
+	}
+	rankedNodes, err := u.retrievalCache.Get(ctx, kbID, key)
+	if err != nil {
+		return false, err
+	}
+	if rankedNodes != nil {
+		return true, nil
+	}
+	kb, err := u.kbRepo.GetKnowledgeBaseByID(ctx, kbID)
+	if err != nil {
+		return false, fmt.Errorf("get kb failed: %w", err)
+	}
+	rankedNodes, err = u.retrieveNodes(ctx, kb, question, nil)
+	if err != nil {
+		return false, err
+	}
+	if err := u.retrievalCache.Set(ctx, kbID, key, rankedNodes, domain.RetrievalPrefetchTTL); err != nil {
+		return false, err
+	}
+	return true, nil
+}
 
-	msgs, err := u.conversationRepo.GetConversationMessagesByID(ctx, conversationID)
+// RetrieveNodes documents of the question with the retrieval settings of kb, bypassing the prefetch cache
+func (u *LLMUsecase) RetrieveNodes(ctx context.Context, kb *domain.KnowledgeBase, question string) ([]*domain.RankedNodeChunks, error) {
+	return u.retrieveNodes(ctx, kb, question, nil)
+}
+
+// TraceRetrieval documents of the question as RetrieveNodes, with the candidates of each retrieval stage
+func (u *LLMUsecase) TraceRetrieval(ctx context.Context, kb *domain.KnowledgeBase, question string) (*domain.RetrievalTrace, []*domain.RankedNodeChunks, error) {
+	trace := &domain.RetrievalTrace{Query: question}
+	rankedNodes, err := u.retrieveNodes(ctx, kb, question, trace)
 	if err != nil {
-		return nil, nil, fmt.Errorf("get conversation messages failed: %w", err)
-	}
-	if len(msgs) > 0 {
-		historyMessages := make([]*schema.Message, 0)
-		for _, msg := range msgs {
-			switch msg.Role {
-			case schema.Assistant:
-				historyMessages = append(historyMessages, schema.AssistantMessage(msg.Content, nil))
-			case schema.User:
-				historyMessages = append(historyMessages, schema.UserMessage(msg.Content))
-			default:
-				continue
-			}
+		return nil, nil, err
+	}
+	return trace, rankedNodes, nil
+}
+
+// retrieveNodes documents of the question by vector search, fused with keyword matches if hybrid retrieval is on.
+// stages are recorded to the trace if not nil
+func (u *LLMUsecase) retrieveNodes(ctx context.Context, kb *domain.KnowledgeBase, question string, trace *domain.RetrievalTrace) ([]*domain.RankedNodeChunks, error) {
+	rankedNodes := make([]*domain.RankedNodeChunks, 0)
+	retrieval := kb.AnswerSettings.Retrieval
+	// the rerank stage replaces reranking in raglite
+	ragRerankModelID := kb.ModelSettings.RerankModelID
+	if retrieval.Rerank {
+		ragRerankModelID = ""
+	}
+	if trace != nil {
+		trace.Hybrid = retrieval.Hybrid
+		trace.Rerank = retrieval.Rerank
+		trace.RAGRerankModelID = ragRerankModelID
+	}
+	// get related documents from raglite
+	records, err := u.rag.QueryRecords(ctx, []string{kb.DatasetID}, question, ragRerankModelID)
+	if err != nil {
+		return nil, fmt.Errorf("get records from raglite failed: %w", err)
+	}
+	u.logger.Info("get related documents from raglite", log.Any("record_count", len(records)))
+	rankedNodesMap := make(map[string]*domain.RankedNodeChunks)
+	// get raw node by doc_id
+	if len(records) > 0 {
+		docIDs := lo.Uniq(lo.Map(records, func(item *domain.NodeContentChunk, _ int) string {
+			return item.DocID
+		}))
+		u.logger.Info("docIDs", log.Any("docIDs", docIDs))
+		docIDNode, err := u.nodeRepo.GetNodeReleasesByDocIDs(ctx, docIDs)
+		if err != nil {
+			return nil, fmt.Errorf("get nodes by ids failed: %w", err)
 		}
-		if len(historyMessages) > 0 {
-			question := historyMessages[len(historyMessages)-1].Content
-
-			template := prompt.FromMessages(schema.GoTemplate,
-				schema.SystemMessage(domain.SystemPrompt),
-				schema.UserMessage(domain.UserQuestionFormatter),
-			)
-			// query dataset id from kb
-			kb, err := u.kbRepo.GetKnowledgeBaseByID(ctx, kbID)
-			if err != nil {
-				return nil, nil, fmt.Errorf("get kb failed: %w", err)
-			}
-			// get related documents from raglite
-			records, err := u.rag.QueryRecords(ctx, []string{kb.DatasetID}, question)
-			if err != nil {
-				return nil, nil, fmt.Errorf("get records from raglite failed: %w", err)
-			}
-			u.logger.Info("get related documents from raglite", log.Any("record_count", len(records)))
-			rankedNodesMap := make(map[string]*domain.RankedNodeChunks)
-			// get raw node by doc_id
-			if len(records) > 0 {
-				docIDs := lo.Uniq(lo.Map(records, func(item *domain.NodeContentChunk, _ int) string {
-					return item.DocID
-				}))
-				u.logger.Info("docIDs", log.Any("docIDs", docIDs))
-				docIDNode, err := u.nodeRepo.GetNodeReleasesByDocIDs(ctx, docIDs)
-				if err != nil {
-					return nil, nil, fmt.Errorf("get nodes by ids failed: %w", err)
-				}
-				u.logger.Info("get nodes by ids", log.Any("docIDNode", docIDNode))
-				for _, record := range records {
-					if nodeChunk, ok := rankedNodesMap[record.DocID]; !ok {
-						if docNode, ok := docIDNode[record.DocID]; ok {
-							rankNodeChunk := &domain.RankedNodeChunks{
-								NodeID:      docNode.NodeID,
-								NodeName:    docNode.Name,
-								NodeSummary: docNode.Meta.Summary,
-								Chunks:      []*domain.NodeContentChunk{record},
-							}
-							rankedNodes = append(rankedNodes, rankNodeChunk)
-							rankedNodesMap[record.DocID] = rankNodeChunk
-						}
-					} else {
-						nodeChunk.Chunks = append(nodeChunk.Chunks, record)
+		u.logger.Info("get nodes by ids", log.Any("docIDNode", docIDNode))
+		for _, record := range records {
+			if nodeChunk, ok := rankedNodesMap[record.DocID]; !ok {
+				if docNode, ok := docIDNode[record.DocID]; ok {
+					rankNodeChunk := &domain.RankedNodeChunks{
+						NodeID:      docNode.NodeID,
+						NodeName:    docNode.Name,
+						NodeSummary: docNode.Meta.Summary,
+						Tags:        docNode.Meta.Tags,
+						Chunks:      []*domain.NodeContentChunk{record},
 					}
+					rankedNodes = append(rankedNodes, rankNodeChunk)
+					rankedNodesMap[record.DocID] = rankNodeChunk
 				}
+			} else {
+				nodeChunk.Chunks = append(nodeChunk.Chunks, record)
 			}
-			u.logger.Info("ranked nodes", log.Int("rankedNodesCount", len(rankedNodes)))
-			documents := domain.FormatNodeChunks(rankedNodes, kb.AccessSettings.BaseURL)
-			u.logger.Info("documents", log.String("documents", documents))
-
-			formattedMessages, err := template.Format(ctx, map[string]any{
-				"CurrentDate": time.Now().Format("2006-01-02"),
-				"Question":    question,
-				"Documents":   documents,
-			})
-			if err != nil {
-				return nil, nil, fmt.Errorf("format messages failed: %w", err)
-			}
-			messages = slices.Insert(formattedMessages, 1, historyMessages[:len(historyMessages)-1]...)
 		}
 	}
-	return messages, rankedNodes, nil
+	trace.Record(domain.RetrievalStageVector, rankedNodes)
+	if retrieval.Hybrid {
+		keywordNodes, err := u.keywordRankedNodes(ctx, kb.ID, question)
+		if err != nil {
+			return nil, fmt.Errorf("keyword search failed: %w", err)
+		}
+		u.logger.Info("get related documents by keyword", log.Int("node_count", len(keywordNodes)))
+		rankedNodes = domain.FuseRankedNodes(rankedNodes, keywordNodes, retrieval.EffectiveKeywordWeight())
+		if trace != nil {
+			trace.KeywordQuery = domain.ParseKeywordQuery(question)
+		}
+		trace.Record(domain.RetrievalStageKeyword, keywordNodes)
+		trace.Record(domain.RetrievalStageFused, rankedNodes)
+	}
+	if retrieval.Rerank && len(rankedNodes) > 0 {
+		rankedNodes = u.rerankNodes(ctx, kb, question, rankedNodes)
+		trace.Record(domain.RetrievalStageReranked, rankedNodes)
+	}
+	return rankedNodes, nil
+}
+
+// rerankNodes nodes reordered by the rerank model of the kb, in the retrieval order if reranking fails
+func (u *LLMUsecase) rerankNodes(ctx context.Context, kb *domain.KnowledgeBase, question string, rankedNodes []*domain.RankedNodeChunks) []*domain.RankedNodeChunks {
+	model, err := u.modelRepo.GetKBModel(ctx, kb.ID, domain.ModelTypeRerank)
+	if err != nil {
+		u.logger.Warn("get rerank model failed, keep retrieval order", log.String("kb_id", kb.ID), log.Error(err))
+		return rankedNodes
+	}
+	documents := make([]string, 0)
+	for _, node := range rankedNodes {
+		for _, chunk := range node.Chunks {
+			documents = append(documents, chunk.Content)
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, domain.RerankTimeout)
+	defer cancel()
+	scores, err := reranker.Rerank(ctx, &reranker.Model{
+		Model:   model.Model,
+		BaseURL: model.BaseURL,
+		APIKey:  model.APIKey,
+		Headers: utils.GetHeaderMap(model.APIHeader),
+	}, question, documents)
+	if err != nil {
+		u.logger.Warn("rerank failed, keep retrieval order", log.String("kb_id", kb.ID), log.String("model", model.Model), log.Error(err))
+		return rankedNodes
+	}
+	retrieval := kb.AnswerSettings.Retrieval
+	reranked := domain.RerankNodes(rankedNodes, scores, retrieval.RerankTopN, retrieval.RerankMinScore)
+	u.logger.Info("reranked documents", log.Int("before", len(rankedNodes)), log.Int("after", len(reranked)))
+	return reranked
 }
 
+// keywordRankedNodes nodes matching words and identifiers of the question, best match first
+func (u *LLMUsecase) keywordRankedNodes(ctx context.Context, kbID, question string) ([]*domain.RankedNodeChunks, error) {
+	keywords := domain.ParseKeywordQuery(question)
+	if keywords.Empty() {
+		return nil, nil
+	}
+	matches, err := u.nodeRepo.KeywordSearchNodeReleases(ctx, kbID, keywords, domain.HybridKeywordLimit)
+	if err != nil {
+		return nil, err
+	}
+	nodes := make([]*domain.RankedNodeChunks, 0, len(matches))
+	for _, match := range matches {
+		nodes = append(nodes, match.ToRankedNodeChunks(keywords))
+	}
+	return nodes, nil
+}
+
+// ChatWithAgent stream answer of the model, usage is counted by the tokenizer of modelName if the model does not report it
 func (u *LLMUsecase) ChatWithAgent(
 	ctx context.Context,
 	chatModel model.BaseChatModel,
+	modelName string,
 	messages []*schema.Message,
 	usage *schema.TokenUsage,
 	onChunk func(ctx context.Context, dataType, chunk string) error,
 ) error {
-	resp, err := chatModel.Stream(ctx, messages)
-	if err != nil {
-		return fmt.Errorf("stream failed: %w", err)
+	return u.ChatWithContinuation(ctx, chatModel, modelName, messages, 0, usage, onChunk)
+}
+
+// ChatWithContinuation stream answer of the model, an answer cut off by the max tokens of the model is continued by
+// up to maxRounds more requests streamed as the rest of the same answer. usage is the sum of all requests
+func (u *LLMUsecase) ChatWithContinuation(
+	ctx context.Context,
+	chatModel model.BaseChatModel,
+	modelName string,
+	messages []*schema.Message,
+	maxRounds int,
+	usage *schema.TokenUsage,
+	onChunk func(ctx context.Context, dataType, chunk string) error,
+	opts ...model.Option,
+) error {
+	stream := &answerStream{onChunk: onChunk}
+	roundMessages := messages
+	for round := 0; ; round++ {
+		roundUsage := schema.TokenUsage{}
+		finishReason, err := stream.run(ctx, chatModel, roundMessages, &roundUsage, opts...)
+		if roundUsage.TotalTokens == 0 {
+			roundUsage.PromptTokens = tokenizer.CountMessages(modelName, roundMessages)
+			roundUsage.CompletionTokens = tokenizer.Count(modelName, stream.round.String())
+			roundUsage.TotalTokens = roundUsage.PromptTokens + roundUsage.CompletionTokens
+		}
+		usage.PromptTokens += roundUsage.PromptTokens
+		usage.CompletionTokens += roundUsage.CompletionTokens
+		usage.TotalTokens += roundUsage.TotalTokens
+		if err != nil {
+			return err
+		}
+		if round >= maxRounds || !domain.IsTruncatedFinish(finishReason) {
+			return nil
+		}
+		u.logger.Info("answer truncated by max tokens, continuing", log.String("model", modelName), log.Int("round", round+1))
+		// the model goes on from its answer so far, without the reasoning
+		answer := strings.TrimSpace(thinkBlockRegex.ReplaceAllString(stream.answer.String(), ""))
+		roundMessages = append(slices.Clip(messages), schema.AssistantMessage(answer, nil), schema.UserMessage(domain.ContinuationPrompt))
 	}
-	firstReasoning := false
-	firstData := false
+}
 
+// continuationOverlapRunes start of a continuation buffered to drop text repeating the end of the answer
+const continuationOverlapRunes = 200
+
+// answerStream answer streamed by one or more requests, reasoning is wrapped in a think block
+type answerStream struct {
+	onChunk func(ctx context.Context, dataType, chunk string) error
+	// all text streamed, and text of the current request
+	answer strings.Builder
+	round  strings.Builder
+	// a think block was opened, and closed by the first answer text
+	reasoning bool
+	answering bool
+	rounds    int
+}
+
+// run stream a request, return the finish reason of the model
+func (s *answerStream) run(ctx context.Context, chatModel model.BaseChatModel, messages []*schema.Message, usage *schema.TokenUsage, opts ...model.Option) (string, error) {
+	s.round.Reset()
+	continued := s.rounds > 0
+	s.rounds++
+	resp, err := chatModel.Stream(ctx, messages, opts...)
+	if err != nil {
+		return "", fmt.Errorf("stream failed: %w", err)
+	}
+	defer resp.Close()
+	// start of a continuation until it is known whether it repeats the answer
+	var pending strings.Builder
+	flushed := !continued
+	finishReason := ""
 	for {
 		msg, err := resp.Recv()
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			return fmt.Errorf("recv failed: %w", err)
+			return finishReason, fmt.Errorf("recv failed: %w", err)
+		}
+		if msg.ResponseMeta != nil {
+			if msg.ResponseMeta.Usage != nil {
+				*usage = *msg.ResponseMeta.Usage
+			}
+			if msg.ResponseMeta.FinishReason != "" {
+				finishReason = msg.ResponseMeta.FinishReason
+			}
 		}
-		reasoning, ok := deepseek.GetReasoningContent(msg)
-		if ok {
-			if !firstReasoning {
-				firstReasoning = true
+		if reasoning, ok := deepseek.GetReasoningContent(msg); ok {
+			// reasoning of continuations is not shown, the answer goes on
+			if continued {
+				continue
+			}
+			if !s.reasoning {
+				s.reasoning = true
 				reasoning = "<think>" + reasoning
 			}
-			if err := onChunk(ctx, "data", reasoning); err != nil {
-				return fmt.Errorf("on chunk reasoning: %w", err)
+			if err := s.emit(ctx, reasoning); err != nil {
+				return finishReason, fmt.Errorf("on chunk reasoning: %w", err)
 			}
 			continue
 		}
-		if firstReasoning && !firstData {
-			firstData = true
-			msg.Content = "</think>\n" + msg.Content
-			if err := onChunk(ctx, "data", msg.Content); err != nil {
-				return fmt.Errorf("on chunk data: %w", err)
-			}
+		content := msg.Content
+		if content == "" {
 			continue
 		}
-		if err := onChunk(ctx, "data", msg.Content); err != nil {
-			return fmt.Errorf("on chunk data: %w", err)
+		if !flushed {
+			pending.WriteString(content)
+			if utf8.RuneCountInString(pending.String()) < continuationOverlapRunes {
+				continue
+			}
+			content = trimOverlap(s.answer.String(), pending.String())
+			flushed = true
 		}
-
-		// set to usage
-		if msg.ResponseMeta.Usage != nil {
-			*usage = *msg.ResponseMeta.Usage
+		if err := s.emitContent(ctx, content); err != nil {
+			return finishReason, err
 		}
 	}
+	if !flushed {
+		if err := s.emitContent(ctx, trimOverlap(s.answer.String(), pending.String())); err != nil {
+			return finishReason, err
+		}
+	}
+	return finishReason, nil
+}
 
+func (s *answerStream) emitContent(ctx context.Context, content string) error {
+	if s.reasoning && !s.answering {
+		s.answering = true
+		content = "</think>\n" + content
+	}
+	if content == "" {
+		return nil
+	}
+	if err := s.emit(ctx, content); err != nil {
+		return fmt.Errorf("on chunk data: %w", err)
+	}
 	return nil
 }
 
+func (s *answerStream) emit(ctx context.Context, chunk string) error {
+	s.answer.WriteString(chunk)
+	s.round.WriteString(chunk)
+	return s.onChunk(ctx, "data", chunk)
+}
+
+// trimOverlap continuation without its start repeating the end of the answer, overlaps shorter than a few runes
+// are kept as they may be a coincidence
+func trimOverlap(answer, continuation string) string {
+	const minOverlap = 8
+	tail := []rune(answer)
+	if len(tail) > continuationOverlapRunes {
+		tail = tail[len(tail)-continuationOverlapRunes:]
+	}
+	head := []rune(continuation)
+	for n := min(len(tail), len(head)); n >= minOverlap; n-- {
+		if string(tail[len(tail)-n:]) == string(head[:n]) {
+			return string(head[n:])
+		}
+	}
+	return continuation
+}
+
 func (u *LLMUsecase) Generate(
 	ctx context.Context,
 	chatModel model.BaseChatModel,
@@ -270,6 +617,18 @@ func (u *LLMUsecase) Generate(
 func (u *LLMUsecase) CheckModel(ctx context.Context, req *domain.CheckModelReq) (*domain.CheckModelResp, error) {
 	checkResp := &domain.CheckModelResp{}
 
+	if req.Type == domain.ModelTypeASR {
+		if err := asr.Check(ctx, &asr.Model{
+			Model:   req.Model,
+			BaseURL: req.BaseURL,
+			APIKey:  req.APIKey,
+			Headers: utils.GetHeaderMap(req.APIHeader),
+		}); err != nil {
+			checkResp.Error = err.Error()
+		}
+		return checkResp, nil
+	}
+
 	if req.Type == domain.ModelTypeEmbedding || req.Type == domain.ModelTypeRerank {
 		url := req.BaseURL
 		reqBody := map[string]any{}
@@ -349,6 +708,74 @@ func (u *LLMUsecase) CheckModel(ctx context.Context, req *domain.CheckModelReq)
 	return checkResp, nil
 }
 
+// CheckSavedModel check connectivity of a configured model
+func (u *LLMUsecase) CheckSavedModel(ctx context.Context, id string) (*domain.CheckModelResp, error) {
+	model, err := u.modelRepo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return u.CheckModel(ctx, checkModelReqOf(&model.ModelListItem))
+}
+
+// CheckKBModels check connectivity of the chat, embedding and rerank models the kb uses
+func (u *LLMUsecase) CheckKBModels(ctx context.Context, kbID string) (*domain.CheckKBModelsResp, error) {
+	chat, err := u.checkKBModel(ctx, kbID, domain.ModelTypeChat)
+	if err != nil {
+		return nil, err
+	}
+	embedding, err := u.checkKBModel(ctx, kbID, domain.ModelTypeEmbedding)
+	if err != nil {
+		return nil, err
+	}
+	rerank, err := u.checkKBModel(ctx, kbID, domain.ModelTypeRerank)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.CheckKBModelsResp{Chat: chat, Embedding: embedding, Rerank: rerank}, nil
+}
+
+func (u *LLMUsecase) checkKBModel(ctx context.Context, kbID string, modelType domain.ModelType) (*domain.KBModelCheck, error) {
+	model, err := u.modelRepo.GetKBModel(ctx, kbID, modelType)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	checkResp, err := u.CheckModel(ctx, checkModelReqOf(&domain.ModelListItem{
+		Provider:   model.Provider,
+		Model:      model.Model,
+		APIKey:     model.APIKey,
+		APIHeader:  model.APIHeader,
+		BaseURL:    model.BaseURL,
+		APIVersion: model.APIVersion,
+		Type:       model.Type,
+	}))
+	if err != nil {
+		return nil, err
+	}
+	return &domain.KBModelCheck{
+		ModelID:        model.ID,
+		Model:          model.Model,
+		Provider:       model.Provider,
+		CheckModelResp: *checkResp,
+	}, nil
+}
+
+func checkModelReqOf(model *domain.ModelListItem) *domain.CheckModelReq {
+	return &domain.CheckModelReq{
+		BaseModelInfo: domain.BaseModelInfo{
+			Provider:   model.Provider,
+			Model:      model.Model,
+			BaseURL:    model.BaseURL,
+			APIKey:     model.APIKey,
+			APIHeader:  model.APIHeader,
+			APIVersion: model.APIVersion,
+			Type:       model.Type,
+		},
+	}
+}
+
 type headerTransport struct {
 	headers map[string]string
 	base    http.RoundTripper
@@ -405,3 +832,93 @@ func (u *LLMUsecase) SummaryNode(ctx context.Context, model *domain.Model, name,
 	}
 	return summary, nil
 }
+
+// GenerateConversationTitle short title of a conversation summarizing its first question
+func (u *LLMUsecase) GenerateConversationTitle(ctx context.Context, model *domain.Model, question string) (string, error) {
+	chatModel, err := u.GetChatModel(ctx, model)
+	if err != nil {
+		return "", err
+	}
+	title, err := u.Generate(ctx, chatModel, []*schema.Message{
+		schema.SystemMessage(domain.ConversationTitlePrompt),
+		schema.UserMessage(question),
+	})
+	if err != nil {
+		return "", err
+	}
+	return domain.CleanConversationTitle(title), nil
+}
+
+// GenerateFollowUps follow-up questions the user may ask after the answer, each answerable by one of the documents
+// retrieved for the question
+func (u *LLMUsecase) GenerateFollowUps(ctx context.Context, model *domain.Model, question, answer string, rankedNodes []*domain.RankedNodeChunks, count int) (domain.FollowUpQuestions, error) {
+	chatModel, err := u.GetChatModel(ctx, model)
+	if err != nil {
+		return nil, err
+	}
+	output, err := u.Generate(ctx, chatModel, []*schema.Message{
+		schema.SystemMessage(domain.FollowUpPrompt(count)),
+		schema.UserMessage(domain.FollowUpMessage(question, answer, rankedNodes)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return domain.ParseFollowUps(output, count, rankedNodes), nil
+}
+
+// GenerateRetrievedAnswer answer of the question as a chat without history would give it with the documents already retrieved
+func (u *LLMUsecase) GenerateRetrievedAnswer(
+	ctx context.Context,
+	kb *domain.KnowledgeBase,
+	model *domain.Model,
+	systemPrompt string,
+	question string,
+	rankedNodes []*domain.RankedNodeChunks,
+) (string, error) {
+	messages, err := u.FormatRetrievedMessages(ctx, kb, []*schema.Message{schema.UserMessage(question)}, rankedNodes, nil, systemPrompt)
+	if err != nil {
+		return "", err
+	}
+	chatModel, err := u.GetChatModel(ctx, model)
+	if err != nil {
+		return "", err
+	}
+	answer, err := u.Generate(ctx, chatModel, messages)
+	if err != nil {
+		return "", err
+	}
+	if _, after, ok := strings.Cut(answer, "</think>"); ok {
+		answer = after
+	}
+	return strings.TrimSpace(answer), nil
+}
+
+// JudgeAnswer scores of the answer against the expected answer and the documents it was given, by the model as judge
+func (u *LLMUsecase) JudgeAnswer(ctx context.Context, model *domain.Model, question, expectedAnswer, documents, answer string) (*domain.EvalJudgement, error) {
+	chatModel, err := u.GetChatModel(ctx, model)
+	if err != nil {
+		return nil, err
+	}
+	output, err := u.Generate(ctx, chatModel, []*schema.Message{
+		schema.SystemMessage(domain.EvalJudgePrompt()),
+		schema.UserMessage(domain.EvalJudgeMessage(question, expectedAnswer, documents, answer)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return domain.ParseEvalJudgement(output)
+}
+
+// Embed get embeddings of texts by openai compatible embedding api, by the embedder service if configured
+func (u *LLMUsecase) Embed(ctx context.Context, model *domain.Model, texts []string) ([][]float32, error) {
+	m := &embedder.Model{
+		Model:   model.Model,
+		BaseURL: model.BaseURL,
+		APIKey:  model.APIKey,
+		Headers: utils.GetHeaderMap(model.APIHeader),
+	}
+	if u.embedder != nil {
+		return u.embedder.Embed(ctx, m, texts)
+	}
+	return embedder.EmbedOpenAI(ctx, m, texts)
+}
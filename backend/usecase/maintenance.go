@@ -0,0 +1,100 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/repo/pg"
+)
+
+type MaintenanceUsecase struct {
+	settingRepo *pg.SettingRepository
+	kbUsecase   *KnowledgeBaseUsecase
+	logger      *log.Logger
+}
+
+func NewMaintenanceUsecase(settingRepo *pg.SettingRepository, kbUsecase *KnowledgeBaseUsecase, logger *log.Logger) *MaintenanceUsecase {
+	return &MaintenanceUsecase{
+		settingRepo: settingRepo,
+		kbUsecase:   kbUsecase,
+		logger:      logger.WithModule("usecase.maintenance"),
+	}
+}
+
+func (u *MaintenanceUsecase) GetMaintenance(ctx context.Context, kbID string) (*domain.MaintenanceResp, error) {
+	resp := &domain.MaintenanceResp{}
+	if err := u.settingRepo.GetSetting(ctx, domain.SettingKeyMaintenance, &resp.Global); err != nil {
+		return nil, err
+	}
+	if kbID != "" {
+		kb, err := u.kbUsecase.GetKnowledgeBase(ctx, kbID)
+		if err != nil {
+			return nil, err
+		}
+		resp.KB = &kb.MaintenanceSettings
+	}
+	return resp, nil
+}
+
+func (u *MaintenanceUsecase) UpdateMaintenance(ctx context.Context, req *domain.UpdateMaintenanceReq) error {
+	settings := &domain.MaintenanceSettings{
+		ReadOnly: req.ReadOnly,
+		Message:  req.Message,
+	}
+	if req.KBID == "" {
+		if err := u.settingRepo.UpdateSetting(ctx, domain.SettingKeyMaintenance, settings); err != nil {
+			return err
+		}
+	} else if err := u.kbUsecase.UpdateKnowledgeBase(ctx, &domain.UpdateKnowledgeBaseReq{
+		ID:                  req.KBID,
+		MaintenanceSettings: settings,
+	}); err != nil {
+		return err
+	}
+	u.logger.Info("maintenance settings updated", log.String("kb_id", req.KBID), log.Any("read_only", req.ReadOnly))
+	return nil
+}
+
+// CheckWritable return ErrReadOnlyMode if writes are locked globally or on the kb.
+// kbID is empty if the kb of the write is unknown, which may be any kb, so writes are locked while any kb is read-only
+func (u *MaintenanceUsecase) CheckWritable(ctx context.Context, kbID string) error {
+	var global domain.MaintenanceSettings
+	if err := u.settingRepo.GetSetting(ctx, domain.SettingKeyMaintenance, &global); err != nil {
+		return err
+	}
+	if global.ReadOnly {
+		return readOnlyError(global.Message)
+	}
+	if kbID == "" {
+		readOnlyKBIDs, err := u.kbUsecase.GetReadOnlyKnowledgeBaseIDs(ctx)
+		if err != nil {
+			return err
+		}
+		if len(readOnlyKBIDs) > 0 {
+			return readOnlyError("the knowledge base of the request is unknown while some are read-only")
+		}
+		return nil
+	}
+	kb, err := u.kbUsecase.GetKnowledgeBase(ctx, kbID)
+	if err != nil {
+		// a kb which does not exist is left to the handler, it can not be written
+		if errors.Is(err, domain.ErrKBNotFound) {
+			return nil
+		}
+		return err
+	}
+	if kb.MaintenanceSettings.ReadOnly {
+		return readOnlyError(kb.MaintenanceSettings.Message)
+	}
+	return nil
+}
+
+func readOnlyError(message string) error {
+	if message == "" {
+		return domain.ErrReadOnlyMode
+	}
+	return fmt.Errorf("%w: %s", domain.ErrReadOnlyMode, message)
+}
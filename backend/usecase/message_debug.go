@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/cloudwego/eino/schema"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/pkg/tokenizer"
+	"github.com/chaitin/panda-wiki/repo/pg"
+)
+
+type MessageDebugUsecase struct {
+	conversationRepo *pg.ConversationRepository
+	kbRepo           *pg.KnowledgeBaseRepository
+	appRepo          *pg.AppRepository
+	llmUsecase       *LLMUsecase
+	chatUsecase      *ChatUsecase
+	logger           *log.Logger
+}
+
+func NewMessageDebugUsecase(
+	conversationRepo *pg.ConversationRepository,
+	kbRepo *pg.KnowledgeBaseRepository,
+	appRepo *pg.AppRepository,
+	llmUsecase *LLMUsecase,
+	chatUsecase *ChatUsecase,
+	logger *log.Logger,
+) *MessageDebugUsecase {
+	return &MessageDebugUsecase{
+		conversationRepo: conversationRepo,
+		kbRepo:           kbRepo,
+		appRepo:          appRepo,
+		llmUsecase:       llmUsecase,
+		chatUsecase:      chatUsecase,
+		logger:           logger.WithModule("usecase.message_debug"),
+	}
+}
+
+// DebugMessage replay retrieval and prompt of the answer as a chat would do now, with the stages of retrieval
+func (u *MessageDebugUsecase) DebugMessage(ctx context.Context, req *domain.MessageDebugReq) (*domain.MessageDebugResp, error) {
+	message, err := u.conversationRepo.GetKBConversationMessage(ctx, req.KBID, req.MessageID)
+	if err != nil {
+		return nil, err
+	}
+	if message.Role != schema.Assistant {
+		return nil, domain.ErrMessageNotAnswer
+	}
+	msgs, err := u.conversationRepo.GetConversationMessagesByID(ctx, message.ConversationID)
+	if err != nil {
+		return nil, err
+	}
+	// history up to the question of the answer
+	if i := slices.IndexFunc(msgs, func(msg *domain.ConversationMessage) bool { return msg.ID == message.ID }); i >= 0 {
+		msgs = msgs[:i]
+	}
+	history := HistoryMessages(msgs)
+	if len(history) == 0 || history[len(history)-1].Role != schema.User {
+		return nil, domain.ErrMessageNotAnswer
+	}
+	question := history[len(history)-1].Content
+	kb, err := u.kbRepo.GetKnowledgeBaseByID(ctx, req.KBID)
+	if err != nil {
+		return nil, err
+	}
+	app, err := u.appRepo.GetAppDetail(ctx, message.AppID)
+	if err != nil {
+		return nil, fmt.Errorf("get app of message failed: %w", err)
+	}
+
+	// the query the chat retrieved with is replayed instead of rewriting the question again
+	query := question
+	if message.RetrievalQuery != "" {
+		query = message.RetrievalQuery
+	}
+	trace, rankedNodes, err := u.llmUsecase.TraceRetrieval(ctx, kb, query)
+	if err != nil {
+		return nil, err
+	}
+	region := u.chatUsecase.resolveRegion(ctx, kb, message.RemoteIP)
+	rankedNodes = region.FilterNodes(rankedNodes)
+	trace.Record(domain.RetrievalStageFiltered, rankedNodes)
+
+	citation := app.Settings.Citation.StyleOrDefault()
+	systemPrompt, err := u.chatUsecase.systemPromptVersion(ctx, app, kb, citation, "", message.PromptTemplateVersion)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := u.llmUsecase.FormatRetrievedMessages(ctx, kb, history, rankedNodes, region, systemPrompt)
+	if err != nil {
+		return nil, err
+	}
+	messages = tokenizer.TrimHistory(message.Model, messages, domain.HistoryTokenBudget)
+	prompt := make([]*domain.PromptMessage, 0, len(messages))
+	for _, msg := range messages {
+		prompt = append(prompt, &domain.PromptMessage{Role: string(msg.Role), Content: msg.Content})
+	}
+
+	notes := []string{
+		"current documents and retrieval settings are used, they may have changed since the answer",
+		"the prompt template version of the answer is replayed, answers saved before the version was recorded are replayed with the built-in prompt",
+		"documents hidden by node acls are not filtered and the locale variable is the default, the viewer and locale of the chat are not recorded",
+	}
+	if message.Route != "" && message.Route != domain.QuestionRouteRAG {
+		notes = append(notes, fmt.Sprintf("the answer was routed to %s and did not use retrieval or the model", message.Route))
+	}
+	if message.RetrievalQuery != "" {
+		notes = append(notes, fmt.Sprintf("the question was rewritten with the conversation history to %q for retrieval", message.RetrievalQuery))
+	}
+	if message.LowConfidence {
+		notes = append(notes, "the answer was a low confidence reply of reference links, the model was not called")
+	}
+	return &domain.MessageDebugResp{
+		Message:   message,
+		Question:  question,
+		Retrieval: trace,
+		Prompt:    prompt,
+		Model: &domain.MessageDebugModel{
+			Provider:              message.Provider,
+			Model:                 message.Model,
+			ModelID:               message.ModelID,
+			Failovers:             message.Failovers,
+			Temperature:           domain.ChatModelTemperature,
+			MaxContinuationRounds: app.Settings.Continuation.RoundsOrDefault(),
+			StopSequences:         kb.ComplianceSettings.Effective().StopSequences,
+			HistoryTokenBudget:    domain.HistoryTokenBudget,
+			CitationStyle:         citation,
+			PromptTemplateVersion: message.PromptTemplateVersion,
+		},
+		Notes: notes,
+	}, nil
+}
@@ -2,12 +2,7 @@ package usecase
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
-	"net/url"
-	"path"
 	"time"
 
 	"github.com/cloudwego/eino/schema"
@@ -19,7 +14,6 @@ import (
 	"github.com/chaitin/panda-wiki/repo/mq"
 	"github.com/chaitin/panda-wiki/repo/pg"
 	"github.com/chaitin/panda-wiki/store/rag"
-	"github.com/chaitin/panda-wiki/utils"
 )
 
 type ModelUsecase struct {
@@ -113,9 +107,7 @@ func (u *ModelUsecase) initEmbeddingAndRerankModel(ctx context.Context) error {
 }
 
 func (u *ModelUsecase) Create(ctx context.Context, model *domain.Model) error {
-	if err := u.modelRepo.Create(ctx, model); err != nil {
-		return err
-	}
+	// embedding and rerank models keep the id of raglite, kbs select them by it
 	if model.Type == domain.ModelTypeEmbedding || model.Type == domain.ModelTypeRerank {
 		if id, err := u.ragStore.AddModel(ctx, model); err != nil {
 			return err
@@ -123,8 +115,11 @@ func (u *ModelUsecase) Create(ctx context.Context, model *domain.Model) error {
 			model.ID = id
 		}
 	}
+	if err := u.modelRepo.Create(ctx, model); err != nil {
+		return err
+	}
 	if model.Type == domain.ModelTypeEmbedding {
-		return u.TriggerUpsertRecords(ctx)
+		return u.TriggerUpsertRecords(ctx, model.ID)
 	}
 	return nil
 }
@@ -133,44 +128,13 @@ func (u *ModelUsecase) GetList(ctx context.Context) ([]*domain.ModelListItem, er
 	return u.modelRepo.GetList(ctx)
 }
 
-// trigger upsert records after embedding model is updated or created
-func (u *ModelUsecase) TriggerUpsertRecords(ctx context.Context) error {
-	// update to new dataset
-	kbList, err := u.kbRepo.GetKnowledgeBaseList(ctx)
+// trigger upsert records of kbs embedding with the model after it is updated or created
+func (u *ModelUsecase) TriggerUpsertRecords(ctx context.Context, modelID string) error {
+	kbs, err := u.kbRepo.GetKnowledgeBasesOfEmbeddingModel(ctx, modelID)
 	if err != nil {
 		return fmt.Errorf("get knowledge base list failed: %w", err)
 	}
-	for _, kb := range kbList {
-		newDatasetID, err := u.ragStore.CreateKnowledgeBase(ctx)
-		if err != nil {
-			return fmt.Errorf("create new dataset failed: %w", err)
-		}
-		if err := u.ragStore.DeleteKnowledgeBase(ctx, kb.DatasetID); err != nil {
-			return fmt.Errorf("delete old dataset failed: %w", err)
-		}
-		if err := u.kbRepo.UpdateDatasetID(ctx, kb.ID, newDatasetID); err != nil {
-			return fmt.Errorf("update knowledge base dataset id failed: %w", err)
-		}
-	}
-	// traverse all nodes
-	err = u.nodeRepo.TraverseNodesByCursor(ctx, func(nodeRelease *domain.NodeRelease) error {
-		// async upsert vector content via mq
-		nodeContentVectorRequests := []*domain.NodeReleaseVectorRequest{
-			{
-				KBID:          nodeRelease.KBID,
-				NodeReleaseID: nodeRelease.ID,
-				Action:        "upsert",
-			},
-		}
-		if err := u.ragRepo.AsyncUpdateNodeReleaseVector(ctx, nodeContentVectorRequests); err != nil {
-			return err
-		}
-		return nil
-	})
-	if err != nil {
-		return err
-	}
-	return nil
+	return rebuildKBDatasets(ctx, u.ragRepo, kbs)
 }
 
 func (u *ModelUsecase) Get(ctx context.Context, id string) (*domain.ModelDetailResp, error) {
@@ -193,7 +157,7 @@ func (u *ModelUsecase) Update(ctx context.Context, req *domain.UpdateModelReq) e
 		}
 	}
 	if req.Type == domain.ModelTypeEmbedding {
-		return u.TriggerUpsertRecords(ctx)
+		return u.TriggerUpsertRecords(ctx, req.ID)
 	}
 	return nil
 }
@@ -203,159 +167,18 @@ func (u *ModelUsecase) GetChatModel(ctx context.Context) (*domain.Model, error)
 }
 
 func (u *ModelUsecase) UpdateUsage(ctx context.Context, modelID string, usage *schema.TokenUsage) error {
-	return u.modelRepo.UpdateUsage(ctx, modelID, usage)
+	if err := u.modelRepo.UpdateUsage(ctx, modelID, usage); err != nil {
+		return err
+	}
+	return u.recordMonthlyUsage(ctx, modelID, usage)
 }
 
 func (u *ModelUsecase) GetUserModelList(ctx context.Context, req *domain.GetProviderModelListReq) (*domain.GetProviderModelListResp, error) {
-	switch provider := domain.ModelProvider(req.Provider); provider {
-	case domain.ModelProviderBrandMoonshot, domain.ModelProviderBrandDeepSeek, domain.ModelProviderBrandAzureOpenAI, domain.ModelProviderBrandVolcengine:
-		return &domain.GetProviderModelListResp{
-			Models: domain.ModelProviderBrandModelsList[domain.ModelProvider(req.Provider)],
-		}, nil
-	case domain.ModelProviderBrandOpenAI, domain.ModelProviderBrandHunyuan, domain.ModelProviderBrandBaiLian:
-		u, err := url.Parse(req.BaseURL)
-		if err != nil {
-			return nil, err
-		}
-		u.Path = path.Join(u.Path, "/models")
-		request, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
-		if err != nil {
-			return nil, err
-		}
-		request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", req.APIKey))
-		resp, err := http.DefaultClient.Do(request)
-		if err != nil {
-			return nil, err
-		}
-		if resp.StatusCode != http.StatusOK {
-			return nil, fmt.Errorf("failed to get models: %s", resp.Status)
-		}
-		defer resp.Body.Close()
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, err
-		}
-		type OpenAIResp struct {
-			Object string `json:"object"`
-			Data   []struct {
-				ID string `json:"id"`
-			} `json:"data"`
-		}
-		var models OpenAIResp
-		err = json.Unmarshal(body, &models)
-		if err != nil {
-			return nil, err
-		}
-		modelsList := make([]domain.ProviderModelListItem, 0)
-		for _, model := range models.Data {
-			modelsList = append(modelsList, domain.ProviderModelListItem{
-				Model: model.ID,
-			})
-		}
-		return &domain.GetProviderModelListResp{
-			Models: modelsList,
-		}, nil
-	case domain.ModelProviderBrandOllama:
-		// get from ollama http://10.10.16.24:11434/api/tags
-		u, err := url.Parse(req.BaseURL)
-		if err != nil {
-			return nil, err
-		}
-		u.Path = "/api/tags"
-		request, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
-		if err != nil {
-			return nil, err
-		}
-		if req.APIHeader != "" {
-			headers := utils.GetHeaderMap(req.APIHeader)
-			for k, v := range headers {
-				request.Header.Set(k, v)
-			}
-		}
-		resp, err := http.DefaultClient.Do(request)
-		if err != nil {
-			return nil, err
-		}
-		defer resp.Body.Close()
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, err
-		}
-		var models domain.GetProviderModelListResp
-		err = json.Unmarshal(body, &models)
-		if err != nil {
-			return nil, err
-		}
-		return &models, nil
-	case domain.ModelProviderBrandSiliconFlow, domain.ModelProviderBrandBaiZhiCloud:
-		if req.Type == domain.ModelTypeEmbedding || req.Type == domain.ModelTypeRerank {
-			if provider == domain.ModelProviderBrandBaiZhiCloud {
-				if req.Type == domain.ModelTypeEmbedding {
-					return &domain.GetProviderModelListResp{
-						Models: []domain.ProviderModelListItem{
-							{
-								Model: "bge-m3",
-							},
-						},
-					}, nil
-				} else {
-					return &domain.GetProviderModelListResp{
-						Models: []domain.ProviderModelListItem{
-							{
-								Model: "bge-reranker-v2-m3",
-							},
-						},
-					}, nil
-				}
-			}
-		}
-		u, err := url.Parse(req.BaseURL)
-		if err != nil {
-			return nil, err
-		}
-		u.Path = "/v1/models"
-		q := u.Query()
-		q.Set("type", "text")
-		q.Set("sub_type", "chat")
-		u.RawQuery = q.Encode()
-		request, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
-		if err != nil {
-			return nil, err
-		}
-		request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", req.APIKey))
-		resp, err := http.DefaultClient.Do(request)
-		if err != nil {
-			return nil, err
-		}
-		defer resp.Body.Close()
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, err
-		}
-		if resp.StatusCode != http.StatusOK {
-			return nil, fmt.Errorf("failed to get models: %s", resp.Status)
-		}
-		type SiliconFlowModelResp struct {
-			Object string `json:"object"`
-			Data   []struct {
-				ID string `json:"id"`
-			} `json:"data"`
-		}
-		var models SiliconFlowModelResp
-		err = json.Unmarshal(body, &models)
-		if err != nil {
-			return nil, err
-		}
-		modelsList := make([]domain.ProviderModelListItem, 0, len(models.Data))
-		for _, model := range models.Data {
-			modelsList = append(modelsList, domain.ProviderModelListItem{
-				Model: model.ID,
-			})
-		}
-		return &domain.GetProviderModelListResp{
-			Models: modelsList,
-		}, nil
-	default:
-		return nil, fmt.Errorf("invalid provider: %s", req.Provider)
+	models, err := modelProviderOf(domain.ModelProvider(req.Provider)).Models(ctx, req)
+	if err != nil {
+		return nil, err
 	}
+	return &domain.GetProviderModelListResp{
+		Models: models,
+	}, nil
 }
@@ -0,0 +1,228 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+	"gorm.io/gorm"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/pkg/bot/dingtalk"
+	"github.com/chaitin/panda-wiki/pkg/bot/feishu"
+)
+
+// fallbackModelIDSuffix usage of the fallback model is recorded as model id + suffix
+const fallbackModelIDSuffix = ":fallback"
+
+func currentMonth() time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+}
+
+func (u *ModelUsecase) GetBudget(ctx context.Context, modelID string) (*domain.ModelBudget, error) {
+	budget, err := u.modelRepo.GetBudget(ctx, modelID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &domain.ModelBudget{
+				ModelID:        modelID,
+				ExceededAction: domain.BudgetExceededActionDisable,
+			}, nil
+		}
+		return nil, err
+	}
+	return budget, nil
+}
+
+func (u *ModelUsecase) UpdateBudget(ctx context.Context, budget *domain.ModelBudget) error {
+	if budget.ExceededAction == domain.BudgetExceededActionFallback &&
+		(budget.FallbackModel.Model == "" || budget.FallbackModel.BaseURL == "") {
+		return fmt.Errorf("fallback model is required")
+	}
+	now := time.Now()
+	budget.CreatedAt = now
+	budget.UpdatedAt = now
+	return u.modelRepo.UpsertBudget(ctx, budget)
+}
+
+// ResolveChatModel check monthly budget of the chat model,
+// return the fallback model or ErrModelBudgetExceeded with the message for users if exceeded
+func (u *ModelUsecase) ResolveChatModel(ctx context.Context, model *domain.Model) (*domain.Model, string, error) {
+	budget, err := u.modelRepo.GetBudget(ctx, model.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model, "", nil
+		}
+		return nil, "", err
+	}
+	usage, err := u.modelRepo.GetMonthlyUsage(ctx, model.ID, currentMonth())
+	if err != nil {
+		return nil, "", err
+	}
+	if budget.UsagePercent(usage) < 100 {
+		return model, "", nil
+	}
+	if budget.ExceededAction == domain.BudgetExceededActionFallback && budget.FallbackModel.Model != "" {
+		fallback := budget.FallbackModel
+		return &domain.Model{
+			ID:         model.ID + fallbackModelIDSuffix,
+			Provider:   fallback.Provider,
+			Model:      fallback.Model,
+			APIKey:     fallback.APIKey,
+			APIHeader:  fallback.APIHeader,
+			BaseURL:    fallback.BaseURL,
+			APIVersion: fallback.APIVersion,
+			Type:       domain.ModelTypeChat,
+		}, "", nil
+	}
+	message := budget.ExceededMessage
+	if message == "" {
+		message = domain.DefaultBudgetExceededMessage
+	}
+	return nil, message, domain.ErrModelBudgetExceeded
+}
+
+// recordMonthlyUsage accumulate usage and spend of the month, notify if a threshold is reached
+func (u *ModelUsecase) recordMonthlyUsage(ctx context.Context, modelID string, usage *schema.TokenUsage) error {
+	// the fallback model is priced by the budget of the model it replaces
+	budgetModelID, fallback := strings.CutSuffix(modelID, fallbackModelIDSuffix)
+	budget, err := u.modelRepo.GetBudget(ctx, budgetModelID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	monthly := &domain.ModelUsageMonthly{
+		ModelID:          modelID,
+		Month:            currentMonth(),
+		PromptTokens:     int64(usage.PromptTokens),
+		CompletionTokens: int64(usage.CompletionTokens),
+		TotalTokens:      int64(usage.TotalTokens),
+	}
+	if budget != nil {
+		if fallback {
+			monthly.Cost = budget.FallbackModel.Cost(monthly.PromptTokens, monthly.CompletionTokens)
+		} else {
+			monthly.Cost = budget.Cost(monthly.PromptTokens, monthly.CompletionTokens)
+		}
+	}
+	monthly, err = u.modelRepo.IncrMonthlyUsage(ctx, monthly)
+	if err != nil {
+		return err
+	}
+	// limits and thresholds are of the budget model
+	if fallback || budget == nil || len(budget.NotifyThresholds) == 0 {
+		return nil
+	}
+	percent := budget.UsagePercent(monthly)
+	thresholds := slices.Clone(budget.NotifyThresholds)
+	slices.Sort(thresholds)
+	reached := 0
+	for _, threshold := range thresholds {
+		if percent >= float64(threshold) {
+			reached = threshold
+		}
+	}
+	if reached == 0 || reached <= monthly.NotifiedThreshold {
+		return nil
+	}
+	updated, err := u.modelRepo.UpdateNotifiedThreshold(ctx, modelID, monthly.Month, reached)
+	if err != nil || !updated {
+		return err
+	}
+	u.notifyBudget(ctx, budget, monthly, reached)
+	return nil
+}
+
+func (u *ModelUsecase) notifyBudget(ctx context.Context, budget *domain.ModelBudget, usage *domain.ModelUsageMonthly, threshold int) {
+	title := "模型用量提醒"
+	text := fmt.Sprintf("模型 %s 本月用量已达预算的 %d%%\n\n- 花费：%.2f / %.2f\n- Token：%d / %d\n",
+		budget.ModelID, threshold, usage.Cost, budget.MonthlyBudget, usage.TotalTokens, budget.MonthlyTokenLimit)
+	u.logger.Warn("model budget threshold reached", log.String("model_id", budget.ModelID), log.Int("threshold", threshold), log.Any("cost", usage.Cost), log.Int64("total_tokens", usage.TotalTokens))
+	webhook := budget.NotifyWebhook
+	if webhook.URL == "" {
+		return
+	}
+	var err error
+	switch webhook.Type {
+	case domain.NotifyWebhookTypeFeishu:
+		err = feishu.SendWebhookMarkdown(ctx, webhook.URL, webhook.Secret, title, text)
+	default:
+		err = dingtalk.SendWebhookMarkdown(ctx, webhook.URL, webhook.Secret, title, "### "+title+"\n\n"+text)
+	}
+	if err != nil {
+		u.logger.Error("send model budget notification failed", log.String("model_id", budget.ModelID), log.Error(err))
+	}
+}
+
+// GetSpend get usage, spend and budget of each model in the month
+func (u *ModelUsecase) GetSpend(ctx context.Context, req *domain.GetModelSpendReq) ([]*domain.ModelSpendResp, error) {
+	month := currentMonth()
+	if req.Month != "" {
+		t, err := time.ParseInLocation("2006-01", req.Month, time.Local)
+		if err != nil {
+			return nil, err
+		}
+		month = t
+	}
+	models, err := u.modelRepo.GetList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	budgets, err := u.modelRepo.GetBudgets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	usages, err := u.modelRepo.GetMonthlyUsages(ctx, month)
+	if err != nil {
+		return nil, err
+	}
+	budgetMap := make(map[string]*domain.ModelBudget, len(budgets))
+	for _, budget := range budgets {
+		budgetMap[budget.ModelID] = budget
+	}
+	usageMap := make(map[string]*domain.ModelUsageMonthly, len(usages))
+	for _, usage := range usages {
+		usageMap[usage.ModelID] = usage
+	}
+	spends := make([]*domain.ModelSpendResp, 0)
+	appendSpend := func(modelID, modelName, provider string, budget *domain.ModelBudget) {
+		usage, ok := usageMap[modelID]
+		if !ok {
+			usage = &domain.ModelUsageMonthly{ModelID: modelID, Month: month}
+		}
+		spend := &domain.ModelSpendResp{
+			ModelID:          modelID,
+			Model:            modelName,
+			Provider:         provider,
+			Month:            month,
+			PromptTokens:     usage.PromptTokens,
+			CompletionTokens: usage.CompletionTokens,
+			TotalTokens:      usage.TotalTokens,
+			Cost:             usage.Cost,
+		}
+		if budget != nil {
+			spend.MonthlyBudget = budget.MonthlyBudget
+			spend.MonthlyTokenLimit = budget.MonthlyTokenLimit
+			spend.UsagePercent = budget.UsagePercent(usage)
+			spend.Exceeded = spend.UsagePercent >= 100
+		}
+		spends = append(spends, spend)
+	}
+	for _, model := range models {
+		if model.Type != domain.ModelTypeChat {
+			continue
+		}
+		budget := budgetMap[model.ID]
+		appendSpend(model.ID, model.Model, string(model.Provider), budget)
+		if budget != nil && budget.FallbackModel.Model != "" {
+			if _, ok := usageMap[model.ID+fallbackModelIDSuffix]; ok {
+				appendSpend(model.ID+fallbackModelIDSuffix, budget.FallbackModel.Model, string(budget.FallbackModel.Provider), nil)
+			}
+		}
+	}
+	return spends, nil
+}
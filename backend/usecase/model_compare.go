@@ -0,0 +1,174 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+	"gorm.io/gorm"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/repo/pg"
+)
+
+// ModelCompareUsecase playground of admins answering a question with several models side by side
+type ModelCompareUsecase struct {
+	llmUsecase *LLMUsecase
+	modelRepo  *pg.ModelRepository
+	logger     *log.Logger
+}
+
+func NewModelCompareUsecase(llmUsecase *LLMUsecase, modelRepo *pg.ModelRepository, logger *log.Logger) *ModelCompareUsecase {
+	return &ModelCompareUsecase{
+		llmUsecase: llmUsecase,
+		modelRepo:  modelRepo,
+		logger:     logger.WithModule("usecase.model_compare"),
+	}
+}
+
+// CompareModels retrieve documents once and answer the question with all models at the same time.
+// a failed model has its error in its result, usage of compared models is not counted
+func (u *ModelCompareUsecase) CompareModels(ctx context.Context, req *domain.CompareModelsReq) (*domain.CompareModelsResp, error) {
+	models := make([]*domain.Model, 0, len(req.Models))
+	budgets := make([]*domain.ModelBudget, 0, len(req.Models))
+	for _, m := range req.Models {
+		model, budget, err := u.resolveModel(ctx, m)
+		if err != nil {
+			return nil, err
+		}
+		models = append(models, model)
+		budgets = append(budgets, budget)
+	}
+	citation := req.CitationStyle
+	if citation == "" {
+		citation = domain.CitationStyleInline
+	}
+	messages, rankedNodes, err := u.llmUsecase.FormatQuestionMessages(ctx, req.KBID, req.Question, domain.SystemPrompt(citation))
+	if err != nil {
+		return nil, err
+	}
+	resp := &domain.CompareModelsResp{
+		Question:  req.Question,
+		Documents: make([]*domain.NodeCotentChunkSSE, 0, len(rankedNodes)),
+		Results:   make([]*domain.CompareModelResult, len(models)),
+	}
+	for _, node := range rankedNodes {
+		resp.Documents = append(resp.Documents, &domain.NodeCotentChunkSSE{
+			NodeID:  node.NodeID,
+			Name:    node.NodeName,
+			Summary: node.NodeSummary,
+		})
+	}
+	var wg sync.WaitGroup
+	for i, model := range models {
+		wg.Add(1)
+		go func(i int, model *domain.Model) {
+			defer wg.Done()
+			resp.Results[i] = u.answer(ctx, model, budgets[i], messages)
+		}(i, model)
+	}
+	wg.Wait()
+	return resp, nil
+}
+
+// resolveModel connection and prices of the compared model
+func (u *ModelCompareUsecase) resolveModel(ctx context.Context, m *domain.CompareModel) (*domain.Model, *domain.ModelBudget, error) {
+	budget := &domain.ModelBudget{PromptPrice: m.PromptPrice, CompletionPrice: m.CompletionPrice}
+	if m.ModelID == "" {
+		return &domain.Model{
+			Provider:   m.Provider,
+			Model:      m.Model,
+			BaseURL:    m.BaseURL,
+			APIKey:     m.APIKey,
+			APIHeader:  m.APIHeader,
+			APIVersion: m.APIVersion,
+			Type:       domain.ModelTypeChat,
+		}, budget, nil
+	}
+	modelID, fallback := strings.CutSuffix(m.ModelID, fallbackModelIDSuffix)
+	detail, err := u.modelRepo.Get(ctx, modelID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get model %s failed: %w", m.ModelID, err)
+	}
+	if detail.Type != domain.ModelTypeChat {
+		return nil, nil, fmt.Errorf("model %s is not a chat model", m.ModelID)
+	}
+	model := &domain.Model{
+		ID:         detail.ID,
+		Provider:   detail.Provider,
+		Model:      detail.Model,
+		BaseURL:    detail.BaseURL,
+		APIKey:     detail.APIKey,
+		APIHeader:  detail.APIHeader,
+		APIVersion: detail.APIVersion,
+		Type:       detail.Type,
+	}
+	saved, err := u.modelRepo.GetBudget(ctx, modelID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, err
+	}
+	if fallback {
+		if saved == nil || saved.FallbackModel.Model == "" {
+			return nil, nil, fmt.Errorf("model %s has no fallback model", modelID)
+		}
+		model = &domain.Model{
+			ID:         m.ModelID,
+			Provider:   saved.FallbackModel.Provider,
+			Model:      saved.FallbackModel.Model,
+			BaseURL:    saved.FallbackModel.BaseURL,
+			APIKey:     saved.FallbackModel.APIKey,
+			APIHeader:  saved.FallbackModel.APIHeader,
+			APIVersion: saved.FallbackModel.APIVersion,
+			Type:       domain.ModelTypeChat,
+		}
+		// budget prices are those of the configured model
+		saved = nil
+	}
+	if saved != nil && budget.PromptPrice == 0 && budget.CompletionPrice == 0 {
+		budget = saved
+	}
+	return model, budget, nil
+}
+
+// answer stream the answer of the model with timing and usage
+func (u *ModelCompareUsecase) answer(ctx context.Context, model *domain.Model, budget *domain.ModelBudget, messages []*schema.Message) *domain.CompareModelResult {
+	result := &domain.CompareModelResult{
+		ModelID:  model.ID,
+		Provider: model.Provider,
+		Model:    model.Model,
+	}
+	ctx, cancel := context.WithTimeout(ctx, domain.ModelCompareTimeout)
+	defer cancel()
+	start := time.Now()
+	chatModel, err := u.llmUsecase.GetChatModel(ctx, model)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	var answer strings.Builder
+	usage := schema.TokenUsage{}
+	err = u.llmUsecase.ChatWithAgent(ctx, chatModel, model.Model, messages, &usage, func(ctx context.Context, dataType, chunk string) error {
+		if result.FirstTokenMS == 0 && chunk != "" {
+			result.FirstTokenMS = time.Since(start).Milliseconds()
+		}
+		answer.WriteString(chunk)
+		return nil
+	})
+	result.LatencyMS = time.Since(start).Milliseconds()
+	result.Answer = answer.String()
+	if err != nil {
+		u.logger.Warn("compare model failed", log.String("model", model.Model), log.Error(err))
+		result.Error = err.Error()
+		return result
+	}
+	result.PromptTokens = usage.PromptTokens
+	result.CompletionTokens = usage.CompletionTokens
+	result.TotalTokens = usage.TotalTokens
+	result.Cost = budget.Cost(int64(usage.PromptTokens), int64(usage.CompletionTokens))
+	return result
+}
@@ -0,0 +1,194 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+	"github.com/ollama/ollama/api"
+	"gorm.io/gorm"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+)
+
+// GetKBChatModels chat models answering in the kb in order, the model selected for the kb first and then the failover
+// pool, in which models of the same priority are shuffled by weight. the default chat model if neither is configured
+func (u *ModelUsecase) GetKBChatModels(ctx context.Context, kb *domain.KnowledgeBase) ([]*domain.Model, error) {
+	models := make([]*domain.Model, 0)
+	if id := kb.ModelSettings.ChatModelID; id != "" {
+		model, err := u.modelRepo.GetModel(ctx, id, domain.ModelTypeChat)
+		switch {
+		case err == nil:
+			models = append(models, model)
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, err
+		}
+	}
+	pool, err := u.modelRepo.GetFailoverChatModels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(models) == 0 && len(pool) == 0 {
+		model, err := u.modelRepo.GetChatModel(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return []*domain.Model{model}, nil
+	}
+	for _, model := range balanceModels(pool) {
+		if len(models) > 0 && model.ID == models[0].ID {
+			continue
+		}
+		models = append(models, model)
+	}
+	return models, nil
+}
+
+// ResolveChatModels check monthly budgets of the chat models, models over budget are replaced by their fallback model
+// or skipped. ErrModelBudgetExceeded with the message of the first model if every model is over budget
+func (u *ModelUsecase) ResolveChatModels(ctx context.Context, models []*domain.Model) ([]*domain.Model, string, error) {
+	resolved := make([]*domain.Model, 0, len(models))
+	exceededMessage := ""
+	for _, model := range models {
+		model, message, err := u.ResolveChatModel(ctx, model)
+		if err != nil {
+			if errors.Is(err, domain.ErrModelBudgetExceeded) {
+				if exceededMessage == "" {
+					exceededMessage = message
+				}
+				continue
+			}
+			return nil, "", err
+		}
+		resolved = append(resolved, model)
+	}
+	if len(resolved) == 0 {
+		return nil, exceededMessage, domain.ErrModelBudgetExceeded
+	}
+	return resolved, "", nil
+}
+
+// balanceModels models sorted by priority with each priority shuffled by weight
+func balanceModels(models []*domain.Model) []*domain.Model {
+	balanced := make([]*domain.Model, 0, len(models))
+	for start := 0; start < len(models); {
+		end := start + 1
+		for end < len(models) && models[end].Priority == models[start].Priority {
+			end++
+		}
+		group := append([]*domain.Model(nil), models[start:end]...)
+		for len(group) > 0 {
+			total := 0
+			for _, model := range group {
+				total += model.EffectiveWeight()
+			}
+			pick := rand.IntN(total)
+			i := 0
+			for ; pick >= group[i].EffectiveWeight(); i++ {
+				pick -= group[i].EffectiveWeight()
+			}
+			balanced = append(balanced, group[i])
+			group = append(group[:i], group[i+1:]...)
+		}
+		start = end
+	}
+	return balanced
+}
+
+var errFirstChunkTimeout = errors.New("no response within the first chunk timeout")
+
+// ChatWithFailover stream answer of the first model which answers, see ChatWithContinuation. a model timing out before
+// its first chunk, rate limited or failing is skipped for the next model as long as nothing of its answer was streamed.
+// return the model which served the answer and the number of models skipped
+func (u *LLMUsecase) ChatWithFailover(
+	ctx context.Context,
+	models []*domain.Model,
+	messages []*schema.Message,
+	maxRounds int,
+	usage *schema.TokenUsage,
+	onChunk func(ctx context.Context, dataType, chunk string) error,
+	opts ...model.Option,
+) (*domain.Model, int, error) {
+	var err error
+	for i, chatModel := range models {
+		last := i == len(models)-1
+		var streamed bool
+		var attemptUsage schema.TokenUsage
+		err = u.chatAttempt(ctx, chatModel, messages, maxRounds, &attemptUsage, !last, func(ctx context.Context, dataType, chunk string) error {
+			streamed = true
+			return onChunk(ctx, dataType, chunk)
+		}, opts...)
+		if err == nil || streamed || last || ctx.Err() != nil || !isModelFailoverError(err) {
+			*usage = attemptUsage
+			return chatModel, i, err
+		}
+		u.logger.Warn("chat model failed, failing over to the next model", log.String("model_id", chatModel.ID),
+			log.String("model", chatModel.Model), log.String("next_model_id", models[i+1].ID), log.Error(err))
+	}
+	return nil, 0, err
+}
+
+// chatAttempt stream answer of the model, canceled if it streams nothing within ModelFirstChunkTimeout if timed
+func (u *LLMUsecase) chatAttempt(
+	ctx context.Context,
+	m *domain.Model,
+	messages []*schema.Message,
+	maxRounds int,
+	usage *schema.TokenUsage,
+	timed bool,
+	onChunk func(ctx context.Context, dataType, chunk string) error,
+	opts ...model.Option,
+) error {
+	chatModel, err := u.GetChatModel(ctx, m)
+	if err != nil {
+		return err
+	}
+	if !timed {
+		return u.ChatWithContinuation(ctx, chatModel, m.Model, messages, maxRounds, usage, onChunk, opts...)
+	}
+	attemptCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	timer := time.AfterFunc(domain.ModelFirstChunkTimeout, func() {
+		cancel(errFirstChunkTimeout)
+	})
+	defer timer.Stop()
+	err = u.ChatWithContinuation(attemptCtx, chatModel, m.Model, messages, maxRounds, usage, func(ctx context.Context, dataType, chunk string) error {
+		timer.Stop()
+		return onChunk(ctx, dataType, chunk)
+	}, opts...)
+	if err != nil && errors.Is(context.Cause(attemptCtx), errFirstChunkTimeout) {
+		return errFirstChunkTimeout
+	}
+	return err
+}
+
+// statusCodePattern http status in errors of the openai compatible and deepseek clients
+var statusCodePattern = regexp.MustCompile(`(?:status code: |HTTP )(\d{3})`)
+
+// isModelFailoverError whether another model may answer: timeouts, rate limits, server and network errors
+func isModelFailoverError(err error) bool {
+	if errors.Is(err, errFirstChunkTimeout) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	statusCode := 0
+	var ollamaErr api.StatusError
+	if errors.As(err, &ollamaErr) {
+		statusCode = ollamaErr.StatusCode
+	} else if match := statusCodePattern.FindStringSubmatch(err.Error()); match != nil {
+		statusCode, _ = strconv.Atoi(match[1])
+	}
+	return statusCode == http.StatusRequestTimeout || statusCode == http.StatusTooManyRequests || statusCode >= http.StatusInternalServerError
+}
@@ -0,0 +1,225 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+
+	"github.com/cloudwego/eino-ext/components/model/deepseek"
+	"github.com/cloudwego/eino-ext/components/model/ollama"
+	"github.com/cloudwego/eino-ext/components/model/openai"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/ollama/ollama/api"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/utils"
+)
+
+// ModelProvider api of a provider brand, builds chat models of its endpoints and lists the models they serve
+type ModelProvider interface {
+	ChatModel(ctx context.Context, m *domain.Model, temperature float32) (model.BaseChatModel, error)
+	Models(ctx context.Context, req *domain.GetProviderModelListReq) ([]domain.ProviderModelListItem, error)
+}
+
+var modelProviders = map[domain.ModelProvider]ModelProvider{
+	domain.ModelProviderBrandOpenAI: &openAIProvider{},
+	domain.ModelProviderBrandAzureOpenAI: &openAIProvider{
+		azure:  true,
+		models: domain.ModelProviderBrandModelsList[domain.ModelProviderBrandAzureOpenAI],
+	},
+	domain.ModelProviderBrandDeepSeek: &deepSeekProvider{openAIProvider{
+		models: domain.ModelProviderBrandModelsList[domain.ModelProviderBrandDeepSeek],
+	}},
+	domain.ModelProviderBrandMoonshot: &openAIProvider{
+		models: domain.ModelProviderBrandModelsList[domain.ModelProviderBrandMoonshot],
+	},
+	domain.ModelProviderBrandVolcengine: &openAIProvider{
+		models: domain.ModelProviderBrandModelsList[domain.ModelProviderBrandVolcengine],
+	},
+	domain.ModelProviderBrandHunyuan:     &openAIProvider{},
+	domain.ModelProviderBrandBaiLian:     &openAIProvider{},
+	domain.ModelProviderBrandVLLM:        &openAIProvider{},
+	domain.ModelProviderBrandOther:       &openAIProvider{},
+	domain.ModelProviderBrandOllama:      &ollamaProvider{},
+	domain.ModelProviderBrandSiliconFlow: &siliconFlowProvider{},
+	domain.ModelProviderBrandBaiZhiCloud: &siliconFlowProvider{
+		fixed: map[domain.ModelType][]domain.ProviderModelListItem{
+			domain.ModelTypeEmbedding: {{Model: "bge-m3"}},
+			domain.ModelTypeRerank:    {{Model: "bge-reranker-v2-m3"}},
+		},
+	},
+}
+
+// modelProviderOf provider of the brand, unknown brands are taken as openai compatible
+func modelProviderOf(brand domain.ModelProvider) ModelProvider {
+	if provider, ok := modelProviders[brand]; ok {
+		return provider
+	}
+	return modelProviders[domain.ModelProviderBrandOther]
+}
+
+// openAIProvider openai compatible api, models are listed by the /models api unless the provider has a fixed list
+type openAIProvider struct {
+	azure  bool
+	models []domain.ProviderModelListItem
+}
+
+func (p *openAIProvider) ChatModel(ctx context.Context, m *domain.Model, temperature float32) (model.BaseChatModel, error) {
+	config := &openai.ChatModelConfig{
+		APIKey:      m.APIKey,
+		BaseURL:     m.BaseURL,
+		Model:       m.Model,
+		Temperature: &temperature,
+	}
+	if p.azure {
+		config.ByAzure = true
+		config.APIVersion = m.APIVersion
+		if config.APIVersion == "" {
+			config.APIVersion = "2024-10-21"
+		}
+	}
+	if m.APIHeader != "" {
+		client := getHttpClientWithAPIHeaderMap(m.APIHeader)
+		if client != nil {
+			config.HTTPClient = client
+		}
+	}
+	return openai.NewChatModel(ctx, config)
+}
+
+func (p *openAIProvider) Models(ctx context.Context, req *domain.GetProviderModelListReq) ([]domain.ProviderModelListItem, error) {
+	if p.models != nil {
+		return p.models, nil
+	}
+	u, err := url.Parse(req.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path.Join(u.Path, "/models")
+	return listOpenAIModels(ctx, u, req)
+}
+
+type deepSeekProvider struct {
+	openAIProvider
+}
+
+func (p *deepSeekProvider) ChatModel(ctx context.Context, m *domain.Model, temperature float32) (model.BaseChatModel, error) {
+	return deepseek.NewChatModel(ctx, &deepseek.ChatModelConfig{
+		BaseURL:     m.BaseURL,
+		APIKey:      m.APIKey,
+		Model:       m.Model,
+		Temperature: temperature,
+	})
+}
+
+// siliconFlowProvider openai compatible api, the /v1/models api of the host lists models by their type
+type siliconFlowProvider struct {
+	openAIProvider
+	// models of the types listed without calling the api
+	fixed map[domain.ModelType][]domain.ProviderModelListItem
+}
+
+func (p *siliconFlowProvider) Models(ctx context.Context, req *domain.GetProviderModelListReq) ([]domain.ProviderModelListItem, error) {
+	if models, ok := p.fixed[req.Type]; ok {
+		return models, nil
+	}
+	u, err := url.Parse(req.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = "/v1/models"
+	q := u.Query()
+	if req.Type == domain.ModelTypeASR {
+		q.Set("type", "audio")
+		q.Set("sub_type", "speech-to-text")
+	} else {
+		q.Set("type", "text")
+		q.Set("sub_type", "chat")
+	}
+	u.RawQuery = q.Encode()
+	return listOpenAIModels(ctx, u, req)
+}
+
+type ollamaProvider struct{}
+
+func (p *ollamaProvider) ChatModel(ctx context.Context, m *domain.Model, temperature float32) (model.BaseChatModel, error) {
+	baseUrl, err := utils.URLRemovePath(m.BaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("ollama url parse failed: %w", err)
+	}
+	return ollama.NewChatModel(ctx, &ollama.ChatModelConfig{
+		BaseURL: baseUrl,
+		Model:   m.Model,
+		Options: &api.Options{
+			Temperature: temperature,
+		},
+	})
+}
+
+func (p *ollamaProvider) Models(ctx context.Context, req *domain.GetProviderModelListReq) ([]domain.ProviderModelListItem, error) {
+	// get from ollama http://10.10.16.24:11434/api/tags
+	u, err := url.Parse(req.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = "/api/tags"
+	body, err := getModelList(ctx, u, req)
+	if err != nil {
+		return nil, err
+	}
+	var models domain.GetProviderModelListResp
+	if err := json.Unmarshal(body, &models); err != nil {
+		return nil, err
+	}
+	return models.Models, nil
+}
+
+// listOpenAIModels models of an openai compatible list api
+func listOpenAIModels(ctx context.Context, u *url.URL, req *domain.GetProviderModelListReq) ([]domain.ProviderModelListItem, error) {
+	body, err := getModelList(ctx, u, req)
+	if err != nil {
+		return nil, err
+	}
+	var models struct {
+		Object string `json:"object"`
+		Data   []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &models); err != nil {
+		return nil, err
+	}
+	modelsList := make([]domain.ProviderModelListItem, 0, len(models.Data))
+	for _, model := range models.Data {
+		modelsList = append(modelsList, domain.ProviderModelListItem{
+			Model: model.ID,
+		})
+	}
+	return modelsList, nil
+}
+
+func getModelList(ctx context.Context, u *url.URL, req *domain.GetProviderModelListReq) ([]byte, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if req.APIKey != "" {
+		request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", req.APIKey))
+	}
+	for k, v := range utils.GetHeaderMap(req.APIHeader) {
+		request.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get models: %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
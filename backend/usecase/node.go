@@ -23,9 +23,13 @@ type NodeUsecase struct {
 	llmUsecase *LLMUsecase
 	logger     *log.Logger
 	s3Client   *s3.MinioClient
+
+	attachmentUsecase *NodeAttachmentUsecase
+	linkRepo          *pg.NodeLinkRepository
+	answerCacheRepo   *pg.AnswerCacheRepository
 }
 
-func NewNodeUsecase(nodeRepo *pg.NodeRepository, ragRepo *mq.RAGRepository, kbRepo *pg.KnowledgeBaseRepository, llmUsecase *LLMUsecase, logger *log.Logger, s3Client *s3.MinioClient, modelRepo *pg.ModelRepository) *NodeUsecase {
+func NewNodeUsecase(nodeRepo *pg.NodeRepository, ragRepo *mq.RAGRepository, kbRepo *pg.KnowledgeBaseRepository, llmUsecase *LLMUsecase, logger *log.Logger, s3Client *s3.MinioClient, modelRepo *pg.ModelRepository, attachmentUsecase *NodeAttachmentUsecase, linkRepo *pg.NodeLinkRepository, answerCacheRepo *pg.AnswerCacheRepository) *NodeUsecase {
 	return &NodeUsecase{
 		nodeRepo:   nodeRepo,
 		ragRepo:    ragRepo,
@@ -34,10 +38,22 @@ func NewNodeUsecase(nodeRepo *pg.NodeRepository, ragRepo *mq.RAGRepository, kbRe
 		modelRepo:  modelRepo,
 		logger:     logger.WithModule("usecase.node"),
 		s3Client:   s3Client,
+
+		attachmentUsecase: attachmentUsecase,
+		linkRepo:          linkRepo,
+		answerCacheRepo:   answerCacheRepo,
 	}
 }
 
 func (u *NodeUsecase) Create(ctx context.Context, req *domain.CreateNodeReq) (string, error) {
+	if err := u.applyFolderDefaults(ctx, req); err != nil {
+		return "", err
+	}
+	if req.LinkToNodeID != "" {
+		if err := u.applyLinkTo(ctx, req); err != nil {
+			return "", err
+		}
+	}
 	nodeID, err := u.nodeRepo.Create(ctx, req)
 	if err != nil {
 		return "", err
@@ -75,11 +91,17 @@ func (u *NodeUsecase) NodeAction(ctx context.Context, req *domain.NodeActionReq)
 		if err := u.ragRepo.AsyncUpdateNodeReleaseVector(ctx, nodeVectorContentRequests); err != nil {
 			return err
 		}
+		// nodes are gone, attachments left behind are only logged
+		if err := u.attachmentUsecase.DeleteNodesAttachments(ctx, req.KBID, req.IDs); err != nil {
+			u.logger.Warn("failed to delete node attachments", log.String("kb_id", req.KBID), log.Error(err))
+		}
+		u.dropAnswerCache(ctx, req.KBID, req.IDs)
 	case "private":
 		// update node visibility to private
 		if err := u.nodeRepo.UpdateNodesVisibility(ctx, req.KBID, req.IDs, domain.NodeVisibilityPrivate); err != nil {
 			return err
 		}
+		u.dropAnswerCache(ctx, req.KBID, req.IDs)
 		// get latest node release and delete in vector
 		nodeReleases, err := u.nodeRepo.GetLatestNodeReleaseByNodeIDs(ctx, req.KBID, req.IDs)
 		if err != nil {
@@ -118,6 +140,7 @@ func (u *NodeUsecase) Update(ctx context.Context, req *domain.UpdateNodeReq) err
 	if err != nil {
 		return err
 	}
+	u.dropAnswerCache(ctx, req.KBID, []string{req.ID})
 	if req.Visibility != nil && *req.Visibility == domain.NodeVisibilityPrivate {
 		// get latest node release
 		nodeRelease, err := u.nodeRepo.GetLatestNodeReleaseByNodeID(ctx, req.ID)
@@ -139,6 +162,14 @@ func (u *NodeUsecase) Update(ctx context.Context, req *domain.UpdateNodeReq) err
 	return nil
 }
 
+// dropAnswerCache cached answers retrieved from the nodes are stale once the nodes change, answers are only
+// served from the cache until they expire if this fails
+func (u *NodeUsecase) dropAnswerCache(ctx context.Context, kbID string, nodeIDs []string) {
+	if err := u.answerCacheRepo.DeleteNodesAnswerCache(ctx, kbID, nodeIDs); err != nil {
+		u.logger.Warn("failed to drop answer cache of nodes", log.String("kb_id", kbID), log.Error(err))
+	}
+}
+
 func (u *NodeUsecase) GetNodeReleaseListByKBID(ctx context.Context, kbID string) ([]*domain.ShareNodeListItemResp, error) {
 	return u.nodeRepo.GetNodeReleaseListByKBID(ctx, kbID)
 }
@@ -152,7 +183,7 @@ func (u *NodeUsecase) MoveNode(ctx context.Context, req *domain.MoveNodeReq) err
 }
 
 func (u *NodeUsecase) SummaryNode(ctx context.Context, req *domain.NodeSummaryReq) (string, error) {
-	model, err := u.modelRepo.GetChatModel(ctx)
+	model, err := u.modelRepo.GetKBModel(ctx, req.KBID, domain.ModelTypeChat)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return "", domain.ErrModelNotConfigured
@@ -231,3 +262,33 @@ func (u *NodeUsecase) GetRecommendNodeList(ctx context.Context, req *domain.GetR
 	}
 	return nil, nil
 }
+
+// DiscardDraft drop unpublished changes of the node
+func (u *NodeUsecase) DiscardDraft(ctx context.Context, req *domain.DiscardNodeDraftReq) error {
+	return u.nodeRepo.DiscardNodeDraft(ctx, req.KBID, req.ID)
+}
+
+// GetPublished get content of the latest published release of the node
+func (u *NodeUsecase) GetPublished(ctx context.Context, id string) (*domain.PublishedNodeResp, error) {
+	nodeRelease, err := u.nodeRepo.GetLatestNodeReleaseByNodeID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNodeNotPublished
+		}
+		return nil, err
+	}
+	node, err := u.nodeRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.PublishedNodeResp{
+		ID:          nodeRelease.ID,
+		NodeID:      nodeRelease.NodeID,
+		Visibility:  nodeRelease.Visibility,
+		Name:        nodeRelease.Name,
+		Content:     nodeRelease.Content,
+		Meta:        nodeRelease.Meta,
+		HasDraft:    node.Status == domain.NodeStatusDraft,
+		PublishedAt: nodeRelease.UpdatedAt,
+	}, nil
+}
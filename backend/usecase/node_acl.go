@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/repo/pg"
+)
+
+var ErrNodeACLGroupsRequired = errors.New("groups are required for groups access")
+
+type NodeACLUsecase struct {
+	repo   *pg.NodeACLRepository
+	kbRepo *pg.KnowledgeBaseRepository
+	logger *log.Logger
+}
+
+func NewNodeACLUsecase(repo *pg.NodeACLRepository, kbRepo *pg.KnowledgeBaseRepository, logger *log.Logger) *NodeACLUsecase {
+	return &NodeACLUsecase{
+		repo:   repo,
+		kbRepo: kbRepo,
+		logger: logger.WithModule("usecase.node_acl"),
+	}
+}
+
+// SetNodeACL set the audience of the nodes, public access removes their rules
+func (u *NodeACLUsecase) SetNodeACL(ctx context.Context, req *domain.SetNodeACLReq) error {
+	if req.Access == domain.NodeAccessPublic {
+		return u.repo.RemoveNodeACLs(ctx, req.KBID, req.NodeIDs)
+	}
+	var groups domain.NodeACLGroups
+	if req.Access == domain.NodeAccessGroups {
+		if len(req.Groups) == 0 {
+			return ErrNodeACLGroupsRequired
+		}
+		groups = req.Groups
+	}
+	count, err := u.repo.SetNodeACLs(ctx, req.KBID, req.NodeIDs, req.Access, groups, req.Subtree)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return domain.ErrNodeNotFound
+	}
+	u.logger.Info("node acl set", log.String("kb_id", req.KBID), log.Int("nodes", int(count)), log.String("access", string(req.Access)))
+	return nil
+}
+
+func (u *NodeACLUsecase) GetNodeACLList(ctx context.Context, req *domain.NodeACLListReq) ([]*domain.NodeACLListItem, error) {
+	return u.repo.GetNodeACLList(ctx, req.KBID)
+}
+
+// ViewerFilter nodes of kb hidden from the viewer, nil if the viewer passes every rule.
+// every node is hidden if the kb is private to readers the viewer is not one of
+func (u *NodeACLUsecase) ViewerFilter(ctx context.Context, kbID string, viewer *domain.NodeViewer) (*domain.NodeACLFilter, error) {
+	kb, err := u.kbRepo.GetKnowledgeBaseByID(ctx, kbID)
+	if err != nil {
+		return nil, err
+	}
+	acls, err := u.repo.GetNodeACLs(ctx, kbID)
+	if err != nil {
+		return nil, err
+	}
+	return viewerFilter(kb.AccessSettings.ReaderAccess, acls, viewer, func() (map[string][]string, error) {
+		return u.repo.GetNodeParents(ctx, kbID)
+	})
+}
+
+// viewerFilter filter of the rules of kb, the tree is only loaded for failed rules covering a subtree
+func viewerFilter(access domain.ReaderAccess, acls []*domain.NodeACL, viewer *domain.NodeViewer, getParents func() (map[string][]string, error)) (*domain.NodeACLFilter, error) {
+	if !access.Allows(viewer) {
+		return domain.NewDenyAllNodeACLFilter(), nil
+	}
+	var parents map[string][]string
+	for _, acl := range acls {
+		if acl.Subtree && !acl.Allows(viewer) {
+			var err error
+			if parents, err = getParents(); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	return domain.NewNodeACLFilter(acls, parents, viewer), nil
+}
+
+// CheckNode return ErrNodeNotFound if the node is hidden from the viewer, so hidden nodes look like missing ones
+func (u *NodeACLUsecase) CheckNode(ctx context.Context, kbID, nodeID string, viewer *domain.NodeViewer) error {
+	filter, err := u.ViewerFilter(ctx, kbID, viewer)
+	if err != nil {
+		return err
+	}
+	if !filter.Allows(nodeID) {
+		return domain.ErrNodeNotFound
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/chaitin/panda-wiki/domain"
+)
+
+func TestViewerFilter(t *testing.T) {
+	// staff-docs covers its subtree, moved was published under staff-docs and later moved out of it
+	acls := []*domain.NodeACL{
+		{NodeID: "staff-docs", Access: domain.NodeAccessGroups, Groups: domain.NodeACLGroups{"staff", "admins"}, Subtree: true},
+		{NodeID: "members", Access: domain.NodeAccessLoggedIn},
+		{NodeID: "open", Access: domain.NodeAccessPublic},
+		{NodeID: "broken", Access: "unknown"},
+	}
+	parents := map[string][]string{
+		"guide":        {"staff-docs"},
+		"guide-detail": {"guide"},
+		"moved":        {"staff-docs", "public-docs"},
+		"members-faq":  {"members"},
+		"intro":        {"public-docs"},
+	}
+	anonymous := (*domain.NodeViewer)(nil)
+	member := &domain.NodeViewer{UserID: "user-1", Groups: []string{"sales"}}
+	staff := &domain.NodeViewer{UserID: "user-2", Groups: []string{"sales", "staff"}}
+	tests := []struct {
+		name    string
+		access  domain.ReaderAccess
+		viewer  *domain.NodeViewer
+		allowed []string
+		denied  []string
+		// the tree is only needed for failed subtree rules
+		loadsParents bool
+	}{
+		{
+			name:         "anonymous viewer only passes public rules",
+			viewer:       anonymous,
+			allowed:      []string{"open", "intro", "public-docs", "members-faq"},
+			denied:       []string{"staff-docs", "guide", "guide-detail", "moved", "members", "broken"},
+			loadsParents: true,
+		},
+		{
+			name:         "logged in viewer outside the groups",
+			viewer:       member,
+			allowed:      []string{"open", "intro", "members", "members-faq"},
+			denied:       []string{"staff-docs", "guide", "guide-detail", "moved", "broken"},
+			loadsParents: true,
+		},
+		{
+			name:    "viewer in one of the groups passes the subtree",
+			viewer:  staff,
+			allowed: []string{"staff-docs", "guide", "guide-detail", "moved", "members", "open"},
+			denied:  []string{"broken"},
+		},
+		{
+			name:    "private kb denies viewers who are not readers",
+			access:  domain.ReaderAccess{Private: true, Groups: []string{"staff"}},
+			viewer:  member,
+			allowed: []string{},
+			denied:  []string{"open", "intro", "members", "node-without-rule"},
+		},
+		{
+			name:    "private kb reader gets the node rules",
+			access:  domain.ReaderAccess{Private: true},
+			viewer:  staff,
+			allowed: []string{"guide-detail", "node-without-rule"},
+			denied:  []string{"broken"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loaded := false
+			filter, err := viewerFilter(tt.access, acls, tt.viewer, func() (map[string][]string, error) {
+				loaded = true
+				return parents, nil
+			})
+			if err != nil {
+				t.Fatalf("viewerFilter() error = %v", err)
+			}
+			if loaded != tt.loadsParents {
+				t.Errorf("parents loaded = %v, want %v", loaded, tt.loadsParents)
+			}
+			for _, id := range tt.allowed {
+				if !filter.Allows(id) {
+					t.Errorf("node %s hidden, want allowed", id)
+				}
+			}
+			for _, id := range tt.denied {
+				if filter.Allows(id) {
+					t.Errorf("node %s allowed, want hidden", id)
+				}
+			}
+		})
+	}
+}
+
+func TestViewerFilterNoRules(t *testing.T) {
+	filter, err := viewerFilter(domain.ReaderAccess{}, nil, nil, func() (map[string][]string, error) {
+		return nil, errors.New("tree loaded without rules")
+	})
+	if err != nil {
+		t.Fatalf("viewerFilter() error = %v", err)
+	}
+	if filter != nil {
+		t.Errorf("filter = %v, want nil as nothing is hidden", filter)
+	}
+}
@@ -0,0 +1,156 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/chaitin/panda-wiki/config"
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/repo/pg"
+	"github.com/chaitin/panda-wiki/store/s3"
+)
+
+type NodeAttachmentUsecase struct {
+	repo     *pg.NodeAttachmentRepository
+	nodeRepo *pg.NodeRepository
+	storage  s3.ObjectStorage
+	config   *config.Config
+	logger   *log.Logger
+}
+
+func NewNodeAttachmentUsecase(repo *pg.NodeAttachmentRepository, nodeRepo *pg.NodeRepository, storage s3.ObjectStorage, config *config.Config, logger *log.Logger) *NodeAttachmentUsecase {
+	return &NodeAttachmentUsecase{
+		repo:     repo,
+		nodeRepo: nodeRepo,
+		storage:  storage,
+		config:   config,
+		logger:   logger.WithModule("usecase.node_attachment"),
+	}
+}
+
+func (u *NodeAttachmentUsecase) UploadNodeAttachment(ctx context.Context, kbID, nodeID string, file *multipart.FileHeader) (*domain.NodeAttachmentListItem, error) {
+	node, err := u.nodeRepo.GetByID(ctx, nodeID)
+	if err != nil {
+		return nil, err
+	}
+	if node.KBID != kbID {
+		return nil, fmt.Errorf("node %s not found in kb %s", nodeID, kbID)
+	}
+	if file.Size > u.config.S3.MaxFileSize {
+		return nil, domain.ErrNodeAttachmentTooLarge
+	}
+	src, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer src.Close()
+
+	attachment, err := u.AddNodeAttachment(ctx, kbID, nodeID, file.Filename, src, file.Size, file.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+	return u.signAttachment(ctx, attachment)
+}
+
+// AddNodeAttachment save the file as attachment of the node, content type is guessed from the extension if empty
+func (u *NodeAttachmentUsecase) AddNodeAttachment(ctx context.Context, kbID, nodeID, filename string, reader io.Reader, size int64, contentType string) (*domain.NodeAttachment, error) {
+	ext := strings.ToLower(filepath.Ext(filename))
+	if contentType == "" {
+		contentType = mime.TypeByExtension(ext)
+	}
+	id := uuid.New().String()
+	attachment := &domain.NodeAttachment{
+		ID:          id,
+		KBID:        kbID,
+		NodeID:      nodeID,
+		Name:        filepath.Base(filename),
+		Key:         fmt.Sprintf("%s/%s/%s%s", kbID, nodeID, id, ext),
+		Size:        size,
+		ContentType: contentType,
+		CreatedAt:   time.Now(),
+	}
+	if err := u.storage.PutObject(ctx, attachment.Key, reader, size, contentType); err != nil {
+		return nil, fmt.Errorf("upload failed: %w", err)
+	}
+	if err := u.repo.CreateNodeAttachment(ctx, attachment); err != nil {
+		if err := u.storage.RemoveObjects(ctx, []string{attachment.Key}); err != nil {
+			u.logger.Warn("failed to remove orphan attachment object", log.String("key", attachment.Key), log.Error(err))
+		}
+		return nil, err
+	}
+	return attachment, nil
+}
+
+func (u *NodeAttachmentUsecase) GetNodeAttachmentList(ctx context.Context, kbID, nodeID string) ([]*domain.NodeAttachmentListItem, error) {
+	attachments, err := u.repo.GetNodeAttachmentList(ctx, kbID, nodeID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*domain.NodeAttachmentListItem, 0, len(attachments))
+	for _, attachment := range attachments {
+		item, err := u.signAttachment(ctx, attachment)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// GetPublishedNodeAttachmentList attachments of a node published in the latest kb release
+func (u *NodeAttachmentUsecase) GetPublishedNodeAttachmentList(ctx context.Context, kbID, nodeID string) ([]*domain.NodeAttachmentListItem, error) {
+	if _, err := u.nodeRepo.GetNodeReleaseDetailByKBIDAndID(ctx, kbID, nodeID); err != nil {
+		return nil, err
+	}
+	return u.GetNodeAttachmentList(ctx, kbID, nodeID)
+}
+
+func (u *NodeAttachmentUsecase) DeleteNodeAttachment(ctx context.Context, req *domain.DeleteNodeAttachmentReq) error {
+	attachment, err := u.repo.DeleteNodeAttachment(ctx, req.KBID, req.ID)
+	if err != nil {
+		return err
+	}
+	return u.storage.RemoveObjects(ctx, []string{attachment.Key})
+}
+
+// DeleteNodesAttachments clean up attachments of deleted nodes
+func (u *NodeAttachmentUsecase) DeleteNodesAttachments(ctx context.Context, kbID string, nodeIDs []string) error {
+	keys, err := u.repo.DeleteNodeAttachmentsByNodeIDs(ctx, kbID, nodeIDs)
+	if err != nil {
+		return err
+	}
+	return u.removeObjects(ctx, keys)
+}
+
+// DeleteKBAttachments clean up attachments of a deleted kb
+func (u *NodeAttachmentUsecase) DeleteKBAttachments(ctx context.Context, kbID string) error {
+	keys, err := u.repo.DeleteNodeAttachmentsByKBID(ctx, kbID)
+	if err != nil {
+		return err
+	}
+	return u.removeObjects(ctx, keys)
+}
+
+func (u *NodeAttachmentUsecase) removeObjects(ctx context.Context, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return u.storage.RemoveObjects(ctx, keys)
+}
+
+func (u *NodeAttachmentUsecase) signAttachment(ctx context.Context, attachment *domain.NodeAttachment) (*domain.NodeAttachmentListItem, error) {
+	url, err := u.storage.SignURL(ctx, attachment.Key, attachment.Name, domain.AttachmentURLExpires)
+	if err != nil {
+		return nil, fmt.Errorf("sign attachment url failed: %w", err)
+	}
+	return &domain.NodeAttachmentListItem{NodeAttachment: attachment, URL: url}, nil
+}
@@ -0,0 +1,189 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/repo/pg"
+)
+
+type NodeCommentUsecase struct {
+	repo        *pg.NodeCommentRepository
+	nodeRepo    *pg.NodeRepository
+	kbRepo      *pg.KnowledgeBaseRepository
+	userRepo    *pg.UserRepository
+	botDetector *BotDetector
+	logger      *log.Logger
+}
+
+func NewNodeCommentUsecase(repo *pg.NodeCommentRepository, nodeRepo *pg.NodeRepository, kbRepo *pg.KnowledgeBaseRepository, userRepo *pg.UserRepository, botDetector *BotDetector, logger *log.Logger) *NodeCommentUsecase {
+	return &NodeCommentUsecase{
+		repo:        repo,
+		nodeRepo:    nodeRepo,
+		kbRepo:      kbRepo,
+		userRepo:    userRepo,
+		botDetector: botDetector,
+		logger:      logger.WithModule("usecase.node_comment"),
+	}
+}
+
+// GetShareNodeComments approved comment threads of a published node
+func (u *NodeCommentUsecase) GetShareNodeComments(ctx context.Context, kbID, nodeID string) ([]*domain.ShareNodeComment, error) {
+	if _, err := u.checkCommentable(ctx, kbID, nodeID); err != nil {
+		return nil, err
+	}
+	comments, err := u.repo.GetApprovedNodeComments(ctx, kbID, nodeID)
+	if err != nil {
+		return nil, err
+	}
+	return buildCommentThreads(comments), nil
+}
+
+// CreateShareNodeComment comment of a reader, spam and moderated comments are not shown until approved
+func (u *NodeCommentUsecase) CreateShareNodeComment(ctx context.Context, req *domain.CreateNodeCommentReq) (*domain.CreateNodeCommentResp, error) {
+	settings, err := u.checkCommentable(ctx, req.KBID, req.NodeID)
+	if err != nil {
+		return nil, err
+	}
+	if u.botDetector.IsBot(ctx, "comment", req.RemoteIP, req.UserAgent, domain.CommentLimitPerMinute) {
+		return nil, domain.ErrCommentRateLimited
+	}
+	comment := &domain.NodeComment{
+		ID:        uuid.New().String(),
+		KBID:      req.KBID,
+		NodeID:    req.NodeID,
+		NickName:  strings.TrimSpace(req.NickName),
+		Content:   strings.TrimSpace(req.Content),
+		Status:    domain.NodeCommentStatusApproved,
+		RemoteIP:  req.RemoteIP,
+		CreatedAt: time.Now(),
+	}
+	if req.ParentID != "" {
+		if err := u.setParent(ctx, comment, req.ParentID); err != nil {
+			return nil, err
+		}
+	}
+	if settings.RequireModeration {
+		comment.Status = domain.NodeCommentStatusPending
+	}
+	if reason := settings.SpamReason(comment.Content + " " + comment.NickName); reason != "" {
+		comment.Status = domain.NodeCommentStatusSpam
+		comment.SpamReason = reason
+	}
+	if err := u.repo.CreateNodeComment(ctx, comment); err != nil {
+		return nil, err
+	}
+	return &domain.CreateNodeCommentResp{ID: comment.ID, Status: comment.Status}, nil
+}
+
+func (u *NodeCommentUsecase) GetNodeCommentList(ctx context.Context, req *domain.NodeCommentListReq) (*domain.PaginatedResult[[]*domain.NodeCommentListItem], error) {
+	comments, total, err := u.repo.GetNodeCommentList(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewPaginatedResult(comments, total), nil
+}
+
+func (u *NodeCommentUsecase) ModerateNodeComments(ctx context.Context, req *domain.ModerateNodeCommentsReq, userID string) error {
+	return u.repo.UpdateNodeCommentsStatus(ctx, req.KBID, req.IDs, req.Status, userID)
+}
+
+// ReplyNodeComment reply of an admin, approved without moderation
+func (u *NodeCommentUsecase) ReplyNodeComment(ctx context.Context, req *domain.ReplyNodeCommentReq, userID string) (string, error) {
+	user, err := u.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	comment := &domain.NodeComment{
+		ID:          uuid.New().String(),
+		KBID:        req.KBID,
+		NickName:    user.Account,
+		UserID:      userID,
+		Content:     strings.TrimSpace(req.Content),
+		Status:      domain.NodeCommentStatusApproved,
+		ModeratedBy: userID,
+		ModeratedAt: &now,
+		CreatedAt:   now,
+	}
+	if err := u.setParent(ctx, comment, req.ParentID); err != nil {
+		return "", err
+	}
+	if err := u.repo.CreateNodeComment(ctx, comment); err != nil {
+		return "", err
+	}
+	return comment.ID, nil
+}
+
+func (u *NodeCommentUsecase) DeleteNodeComment(ctx context.Context, req *domain.DeleteNodeCommentReq) error {
+	return u.repo.DeleteNodeComment(ctx, req.KBID, req.ID)
+}
+
+// checkCommentable comments are enabled in the kb and the node is published
+func (u *NodeCommentUsecase) checkCommentable(ctx context.Context, kbID, nodeID string) (*domain.CommentSettings, error) {
+	kb, err := u.kbRepo.GetKnowledgeBaseByID(ctx, kbID)
+	if err != nil {
+		return nil, err
+	}
+	if !kb.CommentSettings.Enabled {
+		return nil, domain.ErrCommentDisabled
+	}
+	if _, err := u.nodeRepo.GetNodeReleaseDetailByKBIDAndID(ctx, kbID, nodeID); err != nil {
+		return nil, err
+	}
+	return &kb.CommentSettings, nil
+}
+
+// setParent replies join the thread of the replied comment, which must be approved
+func (u *NodeCommentUsecase) setParent(ctx context.Context, comment *domain.NodeComment, parentID string) error {
+	parent, err := u.repo.GetNodeComment(ctx, comment.KBID, parentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.ErrCommentParentNotFound
+		}
+		return err
+	}
+	if parent.Status != domain.NodeCommentStatusApproved || (comment.NodeID != "" && parent.NodeID != comment.NodeID) {
+		return domain.ErrCommentParentNotFound
+	}
+	comment.NodeID = parent.NodeID
+	comment.ParentID = parent.ID
+	comment.RootID = parent.RootID
+	if comment.RootID == "" {
+		comment.RootID = parent.ID
+	}
+	return nil
+}
+
+// buildCommentThreads root comments with replies of their threads in posted order
+func buildCommentThreads(comments []*domain.NodeComment) []*domain.ShareNodeComment {
+	threads := make([]*domain.ShareNodeComment, 0)
+	roots := make(map[string]*domain.ShareNodeComment)
+	for _, comment := range comments {
+		item := &domain.ShareNodeComment{
+			ID:        comment.ID,
+			ParentID:  comment.ParentID,
+			NickName:  comment.NickName,
+			Content:   comment.Content,
+			IsStaff:   comment.UserID != "",
+			CreatedAt: comment.CreatedAt,
+		}
+		if comment.RootID == "" {
+			roots[comment.ID] = item
+			threads = append(threads, item)
+			continue
+		}
+		// replies of rejected or deleted threads are hidden
+		if root, ok := roots[comment.RootID]; ok {
+			root.Replies = append(root.Replies, item)
+		}
+	}
+	return threads
+}
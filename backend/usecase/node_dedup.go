@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+)
+
+// ErrLinkTargetNotFound node to link to instead of a duplicate does not exist
+var ErrLinkTargetNotFound = errors.New("link target node not found")
+
+// CheckDuplicates find existing documents of any kb identical to the items to import,
+// so the import can link to them instead of indexing the same content again
+func (u *NodeUsecase) CheckDuplicates(ctx context.Context, req *domain.NodeDuplicateCheckReq) (*domain.NodeDuplicateCheckResp, error) {
+	keysByHash := make(map[string][]string)
+	hashes := make([]string, 0, len(req.Items))
+	for _, item := range req.Items {
+		hash := domain.ContentHash(item.Content)
+		if hash == "" {
+			continue
+		}
+		if _, ok := keysByHash[hash]; !ok {
+			hashes = append(hashes, hash)
+		}
+		keysByHash[hash] = append(keysByHash[hash], item.Key)
+	}
+	duplicates, err := u.nodeRepo.GetNodesByContentHashes(ctx, hashes)
+	if err != nil {
+		return nil, err
+	}
+	duplicatesByHash := make(map[string][]*domain.NodeDuplicate)
+	for _, duplicate := range duplicates {
+		duplicate.SameKB = duplicate.KBID == req.KBID
+		duplicate.URL = fmt.Sprintf("%s/node/%s", duplicate.BaseURL, duplicate.NodeID)
+		duplicatesByHash[duplicate.ContentHash] = append(duplicatesByHash[duplicate.ContentHash], duplicate)
+	}
+	resp := &domain.NodeDuplicateCheckResp{Items: []*domain.NodeDuplicateCheckResult{}}
+	for _, hash := range hashes {
+		if len(duplicatesByHash[hash]) == 0 {
+			continue
+		}
+		for _, key := range keysByHash[hash] {
+			resp.Items = append(resp.Items, &domain.NodeDuplicateCheckResult{
+				Key:        key,
+				Duplicates: duplicatesByHash[hash],
+			})
+		}
+	}
+	u.logger.Info("check import duplicates", log.String("kb_id", req.KBID), log.Int("item_count", len(req.Items)), log.Int("duplicate_count", len(resp.Items)))
+	return resp, nil
+}
+
+// applyLinkTo replace content of the new node with a link to the existing identical node
+func (u *NodeUsecase) applyLinkTo(ctx context.Context, req *domain.CreateNodeReq) error {
+	target, err := u.nodeRepo.GetNodeByID(ctx, req.LinkToNodeID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrLinkTargetNotFound
+		}
+		return err
+	}
+	// relative links stay inside the kb, links to other kbs need their base url
+	url := fmt.Sprintf("/node/%s", target.ID)
+	if target.KBID != req.KBID {
+		kb, err := u.kbRepo.GetKnowledgeBaseByID(ctx, target.KBID)
+		if err != nil {
+			return err
+		}
+		url = kb.AccessSettings.BaseURL + url
+	}
+	req.Content = domain.LinkedNodeContent(target.Name, url)
+	return nil
+}
@@ -0,0 +1,142 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+)
+
+// effectiveDefaults defaults of the folder merged with defaults of its upper folders
+func (u *NodeUsecase) effectiveDefaults(ctx context.Context, kbID, folderID string) (domain.NodeDefaults, error) {
+	effective := domain.NodeDefaults{}
+	if folderID == "" {
+		return effective, nil
+	}
+	chain, err := u.nodeRepo.GetNodeDefaultsChain(ctx, kbID, folderID)
+	if err != nil {
+		return effective, err
+	}
+	for _, defaults := range chain {
+		effective = effective.Merge(defaults)
+	}
+	return effective, nil
+}
+
+// applyFolderDefaults fill fields not set in the request with defaults of the parent folder
+func (u *NodeUsecase) applyFolderDefaults(ctx context.Context, req *domain.CreateNodeReq) error {
+	defaults, err := u.effectiveDefaults(ctx, req.KBID, req.ParentID)
+	if err != nil {
+		return err
+	}
+	inherited := domain.InheritedFields{}
+	if req.Type == domain.NodeTypeDocument {
+		if req.Visibility == nil && defaults.Visibility != nil {
+			req.Visibility = defaults.Visibility
+			inherited = append(inherited, domain.NodeFieldVisibility)
+		}
+		if req.Content == "" {
+			req.Content = defaults.Template
+		}
+	}
+	if req.Tags == nil && defaults.Tags != nil {
+		req.Tags = defaults.Tags
+		inherited = append(inherited, domain.NodeFieldTags)
+	}
+	if req.SEO == nil && defaults.SEO != nil {
+		req.SEO = defaults.SEO
+		inherited = append(inherited, domain.NodeFieldSEO)
+	}
+	req.InheritedFields = inherited
+	return nil
+}
+
+func (u *NodeUsecase) GetNodeDefaults(ctx context.Context, kbID, id string) (*domain.NodeDefaultsResp, error) {
+	node, err := u.nodeRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if node.KBID != kbID || node.Type != domain.NodeTypeFolder {
+		return nil, gorm.ErrRecordNotFound
+	}
+	effective, err := u.effectiveDefaults(ctx, kbID, id)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.NodeDefaultsResp{
+		Defaults:  node.Defaults,
+		Effective: effective,
+	}, nil
+}
+
+// UpdateNodeDefaults set defaults of the folder, optionally re-apply them to child nodes still inheriting
+func (u *NodeUsecase) UpdateNodeDefaults(ctx context.Context, req *domain.UpdateNodeDefaultsReq) error {
+	if err := u.nodeRepo.UpdateNodeDefaults(ctx, req.KBID, req.ID, req.Defaults); err != nil {
+		return err
+	}
+	if !req.ApplyToChildren {
+		return nil
+	}
+	effective, err := u.effectiveDefaults(ctx, req.KBID, req.ID)
+	if err != nil {
+		return err
+	}
+	nodes, err := u.nodeRepo.GetNodeTree(ctx, req.KBID)
+	if err != nil {
+		return err
+	}
+	children := make(map[string][]*domain.Node)
+	for _, node := range nodes {
+		children[node.ParentID] = append(children[node.ParentID], node)
+	}
+	privateNodeIDs := make([]string, 0)
+	var apply func(parentID string, defaults domain.NodeDefaults) error
+	apply = func(parentID string, defaults domain.NodeDefaults) error {
+		for _, child := range children[parentID] {
+			if len(child.InheritedFields) > 0 {
+				if err := u.nodeRepo.UpdateInheritedNodeFields(ctx, req.KBID, child.ID, child.InheritedFields, defaults); err != nil {
+					return err
+				}
+				if child.InheritedFields.Has(domain.NodeFieldVisibility) && defaults.Visibility != nil &&
+					*defaults.Visibility == domain.NodeVisibilityPrivate && child.Visibility != domain.NodeVisibilityPrivate {
+					privateNodeIDs = append(privateNodeIDs, child.ID)
+				}
+			}
+			if child.Type == domain.NodeTypeFolder {
+				if err := apply(child.ID, defaults.Merge(child.Defaults)); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	if err := apply(req.ID, effective); err != nil {
+		return err
+	}
+	// remove vectors of released documents turned private
+	for _, nodeID := range privateNodeIDs {
+		nodeRelease, err := u.nodeRepo.GetLatestNodeReleaseByNodeID(ctx, nodeID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return err
+		}
+		if nodeRelease.DocID == "" {
+			continue
+		}
+		if err := u.ragRepo.AsyncUpdateNodeReleaseVector(ctx, []*domain.NodeReleaseVectorRequest{
+			{
+				KBID:   req.KBID,
+				DocID:  nodeRelease.DocID,
+				Action: "delete",
+			},
+		}); err != nil {
+			u.logger.Error("failed to delete vector of private node", log.String("node_id", nodeID), log.Error(err))
+		}
+	}
+	return nil
+}
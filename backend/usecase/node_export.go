@@ -0,0 +1,250 @@
+package usecase
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	htmltomarkdown "github.com/JohannesKaufmann/html-to-markdown/v2"
+	"github.com/google/uuid"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/repo/mq"
+	"github.com/chaitin/panda-wiki/repo/pg"
+	"github.com/chaitin/panda-wiki/store/s3"
+)
+
+type NodeExportUsecase struct {
+	repo           *pg.NodeExportRepository
+	nodeRepo       *pg.NodeRepository
+	attachmentRepo *pg.NodeAttachmentRepository
+	mqRepo         *mq.NodeExportRepository
+	storage        s3.ObjectStorage
+	logger         *log.Logger
+}
+
+func NewNodeExportUsecase(
+	repo *pg.NodeExportRepository,
+	nodeRepo *pg.NodeRepository,
+	attachmentRepo *pg.NodeAttachmentRepository,
+	mqRepo *mq.NodeExportRepository,
+	storage s3.ObjectStorage,
+	logger *log.Logger,
+) *NodeExportUsecase {
+	return &NodeExportUsecase{
+		repo:           repo,
+		nodeRepo:       nodeRepo,
+		attachmentRepo: attachmentRepo,
+		mqRepo:         mqRepo,
+		storage:        storage,
+		logger:         logger.WithModule("usecase.node_export"),
+	}
+}
+
+// CreateJob create export job and run it in the consumer
+func (u *NodeExportUsecase) CreateJob(ctx context.Context, req *domain.NodeExportReq) (*domain.NodeExportJob, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	job := &domain.NodeExportJob{
+		ID:        id.String(),
+		KBID:      req.KBID,
+		Status:    domain.NodeExportJobStatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := u.repo.CreateExportJob(ctx, job); err != nil {
+		return nil, err
+	}
+	if err := u.mqRepo.AsyncRunExportJob(ctx, job.KBID, job.ID); err != nil {
+		u.failJob(ctx, job.ID, err)
+		return nil, err
+	}
+	return job, nil
+}
+
+// RunJob write nodes and attachments of kb to a zip archive in object storage, jobs not pending are skipped
+func (u *NodeExportUsecase) RunJob(ctx context.Context, jobID string) error {
+	job, err := u.repo.GetExportJob(ctx, "", jobID)
+	if err != nil {
+		return err
+	}
+	if job.Status != domain.NodeExportJobStatusPending {
+		u.logger.Info("skip export job", log.String("job_id", jobID), log.String("status", string(job.Status)))
+		return nil
+	}
+	if err := u.repo.UpdateExportJob(ctx, jobID, map[string]any{"status": domain.NodeExportJobStatusRunning}); err != nil {
+		return err
+	}
+	// the archive is spooled to disk, object storage needs its size up front
+	file, err := os.CreateTemp("", "panda-wiki-export-*.zip")
+	if err != nil {
+		u.failJob(ctx, jobID, err)
+		return err
+	}
+	defer func() {
+		file.Close()
+		os.Remove(file.Name())
+	}()
+	nodeCount, attachmentCount, err := u.writeArchive(ctx, job.KBID, file)
+	if err != nil {
+		u.failJob(ctx, jobID, err)
+		return err
+	}
+	size, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		u.failJob(ctx, jobID, err)
+		return err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		u.failJob(ctx, jobID, err)
+		return err
+	}
+	key := fmt.Sprintf("export/%s/%s.zip", job.KBID, job.ID)
+	if err := u.storage.PutObject(ctx, key, file, size, "application/zip"); err != nil {
+		u.failJob(ctx, jobID, fmt.Errorf("upload archive failed: %w", err))
+		return err
+	}
+	u.logger.Info("export job succeeded", log.String("job_id", jobID), log.Int("node_count", nodeCount), log.Int("attachment_count", attachmentCount))
+	return u.repo.UpdateExportJob(ctx, jobID, map[string]any{
+		"status":           domain.NodeExportJobStatusSucceeded,
+		"node_count":       nodeCount,
+		"attachment_count": attachmentCount,
+		"size":             size,
+		"key":              key,
+	})
+}
+
+// writeArchive write documents as markdown files in folder directories, attachments of a document are listed in its front-matter
+func (u *NodeExportUsecase) writeArchive(ctx context.Context, kbID string, w io.Writer) (int, int, error) {
+	nodes, err := u.nodeRepo.GetExportNodes(ctx, kbID)
+	if err != nil {
+		return 0, 0, err
+	}
+	attachments, err := u.attachmentRepo.GetKBAttachments(ctx, kbID)
+	if err != nil {
+		return 0, 0, err
+	}
+	archive := zip.NewWriter(w)
+	attachmentCount := 0
+	attachmentPaths := make(map[string][]string)
+	usedPaths := make(map[string]bool)
+	for _, attachment := range attachments {
+		attachmentPath := domain.NodeExportAttachmentPath(attachment.NodeID, attachment.Name)
+		for i := 2; usedPaths[attachmentPath]; i++ {
+			attachmentPath = domain.NodeExportAttachmentPath(attachment.NodeID, fmt.Sprintf("%d-%s", i, attachment.Name))
+		}
+		written, err := u.writeAttachment(ctx, archive, attachmentPath, attachment)
+		if err != nil {
+			return 0, 0, err
+		}
+		if !written {
+			continue
+		}
+		usedPaths[attachmentPath] = true
+		attachmentPaths[attachment.NodeID] = append(attachmentPaths[attachment.NodeID], attachmentPath)
+		attachmentCount++
+	}
+	paths := domain.NodeExportPaths(nodes)
+	for _, node := range nodes {
+		if node.Type == domain.NodeTypeFolder {
+			// keep empty folders
+			if _, err := archive.Create(paths[node.ID] + "/"); err != nil {
+				return 0, 0, err
+			}
+			continue
+		}
+		f, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     paths[node.ID],
+			Method:   zip.Deflate,
+			Modified: node.UpdatedAt,
+		})
+		if err != nil {
+			return 0, 0, err
+		}
+		node.Content = u.markdownContent(node)
+		if _, err := io.WriteString(f, domain.NodeExportMarkdown(node, attachmentPaths[node.ID])); err != nil {
+			return 0, 0, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return 0, 0, err
+	}
+	return len(nodes), attachmentCount, nil
+}
+
+// markdownContent content of html documents converted to markdown, raw html is kept if conversion fails
+func (u *NodeExportUsecase) markdownContent(node *domain.Node) string {
+	if !strings.HasPrefix(node.Content, "<") {
+		return node.Content
+	}
+	markdown, err := htmltomarkdown.ConvertString(node.Content)
+	if err != nil {
+		u.logger.Warn("convert html to markdown failed", log.String("node_id", node.ID), log.Error(err))
+		return node.Content
+	}
+	return markdown
+}
+
+// writeAttachment copy attachment object into the archive, attachments missing in storage are skipped
+func (u *NodeExportUsecase) writeAttachment(ctx context.Context, archive *zip.Writer, name string, attachment *domain.NodeAttachment) (bool, error) {
+	object, err := u.storage.GetObject(ctx, attachment.Key)
+	if err != nil {
+		u.logger.Warn("skip attachment missing in storage", log.String("id", attachment.ID), log.String("key", attachment.Key), log.Error(err))
+		return false, nil
+	}
+	defer object.Close()
+	f, err := archive.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: attachment.CreatedAt,
+	})
+	if err != nil {
+		return false, err
+	}
+	if _, err := io.Copy(f, object); err != nil {
+		return false, fmt.Errorf("copy attachment %s failed: %w", attachment.ID, err)
+	}
+	return true, nil
+}
+
+func (u *NodeExportUsecase) failJob(ctx context.Context, jobID string, jobErr error) {
+	u.logger.Error("export job failed", log.String("job_id", jobID), log.Error(jobErr))
+	if err := u.repo.UpdateExportJob(ctx, jobID, map[string]any{
+		"status": domain.NodeExportJobStatusFailed,
+		"error":  jobErr.Error(),
+	}); err != nil {
+		u.logger.Error("update export job failed", log.String("job_id", jobID), log.Error(err))
+	}
+}
+
+// GetJob status of export job, with download url of the archive when succeeded
+func (u *NodeExportUsecase) GetJob(ctx context.Context, req *domain.NodeExportJobReq) (*domain.NodeExportJob, error) {
+	job, err := u.repo.GetExportJob(ctx, req.KBID, req.JobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status == domain.NodeExportJobStatusSucceeded && job.Key != "" {
+		url, err := u.storage.SignURL(ctx, job.Key, job.ArchiveName(), domain.AttachmentURLExpires)
+		if err != nil {
+			return nil, fmt.Errorf("sign archive url failed: %w", err)
+		}
+		job.URL = url
+	}
+	return job, nil
+}
+
+func (u *NodeExportUsecase) GetJobList(ctx context.Context, req *domain.NodeExportJobListReq) (*domain.PaginatedResult[[]*domain.NodeExportJob], error) {
+	jobs, total, err := u.repo.GetExportJobList(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewPaginatedResult(jobs, total), nil
+}
@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+)
+
+// GetStaleNodes documents of the kb neither updated nor reviewed in the given days, oldest or most viewed first
+func (u *NodeUsecase) GetStaleNodes(ctx context.Context, req *domain.NodeStaleListReq) (*domain.PaginatedResult[[]*domain.NodeStaleItem], error) {
+	days := req.Days
+	if days == 0 {
+		days = domain.NodeStaleDefaultDays
+	}
+	now := time.Now()
+	nodes, total, err := u.nodeRepo.GetStaleNodeList(ctx, req, now.AddDate(0, 0, -days), now.AddDate(0, 0, -domain.NodeStaleViewDays))
+	if err != nil {
+		return nil, err
+	}
+	for _, node := range nodes {
+		node.StaleDays = int(now.Sub(node.FreshAt).Hours() / 24)
+	}
+	return domain.NewPaginatedResult(nodes, total), nil
+}
+
+// MarkNodesReviewed record the content of the nodes was confirmed accurate by the user, without changing their update time
+func (u *NodeUsecase) MarkNodesReviewed(ctx context.Context, req *domain.MarkNodesReviewedReq, userID string) error {
+	count, err := u.nodeRepo.MarkNodesReviewed(ctx, req.KBID, req.NodeIDs, userID)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return domain.ErrNodeNotFound
+	}
+	u.logger.Info("nodes marked reviewed", log.String("kb_id", req.KBID), log.Int("count", int(count)), log.String("user_id", userID))
+	return nil
+}
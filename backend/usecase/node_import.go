@@ -0,0 +1,444 @@
+package usecase
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"path"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/minio/minio-go/v7"
+
+	"github.com/chaitin/panda-wiki/config"
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/pkg/docparser"
+	"github.com/chaitin/panda-wiki/repo/pg"
+	"github.com/chaitin/panda-wiki/store/s3"
+)
+
+var ErrImportArchiveTooLarge = errors.New("archive size too large")
+
+type NodeImportUsecase struct {
+	nodeUsecase       *NodeUsecase
+	kbUsecase         *KnowledgeBaseUsecase
+	attachmentUsecase *NodeAttachmentUsecase
+	modelRepo         *pg.ModelRepository
+	s3Client          *s3.MinioClient
+	config            *config.Config
+	logger            *log.Logger
+}
+
+func NewNodeImportUsecase(nodeUsecase *NodeUsecase, kbUsecase *KnowledgeBaseUsecase, attachmentUsecase *NodeAttachmentUsecase, modelRepo *pg.ModelRepository, s3Client *s3.MinioClient, config *config.Config, logger *log.Logger) *NodeImportUsecase {
+	return &NodeImportUsecase{
+		nodeUsecase:       nodeUsecase,
+		kbUsecase:         kbUsecase,
+		attachmentUsecase: attachmentUsecase,
+		modelRepo:         modelRepo,
+		s3Client:          s3Client,
+		config:            config,
+		logger:            logger.WithModule("usecase.node_import"),
+	}
+}
+
+// importNodeKey nodes with the same key are the same path in the kb
+type importNodeKey struct {
+	parentID string
+	nodeType domain.NodeType
+	name     string
+}
+
+func newImportNodeKey(parentID string, nodeType domain.NodeType, name string) importNodeKey {
+	return importNodeKey{parentID: parentID, nodeType: nodeType, name: strings.ToLower(strings.TrimSpace(name))}
+}
+
+// markdownImport state of an archive being imported
+type markdownImport struct {
+	req    *domain.ImportMarkdownArchiveReq
+	resp   *domain.ImportMarkdownArchiveResp
+	files  map[string]*zip.File
+	nodes  map[importNodeKey]string
+	dirs   map[string]string
+	images map[string]string
+	// documents written by this import, a second file at the same path is skipped
+	written map[string]bool
+}
+
+// ImportMarkdownArchive create folders and documents of a zip of markdown files, documents already at the same path are skipped or overwritten.
+// front-matter written by kb export is read, new and updated documents are published to be indexed
+func (u *NodeImportUsecase) ImportMarkdownArchive(ctx context.Context, req *domain.ImportMarkdownArchiveReq, file *multipart.FileHeader) (*domain.ImportMarkdownArchiveResp, error) {
+	if file.Size > u.config.S3.MaxFileSize {
+		return nil, ErrImportArchiveTooLarge
+	}
+	src, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer src.Close()
+	reader, err := zip.NewReader(src, file.Size)
+	if err != nil {
+		return nil, fmt.Errorf("invalid zip archive: %w", err)
+	}
+	imp, err := u.newMarkdownImport(ctx, req, reader)
+	if err != nil {
+		return nil, err
+	}
+	links := make([]domain.ImportLink, 0)
+	for _, p := range domain.SortedMarkdownPaths(imp.archivePaths()) {
+		nodeID, err := u.importDocument(ctx, imp, p)
+		if err != nil {
+			return nil, fmt.Errorf("import %s failed: %w", p, err)
+		}
+		if nodeID != "" {
+			links = append(links, domain.ImportLink{URL: domain.MarkdownArchiveURL(p), NodeID: nodeID})
+		}
+	}
+	if len(links) > 0 {
+		if _, err := u.nodeUsecase.RewriteImportLinks(ctx, &domain.RewriteImportLinksReq{KBID: req.KBID, Links: links}); err != nil {
+			return nil, err
+		}
+	}
+	changed := append(append([]string{}, imp.resp.NodeIDs...), imp.resp.UpdatedNodeIDs...)
+	if len(changed) > 0 {
+		releaseID, err := u.kbUsecase.PublishNodes(ctx, &domain.PublishNodeReq{
+			KBID:    req.KBID,
+			NodeIDs: changed,
+			Message: fmt.Sprintf("导入 %s", file.Filename),
+		})
+		switch {
+		case errors.Is(err, domain.ErrNodeReviewNotApproved):
+			imp.resp.Warnings = append(imp.resp.Warnings, "documents are kept as drafts until approved in review")
+		case err != nil:
+			return nil, err
+		default:
+			imp.resp.ReleaseID = releaseID
+		}
+	}
+	u.logger.Info("import markdown archive", log.String("kb_id", req.KBID), log.Int("created", len(imp.resp.NodeIDs)),
+		log.Int("updated", len(imp.resp.UpdatedNodeIDs)), log.Int("skipped", len(imp.resp.SkippedPaths)))
+	return imp.resp, nil
+}
+
+func (u *NodeImportUsecase) newMarkdownImport(ctx context.Context, req *domain.ImportMarkdownArchiveReq, reader *zip.Reader) (*markdownImport, error) {
+	existing, err := u.nodeUsecase.GetList(ctx, &domain.GetNodeListReq{KBID: req.KBID})
+	if err != nil {
+		return nil, err
+	}
+	nodes := make(map[importNodeKey]string, len(existing))
+	parentFound := req.ParentID == ""
+	for _, node := range existing {
+		nodes[newImportNodeKey(node.ParentID, node.Type, node.Name)] = node.ID
+		if node.ID == req.ParentID && node.Type == domain.NodeTypeFolder {
+			parentFound = true
+		}
+	}
+	if !parentFound {
+		return nil, fmt.Errorf("folder %s not found in kb %s", req.ParentID, req.KBID)
+	}
+	byName := make(map[string]*zip.File, len(reader.File))
+	names := make([]string, 0, len(reader.File))
+	for _, f := range reader.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		byName[f.Name] = f
+		names = append(names, f.Name)
+	}
+	files := make(map[string]*zip.File)
+	for p, name := range domain.MarkdownArchiveFiles(names) {
+		files[p] = byName[name]
+	}
+	return &markdownImport{
+		req: req,
+		resp: &domain.ImportMarkdownArchiveResp{
+			FolderIDs:      []string{},
+			NodeIDs:        []string{},
+			UpdatedNodeIDs: []string{},
+			SkippedPaths:   []string{},
+			Warnings:       []string{},
+		},
+		files:   files,
+		nodes:   nodes,
+		dirs:    map[string]string{".": req.ParentID},
+		images:  make(map[string]string),
+		written: make(map[string]bool),
+	}, nil
+}
+
+func (imp *markdownImport) archivePaths() map[string]string {
+	paths := make(map[string]string, len(imp.files))
+	for p, f := range imp.files {
+		paths[p] = f.Name
+	}
+	return paths
+}
+
+// importDocument create or overwrite the document at path p, empty node id if skipped
+func (u *NodeImportUsecase) importDocument(ctx context.Context, imp *markdownImport, p string) (string, error) {
+	data, err := u.readArchiveFile(imp.files[p])
+	if err != nil {
+		return "", err
+	}
+	front, content, err := domain.ParseMarkdownFrontMatter(string(data))
+	if err != nil {
+		imp.resp.Warnings = append(imp.resp.Warnings, fmt.Sprintf("%s: invalid front-matter: %v", p, err))
+	}
+	parentID, err := u.ensureFolder(ctx, imp, path.Dir(p))
+	if err != nil {
+		return "", err
+	}
+	title := domain.MarkdownDocumentTitle(p, front)
+	key := newImportNodeKey(parentID, domain.NodeTypeDocument, title)
+	nodeID, exists := imp.nodes[key]
+	if exists && (!imp.req.Overwrite || imp.written[nodeID]) {
+		imp.resp.SkippedPaths = append(imp.resp.SkippedPaths, p)
+		return "", nil
+	}
+	content = u.uploadImages(ctx, imp, p, content)
+	if exists {
+		if err := u.nodeUsecase.Update(ctx, &domain.UpdateNodeReq{ID: nodeID, KBID: imp.req.KBID, Content: &content}); err != nil {
+			return "", err
+		}
+		imp.resp.UpdatedNodeIDs = append(imp.resp.UpdatedNodeIDs, nodeID)
+	} else {
+		nodeID, err = u.nodeUsecase.Create(ctx, &domain.CreateNodeReq{
+			KBID:     imp.req.KBID,
+			ParentID: parentID,
+			Type:     domain.NodeTypeDocument,
+			Name:     title,
+			Content:  content,
+			Emoji:    front.Emoji,
+			Tags:     front.Tags,
+		})
+		if err != nil {
+			return "", err
+		}
+		imp.nodes[key] = nodeID
+		imp.resp.NodeIDs = append(imp.resp.NodeIDs, nodeID)
+	}
+	imp.written[nodeID] = true
+	if err := u.importAttachments(ctx, imp, p, nodeID, front.Attachments, exists); err != nil {
+		return "", err
+	}
+	return nodeID, nil
+}
+
+// ensureFolder node id of the archive directory, missing folders are created
+func (u *NodeImportUsecase) ensureFolder(ctx context.Context, imp *markdownImport, dir string) (string, error) {
+	if id, ok := imp.dirs[dir]; ok {
+		return id, nil
+	}
+	parentID, err := u.ensureFolder(ctx, imp, path.Dir(dir))
+	if err != nil {
+		return "", err
+	}
+	name := path.Base(dir)
+	key := newImportNodeKey(parentID, domain.NodeTypeFolder, name)
+	id, ok := imp.nodes[key]
+	if !ok {
+		id, err = u.nodeUsecase.Create(ctx, &domain.CreateNodeReq{
+			KBID:     imp.req.KBID,
+			ParentID: parentID,
+			Type:     domain.NodeTypeFolder,
+			Name:     name,
+		})
+		if err != nil {
+			return "", err
+		}
+		imp.nodes[key] = id
+		imp.resp.FolderIDs = append(imp.resp.FolderIDs, id)
+	}
+	imp.dirs[dir] = id
+	return id, nil
+}
+
+// uploadImages upload images of the archive referenced by the document and point the links at them
+func (u *NodeImportUsecase) uploadImages(ctx context.Context, imp *markdownImport, p, content string) string {
+	return markdownLinkRegex.ReplaceAllStringFunc(content, func(match string) string {
+		parts := markdownLinkRegex.FindStringSubmatch(match)
+		if !strings.HasPrefix(parts[1], "!") {
+			return match
+		}
+		imagePath := domain.ResolveArchivePath(p, parts[2])
+		f, ok := imp.files[imagePath]
+		if !ok {
+			return match
+		}
+		url, ok := imp.images[imagePath]
+		if !ok {
+			var err error
+			if url, err = u.uploadImage(ctx, imp.req.KBID, f); err != nil {
+				imp.resp.Warnings = append(imp.resp.Warnings, fmt.Sprintf("%s: upload image %s failed: %v", p, imagePath, err))
+				return match
+			}
+			imp.images[imagePath] = url
+			imp.resp.ImageCount++
+		}
+		return parts[1] + url + parts[3]
+	})
+}
+
+func (u *NodeImportUsecase) uploadImage(ctx context.Context, kbID string, f *zip.File) (string, error) {
+	if f.UncompressedSize64 > uint64(u.config.S3.MaxFileSize) {
+		return "", ErrImportArchiveTooLarge
+	}
+	r, err := f.Open()
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+	ext := strings.ToLower(path.Ext(f.Name))
+	key := fmt.Sprintf("%s/%s%s", kbID, uuid.New().String(), ext)
+	if _, err := u.s3Client.PutObject(ctx, domain.Bucket, key, r, int64(f.UncompressedSize64), minio.PutObjectOptions{
+		ContentType: mime.TypeByExtension(ext),
+		UserMetadata: map[string]string{
+			"originalname": path.Base(f.Name),
+		},
+	}); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("/%s/%s", domain.Bucket, key), nil
+}
+
+// importAttachments add files listed in front-matter as attachments of the node, attachments of overwritten documents are not duplicated
+func (u *NodeImportUsecase) importAttachments(ctx context.Context, imp *markdownImport, p, nodeID string, attachments []string, overwrite bool) error {
+	if len(attachments) == 0 {
+		return nil
+	}
+	existing := make(map[string]bool)
+	if overwrite {
+		items, err := u.attachmentUsecase.GetNodeAttachmentList(ctx, imp.req.KBID, nodeID)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			existing[item.Name] = true
+		}
+	}
+	for _, attachment := range attachments {
+		attachmentPath := path.Clean("/" + attachment)[1:]
+		f, ok := imp.files[attachmentPath]
+		if !ok {
+			imp.resp.Warnings = append(imp.resp.Warnings, fmt.Sprintf("%s: attachment %s not found in archive", p, attachment))
+			continue
+		}
+		if existing[path.Base(attachmentPath)] {
+			continue
+		}
+		if f.UncompressedSize64 > uint64(u.config.S3.MaxFileSize) {
+			imp.resp.Warnings = append(imp.resp.Warnings, fmt.Sprintf("%s: attachment %s is too large", p, attachment))
+			continue
+		}
+		r, err := f.Open()
+		if err != nil {
+			return err
+		}
+		_, err = u.attachmentUsecase.AddNodeAttachment(ctx, imp.req.KBID, nodeID, path.Base(attachmentPath), r, int64(f.UncompressedSize64), "")
+		r.Close()
+		if err != nil {
+			return err
+		}
+		imp.resp.AttachmentCount++
+	}
+	return nil
+}
+
+func (u *NodeImportUsecase) readArchiveFile(f *zip.File) ([]byte, error) {
+	if f.UncompressedSize64 > uint64(u.config.S3.MaxFileSize) {
+		return nil, ErrImportArchiveTooLarge
+	}
+	r, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(io.LimitReader(r, u.config.S3.MaxFileSize))
+}
+
+// ImportDocuments convert pdf, docx and pptx files to documents by the parsing service, keeping the original file
+// as an attachment of its document. files failing to parse are skipped, new documents are published to be indexed
+func (u *NodeImportUsecase) ImportDocuments(ctx context.Context, req *domain.ImportDocumentsReq, files []*multipart.FileHeader) (*domain.ImportDocumentsResp, error) {
+	resp := &domain.ImportDocumentsResp{
+		NodeIDs:     []string{},
+		FailedFiles: []string{},
+		Warnings:    []string{},
+	}
+	for _, file := range files {
+		if !docparser.Supported(file.Filename) {
+			return nil, fmt.Errorf("%s: %w", file.Filename, docparser.ErrUnsupportedFile)
+		}
+		if file.Size > u.config.S3.MaxFileSize {
+			return nil, fmt.Errorf("%s: %w", file.Filename, ErrImportArchiveTooLarge)
+		}
+	}
+	for _, file := range files {
+		nodeID, err := u.importParsedDocument(ctx, req, file)
+		if nodeID != "" {
+			resp.NodeIDs = append(resp.NodeIDs, nodeID)
+		} else {
+			resp.FailedFiles = append(resp.FailedFiles, file.Filename)
+		}
+		if err != nil {
+			u.logger.Warn("import document failed", log.String("kb_id", req.KBID), log.String("file", file.Filename), log.Error(err))
+			resp.Warnings = append(resp.Warnings, fmt.Sprintf("%s: %v", file.Filename, err))
+		}
+	}
+	if len(resp.NodeIDs) > 0 {
+		releaseID, err := u.kbUsecase.PublishNodes(ctx, &domain.PublishNodeReq{
+			KBID:    req.KBID,
+			NodeIDs: resp.NodeIDs,
+			Message: fmt.Sprintf("导入 %d 个文档", len(resp.NodeIDs)),
+		})
+		switch {
+		case errors.Is(err, domain.ErrNodeReviewNotApproved):
+			resp.Warnings = append(resp.Warnings, "documents are kept as drafts until approved in review")
+		case err != nil:
+			return nil, err
+		default:
+			resp.ReleaseID = releaseID
+		}
+	}
+	u.logger.Info("import documents", log.String("kb_id", req.KBID), log.Int("created", len(resp.NodeIDs)), log.Int("failed", len(resp.FailedFiles)))
+	return resp, nil
+}
+
+// importParsedDocument create the document of a parsed file and attach the file to it,
+// the node id is returned with the error if only the attachment failed
+func (u *NodeImportUsecase) importParsedDocument(ctx context.Context, req *domain.ImportDocumentsReq, file *multipart.FileHeader) (string, error) {
+	src, err := file.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer src.Close()
+	doc, err := docparser.NewClient(u.config.Parser.URL).Parse(ctx, file.Filename, src)
+	if err != nil {
+		return "", err
+	}
+	name := doc.Title
+	if name == "" {
+		name = strings.TrimSuffix(file.Filename, path.Ext(file.Filename))
+	}
+	nodeID, err := u.nodeUsecase.Create(ctx, &domain.CreateNodeReq{
+		KBID:     req.KBID,
+		ParentID: req.ParentID,
+		Type:     domain.NodeTypeDocument,
+		Name:     name,
+		Content:  doc.Markdown,
+	})
+	if err != nil {
+		return "", err
+	}
+	// the file was read by the upload to the parsing service
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return nodeID, err
+	}
+	if _, err := u.attachmentUsecase.AddNodeAttachment(ctx, req.KBID, nodeID, file.Filename, src, file.Size, file.Header.Get("Content-Type")); err != nil {
+		return nodeID, fmt.Errorf("add original file as attachment failed: %w", err)
+	}
+	return nodeID, nil
+}
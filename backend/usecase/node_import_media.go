@@ -0,0 +1,189 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/minio/minio-go/v7"
+	"gorm.io/gorm"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/pkg/asr"
+	"github.com/chaitin/panda-wiki/utils"
+)
+
+// ImportMedia transcribe an uploaded or downloaded audio or video file by the asr model and create a transcript document,
+// with paragraphs led by their time offsets. uploaded files are kept in storage for the offsets to link to,
+// downloaded ones are linked at their url. the transcript is published to be indexed
+func (u *NodeImportUsecase) ImportMedia(ctx context.Context, req *domain.ImportMediaReq, file *multipart.FileHeader) (*domain.ImportMediaResp, error) {
+	model, err := u.modelRepo.GetModelByType(ctx, domain.ModelTypeASR)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrModelNotConfigured
+		}
+		return nil, err
+	}
+	var (
+		filename string
+		mediaURL string
+		media    io.Reader
+	)
+	if file != nil {
+		if !asr.Supported(file.Filename) {
+			return nil, fmt.Errorf("%s: %w", file.Filename, asr.ErrUnsupportedFile)
+		}
+		if file.Size > u.config.S3.MaxFileSize {
+			return nil, fmt.Errorf("%s: %w", file.Filename, ErrImportArchiveTooLarge)
+		}
+		src, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open file: %w", err)
+		}
+		defer src.Close()
+		if mediaURL, err = u.uploadMedia(ctx, req.KBID, file, src); err != nil {
+			return nil, fmt.Errorf("upload media failed: %w", err)
+		}
+		if _, err := src.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		filename, media = file.Filename, src
+	} else {
+		body, name, err := u.downloadMedia(ctx, req.URL)
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+		filename, mediaURL, media = name, req.URL, body
+	}
+	transcript, err := asr.Transcribe(ctx, &asr.Model{
+		Model:   model.Model,
+		BaseURL: model.BaseURL,
+		APIKey:  model.APIKey,
+		Headers: utils.GetHeaderMap(model.APIHeader),
+	}, filename, media)
+	if err != nil {
+		return nil, fmt.Errorf("transcribe %s failed: %w", filename, err)
+	}
+	if len(transcript.Segments) == 0 {
+		return nil, fmt.Errorf("no speech recognized in %s", filename)
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = strings.TrimSuffix(filename, path.Ext(filename))
+	}
+	segments := make([]domain.MediaTranscriptSegment, 0, len(transcript.Segments))
+	for _, segment := range transcript.Segments {
+		segments = append(segments, domain.MediaTranscriptSegment{Start: segment.Start, End: segment.End, Text: segment.Text})
+	}
+	nodeID, err := u.nodeUsecase.Create(ctx, &domain.CreateNodeReq{
+		KBID:     req.KBID,
+		ParentID: req.ParentID,
+		Type:     domain.NodeTypeDocument,
+		Name:     name,
+		Content:  domain.MediaTranscriptMarkdown(filename, mediaURL, segments),
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp := &domain.ImportMediaResp{
+		NodeID:       nodeID,
+		Duration:     transcript.Duration,
+		Language:     transcript.Language,
+		SegmentCount: len(segments),
+		Warnings:     []string{},
+	}
+	releaseID, err := u.kbUsecase.PublishNodes(ctx, &domain.PublishNodeReq{
+		KBID:    req.KBID,
+		NodeIDs: []string{nodeID},
+		Message: fmt.Sprintf("导入 %s 的转录文本", filename),
+	})
+	switch {
+	case errors.Is(err, domain.ErrNodeReviewNotApproved):
+		resp.Warnings = append(resp.Warnings, "the transcript is kept as a draft until approved in review")
+	case err != nil:
+		return nil, err
+	default:
+		resp.ReleaseID = releaseID
+	}
+	u.logger.Info("import media", log.String("kb_id", req.KBID), log.String("node_id", nodeID), log.String("file", filename),
+		log.Int("segments", len(segments)))
+	return resp, nil
+}
+
+// uploadMedia keep the uploaded media in the static bucket, the transcript links its time offsets to the returned url
+func (u *NodeImportUsecase) uploadMedia(ctx context.Context, kbID string, file *multipart.FileHeader, src io.Reader) (string, error) {
+	ext := strings.ToLower(path.Ext(file.Filename))
+	contentType := file.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = mime.TypeByExtension(ext)
+	}
+	key := fmt.Sprintf("%s/%s%s", kbID, uuid.New().String(), ext)
+	if _, err := u.s3Client.PutObject(ctx, domain.Bucket, key, src, file.Size, minio.PutObjectOptions{
+		ContentType: contentType,
+		UserMetadata: map[string]string{
+			"originalname": file.Filename,
+		},
+	}); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("/%s/%s", domain.Bucket, key), nil
+}
+
+// downloadMedia body and file name of the media at the url, the name gets the extension of its content type if it has none
+func (u *NodeImportUsecase) downloadMedia(ctx context.Context, mediaURL string) (io.ReadCloser, string, error) {
+	if mediaURL == "" {
+		return nil, "", errors.New("file or url is required")
+	}
+	parsed, err := url.Parse(mediaURL)
+	if err != nil {
+		return nil, "", err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, mediaURL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	resp, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return nil, "", fmt.Errorf("download %s failed: %w", mediaURL, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, "", fmt.Errorf("download %s failed: %s", mediaURL, resp.Status)
+	}
+	if resp.ContentLength > u.config.S3.MaxFileSize {
+		resp.Body.Close()
+		return nil, "", fmt.Errorf("%s: %w", mediaURL, ErrImportArchiveTooLarge)
+	}
+	name := path.Base(parsed.Path)
+	if name == "/" || name == "." {
+		name = parsed.Hostname()
+	}
+	if !asr.Supported(name) {
+		contentType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+		exts, _ := mime.ExtensionsByType(contentType)
+		for _, ext := range exts {
+			if asr.Supported(ext) {
+				name = strings.TrimSuffix(name, path.Ext(name)) + ext
+				break
+			}
+		}
+	}
+	if !asr.Supported(name) {
+		resp.Body.Close()
+		return nil, "", fmt.Errorf("%s: %w", mediaURL, asr.ErrUnsupportedFile)
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(resp.Body, u.config.S3.MaxFileSize), resp.Body}, name, nil
+}
@@ -0,0 +1,17 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/chaitin/panda-wiki/domain"
+)
+
+// GetNodeBacklinks nodes of the kb linking to the node
+func (u *NodeUsecase) GetNodeBacklinks(ctx context.Context, kbID, nodeID string) ([]*domain.NodeBacklinkResp, error) {
+	return u.linkRepo.GetBacklinks(ctx, kbID, nodeID)
+}
+
+// GetBrokenNodeLinks intra-wiki links of the kb pointing to deleted nodes
+func (u *NodeUsecase) GetBrokenNodeLinks(ctx context.Context, kbID string) ([]*domain.BrokenNodeLinkResp, error) {
+	return u.linkRepo.GetBrokenLinks(ctx, kbID)
+}
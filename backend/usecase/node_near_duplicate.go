@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/repo/pg"
+)
+
+var ErrNearDuplicateNotFound = errors.New("near-duplicate pair not found")
+
+type NearDuplicateUsecase struct {
+	repo   *pg.NearDuplicateRepository
+	kbRepo *pg.KnowledgeBaseRepository
+	logger *log.Logger
+}
+
+func NewNearDuplicateUsecase(repo *pg.NearDuplicateRepository, kbRepo *pg.KnowledgeBaseRepository, logger *log.Logger) *NearDuplicateUsecase {
+	return &NearDuplicateUsecase{
+		repo:   repo,
+		kbRepo: kbRepo,
+		logger: logger.WithModule("usecase.near_duplicate"),
+	}
+}
+
+func (u *NearDuplicateUsecase) GetNearDuplicates(ctx context.Context, req *domain.NearDuplicateListReq) ([]*domain.NearDuplicateResp, error) {
+	return u.repo.GetNearDuplicates(ctx, req)
+}
+
+// DismissNearDuplicate keep a pair a maintainer found not redundant out of the report
+func (u *NearDuplicateUsecase) DismissNearDuplicate(ctx context.Context, req *domain.NearDuplicateDismissReq) error {
+	nodeID, otherNodeID := domain.NearDuplicateOrder(req.NodeID, req.OtherNodeID)
+	found, err := u.repo.DismissNearDuplicate(ctx, req.KBID, nodeID, otherNodeID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrNearDuplicateNotFound
+	}
+	return nil
+}
+
+// DetectKB compare the documents of the kb now instead of waiting for the daily job
+func (u *NearDuplicateUsecase) DetectKB(ctx context.Context, kbID string) ([]*domain.NearDuplicateResp, error) {
+	if _, err := u.detect(ctx, kbID); err != nil {
+		return nil, err
+	}
+	return u.repo.GetNearDuplicates(ctx, &domain.NearDuplicateListReq{KBID: kbID})
+}
+
+// DetectAll detect near-duplicates of every kb, the counts are recorded as the result of the cron run
+func (u *NearDuplicateUsecase) DetectAll(ctx context.Context) (domain.CronRunResult, error) {
+	kbs, err := u.kbRepo.GetKnowledgeBaseList(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get knowledge base list failed: %w", err)
+	}
+	var errs []error
+	pairCount := 0
+	for _, kb := range kbs {
+		count, err := u.detect(ctx, kb.ID)
+		if err != nil {
+			u.logger.Error("detect near-duplicates failed", log.String("kb_id", kb.ID), log.Error(err))
+			errs = append(errs, fmt.Errorf("kb %s: %w", kb.ID, err))
+			continue
+		}
+		pairCount += count
+	}
+	return domain.CronRunResult{
+		"kb_count":   len(kbs),
+		"pair_count": pairCount,
+	}, errors.Join(errs...)
+}
+
+func (u *NearDuplicateUsecase) detect(ctx context.Context, kbID string) (int, error) {
+	detectedAt := time.Now()
+	nodes, err := u.repo.GetDocumentContents(ctx, kbID)
+	if err != nil {
+		return 0, fmt.Errorf("get document contents failed: %w", err)
+	}
+	shingles := make(map[string]map[uint64]struct{}, len(nodes))
+	for _, node := range nodes {
+		shingles[node.ID] = domain.NearDuplicateShingles(node.Content)
+	}
+	pairs := domain.FindNearDuplicates(kbID, shingles, detectedAt)
+	if err := u.repo.ReplaceKBNearDuplicates(ctx, kbID, pairs, detectedAt); err != nil {
+		return 0, fmt.Errorf("save near-duplicates failed: %w", err)
+	}
+	u.logger.Info("detect near-duplicates", log.String("kb_id", kbID), log.Int("documents", len(nodes)), log.Int("pairs", len(pairs)))
+	return len(pairs), nil
+}
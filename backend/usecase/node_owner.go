@@ -0,0 +1,184 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/pkg/bot/dingtalk"
+	"github.com/chaitin/panda-wiki/pkg/bot/feishu"
+	"github.com/chaitin/panda-wiki/repo/pg"
+)
+
+var ErrNodeOwnerNotFound = errors.New("owner user not found")
+
+type NodeOwnerUsecase struct {
+	repo     *pg.NodeOwnerRepository
+	kbRepo   *pg.KnowledgeBaseRepository
+	userRepo *pg.UserRepository
+	logger   *log.Logger
+}
+
+func NewNodeOwnerUsecase(repo *pg.NodeOwnerRepository, kbRepo *pg.KnowledgeBaseRepository, userRepo *pg.UserRepository, logger *log.Logger) *NodeOwnerUsecase {
+	return &NodeOwnerUsecase{
+		repo:     repo,
+		kbRepo:   kbRepo,
+		userRepo: userRepo,
+		logger:   logger.WithModule("usecase.node_owner"),
+	}
+}
+
+// SetNodeOwner assign the owner and review interval to the nodes, or remove their owner if owner id is empty
+func (u *NodeOwnerUsecase) SetNodeOwner(ctx context.Context, req *domain.SetNodeOwnerReq) error {
+	if req.OwnerID == "" {
+		return u.repo.RemoveNodeOwners(ctx, req.KBID, req.NodeIDs)
+	}
+	user, err := u.userRepo.GetUser(ctx, req.OwnerID)
+	if err != nil {
+		return err
+	}
+	if user.ID == "" {
+		return ErrNodeOwnerNotFound
+	}
+	count, err := u.repo.SetNodeOwners(ctx, req.KBID, req.NodeIDs, req.OwnerID, req.ReviewIntervalDays)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return domain.ErrNodeNotFound
+	}
+	return nil
+}
+
+// GetOverdueNodes owned documents of kb past their review interval, grouped by owner
+func (u *NodeOwnerUsecase) GetOverdueNodes(ctx context.Context, req *domain.OverdueNodeListReq) ([]*domain.OwnerOverdueNodes, error) {
+	kb, err := u.kbRepo.GetKnowledgeBaseByID(ctx, req.KBID)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := u.repo.GetOverdueNodes(ctx, req.KBID, req.OwnerID, kb.ReviewReminderSettings.Interval(), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return groupOverdueNodes(nodes, time.Now()), nil
+}
+
+// SendReviewReminders push the documents due for review to the group of each kb with review reminders enabled,
+// listed by owner. documents are reminded again a while later until they are reviewed
+func (u *NodeOwnerUsecase) SendReviewReminders(ctx context.Context) (domain.CronRunResult, error) {
+	kbs, err := u.kbRepo.GetKnowledgeBaseList(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get knowledge base list failed: %w", err)
+	}
+	now := time.Now()
+	kbCount, nodeCount, failed := 0, 0, 0
+	for _, item := range kbs {
+		kb, err := u.kbRepo.GetKnowledgeBaseByID(ctx, item.ID)
+		if err != nil {
+			return nil, err
+		}
+		settings := kb.ReviewReminderSettings
+		if !settings.Enabled || settings.Webhook.URL == "" {
+			continue
+		}
+		count, err := u.sendReviewReminder(ctx, kb, now)
+		if err != nil {
+			u.logger.Error("send review reminder failed", log.String("kb_id", kb.ID), log.Error(err))
+			failed++
+			continue
+		}
+		if count > 0 {
+			kbCount++
+			nodeCount += count
+		}
+	}
+	result := domain.CronRunResult{"kb_count": kbCount, "node_count": nodeCount}
+	if failed > 0 {
+		return result, fmt.Errorf("send review reminder of %d kbs failed", failed)
+	}
+	return result, nil
+}
+
+func (u *NodeOwnerUsecase) sendReviewReminder(ctx context.Context, kb *domain.KnowledgeBase, now time.Time) (int, error) {
+	settings := kb.ReviewReminderSettings
+	nodes, err := u.repo.GetOverdueNodes(ctx, kb.ID, "", settings.Interval(), now)
+	if err != nil {
+		return 0, err
+	}
+	due := make([]*domain.OverdueNode, 0, len(nodes))
+	for _, node := range nodes {
+		if node.Due(now) {
+			due = append(due, node)
+		}
+	}
+	if len(due) == 0 {
+		return 0, nil
+	}
+	title := fmt.Sprintf("【%s】文档复查提醒", kb.Name)
+	text := renderReviewReminder(groupOverdueNodes(due, now), strings.TrimRight(settings.ConsoleURL, "/"))
+	webhook := settings.Webhook
+	switch webhook.Type {
+	case domain.NotifyWebhookTypeFeishu:
+		err = feishu.SendWebhookMarkdown(ctx, webhook.URL, webhook.Secret, title, text)
+	default:
+		err = dingtalk.SendWebhookMarkdown(ctx, webhook.URL, webhook.Secret, title, "### "+title+"\n\n"+text)
+	}
+	if err != nil {
+		return 0, err
+	}
+	ids := make([]string, 0, len(due))
+	for _, node := range due {
+		ids = append(ids, node.ID)
+	}
+	if err := u.repo.MarkReminded(ctx, ids, now); err != nil {
+		return 0, err
+	}
+	u.logger.Info("review reminder sent", log.String("kb_id", kb.ID), log.Int("nodes", len(due)))
+	return len(due), nil
+}
+
+// groupOverdueNodes group nodes sorted by owner, with the days they are overdue at now
+func groupOverdueNodes(nodes []*domain.OverdueNode, now time.Time) []*domain.OwnerOverdueNodes {
+	owners := make([]*domain.OwnerOverdueNodes, 0)
+	for _, node := range nodes {
+		node.OverdueDays = int(now.Sub(node.FreshAt.AddDate(0, 0, node.ReviewIntervalDays)).Hours() / 24)
+		if len(owners) == 0 || owners[len(owners)-1].OwnerID != node.OwnerID {
+			owners = append(owners, &domain.OwnerOverdueNodes{
+				OwnerID:      node.OwnerID,
+				OwnerAccount: node.OwnerAccount,
+				Nodes:        []*domain.OverdueNode{},
+			})
+		}
+		owner := owners[len(owners)-1]
+		owner.Nodes = append(owner.Nodes, node)
+	}
+	return owners
+}
+
+func renderReviewReminder(owners []*domain.OwnerOverdueNodes, consoleURL string) string {
+	var sb strings.Builder
+	for _, owner := range owners {
+		account := owner.OwnerAccount
+		if account == "" {
+			account = "已删除的用户"
+		}
+		fmt.Fprintf(&sb, "**%s**（%d 篇待复查）\n\n", account, len(owner.Nodes))
+		for i, node := range owner.Nodes {
+			if i == domain.ReviewReminderItemLimit {
+				fmt.Fprintf(&sb, "- ……另有 %d 篇\n", len(owner.Nodes)-i)
+				break
+			}
+			name := strings.TrimSpace(node.Name)
+			if consoleURL != "" {
+				name = fmt.Sprintf("[%s](%s/doc/editor/%s)", name, consoleURL, node.ID)
+			}
+			fmt.Fprintf(&sb, "- %s（最后更新或复查于 %s，已超期 %d 天）\n", name, node.FreshAt.Format("2006-01-02"), node.OverdueDays)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
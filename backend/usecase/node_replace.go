@@ -0,0 +1,232 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/repo/mq"
+	"github.com/chaitin/panda-wiki/repo/pg"
+)
+
+type NodeReplaceUsecase struct {
+	repo   *pg.NodeReplaceRepository
+	mqRepo *mq.NodeReplaceRepository
+	logger *log.Logger
+}
+
+func NewNodeReplaceUsecase(repo *pg.NodeReplaceRepository, mqRepo *mq.NodeReplaceRepository, logger *log.Logger) *NodeReplaceUsecase {
+	return &NodeReplaceUsecase{
+		repo:   repo,
+		mqRepo: mqRepo,
+		logger: logger.WithModule("usecase.node_replace"),
+	}
+}
+
+// Preview nodes affected by the replacement with samples, nothing is changed
+func (u *NodeReplaceUsecase) Preview(ctx context.Context, req *domain.NodeReplaceReq) (*domain.NodeReplacePreviewResp, error) {
+	re, err := req.Compile()
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+	nodes, err := u.repo.GetReplaceDocuments(ctx, req.KBID, req.FolderIDs)
+	if err != nil {
+		return nil, err
+	}
+	resp := &domain.NodeReplacePreviewResp{Nodes: []*domain.NodeReplacePreviewItem{}}
+	for _, node := range nodes {
+		matches := re.FindAllStringIndex(node.Content, -1)
+		if len(matches) == 0 {
+			continue
+		}
+		item := &domain.NodeReplacePreviewItem{
+			ID:         node.ID,
+			Name:       node.Name,
+			MatchCount: len(matches),
+			Samples:    make([]domain.NodeReplaceSample, 0, domain.NodeReplacePreviewSamples),
+		}
+		for _, match := range matches[:min(len(matches), domain.NodeReplacePreviewSamples)] {
+			item.Samples = append(item.Samples, replaceSample(req, re, node.Content, match))
+		}
+		resp.Nodes = append(resp.Nodes, item)
+		resp.NodeCount++
+		resp.MatchCount += len(matches)
+	}
+	return resp, nil
+}
+
+// replaceSample text around the match before and after replacement
+func replaceSample(req *domain.NodeReplaceReq, re *regexp.Regexp, content string, match []int) domain.NodeReplaceSample {
+	prefix := []rune(content[:match[0]])
+	prefix = prefix[max(0, len(prefix)-domain.NodeReplaceSampleContext):]
+	suffix := []rune(content[match[1]:])
+	suffix = suffix[:min(len(suffix), domain.NodeReplaceSampleContext)]
+	matched := content[match[0]:match[1]]
+	return domain.NodeReplaceSample{
+		Before: string(prefix) + matched + string(suffix),
+		After:  string(prefix) + req.ReplaceAll(re, matched) + string(suffix),
+	}
+}
+
+// CreateJob create replace job and run it in the consumer
+func (u *NodeReplaceUsecase) CreateJob(ctx context.Context, req *domain.NodeReplaceReq) (*domain.NodeReplaceJob, error) {
+	if _, err := req.Compile(); err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	job := &domain.NodeReplaceJob{
+		ID:            id.String(),
+		KBID:          req.KBID,
+		Find:          req.Find,
+		Replace:       req.Replace,
+		IsRegex:       req.IsRegex,
+		CaseSensitive: req.CaseSensitive,
+		FolderIDs:     req.FolderIDs,
+		Status:        domain.NodeReplaceJobStatusPending,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := u.repo.CreateReplaceJob(ctx, job); err != nil {
+		return nil, err
+	}
+	if err := u.mqRepo.AsyncRunReplaceJob(ctx, job.KBID, job.ID); err != nil {
+		u.failJob(ctx, job.ID, err)
+		return nil, err
+	}
+	return job, nil
+}
+
+// RunJob replace text of documents and save versions for rollback, jobs not pending are skipped
+func (u *NodeReplaceUsecase) RunJob(ctx context.Context, jobID string) error {
+	job, err := u.repo.GetReplaceJob(ctx, "", jobID)
+	if err != nil {
+		return err
+	}
+	claimed, err := u.repo.ClaimReplaceJob(ctx, jobID)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		u.logger.Info("skip replace job", log.String("job_id", jobID), log.String("status", string(job.Status)))
+		return nil
+	}
+	req := job.Req()
+	re, err := req.Compile()
+	if err != nil {
+		u.failJob(ctx, jobID, err)
+		return nil
+	}
+	nodes, err := u.repo.GetReplaceDocuments(ctx, job.KBID, job.FolderIDs)
+	if err != nil {
+		u.failJob(ctx, jobID, err)
+		return err
+	}
+	versions := make([]*domain.NodeVersion, 0)
+	counts := make(map[string]int)
+	replaceCount := 0
+	for _, node := range nodes {
+		count := len(re.FindAllStringIndex(node.Content, -1))
+		if count == 0 {
+			continue
+		}
+		newContent := req.ReplaceAll(re, node.Content)
+		if newContent == node.Content {
+			continue
+		}
+		counts[node.ID] = count
+		replaceCount += count
+		versions = append(versions, &domain.NodeVersion{
+			KBID:       job.KBID,
+			NodeID:     node.ID,
+			JobID:      jobID,
+			Name:       node.Name,
+			Content:    node.Content,
+			NewContent: newContent,
+			CreatedAt:  time.Now(),
+		})
+	}
+	skippedNodeIDs, err := u.repo.ReplaceNodeContents(ctx, versions)
+	if err != nil {
+		u.failJob(ctx, jobID, err)
+		return err
+	}
+	if len(skippedNodeIDs) > 0 {
+		for _, nodeID := range skippedNodeIDs {
+			replaceCount -= counts[nodeID]
+		}
+		u.logger.Warn("skip replace of nodes edited during replace job", log.String("job_id", jobID), log.String("node_ids", strings.Join(skippedNodeIDs, ",")))
+	}
+	nodeCount := len(versions) - len(skippedNodeIDs)
+	u.logger.Info("replace job succeeded", log.String("job_id", jobID), log.Int("node_count", nodeCount), log.Int("replace_count", replaceCount))
+	return u.repo.UpdateReplaceJob(ctx, jobID, map[string]any{
+		"status":        domain.NodeReplaceJobStatusSucceeded,
+		"node_count":    nodeCount,
+		"replace_count": replaceCount,
+	})
+}
+
+// RecoverJobs settle jobs left running when the consumer stopped, those which changed nothing are run again
+func (u *NodeReplaceUsecase) RecoverJobs(ctx context.Context) error {
+	jobs, err := u.repo.RecoverRunningReplaceJobs(ctx)
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		u.logger.Info("rerun interrupted replace job", log.String("job_id", job.ID))
+		if err := u.mqRepo.AsyncRunReplaceJob(ctx, job.KBID, job.ID); err != nil {
+			u.failJob(ctx, job.ID, err)
+		}
+	}
+	return nil
+}
+
+func (u *NodeReplaceUsecase) failJob(ctx context.Context, jobID string, jobErr error) {
+	u.logger.Error("replace job failed", log.String("job_id", jobID), log.Error(jobErr))
+	if err := u.repo.UpdateReplaceJob(ctx, jobID, map[string]any{
+		"status": domain.NodeReplaceJobStatusFailed,
+		"error":  jobErr.Error(),
+	}); err != nil {
+		u.logger.Error("update replace job failed", log.String("job_id", jobID), log.Error(err))
+	}
+}
+
+func (u *NodeReplaceUsecase) GetJob(ctx context.Context, req *domain.NodeReplaceJobReq) (*domain.NodeReplaceJob, error) {
+	return u.repo.GetReplaceJob(ctx, req.KBID, req.JobID)
+}
+
+func (u *NodeReplaceUsecase) GetJobList(ctx context.Context, req *domain.NodeReplaceJobListReq) (*domain.PaginatedResult[[]*domain.NodeReplaceJob], error) {
+	jobs, total, err := u.repo.GetReplaceJobList(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewPaginatedResult(jobs, total), nil
+}
+
+// Rollback restore content of nodes changed by the job from versions
+func (u *NodeReplaceUsecase) Rollback(ctx context.Context, req *domain.NodeReplaceJobReq) (*domain.NodeReplaceRollbackResp, error) {
+	job, err := u.repo.GetReplaceJob(ctx, req.KBID, req.JobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != domain.NodeReplaceJobStatusSucceeded {
+		return nil, domain.ErrNodeReplaceJobNotRollbackable
+	}
+	resp, err := u.repo.RollbackNodeContents(ctx, req.KBID, req.JobID)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.SkippedNodeIDs) > 0 {
+		u.logger.Warn("skip rollback of nodes edited after replace job", log.String("job_id", req.JobID), log.String("node_ids", strings.Join(resp.SkippedNodeIDs, ",")))
+	}
+	return resp, nil
+}
@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/repo/pg"
+)
+
+type NodeReviewUsecase struct {
+	reviewRepo *pg.NodeReviewRepository
+	nodeRepo   *pg.NodeRepository
+	kbUsecase  *KnowledgeBaseUsecase
+	logger     *log.Logger
+}
+
+func NewNodeReviewUsecase(reviewRepo *pg.NodeReviewRepository, nodeRepo *pg.NodeRepository, kbUsecase *KnowledgeBaseUsecase, logger *log.Logger) *NodeReviewUsecase {
+	return &NodeReviewUsecase{
+		reviewRepo: reviewRepo,
+		nodeRepo:   nodeRepo,
+		kbUsecase:  kbUsecase,
+		logger:     logger.WithModule("usecase.node_review"),
+	}
+}
+
+// Submit submit current version of the node for review
+func (u *NodeReviewUsecase) Submit(ctx context.Context, req *domain.SubmitNodeReviewReq, userID string) (*domain.NodeReview, error) {
+	node, err := u.nodeRepo.GetByID(ctx, req.NodeID)
+	if err != nil {
+		return nil, err
+	}
+	if node.KBID != req.KBID {
+		return nil, fmt.Errorf("node %s not found in kb", req.NodeID)
+	}
+	review := &domain.NodeReview{
+		ID:            uuid.New().String(),
+		KBID:          req.KBID,
+		NodeID:        node.ID,
+		NodeName:      node.Name,
+		NodeUpdatedAt: node.UpdatedAt,
+		Status:        domain.NodeReviewStatusPending,
+		SubmitterID:   userID,
+		SubmitComment: req.Comment,
+		CreatedAt:     time.Now(),
+	}
+	if err := u.reviewRepo.CreateNodeReview(ctx, review); err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// Approve approve the review, then publish and re-index the reviewed version of the node
+func (u *NodeReviewUsecase) Approve(ctx context.Context, req *domain.ApproveNodeReviewReq, userID string) error {
+	review, err := u.getPendingReview(ctx, req.KBID, req.ID, userID)
+	if err != nil {
+		return err
+	}
+	node, err := u.nodeRepo.GetByID(ctx, review.NodeID)
+	if err != nil {
+		return err
+	}
+	if !node.UpdatedAt.Equal(review.NodeUpdatedAt) {
+		// approving would publish content nobody reviewed
+		if _, err := u.reviewRepo.FinishNodeReview(ctx, review.ID, domain.NodeReviewStatusCancelled, userID, req.Comment); err != nil {
+			return err
+		}
+		return domain.ErrNodeChangedSinceSubmit
+	}
+	ok, err := u.reviewRepo.FinishNodeReview(ctx, review.ID, domain.NodeReviewStatusApproved, userID, req.Comment)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return domain.ErrNodeReviewNotPending
+	}
+	if _, err := u.kbUsecase.PublishNodes(ctx, &domain.PublishNodeReq{
+		KBID:    req.KBID,
+		NodeIDs: []string{review.NodeID},
+		Message: fmt.Sprintf("审核通过：%s", review.NodeName),
+	}); err != nil {
+		return fmt.Errorf("publish approved node failed: %w", err)
+	}
+	u.logger.Info("node review approved", log.String("review_id", review.ID), log.String("node_id", review.NodeID), log.String("reviewer_id", userID))
+	return nil
+}
+
+func (u *NodeReviewUsecase) Reject(ctx context.Context, req *domain.RejectNodeReviewReq, userID string) error {
+	review, err := u.getPendingReview(ctx, req.KBID, req.ID, userID)
+	if err != nil {
+		return err
+	}
+	ok, err := u.reviewRepo.FinishNodeReview(ctx, review.ID, domain.NodeReviewStatusRejected, userID, req.Comment)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return domain.ErrNodeReviewNotPending
+	}
+	return nil
+}
+
+func (u *NodeReviewUsecase) getPendingReview(ctx context.Context, kbID, id, userID string) (*domain.NodeReview, error) {
+	review, err := u.reviewRepo.GetNodeReview(ctx, kbID, id)
+	if err != nil {
+		return nil, err
+	}
+	if review.Status != domain.NodeReviewStatusPending {
+		return nil, domain.ErrNodeReviewNotPending
+	}
+	if review.SubmitterID == userID {
+		return nil, domain.ErrNodeReviewSelfReview
+	}
+	return review, nil
+}
+
+func (u *NodeReviewUsecase) GetReview(ctx context.Context, kbID, id string) (*domain.NodeReview, error) {
+	return u.reviewRepo.GetNodeReview(ctx, kbID, id)
+}
+
+func (u *NodeReviewUsecase) GetReviewList(ctx context.Context, req *domain.NodeReviewListReq) (*domain.PaginatedResult[[]*domain.NodeReview], error) {
+	reviews, total, err := u.reviewRepo.GetNodeReviewList(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewPaginatedResult(reviews, total), nil
+}
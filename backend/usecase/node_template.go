@@ -0,0 +1,150 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/repo/pg"
+)
+
+type NodeTemplateUsecase struct {
+	repo        *pg.NodeTemplateRepository
+	nodeUsecase *NodeUsecase
+	logger      *log.Logger
+}
+
+func NewNodeTemplateUsecase(repo *pg.NodeTemplateRepository, nodeUsecase *NodeUsecase, logger *log.Logger) *NodeTemplateUsecase {
+	return &NodeTemplateUsecase{
+		repo:        repo,
+		nodeUsecase: nodeUsecase,
+		logger:      logger.WithModule("usecase.node_template"),
+	}
+}
+
+// GetNodeTemplateList built-in templates followed by templates of the kb
+func (u *NodeTemplateUsecase) GetNodeTemplateList(ctx context.Context, kbID string) ([]*domain.NodeTemplateListItem, error) {
+	templates, err := u.repo.GetNodeTemplateList(ctx, kbID)
+	if err != nil {
+		return nil, err
+	}
+	templates = append(append([]*domain.NodeTemplate{}, domain.BuiltinNodeTemplates...), templates...)
+	items := make([]*domain.NodeTemplateListItem, 0, len(templates))
+	for _, template := range templates {
+		items = append(items, &domain.NodeTemplateListItem{NodeTemplate: template, Placeholders: template.Placeholders()})
+	}
+	return items, nil
+}
+
+func (u *NodeTemplateUsecase) GetNodeTemplate(ctx context.Context, kbID, id string) (*domain.NodeTemplate, error) {
+	if strings.HasPrefix(id, domain.BuiltinNodeTemplatePrefix) {
+		for _, template := range domain.BuiltinNodeTemplates {
+			if template.ID == id {
+				return template, nil
+			}
+		}
+		return nil, fmt.Errorf("node template %s not found", id)
+	}
+	return u.repo.GetNodeTemplate(ctx, kbID, id)
+}
+
+func (u *NodeTemplateUsecase) CreateNodeTemplate(ctx context.Context, req *domain.CreateNodeTemplateReq) (string, error) {
+	now := time.Now()
+	template := &domain.NodeTemplate{
+		ID:        uuid.New().String(),
+		CreatedAt: now,
+	}
+	fillNodeTemplate(template, req, now)
+	if err := u.repo.CreateNodeTemplate(ctx, template); err != nil {
+		return "", err
+	}
+	return template.ID, nil
+}
+
+func (u *NodeTemplateUsecase) UpdateNodeTemplate(ctx context.Context, req *domain.UpdateNodeTemplateReq) error {
+	if strings.HasPrefix(req.ID, domain.BuiltinNodeTemplatePrefix) {
+		return domain.ErrBuiltinNodeTemplateReadOnly
+	}
+	template := &domain.NodeTemplate{ID: req.ID}
+	fillNodeTemplate(template, &req.CreateNodeTemplateReq, time.Now())
+	return u.repo.UpdateNodeTemplate(ctx, template)
+}
+
+func fillNodeTemplate(template *domain.NodeTemplate, req *domain.CreateNodeTemplateReq, now time.Time) {
+	template.KBID = req.KBID
+	template.Name = req.Name
+	template.Description = req.Description
+	template.Category = req.Category
+	template.Type = req.Type
+	template.Emoji = req.Emoji
+	template.Content = req.Content
+	template.Children = req.Children
+	template.UpdatedAt = now
+}
+
+func (u *NodeTemplateUsecase) DeleteNodeTemplate(ctx context.Context, req *domain.DeleteNodeTemplateReq) error {
+	if strings.HasPrefix(req.ID, domain.BuiltinNodeTemplatePrefix) {
+		return domain.ErrBuiltinNodeTemplateReadOnly
+	}
+	return u.repo.DeleteNodeTemplate(ctx, req.KBID, req.ID)
+}
+
+// CreateNodeFromTemplate create the template root node and its children, returns id of the root node
+func (u *NodeTemplateUsecase) CreateNodeFromTemplate(ctx context.Context, req *domain.CreateNodeFromTemplateReq) (string, error) {
+	template, err := u.GetNodeTemplate(ctx, req.KBID, req.TemplateID)
+	if err != nil {
+		return "", err
+	}
+	values := map[string]string{"date": time.Now().Format("2006-01-02")}
+	for key, value := range req.Variables {
+		values[key] = value
+	}
+	name := req.Name
+	if name == "" {
+		name = domain.FillNodeTemplatePlaceholders(template.Name, values)
+	}
+	if _, ok := values["title"]; !ok {
+		values["title"] = name
+	}
+	rootID, err := u.nodeUsecase.Create(ctx, &domain.CreateNodeReq{
+		KBID:     req.KBID,
+		ParentID: req.ParentID,
+		Type:     template.Type,
+		Name:     name,
+		Content:  domain.FillNodeTemplatePlaceholders(template.Content, values),
+		Emoji:    template.Emoji,
+	})
+	if err != nil {
+		return "", err
+	}
+	if err := u.createTemplateChildren(ctx, req.KBID, rootID, template.Children, values); err != nil {
+		return "", err
+	}
+	u.logger.Info("create node from template", log.String("kb_id", req.KBID), log.String("template_id", template.ID), log.String("node_id", rootID))
+	return rootID, nil
+}
+
+func (u *NodeTemplateUsecase) createTemplateChildren(ctx context.Context, kbID, parentID string, items []*domain.NodeTemplateItem, values map[string]string) error {
+	for _, item := range items {
+		id, err := u.nodeUsecase.Create(ctx, &domain.CreateNodeReq{
+			KBID:     kbID,
+			ParentID: parentID,
+			Type:     item.Type,
+			Name:     domain.FillNodeTemplatePlaceholders(item.Name, values),
+			Content:  domain.FillNodeTemplatePlaceholders(item.Content, values),
+			Emoji:    item.Emoji,
+		})
+		if err != nil {
+			return fmt.Errorf("create node %s from template failed: %w", item.Name, err)
+		}
+		if err := u.createTemplateChildren(ctx, kbID, id, item.Children, values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package usecase
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/chaitin/panda-wiki/config"
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/pkg/oidc"
+	"github.com/chaitin/panda-wiki/repo/cache"
+	"github.com/chaitin/panda-wiki/repo/pg"
+)
+
+type OIDCUsecase struct {
+	// nil if sso is not configured
+	client       *oidc.Client
+	config       config.OIDCConfig
+	userRepo     *pg.UserRepository
+	kbMemberRepo *pg.KBMemberRepository
+	stateRepo    *cache.OIDCStateRepo
+	userUsecase  *UserUsecase
+	logger       *log.Logger
+}
+
+func NewOIDCUsecase(
+	config *config.Config,
+	userRepo *pg.UserRepository,
+	kbMemberRepo *pg.KBMemberRepository,
+	stateRepo *cache.OIDCStateRepo,
+	userUsecase *UserUsecase,
+	logger *log.Logger,
+) *OIDCUsecase {
+	u := &OIDCUsecase{
+		config:       config.Auth.OIDC,
+		userRepo:     userRepo,
+		kbMemberRepo: kbMemberRepo,
+		stateRepo:    stateRepo,
+		userUsecase:  userUsecase,
+		logger:       logger.WithModule("usecase.oidc"),
+	}
+	if c := u.config; c.Issuer != "" {
+		u.client = oidc.NewClient(c.Issuer, c.ClientID, c.ClientSecret, c.RedirectURL, c.Scopes)
+		for _, mapping := range c.KBRoles {
+			if domain.KBRole(mapping.Role).Permissions() == nil {
+				u.logger.Warn("unknown kb role of oidc group ignored", log.String("group", mapping.Group), log.String("role", mapping.Role))
+			}
+		}
+	}
+	return u
+}
+
+// Status whether the login page offers sso and password login
+func (u *OIDCUsecase) Status() *domain.OIDCStatusResp {
+	if u.client == nil {
+		return &domain.OIDCStatusResp{PasswordLogin: true}
+	}
+	return &domain.OIDCStatusResp{
+		Enabled:       true,
+		DisplayName:   u.config.DisplayName,
+		PasswordLogin: !u.config.DisablePasswordLogin,
+	}
+}
+
+// LoginURL start a login and return the url of the login page of the provider with the state,
+// which the browser starting the login must keep to finish it
+func (u *OIDCUsecase) LoginURL(ctx context.Context, req *domain.OIDCLoginReq) (string, string, error) {
+	if u.client == nil {
+		return "", "", domain.ErrOIDCDisabled
+	}
+	state, err := oidc.NewVerifier()
+	if err != nil {
+		return "", "", err
+	}
+	login := &domain.OIDCLoginState{Redirect: consoleRedirect(req.Redirect)}
+	if login.Nonce, err = oidc.NewVerifier(); err != nil {
+		return "", "", err
+	}
+	if login.CodeVerifier, err = oidc.NewVerifier(); err != nil {
+		return "", "", err
+	}
+	url, err := u.client.AuthCodeURL(ctx, state, login.Nonce, login.CodeVerifier)
+	if err != nil {
+		return "", "", err
+	}
+	if err := u.stateRepo.Set(ctx, state, login); err != nil {
+		return "", "", err
+	}
+	return url, state, nil
+}
+
+// Callback finish the login the provider redirected back from, return the console token or the challenge of the second factor,
+// and the path to open. browserState is the state kept by the browser at LoginURL, so a callback of a login started by
+// someone else is refused. the user is created on first login, its roles are set from its groups at every login
+func (u *OIDCUsecase) Callback(ctx context.Context, req *domain.OIDCCallbackReq, browserState string, client *domain.SessionClient) (*domain.LoginResp, string, error) {
+	if u.client == nil {
+		return nil, "", domain.ErrOIDCDisabled
+	}
+	if req.State == "" || subtle.ConstantTimeCompare([]byte(req.State), []byte(browserState)) != 1 {
+		return nil, "", domain.ErrOIDCStateInvalid
+	}
+	login, err := u.stateRepo.Take(ctx, req.State)
+	if err != nil {
+		return nil, "", err
+	}
+	if login == nil {
+		return nil, "", domain.ErrOIDCStateInvalid
+	}
+	if req.Error != "" {
+		return nil, login.Redirect, fmt.Errorf("provider rejected login: %s %s", req.Error, req.ErrorDescription)
+	}
+	claims, err := u.client.Exchange(ctx, req.Code, login.Nonce, login.CodeVerifier, u.config.GroupsClaim)
+	if err != nil {
+		return nil, login.Redirect, err
+	}
+	subject := claims.String("sub")
+	if subject == "" {
+		return nil, login.Redirect, errors.New("no subject in id token")
+	}
+	subject = claims.String("iss") + "|" + subject
+	groups := claims.Strings(u.config.GroupsClaim)
+	// keycloak prefixes groups with their path, /wiki-admins matches wiki-admins
+	role, kbRoles := groupRoles(u.config.AdminGroups, u.config.KBRoles, func(group string) bool {
+		return slices.ContainsFunc(groups, func(g string) bool {
+			return strings.TrimPrefix(g, "/") == strings.TrimPrefix(group, "/")
+		})
+	})
+	if role == domain.UserRoleMember && len(kbRoles) == 0 {
+		u.logger.Warn("oidc user without role rejected", log.String("subject", subject), log.Any("groups", groups))
+		return nil, login.Redirect, domain.ErrOIDCNoRole
+	}
+	user, err := u.userRepo.GetUserByOIDCSubject(ctx, subject)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, login.Redirect, err
+	}
+	if user == nil {
+		if user, err = u.createUser(ctx, subject, claims, role); err != nil {
+			return nil, login.Redirect, err
+		}
+	} else if user.Role != role {
+		if err := u.userRepo.UpdateUserRole(ctx, user.ID, role); err != nil {
+			return nil, login.Redirect, err
+		}
+		u.logger.Info("oidc user role updated", log.String("user_id", user.ID), log.String("role", string(role)))
+	}
+	if err := u.kbMemberRepo.SyncUserKBRoles(ctx, user.ID, managedKBIDs(u.config.KBRoles), kbRoles); err != nil {
+		return nil, login.Redirect, err
+	}
+	// sso proves the identity as the password does, the second factor is still asked for
+	resp, err := u.userUsecase.login(ctx, user.ID, client)
+	if err != nil {
+		return nil, login.Redirect, err
+	}
+	u.logger.Info("oidc user logged in", log.String("user_id", user.ID), log.String("account", user.Account), log.String("two_factor", string(resp.TwoFactor)))
+	return resp, login.Redirect, nil
+}
+
+func (u *OIDCUsecase) createUser(ctx context.Context, subject string, claims oidc.Claims, role domain.UserRole) (*domain.User, error) {
+	account := claims.String(u.config.AccountClaim)
+	if account == "" {
+		account = claims.String("email")
+	}
+	if account == "" {
+		account = claims.String("sub")
+	}
+	// local accounts are never taken over by the provider, it could assert any name
+	exists, err := u.userRepo.AccountExists(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, domain.ErrOIDCAccountTaken
+	}
+	// sso users don't know their password, it is random until an admin resets it
+	password, err := oidc.NewVerifier()
+	if err != nil {
+		return nil, err
+	}
+	user := &domain.User{
+		ID:          uuid.New().String(),
+		Account:     account,
+		Password:    password,
+		Role:        role,
+		OIDCSubject: subject,
+	}
+	if err := u.userRepo.CreateUser(ctx, user); err != nil {
+		return nil, err
+	}
+	u.logger.Info("oidc user created", log.String("user_id", user.ID), log.String("account", account), log.String("role", string(role)))
+	return user, nil
+}
+
+// consoleRedirect path of the console to open after login, other sites are never redirected to
+func consoleRedirect(redirect string) string {
+	if !strings.HasPrefix(redirect, "/") || strings.HasPrefix(redirect, "//") || strings.HasPrefix(redirect, "/\\") {
+		return "/"
+	}
+	return redirect
+}
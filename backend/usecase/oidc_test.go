@@ -0,0 +1,38 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/chaitin/panda-wiki/config"
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/pkg/oidc"
+)
+
+func TestOIDCCallbackBrowserState(t *testing.T) {
+	u := &OIDCUsecase{
+		client: oidc.NewClient("https://sso.example.com", "wiki", "secret", "https://wiki.example.com/callback", nil),
+		logger: log.NewLogger(&config.Config{}),
+	}
+	// the state is refused before it is taken, so a callback of another browser does not spend the login
+	tests := []struct {
+		name         string
+		state        string
+		browserState string
+	}{
+		{name: "no state cookie", state: "state-1"},
+		{name: "state of another login", state: "state-1", browserState: "state-2"},
+		{name: "no state", browserState: "state-1"},
+		{name: "empty state and cookie"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := u.Callback(context.Background(), &domain.OIDCCallbackReq{Code: "code", State: tt.state}, tt.browserState, &domain.SessionClient{})
+			if !errors.Is(err, domain.ErrOIDCStateInvalid) {
+				t.Errorf("Callback() error = %v, want %v", err, domain.ErrOIDCStateInvalid)
+			}
+		})
+	}
+}
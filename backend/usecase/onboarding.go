@@ -0,0 +1,151 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/repo/pg"
+)
+
+type OnboardingUsecase struct {
+	kbUsecase   *KnowledgeBaseUsecase
+	nodeUsecase *NodeUsecase
+	appUsecase  *AppUsecase
+	appRepo     *pg.AppRepository
+	kbRepo      *pg.KnowledgeBaseRepository
+	nodeRepo    *pg.NodeRepository
+	modelRepo   *pg.ModelRepository
+	logger      *log.Logger
+}
+
+func NewOnboardingUsecase(
+	kbUsecase *KnowledgeBaseUsecase,
+	nodeUsecase *NodeUsecase,
+	appUsecase *AppUsecase,
+	appRepo *pg.AppRepository,
+	kbRepo *pg.KnowledgeBaseRepository,
+	nodeRepo *pg.NodeRepository,
+	modelRepo *pg.ModelRepository,
+	logger *log.Logger,
+) *OnboardingUsecase {
+	return &OnboardingUsecase{
+		kbUsecase:   kbUsecase,
+		nodeUsecase: nodeUsecase,
+		appUsecase:  appUsecase,
+		appRepo:     appRepo,
+		kbRepo:      kbRepo,
+		nodeRepo:    nodeRepo,
+		modelRepo:   modelRepo,
+		logger:      logger.WithModule("usecase.onboarding"),
+	}
+}
+
+// CreateStarterKB create a kb with sample nodes and a pre-configured web app
+func (u *OnboardingUsecase) CreateStarterKB(ctx context.Context, req *domain.CreateStarterKBReq) (*domain.CreateStarterKBResp, error) {
+	kbID, err := u.kbUsecase.CreateKnowledgeBase(ctx, &domain.CreateKnowledgeBaseReq{
+		Name:     req.Name,
+		Ports:    req.Ports,
+		SSLPorts: req.SSLPorts,
+		Hosts:    req.Hosts,
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp := &domain.CreateStarterKBResp{
+		KBID:    kbID,
+		NodeIDs: make([]string, 0),
+	}
+	if err := u.createStarterNodes(ctx, kbID, "", domain.StarterKBNodes, resp); err != nil {
+		return nil, err
+	}
+
+	app, err := u.appRepo.GetOrCreateApplByKBIDAndType(ctx, kbID, domain.AppTypeWeb)
+	if err != nil {
+		return nil, err
+	}
+	name := req.Name
+	settings := app.Settings
+	settings.Title = req.Name
+	settings.WelcomeStr = domain.StarterKBWelcomeStr
+	settings.RecommendQuestions = domain.StarterKBRecommendQuestions
+	if err := u.appUsecase.UpdateApp(ctx, app.ID, &domain.UpdateAppReq{
+		Name:     &name,
+		Settings: &settings,
+	}); err != nil {
+		return nil, err
+	}
+	resp.AppID = app.ID
+	return resp, nil
+}
+
+func (u *OnboardingUsecase) createStarterNodes(ctx context.Context, kbID, parentID string, nodes []domain.StarterNode, resp *domain.CreateStarterKBResp) error {
+	for _, node := range nodes {
+		nodeType := domain.NodeTypeDocument
+		if len(node.Children) > 0 {
+			nodeType = domain.NodeTypeFolder
+		}
+		nodeID, err := u.nodeUsecase.Create(ctx, &domain.CreateNodeReq{
+			KBID:     kbID,
+			ParentID: parentID,
+			Type:     nodeType,
+			Name:     node.Name,
+			Content:  node.Content,
+			Emoji:    node.Emoji,
+		})
+		if err != nil {
+			return err
+		}
+		resp.NodeIDs = append(resp.NodeIDs, nodeID)
+		if err := u.createStarterNodes(ctx, kbID, nodeID, node.Children, resp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetChecklist report setup completeness of kb
+func (u *OnboardingUsecase) GetChecklist(ctx context.Context, kbID string) (*domain.OnboardingChecklistResp, error) {
+	kb, err := u.kbRepo.GetKnowledgeBaseByID(ctx, kbID)
+	if err != nil {
+		return nil, err
+	}
+
+	modelConfigured := true
+	if _, err := u.modelRepo.GetChatModel(ctx); err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		modelConfigured = false
+	}
+
+	nodeCount, err := u.nodeRepo.GetNodeCount(ctx, kbID)
+	if err != nil {
+		return nil, err
+	}
+
+	contentReleased := true
+	if _, err := u.kbRepo.GetLatestRelease(ctx, kbID); err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		contentReleased = false
+	}
+
+	appPublished := len(kb.AccessSettings.Ports) > 0 || len(kb.AccessSettings.SSLPorts) > 0
+
+	resp := &domain.OnboardingChecklistResp{
+		KBID: kbID,
+		Items: []*domain.OnboardingChecklistItem{
+			{Step: domain.OnboardingStepModelConfigured, Done: modelConfigured},
+			{Step: domain.OnboardingStepContentImported, Done: nodeCount > 0},
+			{Step: domain.OnboardingStepContentReleased, Done: contentReleased},
+			{Step: domain.OnboardingStepAppPublished, Done: appPublished},
+		},
+	}
+	resp.Completed = modelConfigured && nodeCount > 0 && contentReleased && appPublished
+	return resp, nil
+}
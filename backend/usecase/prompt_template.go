@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/repo/pg"
+)
+
+type PromptTemplateUsecase struct {
+	repo         *pg.PromptTemplateRepository
+	appRepo      *pg.AppRepository
+	kbRepo       *pg.KnowledgeBaseRepository
+	llmUsecase   *LLMUsecase
+	modelUsecase *ModelUsecase
+	logger       *log.Logger
+}
+
+func NewPromptTemplateUsecase(
+	repo *pg.PromptTemplateRepository,
+	appRepo *pg.AppRepository,
+	kbRepo *pg.KnowledgeBaseRepository,
+	llmUsecase *LLMUsecase,
+	modelUsecase *ModelUsecase,
+	logger *log.Logger,
+) *PromptTemplateUsecase {
+	return &PromptTemplateUsecase{
+		repo:         repo,
+		appRepo:      appRepo,
+		kbRepo:       kbRepo,
+		llmUsecase:   llmUsecase,
+		modelUsecase: modelUsecase,
+		logger:       logger.WithModule("usecase.prompt_template"),
+	}
+}
+
+func (u *PromptTemplateUsecase) GetAppPrompt(ctx context.Context, appID string) (*domain.GetAppPromptResp, error) {
+	current, err := u.repo.GetLatestAppPromptTemplate(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.GetAppPromptResp{
+		Current:   current,
+		Default:   domain.DefaultPromptTemplate,
+		Variables: domain.PromptVariables,
+	}, nil
+}
+
+func (u *PromptTemplateUsecase) GetAppPromptVersions(ctx context.Context, appID string) ([]*domain.AppPromptTemplate, error) {
+	return u.repo.GetAppPromptTemplateVersions(ctx, appID)
+}
+
+// SaveAppPrompt save the template as the next version of the app, in use by its next chats
+func (u *PromptTemplateUsecase) SaveAppPrompt(ctx context.Context, req *domain.SaveAppPromptReq) (*domain.AppPromptTemplate, error) {
+	if err := domain.ValidatePromptTemplate(req.Content); err != nil {
+		return nil, err
+	}
+	app, err := u.appRepo.GetAppDetail(ctx, req.AppID)
+	if err != nil {
+		return nil, err
+	}
+	template := &domain.AppPromptTemplate{
+		ID:        uuid.New().String(),
+		KBID:      app.KBID,
+		AppID:     app.ID,
+		Content:   req.Content,
+		Note:      req.Note,
+		CreatedAt: time.Now(),
+	}
+	if err := u.repo.CreateAppPromptTemplate(ctx, template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// RestoreAppPrompt save the content of an earlier version as the next version
+func (u *PromptTemplateUsecase) RestoreAppPrompt(ctx context.Context, req *domain.RestoreAppPromptReq) (*domain.AppPromptTemplate, error) {
+	version, err := u.repo.GetAppPromptTemplate(ctx, req.AppID, req.Version)
+	if err != nil {
+		return nil, err
+	}
+	return u.SaveAppPrompt(ctx, &domain.SaveAppPromptReq{
+		AppID:   req.AppID,
+		Content: version.Content,
+		Note:    fmt.Sprintf("restore version %d", version.Version),
+	})
+}
+
+// PreviewAppPrompt render the template for the app without saving it. the question, if any, is answered with it
+// by the chat model of the kb, usage of previews is not counted
+func (u *PromptTemplateUsecase) PreviewAppPrompt(ctx context.Context, req *domain.PreviewAppPromptReq) (*domain.PreviewAppPromptResp, error) {
+	if err := domain.ValidatePromptTemplate(req.Content); err != nil {
+		return nil, err
+	}
+	app, err := u.appRepo.GetAppDetail(ctx, req.AppID)
+	if err != nil {
+		return nil, err
+	}
+	kb, err := u.kbRepo.GetKnowledgeBaseByID(ctx, app.KBID)
+	if err != nil {
+		return nil, err
+	}
+	locale := req.Locale
+	if locale == "" {
+		locale = domain.DefaultPromptLocale
+	}
+	systemPrompt := domain.RenderSystemPrompt(req.Content, &domain.PromptContext{
+		KBName:   kb.Name,
+		AppName:  app.Name,
+		Locale:   locale,
+		Citation: app.Settings.Citation.StyleOrDefault(),
+		Now:      time.Now(),
+	})
+	resp := &domain.PreviewAppPromptResp{Prompt: systemPrompt}
+	if req.Question == "" {
+		return resp, nil
+	}
+	models, err := u.modelUsecase.GetKBChatModels(ctx, kb)
+	if err != nil {
+		return nil, err
+	}
+	messages, rankedNodes, err := u.llmUsecase.FormatQuestionMessages(ctx, kb.ID, req.Question, systemPrompt)
+	if err != nil {
+		return nil, err
+	}
+	resp.Documents = make([]*domain.NodeCotentChunkSSE, 0, len(rankedNodes))
+	for _, node := range rankedNodes {
+		resp.Documents = append(resp.Documents, &domain.NodeCotentChunkSSE{
+			NodeID:  node.NodeID,
+			Name:    node.NodeName,
+			Summary: node.NodeSummary,
+		})
+	}
+	chatModel, err := u.llmUsecase.GetChatModel(ctx, models[0])
+	if err != nil {
+		resp.Error = err.Error()
+		return resp, nil
+	}
+	answer, err := u.llmUsecase.Generate(ctx, chatModel, messages)
+	if err != nil {
+		u.logger.Warn("preview prompt answer failed", log.String("app_id", app.ID), log.Error(err))
+		resp.Error = err.Error()
+		return resp, nil
+	}
+	resp.Answer = answer
+	return resp, nil
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/chaitin/panda-wiki/repo/ipdb"
 	mqRepo "github.com/chaitin/panda-wiki/repo/mq"
 	"github.com/chaitin/panda-wiki/repo/pg"
+	"github.com/chaitin/panda-wiki/store/mail"
 	"github.com/chaitin/panda-wiki/store/rag"
 	"github.com/chaitin/panda-wiki/store/s3"
 )
@@ -16,6 +17,7 @@ var ProviderSet = wire.NewSet(
 	ipdb.ProviderSet,
 	rag.ProviderSet,
 	s3.ProviderSet,
+	mail.ProviderSet,
 
 	NewLLMUsecase,
 	NewNodeUsecase,
@@ -34,4 +36,52 @@ var ProviderSet = wire.NewSet(
 	NewSitemapUsecase,
 	NewFeishuUseCase,
 	NewStatUseCase,
+	NewOnboardingUsecase,
+	NewQuestionClusterUsecase,
+	NewGapReportUsecase,
+	NewTranscriptEmailUsecase,
+	NewBotDetector,
+	NewCronUsecase,
+	NewMaintenanceUsecase,
+	NewNodeReviewUsecase,
+	NewWebhookUsecase,
+	NewDigestUsecase,
+	NewNodeTemplateUsecase,
+	NewSearchUsecase,
+	NewNodeReplaceUsecase,
+	NewNodeAttachmentUsecase,
+	NewExternalLinkUsecase,
+	NewNearDuplicateUsecase,
+	NewRetentionUsecase,
+	NewNodeOwnerUsecase,
+	NewNodeCommentUsecase,
+	NewWarmupUsecase,
+	NewIndexIntegrityUsecase,
+	NewNodeExportUsecase,
+	NewBotProfileUsecase,
+	NewNodeImportUsecase,
+	NewConversationImportUsecase,
+	NewImportSourceUsecase,
+	NewAnomalyUsecase,
+	NewModelCompareUsecase,
+	NewDataExportUsecase,
+	NewGlossaryUsecase,
+	NewTelemetryUsecase,
+	NewAuditUsecase,
+	NewConversationRescoreUsecase,
+	NewEvalUsecase,
+	NewAnswerCacheUsecase,
+	NewGuardrailUsecase,
+	NewOIDCUsecase,
+	NewLDAPUsecase,
+	NewTwoFactorUsecase,
+	NewSessionUsecase,
+	NewIPRuleUsecase,
+	NewNodeACLUsecase,
+	NewReaderUsecase,
+	NewKBMemberUsecase,
+	NewSandboxUsecase,
+	NewPromptTemplateUsecase,
+	NewMessageDebugUsecase,
+	NewAPITokenUsecase,
 )
@@ -0,0 +1,266 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/repo/pg"
+	"github.com/chaitin/panda-wiki/utils"
+)
+
+type QuestionClusterUsecase struct {
+	statRepo   *pg.StatRepository
+	modelRepo  *pg.ModelRepository
+	llmUsecase *LLMUsecase
+	logger     *log.Logger
+}
+
+func NewQuestionClusterUsecase(statRepo *pg.StatRepository, modelRepo *pg.ModelRepository, llmUsecase *LLMUsecase, logger *log.Logger) *QuestionClusterUsecase {
+	return &QuestionClusterUsecase{
+		statRepo:   statRepo,
+		modelRepo:  modelRepo,
+		llmUsecase: llmUsecase,
+		logger:     logger.WithModule("usecase.question_cluster"),
+	}
+}
+
+// ClusterPendingQuestions embed new user questions and assign them to the nearest cluster of their kb
+func (u *QuestionClusterUsecase) ClusterPendingQuestions(ctx context.Context, since time.Time, batchSize int) (int, error) {
+	questions, err := u.statRepo.GetPendingQuestions(ctx, since, batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("get pending questions failed: %w", err)
+	}
+	if len(questions) == 0 {
+		return 0, nil
+	}
+	model, err := u.modelRepo.GetModelByType(ctx, domain.ModelTypeEmbedding)
+	if err != nil {
+		return 0, fmt.Errorf("get embedding model failed: %w", err)
+	}
+	embeddings, err := u.embedQuestions(ctx, model, questions)
+	if err != nil {
+		return 0, fmt.Errorf("embed questions failed: %w", err)
+	}
+
+	kbQuestions := lo.GroupBy(lo.Range(len(questions)), func(i int) string {
+		return questions[i].KBID
+	})
+	for kbID, indexes := range kbQuestions {
+		clusters, err := u.statRepo.GetQuestionClusters(ctx, kbID)
+		if err != nil {
+			return 0, fmt.Errorf("get question clusters failed: %w", err)
+		}
+		changed := make(map[string]*domain.StatQuestionCluster)
+		statQuestions := make([]*domain.StatQuestion, 0, len(indexes))
+		for _, i := range indexes {
+			question := questions[i]
+			if embeddings[i] == nil {
+				// saved without a cluster so the question is not pending forever
+				statQuestions = append(statQuestions, &domain.StatQuestion{
+					ID:             question.ID,
+					KBID:           kbID,
+					ConversationID: question.ConversationID,
+					Question:       question.Content,
+					CreatedAt:      question.CreatedAt,
+				})
+				continue
+			}
+			cluster := u.assignCluster(kbID, clusters, embeddings[i], question)
+			if cluster.Count == 1 {
+				clusters = append(clusters, cluster)
+			}
+			changed[cluster.ID] = cluster
+			statQuestions = append(statQuestions, &domain.StatQuestion{
+				ID:             question.ID,
+				KBID:           kbID,
+				ConversationID: question.ConversationID,
+				ClusterID:      cluster.ID,
+				Question:       question.Content,
+				CreatedAt:      question.CreatedAt,
+			})
+		}
+		if err := u.statRepo.SaveQuestionClusters(ctx, lo.Values(changed), statQuestions); err != nil {
+			return 0, fmt.Errorf("save question clusters failed: %w", err)
+		}
+		if _, err := u.mergeClusters(ctx, clusters, changed); err != nil {
+			return 0, fmt.Errorf("merge question clusters failed: %w", err)
+		}
+	}
+	return len(questions), nil
+}
+
+// embedQuestions embed the questions in one request, or one by one if the request fails so a question the model
+// rejects doesn't fail the batch. the embedding of an empty or rejected question is nil, the error is only returned
+// if no question is embedded.
+func (u *QuestionClusterUsecase) embedQuestions(ctx context.Context, model *domain.Model, questions []*domain.PendingQuestion) ([][]float32, error) {
+	embeddings := make([][]float32, len(questions))
+	indexes := lo.Filter(lo.Range(len(questions)), func(i int, _ int) bool {
+		return strings.TrimSpace(questions[i].Content) != ""
+	})
+	if len(indexes) == 0 {
+		return embeddings, nil
+	}
+	batch, err := u.llmUsecase.Embed(ctx, model, lo.Map(indexes, func(i int, _ int) string {
+		return strings.TrimSpace(questions[i].Content)
+	}))
+	if err == nil && len(batch) == len(indexes) {
+		for j, i := range indexes {
+			embeddings[i] = batch[j]
+		}
+		return embeddings, nil
+	}
+	if err == nil {
+		err = fmt.Errorf("got %d embeddings of %d questions", len(batch), len(indexes))
+	}
+	embedded := 0
+	for _, i := range indexes {
+		embedding, qErr := u.llmUsecase.Embed(ctx, model, []string{strings.TrimSpace(questions[i].Content)})
+		if qErr != nil || len(embedding) != 1 {
+			u.logger.Warn("embed question failed, skip clustering it", log.Error(qErr), log.String("question_id", questions[i].ID))
+			continue
+		}
+		embeddings[i] = embedding[0]
+		embedded++
+	}
+	if embedded == 0 {
+		return nil, err
+	}
+	return embeddings, nil
+}
+
+// assignCluster add question to the most similar cluster, or create a new one if none is similar enough.
+// the label of the cluster is the member question closest to the centroid, the first question labels a new
+// cluster with score 0 as its similarity to the centroid drops once other questions join.
+func (u *QuestionClusterUsecase) assignCluster(kbID string, clusters []*domain.StatQuestionCluster, embedding []float32, question *domain.PendingQuestion) *domain.StatQuestionCluster {
+	best, bestScore := nearestCluster(clusters, embedding)
+	now := time.Now()
+	if best == nil || bestScore < domain.QuestionClusterSimilarityThreshold {
+		return &domain.StatQuestionCluster{
+			ID:         uuid.New().String(),
+			KBID:       kbID,
+			Label:      question.Content,
+			LabelScore: 0,
+			Centroid:   embedding,
+			Count:      1,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+	}
+	best.Centroid = utils.UpdateCentroid(best.Centroid, best.Count, embedding)
+	best.Count++
+	best.UpdatedAt = now
+	if score := utils.CosineSimilarity(best.Centroid, embedding); score > best.LabelScore {
+		best.Label = question.Content
+		best.LabelScore = score
+	}
+	return best
+}
+
+// mergeClusters merge changed clusters whose centroids drifted close to another cluster, the smaller one is merged into the bigger one
+func (u *QuestionClusterUsecase) mergeClusters(ctx context.Context, clusters []*domain.StatQuestionCluster, changed map[string]*domain.StatQuestionCluster) ([]*domain.StatQuestionCluster, error) {
+	merged := make(map[string]bool)
+	for i, a := range clusters {
+		if merged[a.ID] {
+			continue
+		}
+		for _, b := range clusters[i+1:] {
+			if merged[b.ID] {
+				continue
+			}
+			if changed[a.ID] == nil && changed[b.ID] == nil {
+				continue
+			}
+			if utils.CosineSimilarity(a.Centroid, b.Centroid) < domain.QuestionClusterMergeThreshold {
+				continue
+			}
+			into, from := a, b
+			if from.Count > into.Count {
+				into, from = from, into
+			}
+			into.Centroid = mergeCentroids(into.Centroid, into.Count, from.Centroid, from.Count)
+			into.Count += from.Count
+			into.UpdatedAt = time.Now()
+			if from.LabelScore > into.LabelScore {
+				into.Label, into.LabelScore = from.Label, from.LabelScore
+			}
+			if err := u.statRepo.MergeQuestionClusters(ctx, from.ID, into); err != nil {
+				return nil, err
+			}
+			u.logger.Info("merge question clusters", log.String("from", from.ID), log.String("into", into.ID))
+			merged[from.ID] = true
+			if from == a {
+				break
+			}
+		}
+	}
+	return lo.Filter(clusters, func(c *domain.StatQuestionCluster, _ int) bool {
+		return !merged[c.ID]
+	}), nil
+}
+
+func nearestCluster(clusters []*domain.StatQuestionCluster, embedding []float32) (*domain.StatQuestionCluster, float64) {
+	var best *domain.StatQuestionCluster
+	bestScore := 0.0
+	for _, cluster := range clusters {
+		score := utils.CosineSimilarity(cluster.Centroid, embedding)
+		if score > bestScore {
+			best, bestScore = cluster, score
+		}
+	}
+	return best, bestScore
+}
+
+func mergeCentroids(a []float32, countA int64, b []float32, countB int64) []float32 {
+	if len(a) != len(b) || countA+countB <= 0 {
+		return a
+	}
+	centroid := make([]float32, len(a))
+	for i := range a {
+		centroid[i] = (a[i]*float32(countA) + b[i]*float32(countB)) / float32(countA+countB)
+	}
+	return centroid
+}
+
+func (u *QuestionClusterUsecase) GetTopQuestionClusters(ctx context.Context, req *domain.GetQuestionClustersReq) ([]*domain.QuestionClusterResp, error) {
+	end := time.Now()
+	if req.EndTime > 0 {
+		end = time.Unix(req.EndTime, 0)
+	}
+	start := end.Add(-24 * time.Hour)
+	if req.StartTime > 0 {
+		start = time.Unix(req.StartTime, 0)
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	clusters, err := u.statRepo.GetTopQuestionClusters(ctx, req.KBID, start, end, limit)
+	if err != nil {
+		return nil, err
+	}
+	samples, err := u.statRepo.GetQuestionClusterSamples(ctx, lo.Map(clusters, func(c *domain.QuestionClusterResp, _ int) string {
+		return c.ClusterID
+	}), start, end)
+	if err != nil {
+		return nil, err
+	}
+	clusterSamples := lo.GroupBy(samples, func(s *domain.QuestionClusterSample) string {
+		return s.ClusterID
+	})
+	for _, cluster := range clusters {
+		cluster.Questions = lo.Map(lo.Slice(clusterSamples[cluster.ClusterID], 0, domain.QuestionClusterSampleSize), func(s *domain.QuestionClusterSample, _ int) string {
+			return s.Question
+		})
+	}
+	return clusters, nil
+}
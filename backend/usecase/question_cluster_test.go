@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/chaitin/panda-wiki/config"
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+)
+
+func TestAssignClusterLabel(t *testing.T) {
+	u := &QuestionClusterUsecase{}
+	first := u.assignCluster("kb-1", nil, []float32{1, 0}, &domain.PendingQuestion{Content: "forgot my password"})
+	if first.Label != "forgot my password" || first.LabelScore != 0 {
+		t.Fatalf("new cluster label = %q score %v, want the first question with score 0", first.Label, first.LabelScore)
+	}
+	clusters := []*domain.StatQuestionCluster{first}
+	second := u.assignCluster("kb-1", clusters, []float32{0.9, 0.1}, &domain.PendingQuestion{Content: "how to reset password"})
+	if second != first {
+		t.Fatal("similar question created another cluster")
+	}
+	if second.Count != 2 || second.Label != "how to reset password" || second.LabelScore <= 0 {
+		t.Errorf("cluster count = %d label = %q score %v, want the question closest to the centroid", second.Count, second.Label, second.LabelScore)
+	}
+	u.assignCluster("kb-1", clusters, []float32{1, 0.3}, &domain.PendingQuestion{Content: "password help"})
+	if first.Label != "how to reset password" {
+		t.Errorf("label = %q, a question farther from the centroid replaced it", first.Label)
+	}
+}
+
+func TestEmbedQuestions(t *testing.T) {
+	// the model rejects any request containing the bad question
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Input []string `json:"input"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if slices.Contains(req.Input, "bad") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		data := make([]map[string]any, len(req.Input))
+		for i := range req.Input {
+			data[i] = map[string]any{"index": i, "embedding": []float32{1, 0}}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": data})
+	}))
+	defer server.Close()
+	u := &QuestionClusterUsecase{llmUsecase: &LLMUsecase{}, logger: log.NewLogger(&config.Config{})}
+	model := &domain.Model{BaseURL: server.URL}
+	tests := []struct {
+		name      string
+		questions []string
+		embedded  []bool
+		wantErr   bool
+	}{
+		{name: "all embedded", questions: []string{"a", "b"}, embedded: []bool{true, true}},
+		{name: "rejected question skipped", questions: []string{"a", "bad", "b"}, embedded: []bool{true, false, true}},
+		{name: "empty question skipped", questions: []string{" ", "a"}, embedded: []bool{false, true}},
+		{name: "nothing embedded", questions: []string{"bad"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			questions := make([]*domain.PendingQuestion, len(tt.questions))
+			for i, content := range tt.questions {
+				questions[i] = &domain.PendingQuestion{ID: content, Content: content}
+			}
+			embeddings, err := u.embedQuestions(context.Background(), model, questions)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("embedQuestions() succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("embedQuestions() error = %v", err)
+			}
+			for i, embedded := range tt.embedded {
+				if (embeddings[i] != nil) != embedded {
+					t.Errorf("question %q embedded = %v, want %v", tt.questions[i], embeddings[i] != nil, embedded)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"github.com/chaitin/panda-wiki/config"
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/repo/pg"
+)
+
+type ReaderUsecase struct {
+	repo        *pg.ReaderRepository
+	botDetector *BotDetector
+	ldapUsecase *LDAPUsecase
+	config      *config.Config
+	logger      *log.Logger
+}
+
+func NewReaderUsecase(repo *pg.ReaderRepository, botDetector *BotDetector, ldapUsecase *LDAPUsecase, config *config.Config, logger *log.Logger) *ReaderUsecase {
+	return &ReaderUsecase{
+		repo:        repo,
+		botDetector: botDetector,
+		ldapUsecase: ldapUsecase,
+		config:      config,
+		logger:      logger.WithModule("usecase.reader"),
+	}
+}
+
+func (u *ReaderUsecase) CreateReader(ctx context.Context, req *domain.CreateReaderReq) (string, error) {
+	email := normalizeReaderEmail(req.Email)
+	exists, err := u.repo.EmailExists(ctx, req.KBID, email)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		return "", domain.ErrReaderEmailExists
+	}
+	reader := &domain.Reader{
+		ID:        uuid.New().String(),
+		KBID:      req.KBID,
+		Email:     email,
+		Name:      req.Name,
+		Password:  req.Password,
+		Groups:    req.Groups,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := u.repo.CreateReader(ctx, reader); err != nil {
+		return "", err
+	}
+	return reader.ID, nil
+}
+
+func (u *ReaderUsecase) GetReaderList(ctx context.Context, req *domain.ReaderListReq) (*domain.PaginatedResult[[]*domain.Reader], error) {
+	readers, total, err := u.repo.GetReaderList(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewPaginatedResult(readers, total), nil
+}
+
+func (u *ReaderUsecase) UpdateReader(ctx context.Context, req *domain.UpdateReaderReq) error {
+	return u.repo.UpdateReader(ctx, req)
+}
+
+func (u *ReaderUsecase) DeleteReader(ctx context.Context, req *domain.DeleteReaderReq) error {
+	return u.repo.DeleteReader(ctx, req.KBID, req.ID)
+}
+
+type readerClaims struct {
+	Groups []string `json:"groups"`
+	jwt.RegisteredClaims
+}
+
+// Login viewer token of the reader, signed with the jwt secret of the server for the kb
+func (u *ReaderUsecase) Login(ctx context.Context, req *domain.ReaderLoginReq) (*domain.ReaderLoginResp, error) {
+	if u.botDetector.IsBot(ctx, "reader_login", req.RemoteIP, req.UserAgent, domain.ReaderLoginLimitPerMinute) {
+		return nil, domain.ErrReaderLoginLimited
+	}
+	reader, err := u.repo.VerifyReader(ctx, req.KBID, normalizeReaderEmail(req.Email), req.Password)
+	// readers of the directory log in with their directory account in the email field
+	if u.ldapUsecase.ReaderLoginEnabled() && (errors.Is(err, domain.ErrReaderLoginFailed) || err == nil && reader.LDAPDN != "") {
+		reader, err = u.ldapUsecase.ReaderLogin(ctx, req.KBID, strings.TrimSpace(req.Email), req.Password)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if reader.Disabled {
+		return nil, domain.ErrReaderDisabled
+	}
+	now := time.Now()
+	expiresAt := now.Add(domain.ReaderTokenTTL)
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, readerClaims{
+		Groups: reader.Groups,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    domain.ReaderTokenIssuer,
+			Subject:   reader.ID,
+			Audience:  jwt.ClaimStrings{req.KBID},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}).SignedString(u.readerTokenKey())
+	if err != nil {
+		return nil, err
+	}
+	if err := u.repo.UpdateLastLogin(ctx, reader.ID, now); err != nil {
+		u.logger.Warn("failed to update last login of reader", log.String("reader_id", reader.ID), log.Error(err))
+	}
+	return &domain.ReaderLoginResp{Token: token, ExpiresAt: expiresAt, Reader: reader}, nil
+}
+
+// readerTokenKey key of reader tokens derived from the jwt secret, console logins are verified with the secret itself
+// so reader tokens are never accepted by the admin apis
+func (u *ReaderUsecase) readerTokenKey() []byte {
+	mac := hmac.New(sha256.New, []byte(u.config.Auth.JWT.Secret))
+	mac.Write([]byte(domain.ReaderTokenIssuer))
+	return mac.Sum(nil)
+}
+
+// ParseViewerToken viewer of a reader token or of a token of the sso portal signed with the secret of the kb.
+// readers are looked up so disabled readers are rejected and group changes apply to issued tokens
+func (u *ReaderUsecase) ParseViewerToken(ctx context.Context, kbID, secret, token string) (*domain.NodeViewer, error) {
+	if token == "" {
+		return &domain.NodeViewer{}, nil
+	}
+	claims := &readerClaims{}
+	if _, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (any, error) {
+		if issuer, _ := t.Claims.GetIssuer(); issuer == domain.ReaderTokenIssuer {
+			return u.readerTokenKey(), nil
+		}
+		if secret == "" {
+			return nil, jwt.ErrTokenUnverifiable
+		}
+		return []byte(secret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired()); err != nil {
+		return &domain.NodeViewer{}, err
+	}
+	if claims.Issuer != domain.ReaderTokenIssuer {
+		return &domain.NodeViewer{UserID: claims.Subject, Groups: claims.Groups}, nil
+	}
+	// reader tokens of one kb are not valid for another
+	if len(claims.Audience) != 1 || claims.Audience[0] != kbID {
+		return &domain.NodeViewer{}, jwt.ErrTokenInvalidAudience
+	}
+	reader, err := u.repo.GetReader(ctx, kbID, claims.Subject)
+	if err != nil {
+		return &domain.NodeViewer{}, err
+	}
+	if reader.Disabled {
+		return &domain.NodeViewer{}, domain.ErrReaderDisabled
+	}
+	return reader.Viewer(), nil
+}
+
+func normalizeReaderEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/chaitin/panda-wiki/config"
+	"github.com/chaitin/panda-wiki/log"
+)
+
+func TestReaderTokenKey(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Auth.JWT.Secret = "console-secret"
+	u := &ReaderUsecase{config: cfg, logger: log.NewLogger(cfg)}
+	now := time.Now()
+	sign := func(issuer string, key []byte) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, readerClaims{
+			Groups: []string{"staff"},
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    issuer,
+				Subject:   "reader-1",
+				Audience:  jwt.ClaimStrings{"kb-1"},
+				ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+			},
+		}).SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	// console logins are verified with the secret itself, reader tokens must not pass
+	readerToken := sign("panda-wiki-reader", u.readerTokenKey())
+	if _, err := jwt.Parse(readerToken, func(*jwt.Token) (any, error) { return []byte(cfg.Auth.JWT.Secret), nil }); !errors.Is(err, jwt.ErrSignatureInvalid) {
+		t.Errorf("reader token verified with the console secret, error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		secret  string
+		wantErr error
+		wantID  string
+	}{
+		{name: "reader token signed with the console secret", token: sign("panda-wiki-reader", []byte(cfg.Auth.JWT.Secret)), wantErr: jwt.ErrSignatureInvalid},
+		{name: "sso token signed with the kb secret", token: sign("portal", []byte("kb-secret")), secret: "kb-secret", wantID: "reader-1"},
+		{name: "sso token signed with the console secret", token: sign("portal", []byte(cfg.Auth.JWT.Secret)), secret: "kb-secret", wantErr: jwt.ErrSignatureInvalid},
+		{name: "sso token without a kb secret", token: sign("portal", []byte("kb-secret")), wantErr: jwt.ErrTokenUnverifiable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viewer, err := u.ParseViewerToken(context.Background(), "kb-1", tt.secret, tt.token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseViewerToken() error = %v, want %v", err, tt.wantErr)
+			}
+			if viewer.UserID != tt.wantID {
+				t.Errorf("viewer = %q, want %q", viewer.UserID, tt.wantID)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/repo/pg"
+)
+
+type RetentionUsecase struct {
+	settingRepo   *pg.SettingRepository
+	retentionRepo *pg.RetentionRepository
+	logger        *log.Logger
+}
+
+func NewRetentionUsecase(settingRepo *pg.SettingRepository, retentionRepo *pg.RetentionRepository, logger *log.Logger) *RetentionUsecase {
+	return &RetentionUsecase{
+		settingRepo:   settingRepo,
+		retentionRepo: retentionRepo,
+		logger:        logger.WithModule("usecase.retention"),
+	}
+}
+
+// GetRetention retention settings, the defaults if not configured
+func (u *RetentionUsecase) GetRetention(ctx context.Context) (*domain.RetentionSettings, error) {
+	settings := domain.DefaultRetentionSettings
+	if err := u.settingRepo.GetSetting(ctx, domain.SettingKeyRetention, &settings); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+func (u *RetentionUsecase) UpdateRetention(ctx context.Context, settings *domain.RetentionSettings) error {
+	if err := u.settingRepo.UpdateSetting(ctx, domain.SettingKeyRetention, settings); err != nil {
+		return err
+	}
+	u.logger.Info("retention settings updated", log.Any("settings", settings))
+	return nil
+}
+
+// ApplyRetention remove data older than its retention, a failed removal does not stop the others
+func (u *RetentionUsecase) ApplyRetention(ctx context.Context) (domain.CronRunResult, error) {
+	settings, err := u.GetRetention(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	removals := []struct {
+		name   string
+		days   int
+		remove func(context.Context, time.Time) (int64, error)
+	}{
+		{"conversations", settings.ConversationDays, u.retentionRepo.RemoveConversations},
+		{"messages", settings.MessageDays, u.retentionRepo.RemoveMessages},
+		{"stat_events", settings.StatEventDays, u.retentionRepo.RemoveStatPages},
+		{"geo_stats", settings.GeoStatDays, u.retentionRepo.RemoveGeoStats},
+		{"funnel_events", settings.FunnelEventDays, u.retentionRepo.RemoveFunnelEvents},
+		{"job_runs", settings.JobRunDays, u.retentionRepo.RemoveCronRuns},
+		{"audit_logs", settings.AuditLogDays, u.retentionRepo.RemoveAuditLogs},
+		{"guardrail_events", settings.GuardrailEventDays, u.retentionRepo.RemoveGuardrailEvents},
+		{"anomalies", settings.AnomalyDays, u.retentionRepo.RemoveAnomalies},
+		{"revoked_sessions", settings.RevokedSessionDays, u.retentionRepo.RemoveRevokedSessions},
+	}
+	result := domain.CronRunResult{}
+	var errs []error
+	for _, removal := range removals {
+		before, ok := domain.RetentionCutoff(now, removal.days)
+		if !ok {
+			continue
+		}
+		count, err := removal.remove(ctx, before)
+		if err != nil {
+			u.logger.Error("remove old data failed", log.String("data", removal.name), log.Error(err))
+			errs = append(errs, fmt.Errorf("remove old %s: %w", removal.name, err))
+			continue
+		}
+		result[removal.name] = count
+	}
+	return result, errors.Join(errs...)
+}
@@ -0,0 +1,283 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/repo/pg"
+	"github.com/chaitin/panda-wiki/store/rag"
+)
+
+// SandboxUsecase temporary kbs of api tokens, for ci pipelines to test documents without touching production kbs
+type SandboxUsecase struct {
+	kbRepo      *pg.KnowledgeBaseRepository
+	nodeRepo    *pg.NodeRepository
+	rag         rag.RAGService
+	kbUsecase   *KnowledgeBaseUsecase
+	nodeUsecase *NodeUsecase
+	llmUsecase  *LLMUsecase
+	modelRepo   *pg.ModelRepository
+	logger      *log.Logger
+}
+
+func NewSandboxUsecase(
+	kbRepo *pg.KnowledgeBaseRepository,
+	nodeRepo *pg.NodeRepository,
+	rag rag.RAGService,
+	kbUsecase *KnowledgeBaseUsecase,
+	nodeUsecase *NodeUsecase,
+	llmUsecase *LLMUsecase,
+	modelRepo *pg.ModelRepository,
+	logger *log.Logger,
+) *SandboxUsecase {
+	return &SandboxUsecase{
+		kbRepo:      kbRepo,
+		nodeRepo:    nodeRepo,
+		rag:         rag,
+		kbUsecase:   kbUsecase,
+		nodeUsecase: nodeUsecase,
+		llmUsecase:  llmUsecase,
+		modelRepo:   modelRepo,
+		logger:      logger.WithModule("usecase.sandbox"),
+	}
+}
+
+func (u *SandboxUsecase) CreateSandbox(ctx context.Context, token *domain.APIToken, req *domain.CreateSandboxReq) (*domain.SandboxKB, error) {
+	ttl := domain.DefaultSandboxTTL
+	if req.TTLMinutes > 0 {
+		ttl = time.Duration(req.TTLMinutes) * time.Minute
+	}
+	datasetID, err := u.rag.CreateKnowledgeBase(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := time.Now().Add(ttl)
+	kb := &domain.KnowledgeBase{
+		ID:               uuid.New().String(),
+		Name:             req.Name,
+		DatasetID:        datasetID,
+		SandboxTokenID:   token.ID,
+		SandboxExpiresAt: &expiresAt,
+	}
+	if req.AnswerSettings != nil {
+		kb.AnswerSettings = *req.AnswerSettings
+	}
+	if err := u.kbRepo.CreateSandboxKnowledgeBase(ctx, kb, token.MaxSandboxes); err != nil {
+		if err := u.rag.DeleteKnowledgeBase(ctx, datasetID); err != nil {
+			u.logger.Warn("delete dataset of sandbox failed", log.String("dataset_id", datasetID), log.Error(err))
+		}
+		return nil, err
+	}
+	u.logger.Info("sandbox kb created", log.String("kb_id", kb.ID), log.String("token_id", token.ID), log.Any("expires_at", expiresAt))
+	return &domain.SandboxKB{ID: kb.ID, Name: kb.Name, ExpiresAt: expiresAt, CreatedAt: kb.CreatedAt}, nil
+}
+
+func (u *SandboxUsecase) GetSandboxList(ctx context.Context, token *domain.APIToken) ([]*domain.SandboxKB, error) {
+	kbs, err := u.kbRepo.GetSandboxKnowledgeBaseList(ctx, token.ID)
+	if err != nil {
+		return nil, err
+	}
+	sandboxes := make([]*domain.SandboxKB, 0, len(kbs))
+	for _, kb := range kbs {
+		sandbox := &domain.SandboxKB{ID: kb.ID, Name: kb.Name, CreatedAt: kb.CreatedAt}
+		if kb.SandboxExpiresAt != nil {
+			sandbox.ExpiresAt = *kb.SandboxExpiresAt
+		}
+		sandboxes = append(sandboxes, sandbox)
+	}
+	return sandboxes, nil
+}
+
+// getSandbox sandbox kb of the token, production kbs and sandboxes of other tokens are not found
+func (u *SandboxUsecase) getSandbox(ctx context.Context, token *domain.APIToken, kbID string) (*domain.KnowledgeBase, error) {
+	kb, err := u.kbRepo.GetKnowledgeBaseByID(ctx, kbID)
+	if err != nil {
+		if errors.Is(err, domain.ErrKBNotFound) {
+			return nil, domain.ErrSandboxNotFound
+		}
+		return nil, err
+	}
+	if kb.SandboxTokenID == "" || kb.SandboxTokenID != token.ID {
+		return nil, domain.ErrSandboxNotFound
+	}
+	return kb, nil
+}
+
+// PushNodes create public documents in the sandbox and publish them, they are searchable once indexed
+func (u *SandboxUsecase) PushNodes(ctx context.Context, token *domain.APIToken, req *domain.PushSandboxNodesReq) (*domain.PushSandboxNodesResp, error) {
+	if _, err := u.getSandbox(ctx, token, req.KBID); err != nil {
+		return nil, err
+	}
+	visibility := domain.NodeVisibilityPublic
+	nodeIDs := make([]string, 0, len(req.Nodes))
+	for _, node := range req.Nodes {
+		nodeID, err := u.nodeUsecase.Create(ctx, &domain.CreateNodeReq{
+			KBID:       req.KBID,
+			Type:       domain.NodeTypeDocument,
+			Name:       node.Name,
+			Content:    node.Content,
+			Visibility: &visibility,
+		})
+		if err != nil {
+			return nil, err
+		}
+		nodeIDs = append(nodeIDs, nodeID)
+	}
+	releaseID, err := u.kbUsecase.PublishNodes(ctx, &domain.PublishNodeReq{KBID: req.KBID, NodeIDs: nodeIDs})
+	if err != nil {
+		return nil, err
+	}
+	return &domain.PushSandboxNodesResp{NodeIDs: nodeIDs, ReleaseID: releaseID}, nil
+}
+
+// GetStatus whether the published documents of the sandbox are in the vector store
+func (u *SandboxUsecase) GetStatus(ctx context.Context, token *domain.APIToken, req *domain.SandboxKBReq) (*domain.SandboxStatusResp, error) {
+	if _, err := u.getSandbox(ctx, token, req.KBID); err != nil {
+		return nil, err
+	}
+	releases, err := u.nodeRepo.GetIndexedNodeReleases(ctx, req.KBID)
+	if err != nil {
+		return nil, err
+	}
+	resp := &domain.SandboxStatusResp{NodeCount: int64(len(releases))}
+	for _, release := range releases {
+		if release.DocID != "" {
+			resp.IndexedCount++
+		}
+	}
+	resp.Ready = resp.NodeCount > 0 && resp.IndexedCount == resp.NodeCount
+	return resp, nil
+}
+
+// Eval answer the questions from the sandbox as chat would, with the chat model of the kb and the built-in prompt,
+// check the retrieved documents against expectations and score the answers with the same model as judge
+func (u *SandboxUsecase) Eval(ctx context.Context, token *domain.APIToken, req *domain.SandboxEvalReq) (*domain.SandboxEvalResp, error) {
+	kb, err := u.getSandbox(ctx, token, req.KBID)
+	if err != nil {
+		return nil, err
+	}
+	model, err := u.modelRepo.GetKBModel(ctx, kb.ID, domain.ModelTypeChat)
+	if err != nil {
+		return nil, fmt.Errorf("get chat model failed: %w", err)
+	}
+	systemPrompt := domain.RenderSystemPrompt("", &domain.PromptContext{
+		KBName:   kb.Name,
+		Citation: domain.CitationStyleInline,
+		Now:      time.Now(),
+	})
+	topK := req.TopK
+	if topK == 0 {
+		topK = domain.DefaultSandboxEvalTopK
+	}
+	results := make([]*domain.SandboxEvalResult, len(req.Questions))
+	var g errgroup.Group
+	g.SetLimit(domain.SandboxEvalConcurrency)
+	for i, question := range req.Questions {
+		g.Go(func() error {
+			results[i] = u.evalQuestion(ctx, kb, model, systemPrompt, question, topK)
+			return nil
+		})
+	}
+	_ = g.Wait()
+	resp := domain.NewSandboxEvalResp(results, req.MinPassRate)
+	u.logger.Info("sandbox kb evaluated", log.String("kb_id", kb.ID), log.Int("total", resp.Total), log.Int("passed", resp.PassedCount))
+	return resp, nil
+}
+
+// evalQuestion retrieve and answer the question, the model is not called if the confidence gate would reply
+// reference links only
+func (u *SandboxUsecase) evalQuestion(
+	ctx context.Context,
+	kb *domain.KnowledgeBase,
+	model *domain.Model,
+	systemPrompt string,
+	question *domain.SandboxEvalQuestion,
+	topK int,
+) *domain.SandboxEvalResult {
+	ctx, cancel := context.WithTimeout(ctx, domain.EvalAnswerTimeout)
+	defer cancel()
+	rankedNodes, err := u.llmUsecase.RetrieveNodes(ctx, kb, question.Question)
+	if err != nil {
+		u.logger.Warn("retrieve documents of sandbox question failed", log.String("kb_id", kb.ID), log.Error(err))
+		return &domain.SandboxEvalResult{
+			Question:  question.Question,
+			NodeIDs:   []string{},
+			NodeNames: []string{},
+			Reason:    "retrieval failed",
+		}
+	}
+	gate := kb.AnswerSettings.ConfidenceGate
+	confidence := gate.Evaluate(question.Question, rankedNodes)
+	gated := gate.Enabled && !confidence.Confident
+	result := question.Evaluate(confidence, gated, rankedNodes, topK)
+	if gated {
+		return result
+	}
+	answer, err := u.llmUsecase.GenerateRetrievedAnswer(ctx, kb, model, systemPrompt, question.Question, rankedNodes)
+	if err != nil {
+		u.logger.Warn("answer sandbox question failed", log.String("kb_id", kb.ID), log.Error(err))
+		result.Fail("answer failed")
+		return result
+	}
+	documents := domain.FormatNodeChunks(rankedNodes, kb.AccessSettings.BaseURL)
+	judgement, err := u.llmUsecase.JudgeAnswer(ctx, model, question.Question, question.ExpectedAnswer, documents, answer)
+	if err != nil {
+		u.logger.Warn("judge answer of sandbox question failed", log.String("kb_id", kb.ID), log.Error(err))
+		result.Answer = answer
+		result.Fail("judge answer failed")
+		return result
+	}
+	result.Judge(answer, judgement)
+	return result
+}
+
+func (u *SandboxUsecase) DeleteSandbox(ctx context.Context, token *domain.APIToken, req *domain.SandboxKBReq) error {
+	if _, err := u.getSandbox(ctx, token, req.KBID); err != nil {
+		return err
+	}
+	if err := u.kbUsecase.DeleteKnowledgeBase(ctx, req.KBID); err != nil {
+		return err
+	}
+	u.logger.Info("sandbox kb deleted", log.String("kb_id", req.KBID), log.String("token_id", token.ID))
+	return nil
+}
+
+// DeleteTokenSandboxes tear down all sandboxes of the token, when the token is deleted
+func (u *SandboxUsecase) DeleteTokenSandboxes(ctx context.Context, tokenID string) error {
+	kbs, err := u.kbRepo.GetSandboxKnowledgeBaseList(ctx, tokenID)
+	if err != nil {
+		return err
+	}
+	for _, kb := range kbs {
+		if err := u.kbUsecase.DeleteKnowledgeBase(ctx, kb.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteExpiredSandboxes tear down sandboxes the pipelines left behind, return how many were deleted
+func (u *SandboxUsecase) DeleteExpiredSandboxes(ctx context.Context) (int, error) {
+	kbIDs, err := u.kbRepo.GetExpiredSandboxKnowledgeBaseIDs(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	deleted := 0
+	var errs []error
+	for _, kbID := range kbIDs {
+		if err := u.kbUsecase.DeleteKnowledgeBase(ctx, kbID); err != nil {
+			u.logger.Error("delete expired sandbox kb failed", log.String("kb_id", kbID), log.Error(err))
+			errs = append(errs, err)
+			continue
+		}
+		deleted++
+	}
+	return deleted, errors.Join(errs...)
+}
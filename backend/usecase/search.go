@@ -0,0 +1,274 @@
+package usecase
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/repo/pg"
+)
+
+type SearchUsecase struct {
+	nodeRepo *pg.NodeRepository
+	kbRepo   *pg.KnowledgeBaseRepository
+	appRepo  *pg.AppRepository
+	statRepo *pg.StatRepository
+	logger   *log.Logger
+
+	nodeACLUsecase *NodeACLUsecase
+
+	// completion candidates of each kb, searchCompletionCandidates
+	completionCache sync.Map
+}
+
+type searchCompletionCandidates struct {
+	candidates []*domain.SearchCompletion
+	expiresAt  time.Time
+}
+
+func NewSearchUsecase(nodeRepo *pg.NodeRepository, kbRepo *pg.KnowledgeBaseRepository, appRepo *pg.AppRepository, statRepo *pg.StatRepository, nodeACLUsecase *NodeACLUsecase, logger *log.Logger) *SearchUsecase {
+	return &SearchUsecase{
+		nodeRepo:       nodeRepo,
+		kbRepo:         kbRepo,
+		appRepo:        appRepo,
+		statRepo:       statRepo,
+		nodeACLUsecase: nodeACLUsecase,
+		logger:         logger.WithModule("usecase.search"),
+	}
+}
+
+// SearchNodes keyword search of published documents visible to the viewer, results link to the web app of kb
+func (u *SearchUsecase) SearchNodes(ctx context.Context, req *domain.NodeSearchReq) ([]*domain.NodeSearchResult, error) {
+	kb, err := u.kbRepo.GetKnowledgeBaseByID(ctx, req.KBID)
+	if err != nil {
+		return nil, err
+	}
+	aclFilter, err := u.nodeACLUsecase.ViewerFilter(ctx, req.KBID, req.Viewer)
+	if err != nil {
+		return nil, err
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = domain.DefaultNodeSearchLimit
+	}
+	limit = min(limit, domain.MaxNodeSearchLimit)
+	nodes, err := u.nodeRepo.SearchNodeReleases(ctx, req.KBID, req.Query, limit)
+	if err != nil {
+		return nil, err
+	}
+	visible := make([]*domain.NodeSearchResult, 0, len(nodes))
+	for _, node := range nodes {
+		if !aclFilter.Allows(node.ID) {
+			continue
+		}
+		node.URL = node.GetURL(kb.AccessSettings.BaseURL)
+		visible = append(visible, node)
+	}
+	return visible, nil
+}
+
+// FullTextSearchNodes full-text search of published documents ranked by relevance with highlighted snippets,
+// independent of the vector search used by chat
+func (u *SearchUsecase) FullTextSearchNodes(ctx context.Context, req *domain.FullTextSearchReq) (*domain.PaginatedResult[[]*domain.FullTextSearchResult], error) {
+	kb, err := u.kbRepo.GetKnowledgeBaseByID(ctx, req.KBID)
+	if err != nil {
+		return nil, err
+	}
+	aclFilter, err := u.nodeACLUsecase.ViewerFilter(ctx, req.KBID, req.Viewer)
+	if err != nil {
+		return nil, err
+	}
+	terms := domain.SearchTerms(req.Query)
+	results, total, err := u.nodeRepo.FullTextSearchNodeReleases(ctx, req, terms)
+	if err != nil {
+		return nil, err
+	}
+	// hidden documents are dropped from the page, so pages of viewers with hidden matches may come up short
+	visible := make([]*domain.FullTextSearchResult, 0, len(results))
+	for _, result := range results {
+		if !aclFilter.Allows(result.ID) {
+			total--
+			continue
+		}
+		result.URL = result.GetURL(kb.AccessSettings.BaseURL)
+		result.Highlight(terms)
+		visible = append(visible, result)
+	}
+	return domain.NewPaginatedResult(visible, total), nil
+}
+
+// GetSearchCompletions completions of a partial query from document titles and popular questions, tolerating typos
+func (u *SearchUsecase) GetSearchCompletions(ctx context.Context, req *domain.SearchCompletionReq) ([]*domain.SearchCompletion, error) {
+	candidates, err := u.getCompletionCandidates(ctx, req.KBID)
+	if err != nil {
+		return nil, err
+	}
+	// candidates are cached for every viewer, titles of hidden documents are dropped per request
+	aclFilter, err := u.nodeACLUsecase.ViewerFilter(ctx, req.KBID, req.Viewer)
+	if err != nil {
+		return nil, err
+	}
+	if aclFilter != nil {
+		visible := make([]*domain.SearchCompletion, 0, len(candidates))
+		for _, candidate := range candidates {
+			if candidate.Type == domain.SearchCompletionTypeNode && !aclFilter.Allows(candidate.NodeID) {
+				continue
+			}
+			visible = append(visible, candidate)
+		}
+		candidates = visible
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = domain.DefaultSearchCompletionLimit
+	}
+	return domain.RankSearchCompletions(req.Query, candidates, limit), nil
+}
+
+// getCompletionCandidates titles of published documents and popular questions of kb, cached as completions are requested per keystroke
+func (u *SearchUsecase) getCompletionCandidates(ctx context.Context, kbID string) ([]*domain.SearchCompletion, error) {
+	if cached, ok := u.completionCache.Load(kbID); ok {
+		if c := cached.(*searchCompletionCandidates); time.Now().Before(c.expiresAt) {
+			return c.candidates, nil
+		}
+	}
+	kb, err := u.kbRepo.GetKnowledgeBaseByID(ctx, kbID)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := u.nodeRepo.GetNodeReleaseListByKBID(ctx, kbID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	queries, err := u.statRepo.GetPopularQueries(ctx, kbID, time.Now().Add(-domain.PopularQueryWindow),
+		domain.PopularQueryMinCount, domain.PopularQueryMaxLen, domain.PopularQueryLimit)
+	if err != nil {
+		return nil, err
+	}
+	candidates := make([]*domain.SearchCompletion, 0, len(nodes)+len(queries))
+	for _, node := range nodes {
+		if node.Type != domain.NodeTypeDocument {
+			continue
+		}
+		candidates = append(candidates, &domain.SearchCompletion{
+			Text:   node.Name,
+			Type:   domain.SearchCompletionTypeNode,
+			NodeID: node.ID,
+			URL:    fmt.Sprintf("%s/node/%s", kb.AccessSettings.BaseURL, node.ID),
+		})
+	}
+	for _, query := range queries {
+		candidates = append(candidates, &domain.SearchCompletion{
+			Text:  query.Text,
+			Type:  domain.SearchCompletionTypeQuery,
+			Count: query.Count,
+		})
+	}
+	u.completionCache.Store(kbID, &searchCompletionCandidates{
+		candidates: candidates,
+		expiresAt:  time.Now().Add(domain.SearchCompletionCacheTTL),
+	})
+	return candidates, nil
+}
+
+// GetSearchSuggestions search results in opensearch suggestions format: [query, [names], [summaries], [urls]]
+func (u *SearchUsecase) GetSearchSuggestions(ctx context.Context, req *domain.NodeSearchReq) ([]any, error) {
+	nodes, err := u.SearchNodes(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(nodes))
+	summaries := make([]string, 0, len(nodes))
+	urls := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		names = append(names, node.Name)
+		summaries = append(summaries, node.Summary)
+		urls = append(urls, node.URL)
+	}
+	return []any{req.Query, names, summaries, urls}, nil
+}
+
+type openSearchURL struct {
+	Type     string `xml:"type,attr"`
+	Method   string `xml:"method,attr,omitempty"`
+	Rel      string `xml:"rel,attr,omitempty"`
+	Template string `xml:"template,attr"`
+}
+
+type openSearchImage struct {
+	Width  int    `xml:"width,attr"`
+	Height int    `xml:"height,attr"`
+	Type   string `xml:"type,attr,omitempty"`
+	URL    string `xml:",chardata"`
+}
+
+type openSearchDescription struct {
+	XMLName       xml.Name         `xml:"OpenSearchDescription"`
+	XMLNS         string           `xml:"xmlns,attr"`
+	MozXMLNS      string           `xml:"xmlns:moz,attr"`
+	ShortName     string           `xml:"ShortName"`
+	Description   string           `xml:"Description"`
+	InputEncoding string           `xml:"InputEncoding"`
+	Image         *openSearchImage `xml:"Image,omitempty"`
+	URLs          []openSearchURL  `xml:"Url"`
+	SearchForm    string           `xml:"moz:searchForm"`
+}
+
+// GetOpenSearchDescription opensearch description document of kb, so browsers can add the wiki as a search engine.
+// urls are built from the base url of kb, or its host and port if not set
+func (u *SearchUsecase) GetOpenSearchDescription(ctx context.Context, kbID string) (string, error) {
+	kb, err := u.kbRepo.GetKnowledgeBaseByID(ctx, kbID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get knowledge base: %w", err)
+	}
+	app, err := u.appRepo.GetOrCreateApplByKBIDAndType(ctx, kbID, domain.AppTypeWeb)
+	if err != nil {
+		return "", fmt.Errorf("failed to get web app: %w", err)
+	}
+	// the host header is set by the client, links are built from the settings of the kb only
+	baseURL := kb.AccessSettings.SiteURL()
+	if baseURL == "" {
+		return "", domain.NewError(domain.ErrCodeNotFound, "base url of the knowledge base is not set")
+	}
+	name := app.Settings.Title
+	if name == "" {
+		name = kb.Name
+	}
+	shortName := []rune(name)
+	if len(shortName) > domain.OpenSearchShortNameMaxLen {
+		shortName = shortName[:domain.OpenSearchShortNameMaxLen]
+	}
+	description := app.Settings.Desc
+	if description == "" {
+		description = fmt.Sprintf("搜索 %s", name)
+	}
+	doc := openSearchDescription{
+		XMLNS:         "http://a9.com/-/spec/opensearch/1.1/",
+		MozXMLNS:      "http://www.mozilla.org/2006/browser/search/",
+		ShortName:     string(shortName),
+		Description:   description,
+		InputEncoding: "UTF-8",
+		URLs: []openSearchURL{
+			{Type: "text/html", Method: "get", Template: baseURL + "/chat?q={searchTerms}"},
+			{Type: "application/x-suggestions+json", Method: "get", Rel: "suggestions", Template: baseURL + "/client/v1/search/suggest?q={searchTerms}"},
+			{Type: "application/opensearchdescription+xml", Rel: "self", Template: baseURL + "/opensearch.xml"},
+		},
+		SearchForm: baseURL + "/chat",
+	}
+	if icon, err := url.Parse(app.Settings.Icon); err == nil && icon.IsAbs() {
+		doc.Image = &openSearchImage{Width: 16, Height: 16, URL: app.Settings.Icon}
+	}
+	body, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return xml.Header + string(body), nil
+}
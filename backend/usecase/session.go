@@ -0,0 +1,144 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/repo/cache"
+	"github.com/chaitin/panda-wiki/repo/pg"
+)
+
+type SessionUsecase struct {
+	repo            *pg.SessionRepository
+	cache           *cache.SessionRepo
+	kbMemberUsecase *KBMemberUsecase
+	logger          *log.Logger
+}
+
+func NewSessionUsecase(repo *pg.SessionRepository, cache *cache.SessionRepo, kbMemberUsecase *KBMemberUsecase, logger *log.Logger) *SessionUsecase {
+	return &SessionUsecase{
+		repo:            repo,
+		cache:           cache,
+		kbMemberUsecase: kbMemberUsecase,
+		logger:          logger.WithModule("usecase.session"),
+	}
+}
+
+// CreateSession session of the user logging in with the client
+func (u *SessionUsecase) CreateSession(ctx context.Context, userID string, client *domain.SessionClient) (*domain.UserSession, error) {
+	session := domain.NewUserSession(uuid.New().String(), userID, client, time.Now())
+	if err := u.repo.CreateSession(ctx, session); err != nil {
+		return nil, err
+	}
+	if err := u.cache.Set(ctx, session.ID, true, session.ExpiresAt); err != nil {
+		u.logger.Warn("cache session failed", log.String("session_id", session.ID), log.Error(err))
+	}
+	return session, nil
+}
+
+// CheckSession ErrSessionRevoked unless the session is active, the state is cached until the session expires
+func (u *SessionUsecase) CheckSession(ctx context.Context, id string) error {
+	active, found, err := u.cache.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		session, err := u.repo.GetSession(ctx, id)
+		switch {
+		case errors.Is(err, domain.ErrSessionNotFound):
+			active = false
+		case err != nil:
+			return err
+		default:
+			active = session.RevokedAt == nil && session.ExpiresAt.After(time.Now())
+			if err := u.cache.Set(ctx, id, active, session.ExpiresAt); err != nil {
+				u.logger.Warn("cache session failed", log.String("session_id", id), log.Error(err))
+			}
+		}
+	}
+	if !active {
+		return domain.ErrSessionRevoked
+	}
+	u.repo.Touch(id)
+	return nil
+}
+
+// GetSessions active sessions, admins get those of every user or of the user of the request, members their own
+func (u *SessionUsecase) GetSessions(ctx context.Context, req *domain.SessionListReq, operatorID, currentID string) ([]*domain.SessionListItem, error) {
+	admin, err := u.kbMemberUsecase.IsAdmin(ctx, operatorID)
+	if err != nil {
+		return nil, err
+	}
+	userID := req.UserID
+	if !admin {
+		userID = operatorID
+	}
+	sessions, err := u.repo.GetActiveSessions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, session := range sessions {
+		session.Current = session.ID == currentID
+	}
+	return sessions, nil
+}
+
+// RevokeSession log out the session, members may only revoke their own sessions
+func (u *SessionUsecase) RevokeSession(ctx context.Context, req *domain.RevokeSessionReq, operatorID string) error {
+	session, err := u.repo.GetSession(ctx, req.ID)
+	if err != nil {
+		return err
+	}
+	if err := u.checkOwner(ctx, session.UserID, operatorID); err != nil {
+		return err
+	}
+	return u.revoke(ctx, session.UserID, session.ID, operatorID)
+}
+
+// RevokeUserSessions log out every session of the user, members may only revoke their own sessions
+func (u *SessionUsecase) RevokeUserSessions(ctx context.Context, req *domain.RevokeUserSessionsReq, operatorID string) error {
+	if err := u.checkOwner(ctx, req.UserID, operatorID); err != nil {
+		return err
+	}
+	return u.revoke(ctx, req.UserID, "", operatorID)
+}
+
+// RevokeAllSessions log out every session of the user after its password was reset or it was deleted
+func (u *SessionUsecase) RevokeAllSessions(ctx context.Context, userID string) error {
+	return u.revoke(ctx, userID, "", "")
+}
+
+func (u *SessionUsecase) checkOwner(ctx context.Context, userID, operatorID string) error {
+	if userID == operatorID {
+		return nil
+	}
+	admin, err := u.kbMemberUsecase.IsAdmin(ctx, operatorID)
+	if err != nil {
+		return err
+	}
+	if !admin {
+		return domain.ErrPermissionDenied
+	}
+	return nil
+}
+
+func (u *SessionUsecase) revoke(ctx context.Context, userID, id, operatorID string) error {
+	sessions, err := u.repo.RevokeSessions(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	for _, session := range sessions {
+		// an active state left in the cache would keep the session working until it expires
+		if err := u.cache.Set(ctx, session.ID, false, session.ExpiresAt); err != nil {
+			return err
+		}
+	}
+	u.logger.Info("sessions revoked", log.String("user_id", userID), log.String("session_id", id),
+		log.Int("count", len(sessions)), log.String("operator_id", operatorID))
+	return nil
+}
@@ -12,13 +12,14 @@ import (
 )
 
 type SitemapUsecase struct {
-	nodeUsecase *pg.NodeRepository
-	appUsecase  *pg.KnowledgeBaseRepository
-	logger      *log.Logger
+	nodeUsecase    *pg.NodeRepository
+	appUsecase     *pg.KnowledgeBaseRepository
+	nodeACLUsecase *NodeACLUsecase
+	logger         *log.Logger
 }
 
-func NewSitemapUsecase(nodeUsecase *pg.NodeRepository, appUsecase *pg.KnowledgeBaseRepository, logger *log.Logger) *SitemapUsecase {
-	return &SitemapUsecase{nodeUsecase: nodeUsecase, appUsecase: appUsecase, logger: logger.WithModule("usecase.sitemap")}
+func NewSitemapUsecase(nodeUsecase *pg.NodeRepository, appUsecase *pg.KnowledgeBaseRepository, nodeACLUsecase *NodeACLUsecase, logger *log.Logger) *SitemapUsecase {
+	return &SitemapUsecase{nodeUsecase: nodeUsecase, appUsecase: appUsecase, nodeACLUsecase: nodeACLUsecase, logger: logger.WithModule("usecase.sitemap")}
 }
 
 func (u *SitemapUsecase) GetSitemap(ctx context.Context, kbID string) (string, error) {
@@ -32,6 +33,12 @@ func (u *SitemapUsecase) GetSitemap(ctx context.Context, kbID string) (string, e
 		return "", fmt.Errorf("failed to get knowledge base: %w", err)
 	}
 
+	// crawlers are anonymous, documents with acls are left out
+	aclFilter, err := u.nodeACLUsecase.ViewerFilter(ctx, kbID, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to get node acls: %w", err)
+	}
+
 	sb := strings.Builder{}
 	sb.WriteString(`<?xml version="1.0" encoding="UTF-8"?>`)
 	sb.WriteString(`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`)
@@ -41,7 +48,7 @@ func (u *SitemapUsecase) GetSitemap(ctx context.Context, kbID string) (string, e
 
 	// add nodes
 	for _, node := range nodes {
-		if node.Type == domain.NodeTypeDocument {
+		if node.Type == domain.NodeTypeDocument && aclFilter.Allows(node.ID) {
 			sb.WriteString(fmt.Sprintf(`<url><loc>%s</loc><lastmod>%s</lastmod></url>`, node.GetURL(kb.AccessSettings.BaseURL), node.UpdatedAt.Format(time.DateOnly)))
 		}
 	}
@@ -3,6 +3,9 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"sort"
+	"sync"
+	"time"
 
 	"github.com/samber/lo"
 
@@ -10,6 +13,7 @@ import (
 	"github.com/chaitin/panda-wiki/log"
 	"github.com/chaitin/panda-wiki/repo/cache"
 	"github.com/chaitin/panda-wiki/repo/ipdb"
+	"github.com/chaitin/panda-wiki/repo/mq"
 	"github.com/chaitin/panda-wiki/repo/pg"
 )
 
@@ -21,9 +25,21 @@ type StatUseCase struct {
 	ipRepo           *ipdb.IPAddressRepo
 	logger           *log.Logger
 	geoCacheRepo     *cache.GeoRepo
+	visitorRepo      *cache.VisitorRepo
+	statEventRepo    *mq.StatEventRepository
+	kbUsecase        *KnowledgeBaseUsecase
+	botDetector      *BotDetector
+
+	// id of the web app of each kb, cachedWebAppID, every page view is counted to it
+	webAppIDs sync.Map
+}
+
+type cachedWebAppID struct {
+	id        string
+	expiresAt time.Time
 }
 
-func NewStatUseCase(repo *pg.StatRepository, nodeRepo *pg.NodeRepository, conversationRepo *pg.ConversationRepository, appRepo *pg.AppRepository, ipRepo *ipdb.IPAddressRepo, geoCacheRepo *cache.GeoRepo, logger *log.Logger) *StatUseCase {
+func NewStatUseCase(repo *pg.StatRepository, nodeRepo *pg.NodeRepository, conversationRepo *pg.ConversationRepository, appRepo *pg.AppRepository, ipRepo *ipdb.IPAddressRepo, geoCacheRepo *cache.GeoRepo, visitorRepo *cache.VisitorRepo, statEventRepo *mq.StatEventRepository, kbUsecase *KnowledgeBaseUsecase, botDetector *BotDetector, logger *log.Logger) *StatUseCase {
 	return &StatUseCase{
 		repo:             repo,
 		nodeRepo:         nodeRepo,
@@ -31,14 +47,24 @@ func NewStatUseCase(repo *pg.StatRepository, nodeRepo *pg.NodeRepository, conver
 		appRepo:          appRepo,
 		ipRepo:           ipRepo,
 		geoCacheRepo:     geoCacheRepo,
+		visitorRepo:      visitorRepo,
+		statEventRepo:    statEventRepo,
+		kbUsecase:        kbUsecase,
+		botDetector:      botDetector,
 		logger:           logger.WithModule("usecase.stats"),
 	}
 }
 
 func (u *StatUseCase) RecordPage(ctx context.Context, stat *domain.StatPage) error {
+	stat.IsBot = u.botDetector.IsBot(ctx, "page", stat.IP, stat.UA, domain.BotPageViewLimitPerMinute)
 	if err := u.repo.CreateStatPage(ctx, stat); err != nil {
 		return err
 	}
+	u.publishStatEvent(ctx, domain.StatEventTypePage, stat.KBID, stat)
+	// aggregated stats can't be filtered later, leave bot traffic out of them
+	if stat.IsBot && u.kbUsecase.IsBotTrafficFiltered(ctx, stat.KBID) {
+		return nil
+	}
 	remoteIP := stat.IP
 	ipAddress, err := u.ipRepo.GetIPAddress(ctx, remoteIP)
 	if err != nil {
@@ -48,10 +74,185 @@ func (u *StatUseCase) RecordPage(ctx context.Context, stat *domain.StatPage) err
 		if err := u.geoCacheRepo.SetGeo(ctx, stat.KBID, location); err != nil {
 			u.logger.Warn("set geo cache failed", log.Error(err), log.Int64("stat_id", stat.ID), log.String("ip", remoteIP))
 		}
+		// page visits are counted to the web app
+		if appID, err := u.webAppID(ctx, stat.KBID); err != nil {
+			u.logger.Warn("get web app failed", log.Error(err), log.String("kb_id", stat.KBID))
+		} else if err := u.repo.IncrGeoStat(ctx, stat.KBID, appID, ipAddress); err != nil {
+			u.logger.Warn("incr geo stat failed", log.Error(err), log.Int64("stat_id", stat.ID), log.String("ip", remoteIP))
+		}
+	}
+	if stat.Scene == domain.StatPageSceneNodeDetail && stat.NodeID != "" {
+		u.recordNodeView(ctx, stat)
+	}
+	if err := u.RecordFunnelEvent(ctx, stat.KBID, stat.SessionID, domain.FunnelStepVisit); err != nil {
+		u.logger.Warn("record funnel visit failed", log.Error(err), log.Int64("stat_id", stat.ID))
+	}
+	return nil
+}
+
+// webAppID id of the web app of the kb, created if the kb has none, cached for WebAppIDCacheTTL
+func (u *StatUseCase) webAppID(ctx context.Context, kbID string) (string, error) {
+	if cached, ok := u.webAppIDs.Load(kbID); ok {
+		if c := cached.(*cachedWebAppID); time.Now().Before(c.expiresAt) {
+			return c.id, nil
+		}
+	}
+	app, err := u.appRepo.GetOrCreateApplByKBIDAndType(ctx, kbID, domain.AppTypeWeb)
+	if err != nil {
+		return "", err
+	}
+	if app.ID == "" {
+		return "", fmt.Errorf("web app of kb %s not found", kbID)
+	}
+	u.webAppIDs.Store(kbID, &cachedWebAppID{
+		id:        app.ID,
+		expiresAt: time.Now().Add(domain.WebAppIDCacheTTL),
+	})
+	return app.ID, nil
+}
+
+// RecordFunnelEvent record funnel step of session, events without session are ignored
+func (u *StatUseCase) RecordFunnelEvent(ctx context.Context, kbID, sessionID string, step domain.FunnelStep) error {
+	if sessionID == "" {
+		return nil
+	}
+	event := &domain.StatFunnelEvent{
+		KBID:      kbID,
+		SessionID: sessionID,
+		Step:      step,
+		CreatedAt: time.Now(),
+	}
+	if err := u.repo.CreateFunnelEvent(ctx, event); err != nil {
+		return err
+	}
+	u.publishStatEvent(ctx, domain.StatEventTypeFunnel, kbID, event)
+	return nil
+}
+
+// publishStatEvent ship stat event to external analytics, failures are only logged
+func (u *StatUseCase) publishStatEvent(ctx context.Context, eventType domain.StatEventType, kbID string, data any) {
+	if err := u.statEventRepo.AsyncPublishStatEvent(ctx, eventType, kbID, data); err != nil {
+		u.logger.Warn("publish stat event failed", log.Error(err), log.String("type", string(eventType)), log.String("kb_id", kbID))
+	}
+}
+
+func (u *StatUseCase) GetFunnelStat(ctx context.Context, req *domain.GetFunnelStatReq) ([]*domain.FunnelStepStat, error) {
+	end := time.Now()
+	if req.EndTime > 0 {
+		end = time.Unix(req.EndTime, 0)
+	}
+	start := end.Add(-7 * 24 * time.Hour)
+	if req.StartTime > 0 {
+		start = time.Unix(req.StartTime, 0)
+	}
+	reached, converted, err := u.repo.GetFunnelCounts(ctx, req.KBID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	stats := make([]*domain.FunnelStepStat, 0, len(domain.FunnelSteps))
+	for i, step := range domain.FunnelSteps {
+		stat := &domain.FunnelStepStat{
+			Step:      step,
+			Sessions:  reached[i],
+			Converted: converted[i],
+		}
+		if i == 0 {
+			stat.ConversionRate = 1
+		} else if converted[i-1] > 0 {
+			stat.ConversionRate = float64(converted[i]) / float64(converted[i-1])
+		}
+		if reached[0] > 0 {
+			stat.OverallRate = float64(converted[i]) / float64(reached[0])
+		}
+		stats = append(stats, stat)
+	}
+	return stats, nil
+}
+
+// GetAnswerConfidenceStat count low confidence answers which are replied with reference links only
+func (u *StatUseCase) GetAnswerConfidenceStat(ctx context.Context, req *domain.GetAnswerConfidenceStatReq) (*domain.AnswerConfidenceStatResp, error) {
+	end := time.Now()
+	if req.EndTime > 0 {
+		end = time.Unix(req.EndTime, 0)
+	}
+	start := end.Add(-7 * 24 * time.Hour)
+	if req.StartTime > 0 {
+		start = time.Unix(req.StartTime, 0)
+	}
+	daily, err := u.repo.GetAnswerConfidenceCounts(ctx, req.KBID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	resp := &domain.AnswerConfidenceStatResp{Daily: daily}
+	for _, count := range daily {
+		resp.Answered += count.Answered
+		resp.LowConfidence += count.LowConfidence
+	}
+	if resp.Answered > 0 {
+		resp.LowRate = float64(resp.LowConfidence) / float64(resp.Answered)
+	}
+	return resp, nil
+}
+
+func (u *StatUseCase) recordNodeView(ctx context.Context, stat *domain.StatPage) {
+	nodeStat := &domain.StatNodeDaily{KBID: stat.KBID, NodeID: stat.NodeID, Views: 1}
+	isNew, err := u.visitorRepo.AddNodeVisitor(ctx, stat.NodeID, stat.SessionID)
+	if err != nil {
+		u.logger.Warn("add node visitor failed", log.Error(err), log.String("node_id", stat.NodeID))
+	}
+	if isNew {
+		nodeStat.Visitors = 1
+	}
+	if err := u.repo.IncrNodeStat(ctx, nodeStat); err != nil {
+		u.logger.Warn("incr node stat failed", log.Error(err), log.String("node_id", stat.NodeID))
+	}
+}
+
+func (u *StatUseCase) RecordNodeDwell(ctx context.Context, kbID string, req *domain.StatNodeDwellReq) error {
+	// only published nodes of the kb are read on the share pages
+	exists, err := u.nodeRepo.NodeReleaseExists(ctx, kbID, req.NodeID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return domain.ErrNodeNotFound
+	}
+	duration := min(req.Duration, domain.StatNodeMaxDwell.Milliseconds())
+	if err := u.repo.IncrNodeStat(ctx, &domain.StatNodeDaily{
+		KBID:       kbID,
+		NodeID:     req.NodeID,
+		DwellTotal: duration,
+		DwellCount: 1,
+	}); err != nil {
+		return err
 	}
+	u.publishStatEvent(ctx, domain.StatEventTypeDwell, kbID, &domain.StatNodeDwellReq{NodeID: req.NodeID, Duration: duration})
 	return nil
 }
 
+func (u *StatUseCase) GetNodeStats(ctx context.Context, req *domain.GetNodeStatsReq) ([]*domain.NodeStatResp, error) {
+	days := req.Days
+	if days <= 0 {
+		days = 7
+	}
+	now := time.Now()
+	since := time.Date(now.Year(), now.Month(), now.Day()-days+1, 0, 0, 0, 0, now.Location())
+	stats, err := u.repo.GetNodeStats(ctx, req.KBID, req.NodeID, since)
+	if err != nil {
+		return nil, err
+	}
+	nodeNames, err := u.nodeRepo.GetNodeNameByNodeIDs(ctx, lo.Map(stats, func(stat *domain.NodeStatResp, _ int) string {
+		return stat.NodeID
+	}))
+	if err != nil {
+		return nil, err
+	}
+	for _, stat := range stats {
+		stat.NodeName = nodeNames[stat.NodeID]
+	}
+	return stats, nil
+}
+
 func (u *StatUseCase) GetHotPages(ctx context.Context, kbID string) ([]*domain.HotPageResp, error) {
 	hotPages, err := u.repo.GetHotPages(ctx, kbID)
 	if err != nil {
@@ -161,6 +362,55 @@ func (u *StatUseCase) GetGeoCount(ctx context.Context, kbID string) (map[string]
 	return geoCount, nil
 }
 
+// GetGeoStat get visitor locations in time range as country > province > city tree
+func (u *StatUseCase) GetGeoStat(ctx context.Context, req *domain.GetGeoStatReq) (*domain.GeoStatResp, error) {
+	end := time.Now()
+	if req.EndTime > 0 {
+		end = time.Unix(req.EndTime, 0)
+	}
+	start := end.Add(-24 * time.Hour)
+	if req.StartTime > 0 {
+		start = time.Unix(req.StartTime, 0)
+	}
+	counts, err := u.repo.GetGeoCounts(ctx, req.KBID, req.AppID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	resp := &domain.GeoStatResp{Countries: make([]*domain.GeoStatNode, 0)}
+	countries := make(map[string]*domain.GeoStatNode)
+	provinces := make(map[string]*domain.GeoStatNode)
+	for _, count := range counts {
+		resp.Total += count.Count
+		country, ok := countries[count.Country]
+		if !ok {
+			country = &domain.GeoStatNode{Name: count.Country}
+			countries[count.Country] = country
+			resp.Countries = append(resp.Countries, country)
+		}
+		country.Value += count.Count
+		provinceKey := count.Country + "|" + count.Province
+		province, ok := provinces[provinceKey]
+		if !ok {
+			province = &domain.GeoStatNode{Name: count.Province}
+			provinces[provinceKey] = province
+			country.Children = append(country.Children, province)
+		}
+		province.Value += count.Count
+		province.Children = append(province.Children, &domain.GeoStatNode{Name: count.City, Value: count.Count})
+	}
+	sortGeoStatNodes(resp.Countries)
+	return resp, nil
+}
+
+func sortGeoStatNodes(nodes []*domain.GeoStatNode) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i].Value > nodes[j].Value
+	})
+	for _, node := range nodes {
+		sortGeoStatNodes(node.Children)
+	}
+}
+
 func (u *StatUseCase) GetConversationDistribution(ctx context.Context, kbID string) ([]*domain.ConversationDistributionResp, error) {
 	distribution, err := u.conversationRepo.GetConversationDistribution(ctx, kbID)
 	if err != nil {
@@ -179,3 +429,82 @@ func (u *StatUseCase) GetConversationDistribution(ctx context.Context, kbID stri
 	})
 	return distribution, nil
 }
+
+func (u *StatUseCase) GetRealtimeStat(ctx context.Context, kbID string) (*domain.StatRealtimeResp, error) {
+	now := time.Now()
+	activeCount, err := u.conversationRepo.GetActiveConversationCount(ctx, kbID, now.Add(-5*time.Minute))
+	if err != nil {
+		return nil, err
+	}
+	requestCount, err := u.repo.GetRequestCountSince(ctx, kbID, now.Add(-1*time.Minute))
+	if err != nil {
+		return nil, err
+	}
+	geoCount, err := u.geoCacheRepo.GetLast24HourGeo(ctx, kbID)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.StatRealtimeResp{
+		ActiveConversationCount: activeCount,
+		RequestsPerMinute:       requestCount,
+		Geo:                     geoCount,
+		Time:                    now,
+	}, nil
+}
+
+// SubscribeRealtimeStat push realtime stat every interval until ctx is done
+func (u *StatUseCase) SubscribeRealtimeStat(ctx context.Context, kbID string, interval time.Duration) <-chan *domain.StatRealtimeResp {
+	statCh := make(chan *domain.StatRealtimeResp, 1)
+	go func() {
+		defer close(statCh)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			stat, err := u.GetRealtimeStat(ctx, kbID)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				u.logger.Error("get realtime stat failed", log.Error(err), log.String("kb_id", kbID))
+			} else {
+				select {
+				case statCh <- stat:
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return statCh
+}
+
+func (u *StatUseCase) GetTrafficSources(ctx context.Context, req *domain.GetTrafficSourcesReq) ([]*domain.TrafficSourceCount, error) {
+	switch req.Dimension {
+	case domain.TrafficSourceDimensionRefererHost, domain.TrafficSourceDimensionUTMSource, domain.TrafficSourceDimensionUTMMedium,
+		domain.TrafficSourceDimensionUTMCampaign, domain.TrafficSourceDimensionUTMTerm, domain.TrafficSourceDimensionUTMContent:
+	default:
+		return nil, fmt.Errorf("invalid traffic source dimension: %s", req.Dimension)
+	}
+	if req.Type == domain.TrafficSourceTypeConversation {
+		return u.repo.GetConversationTrafficSources(ctx, req.KBID, req.Dimension)
+	}
+	return u.repo.GetPageTrafficSources(ctx, req.KBID, req.Dimension)
+}
+
+// GetPlatformStat device, browser and os breakdown of conversations, widget conversations of last 7 days by default
+func (u *StatUseCase) GetPlatformStat(ctx context.Context, req *domain.GetPlatformStatReq) (*domain.PlatformStatResp, error) {
+	appType := req.AppType
+	if appType == 0 {
+		appType = domain.AppTypeWidget
+	}
+	days := req.Days
+	if days == 0 {
+		days = 7
+	}
+	return u.repo.GetConversationPlatforms(ctx, req.KBID, appType, time.Now().AddDate(0, 0, -days))
+}
@@ -0,0 +1,181 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/chaitin/panda-wiki/config"
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/repo/pg"
+)
+
+var ErrTelemetryReportRequiresEnabled = errors.New("telemetry must be enabled to report upstream")
+
+type TelemetryUsecase struct {
+	settingRepo *pg.SettingRepository
+	repo        *pg.TelemetryRepository
+	config      *config.Config
+	httpClient  *http.Client
+	logger      *log.Logger
+}
+
+func NewTelemetryUsecase(settingRepo *pg.SettingRepository, repo *pg.TelemetryRepository, config *config.Config, logger *log.Logger) *TelemetryUsecase {
+	return &TelemetryUsecase{
+		settingRepo: settingRepo,
+		repo:        repo,
+		config:      config,
+		httpClient:  &http.Client{Timeout: 10 * time.Second},
+		logger:      logger.WithModule("usecase.telemetry"),
+	}
+}
+
+// GetSettings telemetry settings, disabled if not configured
+func (u *TelemetryUsecase) GetSettings(ctx context.Context) (*domain.TelemetrySettingsResp, error) {
+	settings, err := u.getSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.TelemetrySettingsResp{
+		TelemetrySettings: *settings,
+		ReportURL:         u.config.Telemetry.ReportURL,
+	}, nil
+}
+
+func (u *TelemetryUsecase) getSettings(ctx context.Context) (*domain.TelemetrySettings, error) {
+	settings := &domain.TelemetrySettings{}
+	if err := u.settingRepo.GetSetting(ctx, domain.SettingKeyTelemetry, settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// UpdateSettings opt in or out of telemetry, usage aggregated so far is removed when opting out
+func (u *TelemetryUsecase) UpdateSettings(ctx context.Context, req *domain.UpdateTelemetrySettingsReq) error {
+	if req.ReportEnabled && !req.Enabled {
+		return ErrTelemetryReportRequiresEnabled
+	}
+	settings, err := u.getSettings(ctx)
+	if err != nil {
+		return err
+	}
+	settings.Enabled = req.Enabled
+	settings.ReportEnabled = req.ReportEnabled
+	if settings.Enabled && settings.InstanceID == "" {
+		settings.InstanceID = uuid.New().String()
+	}
+	if !settings.Enabled {
+		if err := u.repo.ClearUsage(ctx); err != nil {
+			return err
+		}
+	}
+	if err := u.settingRepo.UpdateSetting(ctx, domain.SettingKeyTelemetry, settings); err != nil {
+		return err
+	}
+	u.logger.Info("telemetry settings updated", log.Any("enabled", settings.Enabled), log.Any("report_enabled", settings.ReportEnabled))
+	return nil
+}
+
+// SaveUsage add usage counted since the last flush, discarded unless telemetry is enabled
+func (u *TelemetryUsecase) SaveUsage(ctx context.Context, counters map[string]domain.TelemetryCounter) error {
+	settings, err := u.getSettings(ctx)
+	if err != nil {
+		return err
+	}
+	if !settings.Enabled {
+		return nil
+	}
+	return u.repo.AddUsage(ctx, counters)
+}
+
+// GetReport anonymous metrics of the deployment over the last days, as they would be sent upstream
+func (u *TelemetryUsecase) GetReport(ctx context.Context) (*domain.TelemetryReport, error) {
+	settings, err := u.getSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	since := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, 1-domain.TelemetryReportDays)
+	features, err := u.repo.GetFeatureUsage(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	deployment, err := u.repo.GetDeployment(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report := &domain.TelemetryReport{
+		InstanceID:  settings.InstanceID,
+		GeneratedAt: now,
+		Since:       since.Format("2006-01-02"),
+		Deployment:  *deployment,
+		Features:    features,
+	}
+	for _, feature := range features {
+		feature.ErrorRate = domain.TelemetryErrorRate(feature.Requests, feature.Errors)
+		report.Requests += feature.Requests
+		report.Errors += feature.Errors
+	}
+	report.ErrorRate = domain.TelemetryErrorRate(report.Requests, report.Errors)
+	return report, nil
+}
+
+// SendReport remove usage older than it is kept, and send the report upstream if the operator enabled reporting
+func (u *TelemetryUsecase) SendReport(ctx context.Context) (domain.CronRunResult, error) {
+	now := time.Now()
+	removed, err := u.repo.RemoveUsage(ctx, now.AddDate(0, 0, -domain.TelemetryUsageKeepDays))
+	if err != nil {
+		return nil, fmt.Errorf("remove old usage failed: %w", err)
+	}
+	result := domain.CronRunResult{"removed": removed, "reported": false}
+	settings, err := u.getSettings(ctx)
+	if err != nil {
+		return result, err
+	}
+	url := u.config.Telemetry.ReportURL
+	if !settings.Enabled || !settings.ReportEnabled || url == "" {
+		return result, nil
+	}
+	report, err := u.GetReport(ctx)
+	if err != nil {
+		return result, err
+	}
+	if err := u.postReport(ctx, url, report); err != nil {
+		return result, fmt.Errorf("send telemetry report failed: %w", err)
+	}
+	settings.ReportedAt = &now
+	if err := u.settingRepo.UpdateSetting(ctx, domain.SettingKeyTelemetry, settings); err != nil {
+		return result, err
+	}
+	result["reported"] = true
+	result["requests"] = report.Requests
+	return result, nil
+}
+
+func (u *TelemetryUsecase) postReport(ctx context.Context, url string, report *domain.TelemetryReport) error {
+	body, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := u.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
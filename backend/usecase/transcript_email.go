@@ -0,0 +1,179 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/repo/cache"
+	"github.com/chaitin/panda-wiki/repo/pg"
+	"github.com/chaitin/panda-wiki/store/mail"
+)
+
+var thinkBlockRegex = regexp.MustCompile(`(?s)<think>.*?</think>`)
+
+var transcriptTemplate = template.Must(template.New("transcript").Parse(`<div style="font-family:sans-serif;max-width:720px;margin:0 auto;color:#21222d">
+<h2>{{.KBName}}</h2>
+<p style="color:#8f9399">{{.Subject}} · {{.CreatedAt}}</p>
+{{range .Messages}}<div style="margin:16px 0;padding:12px 16px;border-radius:8px;background:{{if .IsUser}}#f0f5ff{{else}}#f8f9fa{{end}}">
+<div style="font-weight:bold;margin-bottom:6px">{{if .IsUser}}我{{else}}AI 助手{{end}}</div>
+<div style="white-space:pre-wrap">{{.Content}}</div>
+</div>
+{{end}}{{if .References}}<h3>参考资料</h3>
+<ul>{{range .References}}<li><a href="{{.URL}}">{{.Name}}</a></li>{{end}}</ul>
+{{end}}</div>`))
+
+type transcriptMessage struct {
+	IsUser  bool
+	Content string
+}
+
+type transcriptData struct {
+	KBName     string
+	Subject    string
+	CreatedAt  string
+	Messages   []transcriptMessage
+	References []*domain.ConversationReference
+}
+
+type TranscriptEmailUsecase struct {
+	conversationRepo *pg.ConversationRepository
+	kbRepo           *pg.KnowledgeBaseRepository
+	rateLimitRepo    *cache.RateLimitRepo
+	mailer           *mail.Mailer
+	logger           *log.Logger
+}
+
+func NewTranscriptEmailUsecase(
+	conversationRepo *pg.ConversationRepository,
+	kbRepo *pg.KnowledgeBaseRepository,
+	rateLimitRepo *cache.RateLimitRepo,
+	mailer *mail.Mailer,
+	logger *log.Logger,
+) *TranscriptEmailUsecase {
+	return &TranscriptEmailUsecase{
+		conversationRepo: conversationRepo,
+		kbRepo:           kbRepo,
+		rateLimitRepo:    rateLimitRepo,
+		mailer:           mailer,
+		logger:           logger.WithModule("usecase.transcript_email"),
+	}
+}
+
+// SendTranscript send conversation transcript with reference links to the email of end user
+func (u *TranscriptEmailUsecase) SendTranscript(ctx context.Context, req *domain.SendTranscriptEmailReq) error {
+	if !u.mailer.Enabled() {
+		return mail.ErrMailNotConfigured
+	}
+	conversation, err := u.conversationRepo.GetConversationByNonce(ctx, req.KBID, req.ConversationID, req.Nonce)
+	if err != nil {
+		return fmt.Errorf("get conversation failed: %w", err)
+	}
+	record := &domain.ConversationTranscriptEmail{
+		KBID:           req.KBID,
+		ConversationID: conversation.ID,
+		Email:          req.Email,
+		RemoteIP:       req.RemoteIP,
+	}
+	allowed, err := u.allow(ctx, req)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		record.Status = domain.TranscriptEmailStatusRateLimited
+		u.createRecord(ctx, record)
+		return domain.ErrTranscriptEmailRateLimited
+	}
+
+	sendErr := u.send(ctx, conversation, req.Email)
+	record.Status = domain.TranscriptEmailStatusSent
+	if sendErr != nil {
+		record.Status = domain.TranscriptEmailStatusFailed
+		record.Error = sendErr.Error()
+		u.logger.Error("send transcript email failed", log.String("conversation_id", conversation.ID), log.Error(sendErr))
+	}
+	u.createRecord(ctx, record)
+	return sendErr
+}
+
+func (u *TranscriptEmailUsecase) allow(ctx context.Context, req *domain.SendTranscriptEmailReq) (bool, error) {
+	allowed, err := u.rateLimitRepo.Allow(ctx, "transcript_email:ip:"+req.RemoteIP, domain.TranscriptEmailLimitPerIP, time.Hour)
+	if err != nil || !allowed {
+		return allowed, err
+	}
+	return u.rateLimitRepo.Allow(ctx, "transcript_email:conversation:"+req.ConversationID, domain.TranscriptEmailLimitPerConversation, 24*time.Hour)
+}
+
+func (u *TranscriptEmailUsecase) createRecord(ctx context.Context, record *domain.ConversationTranscriptEmail) {
+	if err := u.conversationRepo.CreateTranscriptEmail(ctx, record); err != nil {
+		u.logger.Warn("create transcript email record failed", log.String("conversation_id", record.ConversationID), log.Error(err))
+	}
+}
+
+func (u *TranscriptEmailUsecase) send(ctx context.Context, conversation *domain.Conversation, email string) error {
+	kb, err := u.kbRepo.GetKnowledgeBaseByID(ctx, conversation.KBID)
+	if err != nil {
+		return fmt.Errorf("get knowledge base failed: %w", err)
+	}
+	messages, err := u.conversationRepo.GetConversationMessagesByID(ctx, conversation.ID)
+	if err != nil {
+		return fmt.Errorf("get conversation messages failed: %w", err)
+	}
+	references, err := u.conversationRepo.GetConversationReferences(ctx, conversation.ID)
+	if err != nil {
+		return fmt.Errorf("get conversation references failed: %w", err)
+	}
+	data := transcriptData{
+		KBName:     kb.Name,
+		Subject:    domain.DisplayTitle(conversation.Title, conversation.Subject),
+		CreatedAt:  conversation.CreatedAt.Format("2006-01-02 15:04"),
+		References: dedupReferences(references),
+	}
+	for _, message := range messages {
+		content := strings.TrimSpace(thinkBlockRegex.ReplaceAllString(message.Content, ""))
+		if content == "" {
+			continue
+		}
+		data.Messages = append(data.Messages, transcriptMessage{
+			IsUser:  message.Role == schema.User,
+			Content: content,
+		})
+	}
+	var body bytes.Buffer
+	if err := transcriptTemplate.Execute(&body, data); err != nil {
+		return fmt.Errorf("render transcript failed: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	subject := fmt.Sprintf("%s - 对话记录", kb.Name)
+	return u.mailer.SendHTML(ctx, email, subject, body.String())
+}
+
+func dedupReferences(references []*domain.ConversationReference) []*domain.ConversationReference {
+	seen := make(map[string]bool, len(references))
+	result := make([]*domain.ConversationReference, 0, len(references))
+	for _, reference := range references {
+		if reference.URL == "" || seen[reference.URL] {
+			continue
+		}
+		seen[reference.URL] = true
+		result = append(result, reference)
+	}
+	return result
+}
+
+func (u *TranscriptEmailUsecase) GetTranscriptEmailList(ctx context.Context, req *domain.TranscriptEmailListReq) (*domain.PaginatedResult[[]*domain.ConversationTranscriptEmail], error) {
+	records, total, err := u.conversationRepo.GetTranscriptEmailList(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewPaginatedResult(records, total), nil
+}
@@ -0,0 +1,410 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/chaitin/panda-wiki/config"
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/pkg/totp"
+	"github.com/chaitin/panda-wiki/repo/cache"
+	"github.com/chaitin/panda-wiki/repo/pg"
+)
+
+var backupCodeEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// twoFactorStore two-factor settings of users, codes are used with conditional updates so each is accepted once
+type twoFactorStore interface {
+	GetTwoFactor(ctx context.Context, userID string) (*domain.UserTwoFactor, error)
+	StartEnrollment(ctx context.Context, userID, secret string) error
+	Enable(ctx context.Context, userID string, step int64, backupCodes domain.TwoFactorBackupCodes) (bool, error)
+	UseStep(ctx context.Context, userID string, step int64) (bool, error)
+	UseBackupCode(ctx context.Context, userID, hash string) (bool, error)
+	UpdateBackupCodes(ctx context.Context, userID string, backupCodes domain.TwoFactorBackupCodes) error
+	DeleteTwoFactor(ctx context.Context, userID string) error
+}
+
+// TwoFactorUsecase totp two-factor authentication of password logins to the admin console
+type TwoFactorUsecase struct {
+	config        config.TwoFactorConfig
+	repo          twoFactorStore
+	challengeRepo *cache.TwoFactorChallengeRepo
+	userRepo      *pg.UserRepository
+	kbMemberRepo  *pg.KBMemberRepository
+	logger        *log.Logger
+}
+
+func NewTwoFactorUsecase(
+	config *config.Config,
+	repo *pg.TwoFactorRepository,
+	challengeRepo *cache.TwoFactorChallengeRepo,
+	userRepo *pg.UserRepository,
+	kbMemberRepo *pg.KBMemberRepository,
+	logger *log.Logger,
+) *TwoFactorUsecase {
+	u := &TwoFactorUsecase{
+		config:        config.Auth.TwoFactor,
+		repo:          repo,
+		challengeRepo: challengeRepo,
+		userRepo:      userRepo,
+		kbMemberRepo:  kbMemberRepo,
+		logger:        logger.WithModule("usecase.two_factor"),
+	}
+	switch u.config.Policy {
+	case domain.TwoFactorPolicyOptional, domain.TwoFactorPolicyOwners, domain.TwoFactorPolicyAll:
+	default:
+		u.logger.Warn("unknown two-factor policy, owners applies", log.String("policy", u.config.Policy))
+		u.config.Policy = domain.TwoFactorPolicyOwners
+	}
+	return u
+}
+
+// Required whether the policy requires the user to enable two-factor authentication,
+// owners are admins and members owning any kb
+func (u *TwoFactorUsecase) Required(ctx context.Context, userID string) (bool, error) {
+	switch u.config.Policy {
+	case domain.TwoFactorPolicyAll:
+		return true, nil
+	case domain.TwoFactorPolicyOptional:
+		return false, nil
+	}
+	role, err := u.userRepo.GetUserRole(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, domain.ErrUserNotFound
+		}
+		return false, err
+	}
+	if role == domain.UserRoleAdmin {
+		return true, nil
+	}
+	members, err := u.kbMemberRepo.GetUserKBMembers(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	for _, member := range members {
+		if member.Role == domain.KBRoleOwner {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// StartLogin challenge of a user who entered a valid password, empty step if no second factor is needed
+func (u *TwoFactorUsecase) StartLogin(ctx context.Context, userID string) (domain.TwoFactorStep, string, error) {
+	twoFactor, err := u.repo.GetTwoFactor(ctx, userID)
+	if err != nil {
+		return "", "", err
+	}
+	var step domain.TwoFactorStep
+	if twoFactor != nil && twoFactor.Enabled {
+		step = domain.TwoFactorStepVerify
+	} else {
+		required, err := u.Required(ctx, userID)
+		if err != nil {
+			return "", "", err
+		}
+		if !required {
+			return "", "", nil
+		}
+		step = domain.TwoFactorStepEnroll
+	}
+	token, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+	if err := u.challengeRepo.Set(ctx, token, &domain.TwoFactorChallenge{UserID: userID, Step: step}); err != nil {
+		return "", "", err
+	}
+	return step, token, nil
+}
+
+// VerifyLogin user of the challenge if the code is valid, the challenge is dropped after too many wrong codes
+func (u *TwoFactorUsecase) VerifyLogin(ctx context.Context, req *domain.TwoFactorLoginReq) (string, error) {
+	challenge, err := u.challenge(ctx, req.ChallengeToken, domain.TwoFactorStepVerify)
+	if err != nil {
+		return "", err
+	}
+	twoFactor, err := u.repo.GetTwoFactor(ctx, challenge.UserID)
+	if err != nil {
+		return "", err
+	}
+	if twoFactor == nil || !twoFactor.Enabled {
+		return "", domain.ErrTwoFactorChallengeInvalid
+	}
+	if err := u.verifyCode(ctx, twoFactor, req.Code); err != nil {
+		return "", u.fail(ctx, req.ChallengeToken, challenge.UserID, err)
+	}
+	if err := u.challengeRepo.Delete(ctx, req.ChallengeToken); err != nil {
+		return "", err
+	}
+	return challenge.UserID, nil
+}
+
+// EnrollLogin start the enrollment required of the user of the challenge
+func (u *TwoFactorUsecase) EnrollLogin(ctx context.Context, req *domain.TwoFactorChallengeReq) (*domain.TwoFactorEnrollResp, error) {
+	challenge, err := u.challenge(ctx, req.ChallengeToken, domain.TwoFactorStepEnroll)
+	if err != nil {
+		return nil, err
+	}
+	return u.Enroll(ctx, challenge.UserID)
+}
+
+// ConfirmLogin enable the enrollment of the user of the challenge with its first code, return the user and its backup codes
+func (u *TwoFactorUsecase) ConfirmLogin(ctx context.Context, req *domain.TwoFactorLoginReq) (string, []string, error) {
+	challenge, err := u.challenge(ctx, req.ChallengeToken, domain.TwoFactorStepEnroll)
+	if err != nil {
+		return "", nil, err
+	}
+	codes, err := u.Confirm(ctx, challenge.UserID, req.Code)
+	if err != nil {
+		if errors.Is(err, domain.ErrTwoFactorInvalidCode) {
+			return "", nil, u.fail(ctx, req.ChallengeToken, challenge.UserID, err)
+		}
+		return "", nil, err
+	}
+	if err := u.challengeRepo.Delete(ctx, req.ChallengeToken); err != nil {
+		return "", nil, err
+	}
+	return challenge.UserID, codes, nil
+}
+
+// Enroll new secret of the user, enabled by the first code of it
+func (u *TwoFactorUsecase) Enroll(ctx context.Context, userID string) (*domain.TwoFactorEnrollResp, error) {
+	twoFactor, err := u.repo.GetTwoFactor(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor != nil && twoFactor.Enabled {
+		return nil, domain.ErrTwoFactorAlreadyEnabled
+	}
+	user, err := u.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.ID == "" {
+		return nil, domain.ErrUserNotFound
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := u.repo.StartEnrollment(ctx, userID, secret); err != nil {
+		return nil, err
+	}
+	return &domain.TwoFactorEnrollResp{
+		Secret: secret,
+		URI:    totp.URI(u.config.Issuer, user.Account, secret),
+	}, nil
+}
+
+// Confirm enable the pending enrollment of the user if the code is valid, return its backup codes
+func (u *TwoFactorUsecase) Confirm(ctx context.Context, userID, code string) ([]string, error) {
+	twoFactor, err := u.repo.GetTwoFactor(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor == nil {
+		return nil, domain.ErrTwoFactorNotEnrolled
+	}
+	if twoFactor.Enabled {
+		return nil, domain.ErrTwoFactorAlreadyEnabled
+	}
+	step, ok := totp.Validate(twoFactor.Secret, normalizeTwoFactorCode(code), time.Now())
+	if !ok {
+		return nil, domain.ErrTwoFactorInvalidCode
+	}
+	codes, hashes, err := generateBackupCodes()
+	if err != nil {
+		return nil, err
+	}
+	enabled, err := u.repo.Enable(ctx, userID, step, hashes)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		return nil, domain.ErrTwoFactorAlreadyEnabled
+	}
+	u.logger.Info("two-factor authentication enabled", log.String("user_id", userID))
+	return codes, nil
+}
+
+func (u *TwoFactorUsecase) GetStatus(ctx context.Context, userID string) (*domain.TwoFactorStatusResp, error) {
+	required, err := u.Required(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	resp := &domain.TwoFactorStatusResp{Required: required}
+	twoFactor, err := u.repo.GetTwoFactor(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor != nil && twoFactor.Enabled {
+		resp.Enabled = true
+		resp.BackupCodesLeft = len(twoFactor.BackupCodes)
+		resp.EnabledAt = twoFactor.EnabledAt
+	}
+	return resp, nil
+}
+
+// RegenerateBackupCodes replace the backup codes of the user, after a valid code
+func (u *TwoFactorUsecase) RegenerateBackupCodes(ctx context.Context, userID, code string) ([]string, error) {
+	twoFactor, err := u.enabledTwoFactor(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.verifyCode(ctx, twoFactor, code); err != nil {
+		return nil, err
+	}
+	codes, hashes, err := generateBackupCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := u.repo.UpdateBackupCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	u.logger.Info("two-factor backup codes regenerated", log.String("user_id", userID))
+	return codes, nil
+}
+
+// Disable turn off two-factor authentication of the user after a valid code, unless the policy requires it
+func (u *TwoFactorUsecase) Disable(ctx context.Context, userID, code string) error {
+	required, err := u.Required(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if required {
+		return domain.ErrTwoFactorRequired
+	}
+	twoFactor, err := u.enabledTwoFactor(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := u.verifyCode(ctx, twoFactor, code); err != nil {
+		return err
+	}
+	if err := u.repo.DeleteTwoFactor(ctx, userID); err != nil {
+		return err
+	}
+	u.logger.Info("two-factor authentication disabled", log.String("user_id", userID))
+	return nil
+}
+
+// Reset remove two-factor authentication of a user who lost its authenticator app and backup codes,
+// the user enrolls again at its next login if the policy requires it
+func (u *TwoFactorUsecase) Reset(ctx context.Context, operatorID string, req *domain.ResetTwoFactorReq) error {
+	if err := u.repo.DeleteTwoFactor(ctx, req.UserID); err != nil {
+		return err
+	}
+	u.logger.Info("two-factor authentication reset", log.String("user_id", req.UserID), log.String("operator_id", operatorID))
+	return nil
+}
+
+func (u *TwoFactorUsecase) enabledTwoFactor(ctx context.Context, userID string) (*domain.UserTwoFactor, error) {
+	twoFactor, err := u.repo.GetTwoFactor(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor == nil || !twoFactor.Enabled {
+		return nil, domain.ErrTwoFactorNotEnrolled
+	}
+	return twoFactor, nil
+}
+
+func (u *TwoFactorUsecase) challenge(ctx context.Context, token string, step domain.TwoFactorStep) (*domain.TwoFactorChallenge, error) {
+	challenge, err := u.challengeRepo.Get(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if challenge == nil || challenge.Step != step {
+		return nil, domain.ErrTwoFactorChallengeInvalid
+	}
+	return challenge, nil
+}
+
+// fail count a wrong code of the challenge and drop it after too many, so codes can not be guessed
+func (u *TwoFactorUsecase) fail(ctx context.Context, token, userID string, cause error) error {
+	attempts, err := u.challengeRepo.Fail(ctx, token)
+	if err != nil {
+		return err
+	}
+	if attempts < domain.TwoFactorMaxAttempts {
+		return cause
+	}
+	u.logger.Warn("two-factor login dropped after too many wrong codes", log.String("user_id", userID))
+	if err := u.challengeRepo.Delete(ctx, token); err != nil {
+		return err
+	}
+	return domain.ErrTwoFactorChallengeInvalid
+}
+
+// verifyCode accept a code of the authenticator app or an unused backup code, each only once
+func (u *TwoFactorUsecase) verifyCode(ctx context.Context, twoFactor *domain.UserTwoFactor, code string) error {
+	code = normalizeTwoFactorCode(code)
+	if len(code) == totp.Digits {
+		step, ok := totp.Validate(twoFactor.Secret, code, time.Now())
+		if !ok {
+			return domain.ErrTwoFactorInvalidCode
+		}
+		used, err := u.repo.UseStep(ctx, twoFactor.UserID, step)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return domain.ErrTwoFactorInvalidCode
+		}
+		return nil
+	}
+	used, err := u.repo.UseBackupCode(ctx, twoFactor.UserID, hashBackupCode(code))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return domain.ErrTwoFactorInvalidCode
+	}
+	u.logger.Info("two-factor backup code used", log.String("user_id", twoFactor.UserID))
+	return nil
+}
+
+func normalizeTwoFactorCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer(" ", "", "-", "").Replace(code)
+}
+
+func hashBackupCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// generateBackupCodes backup codes like k3mz7-qa2xe and their hashes
+func generateBackupCodes() ([]string, domain.TwoFactorBackupCodes, error) {
+	codes := make([]string, 0, domain.TwoFactorBackupCodeCount)
+	hashes := make(domain.TwoFactorBackupCodes, 0, domain.TwoFactorBackupCodeCount)
+	for range domain.TwoFactorBackupCodeCount {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		code := backupCodeEncoding.EncodeToString(b)[:10]
+		codes = append(codes, code[:5]+"-"+code[5:])
+		hashes = append(hashes, hashBackupCode(code))
+	}
+	return codes, hashes, nil
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,191 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chaitin/panda-wiki/config"
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/pkg/totp"
+)
+
+// fakeTwoFactorStore two-factor settings in memory, with the conditions of the updates of the repository
+type fakeTwoFactorStore struct {
+	twoFactors map[string]*domain.UserTwoFactor
+}
+
+func (f *fakeTwoFactorStore) GetTwoFactor(ctx context.Context, userID string) (*domain.UserTwoFactor, error) {
+	return f.twoFactors[userID], nil
+}
+
+func (f *fakeTwoFactorStore) StartEnrollment(ctx context.Context, userID, secret string) error {
+	f.twoFactors[userID] = &domain.UserTwoFactor{UserID: userID, Secret: secret}
+	return nil
+}
+
+func (f *fakeTwoFactorStore) Enable(ctx context.Context, userID string, step int64, backupCodes domain.TwoFactorBackupCodes) (bool, error) {
+	twoFactor := f.twoFactors[userID]
+	if twoFactor == nil || twoFactor.Enabled {
+		return false, nil
+	}
+	twoFactor.Enabled, twoFactor.LastUsedStep, twoFactor.BackupCodes = true, step, backupCodes
+	return true, nil
+}
+
+func (f *fakeTwoFactorStore) UseStep(ctx context.Context, userID string, step int64) (bool, error) {
+	twoFactor := f.twoFactors[userID]
+	if twoFactor == nil || !twoFactor.Enabled || twoFactor.LastUsedStep >= step {
+		return false, nil
+	}
+	twoFactor.LastUsedStep = step
+	return true, nil
+}
+
+func (f *fakeTwoFactorStore) UseBackupCode(ctx context.Context, userID, hash string) (bool, error) {
+	twoFactor := f.twoFactors[userID]
+	if twoFactor == nil || !twoFactor.Enabled {
+		return false, nil
+	}
+	i := slices.Index(twoFactor.BackupCodes, hash)
+	if i < 0 {
+		return false, nil
+	}
+	twoFactor.BackupCodes = slices.Delete(twoFactor.BackupCodes, i, i+1)
+	return true, nil
+}
+
+func (f *fakeTwoFactorStore) UpdateBackupCodes(ctx context.Context, userID string, backupCodes domain.TwoFactorBackupCodes) error {
+	f.twoFactors[userID].BackupCodes = backupCodes
+	return nil
+}
+
+func (f *fakeTwoFactorStore) DeleteTwoFactor(ctx context.Context, userID string) error {
+	delete(f.twoFactors, userID)
+	return nil
+}
+
+func newTestTwoFactor(t *testing.T) (*TwoFactorUsecase, *domain.UserTwoFactor, []string) {
+	t.Helper()
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret() error = %v", err)
+	}
+	codes, hashes, err := generateBackupCodes()
+	if err != nil {
+		t.Fatalf("generateBackupCodes() error = %v", err)
+	}
+	twoFactor := &domain.UserTwoFactor{UserID: "user-1", Secret: secret, Enabled: true, BackupCodes: hashes}
+	u := &TwoFactorUsecase{
+		repo:   &fakeTwoFactorStore{twoFactors: map[string]*domain.UserTwoFactor{"user-1": twoFactor}},
+		logger: log.NewLogger(&config.Config{}),
+	}
+	return u, twoFactor, codes
+}
+
+func TestGenerateBackupCodes(t *testing.T) {
+	codes, hashes, err := generateBackupCodes()
+	if err != nil {
+		t.Fatalf("generateBackupCodes() error = %v", err)
+	}
+	if len(codes) != domain.TwoFactorBackupCodeCount || len(hashes) != domain.TwoFactorBackupCodeCount {
+		t.Fatalf("generated %d codes and %d hashes, want %d", len(codes), len(hashes), domain.TwoFactorBackupCodeCount)
+	}
+	format := regexp.MustCompile(`^[a-z2-7]{5}-[a-z2-7]{5}$`)
+	hash := regexp.MustCompile(`^[0-9a-f]{64}$`)
+	for i, code := range codes {
+		if !format.MatchString(code) {
+			t.Errorf("code %q is not like k3mz7-qa2xe", code)
+		}
+		if !hash.MatchString(hashes[i]) {
+			t.Errorf("hash %q is not a sha256 hex", hashes[i])
+		}
+		if hashes[i] != hashBackupCode(normalizeTwoFactorCode(code)) {
+			t.Errorf("hash of code %d is not the hash of the normalized code", i)
+		}
+		if strings.Contains(hashes[i], strings.ReplaceAll(code, "-", "")) {
+			t.Errorf("hash of code %d contains the code", i)
+		}
+		if slices.Index(codes, code) != i || slices.Index(hashes, hashes[i]) != i {
+			t.Errorf("code %d is generated twice", i)
+		}
+	}
+}
+
+func TestVerifyCodeTOTP(t *testing.T) {
+	step := totp.Step(time.Now())
+	tests := []struct {
+		name     string
+		lastUsed int64
+		step     int64
+		wantErr  error
+	}{
+		{name: "current step", lastUsed: step - 5, step: step},
+		{name: "previous step within skew", lastUsed: step - 5, step: step - 1},
+		{name: "step outside skew", lastUsed: step - 5, step: step - 3, wantErr: domain.ErrTwoFactorInvalidCode},
+		{name: "step used already is replayed", lastUsed: step, step: step, wantErr: domain.ErrTwoFactorInvalidCode},
+		{name: "step before the used one", lastUsed: step, step: step - 1, wantErr: domain.ErrTwoFactorInvalidCode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, twoFactor, _ := newTestTwoFactor(t)
+			twoFactor.LastUsedStep = tt.lastUsed
+			code, err := totp.Code(twoFactor.Secret, tt.step)
+			if err != nil {
+				t.Fatalf("Code() error = %v", err)
+			}
+			if err := u.verifyCode(context.Background(), twoFactor, code); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("verifyCode() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && twoFactor.LastUsedStep != tt.step {
+				t.Errorf("last used step = %d, want %d", twoFactor.LastUsedStep, tt.step)
+			}
+		})
+	}
+}
+
+func TestVerifyCodeReplay(t *testing.T) {
+	u, twoFactor, _ := newTestTwoFactor(t)
+	code, err := totp.Code(twoFactor.Secret, totp.Step(time.Now()))
+	if err != nil {
+		t.Fatalf("Code() error = %v", err)
+	}
+	if err := u.verifyCode(context.Background(), twoFactor, code); err != nil {
+		t.Fatalf("first verifyCode() error = %v", err)
+	}
+	if err := u.verifyCode(context.Background(), twoFactor, " "+code[:3]+" "+code[3:]); !errors.Is(err, domain.ErrTwoFactorInvalidCode) {
+		t.Errorf("replayed verifyCode() error = %v, want %v", err, domain.ErrTwoFactorInvalidCode)
+	}
+}
+
+func TestVerifyCodeBackupCode(t *testing.T) {
+	u, twoFactor, codes := newTestTwoFactor(t)
+	ctx := context.Background()
+	tests := []struct {
+		name    string
+		code    string
+		wantErr error
+	}{
+		{name: "backup code", code: codes[0]},
+		{name: "backup code used already", code: codes[0], wantErr: domain.ErrTwoFactorInvalidCode},
+		{name: "upper case backup code without dash", code: strings.ToUpper(strings.ReplaceAll(codes[1], "-", ""))},
+		{name: "backup code with spaces", code: " " + strings.ReplaceAll(codes[2], "-", " ") + " "},
+		{name: "hash of a backup code", code: hashBackupCode(normalizeTwoFactorCode(codes[3])), wantErr: domain.ErrTwoFactorInvalidCode},
+		{name: "unknown backup code", code: "aaaaa-aaaaa", wantErr: domain.ErrTwoFactorInvalidCode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := u.verifyCode(ctx, twoFactor, tt.code); !errors.Is(err, tt.wantErr) {
+				t.Errorf("verifyCode() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+	if left := len(twoFactor.BackupCodes); left != domain.TwoFactorBackupCodeCount-3 {
+		t.Errorf("backup codes left = %d, want %d", left, domain.TwoFactorBackupCodeCount-3)
+	}
+}
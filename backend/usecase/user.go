@@ -2,11 +2,12 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 
 	"github.com/chaitin/panda-wiki/config"
 	"github.com/chaitin/panda-wiki/domain"
@@ -15,42 +16,117 @@ import (
 )
 
 type UserUsecase struct {
-	repo   *pg.UserRepository
-	logger *log.Logger
-	config *config.Config
+	repo             *pg.UserRepository
+	kbMemberUsecase  *KBMemberUsecase
+	ldapUsecase      *LDAPUsecase
+	twoFactorUsecase *TwoFactorUsecase
+	sessionUsecase   *SessionUsecase
+	apiTokenUsecase  *APITokenUsecase
+	logger           *log.Logger
+	config           *config.Config
 }
 
-func NewUserUsecase(repo *pg.UserRepository, logger *log.Logger, config *config.Config) (*UserUsecase, error) {
+func NewUserUsecase(repo *pg.UserRepository, kbMemberUsecase *KBMemberUsecase, ldapUsecase *LDAPUsecase, twoFactorUsecase *TwoFactorUsecase, sessionUsecase *SessionUsecase, apiTokenUsecase *APITokenUsecase, logger *log.Logger, config *config.Config) (*UserUsecase, error) {
 	if config.AdminPassword != "" {
 		if err := repo.UpsertDefaultUser(context.Background(), &domain.User{
 			ID:       uuid.New().String(),
 			Account:  "admin",
 			Password: config.AdminPassword,
+			Role:     domain.UserRoleAdmin,
 		}); err != nil {
 			return nil, fmt.Errorf("failed to create default user: %w", err)
 		}
 	}
 	return &UserUsecase{
-		repo:   repo,
-		logger: logger.WithModule("usecase.user"),
-		config: config,
+		repo:             repo,
+		kbMemberUsecase:  kbMemberUsecase,
+		ldapUsecase:      ldapUsecase,
+		twoFactorUsecase: twoFactorUsecase,
+		sessionUsecase:   sessionUsecase,
+		apiTokenUsecase:  apiTokenUsecase,
+		logger:           logger.WithModule("usecase.user"),
+		config:           config,
 	}, nil
 }
 
 func (u *UserUsecase) CreateUser(ctx context.Context, user *domain.User) error {
+	if user.Role == "" {
+		user.Role = domain.UserRoleAdmin
+	}
 	return u.repo.CreateUser(ctx, user)
 }
 
-func (u *UserUsecase) VerifyUserAndGenerateToken(ctx context.Context, req domain.LoginReq) (string, error) {
+// VerifyUserAndGenerateToken console token of the user, or the challenge of its second factor if it enabled or must enroll it
+func (u *UserUsecase) VerifyUserAndGenerateToken(ctx context.Context, req domain.LoginReq, client *domain.SessionClient) (*domain.LoginResp, error) {
+	// the built-in admin account keeps password login for recovery when sso is down
+	if oidc := u.config.Auth.OIDC; oidc.Issuer != "" && oidc.DisablePasswordLogin && req.Account != "admin" {
+		return nil, domain.ErrPasswordLoginDisabled
+	}
 	var user *domain.User
 	var err error
 	user, err = u.repo.VerifyUser(ctx, req.Account, req.Password)
+	// accounts of the directory always log in against it, so disabled accounts can not use a local password
+	if u.ldapUsecase.Enabled() && req.Account != "admin" && (err != nil || user.LDAPDN != "") {
+		user, err = u.ldapUsecase.Login(ctx, req.Account, req.Password)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return u.login(ctx, user.ID, client)
+}
+
+// login console token of the user who proved its identity, or the challenge of the second factor if it has to
+func (u *UserUsecase) login(ctx context.Context, userID string, client *domain.SessionClient) (*domain.LoginResp, error) {
+	step, challengeToken, err := u.twoFactorUsecase.StartLogin(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if step != "" {
+		return &domain.LoginResp{TwoFactor: step, ChallengeToken: challengeToken}, nil
+	}
+	token, err := u.GenerateToken(ctx, userID, client)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.LoginResp{Token: token}, nil
+}
+
+// VerifyTwoFactorLogin console token of the challenge after a valid code
+func (u *UserUsecase) VerifyTwoFactorLogin(ctx context.Context, req *domain.TwoFactorLoginReq, client *domain.SessionClient) (*domain.LoginResp, error) {
+	userID, err := u.twoFactorUsecase.VerifyLogin(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	token, err := u.GenerateToken(ctx, userID, client)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.LoginResp{Token: token}, nil
+}
+
+// ConfirmTwoFactorLogin enable the enrollment required at login, return the backup codes and the console token
+func (u *UserUsecase) ConfirmTwoFactorLogin(ctx context.Context, req *domain.TwoFactorLoginReq, client *domain.SessionClient) (*domain.TwoFactorConfirmResp, error) {
+	userID, codes, err := u.twoFactorUsecase.ConfirmLogin(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	token, err := u.GenerateToken(ctx, userID, client)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.TwoFactorConfirmResp{BackupCodes: codes, Token: token}, nil
+}
+
+// GenerateToken console token of a new session of the user, valid for a day unless the session is revoked
+func (u *UserUsecase) GenerateToken(ctx context.Context, userID string, client *domain.SessionClient) (string, error) {
+	session, err := u.sessionUsecase.CreateSession(ctx, userID, client)
 	if err != nil {
 		return "", err
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"id":  user.ID,
-		"exp": time.Now().Add(time.Hour * 24).Unix(),
+		"id":                  userID,
+		domain.ClaimSessionID: session.ID,
+		"exp":                 session.ExpiresAt.Unix(),
 	})
 
 	return token.SignedString([]byte(u.config.Auth.JWT.Secret))
@@ -61,10 +137,16 @@ func (u *UserUsecase) GetUser(ctx context.Context, userID string) (*domain.UserI
 	if err != nil {
 		return nil, err
 	}
+	kbRoles, err := u.kbMemberUsecase.GetUserKBRoles(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
 	return &domain.UserInfoResp{
 		ID:        user.ID,
 		Account:   user.Account,
+		Role:      user.Role,
 		CreatedAt: user.CreatedAt,
+		KBRoles:   kbRoles,
 	}, nil
 }
 
@@ -72,10 +154,42 @@ func (u *UserUsecase) ListUsers(ctx context.Context) ([]*domain.UserListItemResp
 	return u.repo.ListUsers(ctx)
 }
 
+// ResetPassword set the password and log out every session of the user
 func (u *UserUsecase) ResetPassword(ctx context.Context, req *domain.ResetPasswordReq) error {
-	return u.repo.UpdateUserPassword(ctx, req.ID, req.NewPassword)
+	if err := u.repo.UpdateUserPassword(ctx, req.ID, req.NewPassword); err != nil {
+		return err
+	}
+	return u.sessionUsecase.RevokeAllSessions(ctx, req.ID)
 }
 
+// UpdateUserRole change the global role of another user, members keep their roles on kbs.
+// demoted admins lose their service tokens and sandbox tokens
+func (u *UserUsecase) UpdateUserRole(ctx context.Context, operatorID string, req *domain.UpdateUserRoleReq) error {
+	if operatorID == req.ID {
+		return domain.ErrChangeOwnRole
+	}
+	if err := u.repo.UpdateUserRole(ctx, req.ID, req.Role); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.ErrUserNotFound
+		}
+		return err
+	}
+	if req.Role != domain.UserRoleAdmin {
+		if err := u.apiTokenUsecase.RevokeUserAPITokens(ctx, req.ID, true); err != nil {
+			return err
+		}
+	}
+	u.logger.Info("user role updated", log.String("user_id", req.ID), log.String("role", string(req.Role)), log.String("operator_id", operatorID))
+	return nil
+}
+
+// DeleteUser delete the user, log out its sessions and revoke every api token it created
 func (u *UserUsecase) DeleteUser(ctx context.Context, userID string) error {
+	if err := u.sessionUsecase.RevokeAllSessions(ctx, userID); err != nil {
+		return err
+	}
+	if err := u.apiTokenUsecase.RevokeUserAPITokens(ctx, userID, false); err != nil {
+		return err
+	}
 	return u.repo.DeleteUser(ctx, userID)
 }
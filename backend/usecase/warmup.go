@@ -0,0 +1,200 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/chaitin/panda-wiki/config"
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/repo/pg"
+	"github.com/chaitin/panda-wiki/store/rag"
+)
+
+// errDimensionMismatch retrying does not help, the index or the model has to be changed
+var errDimensionMismatch = errors.New("embedding dimension mismatch")
+
+type WarmupUsecase struct {
+	config           *config.Config
+	kbUsecase        *KnowledgeBaseUsecase
+	llmUsecase       *LLMUsecase
+	modelRepo        *pg.ModelRepository
+	conversationRepo *pg.ConversationRepository
+	rag              rag.RAGService
+	logger           *log.Logger
+
+	once   sync.Once
+	mutex  sync.RWMutex
+	status domain.WarmupStatus
+}
+
+func NewWarmupUsecase(config *config.Config, kbUsecase *KnowledgeBaseUsecase, llmUsecase *LLMUsecase, modelRepo *pg.ModelRepository, conversationRepo *pg.ConversationRepository, rag rag.RAGService, logger *log.Logger) *WarmupUsecase {
+	return &WarmupUsecase{
+		config:           config,
+		kbUsecase:        kbUsecase,
+		llmUsecase:       llmUsecase,
+		modelRepo:        modelRepo,
+		conversationRepo: conversationRepo,
+		rag:              rag,
+		logger:           logger.WithModule("usecase.warmup"),
+		status: domain.WarmupStatus{
+			State:             domain.WarmupStateRunning,
+			ExpectedDimension: config.RAG.Dimension,
+			WarmedKBIDs:       []string{},
+		},
+	}
+}
+
+// Start run warm-up in background once, transient failures are retried until it succeeds
+func (u *WarmupUsecase) Start(ctx context.Context) {
+	u.once.Do(func() {
+		go u.run(ctx)
+	})
+}
+
+// GetStatus current warm-up status, not ready until warm-up succeeded
+func (u *WarmupUsecase) GetStatus() domain.WarmupStatus {
+	u.mutex.RLock()
+	defer u.mutex.RUnlock()
+	status := u.status
+	status.WarmedKBIDs = append([]string{}, u.status.WarmedKBIDs...)
+	return status
+}
+
+func (u *WarmupUsecase) run(ctx context.Context) {
+	for {
+		u.setStatus(func(s *domain.WarmupStatus) {
+			s.StartedAt = time.Now()
+			s.FinishedAt = nil
+			s.WarmedKBIDs = []string{}
+		})
+		err := u.warmup(ctx)
+		finishedAt := time.Now()
+		u.setStatus(func(s *domain.WarmupStatus) {
+			s.FinishedAt = &finishedAt
+			if err != nil {
+				s.State = domain.WarmupStateFailed
+				s.Error = err.Error()
+			} else {
+				s.State = domain.WarmupStateReady
+				s.Error = ""
+			}
+		})
+		if err == nil {
+			u.logger.Info("warm-up finished", log.Any("duration", finishedAt.Sub(u.GetStatus().StartedAt).String()))
+			return
+		}
+		if errors.Is(err, errDimensionMismatch) {
+			u.logger.Error("warm-up failed, service will not become ready", log.Error(err))
+			return
+		}
+		u.logger.Warn("warm-up failed, retrying", log.Error(err), log.Any("retry_in", domain.WarmupRetryInterval.String()))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(domain.WarmupRetryInterval):
+		}
+	}
+}
+
+func (u *WarmupUsecase) warmup(ctx context.Context) error {
+	if err := u.verifyEmbeddingDimension(ctx); err != nil {
+		return err
+	}
+	return u.preloadKBs(ctx)
+}
+
+// verifyEmbeddingDimension embed a probe text with the default embedding model and those selected by kbs,
+// and compare their dimensions with the vector index
+func (u *WarmupUsecase) verifyEmbeddingDimension(ctx context.Context) error {
+	models := make([]*domain.Model, 0)
+	defaultModel, err := u.modelRepo.GetModelByType(ctx, domain.ModelTypeEmbedding)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("get embedding model failed: %w", err)
+		}
+	} else {
+		models = append(models, defaultModel)
+	}
+	modelIDs, err := u.kbUsecase.GetEmbeddingModelIDs(ctx)
+	if err != nil {
+		return fmt.Errorf("get embedding models of kbs failed: %w", err)
+	}
+	for _, id := range modelIDs {
+		if defaultModel != nil && id == defaultModel.ID {
+			continue
+		}
+		model, err := u.modelRepo.GetModel(ctx, id, domain.ModelTypeEmbedding)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				u.logger.Warn("embedding model of kbs not found, skip dimension check", log.String("model_id", id))
+				continue
+			}
+			return fmt.Errorf("get embedding model %s failed: %w", id, err)
+		}
+		models = append(models, model)
+	}
+	if len(models) == 0 {
+		// fresh install, the model is verified when it is configured
+		u.logger.Warn("embedding model is not configured, skip dimension check")
+		return nil
+	}
+	for i, model := range models {
+		embeddings, err := u.llmUsecase.Embed(ctx, model, []string{domain.WarmupProbeText})
+		if err != nil {
+			return fmt.Errorf("embed probe with model %s failed: %w", model.Model, err)
+		}
+		if len(embeddings) == 0 {
+			return fmt.Errorf("embedding model %s returned no embedding for probe", model.Model)
+		}
+		actual := len(embeddings[0])
+		if i == 0 {
+			u.setStatus(func(s *domain.WarmupStatus) {
+				s.ActualDimension = actual
+			})
+		}
+		if expected := u.config.RAG.Dimension; actual != expected {
+			return fmt.Errorf("%w: embedding model %s returns %d dimensions but the vector index is configured with %d (rag.dimension)",
+				errDimensionMismatch, model.Model, actual, expected)
+		}
+	}
+	return nil
+}
+
+// preloadKBs fill kb cache and query vector index of the most active kbs
+func (u *WarmupUsecase) preloadKBs(ctx context.Context) error {
+	if u.config.RAG.WarmupKBCount <= 0 {
+		return nil
+	}
+	kbIDs, err := u.conversationRepo.GetActiveKBIDs(ctx, time.Now().Add(-domain.WarmupActivityWindow), u.config.RAG.WarmupKBCount)
+	if err != nil {
+		return fmt.Errorf("get active kbs failed: %w", err)
+	}
+	for _, kbID := range kbIDs {
+		kb, err := u.kbUsecase.GetKnowledgeBase(ctx, kbID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return fmt.Errorf("preload kb %s failed: %w", kbID, err)
+		}
+		if _, err := u.rag.QueryRecords(ctx, []string{kb.DatasetID}, domain.WarmupProbeText, kb.ModelSettings.RerankModelID); err != nil {
+			return fmt.Errorf("warm up index of kb %s failed: %w", kbID, err)
+		}
+		u.setStatus(func(s *domain.WarmupStatus) {
+			s.WarmedKBIDs = append(s.WarmedKBIDs, kbID)
+		})
+	}
+	return nil
+}
+
+func (u *WarmupUsecase) setStatus(update func(s *domain.WarmupStatus)) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	update(&u.status)
+}
@@ -0,0 +1,293 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/google/uuid"
+
+	"github.com/chaitin/panda-wiki/domain"
+	"github.com/chaitin/panda-wiki/log"
+	"github.com/chaitin/panda-wiki/pkg/jsonpath"
+	"github.com/chaitin/panda-wiki/repo/pg"
+)
+
+const webhookMaxRetry = 3
+
+var webhookTemplateFuncs = template.FuncMap{
+	// json encode a value, for strings inside json templates
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"truncate": func(n int, s string) string {
+		r := []rune(s)
+		if len(r) <= n {
+			return s
+		}
+		return string(r[:n])
+	},
+}
+
+type WebhookUsecase struct {
+	repo       *pg.WebhookRepository
+	httpClient *http.Client
+	logger     *log.Logger
+}
+
+func NewWebhookUsecase(repo *pg.WebhookRepository, logger *log.Logger) *WebhookUsecase {
+	return &WebhookUsecase{
+		repo:       repo,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		logger:     logger.WithModule("usecase.webhook"),
+	}
+}
+
+func (u *WebhookUsecase) CreateWebhook(ctx context.Context, req *domain.CreateWebhookReq) (string, error) {
+	if err := validateWebhookFormat(req.Format, req.Template, req.Mapping); err != nil {
+		return "", err
+	}
+	now := time.Now()
+	webhook := &domain.Webhook{
+		ID:        uuid.New().String(),
+		CreatedAt: now,
+	}
+	fillWebhook(webhook, req, now)
+	if err := u.repo.CreateWebhook(ctx, webhook); err != nil {
+		return "", err
+	}
+	return webhook.ID, nil
+}
+
+func (u *WebhookUsecase) UpdateWebhook(ctx context.Context, req *domain.UpdateWebhookReq) error {
+	if err := validateWebhookFormat(req.Format, req.Template, req.Mapping); err != nil {
+		return err
+	}
+	webhook := &domain.Webhook{ID: req.ID}
+	fillWebhook(webhook, &req.CreateWebhookReq, time.Now())
+	if req.RemoveSecret {
+		webhook.Secret = ""
+	}
+	// the secret is masked in the list, an empty one is not a change
+	return u.repo.UpdateWebhook(ctx, webhook, req.Secret != "" || req.RemoveSecret)
+}
+
+func fillWebhook(webhook *domain.Webhook, req *domain.CreateWebhookReq, now time.Time) {
+	webhook.KBID = req.KBID
+	webhook.Name = req.Name
+	webhook.URL = req.URL
+	webhook.Events = req.Events
+	webhook.Secret = req.Secret
+	webhook.Headers = req.Headers
+	webhook.Format = req.Format
+	webhook.Template = req.Template
+	webhook.Mapping = req.Mapping
+	webhook.Enabled = req.Enabled
+	webhook.UpdatedAt = now
+}
+
+func (u *WebhookUsecase) DeleteWebhook(ctx context.Context, req *domain.DeleteWebhookReq) error {
+	return u.repo.DeleteWebhook(ctx, req.KBID, req.ID)
+}
+
+func (u *WebhookUsecase) GetWebhookList(ctx context.Context, kbID string) ([]*domain.Webhook, error) {
+	webhooks, err := u.repo.GetWebhookList(ctx, kbID)
+	if err != nil {
+		return nil, err
+	}
+	for i, webhook := range webhooks {
+		webhooks[i] = webhook.Masked()
+	}
+	return webhooks, nil
+}
+
+// PreviewWebhook render payload of the settings with a sample event
+func (u *WebhookUsecase) PreviewWebhook(ctx context.Context, req *domain.PreviewWebhookReq) (*domain.PreviewWebhookResp, error) {
+	if err := validateWebhookFormat(req.Format, req.Template, req.Mapping); err != nil {
+		return nil, err
+	}
+	event := sampleWebhookEvent(req.Event)
+	payload, err := renderWebhookPayload(&domain.Webhook{
+		Format:   req.Format,
+		Template: req.Template,
+		Mapping:  req.Mapping,
+	}, event)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.PreviewWebhookResp{Event: event, Payload: string(payload)}, nil
+}
+
+// DeliverEvent post the event to all webhooks of the kb subscribed to it
+func (u *WebhookUsecase) DeliverEvent(ctx context.Context, event *domain.WebhookEvent) {
+	webhooks, err := u.repo.GetSubscribedWebhooks(ctx, event.KBID, event.Type)
+	if err != nil {
+		u.logger.Error("get subscribed webhooks failed", log.String("kb_id", event.KBID), log.Error(err))
+		return
+	}
+	for _, webhook := range webhooks {
+		payload, err := renderWebhookPayload(webhook, event)
+		if err != nil {
+			u.logger.Warn("render webhook payload failed", log.String("webhook_id", webhook.ID), log.Error(err))
+			continue
+		}
+		for i := 0; i < webhookMaxRetry; i++ {
+			if err = u.post(ctx, webhook, event, payload); err == nil {
+				break
+			}
+			u.logger.Warn("post webhook failed, retrying", log.String("webhook_id", webhook.ID), log.Int("attempt", i+1), log.Error(err))
+			time.Sleep(time.Duration(i+1) * time.Second)
+		}
+		if err != nil {
+			u.logger.Error("drop webhook event after retries", log.String("webhook_id", webhook.ID), log.String("event_id", event.ID), log.Error(err))
+		}
+	}
+}
+
+func (u *WebhookUsecase) post(ctx context.Context, webhook *domain.Webhook, event *domain.WebhookEvent, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-PandaWiki-Event", string(event.Type))
+	req.Header.Set("X-PandaWiki-Delivery", event.ID)
+	if webhook.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(webhook.Secret))
+		mac.Write(payload)
+		req.Header.Set("X-PandaWiki-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	for key, value := range webhook.Headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := u.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook responded %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+func validateWebhookFormat(format domain.WebhookFormat, tmpl string, mapping domain.StringMap) error {
+	switch format {
+	case domain.WebhookFormatTemplate:
+		if strings.TrimSpace(tmpl) == "" {
+			return fmt.Errorf("template is required")
+		}
+		if _, err := template.New("webhook").Funcs(webhookTemplateFuncs).Parse(tmpl); err != nil {
+			return fmt.Errorf("invalid template: %w", err)
+		}
+	case domain.WebhookFormatJSONPath:
+		if len(mapping) == 0 {
+			return fmt.Errorf("mapping is required")
+		}
+		for field, path := range mapping {
+			if _, err := jsonpath.Get(nil, path); err != nil {
+				return fmt.Errorf("invalid jsonpath of %s: %w", field, err)
+			}
+		}
+	}
+	return nil
+}
+
+// renderWebhookPayload template and jsonpath see the event in its json form, e.g. {{.data.content}} or $.data.content
+func renderWebhookPayload(webhook *domain.Webhook, event *domain.WebhookEvent) ([]byte, error) {
+	raw, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	if webhook.Format == domain.WebhookFormatRaw {
+		return raw, nil
+	}
+	var data any
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, err
+	}
+	switch webhook.Format {
+	case domain.WebhookFormatTemplate:
+		tmpl, err := template.New("webhook").Funcs(webhookTemplateFuncs).Option("missingkey=zero").Parse(webhook.Template)
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case domain.WebhookFormatJSONPath:
+		out := make(map[string]any, len(webhook.Mapping))
+		for field, path := range webhook.Mapping {
+			value, err := jsonpath.Get(data, path)
+			if err != nil {
+				return nil, err
+			}
+			out[field] = value
+		}
+		return json.Marshal(out)
+	}
+	return nil, fmt.Errorf("unknown webhook format: %s", webhook.Format)
+}
+
+func sampleWebhookEvent(eventType domain.WebhookEventType) *domain.WebhookEvent {
+	now := time.Now()
+	var data any
+	switch eventType {
+	case domain.WebhookEventConversationCreated:
+		data = &domain.Conversation{
+			ID:        "00000000-0000-0000-0000-000000000001",
+			KBID:      "00000000-0000-0000-0000-000000000000",
+			AppID:     "00000000-0000-0000-0000-000000000002",
+			Subject:   "如何部署 PandaWiki？",
+			RemoteIP:  "127.0.0.1",
+			CreatedAt: now,
+		}
+	case domain.WebhookEventAnomalyDetected:
+		blockedUntil := now.Add(domain.DefaultAnomalyBlockMinutes * time.Minute)
+		data = &domain.ConversationAnomaly{
+			ID:           "00000000-0000-0000-0000-000000000005",
+			KBID:         "00000000-0000-0000-0000-000000000000",
+			AppID:        "00000000-0000-0000-0000-000000000002",
+			RemoteIP:     "127.0.0.1",
+			Type:         domain.AnomalyTypeQuestionStorm,
+			Detail:       "如何部署 PandaWiki？",
+			Count:        domain.DefaultAnomalyQuestionsPerHour + 1,
+			BlockedUntil: &blockedUntil,
+			CreatedAt:    now,
+		}
+	default:
+		data = &domain.ConversationMessage{
+			ID:             "00000000-0000-0000-0000-000000000003",
+			ConversationID: "00000000-0000-0000-0000-000000000001",
+			AppID:          "00000000-0000-0000-0000-000000000002",
+			Role:           schema.Assistant,
+			Content:        "可以使用一键安装脚本部署 PandaWiki。",
+			Model:          "gpt-4o",
+			TotalTokens:    128,
+			RemoteIP:       "127.0.0.1",
+			CreatedAt:      now,
+		}
+	}
+	dataBytes, _ := json.Marshal(data)
+	return &domain.WebhookEvent{
+		ID:        "00000000-0000-0000-0000-000000000004",
+		Type:      eventType,
+		KBID:      "00000000-0000-0000-0000-000000000000",
+		Data:      dataBytes,
+		CreatedAt: now,
+	}
+}
@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"strings"
+
+	"github.com/mileusna/useragent"
+)
+
+var botUAKeywords = []string{
+	"bot", "crawler", "spider", "slurp", "scrapy", "headless", "phantomjs", "puppeteer", "playwright", "selenium",
+	"curl", "wget", "python-requests", "python-urllib", "aiohttp", "httpx", "go-http-client", "okhttp", "java/",
+	"apache-httpclient", "node-fetch", "axios", "postman", "lighthouse", "pingdom", "uptime", "monitor", "preview",
+}
+
+// IsBotUserAgent detect crawlers, scripts and monitors by user agent, empty user agent is treated as bot
+func IsBotUserAgent(ua string) bool {
+	if strings.TrimSpace(ua) == "" {
+		return true
+	}
+	if useragent.Parse(ua).Bot {
+		return true
+	}
+	ua = strings.ToLower(ua)
+	for _, keyword := range botUAKeywords {
+		if strings.Contains(ua, keyword) {
+			return true
+		}
+	}
+	return false
+}
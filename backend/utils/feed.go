@@ -14,11 +14,15 @@ import (
 // Link: 条目链接（URL）
 // Description: 条目描述内容
 // Published: 发布时间（字符串格式，具体格式由Feed源决定）
+// ID: 条目唯一标识（RSS guid、Atom id、JSON Feed id），没有时为空
+// Content: 条目完整内容（HTML），没有时为空
 type FeedItem struct {
 	Title       string // 条目标题
 	Link        string // 条目链接URL
 	Description string // 条目描述内容
 	Published   string // 发布时间（字符串格式）
+	ID          string // 条目唯一标识
+	Content     string // 条目完整内容（HTML）
 }
 
 // Feed represents a generic feed structure
@@ -93,6 +97,7 @@ func parseRSS(content []byte) (*Feed, error) {
 					Value string `xml:",chardata"`
 				} `xml:"link"`
 				Description string `xml:"description"`
+				Content     string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
 				PubDate     string `xml:"pubDate"`
 				Guid        struct {
 					IsPermaLink string `xml:"isPermaLink,attr"`
@@ -122,6 +127,8 @@ func parseRSS(content []byte) (*Feed, error) {
 			Title:       item.Title,
 			Description: item.Description,
 			Published:   item.PubDate,
+			ID:          strings.TrimSpace(item.Guid.Value),
+			Content:     item.Content,
 		}
 
 		// Try to get link from various sources in order of preference
@@ -161,7 +168,9 @@ func parseAtom(content []byte) (*Feed, error) {
 			Link  []struct {
 				Href string `xml:"href,attr"`
 			} `xml:"link"`
+			ID      string `xml:"id"`
 			Summary string `xml:"summary"`
+			Content string `xml:"content"`
 			Updated string `xml:"updated"`
 		} `xml:"entry"`
 	}
@@ -186,6 +195,8 @@ func parseAtom(content []byte) (*Feed, error) {
 			Title:       entry.Title,
 			Description: entry.Summary,
 			Published:   entry.Updated,
+			ID:          strings.TrimSpace(entry.ID),
+			Content:     entry.Content,
 		}
 		if len(entry.Link) > 0 {
 			item.Link = entry.Link[0].Href
@@ -207,9 +218,11 @@ func parseJSONFeed(content []byte) (*Feed, error) {
 		Description string `json:"description"`
 		HomePageURL string `json:"home_page_url"`
 		Items       []struct {
+			ID            string `json:"id"`
 			Title         string `json:"title"`
 			URL           string `json:"url"`
 			ContentText   string `json:"content_text"`
+			ContentHTML   string `json:"content_html"`
 			DatePublished string `json:"date_published"`
 		} `json:"items"`
 	}
@@ -232,6 +245,8 @@ func parseJSONFeed(content []byte) (*Feed, error) {
 			Link:        item.URL,
 			Description: item.ContentText,
 			Published:   item.DatePublished,
+			ID:          item.ID,
+			Content:     item.ContentHTML,
 		})
 	}
 
@@ -0,0 +1,32 @@
+package utils
+
+import "math"
+
+// CosineSimilarity returns the cosine similarity of a and b, 0 if dimensions mismatch
+func CosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// UpdateCentroid returns the running mean of centroid (built from count vectors) and v
+func UpdateCentroid(centroid []float32, count int64, v []float32) []float32 {
+	if len(centroid) != len(v) || count <= 0 {
+		return append([]float32(nil), v...)
+	}
+	next := make([]float32, len(centroid))
+	for i := range centroid {
+		next[i] = (centroid[i]*float32(count) + v[i]) / float32(count+1)
+	}
+	return next
+}
@@ -33,6 +33,7 @@ import {
   UpdateNodeActionData,
   UpdateNodeData,
   UpdateUserInfo,
+  OIDCStatus,
   UserForm,
   UserInfo
 } from "./type"
@@ -44,6 +45,9 @@ export type * from "./type"
 export const login = (data: UserForm): Promise<{ token: string }> =>
   request({ url: 'api/v1/user/login', method: 'post', data })
 
+export const getOIDCStatus = (): Promise<OIDCStatus> =>
+  request({ url: 'api/v1/user/oidc/status', method: 'get' })
+
 export const getUserList = (): Promise<UserInfo[]> =>
   request({ url: 'api/v1/user/list', method: 'get' })
 
@@ -102,6 +106,9 @@ export const updateNode = (data: UpdateNodeData): Promise<void> =>
 export const createNode = (data: CreateNodeData): Promise<{ id: string }> =>
   request({ url: 'api/v1/node', method: 'post', data })
 
+export const rewriteImportLinks = (data: { kb_id: string, links: { url: string, node_id: string }[] }): Promise<{ rewrite_count: number, updated_node_ids: string[] }> =>
+  request({ url: 'api/v1/node/rewrite_import_links', method: 'post', data })
+
 export const createNodeSummary = (data: CreateNodeSummaryData): Promise<{ summary: string }> =>
   request({ url: 'api/v1/node/summary', method: 'post', data })
 
@@ -22,6 +22,12 @@ export type UserForm = {
   password: string,
 }
 
+export type OIDCStatus = {
+  enabled: boolean,
+  display_name?: string,
+  password_login: boolean,
+}
+
 export type UserInfo = {
   id: string,
   account: string,
@@ -191,6 +191,16 @@ export const ModelProvider = {
     modelDocumentUrl: 'https://portal.azure.com/#view/Microsoft_Azure_ProjectOxford/CognitiveServicesHub/~/OpenAI',
     defaultBaseUrl: 'https://<resource_name>.openai.azure.com',
   },
+  vLLM: {
+    label: 'vLLM',
+    cn: '',
+    icon: 'icon-a-AIshezhi',
+    urlWrite: true,
+    secretRequired: false,
+    customHeader: true,
+    modelDocumentUrl: '',
+    defaultBaseUrl: 'http://127.0.0.1:8000/v1',
+  },
   Other: {
     label: 'Other',
     cn: '其他',
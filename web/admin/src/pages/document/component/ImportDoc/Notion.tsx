@@ -1,4 +1,4 @@
-import { createNode, getNotionIntegration, getNotionIntegrationDetail, ImportDocListItem, ImportDocProps } from "@/api"
+import { createNode, rewriteImportLinks, getNotionIntegration, getNotionIntegrationDetail, ImportDocListItem, ImportDocProps } from "@/api"
 import { useAppSelector } from "@/store"
 import { Box, Button, Checkbox, Skeleton, Stack, TextField } from "@mui/material"
 import { Ellipsis, Icon, Message, Modal } from "ct-mui"
@@ -120,6 +120,11 @@ const NotionImport = ({ open, refresh, onCancel, parentId = null }: ImportDocPro
           }
         }
       }
+      // 二次处理：将导入页面之间的链接指向新建的文档
+      const links = newItems.filter(item => item.success === 1 && item.id !== '' && item.id !== '-1').map(item => ({ url: item.url, node_id: item.id }))
+      if (links.length > 0) {
+        await rewriteImportLinks({ kb_id, links }).catch(() => { })
+      }
       const allSuccess = newItems.every(item => item.success === 1 && item.id !== '-1' && item.id !== '')
       setItems(newItems)
       if (allSuccess) {
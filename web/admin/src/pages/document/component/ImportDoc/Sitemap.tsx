@@ -1,4 +1,4 @@
-import { createNode, ImportDocListItem, ImportDocProps, scrapeCrawler, scrapeSitemap } from "@/api"
+import { createNode, rewriteImportLinks, ImportDocListItem, ImportDocProps, scrapeCrawler, scrapeSitemap } from "@/api"
 import { useAppSelector } from "@/store"
 import { Box, Button, Checkbox, Skeleton, Stack, TextField } from "@mui/material"
 import { Ellipsis, Icon, Message, Modal } from "ct-mui"
@@ -111,6 +111,11 @@ const SitemapImport = ({ open, refresh, onCancel, parentId = null }: ImportDocPr
           }
         }
       }
+      // 二次处理：将导入页面之间的链接指向新建的文档
+      const links = newItems.filter(item => item.success === 1 && item.id !== '' && item.id !== '-1').map(item => ({ url: item.url, node_id: item.id }))
+      if (links.length > 0) {
+        await rewriteImportLinks({ kb_id, links }).catch(() => { })
+      }
       const allSuccess = newItems.every(item => item.success === 1 && item.id !== '-1' && item.id !== '')
       setItems(newItems)
       if (allSuccess) {
@@ -1,4 +1,4 @@
-import { login } from '@/api'
+import { getOIDCStatus, login, OIDCStatus } from '@/api'
 import Bgi from '@/assets/images/login-bgi.png'
 import Logo from '@/assets/images/logo.png'
 import Avatar from '@/components/Avatar'
@@ -6,7 +6,7 @@ import Card from '@/components/Card'
 import { useURLSearchParams } from '@/hooks'
 import { Box, Button, IconButton, Stack, TextField } from "@mui/material"
 import { Icon, Message } from 'ct-mui'
-import { useState } from 'react'
+import { useEffect, useState } from 'react'
 import { useNavigate } from 'react-router-dom'
 
 const Login = () => {
@@ -17,6 +17,24 @@ const Login = () => {
   const [password, setPassword] = useState('')
   const [see, setSee] = useState(false)
   const [loading, setLoading] = useState(false)
+  const [oidc, setOIDC] = useState<OIDCStatus | null>(null)
+
+  useEffect(() => {
+    // the sso callback redirects back with the token or the error in the fragment
+    const hash = new URLSearchParams(window.location.hash.slice(1))
+    if (hash.get('token')) {
+      localStorage.setItem('panda_wiki_token', hash.get('token')!)
+      window.history.replaceState(null, '', window.location.pathname)
+      navigate(hash.get('redirect') || redirect)
+      Message.success('登录成功')
+      return
+    }
+    if (hash.get('error')) {
+      Message.error(hash.get('error')!)
+      window.history.replaceState(null, '', window.location.pathname)
+    }
+    getOIDCStatus().then(setOIDC)
+  }, [])
 
   const submit = () => {
     login({ account, password }).then((res) => {
@@ -124,6 +142,9 @@ const Login = () => {
             setLoading(true)
             submit()
           }} loading={loading}>登录</Button>
+          {oidc?.enabled && <Button fullWidth variant='outlined' sx={{ height: 48, mt: 2 }} onClick={() => {
+            window.location.href = `/api/v1/user/oidc/login?redirect=${encodeURIComponent(redirect)}`
+          }}>使用 {oidc.display_name || 'SSO'} 登录</Button>}
         </Stack>
       </Card>
     </Stack>
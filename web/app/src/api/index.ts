@@ -109,13 +109,78 @@ class ApiClient {
   //   });
   // }
 
+  // 客服端漏斗埋点，step: search | resolution
+  async clientStatFunnel(data: { step: 'search' | 'resolution', kb_id: string, authToken?: string }): Promise<Response<void>> {
+    return this.serverRequest(window?.location.origin + '/client/v1/stat/funnel', {
+      method: 'POST',
+      body: JSON.stringify({
+        step: data.step,
+      }),
+    }, {
+      kb_id: data.kb_id,
+      authToken: data.authToken,
+    });
+  }
+
+  // 发送对话记录到邮箱
+  async clientSendTranscriptEmail(data: { conversation_id: string, nonce: string, email: string, kb_id: string, authToken?: string }): Promise<{ success: boolean, message?: string }> {
+    try {
+      const response = await fetch(window?.location.origin + '/client/v1/chat/transcript_email', {
+        method: 'POST',
+        headers: {
+          'Content-Type': 'application/json',
+          'x-kb-id': data.kb_id,
+          'x-simple-auth-password': data.authToken || '',
+        },
+        body: JSON.stringify({
+          conversation_id: data.conversation_id,
+          nonce: data.nonce,
+          email: data.email,
+        }),
+      });
+      const result = await response.json();
+      return { success: !!result.success, message: result.message };
+    } catch (error) {
+      return { success: false, message: error instanceof Error ? error.message : 'Unknown error' };
+    }
+  }
+
+  // 输入问题时预先检索相关文档，发送同样的问题时复用
+  async clientPrefetchRetrieval(data: { message: string, kb_id: string, authToken?: string }): Promise<Response<{ ready: boolean }>> {
+    return this.serverRequest(window?.location.origin + '/client/v1/chat/prefetch', {
+      method: 'POST',
+      body: JSON.stringify({
+        message: data.message,
+      }),
+    }, {
+      kb_id: data.kb_id,
+      authToken: data.authToken,
+    });
+  }
+
+  // 客服端页面停留时长埋点
+  async clientStatDwell(data: { node_id: string, duration: number, kb_id: string, authToken?: string }): Promise<Response<void>> {
+    return this.serverRequest(window?.location.origin + '/client/v1/stat/dwell', {
+      method: 'POST',
+      body: JSON.stringify({
+        node_id: data.node_id,
+        duration: data.duration,
+      }),
+    }, {
+      kb_id: data.kb_id,
+      authToken: data.authToken,
+    });
+  }
+
   // 客服端页面埋点
   async clientStatPage(data: { node_id: string, scene: number, kb_id: string, authToken?: string }): Promise<Response<void>> {
     return this.serverRequest(window?.location.origin + '/client/v1/stat/page', {
       method: 'POST',
       body: JSON.stringify({
         node_id: data.node_id,
-        scene: data.scene
+        scene: data.scene,
+        referer: document.referrer,
+        url: window.location.href,
       }),
     }, {
       kb_id: data.kb_id,
@@ -18,8 +18,13 @@ export async function generateMetadata(
   const cookieStore = await cookies()
   const authToken = cookieStore.get(`auth_${kb_id}`)?.value || '';
   const node = await getNodeDetail(id, kb_id, authToken);
+  const seo = node?.meta?.seo
   return await formatMeta(
-    { title: node?.name },
+    {
+      title: seo?.title || node?.name,
+      description: seo?.description || node?.meta?.summary,
+      keywords: seo?.keywords || node?.meta?.tags,
+    },
     parent
   );
 }
@@ -103,6 +103,7 @@ export default async function RootLayout({
   return (
     <html lang="en">
       <head>
+        <link rel="search" type="application/opensearchdescription+xml" href="/opensearch.xml" title={kbDetail?.settings?.title || 'Panda-Wiki'} />
         {kbDetail?.settings?.head_code && (
           <>{parse(kbDetail.settings.head_code, options)}</>
         )}
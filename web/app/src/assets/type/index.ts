@@ -93,6 +93,12 @@ export interface NodeDetail {
   meta: {
     summary: string
     emoji?: string
+    tags?: string[]
+    seo?: {
+      title?: string
+      description?: string
+      keywords?: string
+    }
   }
 }
 
@@ -1,18 +1,31 @@
 'use client';
 
 
+import { apiClient } from '@/api';
 import { IconArrowUp } from '@/components/icons';
 import MarkDown from '@/components/markdown';
 import { useStore } from '@/provider';
 import ExpandMoreIcon from '@mui/icons-material/ExpandMore';
-import { Accordion, AccordionDetails, AccordionSummary, Box, IconButton, Skeleton, Stack, TextField } from "@mui/material";
+import { Accordion, AccordionDetails, AccordionSummary, Box, Chip, IconButton, Skeleton, Stack, TextField } from "@mui/material";
 import { useEffect, useState } from 'react';
 import ChatLoading from './ChatLoading';
 import { AnswerStatus } from './constant';
 
+// 回答后推荐的追问，每个都能由检索到的文档回答
+export interface FollowUpQuestion {
+  question: string;
+  node_id: string;
+  node_name: string;
+}
+
 interface ChatResultProps {
   conversation: { q: string, a: string }[];
   answer: string;
+  followUps: FollowUpQuestion[];
+  // 最后一个回答来自相似问题的缓存
+  cached: boolean;
+  // 问题或回答触发了应用的安全防护提醒
+  guardrailWarned: boolean;
   loading: boolean;
   thinking: keyof typeof AnswerStatus;
   onSearch: (input: string) => void;
@@ -20,9 +33,21 @@ interface ChatResultProps {
   setThinking: (thinking: keyof typeof AnswerStatus) => void;
 }
 
-const ChatResult = ({ conversation, answer, loading, thinking, onSearch, handleSearchAbort, setThinking }: ChatResultProps) => {
+// 停止输入多久后预先检索
+const PREFETCH_DELAY = 600
+
+const ChatResult = ({ conversation, answer, followUps, cached, guardrailWarned, loading, thinking, onSearch, handleSearchAbort, setThinking }: ChatResultProps) => {
   const [input, setInput] = useState('')
-  const { mobile = false, themeMode = 'light' } = useStore()
+  const { mobile = false, themeMode = 'light', kb_id, token } = useStore()
+
+  useEffect(() => {
+    const message = input.trim()
+    if (!kb_id || message.length < 4) return
+    const timer = setTimeout(() => {
+      apiClient.clientPrefetchRetrieval({ message, kb_id, authToken: token || '' })
+    }, PREFETCH_DELAY)
+    return () => clearTimeout(timer)
+  }, [input, kb_id, token])
 
   const handleSearch = () => {
     if (input.length > 0) {
@@ -84,6 +109,23 @@ const ChatResult = ({ conversation, answer, loading, thinking, onSearch, handleS
               <Skeleton variant="text" width="70%" />
             </>}
             {index === conversation.length - 1 && answer && <MarkDown content={answer} />}
+            {index === conversation.length - 1 && !loading && cached && <Box sx={{ mt: 1, fontSize: 12, color: 'text.secondary' }}>
+              该回答来自相似问题的缓存
+            </Box>}
+            {index === conversation.length - 1 && !loading && guardrailWarned && <Box sx={{ mt: 1, fontSize: 12, color: 'warning.main' }}>
+              该问题或回答触发了安全提醒，回答仅供参考
+            </Box>}
+            {index === conversation.length - 1 && !loading && followUps.length > 0 && <Stack direction='row' flexWrap='wrap' gap={1} sx={{ mt: 2 }}>
+              {followUps.map(item => <Chip
+                key={item.question}
+                label={item.question}
+                title={item.node_name}
+                variant='outlined'
+                size='small'
+                clickable
+                onClick={() => onSearch(item.question)}
+              />)}
+            </Stack>}
           </AccordionDetails>
         </Accordion>
       ))}
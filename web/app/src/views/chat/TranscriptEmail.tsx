@@ -0,0 +1,73 @@
+'use client';
+
+import { apiClient } from '@/api';
+import { useStore } from '@/provider';
+import { Box, Button, Dialog, DialogActions, DialogContent, DialogTitle, TextField } from '@mui/material';
+import { message } from 'ct-mui';
+import { useState } from 'react';
+
+interface TranscriptEmailProps {
+  conversationId: string;
+  nonce: string;
+  disabled?: boolean;
+}
+
+const TranscriptEmail = ({ conversationId, nonce, disabled }: TranscriptEmailProps) => {
+  const { kb_id, token } = useStore()
+  const [open, setOpen] = useState(false)
+  const [email, setEmail] = useState('')
+  const [sending, setSending] = useState(false)
+
+  if (!conversationId || !nonce) return null
+
+  const handleSend = async () => {
+    if (!/^[^\s@]+@[^\s@]+\.[^\s@]+$/.test(email)) {
+      message.error('请输入正确的邮箱地址')
+      return
+    }
+    setSending(true)
+    const res = await apiClient.clientSendTranscriptEmail({
+      conversation_id: conversationId,
+      nonce,
+      email,
+      kb_id: kb_id || '',
+      authToken: token || '',
+    })
+    setSending(false)
+    if (res.success) {
+      message.success('对话记录已发送到邮箱')
+      setOpen(false)
+    } else {
+      message.error(res.message || '发送失败，请稍后再试')
+    }
+  }
+
+  return <>
+    <Box
+      sx={{ fontSize: 12, color: 'text.secondary', cursor: disabled ? 'not-allowed' : 'pointer', '&:hover': { color: disabled ? 'text.secondary' : 'primary.main' } }}
+      onClick={() => !disabled && setOpen(true)}
+    >
+      发送到邮箱
+    </Box>
+    <Dialog open={open} onClose={() => setOpen(false)} fullWidth maxWidth='xs'>
+      <DialogTitle>发送对话记录</DialogTitle>
+      <DialogContent>
+        <TextField
+          fullWidth
+          autoFocus
+          size='small'
+          sx={{ mt: 1 }}
+          value={email}
+          onChange={e => setEmail(e.target.value)}
+          placeholder='请输入邮箱地址'
+        />
+      </DialogContent>
+      <DialogActions>
+        <Button onClick={() => setOpen(false)}>取消</Button>
+        <Button variant='contained' disabled={sending} onClick={handleSend}>发送</Button>
+      </DialogActions>
+    </Dialog>
+  </>
+}
+
+export default TranscriptEmail;
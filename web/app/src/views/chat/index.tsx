@@ -8,9 +8,10 @@ import SSEClient from '@/utils/fetch';
 import { Box, Stack } from '@mui/material';
 import { message } from 'ct-mui';
 import { useCallback, useEffect, useRef, useState } from 'react';
-import ChatResult from './ChatResult';
+import ChatResult, { FollowUpQuestion } from './ChatResult';
 import ChatTab from './ChatTab';
 import SearchResult from './SearchResult';
+import TranscriptEmail from './TranscriptEmail';
 import { AnswerStatus } from './constant';
 
 const Chat = () => {
@@ -22,6 +23,9 @@ const Chat = () => {
     type: string;
     content: string;
     chunk_result: ChunkResultItem[];
+    follow_ups?: FollowUpQuestion[];
+    metadata?: { route: string };
+    guardrail?: { check: string; stage: string; action: string; detail: string };
   }> | null>(null);
 
   const [conversation, setConversation] = useState<{ q: string, a: string }[]>([]);
@@ -32,6 +36,9 @@ const Chat = () => {
   const [chunkLoading, setChunkLoading] = useState(false);
   const [conversationId, setConversationId] = useState('');
   const [answer, setAnswer] = useState('');
+  const [followUps, setFollowUps] = useState<FollowUpQuestion[]>([]);
+  const [cached, setCached] = useState(false);
+  const [guardrailWarned, setGuardrailWarned] = useState(false);
   const [isUserScrolling, setIsUserScrolling] = useState(false);
 
   const [showType, setShowType] = useState<'chat' | 'search'>('chat');
@@ -49,6 +56,8 @@ const Chat = () => {
       nonce: '',
       conversation_id: '',
       app_type: inIframe ? 2 : 1,
+      referer: document.referrer,
+      url: window.location.href,
     };
     if (conversationId) reqData.conversation_id = conversationId;
     if (nonce) reqData.nonce = nonce;
@@ -56,7 +65,7 @@ const Chat = () => {
     if (sseClientRef.current) {
       sseClientRef.current.subscribe(
         JSON.stringify(reqData),
-        ({ type, content, chunk_result }) => {
+        ({ type, content, chunk_result, follow_ups, metadata, guardrail }) => {
           if (type === 'conversation_id') {
             setConversationId((prev) => prev + content);
           } else if (type === 'nonce') {
@@ -73,6 +82,7 @@ const Chat = () => {
             });
             if (content) message.error(content);
           } else if (type === 'done') {
+            setCached(metadata?.route === 'cache');
             setChunkLoading(false);
             setLoading(false);
             setThinking(4);
@@ -91,6 +101,10 @@ const Chat = () => {
               setThinking(3);
               return newAnswer;
             });
+          } else if (type === 'guardrail') {
+            if (guardrail) setGuardrailWarned(true);
+          } else if (type === 'suggestions') {
+            setFollowUps(follow_ups || []);
           } else if (type === 'chunk_result') {
             setChunkResult((prev) => {
               return [...prev, chunk_result];
@@ -110,6 +124,9 @@ const Chat = () => {
     newConversation.push({ q, a: '' });
     setConversation(newConversation);
     setAnswer('');
+    setFollowUps([]);
+    setCached(false);
+    setGuardrailWarned(false);
     setChunkResult([]);
     setTimeout(() => {
       chatAnswer(q);
@@ -147,13 +164,14 @@ const Chat = () => {
   }, [answer, isUserScrolling]);
 
   useEffect(() => {
-    // 从sessionStorage读取搜索内容
-    const searchQuery = sessionStorage.getItem('chat_search_query');
+    // 从sessionStorage或浏览器地址栏搜索（?q=）读取搜索内容
+    const searchQuery = sessionStorage.getItem('chat_search_query') || new URLSearchParams(window.location.search).get('q');
     if (searchQuery) {
       // 清理sessionStorage
       sessionStorage.removeItem('chat_search_query');
       // 执行搜索
       onSearch(searchQuery, true);
+      apiClient.clientStatFunnel({ step: 'search', kb_id: kb_id || '', authToken: token || '' });
     }
   }, []);
 
@@ -173,10 +191,16 @@ const Chat = () => {
   if (mobile) {
     return <Box sx={{ pt: 12, minHeight: '100vh', position: 'relative' }}>
       <ChatTab showType={showType} setShowType={setShowType} />
+      <Stack direction='row' justifyContent='flex-end' sx={{ mx: 3, mb: 1 }}>
+        <TranscriptEmail conversationId={conversationId} nonce={nonce} disabled={loading} />
+      </Stack>
       <Box sx={{ mx: 3 }}>
         {showType === 'chat' ? <ChatResult
           conversation={conversation}
           answer={answer}
+          followUps={followUps}
+          cached={cached}
+          guardrailWarned={guardrailWarned}
           loading={loading}
           thinking={thinking}
           setThinking={setThinking}
@@ -204,6 +228,9 @@ const Chat = () => {
           <ChatResult
             conversation={conversation}
             answer={answer}
+            followUps={followUps}
+            cached={cached}
+            guardrailWarned={guardrailWarned}
             loading={loading}
             thinking={thinking}
             setThinking={setThinking}
@@ -220,12 +247,14 @@ const Chat = () => {
           p: 3,
           bgcolor: 'background.paper',
         }}>
-          <Box sx={{
-            fontSize: '20px',
-            fontWeight: '700',
-            lineHeight: '28px',
-            mb: 2,
-          }}>搜索结果</Box>
+          <Stack direction='row' alignItems='center' justifyContent='space-between' sx={{ mb: 2 }}>
+            <Box sx={{
+              fontSize: '20px',
+              fontWeight: '700',
+              lineHeight: '28px',
+            }}>搜索结果</Box>
+            <TranscriptEmail conversationId={conversationId} nonce={nonce} disabled={loading} />
+          </Stack>
           <SearchResult list={chunkResult} loading={chunkLoading} />
         </Box>
       </Stack>
@@ -106,6 +106,10 @@ const Doc = ({ node: defaultNode, token }: { node?: NodeDetail, token?: string }
     setFirstRequest(false)
     apiClient.clientStatPage({ scene: VisitSceneNode, node_id: docId || '', kb_id: kb_id || '', authToken: token });
     window.scrollTo({ top: 0, behavior: 'smooth' })
+    const enterAt = Date.now()
+    return () => {
+      if (docId) apiClient.clientStatDwell({ node_id: docId, duration: Date.now() - enterAt, kb_id: kb_id || '', authToken: token });
+    }
   }, [docId])
 
   if (mobile) {